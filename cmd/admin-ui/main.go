@@ -1,10 +1,12 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -15,8 +17,11 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	"golang.org/x/oauth2"
+
+	"github.com/edvin/hosting/internal/ipallow"
 )
 
 func main() {
@@ -77,8 +82,28 @@ func main() {
 	spa := spaHandler{staticDir: staticDir}
 	mux.Handle("/", spa)
 
+	// Optional IP allowlist for the UI, SSO callbacks and the API proxy.
+	// X-Forwarded-For is only honored from TRUSTED_PROXIES (e.g. the load
+	// balancer). The core API should list this service in its own
+	// TRUSTED_PROXIES, since the proxy appends the client to X-Forwarded-For.
+	allowlist, err := ipallow.New(os.Getenv("IP_ALLOWLIST"), os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("invalid IP_ALLOWLIST/TRUSTED_PROXIES: %v", err)
+	}
+	var handler http.Handler = mux
+	if allowlist.Enabled() {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/health" && !allowlist.Allows(r) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			mux.ServeHTTP(w, r)
+		})
+		log.Printf("IP allowlist enabled")
+	}
+
 	log.Printf("Admin UI listening on %s (proxying API to %s, static from %s)", listenAddr, coreAPIURL, staticDir)
-	if err := http.ListenAndServe(listenAddr, mux); err != nil {
+	if err := http.ListenAndServe(listenAddr, handler); err != nil {
 		log.Fatalf("server error: %v", err)
 	}
 }
@@ -122,6 +147,9 @@ type oidcHandler struct {
 	oauthConfig *oauth2.Config
 	coreAPIURL  string
 	adminAPIKey string
+	client      *http.Client
+	// retryDelays are the waits between API key provisioning attempts.
+	retryDelays []time.Duration
 	// state tokens: map[state]true, cleaned up after use
 	mu     sync.Mutex
 	states map[string]bool
@@ -158,6 +186,8 @@ func newOIDCHandler(coreAPIURL string) *oidcHandler {
 		},
 		coreAPIURL:  coreAPIURL,
 		adminAPIKey: adminAPIKey,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		retryDelays: []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second},
 		states:      make(map[string]bool),
 	}
 }
@@ -212,9 +242,15 @@ func (h *oidcHandler) handleCallback(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create an API key for this user via core-api
-	apiKey, err := h.createAPIKey(email, name)
+	apiKey, err := h.createAPIKey(r.Context(), email, name)
 	if err != nil {
 		log.Printf("Failed to create API key for %s: %v", email, err)
+		if errors.Is(err, errCoreUnavailable) {
+			// The authorization code is single-use, so retrying means
+			// starting a fresh login rather than reloading the callback.
+			writeRetryPage(w)
+			return
+		}
 		http.Error(w, "failed to provision access", http.StatusInternalServerError)
 		return
 	}
@@ -232,41 +268,94 @@ window.location.href = '/';
 </body></html>`, jsonString(apiKey))
 }
 
-// createAPIKey calls core-api to create (or find existing) an API key for the SSO user.
-func (h *oidcHandler) createAPIKey(email, name string) (string, error) {
+// errCoreUnavailable marks provisioning failures caused by core-api being
+// unreachable or overloaded, as opposed to the request being rejected.
+var errCoreUnavailable = errors.New("core-api unavailable")
+
+// createAPIKey calls core-api to provision the API key for the SSO user.
+// Core-api rotates the existing sso:<email> key instead of creating a new one,
+// so retrying after a lost response is safe. Transient failures are retried
+// with backoff; if core-api stays unavailable the error wraps
+// errCoreUnavailable.
+func (h *oidcHandler) createAPIKey(ctx context.Context, email, name string) (string, error) {
 	keyName := fmt.Sprintf("sso:%s", email)
 
-	body, _ := json.Marshal(map[string]string{
-		"name": keyName,
+	body, _ := json.Marshal(map[string]any{
+		"name":   keyName,
+		"scopes": []string{"*:*"},
+		"brands": []string{"*"},
 	})
 
-	req, err := http.NewRequest("POST", h.coreAPIURL+"/api/v1/api-keys", strings.NewReader(string(body)))
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		key, err := h.postAPIKey(ctx, body)
+		if err == nil {
+			return key, nil
+		}
+		lastErr = err
+		if !errors.Is(err, errCoreUnavailable) || attempt >= len(h.retryDelays) {
+			break
+		}
+		log.Printf("API key provisioning for %s failed (attempt %d), retrying: %v", email, attempt+1, err)
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("%w: %v", errCoreUnavailable, ctx.Err())
+		case <-time.After(h.retryDelays[attempt]):
+		}
+	}
+	return "", lastErr
+}
+
+// postAPIKey makes a single create request to core-api.
+func (h *oidcHandler) postAPIKey(ctx context.Context, body []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", h.coreAPIURL+"/api/v1/api-keys", bytes.NewReader(body))
 	if err != nil {
 		return "", err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+h.adminAPIKey)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := h.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("core-api request failed: %w", err)
+		return "", fmt.Errorf("%w: %v", errCoreUnavailable, err)
 	}
 	defer resp.Body.Close()
 
 	respBody, _ := io.ReadAll(resp.Body)
 
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return "", fmt.Errorf("%w: status %d: %s", errCoreUnavailable, resp.StatusCode, string(respBody))
+	}
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		return "", fmt.Errorf("core-api returned %d: %s", resp.StatusCode, string(respBody))
 	}
 
 	var result struct {
-		RawKey string `json:"raw_key"`
+		Key string `json:"key"`
 	}
 	if err := json.Unmarshal(respBody, &result); err != nil {
 		return "", fmt.Errorf("parse response: %w", err)
 	}
+	if result.Key == "" {
+		return "", fmt.Errorf("core-api response has no key")
+	}
 
-	return result.RawKey, nil
+	return result.Key, nil
+}
+
+// writeRetryPage tells the user sign-in could not complete because core-api
+// is temporarily unavailable, and offers to start the login again.
+func writeRetryPage(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Retry-After", "10")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	fmt.Fprint(w, `<!DOCTYPE html>
+<html><head><title>Sign-in unavailable</title></head>
+<body>
+<h1>Sign-in temporarily unavailable</h1>
+<p>The hosting API did not respond while completing your sign-in. This is usually brief.</p>
+<p><a href="/auth/login">Try again</a></p>
+</body></html>`)
 }
 
 // parseIDTokenClaims extracts email and name from a JWT ID token without
@@ -331,6 +420,14 @@ func proxyWebSocket(w http.ResponseWriter, r *http.Request, target *url.URL) {
 	r.URL.Scheme = target.Scheme
 	r.URL.Host = target.Host
 	r.Host = target.Host
+	// Append the client like httputil.ReverseProxy does, so the core API's
+	// IP allowlist sees the real client.
+	if clientIP, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		if prior := r.Header.Get("X-Forwarded-For"); prior != "" {
+			clientIP = prior + ", " + clientIP
+		}
+		r.Header.Set("X-Forwarded-For", clientIP)
+	}
 	if err := r.Write(upstream); err != nil {
 		http.Error(w, "failed to write to upstream", http.StatusBadGateway)
 		return
@@ -9,7 +9,11 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// Timezones in requests (cron jobs, maintenance windows) are validated
+	// against the tz database, which the runtime image does not ship.
+	_ "time/tzdata"
 
+	"github.com/rs/zerolog"
 	temporalclient "go.temporal.io/sdk/client"
 
 	"github.com/edvin/hosting/internal/api"
@@ -18,8 +22,13 @@ import (
 	"github.com/edvin/hosting/internal/db"
 	"github.com/edvin/hosting/internal/logging"
 	"github.com/edvin/hosting/internal/metrics"
+	"github.com/edvin/hosting/internal/secrets"
 )
 
+// workflowStartGrace is how long shutdown waits for workflow starts still in
+// progress once the HTTP server has stopped.
+const workflowStartGrace = 5 * time.Second
+
 func main() {
 	if len(os.Args) >= 2 && os.Args[1] == "create-api-key" {
 		createAPIKey(os.Args[2:])
@@ -40,6 +49,8 @@ func main() {
 		fmt.Fprintf(os.Stderr, "invalid config: %v\n", err)
 		os.Exit(1)
 	}
+	// Validated above.
+	secrets.DefaultGenerator, _ = cfg.PasswordGenerator()
 
 	logger := logging.NewLogger(cfg)
 
@@ -53,7 +64,7 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	corePool, err := db.NewCorePool(ctx, cfg.CoreDatabaseURL)
+	corePool, err := db.NewCorePool(ctx, cfg.CoreDatabaseURL, db.CorePoolOptions(cfg, logger))
 	if err != nil {
 		logger.Fatal().Err(err).Msg("failed to connect to core database")
 	}
@@ -65,7 +76,7 @@ func main() {
 	if err != nil {
 		logger.Fatal().Err(err).Msg("failed to configure temporal TLS")
 	}
-	dialOpts := temporalclient.Options{HostPort: cfg.TemporalAddress}
+	dialOpts := temporalclient.Options{HostPort: cfg.TemporalAddress, Logger: logging.TemporalLogger(logger)}
 	if tlsConfig != nil {
 		dialOpts.ConnectionOptions = temporalclient.ConnectionOptions{TLS: tlsConfig}
 		logger.Info().Msg("temporal mTLS enabled")
@@ -76,7 +87,7 @@ func main() {
 	}
 	defer tc.Close()
 
-	srv := api.NewServer(logger, corePool, tc, cfg)
+	srv := api.NewServer(logger, corePool, tc, config.NewStore(cfg, config.LogLevelReloadable...))
 
 	httpServer := &http.Server{
 		Addr:         cfg.HTTPListenAddr,
@@ -93,14 +104,57 @@ func main() {
 		}
 	}()
 
+	// SIGHUP reloads the hot-reloadable config fields without a restart.
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			if _, err := srv.ReloadConfig(); err != nil {
+				logger.Error().Err(err).Msg("config reload failed")
+			}
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Info().Msg("shutting down server")
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer shutdownCancel()
-	httpServer.Shutdown(shutdownCtx)
+	shutdown(httpServer, srv, time.Duration(cfg.ShutdownTimeoutSecs)*time.Second, logger)
+}
+
+// shutdown drains the server: it stops accepting connections, waits up to
+// timeout for in-flight requests, then for workflow starts those requests
+// issued, so none is cut off between recording its operation and reaching
+// Temporal. The audit log is flushed if every request finished.
+func shutdown(httpServer *http.Server, srv *api.Server, timeout time.Duration, logger zerolog.Logger) {
+	logger.Info().
+		Int("in_flight_requests", srv.InFlightRequests()).
+		Int("in_flight_workflow_starts", core.InFlightWorkflowStarts()).
+		Dur("timeout", timeout).
+		Msg("shutting down server")
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	drained := true
+	if err := httpServer.Shutdown(ctx); err != nil {
+		drained = false
+		logger.Warn().Err(err).Int("in_flight_requests", srv.InFlightRequests()).
+			Msg("shutdown timed out with requests in flight")
+	}
+
+	// Starts issued by requests abandoned above get a short grace of their
+	// own; Temporal calls are bounded by the client's RPC timeout.
+	startCtx, startCancel := context.WithTimeout(context.Background(), workflowStartGrace)
+	defer startCancel()
+	if n := core.WaitWorkflowStarts(startCtx); n > 0 {
+		logger.Warn().Int("in_flight_workflow_starts", n).
+			Msg("workflow starts still in progress at exit; their operations may stay pending")
+	}
+
+	if drained {
+		srv.Close()
+	}
+	logger.Info().Msg("server stopped")
 }
 
 func createAPIKey(args []string) {
@@ -124,7 +178,7 @@ func createAPIKey(args []string) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	pool, err := db.NewCorePool(ctx, cfg.CoreDatabaseURL)
+	pool, err := db.NewCorePool(ctx, cfg.CoreDatabaseURL, db.PoolOptions{})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: failed to connect to database: %v\n", err)
 		os.Exit(1)
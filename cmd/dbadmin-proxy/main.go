@@ -10,12 +10,15 @@ import (
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/edvin/hosting/internal/crypto"
 )
 
 func main() {
 	listenAddr := envOr("LISTEN_ADDR", "127.0.0.1:4180")
 	coreAPIURL := requireEnv("CORE_API_URL")
 	coreAPIToken := requireEnv("CORE_API_TOKEN")
+	signingSecret := os.Getenv("INTERNAL_SIGNING_SECRET")
 	sessionDir := envOr("SESSION_DIR", "/tmp/dbadmin-sessions")
 
 	// Ensure session directory exists.
@@ -36,7 +39,7 @@ func main() {
 			return
 		}
 
-		session, err := validateSession(coreAPIURL, coreAPIToken, token)
+		session, err := validateSession(coreAPIURL, coreAPIToken, signingSecret, token)
 		if err != nil {
 			log.Printf("session validation failed: %v", err)
 			http.Error(w, "invalid or expired session", http.StatusForbidden)
@@ -48,8 +51,9 @@ func main() {
 			return
 		}
 
-		// Request temp MySQL credentials from the core API.
-		creds, err := requestTempAccess(coreAPIURL, coreAPIToken, session.Database.ID)
+		// Request temp MySQL credentials from the core API. They are recorded
+		// on the login session, which can be revoked to drop them early.
+		creds, err := requestTempAccess(coreAPIURL, coreAPIToken, signingSecret, session.Database.ID, token)
 		if err != nil {
 			log.Printf("temp access request failed: %v", err)
 			http.Error(w, "failed to create database access", http.StatusInternalServerError)
@@ -125,18 +129,19 @@ type tempAccessResult struct {
 }
 
 // validateSession calls the core API to validate and consume a login session.
-func validateSession(apiURL, apiToken, sessionID string) (*sessionResult, error) {
+func validateSession(apiURL, apiToken, signingSecret, sessionID string) (*sessionResult, error) {
 	req, err := http.NewRequest("POST", apiURL+"/internal/v1/login-sessions/validate", nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+apiToken)
-	req.Header.Set("Content-Type", "application/json")
-
 	q := req.URL.Query()
 	q.Set("session_id", sessionID)
 	req.URL.RawQuery = q.Encode()
 
+	if err := authorize(req, apiToken, signingSecret); err != nil {
+		return nil, err
+	}
+
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("api request: %w", err)
@@ -154,14 +159,19 @@ func validateSession(apiURL, apiToken, sessionID string) (*sessionResult, error)
 	return &result, nil
 }
 
-// requestTempAccess calls the core API to create a temporary MySQL user.
-func requestTempAccess(apiURL, apiToken, databaseID string) (*tempAccessResult, error) {
+// requestTempAccess calls the core API to create a temporary MySQL user for
+// a validated login session.
+func requestTempAccess(apiURL, apiToken, signingSecret, databaseID, sessionID string) (*tempAccessResult, error) {
 	req, err := http.NewRequest("POST", apiURL+"/internal/v1/databases/"+databaseID+"/temp-access", nil)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Authorization", "Bearer "+apiToken)
-	req.Header.Set("Content-Type", "application/json")
+	q := req.URL.Query()
+	q.Set("session_id", sessionID)
+	req.URL.RawQuery = q.Encode()
+	if err := authorize(req, apiToken, signingSecret); err != nil {
+		return nil, err
+	}
 
 	client := &http.Client{Timeout: 60 * time.Second}
 	resp, err := client.Do(req)
@@ -181,6 +191,20 @@ func requestTempAccess(apiURL, apiToken, databaseID string) (*tempAccessResult,
 	return &result, nil
 }
 
+// authorize sets the bearer token on a core API request and, when a signing
+// secret is configured, signs it. The request must be complete.
+func authorize(req *http.Request, apiToken, signingSecret string) error {
+	req.Header.Set("Authorization", "Bearer "+apiToken)
+	req.Header.Set("Content-Type", "application/json")
+	if signingSecret == "" {
+		return nil
+	}
+	if err := crypto.SignRequest(req, signingSecret); err != nil {
+		return fmt.Errorf("sign request: %w", err)
+	}
+	return nil
+}
+
 // randomAlphanumeric generates a random alphanumeric string of the given length.
 func randomAlphanumeric(n int) string {
 	const chars = "abcdefghijklmnopqrstuvwxyz0123456789"
@@ -75,6 +75,24 @@ func main() {
 			os.Exit(1)
 		}
 
+	case "converge-cluster":
+		fs := flag.NewFlagSet("converge-cluster", flag.ExitOnError)
+		apiURL := fs.String("api", "http://localhost:8080", "Core API base URL")
+		apiKey := fs.String("api-key", "", "API key for authentication")
+		maxConcurrent := fs.Int("max-concurrent", 0, "Maximum shards converging at once (default: server default)")
+		poll := fs.Duration("poll", 2*time.Second, "Progress poll interval")
+		fs.Parse(os.Args[2:])
+
+		if fs.NArg() < 1 {
+			fmt.Fprintln(os.Stderr, "Usage: hostctl converge-cluster [-api URL] [-api-key KEY] [-max-concurrent N] <cluster-id>")
+			os.Exit(1)
+		}
+
+		if err := hostctl.ConvergeCluster(*apiURL, *apiKey, fs.Arg(0), *maxConcurrent, *poll); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", os.Args[1])
 		printUsage()
@@ -122,11 +140,13 @@ func printUsage() {
   hostctl cluster apply -f <file> [-f <file>...]
   hostctl seed -f <seed-definition.yaml>
   hostctl converge-shard [-api URL] <shard-id>
+  hostctl converge-cluster [-api URL] [-max-concurrent N] <cluster-id>
 
 Commands:
   cluster apply    Bootstrap cluster infrastructure from a YAML definition
   seed             Seed test data (tenants, webroots, FQDNs, zones, databases, email)
   converge-shard   Trigger shard convergence (push all resources to all nodes)
+  converge-cluster Converge every shard in a cluster and stream per-shard progress
 
 Flags:
   -f string         Path to YAML configuration file (required for cluster/seed)
@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/edvin/hosting/internal/cli"
 )
@@ -25,13 +27,13 @@ func main() {
 	case "use":
 		cmdUse(os.Args[2:])
 	case "active":
-		cmdActive()
+		cmdActive(os.Args[2:])
 	case "tunnel":
 		cmdTunnel(os.Args[2:])
 	case "proxy":
 		cmdProxy(os.Args[2:])
 	case "status":
-		cmdStatus()
+		cmdStatus(os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", os.Args[1])
 		printUsage()
@@ -74,6 +76,11 @@ func cmdImport(args []string) {
 }
 
 func cmdProfiles(args []string) {
+	if len(args) > 0 && args[0] == "delete" {
+		cmdProfilesDelete(args[1:])
+		return
+	}
+
 	profiles, err := cli.ListProfiles()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -103,19 +110,29 @@ func cmdProfiles(args []string) {
 		}
 		fmt.Printf("%-20s %-15s %-30s %s\n", p.Name, alias, tenant, marker)
 	}
+}
 
-	// Handle delete subcommand.
-	if len(args) > 0 && args[0] == "delete" {
-		if len(args) < 2 {
-			fmt.Fprintln(os.Stderr, "Usage: hosting-cli profiles delete <name>")
-			os.Exit(1)
-		}
-		if err := cli.DeleteProfile(args[1]); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-		fmt.Printf("Deleted profile %q\n", args[1])
+func cmdProfilesDelete(args []string) {
+	fs := flag.NewFlagSet("profiles delete", flag.ExitOnError)
+	yes := fs.Bool("y", false, "Delete without asking for confirmation")
+	fs.Parse(args)
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: hosting-cli profiles delete [-y] <name>")
+		os.Exit(1)
 	}
+	name := fs.Arg(0)
+
+	if !*yes && !cli.Confirm(os.Stdin, os.Stdout, fmt.Sprintf("Delete profile %q?", name)) {
+		fmt.Println("Aborted.")
+		os.Exit(1)
+	}
+
+	if err := cli.DeleteProfile(name); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Deleted profile %q\n", name)
 }
 
 func cmdUse(args []string) {
@@ -133,9 +150,18 @@ func cmdUse(args []string) {
 	fmt.Printf("Active profile set to %q\n", name)
 }
 
-func cmdActive() {
+func cmdActive(args []string) {
+	fs := flag.NewFlagSet("active", flag.ExitOnError)
+	output := fs.String("o", "", "Output format: json or yaml (default: human-readable)")
+	fs.Parse(args)
+	format := parseOutputFlag(*output)
+
 	active, err := cli.GetActive()
 	if err != nil || active == "" {
+		if format != cli.OutputText {
+			fmt.Fprintln(os.Stderr, "No active profile. Set one with: hosting-cli use <name>")
+			os.Exit(1)
+		}
 		fmt.Println("No active profile. Set one with: hosting-cli use <name>")
 		return
 	}
@@ -146,6 +172,11 @@ func cmdActive() {
 		os.Exit(1)
 	}
 
+	if format != cli.OutputText {
+		writeProfileStatus(profile, cfg, format)
+		return
+	}
+
 	fmt.Printf("Active profile: %s\n", profile.Name)
 	if profile.Alias != "" {
 		fmt.Printf("Alias:          %s\n", profile.Alias)
@@ -163,6 +194,24 @@ func cmdActive() {
 	}
 }
 
+// parseOutputFlag validates the -o flag, exiting on an unknown format.
+func parseOutputFlag(value string) string {
+	format, err := cli.ParseOutputFormat(value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return format
+}
+
+// writeProfileStatus prints a profile in a structured output format.
+func writeProfileStatus(profile *cli.Profile, cfg *cli.WireGuardConfig, format string) {
+	if err := cli.WriteProfileStatus(os.Stdout, cli.NewProfileStatus(profile, cfg), format); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
 // resolveProfileName resolves a profile name from explicit value, positional arg, or active profile.
 // Supports aliases at every level.
 func resolveProfileName(explicit string, positional string) string {
@@ -225,8 +274,39 @@ func cmdProxy(args []string) {
 	valkeyPort := fs.Int("valkey-port", 6379, "Local port for Valkey proxy")
 	target := fs.String("target", "", "Override target address (e.g. [fd00::1]:3306)")
 	localPort := fs.Int("port", 0, "Local port when using -target")
+	keepalive := fs.Duration("keepalive", cli.DefaultKeepalive, "TCP keepalive period for proxied connections (0 disables)")
+	idleTimeout := fs.Duration("idle-timeout", 0, "Close proxied connections idle for this long (0 disables)")
+	mysqlKeepalive := fs.Duration("mysql-keepalive", 0, "TCP keepalive period for MySQL (default: -keepalive)")
+	mysqlIdleTimeout := fs.Duration("mysql-idle-timeout", 0, "Idle timeout for MySQL connections (default: -idle-timeout)")
+	valkeyKeepalive := fs.Duration("valkey-keepalive", 0, "TCP keepalive period for Valkey (default: -keepalive)")
+	valkeyIdleTimeout := fs.Duration("valkey-idle-timeout", 0, "Idle timeout for Valkey connections (default: -idle-timeout)")
+	grace := fs.Duration("grace", cli.DefaultGracePeriod, "On Ctrl+C, wait this long for open connections to close before disconnecting")
 	fs.Parse(args)
 
+	// Per-service settings fall back to the global ones unless given explicitly.
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	serviceTimeouts := func(svcType string) (time.Duration, time.Duration) {
+		ka, idle := *keepalive, *idleTimeout
+		switch svcType {
+		case "mysql":
+			if set["mysql-keepalive"] {
+				ka = *mysqlKeepalive
+			}
+			if set["mysql-idle-timeout"] {
+				idle = *mysqlIdleTimeout
+			}
+		case "valkey":
+			if set["valkey-keepalive"] {
+				ka = *valkeyKeepalive
+			}
+			if set["valkey-idle-timeout"] {
+				idle = *valkeyIdleTimeout
+			}
+		}
+		return ka, idle
+	}
+
 	name := resolveProfileName(*profileName, "")
 
 	_, cfg, err := cli.LoadProfile(name)
@@ -235,6 +315,16 @@ func cmdProxy(args []string) {
 		os.Exit(1)
 	}
 
+	// Keep the tunnel's NAT mapping alive while proxied connections idle,
+	// at the shortest keepalive any service asks for.
+	tunnelKeepalive := *keepalive
+	for _, svcType := range []string{"mysql", "valkey"} {
+		if ka, _ := serviceTimeouts(svcType); ka > 0 && (tunnelKeepalive == 0 || ka < tunnelKeepalive) {
+			tunnelKeepalive = ka
+		}
+	}
+	cfg.EnsureKeepalive(tunnelKeepalive)
+
 	fmt.Printf("Establishing tunnel with profile %q...\n", name)
 	tunnel, err := cli.CreateTunnel(cfg)
 	if err != nil {
@@ -243,6 +333,8 @@ func cmdProxy(args []string) {
 	}
 	defer tunnel.Close()
 
+	var proxies []*cli.Proxy
+
 	// If a manual target is specified, proxy just that.
 	if *target != "" {
 		if *localPort == 0 {
@@ -250,14 +342,14 @@ func cmdProxy(args []string) {
 			os.Exit(1)
 		}
 		svc := cli.ServiceEntry{Type: "custom", Address: *target}
-		pt := cli.ProxyTarget{Service: svc, LocalPort: *localPort}
-		listener, err := cli.StartProxy(tunnel, pt)
+		pt := cli.ProxyTarget{Service: svc, LocalPort: *localPort, Keepalive: *keepalive, IdleTimeout: *idleTimeout}
+		proxy, err := cli.StartProxy(tunnel, pt)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 		fmt.Printf("Proxying localhost:%d → %s\n", *localPort, *target)
-		defer listener.Close()
+		proxies = append(proxies, proxy)
 	} else {
 		// Auto-proxy services from config metadata.
 		if len(cfg.Services) == 0 {
@@ -275,13 +367,14 @@ func cmdProxy(args []string) {
 				port = *valkeyPort
 			}
 
-			pt := cli.ProxyTarget{Service: svc, LocalPort: port}
-			listener, err := cli.StartProxy(tunnel, pt)
+			ka, idle := serviceTimeouts(svc.Type)
+			pt := cli.ProxyTarget{Service: svc, LocalPort: port, Keepalive: ka, IdleTimeout: idle}
+			proxy, err := cli.StartProxy(tunnel, pt)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Warning: failed to proxy %s on port %d: %v\n", svc.Type, port, err)
 				continue
 			}
-			defer listener.Close()
+			proxies = append(proxies, proxy)
 			listeners = append(listeners, fmt.Sprintf("  %s → localhost:%d", svc.Type, port))
 		}
 
@@ -297,12 +390,50 @@ func cmdProxy(args []string) {
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 	<-sig
 
-	fmt.Println("\nDisconnecting...")
+	drainProxies(proxies, *grace, sig)
+	fmt.Println("Disconnecting...")
+}
+
+// drainProxies stops accepting connections and waits up to grace for open
+// ones to close, printing how many remain. A second signal on sig closes them
+// immediately.
+func drainProxies(proxies []*cli.Proxy, grace time.Duration, sig <-chan os.Signal) {
+	fmt.Println()
+	ctx, cancel := context.WithTimeout(context.Background(), grace)
+	defer cancel()
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	first := true
+	closed := cli.DrainProxies(ctx, proxies, time.Second, func(open int) {
+		if first {
+			fmt.Printf("Waiting up to %s for open connections to close (Ctrl+C again to force)...\n", grace)
+			first = false
+		}
+		fmt.Printf("  %d connection(s) still open\n", open)
+	})
+	if closed > 0 {
+		fmt.Printf("Closed %d connection(s) that were still open.\n", closed)
+	}
 }
 
-func cmdStatus() {
+func cmdStatus(args []string) {
+	fs := flag.NewFlagSet("status", flag.ExitOnError)
+	output := fs.String("o", "", "Output format: json or yaml (default: human-readable)")
+	fs.Parse(args)
+	format := parseOutputFlag(*output)
+
 	active, _ := cli.GetActive()
 	if active == "" {
+		if format != cli.OutputText {
+			fmt.Fprintln(os.Stderr, "No active profile.")
+			os.Exit(1)
+		}
 		fmt.Println("No active profile.")
 		return
 	}
@@ -313,6 +444,11 @@ func cmdStatus() {
 		os.Exit(1)
 	}
 
+	if format != cli.OutputText {
+		writeProfileStatus(profile, cfg, format)
+		return
+	}
+
 	fmt.Printf("Profile:    %s\n", profile.Name)
 	if profile.Alias != "" {
 		fmt.Printf("Alias:      %s\n", profile.Alias)
@@ -333,13 +469,13 @@ func printUsage() {
 
 Usage:
   hosting-cli import [-tenant ID] <config-file>
-  hosting-cli profiles [delete <name>]
+  hosting-cli profiles [delete [-y] <name>]
   hosting-cli use <tenant-id>
-  hosting-cli active
+  hosting-cli active [-o json|yaml]
   hosting-cli tunnel [tenant-id]
-  hosting-cli proxy [-mysql-port 3306] [-valkey-port 6379]
+  hosting-cli proxy [-mysql-port 3306] [-valkey-port 6379] [-keepalive 30s] [-idle-timeout 0] [-grace 30s]
   hosting-cli proxy -target [addr]:port -port <local-port>
-  hosting-cli status
+  hosting-cli status [-o json|yaml]
 
 Commands:
   import     Import a WireGuard config file (profile named after tenant ID)
@@ -13,6 +13,7 @@ import (
 
 	"github.com/edvin/hosting/internal/activity"
 	"github.com/edvin/hosting/internal/agent"
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
 	hostingworkflow "github.com/edvin/hosting/internal/workflow"
 	"github.com/edvin/hosting/internal/config"
 	"github.com/edvin/hosting/internal/logging"
@@ -33,6 +34,16 @@ func main() {
 
 	logger := logging.NewLogger(cfg)
 
+	auditLogPath := getEnv("COMMAND_AUDIT_LOG", "/var/log/node-agent/commands.log")
+	auditor, err := cmdaudit.Open(auditLogPath)
+	if err != nil {
+		logger.Warn().Err(err).Msg("command audit log unavailable, commands are only counted")
+	} else {
+		cmdaudit.SetDefault(auditor)
+		defer auditor.Close()
+		logger.Info().Str("path", auditLogPath).Msg("command audit log enabled")
+	}
+
 	agentCfg := agent.Config{
 		MySQLDSN:          cfg.MySQLDSN,
 		MySQLReplPassword: getEnv("MYSQL_REPL_PASSWORD", ""),
@@ -40,6 +51,7 @@ func main() {
 		NginxListenPort: getEnv("NGINX_LISTEN_PORT", "80"),
 		WebStorageDir:   getEnv("WEB_STORAGE_DIR", "/var/www/storage"),
 		CertDir:         getEnv("CERT_DIR", "/etc/ssl/hosting"),
+		GeoIPDatabasePath: getEnv("GEOIP_DATABASE_PATH", "/var/lib/GeoIP/GeoLite2-Country.mmdb"),
 		ValkeyConfigDir: getEnv("VALKEY_CONFIG_DIR", "/etc/valkey"),
 		ValkeyDataDir:   getEnv("VALKEY_DATA_DIR", "/var/lib/valkey"),
 		InitSystem:      getEnv("INIT_SYSTEM", "direct"),
@@ -52,7 +64,7 @@ func main() {
 	if err != nil {
 		logger.Fatal().Err(err).Msg("failed to configure temporal TLS")
 	}
-	dialOpts := temporalclient.Options{HostPort: cfg.TemporalAddress}
+	dialOpts := temporalclient.Options{HostPort: cfg.TemporalAddress, Logger: logging.TemporalLogger(logger)}
 	if tlsConfig != nil {
 		dialOpts.ConnectionOptions = temporalclient.ConnectionOptions{TLS: tlsConfig}
 		logger.Info().Msg("temporal mTLS enabled")
@@ -64,8 +76,24 @@ func main() {
 	defer tc.Close()
 
 	taskQueue := "node-" + cfg.NodeID
+	// Heavy node operations are capped by default; an explicit
+	// WORKER_ACTIVITY_CONCURRENCY replaces the defaults entirely.
+	if cfg.WorkerActivityConcurrency == "" {
+		cfg.WorkerActivityConcurrency = config.DefaultNodeActivityConcurrency
+	}
+	activityLimits, err := cfg.ActivityConcurrencyLimits()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid WORKER_ACTIVITY_CONCURRENCY")
+	}
+
 	w := worker.New(tc, taskQueue, worker.Options{
-		Interceptors: []interceptor.WorkerInterceptor{&hostingworkflow.ErrorTypingInterceptor{}},
+		MaxConcurrentActivityExecutionSize:     cfg.WorkerMaxConcurrentActivities,
+		MaxConcurrentWorkflowTaskExecutionSize: cfg.WorkerMaxConcurrentWorkflowTasks,
+		WorkerActivitiesPerSecond:              cfg.WorkerActivitiesPerSecond,
+		Interceptors: []interceptor.WorkerInterceptor{
+			&hostingworkflow.ErrorTypingInterceptor{},
+			hostingworkflow.NewActivityConcurrencyInterceptor(activityLimits),
+		},
 	})
 
 	s3Mgr := agent.NewS3Manager(
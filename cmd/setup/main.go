@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/fs"
@@ -75,13 +76,20 @@ func runGenerate(args []string) {
 	flags := flag.NewFlagSet("generate", flag.ExitOnError)
 	manifestPath := flags.String("f", "setup.yaml", "Path to setup manifest file")
 	outputDir := flags.String("output", ".", "Output directory for generated files")
+	validateOnly := flags.Bool("validate", false, "Only validate the manifest; do not generate files")
+	jsonOutput := flags.Bool("json", false, "With -validate, print the result as JSON")
 	flags.Usage = func() {
-		fmt.Fprintf(os.Stderr, "Usage: setup generate [-f setup.yaml] [-output .]\n\n")
+		fmt.Fprintf(os.Stderr, "Usage: setup generate [-f setup.yaml] [-output .] [-validate [-json]]\n\n")
 		fmt.Fprintf(os.Stderr, "Generate deployment files from a setup manifest.\n\n")
 		flags.PrintDefaults()
 	}
 	flags.Parse(args)
 
+	if *validateOnly {
+		runValidate(*manifestPath, *jsonOutput)
+		return
+	}
+
 	fmt.Printf("Generating deployment files from %s...\n", *manifestPath)
 	if err := setup.GenerateFromManifest(*manifestPath, *outputDir); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -90,6 +98,29 @@ func runGenerate(args []string) {
 	fmt.Println("Done.")
 }
 
+// runValidate checks a manifest and exits with status 1 if it is invalid, so
+// it can gate CI pipelines.
+func runValidate(manifestPath string, jsonOutput bool) {
+	result, err := setup.ValidateManifest(manifestPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(result)
+	} else if result.Valid {
+		fmt.Printf("%s is valid.\n", manifestPath)
+	} else {
+		setup.PrintValidationErrors(os.Stderr, manifestPath, result)
+	}
+	if !result.Valid {
+		os.Exit(1)
+	}
+}
+
 func openBrowser(url string) {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
@@ -8,7 +8,9 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 	temporalclient "go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
@@ -21,6 +23,7 @@ import (
 	"github.com/edvin/hosting/internal/llm"
 	"github.com/edvin/hosting/internal/logging"
 	"github.com/edvin/hosting/internal/metrics"
+	"github.com/edvin/hosting/internal/objectstore"
 	"github.com/edvin/hosting/internal/workflow"
 )
 
@@ -43,7 +46,7 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	corePool, err := db.NewCorePool(ctx, cfg.CoreDatabaseURL)
+	corePool, err := db.NewCorePool(ctx, cfg.CoreDatabaseURL, db.CorePoolOptions(cfg, logger))
 	if err != nil {
 		logger.Fatal().Err(err).Msg("failed to connect to core database")
 	}
@@ -61,7 +64,7 @@ func main() {
 	if err != nil {
 		logger.Fatal().Err(err).Msg("failed to configure temporal TLS")
 	}
-	dialOpts := temporalclient.Options{HostPort: cfg.TemporalAddress}
+	dialOpts := temporalclient.Options{HostPort: cfg.TemporalAddress, Logger: logging.TemporalLogger(logger)}
 	if tlsConfig != nil {
 		dialOpts.ConnectionOptions = temporalclient.ConnectionOptions{TLS: tlsConfig}
 		logger.Info().Msg("temporal mTLS enabled")
@@ -72,8 +75,23 @@ func main() {
 	}
 	defer tc.Close()
 
+	workflow.SetDNS01PropagationDelay(time.Duration(cfg.DNSPropagationWaitSecs) * time.Second)
+	retryPolicies := workflow.NewRetryPolicies(workflow.RetryConfig{ACMEMaxAttempts: cfg.ACMEMaxAttempts})
+
+	activityLimits, err := cfg.ActivityConcurrencyLimits()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("invalid WORKER_ACTIVITY_CONCURRENCY")
+	}
+
 	w := worker.New(tc, taskQueue, worker.Options{
-		Interceptors: []interceptor.WorkerInterceptor{&workflow.ErrorTypingInterceptor{}},
+		MaxConcurrentActivityExecutionSize:     cfg.WorkerMaxConcurrentActivities,
+		MaxConcurrentWorkflowTaskExecutionSize: cfg.WorkerMaxConcurrentWorkflowTasks,
+		WorkerActivitiesPerSecond:              cfg.WorkerActivitiesPerSecond,
+		Interceptors: []interceptor.WorkerInterceptor{
+			&workflow.ErrorTypingInterceptor{},
+			&workflow.RetryPolicyInterceptor{Policies: retryPolicies},
+			workflow.NewActivityConcurrencyInterceptor(activityLimits),
+		},
 	})
 
 	// Register activities
@@ -89,7 +107,7 @@ func main() {
 	certActivities := activity.NewCertificateActivity(corePool)
 	w.RegisterActivity(certActivities)
 
-	acmeActivities := activity.NewACMEActivity(cfg.ACMEEmail, cfg.ACMEDirectoryURL)
+	acmeActivities := activity.NewACMEActivity(cfg.ACMEEmail, cfg.ACMEDirectoryURL, coreDBActivities)
 	w.RegisterActivity(acmeActivities)
 
 	migrateActivities := activity.NewMigrate(corePool)
@@ -104,6 +122,9 @@ func main() {
 	webhookActivities := activity.NewWebhook()
 	w.RegisterActivity(webhookActivities)
 
+	exportBucket := objectstore.New(cfg.ExportS3Endpoint, cfg.ExportS3Region, cfg.ExportS3Bucket, cfg.ExportS3AccessKey, cfg.ExportS3SecretKey)
+	w.RegisterActivity(activity.NewExportStorage(exportBucket))
+
 	// Register agent activities (conditionally).
 	if cfg.AgentEnabled {
 		llmClient := llm.NewClient(cfg.LLMBaseURL, cfg.LLMAPIKey, cfg.LLMModel)
@@ -122,11 +143,18 @@ func main() {
 	w.RegisterWorkflow(workflow.DeleteTenantWorkflow)
 	w.RegisterWorkflow(workflow.DeleteSubscriptionWorkflow)
 	w.RegisterWorkflow(workflow.CreateWebrootWorkflow)
+	w.RegisterWorkflow(workflow.CreateWebrootFromTemplateWorkflow)
+	w.RegisterWorkflow(workflow.CloneWebrootWorkflow)
 	w.RegisterWorkflow(workflow.UpdateWebrootWorkflow)
 	w.RegisterWorkflow(workflow.DeleteWebrootWorkflow)
+	w.RegisterWorkflow(workflow.CreateWebrootReleaseWorkflow)
+	w.RegisterWorkflow(workflow.PromoteWebrootReleaseWorkflow)
+	w.RegisterWorkflow(workflow.WebrootDeployLockWorkflow)
 	w.RegisterWorkflow(workflow.BindFQDNWorkflow)
 	w.RegisterWorkflow(workflow.UnbindFQDNWorkflow)
+	w.RegisterWorkflow(workflow.SyncFQDNDNSWorkflow)
 	w.RegisterWorkflow(workflow.ProvisionLECertWorkflow)
+	w.RegisterWorkflow(workflow.BulkProvisionCertsWorkflow)
 	w.RegisterWorkflow(workflow.UploadCustomCertWorkflow)
 	w.RegisterWorkflow(workflow.RenewLECertWorkflow)
 	w.RegisterWorkflow(workflow.CleanupExpiredCertsWorkflow)
@@ -135,6 +163,13 @@ func main() {
 	w.RegisterWorkflow(workflow.CreateZoneRecordWorkflow)
 	w.RegisterWorkflow(workflow.UpdateZoneRecordWorkflow)
 	w.RegisterWorkflow(workflow.DeleteZoneRecordWorkflow)
+	w.RegisterWorkflow(workflow.ApplyZoneRecordsWorkflow)
+	w.RegisterWorkflow(workflow.GetZoneSOASerialWorkflow)
+	w.RegisterWorkflow(workflow.SignZoneWorkflow)
+	w.RegisterWorkflow(workflow.UnsignZoneWorkflow)
+	w.RegisterWorkflow(workflow.RolloverZoneZSKWorkflow)
+	w.RegisterWorkflow(workflow.ScheduleZSKRolloversWorkflow)
+	w.RegisterWorkflow(workflow.ConfigureZoneReplicationWorkflow)
 	w.RegisterWorkflow(workflow.CreateDatabaseWorkflow)
 	w.RegisterWorkflow(workflow.DeleteDatabaseWorkflow)
 	w.RegisterWorkflow(workflow.CreateDatabaseUserWorkflow)
@@ -142,6 +177,8 @@ func main() {
 	w.RegisterWorkflow(workflow.DeleteDatabaseUserWorkflow)
 	w.RegisterWorkflow(workflow.UpdateServiceHostnamesWorkflow)
 	w.RegisterWorkflow(workflow.MigrateTenantWorkflow)
+	w.RegisterWorkflow(workflow.ReassignTenantBrandWorkflow)
+	w.RegisterWorkflow(workflow.UpdateTenantLBSplitWorkflow)
 	w.RegisterWorkflow(workflow.MigrateDatabaseWorkflow)
 	w.RegisterWorkflow(workflow.MigrateValkeyInstanceWorkflow)
 	w.RegisterWorkflow(workflow.CreateEmailAccountWorkflow)
@@ -152,6 +189,10 @@ func main() {
 	w.RegisterWorkflow(workflow.DeleteEmailForwardWorkflow)
 	w.RegisterWorkflow(workflow.UpdateEmailAutoReplyWorkflow)
 	w.RegisterWorkflow(workflow.DeleteEmailAutoReplyWorkflow)
+	w.RegisterWorkflow(workflow.ProvisionEmailDKIMWorkflow)
+	w.RegisterWorkflow(workflow.CreateSMTPRelayUserWorkflow)
+	w.RegisterWorkflow(workflow.UpdateSMTPRelayUserWorkflow)
+	w.RegisterWorkflow(workflow.DeleteSMTPRelayUserWorkflow)
 	w.RegisterWorkflow(workflow.CreateValkeyInstanceWorkflow)
 	w.RegisterWorkflow(workflow.DeleteValkeyInstanceWorkflow)
 	w.RegisterWorkflow(workflow.CreateValkeyUserWorkflow)
@@ -164,23 +205,35 @@ func main() {
 	w.RegisterWorkflow(workflow.DeleteS3BucketWorkflow)
 	w.RegisterWorkflow(workflow.CreateS3AccessKeyWorkflow)
 	w.RegisterWorkflow(workflow.DeleteS3AccessKeyWorkflow)
+	w.RegisterWorkflow(workflow.RotateS3AccessKeyWorkflow)
 	w.RegisterWorkflow(workflow.CreateCronJobWorkflow)
 	w.RegisterWorkflow(workflow.UpdateCronJobWorkflow)
 	w.RegisterWorkflow(workflow.DeleteCronJobWorkflow)
 	w.RegisterWorkflow(workflow.EnableCronJobWorkflow)
 	w.RegisterWorkflow(workflow.DisableCronJobWorkflow)
+	w.RegisterWorkflow(workflow.PauseCronJobWorkflow)
 	w.RegisterWorkflow(workflow.CreateDaemonWorkflow)
 	w.RegisterWorkflow(workflow.UpdateDaemonWorkflow)
 	w.RegisterWorkflow(workflow.DeleteDaemonWorkflow)
 	w.RegisterWorkflow(workflow.EnableDaemonWorkflow)
 	w.RegisterWorkflow(workflow.DisableDaemonWorkflow)
 	w.RegisterWorkflow(workflow.ConvergeShardWorkflow)
+	w.RegisterWorkflow(workflow.ConvergeClusterWorkflow)
 	w.RegisterWorkflow(workflow.CreateBackupWorkflow)
 	w.RegisterWorkflow(workflow.RestoreBackupWorkflow)
+	w.RegisterWorkflow(workflow.ListBackupFilesWorkflow)
+	w.RegisterWorkflow(workflow.RestoreBackupFilesWorkflow)
 	w.RegisterWorkflow(workflow.DeleteBackupWorkflow)
+	w.RegisterWorkflow(workflow.VerifyBackupWorkflow)
+	w.RegisterWorkflow(workflow.StageBackupDownloadWorkflow)
+	w.RegisterWorkflow(workflow.VerifyRecentBackupsWorkflow)
 	w.RegisterWorkflow(workflow.CleanupAuditLogsWorkflow)
 	w.RegisterWorkflow(workflow.CleanupOldBackupsWorkflow)
+	w.RegisterWorkflow(workflow.RunScheduledBackupsWorkflow)
+	w.RegisterWorkflow(workflow.ExportTenantWorkflow)
+	w.RegisterWorkflow(workflow.CleanupTenantExportsWorkflow)
 	w.RegisterWorkflow(workflow.CheckReplicationHealthWorkflow)
+	w.RegisterWorkflow(workflow.PromoteReplicaWorkflow)
 	w.RegisterWorkflow(workflow.SyncEgressRulesWorkflow)
 	w.RegisterWorkflow(workflow.ProcessIncidentQueueWorkflow)
 	w.RegisterWorkflow(workflow.InvestigateIncidentWorkflow)
@@ -191,9 +244,19 @@ func main() {
 	w.RegisterWorkflow(workflow.CheckCertExpiryWorkflow)
 	w.RegisterWorkflow(workflow.CheckCephFSHealthWorkflow)
 	w.RegisterWorkflow(workflow.CollectResourceUsageWorkflow)
+	w.RegisterWorkflow(workflow.CollectValkeyStatsWorkflow)
+	w.RegisterWorkflow(workflow.CollectNodeStatsWorkflow)
+	w.RegisterWorkflow(workflow.CollectNodeCapabilitiesWorkflow)
 	w.RegisterWorkflow(workflow.CreateWireGuardPeerWorkflow)
 	w.RegisterWorkflow(workflow.DeleteWireGuardPeerWorkflow)
 	w.RegisterWorkflow(workflow.CreateTempMySQLAccessWorkflow)
+	w.RegisterWorkflow(workflow.RevokeLoginSessionWorkflow)
+	w.RegisterWorkflow(workflow.ListDatabaseConnectionsWorkflow)
+	w.RegisterWorkflow(workflow.KillDatabaseConnectionWorkflow)
+	w.RegisterWorkflow(workflow.NodeDiagnosticsWorkflow)
+	w.RegisterWorkflow(workflow.FixTenantPermissionsWorkflow)
+	w.RegisterWorkflow(workflow.WebrootNginxPreviewWorkflow)
+	w.RegisterWorkflow(workflow.WebrootAccessLogWorkflow)
 
 	if cfg.MetricsAddr != "" {
 		metricsSrv := metrics.NewServer(cfg.MetricsAddr)
@@ -214,7 +277,37 @@ func main() {
 
 	// Register cron schedules. Errors for already-existing schedules are
 	// ignored so that re-deploys do not fail.
-	registerCronSchedules(ctx, tc, taskQueue, cfg, logger)
+	scheduleIDs := registerCronSchedules(ctx, tc, taskQueue, cfg, logger)
+
+	if cfg.MetricsAddr != "" {
+		scheduleCollector := metrics.NewScheduleCollector(tc, scheduleIDs, logger)
+		prometheus.MustRegister(scheduleCollector)
+		go scheduleCollector.Run(ctx, time.Duration(cfg.ScheduleMetricsIntervalSecs)*time.Second)
+	}
+
+	// SIGHUP reloads the hot-reloadable config fields. Retention changes are
+	// pushed into the existing cron schedules' workflow arguments.
+	cfgStore := config.NewStore(cfg)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			changed, err := cfgStore.Reload()
+			if err != nil {
+				logger.Error().Err(err).Msg("config reload failed")
+				continue
+			}
+			next := cfgStore.Get()
+			if err := logging.SetLevel(next.LogLevel); err != nil {
+				logger.Error().Err(err).Msg("apply log level failed")
+			}
+			if err := logging.SetComponentLevels(next.LogLevels); err != nil {
+				logger.Error().Err(err).Msg("apply component log levels failed")
+			}
+			updateRetentionSchedules(ctx, tc, next, logger)
+			logger.Info().Strs("changed", changed).Msg("config reloaded")
+		}
+	}()
 
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -231,11 +324,11 @@ type cronSchedule struct {
 	args     []interface{}
 }
 
-func registerCronSchedules(ctx context.Context, tc temporalclient.Client, taskQueue string, cfg *config.Config, logger zerolog.Logger) {
+func registerCronSchedules(ctx context.Context, tc temporalclient.Client, taskQueue string, cfg *config.Config, logger zerolog.Logger) []string {
 	schedules := []cronSchedule{
 		{
 			id:       "cert-renewal-cron",
-			cron:     "0 2 * * *",
+			cron:     "0 * * * *",
 			workflow: workflow.RenewLECertWorkflow,
 		},
 		{
@@ -255,6 +348,16 @@ func registerCronSchedules(ctx context.Context, tc temporalclient.Client, taskQu
 			workflow: workflow.CleanupOldBackupsWorkflow,
 			args:     []interface{}{cfg.BackupRetentionDays},
 		},
+		{
+			id:       "scheduled-backups-cron",
+			cron:     "0 * * * *",
+			workflow: workflow.RunScheduledBackupsWorkflow,
+		},
+		{
+			id:       "tenant-export-cleanup-cron",
+			cron:     "30 5 * * *",
+			workflow: workflow.CleanupTenantExportsWorkflow,
+		},
 		{
 			id:       "replication-health-cron",
 			cron:     "* * * * *",
@@ -295,6 +398,39 @@ func registerCronSchedules(ctx context.Context, tc temporalclient.Client, taskQu
 			cron:     "*/30 * * * *",
 			workflow: workflow.CollectResourceUsageWorkflow,
 		},
+		{
+			id:       "valkey-stats-collection-cron",
+			cron:     "*/5 * * * *",
+			workflow: workflow.CollectValkeyStatsWorkflow,
+		},
+		{
+			id:       "node-stats-collection-cron",
+			cron:     "*/5 * * * *",
+			workflow: workflow.CollectNodeStatsWorkflow,
+		},
+		{
+			id:       "node-capabilities-collection-cron",
+			cron:     "*/5 * * * *",
+			workflow: workflow.CollectNodeCapabilitiesWorkflow,
+		},
+		{
+			id:       "dnssec-zsk-rollover-cron",
+			cron:     "0 7 * * *",
+			workflow: workflow.ScheduleZSKRolloversWorkflow,
+			args:     []interface{}{cfg.DNSSECZSKRolloverDays},
+		},
+	}
+
+	if cfg.BackupVerifySampleSize > 0 {
+		schedules = append(schedules, cronSchedule{
+			id:       "backup-verification-cron",
+			cron:     "0 6 * * *",
+			workflow: workflow.VerifyRecentBackupsWorkflow,
+			args: []interface{}{workflow.VerifyBackupsParams{
+				SampleSize: cfg.BackupVerifySampleSize,
+				MaxAgeDays: cfg.BackupVerifyMaxAgeDays,
+			}},
+		})
 	}
 
 	if cfg.AgentEnabled {
@@ -311,6 +447,7 @@ func registerCronSchedules(ctx context.Context, tc temporalclient.Client, taskQu
 
 	scheduleClient := tc.ScheduleClient()
 
+	ids := make([]string, 0, len(schedules))
 	for _, s := range schedules {
 		_, err := scheduleClient.Create(ctx, temporalclient.ScheduleOptions{
 			ID: s.id,
@@ -326,12 +463,58 @@ func registerCronSchedules(ctx context.Context, tc temporalclient.Client, taskQu
 		})
 		if err != nil {
 			if strings.Contains(err.Error(), "already exists") || strings.Contains(err.Error(), "AlreadyExists") || strings.Contains(err.Error(), "already registered") {
-				logger.Info().Str("id", s.id).Msg("cron schedule already exists, skipping")
+				updateScheduleCron(ctx, scheduleClient, s, logger)
 			} else {
 				logger.Fatal().Err(err).Str("id", s.id).Msg("failed to create cron schedule")
 			}
 		} else {
 			logger.Info().Str("id", s.id).Str("cron", s.cron).Msg("created cron schedule")
 		}
+		ids = append(ids, s.id)
+	}
+
+	return ids
+}
+
+// updateScheduleCron rewrites the cron expression of an existing schedule so
+// a changed schedule in registerCronSchedules applies to running clusters.
+func updateScheduleCron(ctx context.Context, scheduleClient temporalclient.ScheduleClient, s cronSchedule, logger zerolog.Logger) {
+	err := scheduleClient.GetHandle(ctx, s.id).Update(ctx, temporalclient.ScheduleUpdateOptions{
+		DoUpdate: func(in temporalclient.ScheduleUpdateInput) (*temporalclient.ScheduleUpdate, error) {
+			in.Description.Schedule.Spec = &temporalclient.ScheduleSpec{CronExpressions: []string{s.cron}}
+			return &temporalclient.ScheduleUpdate{Schedule: &in.Description.Schedule}, nil
+		},
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("id", s.id).Msg("failed to update cron schedule")
+		return
+	}
+	logger.Info().Str("id", s.id).Str("cron", s.cron).Msg("cron schedule already exists, updated spec")
+}
+
+// updateRetentionSchedules rewrites the arguments of the retention cron
+// schedules so reloaded retention settings apply from the next run.
+func updateRetentionSchedules(ctx context.Context, tc temporalclient.Client, cfg *config.Config, logger zerolog.Logger) {
+	retention := map[string]int{
+		"audit-log-retention-cron": cfg.AuditLogRetentionDays,
+		"backup-retention-cron":    cfg.BackupRetentionDays,
+	}
+
+	for id, days := range retention {
+		days := days
+		handle := tc.ScheduleClient().GetHandle(ctx, id)
+		err := handle.Update(ctx, temporalclient.ScheduleUpdateOptions{
+			DoUpdate: func(in temporalclient.ScheduleUpdateInput) (*temporalclient.ScheduleUpdate, error) {
+				action, ok := in.Description.Schedule.Action.(*temporalclient.ScheduleWorkflowAction)
+				if !ok {
+					return nil, fmt.Errorf("unexpected action type %T", in.Description.Schedule.Action)
+				}
+				action.Args = []interface{}{days}
+				return &temporalclient.ScheduleUpdate{Schedule: &in.Description.Schedule}, nil
+			},
+		})
+		if err != nil {
+			logger.Error().Err(err).Str("id", id).Msg("failed to update retention schedule")
+		}
 	}
 }
@@ -10,30 +10,65 @@ import (
 	"fmt"
 	"time"
 
+	"go.temporal.io/sdk/temporal"
 	"golang.org/x/crypto/acme"
 )
 
+// ErrTypeACMERateLimited is the application error type of ACME requests the
+// CA rejected for rate limits. These are not retried by the activity, as
+// retrying within seconds only extends the limit; the error details hold
+// the CA's Retry-After as a time.Duration (zero if it sent none).
+const ErrTypeACMERateLimited = "ACME_RATE_LIMITED"
+
+// acmeError wraps an error of an ACME request, turning rate-limit rejections
+// into non-retryable ErrTypeACMERateLimited errors.
+func acmeError(msg string, err error) error {
+	if retryAfter, ok := acme.RateLimit(err); ok {
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("%s: %v", msg, err), ErrTypeACMERateLimited, err, retryAfter)
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
 // ACMEActivity handles ACME certificate provisioning.
 type ACMEActivity struct {
 	email        string
 	directoryURL string
+	brands       BrandACMEConfigs
+}
+
+// NewACMEActivity creates a new ACMEActivity. email and directoryURL are the
+// platform default CA; brands, if non-nil, resolves per-brand overrides.
+func NewACMEActivity(email, directoryURL string, brands BrandACMEConfigs) *ACMEActivity {
+	return &ACMEActivity{email: email, directoryURL: directoryURL, brands: brands}
+}
+
+// ACMEAccountConfig is a brand's ACME CA with decrypted External Account
+// Binding credentials.
+type ACMEAccountConfig struct {
+	DirectoryURL string
+	Email        string // empty uses the platform contact email
+	EABKeyID     string
+	EABHMACKey   []byte
 }
 
-// NewACMEActivity creates a new ACMEActivity.
-func NewACMEActivity(email, directoryURL string) *ACMEActivity {
-	return &ACMEActivity{email: email, directoryURL: directoryURL}
+// BrandACMEConfigs resolves the ACME CA of a brand. It returns nil if the
+// brand uses the platform default.
+type BrandACMEConfigs interface {
+	GetBrandACMEAccount(ctx context.Context, brandID string) (*ACMEAccountConfig, error)
 }
 
 // ACMEOrderParams holds parameters for ordering a certificate.
 type ACMEOrderParams struct {
-	FQDN string
+	FQDN    string
+	BrandID string // selects the brand's CA; empty uses the platform default
 }
 
 // ACMEOrderResult contains the order URL and authorizations.
 type ACMEOrderResult struct {
-	OrderURL   string
-	AuthzURLs  []string
-	AccountKey []byte // PEM-encoded ECDSA private key
+	OrderURL     string
+	AuthzURLs    []string
+	AccountKey   []byte // PEM-encoded ECDSA private key
+	DirectoryURL string // CA the order was placed with; pass to the later steps
 }
 
 // CreateOrder creates an ACME account (if needed) and submits a new order.
@@ -44,22 +79,43 @@ func (a *ACMEActivity) CreateOrder(ctx context.Context, params ACMEOrderParams)
 		return nil, fmt.Errorf("generate account key: %w", err)
 	}
 
+	// Resolve the brand's CA, falling back to the platform default. The EAB
+	// key is looked up here rather than passed in so it never appears in
+	// workflow history.
+	directoryURL, email := a.directoryURL, a.email
+	var eab *acme.ExternalAccountBinding
+	if a.brands != nil && params.BrandID != "" {
+		cfg, err := a.brands.GetBrandACMEAccount(ctx, params.BrandID)
+		if err != nil {
+			return nil, fmt.Errorf("get ACME config for brand %s: %w", params.BrandID, err)
+		}
+		if cfg != nil {
+			directoryURL = cfg.DirectoryURL
+			if cfg.Email != "" {
+				email = cfg.Email
+			}
+			if cfg.EABKeyID != "" {
+				eab = &acme.ExternalAccountBinding{KID: cfg.EABKeyID, Key: cfg.EABHMACKey}
+			}
+		}
+	}
+
 	client := &acme.Client{
 		Key:          accountKey,
-		DirectoryURL: a.directoryURL,
+		DirectoryURL: directoryURL,
 	}
 
 	// Register account (or retrieve existing).
-	acct := &acme.Account{Contact: []string{"mailto:" + a.email}}
+	acct := &acme.Account{Contact: []string{"mailto:" + email}, ExternalAccountBinding: eab}
 	_, err = client.Register(ctx, acct, acme.AcceptTOS)
 	if err != nil && err != acme.ErrAccountAlreadyExists {
-		return nil, fmt.Errorf("register ACME account: %w", err)
+		return nil, acmeError("register ACME account", err)
 	}
 
 	// Create order.
 	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(params.FQDN))
 	if err != nil {
-		return nil, fmt.Errorf("authorize order: %w", err)
+		return nil, acmeError("authorize order", err)
 	}
 
 	// Serialize account key.
@@ -70,16 +126,18 @@ func (a *ACMEActivity) CreateOrder(ctx context.Context, params ACMEOrderParams)
 	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
 
 	return &ACMEOrderResult{
-		OrderURL:   order.URI,
-		AuthzURLs:  order.AuthzURLs,
-		AccountKey: keyPEM,
+		OrderURL:     order.URI,
+		AuthzURLs:    order.AuthzURLs,
+		AccountKey:   keyPEM,
+		DirectoryURL: directoryURL,
 	}, nil
 }
 
 // ACMEChallengeParams holds parameters for getting the HTTP-01 challenge details.
 type ACMEChallengeParams struct {
-	AuthzURL   string
-	AccountKey []byte // PEM-encoded
+	AuthzURL     string
+	AccountKey   []byte // PEM-encoded
+	DirectoryURL string // from ACMEOrderResult; empty uses the platform default
 }
 
 // ACMEChallengeResult holds the challenge token and response.
@@ -96,7 +154,7 @@ func (a *ACMEActivity) GetHTTP01Challenge(ctx context.Context, params ACMEChalle
 		return nil, err
 	}
 
-	client := &acme.Client{Key: accountKey, DirectoryURL: a.directoryURL}
+	client := a.client(accountKey, params.DirectoryURL)
 
 	authz, err := client.GetAuthorization(ctx, params.AuthzURL)
 	if err != nil {
@@ -128,6 +186,52 @@ func (a *ACMEActivity) GetHTTP01Challenge(ctx context.Context, params ACMEChalle
 	}, nil
 }
 
+// ACMEDNS01ChallengeResult holds the DNS-01 challenge and the TXT record
+// that must be published to satisfy it.
+type ACMEDNS01ChallengeResult struct {
+	ChallengeURL string
+	Domain       string // identifier being validated, without the wildcard label
+	RecordValue  string // TXT value for _acme-challenge.{Domain}
+}
+
+// GetDNS01Challenge retrieves the DNS-01 challenge for an authorization.
+// Wildcard identifiers can only be validated with DNS-01.
+func (a *ACMEActivity) GetDNS01Challenge(ctx context.Context, params ACMEChallengeParams) (*ACMEDNS01ChallengeResult, error) {
+	accountKey, err := parseECKey(params.AccountKey)
+	if err != nil {
+		return nil, err
+	}
+
+	client := a.client(accountKey, params.DirectoryURL)
+
+	authz, err := client.GetAuthorization(ctx, params.AuthzURL)
+	if err != nil {
+		return nil, fmt.Errorf("get authorization: %w", err)
+	}
+
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "dns-01" {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return nil, fmt.Errorf("no dns-01 challenge found")
+	}
+
+	value, err := client.DNS01ChallengeRecord(challenge.Token)
+	if err != nil {
+		return nil, fmt.Errorf("compute dns-01 record: %w", err)
+	}
+
+	return &ACMEDNS01ChallengeResult{
+		ChallengeURL: challenge.URI,
+		Domain:       authz.Identifier.Value,
+		RecordValue:  value,
+	}, nil
+}
+
 // PlaceHTTP01ChallengeParams is used to write the challenge file to a node.
 type PlaceHTTP01ChallengeParams struct {
 	WebrootPath string // e.g. /var/www/storage/{tenant}/{webroot}/{public_folder}
@@ -139,6 +243,7 @@ type PlaceHTTP01ChallengeParams struct {
 type ACMEAcceptParams struct {
 	ChallengeURL string
 	AccountKey   []byte
+	DirectoryURL string
 }
 
 // AcceptChallenge tells the ACME server we're ready for validation.
@@ -148,7 +253,7 @@ func (a *ACMEActivity) AcceptChallenge(ctx context.Context, params ACMEAcceptPar
 		return err
 	}
 
-	client := &acme.Client{Key: accountKey, DirectoryURL: a.directoryURL}
+	client := a.client(accountKey, params.DirectoryURL)
 
 	_, err = client.Accept(ctx, &acme.Challenge{URI: params.ChallengeURL})
 	if err != nil {
@@ -160,9 +265,10 @@ func (a *ACMEActivity) AcceptChallenge(ctx context.Context, params ACMEAcceptPar
 
 // ACMEFinalizeParams holds params for finalizing the order.
 type ACMEFinalizeParams struct {
-	OrderURL   string
-	FQDN       string
-	AccountKey []byte
+	OrderURL     string
+	FQDN         string
+	AccountKey   []byte
+	DirectoryURL string
 }
 
 // ACMEFinalizeResult holds the issued certificate PEM data.
@@ -181,7 +287,7 @@ func (a *ACMEActivity) FinalizeOrder(ctx context.Context, params ACMEFinalizePar
 		return nil, err
 	}
 
-	client := &acme.Client{Key: accountKey, DirectoryURL: a.directoryURL}
+	client := a.client(accountKey, params.DirectoryURL)
 
 	// Wait for order to be ready.
 	order, err := client.WaitOrder(ctx, params.OrderURL)
@@ -206,7 +312,7 @@ func (a *ACMEActivity) FinalizeOrder(ctx context.Context, params ACMEFinalizePar
 	// Finalize order.
 	certDER, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
 	if err != nil {
-		return nil, fmt.Errorf("create order cert: %w", err)
+		return nil, acmeError("create order cert", err)
 	}
 
 	// Encode cert PEM.
@@ -248,6 +354,14 @@ type CleanupHTTP01ChallengeParams struct {
 	Token       string
 }
 
+// client returns an ACME client for the CA an order was placed with.
+func (a *ACMEActivity) client(accountKey *ecdsa.PrivateKey, directoryURL string) *acme.Client {
+	if directoryURL == "" {
+		directoryURL = a.directoryURL
+	}
+	return &acme.Client{Key: accountKey, DirectoryURL: directoryURL}
+}
+
 func parseECKey(keyPEM []byte) (*ecdsa.PrivateKey, error) {
 	block, _ := pem.Decode(keyPEM)
 	if block == nil {
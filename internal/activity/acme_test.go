@@ -6,10 +6,15 @@ import (
 	"crypto/rand"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/temporal"
+	"golang.org/x/crypto/acme"
 )
 
 func TestParseECKey(t *testing.T) {
@@ -32,7 +37,32 @@ func TestParseECKey_InvalidPEM(t *testing.T) {
 }
 
 func TestNewACMEActivity(t *testing.T) {
-	a := NewACMEActivity("test@example.com", "https://acme-staging-v02.api.letsencrypt.org/directory")
+	a := NewACMEActivity("test@example.com", "https://acme-staging-v02.api.letsencrypt.org/directory", nil)
 	assert.Equal(t, "test@example.com", a.email)
 	assert.Equal(t, "https://acme-staging-v02.api.letsencrypt.org/directory", a.directoryURL)
 }
+
+func TestACMEError_RateLimited(t *testing.T) {
+	err := acmeError("authorize order", &acme.Error{
+		StatusCode:  http.StatusTooManyRequests,
+		ProblemType: "urn:ietf:params:acme:error:rateLimited",
+		Header:      http.Header{"Retry-After": []string{"3600"}},
+	})
+
+	var appErr *temporal.ApplicationError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, ErrTypeACMERateLimited, appErr.Type())
+	assert.True(t, appErr.NonRetryable())
+	var retryAfter time.Duration
+	require.NoError(t, appErr.Details(&retryAfter))
+	assert.Equal(t, time.Hour, retryAfter)
+}
+
+func TestACMEError_Other(t *testing.T) {
+	cause := &acme.Error{StatusCode: http.StatusBadRequest, ProblemType: "urn:ietf:params:acme:error:malformed"}
+	err := acmeError("authorize order", cause)
+
+	var appErr *temporal.ApplicationError
+	assert.False(t, errors.As(err, &appErr))
+	assert.ErrorIs(t, err, cause)
+}
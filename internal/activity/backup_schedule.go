@@ -0,0 +1,87 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// GetTenantsDueForBackup returns the IDs of active tenants whose backup
+// schedule is due at now. See model.BackupSchedule.Due.
+func (a *CoreDB) GetTenantsDueForBackup(ctx context.Context, now time.Time) ([]string, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT bs.tenant_id, bs.frequency, bs.last_run_at,
+		        mw.start_time, mw.duration_minutes, mw.timezone
+		 FROM backup_schedules bs
+		 JOIN tenants t ON t.id = bs.tenant_id
+		 LEFT JOIN tenant_maintenance_windows mw ON mw.tenant_id = bs.tenant_id
+		 WHERE bs.enabled AND t.status = $1
+		 ORDER BY bs.tenant_id`,
+		model.StatusActive,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get tenants due for backup: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		s := model.BackupSchedule{Enabled: true}
+		var startTime, timezone *string
+		var durationMinutes *int
+		if err := rows.Scan(&s.TenantID, &s.Frequency, &s.LastRunAt,
+			&startTime, &durationMinutes, &timezone); err != nil {
+			return nil, fmt.Errorf("scan backup schedule: %w", err)
+		}
+
+		var window *model.TenantMaintenanceWindow
+		if startTime != nil {
+			window = &model.TenantMaintenanceWindow{StartTime: *startTime, DurationMinutes: *durationMinutes, Timezone: *timezone}
+		}
+		due, err := s.Due(now, window)
+		if err != nil {
+			// Windows are validated on write; fall back to the default slot.
+			due, _ = s.Due(now, nil)
+		}
+		if due {
+			ids = append(ids, s.TenantID)
+		}
+	}
+	return ids, rows.Err()
+}
+
+// CreateScheduledBackups records a pending backup of each of the tenant's
+// active webroots and databases and marks the tenant's schedule as run. It
+// returns the IDs of the new backups.
+func (a *CoreDB) CreateScheduledBackups(ctx context.Context, tenantID string) ([]string, error) {
+	rows, err := a.db.Query(ctx,
+		`WITH run AS (
+		   UPDATE backup_schedules SET last_run_at = now(), updated_at = now()
+		   WHERE tenant_id = $1
+		 ), sources AS (
+		   SELECT $2::text AS type, id FROM webroots WHERE tenant_id = $1 AND status = $4
+		   UNION ALL
+		   SELECT $3::text, id FROM databases WHERE tenant_id = $1 AND status = $4
+		 )
+		 INSERT INTO backups (id, tenant_id, type, source_id, source_name, status)
+		 SELECT gen_random_uuid()::text, $1, type, id, id, $5 FROM sources
+		 RETURNING id`,
+		tenantID, model.BackupTypeWeb, model.BackupTypeDatabase, model.StatusActive, model.StatusPending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("create scheduled backups for tenant %s: %w", tenantID, err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan backup id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
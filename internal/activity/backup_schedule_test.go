@@ -0,0 +1,64 @@
+package activity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func backupScheduleRow(tenantID string, lastRun *time.Time, start string, tz string) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = tenantID
+		*(dest[1].(*string)) = model.BackupFrequencyDaily
+		*(dest[2].(**time.Time)) = lastRun
+		if start != "" {
+			duration := 60
+			*(dest[3].(**string)) = &start
+			*(dest[4].(**int)) = &duration
+			*(dest[5].(**string)) = &tz
+		}
+		return nil
+	}
+}
+
+func TestCoreDB_GetTenantsDueForBackup(t *testing.T) {
+	db := &mockDB{}
+	a := NewCoreDB(db, "")
+	ctx := context.Background()
+	now := time.Date(2026, 6, 1, 3, 0, 0, 0, time.UTC)
+	recent := now.Add(-2 * time.Hour)
+
+	db.On("Query", ctx, mock.AnythingOfType("string"), []any{model.StatusActive}).Return(newMockRows(
+		backupScheduleRow("window-open", nil, "03:00", "UTC"),
+		backupScheduleRow("window-closed", nil, "12:00", "UTC"),
+		backupScheduleRow("ran-recently", &recent, "03:00", "UTC"),
+	), nil)
+
+	ids, err := a.GetTenantsDueForBackup(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"window-open"}, ids)
+	db.AssertExpectations(t)
+}
+
+func TestCoreDB_CreateScheduledBackups(t *testing.T) {
+	db := &mockDB{}
+	a := NewCoreDB(db, "")
+	ctx := context.Background()
+
+	db.On("Query", ctx, mock.AnythingOfType("string"),
+		[]any{"tenant-1", model.BackupTypeWeb, model.BackupTypeDatabase, model.StatusActive, model.StatusPending},
+	).Return(newMockRows(
+		func(dest ...any) error { *(dest[0].(*string)) = "backup-1"; return nil },
+		func(dest ...any) error { *(dest[0].(*string)) = "backup-2"; return nil },
+	), nil)
+
+	ids, err := a.CreateScheduledBackups(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"backup-1", "backup-2"}, ids)
+	db.AssertExpectations(t)
+}
@@ -25,6 +25,9 @@ type FQDNContext struct {
 	LBAddresses       []model.ClusterLBAddress `json:"lb_addresses"`
 	LBNodes           []model.Node             `json:"lb_nodes"`
 	BrandBaseHostname string                   `json:"brand_base_hostname"`
+	// SplitBackend and SplitWeight are the tenant's traffic split, if any.
+	SplitBackend string `json:"split_backend,omitempty"`
+	SplitWeight  int    `json:"split_weight,omitempty"`
 }
 
 // DatabaseUserContext bundles all data needed by database user workflows.
@@ -63,10 +66,11 @@ type S3AccessKeyContext struct {
 
 // CronJobContext bundles all data needed by cron job workflows.
 type CronJobContext struct {
-	CronJob model.CronJob `json:"cron_job"`
-	Webroot model.Webroot `json:"webroot"`
-	Tenant  model.Tenant  `json:"tenant"`
-	Nodes   []model.Node  `json:"nodes"`
+	CronJob model.CronJob     `json:"cron_job"`
+	Webroot model.Webroot     `json:"webroot"`
+	Tenant  model.Tenant      `json:"tenant"`
+	Nodes   []model.Node      `json:"nodes"`
+	EnvVars map[string]string `json:"env_vars"` // decrypted cron job env
 }
 
 // DaemonContext bundles all data needed by daemon workflows.
@@ -77,6 +81,14 @@ type DaemonContext struct {
 	Nodes   []model.Node  `json:"nodes"`
 }
 
+// SMTPRelayContext bundles an SMTP relay user with the Stalwart instance of
+// its tenant's cluster.
+type SMTPRelayContext struct {
+	RelayUser     model.SMTPRelayUser `json:"relay_user"`
+	StalwartURL   string              `json:"stalwart_url"`
+	StalwartToken string              `json:"stalwart_token"`
+}
+
 // StalwartContext bundles Stalwart connection info resolved from the cluster config,
 // plus the FQDN fields and brand mail DNS config needed by email account workflows.
 type StalwartContext struct {
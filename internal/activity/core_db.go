@@ -35,7 +35,7 @@ type UpdateResourceStatusParams struct {
 	Table         string
 	ID            string
 	Status        string
-	StatusMessage *string // nil = don't change; set to store message
+	StatusMessage *string // nil = don't change (cleared on active); set to store message
 }
 
 // UpdateResourceStatus sets the status of a resource row in the given table.
@@ -47,9 +47,9 @@ func (a *CoreDB) UpdateResourceStatus(ctx context.Context, params UpdateResource
 		return err
 	}
 	if params.Status == model.StatusActive {
-		// Clear status_message on success transitions.
-		query := fmt.Sprintf("UPDATE %s SET status = $1, status_message = NULL, updated_at = now() WHERE id = $2", params.Table)
-		_, err := a.db.Exec(ctx, query, params.Status, params.ID)
+		// Clear status_message on success transitions unless a warning is given.
+		query := fmt.Sprintf("UPDATE %s SET status = $1, status_message = $2, updated_at = now() WHERE id = $3", params.Table)
+		_, err := a.db.Exec(ctx, query, params.Status, params.StatusMessage, params.ID)
 		return err
 	}
 	if params.StatusMessage != nil {
@@ -102,6 +102,7 @@ func (a *CoreDB) DeleteTenantDBRows(ctx context.Context, tenantID string) error
 		`DELETE FROM email_forwards WHERE account_id IN (` + emailAccountSubquery + `)`,
 		`DELETE FROM email_aliases WHERE account_id IN (` + emailAccountSubquery + `)`,
 		`DELETE FROM email_accounts WHERE fqdn_id IN (` + fqdnSubquery + `)`,
+		`DELETE FROM smtp_relay_users WHERE tenant_id=$1`,
 
 		// Certificates and FQDNs (via webroots).
 		`DELETE FROM certificates WHERE fqdn_id IN (` + fqdnSubquery + `)`,
@@ -110,6 +111,7 @@ func (a *CoreDB) DeleteTenantDBRows(ctx context.Context, tenantID string) error
 		// Direct tenant children (web-shard).
 		`DELETE FROM daemons WHERE tenant_id=$1`,
 		`DELETE FROM cron_jobs WHERE tenant_id=$1`,
+		`DELETE FROM webroot_releases WHERE tenant_id=$1`,
 		`DELETE FROM webroots WHERE tenant_id=$1`,
 		`DELETE FROM ssh_keys WHERE tenant_id=$1`,
 		`DELETE FROM backups WHERE tenant_id=$1`,
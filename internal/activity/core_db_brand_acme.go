@@ -0,0 +1,40 @@
+package activity
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/crypto"
+	"github.com/jackc/pgx/v5"
+)
+
+// GetBrandACMEAccount returns the brand's ACME CA with the EAB HMAC key
+// decrypted, or nil if the brand uses the platform default CA.
+func (a *CoreDB) GetBrandACMEAccount(ctx context.Context, brandID string) (*ACMEAccountConfig, error) {
+	var cfg ACMEAccountConfig
+	var encryptedHMAC string
+	err := a.db.QueryRow(ctx,
+		`SELECT directory_url, email, eab_key_id, eab_hmac_key_encrypted
+		 FROM brand_acme_configs WHERE brand_id = $1`, brandID,
+	).Scan(&cfg.DirectoryURL, &cfg.Email, &cfg.EABKeyID, &encryptedHMAC)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get brand ACME config: %w", err)
+	}
+
+	if encryptedHMAC != "" {
+		kek, err := hex.DecodeString(a.kekHex)
+		if err != nil || len(kek) == 0 {
+			return nil, fmt.Errorf("decrypt EAB key for brand %s: secret encryption key not configured", brandID)
+		}
+		cfg.EABHMACKey, err = crypto.Decrypt(encryptedHMAC, kek)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt EAB key for brand %s: %w", brandID, err)
+		}
+	}
+	return &cfg, nil
+}
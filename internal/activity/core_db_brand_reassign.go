@@ -0,0 +1,162 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// BrandReassignmentContext is what ReassignTenantBrandWorkflow needs to move
+// a tenant to another brand. It is read before the tenant's brand changes.
+type BrandReassignmentContext struct {
+	Tenant      model.Tenant `json:"tenant"`
+	SourceBrand model.Brand  `json:"source_brand"`
+	TargetBrand model.Brand  `json:"target_brand"`
+	// ACMECAChanged is set when the brands issue certificates from different
+	// ACME CAs or accounts.
+	ACMECAChanged bool `json:"acme_ca_changed"`
+	// Zones are the tenant's active zones.
+	Zones []NamedResource `json:"zones"`
+	// EmailFQDNs are the tenant's active FQDNs with email accounts.
+	EmailFQDNs []NamedResource `json:"email_fqdns"`
+	// CertFQDNs are the tenant's active FQDNs with an active ACME certificate.
+	CertFQDNs []NamedResource `json:"cert_fqdns"`
+	// ServiceHostnameWebroots are the IDs of the tenant's active webroots
+	// with a service hostname.
+	ServiceHostnameWebroots []string `json:"service_hostname_webroots"`
+}
+
+// NamedResource identifies a resource by ID and name, e.g. a zone or FQDN.
+type NamedResource struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// GetBrandReassignmentContext returns the tenant, both brands, and the
+// tenant's resources whose configuration derives from its brand.
+func (a *CoreDB) GetBrandReassignmentContext(ctx context.Context, tenantID, targetBrandID string) (*BrandReassignmentContext, error) {
+	tenant, err := a.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := a.GetBrandByID(ctx, tenant.BrandID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := a.GetBrandByID(ctx, targetBrandID)
+	if err != nil {
+		return nil, err
+	}
+	bc := &BrandReassignmentContext{Tenant: *tenant, SourceBrand: *source, TargetBrand: *target}
+
+	sourceCA, err := a.brandACMEAccountKey(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	targetCA, err := a.brandACMEAccountKey(ctx, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	bc.ACMECAChanged = sourceCA != targetCA
+
+	if bc.Zones, err = a.listNamedResources(ctx,
+		`SELECT id, name FROM zones WHERE tenant_id = $1 AND status = 'active' ORDER BY name`, tenantID); err != nil {
+		return nil, fmt.Errorf("list zones: %w", err)
+	}
+	if bc.EmailFQDNs, err = a.listNamedResources(ctx,
+		`SELECT DISTINCT f.id, f.fqdn FROM fqdns f
+		 JOIN email_accounts ea ON ea.fqdn_id = f.id
+		 WHERE f.tenant_id = $1 AND f.status = 'active' ORDER BY f.fqdn`, tenantID); err != nil {
+		return nil, fmt.Errorf("list email fqdns: %w", err)
+	}
+	if bc.CertFQDNs, err = a.listNamedResources(ctx,
+		`SELECT DISTINCT f.id, f.fqdn FROM fqdns f
+		 JOIN certificates c ON c.fqdn_id = f.id AND c.is_active AND c.type = $2
+		 WHERE f.tenant_id = $1 AND f.status = 'active' AND f.ssl_enabled ORDER BY f.fqdn`,
+		tenantID, model.CertTypeLetsEncrypt); err != nil {
+		return nil, fmt.Errorf("list certificate fqdns: %w", err)
+	}
+
+	rows, err := a.db.Query(ctx,
+		`SELECT id FROM webroots WHERE tenant_id = $1 AND status = 'active' AND service_hostname_enabled ORDER BY id`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list service hostname webroots: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan webroot id: %w", err)
+		}
+		bc.ServiceHostnameWebroots = append(bc.ServiceHostnameWebroots, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate webroots: %w", err)
+	}
+	return bc, nil
+}
+
+// brandACMEAccountKey identifies the ACME CA and account a brand issues
+// certificates from; it is empty for the platform default.
+func (a *CoreDB) brandACMEAccountKey(ctx context.Context, brandID string) (string, error) {
+	var directoryURL, eabKeyID string
+	err := a.db.QueryRow(ctx,
+		`SELECT directory_url, eab_key_id FROM brand_acme_configs WHERE brand_id = $1`, brandID,
+	).Scan(&directoryURL, &eabKeyID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get brand ACME config: %w", err)
+	}
+	return directoryURL + " " + eabKeyID, nil
+}
+
+func (a *CoreDB) listNamedResources(ctx context.Context, query string, args ...any) ([]NamedResource, error) {
+	rows, err := a.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []NamedResource
+	for rows.Next() {
+		var r NamedResource
+		if err := rows.Scan(&r.ID, &r.Name); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// UpdateTenantBrandParams holds parameters for UpdateTenantBrand.
+type UpdateTenantBrandParams struct {
+	TenantID   string  `json:"tenant_id"`
+	BrandID    string  `json:"brand_id"`
+	ResellerID *string `json:"reseller_id,omitempty"`
+}
+
+// UpdateTenantBrand moves a tenant and its zones to another brand and sets
+// the tenant's reseller, which must belong to that brand or be nil. The
+// updates run in one transaction, so the tenant and its zones never end up
+// on different brands.
+func (a *CoreDB) UpdateTenantBrand(ctx context.Context, params UpdateTenantBrandParams) error {
+	return pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`UPDATE tenants SET brand_id = $1, reseller_id = $2, updated_at = now() WHERE id = $3`,
+			params.BrandID, params.ResellerID, params.TenantID)
+		if err != nil {
+			return fmt.Errorf("update tenant %s brand: %w", params.TenantID, err)
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE zones SET brand_id = $1, updated_at = now() WHERE tenant_id = $2 AND status <> $3`,
+			params.BrandID, params.TenantID, model.StatusDeleted)
+		if err != nil {
+			return fmt.Errorf("update zones of tenant %s brand: %w", params.TenantID, err)
+		}
+		return nil
+	})
+}
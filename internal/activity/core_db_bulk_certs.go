@@ -0,0 +1,68 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// BulkCertFQDN is an FQDN of a bulk certificate issuance. RegisteredDomain is
+// the domain the CA's per-domain rate limits apply to (e.g. example.co.uk for
+// shop.example.co.uk).
+type BulkCertFQDN struct {
+	FQDNID           string `json:"fqdn_id"`
+	FQDN             string `json:"fqdn"`
+	RegisteredDomain string `json:"registered_domain"`
+	HasCert          bool   `json:"has_cert"`
+}
+
+// GetBulkCertFQDNs returns the FQDNs to issue certificates for, with whether
+// each already has an active certificate. FQDNs that no longer exist are left
+// out.
+func (a *CoreDB) GetBulkCertFQDNs(ctx context.Context, fqdnIDs []string) ([]BulkCertFQDN, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT f.id, f.fqdn, EXISTS (SELECT 1 FROM certificates c WHERE c.fqdn_id = f.id AND c.is_active)
+		 FROM fqdns f WHERE f.id = ANY($1) AND f.status NOT IN ($2, $3)`,
+		fqdnIDs, model.StatusDeleting, model.StatusDeleted,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list bulk certificate fqdns: %w", err)
+	}
+	defer rows.Close()
+
+	byID := make(map[string]BulkCertFQDN, len(fqdnIDs))
+	for rows.Next() {
+		var f BulkCertFQDN
+		if err := rows.Scan(&f.FQDNID, &f.FQDN, &f.HasCert); err != nil {
+			return nil, fmt.Errorf("scan bulk certificate fqdn: %w", err)
+		}
+		f.RegisteredDomain = registeredDomain(f.FQDN)
+		byID[f.FQDNID] = f
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate bulk certificate fqdns: %w", err)
+	}
+
+	fqdns := make([]BulkCertFQDN, 0, len(byID))
+	for _, id := range fqdnIDs {
+		if f, ok := byID[id]; ok {
+			fqdns = append(fqdns, f)
+		}
+	}
+	return fqdns, nil
+}
+
+// registeredDomain returns the public suffix plus one label of an FQDN, or
+// the FQDN itself if it has none (e.g. it is a public suffix).
+func registeredDomain(fqdn string) string {
+	name := strings.TrimPrefix(fqdn, "*.")
+	domain, err := publicsuffix.EffectiveTLDPlusOne(name)
+	if err != nil {
+		return name
+	}
+	return domain
+}
@@ -16,14 +16,14 @@ func (a *CoreDB) GetWebrootContext(ctx context.Context, webrootID string) (*Webr
 
 	// JOIN webroots with tenants and brands.
 	err := a.db.QueryRow(ctx,
-		`SELECT w.id, w.tenant_id, w.runtime, w.runtime_version, w.runtime_config, w.public_folder, w.env_file_name, w.service_hostname_enabled, w.status, w.status_message, w.suspend_reason, w.created_at, w.updated_at,
+		`SELECT w.id, w.tenant_id, w.runtime, w.runtime_version, w.runtime_config, w.public_folder, w.error_pages, w.basic_auth, w.env_file_name, w.service_hostname_enabled, w.access_log_enabled, w.connection_limits, w.static_rules, w.geo_blocking, w.deploy_locked_until, w.status, w.status_message, w.suspend_reason, w.created_at, w.updated_at,
 		        t.id, t.brand_id, t.region_id, t.cluster_id, t.shard_id, t.uid, t.sftp_enabled, t.ssh_enabled, t.disk_quota_bytes, t.status, t.status_message, t.suspend_reason, t.created_at, t.updated_at,
 		        b.base_hostname
 		 FROM webroots w
 		 JOIN tenants t ON t.id = w.tenant_id
 		 JOIN brands b ON b.id = t.brand_id
 		 WHERE w.id = $1`, webrootID,
-	).Scan(&wc.Webroot.ID, &wc.Webroot.TenantID, &wc.Webroot.Runtime, &wc.Webroot.RuntimeVersion, &wc.Webroot.RuntimeConfig, &wc.Webroot.PublicFolder, &wc.Webroot.EnvFileName, &wc.Webroot.ServiceHostnameEnabled, &wc.Webroot.Status, &wc.Webroot.StatusMessage, &wc.Webroot.SuspendReason, &wc.Webroot.CreatedAt, &wc.Webroot.UpdatedAt,
+	).Scan(&wc.Webroot.ID, &wc.Webroot.TenantID, &wc.Webroot.Runtime, &wc.Webroot.RuntimeVersion, &wc.Webroot.RuntimeConfig, &wc.Webroot.PublicFolder, &wc.Webroot.ErrorPages, &wc.Webroot.BasicAuth, &wc.Webroot.EnvFileName, &wc.Webroot.ServiceHostnameEnabled, &wc.Webroot.AccessLogEnabled, &wc.Webroot.ConnectionLimits, &wc.Webroot.StaticRules, &wc.Webroot.GeoBlocking, &wc.Webroot.DeployLockedUntil, &wc.Webroot.Status, &wc.Webroot.StatusMessage, &wc.Webroot.SuspendReason, &wc.Webroot.CreatedAt, &wc.Webroot.UpdatedAt,
 		&wc.Tenant.ID, &wc.Tenant.BrandID, &wc.Tenant.RegionID, &wc.Tenant.ClusterID, &wc.Tenant.ShardID, &wc.Tenant.UID, &wc.Tenant.SFTPEnabled, &wc.Tenant.SSHEnabled, &wc.Tenant.DiskQuotaBytes, &wc.Tenant.Status, &wc.Tenant.StatusMessage, &wc.Tenant.SuspendReason, &wc.Tenant.CreatedAt, &wc.Tenant.UpdatedAt,
 		&wc.BrandBaseHostname)
 	if err != nil {
@@ -83,8 +83,8 @@ func (a *CoreDB) GetFQDNContext(ctx context.Context, fqdnID string) (*FQDNContex
 
 	// JOIN fqdns -> webroots -> tenants -> brands.
 	err := a.db.QueryRow(ctx,
-		`SELECT f.id, f.fqdn, f.webroot_id, f.ssl_enabled, f.status, f.status_message, f.created_at, f.updated_at,
-		        w.id, w.tenant_id, w.runtime, w.runtime_version, w.runtime_config, w.public_folder, w.env_file_name, w.service_hostname_enabled, w.status, w.status_message, w.suspend_reason, w.created_at, w.updated_at,
+		`SELECT f.id, f.fqdn, f.webroot_id, f.ssl_enabled, f.force_https, f.redirect_target, f.redirect_code, f.redirect_preserve_path, f.ip_mode, f.status, f.status_message, f.created_at, f.updated_at,
+		        w.id, w.tenant_id, w.runtime, w.runtime_version, w.runtime_config, w.public_folder, w.error_pages, w.basic_auth, w.env_file_name, w.service_hostname_enabled, w.access_log_enabled, w.connection_limits, w.static_rules, w.geo_blocking, w.deploy_locked_until, w.status, w.status_message, w.suspend_reason, w.created_at, w.updated_at,
 		        t.id, t.brand_id, t.region_id, t.cluster_id, t.shard_id, t.uid, t.sftp_enabled, t.ssh_enabled, t.disk_quota_bytes, t.status, t.status_message, t.suspend_reason, t.created_at, t.updated_at,
 		        b.base_hostname
 		 FROM fqdns f
@@ -92,8 +92,8 @@ func (a *CoreDB) GetFQDNContext(ctx context.Context, fqdnID string) (*FQDNContex
 		 JOIN tenants t ON t.id = w.tenant_id
 		 JOIN brands b ON b.id = t.brand_id
 		 WHERE f.id = $1`, fqdnID,
-	).Scan(&fc.FQDN.ID, &fc.FQDN.FQDN, &fc.FQDN.WebrootID, &fc.FQDN.SSLEnabled, &fc.FQDN.Status, &fc.FQDN.StatusMessage, &fc.FQDN.CreatedAt, &fc.FQDN.UpdatedAt,
-		&fc.Webroot.ID, &fc.Webroot.TenantID, &fc.Webroot.Runtime, &fc.Webroot.RuntimeVersion, &fc.Webroot.RuntimeConfig, &fc.Webroot.PublicFolder, &fc.Webroot.EnvFileName, &fc.Webroot.ServiceHostnameEnabled, &fc.Webroot.Status, &fc.Webroot.StatusMessage, &fc.Webroot.SuspendReason, &fc.Webroot.CreatedAt, &fc.Webroot.UpdatedAt,
+	).Scan(&fc.FQDN.ID, &fc.FQDN.FQDN, &fc.FQDN.WebrootID, &fc.FQDN.SSLEnabled, &fc.FQDN.ForceHTTPS, &fc.FQDN.RedirectTarget, &fc.FQDN.RedirectCode, &fc.FQDN.RedirectPreservePath, &fc.FQDN.IPMode, &fc.FQDN.Status, &fc.FQDN.StatusMessage, &fc.FQDN.CreatedAt, &fc.FQDN.UpdatedAt,
+		&fc.Webroot.ID, &fc.Webroot.TenantID, &fc.Webroot.Runtime, &fc.Webroot.RuntimeVersion, &fc.Webroot.RuntimeConfig, &fc.Webroot.PublicFolder, &fc.Webroot.ErrorPages, &fc.Webroot.BasicAuth, &fc.Webroot.EnvFileName, &fc.Webroot.ServiceHostnameEnabled, &fc.Webroot.AccessLogEnabled, &fc.Webroot.ConnectionLimits, &fc.Webroot.StaticRules, &fc.Webroot.GeoBlocking, &fc.Webroot.DeployLockedUntil, &fc.Webroot.Status, &fc.Webroot.StatusMessage, &fc.Webroot.SuspendReason, &fc.Webroot.CreatedAt, &fc.Webroot.UpdatedAt,
 		&fc.Tenant.ID, &fc.Tenant.BrandID, &fc.Tenant.RegionID, &fc.Tenant.ClusterID, &fc.Tenant.ShardID, &fc.Tenant.UID, &fc.Tenant.SFTPEnabled, &fc.Tenant.SSHEnabled, &fc.Tenant.DiskQuotaBytes, &fc.Tenant.Status, &fc.Tenant.StatusMessage, &fc.Tenant.SuspendReason, &fc.Tenant.CreatedAt, &fc.Tenant.UpdatedAt,
 		&fc.BrandBaseHostname)
 	if err != nil {
@@ -127,6 +127,11 @@ func (a *CoreDB) GetFQDNContext(ctx context.Context, fqdnID string) (*FQDNContex
 			return nil, err
 		}
 		fc.Nodes = nodes
+
+		fc.SplitBackend, fc.SplitWeight, err = a.getTenantLBSplit(ctx, fc.Tenant.ID)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &fc, nil
@@ -168,12 +173,12 @@ func (a *CoreDB) GetValkeyUserContext(ctx context.Context, userID string) (*Valk
 	// JOIN valkey_users with valkey_instances.
 	err := a.db.QueryRow(ctx,
 		`SELECT u.id, u.valkey_instance_id, u.username, u.password_hash, u.privileges, u.key_pattern, u.status, u.status_message, u.created_at, u.updated_at,
-		        i.id, i.tenant_id, i.shard_id, i.port, i.max_memory_mb, i.password_hash, i.status, i.status_message, i.suspend_reason, i.created_at, i.updated_at
+		        i.id, i.tenant_id, i.shard_id, i.port, i.max_memory_mb, i.persistence_mode, i.password_hash, i.status, i.status_message, i.suspend_reason, i.created_at, i.updated_at
 		 FROM valkey_users u
 		 JOIN valkey_instances i ON i.id = u.valkey_instance_id
 		 WHERE u.id = $1`, userID,
 	).Scan(&vc.User.ID, &vc.User.ValkeyInstanceID, &vc.User.Username, &vc.User.PasswordHash, &vc.User.Privileges, &vc.User.KeyPattern, &vc.User.Status, &vc.User.StatusMessage, &vc.User.CreatedAt, &vc.User.UpdatedAt,
-		&vc.Instance.ID, &vc.Instance.TenantID, &vc.Instance.ShardID, &vc.Instance.Port, &vc.Instance.MaxMemoryMB, &vc.Instance.PasswordHash, &vc.Instance.Status, &vc.Instance.StatusMessage, &vc.Instance.SuspendReason, &vc.Instance.CreatedAt, &vc.Instance.UpdatedAt)
+		&vc.Instance.ID, &vc.Instance.TenantID, &vc.Instance.ShardID, &vc.Instance.Port, &vc.Instance.MaxMemoryMB, &vc.Instance.PersistenceMode, &vc.Instance.PasswordHash, &vc.Instance.Status, &vc.Instance.StatusMessage, &vc.Instance.SuspendReason, &vc.Instance.CreatedAt, &vc.Instance.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get valkey user context: %w", err)
 	}
@@ -269,19 +274,23 @@ func (a *CoreDB) GetS3AccessKeyContext(ctx context.Context, keyID string) (*S3Ac
 }
 
 // GetStalwartContext resolves Stalwart connection info by traversing FQDN -> webroot -> tenant -> cluster,
-// and includes the brand's mail DNS configuration (SPF, DKIM, DMARC).
+// and includes the brand's mail DNS configuration (SPF, DKIM, DMARC). The DKIM
+// selector and key are the FQDN's own when it has an active one, otherwise the brand's.
 func (a *CoreDB) GetStalwartContext(ctx context.Context, fqdnID string) (*StalwartContext, error) {
 	var sc StalwartContext
 	var clusterConfig []byte
 
 	err := a.db.QueryRow(ctx,
 		`SELECT f.id, f.fqdn, c.config,
-		 b.mail_hostname, b.spf_includes, b.dkim_selector, b.dkim_public_key, b.dmarc_policy
+		 b.mail_hostname, b.spf_includes,
+		 COALESCE(k.selector, b.dkim_selector), COALESCE(k.public_key, b.dkim_public_key),
+		 b.dmarc_policy
 		 FROM fqdns f
 		 JOIN webroots w ON w.id = f.webroot_id
 		 JOIN tenants t ON t.id = w.tenant_id
 		 JOIN clusters c ON c.id = t.cluster_id
 		 JOIN brands b ON b.id = t.brand_id
+		 LEFT JOIN fqdn_dkim_keys k ON k.fqdn_id = f.id AND k.status = 'active'
 		 WHERE f.id = $1`, fqdnID,
 	).Scan(&sc.FQDNID, &sc.FQDN, &clusterConfig,
 		&sc.MailHostname, &sc.SPFIncludes, &sc.DKIMSelector, &sc.DKIMPublicKey, &sc.DMARCPolicy)
@@ -314,20 +323,26 @@ func (a *CoreDB) GetCronJobContext(ctx context.Context, cronJobID string) (*Cron
 
 	// JOIN cron_jobs -> webroots -> tenants.
 	err := a.db.QueryRow(ctx,
-		`SELECT c.id, c.tenant_id, c.webroot_id, c.schedule, c.command, c.working_directory, c.enabled, c.timeout_seconds, c.max_memory_mb, c.status, c.status_message, c.created_at, c.updated_at,
-		        w.id, w.tenant_id, w.runtime, w.runtime_version, w.runtime_config, w.public_folder, w.env_file_name, w.status, w.status_message, w.suspend_reason, w.created_at, w.updated_at,
+		`SELECT c.id, c.tenant_id, c.webroot_id, c.schedule, c.command, c.working_directory, c.enabled, c.timeout_seconds, c.max_memory_mb, c.timezone, c.status, c.status_message, c.created_at, c.updated_at,
+		        w.id, w.tenant_id, w.runtime, w.runtime_version, w.runtime_config, w.public_folder, w.error_pages, w.basic_auth, w.env_file_name, w.status, w.status_message, w.suspend_reason, w.created_at, w.updated_at,
 		        t.id, t.brand_id, t.region_id, t.cluster_id, t.shard_id, t.uid, t.sftp_enabled, t.ssh_enabled, t.disk_quota_bytes, t.status, t.status_message, t.suspend_reason, t.created_at, t.updated_at
 		 FROM cron_jobs c
 		 JOIN webroots w ON w.id = c.webroot_id
 		 JOIN tenants t ON t.id = c.tenant_id
 		 WHERE c.id = $1`, cronJobID,
-	).Scan(&cc.CronJob.ID, &cc.CronJob.TenantID, &cc.CronJob.WebrootID, &cc.CronJob.Schedule, &cc.CronJob.Command, &cc.CronJob.WorkingDirectory, &cc.CronJob.Enabled, &cc.CronJob.TimeoutSeconds, &cc.CronJob.MaxMemoryMB, &cc.CronJob.Status, &cc.CronJob.StatusMessage, &cc.CronJob.CreatedAt, &cc.CronJob.UpdatedAt,
-		&cc.Webroot.ID, &cc.Webroot.TenantID, &cc.Webroot.Runtime, &cc.Webroot.RuntimeVersion, &cc.Webroot.RuntimeConfig, &cc.Webroot.PublicFolder, &cc.Webroot.EnvFileName, &cc.Webroot.Status, &cc.Webroot.StatusMessage, &cc.Webroot.SuspendReason, &cc.Webroot.CreatedAt, &cc.Webroot.UpdatedAt,
+	).Scan(&cc.CronJob.ID, &cc.CronJob.TenantID, &cc.CronJob.WebrootID, &cc.CronJob.Schedule, &cc.CronJob.Command, &cc.CronJob.WorkingDirectory, &cc.CronJob.Enabled, &cc.CronJob.TimeoutSeconds, &cc.CronJob.MaxMemoryMB, &cc.CronJob.Timezone, &cc.CronJob.Status, &cc.CronJob.StatusMessage, &cc.CronJob.CreatedAt, &cc.CronJob.UpdatedAt,
+		&cc.Webroot.ID, &cc.Webroot.TenantID, &cc.Webroot.Runtime, &cc.Webroot.RuntimeVersion, &cc.Webroot.RuntimeConfig, &cc.Webroot.PublicFolder, &cc.Webroot.ErrorPages, &cc.Webroot.BasicAuth, &cc.Webroot.EnvFileName, &cc.Webroot.Status, &cc.Webroot.StatusMessage, &cc.Webroot.SuspendReason, &cc.Webroot.CreatedAt, &cc.Webroot.UpdatedAt,
 		&cc.Tenant.ID, &cc.Tenant.BrandID, &cc.Tenant.RegionID, &cc.Tenant.ClusterID, &cc.Tenant.ShardID, &cc.Tenant.UID, &cc.Tenant.SFTPEnabled, &cc.Tenant.SSHEnabled, &cc.Tenant.DiskQuotaBytes, &cc.Tenant.Status, &cc.Tenant.StatusMessage, &cc.Tenant.SuspendReason, &cc.Tenant.CreatedAt, &cc.Tenant.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get cron job context: %w", err)
 	}
 
+	envVars, err := a.decryptCronJobEnvVars(ctx, []string{cronJobID})
+	if err != nil {
+		return nil, fmt.Errorf("decrypt cron job env vars: %w", err)
+	}
+	cc.EnvVars = envVars[cronJobID]
+
 	// Fetch nodes if tenant has a shard.
 	if cc.Tenant.ShardID != nil {
 		nodes, err := a.ListNodesByShard(ctx, *cc.Tenant.ShardID)
@@ -349,7 +364,7 @@ func (a *CoreDB) GetDaemonContext(ctx context.Context, daemonID string) (*Daemon
 		`SELECT d.id, d.tenant_id, d.node_id, d.webroot_id, d.command, d.proxy_path, d.proxy_port,
 		        d.num_procs, d.stop_signal, d.stop_wait_secs, d.max_memory_mb,
 		        d.enabled, d.status, d.status_message, d.created_at, d.updated_at,
-		        w.id, w.tenant_id, w.runtime, w.runtime_version, w.runtime_config, w.public_folder, w.env_file_name, w.status, w.status_message, w.suspend_reason, w.created_at, w.updated_at,
+		        w.id, w.tenant_id, w.runtime, w.runtime_version, w.runtime_config, w.public_folder, w.error_pages, w.basic_auth, w.env_file_name, w.access_log_enabled, w.connection_limits, w.static_rules, w.geo_blocking, w.deploy_locked_until, w.status, w.status_message, w.suspend_reason, w.created_at, w.updated_at,
 		        t.id, t.brand_id, t.region_id, t.cluster_id, t.shard_id, t.uid, t.sftp_enabled, t.ssh_enabled, t.disk_quota_bytes, t.status, t.status_message, t.suspend_reason, t.created_at, t.updated_at
 		 FROM daemons d
 		 JOIN webroots w ON w.id = d.webroot_id
@@ -359,7 +374,7 @@ func (a *CoreDB) GetDaemonContext(ctx context.Context, daemonID string) (*Daemon
 		&dc.Daemon.ProxyPath, &dc.Daemon.ProxyPort,
 		&dc.Daemon.NumProcs, &dc.Daemon.StopSignal, &dc.Daemon.StopWaitSecs, &dc.Daemon.MaxMemoryMB,
 		&dc.Daemon.Enabled, &dc.Daemon.Status, &dc.Daemon.StatusMessage, &dc.Daemon.CreatedAt, &dc.Daemon.UpdatedAt,
-		&dc.Webroot.ID, &dc.Webroot.TenantID, &dc.Webroot.Runtime, &dc.Webroot.RuntimeVersion, &dc.Webroot.RuntimeConfig, &dc.Webroot.PublicFolder, &dc.Webroot.EnvFileName, &dc.Webroot.Status, &dc.Webroot.StatusMessage, &dc.Webroot.SuspendReason, &dc.Webroot.CreatedAt, &dc.Webroot.UpdatedAt,
+		&dc.Webroot.ID, &dc.Webroot.TenantID, &dc.Webroot.Runtime, &dc.Webroot.RuntimeVersion, &dc.Webroot.RuntimeConfig, &dc.Webroot.PublicFolder, &dc.Webroot.ErrorPages, &dc.Webroot.BasicAuth, &dc.Webroot.EnvFileName, &dc.Webroot.AccessLogEnabled, &dc.Webroot.ConnectionLimits, &dc.Webroot.StaticRules, &dc.Webroot.GeoBlocking, &dc.Webroot.DeployLockedUntil, &dc.Webroot.Status, &dc.Webroot.StatusMessage, &dc.Webroot.SuspendReason, &dc.Webroot.CreatedAt, &dc.Webroot.UpdatedAt,
 		&dc.Tenant.ID, &dc.Tenant.BrandID, &dc.Tenant.RegionID, &dc.Tenant.ClusterID, &dc.Tenant.ShardID, &dc.Tenant.UID, &dc.Tenant.SFTPEnabled, &dc.Tenant.SSHEnabled, &dc.Tenant.DiskQuotaBytes, &dc.Tenant.Status, &dc.Tenant.StatusMessage, &dc.Tenant.SuspendReason, &dc.Tenant.CreatedAt, &dc.Tenant.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get daemon context: %w", err)
@@ -380,8 +395,29 @@ func (a *CoreDB) GetDaemonContext(ctx context.Context, daemonID string) (*Daemon
 // decryptEnvVars batch-fetches and decrypts env vars for the given webroot IDs.
 // Returns webroot ID -> env var name -> plaintext value.
 func (a *CoreDB) decryptEnvVars(ctx context.Context, webrootIDs []string) (map[string]map[string]string, error) {
+	return a.decryptEnvVarRows(ctx,
+		`SELECT e.webroot_id, e.name, e.value, e.is_secret, w.tenant_id
+		 FROM webroot_env_vars e
+		 JOIN webroots w ON w.id = e.webroot_id
+		 WHERE e.webroot_id = ANY($1)`, webrootIDs)
+}
+
+// decryptCronJobEnvVars batch-fetches and decrypts env vars for the given
+// cron job IDs. Returns cron job ID -> env var name -> plaintext value.
+func (a *CoreDB) decryptCronJobEnvVars(ctx context.Context, cronJobIDs []string) (map[string]map[string]string, error) {
+	return a.decryptEnvVarRows(ctx,
+		`SELECT e.cron_job_id, e.name, e.value, e.is_secret, c.tenant_id
+		 FROM cron_job_env_vars e
+		 JOIN cron_jobs c ON c.id = e.cron_job_id
+		 WHERE e.cron_job_id = ANY($1)`, cronJobIDs)
+}
+
+// decryptEnvVarRows runs query, which must select owner ID, name, value,
+// is_secret and tenant ID for the owners in $1, and decrypts secret values
+// with the owning tenant's DEK.
+func (a *CoreDB) decryptEnvVarRows(ctx context.Context, query string, ownerIDs []string) (map[string]map[string]string, error) {
 	result := make(map[string]map[string]string)
-	if len(webrootIDs) == 0 || a.kekHex == "" {
+	if len(ownerIDs) == 0 || a.kekHex == "" {
 		return result, nil
 	}
 
@@ -391,11 +427,7 @@ func (a *CoreDB) decryptEnvVars(ctx context.Context, webrootIDs []string) (map[s
 	}
 
 	// Batch query all env vars.
-	rows, err := a.db.Query(ctx,
-		`SELECT e.webroot_id, e.name, e.value, e.is_secret, w.tenant_id
-		 FROM webroot_env_vars e
-		 JOIN webroots w ON w.id = e.webroot_id
-		 WHERE e.webroot_id = ANY($1)`, webrootIDs)
+	rows, err := a.db.Query(ctx, query, ownerIDs)
 	if err != nil {
 		return nil, fmt.Errorf("query env vars: %w", err)
 	}
@@ -405,9 +437,9 @@ func (a *CoreDB) decryptEnvVars(ctx context.Context, webrootIDs []string) (map[s
 	dekCache := make(map[string][]byte)
 
 	for rows.Next() {
-		var webrootID, name, value, tenantID string
+		var ownerID, name, value, tenantID string
 		var isSecret bool
-		if err := rows.Scan(&webrootID, &name, &value, &isSecret, &tenantID); err != nil {
+		if err := rows.Scan(&ownerID, &name, &value, &isSecret, &tenantID); err != nil {
 			return nil, fmt.Errorf("scan env var: %w", err)
 		}
 
@@ -434,10 +466,10 @@ func (a *CoreDB) decryptEnvVars(ctx context.Context, webrootIDs []string) (map[s
 			value = string(plaintext)
 		}
 
-		if result[webrootID] == nil {
-			result[webrootID] = make(map[string]string)
+		if result[ownerID] == nil {
+			result[ownerID] = make(map[string]string)
 		}
-		result[webrootID][name] = value
+		result[ownerID][name] = value
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterate env vars: %w", err)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/edvin/hosting/internal/db"
 	"github.com/edvin/hosting/internal/model"
 )
 
@@ -16,18 +17,22 @@ type ShardDesiredState struct {
 	EnvVars            map[string]map[string]string `json:"env_vars"`            // webroot ID -> name -> value
 	Daemons            map[string][]model.Daemon   `json:"daemons"`              // webroot ID -> daemons
 	CronJobs           map[string][]model.CronJob  `json:"cron_jobs"`            // webroot ID -> cron jobs
+	CronJobEnvVars     map[string]map[string]string `json:"cron_job_env_vars"`  // cron job ID -> name -> value
 	SSHKeys            map[string][]string         `json:"ssh_keys"`             // tenant ID -> public keys
 	BrandBaseHostnames map[string]string           `json:"brand_base_hostnames"` // tenant ID -> brand base_hostname
 }
 
 // GetShardDesiredState fetches all data needed to converge a web shard in batch.
 func (a *CoreDB) GetShardDesiredState(ctx context.Context, shardID string) (*ShardDesiredState, error) {
+	ctx = db.WithQueryName(ctx, "GetShardDesiredState")
+
 	result := &ShardDesiredState{
 		Webroots:           make(map[string][]model.Webroot),
 		FQDNs:              make(map[string][]FQDNParam),
 		EnvVars:            make(map[string]map[string]string),
 		Daemons:            make(map[string][]model.Daemon),
 		CronJobs:           make(map[string][]model.CronJob),
+		CronJobEnvVars:     make(map[string]map[string]string),
 		SSHKeys:            make(map[string][]string),
 		BrandBaseHostnames: make(map[string]string),
 	}
@@ -70,7 +75,7 @@ func (a *CoreDB) GetShardDesiredState(ctx context.Context, shardID string) (*Sha
 
 	// 3. Fetch all active webroots for those tenants.
 	wrRows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, runtime, runtime_version, runtime_config, public_folder, env_file_name, service_hostname_enabled, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, runtime, runtime_version, runtime_config, public_folder, error_pages, basic_auth, env_file_name, service_hostname_enabled, access_log_enabled, connection_limits, static_rules, geo_blocking, deploy_locked_until, status, status_message, suspend_reason, created_at, updated_at
 		 FROM webroots WHERE tenant_id = ANY($1) AND status = $2`, tenantIDs, model.StatusActive)
 	if err != nil {
 		return nil, fmt.Errorf("batch list webroots: %w", err)
@@ -80,7 +85,7 @@ func (a *CoreDB) GetShardDesiredState(ctx context.Context, shardID string) (*Sha
 	var webrootIDs []string
 	for wrRows.Next() {
 		var w model.Webroot
-		if err := wrRows.Scan(&w.ID, &w.TenantID, &w.Runtime, &w.RuntimeVersion, &w.RuntimeConfig, &w.PublicFolder, &w.EnvFileName, &w.ServiceHostnameEnabled, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		if err := wrRows.Scan(&w.ID, &w.TenantID, &w.Runtime, &w.RuntimeVersion, &w.RuntimeConfig, &w.PublicFolder, &w.ErrorPages, &w.BasicAuth, &w.EnvFileName, &w.ServiceHostnameEnabled, &w.AccessLogEnabled, &w.ConnectionLimits, &w.StaticRules, &w.GeoBlocking, &w.DeployLockedUntil, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan webroot: %w", err)
 		}
 		result.Webroots[w.TenantID] = append(result.Webroots[w.TenantID], w)
@@ -103,7 +108,7 @@ func (a *CoreDB) GetShardDesiredState(ctx context.Context, shardID string) (*Sha
 
 	// 5. Fetch all active FQDNs for those webroots.
 	fqdnRows, err := a.db.Query(ctx,
-		`SELECT fqdn, webroot_id, ssl_enabled
+		`SELECT fqdn, webroot_id, ssl_enabled, force_https, redirect_target, redirect_code, redirect_preserve_path
 		 FROM fqdns WHERE webroot_id = ANY($1) AND status = $2`, webrootIDs, model.StatusActive)
 	if err != nil {
 		return nil, fmt.Errorf("batch list fqdns: %w", err)
@@ -112,7 +117,7 @@ func (a *CoreDB) GetShardDesiredState(ctx context.Context, shardID string) (*Sha
 
 	for fqdnRows.Next() {
 		var f FQDNParam
-		if err := fqdnRows.Scan(&f.FQDN, &f.WebrootID, &f.SSLEnabled); err != nil {
+		if err := fqdnRows.Scan(&f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.ForceHTTPS, &f.RedirectTarget, &f.RedirectCode, &f.RedirectPreservePath); err != nil {
 			return nil, fmt.Errorf("scan fqdn: %w", err)
 		}
 		result.FQDNs[f.WebrootID] = append(result.FQDNs[f.WebrootID], f)
@@ -148,24 +153,33 @@ func (a *CoreDB) GetShardDesiredState(ctx context.Context, shardID string) (*Sha
 
 	// 7. Fetch all cron jobs for those webroots.
 	cronRows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, status, status_message, created_at, updated_at
+		`SELECT id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, timezone, status, status_message, created_at, updated_at
 		 FROM cron_jobs WHERE webroot_id = ANY($1)`, webrootIDs)
 	if err != nil {
 		return nil, fmt.Errorf("batch list cron jobs: %w", err)
 	}
 	defer cronRows.Close()
 
+	var cronJobIDs []string
 	for cronRows.Next() {
 		var j model.CronJob
-		if err := cronRows.Scan(&j.ID, &j.TenantID, &j.WebrootID, &j.Schedule, &j.Command, &j.WorkingDirectory, &j.Enabled, &j.TimeoutSeconds, &j.MaxMemoryMB, &j.Status, &j.StatusMessage, &j.CreatedAt, &j.UpdatedAt); err != nil {
+		if err := cronRows.Scan(&j.ID, &j.TenantID, &j.WebrootID, &j.Schedule, &j.Command, &j.WorkingDirectory, &j.Enabled, &j.TimeoutSeconds, &j.MaxMemoryMB, &j.Timezone, &j.Status, &j.StatusMessage, &j.CreatedAt, &j.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan cron job: %w", err)
 		}
 		result.CronJobs[j.WebrootID] = append(result.CronJobs[j.WebrootID], j)
+		cronJobIDs = append(cronJobIDs, j.ID)
 	}
 	if err := cronRows.Err(); err != nil {
 		return nil, fmt.Errorf("iterate cron jobs: %w", err)
 	}
 
+	// Fetch and decrypt env vars for those cron jobs.
+	cronEnvVars, err := a.decryptCronJobEnvVars(ctx, cronJobIDs)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt cron job env vars: %w", err)
+	}
+	result.CronJobEnvVars = cronEnvVars
+
 	// 8. Fetch all active SSH keys for those tenants.
 	sshRows, err := a.db.Query(ctx,
 		`SELECT tenant_id, public_key FROM ssh_keys WHERE tenant_id = ANY($1) AND status = $2`,
@@ -219,7 +233,7 @@ func (a *CoreDB) ListDaemonsByTenant(ctx context.Context, tenantID string) ([]mo
 // ListCronJobsByTenant retrieves all active cron jobs for a tenant (used in convergence).
 func (a *CoreDB) ListCronJobsByTenant(ctx context.Context, tenantID string) ([]model.CronJob, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, status, status_message, created_at, updated_at
+		`SELECT id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, timezone, status, status_message, created_at, updated_at
 		 FROM cron_jobs WHERE tenant_id = $1 AND status = $2 ORDER BY id`, tenantID, model.StatusActive,
 	)
 	if err != nil {
@@ -230,7 +244,7 @@ func (a *CoreDB) ListCronJobsByTenant(ctx context.Context, tenantID string) ([]m
 	var jobs []model.CronJob
 	for rows.Next() {
 		var j model.CronJob
-		if err := rows.Scan(&j.ID, &j.TenantID, &j.WebrootID, &j.Schedule, &j.Command, &j.WorkingDirectory, &j.Enabled, &j.TimeoutSeconds, &j.MaxMemoryMB, &j.Status, &j.StatusMessage, &j.CreatedAt, &j.UpdatedAt); err != nil {
+		if err := rows.Scan(&j.ID, &j.TenantID, &j.WebrootID, &j.Schedule, &j.Command, &j.WorkingDirectory, &j.Enabled, &j.TimeoutSeconds, &j.MaxMemoryMB, &j.Timezone, &j.Status, &j.StatusMessage, &j.CreatedAt, &j.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan cron job row: %w", err)
 		}
 		jobs = append(jobs, j)
@@ -0,0 +1,68 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// GetTenantExportByID retrieves a tenant export by its ID.
+func (a *CoreDB) GetTenantExportByID(ctx context.Context, id string) (*model.TenantExport, error) {
+	var e model.TenantExport
+	err := a.db.QueryRow(ctx,
+		`SELECT id, tenant_id, object_key, size_bytes, status, status_message, expires_at, completed_at, created_at, updated_at
+		 FROM tenant_exports WHERE id = $1`, id,
+	).Scan(&e.ID, &e.TenantID, &e.ObjectKey, &e.SizeBytes, &e.Status, &e.StatusMessage,
+		&e.ExpiresAt, &e.CompletedAt, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant export by id: %w", err)
+	}
+	return &e, nil
+}
+
+// UpdateTenantExportResult records the uploaded archive of a tenant export.
+func (a *CoreDB) UpdateTenantExportResult(ctx context.Context, params UpdateTenantExportResultParams) error {
+	_, err := a.db.Exec(ctx,
+		`UPDATE tenant_exports SET object_key = $1, size_bytes = $2, completed_at = $3, updated_at = now() WHERE id = $4`,
+		params.ObjectKey, params.SizeBytes, params.CompletedAt, params.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update tenant export result: %w", err)
+	}
+	return nil
+}
+
+// GetExpiredTenantExports returns tenant exports whose retention has passed.
+func (a *CoreDB) GetExpiredTenantExports(ctx context.Context) ([]model.TenantExport, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT id, tenant_id, object_key, size_bytes, status, status_message, expires_at, completed_at, created_at, updated_at
+		 FROM tenant_exports
+		 WHERE expires_at < now()
+		 ORDER BY expires_at ASC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get expired tenant exports: %w", err)
+	}
+	defer rows.Close()
+
+	var exports []model.TenantExport
+	for rows.Next() {
+		var e model.TenantExport
+		if err := rows.Scan(&e.ID, &e.TenantID, &e.ObjectKey, &e.SizeBytes, &e.Status, &e.StatusMessage,
+			&e.ExpiresAt, &e.CompletedAt, &e.CreatedAt, &e.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan expired tenant export: %w", err)
+		}
+		exports = append(exports, e)
+	}
+	return exports, rows.Err()
+}
+
+// DeleteTenantExport removes a tenant export's record.
+func (a *CoreDB) DeleteTenantExport(ctx context.Context, id string) error {
+	_, err := a.db.Exec(ctx, `DELETE FROM tenant_exports WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete tenant export %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// MarkOperationRunning records that an operation's workflow has started.
+func (a *CoreDB) MarkOperationRunning(ctx context.Context, params MarkOperationRunningParams) error {
+	_, err := a.db.Exec(ctx,
+		`UPDATE operations SET status = $1, run_id = $2, started_at = now(), updated_at = now()
+		 WHERE id = $3 AND status = $4`,
+		model.OperationRunning, params.RunID, params.ID, model.OperationPending,
+	)
+	if err != nil {
+		return fmt.Errorf("mark operation %s running: %w", params.ID, err)
+	}
+	return nil
+}
+
+// CompleteOperation records the outcome of an operation's workflow. An empty
+// Error marks the operation as succeeded.
+func (a *CoreDB) CompleteOperation(ctx context.Context, params CompleteOperationParams) error {
+	status := model.OperationSucceeded
+	var errMsg *string
+	if params.Error != "" {
+		status = model.OperationFailed
+		errMsg = &params.Error
+	}
+	_, err := a.db.Exec(ctx,
+		`UPDATE operations SET status = $1, error = $2, completed_at = now(), updated_at = now() WHERE id = $3`,
+		status, errMsg, params.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("complete operation %s: %w", params.ID, err)
+	}
+	return nil
+}
+
+// DeleteOldOperations deletes finished operations older than the specified
+// number of days and returns the count of deleted rows.
+func (a *CoreDB) DeleteOldOperations(ctx context.Context, retentionDays int) (int64, error) {
+	tag, err := a.db.Exec(ctx,
+		`DELETE FROM operations WHERE status IN ($1, $2) AND created_at < now() - make_interval(days => $3)`,
+		model.OperationSucceeded, model.OperationFailed, retentionDays)
+	if err != nil {
+		return 0, fmt.Errorf("delete old operations: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes idempotency keys older than window,
+// after which the API no longer replays them, and returns the count.
+func (a *CoreDB) DeleteExpiredIdempotencyKeys(ctx context.Context, window time.Duration) (int64, error) {
+	tag, err := a.db.Exec(ctx,
+		`DELETE FROM idempotency_keys WHERE created_at < now() - make_interval(secs => $1)`, window.Seconds())
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteExpiredBackupDownloadTokens deletes single-use backup download tokens
+// that have expired and returns the count.
+func (a *CoreDB) DeleteExpiredBackupDownloadTokens(ctx context.Context) (int64, error) {
+	tag, err := a.db.Exec(ctx, `DELETE FROM backup_download_tokens WHERE expires_at < now()`)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired backup download tokens: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
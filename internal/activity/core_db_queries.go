@@ -15,7 +15,7 @@ import (
 // ListValkeyInstancesByTenantID retrieves all valkey instances for a tenant.
 func (a *CoreDB) ListValkeyInstancesByTenantID(ctx context.Context, tenantID string) ([]model.ValkeyInstance, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, shard_id, port, max_memory_mb, password_hash, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, shard_id, port, max_memory_mb, persistence_mode, password_hash, status, status_message, suspend_reason, created_at, updated_at
 		 FROM valkey_instances WHERE tenant_id = $1`, tenantID,
 	)
 	if err != nil {
@@ -26,7 +26,7 @@ func (a *CoreDB) ListValkeyInstancesByTenantID(ctx context.Context, tenantID str
 	var instances []model.ValkeyInstance
 	for rows.Next() {
 		var v model.ValkeyInstance
-		if err := rows.Scan(&v.ID, &v.TenantID, &v.ShardID, &v.Port, &v.MaxMemoryMB,
+		if err := rows.Scan(&v.ID, &v.TenantID, &v.ShardID, &v.Port, &v.MaxMemoryMB, &v.PersistenceMode,
 			&v.PasswordHash, &v.Status, &v.StatusMessage, &v.SuspendReason, &v.CreatedAt, &v.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan valkey instance row: %w", err)
 		}
@@ -38,7 +38,7 @@ func (a *CoreDB) ListValkeyInstancesByTenantID(ctx context.Context, tenantID str
 // ListS3BucketsByTenantID retrieves all S3 buckets for a tenant.
 func (a *CoreDB) ListS3BucketsByTenantID(ctx context.Context, tenantID string) ([]model.S3Bucket, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, shard_id, public, quota_bytes, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, shard_id, public, quota_bytes, status, status_message, suspend_reason, created_at, updated_at, lifecycle_rules
 		 FROM s3_buckets WHERE tenant_id = $1`, tenantID,
 	)
 	if err != nil {
@@ -50,7 +50,7 @@ func (a *CoreDB) ListS3BucketsByTenantID(ctx context.Context, tenantID string) (
 	for rows.Next() {
 		var b model.S3Bucket
 		if err := rows.Scan(&b.ID, &b.TenantID, &b.ShardID,
-			&b.Public, &b.QuotaBytes, &b.Status, &b.StatusMessage, &b.SuspendReason, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			&b.Public, &b.QuotaBytes, &b.Status, &b.StatusMessage, &b.SuspendReason, &b.CreatedAt, &b.UpdatedAt, &b.LifecycleRules); err != nil {
 			return nil, fmt.Errorf("scan s3 bucket row: %w", err)
 		}
 		buckets = append(buckets, b)
@@ -61,7 +61,7 @@ func (a *CoreDB) ListS3BucketsByTenantID(ctx context.Context, tenantID string) (
 // ListZonesByTenantID retrieves all zones for a tenant.
 func (a *CoreDB) ListZonesByTenantID(ctx context.Context, tenantID string) ([]model.Zone, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, brand_id, tenant_id, name, region_id, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, brand_id, tenant_id, name, region_id, status, status_message, suspend_reason, dnssec_status, created_at, updated_at
 		 FROM zones WHERE tenant_id = $1`, tenantID,
 	)
 	if err != nil {
@@ -72,7 +72,7 @@ func (a *CoreDB) ListZonesByTenantID(ctx context.Context, tenantID string) ([]mo
 	var zones []model.Zone
 	for rows.Next() {
 		var z model.Zone
-		if err := rows.Scan(&z.ID, &z.BrandID, &z.TenantID, &z.Name, &z.RegionID, &z.Status, &z.StatusMessage, &z.SuspendReason, &z.CreatedAt, &z.UpdatedAt); err != nil {
+		if err := rows.Scan(&z.ID, &z.BrandID, &z.TenantID, &z.Name, &z.RegionID, &z.Status, &z.StatusMessage, &z.SuspendReason, &z.DNSSECStatus, &z.CreatedAt, &z.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan zone row: %w", err)
 		}
 		zones = append(zones, z)
@@ -84,11 +84,12 @@ func (a *CoreDB) ListZonesByTenantID(ctx context.Context, tenantID string) ([]mo
 func (a *CoreDB) GetBrandByID(ctx context.Context, id string) (*model.Brand, error) {
 	var b model.Brand
 	err := a.db.QueryRow(ctx,
-		`SELECT id, name, base_hostname, primary_ns, secondary_ns, hostmaster_email, mail_hostname, spf_includes, dkim_selector, dkim_public_key, dmarc_policy, status, created_at, updated_at
+		`SELECT id, name, base_hostname, primary_ns, secondary_ns, hostmaster_email, mail_hostname, spf_includes, dkim_selector, dkim_public_key, dmarc_policy, dns_ttls, dns_migration_ttl, status, created_at, updated_at, password_min_length, password_classes, webroot_max_conns_per_ip, webroot_max_requests_per_sec
 		 FROM brands WHERE id = $1`, id,
 	).Scan(&b.ID, &b.Name, &b.BaseHostname, &b.PrimaryNS, &b.SecondaryNS,
 		&b.HostmasterEmail, &b.MailHostname, &b.SPFIncludes, &b.DKIMSelector,
-		&b.DKIMPublicKey, &b.DMARCPolicy, &b.Status, &b.CreatedAt, &b.UpdatedAt)
+		&b.DKIMPublicKey, &b.DMARCPolicy, &b.DNSTTLs, &b.DNSMigrationTTL, &b.Status, &b.CreatedAt, &b.UpdatedAt,
+		&b.PasswordMinLength, &b.PasswordClasses, &b.WebrootMaxConnsPerIP, &b.WebrootMaxRequestsPerSec)
 	if err != nil {
 		return nil, fmt.Errorf("get brand by id: %w", err)
 	}
@@ -112,9 +113,9 @@ func (a *CoreDB) GetTenantByID(ctx context.Context, id string) (*model.Tenant, e
 func (a *CoreDB) GetWebrootByID(ctx context.Context, id string) (*model.Webroot, error) {
 	var w model.Webroot
 	err := a.db.QueryRow(ctx,
-		`SELECT id, tenant_id, runtime, runtime_version, runtime_config, public_folder, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, runtime, runtime_version, runtime_config, public_folder, error_pages, basic_auth, status, status_message, suspend_reason, created_at, updated_at
 		 FROM webroots WHERE id = $1`, id,
-	).Scan(&w.ID, &w.TenantID, &w.Runtime, &w.RuntimeVersion, &w.RuntimeConfig, &w.PublicFolder, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt)
+	).Scan(&w.ID, &w.TenantID, &w.Runtime, &w.RuntimeVersion, &w.RuntimeConfig, &w.PublicFolder, &w.ErrorPages, &w.BasicAuth, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get webroot by id: %w", err)
 	}
@@ -125,9 +126,9 @@ func (a *CoreDB) GetWebrootByID(ctx context.Context, id string) (*model.Webroot,
 func (a *CoreDB) GetFQDNByID(ctx context.Context, id string) (*model.FQDN, error) {
 	var f model.FQDN
 	err := a.db.QueryRow(ctx,
-		`SELECT id, fqdn, webroot_id, ssl_enabled, status, status_message, created_at, updated_at
+		`SELECT id, fqdn, webroot_id, ssl_enabled, force_https, redirect_target, redirect_code, redirect_preserve_path, status, status_message, created_at, updated_at
 		 FROM fqdns WHERE id = $1`, id,
-	).Scan(&f.ID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.Status, &f.StatusMessage, &f.CreatedAt, &f.UpdatedAt)
+	).Scan(&f.ID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.ForceHTTPS, &f.RedirectTarget, &f.RedirectCode, &f.RedirectPreservePath, &f.Status, &f.StatusMessage, &f.CreatedAt, &f.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get fqdn by id: %w", err)
 	}
@@ -138,9 +139,9 @@ func (a *CoreDB) GetFQDNByID(ctx context.Context, id string) (*model.FQDN, error
 func (a *CoreDB) GetZoneByID(ctx context.Context, id string) (*model.Zone, error) {
 	var z model.Zone
 	err := a.db.QueryRow(ctx,
-		`SELECT id, brand_id, tenant_id, name, region_id, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, brand_id, tenant_id, name, region_id, status, status_message, suspend_reason, dnssec_status, secondary_region_ids, created_at, updated_at
 		 FROM zones WHERE id = $1`, id,
-	).Scan(&z.ID, &z.BrandID, &z.TenantID, &z.Name, &z.RegionID, &z.Status, &z.StatusMessage, &z.SuspendReason, &z.CreatedAt, &z.UpdatedAt)
+	).Scan(&z.ID, &z.BrandID, &z.TenantID, &z.Name, &z.RegionID, &z.Status, &z.StatusMessage, &z.SuspendReason, &z.DNSSECStatus, &z.SecondaryRegionIDs, &z.CreatedAt, &z.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get zone by id: %w", err)
 	}
@@ -151,9 +152,9 @@ func (a *CoreDB) GetZoneByID(ctx context.Context, id string) (*model.Zone, error
 func (a *CoreDB) GetZoneByName(ctx context.Context, name string) (*model.Zone, error) {
 	var z model.Zone
 	err := a.db.QueryRow(ctx,
-		`SELECT id, brand_id, tenant_id, name, region_id, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, brand_id, tenant_id, name, region_id, status, status_message, suspend_reason, dnssec_status, created_at, updated_at
 		 FROM zones WHERE name = $1 AND status = $2`, name, model.StatusActive,
-	).Scan(&z.ID, &z.BrandID, &z.TenantID, &z.Name, &z.RegionID, &z.Status, &z.StatusMessage, &z.SuspendReason, &z.CreatedAt, &z.UpdatedAt)
+	).Scan(&z.ID, &z.BrandID, &z.TenantID, &z.Name, &z.RegionID, &z.Status, &z.StatusMessage, &z.SuspendReason, &z.DNSSECStatus, &z.CreatedAt, &z.UpdatedAt)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, nil
@@ -180,9 +181,9 @@ func (a *CoreDB) GetZoneRecordByID(ctx context.Context, id string) (*model.ZoneR
 func (a *CoreDB) GetDatabaseByID(ctx context.Context, id string) (*model.Database, error) {
 	var d model.Database
 	err := a.db.QueryRow(ctx,
-		`SELECT id, tenant_id, shard_id, node_id, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, shard_id, node_id, status, status_message, suspend_reason, created_at, updated_at, charset, collation
 		 FROM databases WHERE id = $1`, id,
-	).Scan(&d.ID, &d.TenantID, &d.ShardID, &d.NodeID, &d.Status, &d.StatusMessage, &d.SuspendReason, &d.CreatedAt, &d.UpdatedAt)
+	).Scan(&d.ID, &d.TenantID, &d.ShardID, &d.NodeID, &d.Status, &d.StatusMessage, &d.SuspendReason, &d.CreatedAt, &d.UpdatedAt, &d.Charset, &d.Collation)
 	if err != nil {
 		return nil, fmt.Errorf("get database by id: %w", err)
 	}
@@ -286,7 +287,7 @@ func (a *CoreDB) GetNodesByClusterAndRole(ctx context.Context, clusterID string,
 // GetFQDNsByWebrootID retrieves all FQDNs bound to a webroot.
 func (a *CoreDB) GetFQDNsByWebrootID(ctx context.Context, webrootID string) ([]model.FQDN, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, fqdn, webroot_id, ssl_enabled, status, status_message, created_at, updated_at
+		`SELECT id, fqdn, webroot_id, ssl_enabled, force_https, redirect_target, redirect_code, redirect_preserve_path, status, status_message, created_at, updated_at
 		 FROM fqdns WHERE webroot_id = $1`, webrootID,
 	)
 	if err != nil {
@@ -297,7 +298,7 @@ func (a *CoreDB) GetFQDNsByWebrootID(ctx context.Context, webrootID string) ([]m
 	var fqdns []model.FQDN
 	for rows.Next() {
 		var f model.FQDN
-		if err := rows.Scan(&f.ID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.Status, &f.StatusMessage, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		if err := rows.Scan(&f.ID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.ForceHTTPS, &f.RedirectTarget, &f.RedirectCode, &f.RedirectPreservePath, &f.Status, &f.StatusMessage, &f.CreatedAt, &f.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan fqdn row: %w", err)
 		}
 		fqdns = append(fqdns, f)
@@ -435,7 +436,7 @@ func (a *CoreDB) UpdateValkeyInstanceShardID(ctx context.Context, instanceID str
 // ListWebrootsByTenantID retrieves all webroots for a tenant.
 func (a *CoreDB) ListWebrootsByTenantID(ctx context.Context, tenantID string) ([]model.Webroot, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, runtime, runtime_version, runtime_config, public_folder, env_file_name, service_hostname_enabled, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, runtime, runtime_version, runtime_config, public_folder, error_pages, basic_auth, env_file_name, service_hostname_enabled, access_log_enabled, connection_limits, static_rules, geo_blocking, deploy_locked_until, status, status_message, suspend_reason, created_at, updated_at
 		 FROM webroots WHERE tenant_id = $1`, tenantID,
 	)
 	if err != nil {
@@ -446,7 +447,7 @@ func (a *CoreDB) ListWebrootsByTenantID(ctx context.Context, tenantID string) ([
 	var webroots []model.Webroot
 	for rows.Next() {
 		var w model.Webroot
-		if err := rows.Scan(&w.ID, &w.TenantID, &w.Runtime, &w.RuntimeVersion, &w.RuntimeConfig, &w.PublicFolder, &w.EnvFileName, &w.ServiceHostnameEnabled, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		if err := rows.Scan(&w.ID, &w.TenantID, &w.Runtime, &w.RuntimeVersion, &w.RuntimeConfig, &w.PublicFolder, &w.ErrorPages, &w.BasicAuth, &w.EnvFileName, &w.ServiceHostnameEnabled, &w.AccessLogEnabled, &w.ConnectionLimits, &w.StaticRules, &w.GeoBlocking, &w.DeployLockedUntil, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan webroot row: %w", err)
 		}
 		webroots = append(webroots, w)
@@ -457,7 +458,7 @@ func (a *CoreDB) ListWebrootsByTenantID(ctx context.Context, tenantID string) ([
 // ListDatabasesByTenantID retrieves all databases for a tenant.
 func (a *CoreDB) ListDatabasesByTenantID(ctx context.Context, tenantID string) ([]model.Database, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, shard_id, node_id, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, shard_id, node_id, status, status_message, suspend_reason, created_at, updated_at, charset, collation
 		 FROM databases WHERE tenant_id = $1`, tenantID,
 	)
 	if err != nil {
@@ -468,7 +469,7 @@ func (a *CoreDB) ListDatabasesByTenantID(ctx context.Context, tenantID string) (
 	var databases []model.Database
 	for rows.Next() {
 		var d model.Database
-		if err := rows.Scan(&d.ID, &d.TenantID, &d.ShardID, &d.NodeID, &d.Status, &d.StatusMessage, &d.SuspendReason, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		if err := rows.Scan(&d.ID, &d.TenantID, &d.ShardID, &d.NodeID, &d.Status, &d.StatusMessage, &d.SuspendReason, &d.CreatedAt, &d.UpdatedAt, &d.Charset, &d.Collation); err != nil {
 			return nil, fmt.Errorf("scan database row: %w", err)
 		}
 		databases = append(databases, d)
@@ -480,9 +481,9 @@ func (a *CoreDB) ListDatabasesByTenantID(ctx context.Context, tenantID string) (
 func (a *CoreDB) GetValkeyInstanceByID(ctx context.Context, id string) (*model.ValkeyInstance, error) {
 	var v model.ValkeyInstance
 	err := a.db.QueryRow(ctx,
-		`SELECT id, tenant_id, shard_id, port, max_memory_mb, password_hash, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, shard_id, port, max_memory_mb, persistence_mode, password_hash, status, status_message, suspend_reason, created_at, updated_at
 		 FROM valkey_instances WHERE id = $1`, id,
-	).Scan(&v.ID, &v.TenantID, &v.ShardID, &v.Port, &v.MaxMemoryMB,
+	).Scan(&v.ID, &v.TenantID, &v.ShardID, &v.Port, &v.MaxMemoryMB, &v.PersistenceMode,
 		&v.PasswordHash, &v.Status, &v.StatusMessage, &v.SuspendReason, &v.CreatedAt, &v.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get valkey instance by id: %w", err)
@@ -508,9 +509,9 @@ func (a *CoreDB) GetValkeyUserByID(ctx context.Context, id string) (*model.Valke
 func (a *CoreDB) GetEmailAccountByID(ctx context.Context, id string) (*model.EmailAccount, error) {
 	var acct model.EmailAccount
 	err := a.db.QueryRow(ctx,
-		`SELECT id, fqdn_id, address, display_name, quota_bytes, status, status_message, created_at, updated_at
+		`SELECT id, fqdn_id, address, display_name, quota_bytes, status, status_message, created_at, updated_at, password_hash
 		 FROM email_accounts WHERE id = $1`, id,
-	).Scan(&acct.ID, &acct.FQDNID, &acct.Address, &acct.DisplayName, &acct.QuotaBytes, &acct.Status, &acct.StatusMessage, &acct.CreatedAt, &acct.UpdatedAt)
+	).Scan(&acct.ID, &acct.FQDNID, &acct.Address, &acct.DisplayName, &acct.QuotaBytes, &acct.Status, &acct.StatusMessage, &acct.CreatedAt, &acct.UpdatedAt, &acct.PasswordHash)
 	if err != nil {
 		return nil, fmt.Errorf("get email account by id: %w", err)
 	}
@@ -552,7 +553,7 @@ func (a *CoreDB) CreateNode(ctx context.Context, n *model.Node) error {
 // ListDatabasesByShard retrieves all databases assigned to a shard (excluding deleted).
 func (a *CoreDB) ListDatabasesByShard(ctx context.Context, shardID string) ([]model.Database, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, shard_id, node_id, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, shard_id, node_id, status, status_message, suspend_reason, created_at, updated_at, charset, collation
 		 FROM databases WHERE shard_id = $1 ORDER BY id`, shardID,
 	)
 	if err != nil {
@@ -563,7 +564,7 @@ func (a *CoreDB) ListDatabasesByShard(ctx context.Context, shardID string) ([]mo
 	var databases []model.Database
 	for rows.Next() {
 		var d model.Database
-		if err := rows.Scan(&d.ID, &d.TenantID, &d.ShardID, &d.NodeID, &d.Status, &d.StatusMessage, &d.SuspendReason, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		if err := rows.Scan(&d.ID, &d.TenantID, &d.ShardID, &d.NodeID, &d.Status, &d.StatusMessage, &d.SuspendReason, &d.CreatedAt, &d.UpdatedAt, &d.Charset, &d.Collation); err != nil {
 			return nil, fmt.Errorf("scan database row: %w", err)
 		}
 		databases = append(databases, d)
@@ -574,7 +575,7 @@ func (a *CoreDB) ListDatabasesByShard(ctx context.Context, shardID string) ([]mo
 // ListValkeyInstancesByShard retrieves all valkey instances assigned to a shard (excluding deleted).
 func (a *CoreDB) ListValkeyInstancesByShard(ctx context.Context, shardID string) ([]model.ValkeyInstance, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, shard_id, port, max_memory_mb, password_hash, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, shard_id, port, max_memory_mb, persistence_mode, password_hash, status, status_message, suspend_reason, created_at, updated_at
 		 FROM valkey_instances WHERE shard_id = $1 ORDER BY id`, shardID,
 	)
 	if err != nil {
@@ -585,7 +586,7 @@ func (a *CoreDB) ListValkeyInstancesByShard(ctx context.Context, shardID string)
 	var instances []model.ValkeyInstance
 	for rows.Next() {
 		var v model.ValkeyInstance
-		if err := rows.Scan(&v.ID, &v.TenantID, &v.ShardID, &v.Port, &v.MaxMemoryMB, &v.PasswordHash, &v.Status, &v.StatusMessage, &v.SuspendReason, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		if err := rows.Scan(&v.ID, &v.TenantID, &v.ShardID, &v.Port, &v.MaxMemoryMB, &v.PersistenceMode, &v.PasswordHash, &v.Status, &v.StatusMessage, &v.SuspendReason, &v.CreatedAt, &v.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan valkey instance row: %w", err)
 		}
 		instances = append(instances, v)
@@ -689,29 +690,65 @@ func (a *CoreDB) GetEmailAutoReplyByID(ctx context.Context, id string) (*model.E
 	return &ar, nil
 }
 
-// GetExpiringLECerts returns Let's Encrypt certificates expiring within the given number of days.
-func (a *CoreDB) GetExpiringLECerts(ctx context.Context, daysBeforeExpiry int) ([]model.Certificate, error) {
+// GetLECertsDueForRenewalParams selects the Let's Encrypt certificates to
+// renew on one run of the renewal cron.
+type GetLECertsDueForRenewalParams struct {
+	DaysBeforeExpiry int
+	UrgentDays       int
+	DefaultHourUTC   int
+	Now              time.Time
+}
+
+// GetLECertsDueForRenewal returns Let's Encrypt certificates expiring within
+// DaysBeforeExpiry days that are due on this run. A certificate whose tenant
+// has a maintenance window is due while Now falls inside the window. Others,
+// and any certificate expiring within UrgentDays, are due during hour
+// DefaultHourUTC.
+func (a *CoreDB) GetLECertsDueForRenewal(ctx context.Context, params GetLECertsDueForRenewalParams) ([]model.Certificate, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, fqdn_id, type, cert_pem, key_pem, chain_pem, issued_at, expires_at, status, status_message, is_active, created_at, updated_at
-		 FROM certificates
-		 WHERE type = $1 AND status = $2 AND is_active = true
-		   AND expires_at <= now() + make_interval(days => $3)
-		 ORDER BY expires_at ASC`,
-		model.CertTypeLetsEncrypt, model.StatusActive, daysBeforeExpiry,
+		`SELECT c.id, c.fqdn_id, c.type, c.cert_pem, c.key_pem, c.chain_pem, c.issued_at, c.expires_at, c.status, c.status_message, c.is_active, c.created_at, c.updated_at,
+		        mw.start_time, mw.duration_minutes, mw.timezone
+		 FROM certificates c
+		 JOIN fqdns f ON f.id = c.fqdn_id
+		 LEFT JOIN tenant_maintenance_windows mw ON mw.tenant_id = f.tenant_id
+		 WHERE c.type = $1 AND c.status = $2 AND c.is_active = true
+		   AND c.expires_at <= now() + make_interval(days => $3)
+		 ORDER BY c.expires_at ASC`,
+		model.CertTypeLetsEncrypt, model.StatusActive, params.DaysBeforeExpiry,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("get expiring LE certs: %w", err)
+		return nil, fmt.Errorf("get LE certs due for renewal: %w", err)
 	}
 	defer rows.Close()
 
+	defaultRun := params.Now.UTC().Hour() == params.DefaultHourUTC
+	urgentBefore := params.Now.AddDate(0, 0, params.UrgentDays)
+
 	var certs []model.Certificate
 	for rows.Next() {
 		var c model.Certificate
+		var startTime, timezone *string
+		var durationMinutes *int
 		if err := rows.Scan(&c.ID, &c.FQDNID, &c.Type, &c.CertPEM, &c.KeyPEM, &c.ChainPEM,
-			&c.IssuedAt, &c.ExpiresAt, &c.Status, &c.StatusMessage, &c.IsActive, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			&c.IssuedAt, &c.ExpiresAt, &c.Status, &c.StatusMessage, &c.IsActive, &c.CreatedAt, &c.UpdatedAt,
+			&startTime, &durationMinutes, &timezone); err != nil {
 			return nil, fmt.Errorf("scan expiring cert: %w", err)
 		}
-		certs = append(certs, c)
+
+		urgent := c.ExpiresAt != nil && c.ExpiresAt.Before(urgentBefore)
+		due := defaultRun && (urgent || startTime == nil)
+		if !due && startTime != nil {
+			window := model.TenantMaintenanceWindow{StartTime: *startTime, DurationMinutes: *durationMinutes, Timezone: *timezone}
+			inWindow, err := window.Contains(params.Now)
+			if err != nil {
+				// Windows are validated on write; fall back to the default hour.
+				inWindow = defaultRun
+			}
+			due = inWindow
+		}
+		if due {
+			certs = append(certs, c)
+		}
 	}
 	return certs, rows.Err()
 }
@@ -830,6 +867,30 @@ func (a *CoreDB) UpdateBackupResult(ctx context.Context, params UpdateBackupResu
 	return nil
 }
 
+// UpdateBackupVerificationParams holds the parameters for UpdateBackupVerification.
+type UpdateBackupVerificationParams struct {
+	ID         string
+	Status     string // model.BackupVerifyPassed or model.BackupVerifyFailed
+	Message    string
+	VerifiedAt time.Time
+}
+
+// UpdateBackupVerification records the outcome of a backup restore test.
+func (a *CoreDB) UpdateBackupVerification(ctx context.Context, params UpdateBackupVerificationParams) error {
+	var msg *string
+	if params.Message != "" {
+		msg = &params.Message
+	}
+	_, err := a.db.Exec(ctx,
+		`UPDATE backups SET verify_status = $1, verify_message = $2, verified_at = $3, updated_at = now() WHERE id = $4`,
+		params.Status, msg, params.VerifiedAt, params.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update backup verification: %w", err)
+	}
+	return nil
+}
+
 // DeleteOldAuditLogs deletes audit log entries older than the specified number of days
 // and returns the count of deleted rows.
 func (a *CoreDB) DeleteOldAuditLogs(ctx context.Context, retentionDays int) (int64, error) {
@@ -845,10 +906,10 @@ func (a *CoreDB) DeleteOldAuditLogs(ctx context.Context, retentionDays int) (int
 func (a *CoreDB) GetS3BucketByID(ctx context.Context, id string) (*model.S3Bucket, error) {
 	var b model.S3Bucket
 	err := a.db.QueryRow(ctx,
-		`SELECT id, tenant_id, shard_id, public, quota_bytes, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, shard_id, public, quota_bytes, status, status_message, suspend_reason, created_at, updated_at, lifecycle_rules
 		 FROM s3_buckets WHERE id = $1`, id,
 	).Scan(&b.ID, &b.TenantID, &b.ShardID,
-		&b.Public, &b.QuotaBytes, &b.Status, &b.StatusMessage, &b.SuspendReason, &b.CreatedAt, &b.UpdatedAt)
+		&b.Public, &b.QuotaBytes, &b.Status, &b.StatusMessage, &b.SuspendReason, &b.CreatedAt, &b.UpdatedAt, &b.LifecycleRules)
 	if err != nil {
 		return nil, fmt.Errorf("get s3 bucket by id: %w", err)
 	}
@@ -870,32 +931,58 @@ func (a *CoreDB) GetS3AccessKeyByID(ctx context.Context, id string) (*model.S3Ac
 }
 
 // FQDNMapping represents an active FQDN-to-shard-backend mapping for LB convergence.
+// SplitBackend and SplitWeight are set when the tenant has a traffic split.
 type FQDNMapping struct {
-	FQDN      string `json:"fqdn"`
-	LBBackend string `json:"lb_backend"`
+	FQDN         string `json:"fqdn"`
+	LBBackend    string `json:"lb_backend"`
+	SplitBackend string `json:"split_backend,omitempty"`
+	SplitWeight  int    `json:"split_weight,omitempty"`
 }
 
-// ListActiveFQDNMappings returns all active FQDN-to-shard-backend mappings for a cluster.
-// Used by LB shard convergence to populate the HAProxy map on LB nodes.
-func (a *CoreDB) ListActiveFQDNMappings(ctx context.Context, clusterID string) ([]FQDNMapping, error) {
-	rows, err := a.db.Query(ctx,
-		`SELECT f.fqdn, s.lb_backend
+// fqdnMappingQuery selects active FQDN mappings with the tenant's traffic
+// split. A split pointing at the tenant's own shard (left behind by a
+// migration) is ignored.
+const fqdnMappingQuery = `SELECT f.fqdn, s.lb_backend, COALESCE(ss.lb_backend, ''), COALESCE(ls.weight, 0)
 		 FROM fqdns f
 		 JOIN webroots w ON w.id = f.webroot_id
 		 JOIN tenants t ON t.id = w.tenant_id
 		 JOIN shards s ON s.id = t.shard_id
+		 LEFT JOIN tenant_lb_splits ls ON ls.tenant_id = t.id AND ls.shard_id <> t.shard_id
+		 LEFT JOIN shards ss ON ss.id = ls.shard_id`
+
+// ListActiveFQDNMappings returns all active FQDN-to-shard-backend mappings for a cluster.
+// Used by LB shard convergence to populate the HAProxy map on LB nodes.
+func (a *CoreDB) ListActiveFQDNMappings(ctx context.Context, clusterID string) ([]FQDNMapping, error) {
+	rows, err := a.db.Query(ctx, fqdnMappingQuery+`
 		 WHERE t.cluster_id = $1 AND f.status = $2`,
 		clusterID, model.StatusActive,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("list active fqdn mappings: %w", err)
 	}
+	return scanFQDNMappings(rows)
+}
+
+// ListTenantFQDNMappings returns the active FQDN-to-shard-backend mappings of
+// one tenant. Used to apply a change to the tenant's traffic split.
+func (a *CoreDB) ListTenantFQDNMappings(ctx context.Context, tenantID string) ([]FQDNMapping, error) {
+	rows, err := a.db.Query(ctx, fqdnMappingQuery+`
+		 WHERE t.id = $1 AND f.status = $2`,
+		tenantID, model.StatusActive,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list tenant fqdn mappings: %w", err)
+	}
+	return scanFQDNMappings(rows)
+}
+
+func scanFQDNMappings(rows pgx.Rows) ([]FQDNMapping, error) {
 	defer rows.Close()
 
 	var mappings []FQDNMapping
 	for rows.Next() {
 		var m FQDNMapping
-		if err := rows.Scan(&m.FQDN, &m.LBBackend); err != nil {
+		if err := rows.Scan(&m.FQDN, &m.LBBackend, &m.SplitBackend, &m.SplitWeight); err != nil {
 			return nil, fmt.Errorf("scan fqdn mapping: %w", err)
 		}
 		mappings = append(mappings, m)
@@ -903,14 +990,38 @@ func (a *CoreDB) ListActiveFQDNMappings(ctx context.Context, clusterID string) (
 	return mappings, rows.Err()
 }
 
-// GetOldBackups returns active backups that are older than the specified number of days.
+// getTenantLBSplit returns the LB backend and weight of the tenant's traffic
+// split, or an empty backend if it has none.
+func (a *CoreDB) getTenantLBSplit(ctx context.Context, tenantID string) (string, int, error) {
+	var backend string
+	var weight int
+	err := a.db.QueryRow(ctx,
+		`SELECT ss.lb_backend, ls.weight
+		 FROM tenant_lb_splits ls
+		 JOIN tenants t ON t.id = ls.tenant_id
+		 JOIN shards ss ON ss.id = ls.shard_id
+		 WHERE ls.tenant_id = $1 AND ls.shard_id <> t.shard_id`, tenantID,
+	).Scan(&backend, &weight)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", 0, nil
+	}
+	if err != nil {
+		return "", 0, fmt.Errorf("get lb split for tenant %s: %w", tenantID, err)
+	}
+	return backend, weight, nil
+}
+
+// GetOldBackups returns active backups that are older than the specified
+// number of days, or than the retention of the tenant's enabled backup
+// schedule if it has one.
 func (a *CoreDB) GetOldBackups(ctx context.Context, retentionDays int) ([]model.Backup, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, type, source_id, source_name, storage_path, size_bytes, status, status_message, started_at, completed_at, created_at, updated_at
-		 FROM backups
-		 WHERE status = $1
-		   AND created_at < now() - make_interval(days => $2)
-		 ORDER BY created_at ASC`,
+		`SELECT b.id, b.tenant_id, b.type, b.source_id, b.source_name, b.storage_path, b.size_bytes, b.status, b.status_message, b.started_at, b.completed_at, b.created_at, b.updated_at
+		 FROM backups b
+		 LEFT JOIN backup_schedules bs ON bs.tenant_id = b.tenant_id AND bs.enabled
+		 WHERE b.status = $1
+		   AND b.created_at < now() - make_interval(days => COALESCE(bs.retention_days, $2))
+		 ORDER BY b.created_at ASC`,
 		model.StatusActive, retentionDays,
 	)
 	if err != nil {
@@ -931,10 +1042,55 @@ func (a *CoreDB) GetOldBackups(ctx context.Context, retentionDays int) ([]model.
 	return backups, rows.Err()
 }
 
+// GetEmailDKIMKeyByFQDN retrieves an FQDN's DKIM key without the private key.
+func (a *CoreDB) GetEmailDKIMKeyByFQDN(ctx context.Context, fqdnID string) (*model.EmailDKIMKey, error) {
+	var k model.EmailDKIMKey
+	err := a.db.QueryRow(ctx,
+		`SELECT id, fqdn_id, selector, public_key, status, status_message, created_at, updated_at
+		 FROM fqdn_dkim_keys WHERE fqdn_id = $1`, fqdnID,
+	).Scan(&k.ID, &k.FQDNID, &k.Selector, &k.PublicKey, &k.Status, &k.StatusMessage, &k.CreatedAt, &k.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get dkim key by fqdn: %w", err)
+	}
+	return &k, nil
+}
+
+// GetBackupsToVerify returns a random sample of up to limit active backups
+// completed within the last maxAgeDays that have not been verified yet.
+func (a *CoreDB) GetBackupsToVerify(ctx context.Context, limit, maxAgeDays int) ([]model.Backup, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT id, tenant_id, type, source_id, source_name, storage_path, size_bytes, status, status_message, started_at, completed_at, created_at, updated_at
+		 FROM backups
+		 WHERE status = $1
+		   AND storage_path <> ''
+		   AND verified_at IS NULL
+		   AND completed_at > now() - make_interval(days => $2)
+		 ORDER BY random()
+		 LIMIT $3`,
+		model.StatusActive, maxAgeDays, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get backups to verify: %w", err)
+	}
+	defer rows.Close()
+
+	var backups []model.Backup
+	for rows.Next() {
+		var b model.Backup
+		if err := rows.Scan(&b.ID, &b.TenantID, &b.Type, &b.SourceID, &b.SourceName,
+			&b.StoragePath, &b.SizeBytes, &b.Status, &b.StatusMessage, &b.StartedAt,
+			&b.CompletedAt, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan backup to verify: %w", err)
+		}
+		backups = append(backups, b)
+	}
+	return backups, rows.Err()
+}
+
 // ListCronJobsByWebroot retrieves all cron jobs for a webroot (excluding deleted).
 func (a *CoreDB) ListCronJobsByWebroot(ctx context.Context, webrootID string) ([]model.CronJob, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, status, status_message, created_at, updated_at
+		`SELECT id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, timezone, status, status_message, created_at, updated_at
 		 FROM cron_jobs WHERE webroot_id = $1 ORDER BY id`, webrootID,
 	)
 	if err != nil {
@@ -945,7 +1101,7 @@ func (a *CoreDB) ListCronJobsByWebroot(ctx context.Context, webrootID string) ([
 	var jobs []model.CronJob
 	for rows.Next() {
 		var j model.CronJob
-		if err := rows.Scan(&j.ID, &j.TenantID, &j.WebrootID, &j.Schedule, &j.Command, &j.WorkingDirectory, &j.Enabled, &j.TimeoutSeconds, &j.MaxMemoryMB, &j.Status, &j.StatusMessage, &j.CreatedAt, &j.UpdatedAt); err != nil {
+		if err := rows.Scan(&j.ID, &j.TenantID, &j.WebrootID, &j.Schedule, &j.Command, &j.WorkingDirectory, &j.Enabled, &j.TimeoutSeconds, &j.MaxMemoryMB, &j.Timezone, &j.Status, &j.StatusMessage, &j.CreatedAt, &j.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan cron job row: %w", err)
 		}
 		jobs = append(jobs, j)
@@ -975,6 +1131,28 @@ func (a *CoreDB) ListShardsByRole(ctx context.Context, role string) ([]model.Sha
 	return shards, rows.Err()
 }
 
+// ListShardsByCluster retrieves all shards for a cluster, ordered by name.
+func (a *CoreDB) ListShardsByCluster(ctx context.Context, clusterID string) ([]model.Shard, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT id, cluster_id, name, role, lb_backend, config, status, status_message, created_at, updated_at
+		 FROM shards WHERE cluster_id = $1 ORDER BY name`, clusterID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list shards by cluster: %w", err)
+	}
+	defer rows.Close()
+
+	var shards []model.Shard
+	for rows.Next() {
+		var s model.Shard
+		if err := rows.Scan(&s.ID, &s.ClusterID, &s.Name, &s.Role, &s.LBBackend, &s.Config, &s.Status, &s.StatusMessage, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan shard row: %w", err)
+		}
+		shards = append(shards, s)
+	}
+	return shards, rows.Err()
+}
+
 // ListShardsByClusterAndRole retrieves all shards for a cluster with the given role.
 func (a *CoreDB) ListShardsByClusterAndRole(ctx context.Context, clusterID, role string) ([]model.Shard, error) {
 	rows, err := a.db.Query(ctx,
@@ -1222,7 +1400,7 @@ func (a *CoreDB) ListDaemonsByWebrootID(ctx context.Context, webrootID string) (
 // ListCronJobsByWebrootID retrieves all cron jobs for a webroot.
 func (a *CoreDB) ListCronJobsByWebrootID(ctx context.Context, webrootID string) ([]model.CronJob, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, consecutive_failures, max_failures, status, status_message, created_at, updated_at
+		`SELECT id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, timezone, consecutive_failures, max_failures, status, status_message, created_at, updated_at
 		 FROM cron_jobs WHERE webroot_id = $1`, webrootID,
 	)
 	if err != nil {
@@ -1233,7 +1411,7 @@ func (a *CoreDB) ListCronJobsByWebrootID(ctx context.Context, webrootID string)
 	var jobs []model.CronJob
 	for rows.Next() {
 		var c model.CronJob
-		if err := rows.Scan(&c.ID, &c.TenantID, &c.WebrootID, &c.Schedule, &c.Command, &c.WorkingDirectory, &c.Enabled, &c.TimeoutSeconds, &c.MaxMemoryMB, &c.ConsecutiveFailures, &c.MaxFailures, &c.Status, &c.StatusMessage, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		if err := rows.Scan(&c.ID, &c.TenantID, &c.WebrootID, &c.Schedule, &c.Command, &c.WorkingDirectory, &c.Enabled, &c.TimeoutSeconds, &c.MaxMemoryMB, &c.Timezone, &c.ConsecutiveFailures, &c.MaxFailures, &c.Status, &c.StatusMessage, &c.CreatedAt, &c.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan cron job row: %w", err)
 		}
 		jobs = append(jobs, c)
@@ -1244,7 +1422,7 @@ func (a *CoreDB) ListCronJobsByWebrootID(ctx context.Context, webrootID string)
 // ListFQDNsByWebrootID retrieves all FQDNs for a webroot.
 func (a *CoreDB) ListFQDNsByWebrootID(ctx context.Context, webrootID string) ([]model.FQDN, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT id, fqdn, webroot_id, ssl_enabled, status, status_message, created_at, updated_at
+		`SELECT id, fqdn, webroot_id, ssl_enabled, force_https, redirect_target, redirect_code, redirect_preserve_path, status, status_message, created_at, updated_at
 		 FROM fqdns WHERE webroot_id = $1`, webrootID,
 	)
 	if err != nil {
@@ -1255,7 +1433,7 @@ func (a *CoreDB) ListFQDNsByWebrootID(ctx context.Context, webrootID string) ([]
 	var fqdns []model.FQDN
 	for rows.Next() {
 		var f model.FQDN
-		if err := rows.Scan(&f.ID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.Status, &f.StatusMessage, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		if err := rows.Scan(&f.ID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.ForceHTTPS, &f.RedirectTarget, &f.RedirectCode, &f.RedirectPreservePath, &f.Status, &f.StatusMessage, &f.CreatedAt, &f.UpdatedAt); err != nil {
 			return nil, fmt.Errorf("scan fqdn row: %w", err)
 		}
 		fqdns = append(fqdns, f)
@@ -1458,12 +1636,16 @@ func (a *CoreDB) GetExpiringCerts(ctx context.Context, days int) ([]ExpiringCert
 
 // UpsertResourceUsageParams holds parameters for upserting a resource usage row.
 type UpsertResourceUsageParams struct {
-	ResourceType string `json:"resource_type"` // "webroot" or "database"
-	Name         string `json:"name"`          // "tenant_name/webroot_name" or "db_name"
+	ResourceType string `json:"resource_type"` // "webroot", "database" or "valkey"
+	Name         string `json:"name"`          // "tenant_name/webroot_name", "db_name" or "instance_name"
 	BytesUsed    int64  `json:"bytes_used"`
+	KeyCount     *int64 `json:"key_count,omitempty"` // valkey only
+	Unavailable  bool   `json:"unavailable,omitempty"`
 }
 
 // UpsertResourceUsage resolves a resource name to its ID and upserts a resource_usage row.
+// Unavailable samples only flip the availability flag so the last known
+// usage figures are kept.
 func (a *CoreDB) UpsertResourceUsage(ctx context.Context, params UpsertResourceUsageParams) error {
 	var resourceID, tenantID string
 
@@ -1491,15 +1673,26 @@ func (a *CoreDB) UpsertResourceUsage(ctx context.Context, params UpsertResourceU
 			return nil // skip unknown databases
 		}
 
+	case "valkey":
+		err := a.db.QueryRow(ctx,
+			`SELECT id, tenant_id FROM valkey_instances WHERE id = $1`, params.Name,
+		).Scan(&resourceID, &tenantID)
+		if err != nil {
+			return nil // skip unknown valkey instances
+		}
+
 	default:
 		return fmt.Errorf("unsupported resource type: %s", params.ResourceType)
 	}
 
 	_, err := a.db.Exec(ctx,
-		`INSERT INTO resource_usage (id, resource_type, resource_id, tenant_id, bytes_used, collected_at)
-		 VALUES ($1, $2, $3, $4, $5, now())
-		 ON CONFLICT (resource_type, resource_id) DO UPDATE SET bytes_used = $5, collected_at = now()`,
-		resourceID+"-usage", params.ResourceType, resourceID, tenantID, params.BytesUsed,
+		`INSERT INTO resource_usage (id, resource_type, resource_id, tenant_id, bytes_used, key_count, available, collected_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, now())
+		 ON CONFLICT (resource_type, resource_id) DO UPDATE SET
+		     bytes_used = CASE WHEN $7 THEN $5 ELSE resource_usage.bytes_used END,
+		     key_count = CASE WHEN $7 THEN $6 ELSE resource_usage.key_count END,
+		     available = $7, collected_at = now()`,
+		resourceID+"-usage", params.ResourceType, resourceID, tenantID, params.BytesUsed, params.KeyCount, !params.Unavailable,
 	)
 	return err
 }
@@ -1507,7 +1700,7 @@ func (a *CoreDB) UpsertResourceUsage(ctx context.Context, params UpsertResourceU
 // ListResourceUsageByTenantID retrieves all resource usage rows for a tenant.
 func (a *CoreDB) ListResourceUsageByTenantID(ctx context.Context, tenantID string) ([]model.ResourceUsage, error) {
 	rows, err := a.db.Query(ctx,
-		`SELECT ru.id, ru.resource_type, ru.resource_id, ru.tenant_id, ru.bytes_used, ru.collected_at
+		`SELECT ru.id, ru.resource_type, ru.resource_id, ru.tenant_id, ru.bytes_used, ru.key_count, ru.available, ru.collected_at
 		 FROM resource_usage ru WHERE ru.tenant_id = $1 ORDER BY ru.resource_type, ru.collected_at DESC`, tenantID,
 	)
 	if err != nil {
@@ -1518,7 +1711,7 @@ func (a *CoreDB) ListResourceUsageByTenantID(ctx context.Context, tenantID strin
 	var usages []model.ResourceUsage
 	for rows.Next() {
 		var u model.ResourceUsage
-		if err := rows.Scan(&u.ID, &u.ResourceType, &u.ResourceID, &u.TenantID, &u.BytesUsed, &u.CollectedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.ResourceType, &u.ResourceID, &u.TenantID, &u.BytesUsed, &u.KeyCount, &u.Available, &u.CollectedAt); err != nil {
 			return nil, fmt.Errorf("scan resource usage: %w", err)
 		}
 		usages = append(usages, u)
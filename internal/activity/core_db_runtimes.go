@@ -0,0 +1,73 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// GetCachedNodeRuntimes returns the last runtime report of each of the given
+// nodes. Nodes that have never reported are omitted.
+func (a *CoreDB) GetCachedNodeRuntimes(ctx context.Context, nodeIDs []string) ([]model.NodeRuntimes, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT node_id, runtime, version, extensions, reported_at
+		 FROM node_runtimes
+		 WHERE node_id = ANY($1)
+		 ORDER BY node_id, runtime, version`, nodeIDs,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get cached node runtimes: %w", err)
+	}
+	defer rows.Close()
+
+	var result []model.NodeRuntimes
+	for rows.Next() {
+		var nodeID string
+		var rt model.InstalledRuntime
+		var nr model.NodeRuntimes
+		if err := rows.Scan(&nodeID, &rt.Runtime, &rt.Version, &rt.Extensions, &nr.ReportedAt); err != nil {
+			return nil, fmt.Errorf("scan node runtime row: %w", err)
+		}
+		if n := len(result); n > 0 && result[n-1].NodeID == nodeID {
+			result[n-1].Runtimes = append(result[n-1].Runtimes, rt)
+			continue
+		}
+		nr.NodeID = nodeID
+		nr.Runtimes = []model.InstalledRuntime{rt}
+		result = append(result, nr)
+	}
+	return result, rows.Err()
+}
+
+// SaveNodeRuntimes replaces the cached runtime report of a node.
+func (a *CoreDB) SaveNodeRuntimes(ctx context.Context, params SaveNodeRuntimesParams) error {
+	tx, err := a.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx, `DELETE FROM node_runtimes WHERE node_id = $1`, params.NodeID); err != nil {
+		return fmt.Errorf("clear node runtimes for %s: %w", params.NodeID, err)
+	}
+	for _, rt := range params.Runtimes {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO node_runtimes (node_id, runtime, version, extensions, reported_at) VALUES ($1, $2, $3, $4, now())
+			 ON CONFLICT DO NOTHING`,
+			params.NodeID, rt.Runtime, rt.Version, extensionsOrEmpty(rt.Extensions),
+		); err != nil {
+			return fmt.Errorf("insert node runtime %s %s for %s: %w", rt.Runtime, rt.Version, params.NodeID, err)
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// extensionsOrEmpty maps a nil slice to an empty one for the NOT NULL
+// extensions column.
+func extensionsOrEmpty(exts []string) []string {
+	if exts == nil {
+		return []string{}
+	}
+	return exts
+}
@@ -0,0 +1,65 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// GetSMTPRelayContext returns a relay user and the Stalwart instance of its
+// tenant's cluster. The password hash is not loaded.
+func (a *CoreDB) GetSMTPRelayContext(ctx context.Context, id string) (*SMTPRelayContext, error) {
+	var rc SMTPRelayContext
+	var clusterConfig []byte
+	u := &rc.RelayUser
+	err := a.db.QueryRow(ctx,
+		`SELECT u.id, u.tenant_id, u.username, u.max_messages_per_hour, u.status, u.status_message, u.created_at, u.updated_at, c.config
+		 FROM smtp_relay_users u
+		 JOIN tenants t ON t.id = u.tenant_id
+		 JOIN clusters c ON c.id = t.cluster_id
+		 WHERE u.id = $1`, id,
+	).Scan(&u.ID, &u.TenantID, &u.Username, &u.MaxMessagesPerHour, &u.Status, &u.StatusMessage,
+		&u.CreatedAt, &u.UpdatedAt, &clusterConfig)
+	if err != nil {
+		return nil, fmt.Errorf("get smtp relay context: %w", err)
+	}
+
+	var cfg struct {
+		StalwartURL   string `json:"stalwart_url"`
+		StalwartToken string `json:"stalwart_token"`
+	}
+	if err := json.Unmarshal(clusterConfig, &cfg); err != nil {
+		return nil, fmt.Errorf("parse cluster config for stalwart: %w", err)
+	}
+	if cfg.StalwartURL == "" {
+		return nil, fmt.Errorf("cluster of tenant %s has no stalwart_url configured", u.TenantID)
+	}
+	rc.StalwartURL = cfg.StalwartURL
+	rc.StalwartToken = cfg.StalwartToken
+	return &rc, nil
+}
+
+// ListSMTPRelayUsersByTenantID returns all relay users of a tenant.
+func (a *CoreDB) ListSMTPRelayUsersByTenantID(ctx context.Context, tenantID string) ([]model.SMTPRelayUser, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT id, tenant_id, username, max_messages_per_hour, status, status_message, created_at, updated_at
+		 FROM smtp_relay_users WHERE tenant_id = $1 ORDER BY id`, tenantID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list smtp relay users by tenant: %w", err)
+	}
+	defer rows.Close()
+
+	var users []model.SMTPRelayUser
+	for rows.Next() {
+		var u model.SMTPRelayUser
+		if err := rows.Scan(&u.ID, &u.TenantID, &u.Username, &u.MaxMessagesPerHour, &u.Status, &u.StatusMessage,
+			&u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan smtp relay user row: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,91 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ErrTypeTenantUIDRangeExhausted is the application error type returned when
+// a cluster has no free tenant UID left.
+const ErrTypeTenantUIDRangeExhausted = "TENANT_UID_RANGE_EXHAUSTED"
+
+// AllocateTenantUID assigns the tenant the lowest free UID in its cluster's
+// range and returns it. Gaps left by deleted tenants are reused. A tenant that
+// already has a UID keeps it, so the activity is safe to retry.
+//
+// Allocations in a cluster are serialized with a transaction-scoped advisory
+// lock, so concurrent CreateTenantWorkflow runs never pick the same UID; the
+// (cluster_id, uid) unique index backs this up.
+func (a *CoreDB) AllocateTenantUID(ctx context.Context, tenantID string) (int, error) {
+	tx, err := a.db.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var clusterID string
+	var rawConfig json.RawMessage
+	if err := tx.QueryRow(ctx,
+		`SELECT t.cluster_id, c.config FROM tenants t JOIN clusters c ON c.id = t.cluster_id WHERE t.id = $1`,
+		tenantID,
+	).Scan(&clusterID, &rawConfig); err != nil {
+		return 0, fmt.Errorf("get tenant %s cluster: %w", tenantID, err)
+	}
+
+	var lo, hi int
+	cfg, err := model.ParseClusterConfig(rawConfig)
+	if err == nil {
+		lo, hi, err = cfg.TenantUIDRange()
+	}
+	if err != nil {
+		return 0, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("cluster %s: %v", clusterID, err), "INVALID_CLUSTER_CONFIG", nil)
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('tenant_uid:' || $1))`, clusterID); err != nil {
+		return 0, fmt.Errorf("lock tenant uids for cluster %s: %w", clusterID, err)
+	}
+
+	// Re-read under the lock: an earlier attempt may have committed a UID.
+	var uid int
+	if err := tx.QueryRow(ctx, `SELECT uid FROM tenants WHERE id = $1 FOR UPDATE`, tenantID).Scan(&uid); err != nil {
+		return 0, fmt.Errorf("get tenant %s uid: %w", tenantID, err)
+	}
+	if uid != 0 {
+		return uid, nil
+	}
+
+	// The lowest free UID is either the start of the range or one past a
+	// used UID whose successor is free.
+	var next *int
+	if err := tx.QueryRow(ctx,
+		`SELECT COALESCE(
+		   (SELECT $2::int WHERE NOT EXISTS (SELECT 1 FROM tenants WHERE cluster_id = $1 AND uid = $2)),
+		   (SELECT min(t.uid) + 1 FROM tenants t
+		     WHERE t.cluster_id = $1 AND t.uid >= $2 AND t.uid < $3
+		       AND NOT EXISTS (SELECT 1 FROM tenants n WHERE n.cluster_id = $1 AND n.uid = t.uid + 1)))`,
+		clusterID, lo, hi,
+	).Scan(&next); err != nil {
+		return 0, fmt.Errorf("find free tenant uid in cluster %s: %w", clusterID, err)
+	}
+	if next == nil {
+		return 0, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("tenant UID range %d-%d of cluster %s is exhausted", lo, hi, clusterID),
+			ErrTypeTenantUIDRangeExhausted, nil)
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE tenants SET uid = $1, updated_at = now() WHERE id = $2`, *next, tenantID,
+	); err != nil {
+		return 0, fmt.Errorf("set tenant %s uid: %w", tenantID, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit tenant %s uid: %w", tenantID, err)
+	}
+	return *next, nil
+}
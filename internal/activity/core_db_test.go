@@ -293,3 +293,204 @@ func TestCoreDB_ListNodesByShard_RowsErr(t *testing.T) {
 	assert.Contains(t, err.Error(), "iteration failed")
 	db.AssertExpectations(t)
 }
+
+// ---------- GetCachedNodeRuntimes ----------
+
+func TestCoreDB_GetCachedNodeRuntimes_GroupsByNode(t *testing.T) {
+	db := &mockDB{}
+	a := NewCoreDB(db, "")
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Microsecond)
+
+	row := func(nodeID, runtime, version string) func(dest ...any) error {
+		return func(dest ...any) error {
+			*(dest[0].(*string)) = nodeID
+			*(dest[1].(*string)) = runtime
+			*(dest[2].(*string)) = version
+			*(dest[3].(*[]string)) = []string{"opcache"}
+			*(dest[4].(*time.Time)) = now
+			return nil
+		}
+	}
+	rows := newMockRows(
+		row("node-1", "php", "8.3"),
+		row("node-1", "php", "8.5"),
+		row("node-2", "php", "8.5"),
+	)
+	db.On("Query", ctx, mock.AnythingOfType("string"), []any{[]string{"node-1", "node-2"}}).Return(rows, nil)
+
+	result, err := a.GetCachedNodeRuntimes(ctx, []string{"node-1", "node-2"})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Equal(t, "node-1", result[0].NodeID)
+	assert.Equal(t, []model.InstalledRuntime{
+		{Runtime: "php", Version: "8.3", Extensions: []string{"opcache"}},
+		{Runtime: "php", Version: "8.5", Extensions: []string{"opcache"}},
+	}, result[0].Runtimes)
+	assert.Equal(t, now, result[0].ReportedAt)
+	assert.Equal(t, "node-2", result[1].NodeID)
+	assert.Len(t, result[1].Runtimes, 1)
+	db.AssertExpectations(t)
+}
+
+func TestCoreDB_GetExpiredTenantExports(t *testing.T) {
+	db := &mockDB{}
+	a := NewCoreDB(db, "")
+	ctx := context.Background()
+	expires := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+
+	rows := newMockRows(func(dest ...any) error {
+		*(dest[0].(*string)) = "export-1"
+		*(dest[1].(*string)) = "tenant-1"
+		*(dest[2].(*string)) = "tenant-exports/tenant-1/export-1.tar.gz"
+		*(dest[3].(*int64)) = 4096
+		*(dest[4].(*string)) = model.StatusActive
+		*(dest[6].(*time.Time)) = expires
+		return nil
+	})
+	db.On("Query", ctx, mock.AnythingOfType("string"), []any(nil)).Return(rows, nil)
+
+	result, err := a.GetExpiredTenantExports(ctx)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	assert.Equal(t, "export-1", result[0].ID)
+	assert.Equal(t, "tenant-exports/tenant-1/export-1.tar.gz", result[0].ObjectKey)
+	assert.Equal(t, expires, result[0].ExpiresAt)
+	db.AssertExpectations(t)
+}
+
+func expiringCertRow(id string, expiresAt time.Time, start string, duration int, tz string) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = id
+		*(dest[1].(*string)) = "fqdn-" + id
+		*(dest[7].(**time.Time)) = &expiresAt
+		if start != "" {
+			*(dest[13].(**string)) = &start
+			*(dest[14].(**int)) = &duration
+			*(dest[15].(**string)) = &tz
+		}
+		return nil
+	}
+}
+
+func TestCoreDB_GetLECertsDueForRenewal(t *testing.T) {
+	rows := func(now time.Time) *mockRows {
+		return newMockRows(
+			expiringCertRow("no-window", now.AddDate(0, 0, 20), "", 0, ""),
+			expiringCertRow("urgent", now.AddDate(0, 0, 3), "12:00", 60, "UTC"),
+			expiringCertRow("window-open", now.AddDate(0, 0, 20), "03:00", 120, "Europe/Oslo"),
+			expiringCertRow("window-closed", now.AddDate(0, 0, 20), "12:00", 60, "UTC"),
+		)
+	}
+	tests := []struct {
+		name string
+		now  time.Time
+		want []string
+	}{
+		{"default hour", time.Date(2026, 6, 1, 2, 0, 0, 0, time.UTC), []string{"no-window", "urgent", "window-open"}},
+		{"other hour", time.Date(2026, 6, 1, 1, 0, 0, 0, time.UTC), []string{"window-open"}},
+		{"window hour", time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC), []string{"urgent", "window-closed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			a := NewCoreDB(db, "")
+			ctx := context.Background()
+
+			db.On("Query", ctx, mock.AnythingOfType("string"), []any{model.CertTypeLetsEncrypt, model.StatusActive, 30}).
+				Return(rows(tt.now), nil)
+
+			certs, err := a.GetLECertsDueForRenewal(ctx, GetLECertsDueForRenewalParams{
+				DaysBeforeExpiry: 30, UrgentDays: 7, DefaultHourUTC: 2, Now: tt.now,
+			})
+			require.NoError(t, err)
+			var ids []string
+			for _, c := range certs {
+				ids = append(ids, c.ID)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestCoreDB_UpdateBackupVerification(t *testing.T) {
+	db := &mockDB{}
+	a := NewCoreDB(db, "")
+	ctx := context.Background()
+	verifiedAt := time.Now()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{model.BackupVerifyPassed, (*string)(nil), verifiedAt, "backup-1"}).
+		Return(pgconn.CommandTag{}, nil)
+	msg := "mysqlcheck: error : Corrupt"
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{model.BackupVerifyFailed, &msg, verifiedAt, "backup-2"}).
+		Return(pgconn.CommandTag{}, nil)
+
+	require.NoError(t, a.UpdateBackupVerification(ctx, UpdateBackupVerificationParams{
+		ID: "backup-1", Status: model.BackupVerifyPassed, VerifiedAt: verifiedAt,
+	}))
+	require.NoError(t, a.UpdateBackupVerification(ctx, UpdateBackupVerificationParams{
+		ID: "backup-2", Status: model.BackupVerifyFailed, Message: msg, VerifiedAt: verifiedAt,
+	}))
+	db.AssertExpectations(t)
+}
+
+func TestCoreDB_ClearWebrootDeployLock(t *testing.T) {
+	db := &mockDB{}
+	a := NewCoreDB(db, "")
+	ctx := context.Background()
+	until := time.Now()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{"webroot-1", until}).
+		Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{"webroot-2", until}).
+		Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+
+	cleared, err := a.ClearWebrootDeployLock(ctx, ClearWebrootDeployLockParams{WebrootID: "webroot-1", Until: until})
+	require.NoError(t, err)
+	assert.True(t, cleared)
+
+	// Extended or already lifted.
+	cleared, err = a.ClearWebrootDeployLock(ctx, ClearWebrootDeployLockParams{WebrootID: "webroot-2", Until: until})
+	require.NoError(t, err)
+	assert.False(t, cleared)
+	db.AssertExpectations(t)
+}
+
+func TestCoreDB_DeleteWebrootReleaseRecord(t *testing.T) {
+	db := &mockDB{}
+	a := NewCoreDB(db, "")
+	ctx := context.Background()
+
+	db.On("Exec", ctx, "DELETE FROM webroot_releases WHERE id = $1", []any{"rel-1"}).
+		Return(pgconn.NewCommandTag("DELETE 1"), nil)
+
+	require.NoError(t, a.DeleteWebrootReleaseRecord(ctx, "rel-1"))
+	db.AssertExpectations(t)
+}
+
+func TestCoreDB_GetBulkCertFQDNs(t *testing.T) {
+	db := &mockDB{}
+	a := NewCoreDB(db, "")
+	ctx := context.Background()
+
+	fqdnRow := func(id, fqdn string, hasCert bool) func(dest ...any) error {
+		return func(dest ...any) error {
+			*(dest[0].(*string)) = id
+			*(dest[1].(*string)) = fqdn
+			*(dest[2].(*bool)) = hasCert
+			return nil
+		}
+	}
+	rows := newMockRows(
+		fqdnRow("fqdn-2", "*.shop.example.co.uk", true),
+		fqdnRow("fqdn-1", "www.example.com", false),
+	)
+	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+
+	result, err := a.GetBulkCertFQDNs(ctx, []string{"fqdn-1", "fqdn-gone", "fqdn-2"})
+	require.NoError(t, err)
+	assert.Equal(t, []BulkCertFQDN{
+		{FQDNID: "fqdn-1", FQDN: "www.example.com", RegisteredDomain: "example.com"},
+		{FQDNID: "fqdn-2", FQDN: "*.shop.example.co.uk", RegisteredDomain: "example.co.uk", HasCert: true},
+	}, result)
+}
@@ -0,0 +1,22 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+)
+
+// ClearWebrootDeployLock lifts a webroot's deploy lock if it is still the
+// one that expires at params.Until or an earlier one. A lock that was
+// extended since, or already lifted, is left alone. Returns whether the lock
+// was lifted.
+func (a *CoreDB) ClearWebrootDeployLock(ctx context.Context, params ClearWebrootDeployLockParams) (bool, error) {
+	tag, err := a.db.Exec(ctx,
+		`UPDATE webroots SET deploy_locked_until = NULL, updated_at = now()
+		 WHERE id = $1 AND deploy_locked_until <= $2`,
+		params.WebrootID, params.Until,
+	)
+	if err != nil {
+		return false, fmt.Errorf("clear deploy lock of webroot %s: %w", params.WebrootID, err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,85 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+const webrootReleaseColumns = `id, tenant_id, webroot_id, name, current, status, status_message, promoted_at, created_at, updated_at`
+
+func scanWebrootRelease(row interface{ Scan(dest ...any) error }, r *model.WebrootRelease) error {
+	return row.Scan(&r.ID, &r.TenantID, &r.WebrootID, &r.Name, &r.Current,
+		&r.Status, &r.StatusMessage, &r.PromotedAt, &r.CreatedAt, &r.UpdatedAt)
+}
+
+// GetWebrootReleaseByID retrieves a webroot release by its ID.
+func (a *CoreDB) GetWebrootReleaseByID(ctx context.Context, id string) (*model.WebrootRelease, error) {
+	var r model.WebrootRelease
+	err := scanWebrootRelease(a.db.QueryRow(ctx,
+		`SELECT `+webrootReleaseColumns+` FROM webroot_releases WHERE id = $1`, id), &r)
+	if err != nil {
+		return nil, fmt.Errorf("get webroot release %s: %w", id, err)
+	}
+	return &r, nil
+}
+
+// SetCurrentWebrootRelease marks a release as the webroot's live release and
+// records when it was promoted.
+func (a *CoreDB) SetCurrentWebrootRelease(ctx context.Context, params SetCurrentWebrootReleaseParams) error {
+	tx, err := a.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE webroot_releases SET current = false, updated_at = now() WHERE webroot_id = $1 AND current`,
+		params.WebrootID,
+	); err != nil {
+		return fmt.Errorf("clear current release: %w", err)
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE webroot_releases SET current = true, promoted_at = now(), updated_at = now() WHERE id = $1 AND webroot_id = $2`,
+		params.ReleaseID, params.WebrootID,
+	); err != nil {
+		return fmt.Errorf("set current release %s: %w", params.ReleaseID, err)
+	}
+	return tx.Commit(ctx)
+}
+
+// ListPrunableWebrootReleases returns the finished releases of a webroot that
+// fall outside the Keep newest. The live release is never returned.
+func (a *CoreDB) ListPrunableWebrootReleases(ctx context.Context, params ListPrunableWebrootReleasesParams) ([]model.WebrootRelease, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT `+webrootReleaseColumns+` FROM webroot_releases
+		 WHERE webroot_id = $1 AND NOT current AND status IN ($2, $3)
+		   AND id NOT IN (SELECT id FROM webroot_releases WHERE webroot_id = $1 ORDER BY name DESC LIMIT $4)
+		 ORDER BY name`,
+		params.WebrootID, model.StatusActive, model.StatusFailed, params.Keep,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list prunable releases for webroot %s: %w", params.WebrootID, err)
+	}
+	defer rows.Close()
+
+	var releases []model.WebrootRelease
+	for rows.Next() {
+		var r model.WebrootRelease
+		if err := scanWebrootRelease(rows, &r); err != nil {
+			return nil, fmt.Errorf("scan webroot release: %w", err)
+		}
+		releases = append(releases, r)
+	}
+	return releases, rows.Err()
+}
+
+// DeleteWebrootReleaseRecord removes a release's record once its directory
+// is gone.
+func (a *CoreDB) DeleteWebrootReleaseRecord(ctx context.Context, id string) error {
+	if _, err := a.db.Exec(ctx, `DELETE FROM webroot_releases WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete webroot release %s: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// UpdateZoneDNSSECStatus sets a zone's DNSSEC signing state.
+func (a *CoreDB) UpdateZoneDNSSECStatus(ctx context.Context, params UpdateZoneDNSSECStatusParams) error {
+	_, err := a.db.Exec(ctx,
+		`UPDATE zones SET dnssec_status = $1, updated_at = now() WHERE id = $2`,
+		params.Status, params.ZoneID,
+	)
+	if err != nil {
+		return fmt.Errorf("update zone %s dnssec status: %w", params.ZoneID, err)
+	}
+	return nil
+}
+
+// SyncZoneDNSSECKeys replaces the zone's mirrored DNSSEC keys with the given
+// set. Keys that are already mirrored keep their created_at, which the ZSK
+// rollover schedule is based on.
+func (a *CoreDB) SyncZoneDNSSECKeys(ctx context.Context, params SyncZoneDNSSECKeysParams) error {
+	tx, err := a.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	ids := make([]int, 0, len(params.Keys))
+	for _, k := range params.Keys {
+		ids = append(ids, k.ID)
+	}
+	_, err = tx.Exec(ctx,
+		`DELETE FROM zone_dnssec_keys WHERE zone_id = $1 AND NOT (pdns_key_id = ANY($2))`,
+		params.ZoneID, ids,
+	)
+	if err != nil {
+		return fmt.Errorf("delete stale dnssec keys of zone %s: %w", params.ZoneID, err)
+	}
+
+	for _, k := range params.Keys {
+		keyType := model.DNSSECKeyZSK
+		var ds *string
+		if k.KSK() {
+			keyType = model.DNSSECKeyKSK
+			ds = &k.DS
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO zone_dnssec_keys (zone_id, pdns_key_id, key_type, key_tag, algorithm, dnskey, ds, active, published)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			 ON CONFLICT (zone_id, pdns_key_id) DO UPDATE SET
+			   key_type = EXCLUDED.key_type, key_tag = EXCLUDED.key_tag, algorithm = EXCLUDED.algorithm,
+			   dnskey = EXCLUDED.dnskey, ds = EXCLUDED.ds, active = EXCLUDED.active, published = EXCLUDED.published`,
+			params.ZoneID, k.ID, keyType, k.KeyTag, k.Algorithm, k.DNSKEY, ds, k.Active, k.Published,
+		)
+		if err != nil {
+			return fmt.Errorf("write dnssec key %d of zone %s: %w", k.ID, params.ZoneID, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit tx: %w", err)
+	}
+	return nil
+}
+
+// ListZonesDueForZSKRollover returns the IDs of signed zones whose active
+// ZSK is older than maxAgeDays and that have no rollover in progress.
+func (a *CoreDB) ListZonesDueForZSKRollover(ctx context.Context, maxAgeDays int) ([]string, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT z.id FROM zones z
+		 WHERE z.status = $1 AND z.dnssec_status = $2
+		   AND EXISTS (SELECT 1 FROM zone_dnssec_keys k
+		               WHERE k.zone_id = z.id AND k.key_type = $3 AND k.active
+		                 AND k.created_at < now() - make_interval(days => $4))
+		   AND NOT EXISTS (SELECT 1 FROM zone_dnssec_keys k
+		                   WHERE k.zone_id = z.id AND k.key_type = $3 AND NOT k.active)
+		 ORDER BY z.id`,
+		model.StatusActive, model.DNSSECSigned, model.DNSSECKeyZSK, maxAgeDays,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list zones due for zsk rollover: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan zone id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,39 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// GetRegionNameservers returns the configured nameservers of the given
+// regions, in region order.
+func (a *CoreDB) GetRegionNameservers(ctx context.Context, regionIDs []string) ([]model.RegionNameserver, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT config FROM regions WHERE id = ANY($1) ORDER BY array_position($1, id)`, regionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get region nameservers: %w", err)
+	}
+	defer rows.Close()
+
+	var nameservers []model.RegionNameserver
+	for rows.Next() {
+		var raw json.RawMessage
+		if err := rows.Scan(&raw); err != nil {
+			return nil, fmt.Errorf("scan region config: %w", err)
+		}
+		var cfg model.RegionConfig
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &cfg); err != nil {
+				return nil, fmt.Errorf("parse region config: %w", err)
+			}
+		}
+		nameservers = append(nameservers, cfg.DNS.Nameservers...)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate region configs: %w", err)
+	}
+	return nameservers, nil
+}
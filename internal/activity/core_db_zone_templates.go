@@ -0,0 +1,49 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ListBrandZoneTemplates returns the DNS zone templates of a brand.
+func (a *CoreDB) ListBrandZoneTemplates(ctx context.Context, brandID string) ([]model.BrandZoneTemplate, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT id, brand_id, type, name, content, ttl, priority, created_at, updated_at
+		 FROM brand_zone_templates WHERE brand_id = $1 ORDER BY created_at, id`, brandID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list brand zone templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []model.BrandZoneTemplate
+	for rows.Next() {
+		var t model.BrandZoneTemplate
+		if err := rows.Scan(&t.ID, &t.BrandID, &t.Type, &t.Name, &t.Content, &t.TTL, &t.Priority,
+			&t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan brand zone template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// CreateTemplateZoneRecords inserts pending zone records (managed_by=template)
+// for already-expanded brand templates. Records that already exist are
+// skipped, so the activity is safe to retry.
+func (a *CoreDB) CreateTemplateZoneRecords(ctx context.Context, params CreateTemplateZoneRecordsParams) error {
+	for _, t := range params.Records {
+		_, err := a.db.Exec(ctx,
+			`INSERT INTO zone_records (id, zone_id, type, name, content, ttl, priority, managed_by, source_type, status, created_at, updated_at)
+			 SELECT gen_random_uuid(), $1, $2, $3, $4, $5, $6, $7, '', $8, now(), now()
+			 WHERE NOT EXISTS (SELECT 1 FROM zone_records WHERE zone_id = $1 AND type = $2 AND name = $3 AND content = $4 AND managed_by = $7)`,
+			params.ZoneID, t.Type, t.Name, t.Content, t.TTL, t.Priority, model.ManagedByTemplate, model.StatusPending,
+		)
+		if err != nil {
+			return fmt.Errorf("create template %s record %s: %w", t.Type, t.Name, err)
+		}
+	}
+	return nil
+}
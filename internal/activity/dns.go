@@ -3,11 +3,13 @@ package activity
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/edvin/hosting/internal/model"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.temporal.io/sdk/temporal"
 )
 
 // DNS contains activities for automatic DNS record management.
@@ -26,10 +28,15 @@ type AutoCreateDNSRecordsParams struct {
 	FQDN         string                   `json:"fqdn"`
 	LBAddresses  []model.ClusterLBAddress `json:"lb_addresses"`
 	SourceFQDNID string                   `json:"source_fqdn_id"`
+	// IPv6Only publishes AAAA records only and removes the FQDN's auto A
+	// records, for FQDNs in model.IPModeIPv6Only.
+	IPv6Only bool `json:"ipv6_only,omitempty"`
 }
 
 // AutoCreateDNSRecords creates A and AAAA records for an FQDN in the matching
 // zone, if the zone exists and no custom-managed record already exists.
+// It is idempotent, so it also brings existing records in line with the
+// FQDN's IP mode.
 func (a *DNS) AutoCreateDNSRecords(ctx context.Context, params AutoCreateDNSRecordsParams) error {
 	zoneName, err := a.findZoneForFQDN(ctx, params.FQDN)
 	if err != nil {
@@ -49,9 +56,27 @@ func (a *DNS) AutoCreateDNSRecords(ctx context.Context, params AutoCreateDNSReco
 		return fmt.Errorf("check custom managed record: %w", err)
 	}
 
+	brand, err := a.zoneBrandTTLs(ctx, zoneName)
+	if err != nil {
+		return fmt.Errorf("get ttl policy: %w", err)
+	}
+
+	if params.IPv6Only {
+		if !hasCustom && domainID > 0 {
+			if err := a.powerdnsDB.DeleteDNSRecord(ctx, DeleteDNSRecordParams{DomainID: domainID, Name: params.FQDN, Type: "A"}); err != nil {
+				return fmt.Errorf("delete A records: %w", err)
+			}
+		}
+		_, err = a.coreDB.Exec(ctx,
+			`DELETE FROM zone_records WHERE name = $1 AND type = 'A' AND managed_by = 'auto' AND source_type = 'fqdn'`, params.FQDN)
+		if err != nil {
+			return fmt.Errorf("delete auto A records from core db: %w", err)
+		}
+	}
+
 	for _, addr := range params.LBAddresses {
 		var recordType string
-		if addr.Family == 4 {
+		if addr.Family == 4 && !params.IPv6Only {
 			recordType = "A"
 		} else if addr.Family == 6 {
 			recordType = "AAAA"
@@ -59,6 +84,8 @@ func (a *DNS) AutoCreateDNSRecords(ctx context.Context, params AutoCreateDNSReco
 			continue
 		}
 
+		ttl := brand.AutoRecordTTL(recordType)
+
 		// Write to PowerDNS only if no custom override exists.
 		if !hasCustom {
 			if err := a.powerdnsDB.WriteDNSRecord(ctx, WriteDNSRecordParams{
@@ -66,7 +93,7 @@ func (a *DNS) AutoCreateDNSRecords(ctx context.Context, params AutoCreateDNSReco
 				Name:     params.FQDN,
 				Type:     recordType,
 				Content:  addr.Address,
-				TTL:      300,
+				TTL:      ttl,
 			}); err != nil {
 				return fmt.Errorf("create %s record for %s: %w", recordType, addr.Address, err)
 			}
@@ -75,10 +102,10 @@ func (a *DNS) AutoCreateDNSRecords(ctx context.Context, params AutoCreateDNSReco
 		// Always record in core DB (auto records exist regardless of override state).
 		_, err = a.coreDB.Exec(ctx,
 			`INSERT INTO zone_records (id, zone_id, type, name, content, ttl, managed_by, source_type, source_fqdn_id, status, created_at, updated_at)
-			 SELECT gen_random_uuid(), z.id, $1, $2, $3, 300, 'auto', 'fqdn', $4, 'active', now(), now()
+			 SELECT gen_random_uuid(), z.id, $1, $2, $3, $6, 'auto', 'fqdn', $4, 'active', now(), now()
 			 FROM zones z WHERE z.name = $5 AND z.status = 'active'
 			 AND NOT EXISTS (SELECT 1 FROM zone_records zr WHERE zr.zone_id = z.id AND zr.type = $1 AND zr.name = $2 AND zr.content = $3 AND zr.managed_by = 'auto')`,
-			recordType, params.FQDN, addr.Address, params.SourceFQDNID, zoneName,
+			recordType, params.FQDN, addr.Address, params.SourceFQDNID, zoneName, ttl,
 		)
 		if err != nil {
 			return fmt.Errorf("record auto %s in core db: %w", recordType, err)
@@ -148,6 +175,11 @@ func (a *DNS) AutoCreateEmailDNSRecords(ctx context.Context, params AutoCreateEm
 		return fmt.Errorf("get dns zone id: %w", err)
 	}
 
+	brand, err := a.zoneBrandTTLs(ctx, zoneName)
+	if err != nil {
+		return fmt.Errorf("get ttl policy: %w", err)
+	}
+
 	// MX record.
 	if params.MailHostname != "" {
 		if err := a.createAutoRecord(ctx, autoRecordDef{
@@ -156,7 +188,7 @@ func (a *DNS) AutoCreateEmailDNSRecords(ctx context.Context, params AutoCreateEm
 			fqdn:         params.FQDN,
 			recordType:   "MX",
 			content:      params.MailHostname,
-			ttl:          300,
+			ttl:          brand.AutoRecordTTL("MX"),
 			priority:     intPtr(10),
 			sourceType:   model.SourceTypeEmailMX,
 			sourceFQDNID: params.SourceFQDNID,
@@ -173,7 +205,7 @@ func (a *DNS) AutoCreateEmailDNSRecords(ctx context.Context, params AutoCreateEm
 		fqdn:         params.FQDN,
 		recordType:   "TXT",
 		content:      spfContent,
-		ttl:          300,
+		ttl:          brand.AutoRecordTTL("TXT"),
 		sourceType:   model.SourceTypeEmailSPF,
 		sourceFQDNID: params.SourceFQDNID,
 	}); err != nil {
@@ -190,7 +222,7 @@ func (a *DNS) AutoCreateEmailDNSRecords(ctx context.Context, params AutoCreateEm
 			fqdn:         dkimName,
 			recordType:   "TXT",
 			content:      dkimContent,
-			ttl:          300,
+			ttl:          brand.AutoRecordTTL("TXT"),
 			sourceType:   model.SourceTypeEmailDKIM,
 			sourceFQDNID: params.SourceFQDNID,
 		}); err != nil {
@@ -207,7 +239,7 @@ func (a *DNS) AutoCreateEmailDNSRecords(ctx context.Context, params AutoCreateEm
 			fqdn:         dmarcName,
 			recordType:   "TXT",
 			content:      params.DMARCPolicy,
-			ttl:          300,
+			ttl:          brand.AutoRecordTTL("TXT"),
 			sourceType:   model.SourceTypeEmailDMARC,
 			sourceFQDNID: params.SourceFQDNID,
 		}); err != nil {
@@ -268,6 +300,91 @@ func (a *DNS) AutoDeleteEmailDNSRecords(ctx context.Context, fqdn string) error
 	return nil
 }
 
+// PublishDKIMRecordParams holds parameters for publishing an FQDN's DKIM record.
+type PublishDKIMRecordParams struct {
+	FQDN         string `json:"fqdn"`
+	Selector     string `json:"selector"`
+	PublicKey    string `json:"public_key"`
+	SourceFQDNID string `json:"source_fqdn_id"`
+}
+
+// PublishDKIMRecord replaces the auto-managed DKIM record(s) of an FQDN with
+// the given selector and public key. Records published for a previous key or
+// the brand's key are removed first so only one DKIM key is advertised. Does
+// nothing if the FQDN has no platform-managed zone.
+func (a *DNS) PublishDKIMRecord(ctx context.Context, params PublishDKIMRecordParams) error {
+	zoneName, err := a.findZoneForFQDN(ctx, params.FQDN)
+	if err != nil {
+		return fmt.Errorf("find zone for fqdn: %w", err)
+	}
+	if zoneName == "" {
+		return nil
+	}
+
+	domainID, err := a.powerdnsDB.GetDNSZoneIDByName(ctx, zoneName)
+	if err != nil {
+		return fmt.Errorf("get dns zone id: %w", err)
+	}
+
+	brand, err := a.zoneBrandTTLs(ctx, zoneName)
+	if err != nil {
+		return fmt.Errorf("get ttl policy: %w", err)
+	}
+
+	rows, err := a.coreDB.Query(ctx,
+		`SELECT DISTINCT name FROM zone_records
+		 WHERE source_fqdn_id = $1 AND managed_by = 'auto' AND source_type = 'email-dkim'`,
+		params.SourceFQDNID)
+	if err != nil {
+		return fmt.Errorf("find existing dkim records: %w", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan dkim record: %w", err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("find existing dkim records: %w", err)
+	}
+
+	if domainID > 0 {
+		for _, name := range names {
+			if err := a.powerdnsDB.DeleteDNSRecord(ctx, DeleteDNSRecordParams{
+				DomainID: domainID, Name: name, Type: "TXT",
+			}); err != nil {
+				return fmt.Errorf("delete dkim record %s: %w", name, err)
+			}
+		}
+	}
+
+	if _, err := a.coreDB.Exec(ctx,
+		`DELETE FROM zone_records
+		 WHERE source_fqdn_id = $1 AND managed_by = 'auto' AND source_type = 'email-dkim'`,
+		params.SourceFQDNID); err != nil {
+		return fmt.Errorf("delete dkim records from core db: %w", err)
+	}
+
+	if err := a.createAutoRecord(ctx, autoRecordDef{
+		zoneName:     zoneName,
+		domainID:     domainID,
+		fqdn:         model.DKIMRecordName(params.Selector, params.FQDN),
+		recordType:   "TXT",
+		content:      model.DKIMRecordContent(params.PublicKey),
+		ttl:          brand.AutoRecordTTL("TXT"),
+		sourceType:   model.SourceTypeEmailDKIM,
+		sourceFQDNID: params.SourceFQDNID,
+	}); err != nil {
+		return fmt.Errorf("create DKIM record: %w", err)
+	}
+
+	return nil
+}
+
 // DeactivateAutoRecordsParams holds parameters for deactivating auto records
 // when a custom record with the same name and type is created.
 type DeactivateAutoRecordsParams struct {
@@ -393,11 +510,12 @@ func (a *DNS) RetroactiveAutoRecords(ctx context.Context, params RetroactiveAuto
 	err := a.coreDB.QueryRow(ctx,
 		`SELECT id, name, base_hostname, primary_ns, secondary_ns, hostmaster_email,
 		 mail_hostname, spf_includes, dkim_selector, dkim_public_key, dmarc_policy,
-		 status, created_at, updated_at
+		 dns_ttls, dns_migration_ttl, status, created_at, updated_at
 		 FROM brands WHERE id = $1`, params.BrandID,
 	).Scan(&brand.ID, &brand.Name, &brand.BaseHostname, &brand.PrimaryNS, &brand.SecondaryNS,
 		&brand.HostmasterEmail, &brand.MailHostname, &brand.SPFIncludes, &brand.DKIMSelector,
-		&brand.DKIMPublicKey, &brand.DMARCPolicy, &brand.Status, &brand.CreatedAt, &brand.UpdatedAt)
+		&brand.DKIMPublicKey, &brand.DMARCPolicy, &brand.DNSTTLs, &brand.DNSMigrationTTL,
+		&brand.Status, &brand.CreatedAt, &brand.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("get brand: %w", err)
 	}
@@ -420,7 +538,7 @@ func (a *DNS) RetroactiveAutoRecords(ctx context.Context, params RetroactiveAuto
 	// Find all active FQDNs whose domain falls under this zone.
 	// Match: fqdn = zone_name OR fqdn ends with .zone_name
 	fqdnRows, err := a.coreDB.Query(ctx,
-		`SELECT f.id, f.fqdn FROM fqdns f
+		`SELECT f.id, f.fqdn, f.ip_mode FROM fqdns f
 		 WHERE f.status = 'active'
 		 AND (f.fqdn = $1 OR f.fqdn LIKE '%.' || $1)`,
 		params.ZoneName)
@@ -430,22 +548,22 @@ func (a *DNS) RetroactiveAutoRecords(ctx context.Context, params RetroactiveAuto
 	defer fqdnRows.Close()
 
 	type fqdnRef struct {
-		id, fqdn string
+		id, fqdn, ipMode string
 	}
 	var fqdns []fqdnRef
 	for fqdnRows.Next() {
 		var f fqdnRef
-		if err := fqdnRows.Scan(&f.id, &f.fqdn); err != nil {
+		if err := fqdnRows.Scan(&f.id, &f.fqdn, &f.ipMode); err != nil {
 			return fmt.Errorf("scan fqdn: %w", err)
 		}
 		fqdns = append(fqdns, f)
 	}
 
-	// Create A/AAAA records for each FQDN.
+	// Create A/AAAA records for each FQDN; IPv6-only FQDNs get no A records.
 	for _, f := range fqdns {
 		for _, addr := range lbAddresses {
 			var recordType string
-			if addr.Family == 4 {
+			if addr.Family == 4 && f.ipMode != model.IPModeIPv6Only {
 				recordType = "A"
 			} else if addr.Family == 6 {
 				recordType = "AAAA"
@@ -453,6 +571,7 @@ func (a *DNS) RetroactiveAutoRecords(ctx context.Context, params RetroactiveAuto
 				continue
 			}
 
+			ttl := brand.AutoRecordTTL(recordType)
 			hasCustom, _ := a.hasCustomRecord(ctx, f.fqdn, recordType)
 			if !hasCustom {
 				_ = a.powerdnsDB.WriteDNSRecord(ctx, WriteDNSRecordParams{
@@ -460,22 +579,23 @@ func (a *DNS) RetroactiveAutoRecords(ctx context.Context, params RetroactiveAuto
 					Name:     f.fqdn,
 					Type:     recordType,
 					Content:  addr.Address,
-					TTL:      300,
+					TTL:      ttl,
 				})
 			}
 
 			_, _ = a.coreDB.Exec(ctx,
 				`INSERT INTO zone_records (id, zone_id, type, name, content, ttl, managed_by, source_type, source_fqdn_id, status, created_at, updated_at)
-				 SELECT gen_random_uuid(), $1, $2, $3, $4, 300, 'auto', 'fqdn', $5, 'active', now(), now()
+				 SELECT gen_random_uuid(), $1, $2, $3, $4, $6, 'auto', 'fqdn', $5, 'active', now(), now()
 				 WHERE NOT EXISTS (SELECT 1 FROM zone_records WHERE zone_id = $1 AND type = $2 AND name = $3 AND content = $4 AND managed_by = 'auto')`,
-				params.ZoneID, recordType, f.fqdn, addr.Address, f.id)
+				params.ZoneID, recordType, f.fqdn, addr.Address, f.id, ttl)
 		}
 	}
 
 	// Find FQDNs that have email accounts and create email DNS records.
 	emailFQDNRows, err := a.coreDB.Query(ctx,
-		`SELECT DISTINCT f.id, f.fqdn FROM fqdns f
+		`SELECT DISTINCT f.id, f.fqdn, COALESCE(k.selector, ''), COALESCE(k.public_key, '') FROM fqdns f
 		 JOIN email_accounts ea ON ea.fqdn_id = f.id
+		 LEFT JOIN fqdn_dkim_keys k ON k.fqdn_id = f.id AND k.status = 'active'
 		 WHERE f.status = 'active'
 		 AND (f.fqdn = $1 OR f.fqdn LIKE '%.' || $1)`,
 		params.ZoneName)
@@ -484,12 +604,20 @@ func (a *DNS) RetroactiveAutoRecords(ctx context.Context, params RetroactiveAuto
 	}
 	defer emailFQDNRows.Close()
 
-	var emailFQDNs []fqdnRef
+	// Per-FQDN DKIM keys take precedence over the brand's.
+	type emailFQDNRef struct {
+		fqdnRef
+		dkimSelector, dkimPublicKey string
+	}
+	var emailFQDNs []emailFQDNRef
 	for emailFQDNRows.Next() {
-		var f fqdnRef
-		if err := emailFQDNRows.Scan(&f.id, &f.fqdn); err != nil {
+		var f emailFQDNRef
+		if err := emailFQDNRows.Scan(&f.id, &f.fqdn, &f.dkimSelector, &f.dkimPublicKey); err != nil {
 			return fmt.Errorf("scan email fqdn: %w", err)
 		}
+		if f.dkimSelector == "" || f.dkimPublicKey == "" {
+			f.dkimSelector, f.dkimPublicKey = brand.DKIMSelector, brand.DKIMPublicKey
+		}
 		emailFQDNs = append(emailFQDNs, f)
 	}
 
@@ -503,8 +631,8 @@ func (a *DNS) RetroactiveAutoRecords(ctx context.Context, params RetroactiveAuto
 			FQDN:          f.fqdn,
 			MailHostname:  mailHostname,
 			SPFIncludes:   brand.SPFIncludes,
-			DKIMSelector:  brand.DKIMSelector,
-			DKIMPublicKey: brand.DKIMPublicKey,
+			DKIMSelector:  f.dkimSelector,
+			DKIMPublicKey: f.dkimPublicKey,
 			DMARCPolicy:   brand.DMARCPolicy,
 			SourceFQDNID:  f.id,
 		}); err != nil {
@@ -547,6 +675,11 @@ func (a *DNS) CreateServiceHostnameRecords(ctx context.Context, params ServiceHo
 		return fmt.Errorf("get dns zone id: %w", err)
 	}
 
+	brand, err := a.zoneBrandTTLs(ctx, zoneName)
+	if err != nil {
+		return fmt.Errorf("get ttl policy: %w", err)
+	}
+
 	for _, svc := range params.Services {
 		hostname := fmt.Sprintf("%s.%s.%s", svc.Service, params.TenantName, params.BaseHostname)
 
@@ -557,7 +690,7 @@ func (a *DNS) CreateServiceHostnameRecords(ctx context.Context, params ServiceHo
 				fqdn:       hostname,
 				recordType: "A",
 				content:    svc.IP,
-				ttl:        300,
+				ttl:        brand.AutoRecordTTL("A"),
 				sourceType: model.SourceTypeServiceHostname,
 			}); err != nil {
 				return fmt.Errorf("create service A record for %s: %w", hostname, err)
@@ -571,7 +704,7 @@ func (a *DNS) CreateServiceHostnameRecords(ctx context.Context, params ServiceHo
 				fqdn:       hostname,
 				recordType: "AAAA",
 				content:    svc.IP6,
-				ttl:        300,
+				ttl:        brand.AutoRecordTTL("AAAA"),
 				sourceType: model.SourceTypeServiceHostname,
 			}); err != nil {
 				return fmt.Errorf("create service AAAA record for %s: %w", hostname, err)
@@ -582,6 +715,134 @@ func (a *DNS) CreateServiceHostnameRecords(ctx context.Context, params ServiceHo
 	return nil
 }
 
+// DeleteServiceHostnameRecords removes the A/AAAA records of the given
+// service hostnames, e.g. before a tenant's brand base hostname changes.
+// Only the service names of params.Services are used.
+func (a *DNS) DeleteServiceHostnameRecords(ctx context.Context, params ServiceHostnameParams) error {
+	zoneName, err := a.findZoneForFQDN(ctx, params.BaseHostname)
+	if err != nil {
+		return fmt.Errorf("find zone for base hostname: %w", err)
+	}
+
+	var domainID int
+	if zoneName != "" {
+		if domainID, err = a.powerdnsDB.GetDNSZoneIDByName(ctx, zoneName); err != nil {
+			return fmt.Errorf("get dns zone id: %w", err)
+		}
+	}
+
+	for _, svc := range params.Services {
+		hostname := fmt.Sprintf("%s.%s.%s", svc.Service, params.TenantName, params.BaseHostname)
+		if domainID > 0 {
+			for _, recordType := range []string{"A", "AAAA"} {
+				if err := a.powerdnsDB.DeleteDNSRecord(ctx, DeleteDNSRecordParams{DomainID: domainID, Name: hostname, Type: recordType}); err != nil {
+					return fmt.Errorf("delete service %s record for %s: %w", recordType, hostname, err)
+				}
+			}
+		}
+		_, err := a.coreDB.Exec(ctx,
+			`DELETE FROM zone_records WHERE name = $1 AND managed_by = 'auto' AND source_type = $2`,
+			hostname, model.SourceTypeServiceHostname)
+		if err != nil {
+			return fmt.Errorf("delete service hostname records for %s from core db: %w", hostname, err)
+		}
+	}
+
+	return nil
+}
+
+// LowerTenantAddressRecordTTLs lowers the TTL of the auto A/AAAA records of a
+// tenant's FQDNs to the brand's migration TTL ahead of a shard move. It
+// returns the highest TTL before the change, which resolvers may still be
+// caching for.
+func (a *DNS) LowerTenantAddressRecordTTLs(ctx context.Context, tenantID string) (int, error) {
+	return a.setTenantAddressRecordTTLs(ctx, tenantID, func(brand *model.Brand, _ string) int {
+		return brand.DNSMigrationTTL
+	})
+}
+
+// RestoreTenantAddressRecordTTLs sets the TTL of the auto A/AAAA records of a
+// tenant's FQDNs back to the brand's TTL policy after a shard move.
+func (a *DNS) RestoreTenantAddressRecordTTLs(ctx context.Context, tenantID string) error {
+	_, err := a.setTenantAddressRecordTTLs(ctx, tenantID, func(brand *model.Brand, recordType string) int {
+		return brand.AutoRecordTTL(recordType)
+	})
+	return err
+}
+
+// setTenantAddressRecordTTLs updates the auto A/AAAA records of a tenant's
+// FQDNs in core DB, and in PowerDNS unless a custom record overrides them.
+// It returns the highest TTL the records had before.
+func (a *DNS) setTenantAddressRecordTTLs(ctx context.Context, tenantID string, ttlFor func(brand *model.Brand, recordType string) int) (int, error) {
+	rows, err := a.coreDB.Query(ctx,
+		`SELECT zr.id, zr.name, zr.type, zr.ttl, z.name, b.dns_ttls, b.dns_migration_ttl
+		 FROM zone_records zr
+		 JOIN zones z ON z.id = zr.zone_id
+		 JOIN brands b ON b.id = z.brand_id
+		 JOIN fqdns f ON f.id = zr.source_fqdn_id
+		 JOIN webroots w ON w.id = f.webroot_id
+		 WHERE w.tenant_id = $1 AND zr.managed_by = 'auto' AND zr.source_type = 'fqdn'
+		 AND zr.type IN ('A', 'AAAA')`, tenantID)
+	if err != nil {
+		return 0, fmt.Errorf("find address records for tenant %s: %w", tenantID, err)
+	}
+	defer rows.Close()
+
+	type addrRec struct {
+		id, name, recType, zoneName string
+		ttl                         int
+		brand                       model.Brand
+	}
+	var recs []addrRec
+	for rows.Next() {
+		var r addrRec
+		if err := rows.Scan(&r.id, &r.name, &r.recType, &r.ttl, &r.zoneName, &r.brand.DNSTTLs, &r.brand.DNSMigrationTTL); err != nil {
+			return 0, fmt.Errorf("scan address record: %w", err)
+		}
+		recs = append(recs, r)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate address records: %w", err)
+	}
+
+	maxTTL := 0
+	for _, r := range recs {
+		if r.ttl > maxTTL {
+			maxTTL = r.ttl
+		}
+		ttl := ttlFor(&r.brand, r.recType)
+
+		hasCustom, err := a.hasCustomRecord(ctx, r.name, r.recType)
+		if err != nil {
+			return 0, fmt.Errorf("check custom override: %w", err)
+		}
+		if !hasCustom {
+			domainID, err := a.powerdnsDB.GetDNSZoneIDByName(ctx, r.zoneName)
+			if err != nil {
+				return 0, fmt.Errorf("get dns zone id: %w", err)
+			}
+			if domainID > 0 {
+				if err := a.powerdnsDB.SetDNSRecordTTL(ctx, SetDNSRecordTTLParams{
+					DomainID: domainID,
+					Name:     r.name,
+					Type:     r.recType,
+					TTL:      ttl,
+				}); err != nil {
+					return 0, err
+				}
+			}
+		}
+
+		if _, err := a.coreDB.Exec(ctx,
+			`UPDATE zone_records SET ttl = $1, updated_at = now() WHERE id = $2`, ttl, r.id,
+		); err != nil {
+			return 0, fmt.Errorf("update ttl of %s %s: %w", r.recType, r.name, err)
+		}
+	}
+
+	return maxTTL, nil
+}
+
 // --- helpers ---
 
 // autoRecordDef defines a single auto-managed DNS record to create.
@@ -636,6 +897,62 @@ func (a *DNS) createAutoRecord(ctx context.Context, def autoRecordDef) error {
 	return nil
 }
 
+// DNS01ChallengeParams identifies the _acme-challenge TXT record for a domain.
+type DNS01ChallengeParams struct {
+	Domain string `json:"domain"`
+	Value  string `json:"value"`
+}
+
+// PlaceDNS01Challenge publishes the ACME DNS-01 TXT record for a domain in
+// its platform-managed zone. The record is written to PowerDNS only; it is
+// transient and not tracked in zone_records.
+func (a *DNS) PlaceDNS01Challenge(ctx context.Context, params DNS01ChallengeParams) error {
+	domainID, err := a.dns01ZoneID(ctx, params.Domain)
+	if err != nil {
+		return err
+	}
+	return a.powerdnsDB.WriteDNSRecord(ctx, WriteDNSRecordParams{
+		DomainID: domainID,
+		Name:     "_acme-challenge." + params.Domain,
+		Type:     "TXT",
+		Content:  strconv.Quote(params.Value),
+		TTL:      60,
+	})
+}
+
+// CleanupDNS01Challenge removes the ACME DNS-01 TXT record for a domain.
+func (a *DNS) CleanupDNS01Challenge(ctx context.Context, params DNS01ChallengeParams) error {
+	domainID, err := a.dns01ZoneID(ctx, params.Domain)
+	if err != nil {
+		return err
+	}
+	return a.powerdnsDB.DeleteDNSRecord(ctx, DeleteDNSRecordParams{
+		DomainID: domainID,
+		Name:     "_acme-challenge." + params.Domain,
+		Type:     "TXT",
+	})
+}
+
+// dns01ZoneID returns the PowerDNS domain ID of the zone serving domain.
+func (a *DNS) dns01ZoneID(ctx context.Context, domain string) (int, error) {
+	zoneName, err := a.findZoneForFQDN(ctx, domain)
+	if err != nil {
+		return 0, fmt.Errorf("find zone for %s: %w", domain, err)
+	}
+	if zoneName == "" {
+		return 0, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("no platform-managed zone for %s; DNS-01 validation requires one", domain), "NO_ZONE", nil)
+	}
+	domainID, err := a.powerdnsDB.GetDNSZoneIDByName(ctx, zoneName)
+	if err != nil {
+		return 0, fmt.Errorf("get dns zone id: %w", err)
+	}
+	if domainID == 0 {
+		return 0, fmt.Errorf("zone %s not found in PowerDNS", zoneName)
+	}
+	return domainID, nil
+}
+
 // findZoneForFQDN walks up the domain hierarchy to find a zone managed by
 // this platform. For example, for "www.example.com" it checks "www.example.com",
 // then "example.com", then "com".
@@ -658,6 +975,24 @@ func (a *DNS) findZoneForFQDN(ctx context.Context, fqdn string) (string, error)
 	return "", nil
 }
 
+// zoneBrandTTLs returns the TTL policy of the brand owning a zone. Only the
+// DNSTTLs and DNSMigrationTTL fields are set; an unknown zone yields the
+// platform defaults.
+func (a *DNS) zoneBrandTTLs(ctx context.Context, zoneName string) (*model.Brand, error) {
+	brand := &model.Brand{DNSMigrationTTL: model.DefaultDNSMigrationTTL}
+	err := a.coreDB.QueryRow(ctx,
+		`SELECT b.dns_ttls, b.dns_migration_ttl FROM zones z JOIN brands b ON b.id = z.brand_id
+		 WHERE z.name = $1 ORDER BY z.status = 'active' DESC LIMIT 1`, zoneName,
+	).Scan(&brand.DNSTTLs, &brand.DNSMigrationTTL)
+	if err == pgx.ErrNoRows {
+		return brand, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return brand, nil
+}
+
 // hasCustomRecord checks if a custom-managed DNS record exists for the given
 // FQDN and record types in the core DB.
 func (a *DNS) hasCustomRecord(ctx context.Context, fqdn string, types ...string) (bool, error) {
@@ -0,0 +1,55 @@
+package activity
+
+import (
+	"context"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+
+	"github.com/edvin/hosting/internal/objectstore"
+)
+
+// exportTransferURLTTL bounds how long a node has to start transferring an
+// export object.
+const exportTransferURLTTL = time.Hour
+
+// ExportStorage contains activities for the object storage holding tenant
+// export archives. The worker holds the bucket credentials; nodes only ever
+// receive presigned URLs.
+type ExportStorage struct {
+	bucket *objectstore.Bucket
+}
+
+// NewExportStorage creates a new ExportStorage activity struct. A nil bucket
+// makes every activity fail with a non-retryable error.
+func NewExportStorage(bucket *objectstore.Bucket) *ExportStorage {
+	return &ExportStorage{bucket: bucket}
+}
+
+// PresignTenantExportUpload returns a presigned PUT URL for the export object.
+func (a *ExportStorage) PresignTenantExportUpload(ctx context.Context, objectKey string) (string, error) {
+	if a.bucket == nil {
+		return "", errExportStorageNotConfigured()
+	}
+	return a.bucket.PresignPut(ctx, objectKey, exportTransferURLTTL)
+}
+
+// PresignTenantExportDownload returns a presigned GET URL for the export object.
+func (a *ExportStorage) PresignTenantExportDownload(ctx context.Context, objectKey string) (string, error) {
+	if a.bucket == nil {
+		return "", errExportStorageNotConfigured()
+	}
+	return a.bucket.PresignGet(ctx, objectKey, exportTransferURLTTL)
+}
+
+// DeleteTenantExportObject deletes an export archive from object storage.
+func (a *ExportStorage) DeleteTenantExportObject(ctx context.Context, objectKey string) error {
+	if a.bucket == nil {
+		return errExportStorageNotConfigured()
+	}
+	return a.bucket.Delete(ctx, objectKey)
+}
+
+func errExportStorageNotConfigured() error {
+	return temporal.NewNonRetryableApplicationError("export storage is not configured", "EXPORT_STORAGE_NOT_CONFIGURED", nil)
+}
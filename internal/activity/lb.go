@@ -12,11 +12,12 @@ import (
 	"github.com/rs/zerolog"
 )
 
-// mapFileMu serializes writes to the on-disk map file.
+// mapFileMu serializes writes to the on-disk map files.
 var mapFileMu sync.Mutex
 
 const (
 	haproxyMapPath      = "/var/lib/haproxy/maps/fqdn-to-shard.map"
+	haproxySplitMapPath = "/var/lib/haproxy/maps/fqdn-split.map"
 	haproxyRuntimeAddr  = "localhost:9999"
 )
 
@@ -32,10 +33,14 @@ func NewNodeLB(logger zerolog.Logger) *NodeLB {
 	return &NodeLB{logger: logger}
 }
 
-// SetLBMapEntryParams holds parameters for SetLBMapEntry.
+// SetLBMapEntryParams holds parameters for SetLBMapEntry. When SplitWeight
+// is set, SplitWeight percent of the FQDN's requests go to SplitBackend
+// instead of LBBackend.
 type SetLBMapEntryParams struct {
-	FQDN      string `json:"fqdn"`
-	LBBackend string `json:"lb_backend"`
+	FQDN         string `json:"fqdn"`
+	LBBackend    string `json:"lb_backend"`
+	SplitBackend string `json:"split_backend,omitempty"`
+	SplitWeight  int    `json:"split_weight,omitempty"`
 }
 
 // DeleteLBMapEntryParams holds parameters for DeleteLBMapEntry.
@@ -45,8 +50,9 @@ type DeleteLBMapEntryParams struct {
 
 // SetLBMapEntry sets a mapping from an FQDN to an LB backend in the HAProxy map file
 // via the Runtime API. It uses "set map" to update existing entries, falling back
-// to "add map" for new entries. The on-disk map file is also updated so entries
-// survive HAProxy restarts.
+// to "add map" for new entries. The FQDN's entry in the split map is set or
+// removed to match SplitWeight. The on-disk map files are also updated so
+// entries survive HAProxy restarts.
 func (a *NodeLB) SetLBMapEntry(ctx context.Context, params SetLBMapEntryParams) error {
 	// Strip trailing dot from FQDN — HAProxy matches against the Host header
 	// which never includes the DNS trailing dot.
@@ -54,84 +60,133 @@ func (a *NodeLB) SetLBMapEntry(ctx context.Context, params SetLBMapEntryParams)
 
 	a.logger.Info().Str("fqdn", fqdn).Str("backend", params.LBBackend).Msg("setting LB map entry")
 
-	// Try "set map" first (updates existing entry).
-	resp, err := haproxyCommand(haproxyRuntimeAddr, fmt.Sprintf("set map %s %s %s\n", haproxyMapPath, fqdn, params.LBBackend))
+	if err := setRuntimeMapEntry(haproxyRuntimeAddr, haproxyMapPath, fqdn, params.LBBackend); err != nil {
+		return fmt.Errorf("map entry %s -> %s: %w", fqdn, params.LBBackend, err)
+	}
+
+	// Persist to on-disk map file so entries survive HAProxy restarts.
+	if err := persistMapEntry(haproxyMapPath, fqdn, params.LBBackend); err != nil {
+		a.logger.Warn().Err(err).Str("fqdn", fqdn).Msg("failed to persist map entry to disk (runtime entry is set)")
+	}
+
+	if params.SplitWeight > 0 && params.SplitBackend != "" {
+		value := splitMapValue(params.SplitBackend, params.SplitWeight)
+		a.logger.Info().Str("fqdn", fqdn).Str("split", value).Msg("setting LB split entry")
+		if err := setRuntimeMapEntry(haproxyRuntimeAddr, haproxySplitMapPath, fqdn, value); err != nil {
+			return fmt.Errorf("split entry %s -> %s: %w", fqdn, value, err)
+		}
+		if err := persistMapEntry(haproxySplitMapPath, fqdn, value); err != nil {
+			a.logger.Warn().Err(err).Str("fqdn", fqdn).Msg("failed to persist split entry to disk (runtime entry is set)")
+		}
+		return nil
+	}
+
+	return a.deleteSplitEntry(fqdn)
+}
+
+// DeleteLBMapEntry removes an FQDN mapping, and its split entry if any, from
+// the HAProxy map files via the Runtime API. The on-disk map files are also
+// updated.
+func (a *NodeLB) DeleteLBMapEntry(ctx context.Context, params DeleteLBMapEntryParams) error {
+	fqdn := strings.TrimSuffix(params.FQDN, ".")
+
+	a.logger.Info().Str("fqdn", fqdn).Msg("deleting LB map entry")
+
+	if err := delRuntimeMapEntry(haproxyRuntimeAddr, haproxyMapPath, fqdn); err != nil {
+		return fmt.Errorf("del map entry %s: %w", fqdn, err)
+	}
+
+	// Remove from on-disk map file.
+	if err := removeMapEntry(haproxyMapPath, fqdn); err != nil {
+		a.logger.Warn().Err(err).Str("fqdn", fqdn).Msg("failed to remove map entry from disk (runtime entry is deleted)")
+	}
+
+	return a.deleteSplitEntry(fqdn)
+}
+
+// deleteSplitEntry removes an FQDN from the split map, sending all of its
+// requests to the backend in the main map.
+func (a *NodeLB) deleteSplitEntry(fqdn string) error {
+	if err := delRuntimeMapEntry(haproxyRuntimeAddr, haproxySplitMapPath, fqdn); err != nil {
+		return fmt.Errorf("del split entry %s: %w", fqdn, err)
+	}
+	if err := removeMapEntry(haproxySplitMapPath, fqdn); err != nil {
+		a.logger.Warn().Err(err).Str("fqdn", fqdn).Msg("failed to remove split entry from disk (runtime entry is deleted)")
+	}
+	return nil
+}
+
+// splitMapValue encodes a split map value. The HAProxy frontend reads the
+// backend and weight back with field(1,|) and field(2,|).
+func splitMapValue(backend string, weight int) string {
+	return fmt.Sprintf("%s|%d", backend, weight)
+}
+
+// setRuntimeMapEntry sets key to value in a map via the Runtime API, using
+// "set map" for existing entries and falling back to "add map" for new ones.
+func setRuntimeMapEntry(addr, path, key, value string) error {
+	resp, err := haproxyCommand(addr, fmt.Sprintf("set map %s %s %s\n", path, key, value))
 	if err != nil {
-		return fmt.Errorf("set map entry %s -> %s: %w", fqdn, params.LBBackend, err)
+		return fmt.Errorf("set map: %w", err)
 	}
 
 	if strings.Contains(strings.ToLower(resp), "not found") {
 		// Entry doesn't exist yet — use "add map" to create it.
-		resp, err = haproxyCommand(haproxyRuntimeAddr, fmt.Sprintf("add map %s %s %s\n", haproxyMapPath, fqdn, params.LBBackend))
+		resp, err = haproxyCommand(addr, fmt.Sprintf("add map %s %s %s\n", path, key, value))
 		if err != nil {
-			return fmt.Errorf("add map entry %s -> %s: %w", fqdn, params.LBBackend, err)
+			return fmt.Errorf("add map: %w", err)
 		}
 		resp = strings.TrimSpace(resp)
 		if resp != "" && strings.Contains(strings.ToLower(resp), "err") {
-			return fmt.Errorf("add map entry %s -> %s: %s", fqdn, params.LBBackend, resp)
+			return fmt.Errorf("add map: %s", resp)
 		}
 	}
-
-	// Persist to on-disk map file so entries survive HAProxy restarts.
-	if err := persistMapEntry(fqdn, params.LBBackend); err != nil {
-		a.logger.Warn().Err(err).Str("fqdn", fqdn).Msg("failed to persist map entry to disk (runtime entry is set)")
-	}
-
 	return nil
 }
 
-// DeleteLBMapEntry removes an FQDN mapping from the HAProxy map file
-// via the Runtime API. The on-disk map file is also updated.
-func (a *NodeLB) DeleteLBMapEntry(ctx context.Context, params DeleteLBMapEntryParams) error {
-	fqdn := strings.TrimSuffix(params.FQDN, ".")
-
-	a.logger.Info().Str("fqdn", fqdn).Msg("deleting LB map entry")
-
-	resp, err := haproxyCommand(haproxyRuntimeAddr, fmt.Sprintf("del map %s %s\n", haproxyMapPath, fqdn))
+// delRuntimeMapEntry removes key from a map via the Runtime API. A missing
+// entry is not an error.
+func delRuntimeMapEntry(addr, path, key string) error {
+	resp, err := haproxyCommand(addr, fmt.Sprintf("del map %s %s\n", path, key))
 	if err != nil {
-		return fmt.Errorf("del map entry %s: %w", fqdn, err)
+		return err
 	}
 
 	// Ignore "not found" — the entry may already be gone.
 	resp = strings.TrimSpace(resp)
 	if resp != "" && !strings.Contains(strings.ToLower(resp), "not found") && strings.Contains(strings.ToLower(resp), "err") {
-		return fmt.Errorf("del map entry %s: %s", fqdn, resp)
-	}
-
-	// Remove from on-disk map file.
-	if err := removeMapEntry(fqdn); err != nil {
-		a.logger.Warn().Err(err).Str("fqdn", fqdn).Msg("failed to remove map entry from disk (runtime entry is deleted)")
+		return fmt.Errorf("%s", resp)
 	}
 	return nil
 }
 
-// persistMapEntry adds or updates an FQDN→backend mapping in the on-disk map file.
-func persistMapEntry(fqdn, backend string) error {
+// persistMapEntry adds or updates a key→value mapping in an on-disk map file.
+func persistMapEntry(path, key, value string) error {
 	mapFileMu.Lock()
 	defer mapFileMu.Unlock()
 
-	entries, err := readMapFile(haproxyMapPath)
+	entries, err := readMapFile(path)
 	if err != nil {
 		return err
 	}
-	entries[fqdn] = backend
-	return writeMapFile(haproxyMapPath, entries)
+	entries[key] = value
+	return writeMapFile(path, entries)
 }
 
-// removeMapEntry deletes an FQDN from the on-disk map file.
-func removeMapEntry(fqdn string) error {
+// removeMapEntry deletes a key from an on-disk map file.
+func removeMapEntry(path, key string) error {
 	mapFileMu.Lock()
 	defer mapFileMu.Unlock()
 
-	entries, err := readMapFile(haproxyMapPath)
+	entries, err := readMapFile(path)
 	if err != nil {
 		return err
 	}
-	delete(entries, fqdn)
-	return writeMapFile(haproxyMapPath, entries)
+	delete(entries, key)
+	return writeMapFile(path, entries)
 }
 
-// readMapFile reads the HAProxy map file into a map of fqdn→backend.
+// readMapFile reads an HAProxy map file into a map of key→value.
 func readMapFile(path string) (map[string]string, error) {
 	entries := make(map[string]string)
 	data, err := os.ReadFile(path)
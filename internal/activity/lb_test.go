@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -115,5 +117,49 @@ func TestNodeLB_DeleteLBMapEntry_Activity(t *testing.T) {
 	require.Error(t, err, "should fail since no HAProxy is listening on localhost:9999")
 }
 
+func TestSetRuntimeMapEntry_FallbackToAdd(t *testing.T) {
+	var cmds []string
+	var mu sync.Mutex
+	addr, cleanup := mockHAProxy(t, func(cmd string) string {
+		mu.Lock()
+		cmds = append(cmds, cmd)
+		mu.Unlock()
+		if strings.HasPrefix(cmd, "set map") {
+			return "entry not found\n"
+		}
+		return "\n"
+	})
+	defer cleanup()
+
+	err := setRuntimeMapEntry(addr, haproxySplitMapPath, "example.com", splitMapValue("shard-web-2", 25))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"set map " + haproxySplitMapPath + " example.com shard-web-2|25",
+		"add map " + haproxySplitMapPath + " example.com shard-web-2|25",
+	}, cmds)
+}
+
+func TestDelRuntimeMapEntry_NotFoundIgnored(t *testing.T) {
+	addr, cleanup := mockHAProxy(t, func(cmd string) string {
+		return "entry not found\n"
+	})
+	defer cleanup()
+
+	require.NoError(t, delRuntimeMapEntry(addr, haproxySplitMapPath, "example.com"))
+}
+
+func TestMapFile_PersistAndRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fqdn-split.map")
+
+	require.NoError(t, persistMapEntry(path, "a.example.com", "shard-web-2|10"))
+	require.NoError(t, persistMapEntry(path, "b.example.com", "shard-web-2|50"))
+	require.NoError(t, persistMapEntry(path, "a.example.com", "shard-web-3|20"))
+	require.NoError(t, removeMapEntry(path, "b.example.com"))
+
+	entries, err := readMapFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a.example.com": "shard-web-3|20"}, entries)
+}
+
 // Suppress unused variable warning for time import (used by other tests in package).
 var _ = time.Now
@@ -0,0 +1,86 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// AgentVersion is the node agent's version, set at build time with
+// -ldflags "-X github.com/edvin/hosting/internal/activity.AgentVersion=...".
+var AgentVersion = "dev"
+
+// nodeAgentActivities are the activity structs the node agent registers.
+// Temporal registers every exported method, so their method sets are what
+// the agent can run.
+var nodeAgentActivities = []any{&NodeLocal{}, &NodeACMEActivity{}, &NodeLB{}}
+
+// ReportNodeCapabilities returns this agent's version and the names of the
+// activities it registers.
+func (a *NodeLocal) ReportNodeCapabilities(ctx context.Context) (*model.NodeCapabilities, error) {
+	return &model.NodeCapabilities{
+		AgentVersion: AgentVersion,
+		Activities:   nodeAgentActivityNames(),
+	}, nil
+}
+
+// nodeAgentActivityNames returns the sorted names of the activities the node
+// agent registers.
+func nodeAgentActivityNames() []string {
+	var names []string
+	for _, acts := range nodeAgentActivities {
+		t := reflect.TypeOf(acts)
+		for i := 0; i < t.NumMethod(); i++ {
+			names = append(names, t.Method(i).Name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// UpsertNodeCapabilities stores the latest capability report for a node.
+func (a *CoreDB) UpsertNodeCapabilities(ctx context.Context, caps model.NodeCapabilities) error {
+	activities := caps.Activities
+	if activities == nil {
+		activities = []string{}
+	}
+	_, err := a.db.Exec(ctx,
+		`INSERT INTO node_capabilities (node_id, agent_version, activities, reported_at)
+		 VALUES ($1, $2, $3, now())
+		 ON CONFLICT (node_id) DO UPDATE SET
+		   agent_version = EXCLUDED.agent_version, activities = EXCLUDED.activities, reported_at = now()`,
+		caps.NodeID, caps.AgentVersion, activities,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert node capabilities for %s: %w", caps.NodeID, err)
+	}
+	return nil
+}
+
+// ListNodesMissingActivity returns the nodes among params.NodeIDs whose agent
+// last reported that it does not register params.Activity. Nodes that have not
+// reported are not returned.
+func (a *CoreDB) ListNodesMissingActivity(ctx context.Context, params ListNodesMissingActivityParams) ([]string, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT node_id FROM node_capabilities
+		 WHERE node_id = ANY($1) AND NOT ($2 = ANY(activities))`,
+		params.NodeIDs, params.Activity,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list nodes missing activity %s: %w", params.Activity, err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan node id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,18 @@
+package activity
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeAgentActivityNames(t *testing.T) {
+	names := nodeAgentActivityNames()
+	assert.True(t, slices.IsSorted(names))
+	assert.Contains(t, names, "ReportNodeCapabilities")
+	assert.Contains(t, names, "CreateValkeyInstance")
+	assert.Contains(t, names, "PlaceHTTP01Challenge")
+	assert.Contains(t, names, "SetLBMapEntry")
+	assert.NotContains(t, names, "deleteSplitEntry")
+}
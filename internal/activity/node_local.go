@@ -4,22 +4,28 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
-	"os/exec"
 	"os/user"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/rs/zerolog"
+	"go.temporal.io/sdk/activity"
 	"go.temporal.io/sdk/temporal"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/edvin/hosting/internal/agent"
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
 	"github.com/edvin/hosting/internal/agent/runtime"
+	"github.com/edvin/hosting/internal/logging"
+	"github.com/edvin/hosting/internal/model"
 )
 
 // grpcStatusError is the interface implemented by gRPC status errors.
@@ -88,7 +94,7 @@ func NewNodeLocal(
 	runtimes map[string]runtime.Manager,
 ) *NodeLocal {
 	return &NodeLocal{
-		logger:    logger.With().Str("component", "node-local-activity").Logger(),
+		logger:    logging.Component(logger, "node-local-activity"),
 		tenant:    tenant,
 		webroot:   webroot,
 		nginx:     nginx,
@@ -151,6 +157,24 @@ func (a *NodeLocal) DeleteTenant(ctx context.Context, name string) error {
 	return asNonRetryable(a.tenant.Delete(ctx, name))
 }
 
+// fixTenantPermissionsBudget bounds a single FixTenantPermissions run so it
+// finishes well within the node activity timeout. A run that hits it
+// reports Truncated and can simply be repeated.
+const fixTenantPermissionsBudget = 90 * time.Second
+
+// FixTenantPermissions reconciles the ownership and modes of a tenant's
+// storage tree, heartbeating the number of entries scanned.
+func (a *NodeLocal) FixTenantPermissions(ctx context.Context, params FixTenantPermissionsParams) (*model.TenantPermissionsFix, error) {
+	a.logger.Info().Str("tenant", params.TenantName).Int("max_changes", params.MaxChanges).Msg("FixTenantPermissions")
+	res, err := a.tenant.FixPermissions(ctx, params.TenantName, params.MaxChanges, fixTenantPermissionsBudget, func(scanned int) {
+		activity.RecordHeartbeat(ctx, scanned)
+	})
+	if err != nil {
+		return nil, asNonRetryable(err)
+	}
+	return res, nil
+}
+
 // --------------------------------------------------------------------------
 // Webroot activities
 // --------------------------------------------------------------------------
@@ -167,15 +191,27 @@ func (a *NodeLocal) CreateWebroot(ctx context.Context, params CreateWebrootParam
 		RuntimeVersion: params.RuntimeVersion,
 		RuntimeConfig:  params.RuntimeConfig,
 		PublicFolder:   params.PublicFolder,
+		ErrorPages:     params.ErrorPages,
+		BasicAuth:      params.BasicAuth,
 		EnvVars:        params.EnvVars,
+		AccessLog:      params.AccessLog,
+		Limits:         params.Limits,
+		StaticRules:    params.StaticRules,
+		GeoBlocking:    params.GeoBlocking,
+		DeployLock:     params.DeployLock,
 	}
+	info.Releases = a.webroot.HasReleases(info.TenantName, info.Name)
 
 	fqdns := make([]*agent.FQDNInfo, len(params.FQDNs))
 	for i, f := range params.FQDNs {
 		fqdns[i] = &agent.FQDNInfo{
-			FQDN:       f.FQDN,
-			WebrootID:  f.WebrootID,
-			SSLEnabled: f.SSLEnabled,
+			FQDN:                 f.FQDN,
+			WebrootID:            f.WebrootID,
+			SSLEnabled:           f.SSLEnabled,
+			ForceHTTPS:           f.ForceHTTPS,
+			RedirectTarget:       f.RedirectTarget,
+			RedirectCode:         f.RedirectCode,
+			RedirectPreservePath: f.RedirectPreservePath,
 		}
 	}
 
@@ -207,6 +243,17 @@ func (a *NodeLocal) CreateWebroot(ctx context.Context, params CreateWebrootParam
 		daemonProxies[i] = agent.DaemonProxyInfo{ProxyPath: d.ProxyPath, Port: d.Port, TargetIP: d.TargetIP, ProxyURL: d.ProxyURL}
 	}
 
+	// Write the htpasswd file before the config that references it.
+	if err := a.nginx.WriteHtpasswd(info); err != nil {
+		return asNonRetryable(fmt.Errorf("write htpasswd: %w", err))
+	}
+	if err := a.nginx.EnsureAccessLogDir(info); err != nil {
+		return asNonRetryable(fmt.Errorf("create access log dir: %w", err))
+	}
+	if err := a.nginx.EnsureGeoIP(info); err != nil {
+		return asNonRetryable(fmt.Errorf("prepare geo-blocking: %w", err))
+	}
+
 	// Generate and write nginx config.
 	nginxConfig, err := a.nginx.GenerateConfig(info, fqdns, daemonProxies...)
 	if err != nil {
@@ -236,15 +283,27 @@ func (a *NodeLocal) UpdateWebroot(ctx context.Context, params UpdateWebrootParam
 		RuntimeVersion: params.RuntimeVersion,
 		RuntimeConfig:  params.RuntimeConfig,
 		PublicFolder:   params.PublicFolder,
+		ErrorPages:     params.ErrorPages,
+		BasicAuth:      params.BasicAuth,
 		EnvVars:        params.EnvVars,
+		AccessLog:      params.AccessLog,
+		Limits:         params.Limits,
+		StaticRules:    params.StaticRules,
+		GeoBlocking:    params.GeoBlocking,
+		DeployLock:     params.DeployLock,
 	}
+	info.Releases = a.webroot.HasReleases(info.TenantName, info.Name)
 
 	fqdns := make([]*agent.FQDNInfo, len(params.FQDNs))
 	for i, f := range params.FQDNs {
 		fqdns[i] = &agent.FQDNInfo{
-			FQDN:       f.FQDN,
-			WebrootID:  f.WebrootID,
-			SSLEnabled: f.SSLEnabled,
+			FQDN:                 f.FQDN,
+			WebrootID:            f.WebrootID,
+			SSLEnabled:           f.SSLEnabled,
+			ForceHTTPS:           f.ForceHTTPS,
+			RedirectTarget:       f.RedirectTarget,
+			RedirectCode:         f.RedirectCode,
+			RedirectPreservePath: f.RedirectPreservePath,
 		}
 	}
 
@@ -276,6 +335,17 @@ func (a *NodeLocal) UpdateWebroot(ctx context.Context, params UpdateWebrootParam
 		daemonProxies[i] = agent.DaemonProxyInfo{ProxyPath: d.ProxyPath, Port: d.Port, TargetIP: d.TargetIP, ProxyURL: d.ProxyURL}
 	}
 
+	// Write the htpasswd file before the config that references it.
+	if err := a.nginx.WriteHtpasswd(info); err != nil {
+		return asNonRetryable(fmt.Errorf("write htpasswd: %w", err))
+	}
+	if err := a.nginx.EnsureAccessLogDir(info); err != nil {
+		return asNonRetryable(fmt.Errorf("create access log dir: %w", err))
+	}
+	if err := a.nginx.EnsureGeoIP(info); err != nil {
+		return asNonRetryable(fmt.Errorf("prepare geo-blocking: %w", err))
+	}
+
 	// Regenerate and write nginx config.
 	nginxConfig, err := a.nginx.GenerateConfig(info, fqdns, daemonProxies...)
 	if err != nil {
@@ -293,6 +363,66 @@ func (a *NodeLocal) UpdateWebroot(ctx context.Context, params UpdateWebrootParam
 	return nil
 }
 
+// PreviewNginxConfig renders the nginx server block this node would write for
+// the webroot, without writing it or reloading nginx.
+func (a *NodeLocal) PreviewNginxConfig(ctx context.Context, params UpdateWebrootParams) (string, error) {
+	a.logger.Info().Str("tenant", params.TenantName).Str("webroot", params.Name).Msg("PreviewNginxConfig")
+
+	info := &runtime.WebrootInfo{
+		ID:             params.ID,
+		TenantName:     params.TenantName,
+		Name:           params.Name,
+		Runtime:        params.Runtime,
+		RuntimeVersion: params.RuntimeVersion,
+		RuntimeConfig:  params.RuntimeConfig,
+		PublicFolder:   params.PublicFolder,
+		ErrorPages:     params.ErrorPages,
+		BasicAuth:      params.BasicAuth,
+		EnvVars:        params.EnvVars,
+		AccessLog:      params.AccessLog,
+		Limits:         params.Limits,
+		StaticRules:    params.StaticRules,
+		GeoBlocking:    params.GeoBlocking,
+		DeployLock:     params.DeployLock,
+	}
+	info.Releases = a.webroot.HasReleases(info.TenantName, info.Name)
+
+	fqdns := make([]*agent.FQDNInfo, len(params.FQDNs))
+	for i, f := range params.FQDNs {
+		fqdns[i] = &agent.FQDNInfo{
+			FQDN:                 f.FQDN,
+			WebrootID:            f.WebrootID,
+			SSLEnabled:           f.SSLEnabled,
+			ForceHTTPS:           f.ForceHTTPS,
+			RedirectTarget:       f.RedirectTarget,
+			RedirectCode:         f.RedirectCode,
+			RedirectPreservePath: f.RedirectPreservePath,
+		}
+	}
+
+	daemonProxies := make([]agent.DaemonProxyInfo, len(params.Daemons))
+	for i, d := range params.Daemons {
+		daemonProxies[i] = agent.DaemonProxyInfo{ProxyPath: d.ProxyPath, Port: d.Port, TargetIP: d.TargetIP, ProxyURL: d.ProxyURL}
+	}
+
+	nginxConfig, err := a.nginx.GenerateConfig(info, fqdns, daemonProxies...)
+	if err != nil {
+		return "", asNonRetryable(fmt.Errorf("generate nginx config: %w", err))
+	}
+	return nginxConfig, nil
+}
+
+// ReadWebrootAccessLog returns the last requests from a webroot's shared
+// access logs. The logs of every node are on shared storage, so any node of
+// the shard can read them all.
+func (a *NodeLocal) ReadWebrootAccessLog(ctx context.Context, params ReadWebrootAccessLogParams) ([]model.WebrootAccessLogLine, error) {
+	lines, err := a.nginx.ReadAccessLog(params.TenantName, params.Name, params.Tail)
+	if err != nil {
+		return nil, asNonRetryable(err)
+	}
+	return lines, nil
+}
+
 // DeleteWebroot deletes a webroot locally on this node.
 func (a *NodeLocal) DeleteWebroot(ctx context.Context, tenantName, webrootName string) error {
 	a.logger.Info().Str("tenant", tenantName).Str("webroot", webrootName).Msg("DeleteWebroot")
@@ -307,6 +437,10 @@ func (a *NodeLocal) DeleteWebroot(ctx context.Context, tenantName, webrootName s
 		return asNonRetryable(fmt.Errorf("reload nginx: %w", err))
 	}
 
+	if err := a.nginx.RemoveHtpasswd(tenantName, webrootName); err != nil {
+		return asNonRetryable(fmt.Errorf("remove htpasswd: %w", err))
+	}
+
 	// Remove runtimes (try all, only one will match).
 	wrInfo := &runtime.WebrootInfo{TenantName: tenantName, Name: webrootName}
 	for _, rt := range a.runtimes {
@@ -321,6 +455,44 @@ func (a *NodeLocal) DeleteWebroot(ctx context.Context, tenantName, webrootName s
 	return nil
 }
 
+// CheckErrorPages returns the configured custom error page paths of a webroot
+// that were left out of the nginx config because the file is missing.
+func (a *NodeLocal) CheckErrorPages(ctx context.Context, params CheckErrorPagesParams) ([]string, error) {
+	return a.nginx.MissingErrorPages(&runtime.WebrootInfo{
+		TenantName:   params.TenantName,
+		Name:         params.Name,
+		PublicFolder: params.PublicFolder,
+		ErrorPages:   params.ErrorPages,
+		Releases:     a.webroot.HasReleases(params.TenantName, params.Name),
+	}), nil
+}
+
+// CreateWebrootRelease creates an empty release directory for a deploy.
+func (a *NodeLocal) CreateWebrootRelease(ctx context.Context, params WebrootReleaseParams) error {
+	a.logger.Info().Str("tenant", params.TenantName).Str("webroot", params.WebrootName).Str("release", params.Release).Msg("CreateWebrootRelease")
+	return asNonRetryable(a.webroot.CreateRelease(ctx, params.TenantName, params.WebrootName, params.Release))
+}
+
+// PromoteWebrootRelease atomically swaps the webroot's current symlink to the
+// release. Storage is shared, so this runs on one node of the shard.
+func (a *NodeLocal) PromoteWebrootRelease(ctx context.Context, params WebrootReleaseParams) error {
+	a.logger.Info().Str("tenant", params.TenantName).Str("webroot", params.WebrootName).Str("release", params.Release).Msg("PromoteWebrootRelease")
+	return asNonRetryable(a.webroot.PromoteRelease(ctx, params.TenantName, params.WebrootName, params.Release))
+}
+
+// DeleteWebrootRelease removes a release directory that is not live.
+func (a *NodeLocal) DeleteWebrootRelease(ctx context.Context, params WebrootReleaseParams) error {
+	a.logger.Info().Str("tenant", params.TenantName).Str("webroot", params.WebrootName).Str("release", params.Release).Msg("DeleteWebrootRelease")
+	return asNonRetryable(a.webroot.DeleteRelease(ctx, params.TenantName, params.WebrootName, params.Release))
+}
+
+// CopyWebrootFiles copies a webroot's files into another webroot of the same
+// tenant. Storage is shared, so this runs on one node of the shard.
+func (a *NodeLocal) CopyWebrootFiles(ctx context.Context, params CopyWebrootFilesParams) error {
+	a.logger.Info().Str("tenant", params.TenantName).Str("source", params.SourceName).Str("target", params.TargetName).Msg("CopyWebrootFiles")
+	return asNonRetryable(a.webroot.CopyFiles(ctx, params.TenantName, params.SourceName, params.TargetName, params.Exclude))
+}
+
 // --------------------------------------------------------------------------
 // Runtime / Nginx activities
 // --------------------------------------------------------------------------
@@ -339,6 +511,7 @@ func (a *NodeLocal) ConfigureRuntime(ctx context.Context, params ConfigureRuntim
 		PublicFolder:   params.PublicFolder,
 		EnvVars:        params.EnvVars,
 	}
+	info.Releases = a.webroot.HasReleases(info.TenantName, info.Name)
 
 	rt, ok := a.runtimes[info.Runtime]
 	if !ok {
@@ -353,6 +526,13 @@ func (a *NodeLocal) ConfigureRuntime(ctx context.Context, params ConfigureRuntim
 	return nil
 }
 
+// GetInstalledRuntimes reports the runtime versions installed on this node.
+func (a *NodeLocal) GetInstalledRuntimes(ctx context.Context) ([]model.InstalledRuntime, error) {
+	installed := runtime.DetectInstalled(ctx)
+	a.logger.Info().Int("count", len(installed)).Msg("GetInstalledRuntimes")
+	return installed, nil
+}
+
 // CleanOrphanedConfigs removes nginx config files that are not in the expected set.
 func (a *NodeLocal) CleanOrphanedConfigs(ctx context.Context, input CleanOrphanedConfigsInput) (CleanOrphanedConfigsResult, error) {
 	a.logger.Info().Int("expected_count", len(input.ExpectedConfigs)).Msg("CleanOrphanedConfigs")
@@ -391,7 +571,7 @@ func (a *NodeLocal) CleanOrphanedFPMPools(ctx context.Context, input CleanOrphan
 // This is needed after removing stale daemon configs that prevented supervisord from starting.
 func (a *NodeLocal) RestartSupervisord(ctx context.Context) error {
 	a.logger.Info().Msg("RestartSupervisord")
-	cmd := exec.CommandContext(ctx, "systemctl", "restart", "supervisor")
+	cmd := cmdaudit.CommandContext(ctx, "systemctl", "restart", "supervisor")
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return asNonRetryable(fmt.Errorf("restart supervisor: %s: %w", string(output), err))
 	}
@@ -404,6 +584,27 @@ func (a *NodeLocal) ReloadNginx(ctx context.Context) error {
 	return asNonRetryable(a.nginx.Reload(ctx))
 }
 
+// ApplyNginxBaseConfig writes the base nginx.conf for the shard's tuning and
+// reloads nginx if it changed, independently of the site configs.
+func (a *NodeLocal) ApplyNginxBaseConfig(ctx context.Context, params ApplyNginxBaseConfigParams) (ApplyNginxBaseConfigResult, error) {
+	a.logger.Info().Msg("ApplyNginxBaseConfig")
+	changed, err := a.nginx.WriteBaseConfig(ctx, params.Tuning)
+	if err != nil {
+		return ApplyNginxBaseConfigResult{}, asNonRetryable(err)
+	}
+	proxyChanged, err := a.nginx.SetProxyProtocol(params.RealIPFrom)
+	if err != nil {
+		return ApplyNginxBaseConfigResult{}, asNonRetryable(err)
+	}
+	changed = changed || proxyChanged
+	if changed {
+		if err := a.nginx.Reload(ctx); err != nil {
+			return ApplyNginxBaseConfigResult{}, asNonRetryable(err)
+		}
+	}
+	return ApplyNginxBaseConfigResult{Changed: changed}, nil
+}
+
 // ReloadPHPFPM gracefully reloads all PHP-FPM services.
 func (a *NodeLocal) ReloadPHPFPM(ctx context.Context) error {
 	a.logger.Info().Msg("ReloadPHPFPM")
@@ -419,9 +620,9 @@ func (a *NodeLocal) ReloadPHPFPM(ctx context.Context) error {
 // --------------------------------------------------------------------------
 
 // CreateDatabase creates a MySQL database locally on this node.
-func (a *NodeLocal) CreateDatabase(ctx context.Context, name string) error {
-	a.logger.Info().Str("database", name).Msg("CreateDatabase")
-	return asNonRetryable(a.database.CreateDatabase(ctx, name))
+func (a *NodeLocal) CreateDatabase(ctx context.Context, params CreateDatabaseParams) error {
+	a.logger.Info().Str("database", params.Name).Str("charset", params.Charset).Str("collation", params.Collation).Msg("CreateDatabase")
+	return asNonRetryable(a.database.CreateDatabase(ctx, params.Name, params.Charset, params.Collation))
 }
 
 // DeleteDatabase drops a MySQL database locally on this node.
@@ -454,6 +655,12 @@ func (a *NodeLocal) CreateTempMySQLUser(ctx context.Context, params CreateTempMy
 	return asNonRetryable(a.database.CreateTempUser(ctx, params.DatabaseName, params.Username, params.PasswordHash))
 }
 
+// DropTempMySQLUser drops a temporary MySQL user and kills its connections.
+func (a *NodeLocal) DropTempMySQLUser(ctx context.Context, username string) error {
+	a.logger.Info().Str("username", username).Msg("DropTempMySQLUser")
+	return asNonRetryable(a.database.DropTempUser(ctx, username))
+}
+
 // ConfigureReplication sets up this node as a replica of the given primary.
 func (a *NodeLocal) ConfigureReplication(ctx context.Context, params ConfigureReplicationParams) error {
 	a.logger.Info().Str("primary", params.PrimaryHost).Msg("ConfigureReplication")
@@ -482,6 +689,23 @@ func (a *NodeLocal) StopReplication(ctx context.Context) error {
 	return asNonRetryable(a.database.StopReplication(ctx))
 }
 
+// ListDatabaseConnections lists the MySQL connections of the given users on this node.
+func (a *NodeLocal) ListDatabaseConnections(ctx context.Context, params ListDatabaseConnectionsParams) ([]model.DatabaseConnection, error) {
+	a.logger.Info().Strs("users", params.Users).Msg("ListDatabaseConnections")
+	conns, err := a.database.ListProcesses(ctx, params.Users)
+	if err != nil {
+		return nil, asNonRetryable(err)
+	}
+	return conns, nil
+}
+
+// KillDatabaseConnection kills a MySQL connection on this node if it belongs
+// to one of the given users.
+func (a *NodeLocal) KillDatabaseConnection(ctx context.Context, params KillDatabaseConnectionParams) error {
+	a.logger.Info().Int64("connection", params.ConnectionID).Msg("KillDatabaseConnection")
+	return asNonRetryable(a.database.KillProcess(ctx, params.ConnectionID, params.Users))
+}
+
 // --------------------------------------------------------------------------
 // Valkey activities
 // --------------------------------------------------------------------------
@@ -489,7 +713,7 @@ func (a *NodeLocal) StopReplication(ctx context.Context) error {
 // CreateValkeyInstance creates a Valkey instance locally on this node.
 func (a *NodeLocal) CreateValkeyInstance(ctx context.Context, params CreateValkeyInstanceParams) error {
 	a.logger.Info().Str("instance", params.Name).Msg("CreateValkeyInstance")
-	return asNonRetryable(a.valkey.CreateInstance(ctx, params.Name, params.Port, params.PasswordHash, params.MaxMemoryMB))
+	return asNonRetryable(a.valkey.CreateInstance(ctx, params.Name, params.Port, params.PasswordHash, params.MaxMemoryMB, params.PersistenceMode))
 }
 
 // DeleteValkeyInstance deletes a Valkey instance locally on this node.
@@ -532,6 +756,32 @@ func (a *NodeLocal) ImportMySQLDatabase(ctx context.Context, params ImportMySQLD
 	return asNonRetryable(a.database.ImportDatabase(ctx, params.DatabaseName, params.DumpPath))
 }
 
+// GrantMySQLMigrationAccess lets a target node stream the database from this
+// node with StreamMySQLDatabase.
+func (a *NodeLocal) GrantMySQLMigrationAccess(ctx context.Context, databaseName string) error {
+	a.logger.Info().Str("database", databaseName).Msg("GrantMySQLMigrationAccess")
+	return asNonRetryable(a.database.GrantMigrationAccess(ctx, databaseName))
+}
+
+// RevokeMySQLMigrationAccess removes the access granted by GrantMySQLMigrationAccess.
+func (a *NodeLocal) RevokeMySQLMigrationAccess(ctx context.Context, databaseName string) error {
+	a.logger.Info().Str("database", databaseName).Msg("RevokeMySQLMigrationAccess")
+	return asNonRetryable(a.database.RevokeMigrationAccess(ctx, databaseName))
+}
+
+// StreamMySQLDatabase pipes mysqldump on the source node straight into the
+// database on this node, heartbeating the number of bytes copied.
+func (a *NodeLocal) StreamMySQLDatabase(ctx context.Context, params StreamMySQLDatabaseParams) (*StreamMySQLDatabaseResult, error) {
+	a.logger.Info().Str("database", params.DatabaseName).Str("source", params.SourceHost).Msg("StreamMySQLDatabase")
+	n, err := a.database.StreamDatabase(ctx, params.DatabaseName, params.SourceHost, func(n int64) {
+		activity.RecordHeartbeat(ctx, n)
+	})
+	if err != nil {
+		return nil, asNonRetryable(err)
+	}
+	return &StreamMySQLDatabaseResult{BytesTransferred: n}, nil
+}
+
 // DumpValkeyData triggers a Valkey BGSAVE and copies the RDB file to the dump path.
 func (a *NodeLocal) DumpValkeyData(ctx context.Context, params DumpValkeyDataParams) error {
 	a.logger.Info().Str("instance", params.Name).Int("port", params.Port).Str("path", params.DumpPath).Msg("DumpValkeyData")
@@ -628,7 +878,7 @@ func (a *NodeLocal) CreateWebBackup(ctx context.Context, params CreateWebBackupP
 		return nil, fmt.Errorf("create backup directory: %w", err)
 	}
 
-	cmd := exec.CommandContext(ctx, "tar", "czf", params.BackupPath, "-C", sourceDir, ".")
+	cmd := cmdaudit.CommandContext(ctx, "tar", "czf", params.BackupPath, "-C", sourceDir, ".")
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return nil, fmt.Errorf("tar czf failed: %w: %s", err, string(out))
 	}
@@ -650,7 +900,7 @@ func (a *NodeLocal) RestoreWebBackup(ctx context.Context, params RestoreWebBacku
 
 	targetDir := fmt.Sprintf("/var/www/storage/%s/webroots/%s", params.TenantName, params.WebrootName)
 
-	cmd := exec.CommandContext(ctx, "tar", "xzf", params.BackupPath, "-C", targetDir)
+	cmd := cmdaudit.CommandContext(ctx, "tar", "xzf", params.BackupPath, "-C", targetDir)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("tar xzf failed: %w: %s", err, string(out))
 	}
@@ -658,6 +908,21 @@ func (a *NodeLocal) RestoreWebBackup(ctx context.Context, params RestoreWebBacku
 	return nil
 }
 
+// ListBackupContents lists the entries of a web backup archive.
+func (a *NodeLocal) ListBackupContents(ctx context.Context, params ListBackupContentsParams) (*model.BackupFileList, error) {
+	a.logger.Info().Str("path", params.BackupPath).Str("prefix", params.Prefix).Msg("ListBackupContents")
+	list, err := a.webroot.ListBackupContents(params.BackupPath, params.Prefix, params.Limit)
+	return list, asNonRetryable(err)
+}
+
+// RestoreBackupFiles extracts individual paths of a web backup into the
+// webroot's storage directory.
+func (a *NodeLocal) RestoreBackupFiles(ctx context.Context, params RestoreBackupFilesParams) (*model.BackupFilesRestore, error) {
+	a.logger.Info().Str("tenant", params.TenantName).Str("webroot", params.WebrootName).Str("path", params.BackupPath).Strs("paths", params.Paths).Msg("RestoreBackupFiles")
+	res, err := a.webroot.RestoreBackupFiles(params.BackupPath, params.TenantName, params.WebrootName, params.Paths, params.Force)
+	return res, asNonRetryable(err)
+}
+
 // CreateMySQLBackup runs mysqldump and stores the compressed output.
 func (a *NodeLocal) CreateMySQLBackup(ctx context.Context, params CreateMySQLBackupParams) (*BackupResult, error) {
 	a.logger.Info().Str("database", params.DatabaseName).Str("path", params.BackupPath).Msg("CreateMySQLBackup")
@@ -668,8 +933,10 @@ func (a *NodeLocal) CreateMySQLBackup(ctx context.Context, params CreateMySQLBac
 	}
 
 	// Run: mysqldump {dbname} | gzip > {backupPath}
-	cmd := exec.CommandContext(ctx, "bash", "-c",
-		fmt.Sprintf("mysqldump %s | gzip > %s", params.DatabaseName, params.BackupPath))
+	// The dump records its binlog position so it can be rolled forward with
+	// ReplayMySQLBinlogs.
+	cmd := cmdaudit.CommandContext(ctx, "bash", "-c",
+		fmt.Sprintf("mysqldump --single-transaction --source-data=2 %s | gzip > %s", params.DatabaseName, params.BackupPath))
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return nil, fmt.Errorf("mysqldump failed: %w: %s", err, string(out))
 	}
@@ -690,7 +957,7 @@ func (a *NodeLocal) RestoreMySQLBackup(ctx context.Context, params RestoreMySQLB
 	a.logger.Info().Str("database", params.DatabaseName).Str("path", params.BackupPath).Msg("RestoreMySQLBackup")
 
 	// Run: gunzip -c {backupPath} | mysql {dbname}
-	cmd := exec.CommandContext(ctx, "bash", "-c",
+	cmd := cmdaudit.CommandContext(ctx, "bash", "-c",
 		fmt.Sprintf("gunzip -c %s | mysql %s", params.BackupPath, params.DatabaseName))
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("mysql restore failed: %w: %s", err, string(out))
@@ -699,12 +966,235 @@ func (a *NodeLocal) RestoreMySQLBackup(ctx context.Context, params RestoreMySQLB
 	return nil
 }
 
+// CheckMySQLBinlogs checks that the binlogs needed to roll a MySQL backup
+// forward to a point in time are still retained on the node.
+func (a *NodeLocal) CheckMySQLBinlogs(ctx context.Context, backupPath string) error {
+	a.logger.Info().Str("path", backupPath).Msg("CheckMySQLBinlogs")
+	return asNonRetryable(a.database.CheckBinlogs(ctx, backupPath))
+}
+
+// ReplayMySQLBinlogs rolls a restored MySQL backup forward to params.StopAt
+// by replaying the database's binlog events.
+func (a *NodeLocal) ReplayMySQLBinlogs(ctx context.Context, params ReplayMySQLBinlogsParams) error {
+	a.logger.Info().Str("database", params.DatabaseName).Str("path", params.BackupPath).Time("stop_at", params.StopAt).Msg("ReplayMySQLBinlogs")
+	return asNonRetryable(a.database.ReplayBinlogs(ctx, params.DatabaseName, params.BackupPath, params.StopAt))
+}
+
 // DeleteBackupFile removes a backup file from disk.
 func (a *NodeLocal) DeleteBackupFile(ctx context.Context, storagePath string) error {
 	a.logger.Info().Str("path", storagePath).Msg("DeleteBackupFile")
 	return os.Remove(storagePath)
 }
 
+// backupVerifyRoot is the only directory VerifyWebBackup extracts into and
+// CleanupBackupVerification removes from.
+const backupVerifyRoot = "/var/backups/hosting/.verify/"
+
+// errBackupVerify marks a backup that could not be restored or failed its
+// integrity check. Retrying will not change the outcome.
+func errBackupVerify(format string, args ...any) error {
+	return temporal.NewNonRetryableApplicationError(fmt.Sprintf(format, args...), "BACKUP_VERIFY_FAILED", nil)
+}
+
+// VerifyWebBackup restore-tests a web backup by extracting it into a scratch
+// directory and listing the archive. The scratch directory is left for
+// CleanupBackupVerification.
+func (a *NodeLocal) VerifyWebBackup(ctx context.Context, params VerifyWebBackupParams) error {
+	a.logger.Info().Str("path", params.BackupPath).Str("scratch", params.ScratchDir).Msg("VerifyWebBackup")
+
+	if !strings.HasPrefix(params.ScratchDir, backupVerifyRoot) {
+		return errBackupVerify("scratch directory %q is outside %s", params.ScratchDir, backupVerifyRoot)
+	}
+	if err := os.MkdirAll(params.ScratchDir, 0700); err != nil {
+		return fmt.Errorf("create scratch directory: %w", err)
+	}
+
+	cmd := cmdaudit.CommandContext(ctx, "tar", "xzf", params.BackupPath, "-C", params.ScratchDir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errBackupVerify("extract %s: %v: %s", params.BackupPath, err, strings.TrimSpace(string(out)))
+	}
+
+	out, err := cmdaudit.CommandContext(ctx, "tar", "tzf", params.BackupPath).Output()
+	if err != nil {
+		return errBackupVerify("list %s: %v", params.BackupPath, err)
+	}
+	if len(strings.TrimSpace(string(out))) == 0 {
+		return errBackupVerify("archive %s has no entries", params.BackupPath)
+	}
+
+	return nil
+}
+
+// VerifyMySQLBackup restore-tests a MySQL backup by importing it into a
+// scratch database and running mysqlcheck over every table. The scratch
+// database is left for CleanupBackupVerification.
+func (a *NodeLocal) VerifyMySQLBackup(ctx context.Context, params VerifyMySQLBackupParams) error {
+	a.logger.Info().Str("path", params.BackupPath).Str("scratch", params.ScratchDatabase).Msg("VerifyMySQLBackup")
+
+	if err := asNonRetryable(a.database.CreateDatabase(ctx, params.ScratchDatabase, "", "")); err != nil {
+		return err
+	}
+
+	// Run: gunzip -c {backupPath} | mysql {scratch}
+	cmd := cmdaudit.CommandContext(ctx, "bash", "-o", "pipefail", "-c",
+		fmt.Sprintf("gunzip -c %s | mysql %s", params.BackupPath, params.ScratchDatabase))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errBackupVerify("import %s: %v: %s", params.BackupPath, err, strings.TrimSpace(string(out)))
+	}
+
+	out, err := cmdaudit.CommandContext(ctx, "mysqlcheck", "--databases", params.ScratchDatabase).CombinedOutput()
+	if err != nil {
+		return errBackupVerify("mysqlcheck: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	if problems := mysqlcheckProblems(string(out)); len(problems) > 0 {
+		return errBackupVerify("mysqlcheck: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// mysqlcheckProblems returns the error lines from mysqlcheck output. Healthy
+// tables print "db.table OK"; damaged ones are followed by "error : ..." lines.
+func mysqlcheckProblems(output string) []string {
+	var problems []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(strings.ToLower(line), "error") {
+			problems = append(problems, line)
+		}
+	}
+	return problems
+}
+
+// CleanupBackupVerification removes the scratch directory and database left
+// by VerifyWebBackup or VerifyMySQLBackup.
+func (a *NodeLocal) CleanupBackupVerification(ctx context.Context, params CleanupBackupVerificationParams) error {
+	a.logger.Info().Str("scratch_dir", params.ScratchDir).Str("scratch_database", params.ScratchDatabase).Msg("CleanupBackupVerification")
+
+	if params.ScratchDir != "" {
+		if !strings.HasPrefix(params.ScratchDir, backupVerifyRoot) {
+			return errBackupVerify("scratch directory %q is outside %s", params.ScratchDir, backupVerifyRoot)
+		}
+		if err := os.RemoveAll(params.ScratchDir); err != nil {
+			return fmt.Errorf("remove scratch directory: %w", err)
+		}
+	}
+	if params.ScratchDatabase != "" {
+		if err := asNonRetryable(a.database.DeleteDatabase(ctx, params.ScratchDatabase)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateTenantExportArchive writes the export manifest into the staging
+// directory and packs the directory into a single gzipped tarball.
+func (a *NodeLocal) CreateTenantExportArchive(ctx context.Context, params CreateTenantExportArchiveParams) (*BackupResult, error) {
+	a.logger.Info().Str("staging", params.StagingDir).Str("path", params.ArchivePath).Msg("CreateTenantExportArchive")
+
+	if err := os.MkdirAll(params.StagingDir, 0750); err != nil {
+		return nil, fmt.Errorf("create staging directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(params.StagingDir, "manifest.json"), params.Manifest, 0640); err != nil {
+		return nil, fmt.Errorf("write manifest: %w", err)
+	}
+
+	cmd := cmdaudit.CommandContext(ctx, "tar", "czf", params.ArchivePath, "-C", params.StagingDir, ".")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("tar czf failed: %w: %s", err, string(out))
+	}
+
+	info, err := os.Stat(params.ArchivePath)
+	if err != nil {
+		return nil, fmt.Errorf("stat export archive: %w", err)
+	}
+
+	return &BackupResult{
+		StoragePath: params.ArchivePath,
+		SizeBytes:   info.Size(),
+	}, nil
+}
+
+// UploadBackupFile streams a local backup file to a presigned PUT URL.
+func (a *NodeLocal) UploadBackupFile(ctx context.Context, params UploadBackupFileParams) error {
+	a.logger.Info().Str("path", params.Path).Msg("UploadBackupFile")
+
+	f, err := os.Open(params.Path)
+	if err != nil {
+		return fmt.Errorf("open backup file: %w", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("stat backup file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, params.URL, f)
+	if err != nil {
+		return temporal.NewNonRetryableApplicationError("create upload request", "REQUEST_ERROR", err)
+	}
+	req.ContentLength = info.Size()
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload backup file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("upload backup file: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// DownloadBackupFile fetches a backup file from a presigned GET URL to a local path.
+func (a *NodeLocal) DownloadBackupFile(ctx context.Context, params DownloadBackupFileParams) error {
+	a.logger.Info().Str("path", params.Path).Msg("DownloadBackupFile")
+
+	if err := os.MkdirAll(filepath.Dir(params.Path), 0750); err != nil {
+		return fmt.Errorf("create backup directory: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, params.URL, nil)
+	if err != nil {
+		return temporal.NewNonRetryableApplicationError("create download request", "REQUEST_ERROR", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("download backup file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("download backup file: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	f, err := os.OpenFile(params.Path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0640)
+	if err != nil {
+		return fmt.Errorf("create backup file: %w", err)
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return fmt.Errorf("write backup file: %w", err)
+	}
+	return f.Close()
+}
+
+// CleanupTenantExport removes an export's staging directory and local archive.
+func (a *NodeLocal) CleanupTenantExport(ctx context.Context, params CleanupTenantExportParams) error {
+	a.logger.Info().Str("staging", params.StagingDir).Str("path", params.ArchivePath).Msg("CleanupTenantExport")
+	if err := os.RemoveAll(params.StagingDir); err != nil {
+		return fmt.Errorf("remove staging directory: %w", err)
+	}
+	if params.ArchivePath == "" {
+		return nil
+	}
+	if err := os.Remove(params.ArchivePath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove export archive: %w", err)
+	}
+	return nil
+}
+
 // --------------------------------------------------------------------------
 // S3 activities
 // --------------------------------------------------------------------------
@@ -727,6 +1217,12 @@ func (a *NodeLocal) CreateS3AccessKey(ctx context.Context, params CreateS3Access
 	return asNonRetryable(a.s3.CreateAccessKey(ctx, params.TenantID, params.AccessKeyID, params.SecretAccessKey))
 }
 
+// RotateS3AccessKey replaces the secret of an S3 access key via RGW on this node.
+func (a *NodeLocal) RotateS3AccessKey(ctx context.Context, params RotateS3AccessKeyParams) error {
+	a.logger.Info().Str("tenant", params.TenantID).Str("access_key", params.AccessKeyID).Msg("RotateS3AccessKey")
+	return asNonRetryable(a.s3.RotateAccessKey(ctx, params.TenantID, params.AccessKeyID, params.SecretAccessKey))
+}
+
 // DeleteS3AccessKey deletes an S3 access key via RGW on this node.
 func (a *NodeLocal) DeleteS3AccessKey(ctx context.Context, params DeleteS3AccessKeyParams) error {
 	a.logger.Info().Str("tenant", params.TenantID).Str("access_key", params.AccessKeyID).Msg("DeleteS3AccessKey")
@@ -739,6 +1235,12 @@ func (a *NodeLocal) UpdateS3BucketPolicy(ctx context.Context, params UpdateS3Buc
 	return asNonRetryable(a.s3.SetBucketPolicy(ctx, params.TenantID, params.Name, params.Public))
 }
 
+// SetS3BucketLifecycle converges the lifecycle rules of an S3 bucket.
+func (a *NodeLocal) SetS3BucketLifecycle(ctx context.Context, params SetS3BucketLifecycleParams) error {
+	a.logger.Info().Str("bucket", params.Name).Int("rules", len(params.Rules)).Msg("SetS3BucketLifecycle")
+	return asNonRetryable(a.s3.SetBucketLifecycle(ctx, params.Name, params.Rules))
+}
+
 // --------------------------------------------------------------------------
 // Cron job activities
 // --------------------------------------------------------------------------
@@ -756,7 +1258,9 @@ func (a *NodeLocal) CreateCronJobUnits(ctx context.Context, params CreateCronJob
 		WorkingDirectory: params.WorkingDirectory,
 		TimeoutSeconds:   params.TimeoutSeconds,
 		MaxMemoryMB:      params.MaxMemoryMB,
+		Timezone:         params.Timezone,
 		EnvFileName:      params.EnvFileName,
+		EnvVars:          params.EnvVars,
 	})
 }
 
@@ -773,7 +1277,9 @@ func (a *NodeLocal) UpdateCronJobUnits(ctx context.Context, params UpdateCronJob
 		WorkingDirectory: params.WorkingDirectory,
 		TimeoutSeconds:   params.TimeoutSeconds,
 		MaxMemoryMB:      params.MaxMemoryMB,
+		Timezone:         params.Timezone,
 		EnvFileName:      params.EnvFileName,
+		EnvVars:          params.EnvVars,
 	})
 }
 
@@ -804,6 +1310,15 @@ func (a *NodeLocal) DisableCronJobTimer(ctx context.Context, params CronJobTimer
 	})
 }
 
+// StopCronJobRun stops a run of the cron job in progress on this node.
+func (a *NodeLocal) StopCronJobRun(ctx context.Context, params CronJobTimerParams) error {
+	a.logger.Info().Str("cron_job", params.ID).Str("tenant", params.TenantName).Msg("StopCronJobRun")
+	return a.cron.StopRun(ctx, &agent.CronJobInfo{
+		ID:         params.ID,
+		TenantName: params.TenantName,
+	})
+}
+
 // --------------------------------------------------------------------------
 // Daemon activities
 // --------------------------------------------------------------------------
@@ -1133,6 +1648,13 @@ func (a *NodeLocal) CheckCephFSMount(ctx context.Context) (*CephFSStatus, error)
 	return status, nil
 }
 
+// GetCommandAuditSummary returns how many external commands the node agent has
+// run since it started, how many failed, and the most recent failures. The
+// full per-command record is in the node's local audit log.
+func (a *NodeLocal) GetCommandAuditSummary(ctx context.Context) (cmdaudit.Summary, error) {
+	return cmdaudit.Default().Summary(), nil
+}
+
 // GetDiskUsage returns disk usage for key mount points on the node.
 func (a *NodeLocal) GetDiskUsage(ctx context.Context) ([]DiskUsage, error) {
 	paths := []string{"/", "/var/lib/mysql", "/var/www/storage"}
@@ -1234,7 +1756,7 @@ func (a *NodeLocal) getWebResourceUsage(ctx context.Context) ([]ResourceUsageEnt
 			webrootPath := filepath.Join(webrootsDir, webrootName)
 
 			// Run du -sb to get total bytes.
-			cmd := exec.CommandContext(ctx, "du", "-sb", webrootPath)
+			cmd := cmdaudit.CommandContext(ctx, "du", "-sb", webrootPath)
 			out, err := cmd.Output()
 			if err != nil {
 				a.logger.Warn().Err(err).Str("path", webrootPath).Msg("du -sb failed")
@@ -1262,7 +1784,7 @@ func (a *NodeLocal) getWebResourceUsage(ctx context.Context) ([]ResourceUsageEnt
 
 // getDatabaseResourceUsage queries MySQL information_schema for per-database sizes.
 func (a *NodeLocal) getDatabaseResourceUsage(ctx context.Context) ([]ResourceUsageEntry, error) {
-	cmd := exec.CommandContext(ctx, "mysql", "-N", "-B", "-e",
+	cmd := cmdaudit.CommandContext(ctx, "mysql", "-N", "-B", "-e",
 		"SELECT table_schema, SUM(data_length + index_length) FROM information_schema.tables WHERE table_schema NOT IN ('mysql','information_schema','performance_schema','sys') GROUP BY table_schema")
 	out, err := cmd.Output()
 	if err != nil {
@@ -1293,6 +1815,49 @@ func (a *NodeLocal) getDatabaseResourceUsage(ctx context.Context) ([]ResourceUsa
 	return entries, nil
 }
 
+// GetValkeyStatsParams holds parameters for collecting Valkey instance stats.
+type GetValkeyStatsParams struct {
+	Instances []ValkeyStatsTarget `json:"instances"`
+}
+
+// ValkeyStatsTarget identifies a Valkey instance to query.
+type ValkeyStatsTarget struct {
+	Name string `json:"name"`
+}
+
+// ValkeyStatsEntry represents memory and key usage collected from a Valkey instance.
+type ValkeyStatsEntry struct {
+	Name            string `json:"name"`
+	UsedMemoryBytes int64  `json:"used_memory_bytes"`
+	KeyCount        int64  `json:"key_count"`
+	Available       bool   `json:"available"`
+	Error           string `json:"error,omitempty"`
+}
+
+// GetValkeyStats collects INFO memory/keyspace figures for each requested
+// instance. Instances that are down or return an error reply are reported as
+// unavailable instead of failing the whole activity.
+func (a *NodeLocal) GetValkeyStats(ctx context.Context, params GetValkeyStatsParams) ([]ValkeyStatsEntry, error) {
+	a.logger.Info().Int("instances", len(params.Instances)).Msg("GetValkeyStats")
+
+	entries := make([]ValkeyStatsEntry, 0, len(params.Instances))
+	for _, inst := range params.Instances {
+		stats, err := a.valkey.GetStats(ctx, inst.Name)
+		if err != nil {
+			a.logger.Warn().Err(err).Str("instance", inst.Name).Msg("valkey stats unavailable")
+			entries = append(entries, ValkeyStatsEntry{Name: inst.Name, Error: err.Error()})
+			continue
+		}
+		entries = append(entries, ValkeyStatsEntry{
+			Name:            inst.Name,
+			UsedMemoryBytes: stats.UsedMemoryBytes,
+			KeyCount:        stats.KeyCount,
+			Available:       true,
+		})
+	}
+	return entries, nil
+}
+
 // --------------------------------------------------------------------------
 // WireGuard activities
 // --------------------------------------------------------------------------
@@ -1343,3 +1908,41 @@ func (a *NodeLocal) SyncWireGuardPeers(ctx context.Context, params SyncWireGuard
 	return a.wireguard.SyncPeers(ctx, agentPeers)
 }
 
+
+// RunNodeDiagnosticsParams selects which self-tests to run. Checks are
+// picked by the node's roles so that, for example, a database node is not
+// reported unhealthy for lacking nginx.
+type RunNodeDiagnosticsParams struct {
+	Roles []string
+}
+
+// RunNodeDiagnostics runs the node self-tests for the given roles and
+// returns a structured report. Failed checks are reported in the result, not
+// as an activity error, so one failing check never hides the others.
+func (a *NodeLocal) RunNodeDiagnostics(ctx context.Context, params RunNodeDiagnosticsParams) (*model.NodeDiagnostics, error) {
+	has := func(role string) bool {
+		for _, r := range params.Roles {
+			if r == role {
+				return true
+			}
+		}
+		return false
+	}
+
+	var diags []agent.Diagnostic
+	if has(model.ShardRoleWeb) {
+		diags = append(diags,
+			agent.CheckMountDiagnostic("/var/www/storage"),
+			a.nginx.Diagnostic(),
+			a.daemon.Diagnostic(),
+		)
+	}
+	if has(model.ShardRoleDatabase) {
+		diags = append(diags, a.database.Diagnostic())
+	}
+	if has(model.ShardRoleValkey) {
+		diags = append(diags, a.valkey.Diagnostic())
+	}
+
+	return agent.RunDiagnostics(ctx, diags), nil
+}
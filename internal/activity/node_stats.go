@@ -0,0 +1,202 @@
+package activity
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// statsFSTypes are the filesystem types CollectNodeStats reports. Pseudo and
+// in-memory filesystems (proc, tmpfs, overlay, ...) are skipped.
+var statsFSTypes = map[string]bool{
+	"ext2":  true,
+	"ext3":  true,
+	"ext4":  true,
+	"xfs":   true,
+	"btrfs": true,
+	"zfs":   true,
+	"ceph":  true,
+	"nfs":   true,
+	"nfs4":  true,
+}
+
+// CollectNodeStats returns the node's CPU load, memory and per-mount disk
+// usage, read from /proc and statfs. A metric that cannot be read is left
+// unset and described in Errors; the activity itself does not fail.
+func (a *NodeLocal) CollectNodeStats(ctx context.Context) (*model.NodeStats, error) {
+	stats := &model.NodeStats{CPUCount: goruntime.NumCPU(), Disks: []model.NodeDiskStats{}}
+
+	if data, err := os.ReadFile("/proc/loadavg"); err != nil {
+		stats.Errors = append(stats.Errors, fmt.Sprintf("loadavg: %v", err))
+	} else if l1, l5, l15, err := parseLoadAvg(string(data)); err != nil {
+		stats.Errors = append(stats.Errors, fmt.Sprintf("loadavg: %v", err))
+	} else {
+		stats.Load1, stats.Load5, stats.Load15 = &l1, &l5, &l15
+	}
+
+	if data, err := os.ReadFile("/proc/meminfo"); err != nil {
+		stats.Errors = append(stats.Errors, fmt.Sprintf("meminfo: %v", err))
+	} else {
+		mem := parseMemInfo(string(data))
+		stats.MemTotalBytes = memInfoBytes(mem, "MemTotal")
+		stats.MemAvailableBytes = memInfoBytes(mem, "MemAvailable")
+		stats.SwapTotalBytes = memInfoBytes(mem, "SwapTotal")
+		stats.SwapFreeBytes = memInfoBytes(mem, "SwapFree")
+		if stats.MemTotalBytes == nil || stats.MemAvailableBytes == nil {
+			stats.Errors = append(stats.Errors, "meminfo: MemTotal or MemAvailable missing")
+		}
+	}
+
+	data, err := os.ReadFile("/proc/self/mounts")
+	if err != nil {
+		stats.Errors = append(stats.Errors, fmt.Sprintf("mounts: %v", err))
+		return stats, nil
+	}
+	seen := make(map[uint64]bool) // dedup bind mounts of the same filesystem
+	for _, m := range parseMounts(string(data)) {
+		var stat syscall.Statfs_t
+		if err := syscall.Statfs(m.path, &stat); err != nil {
+			stats.Errors = append(stats.Errors, fmt.Sprintf("statfs %s: %v", m.path, err))
+			continue
+		}
+		devID := uint64(stat.Fsid.X__val[0])<<32 | uint64(stat.Fsid.X__val[1])
+		if seen[devID] {
+			continue
+		}
+		seen[devID] = true
+
+		total := int64(stat.Blocks) * int64(stat.Bsize)
+		free := int64(stat.Bavail) * int64(stat.Bsize)
+		disk := model.NodeDiskStats{
+			Path:       m.path,
+			FSType:     m.fsType,
+			TotalBytes: total,
+			UsedBytes:  total - free,
+			FreeBytes:  free,
+		}
+		if total > 0 {
+			disk.UsedPct = float64(disk.UsedBytes) / float64(total) * 100
+		}
+		stats.Disks = append(stats.Disks, disk)
+	}
+
+	return stats, nil
+}
+
+// parseLoadAvg parses the 1, 5 and 15 minute load averages from /proc/loadavg.
+func parseLoadAvg(data string) (float64, float64, float64, error) {
+	fields := strings.Fields(data)
+	if len(fields) < 3 {
+		return 0, 0, 0, fmt.Errorf("unexpected format %q", strings.TrimSpace(data))
+	}
+	var loads [3]float64
+	for i := range loads {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("parse %q: %w", fields[i], err)
+		}
+		loads[i] = v
+	}
+	return loads[0], loads[1], loads[2], nil
+}
+
+// parseMemInfo parses /proc/meminfo into byte counts keyed by field name.
+// Lines that do not parse are skipped.
+func parseMemInfo(data string) map[string]int64 {
+	mem := make(map[string]int64)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		key, rest, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		v, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 1 && fields[1] == "kB" {
+			v *= 1024
+		}
+		mem[key] = v
+	}
+	return mem
+}
+
+func memInfoBytes(mem map[string]int64, key string) *int64 {
+	v, ok := mem[key]
+	if !ok {
+		return nil
+	}
+	return &v
+}
+
+type statsMount struct {
+	path   string
+	fsType string
+}
+
+// parseMounts returns the mounts in /proc/self/mounts whose filesystem type
+// is in statsFSTypes.
+func parseMounts(data string) []statsMount {
+	var mounts []statsMount
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || !statsFSTypes[fields[2]] {
+			continue
+		}
+		// Mount points escape whitespace as octal, e.g. "\040" for a space.
+		path := strings.NewReplacer(`\040`, " ", `\011`, "\t", `\012`, "\n", `\134`, `\`).Replace(fields[1])
+		mounts = append(mounts, statsMount{path: path, fsType: fields[2]})
+	}
+	return mounts
+}
+
+// UpsertNodeStats stores the latest stats snapshot for a node.
+func (a *CoreDB) UpsertNodeStats(ctx context.Context, stats model.NodeStats) error {
+	disks := stats.Disks
+	if disks == nil {
+		disks = []model.NodeDiskStats{}
+	}
+	disksJSON, err := json.Marshal(disks)
+	if err != nil {
+		return fmt.Errorf("marshal node disks: %w", err)
+	}
+	errs := stats.Errors
+	if errs == nil {
+		errs = []string{}
+	}
+	errorsJSON, err := json.Marshal(errs)
+	if err != nil {
+		return fmt.Errorf("marshal node stats errors: %w", err)
+	}
+
+	_, err = a.db.Exec(ctx,
+		`INSERT INTO node_stats (node_id, cpu_count, load_1, load_5, load_15,
+		   mem_total_bytes, mem_available_bytes, swap_total_bytes, swap_free_bytes, disks, errors, collected_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, now())
+		 ON CONFLICT (node_id) DO UPDATE SET
+		   cpu_count = EXCLUDED.cpu_count, load_1 = EXCLUDED.load_1, load_5 = EXCLUDED.load_5, load_15 = EXCLUDED.load_15,
+		   mem_total_bytes = EXCLUDED.mem_total_bytes, mem_available_bytes = EXCLUDED.mem_available_bytes,
+		   swap_total_bytes = EXCLUDED.swap_total_bytes, swap_free_bytes = EXCLUDED.swap_free_bytes,
+		   disks = EXCLUDED.disks, errors = EXCLUDED.errors, collected_at = now()`,
+		stats.NodeID, stats.CPUCount, stats.Load1, stats.Load5, stats.Load15,
+		stats.MemTotalBytes, stats.MemAvailableBytes, stats.SwapTotalBytes, stats.SwapFreeBytes, disksJSON, errorsJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert node stats for %s: %w", stats.NodeID, err)
+	}
+	return nil
+}
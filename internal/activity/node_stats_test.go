@@ -0,0 +1,43 @@
+package activity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLoadAvg(t *testing.T) {
+	l1, l5, l15, err := parseLoadAvg("0.52 0.48 0.41 2/812 12345\n")
+	require.NoError(t, err)
+	assert.Equal(t, 0.52, l1)
+	assert.Equal(t, 0.48, l5)
+	assert.Equal(t, 0.41, l15)
+
+	_, _, _, err = parseLoadAvg("garbage")
+	assert.Error(t, err)
+}
+
+func TestParseMemInfo(t *testing.T) {
+	mem := parseMemInfo("MemTotal:       16318504 kB\nMemFree:         1024 kB\nMemAvailable:   8159252 kB\nHugePages_Total:       0\nbroken line\n")
+	assert.Equal(t, int64(16318504*1024), mem["MemTotal"])
+	assert.Equal(t, int64(8159252*1024), mem["MemAvailable"])
+	assert.Equal(t, int64(0), mem["HugePages_Total"])
+	assert.Nil(t, memInfoBytes(mem, "SwapTotal"))
+}
+
+func TestParseMounts(t *testing.T) {
+	mounts := parseMounts(`proc /proc proc rw,nosuid 0 0
+/dev/sda1 / ext4 rw,relatime 0 0
+tmpfs /run tmpfs rw 0 0
+/dev/sdb1 /var/lib/mysql xfs rw 0 0
+10.0.0.1:6789:/ /var/www/storage ceph rw 0 0
+/dev/sdc1 /mnt/with\040space ext4 rw 0 0
+`)
+	assert.Equal(t, []statsMount{
+		{path: "/", fsType: "ext4"},
+		{path: "/var/lib/mysql", fsType: "xfs"},
+		{path: "/var/www/storage", fsType: "ceph"},
+		{path: "/mnt/with space", fsType: "ext4"},
+	}, mounts)
+}
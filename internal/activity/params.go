@@ -2,6 +2,7 @@ package activity
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/edvin/hosting/internal/model"
 )
@@ -25,11 +26,24 @@ type UpdateTenantParams struct {
 	SSHEnabled  bool
 }
 
+// FixTenantPermissionsParams holds parameters for reconciling the ownership
+// and modes of a tenant's storage tree on a node.
+type FixTenantPermissionsParams struct {
+	TenantName string
+	MaxChanges int // stop after this many changes; 0 means no limit
+}
+
 // FQDNParam represents an FQDN for webroot operations.
 type FQDNParam struct {
 	FQDN       string
 	WebrootID  string
 	SSLEnabled bool
+	ForceHTTPS bool
+	// RedirectTarget, when set, makes the FQDN redirect to that host
+	// instead of serving the webroot.
+	RedirectTarget       *string
+	RedirectCode         int
+	RedirectPreservePath bool
 }
 
 // CreateWebrootParams holds parameters for creating a webroot on a node.
@@ -41,8 +55,15 @@ type CreateWebrootParams struct {
 	RuntimeVersion string
 	RuntimeConfig  string
 	PublicFolder   string
+	ErrorPages     map[int]string
+	BasicAuth      map[string]string // username -> bcrypt hash
 	EnvVars        map[string]string
 	EnvFileName    string
+	AccessLog      bool // also log to the tenant's shared logs dir
+	Limits         model.WebrootConnectionLimits
+	StaticRules    model.WebrootStaticRules
+	GeoBlocking    model.WebrootGeoBlocking
+	DeployLock     *time.Time // nginx answers 503 until then; nil when not locked
 	FQDNs          []FQDNParam
 	Daemons        []DaemonProxyInfo
 }
@@ -56,12 +77,36 @@ type UpdateWebrootParams struct {
 	RuntimeVersion string
 	RuntimeConfig  string
 	PublicFolder   string
+	ErrorPages     map[int]string
+	BasicAuth      map[string]string // username -> bcrypt hash
 	EnvVars        map[string]string
 	EnvFileName    string
+	AccessLog      bool // also log to the tenant's shared logs dir
+	Limits         model.WebrootConnectionLimits
+	StaticRules    model.WebrootStaticRules
+	GeoBlocking    model.WebrootGeoBlocking
+	DeployLock     *time.Time // nginx answers 503 until then; nil when not locked
 	FQDNs          []FQDNParam
 	Daemons        []DaemonProxyInfo
 }
 
+// ReadWebrootAccessLogParams holds parameters for reading the tail of a
+// webroot's shared access logs on a node.
+type ReadWebrootAccessLogParams struct {
+	TenantName string
+	Name       string
+	Tail       int
+}
+
+// CheckErrorPagesParams holds parameters for checking a webroot's custom
+// error pages on a node.
+type CheckErrorPagesParams struct {
+	TenantName   string
+	Name         string
+	PublicFolder string
+	ErrorPages   map[int]string
+}
+
 // ConfigureRuntimeParams holds parameters for configuring a runtime on a node.
 type ConfigureRuntimeParams struct {
 	ID             string
@@ -74,6 +119,14 @@ type ConfigureRuntimeParams struct {
 	EnvVars        map[string]string
 }
 
+// CreateDatabaseParams holds parameters for creating a database on a node.
+// Empty Charset/Collation fall back to the platform defaults.
+type CreateDatabaseParams struct {
+	Name      string
+	Charset   string
+	Collation string
+}
+
 // CreateDatabaseUserParams holds parameters for creating a database user on a node.
 type CreateDatabaseUserParams struct {
 	DatabaseName string
@@ -92,10 +145,11 @@ type UpdateDatabaseUserParams struct {
 
 // CreateValkeyInstanceParams holds parameters for creating a Valkey instance on a node.
 type CreateValkeyInstanceParams struct {
-	Name         string
-	Port         int
-	PasswordHash string
-	MaxMemoryMB  int
+	Name            string
+	Port            int
+	PasswordHash    string
+	MaxMemoryMB     int
+	PersistenceMode string
 }
 
 // DeleteValkeyInstanceParams holds parameters for deleting a Valkey instance on a node.
@@ -164,6 +218,18 @@ type ImportMySQLDatabaseParams struct {
 	DumpPath     string
 }
 
+// StreamMySQLDatabaseParams holds parameters for streaming a MySQL database
+// from a source node into the same database on this node.
+type StreamMySQLDatabaseParams struct {
+	DatabaseName string
+	SourceHost   string
+}
+
+// StreamMySQLDatabaseResult reports how much dump data was streamed.
+type StreamMySQLDatabaseResult struct {
+	BytesTransferred int64
+}
+
 // DumpValkeyDataParams holds parameters for dumping Valkey data on a node.
 type DumpValkeyDataParams struct {
 	Name     string
@@ -192,6 +258,24 @@ type RestoreWebBackupParams struct {
 	BackupPath  string
 }
 
+// ListBackupContentsParams holds parameters for listing a web backup
+// archive on a node.
+type ListBackupContentsParams struct {
+	BackupPath string
+	Prefix     string // only entries whose path starts with it
+	Limit      int
+}
+
+// RestoreBackupFilesParams holds parameters for restoring individual paths
+// from a web backup on a node.
+type RestoreBackupFilesParams struct {
+	TenantName  string
+	WebrootName string
+	BackupPath  string
+	Paths       []string // relative to the webroot's storage dir
+	Force       bool     // overwrite files that are newer than the backup's
+}
+
 // CreateMySQLBackupParams holds parameters for creating a MySQL backup on a node.
 type CreateMySQLBackupParams struct {
 	DatabaseName string
@@ -204,6 +288,33 @@ type RestoreMySQLBackupParams struct {
 	BackupPath   string
 }
 
+// ReplayMySQLBinlogsParams holds parameters for rolling a restored MySQL
+// backup forward to a point in time on a node.
+type ReplayMySQLBinlogsParams struct {
+	DatabaseName string
+	BackupPath   string    // the restored dump, which holds the binlog position to start from
+	StopAt       time.Time // events at or after this time are not replayed
+}
+
+// VerifyWebBackupParams holds parameters for restore-testing a web backup on a node.
+type VerifyWebBackupParams struct {
+	BackupPath string
+	ScratchDir string // throwaway extraction target, e.g. /var/backups/hosting/.verify/{backup-id}
+}
+
+// VerifyMySQLBackupParams holds parameters for restore-testing a MySQL backup on a node.
+type VerifyMySQLBackupParams struct {
+	BackupPath      string
+	ScratchDatabase string // throwaway database the dump is imported into
+}
+
+// CleanupBackupVerificationParams holds the scratch resources a backup
+// verification leaves behind. Empty fields are skipped.
+type CleanupBackupVerificationParams struct {
+	ScratchDir      string
+	ScratchDatabase string
+}
+
 // CreateS3BucketParams holds parameters for creating an S3 bucket on a node.
 type CreateS3BucketParams struct {
 	TenantID   string
@@ -224,6 +335,13 @@ type UpdateS3BucketPolicyParams struct {
 	Public   bool
 }
 
+// SetS3BucketLifecycleParams holds parameters for converging an S3 bucket's
+// lifecycle rules on a node. Empty Rules removes the lifecycle configuration.
+type SetS3BucketLifecycleParams struct {
+	Name  string
+	Rules []model.S3LifecycleRule
+}
+
 // CreateS3AccessKeyParams holds parameters for creating an S3 access key on a node.
 type CreateS3AccessKeyParams struct {
 	TenantID        string
@@ -231,6 +349,10 @@ type CreateS3AccessKeyParams struct {
 	SecretAccessKey string
 }
 
+// RotateS3AccessKeyParams holds parameters for replacing the secret of an S3
+// access key on a node.
+type RotateS3AccessKeyParams = CreateS3AccessKeyParams
+
 // DeleteS3AccessKeyParams holds parameters for deleting an S3 access key on a node.
 type DeleteS3AccessKeyParams struct {
 	TenantID    string
@@ -247,6 +369,20 @@ type CleanOrphanedConfigsResult struct {
 	Removed []string `json:"removed"`
 }
 
+// ApplyNginxBaseConfigParams holds the web shard's nginx tuning for the base
+// nginx.conf. RealIPFrom lists the LB addresses trusted to send the PROXY
+// protocol; empty turns PROXY protocol off.
+type ApplyNginxBaseConfigParams struct {
+	Tuning     model.NginxTuning `json:"tuning"`
+	RealIPFrom []string          `json:"real_ip_from,omitempty"`
+}
+
+// ApplyNginxBaseConfigResult reports whether nginx.conf or the PROXY
+// protocol setting changed.
+type ApplyNginxBaseConfigResult struct {
+	Changed bool `json:"changed"`
+}
+
 // CleanOrphanedFPMPoolsInput holds parameters for cleaning orphaned PHP-FPM pool configs.
 type CleanOrphanedFPMPoolsInput struct {
 	ExpectedPools map[string]bool `json:"expected_pools"`
@@ -278,7 +414,9 @@ type CreateCronJobParams struct {
 	WorkingDirectory string
 	TimeoutSeconds   int
 	MaxMemoryMB      int
+	Timezone         string // IANA zone the schedule is evaluated in
 	EnvFileName      string
+	EnvVars          map[string]string // cron job env, loaded at run time
 }
 
 // UpdateCronJobParams holds parameters for updating a cron job on a node.
@@ -353,6 +491,19 @@ type CreateTempMySQLUserParams struct {
 	PasswordHash string
 }
 
+// ListDatabaseConnectionsParams holds parameters for listing the MySQL
+// connections of a database's users on a node.
+type ListDatabaseConnectionsParams struct {
+	Users []string
+}
+
+// KillDatabaseConnectionParams holds parameters for killing a MySQL
+// connection. The connection must belong to one of Users.
+type KillDatabaseConnectionParams struct {
+	ConnectionID int64
+	Users        []string
+}
+
 // ConfigureReplicationParams holds parameters for configuring MySQL replication.
 // The replication password is read from the node-agent's local environment
 // (MYSQL_REPL_PASSWORD), not passed through the workflow.
@@ -427,3 +578,119 @@ type WireGuardPeerConfig struct {
 type SyncWireGuardPeersParams struct {
 	Peers []WireGuardPeerConfig
 }
+
+// SaveNodeRuntimesParams holds parameters for caching a node's runtime report.
+type SaveNodeRuntimesParams struct {
+	NodeID   string
+	Runtimes []model.InstalledRuntime
+}
+
+// UpdateTenantExportResultParams holds the parameters for recording an uploaded export.
+type UpdateTenantExportResultParams struct {
+	ID          string
+	ObjectKey   string
+	SizeBytes   int64
+	CompletedAt time.Time
+}
+
+// CreateTenantExportArchiveParams holds parameters for packing an export staging
+// directory and its manifest into a single archive.
+type CreateTenantExportArchiveParams struct {
+	StagingDir  string
+	ArchivePath string
+	Manifest    []byte
+}
+
+// UploadBackupFileParams holds parameters for uploading a backup file to a presigned URL.
+type UploadBackupFileParams struct {
+	Path string
+	URL  string
+}
+
+// DownloadBackupFileParams holds parameters for downloading a backup file from a presigned URL.
+type DownloadBackupFileParams struct {
+	URL  string
+	Path string
+}
+
+// CleanupTenantExportParams holds the local paths of an export to remove.
+type CleanupTenantExportParams struct {
+	StagingDir  string
+	ArchivePath string
+}
+
+// MarkOperationRunningParams holds the parameters for MarkOperationRunning.
+type MarkOperationRunningParams struct {
+	ID    string
+	RunID string
+}
+
+// CompleteOperationParams holds the parameters for CompleteOperation.
+type CompleteOperationParams struct {
+	ID    string
+	Error string
+}
+
+// CreateTemplateZoneRecordsParams holds the expanded brand templates to
+// create as zone records.
+type CreateTemplateZoneRecordsParams struct {
+	ZoneID  string
+	Records []model.BrandZoneTemplate
+}
+
+// ClearWebrootDeployLockParams identifies a webroot's deploy lock by the
+// time it expires.
+type ClearWebrootDeployLockParams struct {
+	WebrootID string
+	Until     time.Time
+}
+
+// SetCurrentWebrootReleaseParams identifies the release to mark live.
+type SetCurrentWebrootReleaseParams struct {
+	WebrootID string
+	ReleaseID string
+}
+
+// ListPrunableWebrootReleasesParams selects the releases beyond the newest
+// Keep of a webroot.
+type ListPrunableWebrootReleasesParams struct {
+	WebrootID string
+	Keep      int
+}
+
+// WebrootReleaseParams identifies a release directory on a node.
+type WebrootReleaseParams struct {
+	TenantName  string
+	WebrootName string
+	Release     string
+}
+
+// CopyWebrootFilesParams copies the files of one webroot of a tenant into
+// another. Exclude lists paths, relative to the webroot, removed from the
+// target after the copy.
+type CopyWebrootFilesParams struct {
+	TenantName string
+	SourceName string
+	TargetName string
+	Exclude    []string
+}
+
+// UpdateZoneDNSSECStatusParams sets a zone's DNSSEC signing state.
+type UpdateZoneDNSSECStatusParams struct {
+	ZoneID string
+	Status string
+}
+
+// SyncZoneDNSSECKeysParams holds a zone's current keys as read from
+// PowerDNS.
+type SyncZoneDNSSECKeysParams struct {
+	ZoneID string
+	Keys   []DNSSECKey
+}
+
+// ListNodesMissingActivityParams selects the nodes whose agent does not
+// register an activity.
+type ListNodesMissingActivityParams struct {
+	NodeIDs  []string
+	Activity string
+}
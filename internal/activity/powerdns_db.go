@@ -3,9 +3,13 @@ package activity
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/edvin/hosting/internal/model"
 )
 
 // PowerDNSDB contains activities that write to the PowerDNS database.
@@ -109,6 +113,27 @@ func (a *PowerDNSDB) UpdateDNSRecord(ctx context.Context, params UpdateDNSRecord
 	return nil
 }
 
+// SetDNSRecordTTLParams holds parameters for changing the TTL of a DNS record set.
+type SetDNSRecordTTLParams struct {
+	DomainID int
+	Name     string
+	Type     string
+	TTL      int
+}
+
+// SetDNSRecordTTL sets the TTL of every record with the given name and type,
+// leaving their content untouched.
+func (a *PowerDNSDB) SetDNSRecordTTL(ctx context.Context, params SetDNSRecordTTLParams) error {
+	_, err := a.db.Exec(ctx,
+		`UPDATE records SET ttl = $1 WHERE domain_id = $2 AND name = $3 AND type = $4`,
+		params.TTL, params.DomainID, params.Name, params.Type,
+	)
+	if err != nil {
+		return fmt.Errorf("set dns record ttl: %w", err)
+	}
+	return nil
+}
+
 // DeleteDNSRecordParams holds parameters for deleting a DNS record.
 type DeleteDNSRecordParams struct {
 	DomainID int
@@ -150,3 +175,139 @@ func (a *PowerDNSDB) GetDNSZoneIDByName(ctx context.Context, name string) (int,
 	}
 	return id, nil
 }
+
+// DNSRecordKey identifies a PowerDNS record by name, type and content.
+type DNSRecordKey struct {
+	Name    string
+	Type    string
+	Content string
+}
+
+// DNSRecordBatchWrite is a record written by ApplyDNSRecordBatch.
+type DNSRecordBatchWrite struct {
+	Name     string
+	Type     string
+	Content  string
+	TTL      int
+	Priority *int
+}
+
+// ApplyDNSRecordBatchParams holds a set of record changes to one zone.
+type ApplyDNSRecordBatchParams struct {
+	DomainID int
+	Deletes  []DNSRecordKey
+	Writes   []DNSRecordBatchWrite
+}
+
+// ApplyDNSRecordBatch applies a set of record changes to a zone in a single
+// transaction and bumps the zone's SOA serial once, so secondaries and
+// resolvers never see a half-applied change. Deletes are applied first.
+// Writes replace any record with the same name, type and content, which
+// makes the batch safe to retry.
+func (a *PowerDNSDB) ApplyDNSRecordBatch(ctx context.Context, params ApplyDNSRecordBatchParams) error {
+	return pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		for _, d := range params.Deletes {
+			_, err := tx.Exec(ctx,
+				`DELETE FROM records WHERE domain_id = $1 AND name = $2 AND type = $3 AND content = $4`,
+				params.DomainID, d.Name, d.Type, d.Content,
+			)
+			if err != nil {
+				return fmt.Errorf("apply dns record batch: delete %s %s: %w", d.Name, d.Type, err)
+			}
+		}
+
+		for _, w := range params.Writes {
+			_, err := tx.Exec(ctx,
+				`DELETE FROM records WHERE domain_id = $1 AND name = $2 AND type = $3 AND content = $4`,
+				params.DomainID, w.Name, w.Type, w.Content,
+			)
+			if err != nil {
+				return fmt.Errorf("apply dns record batch: replace %s %s: %w", w.Name, w.Type, err)
+			}
+			_, err = tx.Exec(ctx,
+				`INSERT INTO records (domain_id, name, type, content, ttl, prio) VALUES ($1, $2, $3, $4, $5, $6)`,
+				params.DomainID, w.Name, w.Type, w.Content, w.TTL, w.Priority,
+			)
+			if err != nil {
+				return fmt.Errorf("apply dns record batch: write %s %s: %w", w.Name, w.Type, err)
+			}
+		}
+
+		if err := bumpDomainSOASerial(ctx, tx, params.DomainID); err != nil {
+			return fmt.Errorf("apply dns record batch: %w", err)
+		}
+		return nil
+	})
+}
+
+// bumpDomainSOASerial increments the serial of a zone's SOA record, if it
+// has one, so secondaries pick up the change.
+func bumpDomainSOASerial(ctx context.Context, tx pgx.Tx, domainID int) error {
+	var soa string
+	err := tx.QueryRow(ctx,
+		`SELECT content FROM records WHERE domain_id = $1 AND type = 'SOA' FOR UPDATE`, domainID,
+	).Scan(&soa)
+	if err == pgx.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read soa: %w", err)
+	}
+	bumped, err := bumpSOASerial(soa)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx,
+		`UPDATE records SET content = $1 WHERE domain_id = $2 AND type = 'SOA'`, bumped, domainID,
+	)
+	if err != nil {
+		return fmt.Errorf("bump soa serial: %w", err)
+	}
+	return nil
+}
+
+// bumpSOASerial returns the SOA content with its serial (the third field)
+// advanced by model.NextSOASerial.
+func bumpSOASerial(content string) (string, error) {
+	serial, err := soaSerial(content)
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(content)
+	fields[2] = strconv.FormatUint(uint64(model.NextSOASerial(serial)), 10)
+	return strings.Join(fields, " "), nil
+}
+
+// soaSerial returns the serial (the third field) of SOA content.
+func soaSerial(content string) (uint32, error) {
+	fields := strings.Fields(content)
+	if len(fields) < 3 {
+		return 0, fmt.Errorf("malformed soa content %q", content)
+	}
+	serial, err := strconv.ParseUint(fields[2], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("malformed soa serial %q: %w", fields[2], err)
+	}
+	return uint32(serial), nil
+}
+
+// GetDNSZoneSOASerial returns the SOA serial of a PowerDNS zone by name, or
+// nil if the zone or its SOA record does not exist.
+func (a *PowerDNSDB) GetDNSZoneSOASerial(ctx context.Context, name string) (*uint32, error) {
+	var soa string
+	err := a.db.QueryRow(ctx,
+		`SELECT r.content FROM records r JOIN domains d ON d.id = r.domain_id
+		 WHERE d.name = $1 AND r.type = 'SOA'`, name,
+	).Scan(&soa)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get dns zone soa: %w", err)
+	}
+	serial, err := soaSerial(soa)
+	if err != nil {
+		return nil, err
+	}
+	return &serial, nil
+}
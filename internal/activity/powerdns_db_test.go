@@ -0,0 +1,34 @@
+package activity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBumpSOASerial(t *testing.T) {
+	got, err := bumpSOASerial("ns1.example.net hostmaster.example.net 1 10800 3600 604800 300")
+	require.NoError(t, err)
+	assert.Equal(t, "ns1.example.net hostmaster.example.net 2 10800 3600 604800 300", got)
+}
+
+func TestBumpSOASerial_DateSerial(t *testing.T) {
+	got, err := bumpSOASerial("ns1.example.net hostmaster.example.net 2026101501 10800 3600 604800 300")
+	require.NoError(t, err)
+	assert.Equal(t, "ns1.example.net hostmaster.example.net 2026101502 10800 3600 604800 300", got)
+}
+
+func TestBumpSOASerial_WrapsSkippingZero(t *testing.T) {
+	got, err := bumpSOASerial("ns1.example.net hostmaster.example.net 4294967295 10800 3600 604800 300")
+	require.NoError(t, err)
+	assert.Equal(t, "ns1.example.net hostmaster.example.net 1 10800 3600 604800 300", got)
+}
+
+func TestBumpSOASerial_Malformed(t *testing.T) {
+	_, err := bumpSOASerial("ns1.example.net hostmaster.example.net")
+	assert.Error(t, err)
+
+	_, err = bumpSOASerial("ns1.example.net hostmaster.example.net abc 10800")
+	assert.Error(t, err)
+}
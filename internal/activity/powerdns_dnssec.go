@@ -0,0 +1,257 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edvin/hosting/internal/crypto"
+)
+
+// DNSSEC metadata written for signed zones: NSEC3 with no extra iterations
+// and no salt (RFC 9276), in narrow mode so PowerDNS hashes names on the fly
+// and the records table needs no ordername.
+const (
+	dnssecNSEC3Param  = "1 0 0 -"
+	dnssecNSEC3Narrow = "1"
+)
+
+// DNSSECKey is the public part of a key in the PowerDNS cryptokeys table.
+type DNSSECKey struct {
+	ID        int
+	Flags     int
+	Active    bool
+	Published bool
+	KeyTag    int
+	Algorithm int
+	DNSKEY    string
+	DS        string
+}
+
+// KSK reports whether the key is a key-signing key.
+func (k DNSSECKey) KSK() bool {
+	return k.Flags == crypto.DNSKEYFlagsKSK
+}
+
+// EnableDNSSECZoneParams identifies the zone to sign.
+type EnableDNSSECZoneParams struct {
+	DomainID int
+	ZoneName string
+}
+
+// EnableDNSSECZone signs a zone: it generates an active KSK and ZSK unless
+// the zone already has them, writes the NSEC3 metadata, fixes the auth
+// flags of delegation records and bumps the SOA serial. It is safe to retry.
+// It returns the zone's keys.
+func (a *PowerDNSDB) EnableDNSSECZone(ctx context.Context, params EnableDNSSECZoneParams) ([]DNSSECKey, error) {
+	err := pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		// Serialize with concurrent signing and rollovers of the zone.
+		if _, err := tx.Exec(ctx, `SELECT id FROM domains WHERE id = $1 FOR UPDATE`, params.DomainID); err != nil {
+			return fmt.Errorf("lock domain: %w", err)
+		}
+		for _, flags := range []int{crypto.DNSKEYFlagsKSK, crypto.DNSKEYFlagsZSK} {
+			var exists bool
+			err := tx.QueryRow(ctx,
+				`SELECT EXISTS(SELECT 1 FROM cryptokeys WHERE domain_id = $1 AND flags = $2 AND active)`,
+				params.DomainID, flags,
+			).Scan(&exists)
+			if err != nil {
+				return fmt.Errorf("check keys: %w", err)
+			}
+			if exists {
+				continue
+			}
+			if _, err := insertDNSSECKey(ctx, tx, params.DomainID, flags, true); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.Exec(ctx,
+			`DELETE FROM domainmetadata WHERE domain_id = $1 AND kind IN ('NSEC3PARAM', 'NSEC3NARROW')`, params.DomainID)
+		if err != nil {
+			return fmt.Errorf("clear metadata: %w", err)
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO domainmetadata (domain_id, kind, content) VALUES ($1, 'NSEC3PARAM', $2), ($1, 'NSEC3NARROW', $3)`,
+			params.DomainID, dnssecNSEC3Param, dnssecNSEC3Narrow)
+		if err != nil {
+			return fmt.Errorf("write metadata: %w", err)
+		}
+
+		// Delegation NS records and glue below a zone cut are not
+		// authoritative data and must not be signed.
+		_, err = tx.Exec(ctx,
+			`UPDATE records r SET auth = NOT EXISTS (
+				SELECT 1 FROM records d
+				WHERE d.domain_id = r.domain_id AND d.type = 'NS' AND d.name <> $2
+				  AND ((r.name = d.name AND r.type <> 'DS') OR right(r.name, length(d.name) + 1) = '.' || d.name))
+			 WHERE r.domain_id = $1`,
+			params.DomainID, params.ZoneName)
+		if err != nil {
+			return fmt.Errorf("rectify zone: %w", err)
+		}
+		return bumpDomainSOASerial(ctx, tx, params.DomainID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("enable dnssec for %s: %w", params.ZoneName, err)
+	}
+	return a.ListDNSSECKeys(ctx, params.DomainID)
+}
+
+// DisableDNSSECZone removes a zone's keys and NSEC3 metadata, after which
+// PowerDNS serves it unsigned.
+func (a *PowerDNSDB) DisableDNSSECZone(ctx context.Context, domainID int) error {
+	return pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM cryptokeys WHERE domain_id = $1`, domainID); err != nil {
+			return fmt.Errorf("disable dnssec: delete keys: %w", err)
+		}
+		_, err := tx.Exec(ctx,
+			`DELETE FROM domainmetadata WHERE domain_id = $1 AND kind IN ('NSEC3PARAM', 'NSEC3NARROW')`, domainID)
+		if err != nil {
+			return fmt.Errorf("disable dnssec: delete metadata: %w", err)
+		}
+		if err := bumpDomainSOASerial(ctx, tx, domainID); err != nil {
+			return fmt.Errorf("disable dnssec: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListDNSSECKeys returns the public part of a zone's keys, oldest first.
+func (a *PowerDNSDB) ListDNSSECKeys(ctx context.Context, domainID int) ([]DNSSECKey, error) {
+	rows, err := a.db.Query(ctx,
+		`SELECT k.id, k.flags, COALESCE(k.active, false), COALESCE(k.published, true), k.content, d.name
+		 FROM cryptokeys k JOIN domains d ON d.id = k.domain_id
+		 WHERE k.domain_id = $1 ORDER BY k.id`, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("list dnssec keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []DNSSECKey
+	for rows.Next() {
+		var k DNSSECKey
+		var content, zone string
+		if err := rows.Scan(&k.ID, &k.Flags, &k.Active, &k.Published, &content, &zone); err != nil {
+			return nil, fmt.Errorf("scan dnssec key: %w", err)
+		}
+		if err := describeDNSSECKey(&k, zone, content); err != nil {
+			return nil, fmt.Errorf("dnssec key %d: %w", k.ID, err)
+		}
+		keys = append(keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate dnssec keys: %w", err)
+	}
+	return keys, nil
+}
+
+// PrepublishDNSSECZSK adds a new ZSK that is published in the DNSKEY set but
+// not yet used for signing, the first step of a pre-publish rollover
+// (RFC 6781 §4.1.1.1). If the zone already has such a key it is reused, so
+// the activity is safe to retry. It returns the key's ID.
+func (a *PowerDNSDB) PrepublishDNSSECZSK(ctx context.Context, domainID int) (int, error) {
+	var id int
+	err := pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT id FROM domains WHERE id = $1 FOR UPDATE`, domainID); err != nil {
+			return fmt.Errorf("lock domain: %w", err)
+		}
+		err := tx.QueryRow(ctx,
+			`SELECT id FROM cryptokeys WHERE domain_id = $1 AND flags = $2 AND NOT active AND published ORDER BY id DESC LIMIT 1`,
+			domainID, crypto.DNSKEYFlagsZSK,
+		).Scan(&id)
+		if err == nil {
+			return nil
+		}
+		if err != pgx.ErrNoRows {
+			return fmt.Errorf("find prepublished key: %w", err)
+		}
+		if id, err = insertDNSSECKey(ctx, tx, domainID, crypto.DNSKEYFlagsZSK, false); err != nil {
+			return err
+		}
+		return bumpDomainSOASerial(ctx, tx, domainID)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("prepublish zsk: %w", err)
+	}
+	return id, nil
+}
+
+// ActivateDNSSECZSKParams identifies the ZSK to switch signing to.
+type ActivateDNSSECZSKParams struct {
+	DomainID int
+	KeyID    int
+}
+
+// ActivateDNSSECZSK makes the given ZSK the zone's signing key. The other
+// ZSKs stay published but inactive until they are removed.
+func (a *PowerDNSDB) ActivateDNSSECZSK(ctx context.Context, params ActivateDNSSECZSKParams) error {
+	return pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx,
+			`UPDATE cryptokeys SET active = (id = $2), published = true WHERE domain_id = $1 AND flags = $3`,
+			params.DomainID, params.KeyID, crypto.DNSKEYFlagsZSK)
+		if err != nil {
+			return fmt.Errorf("activate zsk %d: %w", params.KeyID, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("activate zsk %d: zone has no zsk", params.KeyID)
+		}
+		if err := bumpDomainSOASerial(ctx, tx, params.DomainID); err != nil {
+			return fmt.Errorf("activate zsk %d: %w", params.KeyID, err)
+		}
+		return nil
+	})
+}
+
+// RemoveInactiveDNSSECZSKs deletes the zone's retired ZSKs: those that are
+// neither active nor the given key.
+func (a *PowerDNSDB) RemoveInactiveDNSSECZSKs(ctx context.Context, params ActivateDNSSECZSKParams) error {
+	return pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`DELETE FROM cryptokeys WHERE domain_id = $1 AND flags = $2 AND NOT active AND id <> $3`,
+			params.DomainID, crypto.DNSKEYFlagsZSK, params.KeyID)
+		if err != nil {
+			return fmt.Errorf("remove retired zsks: %w", err)
+		}
+		if err := bumpDomainSOASerial(ctx, tx, params.DomainID); err != nil {
+			return fmt.Errorf("remove retired zsks: %w", err)
+		}
+		return nil
+	})
+}
+
+// insertDNSSECKey generates a key and stores it in cryptokeys.
+func insertDNSSECKey(ctx context.Context, tx pgx.Tx, domainID, flags int, active bool) (int, error) {
+	content, err := crypto.GenerateDNSSECKey()
+	if err != nil {
+		return 0, err
+	}
+	var id int
+	err = tx.QueryRow(ctx,
+		`INSERT INTO cryptokeys (domain_id, flags, active, published, content) VALUES ($1, $2, $3, true, $4) RETURNING id`,
+		domainID, flags, active, content,
+	).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("insert dnssec key: %w", err)
+	}
+	return id, nil
+}
+
+// describeDNSSECKey fills in the DNSKEY, key tag and DS of a key from its
+// stored private key.
+func describeDNSSECKey(k *DNSSECKey, zone, privateKey string) error {
+	pub, err := crypto.DNSSECPublicKey(privateKey)
+	if err != nil {
+		return err
+	}
+	if k.KeyTag, err = crypto.DNSSECKeyTag(k.Flags, pub); err != nil {
+		return err
+	}
+	if k.DS, err = crypto.DSContent(zone, k.Flags, pub); err != nil {
+		return err
+	}
+	k.Algorithm = crypto.DNSSECAlgorithm
+	k.DNSKEY = crypto.DNSKEYContent(k.Flags, pub)
+	return nil
+}
@@ -0,0 +1,131 @@
+package activity
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// replicaNSMetadataKind records the NS records added for a zone's secondary
+// regions, so they can be removed again when the regions change. PowerDNS
+// ignores metadata kinds starting with X-.
+const replicaNSMetadataKind = "X-HOSTING-REPLICA-NS"
+
+// ConfigureDNSZoneReplicationParams holds the nameservers of a zone's
+// secondary regions.
+type ConfigureDNSZoneReplicationParams struct {
+	DomainID    int
+	ZoneName    string
+	Nameservers []model.RegionNameserver
+}
+
+// ConfigureDNSZoneReplication makes the given nameservers AXFR secondaries
+// of a zone. With nameservers, the zone becomes a MASTER zone that NOTIFYs
+// and allows transfers to their addresses, and their hostnames are added to
+// the zone's NS records, which PowerDNS autosecondaries require before they
+// create the zone. Without nameservers, the zone is NATIVE again and the
+// NS records added earlier are removed. The SOA serial is bumped so the
+// secondaries pick up the change. It is safe to retry.
+func (a *PowerDNSDB) ConfigureDNSZoneReplication(ctx context.Context, params ConfigureDNSZoneReplicationParams) error {
+	err := pgx.BeginFunc(ctx, a.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT id FROM domains WHERE id = $1 FOR UPDATE`, params.DomainID); err != nil {
+			return fmt.Errorf("lock domain: %w", err)
+		}
+
+		zoneType := "NATIVE"
+		if len(params.Nameservers) > 0 {
+			zoneType = "MASTER"
+		}
+		if _, err := tx.Exec(ctx, `UPDATE domains SET type = $2 WHERE id = $1`, params.DomainID, zoneType); err != nil {
+			return fmt.Errorf("set zone type: %w", err)
+		}
+
+		wanted := map[string]bool{}
+		for _, ns := range params.Nameservers {
+			wanted[strings.TrimSuffix(ns.Hostname, ".")] = true
+		}
+
+		// Drop the NS records of regions that are no longer secondaries.
+		rows, err := tx.Query(ctx,
+			`SELECT content FROM domainmetadata WHERE domain_id = $1 AND kind = $2`,
+			params.DomainID, replicaNSMetadataKind)
+		if err != nil {
+			return fmt.Errorf("read replica ns: %w", err)
+		}
+		var owned []string
+		for rows.Next() {
+			var host string
+			if err := rows.Scan(&host); err != nil {
+				rows.Close()
+				return fmt.Errorf("scan replica ns: %w", err)
+			}
+			owned = append(owned, host)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterate replica ns: %w", err)
+		}
+		ownedSet := map[string]bool{}
+		for _, host := range owned {
+			if wanted[host] {
+				ownedSet[host] = true
+				continue
+			}
+			_, err := tx.Exec(ctx,
+				`DELETE FROM records WHERE domain_id = $1 AND name = $2 AND type = 'NS' AND content = $3`,
+				params.DomainID, params.ZoneName, host)
+			if err != nil {
+				return fmt.Errorf("delete ns %s: %w", host, err)
+			}
+		}
+
+		_, err = tx.Exec(ctx,
+			`DELETE FROM domainmetadata WHERE domain_id = $1 AND kind IN ('ALSO-NOTIFY', 'ALLOW-AXFR-FROM', $2)`,
+			params.DomainID, replicaNSMetadataKind)
+		if err != nil {
+			return fmt.Errorf("clear metadata: %w", err)
+		}
+
+		for _, ns := range params.Nameservers {
+			host := strings.TrimSuffix(ns.Hostname, ".")
+			_, err := tx.Exec(ctx,
+				`INSERT INTO domainmetadata (domain_id, kind, content) VALUES ($1, 'ALSO-NOTIFY', $2), ($1, 'ALLOW-AXFR-FROM', $2)`,
+				params.DomainID, ns.Address)
+			if err != nil {
+				return fmt.Errorf("write metadata for %s: %w", host, err)
+			}
+
+			// NS records that already exist, e.g. the brand's, are left
+			// alone and not recorded as added.
+			tag, err := tx.Exec(ctx,
+				`INSERT INTO records (domain_id, name, type, content, ttl)
+				 SELECT $1, $2, 'NS', $3, 86400
+				 WHERE NOT EXISTS (SELECT 1 FROM records WHERE domain_id = $1 AND name = $2 AND type = 'NS' AND content = $3)`,
+				params.DomainID, params.ZoneName, host)
+			if err != nil {
+				return fmt.Errorf("write ns %s: %w", host, err)
+			}
+			if tag.RowsAffected() > 0 {
+				ownedSet[host] = true
+			}
+		}
+
+		for host := range ownedSet {
+			_, err := tx.Exec(ctx,
+				`INSERT INTO domainmetadata (domain_id, kind, content) VALUES ($1, $2, $3)`,
+				params.DomainID, replicaNSMetadataKind, host)
+			if err != nil {
+				return fmt.Errorf("record replica ns %s: %w", host, err)
+			}
+		}
+		return bumpDomainSOASerial(ctx, tx, params.DomainID)
+	})
+	if err != nil {
+		return fmt.Errorf("configure replication for %s: %w", params.ZoneName, err)
+	}
+	return nil
+}
@@ -36,13 +36,38 @@ func (a *Stalwart) StalwartDeleteDomain(ctx context.Context, params StalwartDoma
 	return a.client.DeleteDomain(ctx, params.BaseURL, params.AdminToken, params.Domain)
 }
 
+type StalwartSetDKIMParams struct {
+	BaseURL    string `json:"base_url"`
+	AdminToken string `json:"admin_token"`
+	FQDNID     string `json:"fqdn_id"`
+}
+
+// StalwartSetDKIM installs an FQDN's own DKIM signing key in Stalwart. The
+// private key is read here rather than passed in, so it never appears in
+// workflow history.
+func (a *Stalwart) StalwartSetDKIM(ctx context.Context, params StalwartSetDKIMParams) error {
+	var sig stalwart.DKIMSignature
+	err := a.db.QueryRow(ctx,
+		`SELECT f.fqdn, k.selector, k.private_key_pem
+		 FROM fqdn_dkim_keys k
+		 JOIN fqdns f ON f.id = k.fqdn_id
+		 WHERE k.fqdn_id = $1`, params.FQDNID,
+	).Scan(&sig.Domain, &sig.Selector, &sig.PrivateKeyPEM)
+	if err != nil {
+		return fmt.Errorf("get dkim key for fqdn %s: %w", params.FQDNID, err)
+	}
+	return a.client.SetDKIMSignature(ctx, params.BaseURL, params.AdminToken, sig)
+}
+
 type StalwartCreateAccountParams struct {
 	BaseURL     string `json:"base_url"`
 	AdminToken  string `json:"admin_token"`
 	Address     string `json:"address"`
 	DisplayName string `json:"display_name"`
 	QuotaBytes  int64  `json:"quota_bytes"`
-	Password    string `json:"password"`
+	// Password is stored as the account secret. Stalwart accepts password
+	// hashes such as bcrypt there, so plain passwords need not be passed.
+	Password string `json:"password"`
 }
 
 func (a *Stalwart) StalwartCreateAccount(ctx context.Context, params StalwartCreateAccountParams) error {
@@ -164,3 +189,53 @@ func (a *Stalwart) resolveJMAPAccountID(ctx context.Context, baseURL, adminToken
 	}
 	return stalwart.EncodePrincipalID(principalID), nil
 }
+
+// StalwartRelayUserParams identifies an SMTP relay user on a Stalwart
+// instance.
+type StalwartRelayUserParams struct {
+	BaseURL     string `json:"base_url"`
+	AdminToken  string `json:"admin_token"`
+	RelayUserID string `json:"relay_user_id"`
+}
+
+// StalwartCreateRelayUser creates the principal of an SMTP relay user. The
+// password hash is read here rather than passed in, so it never appears in
+// workflow history.
+func (a *Stalwart) StalwartCreateRelayUser(ctx context.Context, params StalwartRelayUserParams) error {
+	var username, passwordHash string
+	err := a.db.QueryRow(ctx,
+		`SELECT username, password_hash FROM smtp_relay_users WHERE id = $1`, params.RelayUserID,
+	).Scan(&username, &passwordHash)
+	if err != nil {
+		return fmt.Errorf("get smtp relay user %s: %w", params.RelayUserID, err)
+	}
+	return a.client.CreateRelayUser(ctx, params.BaseURL, params.AdminToken, username, passwordHash)
+}
+
+// StalwartRelayRateLimitParams holds parameters for throttling a relay user.
+type StalwartRelayRateLimitParams struct {
+	BaseURL            string `json:"base_url"`
+	AdminToken         string `json:"admin_token"`
+	Username           string `json:"username"`
+	MaxMessagesPerHour int    `json:"max_messages_per_hour"`
+}
+
+// StalwartSetRelayRateLimit installs or replaces a relay user's throttle.
+func (a *Stalwart) StalwartSetRelayRateLimit(ctx context.Context, params StalwartRelayRateLimitParams) error {
+	return a.client.SetRelayThrottle(ctx, params.BaseURL, params.AdminToken, params.Username, params.MaxMessagesPerHour)
+}
+
+// StalwartDeleteRelayUserParams holds parameters for removing a relay user.
+type StalwartDeleteRelayUserParams struct {
+	BaseURL    string `json:"base_url"`
+	AdminToken string `json:"admin_token"`
+	Username   string `json:"username"`
+}
+
+// StalwartDeleteRelayUser removes a relay user's throttle and principal.
+func (a *Stalwart) StalwartDeleteRelayUser(ctx context.Context, params StalwartDeleteRelayUserParams) error {
+	if err := a.client.DeleteRelayThrottle(ctx, params.BaseURL, params.AdminToken, params.Username); err != nil {
+		return err
+	}
+	return a.client.DeleteAccount(ctx, params.BaseURL, params.AdminToken, params.Username)
+}
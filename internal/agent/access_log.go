@@ -0,0 +1,154 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/agent/runtime"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// Shared access logs are written by every node of a shard to the tenant's
+// logs dir on web storage, one file per node, so nodes never append to the
+// same file over CephFS. Readers merge the files by request time.
+
+// accessLogReadChunk is how much of a log file is read per step when
+// scanning backwards for the last lines.
+const accessLogReadChunk = 64 << 10
+
+// sharedAccessLogPath returns this node's shared access log for a webroot.
+func (m *NginxManager) sharedAccessLogPath(tenantName, webrootName string) string {
+	return filepath.Join(m.storageDir, tenantName, "logs", fmt.Sprintf("%s-access.%s.log", webrootName, m.hostname))
+}
+
+// EnsureAccessLogDir creates the tenant's shared logs dir when the webroot
+// has access logs enabled. nginx refuses to load a server block whose
+// access_log directory is missing.
+func (m *NginxManager) EnsureAccessLogDir(webroot *runtime.WebrootInfo) error {
+	if !webroot.AccessLog {
+		return nil
+	}
+	dir := filepath.Dir(m.sharedAccessLogPath(webroot.TenantName, webroot.Name))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return status.Errorf(codes.Internal, "mkdir access log dir %s: %v", dir, err)
+	}
+	return nil
+}
+
+// hostingJSONLine is a line in nginx's hosting_json log format.
+type hostingJSONLine struct {
+	Time        time.Time `json:"time"`
+	RemoteAddr  string    `json:"remote_addr"`
+	Method      string    `json:"method"`
+	URI         string    `json:"uri"`
+	Status      int       `json:"status"`
+	BytesSent   int64     `json:"bytes_sent"`
+	RequestTime float64   `json:"request_time"`
+	Referer     string    `json:"http_referer"`
+	UserAgent   string    `json:"http_user_agent"`
+	Host        string    `json:"host"`
+}
+
+// ReadAccessLog returns the last n requests from a webroot's shared access
+// logs across all nodes, oldest first. Lines that are not valid hosting_json
+// are skipped. A webroot that never logged has no lines.
+func (m *NginxManager) ReadAccessLog(tenantName, webrootName string, n int) ([]model.WebrootAccessLogLine, error) {
+	dir := filepath.Join(m.storageDir, tenantName, "logs")
+	prefix := webrootName + "-access."
+	paths, err := filepath.Glob(filepath.Join(dir, prefix+"*.log"))
+	if err != nil {
+		return nil, fmt.Errorf("list access logs in %s: %w", dir, err)
+	}
+
+	lines := []model.WebrootAccessLogLine{}
+	for _, path := range paths {
+		node := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), prefix), ".log")
+		raw, err := tailLines(path, n)
+		if err != nil {
+			return nil, fmt.Errorf("read access log %s: %w", path, err)
+		}
+		for _, l := range raw {
+			var entry hostingJSONLine
+			if err := json.Unmarshal(l, &entry); err != nil {
+				continue
+			}
+			lines = append(lines, model.WebrootAccessLogLine{
+				Time:        entry.Time,
+				Node:        node,
+				RemoteAddr:  entry.RemoteAddr,
+				Method:      entry.Method,
+				URI:         entry.URI,
+				Status:      entry.Status,
+				BytesSent:   entry.BytesSent,
+				RequestTime: entry.RequestTime,
+				Host:        entry.Host,
+				Referer:     entry.Referer,
+				UserAgent:   entry.UserAgent,
+			})
+		}
+	}
+
+	slices.SortStableFunc(lines, func(a, b model.WebrootAccessLogLine) int {
+		return a.Time.Compare(b.Time)
+	})
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
+
+// tailLines returns the last n non-empty lines of the file at path, reading
+// backwards so large logs are not read in full.
+func tailLines(path string, n int) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	var buf []byte
+	offset := info.Size()
+	for offset > 0 && bytes.Count(buf, []byte("\n")) <= n {
+		size := min(int64(accessLogReadChunk), offset)
+		offset -= size
+		chunk := make([]byte, size)
+		if _, err := f.ReadAt(chunk, offset); err != nil && err != io.EOF {
+			return nil, err
+		}
+		buf = append(chunk, buf...)
+	}
+
+	var lines [][]byte
+	for _, l := range bytes.Split(buf, []byte("\n")) {
+		if len(bytes.TrimSpace(l)) > 0 {
+			lines = append(lines, l)
+		}
+	}
+	// The first line is partial unless the whole file was read.
+	if offset > 0 && len(lines) > 0 {
+		lines = lines[1:]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines, nil
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/agent/runtime"
+)
+
+func writeAccessLog(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+}
+
+func accessLine(ts string, status int, uri string) string {
+	return fmt.Sprintf(`{"time":"%s","remote_addr":"203.0.113.7","method":"GET","uri":"%s","status":%d,"bytes_sent":512,"request_time":0.004,"upstream_time":"","http_referer":"","http_user_agent":"curl/8.5","host":"example.com","server_name":"example.com"}`, ts, uri, status)
+}
+
+func TestReadAccessLog_MergesNodes(t *testing.T) {
+	storage := t.TempDir()
+	mgr := NewNginxManager(zerolog.Nop(), Config{WebStorageDir: storage})
+	logs := filepath.Join(storage, "tenant1", "logs")
+
+	writeAccessLog(t, filepath.Join(logs, "mysite-access.web-1-node-0.log"),
+		accessLine("2026-01-02T10:00:00+00:00", 200, "/a"),
+		accessLine("2026-01-02T10:00:02+00:00", 404, "/c"),
+	)
+	writeAccessLog(t, filepath.Join(logs, "mysite-access.web-1-node-1.log"),
+		accessLine("2026-01-02T10:00:01+00:00", 500, "/b"),
+		"not json",
+		accessLine("2026-01-02T10:00:03+00:00", 301, "/d"),
+	)
+	// Another webroot's log is not included.
+	writeAccessLog(t, filepath.Join(logs, "other-access.web-1-node-0.log"),
+		accessLine("2026-01-02T10:00:04+00:00", 200, "/other"),
+	)
+
+	lines, err := mgr.ReadAccessLog("tenant1", "mysite", 3)
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+	assert.Equal(t, "/b", lines[0].URI)
+	assert.Equal(t, "web-1-node-1", lines[0].Node)
+	assert.Equal(t, 500, lines[0].Status)
+	assert.Equal(t, "/c", lines[1].URI)
+	assert.Equal(t, "web-1-node-0", lines[1].Node)
+	assert.Equal(t, "/d", lines[2].URI)
+	assert.Equal(t, "curl/8.5", lines[2].UserAgent)
+	assert.Equal(t, int64(512), lines[2].BytesSent)
+}
+
+func TestReadAccessLog_NoLogs(t *testing.T) {
+	mgr := NewNginxManager(zerolog.Nop(), Config{WebStorageDir: t.TempDir()})
+
+	lines, err := mgr.ReadAccessLog("tenant1", "mysite", 10)
+	require.NoError(t, err)
+	assert.Empty(t, lines)
+	assert.NotNil(t, lines)
+}
+
+func TestTailLines_LargeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access.log")
+	var lines []string
+	for i := range 5000 {
+		lines = append(lines, fmt.Sprintf("line %04d %s", i, strings.Repeat("x", 40)))
+	}
+	writeAccessLog(t, path, lines...)
+
+	got, err := tailLines(path, 3)
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.True(t, strings.HasPrefix(string(got[0]), "line 4997 "))
+	assert.True(t, strings.HasPrefix(string(got[2]), "line 4999 "))
+}
+
+func TestEnsureAccessLogDir(t *testing.T) {
+	storage := t.TempDir()
+	mgr := NewNginxManager(zerolog.Nop(), Config{WebStorageDir: storage})
+	webroot := &runtime.WebrootInfo{TenantName: "tenant1", Name: "mysite"}
+
+	require.NoError(t, mgr.EnsureAccessLogDir(webroot))
+	_, err := os.Stat(filepath.Join(storage, "tenant1", "logs"))
+	assert.True(t, os.IsNotExist(err))
+
+	webroot.AccessLog = true
+	require.NoError(t, mgr.EnsureAccessLogDir(webroot))
+	info, err := os.Stat(filepath.Join(storage, "tenant1", "logs"))
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+}
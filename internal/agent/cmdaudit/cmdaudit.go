@@ -0,0 +1,182 @@
+// Package cmdaudit records every external command the node agent runs.
+//
+// Commands are created with CommandContext instead of exec.CommandContext.
+// When one finishes, a Record with its redacted argument list, exit code and
+// duration is appended as a JSON line to the audit log, and counted in an
+// in-memory Summary that core can fetch through the GetCommandAuditSummary
+// activity.
+package cmdaudit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxRecentFailures bounds how many failed commands a Summary keeps.
+const maxRecentFailures = 20
+
+// Record is one executed command.
+type Record struct {
+	Time       time.Time `json:"time"`
+	Command    []string  `json:"command"`
+	Dir        string    `json:"dir,omitempty"`
+	ExitCode   int       `json:"exit_code"`
+	DurationMS int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Summary aggregates the commands recorded since the auditor was created.
+type Summary struct {
+	Since          time.Time `json:"since"`
+	Total          int64     `json:"total"`
+	Failed         int64     `json:"failed"`
+	RecentFailures []Record  `json:"recent_failures"`
+}
+
+// Auditor writes command records to a log and keeps a running summary.
+type Auditor struct {
+	mu       sync.Mutex
+	w        io.Writer
+	since    time.Time
+	total    int64
+	failed   int64
+	failures []Record
+}
+
+// New returns an auditor that writes JSON lines to w.
+func New(w io.Writer) *Auditor {
+	return &Auditor{w: w, since: time.Now()}
+}
+
+// Open returns an auditor appending to the file at path, creating it (and
+// its directory) if necessary. The file is only readable by its owner since
+// command lines can reveal tenant and resource names.
+func Open(path string) (*Auditor, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create audit log dir: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log %s: %w", path, err)
+	}
+	return New(f), nil
+}
+
+// Close closes the underlying log if it is closable.
+func (a *Auditor) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if c, ok := a.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Record appends r to the log and the summary. Write failures are ignored;
+// auditing must never fail the command it describes.
+func (a *Auditor) Record(r Record) {
+	line, _ := json.Marshal(r)
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	_, _ = a.w.Write(line)
+	a.total++
+	if r.ExitCode != 0 || r.Error != "" {
+		a.failed++
+		a.failures = append(a.failures, r)
+		if len(a.failures) > maxRecentFailures {
+			a.failures = a.failures[len(a.failures)-maxRecentFailures:]
+		}
+	}
+}
+
+// Summary returns the counts and most recent failures recorded so far.
+func (a *Auditor) Summary() Summary {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return Summary{
+		Since:          a.since,
+		Total:          a.total,
+		Failed:         a.failed,
+		RecentFailures: append([]Record{}, a.failures...),
+	}
+}
+
+var defaultAuditor atomic.Pointer[Auditor]
+
+func init() {
+	defaultAuditor.Store(New(io.Discard))
+}
+
+// SetDefault replaces the auditor used by CommandContext. Until it is called,
+// commands are only counted in the summary.
+func SetDefault(a *Auditor) {
+	defaultAuditor.Store(a)
+}
+
+// Default returns the auditor used by CommandContext.
+func Default() *Auditor {
+	return defaultAuditor.Load()
+}
+
+// Cmd is an exec.Cmd whose Run, Output and CombinedOutput are audited.
+type Cmd struct {
+	*exec.Cmd
+}
+
+// CommandContext is exec.CommandContext with auditing.
+func CommandContext(ctx context.Context, name string, args ...string) *Cmd {
+	return &Cmd{Cmd: exec.CommandContext(ctx, name, args...)}
+}
+
+// Run is exec.Cmd.Run with auditing.
+func (c *Cmd) Run() error {
+	start := time.Now()
+	err := c.Cmd.Run()
+	c.record(start, err)
+	return err
+}
+
+// Output is exec.Cmd.Output with auditing.
+func (c *Cmd) Output() ([]byte, error) {
+	start := time.Now()
+	out, err := c.Cmd.Output()
+	c.record(start, err)
+	return out, err
+}
+
+// CombinedOutput is exec.Cmd.CombinedOutput with auditing.
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	start := time.Now()
+	out, err := c.Cmd.CombinedOutput()
+	c.record(start, err)
+	return out, err
+}
+
+func (c *Cmd) record(start time.Time, err error) {
+	r := Record{
+		Time:       start.UTC(),
+		Command:    Redact(c.Args),
+		Dir:        c.Dir,
+		DurationMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		r.Error = err.Error()
+		r.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			r.ExitCode = exitErr.ExitCode()
+		}
+	}
+	Default().Record(r)
+}
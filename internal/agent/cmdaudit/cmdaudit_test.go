@@ -0,0 +1,133 @@
+package cmdaudit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useAuditor installs a buffer-backed auditor for the duration of the test.
+func useAuditor(t *testing.T) (*Auditor, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	a := New(&buf)
+	prev := Default()
+	SetDefault(a)
+	t.Cleanup(func() { SetDefault(prev) })
+	return a, &buf
+}
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []Record {
+	t.Helper()
+	var records []Record
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var r Record
+		require.NoError(t, json.Unmarshal([]byte(line), &r))
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestCmd_RecordsSuccess(t *testing.T) {
+	a, buf := useAuditor(t)
+
+	out, err := CommandContext(context.Background(), "sh", "-c", "echo hello").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", string(out))
+
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 1)
+	assert.Equal(t, []string{"sh", "-c", "echo hello"}, records[0].Command)
+	assert.Equal(t, 0, records[0].ExitCode)
+	assert.Empty(t, records[0].Error)
+
+	s := a.Summary()
+	assert.EqualValues(t, 1, s.Total)
+	assert.EqualValues(t, 0, s.Failed)
+	assert.Empty(t, s.RecentFailures)
+}
+
+func TestCmd_RecordsExitCode(t *testing.T) {
+	a, buf := useAuditor(t)
+
+	_, err := CommandContext(context.Background(), "sh", "-c", "exit 3").CombinedOutput()
+	require.Error(t, err)
+
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 1)
+	assert.Equal(t, 3, records[0].ExitCode)
+	assert.Equal(t, "exit status 3", records[0].Error)
+
+	s := a.Summary()
+	assert.EqualValues(t, 1, s.Failed)
+	require.Len(t, s.RecentFailures, 1)
+	assert.Equal(t, 3, s.RecentFailures[0].ExitCode)
+}
+
+func TestCmd_RecordsStartFailure(t *testing.T) {
+	_, buf := useAuditor(t)
+
+	err := CommandContext(context.Background(), "/nonexistent/binary").Run()
+	require.Error(t, err)
+
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 1)
+	assert.Equal(t, -1, records[0].ExitCode)
+	assert.NotEmpty(t, records[0].Error)
+}
+
+func TestCmd_RedactsLoggedCommand(t *testing.T) {
+	_, buf := useAuditor(t)
+
+	// The command itself sees the real argument; only the record is redacted.
+	out, err := CommandContext(context.Background(), "sh", "-c", "echo $0", "-psecret", "mysql").Output()
+	require.NoError(t, err)
+	assert.Equal(t, "-psecret\n", string(out))
+
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 1)
+	assert.Equal(t, "-p***", records[0].Command[3])
+	assert.NotContains(t, buf.String(), "secret")
+}
+
+func TestAuditor_KeepsRecentFailures(t *testing.T) {
+	a := New(&bytes.Buffer{})
+	for i := 0; i < maxRecentFailures+5; i++ {
+		a.Record(Record{Command: []string{"false"}, ExitCode: i + 1})
+	}
+
+	s := a.Summary()
+	assert.EqualValues(t, maxRecentFailures+5, s.Failed)
+	require.Len(t, s.RecentFailures, maxRecentFailures)
+	assert.Equal(t, 6, s.RecentFailures[0].ExitCode)
+	assert.Equal(t, maxRecentFailures+5, s.RecentFailures[maxRecentFailures-1].ExitCode)
+}
+
+func TestOpen_AppendsToFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "commands.log")
+
+	a, err := Open(path)
+	require.NoError(t, err)
+	a.Record(Record{Command: []string{"true"}})
+	require.NoError(t, a.Close())
+
+	a, err = Open(path)
+	require.NoError(t, err)
+	a.Record(Record{Command: []string{"false"}, ExitCode: 1})
+	require.NoError(t, a.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(data), "\n"))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
@@ -0,0 +1,64 @@
+package cmdaudit
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces secret values in audited command lines.
+const Redacted = "***"
+
+var (
+	// mysql -p<password>, bare or shell-quoted inside a bash -c script.
+	mysqlPasswordFlag = regexp.MustCompile(`(^|[\s'"])-p[^\s'"]+`)
+	// --password=..., --secret=..., --secret-key=...
+	secretFlagValue = regexp.MustCompile(`(--(?:password|secret|secret-key)=)[^\s'"]+`)
+	// SQL: IDENTIFIED BY '...', IDENTIFIED WITH plugin AS '...',
+	// PASSWORD = '...', SOURCE_PASSWORD='...', MASTER_PASSWORD='...'.
+	sqlPassword = regexp.MustCompile(`(?i)(IDENTIFIED\s+(?:WITH\s+\w+\s+)?(?:BY|AS)\s+|PASSWORD\s*=\s*)'[^']*'`)
+)
+
+// secretFlags take the secret as the following argument.
+var secretFlags = map[string]bool{
+	"--password":   true,
+	"--secret":     true,
+	"--secret-key": true,
+}
+
+// Redact returns a copy of args with passwords and keys masked. Values passed
+// through the environment or stdin never reach the audit log and need no
+// redaction.
+func Redact(args []string) []string {
+	out := make([]string, len(args))
+	copy(out, args)
+
+	mysql := false
+	aclSetUser := false
+	for _, a := range args {
+		if strings.Contains(a, "mysql") {
+			mysql = true
+		}
+		if strings.EqualFold(a, "SETUSER") {
+			aclSetUser = true
+		}
+	}
+	valkeyCLI := len(args) > 0 && filepath.Base(args[0]) == "valkey-cli"
+
+	for i, a := range out {
+		if i > 0 && (secretFlags[args[i-1]] || valkeyCLI && args[i-1] == "-a") {
+			out[i] = Redacted
+			continue
+		}
+		if mysql {
+			a = mysqlPasswordFlag.ReplaceAllString(a, "${1}-p"+Redacted)
+		}
+		if aclSetUser && i > 0 && len(a) > 1 && strings.ContainsRune(">#<!", rune(a[0])) {
+			a = a[:1] + Redacted
+		}
+		a = secretFlagValue.ReplaceAllString(a, "${1}"+Redacted)
+		a = sqlPassword.ReplaceAllString(a, "${1}'"+Redacted+"'")
+		out[i] = a
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+package cmdaudit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{
+			name: "mysql password flag",
+			args: []string{"mysql", "-u", "root", "-psecret", "-h", "127.0.0.1", "-e", "SELECT 1"},
+			want: []string{"mysql", "-u", "root", "-p***", "-h", "127.0.0.1", "-e", "SELECT 1"},
+		},
+		{
+			name: "mysqldump in bash script",
+			args: []string{"bash", "-c", "mysqldump '-u' 'root' '-ps3cr3t' 'db' | gzip > /tmp/db.sql.gz"},
+			want: []string{"bash", "-c", "mysqldump '-u' 'root' '-p***' 'db' | gzip > /tmp/db.sql.gz"},
+		},
+		{
+			name: "replication source password",
+			args: []string{"mysql", "-e", "CHANGE REPLICATION SOURCE TO SOURCE_HOST='db1', SOURCE_USER='repl', SOURCE_PASSWORD='hunter2', SOURCE_AUTO_POSITION=1"},
+			want: []string{"mysql", "-e", "CHANGE REPLICATION SOURCE TO SOURCE_HOST='db1', SOURCE_USER='repl', SOURCE_PASSWORD='***', SOURCE_AUTO_POSITION=1"},
+		},
+		{
+			name: "user password hash",
+			args: []string{"mysql", "-e", "CREATE USER 'u'@'%' IDENTIFIED WITH mysql_native_password AS '*ABCDEF'"},
+			want: []string{"mysql", "-e", "CREATE USER 'u'@'%' IDENTIFIED WITH mysql_native_password AS '***'"},
+		},
+		{
+			name: "identified by",
+			args: []string{"mysql", "-e", "ALTER USER 'u'@'%' identified by 'plain'"},
+			want: []string{"mysql", "-e", "ALTER USER 'u'@'%' identified by '***'"},
+		},
+		{
+			name: "valkey acl setuser",
+			args: []string{"valkey-cli", "-s", "/run/valkey/v.sock", "ACL", "SETUSER", "app", "on", "#abc123", ">plain", "~*", "+@all"},
+			want: []string{"valkey-cli", "-s", "/run/valkey/v.sock", "ACL", "SETUSER", "app", "on", "#***", ">***", "~*", "+@all"},
+		},
+		{
+			name: "valkey auth flag",
+			args: []string{"valkey-cli", "-h", "::1", "-a", "pass", "PING"},
+			want: []string{"valkey-cli", "-h", "::1", "-a", "***", "PING"},
+		},
+		{
+			name: "secret key flags",
+			args: []string{"radosgw-admin", "key", "create", "--access-key=AK", "--secret-key=SK", "--secret", "S2"},
+			want: []string{"radosgw-admin", "key", "create", "--access-key=AK", "--secret-key=***", "--secret", "***"},
+		},
+		{
+			name: "unrelated commands untouched",
+			args: []string{"mkdir", "-p", "/var/lib/mysql/tmp"},
+			want: []string{"mkdir", "-p", "/var/lib/mysql/tmp"},
+		},
+		{
+			name: "dash a outside valkey-cli untouched",
+			args: []string{"gpasswd", "-a", "tenant1", "sftp"},
+			want: []string{"gpasswd", "-a", "tenant1", "sftp"},
+		},
+		{
+			name: "dash p outside mysql untouched",
+			args: []string{"cp", "-pr", "/a", "/b"},
+			want: []string{"cp", "-pr", "/a", "/b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Redact(tt.args))
+		})
+	}
+}
+
+func TestRedact_DoesNotModifyInput(t *testing.T) {
+	args := []string{"mysql", "-psecret"}
+	Redact(args)
+	assert.Equal(t, "-psecret", args[1])
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
 	"text/template"
 
 	"github.com/rs/zerolog"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/agent/runtime"
+	"github.com/edvin/hosting/internal/logging"
 )
 
 // CronJobInfo holds the information needed to manage a cron job on a node.
@@ -23,22 +27,34 @@ type CronJobInfo struct {
 	WorkingDirectory string
 	TimeoutSeconds   int
 	MaxMemoryMB      int
+	Timezone         string
 	EnvFileName      string
+	EnvVars          map[string]string
 }
 
+// cronEnvCredential is the systemd credential name the cron job env is
+// loaded under, and cronEnvWrapper the script that exports it before running
+// the command.
+const (
+	cronEnvCredential = "cron-env"
+	cronEnvWrapper    = "/usr/local/bin/cron-env"
+)
+
 // CronManager manages systemd timer units for tenant cron jobs.
 type CronManager struct {
 	logger        zerolog.Logger
 	webStorageDir string
 	unitDir       string
+	credDir       string
 }
 
 // NewCronManager creates a new CronManager.
 func NewCronManager(logger zerolog.Logger, cfg Config) *CronManager {
 	return &CronManager{
-		logger:        logger.With().Str("component", "cron-manager").Logger(),
+		logger:        logging.Component(logger, "cron-manager"),
 		webStorageDir: cfg.WebStorageDir,
 		unitDir:       "/etc/systemd/system",
+		credDir:       "/etc/hosting/cron-credentials",
 	}
 }
 
@@ -54,8 +70,15 @@ func (m *CronManager) timerPath(info *CronJobInfo) string {
 	return filepath.Join(m.unitDir, m.timerName(info)+".timer")
 }
 
+// credentialPath is where the job's env is stored, encrypted with the host
+// credential key by systemd-creds.
+func (m *CronManager) credentialPath(info *CronJobInfo) string {
+	return filepath.Join(m.credDir, m.timerName(info)+".cred")
+}
+
 func (m *CronManager) workDir(info *CronJobInfo) string {
-	base := filepath.Join(m.webStorageDir, info.TenantName, "webroots", info.WebrootName)
+	webrootDir := filepath.Join(m.webStorageDir, info.TenantName, "webroots", info.WebrootName)
+	base := runtime.AppDir(webrootDir, runtime.HasReleases(webrootDir))
 	if info.WorkingDirectory != "" {
 		return filepath.Join(base, info.WorkingDirectory)
 	}
@@ -70,11 +93,16 @@ func (m *CronManager) CreateUnits(ctx context.Context, info *CronJobInfo) error
 		Str("name", info.Name).
 		Msg("creating cron job units")
 
-	calendar, err := cronToSystemdCalendar(info.Schedule)
+	calendar, err := cronCalendar(info)
 	if err != nil {
 		return fmt.Errorf("invalid cron schedule %q: %w", info.Schedule, err)
 	}
 
+	// Write the env credential before the unit that references it.
+	if err := m.writeEnvCredential(ctx, info); err != nil {
+		return err
+	}
+
 	// Write service unit.
 	serviceContent, err := m.renderService(info)
 	if err != nil {
@@ -108,12 +136,13 @@ func (m *CronManager) DeleteUnits(ctx context.Context, info *CronJobInfo) error
 	m.logger.Info().Str("unit", name).Msg("deleting cron job units")
 
 	// Stop and disable timer (ignore errors — may not be running).
-	_ = exec.CommandContext(ctx, "systemctl", "stop", name+".timer").Run()
-	_ = exec.CommandContext(ctx, "systemctl", "disable", name+".timer").Run()
+	_ = cmdaudit.CommandContext(ctx, "systemctl", "stop", name+".timer").Run()
+	_ = cmdaudit.CommandContext(ctx, "systemctl", "disable", name+".timer").Run()
 
 	// Remove unit files.
 	os.Remove(m.servicePath(info))
 	os.Remove(m.timerPath(info))
+	os.Remove(m.credentialPath(info))
 
 	return m.daemonReload(ctx)
 }
@@ -123,7 +152,7 @@ func (m *CronManager) EnableTimer(ctx context.Context, info *CronJobInfo) error
 	name := m.timerName(info)
 	m.logger.Info().Str("unit", name).Msg("enabling cron timer")
 
-	cmd := exec.CommandContext(ctx, "systemctl", "enable", "--now", name+".timer")
+	cmd := cmdaudit.CommandContext(ctx, "systemctl", "enable", "--now", name+".timer")
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("enable timer %s: %s: %w", name, string(output), err)
 	}
@@ -135,15 +164,90 @@ func (m *CronManager) DisableTimer(ctx context.Context, info *CronJobInfo) error
 	name := m.timerName(info)
 	m.logger.Info().Str("unit", name).Msg("disabling cron timer")
 
-	cmd := exec.CommandContext(ctx, "systemctl", "disable", "--now", name+".timer")
+	cmd := cmdaudit.CommandContext(ctx, "systemctl", "disable", "--now", name+".timer")
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("disable timer %s: %s: %w", name, string(output), err)
 	}
+
+	// is-active exits non-zero for a stopped unit, so only its output is
+	// checked.
+	output, _ := cmdaudit.CommandContext(ctx, "systemctl", "is-active", name+".timer").Output()
+	if state := strings.TrimSpace(string(output)); timerRunning(state) {
+		return fmt.Errorf("timer %s still %s after disable", name, state)
+	}
+	return nil
+}
+
+// StopRun stops a run of the job that is in progress. The service is a
+// oneshot unit, so stopping it kills the command; with no run in progress it
+// is a no-op.
+func (m *CronManager) StopRun(ctx context.Context, info *CronJobInfo) error {
+	name := m.timerName(info)
+	m.logger.Info().Str("unit", name).Msg("stopping cron run")
+
+	cmd := cmdaudit.CommandContext(ctx, "systemctl", "stop", name+".service")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("stop service %s: %s: %w", name, string(output), err)
+	}
 	return nil
 }
 
+// timerRunning reports whether a systemctl is-active state means the timer
+// can still fire.
+func timerRunning(state string) bool {
+	return state == "active" || state == "activating" || state == "reloading"
+}
+
+// writeEnvCredential stores the job's env vars as a systemd encrypted
+// credential, or removes it when the job has none. The values never appear in
+// the unit file: systemd decrypts the credential into the service's private
+// $CREDENTIALS_DIRECTORY when the job starts, and the cron-env wrapper exports
+// it from there.
+func (m *CronManager) writeEnvCredential(ctx context.Context, info *CronJobInfo) error {
+	path := m.credentialPath(info)
+	if len(info.EnvVars) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove env credential: %w", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(m.credDir, 0700); err != nil {
+		return fmt.Errorf("create credential dir: %w", err)
+	}
+	cmd := cmdaudit.CommandContext(ctx, "systemd-creds", "encrypt", "--name="+cronEnvCredential, "-", path)
+	cmd.Stdin = strings.NewReader(encodeCronEnv(info.EnvVars))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("encrypt env credential: %s: %w", string(output), err)
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		return fmt.Errorf("chmod env credential: %w", err)
+	}
+	return nil
+}
+
+// encodeCronEnv serializes env vars as NUL-terminated NAME=VALUE entries,
+// sorted by name. The wrapper splits on NUL and passes each entry to export
+// unchanged, so values may contain any byte except NUL.
+func encodeCronEnv(vars map[string]string) string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte('=')
+		b.WriteString(vars[name])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
 func (m *CronManager) daemonReload(ctx context.Context) error {
-	cmd := exec.CommandContext(ctx, "systemctl", "daemon-reload")
+	cmd := cmdaudit.CommandContext(ctx, "systemctl", "daemon-reload")
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("daemon-reload: %s: %w", string(output), err)
 	}
@@ -163,8 +267,11 @@ Environment=CRON_JOB_ID={{ .ID }}
 {{- if .EnvFilePath }}
 EnvironmentFile=-{{ .EnvFilePath }}
 {{- end }}
+{{- if .CredentialPath }}
+LoadCredentialEncrypted={{ .CredentialName }}:{{ .CredentialPath }}
+{{- end }}
 ExecStartPre=/bin/mkdir -p {{ .LockDir }}
-ExecStart=/usr/bin/flock --nonblock --conflict-exit-code 75 {{ .LockFile }} /bin/bash -c {{ .Command }}
+ExecStart=/usr/bin/flock --nonblock --conflict-exit-code 75 {{ .LockFile }} {{ if .CredentialPath }}{{ .EnvWrapper }} {{ end }}/bin/bash -c {{ .Command }}
 ExecStopPost=+/usr/local/bin/cron-outcome
 SuccessExitStatus=75
 TimeoutStopSec={{ .TimeoutSeconds }}
@@ -194,11 +301,14 @@ WantedBy=timers.target
 
 type serviceData struct {
 	CronJobInfo
-	WorkDir     string
-	WebrootPath string
-	LockDir     string
-	LockFile    string
-	EnvFilePath string
+	WorkDir        string
+	WebrootPath    string
+	LockDir        string
+	LockFile       string
+	EnvFilePath    string
+	CredentialName string
+	CredentialPath string
+	EnvWrapper     string
 }
 
 type timerData struct {
@@ -228,6 +338,11 @@ func (m *CronManager) renderService(info *CronJobInfo) (string, error) {
 		LockFile:    m.lockFile(info),
 		EnvFilePath: filepath.Join(webrootPath, envFileName),
 	}
+	if len(info.EnvVars) > 0 {
+		data.CredentialName = cronEnvCredential
+		data.CredentialPath = m.credentialPath(info)
+		data.EnvWrapper = cronEnvWrapper
+	}
 	var buf strings.Builder
 	if err := serviceTemplate.Execute(&buf, data); err != nil {
 		return "", err
@@ -247,6 +362,20 @@ func (m *CronManager) renderTimer(info *CronJobInfo, calendar string) (string, e
 	return buf.String(), nil
 }
 
+// cronCalendar returns the OnCalendar value of a job. systemd evaluates the
+// calendar in the zone named after the time, and in the node's local zone
+// when there is none.
+func cronCalendar(info *CronJobInfo) (string, error) {
+	calendar, err := cronToSystemdCalendar(info.Schedule)
+	if err != nil {
+		return "", err
+	}
+	if info.Timezone != "" {
+		calendar += " " + info.Timezone
+	}
+	return calendar, nil
+}
+
 // cronToSystemdCalendar converts a 5-field cron expression to systemd OnCalendar format.
 func cronToSystemdCalendar(cron string) (string, error) {
 	fields := strings.Fields(cron)
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCronManager() *CronManager {
+	return NewCronManager(zerolog.Nop(), Config{WebStorageDir: "/var/www/storage"})
+}
+
+func TestCronRenderService_NoEnvVars(t *testing.T) {
+	m := newTestCronManager()
+	out, err := m.renderService(&CronJobInfo{
+		ID: "job1", TenantName: "t1", WebrootName: "w1", Command: "'php artisan schedule:run'",
+		TimeoutSeconds: 60, MaxMemoryMB: 256,
+	})
+	require.NoError(t, err)
+
+	assert.NotContains(t, out, "LoadCredentialEncrypted")
+	assert.NotContains(t, out, cronEnvWrapper)
+	assert.Contains(t, out, "/var/www/storage/t1/.locks/cron-job1.lock /bin/bash -c 'php artisan schedule:run'")
+}
+
+func TestCronRenderService_EnvVarsLoadedAsCredential(t *testing.T) {
+	m := newTestCronManager()
+	out, err := m.renderService(&CronJobInfo{
+		ID: "job1", TenantName: "t1", WebrootName: "w1", Command: "'./backup.sh'",
+		TimeoutSeconds: 60, MaxMemoryMB: 256,
+		EnvVars: map[string]string{"API_TOKEN": "s3cr3t-value"},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, out, "LoadCredentialEncrypted=cron-env:/etc/hosting/cron-credentials/cron-t1-job1.cred\n")
+	assert.Contains(t, out, "cron-job1.lock /usr/local/bin/cron-env /bin/bash -c './backup.sh'")
+	// Neither names nor values end up in the unit, so systemctl show cannot
+	// reveal them.
+	assert.NotContains(t, out, "API_TOKEN")
+	assert.NotContains(t, out, "s3cr3t-value")
+}
+
+func TestEncodeCronEnv(t *testing.T) {
+	got := encodeCronEnv(map[string]string{
+		"B":     "multi\nline",
+		"A":     "x=y",
+		"EMPTY": "",
+	})
+	assert.Equal(t, "A=x=y\x00B=multi\nline\x00EMPTY=\x00", got)
+	assert.Equal(t, "", encodeCronEnv(nil))
+}
+
+func TestCronCalendar_Timezone(t *testing.T) {
+	cal, err := cronCalendar(&CronJobInfo{Schedule: "0 2 * * *", Timezone: "Europe/Oslo"})
+	require.NoError(t, err)
+	assert.Equal(t, "*-*-* 2:0:00 Europe/Oslo", cal)
+
+	cal, err = cronCalendar(&CronJobInfo{Schedule: "*/15 * * * 1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Mon *-*-* *:0/15:00", cal)
+}
+
+func TestTimerRunning(t *testing.T) {
+	for _, state := range []string{"active", "activating", "reloading"} {
+		assert.True(t, timerRunning(state), state)
+	}
+	for _, state := range []string{"inactive", "failed", "unknown", ""} {
+		assert.False(t, timerRunning(state), state)
+	}
+}
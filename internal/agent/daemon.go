@@ -5,13 +5,16 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
 	"text/template"
 
 	"github.com/rs/zerolog"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/agent/runtime"
+	"github.com/edvin/hosting/internal/logging"
 )
 
 // DaemonInfo holds the information needed to manage a daemon on a node.
@@ -39,7 +42,7 @@ type DaemonManager struct {
 // NewDaemonManager creates a new DaemonManager.
 func NewDaemonManager(logger zerolog.Logger, cfg Config) *DaemonManager {
 	return &DaemonManager{
-		logger:        logger.With().Str("component", "daemon-manager").Logger(),
+		logger:        logging.Component(logger, "daemon-manager"),
 		webStorageDir: cfg.WebStorageDir,
 	}
 }
@@ -61,10 +64,11 @@ func (m *DaemonManager) Configure(ctx context.Context, info *DaemonInfo) error {
 		Str("webStorageDir", m.webStorageDir).
 		Msg("configuring daemon")
 
-	workDir := filepath.Join(m.webStorageDir, info.TenantName, "webroots", info.WebrootName)
+	webrootDir := filepath.Join(m.webStorageDir, info.TenantName, "webroots", info.WebrootName)
+	workDir := runtime.AppDir(webrootDir, runtime.HasReleases(webrootDir))
 
 	// Read env vars from the webroot's .env.hosting file and add PORT/HOST for proxy daemons.
-	env := readEnvFile(workDir, info.EnvFileName)
+	env := readEnvFile(webrootDir, info.EnvFileName)
 	if info.ProxyPort != nil {
 		env["PORT"] = fmt.Sprintf("%d", *info.ProxyPort)
 		if info.HostIP != "" {
@@ -231,7 +235,7 @@ func (m *DaemonManager) CleanOrphanedConfigs(expectedConfigs map[string]bool) ([
 
 // supervisorctl executes a supervisorctl command.
 func (m *DaemonManager) supervisorctl(ctx context.Context, args ...string) error {
-	cmd := exec.CommandContext(ctx, "supervisorctl", args...)
+	cmd := cmdaudit.CommandContext(ctx, "supervisorctl", args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("supervisorctl %v: %s: %w", args, string(output), err)
 	}
@@ -6,7 +6,6 @@ import (
 	"net"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
@@ -15,6 +14,10 @@ import (
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/logging"
+	"github.com/edvin/hosting/internal/model"
 )
 
 // validNameRe matches only alphanumeric characters and underscores.
@@ -31,7 +34,7 @@ type DatabaseManager struct {
 // NewDatabaseManager creates a new DatabaseManager.
 func NewDatabaseManager(logger zerolog.Logger, cfg Config) *DatabaseManager {
 	return &DatabaseManager{
-		logger:       logger.With().Str("component", "database-manager").Logger(),
+		logger:       logging.Component(logger, "database-manager"),
 		dsn:          cfg.MySQLDSN,
 		replPassword: cfg.MySQLReplPassword,
 	}
@@ -120,8 +123,8 @@ func (m *DatabaseManager) execMySQL(ctx context.Context, sql string) error {
 	}
 
 	args := append(baseArgs, "-e", sql)
-	cmd := exec.CommandContext(ctx, "mysql", args...)
-	m.logger.Debug().Str("sql", sql).Msg("executing mysql command")
+	cmd := cmdaudit.CommandContext(ctx, "mysql", args...)
+	m.logger.Debug().Strs("cmd", cmdaudit.Redact(cmd.Args)).Msg("executing mysql command")
 
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.Internal, "mysql command failed: %s: %v", string(output), err)
@@ -169,15 +172,21 @@ func validatePrivilege(priv string) error {
 	return nil
 }
 
-// CreateDatabase creates a new MySQL database.
-func (m *DatabaseManager) CreateDatabase(ctx context.Context, name string) error {
+// CreateDatabase creates a new MySQL database with the given character set and
+// collation. Empty values fall back to the platform defaults; anything outside
+// model.DatabaseCollations is rejected before it reaches the SQL statement.
+func (m *DatabaseManager) CreateDatabase(ctx context.Context, name, charset, collation string) error {
 	if err := validateName(name); err != nil {
 		return err
 	}
+	charset, collation, err := model.ResolveDatabaseCharset(charset, collation)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
 
-	m.logger.Info().Str("database", name).Msg("creating database")
+	m.logger.Info().Str("database", name).Str("charset", charset).Str("collation", collation).Msg("creating database")
 
-	sql := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", name)
+	sql := fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s` CHARACTER SET %s COLLATE %s", name, charset, collation)
 	return m.execMySQL(ctx, sql)
 }
 
@@ -302,15 +311,14 @@ func (m *DatabaseManager) DumpDatabase(ctx context.Context, name, dumpPath strin
 		return status.Errorf(codes.Internal, "create dump directory: %v", err)
 	}
 
-	baseArgs, err := m.mysqlArgs()
+	dumpArgs, err := m.dumpArgs(name)
 	if err != nil {
 		return status.Errorf(codes.Internal, "parse mysql DSN: %v", err)
 	}
 
-	// Build: mysqldump {auth args} {dbname} | gzip > {dumpPath}
-	dumpArgs := append(baseArgs, "--single-transaction", "--routines", "--triggers", name)
+	// Build: mysqldump {auth args} {dump options} {dbname} | gzip > {dumpPath}
 	shell := fmt.Sprintf("mysqldump %s | gzip > %s", strings.Join(quoteArgs(dumpArgs), " "), dumpPath)
-	cmd := exec.CommandContext(ctx, "bash", "-c", shell)
+	cmd := cmdaudit.CommandContext(ctx, "bash", "-c", shell)
 	m.logger.Debug().Str("shell", shell).Msg("executing mysqldump")
 
 	if output, err := cmd.CombinedOutput(); err != nil {
@@ -320,6 +328,18 @@ func (m *DatabaseManager) DumpDatabase(ctx context.Context, name, dumpPath strin
 	return nil
 }
 
+// dumpArgs returns the mysqldump arguments for a backup of name.
+// --source-data=2 writes the binlog position of the snapshot as a comment,
+// which point-in-time restores replay from (see parseDumpBinlogPosition). It
+// needs the RELOAD and REPLICATION CLIENT privileges.
+func (m *DatabaseManager) dumpArgs(name string) ([]string, error) {
+	args, err := m.mysqlArgs()
+	if err != nil {
+		return nil, err
+	}
+	return append(args, "--single-transaction", "--source-data=2", "--routines", "--triggers", name), nil
+}
+
 // ImportDatabase imports a gzipped SQL dump into a MySQL database.
 func (m *DatabaseManager) ImportDatabase(ctx context.Context, name, dumpPath string) error {
 	if err := validateName(name); err != nil {
@@ -336,7 +356,7 @@ func (m *DatabaseManager) ImportDatabase(ctx context.Context, name, dumpPath str
 	// Build: gunzip -c {dumpPath} | mysql {auth args} {dbname}
 	importArgs := append(baseArgs, name)
 	shell := fmt.Sprintf("gunzip -c %s | mysql %s", dumpPath, strings.Join(quoteArgs(importArgs), " "))
-	cmd := exec.CommandContext(ctx, "bash", "-c", shell)
+	cmd := cmdaudit.CommandContext(ctx, "bash", "-c", shell)
 	m.logger.Debug().Str("shell", shell).Msg("executing mysql import")
 
 	if output, err := cmd.CombinedOutput(); err != nil {
@@ -411,7 +431,7 @@ func (m *DatabaseManager) GetReplicationStatus(ctx context.Context) (*Replicatio
 		return nil, fmt.Errorf("parse mysql DSN: %w", err)
 	}
 	args := append(baseArgs, "-e", "SHOW REPLICA STATUS\\G")
-	cmd := exec.CommandContext(ctx, "mysql", args...)
+	cmd := cmdaudit.CommandContext(ctx, "mysql", args...)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return nil, fmt.Errorf("show replica status: %s: %w", string(output), err)
@@ -426,14 +446,21 @@ func (m *DatabaseManager) StopReplication(ctx context.Context) error {
 }
 
 // parseReplicaStatus parses the vertical output of SHOW REPLICA STATUS.
+// GTID sets with more than one source continue on the following lines, one
+// source per line.
 func parseReplicaStatus(output string) *ReplicationStatus {
 	status := &ReplicationStatus{}
+	var gtidSet *string // GTID set field still being continued
 	for _, line := range strings.Split(output, "\n") {
 		line = strings.TrimSpace(line)
 		parts := strings.SplitN(line, ": ", 2)
 		if len(parts) != 2 {
+			if gtidSet != nil && strings.HasSuffix(*gtidSet, ",") && line != "" {
+				*gtidSet += line
+			}
 			continue
 		}
+		gtidSet = nil
 		key := strings.TrimSpace(parts[0])
 		val := strings.TrimSpace(parts[1])
 		switch key {
@@ -452,8 +479,10 @@ func parseReplicaStatus(output string) *ReplicationStatus {
 			status.LastError = val
 		case "Executed_Gtid_Set":
 			status.ExecutedGTIDSet = val
+			gtidSet = &status.ExecutedGTIDSet
 		case "Retrieved_Gtid_Set":
 			status.RetrievedGTIDSet = val
+			gtidSet = &status.RetrievedGTIDSet
 		}
 	}
 	return status
@@ -505,6 +534,36 @@ func (m *DatabaseManager) CreateTempUser(ctx context.Context, dbName, username,
 	return m.execMySQL(ctx, "FLUSH PRIVILEGES")
 }
 
+// DropTempUser drops a temporary user created by CreateTempUser and kills
+// its open connections, cutting off DB Admin access before the user would
+// expire on its own. Only tmp_ users can be dropped this way.
+func (m *DatabaseManager) DropTempUser(ctx context.Context, username string) error {
+	if err := validateName(username); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(username, "tmp_") {
+		return status.Errorf(codes.InvalidArgument, "%s is not a temporary user", username)
+	}
+
+	m.logger.Info().Str("username", username).Msg("dropping temporary database user")
+
+	// Drop first so no new connection can be opened, then kill the open ones.
+	if err := m.execMySQL(ctx, fmt.Sprintf("DROP USER IF EXISTS '%s'@'%%'", username)); err != nil {
+		return err
+	}
+	procs, err := m.ListProcesses(ctx, []string{username})
+	if err != nil {
+		return err
+	}
+	for _, p := range procs {
+		// A connection may close between listing and killing it.
+		if err := m.execMySQL(ctx, fmt.Sprintf("KILL %d", p.ID)); err != nil {
+			m.logger.Warn().Err(err).Int64("connection", p.ID).Msg("kill temp user connection failed")
+		}
+	}
+	return nil
+}
+
 // DeleteUser drops a MySQL user.
 func (m *DatabaseManager) DeleteUser(ctx context.Context, dbName, username string) error {
 	if err := validateName(username); err != nil {
@@ -519,3 +578,101 @@ func (m *DatabaseManager) DeleteUser(ctx context.Context, dbName, username strin
 	sql := fmt.Sprintf("DROP USER IF EXISTS '%s'@'%%'", username)
 	return m.execMySQL(ctx, sql)
 }
+
+// maxProcessQueryLen caps the query text returned per connection.
+const maxProcessQueryLen = 1024
+
+// ListProcesses returns the connections of the given MySQL users from
+// SHOW FULL PROCESSLIST. Connections of any other user are never returned.
+func (m *DatabaseManager) ListProcesses(ctx context.Context, users []string) ([]model.DatabaseConnection, error) {
+	for _, u := range users {
+		if err := validateName(u); err != nil {
+			return nil, err
+		}
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+
+	baseArgs, err := m.mysqlArgs()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "parse mysql DSN: %v", err)
+	}
+	// Batch mode without headers: one tab-separated row per connection, with
+	// tabs and newlines inside values escaped.
+	args := append(baseArgs, "-B", "-N", "-e", "SHOW FULL PROCESSLIST")
+	cmd := cmdaudit.CommandContext(ctx, "mysql", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "show processlist: %v", err)
+	}
+	return parseProcessList(string(output), users), nil
+}
+
+// KillProcess kills a connection, but only after checking that it belongs to
+// one of the given users. This keeps tenants on a shared MySQL node from
+// killing each other's (or the platform's) connections.
+func (m *DatabaseManager) KillProcess(ctx context.Context, id int64, users []string) error {
+	procs, err := m.ListProcesses(ctx, users)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, p := range procs {
+		if p.ID == id {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return status.Errorf(codes.NotFound, "connection %d not found", id)
+	}
+
+	m.logger.Info().Int64("connection", id).Msg("killing database connection")
+	return m.execMySQL(ctx, fmt.Sprintf("KILL %d", id))
+}
+
+// parseProcessList parses batch-mode SHOW FULL PROCESSLIST output
+// (Id, User, Host, db, Command, Time, State, Info) and keeps the rows of the
+// given users. NULL values become empty strings and Info is truncated.
+func parseProcessList(output string, users []string) []model.DatabaseConnection {
+	allowed := make(map[string]bool, len(users))
+	for _, u := range users {
+		allowed[u] = true
+	}
+
+	null := func(s string) string {
+		if s == "NULL" {
+			return ""
+		}
+		return s
+	}
+
+	var conns []model.DatabaseConnection
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 8 || !allowed[fields[1]] {
+			continue
+		}
+		id, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			continue
+		}
+		secs, _ := strconv.ParseInt(fields[5], 10, 64)
+		query := null(fields[7])
+		if len(query) > maxProcessQueryLen {
+			query = strings.ToValidUTF8(query[:maxProcessQueryLen], "") + "..."
+		}
+		conns = append(conns, model.DatabaseConnection{
+			ID:          id,
+			User:        fields[1],
+			Host:        fields[2],
+			Database:    null(fields[3]),
+			Command:     fields[4],
+			TimeSeconds: secs,
+			State:       null(fields[6]),
+			Query:       query,
+		})
+	}
+	return conns
+}
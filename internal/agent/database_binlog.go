@@ -0,0 +1,172 @@
+package agent
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+)
+
+// mysqlBinlogDir is where the MySQL role keeps its binary logs (log_bin).
+const mysqlBinlogDir = "/var/lib/mysql"
+
+// dumpHeaderLines is how many lines of a dump are searched for the binlog
+// position. mysqldump writes it before the first table.
+const dumpHeaderLines = 50
+
+// dumpBinlogPosRe matches the binlog position mysqldump --source-data=2
+// writes as a comment (CHANGE MASTER TO on MySQL before 8.0.23).
+var dumpBinlogPosRe = regexp.MustCompile(`CHANGE (?:MASTER|REPLICATION SOURCE) TO (?:MASTER|SOURCE)_LOG_FILE='([^']+)', (?:MASTER|SOURCE)_LOG_POS=(\d+)`)
+
+// CheckBinlogs checks that the binlogs needed to roll a database dump
+// forward are still on this node. It fails with FailedPrecondition if the
+// dump has no binlog position or its binlog has been purged.
+func (m *DatabaseManager) CheckBinlogs(ctx context.Context, dumpPath string) error {
+	_, _, err := m.binlogsSince(ctx, dumpPath)
+	return err
+}
+
+// ReplayBinlogs replays the binlog events of a database from the position
+// recorded in a dump up to (excluding) stopAt, rolling a restored dump
+// forward to a point in time. Replaying is not idempotent, so it must run
+// exactly once after the dump was imported.
+func (m *DatabaseManager) ReplayBinlogs(ctx context.Context, name, dumpPath string, stopAt time.Time) error {
+	if err := validateName(name); err != nil {
+		return err
+	}
+
+	files, pos, err := m.binlogsSince(ctx, dumpPath)
+	if err != nil {
+		return err
+	}
+
+	m.logger.Info().Str("database", name).Str("path", dumpPath).Strs("binlogs", files).
+		Int64("start_position", pos).Time("stop_at", stopAt).Msg("replaying binlogs")
+
+	baseArgs, err := m.mysqlArgs()
+	if err != nil {
+		return status.Errorf(codes.Internal, "parse mysql DSN: %v", err)
+	}
+
+	// GTIDs are skipped: the server has already executed the original
+	// transactions and would silently ignore them. Row events are filtered
+	// by the database of the changed table. --start-position applies to the
+	// first file only.
+	replayArgs := []string{
+		"--skip-gtids",
+		"--database=" + name,
+		"--start-position=" + strconv.FormatInt(pos, 10),
+		"--stop-datetime=" + stopAt.Local().Format(time.DateTime),
+	}
+	for _, f := range files {
+		replayArgs = append(replayArgs, filepath.Join(mysqlBinlogDir, f))
+	}
+	importArgs := append(baseArgs, name)
+	shell := fmt.Sprintf("set -o pipefail; mysqlbinlog %s | mysql %s",
+		strings.Join(quoteArgs(replayArgs), " "), strings.Join(quoteArgs(importArgs), " "))
+	cmd := cmdaudit.CommandContext(ctx, "bash", "-c", shell)
+	m.logger.Debug().Strs("cmd", cmdaudit.Redact(cmd.Args)).Msg("executing binlog replay")
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return status.Errorf(codes.Internal, "binlog replay failed: %s: %v", string(output), err)
+	}
+
+	return nil
+}
+
+// binlogsSince returns the binlogs on this node from the one holding the
+// dump's binlog position onwards, and the position within the first.
+func (m *DatabaseManager) binlogsSince(ctx context.Context, dumpPath string) ([]string, int64, error) {
+	f, err := os.Open(dumpPath)
+	if err != nil {
+		return nil, 0, status.Errorf(codes.NotFound, "open dump: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, 0, status.Errorf(codes.InvalidArgument, "read dump %s: %v", dumpPath, err)
+	}
+	defer gz.Close()
+	file, pos, ok := parseDumpBinlogPosition(gz)
+	if !ok {
+		return nil, 0, status.Errorf(codes.FailedPrecondition,
+			"backup %s has no binlog position; only backups taken with binlog positions can be restored to a point in time", dumpPath)
+	}
+
+	baseArgs, err := m.mysqlArgs()
+	if err != nil {
+		return nil, 0, status.Errorf(codes.Internal, "parse mysql DSN: %v", err)
+	}
+	args := append(baseArgs, "-B", "-N", "-e", "SHOW BINARY LOGS")
+	cmd := cmdaudit.CommandContext(ctx, "mysql", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, 0, status.Errorf(codes.Internal, "show binary logs: %v", err)
+	}
+
+	files, err := binlogsFrom(parseBinaryLogs(string(output)), file)
+	if err != nil {
+		return nil, 0, err
+	}
+	return files, pos, nil
+}
+
+// parseDumpBinlogPosition returns the binlog file and position recorded in
+// the header of an uncompressed mysqldump.
+func parseDumpBinlogPosition(r io.Reader) (string, int64, bool) {
+	br := bufio.NewReader(r)
+	for i := 0; i < dumpHeaderLines; i++ {
+		line, err := br.ReadString('\n')
+		if m := dumpBinlogPosRe.FindStringSubmatch(line); m != nil {
+			pos, perr := strconv.ParseInt(m[2], 10, 64)
+			if perr != nil {
+				return "", 0, false
+			}
+			return m[1], pos, true
+		}
+		if err != nil {
+			break
+		}
+	}
+	return "", 0, false
+}
+
+// parseBinaryLogs returns the file names of batch-mode SHOW BINARY LOGS
+// output (Log_name, File_size, Encrypted), oldest first.
+func parseBinaryLogs(output string) []string {
+	var files []string
+	for _, line := range strings.Split(output, "\n") {
+		if name, _, _ := strings.Cut(line, "\t"); strings.TrimSpace(name) != "" {
+			files = append(files, strings.TrimSpace(name))
+		}
+	}
+	return files
+}
+
+// binlogsFrom returns the binlogs from first onwards. It fails with
+// FailedPrecondition if first has been purged.
+func binlogsFrom(files []string, first string) ([]string, error) {
+	for i, f := range files {
+		if f == first {
+			return files[i:], nil
+		}
+	}
+	oldest := "none"
+	if len(files) > 0 {
+		oldest = files[0]
+	}
+	return nil, status.Errorf(codes.FailedPrecondition,
+		"binlogs are not retained far enough back: the backup starts in %s, but the oldest binlog on this node is %s", first, oldest)
+}
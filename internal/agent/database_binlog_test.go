@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestParseDumpBinlogPosition(t *testing.T) {
+	dump := `-- MySQL dump 10.13  Distrib 8.0.36, for Linux (x86_64)
+--
+-- Host: localhost    Database: shop
+-- ------------------------------------------------------
+/*!40101 SET @OLD_CHARACTER_SET_CLIENT=@@CHARACTER_SET_CLIENT */;
+
+--
+-- Position to start replication or point-in-time recovery from
+--
+
+-- CHANGE REPLICATION SOURCE TO SOURCE_LOG_FILE='binlog.000012', SOURCE_LOG_POS=4711;
+
+DROP TABLE IF EXISTS ` + "`orders`" + `;
+`
+	file, pos, ok := parseDumpBinlogPosition(strings.NewReader(dump))
+	require.True(t, ok)
+	assert.Equal(t, "binlog.000012", file)
+	assert.Equal(t, int64(4711), pos)
+}
+
+func TestParseDumpBinlogPosition_ChangeMaster(t *testing.T) {
+	file, pos, ok := parseDumpBinlogPosition(strings.NewReader("-- CHANGE MASTER TO MASTER_LOG_FILE='binlog.000003', MASTER_LOG_POS=157;\n"))
+	require.True(t, ok)
+	assert.Equal(t, "binlog.000003", file)
+	assert.Equal(t, int64(157), pos)
+}
+
+func TestParseDumpBinlogPosition_Missing(t *testing.T) {
+	dump := "-- MySQL dump 10.13\n" + strings.Repeat("INSERT INTO t VALUES (1);\n", dumpHeaderLines) +
+		"-- CHANGE REPLICATION SOURCE TO SOURCE_LOG_FILE='binlog.000012', SOURCE_LOG_POS=4711;\n"
+	_, _, ok := parseDumpBinlogPosition(strings.NewReader(dump))
+	assert.False(t, ok)
+}
+
+func TestParseBinaryLogs(t *testing.T) {
+	output := "binlog.000011\t1073741950\tNo\nbinlog.000012\t52133\tNo\n"
+	assert.Equal(t, []string{"binlog.000011", "binlog.000012"}, parseBinaryLogs(output))
+}
+
+func TestBinlogsFrom(t *testing.T) {
+	files := []string{"binlog.000011", "binlog.000012", "binlog.000013"}
+
+	got, err := binlogsFrom(files, "binlog.000012")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"binlog.000012", "binlog.000013"}, got)
+
+	_, err = binlogsFrom(files, "binlog.000009")
+	require.Error(t, err)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Contains(t, err.Error(), "oldest binlog on this node is binlog.000011")
+}
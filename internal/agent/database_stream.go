@@ -0,0 +1,142 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/crypto"
+)
+
+// streamProgressInterval is how many bytes StreamDatabase copies between
+// progress callbacks.
+const streamProgressInterval = 16 << 20
+
+// migrationUsername is the temporary MySQL user a target node uses to dump
+// dbName from the source node during a streaming migration.
+func migrationUsername(dbName string) string {
+	sum := sha256.Sum256([]byte(dbName))
+	return "migrate_" + hex.EncodeToString(sum[:8])
+}
+
+// migrationPassword derives the migration user's password from the
+// replication password, which every database node has locally. Source and
+// target derive the same password, so it never passes through a workflow.
+func (m *DatabaseManager) migrationPassword(dbName string) (string, error) {
+	if m.replPassword == "" {
+		return "", fmt.Errorf("MYSQL_REPL_PASSWORD not configured on this node")
+	}
+	mac := hmac.New(sha256.New, []byte(m.replPassword))
+	mac.Write([]byte("migrate:" + dbName))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// GrantMigrationAccess creates the temporary user a target node connects as
+// to stream dbName from this node. Like other temporary users it expires
+// after 2 hours if RevokeMigrationAccess is never called.
+func (m *DatabaseManager) GrantMigrationAccess(ctx context.Context, dbName string) error {
+	password, err := m.migrationPassword(dbName)
+	if err != nil {
+		return err
+	}
+	return m.CreateTempUser(ctx, dbName, migrationUsername(dbName), crypto.MysqlNativePasswordHash(password))
+}
+
+// RevokeMigrationAccess drops the temporary migration user for dbName.
+func (m *DatabaseManager) RevokeMigrationAccess(ctx context.Context, dbName string) error {
+	return m.DeleteUser(ctx, dbName, migrationUsername(dbName))
+}
+
+// StreamDatabase pipes mysqldump of dbName on sourceHost straight into the
+// local database of the same name, without an intermediate dump file. The
+// source must have granted migration access. progress is called with the
+// number of bytes copied so far every streamProgressInterval bytes. It
+// returns the total number of bytes copied, also on failure.
+func (m *DatabaseManager) StreamDatabase(ctx context.Context, dbName, sourceHost string, progress func(int64)) (int64, error) {
+	if err := validateName(dbName); err != nil {
+		return 0, err
+	}
+	password, err := m.migrationPassword(dbName)
+	if err != nil {
+		return 0, err
+	}
+	baseArgs, err := m.mysqlArgs()
+	if err != nil {
+		return 0, status.Errorf(codes.Internal, "parse mysql DSN: %v", err)
+	}
+
+	m.logger.Info().Str("database", dbName).Str("source", sourceHost).Msg("streaming database")
+
+	// Either side failing cancels the other.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	counter := &countingWriter{w: pw, progress: progress}
+
+	var dumpStderr bytes.Buffer
+	dump := cmdaudit.CommandContext(ctx, "mysqldump",
+		"-h", sourceHost, "-P", "3306", "-u", migrationUsername(dbName), "-p"+password,
+		"--compress", "--single-transaction", "--routines", "--triggers", dbName)
+	dump.Stdout = counter
+	dump.Stderr = &dumpStderr
+
+	var importOutput bytes.Buffer
+	imp := cmdaudit.CommandContext(ctx, "mysql", append(baseArgs, dbName)...)
+	imp.Stdin = pr
+	imp.Stdout = &importOutput
+	imp.Stderr = &importOutput
+
+	importDone := make(chan error, 1)
+	go func() {
+		err := imp.Run()
+		// Unblock the dump if the import stopped reading early.
+		pr.CloseWithError(io.ErrClosedPipe)
+		if err != nil {
+			cancel()
+		}
+		importDone <- err
+	}()
+
+	dumpErr := dump.Run()
+	pw.CloseWithError(dumpErr)
+	if dumpErr != nil {
+		cancel()
+	}
+	importErr := <-importDone
+
+	// A failed import kills the dump, so report it first.
+	if importErr != nil {
+		return counter.n, status.Errorf(codes.Internal, "mysql import failed after %d bytes: %s: %v", counter.n, importOutput.String(), importErr)
+	}
+	if dumpErr != nil {
+		return counter.n, status.Errorf(codes.Internal, "mysqldump from %s failed after %d bytes: %s: %v", sourceHost, counter.n, dumpStderr.String(), dumpErr)
+	}
+	return counter.n, nil
+}
+
+// countingWriter counts the bytes written through it and reports progress.
+type countingWriter struct {
+	w        io.Writer
+	n        int64
+	reported int64
+	progress func(int64)
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	if c.progress != nil && c.n-c.reported >= streamProgressInterval {
+		c.reported = c.n
+		c.progress(c.n)
+	}
+	return n, err
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationUsername(t *testing.T) {
+	name := migrationUsername("db_abc123")
+	assert.NoError(t, validateName(name))
+	assert.LessOrEqual(t, len(name), 32)
+	assert.Equal(t, name, migrationUsername("db_abc123"))
+	assert.NotEqual(t, name, migrationUsername("db_abc124"))
+}
+
+func TestMigrationPassword(t *testing.T) {
+	source := NewDatabaseManager(zerolog.Nop(), Config{MySQLReplPassword: "repl-secret"})
+	target := NewDatabaseManager(zerolog.Nop(), Config{MySQLReplPassword: "repl-secret"})
+
+	a, err := source.migrationPassword("db_abc123")
+	require.NoError(t, err)
+	b, err := target.migrationPassword("db_abc123")
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+
+	other, err := source.migrationPassword("db_other")
+	require.NoError(t, err)
+	assert.NotEqual(t, a, other)
+
+	_, err = NewDatabaseManager(zerolog.Nop(), Config{}).migrationPassword("db_abc123")
+	assert.ErrorContains(t, err, "MYSQL_REPL_PASSWORD")
+}
+
+func TestStreamDatabase_RequiresReplPassword(t *testing.T) {
+	mgr := NewDatabaseManager(zerolog.Nop(), Config{MySQLDSN: "root:pw@tcp(127.0.0.1:3306)/hosting"})
+	_, err := mgr.StreamDatabase(context.Background(), "db_abc123", "10.0.0.5", nil)
+	assert.ErrorContains(t, err, "MYSQL_REPL_PASSWORD")
+}
+
+func TestCountingWriter(t *testing.T) {
+	var buf bytes.Buffer
+	var reports []int64
+	w := &countingWriter{w: &buf, progress: func(n int64) { reports = append(reports, n) }}
+
+	chunk := make([]byte, streamProgressInterval/2)
+	for i := 0; i < 5; i++ {
+		_, err := w.Write(chunk)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(len(chunk)*5), w.n)
+	assert.Equal(t, []int64{streamProgressInterval, 2 * streamProgressInterval}, reports)
+}
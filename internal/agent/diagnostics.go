@@ -0,0 +1,175 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// diagnosticTimeout bounds each diagnostic so a hung command cannot use up
+// the time budget of the checks after it.
+const diagnosticTimeout = 10 * time.Second
+
+// Diagnostic is a single named node self-test. Run returns a short detail
+// string on success and an error describing the problem on failure.
+type Diagnostic struct {
+	Name string
+	Run  func(ctx context.Context) (string, error)
+}
+
+// RunDiagnostics runs every diagnostic in order and collects the results.
+// Checks are independent: a failure, timeout or panic in one is recorded and
+// the rest still run.
+func RunDiagnostics(ctx context.Context, diags []Diagnostic) *model.NodeDiagnostics {
+	report := &model.NodeDiagnostics{
+		Healthy:   true,
+		CheckedAt: time.Now().UTC(),
+		Checks:    make([]model.NodeDiagnosticCheck, 0, len(diags)),
+	}
+	for _, d := range diags {
+		result := runDiagnostic(ctx, d)
+		if !result.OK {
+			report.Healthy = false
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report
+}
+
+func runDiagnostic(ctx context.Context, d Diagnostic) (result model.NodeDiagnosticCheck) {
+	result.Name = d.Name
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result.OK = false
+			result.Error = fmt.Sprintf("panic: %v", r)
+		}
+		result.DurationMS = time.Since(start).Milliseconds()
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, diagnosticTimeout)
+	defer cancel()
+
+	detail, err := d.Run(ctx)
+	result.Detail = detail
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.OK = true
+	return result
+}
+
+// CheckMountDiagnostic checks that path is a CephFS mount.
+func CheckMountDiagnostic(path string) Diagnostic {
+	return Diagnostic{
+		Name: "cephfs_mount",
+		Run: func(ctx context.Context) (string, error) {
+			if err := CheckMount(path); err != nil {
+				return "", err
+			}
+			return path, nil
+		},
+	}
+}
+
+// Diagnostic checks that the nginx binary is installed and that the current
+// configuration passes nginx -t.
+func (m *NginxManager) Diagnostic() Diagnostic {
+	return Diagnostic{
+		Name: "nginx",
+		Run: func(ctx context.Context) (string, error) {
+			bin, err := exec.LookPath("nginx")
+			if err != nil {
+				return "", fmt.Errorf("nginx binary not found: %w", err)
+			}
+			// Reload creates missing log dirs before testing; do the same so
+			// the result matches what the next reload would see.
+			m.ensureLogDirs()
+			output, err := cmdaudit.CommandContext(ctx, bin, "-t").CombinedOutput()
+			if err != nil {
+				return "", fmt.Errorf("nginx -t failed: %s: %w", strings.TrimSpace(string(output)), err)
+			}
+			return bin, nil
+		},
+	}
+}
+
+// Diagnostic checks that the local MySQL server accepts connections with the
+// agent's credentials.
+func (m *DatabaseManager) Diagnostic() Diagnostic {
+	return Diagnostic{
+		Name: "mysql",
+		Run: func(ctx context.Context) (string, error) {
+			if err := m.execMySQL(ctx, "SELECT 1"); err != nil {
+				return "", err
+			}
+			return "connected", nil
+		},
+	}
+}
+
+// Diagnostic checks that the Valkey config directory exists and is writable
+// by creating and removing a temporary file in it.
+func (m *ValkeyManager) Diagnostic() Diagnostic {
+	return Diagnostic{
+		Name: "valkey_config_dir",
+		Run: func(ctx context.Context) (string, error) {
+			f, err := os.CreateTemp(m.configDir, ".diagnostics-*")
+			if err != nil {
+				return "", fmt.Errorf("config dir %s not writable: %w", m.configDir, err)
+			}
+			name := f.Name()
+			f.Close()
+			if err := os.Remove(name); err != nil {
+				return "", fmt.Errorf("remove %s: %w", name, err)
+			}
+			return m.configDir, nil
+		},
+	}
+}
+
+// Diagnostic checks that supervisord is reachable and that no program it
+// manages is in a failed state (FATAL or BACKOFF). Stopped programs are not
+// failures; daemons are stopped on purpose.
+func (m *DaemonManager) Diagnostic() Diagnostic {
+	return Diagnostic{
+		Name: "supervisor",
+		Run: func(ctx context.Context) (string, error) {
+			if err := m.supervisorctl(ctx, "pid"); err != nil {
+				return "", fmt.Errorf("supervisord not reachable: %w", err)
+			}
+			// status exits non-zero whenever a program is not running, so its
+			// output is inspected instead of its exit code.
+			output, _ := cmdaudit.CommandContext(ctx, "supervisorctl", "status").Output()
+			total, failed := parseSupervisorStatus(string(output))
+			if len(failed) > 0 {
+				return "", fmt.Errorf("%d of %d programs failed: %s", len(failed), total, strings.Join(failed, ", "))
+			}
+			return fmt.Sprintf("%d programs", total), nil
+		},
+	}
+}
+
+// parseSupervisorStatus parses supervisorctl status output and returns the
+// number of programs and the names of those in FATAL or BACKOFF state.
+func parseSupervisorStatus(output string) (total int, failed []string) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		total++
+		switch fields[1] {
+		case "FATAL", "BACKOFF":
+			failed = append(failed, fields[0])
+		}
+	}
+	return total, failed
+}
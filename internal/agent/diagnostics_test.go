@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDiagnostics_AllPass(t *testing.T) {
+	report := RunDiagnostics(context.Background(), []Diagnostic{
+		{Name: "a", Run: func(context.Context) (string, error) { return "fine", nil }},
+		{Name: "b", Run: func(context.Context) (string, error) { return "", nil }},
+	})
+
+	assert.True(t, report.Healthy)
+	require.Len(t, report.Checks, 2)
+	assert.Equal(t, "a", report.Checks[0].Name)
+	assert.True(t, report.Checks[0].OK)
+	assert.Equal(t, "fine", report.Checks[0].Detail)
+}
+
+func TestRunDiagnostics_FailureDoesNotShortCircuit(t *testing.T) {
+	ran := false
+	report := RunDiagnostics(context.Background(), []Diagnostic{
+		{Name: "broken", Run: func(context.Context) (string, error) { return "", fmt.Errorf("boom") }},
+		{Name: "panics", Run: func(context.Context) (string, error) { panic("nil manager") }},
+		{Name: "after", Run: func(context.Context) (string, error) { ran = true; return "", nil }},
+	})
+
+	assert.False(t, report.Healthy)
+	require.Len(t, report.Checks, 3)
+	assert.False(t, report.Checks[0].OK)
+	assert.Equal(t, "boom", report.Checks[0].Error)
+	assert.False(t, report.Checks[1].OK)
+	assert.Contains(t, report.Checks[1].Error, "panic: nil manager")
+	assert.True(t, ran)
+	assert.True(t, report.Checks[2].OK)
+}
+
+func TestValkeyDiagnostic(t *testing.T) {
+	m := &ValkeyManager{configDir: t.TempDir()}
+	result := runDiagnostic(context.Background(), m.Diagnostic())
+	assert.True(t, result.OK, result.Error)
+
+	m = &ValkeyManager{configDir: "/nonexistent/valkey"}
+	result = runDiagnostic(context.Background(), m.Diagnostic())
+	assert.False(t, result.OK)
+	assert.Contains(t, result.Error, "not writable")
+}
+
+func TestParseSupervisorStatus(t *testing.T) {
+	output := `daemon-t1-worker:daemon-t1-worker_00   RUNNING   pid 123, uptime 1:02:03
+daemon-t1-worker:daemon-t1-worker_01   FATAL     Exited too quickly (process log may have details)
+daemon-t2-queue                        STOPPED   Oct 15 12:00 PM
+daemon-t3-sync                         BACKOFF   Exited too quickly (process log may have details)
+`
+	total, failed := parseSupervisorStatus(output)
+	assert.Equal(t, 4, total)
+	assert.Equal(t, []string{"daemon-t1-worker:daemon-t1-worker_01", "daemon-t3-sync"}, failed)
+}
+
+func TestParseSupervisorStatus_Empty(t *testing.T) {
+	total, failed := parseSupervisorStatus("")
+	assert.Equal(t, 0, total)
+	assert.Empty(t, failed)
+}
@@ -1,6 +1,10 @@
 package agent
 
-import "github.com/rs/zerolog"
+import (
+	"github.com/rs/zerolog"
+
+	"github.com/edvin/hosting/internal/logging"
+)
 
 // DNSManager handles local DNS operations on the node.
 // In v1, DNS is primarily managed through PowerDNS DB writes in Temporal activities.
@@ -12,6 +16,6 @@ type DNSManager struct {
 // NewDNSManager creates a new DNSManager.
 func NewDNSManager(logger zerolog.Logger) *DNSManager {
 	return &DNSManager{
-		logger: logger.With().Str("component", "dns-manager").Logger(),
+		logger: logging.Component(logger, "dns-manager"),
 	}
 }
@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/agent/runtime"
+)
+
+// defaultGeoIPDatabase is where geoipupdate installs the GeoLite2 country
+// database on Debian.
+const defaultGeoIPDatabase = "/var/lib/GeoIP/GeoLite2-Country.mmdb"
+
+// geoIPConfigName is the conf.d file defining $geoip2_country_code, which
+// the geo-blocking maps of webroot configs read.
+const geoIPConfigName = "hosting-geoip2.conf"
+
+// EnsureGeoIP prepares nginx for a webroot with geo-blocking: it checks that
+// the geoip2 module is enabled and the country database is present, and
+// writes the http-level geoip2 config. Without them the webroot's config
+// would fail nginx -t, so this returns a FailedPrecondition error naming
+// what is missing instead. Webroots without geo-blocking need nothing.
+func (m *NginxManager) EnsureGeoIP(webroot *runtime.WebrootInfo) error {
+	if !webroot.GeoBlocking.Enabled() {
+		return nil
+	}
+
+	modules, _ := filepath.Glob(filepath.Join(m.configDir, "modules-enabled", "*geoip2*.conf"))
+	if len(modules) == 0 {
+		return status.Errorf(codes.FailedPrecondition,
+			"webroot %s uses geo-blocking, which requires the nginx geoip2 module on this node: no geoip2 module in %s (install libnginx-mod-http-geoip2)",
+			webroot.Name, filepath.Join(m.configDir, "modules-enabled"))
+	}
+	if info, err := os.Stat(m.geoIPDatabase); err != nil || info.Size() == 0 {
+		return status.Errorf(codes.FailedPrecondition,
+			"webroot %s uses geo-blocking, which requires a GeoIP2 country database on this node: %s is missing or empty (run geoipupdate)",
+			webroot.Name, m.geoIPDatabase)
+	}
+
+	config := fmt.Sprintf(`# Auto-generated by node-agent for webroot geo-blocking
+# DO NOT EDIT MANUALLY
+geoip2 %s {
+    auto_reload 24h;
+    $geoip2_country_code country iso_code;
+}
+`, m.geoIPDatabase)
+
+	path := filepath.Join(m.configDir, "conf.d", geoIPConfigName)
+	if current, err := os.ReadFile(path); err == nil && string(current) == config {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return status.Errorf(codes.Internal, "mkdir %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		return status.Errorf(codes.Internal, "write %s: %v", path, err)
+	}
+	m.logger.Info().Str("path", path).Str("database", m.geoIPDatabase).Msg("wrote geoip2 config")
+	return nil
+}
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/agent/runtime"
+	"github.com/edvin/hosting/internal/model"
+)
+
+func TestEnsureGeoIP(t *testing.T) {
+	mgr := newTestNginxManager(t)
+	mgr.geoIPDatabase = filepath.Join(t.TempDir(), "GeoLite2-Country.mmdb")
+	webroot := &runtime.WebrootInfo{
+		Name:        "mysite",
+		GeoBlocking: model.WebrootGeoBlocking{Mode: model.GeoBlockingDeny, Countries: []string{"KP"}},
+	}
+	confPath := filepath.Join(mgr.configDir, "conf.d", geoIPConfigName)
+
+	// Webroots without geo-blocking need neither module nor database.
+	require.NoError(t, mgr.EnsureGeoIP(&runtime.WebrootInfo{Name: "other"}))
+	assert.NoFileExists(t, confPath)
+
+	err := mgr.EnsureGeoIP(webroot)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.ErrorContains(t, err, "libnginx-mod-http-geoip2")
+
+	require.NoError(t, os.MkdirAll(filepath.Join(mgr.configDir, "modules-enabled"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(mgr.configDir, "modules-enabled", "50-mod-http-geoip2.conf"), []byte("load_module x;\n"), 0644))
+	err = mgr.EnsureGeoIP(webroot)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.ErrorContains(t, err, "geoipupdate")
+
+	require.NoError(t, os.WriteFile(mgr.geoIPDatabase, []byte("mmdb"), 0644))
+	require.NoError(t, mgr.EnsureGeoIP(webroot))
+	config, err := os.ReadFile(confPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(config), "geoip2 "+mgr.geoIPDatabase+" {")
+	assert.Contains(t, string(config), "$geoip2_country_code country iso_code;")
+}
@@ -4,56 +4,231 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
 	"os"
-	"os/exec"
+	"os/user"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
 	"github.com/edvin/hosting/internal/agent/runtime"
+	"github.com/edvin/hosting/internal/logging"
+	"github.com/edvin/hosting/internal/model"
 )
 
 const nginxServerBlockTemplate = `# Auto-generated by node-agent for {{ .TenantName }}/{{ .WebrootName }}
 # DO NOT EDIT MANUALLY
+{{- if .LimitConn }}
+limit_conn_zone $binary_remote_addr zone=conn_{{ .WebrootID }}:{{ .LimitZoneKB }}k;
+{{- end }}
+{{- if .LimitRate }}
+limit_req_zone $binary_remote_addr zone=req_{{ .WebrootID }}:{{ .LimitZoneKB }}k rate={{ .LimitRate }}r/s;
+{{- end }}
+{{- if .CacheRules }}
+
+# Cache rules, first match wins.
+map $uri $cache_control_{{ .WebrootVar }} {
+    default "";
+{{- range .CacheRules }}
+    {{ .Match }} "{{ .CacheControl }}";
+{{- end }}
+}
+map $uri $expires_{{ .WebrootVar }} {
+    default off;
+{{- range .CacheRules }}
+    {{ .Match }} {{ .Expires }};
+{{- end }}
+}
+{{- end }}
+{{- if .GeoCountries }}
+
+# Geo-blocking: 1 blocks the client with 403. Unknown countries and ACME
+# challenges are never blocked.
+map $geoip2_country_code $geo_country_block_{{ .WebrootVar }} {
+    default {{ .GeoDefault }};
+    "" 0;
+{{- range .GeoCountries }}
+    {{ . }} {{ $.GeoListed }};
+{{- end }}
+}
+map $uri $geo_block_{{ .WebrootVar }} {
+    default $geo_country_block_{{ .WebrootVar }};
+    ~^/\.well-known/acme-challenge/ 0;
+}
+{{- end }}
+{{- if .DeployLockRetryAfter }}
+
+# Deploy lock: 1 answers 503 until the lock is cleared. ACME challenges and
+# the custom 503 page are never held off; volatile so the error_page
+# redirect to the latter is looked up again.
+map $uri $deploy_lock_{{ .WebrootVar }} {
+    volatile;
+    default 1;
+    ~^/\.well-known/acme-challenge/ 0;
+{{- range .ErrorPages }}{{ if eq .Code 503 }}
+    "{{ .URI }}" 0;
+{{- end }}{{ end }}
+}
+map $status $deploy_lock_retry_{{ .WebrootVar }} {
+    default "";
+    503 {{ .DeployLockRetryAfter }};
+}
+{{- end }}
+{{ range .Redirects }}
+server {
+    listen {{ $.ListenPort }}{{ $.ListenOpts }};
+    listen [::]:{{ $.ListenPort }}{{ $.ListenOpts }};
+    server_name {{ .Name }};
+{{- template "realip" $ }}
+    root {{ $.DocumentRoot }};
+
+    # ACME HTTP-01 challenges must stay reachable over plain HTTP.
+    location ^~ /.well-known/acme-challenge/ {
+        try_files $uri =404;
+    }
 
-{{ if .HasSSL -}}
+    location / {
+        return {{ .Code }} {{ .HTTPTarget }};
+    }
+}
+{{ if .SSLCertPath }}
 server {
-    listen {{ .ListenPort }};
-    listen [::]:{{ .ListenPort }};
-    server_name {{ .ServerNames }};
-    return 301 https://$host$request_uri;
+    listen 443 ssl{{ $.ListenOpts }};
+    listen [::]:443 ssl{{ $.ListenOpts }};
+
+    ssl_certificate     {{ .SSLCertPath }};
+    ssl_certificate_key {{ .SSLKeyPath }};
+    ssl_protocols       TLSv1.2 TLSv1.3;
+    ssl_ciphers         HIGH:!aNULL:!MD5;
+    ssl_prefer_server_ciphers on;
+
+    server_name {{ .Name }};
+{{- template "realip" $ }}
+
+    location / {
+        return {{ .Code }} {{ .HTTPSTarget }};
+    }
 }
 {{ end -}}
+{{ end -}}
+{{ if .RedirectNames }}
+server {
+    listen {{ .ListenPort }}{{ .ListenOpts }};
+    listen [::]:{{ .ListenPort }}{{ .ListenOpts }};
+    server_name {{ .RedirectNames }};
+{{- template "realip" . }}
+    root {{ .DocumentRoot }};
+
+    # ACME HTTP-01 challenges must stay reachable over plain HTTP.
+    location ^~ /.well-known/acme-challenge/ {
+        try_files $uri =404;
+    }
 
+    location / {
+        return 301 https://$host$request_uri;
+    }
+}
+{{ end -}}
+{{ if .HTTPNames }}
+server {
+    listen {{ .ListenPort }}{{ .ListenOpts }};
+    listen [::]:{{ .ListenPort }}{{ .ListenOpts }};
+
+    server_name {{ .HTTPNames }};
+{{- template "site" . -}}
+}
+{{ end -}}
+{{ if .HasSSL }}
 server {
-{{ if .HasSSL -}}
-    listen 443 ssl;
-    listen [::]:443 ssl;
+    listen 443 ssl{{ .ListenOpts }};
+    listen [::]:443 ssl{{ .ListenOpts }};
 
     ssl_certificate     {{ .SSLCertPath }};
     ssl_certificate_key {{ .SSLKeyPath }};
     ssl_protocols       TLSv1.2 TLSv1.3;
     ssl_ciphers         HIGH:!aNULL:!MD5;
     ssl_prefer_server_ciphers on;
-{{ else -}}
-    listen {{ .ListenPort }};
-    listen [::]:{{ .ListenPort }};
-{{ end -}}
 
     server_name {{ .ServerNames }};
+{{- template "site" . -}}
+}
+{{ end -}}
+{{ define "realip" }}
+{{- if .RealIPConfig }}
+    include {{ .RealIPConfig }};
+{{- end }}
+{{- end }}
+{{ define "site" }}
+{{- template "realip" . }}
     root {{ .DocumentRoot }};
     index index.html index.htm{{ if eq .Runtime "php" }} index.php{{ end }};
+{{- range .ErrorPages }}
+    error_page {{ .Code }} {{ .URI }};
+{{- end }}
 
     access_log /var/log/hosting/{{ .TenantID }}/{{ .WebrootID }}-access.log hosting_json;
+{{- if .AccessLogPath }}
+    access_log {{ .AccessLogPath }} hosting_json;
+{{- end }}
     error_log  /var/log/hosting/{{ .TenantID }}/{{ .WebrootID }}-error.log warn;
 
     # Node identification headers for load balancer debugging.
     add_header X-Served-By $hostname always;
     add_header X-Shard "{{ .ShardName }}" always;
+{{- if .CacheRules }}
+    add_header Cache-Control $cache_control_{{ .WebrootVar }};
+    expires $expires_{{ .WebrootVar }};
+{{- end }}
+{{- if .MimeTypes }}
+
+    include {{ .ConfigDir }}/mime.types;
+    types {
+{{- range .MimeTypes }}
+        {{ .Type }} {{ .Extension }};
+{{- end }}
+    }
+{{- end }}
+{{- if .GeoCountries }}
+
+    if ($geo_block_{{ .WebrootVar }}) {
+        return 403;
+    }
+{{- end }}
+{{- if .DeployLockRetryAfter }}
+
+    add_header Retry-After $deploy_lock_retry_{{ .WebrootVar }} always;
+    if ($deploy_lock_{{ .WebrootVar }}) {
+        return 503;
+    }
+{{- end }}
+{{ if .BasicAuthFile }}
+    auth_basic "Restricted";
+    auth_basic_user_file {{ .BasicAuthFile }};
+{{ end -}}
+{{ if .LimitConn }}
+    limit_conn conn_{{ .WebrootID }} {{ .LimitConn }};
+    limit_conn_status 429;
+{{ end -}}
+{{ if .LimitRate }}
+    limit_req zone=req_{{ .WebrootID }} burst={{ .LimitBurst }} nodelay;
+    limit_req_status 429;
+{{ end }}
+    location ^~ /.well-known/acme-challenge/ {
+{{- if .BasicAuthFile }}
+        # Certificate issuance must not need credentials.
+        auth_basic off;
+{{- end }}
+        try_files $uri =404;
+    }
 
     location / {
         try_files $uri $uri/ {{ .TryFilesTarget }};
@@ -76,7 +251,7 @@ server {
     location ~ \.php$ {
         include snippets/fastcgi-php.conf;
         fastcgi_pass unix:/run/php/{{ .TenantName }}-php{{ .RuntimeVersion }}.sock;
-        fastcgi_param SCRIPT_FILENAME $document_root$fastcgi_script_name;
+        fastcgi_param SCRIPT_FILENAME {{ if .Releases }}$realpath_root{{ else }}$document_root{{ end }}$fastcgi_script_name;
         include fastcgi_params;
     }
 
@@ -120,19 +295,30 @@ server {
         proxy_set_header X-Forwarded-Proto $scheme;
     }
 {{ end -}}
-}
+{{ end -}}
 `
 
 var nginxTmpl = template.Must(template.New("nginx").Parse(nginxServerBlockTemplate))
 
+// nginxGroup is the group nginx workers run as; htpasswd files are readable by
+// it and not by tenants.
+const nginxGroup = "www-data"
+
 // NginxManager generates, writes, and manages nginx configuration files.
 type NginxManager struct {
 	logger     zerolog.Logger
 	configDir  string
 	logDir     string
 	certDir    string
+	storageDir string
 	shardName  string
 	listenPort string // Port for listen directives (default "80")
+	hostname   string // Names this node's shared access log files
+	// geoIPDatabase is the GeoIP2 country database for geo-blocking.
+	geoIPDatabase string
+	// realIPFrom lists the LB addresses trusted to send the PROXY protocol
+	// header. Empty when the shard does not use PROXY protocol.
+	realIPFrom []string
 }
 
 // NewNginxManager creates a new NginxManager.
@@ -145,13 +331,31 @@ func NewNginxManager(logger zerolog.Logger, cfg Config) *NginxManager {
 	if listenPort == "" {
 		listenPort = "80"
 	}
-	return &NginxManager{
-		logger:     logger.With().Str("component", "nginx-manager").Logger(),
+	storageDir := cfg.WebStorageDir
+	if storageDir == "" {
+		storageDir = "/var/www/storage"
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "localhost"
+	}
+	geoIPDatabase := cfg.GeoIPDatabasePath
+	if geoIPDatabase == "" {
+		geoIPDatabase = defaultGeoIPDatabase
+	}
+	m := &NginxManager{
+		logger:     logging.Component(logger, "nginx-manager"),
 		configDir:  cfg.NginxConfigDir,
 		logDir:     logDir,
 		certDir:    cfg.CertDir,
+		storageDir: storageDir,
 		listenPort: listenPort,
+		hostname:   hostname,
+
+		geoIPDatabase: geoIPDatabase,
 	}
+	m.loadProxyProtocol()
+	return m
 }
 
 // SetShardName sets the shard name used in X-Shard response headers.
@@ -173,8 +377,11 @@ type nginxTemplateData struct {
 	WebrootName    string
 	WebrootID      string
 	ShardName      string
-	ServerNames    string
+	ServerNames    string // All names, served on 443 when HasSSL
+	RedirectNames  string // Names redirected from HTTP to HTTPS
+	HTTPNames      string // Names served over plain HTTP
 	DocumentRoot   string
+	Releases       bool // DocumentRoot goes through the current release symlink
 	Runtime        string
 	RuntimeVersion string
 	HasSSL         bool
@@ -183,24 +390,163 @@ type nginxTemplateData struct {
 	TryFilesTarget string
 	ProxyPort      uint32
 	ListenPort     string // HTTP listen port (default "80")
+	ListenOpts     string // extra listen parameters (" proxy_protocol")
+	RealIPConfig   string // realip include for PROXY protocol; empty when off
 	Daemons        []DaemonProxyInfo
+	ErrorPages     []nginxErrorPage
+	BasicAuthFile  string // htpasswd path; empty when the site is not protected
+	AccessLogPath  string // shared access log; empty unless access logs are enabled
+	LimitConn      int    // concurrent connections per client IP; 0 = unlimited
+	LimitRate      int    // requests per second per client IP; 0 = unlimited
+	LimitBurst     int
+	LimitZoneKB    int
+	Redirects      []nginxRedirect
+	WebrootVar     string // WebrootID usable in nginx variable names
+	CacheRules     []nginxCacheRule
+	MimeTypes      []nginxMimeType
+	ConfigDir      string
+	GeoCountries   []string // countries listed for geo-blocking; empty = no restriction
+	GeoDefault     int      // map value for unlisted countries: 1 blocks
+	GeoListed      int      // map value for listed countries
+	// DeployLockRetryAfter is the number of seconds left on the webroot's
+	// deploy lock, sent as Retry-After with the 503s; 0 when not locked.
+	DeployLockRetryAfter int
+}
+
+// nginxCacheRule is a cache rule as the map entries that select its
+// headers. A rule sets only one of CacheControl and Expires; the other is
+// the map default ("" or off), so the first matching rule decides both.
+type nginxCacheRule struct {
+	Match        string // nginx map regex
+	CacheControl string
+	Expires      string
+}
+
+type nginxMimeType struct {
+	Extension string
+	Type      string
+}
+
+// nginxRedirect is a redirecting FQDN. It gets its own server blocks, and
+// its own certificate, instead of sharing the webroot's.
+type nginxRedirect struct {
+	Name        string
+	Code        int
+	HTTPTarget  string // redirect URL on the plain HTTP port
+	HTTPSTarget string
+	SSLCertPath string // empty until the FQDN's certificate is on disk
+	SSLKeyPath  string
+}
+
+type nginxErrorPage struct {
+	Code int
+	URI  string
+}
+
+// errorPages resolves a webroot's configured error pages into error_page
+// directives. Pages whose file does not exist in the webroot's storage dir
+// are returned in missing and left out, so nginx falls back to its default
+// page instead of serving a broken one.
+func (m *NginxManager) errorPages(webroot *runtime.WebrootInfo) (pages []nginxErrorPage, missing []string) {
+	statusCodes := make([]int, 0, len(webroot.ErrorPages))
+	for code := range webroot.ErrorPages {
+		statusCodes = append(statusCodes, code)
+	}
+	sort.Ints(statusCodes)
+
+	webrootDir := runtime.AppDir(filepath.Join(m.storageDir, webroot.TenantName, "webroots", webroot.Name), webroot.Releases)
+	for _, code := range statusCodes {
+		p := webroot.ErrorPages[code]
+		uri, err := runtime.ErrorPageURI(p, webroot.PublicFolder)
+		if err != nil || !fileExists(filepath.Join(webrootDir, filepath.FromSlash(p))) {
+			missing = append(missing, p)
+			continue
+		}
+		pages = append(pages, nginxErrorPage{Code: code, URI: uri})
+	}
+	return pages, missing
+}
+
+// deployLockRetryAfter returns the whole seconds from now until a deploy
+// lock expires, at least 1, or 0 if there is no lock or it has expired.
+// Expiry is decided when the config is rendered: the lock is dropped by the
+// next render after it, which the deploy lock workflow triggers.
+func deployLockRetryAfter(until *time.Time, now time.Time) int {
+	if until == nil || !until.After(now) {
+		return 0
+	}
+	return int(math.Ceil(until.Sub(now).Seconds()))
+}
+
+// cacheRules turns a webroot's cache rules into nginx map entries, in order.
+func cacheRules(rules []model.WebrootCacheRule) []nginxCacheRule {
+	result := make([]nginxCacheRule, 0, len(rules))
+	for _, r := range rules {
+		rule := nginxCacheRule{CacheControl: r.CacheControl, Expires: r.Expires}
+		if r.IsExtension() {
+			rule.Match = `~*` + regexp.QuoteMeta(strings.TrimPrefix(r.Pattern, "*")) + `$`
+		} else {
+			rule.Match = `~^` + regexp.QuoteMeta(r.Pattern)
+		}
+		if rule.Expires == "" {
+			rule.Expires = "off"
+		}
+		result = append(result, rule)
+	}
+	return result
+}
+
+// mimeTypes returns a webroot's MIME type mappings sorted by extension.
+func mimeTypes(types map[string]string) []nginxMimeType {
+	result := make([]nginxMimeType, 0, len(types))
+	for ext, t := range types {
+		result = append(result, nginxMimeType{Extension: ext, Type: t})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Extension < result[j].Extension })
+	return result
+}
+
+// MissingErrorPages returns the configured error page paths of a webroot that
+// cannot be served (file missing or outside the public folder).
+func (m *NginxManager) MissingErrorPages(webroot *runtime.WebrootInfo) []string {
+	_, missing := m.errorPages(webroot)
+	return missing
 }
 
 // GenerateConfig produces the nginx server block configuration for a webroot.
 // daemons may be nil when no daemon proxy locations are needed.
 func (m *NginxManager) GenerateConfig(webroot *runtime.WebrootInfo, fqdns []*FQDNInfo, daemons ...DaemonProxyInfo) (string, error) {
+	if err := webroot.StaticRules.Validate(); err != nil {
+		return "", fmt.Errorf("invalid static rules: %w", err)
+	}
+	if err := webroot.GeoBlocking.Validate(); err != nil {
+		return "", fmt.Errorf("invalid geo-blocking: %w", err)
+	}
+
 	tenantName := webroot.TenantName
 	webrootName := webroot.Name
 	rt := webroot.Runtime
 	rtVersion := webroot.RuntimeVersion
 	publicFolder := webroot.PublicFolder
 
+	// Redirecting FQDNs get server blocks of their own; the rest serve the
+	// webroot.
+	var served []*FQDNInfo
+	var redirects []nginxRedirect
+	for _, f := range fqdns {
+		if f.RedirectTarget != nil {
+			redirects = append(redirects, m.redirect(f))
+		} else {
+			served = append(served, f)
+		}
+	}
+
 	// Build server_name list from FQDNs.
 	var serverNames []string
 	hasSSL := false
 	var sslFQDN string
 
-	for _, f := range fqdns {
+	for _, f := range served {
 		// Strip DNS trailing dot — nginx server_name matches against the Host header
 		// which never includes the trailing dot.
 		serverNames = append(serverNames, strings.TrimSuffix(f.FQDN, "."))
@@ -216,8 +562,9 @@ func (m *NginxManager) GenerateConfig(webroot *runtime.WebrootInfo, fqdns []*FQD
 		serverNames = append(serverNames, "_")
 	}
 
-	// Build the document root path (under webroots/ on CephFS).
-	docRoot := filepath.Join("/var/www/storage", tenantName, "webroots", webrootName)
+	// Build the document root path (under webroots/ on CephFS). With releases
+	// it goes through the current symlink, which nginx resolves per request.
+	docRoot := runtime.AppDir(filepath.Join("/var/www/storage", tenantName, "webroots", webrootName), webroot.Releases)
 	if publicFolder != "" {
 		docRoot = filepath.Join(docRoot, publicFolder)
 	}
@@ -258,6 +605,41 @@ func (m *NginxManager) GenerateConfig(webroot *runtime.WebrootInfo, fqdns []*FQD
 		}
 	}
 
+	// Split the names served on the plain HTTP port: SSL FQDNs with
+	// force_https get a 301 to HTTPS, everything else is served directly.
+	// Without a usable certificate every name is served over HTTP.
+	var redirectNames, httpNames []string
+	if hasSSL {
+		for _, f := range served {
+			name := strings.TrimSuffix(f.FQDN, ".")
+			if f.SSLEnabled && f.ForceHTTPS {
+				redirectNames = append(redirectNames, name)
+			} else {
+				httpNames = append(httpNames, name)
+			}
+		}
+	} else {
+		httpNames = serverNames
+	}
+
+	errorPages, missingPages := m.errorPages(webroot)
+	if len(missingPages) > 0 {
+		m.logger.Warn().
+			Str("webroot", webrootName).
+			Strs("paths", missingPages).
+			Msg("custom error pages not found, falling back to nginx defaults")
+	}
+
+	var basicAuthFile string
+	if len(webroot.BasicAuth) > 0 {
+		basicAuthFile = m.htpasswdPath(tenantName, webrootName)
+	}
+
+	var accessLogPath string
+	if webroot.AccessLog {
+		accessLogPath = m.sharedAccessLogPath(tenantName, webrootName)
+	}
+
 	data := nginxTemplateData{
 		TenantName:     tenantName,
 		TenantID:       tenantName,
@@ -265,7 +647,10 @@ func (m *NginxManager) GenerateConfig(webroot *runtime.WebrootInfo, fqdns []*FQD
 		WebrootID:      webroot.ID,
 		ShardName:      m.shardName,
 		ServerNames:    strings.Join(serverNames, " "),
+		RedirectNames:  strings.Join(redirectNames, " "),
+		HTTPNames:      strings.Join(httpNames, " "),
 		DocumentRoot:   docRoot,
+		Releases:       webroot.Releases,
 		Runtime:        rt,
 		RuntimeVersion: rtVersion,
 		HasSSL:         hasSSL,
@@ -275,6 +660,31 @@ func (m *NginxManager) GenerateConfig(webroot *runtime.WebrootInfo, fqdns []*FQD
 		ProxyPort:      proxyPort,
 		ListenPort:     m.listenPort,
 		Daemons:        daemons,
+		ErrorPages:     errorPages,
+		BasicAuthFile:  basicAuthFile,
+		AccessLogPath:  accessLogPath,
+		LimitConn:      webroot.Limits.MaxConnsPerIP,
+		LimitRate:      webroot.Limits.RequestsPerSecond,
+		LimitBurst:     webroot.Limits.Burst,
+		LimitZoneKB:    model.WebrootLimitZoneKB,
+		Redirects:      redirects,
+		WebrootVar:     strings.ReplaceAll(webroot.ID, "-", "_"),
+		CacheRules:     cacheRules(webroot.StaticRules.CacheRules),
+		MimeTypes:      mimeTypes(webroot.StaticRules.MimeTypes),
+		ConfigDir:      m.configDir,
+	}
+	if len(m.realIPFrom) > 0 {
+		data.ListenOpts = " proxy_protocol"
+		data.RealIPConfig = m.proxyProtocolConfigPath()
+	}
+	data.DeployLockRetryAfter = deployLockRetryAfter(webroot.DeployLock, time.Now())
+	if webroot.GeoBlocking.Enabled() {
+		data.GeoCountries = webroot.GeoBlocking.Countries
+		if webroot.GeoBlocking.Mode == model.GeoBlockingAllow {
+			data.GeoDefault = 1
+		} else {
+			data.GeoListed = 1
+		}
 	}
 
 	var buf bytes.Buffer
@@ -285,6 +695,48 @@ func (m *NginxManager) GenerateConfig(webroot *runtime.WebrootInfo, fqdns []*FQD
 	return buf.String(), nil
 }
 
+// redirect builds the server blocks of a redirecting FQDN. The redirect is
+// served over HTTPS too once the FQDN's certificate is on disk; until then,
+// and for FQDNs without SSL, only over plain HTTP.
+func (m *NginxManager) redirect(f *FQDNInfo) nginxRedirect {
+	name := strings.TrimSuffix(f.FQDN, ".")
+	target := strings.TrimSuffix(*f.RedirectTarget, ".")
+	code := f.RedirectCode
+	if code == 0 {
+		code = 301
+	}
+	path := "/"
+	if f.RedirectPreservePath {
+		path = "$request_uri"
+	}
+
+	r := nginxRedirect{
+		Name:        name,
+		Code:        code,
+		HTTPTarget:  "http://" + target + path,
+		HTTPSTarget: "https://" + target + path,
+	}
+	if !f.SSLEnabled {
+		return r
+	}
+
+	certPath := filepath.Join(m.certDir, name, "fullchain.pem")
+	keyPath := filepath.Join(m.certDir, name, "privkey.pem")
+	if !fileExists(certPath) || !fileExists(keyPath) {
+		m.logger.Warn().
+			Str("fqdn", name).
+			Str("cert_path", certPath).
+			Msg("SSL certificate files not found on disk, redirecting over HTTP only")
+		return r
+	}
+	r.SSLCertPath = certPath
+	r.SSLKeyPath = keyPath
+	if f.ForceHTTPS {
+		r.HTTPTarget = r.HTTPSTarget
+	}
+	return r
+}
+
 // WriteConfig writes an nginx configuration file for a tenant/webroot combination.
 func (m *NginxManager) WriteConfig(tenantName, webrootName, config string) error {
 	sitesDir := filepath.Join(m.configDir, "sites-enabled")
@@ -314,6 +766,68 @@ func (m *NginxManager) WriteConfig(tenantName, webrootName, config string) error
 	return nil
 }
 
+// htpasswdPath returns the basic-auth user file for a tenant/webroot. It lives
+// outside sites-enabled so nginx never tries to load it as config.
+func (m *NginxManager) htpasswdPath(tenantName, webrootName string) string {
+	return filepath.Join(m.configDir, "htpasswd", fmt.Sprintf("%s_%s", tenantName, webrootName))
+}
+
+// WriteHtpasswd writes the basic-auth user file for a webroot from its
+// username -> bcrypt hash map, or removes it when basic auth is off. The file
+// is readable by the nginx workers' group only.
+func (m *NginxManager) WriteHtpasswd(webroot *runtime.WebrootInfo) error {
+	if len(webroot.BasicAuth) == 0 {
+		return m.RemoveHtpasswd(webroot.TenantName, webroot.Name)
+	}
+
+	usernames := make([]string, 0, len(webroot.BasicAuth))
+	for u := range webroot.BasicAuth {
+		usernames = append(usernames, u)
+	}
+	sort.Strings(usernames)
+
+	var buf bytes.Buffer
+	for _, u := range usernames {
+		fmt.Fprintf(&buf, "%s:%s\n", u, webroot.BasicAuth[u])
+	}
+
+	path := m.htpasswdPath(webroot.TenantName, webroot.Name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return status.Errorf(codes.Internal, "mkdir htpasswd dir: %v", err)
+	}
+
+	// Write to a temp file and rename so nginx never reads a partial file.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0640); err != nil {
+		return status.Errorf(codes.Internal, "write htpasswd %s: %v", path, err)
+	}
+	if g, err := user.LookupGroup(nginxGroup); err == nil {
+		if gid, err := strconv.Atoi(g.Gid); err == nil {
+			_ = os.Chown(tmp, -1, gid)
+		}
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return status.Errorf(codes.Internal, "rename htpasswd %s: %v", path, err)
+	}
+
+	m.logger.Info().
+		Str("tenant", webroot.TenantName).
+		Str("webroot", webroot.Name).
+		Int("users", len(usernames)).
+		Msg("wrote htpasswd")
+
+	return nil
+}
+
+// RemoveHtpasswd removes the basic-auth user file for a tenant/webroot.
+func (m *NginxManager) RemoveHtpasswd(tenantName, webrootName string) error {
+	path := m.htpasswdPath(tenantName, webrootName)
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return status.Errorf(codes.Internal, "remove htpasswd %s: %v", path, err)
+	}
+	return nil
+}
+
 // RemoveConfig removes the nginx configuration file for a tenant/webroot.
 func (m *NginxManager) RemoveConfig(tenantName, webrootName string) error {
 	confPath := filepath.Join(m.configDir, "sites-enabled", fmt.Sprintf("%s_%s.conf", tenantName, webrootName))
@@ -343,7 +857,7 @@ func (m *NginxManager) Reload(ctx context.Context) error {
 
 	// Test configuration first. Config errors are non-retryable (FailedPrecondition)
 	// since they require a code/config fix, not a retry.
-	testCmd := exec.CommandContext(ctx, "nginx", "-t")
+	testCmd := cmdaudit.CommandContext(ctx, "nginx", "-t")
 	m.logger.Debug().Strs("cmd", testCmd.Args).Msg("executing nginx -t")
 	if output, err := testCmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.FailedPrecondition, "nginx config test failed: %s: %v", string(output), err)
@@ -354,7 +868,7 @@ func (m *NginxManager) Reload(ctx context.Context) error {
 	if err != nil || len(bytes.TrimSpace(pidData)) == 0 {
 		// Nginx is not running — start it.
 		m.logger.Info().Msg("nginx not running, starting it")
-		startCmd := exec.CommandContext(ctx, "nginx")
+		startCmd := cmdaudit.CommandContext(ctx, "nginx")
 		m.logger.Debug().Strs("cmd", startCmd.Args).Msg("executing nginx")
 		if output, err := startCmd.CombinedOutput(); err != nil {
 			return status.Errorf(codes.Internal, "nginx start failed: %s: %v", string(output), err)
@@ -363,7 +877,7 @@ func (m *NginxManager) Reload(ctx context.Context) error {
 	}
 
 	// Reload nginx.
-	reloadCmd := exec.CommandContext(ctx, "nginx", "-s", "reload")
+	reloadCmd := cmdaudit.CommandContext(ctx, "nginx", "-s", "reload")
 	m.logger.Debug().Strs("cmd", reloadCmd.Args).Msg("executing nginx -s reload")
 	if output, err := reloadCmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.Internal, "nginx reload failed: %s: %v", string(output), err)
@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// defaultWorkerConnections matches the Debian nginx.conf the base config
+// replaces.
+const defaultWorkerConnections = 768
+
+const nginxBaseTemplate = `# Auto-generated by node-agent from the shard's nginx tuning
+# DO NOT EDIT MANUALLY
+user www-data;
+worker_processes auto;
+pid /run/nginx.pid;
+error_log /var/log/nginx/error.log;
+include {{ .ConfigDir }}/modules-enabled/*.conf;
+
+events {
+    worker_connections {{ .WorkerConnections }};
+}
+
+http {
+    sendfile on;
+    tcp_nopush on;
+    types_hash_max_size 2048;
+    server_tokens off;
+
+    include {{ .ConfigDir }}/mime.types;
+    default_type application/octet-stream;
+{{- if .KeepaliveTimeout }}
+    keepalive_timeout {{ .KeepaliveTimeout }}s;
+{{- end }}
+{{- if .ClientBodyBufferSize }}
+    client_body_buffer_size {{ .ClientBodyBufferSize }};
+{{- end }}
+{{- if .ClientMaxBodySize }}
+    client_max_body_size {{ .ClientMaxBodySize }};
+{{- end }}
+{{- if .LargeClientHeaderBuffers }}
+    large_client_header_buffers {{ .LargeClientHeaderBuffers }};
+{{- end }}
+{{- if .ProxyBufferSize }}
+    proxy_buffer_size {{ .ProxyBufferSize }};
+{{- end }}
+{{- if .ProxyBuffers }}
+    proxy_buffers {{ .ProxyBuffers }};
+{{- end }}
+
+    access_log /var/log/nginx/access.log;
+
+{{- if .GzipOff }}
+    gzip off;
+{{- else }}
+    gzip on;
+{{- if .GzipCompLevel }}
+    gzip_comp_level {{ .GzipCompLevel }};
+{{- end }}
+{{- if .GzipMinLength }}
+    gzip_min_length {{ .GzipMinLength }};
+{{- end }}
+{{- if .GzipTypes }}
+    gzip_vary on;
+    gzip_proxied any;
+    gzip_types {{ .GzipTypes }};
+{{- end }}
+{{- end }}
+
+    include {{ .ConfigDir }}/conf.d/*.conf;
+    include {{ .ConfigDir }}/sites-enabled/*;
+}
+`
+
+var nginxBaseTmpl = template.Must(template.New("nginx-base").Parse(nginxBaseTemplate))
+
+type nginxBaseTemplateData struct {
+	model.NginxTuning
+	ConfigDir string
+	GzipOff   bool
+	GzipTypes string
+}
+
+// RenderBaseConfig renders the main nginx.conf for the given shard tuning.
+func (m *NginxManager) RenderBaseConfig(tuning model.NginxTuning) (string, error) {
+	if err := tuning.Validate(); err != nil {
+		return "", status.Errorf(codes.InvalidArgument, "invalid nginx tuning: %v", err)
+	}
+	data := nginxBaseTemplateData{
+		NginxTuning: tuning,
+		ConfigDir:   m.configDir,
+		GzipOff:     tuning.Gzip != nil && !*tuning.Gzip,
+		GzipTypes:   strings.Join(tuning.GzipTypes, " "),
+	}
+	if data.WorkerConnections == 0 {
+		data.WorkerConnections = defaultWorkerConnections
+	}
+	var buf bytes.Buffer
+	if err := nginxBaseTmpl.Execute(&buf, data); err != nil {
+		return "", status.Errorf(codes.Internal, "render nginx.conf: %v", err)
+	}
+	return buf.String(), nil
+}
+
+// WriteBaseConfig renders nginx.conf for the given tuning and writes it if it
+// differs from the file on disk. A new config that fails nginx -t is replaced
+// by the previous one again. It reports whether the file changed; the caller
+// reloads nginx.
+func (m *NginxManager) WriteBaseConfig(ctx context.Context, tuning model.NginxTuning) (bool, error) {
+	config, err := m.RenderBaseConfig(tuning)
+	if err != nil {
+		return false, err
+	}
+
+	path := filepath.Join(m.configDir, "nginx.conf")
+	previous, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, status.Errorf(codes.Internal, "read %s: %v", path, err)
+	}
+	if err == nil && string(previous) == config {
+		return false, nil
+	}
+
+	m.logger.Info().Str("path", path).Msg("writing nginx base config")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(config), 0644); err != nil {
+		return false, status.Errorf(codes.Internal, "write %s: %v", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return false, status.Errorf(codes.Internal, "rename %s: %v", path, err)
+	}
+
+	testCmd := cmdaudit.CommandContext(ctx, "nginx", "-t")
+	if output, err := testCmd.CombinedOutput(); err != nil {
+		if previous != nil {
+			if rbErr := os.WriteFile(path, previous, 0644); rbErr != nil {
+				m.logger.Error().Err(rbErr).Str("path", path).Msg("failed to restore previous nginx.conf")
+			}
+		}
+		return false, status.Errorf(codes.FailedPrecondition, "nginx config test failed: %s: %v", string(output), err)
+	}
+	return true, nil
+}
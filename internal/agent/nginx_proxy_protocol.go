@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// proxyProtocolConfigName is the file, next to nginx.conf, with the realip
+// directives of a shard that receives the PROXY protocol from its LB. Site
+// server blocks include it while PROXY protocol is on. It is kept, with
+// only a comment, once PROXY protocol is turned off, so site configs that
+// still include it keep passing nginx -t until they are regenerated. It
+// also records the setting across agent restarts.
+const proxyProtocolConfigName = "hosting-proxy-protocol.conf"
+
+func (m *NginxManager) proxyProtocolConfigPath() string {
+	return filepath.Join(m.configDir, proxyProtocolConfigName)
+}
+
+// loadProxyProtocol reads the trusted LB addresses back from the PROXY
+// protocol config written by SetProxyProtocol.
+func (m *NginxManager) loadProxyProtocol() {
+	data, err := os.ReadFile(m.proxyProtocolConfigPath())
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if addr, ok := strings.CutPrefix(strings.TrimSpace(line), "set_real_ip_from "); ok {
+			m.realIPFrom = append(m.realIPFrom, strings.TrimSuffix(addr, ";"))
+		}
+	}
+}
+
+// renderProxyProtocolConfig renders the PROXY protocol config for the given
+// trusted LB addresses; none renders the config for PROXY protocol off.
+func renderProxyProtocolConfig(realIPFrom []string) string {
+	var b strings.Builder
+	b.WriteString("# Auto-generated by node-agent from the shard's proxy_protocol setting\n# DO NOT EDIT MANUALLY\n")
+	if len(realIPFrom) == 0 {
+		b.WriteString("# PROXY protocol is off.\n")
+		return b.String()
+	}
+	// Server-level realip directives replace the http-level ones trusting
+	// X-Forwarded-For.
+	b.WriteString("real_ip_header proxy_protocol;\n")
+	for _, addr := range realIPFrom {
+		fmt.Fprintf(&b, "set_real_ip_from %s;\n", addr)
+	}
+	return b.String()
+}
+
+// SetProxyProtocol turns PROXY protocol on for site configs generated from
+// now on, trusting the header from the given LB addresses (IPs or CIDRs), or
+// off if there are none. It reports whether the setting changed; the caller
+// regenerates the site configs and reloads nginx.
+func (m *NginxManager) SetProxyProtocol(realIPFrom []string) (bool, error) {
+	for _, addr := range realIPFrom {
+		if _, err := netip.ParsePrefix(addr); err != nil {
+			if _, err := netip.ParseAddr(addr); err != nil {
+				return false, status.Errorf(codes.InvalidArgument, "invalid PROXY protocol address %q", addr)
+			}
+		}
+	}
+
+	path := m.proxyProtocolConfigPath()
+	config := renderProxyProtocolConfig(realIPFrom)
+	current, err := os.ReadFile(path)
+	if err == nil && string(current) == config {
+		return false, nil
+	}
+	if err != nil && os.IsNotExist(err) && len(realIPFrom) == 0 {
+		// Never turned on.
+		return false, nil
+	}
+
+	m.logger.Info().Str("path", path).Strs("real_ip_from", realIPFrom).Msg("writing PROXY protocol config")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		return false, status.Errorf(codes.Internal, "write %s: %v", path, err)
+	}
+	m.realIPFrom = slices.Clone(realIPFrom)
+	return true, nil
+}
@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/agent/runtime"
+)
+
+func TestGenerateConfig_ProxyProtocol(t *testing.T) {
+	mgr := newSSLNginxManager(t, "www.example.com")
+
+	changed, err := mgr.SetProxyProtocol([]string{"10.0.0.5", "fd00::/64"})
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	webroot := &runtime.WebrootInfo{ID: "wr-001", TenantName: "tenant1", Name: "mysite", Runtime: "static"}
+	target := "example.com"
+	fqdns := []*FQDNInfo{
+		{FQDN: "example.com", SSLEnabled: true},
+		{FQDN: "www.example.com", SSLEnabled: true, RedirectTarget: &target, RedirectCode: 301},
+	}
+	config, err := mgr.GenerateConfig(webroot, fqdns)
+	require.NoError(t, err)
+
+	// Every listen directive takes the header, and every server block trusts
+	// it from the LB.
+	assert.NotContains(t, config, "listen 80;")
+	assert.NotContains(t, config, "listen 443 ssl;")
+	assert.Contains(t, config, "listen 80 proxy_protocol;\n    listen [::]:80 proxy_protocol;")
+	assert.Contains(t, config, "listen 443 ssl proxy_protocol;\n    listen [::]:443 ssl proxy_protocol;")
+	include := "include " + mgr.proxyProtocolConfigPath() + ";"
+	assert.Equal(t, strings.Count(config, "server {"), strings.Count(config, include))
+
+	realIP, err := os.ReadFile(mgr.proxyProtocolConfigPath())
+	require.NoError(t, err)
+	assert.Contains(t, string(realIP), "real_ip_header proxy_protocol;\nset_real_ip_from 10.0.0.5;\nset_real_ip_from fd00::/64;\n")
+
+	// The setting survives an agent restart.
+	restarted := NewNginxManager(zerolog.Nop(), Config{NginxConfigDir: mgr.configDir})
+	assert.Equal(t, []string{"10.0.0.5", "fd00::/64"}, restarted.realIPFrom)
+}
+
+func TestSetProxyProtocol_Off(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	// Never turned on: nothing to write.
+	changed, err := mgr.SetProxyProtocol(nil)
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.NoFileExists(t, mgr.proxyProtocolConfigPath())
+
+	_, err = mgr.SetProxyProtocol([]string{"10.0.0.5"})
+	require.NoError(t, err)
+	changed, err = mgr.SetProxyProtocol([]string{"10.0.0.5"})
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// Turning it off keeps the file for configs that still include it.
+	changed, err = mgr.SetProxyProtocol(nil)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	realIP, err := os.ReadFile(mgr.proxyProtocolConfigPath())
+	require.NoError(t, err)
+	assert.NotContains(t, string(realIP), "real_ip_header")
+
+	config, err := mgr.GenerateConfig(&runtime.WebrootInfo{ID: "wr-001", TenantName: "tenant1", Name: "mysite", Runtime: "static"},
+		[]*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+	assert.Contains(t, config, "listen 80;")
+	assert.NotContains(t, config, "include "+mgr.proxyProtocolConfigPath())
+}
+
+func TestSetProxyProtocol_InvalidAddress(t *testing.T) {
+	mgr := newTestNginxManager(t)
+	_, err := mgr.SetProxyProtocol([]string{"10.0.0.5; deny all"})
+	assert.Error(t, err)
+	assert.NoFileExists(t, mgr.proxyProtocolConfigPath())
+}
@@ -5,12 +5,14 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/edvin/hosting/internal/agent/runtime"
+	"github.com/edvin/hosting/internal/model"
 )
 
 // newTestNginxManager creates an NginxManager with a temporary config directory.
@@ -277,7 +279,7 @@ func TestGenerateConfig_WithSSL(t *testing.T) {
 		Runtime:    "static",
 	}
 	fqdns := []*FQDNInfo{
-		{FQDN: "secure.example.com", SSLEnabled: true},
+		{FQDN: "secure.example.com", SSLEnabled: true, ForceHTTPS: true},
 	}
 
 	config, err := mgr.GenerateConfig(webroot, fqdns)
@@ -317,6 +319,172 @@ func TestGenerateConfig_WithSSL(t *testing.T) {
 	assert.True(t, foundRedirectBlock, "redirect server block should listen on port 80")
 }
 
+// newSSLNginxManager returns an NginxManager with certificate files on disk
+// for the given FQDN.
+func newSSLNginxManager(t *testing.T, fqdn string) *NginxManager {
+	t.Helper()
+	tmpDir := t.TempDir()
+	certDir := filepath.Join(tmpDir, "certs")
+	fqdnCertDir := filepath.Join(certDir, fqdn)
+	require.NoError(t, os.MkdirAll(fqdnCertDir, 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(fqdnCertDir, "fullchain.pem"), []byte("cert"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(fqdnCertDir, "privkey.pem"), []byte("key"), 0600))
+	return NewNginxManager(zerolog.Nop(), Config{NginxConfigDir: tmpDir, CertDir: certDir})
+}
+
+// serverBlocks splits a generated config into its server blocks.
+func serverBlocks(config string) []string {
+	parts := strings.Split(config, "\nserver {\n")
+	return parts[1:]
+}
+
+func TestGenerateConfig_WithSSL_RedirectKeepsACMEReachable(t *testing.T) {
+	mgr := newSSLNginxManager(t, "secure.example.com")
+
+	webroot := &runtime.WebrootInfo{
+		TenantName: "tenant1",
+		Name:       "securesite",
+		Runtime:    "node",
+	}
+	fqdns := []*FQDNInfo{
+		{FQDN: "secure.example.com", SSLEnabled: true, ForceHTTPS: true},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, fqdns)
+	require.NoError(t, err)
+
+	blocks := serverBlocks(config)
+	require.Len(t, blocks, 2)
+
+	// The port-80 block redirects everything except ACME challenges.
+	redirect := blocks[0]
+	assert.Contains(t, redirect, "listen 80;")
+	assert.Contains(t, redirect, "server_name secure.example.com;")
+	assert.Contains(t, redirect, "root /var/www/storage/tenant1/webroots/securesite;")
+	assert.Contains(t, redirect, "location ^~ /.well-known/acme-challenge/ {\n        try_files $uri =404;")
+	assert.Contains(t, redirect, "return 301 https://$host$request_uri")
+	assert.Less(t, strings.Index(redirect, "acme-challenge"), strings.Index(redirect, "return 301"))
+
+	assert.Contains(t, blocks[1], "listen 443 ssl;")
+	assert.NotContains(t, blocks[1], "listen 80;")
+}
+
+func TestGenerateConfig_WithSSL_ForceHTTPSDisabled(t *testing.T) {
+	mgr := newSSLNginxManager(t, "secure.example.com")
+
+	webroot := &runtime.WebrootInfo{
+		TenantName: "tenant1",
+		Name:       "securesite",
+		Runtime:    "php",
+	}
+	fqdns := []*FQDNInfo{
+		{FQDN: "secure.example.com", SSLEnabled: true, ForceHTTPS: false},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, fqdns)
+	require.NoError(t, err)
+
+	assert.NotContains(t, config, "return 301")
+
+	// The app is served on both ports.
+	blocks := serverBlocks(config)
+	require.Len(t, blocks, 2)
+	for _, b := range blocks {
+		assert.Contains(t, b, "server_name secure.example.com;")
+		assert.Contains(t, b, "fastcgi_pass unix:/run/php/tenant1-php.sock;")
+		assert.Contains(t, b, "location ^~ /.well-known/acme-challenge/")
+	}
+	assert.Contains(t, blocks[0], "listen 80;")
+	assert.Contains(t, blocks[1], "listen 443 ssl;")
+}
+
+func TestGenerateConfig_WithSSL_MixedForceHTTPS(t *testing.T) {
+	mgr := newSSLNginxManager(t, "www.example.com")
+
+	webroot := &runtime.WebrootInfo{
+		TenantName: "tenant1",
+		Name:       "mixedsite",
+		Runtime:    "static",
+	}
+	fqdns := []*FQDNInfo{
+		{FQDN: "www.example.com", SSLEnabled: true, ForceHTTPS: true},
+		{FQDN: "legacy.example.com", SSLEnabled: true, ForceHTTPS: false},
+		{FQDN: "plain.example.com", SSLEnabled: false, ForceHTTPS: true},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, fqdns)
+	require.NoError(t, err)
+
+	blocks := serverBlocks(config)
+	require.Len(t, blocks, 3)
+	assert.Contains(t, blocks[0], "server_name www.example.com;")
+	assert.Contains(t, blocks[0], "return 301")
+	assert.Contains(t, blocks[1], "server_name legacy.example.com plain.example.com;")
+	assert.NotContains(t, blocks[1], "return 301")
+	assert.Contains(t, blocks[2], "server_name www.example.com legacy.example.com plain.example.com;")
+	assert.Contains(t, blocks[2], "listen 443 ssl;")
+}
+
+func TestGenerateConfig_RedirectFQDN(t *testing.T) {
+	mgr := newSSLNginxManager(t, "www.example.com")
+
+	webroot := &runtime.WebrootInfo{
+		TenantName: "tenant1",
+		Name:       "site",
+		Runtime:    "static",
+	}
+	target := "example.com"
+	fqdns := []*FQDNInfo{
+		{FQDN: "example.com", SSLEnabled: true, ForceHTTPS: true},
+		{FQDN: "www.example.com", SSLEnabled: true, ForceHTTPS: true, RedirectTarget: &target, RedirectCode: 301, RedirectPreservePath: true},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, fqdns)
+	require.NoError(t, err)
+
+	blocks := serverBlocks(config)
+	require.GreaterOrEqual(t, len(blocks), 2)
+
+	// The alias keeps ACME reachable and redirects everything else, using
+	// its own certificate on 443.
+	assert.Contains(t, blocks[0], "listen 80;")
+	assert.Contains(t, blocks[0], "server_name www.example.com;")
+	assert.Contains(t, blocks[0], "location ^~ /.well-known/acme-challenge/")
+	assert.Contains(t, blocks[0], "return 301 https://example.com$request_uri;")
+	assert.Contains(t, blocks[1], "listen 443 ssl;")
+	assert.Contains(t, blocks[1], "ssl_certificate     "+filepath.Join(mgr.certDir, "www.example.com", "fullchain.pem")+";")
+	assert.Contains(t, blocks[1], "server_name www.example.com;")
+	assert.Contains(t, blocks[1], "return 301 https://example.com$request_uri;")
+	assert.NotContains(t, blocks[1], "root ")
+
+	// The alias is not served by the webroot.
+	for _, b := range blocks[2:] {
+		assert.NotContains(t, b, "www.example.com")
+	}
+}
+
+func TestGenerateConfig_RedirectFQDN_NoPathNoSSL(t *testing.T) {
+	mgr := NewNginxManager(zerolog.Nop(), Config{NginxConfigDir: t.TempDir(), CertDir: t.TempDir()})
+
+	webroot := &runtime.WebrootInfo{
+		TenantName: "tenant1",
+		Name:       "site",
+		Runtime:    "static",
+	}
+	target := "new.example.com."
+	fqdns := []*FQDNInfo{
+		{FQDN: "old.example.com", SSLEnabled: true, RedirectTarget: &target, RedirectCode: 302},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, fqdns)
+	require.NoError(t, err)
+
+	// Without a certificate on disk the redirect is served over HTTP only.
+	assert.Contains(t, config, "return 302 http://new.example.com/;")
+	assert.NotContains(t, config, "https://new.example.com")
+	assert.NotContains(t, config, "listen 443 ssl;")
+}
+
 func TestGenerateConfig_WithSSL_CertsMissing_FallbackToHTTP(t *testing.T) {
 	tmpDir := t.TempDir()
 	cfg := Config{
@@ -487,6 +655,29 @@ func TestGenerateConfig_WithPublicFolder(t *testing.T) {
 	assert.Contains(t, config, "root /var/www/storage/tenant1/webroots/laravelapp/public")
 }
 
+func TestGenerateConfig_WithReleases(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	webroot := &runtime.WebrootInfo{
+		TenantName:   "tenant1",
+		Name:         "laravelapp",
+		Runtime:      "php",
+		PublicFolder: "public",
+		Releases:     true,
+	}
+	fqdns := []*FQDNInfo{
+		{FQDN: "laravel.example.com"},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, fqdns)
+	require.NoError(t, err)
+
+	// The document root goes through the current release symlink, and PHP
+	// gets the resolved path so opcache never serves a swapped-out release.
+	assert.Contains(t, config, "root /var/www/storage/tenant1/webroots/laravelapp/current/public")
+	assert.Contains(t, config, "fastcgi_param SCRIPT_FILENAME $realpath_root$fastcgi_script_name")
+}
+
 func TestGenerateConfig_WithoutPublicFolder(t *testing.T) {
 	mgr := newTestNginxManager(t)
 
@@ -509,6 +700,86 @@ func TestGenerateConfig_WithoutPublicFolder(t *testing.T) {
 	assert.NotContains(t, config, "root /var/www/storage/tenant1/webroots/plainsite/public")
 }
 
+func TestGenerateConfig_ErrorPages(t *testing.T) {
+	tmpDir := t.TempDir()
+	storageDir := filepath.Join(tmpDir, "storage")
+	mgr := NewNginxManager(zerolog.Nop(), Config{
+		NginxConfigDir: tmpDir,
+		WebStorageDir:  storageDir,
+	})
+
+	publicDir := filepath.Join(storageDir, "tenant1", "webroots", "site", "public", "errors")
+	require.NoError(t, os.MkdirAll(publicDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(publicDir, "404.html"), []byte("not found"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(publicDir, "50x.html"), []byte("oops"), 0644))
+
+	webroot := &runtime.WebrootInfo{
+		TenantName:   "tenant1",
+		Name:         "site",
+		Runtime:      "static",
+		PublicFolder: "public",
+		ErrorPages: map[int]string{
+			404: "public/errors/404.html",
+			500: "public/errors/50x.html",
+			502: "public/errors/50x.html",
+		},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "site.example.com"}})
+	require.NoError(t, err)
+
+	assert.Contains(t, config, "error_page 404 /errors/404.html;")
+	assert.Contains(t, config, "error_page 500 /errors/50x.html;")
+	assert.Contains(t, config, "error_page 502 /errors/50x.html;")
+	assert.Less(t, strings.Index(config, "error_page 404"), strings.Index(config, "error_page 500"))
+	assert.Empty(t, mgr.MissingErrorPages(webroot))
+}
+
+func TestGenerateConfig_ErrorPages_MissingFallsBackToDefault(t *testing.T) {
+	tmpDir := t.TempDir()
+	storageDir := filepath.Join(tmpDir, "storage")
+	mgr := NewNginxManager(zerolog.Nop(), Config{
+		NginxConfigDir: tmpDir,
+		WebStorageDir:  storageDir,
+	})
+
+	publicDir := filepath.Join(storageDir, "tenant1", "webroots", "site", "public")
+	require.NoError(t, os.MkdirAll(publicDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(publicDir, "404.html"), []byte("not found"), 0644))
+
+	webroot := &runtime.WebrootInfo{
+		TenantName:   "tenant1",
+		Name:         "site",
+		Runtime:      "static",
+		PublicFolder: "public",
+		ErrorPages: map[int]string{
+			404: "public/404.html",
+			500: "public/500.html",
+		},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "site.example.com"}})
+	require.NoError(t, err)
+
+	assert.Contains(t, config, "error_page 404 /404.html;")
+	assert.NotContains(t, config, "error_page 500")
+	assert.Equal(t, []string{"public/500.html"}, mgr.MissingErrorPages(webroot))
+}
+
+func TestGenerateConfig_NoErrorPages(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	webroot := &runtime.WebrootInfo{
+		TenantName: "tenant1",
+		Name:       "site",
+		Runtime:    "static",
+	}
+
+	config, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "site.example.com"}})
+	require.NoError(t, err)
+	assert.NotContains(t, config, "error_page")
+}
+
 func TestGenerateConfig_NoFQDNs(t *testing.T) {
 	mgr := newTestNginxManager(t)
 
@@ -934,3 +1205,348 @@ func TestGenerateConfig_NoDaemons(t *testing.T) {
 	assert.NotContains(t, config, "proxy_read_timeout 86400s")
 	assert.Contains(t, config, "server_name example.com")
 }
+
+func TestGenerateConfig_BasicAuth(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	webroot := &runtime.WebrootInfo{
+		ID:         "wr-001",
+		TenantName: "tenant1",
+		Name:       "staging",
+		Runtime:    "static",
+		BasicAuth:  map[string]string{"alice": "$2a$10$hash"},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "staging.example.com"}})
+	require.NoError(t, err)
+
+	htpasswd := filepath.Join(mgr.configDir, "htpasswd", "tenant1_staging")
+	assert.Contains(t, config, "    auth_basic \"Restricted\";\n    auth_basic_user_file "+htpasswd+";")
+
+	// ACME challenges stay reachable without credentials.
+	acme := config[strings.Index(config, "location ^~ /.well-known/acme-challenge/"):]
+	acme = acme[:strings.Index(acme, "}")]
+	assert.Contains(t, acme, "auth_basic off;")
+}
+
+func TestGenerateConfig_NoBasicAuth(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	webroot := &runtime.WebrootInfo{
+		ID:         "wr-001",
+		TenantName: "tenant1",
+		Name:       "mysite",
+		Runtime:    "static",
+	}
+
+	config, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+	assert.NotContains(t, config, "auth_basic")
+}
+
+func TestGenerateConfig_ConnectionLimits(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	webroot := &runtime.WebrootInfo{
+		ID:         "wr-001",
+		TenantName: "tenant1",
+		Name:       "mysite",
+		Runtime:    "static",
+		Limits:     model.WebrootConnectionLimits{MaxConnsPerIP: 20, RequestsPerSecond: 10, Burst: 30},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com", SSLEnabled: true}})
+	require.NoError(t, err)
+
+	// Zones are declared once per file, at http level before any server block.
+	assert.True(t, strings.HasPrefix(config, "# Auto-generated by node-agent for tenant1/mysite\n# DO NOT EDIT MANUALLY\n"+
+		"limit_conn_zone $binary_remote_addr zone=conn_wr-001:512k;\n"+
+		"limit_req_zone $binary_remote_addr zone=req_wr-001:512k rate=10r/s;\n"))
+	assert.Equal(t, 1, strings.Count(config, "limit_conn_zone"))
+	assert.Contains(t, config, "    limit_conn conn_wr-001 20;\n    limit_conn_status 429;\n")
+	assert.Contains(t, config, "    limit_req zone=req_wr-001 burst=30 nodelay;\n    limit_req_status 429;\n")
+
+	webroot.Limits = model.WebrootConnectionLimits{RequestsPerSecond: 5}
+	config, err = mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+	assert.NotContains(t, config, "limit_conn")
+	assert.Contains(t, config, "limit_req zone=req_wr-001 burst=0 nodelay;")
+
+	webroot.Limits = model.WebrootConnectionLimits{}
+	config, err = mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+	assert.NotContains(t, config, "limit_")
+}
+
+func TestGenerateConfig_StaticRules(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	webroot := &runtime.WebrootInfo{
+		ID:         "2f4c-77ab",
+		TenantName: "tenant1",
+		Name:       "mysite",
+		Runtime:    "static",
+		StaticRules: model.WebrootStaticRules{
+			CacheRules: []model.WebrootCacheRule{
+				{Pattern: "/assets/v1.2/", CacheControl: "public, max-age=31536000, immutable"},
+				{Pattern: "*.woff2", Expires: "30d"},
+			},
+			MimeTypes: map[string]string{"wasm": "application/wasm", "md": "text/markdown"},
+		},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+
+	// Both maps list every rule so the first match decides both headers.
+	assert.Contains(t, config, "map $uri $cache_control_2f4c_77ab {\n    default \"\";\n"+
+		"    ~^/assets/v1\\.2/ \"public, max-age=31536000, immutable\";\n"+
+		"    ~*\\.woff2$ \"\";\n}\n")
+	assert.Contains(t, config, "map $uri $expires_2f4c_77ab {\n    default off;\n"+
+		"    ~^/assets/v1\\.2/ off;\n"+
+		"    ~*\\.woff2$ 30d;\n}\n")
+	assert.Contains(t, config, "    add_header Cache-Control $cache_control_2f4c_77ab;\n    expires $expires_2f4c_77ab;\n")
+	assert.Contains(t, config, "    include "+mgr.configDir+"/mime.types;\n    types {\n"+
+		"        text/markdown md;\n        application/wasm wasm;\n    }\n")
+
+	webroot.StaticRules = model.WebrootStaticRules{}
+	config, err = mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+	assert.NotContains(t, config, "map $uri")
+	assert.NotContains(t, config, "expires")
+	assert.NotContains(t, config, "types {")
+}
+
+func TestGenerateConfig_StaticRules_Invalid(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	webroot := &runtime.WebrootInfo{
+		ID:         "wr-001",
+		TenantName: "tenant1",
+		Name:       "mysite",
+		Runtime:    "static",
+		StaticRules: model.WebrootStaticRules{
+			MimeTypes: map[string]string{"css": "text/css; }"},
+		},
+	}
+
+	_, err := mgr.GenerateConfig(webroot, nil)
+	assert.ErrorContains(t, err, "invalid static rules")
+}
+
+func TestGenerateConfig_GeoBlocking(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	webroot := &runtime.WebrootInfo{
+		ID:         "2f4c-77ab",
+		TenantName: "tenant1",
+		Name:       "mysite",
+		Runtime:    "static",
+		GeoBlocking: model.WebrootGeoBlocking{
+			Mode:      model.GeoBlockingAllow,
+			Countries: []string{"NO", "SE"},
+		},
+	}
+
+	config, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+
+	// Allow mode blocks every country except the listed ones.
+	assert.Contains(t, config, "map $geoip2_country_code $geo_country_block_2f4c_77ab {\n    default 1;\n    \"\" 0;\n"+
+		"    NO 0;\n    SE 0;\n}\n")
+	assert.Contains(t, config, "map $uri $geo_block_2f4c_77ab {\n    default $geo_country_block_2f4c_77ab;\n"+
+		"    ~^/\\.well-known/acme-challenge/ 0;\n}\n")
+	assert.Contains(t, config, "    if ($geo_block_2f4c_77ab) {\n        return 403;\n    }\n")
+
+	webroot.GeoBlocking.Mode = model.GeoBlockingDeny
+	config, err = mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+	assert.Contains(t, config, "    default 0;\n    \"\" 0;\n    NO 1;\n    SE 1;\n")
+
+	webroot.GeoBlocking = model.WebrootGeoBlocking{}
+	config, err = mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+	assert.NotContains(t, config, "geoip2")
+	assert.NotContains(t, config, "return 403")
+}
+
+func TestGenerateConfig_GeoBlocking_Invalid(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	webroot := &runtime.WebrootInfo{
+		ID:          "wr-001",
+		TenantName:  "tenant1",
+		Name:        "mysite",
+		Runtime:     "static",
+		GeoBlocking: model.WebrootGeoBlocking{Mode: model.GeoBlockingDeny, Countries: []string{"NO 1; }"}},
+	}
+
+	_, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	assert.ErrorContains(t, err, "geo")
+}
+
+func TestGenerateConfig_DeployLock(t *testing.T) {
+	tmpDir := t.TempDir()
+	storageDir := filepath.Join(tmpDir, "storage")
+	mgr := NewNginxManager(zerolog.Nop(), Config{
+		NginxConfigDir: tmpDir,
+		WebStorageDir:  storageDir,
+	})
+	publicDir := filepath.Join(storageDir, "tenant1", "webroots", "mysite", "public")
+	require.NoError(t, os.MkdirAll(publicDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(publicDir, "deploying.html"), []byte("back soon"), 0644))
+
+	until := time.Now().Add(90 * time.Second)
+	webroot := &runtime.WebrootInfo{
+		ID:           "2f4c-77ab",
+		TenantName:   "tenant1",
+		Name:         "mysite",
+		Runtime:      "static",
+		PublicFolder: "public",
+		ErrorPages:   map[int]string{503: "public/deploying.html"},
+		DeployLock:   &until,
+	}
+
+	config, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+
+	// ACME challenges and the custom 503 page get through the lock.
+	assert.Contains(t, config, "map $uri $deploy_lock_2f4c_77ab {\n    volatile;\n    default 1;\n"+
+		"    ~^/\\.well-known/acme-challenge/ 0;\n    \"/deploying.html\" 0;\n}\n")
+	assert.Regexp(t, `map \$status \$deploy_lock_retry_2f4c_77ab \{\n    default "";\n    503 (89|90);\n\}\n`, config)
+	assert.Contains(t, config, "    add_header Retry-After $deploy_lock_retry_2f4c_77ab always;\n"+
+		"    if ($deploy_lock_2f4c_77ab) {\n        return 503;\n    }\n")
+
+	// An expired lock is dropped by the next render.
+	past := time.Now().Add(-time.Second)
+	webroot.DeployLock = &past
+	config, err = mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+	assert.NotContains(t, config, "deploy_lock")
+	assert.NotContains(t, config, "return 503")
+}
+
+func TestDeployLockRetryAfter(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	in := func(d time.Duration) *time.Time { t := now.Add(d); return &t }
+
+	assert.Equal(t, 0, deployLockRetryAfter(nil, now))
+	assert.Equal(t, 0, deployLockRetryAfter(in(0), now))
+	assert.Equal(t, 0, deployLockRetryAfter(in(-time.Minute), now))
+	assert.Equal(t, 1, deployLockRetryAfter(in(time.Millisecond), now))
+	assert.Equal(t, 120, deployLockRetryAfter(in(2*time.Minute), now))
+}
+
+func TestWriteHtpasswd(t *testing.T) {
+	mgr := newTestNginxManager(t)
+	webroot := &runtime.WebrootInfo{
+		TenantName: "tenant1",
+		Name:       "staging",
+		BasicAuth:  map[string]string{"bob": "$2a$10$bbb", "alice": "$2a$10$aaa"},
+	}
+	path := filepath.Join(mgr.configDir, "htpasswd", "tenant1_staging")
+
+	require.NoError(t, mgr.WriteHtpasswd(webroot))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "alice:$2a$10$aaa\nbob:$2a$10$bbb\n", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	// Turning basic auth off removes the file.
+	webroot.BasicAuth = nil
+	require.NoError(t, mgr.WriteHtpasswd(webroot))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGenerateConfig_SharedAccessLog(t *testing.T) {
+	mgr := newTestNginxManager(t)
+	mgr.hostname = "web-1-node-0"
+
+	webroot := &runtime.WebrootInfo{
+		ID:         "wr-001",
+		TenantName: "tenant1",
+		Name:       "mysite",
+		Runtime:    "static",
+	}
+
+	config, err := mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(config, "access_log "))
+
+	webroot.AccessLog = true
+	config, err = mgr.GenerateConfig(webroot, []*FQDNInfo{{FQDN: "example.com"}})
+	require.NoError(t, err)
+	assert.Contains(t, config, "    access_log /var/log/hosting/tenant1/wr-001-access.log hosting_json;\n"+
+		"    access_log /var/www/storage/tenant1/logs/mysite-access.web-1-node-0.log hosting_json;\n"+
+		"    error_log  /var/log/hosting/tenant1/wr-001-error.log warn;")
+}
+
+func TestRenderBaseConfig_Defaults(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	config, err := mgr.RenderBaseConfig(model.NginxTuning{})
+	require.NoError(t, err)
+
+	assert.Contains(t, config, "worker_connections 768;")
+	assert.Contains(t, config, "gzip on;")
+	assert.Contains(t, config, "include "+mgr.configDir+"/sites-enabled/*;")
+	assert.NotContains(t, config, "client_max_body_size")
+	assert.NotContains(t, config, "gzip_types")
+}
+
+func TestRenderBaseConfig_Tuning(t *testing.T) {
+	mgr := newTestNginxManager(t)
+	gzip := true
+
+	config, err := mgr.RenderBaseConfig(model.NginxTuning{
+		WorkerConnections:        4096,
+		KeepaliveTimeout:         30,
+		ClientMaxBodySize:        "64m",
+		LargeClientHeaderBuffers: "4 16k",
+		Gzip:                     &gzip,
+		GzipCompLevel:            5,
+		GzipTypes:                []string{"text/css", "application/json"},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, config, "worker_connections 4096;")
+	assert.Contains(t, config, "keepalive_timeout 30s;")
+	assert.Contains(t, config, "client_max_body_size 64m;")
+	assert.Contains(t, config, "large_client_header_buffers 4 16k;")
+	assert.Contains(t, config, "gzip_comp_level 5;")
+	assert.Contains(t, config, "gzip_types text/css application/json;")
+}
+
+func TestRenderBaseConfig_GzipOff(t *testing.T) {
+	mgr := newTestNginxManager(t)
+	gzip := false
+
+	config, err := mgr.RenderBaseConfig(model.NginxTuning{Gzip: &gzip, GzipCompLevel: 5})
+	require.NoError(t, err)
+
+	assert.Contains(t, config, "gzip off;")
+	assert.NotContains(t, config, "gzip_comp_level")
+}
+
+func TestRenderBaseConfig_RejectsInjection(t *testing.T) {
+	mgr := newTestNginxManager(t)
+
+	_, err := mgr.RenderBaseConfig(model.NginxTuning{ClientMaxBodySize: "1m; include /etc/passwd"})
+	require.Error(t, err)
+}
+
+func TestWriteBaseConfig_Unchanged(t *testing.T) {
+	mgr := newTestNginxManager(t)
+	config, err := mgr.RenderBaseConfig(model.NginxTuning{})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(mgr.configDir, "nginx.conf"), []byte(config), 0644))
+
+	// An unchanged config is not rewritten or tested with nginx -t.
+	changed, err := mgr.WriteBaseConfig(t.Context(), model.NginxTuning{})
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
@@ -0,0 +1,53 @@
+package runtime
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ValidateErrorPages checks a webroot's error_pages map. Keys must be HTTP
+// error status codes (400-599) and each value must be a relative file path
+// inside the webroot's storage dir that resolves under its public folder,
+// since nginx can only serve error pages from the document root.
+func ValidateErrorPages(pages map[int]string, publicFolder string) error {
+	for code, p := range pages {
+		if code < 400 || code > 599 {
+			return fmt.Errorf("error_pages: status code %d must be between 400 and 599", code)
+		}
+		if _, err := ErrorPageURI(p, publicFolder); err != nil {
+			return fmt.Errorf("error_pages[%d]: %w", code, err)
+		}
+	}
+	return nil
+}
+
+// ErrorPageURI converts an error page path (relative to the webroot storage
+// dir) into the URI nginx uses in its error_page directive, i.e. the path
+// relative to the public folder with a leading slash.
+func ErrorPageURI(p, publicFolder string) (string, error) {
+	if p == "" {
+		return "", fmt.Errorf("path is required")
+	}
+	if strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("path %q must be relative to the webroot", p)
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			return "", fmt.Errorf("path %q must not contain '..'", p)
+		}
+	}
+	if strings.ContainsAny(p, " \t\n;{}\"'") {
+		return "", fmt.Errorf("path %q contains invalid characters", p)
+	}
+
+	clean := path.Clean(p)
+	folder := strings.Trim(path.Clean("/"+publicFolder), "/")
+	if folder == "" {
+		return "/" + clean, nil
+	}
+	if !strings.HasPrefix(clean, folder+"/") {
+		return "", fmt.Errorf("path %q is not under public folder %q", p, folder)
+	}
+	return "/" + strings.TrimPrefix(clean, folder+"/"), nil
+}
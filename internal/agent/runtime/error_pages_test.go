@@ -0,0 +1,52 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorPageURI(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		publicFolder string
+		want         string
+		wantErr      bool
+	}{
+		{name: "no public folder", path: "errors/404.html", want: "/errors/404.html"},
+		{name: "under public folder", path: "public/errors/404.html", publicFolder: "public", want: "/errors/404.html"},
+		{name: "public folder with slashes", path: "public/500.html", publicFolder: "/public/", want: "/500.html"},
+		{name: "outside public folder", path: "private/404.html", publicFolder: "public", wantErr: true},
+		{name: "public folder prefix only", path: "public-old/404.html", publicFolder: "public", wantErr: true},
+		{name: "parent traversal", path: "public/../secret.html", publicFolder: "public", wantErr: true},
+		{name: "absolute path", path: "/etc/passwd", wantErr: true},
+		{name: "empty path", path: "", wantErr: true},
+		{name: "nginx metacharacters", path: "404.html; return 200", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ErrorPageURI(tt.path, tt.publicFolder)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateErrorPages(t *testing.T) {
+	assert.NoError(t, ValidateErrorPages(nil, "public"))
+	assert.NoError(t, ValidateErrorPages(map[int]string{404: "public/404.html", 503: "public/503.html"}, "public"))
+
+	err := ValidateErrorPages(map[int]string{200: "public/ok.html"}, "public")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "between 400 and 599")
+
+	err = ValidateErrorPages(map[int]string{404: "other/404.html"}, "public")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "error_pages[404]")
+}
@@ -0,0 +1,109 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// phpFPMGlob matches the versioned PHP-FPM binaries (php-fpm8.3, php-fpm8.5, ...)
+// installed side-by-side from the sury.org packages.
+var phpFPMGlob = "/usr/sbin/php-fpm*"
+
+// phpModsAvailableDir holds one ini file per installed extension of a PHP
+// version; %s is the version.
+var phpModsAvailableDir = "/etc/php/%s/mods-available"
+
+// versionOutput runs a runtime binary's version command. Overridden in tests.
+var versionOutput = func(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := cmdaudit.CommandContext(ctx, name, args...).Output()
+	return string(out), err
+}
+
+var (
+	phpFPMVersionRe = regexp.MustCompile(`^php-fpm(\d+\.\d+)$`)
+	semverRe        = regexp.MustCompile(`(\d+)\.(\d+)(?:\.\d+)?`)
+)
+
+// DetectInstalled reports the runtime versions installed on this node. PHP
+// may have several versions installed side-by-side; Node.js, Python and Ruby
+// are single system installs reported as major (Node.js) or major.minor
+// (Python, Ruby) versions. Runtimes whose binary is missing are omitted.
+func DetectInstalled(ctx context.Context) []model.InstalledRuntime {
+	var installed []model.InstalledRuntime
+
+	paths, _ := filepath.Glob(phpFPMGlob)
+	for _, v := range parsePHPFPMBinaries(paths) {
+		installed = append(installed, model.InstalledRuntime{
+			Runtime:    model.RuntimePHP,
+			Version:    v,
+			Extensions: detectPHPExtensions(v),
+		})
+	}
+
+	if out, err := versionOutput(ctx, "node", "--version"); err == nil {
+		if major, _, ok := parseVersion(out); ok {
+			installed = append(installed, model.InstalledRuntime{Runtime: model.RuntimeNode, Version: major})
+		}
+	}
+	if out, err := versionOutput(ctx, "python3", "--version"); err == nil {
+		if major, minor, ok := parseVersion(out); ok {
+			installed = append(installed, model.InstalledRuntime{Runtime: model.RuntimePython, Version: major + "." + minor})
+		}
+	}
+	if out, err := versionOutput(ctx, "ruby", "--version"); err == nil {
+		if major, minor, ok := parseVersion(out); ok {
+			installed = append(installed, model.InstalledRuntime{Runtime: model.RuntimeRuby, Version: major + "." + minor})
+		}
+	}
+
+	return installed
+}
+
+// detectPHPExtensions reports the toggleable extensions installed for a PHP
+// version. Built-in extensions toggled by flag ship with every PHP build and
+// are always reported.
+func detectPHPExtensions(version string) []string {
+	dir := fmt.Sprintf(phpModsAvailableDir, version)
+	var exts []string
+	for _, name := range PHPToggleableExtensions() {
+		ext := phpToggleableExtensions[name]
+		if ext.flag != "" {
+			exts = append(exts, name)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, ext.module+".ini")); err == nil {
+			exts = append(exts, name)
+		}
+	}
+	return exts
+}
+
+// parsePHPFPMBinaries extracts the sorted PHP versions from php-fpm binary paths.
+func parsePHPFPMBinaries(paths []string) []string {
+	var versions []string
+	for _, p := range paths {
+		if m := phpFPMVersionRe.FindStringSubmatch(filepath.Base(p)); m != nil {
+			versions = append(versions, m[1])
+		}
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// parseVersion extracts the major and minor version from version command
+// output such as "v22.11.0", "Python 3.12.3" or "ruby 3.3.0 (2023-12-25) ...".
+func parseVersion(out string) (major, minor string, ok bool) {
+	m := semverRe.FindStringSubmatch(strings.TrimSpace(out))
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
@@ -0,0 +1,75 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func TestParsePHPFPMBinaries(t *testing.T) {
+	versions := parsePHPFPMBinaries([]string{
+		"/usr/sbin/php-fpm8.5",
+		"/usr/sbin/php-fpm8.3",
+		"/usr/sbin/php-fpm",
+		"/usr/sbin/php-fpm8.3.dpkg-old",
+	})
+	assert.Equal(t, []string{"8.3", "8.5"}, versions)
+}
+
+func TestParseVersion(t *testing.T) {
+	for _, tc := range []struct {
+		out, major, minor string
+	}{
+		{"v22.11.0\n", "22", "11"},
+		{"Python 3.12.3\n", "3", "12"},
+		{"ruby 3.3.0 (2023-12-25 revision 5124f9ac75) [x86_64-linux]\n", "3", "3"},
+	} {
+		major, minor, ok := parseVersion(tc.out)
+		assert.True(t, ok, tc.out)
+		assert.Equal(t, tc.major, major, tc.out)
+		assert.Equal(t, tc.minor, minor, tc.out)
+	}
+
+	_, _, ok := parseVersion("command not found")
+	assert.False(t, ok)
+}
+
+func TestDetectInstalled(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"php-fpm8.3", "php-fpm8.5"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0755))
+	}
+
+	modsDir := filepath.Join(dir, "8.3", "mods-available")
+	assert.NoError(t, os.MkdirAll(modsDir, 0755))
+	for _, name := range []string{"imagick.ini", "mysqli.ini"} {
+		assert.NoError(t, os.WriteFile(filepath.Join(modsDir, name), nil, 0644))
+	}
+
+	origGlob, origMods, origVersion := phpFPMGlob, phpModsAvailableDir, versionOutput
+	t.Cleanup(func() { phpFPMGlob, phpModsAvailableDir, versionOutput = origGlob, origMods, origVersion })
+	phpFPMGlob = filepath.Join(dir, "php-fpm*")
+	phpModsAvailableDir = filepath.Join(dir, "%s", "mods-available")
+	versionOutput = func(_ context.Context, name string, _ ...string) (string, error) {
+		switch name {
+		case "node":
+			return "v22.11.0\n", nil
+		case "python3":
+			return "Python 3.12.3\n", nil
+		}
+		return "", errors.New("not installed")
+	}
+
+	assert.Equal(t, []model.InstalledRuntime{
+		{Runtime: "php", Version: "8.3", Extensions: []string{"imagick", "opcache"}},
+		{Runtime: "php", Version: "8.5", Extensions: []string{"opcache"}},
+		{Runtime: "node", Version: "22"},
+		{Runtime: "python", Version: "3.12"},
+	}, DetectInstalled(context.Background()))
+}
@@ -1,6 +1,11 @@
 package runtime
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+)
 
 // WebrootInfo holds the information needed to configure a runtime for a webroot.
 type WebrootInfo struct {
@@ -11,7 +16,24 @@ type WebrootInfo struct {
 	RuntimeVersion string
 	RuntimeConfig  string
 	PublicFolder   string
+	ErrorPages     map[int]string // status code -> path relative to the webroot storage dir
 	EnvVars        map[string]string
+	BasicAuth      map[string]string // username -> bcrypt hash; protects the site when non-empty
+	// AccessLog also writes the access log to the tenant's shared logs dir,
+	// where it can be read back through the API.
+	AccessLog bool
+	// Limits caps connections and request rate per client IP in nginx.
+	Limits model.WebrootConnectionLimits
+	// StaticRules adds caching headers and MIME types in nginx.
+	StaticRules model.WebrootStaticRules
+	// GeoBlocking restricts access by client country in nginx.
+	GeoBlocking model.WebrootGeoBlocking
+	// DeployLock makes nginx answer 503 with Retry-After until then, except
+	// for ACME challenges. Nil, or a time already past, means not locked.
+	DeployLock *time.Time
+	// Releases is set when the webroot is deployed as releases; the app is
+	// then served from its current release symlink (see AppDir).
+	Releases bool
 }
 
 // Manager defines the interface for language-specific runtime management.
@@ -78,7 +78,7 @@ func computePort(tenant, webroot string) uint32 {
 func (n *Node) Configure(ctx context.Context, webroot *WebrootInfo) error {
 	port := computePort(webroot.TenantName, webroot.Name)
 	entryPoint := "index.js"
-	workingDir := filepath.Join("/var/www/storage", webroot.TenantName, "webroots", webroot.Name)
+	workingDir := AppDir(filepath.Join("/var/www/storage", webroot.TenantName, "webroots", webroot.Name), webroot.Releases)
 
 	data := nodeServiceData{
 		TenantName:  webroot.TenantName,
@@ -35,7 +35,8 @@ php_admin_value[slowlog] = /var/log/hosting/{{ .TenantID }}/php-slow.log
 php_admin_value[request_slowlog_timeout] = 5s
 php_admin_flag[log_errors] = on
 php_admin_value[open_basedir] = /var/www/storage/{{ .TenantName }}/:/tmp/
-{{ range .PHPValues }}php_value[{{ .Key }}] = {{ .Value }}
+{{ range .Extensions }}{{ . }}
+{{ end }}{{ range .PHPValues }}php_value[{{ .Key }}] = {{ .Value }}
 {{ end }}{{ range .PHPAdminValues }}php_admin_value[{{ .Key }}] = {{ .Value }}
 {{ end }}{{ range .EnvVars }}env[{{ .Key }}] = {{ .Value }}
 {{ end }}`
@@ -50,6 +51,37 @@ var phpBlocklistedAdminKeys = map[string]bool{
 	"slowlog":           true,
 	"disable_functions": true,
 	"doc_root":          true,
+	"extension":         true,
+	"zend_extension":    true,
+}
+
+// phpExtension describes how a toggleable extension is switched per pool.
+// Loadable extensions are installed on web nodes but disabled for FPM
+// globally, so enabling one loads it into the pool with
+// php_admin_value[extension]. Built-in extensions are toggled via their
+// enable flag instead.
+type phpExtension struct {
+	module string
+	flag   string
+}
+
+// phpToggleableExtensions is the allowlist of extensions tenants may enable
+// or disable in runtime_config.
+var phpToggleableExtensions = map[string]phpExtension{
+	"apcu":    {module: "apcu"},
+	"imagick": {module: "imagick"},
+	"opcache": {flag: "opcache.enable"},
+}
+
+// PHPToggleableExtensions returns the sorted names of the extensions that can
+// be toggled per webroot.
+func PHPToggleableExtensions() []string {
+	names := make([]string, 0, len(phpToggleableExtensions))
+	for name := range phpToggleableExtensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 // PHPRuntimeConfig represents the parsed runtime_config JSON for PHP webroots.
@@ -57,6 +89,7 @@ type PHPRuntimeConfig struct {
 	PM             *PHPPMConfig      `json:"pm,omitempty"`
 	PHPValues      map[string]string `json:"php_values,omitempty"`
 	PHPAdminValues map[string]string `json:"php_admin_values,omitempty"`
+	Extensions     map[string]bool   `json:"extensions,omitempty"`
 }
 
 // PHPPMConfig holds PHP-FPM process manager settings.
@@ -104,9 +137,38 @@ func ValidatePHPRuntimeConfig(raw json.RawMessage) error {
 		}
 	}
 
+	for name := range cfg.Extensions {
+		if _, ok := phpToggleableExtensions[name]; !ok {
+			return fmt.Errorf("unknown php extension %q; toggleable extensions: %s", name, strings.Join(PHPToggleableExtensions(), ", "))
+		}
+	}
+
 	return nil
 }
 
+// phpExtensionLines renders the pool config lines for the extension toggles,
+// sorted by extension name. Disabling a loadable extension needs no line
+// since it is not loaded unless enabled.
+func phpExtensionLines(extensions map[string]bool) []string {
+	var lines []string
+	for _, name := range sortedKeys(extensions) {
+		ext, ok := phpToggleableExtensions[name]
+		if !ok {
+			continue
+		}
+		enabled := extensions[name]
+		switch {
+		case ext.flag != "" && enabled:
+			lines = append(lines, fmt.Sprintf("php_admin_flag[%s] = on", ext.flag))
+		case ext.flag != "":
+			lines = append(lines, fmt.Sprintf("php_admin_flag[%s] = off", ext.flag))
+		case enabled:
+			lines = append(lines, fmt.Sprintf("php_admin_value[extension] = %s.so", ext.module))
+		}
+	}
+	return lines
+}
+
 func validatePMRange(name string, val *int, min, max int) error {
 	if val == nil {
 		return nil
@@ -132,6 +194,7 @@ type phpPoolData struct {
 	MinSpareServers int
 	MaxSpareServers int
 	MaxRequests     int
+	Extensions      []string
 	PHPValues       []kvPair
 	PHPAdminValues  []kvPair
 	EnvVars         []kvPair
@@ -191,6 +254,7 @@ func (p *PHP) Configure(ctx context.Context, webroot *WebrootInfo) error {
 		MinSpareServers: intOrDefault(cfg.PM, func(pm *PHPPMConfig) *int { return pm.MinSpareServers }, 1),
 		MaxSpareServers: intOrDefault(cfg.PM, func(pm *PHPPMConfig) *int { return pm.MaxSpareServers }, 3),
 		MaxRequests:     intOrDefault(cfg.PM, func(pm *PHPPMConfig) *int { return pm.MaxRequests }, 500),
+		Extensions:      phpExtensionLines(cfg.Extensions),
 		PHPValues:       sortedKVPairs(cfg.PHPValues),
 		PHPAdminValues:  sortedKVPairs(cfg.PHPAdminValues),
 		EnvVars:         sortedKVPairs(webroot.EnvVars),
@@ -365,6 +429,15 @@ func intOrDefault(pm *PHPPMConfig, getter func(*PHPPMConfig) *int, def int) int
 	return *v
 }
 
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 func sortedKVPairs(m map[string]string) []kvPair {
 	if len(m) == 0 {
 		return nil
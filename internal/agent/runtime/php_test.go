@@ -85,6 +85,34 @@ func TestPHP_PoolConfigTemplate_DifferentVersion(t *testing.T) {
 	assert.Contains(t, config, "user = user2")
 }
 
+func TestPHP_PoolConfigTemplate_Extensions(t *testing.T) {
+	data := phpPoolData{
+		TenantName: "tenant1",
+		TenantID:   "tenant1",
+		Version:    "8.3",
+		Extensions: phpExtensionLines(map[string]bool{"opcache": false, "imagick": true, "apcu": false}),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, phpPoolTmpl.Execute(&buf, data))
+	config := buf.String()
+
+	assert.Contains(t, config, "php_admin_value[extension] = imagick.so\nphp_admin_flag[opcache.enable] = off\n")
+	assert.NotContains(t, config, "apcu")
+}
+
+func TestValidatePHPRuntimeConfig_Extensions(t *testing.T) {
+	assert.NoError(t, ValidatePHPRuntimeConfig([]byte(`{"extensions":{"imagick":true,"opcache":false}}`)))
+
+	err := ValidatePHPRuntimeConfig([]byte(`{"extensions":{"xdebug":true}}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown php extension "xdebug"; toggleable extensions: apcu, imagick, opcache`)
+
+	err = ValidatePHPRuntimeConfig([]byte(`{"php_admin_values":{"extension":"xdebug.so"}}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "managed by the platform")
+}
+
 func TestPHP_PoolConfigPath(t *testing.T) {
 	p := NewPHP(zerolog.Nop(), NewDirectManager(zerolog.Nop()))
 
@@ -70,7 +70,7 @@ func (p *Python) unitFilePath(webroot *WebrootInfo) string {
 // Configure generates and writes a systemd service unit for the Gunicorn application.
 func (p *Python) Configure(ctx context.Context, webroot *WebrootInfo) error {
 	wsgiModule := "app:application"
-	workingDir := filepath.Join("/var/www/storage", webroot.TenantName, "webroots", webroot.Name)
+	workingDir := AppDir(filepath.Join("/var/www/storage", webroot.TenantName, "webroots", webroot.Name), webroot.Releases)
 
 	data := pythonServiceData{
 		TenantName:  webroot.TenantName,
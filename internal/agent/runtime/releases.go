@@ -0,0 +1,33 @@
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+)
+
+const (
+	// ReleasesDir is the directory inside a webroot's storage dir that holds
+	// one subdirectory per release.
+	ReleasesDir = "releases"
+
+	// CurrentRelease is the symlink inside a webroot's storage dir that points
+	// at the live release. Promotion replaces it with a single rename(2).
+	CurrentRelease = "current"
+)
+
+// HasReleases reports whether the webroot at webrootDir has a promoted
+// release, i.e. whether its current symlink exists.
+func HasReleases(webrootDir string) bool {
+	fi, err := os.Lstat(filepath.Join(webrootDir, CurrentRelease))
+	return err == nil && fi.Mode()&os.ModeSymlink != 0
+}
+
+// AppDir returns the directory a webroot's application is served from. With
+// releases that is the current symlink, not the release it points at, so
+// nginx and the runtime follow a promotion without being reconfigured.
+func AppDir(webrootDir string, releases bool) string {
+	if releases {
+		return filepath.Join(webrootDir, CurrentRelease)
+	}
+	return webrootDir
+}
@@ -68,7 +68,7 @@ func (r *Ruby) unitFilePath(webroot *WebrootInfo) string {
 
 // Configure generates and writes a systemd service unit for the Puma application.
 func (r *Ruby) Configure(ctx context.Context, webroot *WebrootInfo) error {
-	workingDir := filepath.Join("/var/www/storage", webroot.TenantName, "webroots", webroot.Name)
+	workingDir := AppDir(filepath.Join("/var/www/storage", webroot.TenantName, "webroots", webroot.Name), webroot.Releases)
 
 	data := rubyServiceData{
 		TenantName:  webroot.TenantName,
@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"github.com/rs/zerolog"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
 )
 
 // ServiceManager abstracts system service management so that runtime managers
@@ -128,7 +130,7 @@ func (d *DirectManager) Signal(ctx context.Context, process, signal string) erro
 	pidFile := filepath.Join("/run/php", process+".pid")
 	if data, err := os.ReadFile(pidFile); err == nil {
 		pid := strings.TrimSpace(string(data))
-		cmd := exec.CommandContext(ctx, "kill", "-"+signal, pid)
+		cmd := cmdaudit.CommandContext(ctx, "kill", "-"+signal, pid)
 		if err := cmd.Run(); err == nil {
 			d.logger.Debug().Str("process", process).Str("signal", signal).Str("pid", pid).Msg("signalled via PID file")
 			return nil
@@ -147,7 +149,7 @@ func (d *DirectManager) Signal(ctx context.Context, process, signal string) erro
 // ---------------------------------------------------------------------------
 
 func sysctl(ctx context.Context, args ...string) error {
-	cmd := exec.CommandContext(ctx, "systemctl", args...)
+	cmd := cmdaudit.CommandContext(ctx, "systemctl", args...)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("systemctl %v: %s: %w", args, string(output), err)
 	}
@@ -155,7 +157,7 @@ func sysctl(ctx context.Context, args ...string) error {
 }
 
 func pkillSignal(ctx context.Context, process, signal string) error {
-	cmd := exec.CommandContext(ctx, "pkill", "-"+signal, "-f", process)
+	cmd := cmdaudit.CommandContext(ctx, "pkill", "-"+signal, "-f", process)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		// pkill exit code 1 means no processes matched — not an error.
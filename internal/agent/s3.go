@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -12,6 +11,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/rs/zerolog"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/logging"
+	"github.com/edvin/hosting/internal/model"
 )
 
 // S3Manager handles S3 object storage operations via radosgw-admin CLI
@@ -26,7 +29,7 @@ type S3Manager struct {
 // NewS3Manager creates a new S3Manager.
 func NewS3Manager(logger zerolog.Logger, endpoint, adminKey, adminSecret string) *S3Manager {
 	return &S3Manager{
-		logger:      logger.With().Str("component", "s3-manager").Logger(),
+		logger:      logging.Component(logger, "s3-manager"),
 		endpoint:    endpoint,
 		adminKey:    adminKey,
 		adminSecret: adminSecret,
@@ -45,7 +48,7 @@ func (m *S3Manager) s3Client() *s3.Client {
 
 // execRGWAdmin runs a radosgw-admin command and returns the combined output.
 func (m *S3Manager) execRGWAdmin(ctx context.Context, args ...string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, "radosgw-admin", args...)
+	cmd := cmdaudit.CommandContext(ctx, "radosgw-admin", args...)
 	m.logger.Debug().Strs("args", args).Msg("executing radosgw-admin")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -223,6 +226,57 @@ func (m *S3Manager) CreateAccessKey(ctx context.Context, tenantID, accessKey, se
 	return nil
 }
 
+// RotateAccessKey replaces the secret of an existing S3 access key, keeping
+// its access key ID. radosgw-admin "key create" with an access key the user
+// already owns modifies that key in place, so the old secret stops working
+// as soon as it returns. The key is read back to confirm the new secret took
+// effect; running it again with the same secret is a no-op.
+func (m *S3Manager) RotateAccessKey(ctx context.Context, tenantID, accessKey, secretKey string) error {
+	m.logger.Info().Str("tenant", tenantID).Str("access_key", accessKey).Msg("rotating S3 access key secret")
+
+	if _, err := m.execRGWAdmin(ctx, "key", "create",
+		"--uid="+tenantID,
+		"--access-key="+accessKey,
+		"--secret-key="+secretKey,
+		"--key-type=s3",
+	); err != nil {
+		return fmt.Errorf("rotate access key %s: %w", accessKey, err)
+	}
+
+	out, err := m.execRGWAdmin(ctx, "user", "info", "--uid="+tenantID)
+	if err != nil {
+		return fmt.Errorf("read back access key %s: %w", accessKey, err)
+	}
+	current, err := rgwUserSecret(out, accessKey)
+	if err != nil {
+		return err
+	}
+	if current != secretKey {
+		return fmt.Errorf("access key %s still has its old secret after rotation", accessKey)
+	}
+	return nil
+}
+
+// rgwUserSecret returns the secret of an S3 access key from radosgw-admin
+// "user info" output.
+func rgwUserSecret(userInfo []byte, accessKey string) (string, error) {
+	var info struct {
+		Keys []struct {
+			AccessKey string `json:"access_key"`
+			SecretKey string `json:"secret_key"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(userInfo, &info); err != nil {
+		return "", fmt.Errorf("parse RGW user info: %w", err)
+	}
+	for _, k := range info.Keys {
+		if k.AccessKey == accessKey {
+			return k.SecretKey, nil
+		}
+	}
+	return "", fmt.Errorf("access key %s not found on RGW user", accessKey)
+}
+
 // DeleteAccessKey removes an S3 access key from the RGW user.
 // This operation is idempotent: if the key has already been deleted, it is
 // treated as success.
@@ -300,3 +354,121 @@ func (m *S3Manager) SetBucketPolicy(ctx context.Context, tenantID, name string,
 
 	return nil
 }
+
+// SetBucketLifecycle converges the bucket's lifecycle configuration to rules.
+// An empty rule set removes the configuration. The current configuration is
+// read first and left untouched when it already matches, so re-applying the
+// same policy does not rewrite it.
+func (m *S3Manager) SetBucketLifecycle(ctx context.Context, name string, rules []model.S3LifecycleRule) error {
+	client := m.s3Client()
+
+	current, err := client.GetBucketLifecycleConfiguration(ctx, &s3.GetBucketLifecycleConfigurationInput{
+		Bucket: aws.String(name),
+	})
+	var currentRules []s3types.LifecycleRule
+	if err != nil {
+		if !strings.Contains(err.Error(), "NoSuchLifecycleConfiguration") {
+			return fmt.Errorf("get bucket lifecycle %s: %w", name, err)
+		}
+	} else {
+		currentRules = current.Rules
+	}
+
+	desired := lifecycleRulesFromModel(rules)
+	if lifecycleRulesEqual(currentRules, desired) {
+		m.logger.Debug().Str("bucket", name).Msg("S3 bucket lifecycle already up to date")
+		return nil
+	}
+
+	if len(desired) == 0 {
+		m.logger.Info().Str("bucket", name).Msg("removing S3 bucket lifecycle")
+		_, err := client.DeleteBucketLifecycle(ctx, &s3.DeleteBucketLifecycleInput{
+			Bucket: aws.String(name),
+		})
+		if err != nil && !strings.Contains(err.Error(), "NoSuchLifecycleConfiguration") {
+			return fmt.Errorf("delete bucket lifecycle %s: %w", name, err)
+		}
+		return nil
+	}
+
+	m.logger.Info().Str("bucket", name).Int("rules", len(desired)).Msg("setting S3 bucket lifecycle")
+	_, err = client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+		Bucket:                 aws.String(name),
+		LifecycleConfiguration: &s3types.BucketLifecycleConfiguration{Rules: desired},
+	})
+	if err != nil {
+		return fmt.Errorf("set bucket lifecycle %s: %w", name, err)
+	}
+	return nil
+}
+
+// lifecycleRulesFromModel converts stored rules to the S3 API representation.
+func lifecycleRulesFromModel(rules []model.S3LifecycleRule) []s3types.LifecycleRule {
+	out := make([]s3types.LifecycleRule, 0, len(rules))
+	for _, r := range rules {
+		rule := s3types.LifecycleRule{
+			ID:     aws.String(r.ID),
+			Status: s3types.ExpirationStatusEnabled,
+			Filter: &s3types.LifecycleRuleFilter{Prefix: aws.String(r.Prefix)},
+		}
+		if r.ExpirationDays > 0 {
+			rule.Expiration = &s3types.LifecycleExpiration{Days: aws.Int32(int32(r.ExpirationDays))}
+		}
+		if r.AbortIncompleteMultipartUploadDays > 0 {
+			rule.AbortIncompleteMultipartUpload = &s3types.AbortIncompleteMultipartUpload{
+				DaysAfterInitiation: aws.Int32(int32(r.AbortIncompleteMultipartUploadDays)),
+			}
+		}
+		out = append(out, rule)
+	}
+	return out
+}
+
+// lifecycleRuleKey reduces a rule to the fields this manager sets, so rules
+// read back from RGW compare equal to the ones that were written.
+type lifecycleRuleKey struct {
+	id, prefix            string
+	enabled               bool
+	expireDays, abortDays int32
+}
+
+func keyForLifecycleRule(r s3types.LifecycleRule) lifecycleRuleKey {
+	k := lifecycleRuleKey{
+		id:      aws.ToString(r.ID),
+		enabled: r.Status == s3types.ExpirationStatusEnabled,
+	}
+	// RGW may return the prefix either in the filter or in the deprecated
+	// rule-level field, depending on how the rule was written.
+	if r.Filter != nil && r.Filter.Prefix != nil {
+		k.prefix = *r.Filter.Prefix
+	} else if r.Prefix != nil {
+		k.prefix = *r.Prefix
+	}
+	if r.Expiration != nil {
+		k.expireDays = aws.ToInt32(r.Expiration.Days)
+	}
+	if r.AbortIncompleteMultipartUpload != nil {
+		k.abortDays = aws.ToInt32(r.AbortIncompleteMultipartUpload.DaysAfterInitiation)
+	}
+	return k
+}
+
+// lifecycleRulesEqual reports whether two rule sets are the same regardless
+// of order.
+func lifecycleRulesEqual(a, b []s3types.LifecycleRule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[lifecycleRuleKey]int, len(a))
+	for _, r := range a {
+		seen[keyForLifecycleRule(r)]++
+	}
+	for _, r := range b {
+		k := keyForLifecycleRule(r)
+		if seen[k] == 0 {
+			return false
+		}
+		seen[k]--
+	}
+	return true
+}
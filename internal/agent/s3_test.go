@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func TestLifecycleRulesFromModel(t *testing.T) {
+	rules := lifecycleRulesFromModel([]model.S3LifecycleRule{
+		{ID: "tmp", Prefix: "tmp/", ExpirationDays: 7},
+		{ID: "uploads", AbortIncompleteMultipartUploadDays: 2},
+	})
+	require.Len(t, rules, 2)
+
+	assert.Equal(t, "tmp", aws.ToString(rules[0].ID))
+	assert.Equal(t, s3types.ExpirationStatusEnabled, rules[0].Status)
+	assert.Equal(t, "tmp/", aws.ToString(rules[0].Filter.Prefix))
+	assert.Equal(t, int32(7), aws.ToInt32(rules[0].Expiration.Days))
+	assert.Nil(t, rules[0].AbortIncompleteMultipartUpload)
+
+	assert.Equal(t, "", aws.ToString(rules[1].Filter.Prefix))
+	assert.Nil(t, rules[1].Expiration)
+	assert.Equal(t, int32(2), aws.ToInt32(rules[1].AbortIncompleteMultipartUpload.DaysAfterInitiation))
+}
+
+func TestLifecycleRulesEqual_SameRulesAnyOrder(t *testing.T) {
+	desired := lifecycleRulesFromModel([]model.S3LifecycleRule{
+		{ID: "a", Prefix: "a/", ExpirationDays: 1},
+		{ID: "b", Prefix: "b/", ExpirationDays: 2},
+	})
+	current := []s3types.LifecycleRule{desired[1], desired[0]}
+	assert.True(t, lifecycleRulesEqual(current, desired))
+}
+
+func TestLifecycleRulesEqual_DeprecatedPrefixField(t *testing.T) {
+	desired := lifecycleRulesFromModel([]model.S3LifecycleRule{{ID: "a", Prefix: "a/", ExpirationDays: 1}})
+	current := []s3types.LifecycleRule{{
+		ID:         aws.String("a"),
+		Prefix:     aws.String("a/"),
+		Status:     s3types.ExpirationStatusEnabled,
+		Expiration: &s3types.LifecycleExpiration{Days: aws.Int32(1)},
+	}}
+	assert.True(t, lifecycleRulesEqual(current, desired))
+}
+
+func TestLifecycleRulesEqual_Differences(t *testing.T) {
+	desired := lifecycleRulesFromModel([]model.S3LifecycleRule{{ID: "a", Prefix: "a/", ExpirationDays: 1}})
+
+	changedDays := lifecycleRulesFromModel([]model.S3LifecycleRule{{ID: "a", Prefix: "a/", ExpirationDays: 2}})
+	assert.False(t, lifecycleRulesEqual(changedDays, desired))
+
+	disabled := lifecycleRulesFromModel([]model.S3LifecycleRule{{ID: "a", Prefix: "a/", ExpirationDays: 1}})
+	disabled[0].Status = s3types.ExpirationStatusDisabled
+	assert.False(t, lifecycleRulesEqual(disabled, desired))
+
+	assert.False(t, lifecycleRulesEqual(nil, desired))
+	assert.True(t, lifecycleRulesEqual(nil, lifecycleRulesFromModel(nil)))
+}
+
+func TestRGWUserSecret(t *testing.T) {
+	out := []byte(`{"user_id":"t1","keys":[{"user":"t1","access_key":"AK1","secret_key":"old"},{"user":"t1","access_key":"AK2","secret_key":"new"}]}`)
+
+	secret, err := rgwUserSecret(out, "AK2")
+	require.NoError(t, err)
+	assert.Equal(t, "new", secret)
+
+	_, err = rgwUserSecret(out, "AK3")
+	assert.ErrorContains(t, err, "not found")
+
+	_, err = rgwUserSecret([]byte("not json"), "AK1")
+	assert.Error(t, err)
+}
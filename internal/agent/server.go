@@ -4,6 +4,7 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/edvin/hosting/internal/agent/runtime"
+	"github.com/edvin/hosting/internal/logging"
 )
 
 // Config holds the configuration for the node agent server.
@@ -15,6 +16,9 @@ type Config struct {
 	NginxListenPort    string // Port for nginx listen directives (default "80")
 	WebStorageDir      string
 	CertDir            string
+	// GeoIPDatabasePath is the GeoIP2 country database used for webroot
+	// geo-blocking (default /var/lib/GeoIP/GeoLite2-Country.mmdb).
+	GeoIPDatabasePath string
 	ValkeyConfigDir    string
 	ValkeyDataDir      string
 	// InitSystem selects the service manager implementation.
@@ -66,7 +70,7 @@ func NewServer(logger zerolog.Logger, cfg Config) *Server {
 	}
 
 	return &Server{
-		logger:   logger.With().Str("component", "agent-server").Logger(),
+		logger:   logging.Component(logger, "agent-server"),
 		tenant:   NewTenantManager(logger, cfg),
 		webroot:  NewWebrootManager(logger, cfg),
 		nginx:    nginxMgr,
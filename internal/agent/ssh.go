@@ -4,13 +4,15 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"os/user"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/logging"
 )
 
 const (
@@ -35,7 +37,7 @@ type SSHManager struct {
 // NewSSHManager creates a new SSHManager.
 func NewSSHManager(logger zerolog.Logger, webStorageDir string) *SSHManager {
 	return &SSHManager{
-		logger:        logger.With().Str("component", "ssh-manager").Logger(),
+		logger:        logging.Component(logger, "ssh-manager"),
 		webStorageDir: webStorageDir,
 	}
 }
@@ -97,7 +99,7 @@ func (m *SSHManager) RemoveConfig(ctx context.Context, name string) error {
 
 // addToGroup adds a user to a Linux group.
 func (m *SSHManager) addToGroup(ctx context.Context, user, group string) error {
-	cmd := exec.CommandContext(ctx, "gpasswd", "-a", user, group)
+	cmd := cmdaudit.CommandContext(ctx, "gpasswd", "-a", user, group)
 	m.logger.Debug().Strs("cmd", cmd.Args).Msg("adding user to group")
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("add %s to group %s: %s: %w", user, group, string(output), err)
@@ -108,7 +110,7 @@ func (m *SSHManager) addToGroup(ctx context.Context, user, group string) error {
 // removeFromGroup removes a user from a Linux group. Errors are ignored
 // (the user may not be in the group).
 func (m *SSHManager) removeFromGroup(ctx context.Context, user, group string) {
-	cmd := exec.CommandContext(ctx, "gpasswd", "-d", user, group)
+	cmd := cmdaudit.CommandContext(ctx, "gpasswd", "-d", user, group)
 	_ = cmd.Run()
 }
 
@@ -152,7 +154,7 @@ func (m *SSHManager) setupChrootBindMounts(ctx context.Context, name, chrootDir
 			continue
 		}
 
-		cmd := exec.CommandContext(ctx, "mount", "--bind", "-o", "ro", dir, target)
+		cmd := cmdaudit.CommandContext(ctx, "mount", "--bind", "-o", "ro", dir, target)
 		m.logger.Debug().Strs("cmd", cmd.Args).Msg("bind mounting")
 		if output, err := cmd.CombinedOutput(); err != nil {
 			return fmt.Errorf("mount --bind %s %s: %s: %w", dir, target, string(output), err)
@@ -183,7 +185,7 @@ func (m *SSHManager) setupChrootBindMounts(ctx context.Context, name, chrootDir
 		if _, err := os.Stat(path); err == nil {
 			continue // Already exists.
 		}
-		cmd := exec.CommandContext(ctx, "mknod", path, "c",
+		cmd := cmdaudit.CommandContext(ctx, "mknod", path, "c",
 			fmt.Sprintf("%d", dn.major), fmt.Sprintf("%d", dn.minor))
 		m.logger.Debug().Strs("cmd", cmd.Args).Msg("creating dev node")
 		if output, err := cmd.CombinedOutput(); err != nil {
@@ -200,7 +202,7 @@ func (m *SSHManager) setupChrootBindMounts(ctx context.Context, name, chrootDir
 		return fmt.Errorf("mkdir dev/pts: %w", err)
 	}
 	if !mounted[ptsDir] {
-		cmd := exec.CommandContext(ctx, "mount", "--bind", "/dev/pts", ptsDir)
+		cmd := cmdaudit.CommandContext(ctx, "mount", "--bind", "/dev/pts", ptsDir)
 		m.logger.Debug().Strs("cmd", cmd.Args).Msg("bind mounting /dev/pts")
 		if output, err := cmd.CombinedOutput(); err != nil {
 			m.logger.Warn().Str("output", string(output)).Err(err).Msg("/dev/pts bind mount failed")
@@ -215,7 +217,7 @@ func (m *SSHManager) setupChrootBindMounts(ctx context.Context, name, chrootDir
 		return fmt.Errorf("mkdir proc: %w", err)
 	}
 	if !mounted[procDir] {
-		cmd := exec.CommandContext(ctx, "mount", "-t", "proc", "proc", procDir,
+		cmd := cmdaudit.CommandContext(ctx, "mount", "-t", "proc", "proc", procDir,
 			"-o", "hidepid=2")
 		m.logger.Debug().Strs("cmd", cmd.Args).Msg("mounting proc")
 		if output, err := cmd.CombinedOutput(); err != nil {
@@ -249,7 +251,7 @@ func (m *SSHManager) setupChrootBindMounts(ctx context.Context, name, chrootDir
 			return fmt.Errorf("mkdir etc/%s: %w", sub, err)
 		}
 		if !mounted[target] {
-			cmd := exec.CommandContext(ctx, "mount", "--bind", "-o", "ro", src, target)
+			cmd := cmdaudit.CommandContext(ctx, "mount", "--bind", "-o", "ro", src, target)
 			m.logger.Debug().Strs("cmd", cmd.Args).Msg("bind mounting")
 			if output, err := cmd.CombinedOutput(); err != nil {
 				m.logger.Warn().Str("output", string(output)).Err(err).Msg("bind mount failed")
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -13,6 +12,9 @@ import (
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/logging"
 )
 
 // TenantManager handles Linux user account management for hosting tenants.
@@ -25,7 +27,7 @@ type TenantManager struct {
 // NewTenantManager creates a new TenantManager.
 func NewTenantManager(logger zerolog.Logger, cfg Config) *TenantManager {
 	return &TenantManager{
-		logger:        logger.With().Str("component", "tenant-manager").Logger(),
+		logger:        logging.Component(logger, "tenant-manager"),
 		webStorageDir: cfg.WebStorageDir,
 	}
 }
@@ -66,7 +68,7 @@ func (m *TenantManager) Create(ctx context.Context, info *TenantInfo) error {
 		Msg("creating tenant user")
 
 	// Check if the user already exists.
-	checkCmd := exec.CommandContext(ctx, "id", name)
+	checkCmd := cmdaudit.CommandContext(ctx, "id", name)
 	if err := checkCmd.Run(); err != nil {
 		// User does not exist — create it.
 		if err := m.createUser(ctx, name, uid); err != nil {
@@ -100,7 +102,7 @@ func (m *TenantManager) Create(ctx context.Context, info *TenantInfo) error {
 
 	// Set ownership of tenant-owned CephFS directories.
 	for _, dir := range []string{homeDir, filepath.Join(chrootDir, "webroots"), filepath.Join(chrootDir, "tmp")} {
-		chownCmd := exec.CommandContext(ctx, "chown", fmt.Sprintf("%s:%s", name, name), dir)
+		chownCmd := cmdaudit.CommandContext(ctx, "chown", fmt.Sprintf("%s:%s", name, name), dir)
 		m.logger.Debug().Strs("cmd", chownCmd.Args).Msg("executing chown")
 		if output, err := chownCmd.CombinedOutput(); err != nil {
 			return status.Errorf(codes.Internal, "chown failed for %s: %s: %v", dir, string(output), err)
@@ -115,7 +117,7 @@ func (m *TenantManager) Create(ctx context.Context, info *TenantInfo) error {
 	if err := os.Chmod(logDir, 0750); err != nil {
 		return status.Errorf(codes.Internal, "chmod log dir %s: %v", logDir, err)
 	}
-	chownCmd := exec.CommandContext(ctx, "chown", fmt.Sprintf("%s:%s", name, name), logDir)
+	chownCmd := cmdaudit.CommandContext(ctx, "chown", fmt.Sprintf("%s:%s", name, name), logDir)
 	m.logger.Debug().Strs("cmd", chownCmd.Args).Msg("executing chown")
 	if output, err := chownCmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.Internal, "chown failed for %s: %s: %v", logDir, string(output), err)
@@ -137,7 +139,7 @@ func (m *TenantManager) Create(ctx context.Context, info *TenantInfo) error {
 func (m *TenantManager) createUser(ctx context.Context, name string, uid int32) error {
 	// -M: don't create home (we manage CephFS dirs ourselves)
 	// -d /home: chroot-relative home path (what user sees after chroot)
-	cmd := exec.CommandContext(ctx, "useradd",
+	cmd := cmdaudit.CommandContext(ctx, "useradd",
 		"-M",
 		"-d", "/home",
 		"-u", strconv.FormatInt(int64(uid), 10),
@@ -159,7 +161,7 @@ func (m *TenantManager) createUser(ctx context.Context, name string, uid int32)
 	if strings.Contains(outStr, "already exists") {
 		staleUser = name
 	} else if strings.Contains(outStr, "UID") && strings.Contains(outStr, "not unique") {
-		getentCmd := exec.CommandContext(ctx, "getent", "passwd", strconv.FormatInt(int64(uid), 10))
+		getentCmd := cmdaudit.CommandContext(ctx, "getent", "passwd", strconv.FormatInt(int64(uid), 10))
 		getentOut, err := getentCmd.Output()
 		if err != nil {
 			return status.Errorf(codes.Internal, "getent passwd %d failed: %v", uid, err)
@@ -181,11 +183,11 @@ func (m *TenantManager) createUser(ctx context.Context, name string, uid int32)
 
 	// Kill any remaining processes and remove the user.
 	for i := 0; i < 10; i++ {
-		killCmd := exec.CommandContext(ctx, "pkill", "-9", "-u", staleUser)
+		killCmd := cmdaudit.CommandContext(ctx, "pkill", "-9", "-u", staleUser)
 		_ = killCmd.Run() // Ignore error — no processes is fine.
 		time.Sleep(500 * time.Millisecond)
 
-		delCmd := exec.CommandContext(ctx, "userdel", staleUser)
+		delCmd := cmdaudit.CommandContext(ctx, "userdel", staleUser)
 		delOutput, err := delCmd.CombinedOutput()
 		if err == nil {
 			break
@@ -200,7 +202,7 @@ func (m *TenantManager) createUser(ctx context.Context, name string, uid int32)
 	}
 
 	// Retry useradd.
-	retryCmd := exec.CommandContext(ctx, "useradd",
+	retryCmd := cmdaudit.CommandContext(ctx, "useradd",
 		"-M", "-d", "/home",
 		"-u", strconv.FormatInt(int64(uid), 10),
 		"-s", "/bin/bash",
@@ -228,7 +230,7 @@ func (m *TenantManager) stopUserServices(ctx context.Context, username string) {
 	for _, dir := range fpmVersions {
 		version := filepath.Base(filepath.Dir(dir))
 		m.logger.Debug().Str("version", version).Msg("restarting PHP-FPM to clear stale workers")
-		_ = exec.CommandContext(ctx, "systemctl", "restart", "php"+version+"-fpm").Run()
+		_ = cmdaudit.CommandContext(ctx, "systemctl", "restart", "php"+version+"-fpm").Run()
 	}
 
 	// 2. Stop supervisord daemons for this user.
@@ -236,12 +238,12 @@ func (m *TenantManager) stopUserServices(ctx context.Context, username string) {
 	for _, conf := range confs {
 		program := strings.TrimSuffix(filepath.Base(conf), ".conf")
 		m.logger.Debug().Str("program", program).Msg("stopping supervisord daemon")
-		_ = exec.CommandContext(ctx, "supervisorctl", "stop", program+":*").Run()
+		_ = cmdaudit.CommandContext(ctx, "supervisorctl", "stop", program+":*").Run()
 		os.Remove(conf)
 	}
 	if len(confs) > 0 {
-		_ = exec.CommandContext(ctx, "supervisorctl", "reread").Run()
-		_ = exec.CommandContext(ctx, "supervisorctl", "update").Run()
+		_ = cmdaudit.CommandContext(ctx, "supervisorctl", "reread").Run()
+		_ = cmdaudit.CommandContext(ctx, "supervisorctl", "update").Run()
 	}
 
 	// 3. Stop and disable systemd cron timers for this user.
@@ -249,14 +251,14 @@ func (m *TenantManager) stopUserServices(ctx context.Context, username string) {
 	for _, timer := range timers {
 		unit := filepath.Base(timer)
 		m.logger.Debug().Str("timer", unit).Msg("stopping cron timer")
-		_ = exec.CommandContext(ctx, "systemctl", "stop", unit).Run()
-		_ = exec.CommandContext(ctx, "systemctl", "disable", unit).Run()
+		_ = cmdaudit.CommandContext(ctx, "systemctl", "stop", unit).Run()
+		_ = cmdaudit.CommandContext(ctx, "systemctl", "disable", unit).Run()
 	}
 
 	// 4. Kill ALL processes owned by this user's UID. This catches any runtime
 	//    (PHP-FPM, Node, Python, Ruby, daemons) regardless of how it was started
 	//    or which previous tenant name the process was spawned under.
-	_ = exec.CommandContext(ctx, "pkill", "-9", "-u", username).Run()
+	_ = cmdaudit.CommandContext(ctx, "pkill", "-9", "-u", username).Run()
 
 	time.Sleep(1 * time.Second)
 }
@@ -267,7 +269,7 @@ func (m *TenantManager) setQuota(ctx context.Context, tenantDir string, quotaByt
 	if quotaBytes <= 0 {
 		return nil
 	}
-	cmd := exec.CommandContext(ctx, "setfattr",
+	cmd := cmdaudit.CommandContext(ctx, "setfattr",
 		"-n", "ceph.quota.max_bytes",
 		"-v", strconv.FormatInt(quotaBytes, 10),
 		tenantDir,
@@ -296,14 +298,14 @@ func (m *TenantManager) Update(ctx context.Context, info *TenantInfo) error {
 func (m *TenantManager) Suspend(ctx context.Context, name string) error {
 	m.logger.Info().Str("tenant", name).Msg("suspending tenant user")
 
-	cmd := exec.CommandContext(ctx, "usermod", "-L", name)
+	cmd := cmdaudit.CommandContext(ctx, "usermod", "-L", name)
 	m.logger.Debug().Strs("cmd", cmd.Args).Msg("executing usermod -L")
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.Internal, "usermod -L failed for %s: %s: %v", name, string(output), err)
 	}
 
 	// Kill any running processes for the user.
-	killCmd := exec.CommandContext(ctx, "pkill", "-u", name)
+	killCmd := cmdaudit.CommandContext(ctx, "pkill", "-u", name)
 	m.logger.Debug().Strs("cmd", killCmd.Args).Msg("executing pkill")
 	_ = killCmd.Run()
 
@@ -314,7 +316,7 @@ func (m *TenantManager) Suspend(ctx context.Context, name string) error {
 func (m *TenantManager) Unsuspend(ctx context.Context, name string) error {
 	m.logger.Info().Str("tenant", name).Msg("unsuspending tenant user")
 
-	cmd := exec.CommandContext(ctx, "usermod", "-U", name)
+	cmd := cmdaudit.CommandContext(ctx, "usermod", "-U", name)
 	m.logger.Debug().Strs("cmd", cmd.Args).Msg("executing usermod -U")
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.Internal, "usermod -U failed for %s: %s: %v", name, string(output), err)
@@ -337,11 +339,11 @@ func (m *TenantManager) Delete(ctx context.Context, name string) error {
 	// Kill all processes owned by the user and remove. Retry because
 	// other runtimes (daemons, workers) may take a moment to exit.
 	for i := 0; i < 10; i++ {
-		killCmd := exec.CommandContext(ctx, "pkill", "-9", "-u", name)
+		killCmd := cmdaudit.CommandContext(ctx, "pkill", "-9", "-u", name)
 		_ = killCmd.Run() // Ignore error — no processes is fine.
 		time.Sleep(500 * time.Millisecond)
 
-		cmd := exec.CommandContext(ctx, "userdel", name)
+		cmd := cmdaudit.CommandContext(ctx, "userdel", name)
 		output, err := cmd.CombinedOutput()
 		if err == nil {
 			break
@@ -368,7 +370,7 @@ func (m *TenantManager) Delete(ctx context.Context, name string) error {
 			fields := strings.Fields(line)
 			if len(fields) >= 2 && strings.HasPrefix(fields[1], chrootDir+"/") {
 				m.logger.Debug().Str("mount", fields[1]).Msg("unmounting chroot bind mount")
-				umount := exec.CommandContext(ctx, "umount", "-l", fields[1])
+				umount := cmdaudit.CommandContext(ctx, "umount", "-l", fields[1])
 				if out, err := umount.CombinedOutput(); err != nil {
 					m.logger.Warn().Str("mount", fields[1]).Str("output", string(out)).Err(err).Msg("umount failed")
 				}
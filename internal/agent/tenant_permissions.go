@@ -0,0 +1,236 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// tenantOwnedDirs are the directories of a tenant's chroot that belong to
+// the tenant, with their modes. FixPermissions reconciles them and
+// everything beneath them; see TenantManager.Create for the layout.
+var tenantOwnedDirs = []struct {
+	name string
+	mode fs.FileMode
+}{
+	{"home", 0700},
+	{"webroots", 0751},
+	{"tmp", fs.ModeSticky | 0777},
+}
+
+// permissionsProgressEvery is how many entries FixPermissions scans between
+// progress reports.
+const permissionsProgressEvery = 1000
+
+// errPermissionsLimit stops the walk when a limit of FixPermissions is hit.
+var errPermissionsLimit = errors.New("permissions fix limit reached")
+
+// FixPermissions reconciles the ownership and modes of a tenant's storage
+// tree with the layout TenantManager.Create sets up: the chroot is
+// root:root 0755, its home, webroots and tmp directories get their fixed
+// modes, and everything beneath them is owned by the tenant's user and
+// group with the mode fixed by fixedMode. Other entries in the chroot are
+// left alone.
+//
+// All changes go through an os.Root on the chroot, so symlinks are never
+// followed out of it. Hard-linked files not owned by the tenant are
+// skipped. The walk stops, with Truncated set, after maxChanges changes or
+// after budget, whichever comes first; progress is called every
+// permissionsProgressEvery entries with the number scanned.
+func (m *TenantManager) FixPermissions(ctx context.Context, name string, maxChanges int, budget time.Duration, progress func(scanned int)) (*model.TenantPermissionsFix, error) {
+	if err := CheckMount(m.webStorageDir); err != nil {
+		return nil, err
+	}
+	if name == "" || name == "." || name == ".." || name != filepath.Base(name) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid tenant name %q", name)
+	}
+
+	u, err := user.Lookup(name)
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "look up tenant user %s: %v", name, err)
+	}
+	uid, _ := strconv.Atoi(u.Uid)
+	gid, _ := strconv.Atoi(u.Gid)
+	if uid == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "tenant user %s is root", name)
+	}
+
+	chrootDir := filepath.Join(m.webStorageDir, name)
+	if fi, err := os.Lstat(chrootDir); err != nil || !fi.IsDir() {
+		return nil, status.Errorf(codes.NotFound, "tenant storage %s is not a directory", chrootDir)
+	}
+	root, err := os.OpenRoot(chrootDir)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "open tenant storage %s: %v", chrootDir, err)
+	}
+	defer root.Close()
+
+	m.logger.Info().Str("tenant", name).Int("uid", uid).Str("chroot", chrootDir).Msg("fixing tenant permissions")
+
+	f := &permissionsFixer{
+		root:       root,
+		uid:        uid,
+		gid:        gid,
+		maxChanges: maxChanges,
+		deadline:   time.Now().Add(budget),
+		progress:   progress,
+	}
+
+	err = f.fix(ctx, ".", 0, 0, 0755)
+	for _, d := range tenantOwnedDirs {
+		if err != nil {
+			break
+		}
+		if _, statErr := root.Lstat(d.name); errors.Is(statErr, fs.ErrNotExist) {
+			continue
+		}
+		err = f.fix(ctx, d.name, uid, gid, d.mode)
+		if err == nil {
+			err = f.walk(ctx, d.name)
+		}
+	}
+	if errors.Is(err, errPermissionsLimit) {
+		f.result.Truncated = true
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info().Str("tenant", name).Int("scanned", f.result.Scanned).Int("changed", f.result.Changed).
+		Int("skipped", f.result.Skipped).Bool("truncated", f.result.Truncated).Msg("fixed tenant permissions")
+	return &f.result, nil
+}
+
+// permissionsFixer holds the state of one FixPermissions run.
+type permissionsFixer struct {
+	root       *os.Root
+	uid, gid   int
+	maxChanges int
+	deadline   time.Time
+	progress   func(scanned int)
+	result     model.TenantPermissionsFix
+}
+
+// walk fixes everything beneath dir, which is relative to the chroot.
+func (f *permissionsFixer) walk(ctx context.Context, dir string) error {
+	return fs.WalkDir(f.root.FS(), dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Entries removed while walking are not an error.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return status.Errorf(codes.Internal, "walk %s: %v", path, err)
+		}
+		if path == dir {
+			return nil
+		}
+		info, err := d.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "stat %s: %v", path, err)
+		}
+		return f.fixEntry(ctx, path, info)
+	})
+}
+
+// fix sets the owner and exact mode of a top-level chroot entry.
+func (f *permissionsFixer) fix(ctx context.Context, path string, uid, gid int, mode fs.FileMode) error {
+	info, err := f.root.Lstat(path)
+	if err != nil {
+		return status.Errorf(codes.Internal, "stat %s: %v", path, err)
+	}
+	if !info.IsDir() {
+		return status.Errorf(codes.FailedPrecondition, "%s in tenant storage is not a directory", path)
+	}
+	return f.apply(ctx, path, info, uid, gid, mode)
+}
+
+// fixEntry sets the owner of an entry beneath a tenant-owned directory to
+// the tenant, and its mode to fixedMode.
+func (f *permissionsFixer) fixEntry(ctx context.Context, path string, info fs.FileInfo) error {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && info.Mode().IsRegular() && st.Nlink > 1 && int(st.Uid) != f.uid {
+		f.result.Skipped++
+		return f.scanned(ctx)
+	}
+	return f.apply(ctx, path, info, f.uid, f.gid, fixedMode(info.Mode()))
+}
+
+// apply changes the owner and mode of path where they differ from the
+// wanted ones. Symlinks only get their owner changed.
+func (f *permissionsFixer) apply(ctx context.Context, path string, info fs.FileInfo, uid, gid int, mode fs.FileMode) error {
+	changed := false
+	if st, ok := info.Sys().(*syscall.Stat_t); !ok || int(st.Uid) != uid || int(st.Gid) != gid {
+		if err := f.root.Lchown(path, uid, gid); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return status.Errorf(codes.Internal, "chown %s: %v", path, err)
+		}
+		changed = true
+	}
+	// Chown clears setuid and setgid bits, so the mode is compared after it.
+	if info.Mode()&fs.ModeSymlink == 0 && (changed || permBits(info.Mode()) != mode) {
+		if err := f.root.Chmod(path, mode); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return status.Errorf(codes.Internal, "chmod %s: %v", path, err)
+		}
+		changed = changed || permBits(info.Mode()) != mode
+	}
+	if changed {
+		f.result.Changed++
+		if f.maxChanges > 0 && f.result.Changed >= f.maxChanges {
+			return errPermissionsLimit
+		}
+	}
+	return f.scanned(ctx)
+}
+
+// scanned counts an entry, reports progress and checks the time limit.
+func (f *permissionsFixer) scanned(ctx context.Context) error {
+	f.result.Scanned++
+	if f.result.Scanned%permissionsProgressEvery == 0 {
+		if f.progress != nil {
+			f.progress(f.result.Scanned)
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if time.Now().After(f.deadline) {
+			return errPermissionsLimit
+		}
+	}
+	return nil
+}
+
+// permBits returns the permission, setuid, setgid and sticky bits of mode.
+func permBits(mode fs.FileMode) fs.FileMode {
+	return mode & (fs.ModePerm | fs.ModeSetuid | fs.ModeSetgid | fs.ModeSticky)
+}
+
+// fixedMode returns the mode a file or directory in a tenant's storage
+// should have: readable and writable (and, for directories, searchable) by
+// the tenant, never writable by others, and without setuid or setgid.
+// Directories keep their sticky bit; other bits are left as they are.
+func fixedMode(mode fs.FileMode) fs.FileMode {
+	m := permBits(mode) &^ (fs.ModeSetuid | fs.ModeSetgid | 0002)
+	if mode.IsDir() {
+		m |= 0700
+		if mode&fs.ModeSticky != 0 {
+			// Shared directories like tmp/ may stay world-writable.
+			m |= permBits(mode) & 0002
+		}
+	} else {
+		m = (m | 0600) &^ fs.ModeSticky
+	}
+	return m
+}
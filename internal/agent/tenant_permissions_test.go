@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixedMode(t *testing.T) {
+	tests := []struct {
+		name string
+		mode fs.FileMode
+		want fs.FileMode
+	}{
+		{"file ok", 0644, 0644},
+		{"file world-writable", 0666, 0664},
+		{"file unreadable by owner", 0044, 0644},
+		{"file setuid", fs.ModeSetuid | 0755, 0755},
+		{"file setgid", fs.ModeSetgid | 0750, 0750},
+		{"dir ok", fs.ModeDir | 0755, 0755},
+		{"dir world-writable", fs.ModeDir | 0777, 0775},
+		{"dir unsearchable by owner", fs.ModeDir | 0055, 0755},
+		{"dir setgid", fs.ModeDir | fs.ModeSetgid | 0775, 0775},
+		{"dir sticky", fs.ModeDir | fs.ModeSticky | 0777, fs.ModeSticky | 0777},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fixedMode(tt.mode))
+		})
+	}
+}
+
+func newTestFixer(t *testing.T, dir string, maxChanges int) *permissionsFixer {
+	t.Helper()
+	root, err := os.OpenRoot(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { root.Close() })
+	return &permissionsFixer{
+		root:       root,
+		uid:        os.Getuid(),
+		gid:        os.Getgid(),
+		maxChanges: maxChanges,
+		deadline:   time.Now().Add(time.Minute),
+	}
+}
+
+func TestPermissionsFixer_Walk(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "webroots", "site", "public"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "webroots", "site", "public", "index.php"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "webroots", "site", "config.php"), nil, 0644))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "webroots", "site", "config.php"), 0666))
+	require.NoError(t, os.Chmod(filepath.Join(dir, "webroots", "site", "public"), 0777))
+	outside := filepath.Join(t.TempDir(), "outside")
+	require.NoError(t, os.WriteFile(outside, nil, 0666))
+	require.NoError(t, os.Chmod(outside, 0666))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "webroots", "site", "link")))
+
+	f := newTestFixer(t, dir, 0)
+	require.NoError(t, f.walk(context.Background(), "webroots"))
+
+	assert.Equal(t, 5, f.result.Scanned)
+	assert.Equal(t, 2, f.result.Changed)
+	assert.False(t, f.result.Truncated)
+
+	fi, err := os.Stat(filepath.Join(dir, "webroots", "site", "config.php"))
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0664), fi.Mode().Perm())
+	fi, err = os.Stat(filepath.Join(dir, "webroots", "site", "public"))
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0775), fi.Mode().Perm())
+
+	// The symlink target outside the tree is left alone.
+	fi, err = os.Stat(outside)
+	require.NoError(t, err)
+	assert.Equal(t, fs.FileMode(0666), fi.Mode().Perm())
+
+	// A second run has nothing left to change.
+	f = newTestFixer(t, dir, 0)
+	require.NoError(t, f.walk(context.Background(), "webroots"))
+	assert.Equal(t, 5, f.result.Scanned)
+	assert.Equal(t, 0, f.result.Changed)
+}
+
+func TestPermissionsFixer_MaxChanges(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "home"), 0700))
+	for _, name := range []string{"a", "b", "c"} {
+		path := filepath.Join(dir, "home", name)
+		require.NoError(t, os.WriteFile(path, nil, 0600))
+		require.NoError(t, os.Chmod(path, 0666))
+	}
+
+	f := newTestFixer(t, dir, 2)
+	err := f.walk(context.Background(), "home")
+	assert.ErrorIs(t, err, errPermissionsLimit)
+	assert.Equal(t, 2, f.result.Changed)
+}
@@ -3,13 +3,14 @@ package agent
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"strings"
 	"sync"
 
 	"github.com/rs/zerolog"
 
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
 	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/logging"
 	"github.com/edvin/hosting/internal/model"
 )
 
@@ -32,7 +33,7 @@ type TenantULAManager struct {
 // NewTenantULAManager creates a new TenantULAManager.
 func NewTenantULAManager(logger zerolog.Logger) *TenantULAManager {
 	return &TenantULAManager{
-		logger: logger.With().Str("component", "tenant-ula").Logger(),
+		logger: logging.Component(logger, "tenant-ula"),
 	}
 }
 
@@ -55,7 +56,7 @@ func (m *TenantULAManager) ensureTable(ctx context.Context) error {
 		{[]string{"add", "chain", "ip6", "tenant_binding", "output", "{ type filter hook output priority 0 ; policy accept ; }"}, "create chain"},
 	}
 	for _, s := range steps {
-		if out, err := exec.CommandContext(ctx, "nft", s.args...).CombinedOutput(); err != nil {
+		if out, err := cmdaudit.CommandContext(ctx, "nft", s.args...).CombinedOutput(); err != nil {
 			return fmt.Errorf("nft %s: %s: %w", s.desc, string(out), err)
 		}
 	}
@@ -71,7 +72,7 @@ table ip6 tenant_binding {
     }
 }
 `
-	cmd := exec.CommandContext(ctx, "nft", "-f", "-")
+	cmd := cmdaudit.CommandContext(ctx, "nft", "-f", "-")
 	cmd.Stdin = strings.NewReader(nftScript)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("nft flush+rule: %s: %w", string(out), err)
@@ -96,13 +97,13 @@ func (m *TenantULAManager) Configure(ctx context.Context, info *TenantULAInfo) e
 		Msg("configuring tenant ULA")
 
 	// Add IPv6 address to tenant0 interface — idempotent.
-	out, err := exec.CommandContext(ctx, "ip", "-6", "addr", "add", ula+"/128", "dev", "tenant0").CombinedOutput()
+	out, err := cmdaudit.CommandContext(ctx, "ip", "-6", "addr", "add", ula+"/128", "dev", "tenant0").CombinedOutput()
 	if err != nil && !isAddrAlreadyExists(string(out)) {
 		return fmt.Errorf("ip addr add %s: %s: %w", ula, string(out), err)
 	}
 
 	// Add nftables element to allow this (address, uid) pair.
-	out, err = exec.CommandContext(ctx, "nft", "add", "element", "ip6", "tenant_binding", "allowed",
+	out, err = cmdaudit.CommandContext(ctx, "nft", "add", "element", "ip6", "tenant_binding", "allowed",
 		fmt.Sprintf("{ %s . %d }", ula, info.TenantUID)).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("nft add element %s . %d: %s: %w", ula, info.TenantUID, string(out), err)
@@ -132,7 +133,7 @@ func (m *TenantULAManager) ConfigureRoutes(ctx context.Context, info *ULARoutesI
 
 	// Add transit address on primary interface — idempotent.
 	transitAddr := fmt.Sprintf("fd00:%x:0::%x/64", clusterHash, info.ThisNodeIndex)
-	out, err := exec.CommandContext(ctx, "ip", "-6", "addr", "add", transitAddr, "dev", iface).CombinedOutput()
+	out, err := cmdaudit.CommandContext(ctx, "ip", "-6", "addr", "add", transitAddr, "dev", iface).CombinedOutput()
 	if err != nil && !isAddrAlreadyExists(string(out)) {
 		return fmt.Errorf("ip addr add transit %s dev %s: %s: %w", transitAddr, iface, string(out), err)
 	}
@@ -142,7 +143,7 @@ func (m *TenantULAManager) ConfigureRoutes(ctx context.Context, info *ULARoutesI
 	for _, otherIdx := range info.OtherNodeIndices {
 		prefix := fmt.Sprintf("fd00:%x:%x::/48", clusterHash, otherIdx)
 		nextHop := fmt.Sprintf("fd00:%x:0::%x", clusterHash, otherIdx)
-		out, err := exec.CommandContext(ctx, "ip", "-6", "route", "replace", prefix, "via", nextHop).CombinedOutput()
+		out, err := cmdaudit.CommandContext(ctx, "ip", "-6", "route", "replace", prefix, "via", nextHop).CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("ip route replace %s via %s: %s: %w", prefix, nextHop, string(out), err)
 		}
@@ -155,7 +156,7 @@ func (m *TenantULAManager) ConfigureRoutes(ctx context.Context, info *ULARoutesI
 // detectPrimaryInterface finds the network interface used for the default IPv4 route.
 func (m *TenantULAManager) detectPrimaryInterface(ctx context.Context) (string, error) {
 	// Output: "default via 10.10.10.1 dev enp0s2 proto ..."
-	out, err := exec.CommandContext(ctx, "ip", "-4", "route", "show", "default").CombinedOutput()
+	out, err := cmdaudit.CommandContext(ctx, "ip", "-4", "route", "show", "default").CombinedOutput()
 	if err != nil {
 		return "", fmt.Errorf("detect default interface: %s: %w", string(out), err)
 	}
@@ -179,7 +180,7 @@ func (m *TenantULAManager) Remove(ctx context.Context, info *TenantULAInfo) erro
 		Msg("removing tenant ULA")
 
 	// Remove IPv6 address from tenant0 — ignore errors if address not present.
-	out, err := exec.CommandContext(ctx, "ip", "-6", "addr", "del", ula+"/128", "dev", "tenant0").CombinedOutput()
+	out, err := cmdaudit.CommandContext(ctx, "ip", "-6", "addr", "del", ula+"/128", "dev", "tenant0").CombinedOutput()
 	if err != nil {
 		outStr := string(out)
 		if !strings.Contains(outStr, "Cannot assign") && !strings.Contains(outStr, "not found") {
@@ -188,7 +189,7 @@ func (m *TenantULAManager) Remove(ctx context.Context, info *TenantULAInfo) erro
 	}
 
 	// Remove nftables element — ignore errors if not present.
-	_, _ = exec.CommandContext(ctx, "nft", "delete", "element", "ip6", "tenant_binding", "allowed",
+	_, _ = cmdaudit.CommandContext(ctx, "nft", "delete", "element", "ip6", "tenant_binding", "allowed",
 		fmt.Sprintf("{ %s . %d }", ula, info.TenantUID)).CombinedOutput()
 
 	return nil
@@ -214,7 +215,7 @@ func (m *TenantULAManager) EnsureServiceIngressTable(ctx context.Context) error
 		{[]string{"add", "chain", "ip6", "tenant_service_ingress", "input", "{ type filter hook input priority 0 ; policy accept ; }"}, "create chain"},
 	}
 	for _, s := range steps {
-		if out, err := exec.CommandContext(ctx, "nft", s.args...).CombinedOutput(); err != nil {
+		if out, err := cmdaudit.CommandContext(ctx, "nft", s.args...).CombinedOutput(); err != nil {
 			return fmt.Errorf("nft %s: %s: %w", s.desc, string(out), err)
 		}
 	}
@@ -230,7 +231,7 @@ table ip6 tenant_service_ingress {
     }
 }
 `
-	cmd := exec.CommandContext(ctx, "nft", "-f", "-")
+	cmd := cmdaudit.CommandContext(ctx, "nft", "-f", "-")
 	cmd.Stdin = strings.NewReader(nftScript)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("nft service ingress flush+rules: %s: %w", string(out), err)
@@ -258,13 +259,13 @@ func (m *TenantULAManager) ConfigureServiceAddr(ctx context.Context, info *Tenan
 		Msg("configuring service tenant ULA")
 
 	// Add IPv6 address to tenant0 interface — idempotent.
-	out, err := exec.CommandContext(ctx, "ip", "-6", "addr", "add", ula+"/128", "dev", "tenant0").CombinedOutput()
+	out, err := cmdaudit.CommandContext(ctx, "ip", "-6", "addr", "add", ula+"/128", "dev", "tenant0").CombinedOutput()
 	if err != nil && !isAddrAlreadyExists(string(out)) {
 		return fmt.Errorf("ip addr add %s: %s: %w", ula, string(out), err)
 	}
 
 	// Add to nftables ula_addrs set.
-	out, err = exec.CommandContext(ctx, "nft", "add", "element", "ip6", "tenant_service_ingress", "ula_addrs",
+	out, err = cmdaudit.CommandContext(ctx, "nft", "add", "element", "ip6", "tenant_service_ingress", "ula_addrs",
 		fmt.Sprintf("{ %s }", ula)).CombinedOutput()
 	if err != nil {
 		return fmt.Errorf("nft add element %s: %s: %w", ula, string(out), err)
@@ -285,7 +286,7 @@ func (m *TenantULAManager) RemoveServiceAddr(ctx context.Context, info *TenantUL
 		Msg("removing service tenant ULA")
 
 	// Remove IPv6 address from tenant0 — ignore errors if not present.
-	out, err := exec.CommandContext(ctx, "ip", "-6", "addr", "del", ula+"/128", "dev", "tenant0").CombinedOutput()
+	out, err := cmdaudit.CommandContext(ctx, "ip", "-6", "addr", "del", ula+"/128", "dev", "tenant0").CombinedOutput()
 	if err != nil {
 		outStr := string(out)
 		if !strings.Contains(outStr, "Cannot assign") && !strings.Contains(outStr, "not found") {
@@ -294,7 +295,7 @@ func (m *TenantULAManager) RemoveServiceAddr(ctx context.Context, info *TenantUL
 	}
 
 	// Remove from nftables set — ignore errors if not present.
-	_, _ = exec.CommandContext(ctx, "nft", "delete", "element", "ip6", "tenant_service_ingress", "ula_addrs",
+	_, _ = cmdaudit.CommandContext(ctx, "nft", "delete", "element", "ip6", "tenant_service_ingress", "ula_addrs",
 		fmt.Sprintf("{ %s }", ula)).CombinedOutput()
 
 	return nil
@@ -327,7 +328,7 @@ func (m *TenantULAManager) ConfigureRoutesV2(ctx context.Context, info *ULARoute
 
 	// Add transit address on primary interface — idempotent.
 	transitAddr := fmt.Sprintf("fd00:%x:0::%x/64", clusterHash, info.ThisTransitIndex)
-	out, err := exec.CommandContext(ctx, "ip", "-6", "addr", "add", transitAddr, "dev", iface).CombinedOutput()
+	out, err := cmdaudit.CommandContext(ctx, "ip", "-6", "addr", "add", transitAddr, "dev", iface).CombinedOutput()
 	if err != nil && !isAddrAlreadyExists(string(out)) {
 		return fmt.Errorf("ip addr add transit %s dev %s: %s: %w", transitAddr, iface, string(out), err)
 	}
@@ -337,7 +338,7 @@ func (m *TenantULAManager) ConfigureRoutesV2(ctx context.Context, info *ULARoute
 	for _, peer := range info.Peers {
 		prefix := fmt.Sprintf("fd00:%x:%x::/48", clusterHash, peer.PrefixIndex)
 		nextHop := fmt.Sprintf("fd00:%x:0::%x", clusterHash, peer.TransitIndex)
-		out, err := exec.CommandContext(ctx, "ip", "-6", "route", "replace", prefix, "via", nextHop).CombinedOutput()
+		out, err := cmdaudit.CommandContext(ctx, "ip", "-6", "route", "replace", prefix, "via", nextHop).CombinedOutput()
 		if err != nil {
 			return fmt.Errorf("ip route replace %s via %s: %s: %w", prefix, nextHop, string(out), err)
 		}
@@ -366,12 +367,12 @@ func (m *TenantULAManager) SyncEgressRules(ctx context.Context, tenantUID int, r
 	}
 
 	// Create chain if it doesn't exist (idempotent).
-	if out, err := exec.CommandContext(ctx, "nft", "add", "chain", "inet", "tenant_egress", chainName).CombinedOutput(); err != nil {
+	if out, err := cmdaudit.CommandContext(ctx, "nft", "add", "chain", "inet", "tenant_egress", chainName).CombinedOutput(); err != nil {
 		return fmt.Errorf("nft add egress chain: %s: %w", string(out), err)
 	}
 
 	// Flush the chain to remove old rules.
-	if out, err := exec.CommandContext(ctx, "nft", "flush", "chain", "inet", "tenant_egress", chainName).CombinedOutput(); err != nil {
+	if out, err := cmdaudit.CommandContext(ctx, "nft", "flush", "chain", "inet", "tenant_egress", chainName).CombinedOutput(); err != nil {
 		return fmt.Errorf("nft flush egress chain: %s: %w", string(out), err)
 	}
 
@@ -387,18 +388,18 @@ func (m *TenantULAManager) SyncEgressRules(ctx context.Context, tenantUID int, r
 	// Final reject — anything not matching an allowed CIDR is blocked.
 	b.WriteString(fmt.Sprintf("add rule inet tenant_egress %s reject\n", chainName))
 
-	cmd := exec.CommandContext(ctx, "nft", "-f", "-")
+	cmd := cmdaudit.CommandContext(ctx, "nft", "-f", "-")
 	cmd.Stdin = strings.NewReader(b.String())
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("nft add egress rules: %s: %w", string(out), err)
 	}
 
 	// Ensure jump rule exists.
-	listOut, _ := exec.CommandContext(ctx, "nft", "list", "chain", "inet", "tenant_egress", "output").CombinedOutput()
+	listOut, _ := cmdaudit.CommandContext(ctx, "nft", "list", "chain", "inet", "tenant_egress", "output").CombinedOutput()
 	jumpTarget := fmt.Sprintf("jump %s", chainName)
 	if !strings.Contains(string(listOut), jumpTarget) {
 		jumpCmd := fmt.Sprintf("add rule inet tenant_egress output meta skuid %d jump %s\n", tenantUID, chainName)
-		cmd := exec.CommandContext(ctx, "nft", "-f", "-")
+		cmd := cmdaudit.CommandContext(ctx, "nft", "-f", "-")
 		cmd.Stdin = strings.NewReader(jumpCmd)
 		if out, err := cmd.CombinedOutput(); err != nil {
 			return fmt.Errorf("nft add jump rule: %s: %w", string(out), err)
@@ -419,7 +420,7 @@ func (m *TenantULAManager) ensureEgressTable(ctx context.Context) error {
 		{[]string{"add", "chain", "inet", "tenant_egress", "output", "{ type filter hook output priority 1 ; policy accept ; }"}, "create egress output chain"},
 	}
 	for _, s := range steps {
-		if out, err := exec.CommandContext(ctx, "nft", s.args...).CombinedOutput(); err != nil {
+		if out, err := cmdaudit.CommandContext(ctx, "nft", s.args...).CombinedOutput(); err != nil {
 			return fmt.Errorf("nft %s: %s: %w", s.desc, string(out), err)
 		}
 	}
@@ -429,10 +430,10 @@ func (m *TenantULAManager) ensureEgressTable(ctx context.Context) error {
 // removeEgressChain removes a tenant's egress chain and jump rule.
 func (m *TenantULAManager) removeEgressChain(ctx context.Context, uid int, chainName string) error {
 	// Flush chain (ignore errors if it doesn't exist).
-	exec.CommandContext(ctx, "nft", "flush", "chain", "inet", "tenant_egress", chainName).CombinedOutput()
+	cmdaudit.CommandContext(ctx, "nft", "flush", "chain", "inet", "tenant_egress", chainName).CombinedOutput()
 	// Delete the chain — nft requires no references to it first, so remove
 	// the jump rule from the output chain by listing handles and deleting.
-	exec.CommandContext(ctx, "nft", "delete", "chain", "inet", "tenant_egress", chainName).CombinedOutput()
+	cmdaudit.CommandContext(ctx, "nft", "delete", "chain", "inet", "tenant_egress", chainName).CombinedOutput()
 
 	m.logger.Info().Int("uid", uid).Msg("removed egress chain (no rules)")
 	return nil
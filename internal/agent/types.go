@@ -15,6 +15,12 @@ type FQDNInfo struct {
 	FQDN       string
 	WebrootID  string
 	SSLEnabled bool
+	ForceHTTPS bool // Redirect plain HTTP to HTTPS when SSL is enabled.
+	// RedirectTarget, when set, answers every request for the FQDN with a
+	// RedirectCode redirect to this host instead of serving the webroot.
+	RedirectTarget       *string
+	RedirectCode         int
+	RedirectPreservePath bool // Keep the request path and query in the redirect.
 }
 
 // CertificateInfo holds SSL certificate data for installation.
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -14,7 +13,10 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
 	"github.com/edvin/hosting/internal/agent/runtime"
+	"github.com/edvin/hosting/internal/logging"
+	"github.com/edvin/hosting/internal/model"
 )
 
 // ValkeyManager handles Valkey instance and user operations via valkey-cli and systemd.
@@ -28,7 +30,7 @@ type ValkeyManager struct {
 // NewValkeyManager creates a new ValkeyManager.
 func NewValkeyManager(logger zerolog.Logger, cfg Config, svcMgr runtime.ServiceManager) *ValkeyManager {
 	return &ValkeyManager{
-		logger:    logger.With().Str("component", "valkey-manager").Logger(),
+		logger:    logging.Component(logger, "valkey-manager"),
 		configDir: cfg.ValkeyConfigDir,
 		dataDir:   cfg.ValkeyDataDir,
 		svcMgr:    svcMgr,
@@ -54,8 +56,8 @@ func (m *ValkeyManager) socketPath(name string) string {
 func (m *ValkeyManager) execValkeyCLI(ctx context.Context, name string, valkeyArgs ...string) (string, error) {
 	args := []string{"-s", m.socketPath(name)}
 	args = append(args, valkeyArgs...)
-	cmd := exec.CommandContext(ctx, "valkey-cli", args...)
-	m.logger.Debug().Str("instance", name).Strs("args", valkeyArgs).Msg("executing valkey-cli command")
+	cmd := cmdaudit.CommandContext(ctx, "valkey-cli", args...)
+	m.logger.Debug().Str("instance", name).Strs("args", cmdaudit.Redact(cmd.Args)).Msg("executing valkey-cli command")
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -64,16 +66,144 @@ func (m *ValkeyManager) execValkeyCLI(ctx context.Context, name string, valkeyAr
 	return strings.TrimSpace(string(output)), nil
 }
 
+// ValkeyStats holds memory and keyspace figures reported by a Valkey instance.
+type ValkeyStats struct {
+	UsedMemoryBytes int64
+	MaxMemoryBytes  int64
+	KeyCount        int64
+}
+
+// GetStats runs INFO memory and INFO keyspace against the instance and returns
+// the parsed figures.
+func (m *ValkeyManager) GetStats(ctx context.Context, name string) (*ValkeyStats, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(m.socketPath(name)); err != nil {
+		return nil, status.Errorf(codes.Unavailable, "valkey instance %s is not running", name)
+	}
+
+	memInfo, err := m.queryValkeyCLI(ctx, name, "INFO", "memory")
+	if err != nil {
+		return nil, err
+	}
+	keyspaceInfo, err := m.queryValkeyCLI(ctx, name, "INFO", "keyspace")
+	if err != nil {
+		return nil, err
+	}
+
+	stats := parseValkeyMemoryInfo(memInfo)
+	stats.KeyCount = parseValkeyKeyspaceInfo(keyspaceInfo)
+	return stats, nil
+}
+
+// queryValkeyCLI is like execValkeyCLI but reports failures as Unavailable.
+// valkey-cli prints error replies to stdout with a zero exit code, so
+// NOAUTH/WRONGPASS/ERR replies are turned into errors here.
+func (m *ValkeyManager) queryValkeyCLI(ctx context.Context, name string, valkeyArgs ...string) (string, error) {
+	args := []string{"-s", m.socketPath(name)}
+	args = append(args, valkeyArgs...)
+	cmd := cmdaudit.CommandContext(ctx, "valkey-cli", args...)
+
+	output, err := cmd.CombinedOutput()
+	out := strings.TrimSpace(string(output))
+	if err != nil {
+		return "", status.Errorf(codes.Unavailable, "valkey-cli failed: %s: %v", out, err)
+	}
+	if strings.HasPrefix(out, "NOAUTH") || strings.HasPrefix(out, "WRONGPASS") || strings.HasPrefix(out, "ERR") {
+		return "", status.Errorf(codes.Unavailable, "valkey-cli: %s", out)
+	}
+	return out, nil
+}
+
+// parseValkeyMemoryInfo extracts used_memory and maxmemory from INFO memory output.
+func parseValkeyMemoryInfo(info string) *ValkeyStats {
+	stats := &ValkeyStats{}
+	for _, line := range strings.Split(info, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), ":")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "used_memory":
+			stats.UsedMemoryBytes = n
+		case "maxmemory":
+			stats.MaxMemoryBytes = n
+		}
+	}
+	return stats
+}
+
+// parseValkeyKeyspaceInfo sums the key counts of all databases in INFO keyspace
+// output, e.g. "db0:keys=12,expires=0,avg_ttl=0".
+func parseValkeyKeyspaceInfo(info string) int64 {
+	var total int64
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "db") {
+			continue
+		}
+		_, fields, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		for _, field := range strings.Split(fields, ",") {
+			if v, ok := strings.CutPrefix(field, "keys="); ok {
+				if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+					total += n
+				}
+			}
+		}
+	}
+	return total
+}
+
+// valkeyRDBSavePoints is Valkey's default snapshot schedule: after an hour
+// with at least 1 change, 5 minutes with 100 or a minute with 10000.
+const valkeyRDBSavePoints = "3600 1 300 100 60 10000"
+
+// valkeyPersistence returns the save and appendonly settings for a
+// persistence mode. An empty mode means RDB.
+func valkeyPersistence(mode string) (save, appendOnly string) {
+	switch mode {
+	case model.ValkeyPersistenceAOF:
+		return "", "yes"
+	case model.ValkeyPersistenceNone:
+		return "", "no"
+	default:
+		return valkeyRDBSavePoints, "no"
+	}
+}
+
+// valkeyPersistenceConfig renders the config file directives for a
+// persistence mode.
+func valkeyPersistenceConfig(mode string) string {
+	save, appendOnly := valkeyPersistence(mode)
+	if save == "" {
+		save = `""`
+	}
+	return fmt.Sprintf(`save %s
+dbfilename dump.rdb
+appendonly %s
+appendfilename "appendonly.aof"
+`, save, appendOnly)
+}
+
 // CreateInstance provisions a new Valkey instance with config, ACL file, and systemd unit.
 // Auth is via ACL file (no requirepass). Local management uses the Unix socket.
 // This method is idempotent: if the instance already exists, its config is
 // converged and a running instance is updated via CONFIG SET.
-func (m *ValkeyManager) CreateInstance(ctx context.Context, name string, port int, passwordHash string, maxMemoryMB int) error {
+func (m *ValkeyManager) CreateInstance(ctx context.Context, name string, port int, passwordHash string, maxMemoryMB int, persistenceMode string) error {
 	if err := validateName(name); err != nil {
 		return err
 	}
 
-	m.logger.Info().Str("instance", name).Int("port", port).Msg("creating valkey instance")
+	m.logger.Info().Str("instance", name).Int("port", port).Str("persistence", persistenceMode).Msg("creating valkey instance")
 
 	dataPath := filepath.Join(m.dataDir, name)
 	config := fmt.Sprintf(`port %d
@@ -84,11 +214,8 @@ unixsocketperm 700
 maxmemory %dmb
 maxmemory-policy allkeys-lru
 dir %s
-dbfilename dump.rdb
-appendonly yes
-appendfilename "appendonly.aof"
-aclfile %s
-`, port, name, maxMemoryMB, dataPath, m.aclPath(name))
+%saclfile %s
+`, port, name, maxMemoryMB, dataPath, valkeyPersistenceConfig(persistenceMode), m.aclPath(name))
 
 	aclContent := fmt.Sprintf("user default on #%s ~* &* +@all\n", passwordHash)
 
@@ -101,6 +228,15 @@ aclfile %s
 	if _, err := os.Stat(m.aclPath(name)); err == nil {
 		m.logger.Info().Str("instance", name).Msg("instance already exists, converging config")
 
+		// Switch persistence on the running instance before the config file
+		// changes, so a failed snapshot leaves the old mode in place on restart.
+		_, pingErr := m.execValkeyCLI(ctx, name, "PING")
+		if pingErr == nil {
+			if err := m.applyPersistence(ctx, name, persistenceMode); err != nil {
+				return err
+			}
+		}
+
 		// Rewrite config and ACL file to desired state.
 		if err := os.WriteFile(m.configPath(name), []byte(config), 0640); err != nil {
 			return status.Errorf(codes.Internal, "rewrite config: %v", err)
@@ -110,7 +246,7 @@ aclfile %s
 		}
 
 		// Try to update running instance via CLI.
-		if _, pingErr := m.execValkeyCLI(ctx, name, "PING"); pingErr == nil {
+		if pingErr == nil {
 			// Instance is running — update config live.
 			if _, err := m.execValkeyCLI(ctx, name, "CONFIG", "SET", "maxmemory", fmt.Sprintf("%dmb", maxMemoryMB)); err != nil {
 				m.logger.Warn().Err(err).Msg("CONFIG SET maxmemory failed")
@@ -124,7 +260,7 @@ aclfile %s
 
 		// Instance config exists but process not running — start it.
 		m.logger.Info().Str("instance", name).Msg("instance not running, starting")
-		cmd := exec.CommandContext(ctx, "valkey-server", m.configPath(name), "--daemonize", "yes")
+		cmd := cmdaudit.CommandContext(ctx, "valkey-server", m.configPath(name), "--daemonize", "yes")
 		if output, err := cmd.CombinedOutput(); err != nil {
 			return status.Errorf(codes.Internal, "valkey-server restart: %s: %v", string(output), err)
 		}
@@ -150,7 +286,7 @@ aclfile %s
 	}
 
 	// Start valkey-server with the config file.
-	cmd := exec.CommandContext(ctx, "valkey-server", m.configPath(name), "--daemonize", "yes")
+	cmd := cmdaudit.CommandContext(ctx, "valkey-server", m.configPath(name), "--daemonize", "yes")
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.Internal, "valkey-server start: %s: %v", string(output), err)
 	}
@@ -164,6 +300,115 @@ aclfile %s
 	return nil
 }
 
+// applyPersistence switches a running instance to a persistence mode via
+// CONFIG SET. Turning AOF on makes Valkey rewrite the AOF from memory. Before
+// AOF is turned off for RDB a fresh snapshot is written, so a restart does not
+// load an older one. Switching to none removes the snapshot and AOF, so a
+// restart starts empty instead of from stale data.
+func (m *ValkeyManager) applyPersistence(ctx context.Context, name, mode string) error {
+	currentAOF, err := m.configGet(ctx, name, "appendonly")
+	if err != nil {
+		return err
+	}
+	currentSave, err := m.configGet(ctx, name, "save")
+	if err != nil {
+		return err
+	}
+
+	save, appendOnly := valkeyPersistence(mode)
+	if currentAOF == appendOnly && currentSave == save {
+		return nil
+	}
+
+	m.logger.Info().Str("instance", name).Str("persistence", mode).Msg("switching valkey persistence mode")
+
+	if currentAOF == "yes" && appendOnly == "no" && save != "" {
+		if err := m.bgsave(ctx, name); err != nil {
+			return status.Errorf(codes.Internal, "snapshot before disabling AOF: %v", err)
+		}
+	}
+	if _, err := m.execValkeyCLI(ctx, name, "CONFIG", "SET", "save", save); err != nil {
+		return fmt.Errorf("CONFIG SET save: %w", err)
+	}
+	if _, err := m.execValkeyCLI(ctx, name, "CONFIG", "SET", "appendonly", appendOnly); err != nil {
+		return fmt.Errorf("CONFIG SET appendonly: %w", err)
+	}
+
+	if mode == model.ValkeyPersistenceNone {
+		dataPath := filepath.Join(m.dataDir, name)
+		for _, path := range []string{
+			filepath.Join(dataPath, "dump.rdb"),
+			filepath.Join(dataPath, "appendonly.aof"),
+			filepath.Join(dataPath, "appendonlydir"),
+		} {
+			if err := os.RemoveAll(path); err != nil {
+				m.logger.Warn().Err(err).Str("path", path).Msg("remove persistence file failed")
+			}
+		}
+	}
+	return nil
+}
+
+// configGet returns the value of a single config parameter of a running
+// instance.
+func (m *ValkeyManager) configGet(ctx context.Context, name, key string) (string, error) {
+	out, err := m.execValkeyCLI(ctx, name, "CONFIG", "GET", key)
+	if err != nil {
+		return "", fmt.Errorf("CONFIG GET %s: %w", key, err)
+	}
+	return parseValkeyConfigGet(out), nil
+}
+
+// parseValkeyConfigGet extracts the value from valkey-cli's CONFIG GET
+// output, which prints the key and value on separate lines.
+func parseValkeyConfigGet(out string) string {
+	lines := strings.SplitN(out, "\n", 2)
+	if len(lines) < 2 {
+		return ""
+	}
+	return strings.TrimSpace(lines[1])
+}
+
+// bgsave triggers a BGSAVE and waits until LASTSAVE moves past its previous
+// value, meaning the snapshot is on disk.
+func (m *ValkeyManager) bgsave(ctx context.Context, name string) error {
+	// Record LASTSAVE before BGSAVE.
+	beforeSave, err := m.execValkeyCLI(ctx, name, "LASTSAVE")
+	if err != nil {
+		return fmt.Errorf("LASTSAVE before: %w", err)
+	}
+	beforeTS, err := strconv.ParseInt(beforeSave, 10, 64)
+	if err != nil {
+		return fmt.Errorf("parse LASTSAVE timestamp %q: %w", beforeSave, err)
+	}
+
+	// Trigger BGSAVE.
+	if _, err := m.execValkeyCLI(ctx, name, "BGSAVE"); err != nil {
+		return fmt.Errorf("BGSAVE: %w", err)
+	}
+
+	// Poll LASTSAVE until the timestamp changes, meaning BGSAVE completed.
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		afterSave, err := m.execValkeyCLI(ctx, name, "LASTSAVE")
+		if err != nil {
+			return fmt.Errorf("LASTSAVE poll: %w", err)
+		}
+		afterTS, err := strconv.ParseInt(afterSave, 10, 64)
+		if err != nil {
+			return fmt.Errorf("parse LASTSAVE timestamp %q: %w", afterSave, err)
+		}
+		if afterTS > beforeTS {
+			return nil
+		}
+	}
+}
+
 // DeleteInstance stops and removes a Valkey instance.
 func (m *ValkeyManager) DeleteInstance(ctx context.Context, name string, port int) error {
 	if err := validateName(name); err != nil {
@@ -219,46 +464,14 @@ func (m *ValkeyManager) DumpData(ctx context.Context, name string, port int, dum
 		return status.Errorf(codes.Internal, "create dump directory: %v", err)
 	}
 
-	// Record LASTSAVE before BGSAVE.
-	beforeSave, err := m.execValkeyCLI(ctx, name, "LASTSAVE")
-	if err != nil {
-		return fmt.Errorf("LASTSAVE before: %w", err)
-	}
-	beforeTS, err := strconv.ParseInt(beforeSave, 10, 64)
-	if err != nil {
-		return fmt.Errorf("parse LASTSAVE timestamp %q: %w", beforeSave, err)
-	}
-
-	// Trigger BGSAVE.
-	if _, err := m.execValkeyCLI(ctx, name, "BGSAVE"); err != nil {
-		return fmt.Errorf("BGSAVE: %w", err)
-	}
-
-	// Poll LASTSAVE until the timestamp changes, meaning BGSAVE completed.
-	for {
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(500 * time.Millisecond):
-		}
-
-		afterSave, err := m.execValkeyCLI(ctx, name, "LASTSAVE")
-		if err != nil {
-			return fmt.Errorf("LASTSAVE poll: %w", err)
-		}
-		afterTS, err := strconv.ParseInt(afterSave, 10, 64)
-		if err != nil {
-			return fmt.Errorf("parse LASTSAVE timestamp %q: %w", afterSave, err)
-		}
-		if afterTS > beforeTS {
-			break
-		}
+	if err := m.bgsave(ctx, name); err != nil {
+		return err
 	}
 
 	// Copy the RDB file to the dump path.
 	dataPath := filepath.Join(m.dataDir, name)
 	rdbPath := filepath.Join(dataPath, "dump.rdb")
-	cmd := exec.CommandContext(ctx, "cp", rdbPath, dumpPath)
+	cmd := cmdaudit.CommandContext(ctx, "cp", rdbPath, dumpPath)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.Internal, "copy RDB: %s: %v", string(output), err)
 	}
@@ -291,7 +504,7 @@ func (m *ValkeyManager) ImportData(ctx context.Context, name string, port int, d
 	// Replace the RDB file.
 	dataPath := filepath.Join(m.dataDir, name)
 	rdbPath := filepath.Join(dataPath, "dump.rdb")
-	cmd := exec.CommandContext(ctx, "cp", dumpPath, rdbPath)
+	cmd := cmdaudit.CommandContext(ctx, "cp", dumpPath, rdbPath)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.Internal, "copy RDB: %s: %v", string(output), err)
 	}
@@ -308,7 +521,7 @@ func (m *ValkeyManager) ImportData(ctx context.Context, name string, port int, d
 	}
 
 	// Restart the instance.
-	startCmd := exec.CommandContext(ctx, "valkey-server", m.configPath(name), "--daemonize", "yes")
+	startCmd := cmdaudit.CommandContext(ctx, "valkey-server", m.configPath(name), "--daemonize", "yes")
 	if output, err := startCmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.Internal, "valkey-server restart: %s: %v", string(output), err)
 	}
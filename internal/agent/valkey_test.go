@@ -0,0 +1,47 @@
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseValkeyMemoryInfo(t *testing.T) {
+	info := "# Memory\r\nused_memory:1048576\r\nused_memory_human:1.00M\r\nmaxmemory:67108864\r\nmaxmemory_policy:allkeys-lru\r\n"
+	stats := parseValkeyMemoryInfo(info)
+	assert.Equal(t, int64(1048576), stats.UsedMemoryBytes)
+	assert.Equal(t, int64(67108864), stats.MaxMemoryBytes)
+}
+
+func TestParseValkeyKeyspaceInfo(t *testing.T) {
+	info := "# Keyspace\r\ndb0:keys=12,expires=3,avg_ttl=0\r\ndb1:keys=5,expires=0,avg_ttl=0\r\n"
+	assert.Equal(t, int64(17), parseValkeyKeyspaceInfo(info))
+}
+
+func TestParseValkeyKeyspaceInfo_Empty(t *testing.T) {
+	assert.Equal(t, int64(0), parseValkeyKeyspaceInfo("# Keyspace\r\n"))
+}
+
+func TestValkeyPersistenceConfig(t *testing.T) {
+	rdb := valkeyPersistenceConfig("rdb")
+	assert.Contains(t, rdb, "save 3600 1 300 100 60 10000\n")
+	assert.Contains(t, rdb, "appendonly no\n")
+
+	aof := valkeyPersistenceConfig("aof")
+	assert.Contains(t, aof, "save \"\"\n")
+	assert.Contains(t, aof, "appendonly yes\n")
+
+	none := valkeyPersistenceConfig("none")
+	assert.Contains(t, none, "save \"\"\n")
+	assert.Contains(t, none, "appendonly no\n")
+}
+
+func TestValkeyPersistenceConfig_EmptyIsRDB(t *testing.T) {
+	assert.Equal(t, valkeyPersistenceConfig("rdb"), valkeyPersistenceConfig(""))
+}
+
+func TestParseValkeyConfigGet(t *testing.T) {
+	assert.Equal(t, "yes", parseValkeyConfigGet("appendonly\nyes"))
+	assert.Equal(t, "3600 1 300 100 60 10000", parseValkeyConfigGet("save\n3600 1 300 100 60 10000"))
+	assert.Equal(t, "", parseValkeyConfigGet("save"))
+}
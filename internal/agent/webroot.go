@@ -4,15 +4,18 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
+	"os/user"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/rs/zerolog"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
 	"github.com/edvin/hosting/internal/agent/runtime"
+	"github.com/edvin/hosting/internal/logging"
 )
 
 // WebrootManager handles webroot directory creation and cleanup on CephFS.
@@ -24,7 +27,7 @@ type WebrootManager struct {
 // NewWebrootManager creates a new WebrootManager.
 func NewWebrootManager(logger zerolog.Logger, cfg Config) *WebrootManager {
 	return &WebrootManager{
-		logger:        logger.With().Str("component", "webroot-manager").Logger(),
+		logger:        logging.Component(logger, "webroot-manager"),
 		webStorageDir: cfg.WebStorageDir,
 	}
 }
@@ -34,6 +37,21 @@ func (m *WebrootManager) storagePath(tenantName, webrootName string) string {
 	return filepath.Join(m.webStorageDir, tenantName, "webroots", webrootName)
 }
 
+// lookupTenantOwner returns the UID and GID of a tenant's system user, which
+// owns everything the tenant can write in its storage.
+func lookupTenantOwner(tenantName string) (uid, gid int, err error) {
+	u, err := user.Lookup(tenantName)
+	if err != nil {
+		return 0, 0, status.Errorf(codes.NotFound, "look up tenant user %s: %v", tenantName, err)
+	}
+	uid, _ = strconv.Atoi(u.Uid)
+	gid, _ = strconv.Atoi(u.Gid)
+	if uid == 0 {
+		return 0, 0, status.Errorf(codes.FailedPrecondition, "tenant user %s is root", tenantName)
+	}
+	return uid, gid, nil
+}
+
 // Create provisions a new webroot directory on CephFS.
 func (m *WebrootManager) Create(ctx context.Context, info *runtime.WebrootInfo) error {
 	if err := CheckMount(m.webStorageDir); err != nil {
@@ -66,7 +84,7 @@ func (m *WebrootManager) Create(ctx context.Context, info *runtime.WebrootInfo)
 	}
 
 	// Set ownership of the storage directory to the tenant user.
-	chownCmd := exec.CommandContext(ctx, "chown", "-R", fmt.Sprintf("%s:%s", tenantName, tenantName), storageDir)
+	chownCmd := cmdaudit.CommandContext(ctx, "chown", "-R", fmt.Sprintf("%s:%s", tenantName, tenantName), storageDir)
 	m.logger.Debug().Strs("cmd", chownCmd.Args).Msg("executing chown on webroot storage")
 	if output, err := chownCmd.CombinedOutput(); err != nil {
 		return status.Errorf(codes.Internal, "chown storage %s: %s: %v", storageDir, string(output), err)
@@ -135,6 +153,53 @@ func (m *WebrootManager) Delete(ctx context.Context, tenantName, webrootName str
 	return nil
 }
 
+// CopyFiles copies the storage directory of one webroot of a tenant into
+// another, preserving ownership, modes and symlinks. Release symlinks are
+// relative, so a copied current release points into the target's own
+// releases. Paths in exclude, relative to the webroot, are removed from the
+// target afterwards; they are used for files that are regenerated for the
+// target, such as its env file. Storage is shared, so this runs on one node
+// of the shard.
+func (m *WebrootManager) CopyFiles(ctx context.Context, tenantName, sourceName, targetName string, exclude []string) error {
+	if err := CheckMount(m.webStorageDir); err != nil {
+		return err
+	}
+
+	sourceDir := m.storagePath(tenantName, sourceName)
+	targetDir := m.storagePath(tenantName, targetName)
+	if !m.isValidStoragePath(sourceDir) || !m.isValidStoragePath(targetDir) || sourceDir == targetDir {
+		return status.Errorf(codes.InvalidArgument, "invalid webroot copy %s -> %s", sourceDir, targetDir)
+	}
+	if fi, err := os.Stat(sourceDir); err != nil || !fi.IsDir() {
+		return status.Errorf(codes.FailedPrecondition, "webroot %s has no storage directory", sourceName)
+	}
+
+	m.logger.Info().
+		Str("tenant", tenantName).
+		Str("source", sourceName).
+		Str("target", targetName).
+		Msg("copying webroot files")
+
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		return status.Errorf(codes.Internal, "mkdir storage %s: %v", targetDir, err)
+	}
+	cmd := cmdaudit.CommandContext(ctx, "cp", "-a", sourceDir+"/.", targetDir+"/")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return status.Errorf(codes.Internal, "copy %s to %s: %s: %v", sourceDir, targetDir, string(output), err)
+	}
+
+	for _, rel := range exclude {
+		path := filepath.Join(targetDir, rel)
+		if rel == "" || !strings.HasPrefix(path, targetDir+string(filepath.Separator)) {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return status.Errorf(codes.Internal, "remove %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
 // isValidStoragePath checks that the path is a subdirectory of the web storage
 // directory and contains no path traversal components.
 func (m *WebrootManager) isValidStoragePath(path string) bool {
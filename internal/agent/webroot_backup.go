@@ -0,0 +1,335 @@
+package agent
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ListBackupContents lists the entries of a web backup archive whose path
+// starts with prefix, returning at most limit of them. Truncated is set when
+// there are more.
+func (m *WebrootManager) ListBackupContents(archivePath, prefix string, limit int) (*model.BackupFileList, error) {
+	list := &model.BackupFileList{Files: []model.BackupFile{}}
+	err := walkBackupArchive(archivePath, func(name string, hdr *tar.Header, _ io.Reader) error {
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		if len(list.Files) == limit {
+			list.Truncated = true
+			return errStopWalk
+		}
+		list.Files = append(list.Files, model.BackupFile{
+			Path:    name,
+			Type:    backupFileType(hdr),
+			Size:    hdr.Size,
+			ModTime: hdr.ModTime.UTC(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// RestoreBackupFiles extracts the given paths from a web backup archive into
+// the webroot's storage dir. A path that names a directory restores
+// everything under it. Files that exist on disk with a newer modification
+// time than in the archive are skipped unless force is set. Only regular
+// files and directories are restored, owned by the tenant's user.
+//
+// The storage dir is writable by the tenant, so all writes go through an
+// os.Root on it: a directory swapped for a symlink mid-restore cannot send
+// a write outside, and existing symlinks on the way are refused.
+func (m *WebrootManager) RestoreBackupFiles(archivePath, tenantName, webrootName string, paths []string, force bool) (*model.BackupFilesRestore, error) {
+	if err := CheckMount(m.webStorageDir); err != nil {
+		return nil, err
+	}
+	uid, gid, err := lookupTenantOwner(tenantName)
+	if err != nil {
+		return nil, err
+	}
+	return m.restoreBackupFiles(archivePath, tenantName, webrootName, paths, force, uid, gid)
+}
+
+// restoreBackupFiles is RestoreBackupFiles with the owner of restored files
+// and directories already resolved.
+func (m *WebrootManager) restoreBackupFiles(archivePath, tenantName, webrootName string, paths []string, force bool, uid, gid int) (*model.BackupFilesRestore, error) {
+	wanted := make([]string, len(paths))
+	for i, p := range paths {
+		clean, err := cleanBackupPath(p)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		wanted[i] = clean
+	}
+
+	// Check every path is in the archive before writing anything.
+	found := make(map[string]bool, len(wanted))
+	err := walkBackupArchive(archivePath, func(name string, _ *tar.Header, _ io.Reader) error {
+		for _, w := range wanted {
+			if backupPathMatches(name, w) {
+				found[w] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, w := range wanted {
+		if !found[w] {
+			missing = append(missing, w)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, status.Errorf(codes.NotFound, "not in backup: %s", strings.Join(missing, ", "))
+	}
+
+	rootDir := m.storagePath(tenantName, webrootName)
+	if !m.isValidStoragePath(rootDir) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid storage path: %s", rootDir)
+	}
+	if fi, err := os.Lstat(rootDir); err != nil || !fi.IsDir() {
+		return nil, status.Errorf(codes.FailedPrecondition, "webroot storage dir %s is not a directory", rootDir)
+	}
+	root, err := os.OpenRoot(rootDir)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "open webroot storage dir %s: %v", rootDir, err)
+	}
+	defer root.Close()
+	rs := &backupRestorer{root: root, uid: uid, gid: gid}
+
+	result := &model.BackupFilesRestore{Restored: []string{}, Skipped: []string{}}
+	err = walkBackupArchive(archivePath, func(name string, hdr *tar.Header, r io.Reader) error {
+		matched := false
+		for _, w := range wanted {
+			if backupPathMatches(name, w) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return nil
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := rs.checkNoSymlinks(name); err != nil {
+				return err
+			}
+			if err := rs.mkdirAll(name); err != nil {
+				return fmt.Errorf("create %s: %w", name, err)
+			}
+		case tar.TypeReg:
+			if err := rs.checkNoSymlinks(name); err != nil {
+				return err
+			}
+			if fi, err := root.Lstat(name); err == nil && !force && fi.ModTime().After(hdr.ModTime) {
+				result.Skipped = append(result.Skipped, name)
+				return nil
+			}
+			if err := rs.writeFile(name, hdr, r); err != nil {
+				return fmt.Errorf("restore %s: %w", name, err)
+			}
+			result.Restored = append(result.Restored, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.logger.Info().
+		Str("tenant", tenantName).
+		Str("webroot", webrootName).
+		Int("restored", len(result.Restored)).
+		Int("skipped", len(result.Skipped)).
+		Msg("restored files from backup")
+	return result, nil
+}
+
+var errStopWalk = errors.New("stop walk")
+
+// walkBackupArchive calls fn for each entry of a tar.gz archive with its
+// cleaned relative path. Entries that would land outside the archive root
+// are ignored. fn may return errStopWalk to end the walk early.
+func walkBackupArchive(archivePath string, fn func(name string, hdr *tar.Header, r io.Reader) error) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return status.Errorf(codes.NotFound, "open backup %s: %v", archivePath, err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("read backup %s: %w", archivePath, err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read backup %s: %w", archivePath, err)
+		}
+		name, err := cleanBackupPath(hdr.Name)
+		if err != nil {
+			continue
+		}
+		if err := fn(name, hdr, tr); err != nil {
+			if errors.Is(err, errStopWalk) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// cleanBackupPath normalizes a path relative to the webroot's storage dir, as
+// stored in backups ("./public/index.php" becomes "public/index.php"), and
+// rejects absolute paths, ".." components and the root itself.
+func cleanBackupPath(p string) (string, error) {
+	if strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("path %q must be relative to the webroot", p)
+	}
+	for _, part := range strings.Split(p, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("path %q must not contain ..", p)
+		}
+	}
+	clean := path.Clean(p)
+	if clean == "." {
+		return "", fmt.Errorf("path %q names the webroot itself", p)
+	}
+	return clean, nil
+}
+
+// backupPathMatches reports whether an archive entry is want or under it.
+func backupPathMatches(name, want string) bool {
+	return name == want || strings.HasPrefix(name, want+"/")
+}
+
+// backupRestorer writes archive entries into a webroot's storage dir
+// through an os.Root, owned by uid:gid.
+type backupRestorer struct {
+	root     *os.Root
+	uid, gid int
+}
+
+// checkNoSymlinks refuses to restore name if it or a directory on the way to
+// it is an existing symlink. The os.Root already keeps writes inside the
+// storage dir; this keeps them from landing elsewhere in it.
+func (rs *backupRestorer) checkNoSymlinks(name string) error {
+	dest := ""
+	for _, part := range strings.Split(name, "/") {
+		dest = path.Join(dest, part)
+		fi, err := rs.root.Lstat(dest)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("stat %s: %w", dest, err)
+		}
+		if fi.Mode()&fs.ModeSymlink != 0 {
+			return status.Errorf(codes.FailedPrecondition, "refusing to restore %s through symlink %s", name, dest)
+		}
+	}
+	return nil
+}
+
+// mkdirAll creates dir and any missing parents, giving the ones it creates
+// to the tenant.
+func (rs *backupRestorer) mkdirAll(dir string) error {
+	cur := ""
+	for _, part := range strings.Split(dir, "/") {
+		cur = path.Join(cur, part)
+		err := rs.root.Mkdir(cur, 0755)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := rs.chown(cur); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFile replaces name with the archive entry's content through a
+// temporary file in the same directory, keeping its mode and mtime.
+func (rs *backupRestorer) writeFile(name string, hdr *tar.Header, r io.Reader) error {
+	if dir := path.Dir(name); dir != "." {
+		if err := rs.mkdirAll(dir); err != nil {
+			return err
+		}
+	}
+	tmp := path.Join(path.Dir(name), ".restore-"+rand.Text())
+	f, err := rs.root.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	defer rs.root.Remove(tmp)
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if os.Geteuid() == 0 {
+		if err := f.Chown(rs.uid, rs.gid); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	// Chown clears setuid and setgid bits, so the mode is set after it.
+	if err := f.Chmod(os.FileMode(hdr.Mode).Perm()); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := rs.root.Chtimes(tmp, hdr.ModTime, hdr.ModTime); err != nil {
+		return err
+	}
+	return rs.root.Rename(tmp, name)
+}
+
+// chown gives a restored directory to the tenant. Ownership recorded in the
+// archive is ignored: the tenant's UID may have changed since the backup.
+func (rs *backupRestorer) chown(name string) error {
+	if os.Geteuid() != 0 {
+		return nil
+	}
+	return rs.root.Lchown(name, rs.uid, rs.gid)
+}
+
+func backupFileType(hdr *tar.Header) string {
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return model.BackupFileTypeDir
+	case tar.TypeSymlink:
+		return model.BackupFileTypeSymlink
+	default:
+		return model.BackupFileTypeFile
+	}
+}
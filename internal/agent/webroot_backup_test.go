@@ -0,0 +1,212 @@
+package agent
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+var backupTime = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+// backupUID is the owner recorded in test archives, which restores ignore.
+const backupUID = 4242
+
+// writeTestBackup writes a tar.gz laid out like CreateWebBackup's, with
+// "./"-prefixed names. Entries ending in "/" are directories.
+func writeTestBackup(t *testing.T, entries map[string]string, order ...string) string {
+	t.Helper()
+	archive := filepath.Join(t.TempDir(), "backup.tar.gz")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755, ModTime: backupTime}))
+	for _, name := range order {
+		hdr := &tar.Header{Name: "./" + name, Mode: 0644, ModTime: backupTime, Uid: backupUID, Gid: backupUID}
+		switch {
+		case name[len(name)-1] == '/':
+			hdr.Typeflag, hdr.Mode = tar.TypeDir, 0755
+		case entries[name] == "->":
+			hdr.Typeflag, hdr.Linkname = tar.TypeSymlink, "/etc/passwd"
+		default:
+			hdr.Typeflag, hdr.Size = tar.TypeReg, int64(len(entries[name]))
+		}
+		require.NoError(t, tw.WriteHeader(hdr))
+		if hdr.Typeflag == tar.TypeReg {
+			_, err := tw.Write([]byte(entries[name]))
+			require.NoError(t, err)
+		}
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	require.NoError(t, f.Close())
+	return archive
+}
+
+func testBackup(t *testing.T) string {
+	return writeTestBackup(t, map[string]string{
+		"public/index.php":     "<?php echo 'hi';",
+		"public/uploads/a.jpg": "jpeg-a",
+		"public/uploads/b.jpg": "jpeg-b",
+		"public/link":          "->",
+		"wp-config.php":        "<?php // config",
+	}, "public/", "public/index.php", "public/uploads/", "public/uploads/a.jpg", "public/uploads/b.jpg", "public/link", "wp-config.php")
+}
+
+func newBackupTestWebroot(t *testing.T) (*WebrootManager, string) {
+	t.Helper()
+	t.Setenv("CEPHFS_ENABLED", "false")
+	mgr := newTestWebrootManager(t)
+	root := mgr.storagePath("tenant1", "mysite")
+	require.NoError(t, os.MkdirAll(root, 0755))
+	return mgr, root
+}
+
+func TestWebrootManager_ListBackupContents(t *testing.T) {
+	mgr := newTestWebrootManager(t)
+	archive := testBackup(t)
+
+	list, err := mgr.ListBackupContents(archive, "", 100)
+	require.NoError(t, err)
+	assert.False(t, list.Truncated)
+	require.Len(t, list.Files, 7)
+	assert.Equal(t, model.BackupFile{Path: "public/index.php", Type: model.BackupFileTypeFile, Size: 16, ModTime: backupTime}, list.Files[1])
+	assert.Equal(t, model.BackupFileTypeDir, list.Files[0].Type)
+	assert.Equal(t, model.BackupFileTypeSymlink, list.Files[5].Type)
+
+	list, err = mgr.ListBackupContents(archive, "public/uploads/", 1)
+	require.NoError(t, err)
+	assert.True(t, list.Truncated)
+	require.Len(t, list.Files, 1)
+	assert.Equal(t, "public/uploads/a.jpg", list.Files[0].Path)
+}
+
+func TestWebrootManager_RestoreBackupFiles(t *testing.T) {
+	mgr, root := newBackupTestWebroot(t)
+	archive := testBackup(t)
+
+	res, err := mgr.restoreBackupFiles(archive, "tenant1", "mysite", []string{"wp-config.php", "public/uploads"}, false, os.Getuid(), os.Getgid())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"public/uploads/a.jpg", "public/uploads/b.jpg", "wp-config.php"}, res.Restored)
+	assert.Empty(t, res.Skipped)
+
+	data, err := os.ReadFile(filepath.Join(root, "public/uploads/b.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg-b", string(data))
+	fi, err := os.Stat(filepath.Join(root, "wp-config.php"))
+	require.NoError(t, err)
+	assert.True(t, fi.ModTime().Equal(backupTime))
+
+	// Nothing else is restored.
+	_, err = os.Stat(filepath.Join(root, "public/index.php"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWebrootManager_RestoreBackupFiles_SkipsNewerUnlessForced(t *testing.T) {
+	mgr, root := newBackupTestWebroot(t)
+	archive := testBackup(t)
+
+	target := filepath.Join(root, "wp-config.php")
+	require.NoError(t, os.WriteFile(target, []byte("edited"), 0644))
+
+	res, err := mgr.restoreBackupFiles(archive, "tenant1", "mysite", []string{"wp-config.php"}, false, os.Getuid(), os.Getgid())
+	require.NoError(t, err)
+	assert.Empty(t, res.Restored)
+	assert.Equal(t, []string{"wp-config.php"}, res.Skipped)
+	data, _ := os.ReadFile(target)
+	assert.Equal(t, "edited", string(data))
+
+	res, err = mgr.restoreBackupFiles(archive, "tenant1", "mysite", []string{"wp-config.php"}, true, os.Getuid(), os.Getgid())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wp-config.php"}, res.Restored)
+	data, _ = os.ReadFile(target)
+	assert.Equal(t, "<?php // config", string(data))
+}
+
+func TestWebrootManager_RestoreBackupFiles_RejectsTraversal(t *testing.T) {
+	mgr, _ := newBackupTestWebroot(t)
+	archive := testBackup(t)
+
+	for _, p := range []string{"../other/wp-config.php", "public/../../x", "/etc/passwd", "."} {
+		_, err := mgr.restoreBackupFiles(archive, "tenant1", "mysite", []string{p}, false, os.Getuid(), os.Getgid())
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), p)
+	}
+}
+
+func TestWebrootManager_RestoreBackupFiles_NotInBackup(t *testing.T) {
+	mgr, _ := newBackupTestWebroot(t)
+	archive := testBackup(t)
+
+	_, err := mgr.restoreBackupFiles(archive, "tenant1", "mysite", []string{"wp-config.php", "missing.txt"}, false, os.Getuid(), os.Getgid())
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Contains(t, err.Error(), "missing.txt")
+}
+
+func TestWebrootManager_RestoreBackupFiles_RefusesSymlinkedDir(t *testing.T) {
+	mgr, root := newBackupTestWebroot(t)
+	archive := testBackup(t)
+
+	outside := t.TempDir()
+	require.NoError(t, os.Symlink(outside, filepath.Join(root, "public")))
+
+	_, err := mgr.restoreBackupFiles(archive, "tenant1", "mysite", []string{"public/index.php"}, false, os.Getuid(), os.Getgid())
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	_, err = os.Stat(filepath.Join(outside, "index.php"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWebrootManager_RestoreBackupFiles_SkipsArchiveSymlinks(t *testing.T) {
+	mgr, root := newBackupTestWebroot(t)
+	archive := testBackup(t)
+
+	res, err := mgr.restoreBackupFiles(archive, "tenant1", "mysite", []string{"public/link"}, false, os.Getuid(), os.Getgid())
+	require.NoError(t, err)
+	assert.Empty(t, res.Restored)
+	_, err = os.Lstat(filepath.Join(root, "public/link"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWebrootManager_RestoreBackupFiles_OwnedByTenant(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("chown needs root")
+	}
+	mgr, root := newBackupTestWebroot(t)
+	archive := testBackup(t)
+
+	_, err := mgr.restoreBackupFiles(archive, "tenant1", "mysite", []string{"public/uploads/a.jpg"}, false, 1234, 1234)
+	require.NoError(t, err)
+
+	for _, p := range []string{"public", "public/uploads", "public/uploads/a.jpg"} {
+		fi, err := os.Lstat(filepath.Join(root, p))
+		require.NoError(t, err)
+		st := fi.Sys().(*syscall.Stat_t)
+		assert.Equal(t, uint32(1234), st.Uid, p)
+		assert.Equal(t, uint32(1234), st.Gid, p)
+	}
+}
+
+func TestWebrootManager_RestoreBackupFiles_RefusesSymlinkedStorageDir(t *testing.T) {
+	t.Setenv("CEPHFS_ENABLED", "false")
+	mgr := newTestWebrootManager(t)
+	outside := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Dir(mgr.storagePath("tenant1", "mysite")), 0755))
+	require.NoError(t, os.Symlink(outside, mgr.storagePath("tenant1", "mysite")))
+
+	_, err := mgr.restoreBackupFiles(testBackup(t), "tenant1", "mysite", []string{"wp-config.php"}, false, os.Getuid(), os.Getgid())
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	entries, err := os.ReadDir(outside)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -0,0 +1,185 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/edvin/hosting/internal/agent/runtime"
+)
+
+// HasReleases reports whether a release has been promoted for the webroot,
+// i.e. whether it is served from its current release symlink.
+func (m *WebrootManager) HasReleases(tenantName, webrootName string) bool {
+	return runtime.HasReleases(m.storagePath(tenantName, webrootName))
+}
+
+// releasePath returns the directory of a release, rejecting names that would
+// escape the webroot's releases directory.
+func (m *WebrootManager) releasePath(tenantName, webrootName, release string) (string, error) {
+	if release == "" || release == "." || release == ".." || strings.ContainsAny(release, `/\`) {
+		return "", status.Errorf(codes.InvalidArgument, "invalid release name %q", release)
+	}
+	return filepath.Join(m.storagePath(tenantName, webrootName), runtime.ReleasesDir, release), nil
+}
+
+// CreateRelease creates an empty release directory, owned by the tenant, for
+// the tenant to upload a deploy into. The release must not exist yet.
+//
+// Release names are predictable and the releases directory is writable by
+// the tenant, so everything goes through an os.Root on the tenant's chroot
+// and symlinks are refused rather than followed.
+func (m *WebrootManager) CreateRelease(ctx context.Context, tenantName, webrootName, release string) error {
+	if err := CheckMount(m.webStorageDir); err != nil {
+		return err
+	}
+	if _, err := m.releasePath(tenantName, webrootName, release); err != nil {
+		return err
+	}
+	if tenantName == "" || tenantName == "." || tenantName == ".." || tenantName != filepath.Base(tenantName) {
+		return status.Errorf(codes.InvalidArgument, "invalid tenant name %q", tenantName)
+	}
+	if webrootName == "" || webrootName == "." || webrootName == ".." || webrootName != filepath.Base(webrootName) {
+		return status.Errorf(codes.InvalidArgument, "invalid webroot name %q", webrootName)
+	}
+
+	uid, gid, err := lookupTenantOwner(tenantName)
+	if err != nil {
+		return err
+	}
+
+	root, err := os.OpenRoot(filepath.Join(m.webStorageDir, tenantName))
+	if err != nil {
+		return status.Errorf(codes.Internal, "open tenant storage %s: %v", tenantName, err)
+	}
+	defer root.Close()
+
+	m.logger.Info().
+		Str("tenant", tenantName).
+		Str("webroot", webrootName).
+		Str("release", release).
+		Msg("creating webroot release")
+
+	return createReleaseDir(root, webrootName, release, uid, gid)
+}
+
+// createReleaseDir creates webroots/<webrootName>/releases/<release> in a
+// tenant's chroot and hands it and the releases directory to uid:gid. The
+// webroot and releases directories must be real directories, not symlinks,
+// and the release must not exist.
+func createReleaseDir(root *os.Root, webrootName, release string, uid, gid int) error {
+	webrootDir := filepath.Join("webroots", webrootName)
+	if err := requireRealDir(root, webrootDir); err != nil {
+		return err
+	}
+
+	releasesDir := filepath.Join(webrootDir, runtime.ReleasesDir)
+	if err := root.Mkdir(releasesDir, 0755); err != nil && !errors.Is(err, fs.ErrExist) {
+		return status.Errorf(codes.Internal, "mkdir %s: %v", releasesDir, err)
+	}
+	if err := requireRealDir(root, releasesDir); err != nil {
+		return err
+	}
+	if err := root.Lchown(releasesDir, uid, gid); err != nil {
+		return status.Errorf(codes.Internal, "chown %s: %v", releasesDir, err)
+	}
+
+	releaseDir := filepath.Join(releasesDir, release)
+	if err := root.Mkdir(releaseDir, 0755); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return status.Errorf(codes.AlreadyExists, "release %s already exists", release)
+		}
+		return status.Errorf(codes.Internal, "mkdir release %s: %v", releaseDir, err)
+	}
+	if err := root.Lchown(releaseDir, uid, gid); err != nil {
+		return status.Errorf(codes.Internal, "chown release %s: %v", releaseDir, err)
+	}
+	return nil
+}
+
+// requireRealDir fails unless path in root is a directory. Symlinks are not
+// followed.
+func requireRealDir(root *os.Root, path string) error {
+	fi, err := root.Lstat(path)
+	if err != nil {
+		return status.Errorf(codes.FailedPrecondition, "stat %s: %v", path, err)
+	}
+	if !fi.IsDir() {
+		return status.Errorf(codes.FailedPrecondition, "%s is not a directory", path)
+	}
+	return nil
+}
+
+// PromoteRelease points the webroot's current symlink at release. The new
+// link is created next to the old one and renamed over it, so requests see
+// either the old or the new release and never a missing document root.
+func (m *WebrootManager) PromoteRelease(ctx context.Context, tenantName, webrootName, release string) error {
+	if err := CheckMount(m.webStorageDir); err != nil {
+		return err
+	}
+	releaseDir, err := m.releasePath(tenantName, webrootName, release)
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(releaseDir); err != nil || !fi.IsDir() {
+		return status.Errorf(codes.FailedPrecondition, "release %s does not exist", release)
+	}
+
+	m.logger.Info().
+		Str("tenant", tenantName).
+		Str("webroot", webrootName).
+		Str("release", release).
+		Msg("promoting webroot release")
+
+	webrootDir := m.storagePath(tenantName, webrootName)
+	current := filepath.Join(webrootDir, runtime.CurrentRelease)
+	tmp := filepath.Join(webrootDir, "."+runtime.CurrentRelease+".tmp")
+
+	// The target is relative so the link resolves the same way inside the
+	// tenant's SFTP chroot as on the host.
+	_ = os.Remove(tmp)
+	if err := os.Symlink(filepath.Join(runtime.ReleasesDir, release), tmp); err != nil {
+		return status.Errorf(codes.Internal, "create release symlink: %v", err)
+	}
+	if err := os.Rename(tmp, current); err != nil {
+		_ = os.Remove(tmp)
+		return status.Errorf(codes.Internal, "swap current release symlink: %v", err)
+	}
+	return nil
+}
+
+// DeleteRelease removes a release directory. The live release is refused.
+func (m *WebrootManager) DeleteRelease(ctx context.Context, tenantName, webrootName, release string) error {
+	if err := CheckMount(m.webStorageDir); err != nil {
+		return err
+	}
+	releaseDir, err := m.releasePath(tenantName, webrootName, release)
+	if err != nil {
+		return err
+	}
+
+	current := filepath.Join(m.storagePath(tenantName, webrootName), runtime.CurrentRelease)
+	if target, err := os.Readlink(current); err == nil && filepath.Base(target) == release {
+		return status.Errorf(codes.FailedPrecondition, "release %s is live", release)
+	}
+
+	m.logger.Info().
+		Str("tenant", tenantName).
+		Str("webroot", webrootName).
+		Str("release", release).
+		Msg("deleting webroot release")
+
+	if !m.isValidStoragePath(releaseDir) {
+		return status.Errorf(codes.Internal, "refusing to remove invalid storage path: %s", releaseDir)
+	}
+	if err := os.RemoveAll(releaseDir); err != nil {
+		return status.Errorf(codes.Internal, "remove release %s: %v", releaseDir, err)
+	}
+	return nil
+}
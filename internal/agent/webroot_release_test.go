@@ -0,0 +1,151 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// makeRelease creates a release directory directly, bypassing the tenant user
+// lookup in CreateRelease.
+func makeRelease(t *testing.T, mgr *WebrootManager, release string) string {
+	t.Helper()
+	dir, err := mgr.releasePath("tenant1", "mysite", release)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	return dir
+}
+
+func TestWebrootManager_PromoteRelease_SwapsSymlink(t *testing.T) {
+	t.Setenv("CEPHFS_ENABLED", "false")
+	mgr := newTestWebrootManager(t)
+	ctx := context.Background()
+
+	makeRelease(t, mgr, "20260101T000000Z")
+	makeRelease(t, mgr, "20260102T000000Z")
+	assert.False(t, mgr.HasReleases("tenant1", "mysite"))
+
+	require.NoError(t, mgr.PromoteRelease(ctx, "tenant1", "mysite", "20260101T000000Z"))
+	assert.True(t, mgr.HasReleases("tenant1", "mysite"))
+
+	current := filepath.Join(mgr.storagePath("tenant1", "mysite"), "current")
+	target, err := os.Readlink(current)
+	require.NoError(t, err)
+	assert.Equal(t, "releases/20260101T000000Z", target)
+
+	require.NoError(t, mgr.PromoteRelease(ctx, "tenant1", "mysite", "20260102T000000Z"))
+	target, err = os.Readlink(current)
+	require.NoError(t, err)
+	assert.Equal(t, "releases/20260102T000000Z", target)
+
+	// No temporary link is left behind.
+	_, err = os.Lstat(filepath.Join(mgr.storagePath("tenant1", "mysite"), ".current.tmp"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWebrootManager_PromoteRelease_MissingRelease(t *testing.T) {
+	t.Setenv("CEPHFS_ENABLED", "false")
+	mgr := newTestWebrootManager(t)
+
+	err := mgr.PromoteRelease(context.Background(), "tenant1", "mysite", "20260101T000000Z")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+	assert.False(t, mgr.HasReleases("tenant1", "mysite"))
+}
+
+func TestWebrootManager_ReleasePath_RejectsTraversal(t *testing.T) {
+	mgr := newTestWebrootManager(t)
+
+	for _, name := range []string{"", ".", "..", "../other", "a/b"} {
+		_, err := mgr.releasePath("tenant1", "mysite", name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestWebrootManager_DeleteRelease(t *testing.T) {
+	t.Setenv("CEPHFS_ENABLED", "false")
+	mgr := newTestWebrootManager(t)
+	ctx := context.Background()
+
+	oldDir := makeRelease(t, mgr, "20260101T000000Z")
+	makeRelease(t, mgr, "20260102T000000Z")
+	require.NoError(t, mgr.PromoteRelease(ctx, "tenant1", "mysite", "20260102T000000Z"))
+
+	err := mgr.DeleteRelease(ctx, "tenant1", "mysite", "20260102T000000Z")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "is live")
+
+	require.NoError(t, mgr.DeleteRelease(ctx, "tenant1", "mysite", "20260101T000000Z"))
+	_, err = os.Stat(oldDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func openTestChroot(t *testing.T) (string, *os.Root) {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "webroots", "mysite"), 0755))
+	root, err := os.OpenRoot(dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { root.Close() })
+	return dir, root
+}
+
+func TestCreateReleaseDir(t *testing.T) {
+	dir, root := openTestChroot(t)
+
+	require.NoError(t, createReleaseDir(root, "mysite", "20260101T000000Z", os.Getuid(), os.Getgid()))
+	fi, err := os.Lstat(filepath.Join(dir, "webroots", "mysite", "releases", "20260101T000000Z"))
+	require.NoError(t, err)
+	assert.True(t, fi.IsDir())
+
+	// A second release reuses the releases directory.
+	require.NoError(t, createReleaseDir(root, "mysite", "20260102T000000Z", os.Getuid(), os.Getgid()))
+}
+
+func TestCreateReleaseDir_ExistingRelease(t *testing.T) {
+	_, root := openTestChroot(t)
+
+	require.NoError(t, createReleaseDir(root, "mysite", "20260101T000000Z", os.Getuid(), os.Getgid()))
+	err := createReleaseDir(root, "mysite", "20260101T000000Z", os.Getuid(), os.Getgid())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+}
+
+func TestCreateReleaseDir_RejectsSymlinks(t *testing.T) {
+	outside := t.TempDir()
+
+	t.Run("release", func(t *testing.T) {
+		dir, root := openTestChroot(t)
+		releases := filepath.Join(dir, "webroots", "mysite", "releases")
+		require.NoError(t, os.Mkdir(releases, 0755))
+		require.NoError(t, os.Symlink(outside, filepath.Join(releases, "20260101T000000Z")))
+
+		err := createReleaseDir(root, "mysite", "20260101T000000Z", os.Getuid(), os.Getgid())
+		require.Error(t, err)
+	})
+
+	t.Run("releases dir", func(t *testing.T) {
+		dir, root := openTestChroot(t)
+		require.NoError(t, os.Symlink("..", filepath.Join(dir, "webroots", "mysite", "releases")))
+
+		err := createReleaseDir(root, "mysite", "20260101T000000Z", os.Getuid(), os.Getgid())
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a directory")
+	})
+
+	t.Run("webroot dir", func(t *testing.T) {
+		dir, root := openTestChroot(t)
+		require.NoError(t, os.Symlink(outside, filepath.Join(dir, "webroots", "other")))
+
+		err := createReleaseDir(root, "other", "20260101T000000Z", os.Getuid(), os.Getgid())
+		require.Error(t, err)
+	})
+
+	entries, err := os.ReadDir(outside)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
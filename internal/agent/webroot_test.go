@@ -1,10 +1,14 @@
 package agent
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func newTestWebrootManager(t *testing.T) *WebrootManager {
@@ -76,3 +80,46 @@ func TestWebrootManager_IsValidStoragePath_PathTraversal(t *testing.T) {
 		})
 	}
 }
+
+func TestWebrootManager_CopyFiles(t *testing.T) {
+	t.Setenv("CEPHFS_ENABLED", "false")
+	mgr := newTestWebrootManager(t)
+
+	src := mgr.storagePath("tenant1", "mysite")
+	require.NoError(t, os.MkdirAll(filepath.Join(src, "releases", "r1", "public"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(src, "releases", "r1", "public", "index.php"), []byte("<?php"), 0644))
+	require.NoError(t, os.Symlink("releases/r1", filepath.Join(src, "current")))
+	require.NoError(t, os.WriteFile(filepath.Join(src, ".env.hosting"), []byte("SECRET=x"), 0600))
+	require.NoError(t, os.MkdirAll(filepath.Join(src, ".bin"), 0755))
+
+	err := mgr.CopyFiles(context.Background(), "tenant1", "mysite", "staging", []string{".env.hosting", ".bin", "../mysite"})
+	require.NoError(t, err)
+
+	dst := mgr.storagePath("tenant1", "staging")
+	data, err := os.ReadFile(filepath.Join(dst, "current", "public", "index.php"))
+	require.NoError(t, err)
+	assert.Equal(t, "<?php", string(data))
+
+	// The release symlink stays relative to the clone.
+	target, err := os.Readlink(filepath.Join(dst, "current"))
+	require.NoError(t, err)
+	assert.Equal(t, "releases/r1", target)
+
+	_, err = os.Stat(filepath.Join(dst, ".env.hosting"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dst, ".bin"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Excludes never reach outside the target.
+	_, err = os.Stat(filepath.Join(src, ".env.hosting"))
+	assert.NoError(t, err)
+}
+
+func TestWebrootManager_CopyFiles_MissingSource(t *testing.T) {
+	t.Setenv("CEPHFS_ENABLED", "false")
+	mgr := newTestWebrootManager(t)
+
+	err := mgr.CopyFiles(context.Background(), "tenant1", "missing", "staging", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no storage directory")
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
 
 	"github.com/rs/zerolog"
+
+	"github.com/edvin/hosting/internal/agent/cmdaudit"
+	"github.com/edvin/hosting/internal/logging"
 )
 
 const (
@@ -25,7 +27,7 @@ type WireGuardManager struct {
 // NewWireGuardManager creates a new WireGuardManager.
 func NewWireGuardManager(logger zerolog.Logger) *WireGuardManager {
 	return &WireGuardManager{
-		logger: logger.With().Str("component", "wireguard").Logger(),
+		logger: logging.Component(logger, "wireguard"),
 	}
 }
 
@@ -42,16 +44,16 @@ func (m *WireGuardManager) GetPublicKey(ctx context.Context) (string, error) {
 // reading the private key from /etc/wireguard/server.key.
 func (m *WireGuardManager) ensureInterface(ctx context.Context) error {
 	// Check if wg0 already exists and is up.
-	if _, err := exec.CommandContext(ctx, "ip", "link", "show", wgInterface).CombinedOutput(); err == nil {
+	if _, err := cmdaudit.CommandContext(ctx, "ip", "link", "show", wgInterface).CombinedOutput(); err == nil {
 		return nil // Already exists.
 	}
 
 	// Create the interface.
-	if out, err := exec.CommandContext(ctx, "ip", "link", "add", wgInterface, "type", "wireguard").CombinedOutput(); err != nil {
+	if out, err := cmdaudit.CommandContext(ctx, "ip", "link", "add", wgInterface, "type", "wireguard").CombinedOutput(); err != nil {
 		return fmt.Errorf("create %s: %s: %w", wgInterface, string(out), err)
 	}
 
-	if out, err := exec.CommandContext(ctx, "wg", "set", wgInterface,
+	if out, err := cmdaudit.CommandContext(ctx, "wg", "set", wgInterface,
 		"listen-port", fmt.Sprintf("%d", wgListenPort),
 		"private-key", wgKeyFile,
 	).CombinedOutput(); err != nil {
@@ -59,7 +61,7 @@ func (m *WireGuardManager) ensureInterface(ctx context.Context) error {
 	}
 
 	// Bring interface up.
-	if out, err := exec.CommandContext(ctx, "ip", "link", "set", wgInterface, "up").CombinedOutput(); err != nil {
+	if out, err := cmdaudit.CommandContext(ctx, "ip", "link", "set", wgInterface, "up").CombinedOutput(); err != nil {
 		return fmt.Errorf("ip link set %s up: %s: %w", wgInterface, string(out), err)
 	}
 
@@ -95,7 +97,7 @@ func (m *WireGuardManager) AddPeer(ctx context.Context, params AddPeerParams) er
 
 	allowedIPs := params.AssignedIP + "/128"
 
-	if out, err := exec.CommandContext(ctx, "wg", "set", wgInterface,
+	if out, err := cmdaudit.CommandContext(ctx, "wg", "set", wgInterface,
 		"peer", params.PublicKey,
 		"preshared-key", pskFile.Name(),
 		"allowed-ips", allowedIPs,
@@ -104,7 +106,7 @@ func (m *WireGuardManager) AddPeer(ctx context.Context, params AddPeerParams) er
 	}
 
 	// Add route for the peer's assigned IP.
-	if out, err := exec.CommandContext(ctx, "ip", "-6", "route", "replace",
+	if out, err := cmdaudit.CommandContext(ctx, "ip", "-6", "route", "replace",
 		params.AssignedIP+"/128", "dev", wgInterface).CombinedOutput(); err != nil {
 		return fmt.Errorf("ip route add %s: %s: %w", params.AssignedIP, string(out), err)
 	}
@@ -122,14 +124,14 @@ func (m *WireGuardManager) RemovePeer(ctx context.Context, publicKey string, ass
 		return fmt.Errorf("ensure wg interface: %w", err)
 	}
 
-	if out, err := exec.CommandContext(ctx, "wg", "set", wgInterface,
+	if out, err := cmdaudit.CommandContext(ctx, "wg", "set", wgInterface,
 		"peer", publicKey, "remove",
 	).CombinedOutput(); err != nil {
 		return fmt.Errorf("wg remove peer %s: %s: %w", publicKey, string(out), err)
 	}
 
 	// Remove route.
-	exec.CommandContext(ctx, "ip", "-6", "route", "del", assignedIP+"/128", "dev", wgInterface).CombinedOutput()
+	cmdaudit.CommandContext(ctx, "ip", "-6", "route", "del", assignedIP+"/128", "dev", wgInterface).CombinedOutput()
 
 	// Remove nftables FORWARD rules.
 	m.removeForwardRules(ctx, assignedIP)
@@ -159,7 +161,7 @@ func (m *WireGuardManager) SyncPeers(ctx context.Context, peers []AddPeerParams)
 	// Remove peers not in desired state.
 	for _, pubkey := range currentPeers {
 		if _, ok := desired[pubkey]; !ok {
-			exec.CommandContext(ctx, "wg", "set", wgInterface, "peer", pubkey, "remove").CombinedOutput()
+			cmdaudit.CommandContext(ctx, "wg", "set", wgInterface, "peer", pubkey, "remove").CombinedOutput()
 		}
 	}
 
@@ -178,7 +180,7 @@ func (m *WireGuardManager) SyncPeers(ctx context.Context, peers []AddPeerParams)
 }
 
 func (m *WireGuardManager) listCurrentPeers(ctx context.Context) ([]string, error) {
-	out, err := exec.CommandContext(ctx, "wg", "show", wgInterface, "peers").CombinedOutput()
+	out, err := cmdaudit.CommandContext(ctx, "wg", "show", wgInterface, "peers").CombinedOutput()
 	if err != nil {
 		// Interface may not exist yet.
 		return nil, nil
@@ -195,19 +197,19 @@ func (m *WireGuardManager) listCurrentPeers(ctx context.Context) ([]string, erro
 
 func (m *WireGuardManager) addForwardRules(ctx context.Context, srcIP string, allowedDstIPs []string) {
 	// Ensure the wg_forward table and chain exist.
-	exec.CommandContext(ctx, "nft", "add", "table", "ip6", "wg_forward").CombinedOutput()
-	exec.CommandContext(ctx, "nft", "add", "chain", "ip6", "wg_forward", "forward",
+	cmdaudit.CommandContext(ctx, "nft", "add", "table", "ip6", "wg_forward").CombinedOutput()
+	cmdaudit.CommandContext(ctx, "nft", "add", "chain", "ip6", "wg_forward", "forward",
 		"{ type filter hook forward priority 0 ; policy drop ; }").CombinedOutput()
 
 	for _, dst := range allowedDstIPs {
-		exec.CommandContext(ctx, "nft", "add", "rule", "ip6", "wg_forward", "forward",
+		cmdaudit.CommandContext(ctx, "nft", "add", "rule", "ip6", "wg_forward", "forward",
 			"ip6", "saddr", srcIP, "ip6", "daddr", dst, "accept").CombinedOutput()
 	}
 }
 
 func (m *WireGuardManager) removeForwardRules(ctx context.Context, srcIP string) {
 	// List rules with handles and delete matching ones.
-	out, err := exec.CommandContext(ctx, "nft", "-a", "list", "chain", "ip6", "wg_forward", "forward").CombinedOutput()
+	out, err := cmdaudit.CommandContext(ctx, "nft", "-a", "list", "chain", "ip6", "wg_forward", "forward").CombinedOutput()
 	if err != nil {
 		return
 	}
@@ -216,7 +218,7 @@ func (m *WireGuardManager) removeForwardRules(ctx context.Context, srcIP string)
 			parts := strings.Fields(line)
 			for i, p := range parts {
 				if p == "handle" && i+1 < len(parts) {
-					exec.CommandContext(ctx, "nft", "delete", "rule", "ip6", "wg_forward", "forward", "handle", parts[i+1]).CombinedOutput()
+					cmdaudit.CommandContext(ctx, "nft", "delete", "rule", "ip6", "wg_forward", "forward", "handle", parts[i+1]).CombinedOutput()
 				}
 			}
 		}
@@ -239,7 +241,7 @@ func (m *WireGuardManager) rebuildForwardTable(ctx context.Context, peers []AddP
 	b.WriteString("    }\n")
 	b.WriteString("}\n")
 
-	cmd := exec.CommandContext(ctx, "nft", "-f", "-")
+	cmd := cmdaudit.CommandContext(ctx, "nft", "-f", "-")
 	cmd.Stdin = strings.NewReader(b.String())
 	if out, err := cmd.CombinedOutput(); err != nil {
 		m.logger.Warn().Err(err).Str("output", string(out)).Msg("failed to rebuild wg_forward table")
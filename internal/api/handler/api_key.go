@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 
+	mw "github.com/edvin/hosting/internal/api/middleware"
 	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/api/response"
 	"github.com/edvin/hosting/internal/core"
@@ -173,3 +174,24 @@ func (h *APIKey) Revoke(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// Me godoc
+//
+//	@Summary		Get the calling API key's identity
+//	@Description	Returns the ID, scopes, brand access, and reseller of the API key making the request. Control panels call this at login to resolve the reseller context; reseller_id is omitted for brand-level keys. Requires no scope.
+//	@Tags			API Keys
+//	@Security		ApiKeyAuth
+//	@Success		200 {object} map[string]any
+//	@Router			/me [get]
+func (h *APIKey) Me(w http.ResponseWriter, r *http.Request) {
+	identity := mw.GetIdentity(r.Context())
+	resp := map[string]any{
+		"id":     identity.ID,
+		"scopes": identity.Scopes,
+		"brands": identity.Brands,
+	}
+	if identity.ResellerID != "" {
+		resp["reseller_id"] = identity.ResellerID
+	}
+	response.WriteJSON(w, http.StatusOK, resp)
+}
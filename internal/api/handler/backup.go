@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -10,6 +11,8 @@ import (
 	"github.com/edvin/hosting/internal/model"
 	"github.com/edvin/hosting/internal/platform"
 	"github.com/go-chi/chi/v5"
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/grpc/codes"
 )
 
 type Backup struct {
@@ -200,10 +203,11 @@ func (h *Backup) Delete(w http.ResponseWriter, r *http.Request) {
 // Restore godoc
 //
 //	@Summary		Restore a backup
-//	@Description	Restores a backup to its original source. For databases, this replaces the current data with the backup contents. For webroots, this overwrites the files. Triggers a Temporal workflow on the appropriate node. Async (202).
+//	@Description	Restores a backup to its original source. For databases, this replaces the current data with the backup contents. For webroots, this overwrites the files. Triggers a Temporal workflow on the appropriate node. Async (202). With restore_to, a database is restored to that point in time instead: the newest active backup of the database completed before restore_to is restored, then the database's binlogs are replayed up to restore_to. 400 if the backup is not a database backup, restore_to is in the future, or no backup completed before it. If the node no longer retains the binlogs from that backup on, the restore is refused without touching the database and the reason is recorded in the backup's status_message.
 //	@Tags			Backups
 //	@Security		ApiKeyAuth
 //	@Param			id path string true "Backup ID"
+//	@Param			body body request.RestoreBackup false "Restore options"
 //	@Success		202
 //	@Failure		400 {object} response.ErrorResponse
 //	@Failure		500 {object} response.ErrorResponse
@@ -215,6 +219,14 @@ func (h *Backup) Restore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var req request.RestoreBackup
+	if r.ContentLength != 0 {
+		if err := request.Decode(r, &req); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
 	backup, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -224,7 +236,11 @@ func (h *Backup) Restore(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.svc.Restore(r.Context(), id); err != nil {
+	if err := h.svc.Restore(r.Context(), id, req.RestoreTo); err != nil {
+		if errors.Is(err, core.ErrInvalidRestoreTo) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		response.WriteServiceError(w, err)
 		return
 	}
@@ -232,6 +248,114 @@ func (h *Backup) Restore(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// Files godoc
+//
+//	@Summary		List files in a web backup
+//	@Description	Lists the entries of an active web backup's archive, read on the node that holds it. Paths are relative to the webroot's storage dir and can be passed to POST /backups/{id}/restore-files. prefix limits the listing to paths starting with it. At most 5000 entries are returned; truncated is set when there are more.
+//	@Tags			Backups
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Backup ID"
+//	@Param			prefix query string false "Only list paths starting with this prefix, e.g. public/uploads/"
+//	@Success		200 {object} model.BackupFileList
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/backups/{id}/files [get]
+func (h *Backup) Files(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	backup, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, backup.TenantID) {
+		return
+	}
+
+	list, err := h.svc.ListFiles(r.Context(), backup, r.URL.Query().Get("prefix"))
+	if err != nil {
+		writeBackupFilesError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, list)
+}
+
+// RestoreFiles godoc
+//
+//	@Summary		Restore files from a web backup
+//	@Description	Restores individual files or directories of an active web backup into its webroot and waits for the result. A directory path restores everything under it. Files that are newer on disk than in the backup are skipped and listed in skipped unless force is set. Paths must be relative to the webroot's storage dir; absolute paths, .. components and paths through symlinks are rejected. Symlinks in the backup are not restored. 400 if a path is not in the backup.
+//	@Tags			Backups
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Backup ID"
+//	@Param			body body request.RestoreBackupFiles true "Paths to restore"
+//	@Success		200 {object} model.BackupFilesRestore
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/backups/{id}/restore-files [post]
+func (h *Backup) RestoreFiles(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.RestoreBackupFiles
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	backup, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantMutable(w, r, h.tenantSvc, backup.TenantID) {
+		return
+	}
+
+	res, err := h.svc.RestoreFiles(r.Context(), backup, req.Paths, req.Force)
+	if err != nil {
+		writeBackupFilesError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, res)
+}
+
+// writeBackupFilesError maps the node agent's rejections of a backup file
+// request to client errors.
+func writeBackupFilesError(w http.ResponseWriter, err error) {
+	if errors.Is(err, core.ErrBackupHasNoFiles) {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) {
+		switch appErr.Type() {
+		case codes.InvalidArgument.String(), codes.NotFound.String():
+			response.WriteError(w, http.StatusBadRequest, appErr.Message())
+			return
+		case codes.FailedPrecondition.String():
+			response.WriteError(w, http.StatusConflict, appErr.Message())
+			return
+		}
+	}
+	response.WriteServiceError(w, err)
+}
+
 // Retry godoc
 //
 //	@Summary		Retry a failed backup
@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+
+	mw "github.com/edvin/hosting/internal/api/middleware"
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
+)
+
+type BackupDownload struct {
+	svc       *core.BackupDownloadService
+	backups   *core.BackupService
+	tenantSvc *core.TenantService
+	audit     *mw.AuditLogger
+}
+
+func NewBackupDownload(svc *core.BackupDownloadService, backups *core.BackupService, tenantSvc *core.TenantService, audit *mw.AuditLogger) *BackupDownload {
+	return &BackupDownload{svc: svc, backups: backups, tenantSvc: tenantSvc, audit: audit}
+}
+
+// CreateURL godoc
+//
+//	@Summary		Create a backup download URL
+//	@Description	Returns a signed URL for downloading the backup file without an API key. The URL expires after expires_in seconds (default BACKUP_DOWNLOAD_URL_TTL_SECS, 15 minutes unless configured). With single_use it stops working after the first download. Downloads are recorded in the audit log under the API key that created the URL.
+//	@Tags			Backups
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Backup ID"
+//	@Param			body body request.CreateBackupDownloadURL false "URL options"
+//	@Success		200 {object} model.BackupDownloadURL
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		503 {object} response.ErrorResponse
+//	@Router			/backups/{id}/download-url [post]
+func (h *BackupDownload) CreateURL(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.CreateBackupDownloadURL
+	if r.ContentLength != 0 {
+		if err := request.Decode(r, &req); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if !h.svc.Enabled() {
+		response.WriteError(w, http.StatusServiceUnavailable, "backup downloads are not configured")
+		return
+	}
+
+	backup, err := h.backups.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, backup.TenantID) {
+		return
+	}
+
+	var apiKeyID string
+	if identity := mw.GetIdentity(r.Context()); identity != nil {
+		apiKeyID = identity.ID
+	}
+	ttl := time.Duration(req.ExpiresIn) * time.Second
+	download, err := h.svc.CreateURL(r.Context(), backup, apiKeyID, ttl, req.SingleUse)
+	if errors.Is(err, core.ErrBackupNotDownloadable) {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, download)
+}
+
+// Download godoc
+//
+//	@Summary		Download a backup
+//	@Description	Stages the backup file in the export bucket and redirects to a short-lived presigned URL for it. Authenticated by the signed token from POST /backups/{id}/download-url rather than an API key; the token must be unexpired, issued for this backup, and still match the backup's tenant. Each download is written to the audit log.
+//	@Tags			Backups
+//	@Param			id path string true "Backup ID"
+//	@Param			token query string true "Signed download token"
+//	@Success		302
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		410 {object} response.ErrorResponse
+//	@Failure		503 {object} response.ErrorResponse
+//	@Router			/backups/{id}/download [get]
+func (h *BackupDownload) Download(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		response.WriteError(w, http.StatusForbidden, core.ErrInvalidDownloadToken.Error())
+		return
+	}
+	if !h.svc.Enabled() {
+		response.WriteError(w, http.StatusServiceUnavailable, "backup downloads are not configured")
+		return
+	}
+
+	grant, err := h.svc.Verify(r.Context(), id, token)
+	switch {
+	case errors.Is(err, core.ErrInvalidDownloadToken):
+		response.WriteError(w, http.StatusForbidden, err.Error())
+		return
+	case errors.Is(err, pgx.ErrNoRows):
+		response.WriteError(w, http.StatusNotFound, "backup not found")
+		return
+	case errors.Is(err, core.ErrBackupNotDownloadable):
+		response.WriteError(w, http.StatusGone, err.Error())
+		return
+	case err != nil:
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	// Staging copies the whole file from its node and can outlast the
+	// server's write timeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	fileURL, err := h.svc.Stage(r.Context(), grant.Backup)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	// Single-use URLs are consumed only once the file is ready, so a failed
+	// staging attempt doesn't burn the URL.
+	if err := h.svc.Claim(r.Context(), grant); err != nil {
+		if errors.Is(err, core.ErrDownloadTokenUsed) {
+			response.WriteError(w, http.StatusGone, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	if h.audit != nil {
+		h.audit.RecordAs(r, grant.APIKeyID, http.StatusFound)
+	}
+	http.Redirect(w, r, fileURL, http.StatusFound)
+}
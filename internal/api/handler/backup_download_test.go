@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edvin/hosting/internal/core"
+)
+
+func newBackupDownloadHandler() *BackupDownload {
+	return &BackupDownload{svc: core.NewBackupDownloadService(nil, nil, nil, "", "", 0)}
+}
+
+func TestBackupDownloadCreateURL_EmptyID(t *testing.T) {
+	h := newBackupDownloadHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/backups//download-url", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.CreateURL(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestBackupDownloadCreateURL_InvalidExpiresIn(t *testing.T) {
+	h := newBackupDownloadHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/backups/"+validID+"/download-url", map[string]any{"expires_in": 5})
+	r = withChiURLParam(r, "id", validID)
+
+	h.CreateURL(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestBackupDownloadCreateURL_NotConfigured(t *testing.T) {
+	h := newBackupDownloadHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/backups/"+validID+"/download-url", nil)
+	r = withChiURLParam(r, "id", validID)
+
+	h.CreateURL(rec, r)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "not configured")
+}
+
+func TestBackupDownloadDownload_MissingToken(t *testing.T) {
+	h := newBackupDownloadHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/backups/"+validID+"/download", nil)
+	r = withChiURLParam(r, "id", validID)
+
+	h.Download(rec, r)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestBackupDownloadDownload_NotConfigured(t *testing.T) {
+	h := newBackupDownloadHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/backups/"+validID+"/download?token=abc.def", nil)
+	r = withChiURLParam(r, "id", validID)
+
+	h.Download(rec, r)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
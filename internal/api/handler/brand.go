@@ -1,14 +1,19 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/edvin/hosting/internal/agent/runtime"
 	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/api/response"
 	"github.com/edvin/hosting/internal/core"
 	"github.com/edvin/hosting/internal/model"
 	"github.com/edvin/hosting/internal/platform"
+	"github.com/edvin/hosting/internal/secrets"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -75,20 +80,32 @@ func (h *Brand) Create(w http.ResponseWriter, r *http.Request) {
 		id = platform.NewID()
 	}
 	brand := &model.Brand{
-		ID:              id,
-		Name:            req.Name,
-		BaseHostname:    req.BaseHostname,
-		PrimaryNS:       req.PrimaryNS,
-		SecondaryNS:     req.SecondaryNS,
-		HostmasterEmail: req.HostmasterEmail,
-		MailHostname:    req.MailHostname,
-		SPFIncludes:     req.SPFIncludes,
-		DKIMSelector:    req.DKIMSelector,
-		DKIMPublicKey:   req.DKIMPublicKey,
-		DMARCPolicy:     req.DMARCPolicy,
-		Status:          model.StatusActive,
-		CreatedAt:       now,
-		UpdatedAt:       now,
+		ID:                       id,
+		Name:                     req.Name,
+		BaseHostname:             req.BaseHostname,
+		PrimaryNS:                req.PrimaryNS,
+		SecondaryNS:              req.SecondaryNS,
+		HostmasterEmail:          req.HostmasterEmail,
+		MailHostname:             req.MailHostname,
+		SPFIncludes:              req.SPFIncludes,
+		DKIMSelector:             req.DKIMSelector,
+		DKIMPublicKey:            req.DKIMPublicKey,
+		DMARCPolicy:              req.DMARCPolicy,
+		DNSTTLs:                  req.DNSTTLs,
+		DNSMigrationTTL:          model.DefaultDNSMigrationTTL,
+		PasswordMinLength:        secrets.MinLength,
+		PasswordClasses:          req.PasswordClasses,
+		WebrootMaxConnsPerIP:     req.WebrootMaxConnsPerIP,
+		WebrootMaxRequestsPerSec: req.WebrootMaxRequestsPerSec,
+		Status:                   model.StatusActive,
+		CreatedAt:                now,
+		UpdatedAt:                now,
+	}
+	if req.DNSMigrationTTL != nil {
+		brand.DNSMigrationTTL = *req.DNSMigrationTTL
+	}
+	if req.PasswordMinLength != nil {
+		brand.PasswordMinLength = *req.PasswordMinLength
 	}
 
 	if err := h.svc.Create(r.Context(), brand); err != nil {
@@ -188,6 +205,24 @@ func (h *Brand) Update(w http.ResponseWriter, r *http.Request) {
 	if req.DMARCPolicy != nil {
 		brand.DMARCPolicy = *req.DMARCPolicy
 	}
+	if req.DNSTTLs != nil {
+		brand.DNSTTLs = req.DNSTTLs
+	}
+	if req.DNSMigrationTTL != nil {
+		brand.DNSMigrationTTL = *req.DNSMigrationTTL
+	}
+	if req.PasswordMinLength != nil {
+		brand.PasswordMinLength = *req.PasswordMinLength
+	}
+	if req.PasswordClasses != nil {
+		brand.PasswordClasses = req.PasswordClasses
+	}
+	if req.WebrootMaxConnsPerIP != nil {
+		brand.WebrootMaxConnsPerIP = *req.WebrootMaxConnsPerIP
+	}
+	if req.WebrootMaxRequestsPerSec != nil {
+		brand.WebrootMaxRequestsPerSec = *req.WebrootMaxRequestsPerSec
+	}
 
 	if err := h.svc.Update(r.Context(), brand); err != nil {
 		response.WriteServiceError(w, err)
@@ -285,3 +320,356 @@ func (h *Brand) SetClusters(w http.ResponseWriter, r *http.Request) {
 
 	response.WriteJSON(w, http.StatusOK, map[string][]string{"cluster_ids": req.ClusterIDs})
 }
+
+// ListZoneTemplates godoc
+//
+//	@Summary		List DNS zone templates for a brand
+//	@Description	Returns the DNS records applied to every zone created for this brand. Names and content may contain placeholders such as {zone} and {mail_hostname}, expanded when the zone is created.
+//	@Tags			Brands
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Brand ID (slug)"
+//	@Success		200 {object} map[string][]model.BrandZoneTemplate
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/brands/{id}/zone-templates [get]
+func (h *Brand) ListZoneTemplates(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	templates, err := h.svc.ListZoneTemplates(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	if templates == nil {
+		templates = []model.BrandZoneTemplate{}
+	}
+
+	response.WriteJSON(w, http.StatusOK, map[string][]model.BrandZoneTemplate{"records": templates})
+}
+
+// SetZoneTemplates godoc
+//
+//	@Summary		Set DNS zone templates for a brand
+//	@Description	Replaces the DNS records applied to new zones of this brand. Supported placeholders: {zone}, {base_hostname}, {primary_ns}, {secondary_ns}, {hostmaster_email}, {mail_hostname}, {dkim_selector}, {dkim_public_key}, {dmarc_policy}. A SOA template or NS templates replace the zone's default SOA and NS records and must be named {zone}. All other templates are created as zone records with managed_by "template". Existing zones are not changed. Pass an empty array to remove all templates.
+//	@Tags			Brands
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Brand ID (slug)"
+//	@Param			body body request.SetBrandZoneTemplates true "Template records"
+//	@Success		200 {object} map[string][]model.BrandZoneTemplate
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/brands/{id}/zone-templates [put]
+func (h *Brand) SetZoneTemplates(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.SetBrandZoneTemplates
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	templates := make([]model.BrandZoneTemplate, 0, len(req.Records))
+	for _, rec := range req.Records {
+		if err := request.ValidateZoneTemplate(rec.Type, rec.Name, rec.Content, rec.Priority); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		templates = append(templates, model.BrandZoneTemplate{
+			Type:     rec.Type,
+			Name:     rec.Name,
+			Content:  rec.Content,
+			TTL:      rec.TTL,
+			Priority: rec.Priority,
+		})
+	}
+
+	templates, err = h.svc.SetZoneTemplates(r.Context(), id, templates)
+	if err != nil {
+		if errors.Is(err, core.ErrMultipleSOATemplates) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, map[string][]model.BrandZoneTemplate{"records": templates})
+}
+
+// GetACMEConfig godoc
+//
+//	@Summary		Get ACME configuration for a brand
+//	@Description	Returns the ACME CA that Let's Encrypt-type certificates of the brand's FQDNs are issued from. The EAB HMAC key is never returned. Returns 404 if the brand uses the platform default CA.
+//	@Tags			Brands
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Brand ID (slug)"
+//	@Success		200 {object} model.BrandACMEConfig
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/brands/{id}/acme [get]
+func (h *Brand) GetACMEConfig(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cfg, err := h.svc.GetACMEConfig(r.Context(), id)
+	if errors.Is(err, core.ErrNoBrandACMEConfig) {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, cfg)
+}
+
+// SetACMEConfig godoc
+//
+//	@Summary		Set ACME configuration for a brand
+//	@Description	Makes certificates of the brand's FQDNs be issued by another ACME CA, such as ZeroSSL or an internal CA. The directory is fetched to check it; CAs that require External Account Binding (EAB) need eab_key_id and eab_hmac_key (base64url, as issued by the CA), which is stored encrypted. Existing certificates are not reissued; renewals use the new CA.
+//	@Tags			Brands
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Brand ID (slug)"
+//	@Param			body body request.SetBrandACMEConfig true "ACME configuration"
+//	@Success		200 {object} model.BrandACMEConfig
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/brands/{id}/acme [put]
+func (h *Brand) SetACMEConfig(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.SetBrandACMEConfig
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cfg := &model.BrandACMEConfig{
+		BrandID:      id,
+		DirectoryURL: req.DirectoryURL,
+		Email:        req.Email,
+		EABKeyID:     req.EABKeyID,
+	}
+	if err := h.svc.SetACMEConfig(r.Context(), cfg, req.EABHMACKey); err != nil {
+		if errors.Is(err, core.ErrInvalidACMEConfig) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, cfg)
+}
+
+// DeleteACMEConfig godoc
+//
+//	@Summary		Remove ACME configuration for a brand
+//	@Description	Returns the brand to the platform default ACME CA. Returns 404 if the brand has no configuration.
+//	@Tags			Brands
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Brand ID (slug)"
+//	@Success		204
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/brands/{id}/acme [delete]
+func (h *Brand) DeleteACMEConfig(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.DeleteACMEConfig(r.Context(), id); err != nil {
+		if errors.Is(err, core.ErrNoBrandACMEConfig) {
+			response.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAppTemplates godoc
+//
+//	@Summary		List app templates for a brand
+//	@Description	Returns the brand's app templates (webroot presets such as WordPress or Laravel) that tenants of the brand can pass as template_id when creating a webroot.
+//	@Tags			Brands
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Brand ID (slug)"
+//	@Success		200 {object} map[string][]model.AppTemplate
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/brands/{id}/app-templates [get]
+func (h *Brand) ListAppTemplates(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	templates, err := h.svc.ListAppTemplates(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	if templates == nil {
+		templates = []model.AppTemplate{}
+	}
+
+	response.WriteJSON(w, http.StatusOK, map[string][]model.AppTemplate{"templates": templates})
+}
+
+// SetAppTemplates godoc
+//
+//	@Summary		Set app templates for a brand
+//	@Description	Replaces the brand's app templates. Each template sets the runtime, runtime version, runtime config and public folder of webroots created from it, can create a linked database (create_database) and adds env_vars to the webroot. Env var values may contain {webroot_id} and {database_name}, expanded when the webroot is created. Template IDs are slugs chosen by the caller and stay stable across updates. Existing webroots are not changed. Pass an empty array to remove all templates.
+//	@Tags			Brands
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Brand ID (slug)"
+//	@Param			body body request.SetAppTemplates true "App templates"
+//	@Success		200 {object} map[string][]model.AppTemplate
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/brands/{id}/app-templates [put]
+func (h *Brand) SetAppTemplates(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.SetAppTemplates
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now()
+	templates := make([]model.AppTemplate, 0, len(req.Templates))
+	for _, t := range req.Templates {
+		runtimeConfig := t.RuntimeConfig
+		if runtimeConfig == nil {
+			runtimeConfig = json.RawMessage(`{}`)
+		}
+		if t.Runtime == "php" {
+			if err := runtime.ValidatePHPRuntimeConfig(runtimeConfig); err != nil {
+				response.WriteError(w, http.StatusBadRequest, fmt.Sprintf("template %q: %s", t.ID, err.Error()))
+				return
+			}
+		}
+		envVars := t.EnvVars
+		if envVars == nil {
+			envVars = map[string]string{}
+		}
+		templates = append(templates, model.AppTemplate{
+			ID:             t.ID,
+			BrandID:        id,
+			Name:           t.Name,
+			Description:    t.Description,
+			Runtime:        t.Runtime,
+			RuntimeVersion: t.RuntimeVersion,
+			RuntimeConfig:  runtimeConfig,
+			PublicFolder:   t.PublicFolder,
+			CreateDatabase: t.CreateDatabase,
+			EnvVars:        envVars,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		})
+	}
+
+	if err := h.svc.SetAppTemplates(r.Context(), id, templates); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, map[string][]model.AppTemplate{"templates": templates})
+}
+
+// GetRuntimeDefaults godoc
+//
+//	@Summary		Get default runtime versions for a brand
+//	@Description	Returns the version per runtime that webroots of the brand get when they are created without a runtime_version.
+//	@Tags			Brands
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Brand ID (slug)"
+//	@Success		200 {object} map[string]map[string]string
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/brands/{id}/runtime-defaults [get]
+func (h *Brand) GetRuntimeDefaults(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defaults, err := h.svc.GetRuntimeDefaults(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, map[string]map[string]string{"defaults": defaults})
+}
+
+// SetRuntimeDefaults godoc
+//
+//	@Summary		Set default runtime versions for a brand
+//	@Description	Replaces the brand's default runtime versions, e.g. {"defaults": {"php": "8.3"}}. Webroots created without a runtime_version get the default of their runtime. Returns 400 if a version has not been reported installed by any node in the brand's clusters. Existing webroots are not changed. Pass an empty object to remove all defaults.
+//	@Tags			Brands
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Brand ID (slug)"
+//	@Param			body body request.SetBrandRuntimeDefaults true "Default versions per runtime"
+//	@Success		200 {object} map[string]map[string]string
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/brands/{id}/runtime-defaults [put]
+func (h *Brand) SetRuntimeDefaults(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.SetBrandRuntimeDefaults
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.SetRuntimeDefaults(r.Context(), id, req.Defaults); err != nil {
+		if errors.Is(err, core.ErrInvalidRuntimeDefault) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, map[string]map[string]string{"defaults": req.Defaults})
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edvin/hosting/internal/core"
+)
+
+func newBrandHandler() *Brand {
+	return &Brand{svc: core.NewBrandService(nil, "")}
+}
+
+func TestBrandSetZoneTemplates_EmptyID(t *testing.T) {
+	h := newBrandHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/brands//zone-templates", map[string]any{"records": []any{}})
+	r = withChiURLParam(r, "id", "")
+
+	h.SetZoneTemplates(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestBrandSetZoneTemplates_UnknownVariable(t *testing.T) {
+	h := newBrandHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/brands/acme/zone-templates", map[string]any{
+		"records": []map[string]any{
+			{"type": "CNAME", "name": "www.{zone}", "content": "{tenant}.example.net"},
+		},
+	})
+	r = withChiURLParam(r, "id", "acme")
+
+	h.SetZoneTemplates(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "unknown template variable {tenant}")
+}
+
+func TestBrandSetZoneTemplates_MultipleSOA(t *testing.T) {
+	h := newBrandHandler()
+	rec := httptest.NewRecorder()
+	soa := map[string]any{"type": "SOA", "name": "{zone}", "content": "{primary_ns} {hostmaster_email} 1 10800 3600 604800 300"}
+	r := newRequest(http.MethodPut, "/brands/acme/zone-templates", map[string]any{
+		"records": []map[string]any{soa, soa},
+	})
+	r = withChiURLParam(r, "id", "acme")
+
+	h.SetZoneTemplates(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "at most one SOA")
+}
+
+func TestBrandSetAppTemplates_EmptyID(t *testing.T) {
+	h := newBrandHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/brands//app-templates", map[string]any{"templates": []any{}})
+	r = withChiURLParam(r, "id", "")
+
+	h.SetAppTemplates(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestBrandSetAppTemplates_InvalidEnvVarName(t *testing.T) {
+	h := newBrandHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/brands/acme/app-templates", map[string]any{
+		"templates": []map[string]any{
+			{"id": "wordpress", "name": "WordPress", "runtime": "php", "runtime_version": "8.3", "env_vars": map[string]string{"DB-NAME": "{database_name}"}},
+		},
+	})
+	r = withChiURLParam(r, "id", "acme")
+
+	h.SetAppTemplates(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "invalid env var name")
+}
+
+func TestBrandSetAppTemplates_DuplicateID(t *testing.T) {
+	h := newBrandHandler()
+	rec := httptest.NewRecorder()
+	tmpl := map[string]any{"id": "laravel", "name": "Laravel", "runtime": "php", "runtime_version": "8.3", "public_folder": "public"}
+	r := newRequest(http.MethodPut, "/brands/acme/app-templates", map[string]any{
+		"templates": []map[string]any{tmpl, tmpl},
+	})
+	r = withChiURLParam(r, "id", "acme")
+
+	h.SetAppTemplates(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "duplicate template id")
+}
+
+func TestBrandSetRuntimeDefaults_EmptyID(t *testing.T) {
+	h := newBrandHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/brands//runtime-defaults", map[string]any{"defaults": map[string]string{}})
+	r = withChiURLParam(r, "id", "")
+
+	h.SetRuntimeDefaults(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestBrandSetRuntimeDefaults_UnknownRuntime(t *testing.T) {
+	h := newBrandHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/brands/acme/runtime-defaults", map[string]any{
+		"defaults": map[string]string{"static": "1"},
+	})
+	r = withChiURLParam(r, "id", "acme")
+
+	h.SetRuntimeDefaults(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "validation error")
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -10,14 +11,16 @@ import (
 	"github.com/edvin/hosting/internal/model"
 	"github.com/edvin/hosting/internal/platform"
 	"github.com/go-chi/chi/v5"
+	"go.temporal.io/api/serviceerror"
 )
 
 type Certificate struct {
-	svc *core.CertificateService
+	svc       *core.CertificateService
+	ownership *core.OwnershipService
 }
 
-func NewCertificate(svc *core.CertificateService) *Certificate {
-	return &Certificate{svc: svc}
+func NewCertificate(svc *core.CertificateService, ownership *core.OwnershipService) *Certificate {
+	return &Certificate{svc: svc, ownership: ownership}
 }
 
 // ListByFQDN godoc
@@ -40,6 +43,10 @@ func (h *Certificate) ListByFQDN(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "fqdns", fqdnID) {
+		return
+	}
+
 	pg := request.ParsePagination(r)
 
 	certs, hasMore, err := h.svc.ListByFQDN(r.Context(), fqdnID, pg.Limit, pg.Cursor)
@@ -83,6 +90,10 @@ func (h *Certificate) Upload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "fqdns", fqdnID) {
+		return
+	}
+
 	now := time.Now()
 	cert := &model.Certificate{
 		ID:        platform.NewID(),
@@ -122,9 +133,145 @@ func (h *Certificate) Retry(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !checkOwnership(w, r, h.ownership, "certificates", id) {
+		return
+	}
 	if err := h.svc.Retry(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// BulkProvision godoc
+//
+//	@Summary		Issue certificates for many FQDNs
+//	@Description	Issues Let's Encrypt certificates for a tenant's FQDNs without hitting the CA's rate limits, e.g. when onboarding a customer with hundreds of domains. FQDNs without a certificate are issued first, then those that already have one (early renewals). Issuances are spaced to at most per_hour (default 20), and new certificates per registered domain are held to per_domain_per_week (default 50, Let's Encrypt's limit) in any 7 days. Attempts the CA rejects for rate limits are retried with backoff. The FQDNs must have SSL enabled and not use a custom certificate. Async — returns 202 and starts BulkProvisionCertsWorkflow; GET /operations/{id} for the operation in the X-Operation-ID header reports per-FQDN progress. Returns 409 while an earlier bulk issuance for the tenant is running.
+//	@Tags			Certificates
+//	@Security		ApiKeyAuth
+//	@Param			tenantID	path	string								true	"Tenant ID"
+//	@Param			body		body	request.BulkProvisionCertificates	true	"FQDNs and issuance rates"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		403	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		409	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/tenants/{tenantID}/certificates/bulk [post]
+func (h *Certificate) BulkProvision(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := request.RequireID(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.BulkProvisionCertificates
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkOwnership(w, r, h.ownership, "tenants", tenantID) {
+		return
+	}
+
+	err = h.svc.BulkProvision(r.Context(), core.BulkProvisionCertsParams{
+		TenantID:         tenantID,
+		FQDNIDs:          req.FQDNIDs,
+		PerHour:          req.PerHour,
+		PerDomainPerWeek: req.PerDomainPerWeek,
+	})
+	switch {
+	case errors.Is(err, core.ErrInvalidBulkCerts):
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, core.ErrBulkCertsRunning):
+		response.WriteError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		response.WriteServiceError(w, err)
+	default:
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
+
+// Renew godoc
+//
+//	@Summary		Force renewal of a Let's Encrypt certificate
+//	@Description	Immediately reissues a Let's Encrypt certificate instead of waiting for the nightly renewal cron, e.g. after the customer changed DNS. Only one renewal per certificate runs at a time; if one is already in flight it is returned instead of starting another. Async — returns 202 with the workflow ID; poll GET /certificates/{id}/renew for the result.
+//	@Tags			Certificates
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Certificate ID"
+//	@Success		202 {object} model.CertificateRenewal
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/certificates/{id}/renew [post]
+func (h *Certificate) Renew(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkOwnership(w, r, h.ownership, "certificates", id) {
+		return
+	}
+
+	cert, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	if cert.Type != model.CertTypeLetsEncrypt {
+		response.WriteError(w, http.StatusBadRequest, "only Let's Encrypt certificates can be renewed")
+		return
+	}
+
+	renewal, err := h.svc.Renew(r.Context(), cert)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	response.WriteJSON(w, http.StatusAccepted, renewal)
+}
+
+// GetRenewal godoc
+//
+//	@Summary		Get certificate renewal status
+//	@Description	Returns the status of the most recent renewal of a certificate (running, completed or failed). On completion the response includes the newly issued certificate ID and its expiry.
+//	@Tags			Certificates
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Certificate ID"
+//	@Success		200 {object} model.CertificateRenewal
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/certificates/{id}/renew [get]
+func (h *Certificate) GetRenewal(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkOwnership(w, r, h.ownership, "certificates", id) {
+		return
+	}
+
+	cert, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	renewal, err := h.svc.GetRenewal(r.Context(), cert)
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			response.WriteError(w, http.StatusNotFound, "no renewal found for certificate "+id)
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, renewal)
+}
@@ -11,7 +11,7 @@ import (
 )
 
 func newCertificateHandler() *Certificate {
-	return NewCertificate(nil)
+	return NewCertificate(nil, nil)
 }
 
 // --- ListByFQDN ---
@@ -186,3 +186,62 @@ func TestCertificateUpload_ErrorResponseFormat(t *testing.T) {
 	_, hasError := body["error"]
 	assert.True(t, hasError)
 }
+
+// --- Renew ---
+
+func TestCertificateRenew_EmptyID(t *testing.T) {
+	h := newCertificateHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/certificates//renew", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Renew(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestCertificateGetRenewal_EmptyID(t *testing.T) {
+	h := newCertificateHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/certificates//renew", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.GetRenewal(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+// --- BulkProvision ---
+
+func TestCertificateBulkProvision_EmptyFQDNIDs(t *testing.T) {
+	h := newCertificateHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants/test-tenant/certificates/bulk", map[string]any{
+		"fqdn_ids": []string{},
+	})
+	r = withChiURLParam(r, "tenantID", "test-tenant")
+
+	h.BulkProvision(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestCertificateBulkProvision_RateTooHigh(t *testing.T) {
+	h := newCertificateHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants/test-tenant/certificates/bulk", map[string]any{
+		"fqdn_ids":            []string{"test-fqdn"},
+		"per_domain_per_week": 100,
+	})
+	r = withChiURLParam(r, "tenantID", "test-tenant")
+
+	h.BulkProvision(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "PerDomainPerWeek")
+}
@@ -85,6 +85,10 @@ func (h *Cluster) Create(w http.ResponseWriter, r *http.Request) {
 	if cfg == nil {
 		cfg = json.RawMessage(`{}`)
 	}
+	if err := validateClusterConfig(cfg); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	spec := req.Spec
 	if spec == nil {
 		spec = json.RawMessage(`{}`)
@@ -110,6 +114,17 @@ func (h *Cluster) Create(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusCreated, cluster)
 }
 
+// validateClusterConfig checks the settings of a cluster config document
+// that the platform reads, such as the tenant UID range.
+func validateClusterConfig(raw json.RawMessage) error {
+	cfg, err := model.ParseClusterConfig(raw)
+	if err != nil {
+		return err
+	}
+	_, _, err = cfg.TenantUIDRange()
+	return err
+}
+
 // Get godoc
 //
 //	@Summary		Get a cluster
@@ -176,6 +191,10 @@ func (h *Cluster) Update(w http.ResponseWriter, r *http.Request) {
 		cluster.Status = req.Status
 	}
 	if req.Config != nil {
+		if err := validateClusterConfig(req.Config); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		cluster.Config = req.Config
 	}
 	if req.Spec != nil {
@@ -161,6 +161,23 @@ func TestClusterCreate_ValidBodyWithOptionals(t *testing.T) {
 	assert.NotEqual(t, http.StatusBadRequest, rec.Code)
 }
 
+func TestClusterCreate_InvalidTenantUIDRange(t *testing.T) {
+	h := newClusterHandler()
+	rec := httptest.NewRecorder()
+	rid := "test-region-3"
+	r := newRequest(http.MethodPost, "/regions/"+rid+"/clusters", map[string]any{
+		"name":   "cluster-01",
+		"config": map[string]any{"tenant_uid_min": 30000, "tenant_uid_max": 20000},
+	})
+	r = withChiURLParam(r, "regionID", rid)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "tenant_uid_max")
+}
+
 // --- Get ---
 
 func TestClusterGet_EmptyID(t *testing.T) {
@@ -73,7 +73,7 @@ func (h *CronJob) ListByWebroot(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create a cron job
-//	@Description	Creates a scheduled cron job for a webroot. Async — returns 202 and triggers a Temporal workflow to configure the cron job on the web server.
+//	@Description	Creates a scheduled cron job for a webroot. The schedule is evaluated in `timezone` (IANA name, default UTC). Async — returns 202 and triggers a Temporal workflow to configure the cron job on the web server.
 //	@Tags			Cron Jobs
 //	@Security		ApiKeyAuth
 //	@Param			webrootID path string true "Webroot ID"
@@ -141,6 +141,7 @@ func (h *CronJob) Create(w http.ResponseWriter, r *http.Request) {
 		Enabled:          false,
 		TimeoutSeconds:   timeoutSeconds,
 		MaxMemoryMB:      maxMemoryMB,
+		Timezone:         cronTimezone(req.Timezone),
 		Status:           model.StatusPending,
 		CreatedAt:        now,
 		UpdatedAt:        now,
@@ -188,7 +189,7 @@ func (h *CronJob) Get(w http.ResponseWriter, r *http.Request) {
 // Update godoc
 //
 //	@Summary		Update a cron job
-//	@Description	Partial update of a cron job — supports changing schedule, command, working directory, timeout, and memory limit. Async — returns 202 and triggers re-convergence.
+//	@Description	Partial update of a cron job — supports changing schedule, timezone, command, working directory, timeout, and memory limit. Async — returns 202 and triggers re-convergence.
 //	@Tags			Cron Jobs
 //	@Security		ApiKeyAuth
 //	@Param			id path string true "Cron Job ID"
@@ -244,6 +245,9 @@ func (h *CronJob) Update(w http.ResponseWriter, r *http.Request) {
 	if req.MaxMemoryMB != nil {
 		cronJob.MaxMemoryMB = *req.MaxMemoryMB
 	}
+	if req.Timezone != nil {
+		cronJob.Timezone = cronTimezone(*req.Timezone)
+	}
 
 	if err := h.svc.Update(r.Context(), cronJob); err != nil {
 		response.WriteServiceError(w, err)
@@ -358,6 +362,76 @@ func (h *CronJob) Disable(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// Pause godoc
+//
+//	@Summary		Pause a cron job
+//	@Description	Disables the timers of an active cron job on all nodes and stops a run in progress, without deleting the job. Async — returns 202 and triggers a Temporal workflow. Resume with POST /cron-jobs/{id}/resume.
+//	@Tags			Cron Jobs
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Cron Job ID"
+//	@Success		202
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/cron-jobs/{id}/pause [post]
+func (h *CronJob) Pause(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cronJob, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.services.Tenant, cronJob.TenantID) {
+		return
+	}
+
+	if err := h.svc.Pause(r.Context(), id); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Resume godoc
+//
+//	@Summary		Resume a cron job
+//	@Description	Re-enables the timers of a paused cron job on all nodes. Same as POST /cron-jobs/{id}/enable. Async — returns 202 and triggers a Temporal workflow.
+//	@Tags			Cron Jobs
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Cron Job ID"
+//	@Success		202
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/cron-jobs/{id}/resume [post]
+func (h *CronJob) Resume(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cronJob, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.services.Tenant, cronJob.TenantID) {
+		return
+	}
+
+	if err := h.svc.Resume(r.Context(), id); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
 // Retry godoc
 //
 //	@Summary		Retry a failed cron job
@@ -392,3 +466,12 @@ func (h *CronJob) Retry(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// cronTimezone returns the timezone a cron job is created or updated with,
+// UTC when none is given.
+func cronTimezone(tz string) string {
+	if tz == "" {
+		return model.DefaultCronTimezone
+	}
+	return tz
+}
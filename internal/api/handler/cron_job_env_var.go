@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/go-chi/chi/v5"
+)
+
+type CronJobEnvVar struct {
+	svc      *core.CronJobEnvVarService
+	services *core.Services
+}
+
+func NewCronJobEnvVar(services *core.Services) *CronJobEnvVar {
+	return &CronJobEnvVar{svc: services.CronJobEnvVar, services: services}
+}
+
+// List godoc
+//
+//	@Summary		List cron job env vars
+//	@Description	Returns the env vars injected into a cron job at run time. Secret values are write-only and returned as "***".
+//	@Tags			Cron Jobs
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Cron job ID"
+//	@Success		200 {object} map[string]any
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Router			/cron-jobs/{id}/env-vars [get]
+func (h *CronJobEnvVar) List(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cronJob, err := h.services.CronJob.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.services.Tenant, cronJob.TenantID) {
+		return
+	}
+
+	vars, err := h.svc.List(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	type envVarResponse struct {
+		Name     string `json:"name"`
+		Value    string `json:"value"`
+		IsSecret bool   `json:"is_secret"`
+	}
+
+	items := make([]envVarResponse, len(vars))
+	for i, v := range vars {
+		items[i] = envVarResponse{Name: v.Name, Value: v.Value, IsSecret: v.IsSecret}
+	}
+
+	response.WriteJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// Set godoc
+//
+//	@Summary		Replace cron job env vars
+//	@Description	Replaces all env vars of a cron job and rewrites its units on the nodes. Omit value on a secret entry to keep its stored value. Returns 202 immediately.
+//	@Tags			Cron Jobs
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Cron job ID"
+//	@Param			body body request.SetCronJobEnvVars true "Env vars"
+//	@Success		202
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Router			/cron-jobs/{id}/env-vars [put]
+func (h *CronJobEnvVar) Set(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.SetCronJobEnvVars
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	cronJob, err := h.services.CronJob.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.services.Tenant, cronJob.TenantID) {
+		return
+	}
+
+	vars := make([]core.CronJobEnvVarInput, len(req.Vars))
+	for i, v := range req.Vars {
+		vars[i] = core.CronJobEnvVarInput{Name: v.Name, Value: v.Value, IsSecret: v.Secret}
+	}
+
+	err = h.svc.BulkSet(r.Context(), id, vars)
+	if errors.Is(err, core.ErrNoStoredSecret) {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Delete godoc
+//
+//	@Summary		Delete a cron job env var
+//	@Description	Removes a single env var by name and rewrites the cron job's units. Returns 202 immediately.
+//	@Tags			Cron Jobs
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Cron job ID"
+//	@Param			name path string true "Env var name"
+//	@Success		202
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Router			/cron-jobs/{id}/env-vars/{name} [delete]
+func (h *CronJobEnvVar) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		response.WriteError(w, http.StatusBadRequest, "missing env var name")
+		return
+	}
+
+	cronJob, err := h.services.CronJob.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.services.Tenant, cronJob.TenantID) {
+		return
+	}
+
+	if err := h.svc.DeleteByName(r.Context(), id, name); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCronJobEnvVarHandler() *CronJobEnvVar {
+	return &CronJobEnvVar{svc: nil, services: nil}
+}
+
+func TestCronJobEnvVarList_EmptyID(t *testing.T) {
+	h := newCronJobEnvVarHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/cron-jobs//env-vars", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.List(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestCronJobEnvVarSet_InvalidName(t *testing.T) {
+	h := newCronJobEnvVarHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/cron-jobs/"+validID+"/env-vars", map[string]any{
+		"vars": []map[string]any{{"name": "1BAD", "value": "x"}},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.Set(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "invalid env var name")
+}
+
+func TestCronJobEnvVarSet_MissingValueOnPlainVar(t *testing.T) {
+	h := newCronJobEnvVarHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/cron-jobs/"+validID+"/env-vars", map[string]any{
+		"vars": []map[string]any{{"name": "LOG_LEVEL"}},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.Set(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "value is required")
+}
+
+func TestCronJobEnvVarSet_NULInValue(t *testing.T) {
+	h := newCronJobEnvVarHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/cron-jobs/"+validID+"/env-vars", map[string]any{
+		"vars": []map[string]any{{"name": "TOKEN", "value": "a\x00b", "secret": true}},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.Set(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "NUL")
+}
+
+func TestCronJobEnvVarSet_DuplicateName(t *testing.T) {
+	h := newCronJobEnvVarHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/cron-jobs/"+validID+"/env-vars", map[string]any{
+		"vars": []map[string]any{{"name": "A", "value": "1"}, {"name": "A", "value": "2"}},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.Set(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "duplicate")
+}
+
+func TestCronJobEnvVarDelete_EmptyName(t *testing.T) {
+	h := newCronJobEnvVarHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/cron-jobs/"+validID+"/env-vars/", nil)
+	r = withChiURLParams(r, map[string]string{"id": validID, "name": ""})
+
+	h.Delete(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing env var name")
+}
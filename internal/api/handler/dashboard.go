@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/api/response"
 	"github.com/edvin/hosting/internal/core"
 )
@@ -33,3 +35,49 @@ func (h *Dashboard) Stats(w http.ResponseWriter, r *http.Request) {
 
 	response.WriteJSON(w, http.StatusOK, stats)
 }
+
+// Failures godoc
+//
+//	@Summary		List failed resources
+//	@Description	Returns every resource currently in failed status across the platform (tenants, webroots, FQDNs, certificates, databases, email, backups, and the other resource types), with its tenant, status message, and last update, most recent first. Read with a single query over all resource tables. Platform admin only.
+//	@Tags			Dashboard
+//	@Security		ApiKeyAuth
+//	@Param			resource_type	query		string	false	"Filter by resource type (e.g. webroot, fqdn, database_user)"
+//	@Param			tenant_id		query		string	false	"Filter by tenant"
+//	@Param			search			query		string	false	"Search in resource ID or status message"
+//	@Param			order			query		string	false	"Sort order by updated_at (asc, desc)"	default(desc)
+//	@Param			limit			query		int		false	"Page size"	default(50)
+//	@Param			cursor			query		string	false	"Pagination cursor"
+//	@Success		200				{object}	response.PaginatedResponse{items=[]core.ResourceFailure}
+//	@Failure		400				{object}	response.ErrorResponse
+//	@Failure		500				{object}	response.ErrorResponse
+//	@Router			/failures [get]
+func (h *Dashboard) Failures(w http.ResponseWriter, r *http.Request) {
+	params := request.ParseListParams(r, "updated_at")
+
+	failures, hasMore, err := h.svc.ListFailures(r.Context(), core.FailureListParams{
+		ResourceType: r.URL.Query().Get("resource_type"),
+		TenantID:     r.URL.Query().Get("tenant_id"),
+		Search:       params.Search,
+		Order:        params.Order,
+		Limit:        params.Limit,
+		Cursor:       params.Cursor,
+	})
+	if errors.Is(err, core.ErrUnknownResourceType) || errors.Is(err, core.ErrInvalidCursor) {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	if failures == nil {
+		failures = []core.ResourceFailure{}
+	}
+	var nextCursor string
+	if hasMore && len(failures) > 0 {
+		nextCursor = core.FailureCursor(failures[len(failures)-1])
+	}
+	response.WritePaginated(w, http.StatusOK, failures, nextCursor, hasMore)
+}
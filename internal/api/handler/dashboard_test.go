@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/edvin/hosting/internal/core"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,3 +34,23 @@ func TestNewDashboard(t *testing.T) {
 	h := NewDashboard(nil)
 	assert.NotNil(t, h)
 }
+
+func TestDashboardFailures_UnknownResourceType(t *testing.T) {
+	h := NewDashboard(core.NewDashboardService(nil))
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/failures?resource_type=widget", nil)
+
+	h.Failures(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestDashboardFailures_InvalidCursor(t *testing.T) {
+	h := NewDashboard(core.NewDashboardService(nil))
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/failures?cursor=bogus", nil)
+
+	h.Failures(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
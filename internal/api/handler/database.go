@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -12,6 +14,8 @@ import (
 	"github.com/edvin/hosting/internal/model"
 	"github.com/edvin/hosting/internal/platform"
 	"github.com/go-chi/chi/v5"
+	"go.temporal.io/sdk/temporal"
+	"google.golang.org/grpc/codes"
 )
 
 type Database struct {
@@ -70,7 +74,7 @@ func (h *Database) ListByTenant(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create a database
-//	@Description	Creates a MySQL database on the specified shard for a tenant. Accepts optional nested user objects to create database users in the same request. Returns 202 and triggers a Temporal workflow to provision the database on the shard's primary MySQL node.
+//	@Description	Creates a MySQL database on the specified shard for a tenant. The character set and collation default to utf8mb4/utf8mb4_unicode_ci and must come from the supported allowlist; they cannot be changed after creation (recreate the database instead). Accepts optional nested user objects to create database users in the same request. Returns 202 and triggers a Temporal workflow to provision the database on the shard's primary MySQL node.
 //	@Tags			Databases
 //	@Security		ApiKeyAuth
 //	@Param			tenantID	path		string					true	"Tenant ID"
@@ -91,10 +95,22 @@ func (h *Database) Create(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	charset, collation, err := model.ResolveDatabaseCharset(req.Charset, req.Collation)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	if !checkTenantBrand(w, r, h.tenantSvc, tenantID) {
 		return
 	}
+	passwords := make([]string, len(req.Users))
+	for i, ur := range req.Users {
+		passwords[i] = ur.Password
+	}
+	if !checkPasswordPolicy(w, r, h.tenantSvc, tenantID, passwords...) {
+		return
+	}
 
 	now := time.Now()
 	shardID := req.ShardID
@@ -103,6 +119,8 @@ func (h *Database) Create(w http.ResponseWriter, r *http.Request) {
 		TenantID:       tenantID,
 		SubscriptionID: req.SubscriptionID,
 		ShardID:        &shardID,
+		Charset:        charset,
+		Collation:      collation,
 		Status:    model.StatusPending,
 		CreatedAt: now,
 		UpdatedAt: now,
@@ -277,3 +295,122 @@ func (h *Database) Retry(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// ListConnections godoc
+//
+//	@Summary		List active database connections
+//	@Description	Synchronously lists the MySQL connections of this database's users on the shard primary, including host, command, state, duration and the current query (truncated to 1024 bytes).
+//	@Tags			Databases
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Database ID"
+//	@Success		200	{object}	response.PaginatedResponse{items=[]model.DatabaseConnection}
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/databases/{id}/connections [get]
+func (h *Database) ListConnections(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	database, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, database.TenantID) {
+		return
+	}
+
+	conns, err := h.svc.ListConnections(r.Context(), database)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	response.WritePaginated(w, http.StatusOK, conns, "", false)
+}
+
+// ConnectionInfo godoc
+//
+//	@Summary		Get database connection info
+//	@Description	Returns the database's address on the tenant's WireGuard network (the tenant's ULA on the shard primary, as forwarded by hosting-cli proxy), the hosting-cli proxy command that forwards it to localhost, and a DSN template per database user. Passwords are only stored as hashes and are never returned; DSNs contain a {password} placeholder.
+//	@Tags			Databases
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Database ID"
+//	@Success		200	{object}	model.ConnectionInfo
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		403	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/databases/{id}/connection-info [get]
+func (h *Database) ConnectionInfo(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	database, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, database.TenantID) {
+		return
+	}
+
+	info, err := h.svc.ConnectionInfo(r.Context(), database)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, info)
+}
+
+// KillConnection godoc
+//
+//	@Summary		Kill a database connection
+//	@Description	Synchronously kills a MySQL connection on the shard primary. The connection must belong to one of this database's users; otherwise 404 is returned.
+//	@Tags			Databases
+//	@Security		ApiKeyAuth
+//	@Param			id	path	string	true	"Database ID"
+//	@Param			pid	path	int		true	"Connection ID (processlist ID)"
+//	@Success		204
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/databases/{id}/connections/{pid} [delete]
+func (h *Database) KillConnection(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	pid, err := strconv.ParseInt(chi.URLParam(r, "pid"), 10, 64)
+	if err != nil || pid <= 0 {
+		response.WriteError(w, http.StatusBadRequest, "invalid connection id")
+		return
+	}
+
+	database, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, database.TenantID) {
+		return
+	}
+
+	if err := h.svc.KillConnection(r.Context(), database, pid); err != nil {
+		var appErr *temporal.ApplicationError
+		if errors.As(err, &appErr) && appErr.Type() == codes.NotFound.String() {
+			response.WriteError(w, http.StatusNotFound, fmt.Sprintf("connection %d not found", pid))
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
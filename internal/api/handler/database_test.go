@@ -76,6 +76,41 @@ func TestDatabaseCreate_EmptyBody(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
 
+func TestDatabaseCreate_InvalidCharset(t *testing.T) {
+	h := newDatabaseHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants/"+validID+"/databases", map[string]any{
+		"subscription_id": "sub-1",
+		"shard_id":        validID,
+		"charset":         "utf8mb4 COLLATE utf8mb4_bin; DROP DATABASE mysql",
+	})
+	r = withChiURLParam(r, "tenantID", validID)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "unsupported charset")
+}
+
+func TestDatabaseCreate_CollationMismatch(t *testing.T) {
+	h := newDatabaseHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants/"+validID+"/databases", map[string]any{
+		"subscription_id": "sub-1",
+		"shard_id":        validID,
+		"charset":         "utf8mb4",
+		"collation":       "latin1_swedish_ci",
+	})
+	r = withChiURLParam(r, "tenantID", validID)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "not valid for charset")
+}
+
 func TestDatabaseCreate_MissingShardID(t *testing.T) {
 	h := newDatabaseHandler()
 	rec := httptest.NewRecorder()
@@ -215,6 +250,49 @@ func TestDatabaseDelete_EmptyID(t *testing.T) {
 	assert.Contains(t, body["error"], "missing required ID")
 }
 
+// --- Connections ---
+
+func TestDatabaseListConnections_EmptyID(t *testing.T) {
+	h := newDatabaseHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/databases//connections", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.ListConnections(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestDatabaseConnectionInfo_EmptyID(t *testing.T) {
+	h := newDatabaseHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/databases//connection-info", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.ConnectionInfo(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestDatabaseKillConnection_InvalidPID(t *testing.T) {
+	for _, pid := range []string{"", "abc", "0", "-5"} {
+		h := newDatabaseHandler()
+		rec := httptest.NewRecorder()
+		r := newRequest(http.MethodDelete, "/databases/test-database-1/connections/"+pid, nil)
+		r = withChiURLParams(r, map[string]string{"id": "test-database-1", "pid": pid})
+
+		h.KillConnection(rec, r)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "pid %q", pid)
+		body := decodeErrorResponse(rec)
+		assert.Contains(t, body["error"], "invalid connection id")
+	}
+}
+
 // --- Migrate ---
 
 func TestDatabaseMigrate_Success(t *testing.T) {
@@ -240,7 +318,9 @@ func TestDatabaseMigrate_Success(t *testing.T) {
 		*(dest[7].(*string)) = ""         // SuspendReason
 		*(dest[8].(*time.Time)) = now     // CreatedAt
 		*(dest[9].(*time.Time)) = now     // UpdatedAt
-		*(dest[10].(**string)) = nil      // ShardName
+		*(dest[10].(*string)) = "utf8mb4" // Charset
+		*(dest[11].(*string)) = "utf8mb4_unicode_ci" // Collation
+		*(dest[12].(**string)) = nil      // ShardName
 		return nil
 	}}
 	db.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(getRow).Once()
@@ -15,12 +15,14 @@ import (
 )
 
 type DatabaseUser struct {
-	svc   *core.DatabaseUserService
-	dbSvc *core.DatabaseService
+	svc       *core.DatabaseUserService
+	dbSvc     *core.DatabaseService
+	tenantSvc *core.TenantService
+	ownership *core.OwnershipService
 }
 
-func NewDatabaseUser(svc *core.DatabaseUserService, dbSvc *core.DatabaseService) *DatabaseUser {
-	return &DatabaseUser{svc: svc, dbSvc: dbSvc}
+func NewDatabaseUser(svc *core.DatabaseUserService, dbSvc *core.DatabaseService, tenantSvc *core.TenantService, ownership *core.OwnershipService) *DatabaseUser {
+	return &DatabaseUser{svc: svc, dbSvc: dbSvc, tenantSvc: tenantSvc, ownership: ownership}
 }
 
 // ListByDatabase godoc
@@ -43,6 +45,10 @@ func (h *DatabaseUser) ListByDatabase(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "databases", databaseID) {
+		return
+	}
+
 	pg := request.ParsePagination(r)
 
 	users, hasMore, err := h.svc.ListByDatabase(r.Context(), databaseID, pg.Limit, pg.Cursor)
@@ -64,7 +70,7 @@ func (h *DatabaseUser) ListByDatabase(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create a database user
-//	@Description	Creates a MySQL user with the given username, password, and privileges on the parent database. Returns 202 and triggers a Temporal workflow to provision the user on the MySQL node. The password is not returned in subsequent GET requests.
+//	@Description	Creates a MySQL user with the given username, password, and privileges on the parent database. The password must meet the password policy of the tenant's brand. Returns 202 and triggers a Temporal workflow to provision the user on the MySQL node. The password is not returned in subsequent GET requests.
 //	@Tags			Database Users
 //	@Security		ApiKeyAuth
 //	@Param			databaseID	path		string						true	"Database ID"
@@ -86,6 +92,10 @@ func (h *DatabaseUser) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "databases", databaseID) {
+		return
+	}
+
 	// Validate username starts with parent database name.
 	db, err := h.dbSvc.GetByID(r.Context(), databaseID)
 	if err != nil {
@@ -96,6 +106,9 @@ func (h *DatabaseUser) Create(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, fmt.Sprintf("username %q must start with database name %q", req.Username, db.ID))
 		return
 	}
+	if !checkPasswordPolicy(w, r, h.tenantSvc, db.TenantID, req.Password) {
+		return
+	}
 
 	now := time.Now()
 	user := &model.DatabaseUser{
@@ -135,6 +148,10 @@ func (h *DatabaseUser) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "database_users", id) {
+		return
+	}
+
 	user, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -148,7 +165,7 @@ func (h *DatabaseUser) Get(w http.ResponseWriter, r *http.Request) {
 // Update godoc
 //
 //	@Summary		Update a database user
-//	@Description	Updates the password and/or privileges of a database user. Returns 202 and triggers a Temporal workflow to apply the changes on the MySQL node. Only provided fields are updated.
+//	@Description	Updates the password and/or privileges of a database user. A new password must meet the password policy of the tenant's brand. Returns 202 and triggers a Temporal workflow to apply the changes on the MySQL node. Only provided fields are updated.
 //	@Tags			Database Users
 //	@Security		ApiKeyAuth
 //	@Param			id		path		string						true	"Database user ID"
@@ -171,6 +188,10 @@ func (h *DatabaseUser) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "database_users", id) {
+		return
+	}
+
 	user, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -180,6 +201,16 @@ func (h *DatabaseUser) Update(w http.ResponseWriter, r *http.Request) {
 	if req.Privileges != nil {
 		user.Privileges = req.Privileges
 	}
+	if req.Password != "" {
+		db, err := h.dbSvc.GetByID(r.Context(), user.DatabaseID)
+		if err != nil {
+			response.WriteServiceError(w, err)
+			return
+		}
+		if !checkPasswordPolicy(w, r, h.tenantSvc, db.TenantID, req.Password) {
+			return
+		}
+	}
 
 	if err := h.svc.Update(r.Context(), user, req.Password); err != nil {
 		response.WriteServiceError(w, err)
@@ -208,6 +239,10 @@ func (h *DatabaseUser) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "database_users", id) {
+		return
+	}
+
 	if err := h.svc.Delete(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -233,6 +268,9 @@ func (h *DatabaseUser) Retry(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !checkOwnership(w, r, h.ownership, "database_users", id) {
+		return
+	}
 	if err := h.svc.Retry(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -11,7 +11,7 @@ import (
 )
 
 func newDatabaseUserHandler() *DatabaseUser {
-	return NewDatabaseUser(nil, nil)
+	return NewDatabaseUser(nil, nil, nil, nil)
 }
 
 // --- ListByDatabase ---
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/edvin/hosting/internal/api/request"
@@ -42,6 +44,10 @@ func (h *EmailAccount) ListByTenant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.services.Ownership, "tenants", tenantID) {
+		return
+	}
+
 	pg := request.ParsePagination(r)
 
 	accounts, hasMore, err := h.svc.ListByTenant(r.Context(), tenantID, pg.Limit, pg.Cursor)
@@ -77,6 +83,10 @@ func (h *EmailAccount) ListByFQDN(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.services.Ownership, "fqdns", fqdnID) {
+		return
+	}
+
 	pg := request.ParsePagination(r)
 
 	accounts, hasMore, err := h.svc.ListByFQDN(r.Context(), fqdnID, pg.Limit, pg.Cursor)
@@ -95,13 +105,14 @@ func (h *EmailAccount) ListByFQDN(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create an email account
-//	@Description	Asynchronously creates an email account (mailbox) under the specified FQDN, which must have email enabled. Supports nested aliases, forwards, and autoreply in a single request. Triggers a Temporal workflow to provision the account in Stalwart. Returns 202 Accepted.
+//	@Description	Asynchronously creates an email account (mailbox) under the specified FQDN, which must have email enabled. Supports nested aliases, forwards, and autoreply in a single request. Triggers a Temporal workflow to provision the account in Stalwart. Returns 202 Accepted, with a no_mx_record warning if the domain has no MX record.
 //	@Tags			Email Accounts
 //	@Security		ApiKeyAuth
 //	@Param			fqdnID path string true "FQDN ID"
 //	@Param			body body request.CreateEmailAccount true "Email account details"
 //	@Success		202 {object} model.EmailAccount
 //	@Failure		400 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
 //	@Failure		500 {object} response.ErrorResponse
 //	@Router			/fqdns/{fqdnID}/email-accounts [post]
 func (h *EmailAccount) Create(w http.ResponseWriter, r *http.Request) {
@@ -117,6 +128,10 @@ func (h *EmailAccount) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.services.Ownership, "fqdns", fqdnID) {
+		return
+	}
+
 	now := time.Now()
 	account := &model.EmailAccount{
 		ID:             platform.NewID(),
@@ -131,6 +146,10 @@ func (h *EmailAccount) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.Create(r.Context(), account); err != nil {
+		if errors.Is(err, core.ErrEmailLimitExceeded) {
+			response.WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
 		response.WriteServiceError(w, err)
 		return
 	}
@@ -195,7 +214,89 @@ func (h *EmailAccount) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	response.WriteJSON(w, http.StatusAccepted, account)
+	response.WriteJSONWithWarnings(w, http.StatusAccepted, account, h.services.FQDN.MailWarnings(r.Context(), fqdnID))
+}
+
+// Import godoc
+//
+//	@Summary		Import email accounts from CSV
+//	@Description	Creates email accounts under an FQDN from a CSV body with the columns address, display_name, quota (bytes) and an optional initial password, which must meet the password policy of the tenant's brand; a header line is allowed. All rows are validated first: if any row is invalid, 422 is returned with per-row errors and nothing is created. The FQDN's account and quota limits apply to the whole batch (409). Accounts are then created and provisioned a few at a time, and the outcome reported per row. With dry_run=true only validation runs.
+//	@Tags			Email Accounts
+//	@Security		ApiKeyAuth
+//	@Accept			text/csv
+//	@Param			fqdnID path string true "FQDN ID"
+//	@Param			subscription_id query string true "Subscription the accounts belong to"
+//	@Param			dry_run query bool false "Only validate"
+//	@Success		200 {object} model.EmailAccountImport "Dry run"
+//	@Success		202 {object} model.EmailAccountImport
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		422 {object} model.EmailAccountImport
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/fqdns/{fqdnID}/email-accounts/import [post]
+func (h *EmailAccount) Import(w http.ResponseWriter, r *http.Request) {
+	fqdnID, err := request.RequireID(chi.URLParam(r, "fqdnID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	subscriptionID, err := request.RequireID(r.URL.Query().Get("subscription_id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, "subscription_id: "+err.Error())
+		return
+	}
+	dryRun := r.URL.Query().Get("dry_run") == "true"
+
+	rows, err := request.ParseEmailAccountCSV(r.Body)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkOwnership(w, r, h.services.Ownership, "fqdns", fqdnID) {
+		return
+	}
+	if slices.ContainsFunc(rows, func(row model.EmailAccountImportRow) bool { return row.Password != "" }) {
+		fqdn, err := h.services.FQDN.GetByID(r.Context(), fqdnID)
+		if err != nil {
+			response.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		policy, err := h.services.Tenant.PasswordPolicy(r.Context(), fqdn.TenantID)
+		if err != nil {
+			response.WriteServiceError(w, err)
+			return
+		}
+		for i, row := range rows {
+			if row.Error == "" && row.Password != "" {
+				if err := policy.Check(row.Password); err != nil {
+					rows[i].Error = err.Error()
+				}
+			}
+		}
+	}
+
+	result, err := h.svc.Import(r.Context(), fqdnID, subscriptionID, rows, dryRun)
+	switch {
+	case errors.Is(err, core.ErrEmailImportInvalid):
+		response.WriteJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	case errors.Is(err, core.ErrEmailLimitExceeded):
+		response.WriteError(w, http.StatusConflict, err.Error())
+		return
+	case errors.Is(err, core.ErrSubscriptionTenantMismatch):
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case err != nil:
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	status := http.StatusAccepted
+	if dryRun {
+		status = http.StatusOK
+	}
+	response.WriteJSON(w, status, result)
 }
 
 // Get godoc
@@ -216,6 +317,10 @@ func (h *EmailAccount) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.services.Ownership, "email_accounts", id) {
+		return
+	}
+
 	account, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -243,6 +348,10 @@ func (h *EmailAccount) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.services.Ownership, "email_accounts", id) {
+		return
+	}
+
 	if err := h.svc.Delete(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -268,6 +377,9 @@ func (h *EmailAccount) Retry(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !checkOwnership(w, r, h.services.Ownership, "email_accounts", id) {
+		return
+	}
 	if err := h.svc.Retry(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -141,3 +141,31 @@ func TestEmailAccountCreate_WithInvalidNestedAutoReply_ValidationFails(t *testin
 	body := decodeErrorResponse(rec)
 	assert.Contains(t, body["error"], "validation error")
 }
+
+// --- Import ---
+
+func TestEmailAccountImport_MissingSubscription(t *testing.T) {
+	h := newEmailAccountHandler()
+	rec := httptest.NewRecorder()
+	r := newRequestRaw(http.MethodPost, "/fqdns/test-fqdn-1/email-accounts/import", "alice@example.com,Alice,0\n")
+	r = withChiURLParam(r, "fqdnID", "test-fqdn-1")
+
+	h.Import(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "subscription_id")
+}
+
+func TestEmailAccountImport_MalformedCSV(t *testing.T) {
+	h := newEmailAccountHandler()
+	rec := httptest.NewRecorder()
+	r := newRequestRaw(http.MethodPost, "/fqdns/test-fqdn-1/email-accounts/import?subscription_id=sub-1", "alice@example.com,\"Alice,0\n")
+	r = withChiURLParam(r, "fqdnID", "test-fqdn-1")
+
+	h.Import(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "invalid CSV")
+}
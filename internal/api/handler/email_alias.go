@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -13,11 +14,12 @@ import (
 )
 
 type EmailAlias struct {
-	svc *core.EmailAliasService
+	svc       *core.EmailAliasService
+	ownership *core.OwnershipService
 }
 
-func NewEmailAlias(svc *core.EmailAliasService) *EmailAlias {
-	return &EmailAlias{svc: svc}
+func NewEmailAlias(svc *core.EmailAliasService, ownership *core.OwnershipService) *EmailAlias {
+	return &EmailAlias{svc: svc, ownership: ownership}
 }
 
 // ListByAccount godoc
@@ -40,6 +42,10 @@ func (h *EmailAlias) ListByAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "email_accounts", id) {
+		return
+	}
+
 	pg := request.ParsePagination(r)
 
 	aliases, hasMore, err := h.svc.ListByAccountID(r.Context(), id, pg.Limit, pg.Cursor)
@@ -58,13 +64,14 @@ func (h *EmailAlias) ListByAccount(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create an email alias
-//	@Description	Asynchronously creates an email alias that delivers to the parent account. The alias address must be a valid email address. Triggers a Temporal workflow to configure the alias in Stalwart. Returns 202 Accepted.
+//	@Description	Asynchronously creates an email alias that delivers to the parent account. The alias address must be a valid email address. With catch_all set and no address, creates the domain's catch-all alias ("@domain"), which receives mail for every address on the domain that no account or other alias claims; a domain can have one. Triggers a Temporal workflow to configure the alias in Stalwart. Returns 202 Accepted.
 //	@Tags			Email Aliases
 //	@Security		ApiKeyAuth
 //	@Param			id path string true "Email account ID"
 //	@Param			body body request.CreateEmailAlias true "Email alias details"
 //	@Success		202 {object} model.EmailAlias
 //	@Failure		400 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
 //	@Failure		500 {object} response.ErrorResponse
 //	@Router			/email-accounts/{id}/aliases [post]
 func (h *EmailAlias) Create(w http.ResponseWriter, r *http.Request) {
@@ -79,18 +86,31 @@ func (h *EmailAlias) Create(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if req.CatchAll && req.Address != "" {
+		response.WriteError(w, http.StatusBadRequest, "address must be empty for a catch-all alias")
+		return
+	}
+
+	if !checkOwnership(w, r, h.ownership, "email_accounts", id) {
+		return
+	}
 
 	now := time.Now()
 	alias := &model.EmailAlias{
 		ID:             platform.NewID(),
 		EmailAccountID: id,
 		Address:        req.Address,
+		CatchAll:       req.CatchAll,
 		Status:         model.StatusPending,
 		CreatedAt:      now,
 		UpdatedAt:      now,
 	}
 
 	if err := h.svc.Create(r.Context(), alias); err != nil {
+		if errors.Is(err, core.ErrCatchAllExists) {
+			response.WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
 		response.WriteServiceError(w, err)
 		return
 	}
@@ -116,6 +136,10 @@ func (h *EmailAlias) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "email_aliases", aliasID) {
+		return
+	}
+
 	alias, err := h.svc.GetByID(r.Context(), aliasID)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -143,6 +167,10 @@ func (h *EmailAlias) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "email_aliases", aliasID) {
+		return
+	}
+
 	if err := h.svc.Delete(r.Context(), aliasID); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -168,6 +196,9 @@ func (h *EmailAlias) Retry(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !checkOwnership(w, r, h.ownership, "email_aliases", id) {
+		return
+	}
 	if err := h.svc.Retry(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
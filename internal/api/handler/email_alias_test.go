@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newEmailAliasHandler() *EmailAlias {
+	return &EmailAlias{svc: nil}
+}
+
+func TestEmailAliasCreate_MissingAddress(t *testing.T) {
+	h := newEmailAliasHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/email-accounts/"+validID+"/aliases", map[string]any{})
+	r = withChiURLParam(r, "id", validID)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestEmailAliasCreate_CatchAllWithAddress(t *testing.T) {
+	h := newEmailAliasHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/email-accounts/"+validID+"/aliases", map[string]any{
+		"address":   "info@example.com",
+		"catch_all": true,
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "catch-all")
+}
@@ -13,11 +13,12 @@ import (
 )
 
 type EmailAutoReply struct {
-	svc *core.EmailAutoReplyService
+	svc       *core.EmailAutoReplyService
+	ownership *core.OwnershipService
 }
 
-func NewEmailAutoReply(svc *core.EmailAutoReplyService) *EmailAutoReply {
-	return &EmailAutoReply{svc: svc}
+func NewEmailAutoReply(svc *core.EmailAutoReplyService, ownership *core.OwnershipService) *EmailAutoReply {
+	return &EmailAutoReply{svc: svc, ownership: ownership}
 }
 
 // Get godoc
@@ -38,6 +39,10 @@ func (h *EmailAutoReply) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "email_accounts", id) {
+		return
+	}
+
 	ar, err := h.svc.GetByAccountID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -72,6 +77,10 @@ func (h *EmailAutoReply) Put(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "email_accounts", id) {
+		return
+	}
+
 	now := time.Now()
 	ar := &model.EmailAutoReply{
 		ID:             platform.NewID(),
@@ -112,6 +121,10 @@ func (h *EmailAutoReply) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "email_accounts", id) {
+		return
+	}
+
 	if err := h.svc.Delete(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -137,6 +150,9 @@ func (h *EmailAutoReply) Retry(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !checkOwnership(w, r, h.ownership, "email_accounts", id) {
+		return
+	}
 	if err := h.svc.Retry(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
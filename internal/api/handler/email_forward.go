@@ -13,11 +13,12 @@ import (
 )
 
 type EmailForward struct {
-	svc *core.EmailForwardService
+	svc       *core.EmailForwardService
+	ownership *core.OwnershipService
 }
 
-func NewEmailForward(svc *core.EmailForwardService) *EmailForward {
-	return &EmailForward{svc: svc}
+func NewEmailForward(svc *core.EmailForwardService, ownership *core.OwnershipService) *EmailForward {
+	return &EmailForward{svc: svc, ownership: ownership}
 }
 
 // ListByAccount godoc
@@ -40,6 +41,10 @@ func (h *EmailForward) ListByAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "email_accounts", id) {
+		return
+	}
+
 	pg := request.ParsePagination(r)
 
 	forwards, hasMore, err := h.svc.ListByAccountID(r.Context(), id, pg.Limit, pg.Cursor)
@@ -80,6 +85,10 @@ func (h *EmailForward) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "email_accounts", id) {
+		return
+	}
+
 	keepCopy := true
 	if req.KeepCopy != nil {
 		keepCopy = *req.KeepCopy
@@ -122,6 +131,10 @@ func (h *EmailForward) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "email_forwards", forwardID) {
+		return
+	}
+
 	fwd, err := h.svc.GetByID(r.Context(), forwardID)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -149,6 +162,10 @@ func (h *EmailForward) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "email_forwards", forwardID) {
+		return
+	}
+
 	if err := h.svc.Delete(r.Context(), forwardID); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -174,6 +191,9 @@ func (h *EmailForward) Retry(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !checkOwnership(w, r, h.ownership, "email_forwards", id) {
+		return
+	}
 	if err := h.svc.Retry(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
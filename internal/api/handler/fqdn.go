@@ -1,15 +1,20 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"slices"
 	"time"
 
+	mw "github.com/edvin/hosting/internal/api/middleware"
 	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/api/response"
 	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/crypto"
 	"github.com/edvin/hosting/internal/model"
 	"github.com/edvin/hosting/internal/platform"
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
 )
 
 type FQDN struct {
@@ -91,23 +96,42 @@ func (h *FQDN) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !checkTenantBrand(w, r, h.services.Tenant, tenantID) {
+	if !checkTenantMutable(w, r, h.services.Tenant, tenantID) {
+		return
+	}
+
+	if err := h.svc.ValidateBinding(r.Context(), tenantID, req.FQDN, req.WebrootID, ""); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	now := time.Now()
 	fqdn := &model.FQDN{
-		ID:        platform.NewID(),
-		TenantID:  tenantID,
-		FQDN:      req.FQDN,
-		WebrootID: req.WebrootID,
-		Status:    model.StatusPending,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:                   platform.NewID(),
+		TenantID:             tenantID,
+		FQDN:                 req.FQDN,
+		WebrootID:            req.WebrootID,
+		ForceHTTPS:           true,
+		RedirectCode:         301,
+		RedirectPreservePath: true,
+		IPMode:               model.IPModeDualStack,
+		Status:               model.StatusPending,
+		CreatedAt:            now,
+		UpdatedAt:            now,
 	}
 	if req.SSLEnabled != nil {
 		fqdn.SSLEnabled = *req.SSLEnabled
 	}
+	if req.ForceHTTPS != nil {
+		fqdn.ForceHTTPS = *req.ForceHTTPS
+	}
+	if req.IPMode != nil {
+		fqdn.IPMode = *req.IPMode
+	}
+	if err := setFQDNRedirect(fqdn, req.RedirectTarget, req.RedirectCode, req.RedirectPreservePath); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	if err := h.svc.Create(r.Context(), fqdn); err != nil {
 		response.WriteServiceError(w, err)
@@ -120,7 +144,7 @@ func (h *FQDN) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.WriteJSON(w, http.StatusAccepted, fqdn)
+	response.WriteJSONWithWarnings(w, http.StatusAccepted, fqdn, h.svc.Warnings(r.Context(), fqdn))
 }
 
 func (h *FQDN) Get(w http.ResponseWriter, r *http.Request) {
@@ -143,6 +167,282 @@ func (h *FQDN) Get(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusOK, fqdn)
 }
 
+// DNSCheck godoc
+//
+//	@Summary		Check DNS resolution for an FQDN
+//	@Description	Resolves the FQDN's A and AAAA records on its authoritative nameservers and on public resolvers, concurrently and with a short per-query timeout. Each server's answer is compared with the load balancer addresses of the tenant's cluster and reported as match, mismatch, no_records, or error, together with the lowest TTL observed. The overall status is match only when every server matches, which is a good signal that a DNS change has propagated.
+//	@Tags			FQDNs
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "FQDN ID"
+//	@Success		200 {object} model.FQDNDNSCheck
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/fqdns/{id}/dns-check [get]
+func (h *FQDN) DNSCheck(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fqdn, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, fqdn.TenantID) {
+		return
+	}
+
+	check, err := h.svc.DNSCheck(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, check)
+}
+
+// certificateKeyExportScope must be granted to a key by name for it to
+// download private keys; the *:* wildcard does not include it.
+const certificateKeyExportScope = "certificates:export_key"
+
+// GetCertificate godoc
+//
+//	@Summary		Download an FQDN's certificate
+//	@Description	Returns the FQDN's active certificate and chain for use elsewhere, e.g. on a CDN. The private key is omitted unless include_key=true is passed. Key downloads require a key that was granted the certificates:export_key scope by name and is bound to the tenant's brand or reseller (platform-wide keys are refused), and are written to the audit log.
+//	@Tags			Certificates
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "FQDN ID"
+//	@Param			include_key query bool false "Include the private key"
+//	@Success		200 {object} model.Certificate
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		401 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/fqdns/{id}/certificate [get]
+func (h *FQDN) GetCertificate(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	includeKey := r.URL.Query().Get("include_key") == "true"
+	if includeKey && !checkKeyExport(w, r) {
+		return
+	}
+
+	fqdn, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, fqdn.TenantID) {
+		return
+	}
+
+	cert, err := h.services.Certificate.GetActiveByFQDN(r.Context(), fqdn.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.WriteError(w, http.StatusNotFound, "no active certificate for this fqdn")
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	if includeKey {
+		mw.AuditRead(r.Context())
+	} else {
+		cert.KeyPEM = ""
+	}
+	response.WriteJSON(w, http.StatusOK, cert)
+}
+
+// checkKeyExport checks that the caller may download private keys. Since
+// there are no single-tenant API keys, it requires the narrowest keys there
+// are: bound to a brand or reseller rather than platform-wide, so together
+// with checkTenantBrand only keys scoped to the owning tenant's brand or
+// reseller get the key. Returns false and writes 401 or 403 otherwise.
+func checkKeyExport(w http.ResponseWriter, r *http.Request) bool {
+	identity := mw.GetIdentity(r.Context())
+	if identity == nil {
+		response.WriteError(w, http.StatusUnauthorized, "private key download requires an authenticated request")
+		return false
+	}
+	if !slices.Contains(identity.Scopes, certificateKeyExportScope) {
+		response.WriteError(w, http.StatusForbidden, "insufficient scope: private key download requires "+certificateKeyExportScope)
+		return false
+	}
+	if mw.IsPlatformAdmin(identity) {
+		response.WriteError(w, http.StatusForbidden, "private key download requires a key bound to the tenant's brand or reseller")
+		return false
+	}
+	return true
+}
+
+// ImportCertificate godoc
+//
+//	@Summary		Import a certificate bundle
+//	@Description	Imports a custom certificate from a single bundle and auto-detects its format: PEM text with certificate, key, and chain in any order, or a base64-encoded PKCS#12 file unlocked with the passphrase. The bundle is split into leaf, key, and chain, checked for expiry and the FQDN, and stored as normalized PEM. Async — returns 202 and deploys the certificate like a regular upload.
+//	@Tags			Certificates
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "FQDN ID"
+//	@Param			body body request.ImportCertificate true "Certificate bundle"
+//	@Success		202 {object} model.Certificate
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/fqdns/{id}/certificate [post]
+func (h *FQDN) ImportCertificate(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.ImportCertificate
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fqdn, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, fqdn.TenantID) {
+		return
+	}
+
+	cert, err := h.services.Certificate.Import(r.Context(), fqdn, []byte(req.Bundle), req.Passphrase)
+	if errors.Is(err, crypto.ErrInvalidCertificateBundle) {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	cert.KeyPEM = ""
+	response.WriteJSON(w, http.StatusAccepted, cert)
+}
+
+// GetDKIM godoc
+//
+//	@Summary		Get an FQDN's DKIM key
+//	@Description	Returns the FQDN's own DKIM selector, public key, and provisioning status. The private key is never returned. Returns 404 if the FQDN signs with the brand's DKIM key.
+//	@Tags			Email
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "FQDN ID"
+//	@Success		200 {object} model.EmailDKIMKey
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/fqdns/{id}/dkim [get]
+func (h *FQDN) GetDKIM(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fqdn, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, fqdn.TenantID) {
+		return
+	}
+
+	key, err := h.services.EmailDKIM.GetByFQDN(r.Context(), fqdn.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		response.WriteError(w, http.StatusNotFound, "fqdn has no dkim key of its own")
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, key)
+}
+
+// ProvisionDKIM godoc
+//
+//	@Summary		Provision an FQDN's DKIM key
+//	@Description	Generates a new 2048-bit RSA DKIM key for the FQDN, installs it as the domain's signing key in Stalwart, and publishes the selector's TXT record in the FQDN's zone. Replaces any earlier key, so calling it again rotates the key. The selector defaults to "s" plus the current year and month. Async — returns 202.
+//	@Tags			Email
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "FQDN ID"
+//	@Param			body body request.ProvisionDKIM true "DKIM options"
+//	@Success		202 {object} model.EmailDKIMKey
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/fqdns/{id}/dkim [post]
+func (h *FQDN) ProvisionDKIM(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.ProvisionDKIM
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fqdn, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, fqdn.TenantID) {
+		return
+	}
+
+	now := time.Now()
+	selector := req.Selector
+	if selector == "" {
+		selector = now.UTC().Format("s200601")
+	}
+
+	privateKeyPEM, publicKey, err := crypto.GenerateDKIMKey()
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	key := &model.EmailDKIMKey{
+		ID:            platform.NewID(),
+		FQDNID:        fqdn.ID,
+		Selector:      selector,
+		PrivateKeyPEM: privateKeyPEM,
+		PublicKey:     publicKey,
+		Status:        model.StatusPending,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := h.services.EmailDKIM.Provision(r.Context(), key); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, key)
+}
+
 func (h *FQDN) Update(w http.ResponseWriter, r *http.Request) {
 	id, err := request.RequireID(chi.URLParam(r, "id"))
 	if err != nil {
@@ -162,22 +462,59 @@ func (h *FQDN) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !checkTenantBrand(w, r, h.services.Tenant, fqdn.TenantID) {
+	if !checkTenantMutable(w, r, h.services.Tenant, fqdn.TenantID) {
 		return
 	}
 
 	if req.WebrootID != nil {
+		if err := h.svc.ValidateBinding(r.Context(), fqdn.TenantID, fqdn.FQDN, req.WebrootID, fqdn.ID); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		fqdn.WebrootID = req.WebrootID
 	}
 	if req.SSLEnabled != nil {
 		fqdn.SSLEnabled = *req.SSLEnabled
 	}
+	if req.ForceHTTPS != nil {
+		fqdn.ForceHTTPS = *req.ForceHTTPS
+	}
+	if err := setFQDNRedirect(fqdn, req.RedirectTarget, req.RedirectCode, req.RedirectPreservePath); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	ipModeChanged := req.IPMode != nil && *req.IPMode != fqdn.IPMode
+	if req.IPMode != nil {
+		fqdn.IPMode = *req.IPMode
+	}
+	if req.MaxEmailAccounts != nil {
+		fqdn.MaxEmailAccounts = req.MaxEmailAccounts
+		if *req.MaxEmailAccounts == 0 {
+			fqdn.MaxEmailAccounts = nil
+		}
+	}
+	if req.EmailQuotaBytes != nil {
+		fqdn.EmailQuotaBytes = req.EmailQuotaBytes
+		if *req.EmailQuotaBytes == 0 {
+			fqdn.EmailQuotaBytes = nil
+		}
+	}
 
 	if err := h.svc.Update(r.Context(), fqdn); err != nil {
 		response.WriteServiceError(w, err)
 		return
 	}
 
+	// A new IP mode changes which auto DNS records the FQDN gets.
+	if ipModeChanged {
+		if err := h.svc.SyncDNS(r.Context(), fqdn); err != nil {
+			response.WriteServiceError(w, err)
+			return
+		}
+		response.WriteJSONWithWarnings(w, http.StatusOK, fqdn, h.svc.Warnings(r.Context(), fqdn))
+		return
+	}
+
 	response.WriteJSON(w, http.StatusOK, fqdn)
 }
 
@@ -194,7 +531,7 @@ func (h *FQDN) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !checkTenantBrand(w, r, h.services.Tenant, fqdn.TenantID) {
+	if !checkTenantMutable(w, r, h.services.Tenant, fqdn.TenantID) {
 		return
 	}
 
@@ -212,6 +549,9 @@ func (h *FQDN) Retry(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !checkOwnership(w, r, h.services.Ownership, "fqdns", id) {
+		return
+	}
 	if err := h.svc.Retry(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
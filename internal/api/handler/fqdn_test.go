@@ -8,6 +8,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	mw "github.com/edvin/hosting/internal/api/middleware"
 )
 
 func newFQDNHandler() *FQDN {
@@ -241,6 +243,45 @@ func TestFQDNCreate_WithInvalidNestedEmail_ValidationFails(t *testing.T) {
 
 // --- Get ---
 
+func TestFQDNCreate_InvalidRedirect(t *testing.T) {
+	tests := []struct {
+		name string
+		body map[string]any
+	}{
+		{"bad code", map[string]any{"fqdn": "www.example.com", "redirect_target": "example.com", "redirect_code": 303}},
+		{"bad target", map[string]any{"fqdn": "www.example.com", "redirect_target": "https://example.com/"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newFQDNHandler()
+			rec := httptest.NewRecorder()
+			tid := "test-tenant-1"
+			r := newRequest(http.MethodPost, "/tenants/"+tid+"/fqdns", tt.body)
+			r = withChiURLParam(r, "tenantID", tid)
+
+			h.Create(rec, r)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			body := decodeErrorResponse(rec)
+			assert.Contains(t, body["error"], "validation error")
+		})
+	}
+}
+
+func TestFQDNCreate_InvalidIPMode(t *testing.T) {
+	h := newFQDNHandler()
+	rec := httptest.NewRecorder()
+	tid := "test-tenant-1"
+	r := newRequest(http.MethodPost, "/tenants/"+tid+"/fqdns", map[string]any{"fqdn": "www.example.com", "ip_mode": "ipv4_only"})
+	r = withChiURLParam(r, "tenantID", tid)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "validation error")
+}
+
 func TestFQDNGet_EmptyID(t *testing.T) {
 	h := newFQDNHandler()
 	rec := httptest.NewRecorder()
@@ -254,6 +295,21 @@ func TestFQDNGet_EmptyID(t *testing.T) {
 	assert.Contains(t, body["error"], "missing required ID")
 }
 
+// --- DNSCheck ---
+
+func TestFQDNDNSCheck_EmptyID(t *testing.T) {
+	h := newFQDNHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/fqdns//dns-check", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.DNSCheck(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
 // --- Delete ---
 
 func TestFQDNDelete_EmptyID(t *testing.T) {
@@ -286,3 +342,103 @@ func TestFQDNCreate_ErrorResponseFormat(t *testing.T) {
 	_, hasError := body["error"]
 	assert.True(t, hasError)
 }
+
+// --- GetCertificate ---
+
+func TestFQDNGetCertificate_EmptyID(t *testing.T) {
+	h := newFQDNHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/fqdns//certificate", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.GetCertificate(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestFQDNImportCertificate_EmptyID(t *testing.T) {
+	h := newFQDNHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/fqdns//certificate", map[string]any{"bundle": "x"})
+	r = withChiURLParam(r, "id", "")
+
+	h.ImportCertificate(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestFQDNImportCertificate_MissingBundle(t *testing.T) {
+	h := newFQDNHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/fqdns/"+validID+"/certificate", map[string]any{"passphrase": "secret"})
+	r = withChiURLParam(r, "id", validID)
+
+	h.ImportCertificate(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestFQDNGetCertificate_IncludeKeyUnauthenticated(t *testing.T) {
+	h := newFQDNHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/fqdns/"+validID+"/certificate?include_key=true", nil)
+	r = withChiURLParam(r, "id", validID)
+
+	h.GetCertificate(rec, r)
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// --- DKIM ---
+
+func TestFQDNGetDKIM_EmptyID(t *testing.T) {
+	h := newFQDNHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/fqdns//dkim", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.GetDKIM(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestFQDNProvisionDKIM_InvalidSelector(t *testing.T) {
+	h := newFQDNHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/fqdns/"+validID+"/dkim", map[string]any{"selector": "Bad_Selector"})
+	r = withChiURLParam(r, "id", validID)
+
+	h.ProvisionDKIM(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestFQDNGetCertificate_IncludeKeyWithoutExportScope(t *testing.T) {
+	h := newFQDNHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/fqdns/"+validID+"/certificate?include_key=true", nil)
+	r = withChiURLParam(r, "id", validID)
+	r = withIdentity(r, &mw.APIKeyIdentity{Scopes: []string{"*:*"}, Brands: []string{"acme"}})
+
+	h.GetCertificate(rec, r)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "certificates:export_key")
+}
+
+func TestFQDNGetCertificate_IncludeKeyPlatformKey(t *testing.T) {
+	h := newFQDNHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/fqdns/"+validID+"/certificate?include_key=true", nil)
+	r = withChiURLParam(r, "id", validID)
+	r = withIdentity(r, &mw.APIKeyIdentity{Scopes: []string{"certificates:read", "certificates:export_key"}, Brands: []string{"*"}})
+
+	h.GetCertificate(rec, r)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "brand or reseller")
+}
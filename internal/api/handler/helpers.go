@@ -2,10 +2,9 @@ package handler
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/hex"
 	"fmt"
 	"net/http"
+	"slices"
 	"time"
 
 	"golang.org/x/crypto/ssh"
@@ -16,9 +15,11 @@ import (
 	"github.com/edvin/hosting/internal/core"
 	"github.com/edvin/hosting/internal/model"
 	"github.com/edvin/hosting/internal/platform"
+	"github.com/edvin/hosting/internal/secrets"
 )
 
-// checkTenantBrand verifies that the caller has brand access to the given tenant.
+// checkTenantBrand verifies that the caller has brand access to the given tenant,
+// and for reseller-scoped keys that the tenant belongs to their reseller.
 // Returns false and writes an error response if access is denied.
 func checkTenantBrand(w http.ResponseWriter, r *http.Request, tenantSvc *core.TenantService, tenantID string) bool {
 	tenant, err := tenantSvc.GetByID(r.Context(), tenantID)
@@ -26,10 +27,79 @@ func checkTenantBrand(w http.ResponseWriter, r *http.Request, tenantSvc *core.Te
 		response.WriteError(w, http.StatusNotFound, err.Error())
 		return false
 	}
-	if !mw.HasBrandAccess(mw.GetIdentity(r.Context()), tenant.BrandID) {
-		response.WriteError(w, http.StatusForbidden, "no access to this brand")
+	if !mw.HasTenantAccess(mw.GetIdentity(r.Context()), tenant.BrandID, tenant.ResellerID) {
+		response.WriteError(w, http.StatusForbidden, "no access to this tenant")
 		return false
 	}
+	mw.AuditTenant(r.Context(), tenant.ID)
+	return true
+}
+
+// checkTenantMutable is checkTenantBrand for requests that change the
+// tenant's web resources. It also rejects them with 409 while the tenant is
+// migrating, since the migration works from a snapshot of those resources.
+func checkTenantMutable(w http.ResponseWriter, r *http.Request, tenantSvc *core.TenantService, tenantID string) bool {
+	tenant, err := tenantSvc.GetByID(r.Context(), tenantID)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return false
+	}
+	if !mw.HasTenantAccess(mw.GetIdentity(r.Context()), tenant.BrandID, tenant.ResellerID) {
+		response.WriteError(w, http.StatusForbidden, "no access to this tenant")
+		return false
+	}
+	mw.AuditTenant(r.Context(), tenant.ID)
+	if tenant.Status == model.StatusMigrating {
+		response.WriteError(w, http.StatusConflict, core.ErrTenantMigrating.Error())
+		return false
+	}
+	return true
+}
+
+// checkOwnership is checkTenantBrand for a resource identified by type and
+// ID, such as "email_aliases", that is resolved to its owning tenant first.
+// Returns false and writes 404 if the resource does not exist or 403 if
+// access is denied.
+func checkOwnership(w http.ResponseWriter, r *http.Request, ownership *core.OwnershipService, resourceType, id string) bool {
+	owner, err := ownership.Owner(r.Context(), resourceType, id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return false
+	}
+	if !mw.HasTenantAccess(mw.GetIdentity(r.Context()), owner.BrandID, owner.ResellerID) {
+		response.WriteError(w, http.StatusForbidden, "no access to this tenant")
+		return false
+	}
+	mw.AuditTenant(r.Context(), owner.TenantID)
+	return true
+}
+
+// checkPasswordPolicy verifies user-supplied passwords against the password
+// policy of the tenant's brand. Empty passwords (left unchanged) are skipped.
+// Returns false and writes a 400 naming the broken rule if one is too weak.
+func checkPasswordPolicy(w http.ResponseWriter, r *http.Request, tenantSvc *core.TenantService, tenantID string, passwords ...string) bool {
+	if !slices.ContainsFunc(passwords, func(pw string) bool { return pw != "" }) {
+		return true
+	}
+	policy, err := tenantSvc.PasswordPolicy(r.Context(), tenantID)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return false
+	}
+	return checkPasswords(w, policy, passwords...)
+}
+
+// checkPasswords is checkPasswordPolicy for an already loaded policy.
+func checkPasswords(w http.ResponseWriter, policy secrets.Policy, passwords ...string) bool {
+	for _, pw := range passwords {
+		if pw == "" {
+			continue
+		}
+		if err := policy.Check(pw); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return false
+		}
+	}
 	return true
 }
 
@@ -42,11 +112,14 @@ func parseSSHKey(publicKey string) (string, error) {
 	return ssh.FingerprintSHA256(pubKey), nil
 }
 
-// generatePassword creates a random 32-character hex password.
-func generatePassword() string {
-	b := make([]byte, 16)
-	_, _ = rand.Read(b)
-	return hex.EncodeToString(b)
+// generatePassword creates a random password with the configured length and
+// character classes (see secrets.DefaultGenerator).
+func generatePassword() (string, error) {
+	pw, err := secrets.Generate()
+	if err != nil {
+		return "", fmt.Errorf("generate password: %w", err)
+	}
+	return pw, nil
 }
 
 // createNestedFQDNs creates FQDNs and their nested email resources for a webroot.
@@ -54,18 +127,34 @@ func createNestedFQDNs(ctx context.Context, services *core.Services, webrootID s
 	for _, fr := range fqdns {
 		now := time.Now()
 		wid := webrootID
+		if err := services.FQDN.ValidateBinding(ctx, tenantID, fr.FQDN, &wid, ""); err != nil {
+			return err
+		}
 		fqdn := &model.FQDN{
-			ID:        platform.NewID(),
-			TenantID:  tenantID,
-			FQDN:      fr.FQDN,
-			WebrootID: &wid,
-			Status:    model.StatusPending,
-			CreatedAt: now,
-			UpdatedAt: now,
+			ID:                   platform.NewID(),
+			TenantID:             tenantID,
+			FQDN:                 fr.FQDN,
+			WebrootID:            &wid,
+			ForceHTTPS:           true,
+			RedirectCode:         301,
+			RedirectPreservePath: true,
+			IPMode:               model.IPModeDualStack,
+			Status:               model.StatusPending,
+			CreatedAt:            now,
+			UpdatedAt:            now,
 		}
 		if fr.SSLEnabled != nil {
 			fqdn.SSLEnabled = *fr.SSLEnabled
 		}
+		if fr.ForceHTTPS != nil {
+			fqdn.ForceHTTPS = *fr.ForceHTTPS
+		}
+		if fr.IPMode != nil {
+			fqdn.IPMode = *fr.IPMode
+		}
+		if err := setFQDNRedirect(fqdn, fr.RedirectTarget, fr.RedirectCode, fr.RedirectPreservePath); err != nil {
+			return err
+		}
 		if err := services.FQDN.Create(ctx, fqdn); err != nil {
 			return fmt.Errorf("create fqdn %s: %s", fr.FQDN, err.Error())
 		}
@@ -76,6 +165,25 @@ func createNestedFQDNs(ctx context.Context, services *core.Services, webrootID s
 	return nil
 }
 
+// setFQDNRedirect applies the redirect fields of a create or update request
+// to fqdn and validates the result. Fields left out keep their current
+// values; an empty target makes the FQDN serve its webroot again.
+func setFQDNRedirect(fqdn *model.FQDN, target *string, code *int, preservePath *bool) error {
+	if target != nil {
+		fqdn.RedirectTarget = target
+		if *target == "" {
+			fqdn.RedirectTarget = nil
+		}
+	}
+	if code != nil {
+		fqdn.RedirectCode = *code
+	}
+	if preservePath != nil {
+		fqdn.RedirectPreservePath = *preservePath
+	}
+	return fqdn.ValidateRedirect()
+}
+
 // createNestedEmailAccounts creates email accounts and their nested aliases, forwards, and auto-replies.
 func createNestedEmailAccounts(ctx context.Context, services *core.Services, fqdnID string, accounts []request.CreateEmailAccountNested) error {
 	for _, ar := range accounts {
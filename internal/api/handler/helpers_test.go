@@ -1,10 +1,21 @@
 package handler
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"unicode"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+
+	mw "github.com/edvin/hosting/internal/api/middleware"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/secrets"
 )
 
 func TestParseSSHKey_Valid(t *testing.T) {
@@ -21,12 +32,199 @@ func TestParseSSHKey_Invalid(t *testing.T) {
 }
 
 func TestGeneratePassword_Length(t *testing.T) {
-	pw := generatePassword()
+	pw, err := generatePassword()
+	require.NoError(t, err)
 	assert.Len(t, pw, 32)
 }
 
 func TestGeneratePassword_Unique(t *testing.T) {
-	pw1 := generatePassword()
-	pw2 := generatePassword()
+	pw1, err := generatePassword()
+	require.NoError(t, err)
+	pw2, err := generatePassword()
+	require.NoError(t, err)
 	assert.NotEqual(t, pw1, pw2)
 }
+
+func TestGeneratePassword_StartsWithLetter(t *testing.T) {
+	for range 50 {
+		pw, err := generatePassword()
+		require.NoError(t, err)
+		assert.True(t, unicode.IsLetter(rune(pw[0])), pw)
+	}
+}
+
+func TestCheckPasswords_RejectsWeak(t *testing.T) {
+	policy := secrets.Policy{MinLength: 10, Classes: []secrets.Class{secrets.Digit}}
+	rec := httptest.NewRecorder()
+
+	ok := checkPasswords(rec, policy, "", "long-enough-1", "no-digits-here")
+
+	assert.False(t, ok)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "must contain a digit")
+}
+
+func TestCheckPasswords_SkipsEmpty(t *testing.T) {
+	policy := secrets.Policy{MinLength: 12}
+	rec := httptest.NewRecorder()
+
+	assert.True(t, checkPasswords(rec, policy, "", "a-strong-passphrase"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// ownedBy returns an OwnershipService for which every resource belongs to
+// tenant-b of brand-b and the given reseller.
+func ownedBy(resellerID *string) *core.OwnershipService {
+	db := &handlerMockDB{}
+	isTenantQuery := mock.MatchedBy(func(sql string) bool { return strings.Contains(sql, "FROM tenants") })
+	db.On("QueryRow", mock.Anything, isTenantQuery, mock.Anything).Return(&handlerMockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "brand-b"
+		*(dest[1].(**string)) = resellerID
+		return nil
+	}})
+	db.On("QueryRow", mock.Anything, mock.Anything, mock.Anything).Return(&handlerMockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "tenant-b"
+		return nil
+	}})
+	return core.NewOwnershipService(db)
+}
+
+func withIdentity(r *http.Request, identity *mw.APIKeyIdentity) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), mw.APIKeyIdentityKey, identity))
+}
+
+func TestCheckOwnership_SameBrand(t *testing.T) {
+	rec := httptest.NewRecorder()
+	r := withIdentity(newRequest(http.MethodGet, "/", nil), &mw.APIKeyIdentity{Brands: []string{"brand-b"}})
+
+	assert.True(t, checkOwnership(rec, r, ownedBy(nil), "email_aliases", validID))
+}
+
+func TestCheckOwnership_NotFound(t *testing.T) {
+	db := &handlerMockDB{}
+	db.On("QueryRow", mock.Anything, mock.Anything, mock.Anything).Return(&handlerMockRow{scanFunc: func(dest ...any) error {
+		return pgx.ErrNoRows
+	}})
+	rec := httptest.NewRecorder()
+	r := withPlatformAdmin(newRequest(http.MethodGet, "/", nil))
+
+	assert.False(t, checkOwnership(rec, r, core.NewOwnershipService(db), "email_aliases", validID))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+// crossTenantCase is a resource-scoped endpoint called for a resource of
+// another tenant.
+type crossTenantCase struct {
+	name    string
+	handle  http.HandlerFunc
+	method  string
+	params  map[string]string
+	body    any
+	rawBody string
+	query   string
+}
+
+// crossTenantCases lists every endpoint that resolves its tenant with
+// checkOwnership. The handlers have no other services, so one that skipped
+// the check would panic instead of returning 403.
+func crossTenantCases(own *core.OwnershipService) []crossTenantCase {
+	services := &core.Services{Ownership: own}
+	alias := &EmailAlias{ownership: own}
+	forward := &EmailForward{ownership: own}
+	autoReply := &EmailAutoReply{ownership: own}
+	account := &EmailAccount{services: services}
+	fqdn := &FQDN{services: services}
+	cert := &Certificate{ownership: own}
+	dbUser := &DatabaseUser{ownership: own}
+	valkeyUser := &ValkeyUser{ownership: own}
+	s3Key := &S3AccessKey{ownership: own}
+	record := &ZoneRecord{ownership: own}
+	logs := &Logs{ownership: own}
+	login := &OIDCLogin{ownership: own}
+
+	id := map[string]string{"id": validID}
+	return []crossTenantCase{
+		{name: "email alias list", handle: alias.ListByAccount, method: http.MethodGet, params: id},
+		{name: "email alias create", handle: alias.Create, method: http.MethodPost, params: id, body: map[string]any{"address": "info@example.com"}},
+		{name: "email alias get", handle: alias.Get, method: http.MethodGet, params: map[string]string{"aliasID": validID}},
+		{name: "email alias delete", handle: alias.Delete, method: http.MethodDelete, params: map[string]string{"aliasID": validID}},
+		{name: "email alias retry", handle: alias.Retry, method: http.MethodPost, params: map[string]string{"aliasID": validID}},
+		{name: "email forward list", handle: forward.ListByAccount, method: http.MethodGet, params: id},
+		{name: "email forward create", handle: forward.Create, method: http.MethodPost, params: id, body: map[string]any{"destination": "user@example.net"}},
+		{name: "email forward get", handle: forward.Get, method: http.MethodGet, params: map[string]string{"forwardID": validID}},
+		{name: "email forward delete", handle: forward.Delete, method: http.MethodDelete, params: map[string]string{"forwardID": validID}},
+		{name: "email forward retry", handle: forward.Retry, method: http.MethodPost, params: map[string]string{"forwardID": validID}},
+		{name: "email autoreply get", handle: autoReply.Get, method: http.MethodGet, params: id},
+		{name: "email autoreply put", handle: autoReply.Put, method: http.MethodPut, params: id, body: map[string]any{"subject": "Away", "body": "Back soon"}},
+		{name: "email autoreply delete", handle: autoReply.Delete, method: http.MethodDelete, params: id},
+		{name: "email autoreply retry", handle: autoReply.Retry, method: http.MethodPost, params: id},
+		{name: "email account list by tenant", handle: account.ListByTenant, method: http.MethodGet, params: map[string]string{"tenantID": validID}},
+		{name: "email account list by fqdn", handle: account.ListByFQDN, method: http.MethodGet, params: map[string]string{"fqdnID": validID}},
+		{name: "email account create", handle: account.Create, method: http.MethodPost, params: map[string]string{"fqdnID": validID}, body: map[string]any{"subscription_id": validID2, "address": "info@example.com"}},
+		{name: "email account import", handle: account.Import, method: http.MethodPost, params: map[string]string{"fqdnID": validID}, rawBody: "address\ninfo@example.com\n", query: "?subscription_id=" + validID2},
+		{name: "email account get", handle: account.Get, method: http.MethodGet, params: id},
+		{name: "email account delete", handle: account.Delete, method: http.MethodDelete, params: id},
+		{name: "email account retry", handle: account.Retry, method: http.MethodPost, params: id},
+		{name: "fqdn retry", handle: fqdn.Retry, method: http.MethodPost, params: id},
+		{name: "certificate list", handle: cert.ListByFQDN, method: http.MethodGet, params: map[string]string{"fqdnID": validID}},
+		{name: "certificate upload", handle: cert.Upload, method: http.MethodPost, params: map[string]string{"fqdnID": validID}, body: map[string]any{"cert_pem": "cert", "key_pem": "key"}},
+		{name: "certificate retry", handle: cert.Retry, method: http.MethodPost, params: id},
+		{name: "certificate renew", handle: cert.Renew, method: http.MethodPost, params: id},
+		{name: "certificate renewal", handle: cert.GetRenewal, method: http.MethodGet, params: id},
+		{name: "certificate bulk provision", handle: cert.BulkProvision, method: http.MethodPost, params: map[string]string{"tenantID": validID}, body: map[string]any{"fqdn_ids": []string{validID2}}},
+		{name: "database user list", handle: dbUser.ListByDatabase, method: http.MethodGet, params: map[string]string{"databaseID": validID}},
+		{name: "database user create", handle: dbUser.Create, method: http.MethodPost, params: map[string]string{"databaseID": validID}, body: map[string]any{"username": "test_id_1_app", "password": "long-enough-password", "privileges": []string{"ALL"}}},
+		{name: "database user get", handle: dbUser.Get, method: http.MethodGet, params: id},
+		{name: "database user update", handle: dbUser.Update, method: http.MethodPut, params: id, body: map[string]any{"privileges": []string{"SELECT"}}},
+		{name: "database user delete", handle: dbUser.Delete, method: http.MethodDelete, params: id},
+		{name: "database user retry", handle: dbUser.Retry, method: http.MethodPost, params: id},
+		{name: "valkey user list", handle: valkeyUser.ListByInstance, method: http.MethodGet, params: map[string]string{"instanceID": validID}},
+		{name: "valkey user create", handle: valkeyUser.Create, method: http.MethodPost, params: map[string]string{"instanceID": validID}, body: map[string]any{"username": "test-id-1-app", "password": "long-enough-password", "privileges": []string{"allcommands"}}},
+		{name: "valkey user get", handle: valkeyUser.Get, method: http.MethodGet, params: id},
+		{name: "valkey user update", handle: valkeyUser.Update, method: http.MethodPut, params: id, body: map[string]any{"privileges": []string{"allcommands"}}},
+		{name: "valkey user delete", handle: valkeyUser.Delete, method: http.MethodDelete, params: id},
+		{name: "valkey user retry", handle: valkeyUser.Retry, method: http.MethodPost, params: id},
+		{name: "s3 access key list", handle: s3Key.ListByBucket, method: http.MethodGet, params: map[string]string{"bucketID": validID}},
+		{name: "s3 access key create", handle: s3Key.Create, method: http.MethodPost, params: map[string]string{"bucketID": validID}, body: map[string]any{}},
+		{name: "s3 access key delete", handle: s3Key.Delete, method: http.MethodDelete, params: id},
+		{name: "zone record list", handle: record.ListByZone, method: http.MethodGet, params: map[string]string{"zoneID": validID}},
+		{name: "zone record create", handle: record.Create, method: http.MethodPost, params: map[string]string{"zoneID": validID}, body: map[string]any{"type": "A", "name": "www", "content": "192.0.2.1"}},
+		{name: "zone record replace", handle: record.ReplaceByZone, method: http.MethodPut, params: map[string]string{"zoneID": validID}, body: map[string]any{"records": []map[string]any{{"type": "A", "name": "www", "content": "192.0.2.1"}}}},
+		{name: "zone record get", handle: record.Get, method: http.MethodGet, params: id},
+		{name: "zone record update", handle: record.Update, method: http.MethodPut, params: id, body: map[string]any{"content": "192.0.2.2"}},
+		{name: "zone record delete", handle: record.Delete, method: http.MethodDelete, params: id},
+		{name: "zone record retry", handle: record.Retry, method: http.MethodPost, params: id},
+		{name: "tenant logs", handle: logs.TenantLogs, method: http.MethodGet, params: map[string]string{"tenantID": validID}},
+		{name: "delete tenant logs", handle: logs.DeleteTenantLogs, method: http.MethodDelete, params: map[string]string{"tenantID": validID}},
+		{name: "login session", handle: login.CreateLoginSession, method: http.MethodPost, params: id},
+	}
+}
+
+func runCrossTenantCases(t *testing.T, own *core.OwnershipService, identity *mw.APIKeyIdentity) {
+	for _, tc := range crossTenantCases(own) {
+		t.Run(tc.name, func(t *testing.T) {
+			var r *http.Request
+			if tc.rawBody != "" {
+				r = newRequestRaw(tc.method, "/"+tc.query, tc.rawBody)
+			} else {
+				r = newRequest(tc.method, "/"+tc.query, tc.body)
+			}
+			r = withIdentity(withChiURLParams(r, tc.params), identity)
+			rec := httptest.NewRecorder()
+
+			tc.handle(rec, r)
+
+			assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestCrossTenantAccess_OtherBrand(t *testing.T) {
+	runCrossTenantCases(t, ownedBy(nil), &mw.APIKeyIdentity{Scopes: []string{"*:*"}, Brands: []string{"brand-a"}})
+}
+
+func TestCrossTenantAccess_OtherReseller(t *testing.T) {
+	other := "reseller-2"
+	runCrossTenantCases(t, ownedBy(&other), &mw.APIKeyIdentity{Scopes: []string{"*:*"}, Brands: []string{"brand-b"}, ResellerID: "reseller-1"})
+}
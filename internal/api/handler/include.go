@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+
+	mw "github.com/edvin/hosting/internal/api/middleware"
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/api/response"
+)
+
+// parseIncludes parses the ?include= expansions of a GET. allowed maps each
+// expansion the endpoint offers to the scope resource whose read access it
+// requires, so that an include never returns what the API key could not
+// list on its own. Returns false and writes 400 or 403 on failure.
+func parseIncludes(w http.ResponseWriter, r *http.Request, allowed map[string]string) ([]string, bool) {
+	includes, err := request.ParseIncludes(r, slices.Sorted(maps.Keys(allowed))...)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	identity := mw.GetIdentity(r.Context())
+	for _, name := range includes {
+		if !mw.HasScope(identity, allowed[name], "read") {
+			response.WriteError(w, http.StatusForbidden, "insufficient scope: include="+name+" requires "+allowed[name]+":read")
+			return nil, false
+		}
+	}
+	return includes, true
+}
+
+// includedList returns the first page of an included resource for the
+// response, as [] rather than null when empty. If there are more than fit
+// on the page, name is added to truncated.
+func includedList[T any](list []T, hasMore bool, name string, truncated *[]string) *[]T {
+	if list == nil {
+		list = []T{}
+	}
+	if hasMore {
+		*truncated = append(*truncated, name)
+	}
+	return &list
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
+)
+
+type LoginSession struct {
+	svc       *core.LoginSessionService
+	tenantSvc *core.TenantService
+}
+
+func NewLoginSession(svc *core.LoginSessionService, tenantSvc *core.TenantService) *LoginSession {
+	return &LoginSession{svc: svc, tenantSvc: tenantSvc}
+}
+
+// ListByTenant godoc
+//
+//	@Summary		List active login sessions
+//	@Description	Returns the tenant's login sessions that are still pending (created but not yet used) or have live DB Admin access (a temporary MySQL user that has not expired), newest first.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		200 {array} model.OIDCLoginSession
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/sessions [get]
+func (h *LoginSession) ListByTenant(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, id) {
+		return
+	}
+
+	sessions, err := h.svc.ListActive(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, sessions)
+}
+
+// RevokeAll godoc
+//
+//	@Summary		Revoke all login sessions
+//	@Description	Revokes all of the tenant's pending and active login sessions. Pending sessions can no longer be used to log in; the temporary MySQL users of active ones are dropped and their connections killed asynchronously, which ends the DB Admin sessions. Allowed for suspended tenants. Returns the revoked sessions.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		202 {array} model.OIDCLoginSession
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/sessions [delete]
+func (h *LoginSession) RevokeAll(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, id) {
+		return
+	}
+
+	sessions, err := h.svc.Revoke(r.Context(), id, "")
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	response.WriteJSON(w, http.StatusAccepted, sessions)
+}
+
+// Revoke godoc
+//
+//	@Summary		Revoke a login session
+//	@Description	Revokes one pending or active login session of the tenant, like DELETE /tenants/{id}/sessions. Returns 404 if the session is not a live session of the tenant.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Param			sessionID path string true "Login session ID"
+//	@Success		202 {object} model.OIDCLoginSession
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/sessions/{sessionID} [delete]
+func (h *LoginSession) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	sessionID, err := request.RequireID(chi.URLParam(r, "sessionID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, id) {
+		return
+	}
+
+	sessions, err := h.svc.Revoke(r.Context(), id, sessionID)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	if len(sessions) == 0 {
+		response.WriteError(w, http.StatusNotFound, "login session not found or no longer active")
+		return
+	}
+	response.WriteJSON(w, http.StatusAccepted, sessions[0])
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newLoginSessionHandler() *LoginSession {
+	return NewLoginSession(nil, nil)
+}
+
+func TestLoginSessionListByTenant_EmptyID(t *testing.T) {
+	h := newLoginSessionHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/tenants//sessions", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.ListByTenant(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestLoginSessionRevokeAll_EmptyID(t *testing.T) {
+	h := newLoginSessionHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/tenants//sessions", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.RevokeAll(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestLoginSessionRevoke_EmptySessionID(t *testing.T) {
+	h := newLoginSessionHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/tenants/test-tenant-1/sessions/", nil)
+	r = withChiURLParams(r, map[string]string{"id": "test-tenant-1", "sessionID": ""})
+
+	h.Revoke(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -19,14 +20,16 @@ type Logs struct {
 	lokiURL       string
 	tenantLokiURL string
 	client        *http.Client
+	ownership     *core.OwnershipService
 }
 
 // NewLogs creates a new Logs handler.
-func NewLogs(lokiURL, tenantLokiURL string) *Logs {
+func NewLogs(lokiURL, tenantLokiURL string, ownership *core.OwnershipService) *Logs {
 	return &Logs{
 		lokiURL:       strings.TrimRight(lokiURL, "/"),
 		tenantLokiURL: strings.TrimRight(tenantLokiURL, "/"),
 		client:        &http.Client{Timeout: 30 * time.Second},
+		ownership:     ownership,
 	}
 }
 
@@ -91,6 +94,10 @@ func (h *Logs) TenantLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "tenants", tenantID) {
+		return
+	}
+
 	// Validate log_type if provided
 	logType := r.URL.Query().Get("log_type")
 	if logType != "" {
@@ -154,6 +161,10 @@ func (h *Logs) DeleteTenantLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "tenants", tenantID) {
+		return
+	}
+
 	query := fmt.Sprintf(`{tenant_id="%s"}`, tenantID)
 
 	now := time.Now()
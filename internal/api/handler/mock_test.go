@@ -31,6 +31,14 @@ func (m *handlerMockDB) QueryRow(ctx context.Context, sql string, arguments ...a
 	return args.Get(0).(pgx.Row)
 }
 
+func (m *handlerMockDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(pgx.Tx), args.Error(1)
+}
+
 // handlerMockRow implements pgx.Row for handler tests.
 type handlerMockRow struct {
 	scanFunc func(dest ...any) error
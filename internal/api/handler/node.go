@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -142,6 +143,137 @@ func (h *Node) Get(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusOK, node)
 }
 
+// Diagnostics godoc
+//
+//	@Summary		Run node diagnostics
+//	@Description	Synchronously runs the node agent self-tests on the node's task queue and returns a structured report. Checks depend on the node's roles: CephFS mount, nginx -t and supervisor status on web nodes, MySQL connectivity on database nodes, and Valkey config dir writability on valkey nodes. A failing check is reported in the result and does not stop the others. Returns 500 if the node agent does not pick up the request.
+//	@Tags			Nodes
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Node ID"
+//	@Success		200	{object}	model.NodeDiagnostics
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/nodes/{id}/diagnostics [get]
+func (h *Node) Diagnostics(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	node, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	report, err := h.svc.Diagnostics(r.Context(), node)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, report)
+}
+
+// Stats godoc
+//
+//	@Summary		Get node resource usage
+//	@Description	Returns the latest CPU load, memory and per-mount disk usage collected from the node agent by the node stats cron (every 5 minutes). Metrics the agent could not read are omitted and described in errors. Returns 404 if no stats have been collected for the node yet.
+//	@Tags			Nodes
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Node ID"
+//	@Success		200	{object}	model.NodeStats
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/nodes/{id}/stats [get]
+func (h *Node) Stats(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stats, err := h.svc.GetStats(r.Context(), id)
+	if errors.Is(err, core.ErrNoNodeStats) {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, stats)
+}
+
+// Capabilities godoc
+//
+//	@Summary		Get node agent capabilities
+//	@Description	Returns the agent version and the Temporal activities last reported by the node agent, refreshed by the node capabilities cron (every 5 minutes). Workflows skip nodes whose agent does not register an activity they need. Returns 404 if the agent has not reported yet.
+//	@Tags			Nodes
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Node ID"
+//	@Success		200	{object}	model.NodeCapabilities
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/nodes/{id}/capabilities [get]
+func (h *Node) Capabilities(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	caps, err := h.svc.GetCapabilities(r.Context(), id)
+	if errors.Is(err, core.ErrNoNodeCapabilities) {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, caps)
+}
+
+// Resources godoc
+//
+//	@Summary		List resources hosted on a node
+//	@Description	Synchronously lists the tenants, webroots, databases, Valkey instances and daemons hosted on a node, for planning a drain or migration before decommissioning it. Resources are found through the node's shard assignments and the node_id of databases and daemons. Pinned resources live only on this node (databases on a shard the node is primary of, databases and daemons bound to the node) and must be migrated or failed over first; the rest float across the shard's other nodes. Shards with node_count 1 have no other node to take over.
+//	@Tags			Nodes
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Node ID"
+//	@Success		200	{object}	model.NodeResources
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/nodes/{id}/resources [get]
+func (h *Node) Resources(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := h.svc.GetByID(r.Context(), id); err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	resources, err := h.svc.ListResources(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, resources)
+}
+
 // Update godoc
 //
 //	@Summary		Update a node
@@ -189,6 +189,66 @@ func TestNodeGet_EmptyID(t *testing.T) {
 	assert.Contains(t, body["error"], "missing required ID")
 }
 
+// --- Diagnostics ---
+
+func TestNodeDiagnostics_EmptyID(t *testing.T) {
+	h := newNodeHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/nodes//diagnostics", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Diagnostics(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+// --- Stats ---
+
+func TestNodeStats_EmptyID(t *testing.T) {
+	h := newNodeHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/nodes//stats", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Stats(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+// --- Capabilities ---
+
+func TestNodeCapabilities_EmptyID(t *testing.T) {
+	h := newNodeHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/nodes//capabilities", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Capabilities(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+// --- Resources ---
+
+func TestNodeResources_EmptyID(t *testing.T) {
+	h := newNodeHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/nodes//resources", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Resources(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
 // --- Update ---
 
 func TestNodeUpdate_EmptyID(t *testing.T) {
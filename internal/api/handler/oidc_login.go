@@ -2,6 +2,7 @@ package handler
 
 import (
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
@@ -13,16 +14,19 @@ import (
 	"github.com/edvin/hosting/internal/api/response"
 	"github.com/edvin/hosting/internal/core"
 	"github.com/edvin/hosting/internal/crypto"
+	"github.com/edvin/hosting/internal/model"
 	"github.com/edvin/hosting/internal/workflow"
 )
 
 type OIDCLogin struct {
 	oidcSvc        *core.OIDCService
+	sessionSvc     *core.LoginSessionService
+	ownership      *core.OwnershipService
 	temporalClient temporalclient.Client
 }
 
-func NewOIDCLogin(oidcSvc *core.OIDCService, temporalClient temporalclient.Client) *OIDCLogin {
-	return &OIDCLogin{oidcSvc: oidcSvc, temporalClient: temporalClient}
+func NewOIDCLogin(oidcSvc *core.OIDCService, sessionSvc *core.LoginSessionService, ownership *core.OwnershipService, temporalClient temporalclient.Client) *OIDCLogin {
+	return &OIDCLogin{oidcSvc: oidcSvc, sessionSvc: sessionSvc, ownership: ownership, temporalClient: temporalClient}
 }
 
 // CreateLoginSession godoc
@@ -43,6 +47,10 @@ func (h *OIDCLogin) CreateLoginSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "tenants", id) {
+		return
+	}
+
 	if err := h.oidcSvc.EnsureSigningKey(r.Context()); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -109,12 +117,14 @@ func (h *OIDCLogin) ValidateLoginSession(w http.ResponseWriter, r *http.Request)
 // CreateTempAccess godoc
 //
 //	@Summary		Create temporary MySQL access
-//	@Description	Creates a temporary MySQL user with access to a specific database. The user auto-expires after 2 hours. Internal endpoint used by the dbadmin proxy.
+//	@Description	Creates a temporary MySQL user with access to a specific database for a login session the dbadmin proxy has just validated. The user is recorded on the session, so it can be listed and revoked, and auto-expires after 2 hours. Each session gets at most one user; revoked sessions get none (403). Internal endpoint used by the dbadmin proxy.
 //	@Tags			Internal
 //	@Security		ApiKeyAuth
 //	@Param			id path string true "Database ID"
+//	@Param			session_id query string true "Validated login session ID"
 //	@Success		200 {object} map[string]any
 //	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
 //	@Failure		500 {object} response.ErrorResponse
 //	@Router			/internal/v1/databases/{id}/temp-access [post]
 func (h *OIDCLogin) CreateTempAccess(w http.ResponseWriter, r *http.Request) {
@@ -123,6 +133,11 @@ func (h *OIDCLogin) CreateTempAccess(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	sessionID := r.URL.Query().Get("session_id")
+	if sessionID == "" {
+		response.WriteError(w, http.StatusBadRequest, "missing session_id parameter")
+		return
+	}
 
 	// Look up database connection info (includes primary node IP).
 	// No tenant filter here — this is an internal endpoint called by dbadmin-proxy
@@ -142,9 +157,24 @@ func (h *OIDCLogin) CreateTempAccess(w http.ResponseWriter, r *http.Request) {
 
 	// Generate temporary credentials.
 	username := "tmp_" + randomAlphanumeric(8)
-	password := randomAlphanumeric(32)
+	password, err := generatePassword()
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "failed to generate password")
+		return
+	}
 	passwordHash := crypto.MysqlNativePasswordHash(password)
 
+	// Register the user on the session before creating it, so a revoke from
+	// now on knows which user to drop.
+	if err := h.sessionSvc.Activate(r.Context(), sessionID, dbID, username); err != nil {
+		if errors.Is(err, core.ErrLoginSessionNotUsable) {
+			response.WriteError(w, http.StatusForbidden, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
 	// Start workflow and wait for completion.
 	workflowID := fmt.Sprintf("temp-access-%s-%s", dbID, username)
 	run, err := h.temporalClient.ExecuteWorkflow(r.Context(), temporalclient.StartWorkflowOptions{
@@ -166,6 +196,18 @@ func (h *OIDCLogin) CreateTempAccess(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A revoke that came in while the user was being created may have tried
+	// to drop it before it existed; drop it again.
+	if revoked, err := h.sessionSvc.IsRevoked(r.Context(), sessionID); err != nil || revoked {
+		_ = h.sessionSvc.StartRevokeAccess(r.Context(), "", model.RevokeLoginSessionParams{
+			SessionID: sessionID,
+			ShardID:   shardID,
+			Username:  username,
+		})
+		response.WriteError(w, http.StatusForbidden, core.ErrLoginSessionNotUsable.Error())
+		return
+	}
+
 	response.WriteJSON(w, http.StatusOK, map[string]any{
 		"username":      username,
 		"password":      password,
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	mw "github.com/edvin/hosting/internal/api/middleware"
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+type Operation struct {
+	svc       *core.OperationService
+	tenantSvc *core.TenantService
+}
+
+func NewOperation(svc *core.OperationService, tenantSvc *core.TenantService) *Operation {
+	return &Operation{svc: svc, tenantSvc: tenantSvc}
+}
+
+// Get godoc
+//
+//	@Summary		Get an async operation
+//	@Description	Returns the status of an operation started by a mutating request. Every workflow a request starts is recorded as an operation and its ID returned in an X-Operation-ID response header. Status is one of pending (queued behind other work for the tenant), running, succeeded or failed; failed operations include the error. Operations not tied to a tenant are only visible to platform admins.
+//	@Tags			Operations
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Operation ID"
+//	@Success		200 {object} model.Operation
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/operations/{id} [get]
+func (h *Operation) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	op, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.WriteError(w, http.StatusNotFound, "operation not found")
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	if !mw.IsPlatformAdmin(mw.GetIdentity(r.Context())) {
+		if op.TenantID == nil {
+			response.WriteError(w, http.StatusNotFound, "operation not found")
+			return
+		}
+		if !checkTenantBrand(w, r, h.tenantSvc, *op.TenantID) {
+			return
+		}
+	}
+
+	response.WriteJSON(w, http.StatusOK, op)
+}
+
+// History godoc
+//
+//	@Summary		Get the workflow history of an operation
+//	@Description	Returns the Temporal event history of the operation's workflow as a list of steps: the workflow itself, then its activities, child workflows and timers in the order they were scheduled, each with its status, attempt, duration, error and a one-line summary. Inputs are decoded with passwords, keys, tokens, env vars and other secrets redacted, and long strings shortened. For running workflows, activities that are being retried show their current attempt and last error. Pending operations have no steps yet. Returns 404 if the history has been purged from Temporal. Platform admin only.
+//	@Tags			Operations
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Operation ID"
+//	@Success		200 {object} model.OperationHistory
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/operations/{id}/history [get]
+func (h *Operation) History(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	history, err := h.svc.History(r.Context(), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			response.WriteError(w, http.StatusNotFound, "operation not found")
+		case errors.Is(err, core.ErrOperationHistoryUnavailable):
+			response.WriteError(w, http.StatusNotFound, err.Error())
+		default:
+			response.WriteServiceError(w, err)
+		}
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, history)
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edvin/hosting/internal/core"
+)
+
+func newOperationHandler() *Operation {
+	return &Operation{svc: core.NewOperationService(nil, nil), tenantSvc: nil}
+}
+
+func TestOperationGet_EmptyID(t *testing.T) {
+	h := newOperationHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/operations/", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Get(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestOperationHistory_EmptyID(t *testing.T) {
+	h := newOperationHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/operations//history", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.History(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
@@ -51,7 +51,7 @@ func (h *Region) List(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create a region
-//	@Description	Synchronously creates a region with a slug ID and optional JSON config. Returns 201 on success.
+//	@Description	Synchronously creates a region with a slug ID and optional JSON config. config.dns.nameservers lists the region's nameservers as hostname/address pairs, used for zone replication. Returns 201 on success.
 //	@Tags			Regions
 //	@Security	ApiKeyAuth
 //	@Param		body	body		request.CreateRegion	true	"Region"
@@ -66,6 +66,11 @@ func (h *Region) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := request.ValidateRegionConfig(req.Config); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	cfg := req.Config
 	if cfg == nil {
 		cfg = json.RawMessage(`{}`)
@@ -118,7 +123,7 @@ func (h *Region) Get(w http.ResponseWriter, r *http.Request) {
 // Update godoc
 //
 //	@Summary		Update a region
-//	@Description	Synchronously performs a partial update of a region's name or config. Only provided fields are changed.
+//	@Description	Synchronously performs a partial update of a region's name or config. Only provided fields are changed. Changing config.dns.nameservers does not update zones that already replicate to the region; update their secondary_region_ids to apply it.
 //	@Tags			Regions
 //	@Security	ApiKeyAuth
 //	@Param		id		path		string				true	"Region ID"
@@ -140,6 +145,10 @@ func (h *Region) Update(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := request.ValidateRegionConfig(req.Config); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	region, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
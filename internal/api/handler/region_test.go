@@ -119,6 +119,23 @@ func TestRegionCreate_ValidBodyWithConfig(t *testing.T) {
 	assert.NotEqual(t, http.StatusBadRequest, rec.Code)
 }
 
+func TestRegionCreate_InvalidNameserver(t *testing.T) {
+	h := newRegionHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/regions", map[string]any{
+		"name": "fra-1",
+		"config": map[string]any{"dns": map[string]any{
+			"nameservers": []map[string]any{{"hostname": "ns1.fra.example.net", "address": "not-an-ip"}},
+		}},
+	})
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "invalid address")
+}
+
 func TestRegionCreate_OptionalConfig(t *testing.T) {
 	h := newRegionHandler()
 	rec := httptest.NewRecorder()
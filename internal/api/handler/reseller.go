@@ -0,0 +1,402 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	mw "github.com/edvin/hosting/internal/api/middleware"
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	"github.com/go-chi/chi/v5"
+)
+
+type Reseller struct {
+	svc      *core.ResellerService
+	services *core.Services
+}
+
+func NewReseller(services *core.Services) *Reseller {
+	return &Reseller{svc: services.Reseller, services: services}
+}
+
+// checkResellerAccess fetches a reseller and verifies the caller can access
+// it: brand access, and for reseller-scoped keys only their own reseller.
+func (h *Reseller) checkResellerAccess(w http.ResponseWriter, r *http.Request, id string) (*model.Reseller, bool) {
+	reseller, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	identity := mw.GetIdentity(r.Context())
+	if !mw.HasBrandAccess(identity, reseller.BrandID) {
+		response.WriteError(w, http.StatusForbidden, "no access to this brand")
+		return nil, false
+	}
+	if mw.IsResellerScoped(identity) && identity.ResellerID != reseller.ID {
+		response.WriteError(w, http.StatusForbidden, "no access to this reseller")
+		return nil, false
+	}
+	return reseller, true
+}
+
+// List godoc
+//
+//	@Summary		List resellers
+//	@Description	Returns a paginated list of resellers in the caller's brands. Resellers own a subset of a brand's tenants. Reseller-scoped keys only see their own reseller.
+//	@Tags			Resellers
+//	@Security		ApiKeyAuth
+//	@Param			search query string false "Search by ID or name"
+//	@Param			sort query string false "Sort field" default(created_at)
+//	@Param			order query string false "Sort order (asc/desc)" default(asc)
+//	@Param			limit query int false "Page size" default(50)
+//	@Param			cursor query string false "Pagination cursor"
+//	@Success		200 {object} response.PaginatedResponse{items=[]model.Reseller}
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/resellers [get]
+func (h *Reseller) List(w http.ResponseWriter, r *http.Request) {
+	params := request.ParseListParams(r, "created_at")
+	params.BrandIDs = mw.BrandIDs(r.Context())
+	params.ResellerID = mw.ResellerID(r.Context())
+
+	resellers, hasMore, err := h.svc.List(r.Context(), params)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	var nextCursor string
+	if hasMore && len(resellers) > 0 {
+		nextCursor = resellers[len(resellers)-1].ID
+	}
+	response.WritePaginated(w, http.StatusOK, resellers, nextCursor, hasMore)
+}
+
+// Create godoc
+//
+//	@Summary		Create a reseller
+//	@Description	Creates a reseller within a brand. Assign tenants to it and issue reseller-scoped API keys that only reach those tenants. Synchronous (201).
+//	@Tags			Resellers
+//	@Security		ApiKeyAuth
+//	@Param			body body request.CreateReseller true "Reseller details"
+//	@Success		201 {object} model.Reseller
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/resellers [post]
+func (h *Reseller) Create(w http.ResponseWriter, r *http.Request) {
+	var req request.CreateReseller
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !mw.HasBrandAccess(mw.GetIdentity(r.Context()), req.BrandID) {
+		response.WriteError(w, http.StatusForbidden, "no access to this brand")
+		return
+	}
+
+	now := time.Now()
+	reseller := &model.Reseller{
+		ID:        platform.NewID(),
+		BrandID:   req.BrandID,
+		Name:      req.Name,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.svc.Create(r.Context(), reseller); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusCreated, reseller)
+}
+
+// Get godoc
+//
+//	@Summary		Get a reseller
+//	@Description	Returns a single reseller by ID.
+//	@Tags			Resellers
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Reseller ID"
+//	@Success		200 {object} model.Reseller
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Router			/resellers/{id} [get]
+func (h *Reseller) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reseller, ok := h.checkResellerAccess(w, r, id)
+	if !ok {
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, reseller)
+}
+
+// Update godoc
+//
+//	@Summary		Update a reseller
+//	@Description	Renames a reseller. Synchronous.
+//	@Tags			Resellers
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Reseller ID"
+//	@Param			body body request.UpdateReseller true "Reseller updates"
+//	@Success		200 {object} model.Reseller
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/resellers/{id} [put]
+func (h *Reseller) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.UpdateReseller
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reseller, ok := h.checkResellerAccess(w, r, id)
+	if !ok {
+		return
+	}
+
+	reseller.Name = req.Name
+	if err := h.svc.Update(r.Context(), reseller); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, reseller)
+}
+
+// Delete godoc
+//
+//	@Summary		Delete a reseller
+//	@Description	Deletes a reseller and its API keys. Fails with 409 while tenants are still assigned to it. Synchronous (204).
+//	@Tags			Resellers
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Reseller ID"
+//	@Success		204
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/resellers/{id} [delete]
+func (h *Reseller) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, ok := h.checkResellerAccess(w, r, id); !ok {
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, core.ErrResellerHasTenants) {
+			response.WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListTenants godoc
+//
+//	@Summary		List a reseller's tenants
+//	@Description	Returns a paginated list of the tenants assigned to a reseller, with the same search, status filtering, and sorting as the tenant list. Reseller-scoped keys may only list their own reseller.
+//	@Tags			Resellers
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Reseller ID"
+//	@Param			search query string false "Search query"
+//	@Param			status query string false "Filter by status"
+//	@Param			sort query string false "Sort field" default(created_at)
+//	@Param			order query string false "Sort order (asc/desc)" default(asc)
+//	@Param			limit query int false "Page size" default(50)
+//	@Param			cursor query string false "Pagination cursor"
+//	@Success		200 {object} response.PaginatedResponse{items=[]model.Tenant}
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/resellers/{id}/tenants [get]
+func (h *Reseller) ListTenants(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reseller, ok := h.checkResellerAccess(w, r, id)
+	if !ok {
+		return
+	}
+
+	params := request.ParseListParams(r, "created_at")
+	params.BrandIDs = []string{reseller.BrandID}
+	params.ResellerID = reseller.ID
+
+	tenants, hasMore, err := h.services.Tenant.List(r.Context(), params)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	var nextCursor string
+	if hasMore && len(tenants) > 0 {
+		nextCursor = tenants[len(tenants)-1].ID
+	}
+	response.WritePaginated(w, http.StatusOK, tenants, nextCursor, hasMore)
+}
+
+// AssignTenant godoc
+//
+//	@Summary		Assign a tenant to a reseller
+//	@Description	Moves a tenant of the reseller's brand under the reseller, reassigning it if another reseller owned it. Synchronous (204).
+//	@Tags			Resellers
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Reseller ID"
+//	@Param			body body request.AssignResellerTenant true "Tenant to assign"
+//	@Success		204
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/resellers/{id}/tenants [post]
+func (h *Reseller) AssignTenant(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.AssignResellerTenant
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, ok := h.checkResellerAccess(w, r, id); !ok {
+		return
+	}
+
+	if err := h.svc.AssignTenant(r.Context(), id, req.TenantID); err != nil {
+		if errors.Is(err, core.ErrResellerBrandMismatch) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// UnassignTenant godoc
+//
+//	@Summary		Remove a tenant from a reseller
+//	@Description	Returns a tenant to its brand. Reseller-scoped keys lose access to it. Synchronous (204).
+//	@Tags			Resellers
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Reseller ID"
+//	@Param			tenantID path string true "Tenant ID"
+//	@Success		204
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/resellers/{id}/tenants/{tenantID} [delete]
+func (h *Reseller) UnassignTenant(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	tenantID, err := request.RequireID(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, ok := h.checkResellerAccess(w, r, id); !ok {
+		return
+	}
+
+	if err := h.svc.UnassignTenant(r.Context(), id, tenantID); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CreateAPIKey godoc
+//
+//	@Summary		Create a reseller API key
+//	@Description	Generates an API key bound to the reseller. It has the reseller's brand access and only sees and acts on the reseller's tenants and their zones; brand-level endpoints reject it. The full key value is returned exactly once. Synchronous (201).
+//	@Tags			Resellers
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Reseller ID"
+//	@Param			body body request.CreateResellerAPIKey true "API key details"
+//	@Success		201 {object} map[string]any
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/resellers/{id}/api-keys [post]
+func (h *Reseller) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.CreateResellerAPIKey
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reseller, ok := h.checkResellerAccess(w, r, id)
+	if !ok {
+		return
+	}
+
+	key, rawKey, err := h.services.APIKey.CreateForReseller(r.Context(), req.Name, req.Scopes, reseller)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	resp := map[string]any{
+		"id":          key.ID,
+		"name":        key.Name,
+		"key":         rawKey,
+		"key_prefix":  key.KeyPrefix,
+		"scopes":      key.Scopes,
+		"brands":      key.Brands,
+		"reseller_id": reseller.ID,
+		"created_at":  key.CreatedAt,
+	}
+	response.WriteJSON(w, http.StatusCreated, resp)
+}
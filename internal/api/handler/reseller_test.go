@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	mw "github.com/edvin/hosting/internal/api/middleware"
+)
+
+func TestResellerCreate_MissingName(t *testing.T) {
+	h := &Reseller{}
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/resellers", map[string]any{"brand_id": "acme"})
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestResellerCreate_NoBrandAccess(t *testing.T) {
+	h := &Reseller{}
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/resellers", map[string]any{"brand_id": "acme", "name": "Partner"})
+	r = r.WithContext(context.WithValue(r.Context(), mw.APIKeyIdentityKey, &mw.APIKeyIdentity{Brands: []string{"other"}}))
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestResellerAssignTenant_MissingTenantID(t *testing.T) {
+	h := &Reseller{}
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/resellers/test-reseller-1/tenants", map[string]any{})
+	r = withChiURLParam(r, "id", "test-reseller-1")
+
+	h.AssignTenant(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestResellerListTenants_EmptyID(t *testing.T) {
+	h := &Reseller{}
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/resellers//tenants", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.ListTenants(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestResellerCreateAPIKey_MissingScopes(t *testing.T) {
+	h := &Reseller{}
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/resellers/test-reseller-1/api-keys", map[string]any{"name": "panel"})
+	r = withChiURLParam(r, "id", "test-reseller-1")
+
+	h.CreateAPIKey(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAPIKeyMe_ResellerKey(t *testing.T) {
+	h := &APIKey{}
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/me", nil)
+	r = r.WithContext(context.WithValue(r.Context(), mw.APIKeyIdentityKey, &mw.APIKeyIdentity{
+		ID: "key-1", Scopes: []string{"tenants:read"}, Brands: []string{"acme"}, ResellerID: "reseller-1",
+	}))
+
+	h.Me(rec, r)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"reseller_id":"reseller-1"`)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -13,11 +14,21 @@ import (
 )
 
 type S3AccessKey struct {
-	svc *core.S3AccessKeyService
+	svc       *core.S3AccessKeyService
+	bucketSvc *core.S3BucketService
+	tenantSvc *core.TenantService
+	ownership *core.OwnershipService
 }
 
-func NewS3AccessKey(svc *core.S3AccessKeyService) *S3AccessKey {
-	return &S3AccessKey{svc: svc}
+func NewS3AccessKey(svc *core.S3AccessKeyService, bucketSvc *core.S3BucketService, tenantSvc *core.TenantService, ownership *core.OwnershipService) *S3AccessKey {
+	return &S3AccessKey{svc: svc, bucketSvc: bucketSvc, tenantSvc: tenantSvc, ownership: ownership}
+}
+
+// keyWithSecret is an access key with its secret, returned only when the
+// secret is generated.
+type keyWithSecret struct {
+	*model.S3AccessKey
+	SecretAccessKey string `json:"secret_access_key"`
 }
 
 // ListByBucket godoc
@@ -40,6 +51,10 @@ func (h *S3AccessKey) ListByBucket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "s3_buckets", bucketID) {
+		return
+	}
+
 	pg := request.ParsePagination(r)
 
 	keys, hasMore, err := h.svc.ListByBucket(r.Context(), bucketID, pg.Limit, pg.Cursor)
@@ -80,6 +95,10 @@ func (h *S3AccessKey) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "s3_buckets", bucketID) {
+		return
+	}
+
 	permissions := req.Permissions
 	if permissions == "" {
 		permissions = "read-write"
@@ -102,16 +121,63 @@ func (h *S3AccessKey) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Return with secret visible (only shown on creation).
-	type keyWithSecret struct {
-		*model.S3AccessKey
-		SecretAccessKey string `json:"secret_access_key"`
-	}
 	response.WriteJSON(w, http.StatusCreated, keyWithSecret{
 		S3AccessKey:    key,
 		SecretAccessKey: secretAccessKey,
 	})
 }
 
+// Rotate godoc
+//
+//	@Summary		Rotate an S3 access key secret
+//	@Description	Generates a new secret for an active access key, keeping the same access key ID. The new secret is only returned once in this response. The old secret stops working once the workflow has applied the new one in RGW, at which point the key returns to active. Triggers a Temporal workflow and returns 202.
+//	@Tags			S3 Access Keys
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"S3 access key ID"
+//	@Success		202	{object}	model.S3AccessKey
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		403	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		409	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/s3-access-keys/{id}/rotate [post]
+func (h *S3AccessKey) Rotate(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	key, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	bucket, err := h.bucketSvc.GetByID(r.Context(), key.S3BucketID)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantMutable(w, r, h.tenantSvc, bucket.TenantID) {
+		return
+	}
+
+	rotated, secretAccessKey, err := h.svc.Rotate(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, core.ErrS3AccessKeyNotActive) {
+			response.WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, keyWithSecret{
+		S3AccessKey:     rotated,
+		SecretAccessKey: secretAccessKey,
+	})
+}
+
 // Delete godoc
 //
 //	@Summary		Delete an S3 access key
@@ -130,6 +196,10 @@ func (h *S3AccessKey) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "s3_access_keys", id) {
+		return
+	}
+
 	if err := h.svc.Delete(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
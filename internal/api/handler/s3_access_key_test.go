@@ -120,3 +120,18 @@ func TestS3AccessKeyDelete_EmptyID(t *testing.T) {
 	body := decodeErrorResponse(rec)
 	assert.Contains(t, body["error"], "missing required ID")
 }
+
+// --- Rotate ---
+
+func TestS3AccessKeyRotate_EmptyID(t *testing.T) {
+	h := newS3AccessKeyHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/s3-access-keys//rotate", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Rotate(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
@@ -91,6 +91,12 @@ func (h *S3Bucket) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	rules, err := model.NormalizeS3LifecycleRules(req.LifecycleRules)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid lifecycle_rules: "+err.Error())
+		return
+	}
+
 	if !checkTenantBrand(w, r, h.tenantSvc, tenantID) {
 		return
 	}
@@ -102,6 +108,7 @@ func (h *S3Bucket) Create(w http.ResponseWriter, r *http.Request) {
 		TenantID:       tenantID,
 		SubscriptionID: req.SubscriptionID,
 		ShardID:        &shardID,
+		LifecycleRules: rules,
 		Status:    model.StatusPending,
 		CreatedAt: now,
 		UpdatedAt: now,
@@ -200,6 +207,60 @@ func (h *S3Bucket) Update(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusAccepted, bucket)
 }
 
+// SetLifecycle godoc
+//
+//	@Summary		Set S3 bucket lifecycle rules
+//	@Description	Replaces the bucket's lifecycle rules. Each rule applies to a key prefix (empty for the whole bucket) and expires objects and/or aborts incomplete multipart uploads after a number of days. Rules without an id are named rule-N by position. An empty list removes the lifecycle configuration. Applying the same rules again leaves the bucket unchanged. Triggers a Temporal workflow and returns 202 immediately.
+//	@Tags			S3 Buckets
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string							true	"S3 bucket ID"
+//	@Param			body	body		request.SetS3BucketLifecycle	true	"Lifecycle rules"
+//	@Success		202		{object}	model.S3Bucket
+//	@Failure		400		{object}	response.ErrorResponse
+//	@Failure		404		{object}	response.ErrorResponse
+//	@Failure		500		{object}	response.ErrorResponse
+//	@Router			/s3-buckets/{id}/lifecycle [put]
+func (h *S3Bucket) SetLifecycle(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.SetS3BucketLifecycle
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	rules, err := model.NormalizeS3LifecycleRules(req.Rules)
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, "invalid rules: "+err.Error())
+		return
+	}
+
+	bucket, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, bucket.TenantID) {
+		return
+	}
+
+	if err := h.svc.SetLifecycle(r.Context(), id, rules); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	bucket, err = h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, bucket)
+}
+
 // Delete godoc
 //
 //	@Summary		Delete an S3 bucket
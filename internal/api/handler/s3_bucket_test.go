@@ -165,6 +165,64 @@ func TestS3BucketUpdate_InvalidJSON(t *testing.T) {
 	assert.Contains(t, body["error"], "invalid JSON")
 }
 
+// --- SetLifecycle ---
+
+func TestS3BucketSetLifecycle_EmptyID(t *testing.T) {
+	h := newS3BucketHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/s3-buckets//lifecycle", map[string]any{"rules": []any{}})
+	r = withChiURLParam(r, "id", "")
+
+	h.SetLifecycle(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestS3BucketSetLifecycle_InvalidJSON(t *testing.T) {
+	h := newS3BucketHandler()
+	rec := httptest.NewRecorder()
+	r := newRequestRaw(http.MethodPut, "/s3-buckets/"+validID+"/lifecycle", "{bad json")
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetLifecycle(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "invalid JSON")
+}
+
+func TestS3BucketSetLifecycle_InvalidRule(t *testing.T) {
+	h := newS3BucketHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/s3-buckets/"+validID+"/lifecycle", map[string]any{
+		"rules": []map[string]any{{"prefix": "/logs", "expiration_days": 7}},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetLifecycle(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "must not start with /")
+}
+
+func TestS3BucketSetLifecycle_NoAction(t *testing.T) {
+	h := newS3BucketHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/s3-buckets/"+validID+"/lifecycle", map[string]any{
+		"rules": []map[string]any{{"prefix": "tmp/"}},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetLifecycle(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "expiration_days")
+}
+
 // --- Delete ---
 
 func TestS3BucketDelete_EmptyID(t *testing.T) {
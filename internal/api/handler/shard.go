@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
@@ -59,7 +60,7 @@ func (h *Shard) ListByCluster(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create a shard
-//	@Description	Synchronously creates a shard with a role (web, database, dns, email, valkey, storage, dbadmin, or lb). The role determines what services the shard's nodes run. Returns 201 on success.
+//	@Description	Synchronously creates a shard with a role (web, database, dns, email, valkey, storage, dbadmin, or lb). The role determines what services the shard's nodes run. A web shard's config may carry nginx tuning under nginx, which is validated and applied to the nodes' nginx.conf on convergence. Returns 201 on success.
 //	@Tags			Shards
 //	@Security		ApiKeyAuth
 //	@Param			clusterID	path		string				true	"Cluster ID"
@@ -81,6 +82,11 @@ func (h *Shard) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := request.ValidateShardConfig(req.Role, req.Config); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	cfg := req.Config
 	if cfg == nil {
 		cfg = json.RawMessage(`{}`)
@@ -137,7 +143,7 @@ func (h *Shard) Get(w http.ResponseWriter, r *http.Request) {
 // Update godoc
 //
 //	@Summary		Update a shard
-//	@Description	Synchronously performs a partial update of a shard's lb_backend, config, or status. Only provided fields are changed.
+//	@Description	Synchronously performs a partial update of a shard's lb_backend, config, or status. Only provided fields are changed. Config changes, such as a web shard's nginx tuning, take effect on the next convergence.
 //	@Tags			Shards
 //	@Security		ApiKeyAuth
 //	@Param			id		path		string				true	"Shard ID"
@@ -170,6 +176,10 @@ func (h *Shard) Update(w http.ResponseWriter, r *http.Request) {
 		shard.LBBackend = req.LBBackend
 	}
 	if req.Config != nil {
+		if err := request.ValidateShardConfig(shard.Role, req.Config); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		shard.Config = req.Config
 	}
 	if req.Status != "" {
@@ -187,13 +197,14 @@ func (h *Shard) Update(w http.ResponseWriter, r *http.Request) {
 // Converge godoc
 //
 //	@Summary		Trigger shard convergence
-//	@Description	Starts an asynchronous convergence workflow that reconciles all nodes in the shard, ensuring web server configs, databases, DNS zones, and other services match the desired state. This is the primary mechanism for applying configuration changes. Returns 202 immediately.
+//	@Description	Starts an asynchronous convergence workflow that reconciles all nodes in the shard, ensuring web server configs, databases, DNS zones, and other services match the desired state. This is the primary mechanism for applying configuration changes. Returns 202 immediately, or 409 if a convergence for the shard is already running.
 //	@Tags			Shards
 //	@Security		ApiKeyAuth
 //	@Param			id	path		string	true	"Shard ID"
 //	@Success		202	{object}	map[string]string
 //	@Failure		400	{object}	response.ErrorResponse
 //	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		409	{object}	response.ErrorResponse
 //	@Failure		500	{object}	response.ErrorResponse
 //	@Router			/shards/{id}/converge [post]
 func (h *Shard) Converge(w http.ResponseWriter, r *http.Request) {
@@ -210,6 +221,10 @@ func (h *Shard) Converge(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.Converge(r.Context(), id); err != nil {
+		if errors.Is(err, core.ErrConvergeRunning) {
+			response.WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
 		response.WriteServiceError(w, err)
 		return
 	}
@@ -217,6 +232,162 @@ func (h *Shard) Converge(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusAccepted, map[string]string{"status": "converging"})
 }
 
+// PromoteReplica godoc
+//
+//	@Summary		Promote a database replica to primary
+//	@Description	Fails a database shard over to one of its replicas, for when the primary has failed. Starts PromoteReplicaWorkflow, which makes the old primary read-only (best effort), checks that the replica has applied all replication it received and lags at most max_lag_seconds (default 30), stops replication on it, makes it read-write, records it as the shard's primary and repoints the remaining replicas. node_id picks the replica; by default the first caught-up replica is used. force promotes even if the replica is not caught up, accepting the loss of unreplicated transactions. Returns 202 immediately, or 409 while the shard is converging.
+//	@Tags			Shards
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string					true	"Shard ID"
+//	@Param			body	body		request.PromoteReplica	false	"Promotion options"
+//	@Success		202		{object}	map[string]string
+//	@Failure		400		{object}	response.ErrorResponse
+//	@Failure		404		{object}	response.ErrorResponse
+//	@Failure		409		{object}	response.ErrorResponse
+//	@Failure		500		{object}	response.ErrorResponse
+//	@Router			/shards/{id}/promote-replica [post]
+func (h *Shard) PromoteReplica(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.PromoteReplica
+	if r.ContentLength != 0 {
+		if err := request.Decode(r, &req); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	shard, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	err = h.svc.PromoteReplica(r.Context(), shard, core.PromoteReplicaParams{
+		NodeID:        req.NodeID,
+		MaxLagSeconds: req.MaxLagSeconds,
+		Force:         req.Force,
+	})
+	switch {
+	case errors.Is(err, core.ErrNotDatabaseShard):
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, core.ErrConvergeRunning):
+		response.WriteError(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, map[string]string{
+		"status":      "promoting",
+		"workflow_id": core.PromoteReplicaWorkflowID(id),
+	})
+}
+
+// ConvergeStatus godoc
+//
+//	@Summary		Get shard convergence status
+//	@Description	Reports whether a convergence workflow is currently running for the shard, whether it was started via the API, a cluster batch, or tenant deletion.
+//	@Tags			Shards
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Shard ID"
+//	@Success		200	{object}	model.ShardConvergence
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/shards/{id}/converge [get]
+func (h *Shard) ConvergeStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	_, err = h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	status, err := h.svc.ConvergeStatus(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, status)
+}
+
+// ConvergeCluster godoc
+//
+//	@Summary		Converge all shards in a cluster
+//	@Description	Starts a batch that runs shard convergence for every shard in the cluster, at most max_concurrent shards at a time (default 2). Returns 202 with a batch ID; poll GET /converge-batches/{id} for per-shard results.
+//	@Tags			Shards
+//	@Security		ApiKeyAuth
+//	@Param			clusterID	path		string					true	"Cluster ID"
+//	@Param			body		body		request.ConvergeCluster	false	"Batch options"
+//	@Success		202			{object}	map[string]string
+//	@Failure		400			{object}	response.ErrorResponse
+//	@Failure		404			{object}	response.ErrorResponse
+//	@Failure		500			{object}	response.ErrorResponse
+//	@Router			/clusters/{clusterID}/converge [post]
+func (h *Shard) ConvergeCluster(w http.ResponseWriter, r *http.Request) {
+	clusterID, err := request.RequireID(chi.URLParam(r, "clusterID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.ConvergeCluster
+	if r.ContentLength != 0 {
+		if err := request.Decode(r, &req); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	batchID, err := h.svc.ConvergeCluster(r.Context(), clusterID, req.MaxConcurrent)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, map[string]string{"batch_id": batchID, "status": model.ConvergeBatchRunning})
+}
+
+// GetConvergeBatch godoc
+//
+//	@Summary		Get cluster convergence batch progress
+//	@Description	Returns aggregated progress for a cluster convergence batch, including per-shard status and errors.
+//	@Tags			Shards
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Batch ID"
+//	@Success		200	{object}	model.ConvergeBatch
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Router			/converge-batches/{id} [get]
+func (h *Shard) GetConvergeBatch(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	batch, err := h.svc.GetConvergeBatch(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, batch)
+}
+
 // Retry godoc
 //
 //	@Summary		Retry a failed shard convergence
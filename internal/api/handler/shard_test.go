@@ -169,6 +169,41 @@ func TestShardCreate_ValidBodyWithOptionals(t *testing.T) {
 	assert.NotEqual(t, http.StatusBadRequest, rec.Code)
 }
 
+func TestShardCreate_InvalidNginxTuning(t *testing.T) {
+	h := newShardHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/clusters/"+validID+"/shards", map[string]any{
+		"name":   "web-shard-01",
+		"role":   "web",
+		"config": map[string]any{"nginx": map[string]any{"client_max_body_size": "1m; include /etc/passwd"}},
+	})
+	r = withChiURLParam(r, "clusterID", validID)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "client_max_body_size")
+}
+
+func TestShardCreate_ValidNginxTuning(t *testing.T) {
+	h := newShardHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/clusters/"+validID+"/shards", map[string]any{
+		"name":   "web-shard-01",
+		"role":   "web",
+		"config": map[string]any{"nginx": map[string]any{"worker_connections": 4096, "gzip_types": []string{"text/css"}}},
+	})
+	r = withChiURLParam(r, "clusterID", validID)
+
+	func() {
+		defer func() { recover() }()
+		h.Create(rec, r)
+	}()
+
+	assert.NotEqual(t, http.StatusBadRequest, rec.Code)
+}
+
 // --- Get ---
 
 func TestShardGet_EmptyID(t *testing.T) {
@@ -256,3 +291,31 @@ func TestShardCreate_ErrorResponseFormat(t *testing.T) {
 	_, hasError := body["error"]
 	assert.True(t, hasError)
 }
+
+// --- PromoteReplica ---
+
+func TestShardPromoteReplica_EmptyID(t *testing.T) {
+	h := newShardHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/shards//promote-replica", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.PromoteReplica(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestShardPromoteReplica_InvalidMaxLag(t *testing.T) {
+	h := newShardHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/shards/"+validID+"/promote-replica", map[string]any{
+		"max_lag_seconds": 7200,
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.PromoteReplica(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
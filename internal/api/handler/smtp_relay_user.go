@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	"github.com/go-chi/chi/v5"
+)
+
+type SMTPRelayUser struct {
+	svc       *core.SMTPRelayUserService
+	tenantSvc *core.TenantService
+}
+
+func NewSMTPRelayUser(svc *core.SMTPRelayUserService, tenantSvc *core.TenantService) *SMTPRelayUser {
+	return &SMTPRelayUser{svc: svc, tenantSvc: tenantSvc}
+}
+
+// relayUserWithPassword is a relay user with its password, returned only
+// when the user is created.
+type relayUserWithPassword struct {
+	*model.SMTPRelayUser
+	Password string `json:"password"`
+}
+
+// ListByTenant godoc
+//
+//	@Summary		List SMTP relay users for a tenant
+//	@Description	Returns a paginated list of the tenant's SMTP relay users with their relay hostname and port. Passwords are never returned after creation.
+//	@Tags			SMTP Relay Users
+//	@Security		ApiKeyAuth
+//	@Param			tenantID	path		string	true	"Tenant ID"
+//	@Param			limit		query		int		false	"Page size"	default(50)
+//	@Param			cursor		query		string	false	"Pagination cursor"
+//	@Success		200			{object}	response.PaginatedResponse{items=[]model.SMTPRelayUser}
+//	@Failure		400			{object}	response.ErrorResponse
+//	@Failure		500			{object}	response.ErrorResponse
+//	@Router			/tenants/{tenantID}/smtp-relay-users [get]
+func (h *SMTPRelayUser) ListByTenant(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := request.RequireID(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.tenantSvc, tenantID) {
+		return
+	}
+
+	pg := request.ParsePagination(r)
+
+	users, hasMore, err := h.svc.ListByTenant(r.Context(), tenantID, pg.Limit, pg.Cursor)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	var nextCursor string
+	if hasMore && len(users) > 0 {
+		nextCursor = users[len(users)-1].ID
+	}
+	response.WritePaginated(w, http.StatusOK, users, nextCursor, hasMore)
+}
+
+// Create godoc
+//
+//	@Summary		Create an SMTP relay user
+//	@Description	Creates SMTP submission credentials for the tenant's applications, provisioned in Stalwart. The username is generated and the password is only returned once in this response. Clients connect to the returned hostname on port 587 with STARTTLS and may send as any address. max_messages_per_hour defaults to 100. Returns 400 if the tenant's cluster has no mail server. Triggers a Temporal workflow and returns 202.
+//	@Tags			SMTP Relay Users
+//	@Security		ApiKeyAuth
+//	@Param			tenantID	path		string						true	"Tenant ID"
+//	@Param			body		body		request.CreateSMTPRelayUser	true	"Relay user details"
+//	@Success		202			{object}	model.SMTPRelayUser
+//	@Failure		400			{object}	response.ErrorResponse
+//	@Failure		500			{object}	response.ErrorResponse
+//	@Router			/tenants/{tenantID}/smtp-relay-users [post]
+func (h *SMTPRelayUser) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := request.RequireID(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.CreateSMTPRelayUser
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.tenantSvc, tenantID) {
+		return
+	}
+
+	maxPerHour := req.MaxMessagesPerHour
+	if maxPerHour == 0 {
+		maxPerHour = model.DefaultSMTPRelayMaxMessagesPerHour
+	}
+
+	now := time.Now()
+	name := platform.NewName("relay-")
+	user := &model.SMTPRelayUser{
+		ID:                 name,
+		TenantID:           tenantID,
+		Username:           name,
+		MaxMessagesPerHour: maxPerHour,
+		Status:             model.StatusPending,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+
+	password, err := h.svc.Create(r.Context(), user)
+	if err != nil {
+		if errors.Is(err, core.ErrEmailNotEnabled) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, relayUserWithPassword{
+		SMTPRelayUser: user,
+		Password:      password,
+	})
+}
+
+// Get godoc
+//
+//	@Summary		Get an SMTP relay user
+//	@Description	Returns a relay user with the messages it submitted in the last hour and day. send_counts is omitted when the platform log store cannot be queried.
+//	@Tags			SMTP Relay Users
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Relay user ID"
+//	@Success		200	{object}	model.SMTPRelayUser
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Router			/smtp-relay-users/{id} [get]
+func (h *SMTPRelayUser) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, user.TenantID) {
+		return
+	}
+
+	h.svc.LoadSendCounts(r.Context(), user)
+	response.WriteJSON(w, http.StatusOK, user)
+}
+
+// Update godoc
+//
+//	@Summary		Update an SMTP relay user's rate limit
+//	@Description	Changes how many messages the relay user may submit per hour (1-10000). Stalwart rejects submissions over the limit with a temporary error. Triggers a Temporal workflow and returns 202.
+//	@Tags			SMTP Relay Users
+//	@Security		ApiKeyAuth
+//	@Param			id		path	string						true	"Relay user ID"
+//	@Param			body	body	request.UpdateSMTPRelayUser	true	"Rate limit"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/smtp-relay-users/{id} [put]
+func (h *SMTPRelayUser) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.UpdateSMTPRelayUser
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantMutable(w, r, h.tenantSvc, user.TenantID) {
+		return
+	}
+
+	if err := h.svc.UpdateRateLimit(r.Context(), id, req.MaxMessagesPerHour); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Delete godoc
+//
+//	@Summary		Delete an SMTP relay user
+//	@Description	Removes the relay user and its rate limit from Stalwart. Triggers a Temporal workflow and returns 202.
+//	@Tags			SMTP Relay Users
+//	@Security		ApiKeyAuth
+//	@Param			id	path	string	true	"Relay user ID"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/smtp-relay-users/{id} [delete]
+func (h *SMTPRelayUser) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, user.TenantID) {
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), id); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Retry godoc
+//
+//	@Summary		Retry a failed SMTP relay user
+//	@Description	Re-triggers the provisioning workflow for a relay user in failed state.
+//	@Tags			SMTP Relay Users
+//	@Security		ApiKeyAuth
+//	@Param			id	path	string	true	"Relay user ID"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/smtp-relay-users/{id}/retry [post]
+func (h *SMTPRelayUser) Retry(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	user, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, user.TenantID) {
+		return
+	}
+	if err := h.svc.Retry(r.Context(), id); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
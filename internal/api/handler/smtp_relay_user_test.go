@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newSMTPRelayUserHandler() *SMTPRelayUser {
+	return &SMTPRelayUser{svc: nil}
+}
+
+func TestSMTPRelayUserCreate_EmptyTenantID(t *testing.T) {
+	h := newSMTPRelayUserHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants//smtp-relay-users", map[string]any{})
+	r = withChiURLParam(r, "tenantID", "")
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestSMTPRelayUserCreate_RateLimitTooHigh(t *testing.T) {
+	h := newSMTPRelayUserHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants/"+validID+"/smtp-relay-users", map[string]any{
+		"max_messages_per_hour": 10001,
+	})
+	r = withChiURLParam(r, "tenantID", validID)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestSMTPRelayUserUpdate_MissingRateLimit(t *testing.T) {
+	h := newSMTPRelayUserHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/smtp-relay-users/"+validID, map[string]any{})
+	r = withChiURLParam(r, "id", validID)
+
+	h.Update(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestSMTPRelayUserUpdate_InvalidJSON(t *testing.T) {
+	h := newSMTPRelayUserHandler()
+	rec := httptest.NewRecorder()
+	r := newRequestRaw(http.MethodPut, "/smtp-relay-users/"+validID, "{bad json")
+	r = withChiURLParam(r, "id", validID)
+
+	h.Update(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "invalid JSON")
+}
+
+func TestSMTPRelayUserDelete_EmptyID(t *testing.T) {
+	h := newSMTPRelayUserHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/smtp-relay-users/", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Delete(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/go-chi/chi/v5"
+)
+
+// StatusReset serves the admin-only endpoints that recover resources stuck
+// in a transitional status.
+type StatusReset struct {
+	svc *core.StatusResetService
+}
+
+func NewStatusReset(svc *core.StatusResetService) *StatusReset {
+	return &StatusReset{svc: svc}
+}
+
+// Tenant godoc
+//
+//	@Summary		Reset a stuck tenant status
+//	@Description	Moves a tenant stuck in pending, provisioning, converging or deleting to failed so it can be retried. Refused with 409 while a workflow that may still update the tenant is running. Platform admin only.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		200 {object} model.StatusReset
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/reset-status [post]
+func (h *StatusReset) Tenant(w http.ResponseWriter, r *http.Request) {
+	h.reset(w, r, "tenants")
+}
+
+// Webroot godoc
+//
+//	@Summary		Reset a stuck webroot status
+//	@Description	Moves a webroot stuck in pending, provisioning, converging or deleting to failed so it can be retried. Refused with 409 while a workflow that may still update the webroot is running. Platform admin only.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Webroot ID"
+//	@Success		200 {object} model.StatusReset
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/webroots/{id}/reset-status [post]
+func (h *StatusReset) Webroot(w http.ResponseWriter, r *http.Request) {
+	h.reset(w, r, "webroots")
+}
+
+// Database godoc
+//
+//	@Summary		Reset a stuck database status
+//	@Description	Moves a database stuck in pending, provisioning, converging or deleting to failed so it can be retried. Refused with 409 while a workflow that may still update the database is running. Platform admin only.
+//	@Tags			Databases
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Database ID"
+//	@Success		200 {object} model.StatusReset
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/databases/{id}/reset-status [post]
+func (h *StatusReset) Database(w http.ResponseWriter, r *http.Request) {
+	h.reset(w, r, "databases")
+}
+
+// Certificate godoc
+//
+//	@Summary		Reset a stuck certificate status
+//	@Description	Moves a certificate stuck in pending, provisioning, converging or deleting to failed so it can be retried. Refused with 409 while a workflow that may still update the certificate is running. Platform admin only.
+//	@Tags			Certificates
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Certificate ID"
+//	@Success		200 {object} model.StatusReset
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/certificates/{id}/reset-status [post]
+func (h *StatusReset) Certificate(w http.ResponseWriter, r *http.Request) {
+	h.reset(w, r, "certificates")
+}
+
+func (h *StatusReset) reset(w http.ResponseWriter, r *http.Request, resourceType string) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	reset, err := h.svc.Reset(r.Context(), resourceType, id)
+	if err != nil {
+		if errors.Is(err, core.ErrWorkflowRunning) || errors.Is(err, core.ErrStatusNotStuck) {
+			response.WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, reset)
+}
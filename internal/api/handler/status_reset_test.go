@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusResetTenant_EmptyID(t *testing.T) {
+	h := NewStatusReset(nil)
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants//reset-status", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Tenant(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestStatusResetCertificate_EmptyID(t *testing.T) {
+	h := NewStatusReset(nil)
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/certificates//reset-status", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Certificate(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -2,10 +2,12 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/edvin/hosting/internal/agent/runtime"
 	mw "github.com/edvin/hosting/internal/api/middleware"
 	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/api/response"
@@ -24,24 +26,25 @@ func NewTenant(services *core.Services) *Tenant {
 	return &Tenant{svc: services.Tenant, services: services}
 }
 
-// checkTenantBrandAccess fetches a tenant and verifies brand access for the caller.
+// checkTenantBrandAccess fetches a tenant and verifies brand and reseller access for the caller.
 func (h *Tenant) checkTenantBrandAccess(w http.ResponseWriter, r *http.Request, id string) bool {
 	tenant, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
 		return false
 	}
-	if !mw.HasBrandAccess(mw.GetIdentity(r.Context()), tenant.BrandID) {
-		response.WriteError(w, http.StatusForbidden, "no access to this brand")
+	if !mw.HasTenantAccess(mw.GetIdentity(r.Context()), tenant.BrandID, tenant.ResellerID) {
+		response.WriteError(w, http.StatusForbidden, "no access to this tenant")
 		return false
 	}
+	mw.AuditTenant(r.Context(), tenant.ID)
 	return true
 }
 
 // List godoc
 //
 //	@Summary		List tenants
-//	@Description	Returns a paginated list of tenants with optional search, status filtering, and sorting. Includes computed region, cluster, and shard names.
+//	@Description	Returns a paginated list of tenants with optional search, status filtering, and sorting. Includes computed region, cluster, and shard names. Reseller-scoped keys only see their reseller's tenants.
 //	@Tags			Tenants
 //	@Security		ApiKeyAuth
 //	@Param			search query string false "Search query"
@@ -58,6 +61,7 @@ func (h *Tenant) List(w http.ResponseWriter, r *http.Request) {
 	params := request.ParseListParams(r, "created_at")
 	params.BrandIDs = mw.BrandIDs(r.Context())
 	params.CustomerID = r.URL.Query().Get("customer_id")
+	params.ResellerID = mw.ResellerID(r.Context())
 
 	tenants, hasMore, err := h.svc.List(r.Context(), params)
 	if err != nil {
@@ -75,7 +79,7 @@ func (h *Tenant) List(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create a tenant
-//	@Description	Creates a new tenant with a generated short ID and UID. Supports nested creation of zones, webroots, databases, valkey instances, S3 buckets, and SSH keys in one request. Validates that the target cluster is in the brand's allowed cluster list. Async — returns 202 and triggers Temporal provisioning workflows for each resource.
+//	@Description	Creates a new tenant with a generated short ID and UID. Tenants created with a reseller-scoped key are assigned to that reseller. Supports nested creation of zones, webroots, databases, valkey instances, S3 buckets, and SSH keys in one request. Validates that the target cluster is in the brand's allowed cluster list. Async — returns 202 and triggers Temporal provisioning workflows for each resource.
 //	@Tags			Tenants
 //	@Security		ApiKeyAuth
 //	@Param			body body request.CreateTenant true "Tenant details"
@@ -115,6 +119,42 @@ func (h *Tenant) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	for _, wr := range req.Webroots {
+		if err := runtime.ValidateErrorPages(wr.ErrorPages, wr.PublicFolder); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	for i, dr := range req.Databases {
+		charset, collation, err := model.ResolveDatabaseCharset(dr.Charset, dr.Collation)
+		if err != nil {
+			response.WriteError(w, http.StatusBadRequest, fmt.Sprintf("databases[%d]: %s", i, err.Error()))
+			return
+		}
+		req.Databases[i].Charset, req.Databases[i].Collation = charset, collation
+	}
+	var passwords []string
+	for _, dr := range req.Databases {
+		for _, ur := range dr.Users {
+			passwords = append(passwords, ur.Password)
+		}
+	}
+	for _, vr := range req.ValkeyInstances {
+		for _, ur := range vr.Users {
+			passwords = append(passwords, ur.Password)
+		}
+	}
+	if len(passwords) > 0 {
+		policy, err := h.services.Brand.PasswordPolicy(r.Context(), req.BrandID)
+		if err != nil {
+			response.WriteServiceError(w, err)
+			return
+		}
+		if !checkPasswords(w, policy, passwords...) {
+			return
+		}
+	}
+
 	var tenant *model.Tenant
 	err = h.services.WithTx(r.Context(), func(tx *core.Services) error {
 		skipCtx := core.WithSkipWorkflow(r.Context())
@@ -132,6 +172,9 @@ func (h *Tenant) Create(w http.ResponseWriter, r *http.Request) {
 			CreatedAt: now,
 			UpdatedAt: now,
 		}
+		if resellerID := mw.ResellerID(r.Context()); resellerID != "" {
+			tenant.ResellerID = &resellerID
+		}
 		if req.SFTPEnabled != nil {
 			tenant.SFTPEnabled = *req.SFTPEnabled
 		}
@@ -193,6 +236,10 @@ func (h *Tenant) Create(w http.ResponseWriter, r *http.Request) {
 			if wr.ServiceHostnameEnabled != nil {
 				serviceHostnameEnabled = *wr.ServiceHostnameEnabled
 			}
+			var accessLogEnabled bool
+			if wr.AccessLogEnabled != nil {
+				accessLogEnabled = *wr.AccessLogEnabled
+			}
 			webroot := &model.Webroot{
 				ID:                     platform.NewName("w"),
 				TenantID:               tenant.ID,
@@ -201,8 +248,10 @@ func (h *Tenant) Create(w http.ResponseWriter, r *http.Request) {
 				RuntimeVersion:         wr.RuntimeVersion,
 				RuntimeConfig:          runtimeConfig,
 				PublicFolder:           wr.PublicFolder,
+				ErrorPages:             wr.ErrorPages,
 				EnvFileName:            wr.EnvFileName,
 				ServiceHostnameEnabled: serviceHostnameEnabled,
+				AccessLogEnabled:       accessLogEnabled,
 				Status:                 model.StatusPending,
 				CreatedAt:              now2,
 				UpdatedAt:              now2,
@@ -264,6 +313,7 @@ func (h *Tenant) Create(w http.ResponseWriter, r *http.Request) {
 					Enabled:          false,
 					TimeoutSeconds:   3600,
 					MaxMemoryMB:      512,
+					Timezone:         cronTimezone(cr.Timezone),
 					Status:           model.StatusPending,
 					CreatedAt:        now3,
 					UpdatedAt:        now3,
@@ -283,6 +333,8 @@ func (h *Tenant) Create(w http.ResponseWriter, r *http.Request) {
 				TenantID:       tenant.ID,
 				SubscriptionID: dr.SubscriptionID,
 				ShardID:        &dbShardID,
+				Charset:        dr.Charset,
+				Collation:      dr.Collation,
 				Status:    model.StatusPending,
 				CreatedAt: now2,
 				UpdatedAt: now2,
@@ -315,16 +367,24 @@ func (h *Tenant) Create(w http.ResponseWriter, r *http.Request) {
 			if maxMemoryMB == 0 {
 				maxMemoryMB = 64
 			}
-			valkeyPassword := generatePassword()
+			persistenceMode := vr.PersistenceMode
+			if persistenceMode == "" {
+				persistenceMode = model.ValkeyPersistenceRDB
+			}
+			valkeyPassword, err := generatePassword()
+			if err != nil {
+				return err
+			}
 			instance := &model.ValkeyInstance{
-				ID:             platform.NewName("kv"),
-				TenantID:       tenant.ID,
-				SubscriptionID: vr.SubscriptionID,
-				ShardID:        &vShardID,
-				MaxMemoryMB:    maxMemoryMB,
-				Status:         model.StatusPending,
-				CreatedAt:      now2,
-				UpdatedAt:      now2,
+				ID:              platform.NewName("kv"),
+				TenantID:        tenant.ID,
+				SubscriptionID:  vr.SubscriptionID,
+				ShardID:         &vShardID,
+				MaxMemoryMB:     maxMemoryMB,
+				PersistenceMode: persistenceMode,
+				Status:          model.StatusPending,
+				CreatedAt:       now2,
+				UpdatedAt:       now2,
 			}
 			if err := tx.ValkeyInstance.Create(skipCtx, instance, valkeyPassword); err != nil {
 				return fmt.Errorf("create valkey instance: %w", err)
@@ -395,16 +455,29 @@ func (h *Tenant) Create(w http.ResponseWriter, r *http.Request) {
 		for _, fr := range req.FQDNs {
 			now2 := time.Now()
 			fqdn := &model.FQDN{
-				ID:        platform.NewID(),
-				TenantID:  tenant.ID,
-				FQDN:      fr.FQDN,
-				Status:    model.StatusPending,
-				CreatedAt: now2,
-				UpdatedAt: now2,
+				ID:                   platform.NewID(),
+				TenantID:             tenant.ID,
+				FQDN:                 fr.FQDN,
+				ForceHTTPS:           true,
+				RedirectCode:         301,
+				RedirectPreservePath: true,
+				IPMode:               model.IPModeDualStack,
+				Status:               model.StatusPending,
+				CreatedAt:            now2,
+				UpdatedAt:            now2,
 			}
 			if fr.SSLEnabled != nil {
 				fqdn.SSLEnabled = *fr.SSLEnabled
 			}
+			if fr.ForceHTTPS != nil {
+				fqdn.ForceHTTPS = *fr.ForceHTTPS
+			}
+			if fr.IPMode != nil {
+				fqdn.IPMode = *fr.IPMode
+			}
+			if err := setFQDNRedirect(fqdn, fr.RedirectTarget, fr.RedirectCode, fr.RedirectPreservePath); err != nil {
+				return fmt.Errorf("create fqdn %s: %w", fr.FQDN, err)
+			}
 			if err := tx.FQDN.Create(skipCtx, fqdn); err != nil {
 				return fmt.Errorf("create fqdn %s: %w", fr.FQDN, err)
 			}
@@ -452,10 +525,13 @@ func (h *Tenant) Create(w http.ResponseWriter, r *http.Request) {
 		return nil
 	})
 	if err != nil {
-		response.WriteServiceError(w, err)
+		// Nested egress rules may be rejected by validation.
+		writeEgressRuleError(w, err)
 		return
 	}
 
+	mw.AuditTenant(r.Context(), tenant.ID)
+
 	// Commit succeeded — signal per-tenant entity workflow
 	_ = h.services.SignalProvision(r.Context(), tenant.ID, model.ProvisionTask{
 		WorkflowName: "CreateTenantWorkflow",
@@ -466,15 +542,38 @@ func (h *Tenant) Create(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusAccepted, tenant)
 }
 
+// tenantIncludes maps the ?include= expansions of GET /tenants/{id} to the
+// scope resource each requires.
+var tenantIncludes = map[string]string{
+	"subscriptions": "subscriptions",
+	"webroots":      "webroots",
+	"fqdns":         "fqdns",
+	"databases":     "databases",
+}
+
+// tenantWithIncludes is a tenant with the related resources requested with
+// ?include=. Each list holds the first request.MaxLimit items; the includes
+// with more are named in truncated_includes.
+type tenantWithIncludes struct {
+	*model.Tenant
+	Subscriptions     *[]model.Subscription `json:"subscriptions,omitempty"`
+	Webroots          *[]model.Webroot      `json:"webroots,omitempty"`
+	FQDNs             *[]model.FQDN         `json:"fqdns,omitempty"`
+	Databases         *[]model.Database     `json:"databases,omitempty"`
+	TruncatedIncludes []string              `json:"truncated_includes,omitempty"`
+}
+
 // Get godoc
 //
 //	@Summary		Get a tenant
-//	@Description	Returns a single tenant by ID, including computed region, cluster, and shard names.
+//	@Description	Returns a single tenant by ID, including computed region, cluster, and shard names. include expands related resources inline, saving a request per list: subscriptions, webroots, fqdns and databases, comma-separated. Each expansion requires read scope for its resource and returns at most 200 items; expansions with more are named in truncated_includes and can be paged through their list endpoints. 400 for any other include.
 //	@Tags			Tenants
 //	@Security		ApiKeyAuth
 //	@Param			id path string true "Tenant ID"
-//	@Success		200 {object} model.Tenant
+//	@Param			include query string false "Related resources to expand: subscriptions, webroots, fqdns, databases"
+//	@Success		200 {object} tenantWithIncludes
 //	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
 //	@Failure		404 {object} response.ErrorResponse
 //	@Router			/tenants/{id} [get]
 func (h *Tenant) Get(w http.ResponseWriter, r *http.Request) {
@@ -484,18 +583,63 @@ func (h *Tenant) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	includes, ok := parseIncludes(w, r, tenantIncludes)
+	if !ok {
+		return
+	}
+
 	tenant, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
-	if !mw.HasBrandAccess(mw.GetIdentity(r.Context()), tenant.BrandID) {
-		response.WriteError(w, http.StatusForbidden, "no access to this brand")
+	if !mw.HasTenantAccess(mw.GetIdentity(r.Context()), tenant.BrandID, tenant.ResellerID) {
+		response.WriteError(w, http.StatusForbidden, "no access to this tenant")
 		return
 	}
+	mw.AuditTenant(r.Context(), tenant.ID)
 
-	response.WriteJSON(w, http.StatusOK, tenant)
+	if len(includes) == 0 {
+		response.WriteJSON(w, http.StatusOK, tenant)
+		return
+	}
+
+	resp := tenantWithIncludes{Tenant: tenant}
+	for _, include := range includes {
+		switch include {
+		case "subscriptions":
+			subs, hasMore, err := h.services.Subscription.ListByTenant(r.Context(), tenant.ID, request.MaxLimit, "")
+			if err != nil {
+				response.WriteServiceError(w, err)
+				return
+			}
+			resp.Subscriptions = includedList(subs, hasMore, include, &resp.TruncatedIncludes)
+		case "webroots":
+			webroots, hasMore, err := h.services.Webroot.ListByTenant(r.Context(), tenant.ID, request.MaxLimit, "")
+			if err != nil {
+				response.WriteServiceError(w, err)
+				return
+			}
+			resp.Webroots = includedList(webroots, hasMore, include, &resp.TruncatedIncludes)
+		case "fqdns":
+			fqdns, hasMore, err := h.services.FQDN.ListByTenant(r.Context(), tenant.ID, request.MaxLimit, "")
+			if err != nil {
+				response.WriteServiceError(w, err)
+				return
+			}
+			resp.FQDNs = includedList(fqdns, hasMore, include, &resp.TruncatedIncludes)
+		case "databases":
+			databases, hasMore, err := h.services.Database.ListByTenant(r.Context(), tenant.ID, request.ListParams{Limit: request.MaxLimit, Sort: "created_at"})
+			if err != nil {
+				response.WriteServiceError(w, err)
+				return
+			}
+			resp.Databases = includedList(databases, hasMore, include, &resp.TruncatedIncludes)
+		}
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
 }
 
 // Update godoc
@@ -530,8 +674,14 @@ func (h *Tenant) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !mw.HasBrandAccess(mw.GetIdentity(r.Context()), tenant.BrandID) {
-		response.WriteError(w, http.StatusForbidden, "no access to this brand")
+	if !mw.HasTenantAccess(mw.GetIdentity(r.Context()), tenant.BrandID, tenant.ResellerID) {
+		response.WriteError(w, http.StatusForbidden, "no access to this tenant")
+		return
+	}
+	mw.AuditTenant(r.Context(), tenant.ID)
+
+	if tenant.Status == model.StatusMigrating {
+		response.WriteError(w, http.StatusConflict, core.ErrTenantMigrating.Error())
 		return
 	}
 
@@ -574,7 +724,7 @@ func (h *Tenant) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.checkTenantBrandAccess(w, r, id) {
+	if !checkTenantMutable(w, r, h.svc, id) {
 		return
 	}
 
@@ -617,7 +767,7 @@ func (h *Tenant) Suspend(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.checkTenantBrandAccess(w, r, id) {
+	if !checkTenantMutable(w, r, h.svc, id) {
 		return
 	}
 
@@ -647,7 +797,7 @@ func (h *Tenant) Unsuspend(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.checkTenantBrandAccess(w, r, id) {
+	if !checkTenantMutable(w, r, h.svc, id) {
 		return
 	}
 
@@ -724,16 +874,59 @@ func (h *Tenant) ResourceUsage(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusOK, map[string]interface{}{"items": usages})
 }
 
+// Events godoc
+//
+//	@Summary		List a tenant's events
+//	@Description	Returns the tenant's activity feed, newest first: resources created, updated or deleted, certificate renewals requested, backups started, suspensions and similar actions, each with a type (e.g. webroot.created), the resource affected and a short summary. The feed is derived from the audit log and only includes successful requests; retries, status resets, platform-internal actions and request details are left out, and moves of the tenant's resources between servers appear as maintenance. Filter with type, either an event type or a resource type (webroot).
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Param			type query string false "Event type or resource type"
+//	@Param			limit query int false "Page size" default(50)
+//	@Param			cursor query string false "Pagination cursor"
+//	@Success		200 {object} response.PaginatedResponse{items=[]model.TenantEvent}
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/events [get]
+func (h *Tenant) Events(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.checkTenantBrandAccess(w, r, id) {
+		return
+	}
+
+	pg := request.ParsePagination(r)
+
+	events, hasMore, err := h.services.TenantEvent.List(r.Context(), id, r.URL.Query().Get("type"), pg.Limit, pg.Cursor)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	var nextCursor string
+	if hasMore && len(events) > 0 {
+		nextCursor = events[len(events)-1].ID
+	}
+	response.WritePaginated(w, http.StatusOK, events, nextCursor, hasMore)
+}
+
 // Migrate godoc
 //
 //	@Summary		Migrate a tenant to another shard
-//	@Description	Moves a tenant to a different web shard. Optionally migrates associated zones and FQDNs to the target shard. Async — returns 202 and triggers a multi-step Temporal migration workflow.
+//	@Description	Moves a tenant to a different web shard. Optionally migrates associated zones and FQDNs to the target shard. Async — returns 202 and triggers a multi-step Temporal migration workflow. The tenant is `migrating` until it finishes, and changes to it, its webroots, and its FQDNs are rejected with 409 meanwhile; progress is at `/tenants/{id}/migration-status`.
 //	@Tags			Tenants
 //	@Security		ApiKeyAuth
 //	@Param			id path string true "Tenant ID"
 //	@Param			body body request.MigrateTenant true "Migration details"
 //	@Success		202
 //	@Failure		400 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
 //	@Failure		500 {object} response.ErrorResponse
 //	@Router			/tenants/{id}/migrate [post]
 func (h *Tenant) Migrate(w http.ResponseWriter, r *http.Request) {
@@ -743,7 +936,7 @@ func (h *Tenant) Migrate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !h.checkTenantBrandAccess(w, r, id) {
+	if !checkTenantMutable(w, r, h.svc, id) {
 		return
 	}
 
@@ -754,6 +947,235 @@ func (h *Tenant) Migrate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.Migrate(r.Context(), id, req.TargetShardID, req.MigrateZones, req.MigrateFQDNs); err != nil {
+		if errors.Is(err, core.ErrTenantMigrating) {
+			response.WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// MigrationStatus godoc
+//
+//	@Summary		Get tenant migration progress
+//	@Description	Returns the progress of the tenant's latest shard migration: the current phase (snapshot, transfer, cutover, cleanup), per-resource steps, and on failure the failed phase and whether the tenant is still served from the source shard, already from the target shard, or split between them. Read from the running or finished migration workflow; returns 404 if the tenant has no migration on record.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		200 {object} model.TenantMigration
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/migration-status [get]
+func (h *Tenant) MigrationStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.checkTenantBrandAccess(w, r, id) {
+		return
+	}
+
+	migration, err := h.svc.MigrationStatus(r.Context(), id)
+	if errors.Is(err, core.ErrNoMigration) {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, migration)
+}
+
+// ReassignBrand godoc
+//
+//	@Summary		Move a tenant to another brand
+//	@Description	Platform admin only. Moves a tenant and its zones to another brand in the same cluster and reconfigures what derives from the brand: zone SOA and NS records, Stalwart domains and mail DNS records (MX, SPF, DKIM, DMARC), service hostnames if the base hostname changes, and Let's Encrypt certificates if the base hostname or ACME CA changes. Returns 400 if the brand is not active or does not serve the tenant's cluster — tenants cannot change cluster. reseller_id must be a reseller of the target brand; if omitted, the tenant keeps its reseller only if it belongs to the target brand. Async — returns 202; per-resource results are at `/tenants/{id}/brand-reassignment`.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Param			body body request.ReassignTenantBrand true "Target brand"
+//	@Success		202
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/reassign-brand [post]
+func (h *Tenant) ReassignBrand(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.svc, id) {
+		return
+	}
+
+	var req request.ReassignTenantBrand
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.ReassignBrand(r.Context(), id, req.BrandID, req.ResellerID); err != nil {
+		if errors.Is(err, core.ErrInvalidBrandReassignment) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// FixPermissions godoc
+//
+//	@Summary		Reconcile a tenant's file ownership and modes
+//	@Description	Platform admin only. Walks the tenant's storage tree on its shard and sets every file and directory under home, webroots and tmp back to the tenant's user and group, clearing setuid/setgid and other-write bits. Nothing outside the tenant's storage root is touched and symlinks are not followed; hard-linked files owned by someone else are skipped. A run is bounded in time and number of changes: if truncated is set, call the endpoint again to continue. Synchronous — returns the number of entries scanned, changed and skipped. Returns 409 while the tenant is migrating.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		200 {object} model.TenantPermissionsFix
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/fix-permissions [post]
+func (h *Tenant) FixPermissions(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.svc, id) {
+		return
+	}
+
+	fix, err := h.svc.FixPermissions(r.Context(), id)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, fix)
+}
+
+// BrandReassignmentStatus godoc
+//
+//	@Summary		Get tenant brand reassignment results
+//	@Description	Returns the result of the tenant's latest move to another brand: the source and target brand and, per reconfigured resource (tenant, zone, email domain, service hostname, certificate), whether it succeeded. The tenant keeps the target brand even if some steps failed; reassigning it again retries them. Returns 404 if the tenant has no brand reassignment on record.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		200 {object} model.TenantBrandReassignment
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/brand-reassignment [get]
+func (h *Tenant) BrandReassignmentStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.checkTenantBrandAccess(w, r, id) {
+		return
+	}
+
+	reassignment, err := h.svc.BrandReassignmentStatus(r.Context(), id)
+	if errors.Is(err, core.ErrNoBrandReassignment) {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, reassignment)
+}
+
+// GetLBSplit godoc
+//
+//	@Summary		Get tenant traffic split
+//	@Description	Returns the tenant's traffic split: the percentage of requests to its FQDNs that the load balancers send to another web shard instead of the tenant's own. Returns 404 if all traffic goes to the tenant's shard.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		200 {object} model.TenantLBSplit
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/lb-split [get]
+func (h *Tenant) GetLBSplit(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.checkTenantBrandAccess(w, r, id) {
+		return
+	}
+
+	split, err := h.svc.GetLBSplit(r.Context(), id)
+	if errors.Is(err, core.ErrNoLBSplit) {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, split)
+}
+
+// SetLBSplit godoc
+//
+//	@Summary		Set tenant traffic split
+//	@Description	Sends weight percent (1-100) of requests to the tenant's FQDNs to the LB backend of another web shard in the same cluster, e.g. to shift traffic gradually before a migration. The rest goes to the tenant's own shard. Async — returns 202 and updates the load balancers via a Temporal workflow. Returns 400 if the shard is the tenant's own, not a web shard, or in another cluster.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Param			body body request.SetTenantLBSplit true "Split shard and weight"
+//	@Success		202
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/lb-split [put]
+func (h *Tenant) SetLBSplit(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.svc, id) {
+		return
+	}
+
+	var req request.SetTenantLBSplit
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.svc.SetLBSplit(r.Context(), id, req.ShardID, req.Weight); err != nil {
+		if errors.Is(err, core.ErrInvalidLBSplitShard) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		response.WriteServiceError(w, err)
 		return
 	}
@@ -761,6 +1183,238 @@ func (h *Tenant) Migrate(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// DeleteLBSplit godoc
+//
+//	@Summary		Remove tenant traffic split
+//	@Description	Sends all requests to the tenant's FQDNs back to its own shard. Async — returns 202 and updates the load balancers via a Temporal workflow. Returns 404 if the tenant has no split.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		202
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/lb-split [delete]
+func (h *Tenant) DeleteLBSplit(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.svc, id) {
+		return
+	}
+
+	if err := h.svc.DeleteLBSplit(r.Context(), id); err != nil {
+		if errors.Is(err, core.ErrNoLBSplit) {
+			response.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// GetMaintenanceWindow godoc
+//
+//	@Summary		Get tenant maintenance window
+//	@Description	Returns the tenant's daily maintenance window: the local start time, duration and IANA timezone during which disruptive automatic operations such as certificate renewals are scheduled. Returns 404 if the tenant follows the platform default schedule.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		200 {object} model.TenantMaintenanceWindow
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/maintenance-window [get]
+func (h *Tenant) GetMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.checkTenantBrandAccess(w, r, id) {
+		return
+	}
+
+	mw, err := h.svc.GetMaintenanceWindow(r.Context(), id)
+	if errors.Is(err, core.ErrNoMaintenanceWindow) {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, mw)
+}
+
+// SetMaintenanceWindow godoc
+//
+//	@Summary		Set tenant maintenance window
+//	@Description	Sets a daily window (start_time "HH:MM" in an IANA timezone, 60-720 minutes long) during which certificate renewals and other disruptive automatic operations are scheduled for the tenant. Non-urgent work outside the window is deferred; certificates within 7 days of expiry are renewed regardless. Replaces any existing window.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Param			body body request.SetTenantMaintenanceWindow true "Window start, duration and timezone"
+//	@Success		200 {object} model.TenantMaintenanceWindow
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/maintenance-window [put]
+func (h *Tenant) SetMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.svc, id) {
+		return
+	}
+
+	var req request.SetTenantMaintenanceWindow
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mw := &model.TenantMaintenanceWindow{
+		TenantID:        id,
+		StartTime:       req.StartTime,
+		DurationMinutes: req.DurationMinutes,
+		Timezone:        req.Timezone,
+	}
+	if err := h.svc.SetMaintenanceWindow(r.Context(), mw); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, mw)
+}
+
+// DeleteMaintenanceWindow godoc
+//
+//	@Summary		Remove tenant maintenance window
+//	@Description	Returns the tenant to the platform default schedule for automatic operations. Returns 404 if the tenant has no window.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		204
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/maintenance-window [delete]
+func (h *Tenant) DeleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.svc, id) {
+		return
+	}
+
+	if err := h.svc.DeleteMaintenanceWindow(r.Context(), id); err != nil {
+		if errors.Is(err, core.ErrNoMaintenanceWindow) {
+			response.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetBackupSchedule godoc
+//
+//	@Summary		Get tenant backup schedule
+//	@Description	Returns the tenant's automatic backup schedule: how often all of its webroots and databases are backed up, how long those backups are kept, and when the schedule last ran. Returns 404 if the tenant is only backed up on request.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Success		200 {object} model.BackupSchedule
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/backup-schedule [get]
+func (h *Tenant) GetBackupSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.checkTenantBrandAccess(w, r, id) {
+		return
+	}
+
+	bs, err := h.svc.GetBackupSchedule(r.Context(), id)
+	if errors.Is(err, core.ErrNoBackupSchedule) {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, bs)
+}
+
+// SetBackupSchedule godoc
+//
+//	@Summary		Set tenant backup schedule
+//	@Description	Backs up all of the tenant's webroots and databases daily or weekly. Runs are staggered across tenants and fall inside the tenant's maintenance window if it has one. retention_days (1-365) replaces the platform backup retention for the tenant's backups while the schedule is enabled. Set enabled to false to pause the schedule. Replaces any existing schedule.
+//	@Tags			Tenants
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Tenant ID"
+//	@Param			body body request.SetBackupSchedule true "Frequency, retention and enabled flag"
+//	@Success		200 {object} model.BackupSchedule
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{id}/backup-schedule [put]
+func (h *Tenant) SetBackupSchedule(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.svc, id) {
+		return
+	}
+
+	var req request.SetBackupSchedule
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bs := &model.BackupSchedule{
+		TenantID:      id,
+		Frequency:     req.Frequency,
+		RetentionDays: req.RetentionDays,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+	}
+	if err := h.svc.SetBackupSchedule(r.Context(), bs); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, bs)
+}
+
 // Retry godoc
 //
 //	@Summary		Retry a failed tenant
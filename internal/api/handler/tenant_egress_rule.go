@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -63,13 +64,14 @@ func (h *TenantEgressRule) ListByTenant(w http.ResponseWriter, r *http.Request)
 // Create godoc
 //
 //	@Summary		Create an egress rule
-//	@Description	Adds a network egress rule for a tenant. Rules control which destination CIDRs the tenant's processes can reach. Async — returns 202 and triggers a workflow to sync nftables rules on all shard nodes.
+//	@Description	Adds a network egress rule for a tenant. Rules control which destination CIDRs the tenant's processes can reach. The CIDR is stored with its host bits cleared. Returns 400 if it overlaps a range blocked by the platform (EGRESS_CIDR_BLOCKLIST) and 409 if it overlaps another of the tenant's rules. Async — returns 202 and triggers a workflow to sync nftables rules on all shard nodes.
 //	@Tags			Tenant Egress Rules
 //	@Security		ApiKeyAuth
 //	@Param			tenantID path string true "Tenant ID"
 //	@Param			body body request.CreateTenantEgressRule true "Egress rule details"
 //	@Success		202 {object} model.TenantEgressRule
 //	@Failure		400 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
 //	@Failure		500 {object} response.ErrorResponse
 //	@Router			/tenants/{tenantID}/egress-rules [post]
 func (h *TenantEgressRule) Create(w http.ResponseWriter, r *http.Request) {
@@ -101,7 +103,7 @@ func (h *TenantEgressRule) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.svc.Create(r.Context(), rule); err != nil {
-		response.WriteServiceError(w, err)
+		writeEgressRuleError(w, err)
 		return
 	}
 
@@ -205,3 +207,15 @@ func (h *TenantEgressRule) Retry(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// writeEgressRuleError maps egress rule validation errors to 400 and 409.
+func writeEgressRuleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, core.ErrInvalidEgressCIDR):
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, core.ErrEgressRuleOverlap):
+		response.WriteError(w, http.StatusConflict, err.Error())
+	default:
+		response.WriteServiceError(w, err)
+	}
+}
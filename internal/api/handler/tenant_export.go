@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+)
+
+type TenantExport struct {
+	svc       *core.TenantExportService
+	tenantSvc *core.TenantService
+}
+
+func NewTenantExport(svc *core.TenantExportService, tenantSvc *core.TenantService) *TenantExport {
+	return &TenantExport{svc: svc, tenantSvc: tenantSvc}
+}
+
+// Get godoc
+//
+//	@Summary		Get the latest tenant export
+//	@Description	Returns the tenant's most recent data export. Once the export is active, download_url is a signed URL to the archive (webroot files, database dumps, Valkey data and a manifest.json of the tenant's configuration), valid for one hour or until the export expires.
+//	@Tags			Backups
+//	@Security		ApiKeyAuth
+//	@Param			tenantID path string true "Tenant ID"
+//	@Success		200 {object} model.TenantExport
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{tenantID}/export [get]
+func (h *TenantExport) Get(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := request.RequireID(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.tenantSvc, tenantID) {
+		return
+	}
+
+	export, err := h.svc.GetLatest(r.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			response.WriteError(w, http.StatusNotFound, "tenant has no export")
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	if err := h.svc.SignDownload(r.Context(), export); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, export)
+}
+
+// Create godoc
+//
+//	@Summary		Export all tenant data
+//	@Description	Starts an account-wide export of the tenant: webroot files, database dumps, Valkey data and a manifest.json of the tenant's configuration, packed into a single archive. Poll GET /tenants/{tenantID}/export for the download URL. Only one export runs at a time. Async (202).
+//	@Tags			Backups
+//	@Security		ApiKeyAuth
+//	@Param			tenantID path string true "Tenant ID"
+//	@Success		202 {object} model.TenantExport
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		503 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{tenantID}/export [post]
+func (h *TenantExport) Create(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := request.RequireID(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !h.svc.Enabled() {
+		response.WriteError(w, http.StatusServiceUnavailable, "tenant exports are not configured")
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.tenantSvc, tenantID) {
+		return
+	}
+
+	export, err := h.svc.Create(r.Context(), tenantID)
+	if err != nil {
+		if errors.Is(err, core.ErrTenantExportInProgress) {
+			response.WriteError(w, http.StatusConflict, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, export)
+}
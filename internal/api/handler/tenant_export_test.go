@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edvin/hosting/internal/core"
+)
+
+func newTenantExportHandler() *TenantExport {
+	return &TenantExport{svc: core.NewTenantExportService(nil, nil, nil, 7), tenantSvc: nil}
+}
+
+func TestTenantExportGet_EmptyTenantID(t *testing.T) {
+	h := newTenantExportHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/tenants//export", nil)
+	r = withChiURLParam(r, "tenantID", "")
+
+	h.Get(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestTenantExportCreate_EmptyTenantID(t *testing.T) {
+	h := newTenantExportHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants//export", nil)
+	r = withChiURLParam(r, "tenantID", "")
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantExportCreate_NotConfigured(t *testing.T) {
+	h := newTenantExportHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants/"+validID+"/export", nil)
+	r = withChiURLParam(r, "tenantID", validID)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "not configured")
+}
@@ -196,6 +196,144 @@ func TestTenantUnsuspend_EmptyID(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
 
+// --- MigrationStatus ---
+
+func TestTenantMigrationStatus_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/tenants//migration-status", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.MigrationStatus(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// --- ReassignBrand ---
+
+func TestTenantReassignBrand_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants//reassign-brand", map[string]any{"brand_id": "brand-2"})
+	r = withChiURLParam(r, "id", "")
+
+	h.ReassignBrand(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// --- FixPermissions ---
+
+func TestTenantFixPermissions_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants//fix-permissions", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.FixPermissions(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantBrandReassignmentStatus_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/tenants//brand-reassignment", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.BrandReassignmentStatus(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantGetLBSplit_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/tenants//lb-split", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.GetLBSplit(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantSetLBSplit_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/tenants//lb-split", map[string]any{"shard_id": "web-2", "weight": 20})
+	r = withChiURLParam(r, "id", "")
+
+	h.SetLBSplit(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantDeleteLBSplit_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/tenants//lb-split", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.DeleteLBSplit(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantGetMaintenanceWindow_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/tenants//maintenance-window", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.GetMaintenanceWindow(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantSetMaintenanceWindow_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/tenants//maintenance-window", map[string]any{"start_time": "03:00", "duration_minutes": 120, "timezone": "Europe/Oslo"})
+	r = withChiURLParam(r, "id", "")
+
+	h.SetMaintenanceWindow(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantDeleteMaintenanceWindow_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/tenants//maintenance-window", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.DeleteMaintenanceWindow(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantGetBackupSchedule_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/tenants//backup-schedule", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.GetBackupSchedule(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestTenantSetBackupSchedule_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/tenants//backup-schedule", map[string]any{"frequency": "daily", "retention_days": 30})
+	r = withChiURLParam(r, "id", "")
+
+	h.SetBackupSchedule(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
 // --- JSON content-type verification ---
 
 func TestTenantCreate_ResponseHasJSONContentType(t *testing.T) {
@@ -760,3 +898,18 @@ func TestTenantCreate_ErrorResponseFormat(t *testing.T) {
 	_, hasError := body["error"]
 	assert.True(t, hasError, "error response should contain 'error' key")
 }
+
+// --- Events ---
+
+func TestTenantEvents_EmptyID(t *testing.T) {
+	h := newTenantHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/tenants//events", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Events(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
@@ -68,7 +68,7 @@ func (h *ValkeyInstance) ListByTenant(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create a Valkey instance
-//	@Description	Asynchronously creates a Valkey instance on the specified shard. Auto-generates a port and password. Optionally creates nested users in the same request. Triggers a Temporal workflow and returns 202 immediately.
+//	@Description	Asynchronously creates a Valkey instance on the specified shard. Auto-generates a port and password. persistence_mode is rdb (default, periodic snapshots), aof (append-only file) or none (in-memory cache). Optionally creates nested users in the same request. Triggers a Temporal workflow and returns 202 immediately.
 //	@Tags			Valkey Instances
 //	@Security		ApiKeyAuth
 //	@Param			tenantID	path		string							true	"Tenant ID"
@@ -93,25 +93,41 @@ func (h *ValkeyInstance) Create(w http.ResponseWriter, r *http.Request) {
 	if !checkTenantBrand(w, r, h.tenantSvc, tenantID) {
 		return
 	}
+	passwords := make([]string, len(req.Users))
+	for i, ur := range req.Users {
+		passwords[i] = ur.Password
+	}
+	if !checkPasswordPolicy(w, r, h.tenantSvc, tenantID, passwords...) {
+		return
+	}
 
 	maxMemoryMB := req.MaxMemoryMB
 	if maxMemoryMB == 0 {
 		maxMemoryMB = 64
 	}
+	persistenceMode := req.PersistenceMode
+	if persistenceMode == "" {
+		persistenceMode = model.ValkeyPersistenceRDB
+	}
 
-	password := generatePassword()
+	password, err := generatePassword()
+	if err != nil {
+		response.WriteError(w, http.StatusInternalServerError, "failed to generate password")
+		return
+	}
 
 	now := time.Now()
 	shardID := req.ShardID
 	instance := &model.ValkeyInstance{
-		ID:             platform.NewName("kv"),
-		TenantID:       tenantID,
-		SubscriptionID: req.SubscriptionID,
-		ShardID:        &shardID,
-		MaxMemoryMB:    maxMemoryMB,
-		Status:         model.StatusPending,
-		CreatedAt:      now,
-		UpdatedAt:      now,
+		ID:              platform.NewName("kv"),
+		TenantID:        tenantID,
+		SubscriptionID:  req.SubscriptionID,
+		ShardID:         &shardID,
+		MaxMemoryMB:     maxMemoryMB,
+		PersistenceMode: persistenceMode,
+		Status:          model.StatusPending,
+		CreatedAt:       now,
+		UpdatedAt:       now,
 	}
 
 	if err := h.svc.Create(r.Context(), instance, password); err != nil {
@@ -186,6 +202,130 @@ func (h *ValkeyInstance) Get(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusOK, instance)
 }
 
+// Update godoc
+//
+//	@Summary		Update a Valkey instance
+//	@Description	Asynchronously changes the persistence mode of a Valkey instance. Running instances are reconfigured in place; when AOF is turned off a fresh RDB snapshot is written first so no data is lost. Triggers a Temporal workflow and returns 202 immediately.
+//	@Tags			Valkey Instances
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string							true	"Valkey instance ID"
+//	@Param			body	body		request.UpdateValkeyInstance	true	"Valkey instance updates"
+//	@Success		202		{object}	model.ValkeyInstance
+//	@Failure		400		{object}	response.ErrorResponse
+//	@Failure		404		{object}	response.ErrorResponse
+//	@Failure		500		{object}	response.ErrorResponse
+//	@Router			/valkey-instances/{id} [put]
+func (h *ValkeyInstance) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.UpdateValkeyInstance
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	instance, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.tenantSvc, instance.TenantID) {
+		return
+	}
+
+	if req.PersistenceMode != "" {
+		instance.PersistenceMode = req.PersistenceMode
+	}
+
+	if err := h.svc.Update(r.Context(), instance); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	instance.PasswordHash = ""
+	response.WriteJSON(w, http.StatusAccepted, instance)
+}
+
+// Stats godoc
+//
+//	@Summary		Get Valkey instance stats
+//	@Description	Returns the most recent memory usage and key count for a Valkey instance. Data is collected periodically by the valkey stats cron workflow; available is false when the instance could not be queried or has not been sampled yet.
+//	@Tags			Valkey Instances
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Valkey instance ID"
+//	@Success		200	{object}	model.ValkeyInstanceStats
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/valkey-instances/{id}/stats [get]
+func (h *ValkeyInstance) Stats(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	instance, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.tenantSvc, instance.TenantID) {
+		return
+	}
+
+	stats, err := h.svc.GetStats(r.Context(), instance)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, stats)
+}
+
+// ConnectionInfo godoc
+//
+//	@Summary		Get Valkey instance connection info
+//	@Description	Returns the instance's address on the tenant's WireGuard network (the tenant's ULA on the shard primary, as forwarded by hosting-cli proxy), the hosting-cli proxy command that forwards it to localhost, and a DSN template for the default user and each Valkey user. Passwords are only stored as hashes and are never returned; DSNs contain a {password} placeholder.
+//	@Tags			Valkey Instances
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Valkey instance ID"
+//	@Success		200	{object}	model.ConnectionInfo
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		403	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/valkey-instances/{id}/connection-info [get]
+func (h *ValkeyInstance) ConnectionInfo(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	instance, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.tenantSvc, instance.TenantID) {
+		return
+	}
+
+	info, err := h.svc.ConnectionInfo(r.Context(), instance)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	response.WriteJSON(w, http.StatusOK, info)
+}
+
 // Delete godoc
 //
 //	@Summary		Delete a Valkey instance
@@ -117,13 +117,14 @@ func TestValkeyInstanceMigrate_Success(t *testing.T) {
 		*(dest[3].(**string)) = nil        // ShardID
 		*(dest[4].(*int)) = 0              // Port
 		*(dest[5].(*int)) = 64             // MaxMemoryMB
-		*(dest[6].(*string)) = ""          // Password
-		*(dest[7].(*string)) = "active"    // Status
-		*(dest[8].(**string)) = nil        // StatusMessage
-		*(dest[9].(*string)) = ""          // SuspendReason
-		*(dest[10].(*time.Time)) = now     // CreatedAt
-		*(dest[11].(*time.Time)) = now     // UpdatedAt
-		*(dest[12].(**string)) = nil       // ShardName
+		*(dest[6].(*string)) = "rdb"       // PersistenceMode
+		*(dest[7].(*string)) = ""          // Password
+		*(dest[8].(*string)) = "active"    // Status
+		*(dest[9].(**string)) = nil        // StatusMessage
+		*(dest[10].(*string)) = ""         // SuspendReason
+		*(dest[11].(*time.Time)) = now     // CreatedAt
+		*(dest[12].(*time.Time)) = now     // UpdatedAt
+		*(dest[13].(**string)) = nil       // ShardName
 		return nil
 	}}
 	db.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(getRow).Once()
@@ -198,3 +199,81 @@ func TestValkeyInstanceMigrate_BadID(t *testing.T) {
 	body := decodeErrorResponse(rec)
 	assert.Contains(t, body["error"], "missing required ID")
 }
+
+func TestValkeyInstanceCreate_InvalidPersistenceMode(t *testing.T) {
+	h := newValkeyInstanceHandler()
+	rec := httptest.NewRecorder()
+	tid := "test-tenant-1"
+	r := newRequest(http.MethodPost, "/tenants/"+tid+"/valkey-instances", map[string]any{
+		"subscription_id":  "sub-1",
+		"shard_id":         "test-shard-1",
+		"persistence_mode": "always",
+	})
+	r = withChiURLParam(r, "tenantID", tid)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "validation error")
+}
+
+// --- Update ---
+
+func TestValkeyInstanceUpdate_BadID(t *testing.T) {
+	h := newValkeyInstanceHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/valkey-instances/", map[string]any{
+		"persistence_mode": "aof",
+	})
+	r = withChiURLParam(r, "id", "")
+
+	h.Update(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestValkeyInstanceUpdate_InvalidPersistenceMode(t *testing.T) {
+	h := newValkeyInstanceHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/valkey-instances/kv-1", map[string]any{
+		"persistence_mode": "always",
+	})
+	r = withChiURLParam(r, "id", "kv-1")
+
+	h.Update(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "validation error")
+}
+
+// --- Stats ---
+
+func TestValkeyInstanceStats_BadID(t *testing.T) {
+	h := newValkeyInstanceHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/valkey-instances//stats", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Stats(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestValkeyInstanceConnectionInfo_BadID(t *testing.T) {
+	h := newValkeyInstanceHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/valkey-instances//connection-info", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.ConnectionInfo(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
@@ -17,10 +17,12 @@ import (
 type ValkeyUser struct {
 	svc         *core.ValkeyUserService
 	instanceSvc *core.ValkeyInstanceService
+	tenantSvc   *core.TenantService
+	ownership   *core.OwnershipService
 }
 
-func NewValkeyUser(svc *core.ValkeyUserService, instanceSvc *core.ValkeyInstanceService) *ValkeyUser {
-	return &ValkeyUser{svc: svc, instanceSvc: instanceSvc}
+func NewValkeyUser(svc *core.ValkeyUserService, instanceSvc *core.ValkeyInstanceService, tenantSvc *core.TenantService, ownership *core.OwnershipService) *ValkeyUser {
+	return &ValkeyUser{svc: svc, instanceSvc: instanceSvc, tenantSvc: tenantSvc, ownership: ownership}
 }
 
 // ListByInstance godoc
@@ -43,6 +45,10 @@ func (h *ValkeyUser) ListByInstance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "valkey_instances", instanceID) {
+		return
+	}
+
 	pg := request.ParsePagination(r)
 
 	users, hasMore, err := h.svc.ListByInstance(r.Context(), instanceID, pg.Limit, pg.Cursor)
@@ -64,7 +70,7 @@ func (h *ValkeyUser) ListByInstance(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create a Valkey user
-//	@Description	Asynchronously creates a Valkey ACL user with username, password, privileges, and key pattern. Key pattern defaults to "~*" (all keys). Triggers a Temporal workflow and returns 202 immediately.
+//	@Description	Asynchronously creates a Valkey ACL user with username, password, privileges, and key pattern. Key pattern defaults to "~*" (all keys). The password must meet the password policy of the tenant's brand. Triggers a Temporal workflow and returns 202 immediately.
 //	@Tags			Valkey Users
 //	@Security		ApiKeyAuth
 //	@Param			instanceID	path		string						true	"Valkey instance ID"
@@ -86,6 +92,10 @@ func (h *ValkeyUser) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "valkey_instances", instanceID) {
+		return
+	}
+
 	// Validate username starts with parent instance name.
 	instance, err := h.instanceSvc.GetByID(r.Context(), instanceID)
 	if err != nil {
@@ -96,6 +106,9 @@ func (h *ValkeyUser) Create(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, fmt.Sprintf("username %q must start with instance name %q", req.Username, instance.ID))
 		return
 	}
+	if !checkPasswordPolicy(w, r, h.tenantSvc, instance.TenantID, req.Password) {
+		return
+	}
 
 	keyPattern := req.KeyPattern
 	if keyPattern == "" {
@@ -141,6 +154,10 @@ func (h *ValkeyUser) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "valkey_users", id) {
+		return
+	}
+
 	user, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -154,7 +171,7 @@ func (h *ValkeyUser) Get(w http.ResponseWriter, r *http.Request) {
 // Update godoc
 //
 //	@Summary		Update a Valkey user
-//	@Description	Asynchronously updates a Valkey user's password, privileges, or key pattern. Triggers a Temporal workflow and returns 202 immediately.
+//	@Description	Asynchronously updates a Valkey user's password, privileges, or key pattern. A new password must meet the password policy of the tenant's brand. Triggers a Temporal workflow and returns 202 immediately.
 //	@Tags			Valkey Users
 //	@Security		ApiKeyAuth
 //	@Param			id		path		string						true	"Valkey user ID"
@@ -177,6 +194,10 @@ func (h *ValkeyUser) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "valkey_users", id) {
+		return
+	}
+
 	user, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -189,6 +210,16 @@ func (h *ValkeyUser) Update(w http.ResponseWriter, r *http.Request) {
 	if req.KeyPattern != "" {
 		user.KeyPattern = req.KeyPattern
 	}
+	if req.Password != "" {
+		instance, err := h.instanceSvc.GetByID(r.Context(), user.ValkeyInstanceID)
+		if err != nil {
+			response.WriteServiceError(w, err)
+			return
+		}
+		if !checkPasswordPolicy(w, r, h.tenantSvc, instance.TenantID, req.Password) {
+			return
+		}
+	}
 
 	if err := h.svc.Update(r.Context(), user, req.Password); err != nil {
 		response.WriteServiceError(w, err)
@@ -217,6 +248,10 @@ func (h *ValkeyUser) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "valkey_users", id) {
+		return
+	}
+
 	if err := h.svc.Delete(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -242,6 +277,9 @@ func (h *ValkeyUser) Retry(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !checkOwnership(w, r, h.ownership, "valkey_users", id) {
+		return
+	}
 	if err := h.svc.Retry(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -2,10 +2,14 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/edvin/hosting/internal/agent/runtime"
+	mw "github.com/edvin/hosting/internal/api/middleware"
 	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/api/response"
 	"github.com/edvin/hosting/internal/core"
@@ -65,13 +69,15 @@ func (h *Webroot) ListByTenant(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create a webroot
-//	@Description	Creates a webroot (website document root) for a tenant. Requires name, runtime (php/node/python/ruby/static), and runtime version. Supports nested FQDN creation. Async — returns 202 and triggers a Temporal workflow.
+//	@Description	Creates a webroot (website document root) for a tenant. Requires name and runtime (php/node/python/ruby/static). Without runtime_version the webroot gets the brand's default version for the runtime (see /brands/{id}/runtime-defaults); returns 400 if the brand has none or it is not installed on the tenant's shard. Supports nested FQDN creation. Async — returns 202 and triggers a Temporal workflow. The response includes a quota_near_limit warning if the tenant's disk usage is near its quota.
+//	@Description	With template_id, the runtime, runtime version, runtime config and public folder default to the app template's (fields given in the request win), the template's env vars are added, and a database is created if the template asks for one. Everything is provisioned by one workflow that deletes all of it again if a step fails. Nested FQDNs cannot be combined with template_id. Returns 404 for an unknown template_id.
 //	@Tags			Webroots
 //	@Security		ApiKeyAuth
 //	@Param			tenantID path string true "Tenant ID"
 //	@Param			body body request.CreateWebroot true "Webroot details"
 //	@Success		202 {object} model.Webroot
 //	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
 //	@Failure		500 {object} response.ErrorResponse
 //	@Router			/tenants/{tenantID}/webroots [post]
 func (h *Webroot) Create(w http.ResponseWriter, r *http.Request) {
@@ -81,102 +87,981 @@ func (h *Webroot) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var req request.CreateWebroot
+	var req request.CreateWebroot
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.TemplateID != "" && len(req.FQDNs) > 0 {
+		response.WriteError(w, http.StatusBadRequest, "fqdns cannot be combined with template_id; add FQDNs once the webroot is active")
+		return
+	}
+
+	if req.TemplateID == "" {
+		if err := runtime.ValidateErrorPages(req.ErrorPages, req.PublicFolder); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if !checkTenantMutable(w, r, h.services.Tenant, tenantID) {
+		return
+	}
+
+	var tmpl *model.AppTemplate
+	if req.TemplateID != "" {
+		tenant, err := h.services.Tenant.GetByID(r.Context(), tenantID)
+		if err != nil {
+			response.WriteServiceError(w, err)
+			return
+		}
+		tmpl, err = h.services.Brand.GetAppTemplate(r.Context(), tenant.BrandID, req.TemplateID)
+		if errors.Is(err, core.ErrAppTemplateNotFound) {
+			response.WriteError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		if err != nil {
+			response.WriteServiceError(w, err)
+			return
+		}
+		applyAppTemplate(&req, tmpl)
+		if err := runtime.ValidateErrorPages(req.ErrorPages, req.PublicFolder); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if req.RuntimeVersion == "" && req.Runtime != model.RuntimeStatic {
+		version, err := h.svc.DefaultRuntimeVersion(r.Context(), tenantID, req.Runtime)
+		if errors.Is(err, core.ErrNoRuntimeDefault) || errors.Is(err, core.ErrRuntimeDefaultUnavailable) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			response.WriteServiceError(w, err)
+			return
+		}
+		req.RuntimeVersion = version
+	}
+
+	now := time.Now()
+	runtimeConfig := req.RuntimeConfig
+	if runtimeConfig == nil {
+		runtimeConfig = json.RawMessage(`{}`)
+	}
+
+	// Validate PHP runtime_config if applicable.
+	if req.Runtime == "php" {
+		if err := runtime.ValidatePHPRuntimeConfig(runtimeConfig); err != nil {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	envFileName := req.EnvFileName
+	if envFileName == "" {
+		envFileName = ".env.hosting"
+	}
+	serviceHostnameEnabled := true
+	if req.ServiceHostnameEnabled != nil {
+		serviceHostnameEnabled = *req.ServiceHostnameEnabled
+	}
+	var accessLogEnabled bool
+	if req.AccessLogEnabled != nil {
+		accessLogEnabled = *req.AccessLogEnabled
+	}
+
+	webroot := &model.Webroot{
+		ID:                     platform.NewName("w"),
+		TenantID:               tenantID,
+		SubscriptionID:         req.SubscriptionID,
+		Runtime:                req.Runtime,
+		RuntimeVersion:         req.RuntimeVersion,
+		RuntimeConfig:          runtimeConfig,
+		PublicFolder:            req.PublicFolder,
+		ErrorPages:              req.ErrorPages,
+		EnvFileName:             envFileName,
+		ServiceHostnameEnabled: serviceHostnameEnabled,
+		AccessLogEnabled:       accessLogEnabled,
+		Status:                 model.StatusPending,
+		CreatedAt:              now,
+		UpdatedAt:              now,
+	}
+
+	if tmpl != nil {
+		if _, err := h.svc.CreateFromTemplate(r.Context(), webroot, tmpl); err != nil {
+			if errors.Is(err, core.ErrNoDatabaseShard) {
+				response.WriteError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			response.WriteServiceError(w, err)
+			return
+		}
+		response.WriteJSONWithWarnings(w, http.StatusAccepted, webroot, h.services.Tenant.QuotaWarnings(r.Context(), tenantID))
+		return
+	}
+
+	if err := h.svc.Create(r.Context(), webroot); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	// Nested FQDN creation
+	if err := createNestedFQDNs(r.Context(), h.services, webroot.ID, tenantID, req.FQDNs); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSONWithWarnings(w, http.StatusAccepted, webroot, h.services.Tenant.QuotaWarnings(r.Context(), tenantID))
+}
+
+// applyAppTemplate fills the runtime settings the request leaves empty from
+// the template.
+func applyAppTemplate(req *request.CreateWebroot, tmpl *model.AppTemplate) {
+	if req.Runtime == "" {
+		req.Runtime = tmpl.Runtime
+	}
+	if req.RuntimeVersion == "" {
+		req.RuntimeVersion = tmpl.RuntimeVersion
+	}
+	if req.RuntimeConfig == nil && len(tmpl.RuntimeConfig) > 0 {
+		req.RuntimeConfig = tmpl.RuntimeConfig
+	}
+	if req.PublicFolder == "" {
+		req.PublicFolder = tmpl.PublicFolder
+	}
+}
+
+// ListTemplates godoc
+//
+//	@Summary		List app templates available to a tenant
+//	@Description	Returns the app templates of the tenant's brand, which can be passed as template_id when creating a webroot.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			tenantID path string true "Tenant ID"
+//	@Success		200 {object} map[string][]model.AppTemplate
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/tenants/{tenantID}/app-templates [get]
+func (h *Webroot) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := request.RequireID(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, tenantID) {
+		return
+	}
+
+	tenant, err := h.services.Tenant.GetByID(r.Context(), tenantID)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	templates, err := h.services.Brand.ListAppTemplates(r.Context(), tenant.BrandID)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+	if templates == nil {
+		templates = []model.AppTemplate{}
+	}
+
+	response.WriteJSON(w, http.StatusOK, map[string][]model.AppTemplate{"templates": templates})
+}
+
+// webrootIncludes maps the ?include= expansions of GET /webroots/{id} to the
+// scope resource each requires.
+var webrootIncludes = map[string]string{
+	"fqdns":    "fqdns",
+	"daemons":  "daemons",
+	"cronjobs": "cron_jobs",
+}
+
+// webrootWithIncludes is a webroot with the related resources requested with
+// ?include=. Each list holds the first request.MaxLimit items; the includes
+// with more are named in truncated_includes.
+type webrootWithIncludes struct {
+	*model.Webroot
+	FQDNs             *[]model.FQDN    `json:"fqdns,omitempty"`
+	Daemons           *[]model.Daemon  `json:"daemons,omitempty"`
+	CronJobs          *[]model.CronJob `json:"cron_jobs,omitempty"`
+	TruncatedIncludes []string         `json:"truncated_includes,omitempty"`
+}
+
+// Get godoc
+//
+//	@Summary		Get a webroot
+//	@Description	Returns a single webroot by ID, including runtime configuration details. include expands related resources inline, saving a request per list: fqdns, daemons and cronjobs (returned as cron_jobs), comma-separated. Each expansion requires read scope for its resource and returns at most 200 items; expansions with more are named in truncated_includes and can be paged through their list endpoints. 400 for any other include.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Webroot ID"
+//	@Param			include query string false "Related resources to expand: fqdns, daemons, cronjobs"
+//	@Success		200 {object} webrootWithIncludes
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Router			/webroots/{id} [get]
+func (h *Webroot) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	includes, ok := parseIncludes(w, r, webrootIncludes)
+	if !ok {
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	if len(includes) == 0 {
+		response.WriteJSON(w, http.StatusOK, webroot)
+		return
+	}
+
+	resp := webrootWithIncludes{Webroot: webroot}
+	for _, include := range includes {
+		switch include {
+		case "fqdns":
+			fqdns, hasMore, err := h.services.FQDN.ListByWebroot(r.Context(), webroot.ID, request.MaxLimit, "")
+			if err != nil {
+				response.WriteServiceError(w, err)
+				return
+			}
+			resp.FQDNs = includedList(fqdns, hasMore, include, &resp.TruncatedIncludes)
+		case "daemons":
+			daemons, hasMore, err := h.services.Daemon.ListByWebroot(r.Context(), webroot.ID, request.MaxLimit, "")
+			if err != nil {
+				response.WriteServiceError(w, err)
+				return
+			}
+			resp.Daemons = includedList(daemons, hasMore, include, &resp.TruncatedIncludes)
+		case "cronjobs":
+			cronJobs, hasMore, err := h.services.CronJob.ListByWebroot(r.Context(), webroot.ID, request.MaxLimit, "")
+			if err != nil {
+				response.WriteServiceError(w, err)
+				return
+			}
+			resp.CronJobs = includedList(cronJobs, hasMore, include, &resp.TruncatedIncludes)
+		}
+	}
+
+	response.WriteJSON(w, http.StatusOK, resp)
+}
+
+// NginxPreview godoc
+//
+//	@Summary		Preview a webroot's nginx config
+//	@Description	Synchronously renders the nginx server block for the webroot on one web node of its shard, from the webroot's current FQDNs, runtime, error pages and daemon proxies. Read-only: nothing is written or reloaded. The result is the would-be config and may differ from the file on disk if the webroot has not been updated or the shard converged since the last change. Returns 500 if the node agent does not pick up the request.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Webroot ID"
+//	@Success		200	{object}	model.WebrootNginxPreview
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/nginx-preview [get]
+func (h *Webroot) NginxPreview(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	preview, err := h.svc.NginxPreview(r.Context(), webroot.ID)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, preview)
+}
+
+// Default and maximum number of lines returned by AccessLogs.
+const (
+	defaultAccessLogTail = 100
+	maxAccessLogTail     = 1000
+)
+
+// AccessLogs godoc
+//
+//	@Summary		Tail a webroot's access logs
+//	@Description	Synchronously reads the most recent requests from the webroot's shared access logs, merged across the web nodes of its shard and ordered oldest first, with a count per HTTP status class. Requests are only logged there while access_log_enabled is set on the webroot; enabled is false otherwise, and lines written before it was disabled are still returned. Returns 500 if the node agent does not pick up the request.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string	true	"Webroot ID"
+//	@Param			tail	query		int		false	"Number of lines (default 100, max 1000)"
+//	@Success		200		{object}	model.WebrootAccessLog
+//	@Failure		400		{object}	response.ErrorResponse
+//	@Failure		404		{object}	response.ErrorResponse
+//	@Failure		500		{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/access-logs [get]
+func (h *Webroot) AccessLogs(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tail := defaultAccessLogTail
+	if v := r.URL.Query().Get("tail"); v != "" {
+		tail, err = strconv.Atoi(v)
+		if err != nil || tail < 1 || tail > maxAccessLogTail {
+			response.WriteError(w, http.StatusBadRequest, fmt.Sprintf("tail must be between 1 and %d", maxAccessLogTail))
+			return
+		}
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	log, err := h.svc.AccessLog(r.Context(), webroot.ID, tail)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, log)
+}
+
+// GetBasicAuth godoc
+//
+//	@Summary		Get a webroot's basic auth
+//	@Description	Returns whether HTTP basic auth protects the webroot and the allowed usernames. Password hashes are never returned.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Webroot ID"
+//	@Success		200	{object}	model.WebrootBasicAuth
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/basic-auth [get]
+func (h *Webroot) GetBasicAuth(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	basicAuth, err := h.svc.GetBasicAuth(r.Context(), webroot.ID)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, basicAuth)
+}
+
+// SetBasicAuth godoc
+//
+//	@Summary		Set a webroot's basic auth
+//	@Description	Replaces the users allowed through the webroot's HTTP basic auth. Each user gives either a plain password (hashed with bcrypt before storage) or an existing bcrypt password_hash; plain passwords are never stored. An empty users list disables basic auth. ACME HTTP-01 challenges stay reachable without credentials. Async — returns 202 and regenerates the nginx config on the shard.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id		path	string						true	"Webroot ID"
+//	@Param			body	body	request.SetWebrootBasicAuth	true	"Basic auth users"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		409	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/basic-auth [put]
+func (h *Webroot) SetBasicAuth(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.SetWebrootBasicAuth
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	users := make([]model.WebrootBasicAuthUser, len(req.Users))
+	for i, u := range req.Users {
+		users[i] = model.WebrootBasicAuthUser{
+			Username:     u.Username,
+			Password:     u.Password,
+			PasswordHash: u.PasswordHash,
+		}
+	}
+
+	if err := h.svc.SetBasicAuth(r.Context(), webroot.ID, users); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// DeleteBasicAuth godoc
+//
+//	@Summary		Remove a webroot's basic auth
+//	@Description	Removes all basic-auth users so the webroot is served without a password. Async — returns 202 and regenerates the nginx config on the shard.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id	path	string	true	"Webroot ID"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		409	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/basic-auth [delete]
+func (h *Webroot) DeleteBasicAuth(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	if err := h.svc.SetBasicAuth(r.Context(), webroot.ID, nil); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// GetConnectionLimits godoc
+//
+//	@Summary		Get a webroot's connection limits
+//	@Description	Returns the per-client-IP concurrent connection and request rate limits of the webroot. Zero means the limit is off.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Webroot ID"
+//	@Success		200	{object}	model.WebrootConnectionLimits
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/connection-limits [get]
+func (h *Webroot) GetConnectionLimits(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, webroot.ConnectionLimits)
+}
+
+// SetConnectionLimits godoc
+//
+//	@Summary		Set a webroot's connection limits
+//	@Description	Limits concurrent connections (nginx limit_conn) and requests per second with a burst allowance (nginx limit_req) per client IP. Requests over a limit get 429. Zero turns a limit off. Values above the brand's webroot_max_conns_per_ip or webroot_max_requests_per_sec are rejected with 400; 409 if the shard has no shared memory left for the limit zones. Async — returns 202 and regenerates the nginx config on the shard.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id		path	string								true	"Webroot ID"
+//	@Param			body	body	request.SetWebrootConnectionLimits	true	"Connection limits"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		409	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/connection-limits [put]
+func (h *Webroot) SetConnectionLimits(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.SetWebrootConnectionLimits
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	h.setConnectionLimits(w, r, webroot.ID, model.WebrootConnectionLimits{
+		MaxConnsPerIP:     req.MaxConnsPerIP,
+		RequestsPerSecond: req.RequestsPerSecond,
+		Burst:             req.Burst,
+	})
+}
+
+// DeleteConnectionLimits godoc
+//
+//	@Summary		Remove a webroot's connection limits
+//	@Description	Turns off the webroot's per-client-IP connection and request rate limits. Async — returns 202 and regenerates the nginx config on the shard.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id	path	string	true	"Webroot ID"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		409	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/connection-limits [delete]
+func (h *Webroot) DeleteConnectionLimits(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	h.setConnectionLimits(w, r, webroot.ID, model.WebrootConnectionLimits{})
+}
+
+func (h *Webroot) setConnectionLimits(w http.ResponseWriter, r *http.Request, webrootID string, limits model.WebrootConnectionLimits) {
+	if err := h.svc.SetConnectionLimits(r.Context(), webrootID, limits); err != nil {
+		switch {
+		case errors.Is(err, core.ErrConnectionLimitAboveBrandMax):
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, core.ErrLimitZoneBudgetExceeded):
+			response.WriteError(w, http.StatusConflict, err.Error())
+		default:
+			response.WriteServiceError(w, err)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// GetStaticRules godoc
+//
+//	@Summary		Get a webroot's static rules
+//	@Description	Returns the webroot's cache rules and custom MIME types. Empty when none are set.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Webroot ID"
+//	@Success		200	{object}	model.WebrootStaticRules
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/static-rules [get]
+func (h *Webroot) GetStaticRules(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, webroot.StaticRules)
+}
+
+// SetStaticRules godoc
+//
+//	@Summary		Set a webroot's static rules
+//	@Description	Replaces the webroot's cache rules and custom MIME types. Cache rules match a path prefix ("/assets/") or an extension glob ("*.woff2"); the first matching rule sets either a Cache-Control header (cache_control) or Expires with Cache-Control max-age (expires, an nginx time such as "30d" or "max"). Proxied runtimes (node, python, ruby) get the headers added to the app's own. mime_types maps extensions without the dot to a Content-Type and overrides nginx's defaults. Async — returns 202 and regenerates the nginx config on the shard.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id		path	string							true	"Webroot ID"
+//	@Param			body	body	request.SetWebrootStaticRules	true	"Static rules"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/static-rules [put]
+func (h *Webroot) SetStaticRules(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.SetWebrootStaticRules
 	if err := request.Decode(r, &req); err != nil {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	if !checkTenantBrand(w, r, h.services.Tenant, tenantID) {
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
-	now := time.Now()
-	runtimeConfig := req.RuntimeConfig
-	if runtimeConfig == nil {
-		runtimeConfig = json.RawMessage(`{}`)
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
+		return
 	}
 
-	// Validate PHP runtime_config if applicable.
-	if req.Runtime == "php" {
-		if err := runtime.ValidatePHPRuntimeConfig(runtimeConfig); err != nil {
+	h.setStaticRules(w, r, webroot.ID, req.Rules())
+}
+
+// DeleteStaticRules godoc
+//
+//	@Summary		Remove a webroot's static rules
+//	@Description	Removes all of the webroot's cache rules and custom MIME types. Async — returns 202 and regenerates the nginx config on the shard.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id	path	string	true	"Webroot ID"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/static-rules [delete]
+func (h *Webroot) DeleteStaticRules(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	h.setStaticRules(w, r, webroot.ID, model.WebrootStaticRules{})
+}
+
+func (h *Webroot) setStaticRules(w http.ResponseWriter, r *http.Request, webrootID string, rules model.WebrootStaticRules) {
+	if err := h.svc.SetStaticRules(r.Context(), webrootID, rules); err != nil {
+		if errors.Is(err, core.ErrInvalidStaticRules) {
 			response.WriteError(w, http.StatusBadRequest, err.Error())
 			return
 		}
+		response.WriteServiceError(w, err)
+		return
 	}
 
-	envFileName := req.EnvFileName
-	if envFileName == "" {
-		envFileName = ".env.hosting"
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// geoBlockingWithNote is a webroot's geo-blocking config with the note that
+// it depends on the geoip2 module on the web nodes.
+type geoBlockingWithNote struct {
+	model.WebrootGeoBlocking
+	Note string `json:"note"`
+}
+
+// GetGeoBlocking godoc
+//
+//	@Summary		Get a webroot's geo-blocking
+//	@Description	Returns the webroot's country allow or deny list. mode and countries are empty when access is not restricted. The note explains that geo-blocking requires the nginx geoip2 module on the web nodes.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Webroot ID"
+//	@Success		200	{object}	geoBlockingWithNote
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/geo-blocking [get]
+func (h *Webroot) GetGeoBlocking(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
 	}
-	serviceHostnameEnabled := true
-	if req.ServiceHostnameEnabled != nil {
-		serviceHostnameEnabled = *req.ServiceHostnameEnabled
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
 	}
 
-	webroot := &model.Webroot{
-		ID:                     platform.NewName("w"),
-		TenantID:               tenantID,
-		Runtime:                req.Runtime,
-		RuntimeVersion:         req.RuntimeVersion,
-		RuntimeConfig:          runtimeConfig,
-		PublicFolder:            req.PublicFolder,
-		EnvFileName:             envFileName,
-		ServiceHostnameEnabled: serviceHostnameEnabled,
-		Status:                 model.StatusPending,
-		CreatedAt:              now,
-		UpdatedAt:              now,
+	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+		return
 	}
 
-	if err := h.svc.Create(r.Context(), webroot); err != nil {
+	response.WriteJSON(w, http.StatusOK, geoBlockingWithNote{
+		WebrootGeoBlocking: webroot.GeoBlocking,
+		Note:               model.WebrootGeoBlockingNote,
+	})
+}
+
+// SetGeoBlocking godoc
+//
+//	@Summary		Set a webroot's geo-blocking
+//	@Description	Restricts the webroot to clients from the listed countries (mode allow) or blocks clients from them (mode deny). countries are ISO 3166-1 alpha-2 codes such as "NO"; codes are case-insensitive and must be assigned. Blocked clients get 403. Clients whose country cannot be determined, such as private addresses, and ACME HTTP-01 challenges are never blocked. Requires the nginx geoip2 module and a GeoIP2 country database on the shard's web nodes: the webroot update fails with a message naming what is missing on nodes without them. Async — returns 202 with the stored config and regenerates the nginx config on the shard.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string							true	"Webroot ID"
+//	@Param			body	body		request.SetWebrootGeoBlocking	true	"Geo-blocking"
+//	@Success		202		{object}	geoBlockingWithNote
+//	@Failure		400		{object}	response.ErrorResponse
+//	@Failure		404		{object}	response.ErrorResponse
+//	@Failure		500		{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/geo-blocking [put]
+func (h *Webroot) SetGeoBlocking(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.SetWebrootGeoBlocking
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.Validate(); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	h.setGeoBlocking(w, r, webroot.ID, req.GeoBlocking())
+}
+
+// DeleteGeoBlocking godoc
+//
+//	@Summary		Remove a webroot's geo-blocking
+//	@Description	Serves the webroot to clients from every country again. Async — returns 202 and regenerates the nginx config on the shard.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id	path	string	true	"Webroot ID"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/geo-blocking [delete]
+func (h *Webroot) DeleteGeoBlocking(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	if err := h.svc.SetGeoBlocking(r.Context(), webroot.ID, model.WebrootGeoBlocking{}); err != nil {
 		response.WriteServiceError(w, err)
 		return
 	}
 
-	// Nested FQDN creation
-	if err := createNestedFQDNs(r.Context(), h.services, webroot.ID, tenantID, req.FQDNs); err != nil {
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (h *Webroot) setGeoBlocking(w http.ResponseWriter, r *http.Request, webrootID string, geo model.WebrootGeoBlocking) {
+	if err := h.svc.SetGeoBlocking(r.Context(), webrootID, geo); err != nil {
+		if errors.Is(err, core.ErrInvalidGeoBlocking) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		response.WriteServiceError(w, err)
 		return
 	}
 
-	response.WriteJSON(w, http.StatusAccepted, webroot)
+	response.WriteJSON(w, http.StatusAccepted, geoBlockingWithNote{
+		WebrootGeoBlocking: geo,
+		Note:               model.WebrootGeoBlockingNote,
+	})
 }
 
-// Get godoc
+// SetDeployLock godoc
 //
-//	@Summary		Get a webroot
-//	@Description	Returns a single webroot by ID, including runtime configuration details.
+//	@Summary		Lock a webroot during a deploy
+//	@Description	Makes nginx answer the webroot's requests with 503 and a Retry-After of the seconds left, so clients do not hit a half-deployed app. The webroot's custom 503 page is served if it has one, and ACME HTTP-01 challenges are never held off. The lock lifts by itself after ttl_seconds (at most 600), even if it is never cleared; setting it again replaces the expiry. Async — returns 202 with the expiry and applies the lock to the nginx config on the shard.
 //	@Tags			Webroots
 //	@Security		ApiKeyAuth
-//	@Param			id path string true "Webroot ID"
-//	@Success		200 {object} model.Webroot
-//	@Failure		400 {object} response.ErrorResponse
-//	@Failure		404 {object} response.ErrorResponse
-//	@Router			/webroots/{id} [get]
-func (h *Webroot) Get(w http.ResponseWriter, r *http.Request) {
+//	@Param			id		path		string						true	"Webroot ID"
+//	@Param			body	body		request.SetWebrootDeployLock	true	"Lock duration"
+//	@Success		202		{object}	model.WebrootDeployLock
+//	@Failure		400		{object}	response.ErrorResponse
+//	@Failure		404		{object}	response.ErrorResponse
+//	@Failure		500		{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/deploy-lock [post]
+func (h *Webroot) SetDeployLock(w http.ResponseWriter, r *http.Request) {
 	id, err := request.RequireID(chi.URLParam(r, "id"))
 	if err != nil {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
+	var req request.SetWebrootDeployLock
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	webroot, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
 		return
 	}
 
-	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	lock, err := h.svc.SetDeployLock(r.Context(), webroot.ID, req.TTL())
+	if err != nil {
+		if errors.Is(err, core.ErrInvalidDeployLockTTL) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, lock)
+}
+
+// DeleteDeployLock godoc
+//
+//	@Summary		Lift a webroot's deploy lock
+//	@Description	Serves the webroot again before its deploy lock expires. Async — returns 202 and regenerates the nginx config on the shard.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id	path	string	true	"Webroot ID"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/webroots/{id}/deploy-lock [delete]
+func (h *Webroot) DeleteDeployLock(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
 		return
 	}
 
-	response.WriteJSON(w, http.StatusOK, webroot)
+	if err := h.svc.ClearDeployLock(r.Context(), webroot.ID); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
 }
 
 // Update godoc
 //
 //	@Summary		Update a webroot
-//	@Description	Partial update of a webroot — supports changing runtime, version, runtime config, or public folder. Async — returns 202 and triggers re-convergence of the web server configuration.
+//	@Description	Partial update of a webroot — supports changing runtime, version, runtime config, public folder, or custom error pages (status code → path under the public folder; an empty object clears them). Async — returns 202 and triggers re-convergence of the web server configuration. The response includes a quota_near_limit warning if the tenant's disk usage is near its quota.
 //	@Tags			Webroots
 //	@Security		ApiKeyAuth
 //	@Param			id path string true "Webroot ID"
@@ -205,7 +1090,7 @@ func (h *Webroot) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
 		return
 	}
 
@@ -221,12 +1106,24 @@ func (h *Webroot) Update(w http.ResponseWriter, r *http.Request) {
 	if req.PublicFolder != nil {
 		webroot.PublicFolder = *req.PublicFolder
 	}
+	if req.ErrorPages != nil {
+		webroot.ErrorPages = req.ErrorPages
+	}
 	if req.EnvFileName != nil {
 		webroot.EnvFileName = *req.EnvFileName
 	}
 	if req.ServiceHostnameEnabled != nil {
 		webroot.ServiceHostnameEnabled = *req.ServiceHostnameEnabled
 	}
+	if req.AccessLogEnabled != nil {
+		webroot.AccessLogEnabled = *req.AccessLogEnabled
+	}
+
+	// Validate error pages against the merged public folder.
+	if err := runtime.ValidateErrorPages(webroot.ErrorPages, webroot.PublicFolder); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Validate PHP runtime_config after merging.
 	effectiveRuntime := webroot.Runtime
@@ -242,7 +1139,7 @@ func (h *Webroot) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response.WriteJSON(w, http.StatusAccepted, webroot)
+	response.WriteJSONWithWarnings(w, http.StatusAccepted, webroot, h.services.Tenant.QuotaWarnings(r.Context(), webroot.TenantID))
 }
 
 // Delete godoc
@@ -268,7 +1165,7 @@ func (h *Webroot) Delete(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusNotFound, err.Error())
 		return
 	}
-	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+	if !checkTenantMutable(w, r, h.services.Tenant, webroot.TenantID) {
 		return
 	}
 
@@ -311,3 +1208,65 @@ func (h *Webroot) Retry(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// Clone godoc
+//
+//	@Summary		Clone a webroot
+//	@Description	Creates a new webroot in the same tenant with the source's runtime, settings, basic auth, files and env vars, e.g. for a staging copy. The source must be active. Secret env vars are not copied: they take the value given in secret_env_vars or a new random one, listed in regenerated_secrets. The clone gets no FQDNs, only its service hostname if the source has one enabled. With database_id, that database is cloned to a new database on the same shard and the source webroot and database names in plain env vars are pointed at the clones; database users are not cloned, and the databases:write scope is also required. Async — returns 202 and starts CloneWebrootWorkflow, tracked by the operation in the X-Operation-ID header. A failed clone is deleted again.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Source webroot ID"
+//	@Param			body body request.CloneWebroot true "Clone options"
+//	@Success		202 {object} model.WebrootClone
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		403 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Failure		500 {object} response.ErrorResponse
+//	@Router			/webroots/{id}/clone [post]
+func (h *Webroot) Clone(w http.ResponseWriter, r *http.Request) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.CloneWebroot
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// The route only requires webroots:write; a database is cloned too.
+	if req.DatabaseID != "" && !mw.HasScope(mw.GetIdentity(r.Context()), "databases", "write") {
+		response.WriteError(w, http.StatusForbidden, "cloning a database requires the databases:write scope")
+		return
+	}
+
+	source, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantMutable(w, r, h.services.Tenant, source.TenantID) {
+		return
+	}
+
+	clone, err := h.services.WebrootClone.Clone(r.Context(), id, core.CloneWebrootInput{
+		DatabaseID:    req.DatabaseID,
+		SecretEnvVars: req.SecretEnvVars,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrCloneSourceNotActive):
+			response.WriteError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, core.ErrCloneDatabaseNotFound), errors.Is(err, core.ErrCloneUnknownSecret):
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+		default:
+			response.WriteServiceError(w, err)
+		}
+		return
+	}
+
+	response.WriteJSONWithWarnings(w, http.StatusAccepted, clone, h.services.Tenant.QuotaWarnings(r.Context(), source.TenantID))
+}
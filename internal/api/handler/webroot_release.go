@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	"github.com/go-chi/chi/v5"
+)
+
+type WebrootRelease struct {
+	svc      *core.WebrootReleaseService
+	services *core.Services
+}
+
+func NewWebrootRelease(services *core.Services) *WebrootRelease {
+	return &WebrootRelease{svc: services.WebrootRelease, services: services}
+}
+
+// activeWebroot resolves the webroot in the URL and checks access to it. It
+// writes the error response and returns nil on failure.
+func (h *WebrootRelease) activeWebroot(w http.ResponseWriter, r *http.Request, param string) *model.Webroot {
+	webrootID, err := request.RequireID(chi.URLParam(r, param))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return nil
+	}
+
+	webroot, err := h.services.Webroot.GetByID(r.Context(), webrootID)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, "webroot not found")
+		return nil
+	}
+	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+		return nil
+	}
+	if webroot.Status != model.StatusActive {
+		response.WriteError(w, http.StatusBadRequest, "webroot is not active")
+		return nil
+	}
+	return webroot
+}
+
+// ListByWebroot godoc
+//
+//	@Summary		List webroot releases
+//	@Description	Returns the webroot's releases, newest first. The live release has current set.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			webrootID path string true "Webroot ID"
+//	@Param			limit query int false "Page size" default(50)
+//	@Param			cursor query string false "Pagination cursor"
+//	@Success		200 {object} response.PaginatedResponse{items=[]model.WebrootRelease}
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Router			/webroots/{webrootID}/releases [get]
+func (h *WebrootRelease) ListByWebroot(w http.ResponseWriter, r *http.Request) {
+	webrootID, err := request.RequireID(chi.URLParam(r, "webrootID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot, err := h.services.Webroot.GetByID(r.Context(), webrootID)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if !checkTenantBrand(w, r, h.services.Tenant, webroot.TenantID) {
+		return
+	}
+
+	pg := request.ParsePagination(r)
+
+	releases, hasMore, err := h.svc.ListByWebroot(r.Context(), webrootID, pg.Limit, pg.Cursor)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	var nextCursor string
+	if hasMore && len(releases) > 0 {
+		nextCursor = releases[len(releases)-1].Name
+	}
+	response.WritePaginated(w, http.StatusOK, releases, nextCursor, hasMore)
+}
+
+// Create godoc
+//
+//	@Summary		Create a webroot release
+//	@Description	Creates an empty release directory, releases/<name> in the webroot, named after the current UTC time. Upload the deploy into it, then promote it. Async — returns 202 and starts a Temporal workflow.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			webrootID path string true "Webroot ID"
+//	@Success		202 {object} model.WebrootRelease
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Router			/webroots/{webrootID}/releases [post]
+func (h *WebrootRelease) Create(w http.ResponseWriter, r *http.Request) {
+	webroot := h.activeWebroot(w, r, "webrootID")
+	if webroot == nil {
+		return
+	}
+
+	now := time.Now()
+	release := &model.WebrootRelease{
+		ID:        platform.NewName("rel"),
+		TenantID:  webroot.TenantID,
+		WebrootID: webroot.ID,
+		Name:      now.UTC().Format(model.WebrootReleaseNameFormat),
+		Status:    model.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := h.svc.Create(r.Context(), release); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, release)
+}
+
+// Promote godoc
+//
+//	@Summary		Promote a webroot release
+//	@Description	Atomically swaps the webroot's current symlink to the release and reloads the runtime. Releases beyond the newest five are pruned. Async — returns 202 and starts a Temporal workflow.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			webrootID path string true "Webroot ID"
+//	@Param			releaseID path string true "Release ID"
+//	@Success		202 {object} model.WebrootRelease
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Router			/webroots/{webrootID}/releases/{releaseID}/promote [post]
+func (h *WebrootRelease) Promote(w http.ResponseWriter, r *http.Request) {
+	releaseID, err := request.RequireID(chi.URLParam(r, "releaseID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	webroot := h.activeWebroot(w, r, "webrootID")
+	if webroot == nil {
+		return
+	}
+
+	release, err := h.svc.GetByID(r.Context(), releaseID)
+	if err != nil || release.WebrootID != webroot.ID {
+		response.WriteError(w, http.StatusNotFound, "release not found")
+		return
+	}
+	if release.Status != model.StatusActive {
+		response.WriteError(w, http.StatusBadRequest, "release is not active")
+		return
+	}
+
+	if err := h.svc.Promote(r.Context(), release); err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, release)
+}
+
+// Rollback godoc
+//
+//	@Summary		Roll back a webroot
+//	@Description	Promotes the newest previously-live release older than the current one and returns it. Repeated rollbacks walk further back. Async — returns 202 and starts a Temporal workflow.
+//	@Tags			Webroots
+//	@Security		ApiKeyAuth
+//	@Param			id path string true "Webroot ID"
+//	@Success		202 {object} model.WebrootRelease
+//	@Failure		400 {object} response.ErrorResponse
+//	@Failure		404 {object} response.ErrorResponse
+//	@Failure		409 {object} response.ErrorResponse
+//	@Router			/webroots/{id}/rollback [post]
+func (h *WebrootRelease) Rollback(w http.ResponseWriter, r *http.Request) {
+	webroot := h.activeWebroot(w, r, "id")
+	if webroot == nil {
+		return
+	}
+
+	release, err := h.svc.Rollback(r.Context(), webroot.ID)
+	if errors.Is(err, core.ErrNoPreviousRelease) {
+		response.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusAccepted, release)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newWebrootReleaseHandler() *WebrootRelease {
+	return &WebrootRelease{svc: nil, services: nil}
+}
+
+func TestWebrootReleaseListByWebroot_EmptyID(t *testing.T) {
+	h := newWebrootReleaseHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/webroots//releases", nil)
+	r = withChiURLParam(r, "webrootID", "")
+
+	h.ListByWebroot(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestWebrootReleaseCreate_EmptyWebrootID(t *testing.T) {
+	h := newWebrootReleaseHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/webroots//releases", nil)
+	r = withChiURLParam(r, "webrootID", "")
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestWebrootReleasePromote_EmptyReleaseID(t *testing.T) {
+	h := newWebrootReleaseHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/webroots/"+validID+"/releases//promote", nil)
+	r = withChiURLParams(r, map[string]string{"webrootID": validID, "releaseID": ""})
+
+	h.Promote(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestWebrootReleaseRollback_EmptyID(t *testing.T) {
+	h := newWebrootReleaseHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/webroots//rollback", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Rollback(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -8,6 +9,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	mw "github.com/edvin/hosting/internal/api/middleware"
 )
 
 func newWebrootHandler() *Webroot {
@@ -100,11 +103,13 @@ func TestWebrootCreate_MissingRuntime(t *testing.T) {
 	assert.Contains(t, body["error"], "validation error")
 }
 
-func TestWebrootCreate_MissingRuntimeVersion(t *testing.T) {
+func TestWebrootCreate_TemplateWithFQDNs(t *testing.T) {
 	h := newWebrootHandler()
 	rec := httptest.NewRecorder()
 	r := newRequest(http.MethodPost, "/tenants/"+validID+"/webroots", map[string]any{
-		"runtime": "php",
+		"subscription_id": "sub-1",
+		"template_id":     "wordpress",
+		"fqdns":           []map[string]any{{"fqdn": "example.com"}},
 	})
 	r = withChiURLParam(r, "tenantID", validID)
 
@@ -112,7 +117,18 @@ func TestWebrootCreate_MissingRuntimeVersion(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 	body := decodeErrorResponse(rec)
-	assert.Contains(t, body["error"], "validation error")
+	assert.Contains(t, body["error"], "cannot be combined with template_id")
+}
+
+func TestWebrootListTemplates_EmptyTenantID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/tenants//app-templates", nil)
+	r = withChiURLParam(r, "tenantID", "")
+
+	h.ListTemplates(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
 
 func TestWebrootCreate_InvalidRuntime(t *testing.T) {
@@ -178,6 +194,61 @@ func TestWebrootCreate_ValidBody(t *testing.T) {
 	assert.NotEqual(t, http.StatusBadRequest, rec.Code)
 }
 
+func TestWebrootCreate_ErrorPagesOutsidePublicFolder(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants/"+validID+"/webroots", map[string]any{
+		"subscription_id": "sub-1",
+		"runtime":         "static",
+		"runtime_version": "1",
+		"public_folder":   "public",
+		"error_pages":     map[string]any{"404": "private/404.html"},
+	})
+	r = withChiURLParam(r, "tenantID", validID)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "not under public folder")
+}
+
+func TestWebrootCreate_ErrorPagesInvalidStatusCode(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants/"+validID+"/webroots", map[string]any{
+		"subscription_id": "sub-1",
+		"runtime":         "static",
+		"runtime_version": "1",
+		"error_pages":     map[string]any{"302": "redirect.html"},
+	})
+	r = withChiURLParam(r, "tenantID", validID)
+
+	h.Create(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootCreate_ValidErrorPages(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/tenants/"+validID+"/webroots", map[string]any{
+		"subscription_id": "sub-1",
+		"runtime":         "static",
+		"runtime_version": "1",
+		"public_folder":   "public",
+		"error_pages":     map[string]any{"404": "public/errors/404.html", "503": "public/errors/503.html"},
+	})
+	r = withChiURLParam(r, "tenantID", validID)
+
+	func() {
+		defer func() { recover() }()
+		h.Create(rec, r)
+	}()
+
+	assert.NotEqual(t, http.StatusBadRequest, rec.Code)
+}
+
 // --- Nested resource validation ---
 
 func TestWebrootCreate_WithNestedFQDNs_ValidationPasses(t *testing.T) {
@@ -266,6 +337,328 @@ func TestWebrootGet_EmptyID(t *testing.T) {
 	assert.Contains(t, body["error"], "missing required ID")
 }
 
+func TestWebrootGet_UnknownInclude(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/webroots/test-webroot-1?include=fqdns,certificates", nil)
+	r = withChiURLParam(r, "id", "test-webroot-1")
+
+	h.Get(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], `unknown include "certificates": allowed are cronjobs, daemons, fqdns`)
+}
+
+func TestWebrootGet_IncludeRequiresScope(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/webroots/test-webroot-1?include=fqdns,cronjobs", nil)
+	r = withChiURLParam(r, "id", "test-webroot-1")
+	r = r.WithContext(context.WithValue(r.Context(), mw.APIKeyIdentityKey, &mw.APIKeyIdentity{
+		ID: "key-1", Scopes: []string{"webroots:read", "fqdns:read"}, Brands: []string{"*"},
+	}))
+
+	h.Get(rec, r)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "include=cronjobs requires cron_jobs:read")
+}
+
+// --- NginxPreview ---
+
+func TestWebrootNginxPreview_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/webroots//nginx-preview", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.NginxPreview(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+// --- AccessLogs ---
+
+func TestWebrootAccessLogs_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/webroots//access-logs", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.AccessLogs(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestWebrootAccessLogs_InvalidTail(t *testing.T) {
+	for _, tail := range []string{"0", "1001", "abc"} {
+		h := newWebrootHandler()
+		rec := httptest.NewRecorder()
+		r := newRequest(http.MethodGet, "/webroots/test-webroot-1/access-logs?tail="+tail, nil)
+		r = withChiURLParam(r, "id", "test-webroot-1")
+
+		h.AccessLogs(rec, r)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, "tail=%s", tail)
+		body := decodeErrorResponse(rec)
+		assert.Contains(t, body["error"], "tail must be between 1 and 1000")
+	}
+}
+
+// --- Basic auth ---
+
+func TestWebrootGetBasicAuth_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/webroots//basic-auth", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.GetBasicAuth(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestWebrootSetBasicAuth_MissingPassword(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/webroots/"+validID+"/basic-auth", map[string]any{
+		"users": []map[string]any{{"username": "staging"}},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetBasicAuth(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "password or password_hash is required")
+}
+
+func TestWebrootSetBasicAuth_InvalidHash(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/webroots/"+validID+"/basic-auth", map[string]any{
+		"users": []map[string]any{{"username": "staging", "password_hash": "plaintext"}},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetBasicAuth(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "bcrypt")
+}
+
+func TestWebrootDeleteBasicAuth_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/webroots//basic-auth", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.DeleteBasicAuth(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+// --- Connection limits ---
+
+func TestWebrootGetConnectionLimits_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/webroots//connection-limits", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.GetConnectionLimits(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootSetConnectionLimits_BurstWithoutRate(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/webroots/"+validID+"/connection-limits", map[string]any{
+		"max_conns_per_ip": 10,
+		"burst":            20,
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetConnectionLimits(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "burst requires requests_per_second")
+}
+
+func TestWebrootSetConnectionLimits_Negative(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/webroots/"+validID+"/connection-limits", map[string]any{
+		"requests_per_second": -1,
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetConnectionLimits(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootDeleteConnectionLimits_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/webroots//connection-limits", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.DeleteConnectionLimits(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+// --- Static rules ---
+
+func TestWebrootGetStaticRules_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/webroots//static-rules", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.GetStaticRules(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootSetStaticRules_InvalidPattern(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/webroots/"+validID+"/static-rules", map[string]any{
+		"cache_rules": []map[string]any{{"pattern": "~ .*", "cache_control": "no-cache"}},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetStaticRules(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "pattern must be")
+}
+
+func TestWebrootSetStaticRules_InvalidMimeType(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/webroots/"+validID+"/static-rules", map[string]any{
+		"mime_types": map[string]string{"md": "text/markdown; charset=utf-8"},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetStaticRules(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootDeleteStaticRules_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/webroots//static-rules", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.DeleteStaticRules(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootGetGeoBlocking_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/webroots//geo-blocking", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.GetGeoBlocking(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootSetGeoBlocking_InvalidCountry(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/webroots/"+validID+"/geo-blocking", map[string]any{
+		"mode":      "deny",
+		"countries": []string{"no", "XX"},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetGeoBlocking(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], `"XX" is not an ISO 3166-1`)
+}
+
+func TestWebrootSetGeoBlocking_InvalidMode(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/webroots/"+validID+"/geo-blocking", map[string]any{
+		"mode":      "block",
+		"countries": []string{"NO"},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetGeoBlocking(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootDeleteGeoBlocking_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/webroots//geo-blocking", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.DeleteGeoBlocking(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootSetDeployLock_TTLTooLong(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/webroots/"+validID+"/deploy-lock", map[string]any{"ttl_seconds": 3600})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetDeployLock(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootSetDeployLock_MissingTTL(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/webroots/"+validID+"/deploy-lock", map[string]any{})
+	r = withChiURLParam(r, "id", validID)
+
+	h.SetDeployLock(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestWebrootDeleteDeployLock_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodDelete, "/webroots//deploy-lock", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.DeleteDeployLock(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
 // --- Update ---
 
 func TestWebrootUpdate_EmptyID(t *testing.T) {
@@ -326,6 +719,64 @@ func TestWebrootDelete_EmptyID(t *testing.T) {
 	assert.Contains(t, body["error"], "missing required ID")
 }
 
+// --- Clone ---
+
+func TestWebrootClone_EmptyID(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/webroots//clone", map[string]any{})
+	r = withChiURLParam(r, "id", "")
+
+	h.Clone(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestWebrootClone_InvalidJSON(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequestRaw(http.MethodPost, "/webroots/"+validID+"/clone", "not json")
+	r = withChiURLParam(r, "id", validID)
+
+	h.Clone(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "invalid JSON")
+}
+
+func TestWebrootClone_EmptySecretValue(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/webroots/"+validID+"/clone", map[string]any{
+		"secret_env_vars": map[string]string{"API_KEY": ""},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.Clone(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "validation error")
+}
+
+func TestWebrootClone_DatabaseRequiresScope(t *testing.T) {
+	h := newWebrootHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/webroots/"+validID+"/clone", map[string]any{
+		"database_id": "db_source",
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.Clone(rec, r)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "databases:write")
+}
+
 // --- Error response format ---
 
 func TestWebrootCreate_ErrorResponseFormat(t *testing.T) {
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -28,17 +30,46 @@ func (h *Zone) checkZoneBrandAccess(w http.ResponseWriter, r *http.Request, id s
 		response.WriteError(w, http.StatusNotFound, err.Error())
 		return false
 	}
-	if !mw.HasBrandAccess(mw.GetIdentity(r.Context()), zone.BrandID) {
-		response.WriteError(w, http.StatusForbidden, "no access to this brand")
+	if !h.hasZoneAccess(r, zone) {
+		response.WriteError(w, http.StatusForbidden, "no access to this zone")
+		return false
+	}
+	return true
+}
+
+// hasZoneAccess checks brand access to a zone. Reseller-scoped keys only
+// reach zones attached to one of their reseller's tenants. The zone's tenant,
+// if any, is recorded for the audit log.
+func (h *Zone) hasZoneAccess(r *http.Request, zone *model.Zone) bool {
+	identity := mw.GetIdentity(r.Context())
+	if !mw.HasBrandAccess(identity, zone.BrandID) {
+		return false
+	}
+	if mw.IsResellerScoped(identity) && !h.hasTenantAccess(r, zone.TenantID) {
 		return false
 	}
+	if zone.TenantID != "" {
+		mw.AuditTenant(r.Context(), zone.TenantID)
+	}
 	return true
 }
 
+// hasTenantAccess checks that the caller can act on the given tenant.
+func (h *Zone) hasTenantAccess(r *http.Request, tenantID string) bool {
+	if tenantID == "" {
+		return false
+	}
+	tenant, err := h.services.Tenant.GetByID(r.Context(), tenantID)
+	if err != nil {
+		return false
+	}
+	return mw.HasTenantAccess(mw.GetIdentity(r.Context()), tenant.BrandID, tenant.ResellerID)
+}
+
 // List godoc
 //
 //	@Summary		List zones
-//	@Description	Returns a paginated list of all DNS zones across all brands. Supports filtering by search term and status, and sorting by any column.
+//	@Description	Returns a paginated list of all DNS zones across all brands. Supports filtering by search term and status, and sorting by any column. Reseller-scoped keys only see zones of their reseller's tenants.
 //	@Tags			Zones
 //	@Security		ApiKeyAuth
 //	@Param			limit		query		int		false	"Page size"						default(50)
@@ -54,6 +85,7 @@ func (h *Zone) checkZoneBrandAccess(w http.ResponseWriter, r *http.Request, id s
 func (h *Zone) List(w http.ResponseWriter, r *http.Request) {
 	params := request.ParseListParams(r, "created_at")
 	params.BrandIDs = mw.BrandIDs(r.Context())
+	params.ResellerID = mw.ResellerID(r.Context())
 
 	zones, hasMore, err := h.svc.List(r.Context(), params)
 	if err != nil {
@@ -71,7 +103,7 @@ func (h *Zone) List(w http.ResponseWriter, r *http.Request) {
 // Create godoc
 //
 //	@Summary		Create a zone
-//	@Description	Creates a DNS zone (e.g. "example.com"). Requires brand_id and region_id; if tenant_id is provided, the brand is derived from the tenant. Returns 202 and triggers a Temporal workflow to create the zone in the brand's PowerDNS database with SOA and NS records.
+//	@Description	Creates a DNS zone (e.g. "example.com"). Requires brand_id and region_id; if tenant_id is provided, the brand is derived from the tenant. Returns 202 and triggers a Temporal workflow to create the zone in the brand's PowerDNS database with SOA and NS records. Optional secondary_region_ids name other regions whose nameservers also serve the zone as AXFR secondaries; each must have nameservers in its config (400 otherwise).
 //	@Tags			Zones
 //	@Security		ApiKeyAuth
 //	@Param			body	body		request.CreateZone	true	"Zone details"
@@ -105,24 +137,36 @@ func (h *Zone) Create(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusForbidden, "no access to this brand")
 		return
 	}
+	if mw.IsResellerScoped(mw.GetIdentity(r.Context())) && !h.hasTenantAccess(r, req.TenantID) {
+		response.WriteError(w, http.StatusForbidden, "reseller API keys can only create zones for their own tenants")
+		return
+	}
 
 	now := time.Now()
 	zone := &model.Zone{
-		ID:             platform.NewID(),
-		BrandID:        brandID,
-		TenantID:       req.TenantID,
-		SubscriptionID: req.SubscriptionID,
-		Name:           req.Name,
-		RegionID:       req.RegionID,
-		Status:         model.StatusPending,
-		CreatedAt:      now,
-		UpdatedAt:      now,
+		ID:                 platform.NewID(),
+		BrandID:            brandID,
+		TenantID:           req.TenantID,
+		SubscriptionID:     req.SubscriptionID,
+		Name:               req.Name,
+		RegionID:           req.RegionID,
+		SecondaryRegionIDs: req.SecondaryRegionIDs,
+		Status:             model.StatusPending,
+		CreatedAt:          now,
+		UpdatedAt:          now,
 	}
 
 	if err := h.svc.Create(r.Context(), zone); err != nil {
+		if errors.Is(err, core.ErrInvalidSecondaryRegion) {
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		response.WriteServiceError(w, err)
 		return
 	}
+	if zone.TenantID != "" {
+		mw.AuditTenant(r.Context(), zone.TenantID)
+	}
 
 	response.WriteJSON(w, http.StatusAccepted, zone)
 }
@@ -151,8 +195,8 @@ func (h *Zone) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !mw.HasBrandAccess(mw.GetIdentity(r.Context()), zone.BrandID) {
-		response.WriteError(w, http.StatusForbidden, "no access to this brand")
+	if !h.hasZoneAccess(r, zone) {
+		response.WriteError(w, http.StatusForbidden, "no access to this zone")
 		return
 	}
 
@@ -162,14 +206,16 @@ func (h *Zone) Get(w http.ResponseWriter, r *http.Request) {
 // Update godoc
 //
 //	@Summary		Update a zone
-//	@Description	Updates a DNS zone. Currently only supports changing the tenant_id association. This is a synchronous operation.
+//	@Description	Updates a DNS zone's tenant_id association and secondary_region_ids. A tenant change is synchronous. Changing the secondary regions of an active zone returns 202 and starts ConfigureZoneReplicationWorkflow, which updates the zone's NS records and transfer settings in PowerDNS; an empty list stops replication. Returns 409 if the zone is not active.
 //	@Tags			Zones
 //	@Security		ApiKeyAuth
 //	@Param			id		path		string				true	"Zone ID"
 //	@Param			body	body		request.UpdateZone	true	"Zone updates"
 //	@Success		200		{object}	model.Zone
+//	@Success		202		{object}	model.Zone
 //	@Failure		400		{object}	response.ErrorResponse
 //	@Failure		404		{object}	response.ErrorResponse
+//	@Failure		409		{object}	response.ErrorResponse
 //	@Failure		500		{object}	response.ErrorResponse
 //	@Router			/zones/{id} [put]
 func (h *Zone) Update(w http.ResponseWriter, r *http.Request) {
@@ -191,21 +237,42 @@ func (h *Zone) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !mw.HasBrandAccess(mw.GetIdentity(r.Context()), zone.BrandID) {
-		response.WriteError(w, http.StatusForbidden, "no access to this brand")
+	if !h.hasZoneAccess(r, zone) {
+		response.WriteError(w, http.StatusForbidden, "no access to this zone")
 		return
 	}
 
 	if req.TenantID != nil {
+		if mw.IsResellerScoped(mw.GetIdentity(r.Context())) && !h.hasTenantAccess(r, *req.TenantID) {
+			response.WriteError(w, http.StatusForbidden, "no access to this tenant")
+			return
+		}
 		zone.TenantID = *req.TenantID
 	}
 
+	status := http.StatusOK
+	if req.SecondaryRegionIDs != nil {
+		err := h.svc.SetSecondaryRegions(r.Context(), zone, *req.SecondaryRegionIDs)
+		switch {
+		case errors.Is(err, core.ErrInvalidSecondaryRegion):
+			response.WriteError(w, http.StatusBadRequest, err.Error())
+			return
+		case errors.Is(err, core.ErrZoneReplicationState):
+			response.WriteError(w, http.StatusConflict, err.Error())
+			return
+		case err != nil:
+			response.WriteServiceError(w, err)
+			return
+		}
+		status = http.StatusAccepted
+	}
+
 	if err := h.svc.Update(r.Context(), zone); err != nil {
 		response.WriteServiceError(w, err)
 		return
 	}
 
-	response.WriteJSON(w, http.StatusOK, zone)
+	response.WriteJSON(w, status, zone)
 }
 
 // Delete godoc
@@ -264,3 +331,177 @@ func (h *Zone) Retry(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(http.StatusAccepted)
 }
+
+// Nameservers godoc
+//
+//	@Summary		List a zone's nameservers
+//	@Description	Returns where the zone is served: the brand's primary_ns and secondary_ns, the nameservers of the zone's region (role primary) and those of its secondary regions (role secondary). Each nameserver is asked for the zone's NS records; reachable is false, with an error, if it did not answer or does not serve the zone yet.
+//	@Tags			Zones
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Zone ID"
+//	@Success		200	{object}	model.ZoneNameservers
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		403	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		500	{object}	response.ErrorResponse
+//	@Router			/zones/{id}/nameservers [get]
+func (h *Zone) Nameservers(w http.ResponseWriter, r *http.Request) {
+	zone, ok := h.dnssecZone(w, r)
+	if !ok {
+		return
+	}
+
+	nameservers, err := h.svc.Nameservers(r.Context(), zone)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, nameservers)
+}
+
+// Export godoc
+//
+//	@Summary		Export a zone
+//	@Description	Downloads everything the zone serves: the SOA and NS records from the brand configuration, followed by its custom, template and auto-managed records. format=bind (the default) returns an RFC 1035 zone file with fully qualified names. format=json returns the records with managed_by and platform_managed; records that are not platform-managed can be passed unchanged to PUT /zones/{zoneID}/records to restore them.
+//	@Tags			Zones
+//	@Security		ApiKeyAuth
+//	@Param			id		path		string	true	"Zone ID"
+//	@Param			format	query		string	false	"Export format (bind or json)"	default(bind)
+//	@Success		200		{object}	model.ZoneExport
+//	@Failure		400		{object}	response.ErrorResponse
+//	@Failure		403		{object}	response.ErrorResponse
+//	@Failure		404		{object}	response.ErrorResponse
+//	@Failure		500		{object}	response.ErrorResponse
+//	@Router			/zones/{id}/export [get]
+func (h *Zone) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = model.ZoneExportBIND
+	}
+	if format != model.ZoneExportBIND && format != model.ZoneExportJSON {
+		response.WriteError(w, http.StatusBadRequest, "format must be bind or json")
+		return
+	}
+
+	zone, ok := h.dnssecZone(w, r)
+	if !ok {
+		return
+	}
+
+	export, err := h.svc.Export(r.Context(), zone)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	if format == model.ZoneExportJSON {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", zone.Name+".json"))
+		response.WriteJSON(w, http.StatusOK, export)
+		return
+	}
+	w.Header().Set("Content-Type", "text/dns")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", zone.Name+".zone"))
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(export.BIND()))
+}
+
+// GetDNSSEC godoc
+//
+//	@Summary		Get a zone's DNSSEC state
+//	@Description	Returns the zone's DNSSEC status (unsigned, signing, signed, unsigning or failed), its DNSKEY records and the DS records to add at the registrar. Keys that are published but not active are being rolled over.
+//	@Tags			Zones
+//	@Security		ApiKeyAuth
+//	@Param			id	path		string	true	"Zone ID"
+//	@Success		200	{object}	model.ZoneDNSSEC
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		403	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Router			/zones/{id}/dnssec [get]
+func (h *Zone) GetDNSSEC(w http.ResponseWriter, r *http.Request) {
+	zone, ok := h.dnssecZone(w, r)
+	if !ok {
+		return
+	}
+
+	dnssec, err := h.svc.GetDNSSEC(r.Context(), zone)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, dnssec)
+}
+
+// Sign godoc
+//
+//	@Summary		Enable DNSSEC for a zone
+//	@Description	Signs an active zone. Returns 202 and starts SignZoneWorkflow, which generates an ECDSA P-256 KSK and ZSK in PowerDNS. Once dnssec_status is signed, GET /zones/{id}/dnssec returns the DS record for the registrar. Returns 409 if the zone is not active or already signed.
+//	@Tags			Zones
+//	@Security		ApiKeyAuth
+//	@Param			id	path	string	true	"Zone ID"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		403	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		409	{object}	response.ErrorResponse
+//	@Router			/zones/{id}/dnssec [post]
+func (h *Zone) Sign(w http.ResponseWriter, r *http.Request) {
+	zone, ok := h.dnssecZone(w, r)
+	if !ok {
+		return
+	}
+	h.writeDNSSECChange(w, h.svc.Sign(r.Context(), zone))
+}
+
+// Unsign godoc
+//
+//	@Summary		Disable DNSSEC for a zone
+//	@Description	Removes the zone's DNSSEC keys. Returns 202 and starts UnsignZoneWorkflow. Remove the DS record at the registrar and wait for its TTL to pass first, or validating resolvers will reject the zone. Returns 409 if the zone is not signed.
+//	@Tags			Zones
+//	@Security		ApiKeyAuth
+//	@Param			id	path	string	true	"Zone ID"
+//	@Success		202
+//	@Failure		400	{object}	response.ErrorResponse
+//	@Failure		403	{object}	response.ErrorResponse
+//	@Failure		404	{object}	response.ErrorResponse
+//	@Failure		409	{object}	response.ErrorResponse
+//	@Router			/zones/{id}/dnssec [delete]
+func (h *Zone) Unsign(w http.ResponseWriter, r *http.Request) {
+	zone, ok := h.dnssecZone(w, r)
+	if !ok {
+		return
+	}
+	h.writeDNSSECChange(w, h.svc.Unsign(r.Context(), zone))
+}
+
+// dnssecZone loads the zone of a DNSSEC, nameserver or export request and
+// checks access to it.
+func (h *Zone) dnssecZone(w http.ResponseWriter, r *http.Request) (*model.Zone, bool) {
+	id, err := request.RequireID(chi.URLParam(r, "id"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	zone, err := h.svc.GetByID(r.Context(), id)
+	if err != nil {
+		response.WriteError(w, http.StatusNotFound, err.Error())
+		return nil, false
+	}
+	if !h.hasZoneAccess(r, zone) {
+		response.WriteError(w, http.StatusForbidden, "no access to this zone")
+		return nil, false
+	}
+	return zone, true
+}
+
+func (h *Zone) writeDNSSECChange(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, core.ErrDNSSECState):
+		response.WriteError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		response.WriteServiceError(w, err)
+	default:
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
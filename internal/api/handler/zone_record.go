@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -13,11 +15,13 @@ import (
 )
 
 type ZoneRecord struct {
-	svc *core.ZoneRecordService
+	svc       *core.ZoneRecordService
+	tenantSvc *core.TenantService
+	ownership *core.OwnershipService
 }
 
-func NewZoneRecord(svc *core.ZoneRecordService) *ZoneRecord {
-	return &ZoneRecord{svc: svc}
+func NewZoneRecord(svc *core.ZoneRecordService, tenantSvc *core.TenantService, ownership *core.OwnershipService) *ZoneRecord {
+	return &ZoneRecord{svc: svc, tenantSvc: tenantSvc, ownership: ownership}
 }
 
 // ListByZone godoc
@@ -40,6 +44,10 @@ func (h *ZoneRecord) ListByZone(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "zones", zoneID) {
+		return
+	}
+
 	pg := request.ParsePagination(r)
 
 	records, hasMore, err := h.svc.ListByZone(r.Context(), zoneID, pg.Limit, pg.Cursor)
@@ -55,6 +63,56 @@ func (h *ZoneRecord) ListByZone(w http.ResponseWriter, r *http.Request) {
 	response.WritePaginated(w, http.StatusOK, records, nextCursor, hasMore)
 }
 
+// SearchByTenant godoc
+//
+//	@Summary		Search zone records across a tenant's zones
+//	@Description	Finds DNS records in all of the tenant's zones with one request, e.g. which zone points a name at an IP. type and content match exactly (type is case-insensitive), name matches any part of the record name. Each match includes its zone_name. Deleted records are not returned.
+//	@Tags			Zone Records
+//	@Security		ApiKeyAuth
+//	@Param			tenantID	path		string	true	"Tenant ID"
+//	@Param			type		query		string	false	"Record type, e.g. A"
+//	@Param			name		query		string	false	"Part of the record name"
+//	@Param			content		query		string	false	"Exact record content, e.g. 1.2.3.4"
+//	@Param			limit		query		int		false	"Page size"	default(50)
+//	@Param			cursor		query		string	false	"Pagination cursor"
+//	@Success		200			{object}	response.PaginatedResponse{items=[]model.ZoneRecordSearchResult}
+//	@Failure		400			{object}	response.ErrorResponse
+//	@Failure		403			{object}	response.ErrorResponse
+//	@Failure		404			{object}	response.ErrorResponse
+//	@Failure		500			{object}	response.ErrorResponse
+//	@Router			/tenants/{tenantID}/zone-records/search [get]
+func (h *ZoneRecord) SearchByTenant(w http.ResponseWriter, r *http.Request) {
+	tenantID, err := request.RequireID(chi.URLParam(r, "tenantID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !checkTenantBrand(w, r, h.tenantSvc, tenantID) {
+		return
+	}
+
+	pg := request.ParsePagination(r)
+	q := r.URL.Query()
+	filters := core.ZoneRecordSearchFilters{
+		Type:    q.Get("type"),
+		Name:    q.Get("name"),
+		Content: q.Get("content"),
+	}
+
+	results, hasMore, err := h.svc.SearchByTenant(r.Context(), tenantID, filters, pg.Limit, pg.Cursor)
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	var nextCursor string
+	if hasMore && len(results) > 0 {
+		nextCursor = results[len(results)-1].ID
+	}
+	response.WritePaginated(w, http.StatusOK, results, nextCursor, hasMore)
+}
+
 // Create godoc
 //
 //	@Summary		Create a zone record
@@ -85,6 +143,10 @@ func (h *ZoneRecord) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "zones", zoneID) {
+		return
+	}
+
 	ttl := req.TTL
 	if ttl == 0 {
 		ttl = 3600
@@ -113,6 +175,147 @@ func (h *ZoneRecord) Create(w http.ResponseWriter, r *http.Request) {
 	response.WriteJSON(w, http.StatusAccepted, record)
 }
 
+// ReplaceByZone godoc
+//
+//	@Summary		Replace a zone's records
+//	@Description	Makes the zone's custom and template records match the given set. The diff is computed server-side, matching records by type, name and content: new records are created as custom, matched records with a different TTL or priority are updated, and records not in the set are deleted. Auto-managed records are not affected and must not be included. All changes are applied to PowerDNS in one transaction that bumps the SOA serial once. Returns 202 with the diff and triggers a Temporal workflow, or 200 if nothing changed. Returns 409 while an earlier change to the zone's records is still in progress.
+//	@Tags			Zone Records
+//	@Security		ApiKeyAuth
+//	@Param			zoneID	path		string						true	"Zone ID"
+//	@Param			body	body		request.ReplaceZoneRecords	true	"Desired record set"
+//	@Success		200		{object}	model.ZoneRecordSetChange
+//	@Success		202		{object}	model.ZoneRecordSetChange
+//	@Failure		400		{object}	response.ErrorResponse
+//	@Failure		409		{object}	response.ErrorResponse
+//	@Failure		500		{object}	response.ErrorResponse
+//	@Router			/zones/{zoneID}/records [put]
+func (h *ZoneRecord) ReplaceByZone(w http.ResponseWriter, r *http.Request) {
+	zoneID, err := request.RequireID(chi.URLParam(r, "zoneID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.ReplaceZoneRecords
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	records, ok := zoneRecordSet(w, zoneID, req)
+	if !ok {
+		return
+	}
+
+	if !checkOwnership(w, r, h.ownership, "zones", zoneID) {
+		return
+	}
+
+	change, err := h.svc.ReplaceRecords(r.Context(), zoneID, records)
+	if errors.Is(err, core.ErrZoneRecordsBusy) {
+		response.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	if change.Empty() {
+		response.WriteJSON(w, http.StatusOK, change)
+		return
+	}
+	response.WriteJSON(w, http.StatusAccepted, change)
+}
+
+// PreviewByZone godoc
+//
+//	@Summary		Preview a replacement of a zone's records
+//	@Description	Returns the diff that PUT /zones/{zoneID}/records would apply for the given record set, computed the same way, without changing anything. Also returns the zone's current SOA serial and the serial after the change, which is bumped once if anything changes (null if the zone has no SOA record). Created records get new IDs when the set is applied. Returns 409 while an earlier change to the zone's records is still in progress, as the apply would.
+//	@Tags			Zone Records
+//	@Security		ApiKeyAuth
+//	@Param			zoneID	path		string						true	"Zone ID"
+//	@Param			body	body		request.ReplaceZoneRecords	true	"Desired record set"
+//	@Success		200		{object}	model.ZoneRecordSetPreview
+//	@Failure		400		{object}	response.ErrorResponse
+//	@Failure		409		{object}	response.ErrorResponse
+//	@Failure		500		{object}	response.ErrorResponse
+//	@Router			/zones/{zoneID}/records/preview [post]
+func (h *ZoneRecord) PreviewByZone(w http.ResponseWriter, r *http.Request) {
+	zoneID, err := request.RequireID(chi.URLParam(r, "zoneID"))
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req request.ReplaceZoneRecords
+	if err := request.Decode(r, &req); err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	records, ok := zoneRecordSet(w, zoneID, req)
+	if !ok {
+		return
+	}
+
+	if !checkOwnership(w, r, h.ownership, "zones", zoneID) {
+		return
+	}
+
+	preview, err := h.svc.PreviewRecords(r.Context(), zoneID, records)
+	if errors.Is(err, core.ErrZoneRecordsBusy) {
+		response.WriteError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		response.WriteServiceError(w, err)
+		return
+	}
+
+	response.WriteJSON(w, http.StatusOK, preview)
+}
+
+// zoneRecordSet validates a desired record set and builds its records as
+// new custom records, writing a 400 if it is invalid.
+func zoneRecordSet(w http.ResponseWriter, zoneID string, req request.ReplaceZoneRecords) ([]model.ZoneRecord, bool) {
+	now := time.Now()
+	seen := make(map[string]bool, len(req.Records))
+	records := make([]model.ZoneRecord, 0, len(req.Records))
+	for i, rec := range req.Records {
+		if err := request.ValidateZoneRecord(rec.Type, rec.Name, rec.Content, rec.Priority); err != nil {
+			response.WriteError(w, http.StatusBadRequest, fmt.Sprintf("records[%d]: %s", i, err.Error()))
+			return nil, false
+		}
+		key := rec.Type + " " + rec.Name + " " + rec.Content
+		if seen[key] {
+			response.WriteError(w, http.StatusBadRequest, fmt.Sprintf("records[%d]: duplicate record %s", i, key))
+			return nil, false
+		}
+		seen[key] = true
+
+		ttl := rec.TTL
+		if ttl == 0 {
+			ttl = 3600
+		}
+		records = append(records, model.ZoneRecord{
+			ID:        platform.NewID(),
+			ZoneID:    zoneID,
+			Type:      rec.Type,
+			Name:      rec.Name,
+			Content:   rec.Content,
+			TTL:       ttl,
+			Priority:  rec.Priority,
+			ManagedBy: model.ManagedByCustom,
+			Status:    model.StatusPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	}
+
+	return records, true
+}
+
 // Get godoc
 //
 //	@Summary		Get a zone record
@@ -131,6 +334,10 @@ func (h *ZoneRecord) Get(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "zone_records", id) {
+		return
+	}
+
 	record, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -166,6 +373,10 @@ func (h *ZoneRecord) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "zone_records", id) {
+		return
+	}
+
 	record, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -218,6 +429,10 @@ func (h *ZoneRecord) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkOwnership(w, r, h.ownership, "zone_records", id) {
+		return
+	}
+
 	record, err := h.svc.GetByID(r.Context(), id)
 	if err != nil {
 		response.WriteError(w, http.StatusNotFound, err.Error())
@@ -253,6 +468,9 @@ func (h *ZoneRecord) Retry(w http.ResponseWriter, r *http.Request) {
 		response.WriteError(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if !checkOwnership(w, r, h.ownership, "zone_records", id) {
+		return
+	}
 	if err := h.svc.Retry(r.Context(), id); err != nil {
 		response.WriteServiceError(w, err)
 		return
@@ -11,7 +11,22 @@ import (
 )
 
 func newZoneRecordHandler() *ZoneRecord {
-	return NewZoneRecord(nil)
+	return NewZoneRecord(nil, nil, nil)
+}
+
+// --- SearchByTenant ---
+
+func TestZoneRecordSearchByTenant_EmptyID(t *testing.T) {
+	h := newZoneRecordHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/tenants//zone-records/search?type=A", nil)
+	r = withChiURLParam(r, "tenantID", "")
+
+	h.SearchByTenant(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
 }
 
 // --- ListByZone ---
@@ -307,6 +322,101 @@ func TestZoneRecordCreate_WithPriority(t *testing.T) {
 
 // --- Get ---
 
+// --- ReplaceByZone ---
+
+func TestZoneRecordReplaceByZone_EmptyZoneID(t *testing.T) {
+	h := newZoneRecordHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/zones//records", map[string]any{"records": []any{}})
+	r = withChiURLParam(r, "zoneID", "")
+
+	h.ReplaceByZone(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestZoneRecordReplaceByZone_MissingRecords(t *testing.T) {
+	h := newZoneRecordHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/zones/"+validID+"/records", map[string]any{})
+	r = withChiURLParam(r, "zoneID", validID)
+
+	h.ReplaceByZone(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "validation error")
+}
+
+func TestZoneRecordReplaceByZone_InvalidRecord(t *testing.T) {
+	h := newZoneRecordHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/zones/"+validID+"/records", map[string]any{
+		"records": []map[string]any{
+			{"type": "A", "name": "www.example.com", "content": "not-an-ip"},
+		},
+	})
+	r = withChiURLParam(r, "zoneID", validID)
+
+	h.ReplaceByZone(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "records[0]")
+}
+
+func TestZoneRecordReplaceByZone_DuplicateRecord(t *testing.T) {
+	h := newZoneRecordHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/zones/"+validID+"/records", map[string]any{
+		"records": []map[string]any{
+			{"type": "A", "name": "www.example.com", "content": "1.2.3.4"},
+			{"type": "A", "name": "www.example.com", "content": "1.2.3.4", "ttl": 300},
+		},
+	})
+	r = withChiURLParam(r, "zoneID", validID)
+
+	h.ReplaceByZone(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "records[1]: duplicate record")
+}
+
+// --- PreviewByZone ---
+
+func TestZoneRecordPreviewByZone_EmptyZoneID(t *testing.T) {
+	h := newZoneRecordHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/zones//records/preview", map[string]any{"records": []any{}})
+	r = withChiURLParam(r, "zoneID", "")
+
+	h.PreviewByZone(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "missing required ID")
+}
+
+func TestZoneRecordPreviewByZone_InvalidRecord(t *testing.T) {
+	h := newZoneRecordHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPost, "/zones/"+validID+"/records/preview", map[string]any{
+		"records": []map[string]any{
+			{"type": "A", "name": "www.example.com", "content": "not-an-ip"},
+		},
+	})
+	r = withChiURLParam(r, "zoneID", validID)
+
+	h.PreviewByZone(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "records[0]")
+}
+
 func TestZoneRecordGet_EmptyID(t *testing.T) {
 	h := newZoneRecordHandler()
 	rec := httptest.NewRecorder()
@@ -178,6 +178,19 @@ func TestZoneUpdate_InvalidJSON(t *testing.T) {
 	assert.Contains(t, body["error"], "invalid JSON")
 }
 
+func TestZoneUpdate_TooManySecondaryRegions(t *testing.T) {
+	h := newZoneHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodPut, "/zones/"+validID, map[string]any{
+		"secondary_region_ids": []string{"a", "b", "c", "d", "e", "f"},
+	})
+	r = withChiURLParam(r, "id", validID)
+
+	h.Update(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
 func TestZoneUpdate_EmptyBody(t *testing.T) {
 	h := newZoneHandler()
 	rec := httptest.NewRecorder()
@@ -204,6 +217,56 @@ func TestZoneDelete_EmptyID(t *testing.T) {
 	assert.Contains(t, body["error"], "missing required ID")
 }
 
+// --- DNSSEC ---
+
+func TestZoneDNSSEC_EmptyID(t *testing.T) {
+	h := newZoneHandler()
+	handlers := map[string]http.HandlerFunc{
+		http.MethodGet:    h.GetDNSSEC,
+		http.MethodPost:   h.Sign,
+		http.MethodDelete: h.Unsign,
+	}
+	for method, handle := range handlers {
+		t.Run(method, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			r := newRequest(method, "/zones//dnssec", nil)
+			r = withChiURLParam(r, "id", "")
+
+			handle(rec, r)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			body := decodeErrorResponse(rec)
+			assert.Contains(t, body["error"], "missing required ID")
+		})
+	}
+}
+
+// --- Export ---
+
+func TestZoneExport_InvalidFormat(t *testing.T) {
+	h := newZoneHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/zones/zone-1/export?format=yaml", nil)
+	r = withChiURLParam(r, "id", "zone-1")
+
+	h.Export(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	body := decodeErrorResponse(rec)
+	assert.Contains(t, body["error"], "format must be bind or json")
+}
+
+func TestZoneExport_EmptyID(t *testing.T) {
+	h := newZoneHandler()
+	rec := httptest.NewRecorder()
+	r := newRequest(http.MethodGet, "/zones//export", nil)
+	r = withChiURLParam(r, "id", "")
+
+	h.Export(rec, r)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
 // --- Error response format ---
 
 func TestZoneCreate_ErrorResponseFormat(t *testing.T) {
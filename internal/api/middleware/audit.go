@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strings"
@@ -17,10 +18,12 @@ type AuditLogger struct {
 	pool   *pgxpool.Pool
 	logger zerolog.Logger
 	ch     chan auditEntry
+	done   chan struct{}
 }
 
 type auditEntry struct {
 	APIKeyID     *string
+	TenantID     *string
 	Method       string
 	Path         string
 	ResourceType *string
@@ -34,19 +37,21 @@ func NewAuditLogger(pool *pgxpool.Pool, logger zerolog.Logger) *AuditLogger {
 		pool:   pool,
 		logger: logger,
 		ch:     make(chan auditEntry, 1024),
+		done:   make(chan struct{}),
 	}
 	go al.drain()
 	return al
 }
 
 func (al *AuditLogger) drain() {
+	defer close(al.done)
 	for entry := range al.ch {
 		_, err := al.pool.Exec(
 			// use context.Background since this is async
 			context.Background(),
-			`INSERT INTO audit_logs (api_key_id, method, path, resource_type, resource_id, status_code, request_body, created_at)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7, now())`,
-			entry.APIKeyID, entry.Method, entry.Path, entry.ResourceType, entry.ResourceID, entry.StatusCode, entry.RequestBody,
+			`INSERT INTO audit_logs (api_key_id, tenant_id, method, path, resource_type, resource_id, status_code, request_body, created_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, now())`,
+			entry.APIKeyID, entry.TenantID, entry.Method, entry.Path, entry.ResourceType, entry.ResourceID, entry.StatusCode, entry.RequestBody,
 		)
 		if err != nil {
 			al.logger.Error().Err(err).Msg("failed to write audit log")
@@ -54,24 +59,68 @@ func (al *AuditLogger) drain() {
 	}
 }
 
-// Close drains remaining entries and closes the channel.
+// Close stops accepting entries and waits until the queued ones are
+// written. No requests may be served once it is called.
 func (al *AuditLogger) Close() {
 	close(al.ch)
+	<-al.done
 }
 
-// Middleware returns a chi middleware that logs mutating API requests.
+type auditStateKey struct{}
+
+// auditState is what handlers report about a request to the audit logger.
+type auditState struct {
+	read     bool
+	tenantID string
+}
+
+// AuditRead marks a read request for the audit log. Reads are not audited by
+// default; handlers call this for reads that expose secrets, such as private
+// key downloads, just before writing the successful response.
+func AuditRead(ctx context.Context) {
+	if audit, ok := ctx.Value(auditStateKey{}).(*auditState); ok {
+		audit.read = true
+	}
+}
+
+// AuditTenant records the tenant a request acts on in its audit entry, which
+// puts the request in the tenant's event feed. The tenant authorization
+// helpers call this; the first tenant reported wins.
+func AuditTenant(ctx context.Context, tenantID string) {
+	if audit, ok := ctx.Value(auditStateKey{}).(*auditState); ok && audit.tenantID == "" {
+		audit.tenantID = tenantID
+	}
+}
+
+// Middleware returns a chi middleware that logs mutating API requests, and
+// read requests marked with AuditRead.
 func (al *AuditLogger) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Only audit mutating operations.
+		audit := &auditState{}
+		r = r.WithContext(context.WithValue(r.Context(), auditStateKey{}, audit))
+
 		if r.Method != http.MethodPost && r.Method != http.MethodPut && r.Method != http.MethodDelete {
+			// The response writer is left unwrapped so streaming and
+			// websocket reads keep working.
 			next.ServeHTTP(w, r)
+			if audit.read {
+				al.record(r, http.StatusOK, nil)
+			}
 			return
 		}
 
-		// Read and re-buffer the request body.
+		// Read and re-buffer the request body. Binary uploads are streamed
+		// to the handler untouched; their content is never logged anyway.
 		var bodyBytes []byte
-		if r.Body != nil {
-			bodyBytes, _ = io.ReadAll(r.Body)
+		if r.Body != nil && !isStreamedUpload(r) {
+			var err error
+			bodyBytes, err = io.ReadAll(r.Body)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				WriteBodyTooLarge(w, tooLarge.Limit)
+				al.record(r, http.StatusRequestEntityTooLarge, nil)
+				return
+			}
 			r.Body = io.NopCloser(bytes.NewBuffer(bodyBytes))
 		}
 
@@ -79,38 +128,69 @@ func (al *AuditLogger) Middleware(next http.Handler) http.Handler {
 		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
 		next.ServeHTTP(sw, r)
 
-		// Extract resource info from path.
-		resourceType, resourceID := extractResource(r.URL.Path)
-
-		// Get API key ID from context.
-		var apiKeyID *string
-		if id, ok := r.Context().Value(APIKeyIDKey).(string); ok {
-			apiKeyID = &id
-		}
-
 		// Sanitize body - don't log passwords or keys.
 		var sanitizedBody json.RawMessage
 		if len(bodyBytes) > 0 && json.Valid(bodyBytes) {
 			sanitizedBody = sanitizeBody(bodyBytes)
 		}
 
-		// Send to async writer.
-		select {
-		case al.ch <- auditEntry{
-			APIKeyID:     apiKeyID,
-			Method:       r.Method,
-			Path:         r.URL.Path,
-			ResourceType: resourceType,
-			ResourceID:   resourceID,
-			StatusCode:   sw.status,
-			RequestBody:  sanitizedBody,
-		}:
-		default:
-			al.logger.Warn().Msg("audit log buffer full, dropping entry")
-		}
+		al.record(r, sw.status, sanitizedBody)
 	})
 }
 
+// isStreamedUpload reports whether the request carries a binary or multipart
+// upload, which handlers read as a stream (see request.SpoolBody).
+func isStreamedUpload(r *http.Request) bool {
+	ct := r.Header.Get("Content-Type")
+	return strings.HasPrefix(ct, "application/octet-stream") || strings.HasPrefix(ct, "multipart/")
+}
+
+// record queues an audit entry for a handled request.
+func (al *AuditLogger) record(r *http.Request, status int, body json.RawMessage) {
+	// Get API key ID from context.
+	var apiKeyID *string
+	if id, ok := r.Context().Value(APIKeyIDKey).(string); ok {
+		apiKeyID = &id
+	}
+	var tenantID *string
+	if audit, ok := r.Context().Value(auditStateKey{}).(*auditState); ok && audit.tenantID != "" {
+		tenantID = &audit.tenantID
+	}
+	al.enqueue(r, apiKeyID, tenantID, status, body)
+}
+
+// RecordAs queues an audit entry for a request served outside the
+// authenticated API, attributing it to apiKeyID. Signed backup downloads use
+// this to log the key that requested the download URL.
+func (al *AuditLogger) RecordAs(r *http.Request, apiKeyID string, status int) {
+	var keyID *string
+	if apiKeyID != "" {
+		keyID = &apiKeyID
+	}
+	al.enqueue(r, keyID, nil, status, nil)
+}
+
+func (al *AuditLogger) enqueue(r *http.Request, apiKeyID, tenantID *string, status int, body json.RawMessage) {
+	// Extract resource info from path.
+	resourceType, resourceID := extractResource(r.URL.Path)
+
+	// Send to async writer.
+	select {
+	case al.ch <- auditEntry{
+		APIKeyID:     apiKeyID,
+		TenantID:     tenantID,
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		StatusCode:   status,
+		RequestBody:  body,
+	}:
+	default:
+		al.logger.Warn().Msg("audit log buffer full, dropping entry")
+	}
+}
+
 func extractResource(path string) (*string, *string) {
 	// Extract the last resource type and optional ID from the path.
 	// e.g., /api/v1/tenants -> type=tenants
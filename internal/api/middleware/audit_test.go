@@ -2,9 +2,13 @@ package middleware
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestExtractResource_SimplePath(t *testing.T) {
@@ -47,3 +51,72 @@ func TestSanitizeBody(t *testing.T) {
 	assert.Equal(t, "[REDACTED]", result["password"])
 	assert.Equal(t, "[REDACTED]", result["key_pem"])
 }
+
+func TestAuditRead_MarksRequest(t *testing.T) {
+	al := &AuditLogger{ch: make(chan auditEntry, 1)}
+	h := al.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("include_key") == "true" {
+			AuditRead(r.Context())
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/fqdns/abc/certificate", nil))
+	assert.Len(t, al.ch, 0)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/fqdns/abc/certificate?include_key=true", nil))
+	require.Len(t, al.ch, 1)
+	entry := <-al.ch
+	assert.Equal(t, http.MethodGet, entry.Method)
+	assert.Equal(t, "/api/v1/fqdns/abc/certificate", entry.Path)
+	assert.Equal(t, http.StatusOK, entry.StatusCode)
+}
+
+func TestAuditTenant_RecordsTenant(t *testing.T) {
+	al := &AuditLogger{ch: make(chan auditEntry, 2)}
+	h := al.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/brands" {
+			AuditTenant(r.Context(), "t-1")
+			AuditTenant(r.Context(), "t-2")
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/api/v1/webroots/abc", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/brands", nil))
+	require.Len(t, al.ch, 2)
+	entry := <-al.ch
+	require.NotNil(t, entry.TenantID)
+	assert.Equal(t, "t-1", *entry.TenantID)
+	assert.Nil(t, (<-al.ch).TenantID)
+}
+
+func TestAuditMiddleware_BodyTooLarge(t *testing.T) {
+	al := &AuditLogger{ch: make(chan auditEntry, 1)}
+	called := false
+	h := MaxBodySize(16, 16)(al.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tenants", strings.NewReader(strings.Repeat("x", 100)))
+	req.ContentLength = -1
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.False(t, called)
+	require.Len(t, al.ch, 1)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, (<-al.ch).StatusCode)
+}
+
+func TestAuditRecordAs(t *testing.T) {
+	al := &AuditLogger{ch: make(chan auditEntry, 1)}
+	al.RecordAs(httptest.NewRequest(http.MethodGet, "/api/v1/backups/abc/download?token=secret", nil), "key-1", http.StatusOK)
+
+	require.Len(t, al.ch, 1)
+	entry := <-al.ch
+	require.NotNil(t, entry.APIKeyID)
+	assert.Equal(t, "key-1", *entry.APIKeyID)
+	assert.Equal(t, "/api/v1/backups/abc/download", entry.Path)
+	assert.Equal(t, http.StatusOK, entry.StatusCode)
+}
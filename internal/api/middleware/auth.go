@@ -17,10 +17,13 @@ type contextKey string
 const APIKeyIdentityKey contextKey = "api_key_identity"
 
 // APIKeyIdentity holds the authenticated key's ID, scopes, and brand access.
+// ResellerID is set for keys bound to a reseller, which only reach that
+// reseller's tenants.
 type APIKeyIdentity struct {
-	ID     string
-	Scopes []string
-	Brands []string
+	ID         string
+	Scopes     []string
+	Brands     []string
+	ResellerID string
 }
 
 // APIKeyIDKey is kept for backward compatibility (audit logger).
@@ -50,13 +53,17 @@ func Auth(pool *pgxpool.Pool) func(http.Handler) http.Handler {
 
 			var identity APIKeyIdentity
 			err := pool.QueryRow(r.Context(),
-				`SELECT id, scopes, brands FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL`, keyHash,
-			).Scan(&identity.ID, &identity.Scopes, &identity.Brands)
+				`SELECT k.id, k.scopes, CASE WHEN r.id IS NULL THEN k.brands ELSE ARRAY[r.brand_id] END, COALESCE(k.reseller_id, '')
+				 FROM api_keys k
+				 LEFT JOIN resellers r ON r.id = k.reseller_id
+				 WHERE k.key_hash = $1 AND k.revoked_at IS NULL`, keyHash,
+			).Scan(&identity.ID, &identity.Scopes, &identity.Brands, &identity.ResellerID)
 			if err != nil {
 				response.WriteError(w, http.StatusUnauthorized, "invalid API key")
 				return
 			}
 
+			setRequestAPIKeyID(r.Context(), identity.ID)
 			ctx := context.WithValue(r.Context(), APIKeyIdentityKey, &identity)
 			ctx = context.WithValue(ctx, APIKeyIDKey, identity.ID)
 			next.ServeHTTP(w, r.WithContext(ctx))
@@ -40,6 +40,24 @@ func HasBrandAccess(identity *APIKeyIdentity, brandID string) bool {
 	return false
 }
 
+// HasTenantAccess checks if the identity can access a tenant with the given
+// brand and reseller. Reseller-scoped keys additionally require the tenant to
+// belong to their reseller.
+func HasTenantAccess(identity *APIKeyIdentity, brandID string, resellerID *string) bool {
+	if !HasBrandAccess(identity, brandID) {
+		return false
+	}
+	if identity.ResellerID == "" {
+		return true
+	}
+	return resellerID != nil && *resellerID == identity.ResellerID
+}
+
+// IsResellerScoped checks if the identity is bound to a reseller.
+func IsResellerScoped(identity *APIKeyIdentity) bool {
+	return identity != nil && identity.ResellerID != ""
+}
+
 // IsPlatformAdmin checks if the identity has wildcard brand access.
 func IsPlatformAdmin(identity *APIKeyIdentity) bool {
 	if identity == nil {
@@ -81,6 +99,20 @@ func RequirePlatformAdmin() func(http.Handler) http.Handler {
 	}
 }
 
+// RequireBrandLevel returns middleware that rejects reseller-scoped keys, for
+// endpoints that act on a brand as a whole rather than on individual tenants.
+func RequireBrandLevel() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsResellerScoped(GetIdentity(r.Context())) {
+				response.WriteError(w, http.StatusForbidden, "not available to reseller API keys")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // BrandIDs returns the identity's brand list for use in query filtering.
 // Returns nil if the identity is a platform admin (wildcard).
 func BrandIDs(ctx context.Context) []string {
@@ -95,3 +127,13 @@ func BrandIDs(ctx context.Context) []string {
 	}
 	return identity.Brands
 }
+
+// ResellerID returns the identity's reseller for use in query filtering.
+// Returns "" for keys that are not bound to a reseller.
+func ResellerID(ctx context.Context) string {
+	identity := GetIdentity(ctx)
+	if identity == nil {
+		return ""
+	}
+	return identity.ResellerID
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasTenantAccess(t *testing.T) {
+	reseller := "reseller-1"
+	other := "reseller-2"
+	brandKey := &APIKeyIdentity{Brands: []string{"acme"}}
+	resellerKey := &APIKeyIdentity{Brands: []string{"acme"}, ResellerID: reseller}
+
+	assert.True(t, HasTenantAccess(brandKey, "acme", nil))
+	assert.True(t, HasTenantAccess(brandKey, "acme", &reseller))
+	assert.False(t, HasTenantAccess(brandKey, "other", nil))
+
+	assert.True(t, HasTenantAccess(resellerKey, "acme", &reseller))
+	assert.False(t, HasTenantAccess(resellerKey, "acme", &other))
+	assert.False(t, HasTenantAccess(resellerKey, "acme", nil))
+	assert.False(t, HasTenantAccess(resellerKey, "other", &reseller))
+
+	assert.False(t, HasTenantAccess(nil, "acme", nil))
+}
+
+func TestRequireBrandLevel(t *testing.T) {
+	handler := RequireBrandLevel()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for _, tc := range []struct {
+		name     string
+		identity *APIKeyIdentity
+		want     int
+	}{
+		{"brand key", &APIKeyIdentity{Brands: []string{"acme"}}, http.StatusOK},
+		{"reseller key", &APIKeyIdentity{Brands: []string{"acme"}, ResellerID: "reseller-1"}, http.StatusForbidden},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/brands", nil)
+			req = req.WithContext(context.WithValue(req.Context(), APIKeyIdentityKey, tc.identity))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tc.want, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/edvin/hosting/internal/api/response"
+)
+
+// uploadPaths are the endpoints that accept certificate bundles, which may
+// exceed the default body limit and get the upload limit instead.
+var uploadPaths = []*regexp.Regexp{
+	regexp.MustCompile(`^/api/v1/fqdns/[^/]+/certificates?$`),
+}
+
+// MaxBodySize returns a middleware that caps request bodies at limit bytes,
+// or uploadLimit bytes for certificate upload and import endpoints. Requests
+// whose Content-Length already exceeds the limit are rejected with 413 before
+// any of the body is read; chunked bodies fail with an *http.MaxBytesError
+// once the limit is crossed. A limit of 0 disables the check.
+func MaxBodySize(limit, uploadLimit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			max := limit
+			for _, re := range uploadPaths {
+				if re.MatchString(r.URL.Path) {
+					max = uploadLimit
+					break
+				}
+			}
+			if max <= 0 || r.Body == nil || r.Body == http.NoBody {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if r.ContentLength > max {
+				WriteBodyTooLarge(w, max)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, max)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WriteBodyTooLarge writes the 413 response for a body over limit bytes.
+func WriteBodyTooLarge(w http.ResponseWriter, limit int64) {
+	response.WriteError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", limit))
+}
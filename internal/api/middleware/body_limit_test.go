@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// serveLimited runs a handler that reads the whole body behind MaxBodySize
+// and returns the response status.
+func serveLimited(t *testing.T, limit, uploadLimit int64, req *http.Request) int {
+	t.Helper()
+	h := MaxBodySize(limit, uploadLimit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			WriteBodyTooLarge(w, limit)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestMaxBodySize_UnderLimit(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tenants", strings.NewReader(`{"name":"t"}`))
+	assert.Equal(t, http.StatusOK, serveLimited(t, 64, 1024, req))
+}
+
+func TestMaxBodySize_ContentLengthOverLimit(t *testing.T) {
+	called := false
+	h := MaxBodySize(16, 1024)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/tenants", strings.NewReader(strings.Repeat("x", 17))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.False(t, called, "handler must not run for an oversized body")
+}
+
+func TestMaxBodySize_ChunkedOverLimit(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tenants", strings.NewReader(strings.Repeat("x", 100)))
+	req.ContentLength = -1
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serveLimited(t, 16, 1024, req))
+}
+
+func TestMaxBodySize_UploadPathUsesUploadLimit(t *testing.T) {
+	body := strings.Repeat("x", 100)
+	for _, path := range []string{"/api/v1/fqdns/abc/certificate", "/api/v1/fqdns/abc/certificates"} {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		assert.Equal(t, http.StatusOK, serveLimited(t, 16, 1024, req), path)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/fqdns/abc/dkim", strings.NewReader(body))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serveLimited(t, 16, 1024, req))
+}
+
+func TestMaxBodySize_ZeroDisables(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tenants", strings.NewReader(strings.Repeat("x", 100)))
+	assert.Equal(t, http.StatusOK, serveLimited(t, 0, 0, req))
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSPolicy configures which browser origins may call the API.
+type CORSPolicy struct {
+	// AllowedOrigins are normalized origins (scheme://host[:port]), or the
+	// single entry "*" for any origin. Empty means same-origin only.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	AllowCredentials bool
+	MaxAgeSecs       int
+}
+
+// corsExposedHeaders are response headers browser clients may read.
+var corsExposedHeaders = strings.Join([]string{RequestIDHeader, OperationIDHeader, IdempotentReplayedHeader, "Content-Disposition"}, ", ")
+
+// CORS returns a middleware that applies policy to cross-origin requests.
+// Requests without an Origin header, or from origins not in the allowlist,
+// pass through without CORS headers, so the browser blocks the response.
+// Preflight requests from allowed origins are answered with 204 before
+// authentication runs, since browsers never send the Authorization header on
+// a preflight. Credentials are only ever allowed for an explicitly listed
+// origin, never for the "*" wildcard.
+func CORS(policy CORSPolicy) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(policy.AllowedOrigins))
+	anyOrigin := false
+	for _, o := range policy.AllowedOrigins {
+		if o == "*" {
+			anyOrigin = true
+			continue
+		}
+		allowed[strings.ToLower(o)] = true
+	}
+	methods := strings.Join(policy.AllowedMethods, ", ")
+	headers := strings.Join(policy.AllowedHeaders, ", ")
+	maxAge := strconv.Itoa(policy.MaxAgeSecs)
+
+	return func(next http.Handler) http.Handler {
+		if !anyOrigin && len(allowed) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Add("Vary", "Origin")
+
+			explicit := allowed[strings.ToLower(origin)]
+			if !explicit && !anyOrigin {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h := w.Header()
+			if explicit {
+				h.Set("Access-Control-Allow-Origin", origin)
+				if policy.AllowCredentials {
+					h.Set("Access-Control-Allow-Credentials", "true")
+				}
+			} else {
+				h.Set("Access-Control-Allow-Origin", "*")
+			}
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				h.Add("Vary", "Access-Control-Request-Method")
+				h.Add("Vary", "Access-Control-Request-Headers")
+				h.Set("Access-Control-Allow-Methods", methods)
+				h.Set("Access-Control-Allow-Headers", headers)
+				if policy.MaxAgeSecs > 0 {
+					h.Set("Access-Control-Max-Age", maxAge)
+				}
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			h.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
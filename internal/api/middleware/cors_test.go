@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testCORSPolicy = CORSPolicy{
+	AllowedOrigins: []string{"https://dash.example.com"},
+	AllowedMethods: []string{"GET", "POST"},
+	AllowedHeaders: []string{"Authorization", "Content-Type"},
+	MaxAgeSecs:     600,
+}
+
+// serveCORS runs req through CORS(policy) and reports whether the wrapped
+// handler was reached.
+func serveCORS(policy CORSPolicy, req *http.Request) (*httptest.ResponseRecorder, bool) {
+	called := false
+	h := CORS(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec, called
+}
+
+func preflight(origin string) *http.Request {
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/tenants", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "authorization")
+	return req
+}
+
+func TestCORS_UnconfiguredSendsNoHeaders(t *testing.T) {
+	rec, called := serveCORS(CORSPolicy{}, preflight("https://dash.example.com"))
+	assert.True(t, called)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORS_PreflightAllowedOrigin(t *testing.T) {
+	rec, called := serveCORS(testCORSPolicy, preflight("https://dash.example.com"))
+	assert.False(t, called, "preflight must be answered before auth")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://dash.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization, Content-Type", rec.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_PreflightDisallowedOrigin(t *testing.T) {
+	rec, called := serveCORS(testCORSPolicy, preflight("https://evil.example.com"))
+	assert.True(t, called)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "Origin", rec.Header().Get("Vary"))
+}
+
+func TestCORS_SimpleRequestExposesHeaders(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tenants", nil)
+	req.Header.Set("Origin", "https://DASH.example.com")
+	rec, called := serveCORS(testCORSPolicy, req)
+	assert.True(t, called)
+	assert.Equal(t, "https://DASH.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	exposed := rec.Header().Get("Access-Control-Expose-Headers")
+	for _, h := range []string{RequestIDHeader, OperationIDHeader, IdempotentReplayedHeader, "Content-Disposition"} {
+		assert.Contains(t, exposed, h)
+	}
+}
+
+func TestCORS_CredentialsOnlyForExplicitOrigins(t *testing.T) {
+	policy := testCORSPolicy
+	policy.AllowCredentials = true
+	rec, _ := serveCORS(policy, preflight("https://dash.example.com"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	policy.AllowedOrigins = []string{"*"}
+	rec, _ = serveCORS(policy, preflight("https://dash.example.com"))
+	assert.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORS_NonPreflightOptionsPassesThrough(t *testing.T) {
+	req := httptest.NewRequest(http.MethodOptions, "/api/v1/tenants", nil)
+	req.Header.Set("Origin", "https://dash.example.com")
+	_, called := serveCORS(testCORSPolicy, req)
+	assert.True(t, called)
+}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/rs/zerolog"
+
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// IdempotencyKeyHeader lets clients retry a POST without repeating its effect.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set to "true" on responses replayed from a
+// stored Idempotency-Key.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+const maxIdempotencyKeyLen = 255
+
+// replayedHeaders are the response headers stored with an idempotency key
+// and sent again on replay.
+var replayedHeaders = []string{"Content-Type", "Location", OperationIDHeader}
+
+// idempotencyStateKey holds the *idempotencyState of a request handled by
+// Idempotency.
+const idempotencyStateKey contextKey = "idempotency_state"
+
+// idempotencyState is shared between Idempotency and route middleware.
+type idempotencyState struct {
+	secret bool
+}
+
+// IdempotencyStore claims idempotency keys and stores their responses. It is
+// implemented by core.IdempotencyService.
+type IdempotencyStore interface {
+	Claim(ctx context.Context, apiKeyID, key, requestHash string) (*model.IdempotencyKey, error)
+	Complete(ctx context.Context, apiKeyID, key string, statusCode int, headers http.Header, body []byte, withheld bool) error
+	Release(ctx context.Context, apiKeyID, key string) error
+}
+
+// Idempotency returns a middleware that makes POST requests carrying an
+// Idempotency-Key header safe to retry. Keys are scoped to the API key. The
+// first request with a key runs normally and its response is stored; a
+// retry with the same key and request gets the stored response instead of
+// running again. 5xx responses are not stored, so those can be retried.
+// Successful responses of routes wrapped in SecretResponse are stored
+// without their body and a retry gets 409 instead of the credentials.
+// It must run after Auth, and after Operations so X-Operation-ID headers are
+// captured.
+func Idempotency(store IdempotencyStore, logger zerolog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			apiKeyID, _ := r.Context().Value(APIKeyIDKey).(string)
+			// Streamed uploads are not buffered, so they cannot be hashed.
+			if r.Method != http.MethodPost || key == "" || apiKeyID == "" || isStreamedUpload(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				response.WriteError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+
+			var body []byte
+			var err error
+			if r.Body != nil {
+				body, err = io.ReadAll(r.Body)
+			}
+			if err != nil {
+				var maxErr *http.MaxBytesError
+				if errors.As(err, &maxErr) {
+					WriteBodyTooLarge(w, maxErr.Limit)
+					return
+				}
+				response.WriteError(w, http.StatusBadRequest, "failed to read request body")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			stored, err := store.Claim(r.Context(), apiKeyID, key, requestHash(r, body))
+			switch {
+			case errors.Is(err, core.ErrIdempotencyKeyInProgress):
+				response.WriteError(w, http.StatusConflict, err.Error())
+				return
+			case errors.Is(err, core.ErrIdempotencyKeyReused):
+				response.WriteError(w, http.StatusUnprocessableEntity, err.Error())
+				return
+			case err != nil:
+				logger.Error().Err(err).Msg("claim idempotency key")
+				response.WriteError(w, http.StatusInternalServerError, "internal server error")
+				return
+			}
+			if stored != nil {
+				replay(w, stored)
+				return
+			}
+
+			// The claim is released unless a response is stored, including
+			// when the handler panics.
+			ctx := context.WithoutCancel(r.Context())
+			completed := false
+			defer func() {
+				if !completed {
+					if err := store.Release(ctx, apiKeyID, key); err != nil {
+						logger.Error().Err(err).Msg("release idempotency key")
+					}
+				}
+			}()
+
+			state := &idempotencyState{}
+			r = r.WithContext(context.WithValue(r.Context(), idempotencyStateKey, state))
+			rec := &idempotencyRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+			if rec.status >= http.StatusInternalServerError {
+				return
+			}
+
+			headers := http.Header{}
+			for _, h := range replayedHeaders {
+				if v := w.Header().Values(h); len(v) > 0 {
+					headers[h] = v
+				}
+			}
+			withheld := state.secret && rec.status < http.StatusMultipleChoices
+			if err := store.Complete(ctx, apiKeyID, key, rec.status, headers, rec.body.Bytes(), withheld); err != nil {
+				logger.Error().Err(err).Msg("store idempotent response")
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// SecretResponse marks a route whose successful responses carry credentials,
+// such as raw API keys, passwords or secret access keys. Idempotency does
+// not store their body, so the credentials are never persisted; a retry with
+// the same key gets 409 with the stored Location and X-Operation-ID headers.
+func SecretResponse(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if state, ok := r.Context().Value(idempotencyStateKey).(*idempotencyState); ok {
+			state.secret = true
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requestHash identifies a request by method, path with query, and body, so
+// a key reused for a different request is detected.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// replay writes a stored response. A withheld response is answered with 409
+// and the stored headers only.
+func replay(w http.ResponseWriter, stored *model.IdempotencyKey) {
+	for h, values := range stored.ResponseHeaders {
+		if stored.ResponseWithheld && h == "Content-Type" {
+			continue
+		}
+		for _, v := range values {
+			w.Header().Add(h, v)
+		}
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	if stored.ResponseWithheld {
+		response.WriteError(w, http.StatusConflict, "the request already succeeded; its response contained credentials and is not replayed")
+		return
+	}
+	w.WriteHeader(*stored.StatusCode)
+	w.Write(stored.ResponseBody)
+}
+
+// idempotencyRecorder passes the response through while keeping a copy of
+// its status and body.
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *idempotencyRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.wroteHeader = true
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *idempotencyRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
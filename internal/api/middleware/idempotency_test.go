@@ -0,0 +1,201 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// memIdempotencyStore is an in-memory IdempotencyStore.
+type memIdempotencyStore struct {
+	keys map[string]*model.IdempotencyKey
+}
+
+func newMemIdempotencyStore() *memIdempotencyStore {
+	return &memIdempotencyStore{keys: map[string]*model.IdempotencyKey{}}
+}
+
+func (s *memIdempotencyStore) Claim(_ context.Context, apiKeyID, key, requestHash string) (*model.IdempotencyKey, error) {
+	k, ok := s.keys[apiKeyID+"/"+key]
+	if !ok {
+		s.keys[apiKeyID+"/"+key] = &model.IdempotencyKey{APIKeyID: apiKeyID, Key: key, RequestHash: requestHash}
+		return nil, nil
+	}
+	if k.RequestHash != requestHash {
+		return nil, core.ErrIdempotencyKeyReused
+	}
+	if k.StatusCode == nil {
+		return nil, core.ErrIdempotencyKeyInProgress
+	}
+	return k, nil
+}
+
+func (s *memIdempotencyStore) Complete(_ context.Context, apiKeyID, key string, statusCode int, headers http.Header, body []byte, withheld bool) error {
+	k := s.keys[apiKeyID+"/"+key]
+	k.StatusCode = &statusCode
+	k.ResponseHeaders = headers
+	if !withheld {
+		k.ResponseBody = body
+	}
+	k.ResponseWithheld = withheld
+	return nil
+}
+
+func (s *memIdempotencyStore) Release(_ context.Context, apiKeyID, key string) error {
+	if k, ok := s.keys[apiKeyID+"/"+key]; ok && k.StatusCode == nil {
+		delete(s.keys, apiKeyID+"/"+key)
+	}
+	return nil
+}
+
+// countingCreate is a create handler that counts how often it ran.
+func countingCreate(calls *int, status int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add(OperationIDHeader, "op-1")
+		w.WriteHeader(status)
+		w.Write([]byte(`{"id":"t-1"}`))
+	})
+}
+
+func idempotentRequest(apiKeyID, key, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/tenants", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		r.Header.Set(IdempotencyKeyHeader, key)
+	}
+	return r.WithContext(context.WithValue(r.Context(), APIKeyIDKey, apiKeyID))
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemIdempotencyStore(), zerolog.Nop())(countingCreate(&calls, http.StatusAccepted))
+
+	first := httptest.NewRecorder()
+	h.ServeHTTP(first, idempotentRequest("key-1", "abc", `{"name":"t"}`))
+	require.Equal(t, http.StatusAccepted, first.Code)
+	assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+	second := httptest.NewRecorder()
+	h.ServeHTTP(second, idempotentRequest("key-1", "abc", `{"name":"t"}`))
+
+	assert.Equal(t, 1, calls, "retry must not run the handler again")
+	assert.Equal(t, http.StatusAccepted, second.Code)
+	assert.Equal(t, `{"id":"t-1"}`, second.Body.String())
+	assert.Equal(t, "application/json", second.Header().Get("Content-Type"))
+	assert.Equal(t, "op-1", second.Header().Get(OperationIDHeader))
+	assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotency_KeysScopedPerAPIKey(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemIdempotencyStore(), zerolog.Nop())(countingCreate(&calls, http.StatusAccepted))
+
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", "abc", `{"name":"t"}`))
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-2", "abc", `{"name":"t"}`))
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_DifferentBodyRejected(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemIdempotencyStore(), zerolog.Nop())(countingCreate(&calls, http.StatusAccepted))
+
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", "abc", `{"name":"t"}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, idempotentRequest("key-1", "abc", `{"name":"other"}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotency_InProgressConflict(t *testing.T) {
+	store := newMemIdempotencyStore()
+	var inner *httptest.ResponseRecorder
+	var h http.Handler
+	h = Idempotency(store, zerolog.Nop())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A concurrent retry while this request is still running.
+		inner = httptest.NewRecorder()
+		h.ServeHTTP(inner, idempotentRequest("key-1", "abc", `{}`))
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", "abc", `{}`))
+	require.NotNil(t, inner)
+	assert.Equal(t, http.StatusConflict, inner.Code)
+}
+
+func TestIdempotency_ServerErrorNotStored(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemIdempotencyStore(), zerolog.Nop())(countingCreate(&calls, http.StatusInternalServerError))
+
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", "abc", `{}`))
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", "abc", `{}`))
+
+	assert.Equal(t, 2, calls, "a 5xx must be retryable with the same key")
+}
+
+func TestIdempotency_WithoutHeaderPassesThrough(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemIdempotencyStore(), zerolog.Nop())(countingCreate(&calls, http.StatusAccepted))
+
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", "", `{}`))
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", "", `{}`))
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotency_KeyTooLong(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemIdempotencyStore(), zerolog.Nop())(countingCreate(&calls, http.StatusAccepted))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, idempotentRequest("key-1", strings.Repeat("k", 256), `{}`))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, 0, calls)
+}
+
+func TestIdempotency_SecretResponseWithheld(t *testing.T) {
+	calls := 0
+	store := newMemIdempotencyStore()
+	h := Idempotency(store, zerolog.Nop())(SecretResponse(countingCreate(&calls, http.StatusCreated)))
+
+	first := httptest.NewRecorder()
+	h.ServeHTTP(first, idempotentRequest("key-1", "abc", `{}`))
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Equal(t, `{"id":"t-1"}`, first.Body.String())
+	assert.Nil(t, store.keys["key-1/abc"].ResponseBody, "credentials must not be stored")
+
+	second := httptest.NewRecorder()
+	h.ServeHTTP(second, idempotentRequest("key-1", "abc", `{}`))
+
+	assert.Equal(t, 1, calls, "retry must not run the handler again")
+	assert.Equal(t, http.StatusConflict, second.Code)
+	assert.NotContains(t, second.Body.String(), "t-1")
+	assert.Equal(t, "op-1", second.Header().Get(OperationIDHeader))
+	assert.Equal(t, "true", second.Header().Get(IdempotentReplayedHeader))
+}
+
+func TestIdempotency_SecretResponseErrorReplayed(t *testing.T) {
+	calls := 0
+	h := Idempotency(newMemIdempotencyStore(), zerolog.Nop())(SecretResponse(countingCreate(&calls, http.StatusBadRequest)))
+
+	h.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key-1", "abc", `{}`))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, idempotentRequest("key-1", "abc", `{}`))
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, `{"id":"t-1"}`, rec.Body.String())
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// InFlight counts the requests being served, so shutdown can report how
+// many it is waiting for.
+type InFlight struct {
+	n atomic.Int64
+}
+
+// Middleware counts a request from the moment it is routed until its handler
+// returns.
+func (f *InFlight) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.n.Add(1)
+		defer f.n.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Count returns the number of requests being served.
+func (f *InFlight) Count() int {
+	return int(f.n.Load())
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/edvin/hosting/internal/api/response"
+	"github.com/edvin/hosting/internal/ipallow"
+)
+
+// ssoPathPrefixes are the OIDC provider endpoints, which are governed by the
+// SSO allowlist instead of the API allowlist.
+var ssoPathPrefixes = []string{"/oidc/", "/.well-known/openid-configuration"}
+
+// IPAllowlist returns a middleware that rejects requests from clients outside
+// the allowed ranges with 403. OIDC provider endpoints are checked against
+// sso, everything else against api; health checks and metrics are never
+// restricted. It must run before middleware.RealIP, which trusts
+// X-Forwarded-For from any peer.
+func IPAllowlist(api, sso *ipallow.List) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !api.Enabled() && !sso.Enabled() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if quietPaths[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			list := api
+			for _, prefix := range ssoPathPrefixes {
+				if strings.HasPrefix(r.URL.Path, prefix) {
+					list = sso
+					break
+				}
+			}
+			if !list.Allows(r) {
+				response.WriteError(w, http.StatusForbidden, "client address not allowed")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/ipallow"
+)
+
+func serveAllowlisted(t *testing.T, api, sso *ipallow.List, path, remoteAddr string) int {
+	t.Helper()
+	h := IPAllowlist(api, sso)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestIPAllowlist_SeparateAPIAndSSOLists(t *testing.T) {
+	api, err := ipallow.New("10.0.0.0/8", "")
+	require.NoError(t, err)
+	sso, err := ipallow.New("203.0.113.0/24", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, serveAllowlisted(t, api, sso, "/api/v1/tenants", "10.1.2.3:1000"))
+	assert.Equal(t, http.StatusForbidden, serveAllowlisted(t, api, sso, "/api/v1/tenants", "203.0.113.5:1000"))
+	assert.Equal(t, http.StatusOK, serveAllowlisted(t, api, sso, "/oidc/authorize", "203.0.113.5:1000"))
+	assert.Equal(t, http.StatusForbidden, serveAllowlisted(t, api, sso, "/oidc/token", "10.1.2.3:1000"))
+	assert.Equal(t, http.StatusOK, serveAllowlisted(t, api, sso, "/.well-known/openid-configuration", "203.0.113.5:1000"))
+}
+
+func TestIPAllowlist_ProbesUnrestricted(t *testing.T) {
+	api, err := ipallow.New("10.0.0.0/8", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, serveAllowlisted(t, api, nil, "/healthz", "198.51.100.1:1000"))
+	assert.Equal(t, http.StatusOK, serveAllowlisted(t, api, nil, "/metrics", "198.51.100.1:1000"))
+}
+
+func TestIPAllowlist_EmptySSOListAllowsAll(t *testing.T) {
+	api, err := ipallow.New("10.0.0.0/8", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, serveAllowlisted(t, api, nil, "/oidc/authorize", "198.51.100.1:1000"))
+}
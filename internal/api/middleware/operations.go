@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/edvin/hosting/internal/core"
+)
+
+// OperationIDHeader carries the ID of each operation started by a request.
+const OperationIDHeader = "X-Operation-ID"
+
+// Operations is a middleware that enables operation tracking for the request
+// and returns the recorded operation IDs in X-Operation-ID response headers,
+// one per workflow started, in start order. A 202 response also gets the
+// first operation ID as operation_id in its JSON object body, or as the
+// whole body when the handler wrote none.
+func Operations(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := core.WithOperationTracking(r.Context())
+		ow := &operationWriter{ResponseWriter: w, ctx: ctx}
+		next.ServeHTTP(ow, r.WithContext(ctx))
+		ow.finish()
+	})
+}
+
+// operationWriter adds the operation headers just before the response
+// headers are sent. A 202 response with an operation is held back until the
+// handler returns so operation_id can be added to its body.
+type operationWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	wroteHeader bool
+	held        bool
+	body        bytes.Buffer
+}
+
+func (w *operationWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		for _, id := range core.OperationIDs(w.ctx) {
+			w.Header().Add(OperationIDHeader, id)
+		}
+		// Replayed idempotent responses set the header themselves.
+		if code == http.StatusAccepted && w.Header().Get(OperationIDHeader) != "" {
+			w.held = true
+			return
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *operationWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.held {
+		return w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// finish sends a held-back 202 response with operation_id added. Bodies
+// that are not a JSON object are sent unchanged.
+func (w *operationWriter) finish() {
+	if !w.held {
+		return
+	}
+	id, _ := json.Marshal(w.Header().Get(OperationIDHeader))
+	body := bytes.TrimSpace(w.body.Bytes())
+
+	var buf bytes.Buffer
+	switch {
+	case len(body) == 0:
+		w.Header().Set("Content-Type", "application/json")
+		buf.WriteString(`{"operation_id":`)
+		buf.Write(id)
+		buf.WriteString("}\n")
+	case body[0] == '{' && strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") && json.Valid(body):
+		buf.Write(body[:len(body)-1])
+		if len(bytes.TrimSpace(body[1:len(body)-1])) > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`"operation_id":`)
+		buf.Write(id)
+		buf.WriteString("}\n")
+	default:
+		buf.Write(w.body.Bytes())
+	}
+
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(http.StatusAccepted)
+	w.ResponseWriter.Write(buf.Bytes())
+}
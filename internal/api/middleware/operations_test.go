@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/edvin/hosting/internal/api/response"
+)
+
+// serveOperations runs handler behind Operations. Handlers set the operation
+// header themselves, as a replayed idempotent response does, since recording
+// an operation needs the database.
+func serveOperations(handler http.HandlerFunc) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	Operations(handler).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/webroots/w1/retry", nil))
+	return rec
+}
+
+func TestOperations_AddsOperationIDToAcceptedBody(t *testing.T) {
+	rec := serveOperations(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(OperationIDHeader, "op-1")
+		response.WriteJSON(w, http.StatusAccepted, map[string]string{"id": "w1"})
+	})
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "op-1", rec.Header().Get(OperationIDHeader))
+	assert.JSONEq(t, `{"id":"w1","operation_id":"op-1"}`, rec.Body.String())
+}
+
+func TestOperations_EmptyAcceptedBody(t *testing.T) {
+	rec := serveOperations(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(OperationIDHeader, "op-1")
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"operation_id":"op-1"}`, rec.Body.String())
+}
+
+func TestOperations_NonObjectBodyUnchanged(t *testing.T) {
+	rec := serveOperations(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(OperationIDHeader, "op-1")
+		response.WriteJSON(w, http.StatusAccepted, []string{"a"})
+	})
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.JSONEq(t, `["a"]`, rec.Body.String())
+}
+
+func TestOperations_NoOperationLeavesBodyAlone(t *testing.T) {
+	rec := serveOperations(func(w http.ResponseWriter, r *http.Request) {
+		response.WriteJSON(w, http.StatusAccepted, map[string]string{"id": "w1"})
+	})
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.Empty(t, rec.Header().Get(OperationIDHeader))
+	assert.JSONEq(t, `{"id":"w1"}`, rec.Body.String())
+}
+
+func TestOperations_OtherStatusNotHeld(t *testing.T) {
+	rec := serveOperations(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(OperationIDHeader, "op-1")
+		response.WriteJSON(w, http.StatusOK, map[string]string{"id": "w1"})
+	})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"id":"w1"}`, rec.Body.String())
+}
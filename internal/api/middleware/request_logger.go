@@ -1,33 +1,117 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/zerolog"
 )
 
+// RequestIDHeader is echoed on every response so clients can quote the ID in
+// support tickets. Error responses also carry it in the body.
+const RequestIDHeader = "X-Request-ID"
+
+// quietPaths are probe and scrape endpoints that are only logged when they fail.
+var quietPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// sensitiveParams are query parameters whose values are replaced before logging.
+var sensitiveParams = map[string]bool{
+	"token":         true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"api_key":       true,
+	"key":           true,
+	"code":          true,
+	"password":      true,
+	"secret":        true,
+	"client_secret": true,
+}
+
+type requestLogFieldsKey struct{}
+
+// requestLogFields carries values that are only known further down the
+// middleware chain (after authentication) back up to the request logger.
+type requestLogFields struct {
+	apiKeyID string
+}
+
+// setRequestAPIKeyID records the authenticated API key for the access log.
+func setRequestAPIKeyID(ctx context.Context, id string) {
+	if f, ok := ctx.Value(requestLogFieldsKey{}).(*requestLogFields); ok {
+		f.apiKeyID = id
+	}
+}
+
 // RequestLogger returns a middleware that logs each request with method, path,
-// status, duration, and request ID.
+// redacted query, status, duration, API key ID and request ID. It must run
+// after middleware.RequestID; the request ID is echoed in the X-Request-ID
+// response header. Health check and metrics requests are only logged when
+// they return an error status.
 func RequestLogger(logger zerolog.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
 			reqID := middleware.GetReqID(r.Context())
+			if reqID != "" {
+				w.Header().Set(RequestIDHeader, reqID)
+			}
 			reqLogger := logger.With().Str("request_id", reqID).Logger()
-			r = r.WithContext(reqLogger.WithContext(r.Context()))
+			fields := &requestLogFields{}
+			ctx := context.WithValue(r.Context(), requestLogFieldsKey{}, fields)
+			r = r.WithContext(reqLogger.WithContext(ctx))
 
 			ww := &statusWriter{ResponseWriter: w, status: http.StatusOK}
 			next.ServeHTTP(ww, r)
 
-			reqLogger.Info().
+			if quietPaths[r.URL.Path] && ww.status < http.StatusBadRequest {
+				return
+			}
+
+			event := reqLogger.Info()
+			if ww.status >= http.StatusInternalServerError {
+				event = reqLogger.Error()
+			} else if ww.status >= http.StatusBadRequest {
+				event = reqLogger.Warn()
+			}
+			event = event.
 				Str("method", r.Method).
 				Str("path", r.URL.Path).
 				Int("status", ww.status).
-				Dur("duration", time.Since(start)).
-				Msg("request")
+				Dur("duration", time.Since(start))
+			if r.URL.RawQuery != "" {
+				event = event.Str("query", redactQuery(r.URL.RawQuery))
+			}
+			if fields.apiKeyID != "" {
+				event = event.Str("api_key_id", fields.apiKeyID)
+			}
+			event.Msg("request")
 		})
 	}
 }
+
+// redactQuery replaces the values of sensitive query parameters with
+// "REDACTED". Unparseable queries are dropped entirely rather than logged raw.
+func redactQuery(raw string) string {
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return "REDACTED"
+	}
+	for name := range values {
+		if sensitiveParams[strings.ToLower(name)] {
+			for i := range values[name] {
+				values[name][i] = "REDACTED"
+			}
+		}
+	}
+	return values.Encode()
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/api/response"
+)
+
+func serveLogged(t *testing.T, handler http.HandlerFunc, req *http.Request) (*httptest.ResponseRecorder, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := zerolog.New(&buf)
+	h := middleware.RequestID(RequestLogger(logger)(handler))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec, &buf
+}
+
+func TestRequestLogger_LogsFields(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tenants?limit=10", nil)
+	req.Header.Set("X-Request-Id", "req-123")
+
+	rec, buf := serveLogged(t, func(w http.ResponseWriter, r *http.Request) {
+		setRequestAPIKeyID(r.Context(), "key-1")
+		w.WriteHeader(http.StatusCreated)
+	}, req)
+
+	assert.Equal(t, "req-123", rec.Header().Get(RequestIDHeader))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/api/v1/tenants", entry["path"])
+	assert.Equal(t, "limit=10", entry["query"])
+	assert.Equal(t, float64(http.StatusCreated), entry["status"])
+	assert.Equal(t, "key-1", entry["api_key_id"])
+	assert.Equal(t, "req-123", entry["request_id"])
+	assert.Contains(t, entry, "duration")
+}
+
+func TestRequestLogger_GeneratesRequestID(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tenants", nil)
+
+	rec, _ := serveLogged(t, func(w http.ResponseWriter, r *http.Request) {}, req)
+
+	assert.NotEmpty(t, rec.Header().Get(RequestIDHeader))
+}
+
+func TestRequestLogger_RedactsSensitiveQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tenants/t1/terminal?token=s3cr3t&cols=80", nil)
+
+	_, buf := serveLogged(t, func(w http.ResponseWriter, r *http.Request) {}, req)
+
+	assert.NotContains(t, buf.String(), "s3cr3t")
+	assert.Contains(t, buf.String(), "token=REDACTED")
+	assert.Contains(t, buf.String(), "cols=80")
+}
+
+func TestRequestLogger_SkipsHealthyProbes(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+
+	_, buf := serveLogged(t, func(w http.ResponseWriter, r *http.Request) {}, req)
+
+	assert.Empty(t, buf.String())
+}
+
+func TestRequestLogger_LogsFailingProbes(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+	_, buf := serveLogged(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}, req)
+
+	assert.Contains(t, buf.String(), `"path":"/readyz"`)
+	assert.Contains(t, buf.String(), `"level":"error"`)
+}
+
+func TestRequestLogger_RequestIDInErrorBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tenants/missing", nil)
+	req.Header.Set("X-Request-Id", "req-456")
+
+	rec, _ := serveLogged(t, func(w http.ResponseWriter, r *http.Request) {
+		response.WriteError(w, http.StatusNotFound, "not found")
+	}, req)
+
+	var body response.ErrorResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+	assert.Equal(t, "not found", body.Error)
+	assert.Equal(t, "req-456", body.RequestID)
+}
+
+func TestRedactQuery(t *testing.T) {
+	assert.Equal(t, "a=1&access_token=REDACTED", redactQuery("access_token=abc&a=1"))
+	assert.Equal(t, "Token=REDACTED", redactQuery("Token=abc"))
+	assert.Equal(t, "REDACTED", redactQuery("token=%zz"))
+	assert.False(t, strings.Contains(redactQuery("code=xyz&state=1"), "xyz"))
+}
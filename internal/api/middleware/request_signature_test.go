@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/crypto"
+)
+
+func signatureHandler(secret string) http.Handler {
+	return RequireSignature(secret)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		w.Write(body)
+	}))
+}
+
+func newSignedRequest(t *testing.T, secret string) *http.Request {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/internal/v1/cron-jobs/c1/outcome", strings.NewReader(`{"success":true}`))
+	require.NoError(t, crypto.SignRequest(req, secret))
+	return req
+}
+
+func TestRequireSignature_Valid(t *testing.T) {
+	h := signatureHandler("s3cret")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newSignedRequest(t, "s3cret"))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"success":true}`, rec.Body.String(), "handler still reads the body")
+}
+
+func TestRequireSignature_Rejected(t *testing.T) {
+	h := signatureHandler("s3cret")
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, newSignedRequest(t, "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/internal/v1/cron-jobs/c1/outcome", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRequireSignature_Replay(t *testing.T) {
+	h := signatureHandler("s3cret")
+	req := newSignedRequest(t, "s3cret")
+	replay := req.Clone(req.Context())
+	replay.Body = io.NopCloser(strings.NewReader(`{"success":true}`))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, replay)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestRequireSignature_Disabled(t *testing.T) {
+	h := signatureHandler("")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/internal/v1/cron-jobs/c1/outcome", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -1,10 +1,50 @@
 package request
 
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
 type CreateBackup struct {
 	Type     string `json:"type" validate:"required,oneof=web database"`
 	SourceID string `json:"source_id" validate:"required"`
 }
 
+// RestoreBackup restores the whole backup. With RestoreTo, a database is
+// instead restored to that point in time: the newest backup of it completed
+// before then is restored and its binlogs are replayed up to RestoreTo.
 type RestoreBackup struct {
-	// empty for now -- restores the whole backup
+	RestoreTo *time.Time `json:"restore_to"`
+}
+
+// RestoreBackupFiles restores individual files or directories of a web
+// backup. Paths are relative to the webroot's storage dir, as listed by
+// GET /backups/{id}/files.
+type RestoreBackupFiles struct {
+	Paths []string `json:"paths" validate:"required,min=1,max=100,dive,required,max=1024"`
+	Force bool     `json:"force"` // overwrite files that are newer than the backup's
+}
+
+// Validate rejects paths that would leave the webroot's storage dir.
+func (r *RestoreBackupFiles) Validate() error {
+	for _, p := range r.Paths {
+		if strings.HasPrefix(p, "/") {
+			return fmt.Errorf("path %q must be relative to the webroot", p)
+		}
+		if slices.Contains(strings.Split(p, "/"), "..") {
+			return fmt.Errorf("path %q must not contain ..", p)
+		}
+		if path.Clean(p) == "." {
+			return fmt.Errorf("path %q names the whole webroot; use POST /backups/{id}/restore", p)
+		}
+	}
+	return nil
+}
+
+type CreateBackupDownloadURL struct {
+	ExpiresIn int  `json:"expires_in" validate:"omitempty,min=60,max=86400"` // seconds; defaults to BACKUP_DOWNLOAD_URL_TTL_SECS
+	SingleUse bool `json:"single_use"`
 }
@@ -0,0 +1,34 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreBackupFiles_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		paths   []string
+		wantErr string
+	}{
+		{"file", []string{"wp-config.php"}, ""},
+		{"dir", []string{"public/uploads/2026/"}, ""},
+		{"dotted name", []string{"public/..hidden"}, ""},
+		{"absolute", []string{"/etc/passwd"}, "relative to the webroot"},
+		{"traversal", []string{"public/../../other/wp-config.php"}, "must not contain .."},
+		{"root", []string{"./"}, "whole webroot"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := RestoreBackupFiles{Paths: tt.paths}
+			err := req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
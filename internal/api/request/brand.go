@@ -1,5 +1,10 @@
 package request
 
+import (
+	"encoding/json"
+	"fmt"
+)
+
 type CreateBrand struct {
 	ID               string `json:"id"`
 	Name             string `json:"name" validate:"required"`
@@ -12,6 +17,12 @@ type CreateBrand struct {
 	DKIMSelector     string `json:"dkim_selector"`
 	DKIMPublicKey    string `json:"dkim_public_key"`
 	DMARCPolicy      string `json:"dmarc_policy"`
+	DNSTTLs          map[string]int `json:"dns_ttls" validate:"omitempty,dive,keys,oneof=A AAAA CNAME MX TXT,endkeys,min=60,max=86400"`
+	DNSMigrationTTL  *int   `json:"dns_migration_ttl" validate:"omitempty,min=30,max=3600"`
+	PasswordMinLength *int  `json:"password_min_length" validate:"omitempty,min=8,max=72"`
+	PasswordClasses  []string `json:"password_classes" validate:"omitempty,unique,dive,oneof=lower upper digit symbol"`
+	WebrootMaxConnsPerIP     int `json:"webroot_max_conns_per_ip" validate:"min=0"`
+	WebrootMaxRequestsPerSec int `json:"webroot_max_requests_per_sec" validate:"min=0"`
 }
 
 type UpdateBrand struct {
@@ -25,8 +36,76 @@ type UpdateBrand struct {
 	DKIMSelector     *string `json:"dkim_selector"`
 	DKIMPublicKey    *string `json:"dkim_public_key"`
 	DMARCPolicy      *string `json:"dmarc_policy"`
+	DNSTTLs          map[string]int `json:"dns_ttls" validate:"omitempty,dive,keys,oneof=A AAAA CNAME MX TXT,endkeys,min=60,max=86400"`
+	DNSMigrationTTL  *int    `json:"dns_migration_ttl" validate:"omitempty,min=30,max=3600"`
+	PasswordMinLength *int   `json:"password_min_length" validate:"omitempty,min=8,max=72"`
+	PasswordClasses  []string `json:"password_classes" validate:"omitempty,unique,dive,oneof=lower upper digit symbol"`
+	WebrootMaxConnsPerIP     *int `json:"webroot_max_conns_per_ip" validate:"omitempty,min=0"`
+	WebrootMaxRequestsPerSec *int `json:"webroot_max_requests_per_sec" validate:"omitempty,min=0"`
 }
 
 type SetBrandClusters struct {
 	ClusterIDs []string `json:"cluster_ids" validate:"required"`
 }
+
+// SetBrandACMEConfig sets the ACME CA for a brand's certificates.
+// EABKeyID and EABHMACKey are required by CAs such as ZeroSSL and must be
+// given together.
+type SetBrandACMEConfig struct {
+	DirectoryURL string `json:"directory_url" validate:"required,url"`
+	Email        string `json:"email" validate:"omitempty,email"`
+	EABKeyID     string `json:"eab_key_id"`
+	EABHMACKey   string `json:"eab_hmac_key"`
+}
+
+type BrandZoneTemplateRecord struct {
+	Type     string `json:"type" validate:"required,oneof=SOA A AAAA CNAME MX TXT SRV NS CAA PTR ALIAS HTTPS SVCB TLSA NAPTR LOC SSHFP"`
+	Name     string `json:"name" validate:"required"`
+	Content  string `json:"content" validate:"required"`
+	TTL      int    `json:"ttl" validate:"omitempty,min=60,max=86400"`
+	Priority *int   `json:"priority"`
+}
+
+type SetBrandZoneTemplates struct {
+	Records []BrandZoneTemplateRecord `json:"records" validate:"required,dive"`
+}
+
+type AppTemplate struct {
+	ID             string            `json:"id" validate:"required,slug"`
+	Name           string            `json:"name" validate:"required"`
+	Description    string            `json:"description"`
+	Runtime        string            `json:"runtime" validate:"required,oneof=php node python ruby static"`
+	RuntimeVersion string            `json:"runtime_version" validate:"required"`
+	RuntimeConfig  json.RawMessage   `json:"runtime_config"`
+	PublicFolder   string            `json:"public_folder"`
+	CreateDatabase bool              `json:"create_database"`
+	EnvVars        map[string]string `json:"env_vars"`
+}
+
+type SetAppTemplates struct {
+	Templates []AppTemplate `json:"templates" validate:"required,dive"`
+}
+
+// Validate checks that template IDs are unique and env var names match the
+// allowed pattern.
+func (r *SetAppTemplates) Validate() error {
+	seen := make(map[string]bool, len(r.Templates))
+	for _, t := range r.Templates {
+		if seen[t.ID] {
+			return fmt.Errorf("duplicate template id %q", t.ID)
+		}
+		seen[t.ID] = true
+		for name := range t.EnvVars {
+			if !envVarNameRe.MatchString(name) {
+				return fmt.Errorf("template %q: invalid env var name %q: must match %s", t.ID, name, envVarNameRe.String())
+			}
+		}
+	}
+	return nil
+}
+
+// SetBrandRuntimeDefaults replaces a brand's default runtime versions, keyed
+// by runtime. An empty map removes all defaults.
+type SetBrandRuntimeDefaults struct {
+	Defaults map[string]string `json:"defaults" validate:"required,dive,keys,oneof=php node python ruby,endkeys,required"`
+}
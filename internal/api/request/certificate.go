@@ -5,3 +5,20 @@ type UploadCertificate struct {
 	KeyPEM   string `json:"key_pem" validate:"required"`
 	ChainPEM string `json:"chain_pem"`
 }
+
+// ImportCertificate carries a certificate bundle in any supported format:
+// PEM text with the certificate, key, and chain in any order, or a
+// base64-encoded PKCS#12 (.pfx/.p12) file.
+type ImportCertificate struct {
+	Bundle     string `json:"bundle" validate:"required"`
+	Passphrase string `json:"passphrase"`
+}
+
+// BulkProvisionCertificates lists FQDNs to issue Let's Encrypt certificates
+// for, spread over time. Zero rates use the defaults (20 per hour, 50 per
+// registered domain per week).
+type BulkProvisionCertificates struct {
+	FQDNIDs          []string `json:"fqdn_ids" validate:"required,min=1,max=1000,unique,dive,required"`
+	PerHour          int      `json:"per_hour" validate:"omitempty,min=1,max=300"`
+	PerDomainPerWeek int      `json:"per_domain_per_week" validate:"omitempty,min=1,max=50"`
+}
@@ -6,6 +6,8 @@ type CreateCronJob struct {
 	WorkingDirectory string `json:"working_directory" validate:"omitempty,max=255"`
 	TimeoutSeconds   int    `json:"timeout_seconds" validate:"omitempty,min=1,max=86400"`
 	MaxMemoryMB      int    `json:"max_memory_mb" validate:"omitempty,min=16,max=4096"`
+	// Timezone is the IANA zone the schedule is evaluated in (default UTC).
+	Timezone string `json:"timezone" validate:"omitempty,timezone"`
 }
 
 type UpdateCronJob struct {
@@ -14,4 +16,5 @@ type UpdateCronJob struct {
 	WorkingDirectory *string `json:"working_directory" validate:"omitempty,max=255"`
 	TimeoutSeconds   *int    `json:"timeout_seconds" validate:"omitempty,min=1,max=86400"`
 	MaxMemoryMB      *int    `json:"max_memory_mb" validate:"omitempty,min=16,max=4096"`
+	Timezone         *string `json:"timezone" validate:"omitempty,timezone"`
 }
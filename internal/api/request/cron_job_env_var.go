@@ -0,0 +1,44 @@
+package request
+
+import (
+	"fmt"
+	"strings"
+)
+
+type SetCronJobEnvVars struct {
+	Vars []CronJobEnvVarEntry `json:"vars" validate:"required,dive"`
+}
+
+// CronJobEnvVarEntry is one cron job env var. Secret values are write-only;
+// omitting value on a secret keeps the value already stored under its name.
+type CronJobEnvVarEntry struct {
+	Name   string  `json:"name" validate:"required"`
+	Value  *string `json:"value"`
+	Secret bool    `json:"secret"`
+}
+
+// Validate checks names, rejects duplicates, and requires a value on every
+// non-secret entry. Values cannot contain NUL bytes, which the process
+// environment cannot carry.
+func (r *SetCronJobEnvVars) Validate() error {
+	seen := make(map[string]bool, len(r.Vars))
+	for _, v := range r.Vars {
+		if !envVarNameRe.MatchString(v.Name) {
+			return fmt.Errorf("invalid env var name %q: must match %s", v.Name, envVarNameRe.String())
+		}
+		if seen[v.Name] {
+			return fmt.Errorf("duplicate env var name %q", v.Name)
+		}
+		seen[v.Name] = true
+		if v.Value == nil {
+			if !v.Secret {
+				return fmt.Errorf("env var %q: value is required", v.Name)
+			}
+			continue
+		}
+		if strings.ContainsRune(*v.Value, 0) {
+			return fmt.Errorf("env var %q: value must not contain NUL bytes", v.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package request
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateCronJob_Timezone(t *testing.T) {
+	tests := []struct {
+		timezone string
+		valid    bool
+	}{
+		{"", true},
+		{"UTC", true},
+		{"Europe/Oslo", true},
+		{"America/New_York", true},
+		{"Local", false},
+		{"Mars/Olympus", false},
+		{"+02:00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			body := `{"schedule":"0 2 * * *","command":"true","timezone":"` + tt.timezone + `"}`
+			r, err := http.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+			assert.NoError(t, err)
+			var req CreateCronJob
+			err = Decode(r, &req)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -3,5 +3,7 @@ package request
 type CreateDatabase struct {
 	SubscriptionID string                     `json:"subscription_id" validate:"required"`
 	ShardID        string                     `json:"shard_id" validate:"required"`
+	Charset        string                     `json:"charset"`
+	Collation      string                     `json:"collation"`
 	Users          []CreateDatabaseUserNested `json:"users" validate:"omitempty,dive"`
 }
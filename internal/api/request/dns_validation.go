@@ -7,6 +7,8 @@ import (
 	"net"
 	"strconv"
 	"strings"
+
+	"github.com/edvin/hosting/internal/model"
 )
 
 // ValidateZoneRecord validates DNS record content, name, and priority based on the record type.
@@ -20,6 +22,48 @@ func ValidateZoneRecord(recordType, name, content string, priority *int) error {
 	return validateRecordPriority(recordType, priority)
 }
 
+// zoneTemplateSampleVars are the values zone templates are expanded with for
+// validation, so templated records are checked like any other record.
+var zoneTemplateSampleVars = model.ZoneTemplateVars("example.com", model.Brand{
+	BaseHostname:    "hosting.example.net",
+	PrimaryNS:       "ns1.example.net",
+	SecondaryNS:     "ns2.example.net",
+	HostmasterEmail: "hostmaster.example.net",
+	MailHostname:    "mail.example.net",
+	DKIMSelector:    "default",
+	DKIMPublicKey:   "MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8AMIIBCgKCAQEA",
+	DMARCPolicy:     "none",
+})
+
+// ValidateZoneTemplate validates a brand zone template record. Placeholders
+// must be known template variables; the expanded record must pass
+// ValidateZoneRecord. SOA and NS templates replace the zone's default SOA and
+// NS records, so they must be at the zone apex ({zone}).
+func ValidateZoneTemplate(recordType, name, content string, priority *int) error {
+	expName, err := model.ExpandZoneTemplate(name, zoneTemplateSampleVars)
+	if err != nil {
+		return fmt.Errorf("record name: %w", err)
+	}
+	expContent, err := model.ExpandZoneTemplate(content, zoneTemplateSampleVars)
+	if err != nil {
+		return fmt.Errorf("record content: %w", err)
+	}
+
+	switch recordType {
+	case "SOA", "NS":
+		if expName != zoneTemplateSampleVars["zone"] {
+			return fmt.Errorf("%s template name must be {zone}", recordType)
+		}
+	}
+	if recordType == "SOA" {
+		if priority != nil {
+			return fmt.Errorf("SOA record must not have a priority value")
+		}
+		return validateSOAContent(expContent)
+	}
+	return ValidateZoneRecord(recordType, expName, expContent, priority)
+}
+
 // validateRecordName checks that the DNS record name is valid.
 // Accepts "@" for zone apex, "*" or wildcard prefixes like "*.sub", and standard hostnames.
 func validateRecordName(name string) error {
@@ -169,6 +213,23 @@ func isValidLabel(label string) bool {
 	return true
 }
 
+// validateSOAContent validates SOA content: "mname rname serial refresh retry expire minimum".
+func validateSOAContent(content string) error {
+	fields := strings.Fields(content)
+	if len(fields) != 7 {
+		return fmt.Errorf("SOA record content must have 7 fields: mname rname serial refresh retry expire minimum")
+	}
+	if !isValidHostname(fields[0]) || !isValidHostname(fields[1]) {
+		return fmt.Errorf("SOA record mname and rname must be valid hostnames")
+	}
+	for _, f := range fields[2:] {
+		if _, err := strconv.ParseUint(f, 10, 32); err != nil {
+			return fmt.Errorf("SOA record serial and timers must be unsigned integers")
+		}
+	}
+	return nil
+}
+
 // validateSRVContent validates SRV record content: "{weight} {port} {target}".
 func validateSRVContent(content string) error {
 	parts := strings.Fields(content)
@@ -144,6 +144,45 @@ func TestValidateZoneRecord(t *testing.T) {
 	}
 }
 
+func TestValidateZoneTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rtype    string
+		rname    string
+		content  string
+		priority *int
+		wantErr  string
+	}{
+		{"MX at apex", "MX", "{zone}", "{mail_hostname}", intPtr(10), ""},
+		{"MX missing priority", "MX", "{zone}", "{mail_hostname}", nil, "requires a priority"},
+		{"SPF", "TXT", "{zone}", "v=spf1 mx ~all", nil, ""},
+		{"DKIM", "TXT", "{dkim_selector}._domainkey.{zone}", "v=DKIM1; k=rsa; p={dkim_public_key}", nil, ""},
+		{"DMARC", "TXT", "_dmarc.{zone}", "v=DMARC1; p={dmarc_policy}", nil, ""},
+		{"apex A", "A", "{zone}", "203.0.113.10", nil, ""},
+		{"apex AAAA bad content", "AAAA", "{zone}", "203.0.113.10", nil, "valid IPv6"},
+		{"unknown variable in name", "A", "{tenant}.{zone}", "203.0.113.10", nil, "unknown template variable {tenant}"},
+		{"unknown variable in content", "CNAME", "www.{zone}", "{webroot}", nil, "unknown template variable {webroot}"},
+		{"NS at apex", "NS", "{zone}", "{primary_ns}", nil, ""},
+		{"NS below apex", "NS", "sub.{zone}", "{primary_ns}", nil, "must be {zone}"},
+		{"SOA valid", "SOA", "{zone}", "{primary_ns} {hostmaster_email} 1 10800 3600 604800 300", nil, ""},
+		{"SOA below apex", "SOA", "www.{zone}", "{primary_ns} {hostmaster_email} 1 10800 3600 604800 300", nil, "must be {zone}"},
+		{"SOA too few fields", "SOA", "{zone}", "{primary_ns} {hostmaster_email} 1", nil, "7 fields"},
+		{"SOA bad timer", "SOA", "{zone}", "{primary_ns} {hostmaster_email} 1 10800 x 604800 300", nil, "unsigned integers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateZoneTemplate(tt.rtype, tt.rname, tt.content, tt.priority)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestIsValidHostname(t *testing.T) {
 	tests := []struct {
 		hostname string
@@ -0,0 +1,87 @@
+package request
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// MaxEmailAccountImportRows caps the number of accounts in one import.
+const MaxEmailAccountImportRows = 1000
+
+// ParseEmailAccountCSV reads an email account import: one account per line
+// with the columns address, display_name, quota (bytes, empty for none) and
+// an optional initial password. A first line whose first column is
+// "address" is a header and skipped.
+//
+// Each row is checked on its own and returned with Error set if it is
+// invalid, so all problems can be reported at once. Only a malformed or
+// oversized file fails the whole parse.
+func ParseEmailAccountCSV(r io.Reader) ([]model.EmailAccountImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	var rows []model.EmailAccountImportRow
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, fmt.Errorf("request body exceeds %d bytes: %w", tooLarge.Limit, err)
+			}
+			return nil, fmt.Errorf("invalid CSV: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		if len(rows) == 0 && line == 1 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+		if len(rows) == MaxEmailAccountImportRows {
+			return nil, fmt.Errorf("import exceeds %d accounts", MaxEmailAccountImportRows)
+		}
+		rows = append(rows, parseEmailAccountRecord(line, record))
+	}
+	if len(rows) == 0 {
+		return nil, errors.New("import contains no accounts")
+	}
+	return rows, nil
+}
+
+func parseEmailAccountRecord(line int, record []string) model.EmailAccountImportRow {
+	row := model.EmailAccountImportRow{Line: line}
+	if len(record) < 3 || len(record) > 4 {
+		row.Error = fmt.Sprintf("expected 3 or 4 columns, got %d", len(record))
+		return row
+	}
+	row.Address = strings.ToLower(strings.TrimSpace(record[0]))
+	row.DisplayName = strings.TrimSpace(record[1])
+	if len(record) == 4 {
+		row.Password = record[3]
+	}
+
+	if err := validate.Var(row.Address, "required,email"); err != nil {
+		row.Error = fmt.Sprintf("invalid address %q", row.Address)
+		return row
+	}
+	if q := strings.TrimSpace(record[2]); q != "" {
+		n, err := strconv.ParseInt(q, 10, 64)
+		if err != nil || n < 0 {
+			row.Error = fmt.Sprintf("invalid quota %q: must be a non-negative number of bytes", q)
+			return row
+		}
+		row.QuotaBytes = n
+	}
+	if row.Password != "" && (len(row.Password) < 8 || len(row.Password) > 72) {
+		row.Error = "password must be 8 to 72 bytes"
+	}
+	return row
+}
@@ -0,0 +1,63 @@
+package request
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func TestParseEmailAccountCSV(t *testing.T) {
+	csv := "address,display_name,quota,password\n" +
+		"Alice@Example.com,Alice,1073741824,s3cret-pass\n" +
+		"bob@example.com,\"Bob, Jr.\",,\n" +
+		"carol@example.com,Carol,\n" +
+		"not-an-address,X,0\n" +
+		"dave@example.com,Dave,-5\n" +
+		"erin@example.com,Erin,0,short\n" +
+		"frank@example.com\n"
+
+	rows, err := ParseEmailAccountCSV(strings.NewReader(csv))
+	require.NoError(t, err)
+	require.Len(t, rows, 7)
+
+	assert.Equal(t, model.EmailAccountImportRow{
+		Line: 2, Address: "alice@example.com", DisplayName: "Alice", QuotaBytes: 1073741824, Password: "s3cret-pass",
+	}, rows[0])
+	assert.Equal(t, model.EmailAccountImportRow{Line: 3, Address: "bob@example.com", DisplayName: "Bob, Jr."}, rows[1])
+	assert.Empty(t, rows[2].Error)
+	assert.Contains(t, rows[3].Error, "invalid address")
+	assert.Contains(t, rows[4].Error, "invalid quota")
+	assert.Contains(t, rows[5].Error, "8 to 72 bytes")
+	assert.Contains(t, rows[6].Error, "expected 3 or 4 columns")
+}
+
+func TestParseEmailAccountCSV_NoHeader(t *testing.T) {
+	rows, err := ParseEmailAccountCSV(strings.NewReader("alice@example.com,Alice,0\n"))
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, 1, rows[0].Line)
+	assert.Empty(t, rows[0].Error)
+}
+
+func TestParseEmailAccountCSV_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"empty", "", "no accounts"},
+		{"header only", "address,display_name,quota\n", "no accounts"},
+		{"malformed", "alice@example.com,\"Alice,0\n", "invalid CSV"},
+		{"too many rows", strings.Repeat("a@example.com,A,0\n", MaxEmailAccountImportRows+1), "exceeds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseEmailAccountCSV(strings.NewReader(tt.body))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
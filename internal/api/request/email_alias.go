@@ -1,5 +1,6 @@
 package request
 
 type CreateEmailAlias struct {
-	Address string `json:"address" validate:"required,email"`
+	Address  string `json:"address" validate:"required_unless=CatchAll true,omitempty,email"`
+	CatchAll bool   `json:"catch_all"`
 }
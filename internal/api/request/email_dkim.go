@@ -0,0 +1,7 @@
+package request
+
+// ProvisionDKIM is the request body for generating an FQDN's DKIM key.
+// An empty selector defaults to one derived from the current month.
+type ProvisionDKIM struct {
+	Selector string `json:"selector" validate:"omitempty,dns_label"`
+}
@@ -12,6 +12,7 @@ type ListParams struct {
 	Order    string // "asc" or "desc"
 	BrandIDs   []string // populated from auth context, not query params
 	CustomerID string   // optional filter from query params
+	ResellerID string   // populated from auth context or the reseller being listed
 }
 
 // ParseListParams extracts list parameters from the query string.
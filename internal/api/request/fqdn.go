@@ -1,13 +1,31 @@
 package request
 
 type CreateFQDN struct {
-	FQDN          string                     `json:"fqdn" validate:"required,fqdn"`
-	WebrootID     *string                    `json:"webroot_id"`
-	SSLEnabled    *bool                      `json:"ssl_enabled"`
-	EmailAccounts []CreateEmailAccountNested `json:"email_accounts" validate:"omitempty,dive"`
+	FQDN       string  `json:"fqdn" validate:"required,fqdn_or_wildcard"`
+	WebrootID  *string `json:"webroot_id"`
+	SSLEnabled *bool   `json:"ssl_enabled"`
+	ForceHTTPS *bool   `json:"force_https"`
+	// RedirectTarget makes the FQDN redirect to another host instead of
+	// serving the webroot. RedirectCode defaults to 301 and
+	// RedirectPreservePath to true.
+	RedirectTarget       *string                    `json:"redirect_target" validate:"omitempty,fqdn"`
+	RedirectCode         *int                       `json:"redirect_code" validate:"omitempty,oneof=301 302"`
+	RedirectPreservePath *bool                      `json:"redirect_preserve_path"`
+	IPMode               *string                    `json:"ip_mode" validate:"omitempty,oneof=dual_stack ipv6_only"`
+	EmailAccounts        []CreateEmailAccountNested `json:"email_accounts" validate:"omitempty,dive"`
 }
 
 type UpdateFQDN struct {
 	WebrootID  *string `json:"webroot_id"`
 	SSLEnabled *bool   `json:"ssl_enabled"`
+	ForceHTTPS *bool   `json:"force_https"`
+	// RedirectTarget sets the host the FQDN redirects to; "" makes it serve
+	// the webroot again.
+	RedirectTarget       *string `json:"redirect_target" validate:"omitempty,len=0|fqdn"`
+	RedirectCode         *int    `json:"redirect_code" validate:"omitempty,oneof=301 302"`
+	RedirectPreservePath *bool   `json:"redirect_preserve_path"`
+	IPMode               *string `json:"ip_mode" validate:"omitempty,oneof=dual_stack ipv6_only"`
+	// Email limits for the FQDN; 0 removes the limit.
+	MaxEmailAccounts *int   `json:"max_email_accounts" validate:"omitempty,min=0"`
+	EmailQuotaBytes  *int64 `json:"email_quota_bytes" validate:"omitempty,min=0"`
 }
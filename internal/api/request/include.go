@@ -0,0 +1,31 @@
+package request
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ParseIncludes returns the related resources requested with the
+// comma-separated include query parameter, in the order given and without
+// duplicates. Only the allowed names are accepted, so that each endpoint
+// decides which expansions it can afford.
+func ParseIncludes(r *http.Request, allowed ...string) ([]string, error) {
+	raw := r.URL.Query().Get("include")
+	if raw == "" {
+		return nil, nil
+	}
+	var includes []string
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || slices.Contains(includes, name) {
+			continue
+		}
+		if !slices.Contains(allowed, name) {
+			return nil, fmt.Errorf("unknown include %q: allowed are %s", name, strings.Join(allowed, ", "))
+		}
+		includes = append(includes, name)
+	}
+	return includes, nil
+}
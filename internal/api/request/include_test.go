@@ -0,0 +1,30 @@
+package request
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIncludes(t *testing.T) {
+	r := httptest.NewRequest("GET", "/webroots/abc?include=daemons,%20fqdns,,daemons", nil)
+	includes, err := ParseIncludes(r, "fqdns", "daemons", "cronjobs")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"daemons", "fqdns"}, includes)
+}
+
+func TestParseIncludes_None(t *testing.T) {
+	r := httptest.NewRequest("GET", "/webroots/abc", nil)
+	includes, err := ParseIncludes(r, "fqdns")
+	require.NoError(t, err)
+	assert.Empty(t, includes)
+}
+
+func TestParseIncludes_NotAllowed(t *testing.T) {
+	r := httptest.NewRequest("GET", "/webroots/abc?include=fqdns,certificates", nil)
+	_, err := ParseIncludes(r, "fqdns", "daemons")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown include "certificates": allowed are fqdns, daemons`)
+}
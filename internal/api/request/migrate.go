@@ -13,3 +13,10 @@ type MigrateDatabase struct {
 type MigrateValkeyInstance struct {
 	TargetShardID string `json:"target_shard_id" validate:"required"`
 }
+
+// SetTenantLBSplit sends Weight percent of a tenant's web traffic to the LB
+// backend of ShardID instead of the tenant's own shard.
+type SetTenantLBSplit struct {
+	ShardID string `json:"shard_id" validate:"required"`
+	Weight  int    `json:"weight" validate:"required,min=1,max=100"`
+}
@@ -22,17 +22,24 @@ type CreateWebrootNested struct {
 	RuntimeVersion         string             `json:"runtime_version" validate:"required"`
 	RuntimeConfig          json.RawMessage    `json:"runtime_config"`
 	PublicFolder           string             `json:"public_folder"`
+	ErrorPages             map[int]string     `json:"error_pages"`
 	EnvFileName            string             `json:"env_file_name"`
 	ServiceHostnameEnabled *bool              `json:"service_hostname_enabled"`
+	AccessLogEnabled       *bool              `json:"access_log_enabled"`
 	FQDNs                  []CreateFQDNNested `json:"fqdns" validate:"omitempty,dive"`
 	Daemons                []CreateDaemonNested  `json:"daemons" validate:"omitempty,dive"`
 	CronJobs               []CreateCronJobNested `json:"cron_jobs" validate:"omitempty,dive"`
 }
 
 type CreateFQDNNested struct {
-	FQDN          string                     `json:"fqdn" validate:"required,fqdn"`
-	SSLEnabled    *bool                      `json:"ssl_enabled"`
-	EmailAccounts []CreateEmailAccountNested `json:"email_accounts" validate:"omitempty,dive"`
+	FQDN                 string                     `json:"fqdn" validate:"required,fqdn_or_wildcard"`
+	SSLEnabled           *bool                      `json:"ssl_enabled"`
+	ForceHTTPS           *bool                      `json:"force_https"`
+	RedirectTarget       *string                    `json:"redirect_target" validate:"omitempty,fqdn"`
+	RedirectCode         *int                       `json:"redirect_code" validate:"omitempty,oneof=301 302"`
+	RedirectPreservePath *bool                      `json:"redirect_preserve_path"`
+	IPMode               *string                    `json:"ip_mode" validate:"omitempty,oneof=dual_stack ipv6_only"`
+	EmailAccounts        []CreateEmailAccountNested `json:"email_accounts" validate:"omitempty,dive"`
 }
 
 type CreateEmailAccountNested struct {
@@ -65,6 +72,8 @@ type CreateEmailAutoReplyNested struct {
 type CreateDatabaseNested struct {
 	SubscriptionID string                           `json:"subscription_id" validate:"required"`
 	ShardID        string                           `json:"shard_id" validate:"required"`
+	Charset        string                           `json:"charset"`
+	Collation      string                           `json:"collation"`
 	Users          []CreateDatabaseUserNested       `json:"users" validate:"omitempty,dive"`
 }
 
@@ -75,10 +84,11 @@ type CreateDatabaseUserNested struct {
 }
 
 type CreateValkeyInstanceNested struct {
-	SubscriptionID string                   `json:"subscription_id" validate:"required"`
-	ShardID        string                   `json:"shard_id" validate:"required"`
-	MaxMemoryMB    int                      `json:"max_memory_mb" validate:"omitempty,min=1"`
-	Users          []CreateValkeyUserNested `json:"users" validate:"omitempty,dive"`
+	SubscriptionID  string                   `json:"subscription_id" validate:"required"`
+	ShardID         string                   `json:"shard_id" validate:"required"`
+	MaxMemoryMB     int                      `json:"max_memory_mb" validate:"omitempty,min=1"`
+	PersistenceMode string                   `json:"persistence_mode" validate:"omitempty,oneof=rdb aof none"`
+	Users           []CreateValkeyUserNested `json:"users" validate:"omitempty,dive"`
 }
 
 type CreateValkeyUserNested struct {
@@ -102,8 +112,8 @@ type CreateSSHKeyNested struct {
 }
 
 type CreateEgressRuleNested struct {
-	CIDR        string `json:"cidr" validate:"required"`
-	Description string `json:"description"`
+	CIDR        string `json:"cidr" validate:"required,cidrv4|cidrv6"`
+	Description string `json:"description" validate:"max=255"`
 }
 
 type CreateS3AccessKeyNested struct{}
@@ -119,4 +129,5 @@ type CreateCronJobNested struct {
 	Schedule         string `json:"schedule" validate:"required"`
 	Command          string `json:"command" validate:"required"`
 	WorkingDirectory string `json:"working_directory"`
+	Timezone         string `json:"timezone" validate:"omitempty,timezone"`
 }
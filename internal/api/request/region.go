@@ -1,6 +1,11 @@
 package request
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
 
 type CreateRegion struct {
 	Name   string          `json:"name" validate:"required,slug"`
@@ -12,6 +17,22 @@ type UpdateRegion struct {
 	Config json.RawMessage `json:"config"`
 }
 
+// ValidateRegionConfig checks the parts of a region config the platform
+// reads. Other keys are left alone.
+func ValidateRegionConfig(cfg json.RawMessage) error {
+	if len(cfg) == 0 {
+		return nil
+	}
+	var region model.RegionConfig
+	if err := json.Unmarshal(cfg, &region); err != nil {
+		return fmt.Errorf("invalid region config: %w", err)
+	}
+	if err := region.DNS.Validate(); err != nil {
+		return fmt.Errorf("invalid dns config: %w", err)
+	}
+	return nil
+}
+
 type AddClusterRuntime struct {
 	Runtime string `json:"runtime" validate:"required"`
 	Version string `json:"version" validate:"required"`
@@ -0,0 +1,21 @@
+package request
+
+type CreateReseller struct {
+	BrandID string `json:"brand_id" validate:"required"`
+	Name    string `json:"name" validate:"required,min=1,max=255"`
+}
+
+type UpdateReseller struct {
+	Name string `json:"name" validate:"required,min=1,max=255"`
+}
+
+type AssignResellerTenant struct {
+	TenantID string `json:"tenant_id" validate:"required"`
+}
+
+// CreateResellerAPIKey holds the request body for creating a reseller-scoped
+// API key. Brand access is taken from the reseller.
+type CreateResellerAPIKey struct {
+	Name   string   `json:"name" validate:"required,min=1,max=255"`
+	Scopes []string `json:"scopes" validate:"required,min=1"`
+}
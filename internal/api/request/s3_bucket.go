@@ -1,13 +1,22 @@
 package request
 
+import "github.com/edvin/hosting/internal/model"
+
 type CreateS3Bucket struct {
-	SubscriptionID string `json:"subscription_id" validate:"required"`
-	ShardID        string `json:"shard_id" validate:"required"`
-	Public         *bool  `json:"public"`
-	QuotaBytes     *int64 `json:"quota_bytes"`
+	SubscriptionID string                  `json:"subscription_id" validate:"required"`
+	ShardID        string                  `json:"shard_id" validate:"required"`
+	Public         *bool                   `json:"public"`
+	QuotaBytes     *int64                  `json:"quota_bytes"`
+	LifecycleRules []model.S3LifecycleRule `json:"lifecycle_rules"`
 }
 
 type UpdateS3Bucket struct {
 	Public     *bool  `json:"public"`
 	QuotaBytes *int64 `json:"quota_bytes"`
 }
+
+// SetS3BucketLifecycle replaces all lifecycle rules of a bucket. An empty
+// list removes the lifecycle configuration.
+type SetS3BucketLifecycle struct {
+	Rules []model.S3LifecycleRule `json:"rules"`
+}
@@ -1,6 +1,11 @@
 package request
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
 
 type CreateShard struct {
 	Name      string          `json:"name" validate:"required,slug"`
@@ -14,3 +19,30 @@ type UpdateShard struct {
 	Config    json.RawMessage `json:"config"`
 	Status    string          `json:"status"`
 }
+
+// ValidateShardConfig checks the config of a shard with the given role.
+// Only web shard configs are checked; they carry the nginx tuning rendered
+// into nginx.conf on the shard's nodes.
+func ValidateShardConfig(role string, cfg json.RawMessage) error {
+	if role != model.ShardRoleWeb || len(cfg) == 0 {
+		return nil
+	}
+	var web model.WebShardConfig
+	if err := json.Unmarshal(cfg, &web); err != nil {
+		return fmt.Errorf("invalid web shard config: %w", err)
+	}
+	if err := web.Nginx.Validate(); err != nil {
+		return fmt.Errorf("invalid nginx tuning: %w", err)
+	}
+	return nil
+}
+
+type ConvergeCluster struct {
+	MaxConcurrent int `json:"max_concurrent" validate:"omitempty,min=1,max=20"`
+}
+
+type PromoteReplica struct {
+	NodeID        string `json:"node_id"`
+	MaxLagSeconds int    `json:"max_lag_seconds" validate:"omitempty,min=1,max=3600"`
+	Force         bool   `json:"force"`
+}
@@ -0,0 +1,12 @@
+package request
+
+// CreateSMTPRelayUser creates SMTP relay credentials for a tenant. The
+// hourly limit defaults to model.DefaultSMTPRelayMaxMessagesPerHour.
+type CreateSMTPRelayUser struct {
+	MaxMessagesPerHour int `json:"max_messages_per_hour" validate:"omitempty,min=1,max=10000"`
+}
+
+// UpdateSMTPRelayUser changes a relay user's hourly message limit.
+type UpdateSMTPRelayUser struct {
+	MaxMessagesPerHour int `json:"max_messages_per_hour" validate:"required,min=1,max=10000"`
+}
@@ -27,3 +27,27 @@ type UpdateTenant struct {
 	SSHEnabled     *bool   `json:"ssh_enabled"`
 	DiskQuotaBytes *int64  `json:"disk_quota_bytes"`
 }
+
+// SetTenantMaintenanceWindow sets a daily window, starting at StartTime
+// ("HH:MM") in Timezone (IANA name), during which automatic operations such
+// as certificate renewals are scheduled for the tenant.
+type SetTenantMaintenanceWindow struct {
+	StartTime       string `json:"start_time" validate:"required,datetime=15:04"`
+	DurationMinutes int    `json:"duration_minutes" validate:"required,min=60,max=720"`
+	Timezone        string `json:"timezone" validate:"required,timezone"`
+}
+
+// SetBackupSchedule backs up all of a tenant's webroots and databases daily or
+// weekly, keeping each backup for RetentionDays. Enabled defaults to true.
+type SetBackupSchedule struct {
+	Frequency     string `json:"frequency" validate:"required,oneof=daily weekly"`
+	RetentionDays int    `json:"retention_days" validate:"required,min=1,max=365"`
+	Enabled       *bool  `json:"enabled"`
+}
+
+// ReassignTenantBrand moves a tenant to another brand. ResellerID, if set,
+// must be a reseller of the target brand.
+type ReassignTenantBrand struct {
+	BrandID    string  `json:"brand_id" validate:"required"`
+	ResellerID *string `json:"reseller_id"`
+}
@@ -0,0 +1,29 @@
+package request
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// SpoolBody streams the request body into a temporary file and returns it
+// rewound to the start, so large uploads such as restore or export archives
+// are never held in memory. The body is still subject to the MaxBodySize
+// limit. The caller must close and remove the file.
+func SpoolBody(r *http.Request) (*os.File, int64, error) {
+	f, err := os.CreateTemp("", "upload-*")
+	if err != nil {
+		return nil, 0, fmt.Errorf("create upload file: %w", err)
+	}
+	n, err := io.Copy(f, r.Body)
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, 0, fmt.Errorf("spool upload: %w", err)
+	}
+	return f, n, nil
+}
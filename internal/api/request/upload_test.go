@@ -0,0 +1,36 @@
+package request
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpoolBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("archive bytes"))
+
+	f, n, err := SpoolBody(r)
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	assert.Equal(t, int64(13), n)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	assert.Equal(t, "archive bytes", string(data))
+}
+
+func TestSpoolBody_TooLargeRemovesFile(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(strings.Repeat("x", 100)))
+	r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, 10)
+
+	f, _, err := SpoolBody(r)
+	require.Error(t, err)
+	assert.Nil(t, f)
+}
@@ -2,9 +2,11 @@ package request
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -13,6 +15,7 @@ var validate = validator.New()
 
 var nameRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,62}$`)
 var mysqlNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+var dnsLabelRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
 
 func init() {
 	validate.RegisterValidation("slug", func(fl validator.FieldLevel) bool {
@@ -21,10 +24,23 @@ func init() {
 	validate.RegisterValidation("mysql_name", func(fl validator.FieldLevel) bool {
 		return mysqlNameRegex.MatchString(fl.Field().String())
 	})
+	validate.RegisterValidation("dns_label", func(fl validator.FieldLevel) bool {
+		return dnsLabelRegex.MatchString(fl.Field().String())
+	})
+	// fqdn_or_wildcard accepts an FQDN or a single leading wildcard label
+	// such as "*.example.com".
+	validate.RegisterValidation("fqdn_or_wildcard", func(fl validator.FieldLevel) bool {
+		name := strings.TrimPrefix(fl.Field().String(), "*.")
+		return validate.Var(name, "fqdn") == nil
+	})
 }
 
 func Decode(r *http.Request, v any) error {
 	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return fmt.Errorf("request body exceeds %d bytes: %w", tooLarge.Limit, err)
+		}
 		return fmt.Errorf("invalid JSON: %w", err)
 	}
 	if err := validate.Struct(v); err != nil {
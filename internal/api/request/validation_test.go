@@ -3,6 +3,7 @@ package request
 import (
 	"bytes"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 
@@ -57,6 +58,18 @@ func TestDecode_InvalidJSON(t *testing.T) {
 	assert.Contains(t, err.Error(), "invalid JSON")
 }
 
+func TestDecode_BodyTooLarge(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("a", 100) + `"}`
+	r, err := http.NewRequest(http.MethodPost, "/", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, 32)
+
+	var payload testDecodePayload
+	err = Decode(r, &payload)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request body exceeds 32 bytes")
+}
+
 func TestDecode_ValidationFails(t *testing.T) {
 	// Missing the required "name" field.
 	body := `{"email":"alice@example.com"}`
@@ -121,3 +134,62 @@ func TestMySQLNameValidation_Invalid(t *testing.T) {
 		})
 	}
 }
+
+func TestDNSLabelValidation(t *testing.T) {
+	valid := []string{"s202610", "default", "mail-2026", "a", "0"}
+	for _, label := range valid {
+		t.Run(label, func(t *testing.T) {
+			assert.NoError(t, validate.Var(label, "dns_label"))
+		})
+	}
+	invalid := []string{"", "-leading", "trailing-", "Upper", "has.dot", "under_score", strings.Repeat("a", 64)}
+	for _, label := range invalid {
+		t.Run(label, func(t *testing.T) {
+			assert.Error(t, validate.Var(label, "dns_label"))
+		})
+	}
+}
+
+func TestFQDNOrWildcardValidation(t *testing.T) {
+	valid := []string{"example.com", "www.example.com", "*.example.com", "*.sub.example.com"}
+	for _, name := range valid {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, validate.Var(name, "fqdn_or_wildcard"))
+		})
+	}
+
+	invalid := []string{"*", "*.", "**.example.com", "www.*.example.com", "*example.com", "*.*.example.com", "not a domain"}
+	for _, name := range invalid {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, validate.Var(name, "fqdn_or_wildcard"))
+		})
+	}
+}
+
+func TestUpdateBrandDNSTTLsValidation(t *testing.T) {
+	valid := UpdateBrand{DNSTTLs: map[string]int{"A": 120, "MX": 86400}}
+	assert.NoError(t, validate.Struct(valid))
+
+	unknownType := UpdateBrand{DNSTTLs: map[string]int{"SRV": 300}}
+	assert.Error(t, validate.Struct(unknownType))
+
+	tooShort := UpdateBrand{DNSTTLs: map[string]int{"A": 10}}
+	assert.Error(t, validate.Struct(tooShort))
+
+	migrationTTL := 5
+	assert.Error(t, validate.Struct(UpdateBrand{DNSMigrationTTL: &migrationTTL}))
+}
+
+func TestDecode_UpdateFQDNClearsRedirect(t *testing.T) {
+	r, err := http.NewRequest(http.MethodPut, "/", bytes.NewBufferString(`{"redirect_target":""}`))
+	require.NoError(t, err)
+
+	var req UpdateFQDN
+	require.NoError(t, Decode(r, &req))
+	require.NotNil(t, req.RedirectTarget)
+	assert.Empty(t, *req.RedirectTarget)
+
+	r, err = http.NewRequest(http.MethodPut, "/", bytes.NewBufferString(`{"redirect_target":"not a host"}`))
+	require.NoError(t, err)
+	assert.Error(t, Decode(r, &req))
+}
@@ -1,8 +1,13 @@
 package request
 
 type CreateValkeyInstance struct {
-	SubscriptionID string                   `json:"subscription_id" validate:"required"`
-	ShardID        string                   `json:"shard_id" validate:"required"`
-	MaxMemoryMB    int                      `json:"max_memory_mb" validate:"omitempty,min=1"`
-	Users          []CreateValkeyUserNested `json:"users" validate:"omitempty,dive"`
+	SubscriptionID  string                   `json:"subscription_id" validate:"required"`
+	ShardID         string                   `json:"shard_id" validate:"required"`
+	MaxMemoryMB     int                      `json:"max_memory_mb" validate:"omitempty,min=1"`
+	PersistenceMode string                   `json:"persistence_mode" validate:"omitempty,oneof=rdb aof none"`
+	Users           []CreateValkeyUserNested `json:"users" validate:"omitempty,dive"`
+}
+
+type UpdateValkeyInstance struct {
+	PersistenceMode string `json:"persistence_mode" validate:"omitempty,oneof=rdb aof none"`
 }
@@ -6,12 +6,15 @@ import (
 
 type CreateWebroot struct {
 	SubscriptionID         string             `json:"subscription_id" validate:"required"`
-	Runtime                string             `json:"runtime" validate:"required,oneof=php node python ruby static"`
-	RuntimeVersion         string             `json:"runtime_version" validate:"required"`
+	TemplateID             string             `json:"template_id"`
+	Runtime                string             `json:"runtime" validate:"required_without=TemplateID,omitempty,oneof=php node python ruby static"`
+	RuntimeVersion         string             `json:"runtime_version"`
 	RuntimeConfig          json.RawMessage    `json:"runtime_config"`
 	PublicFolder           string             `json:"public_folder"`
+	ErrorPages             map[int]string     `json:"error_pages"`
 	EnvFileName            string             `json:"env_file_name"`
 	ServiceHostnameEnabled *bool              `json:"service_hostname_enabled"`
+	AccessLogEnabled       *bool              `json:"access_log_enabled"`
 	FQDNs                  []CreateFQDNNested `json:"fqdns" validate:"omitempty,dive"`
 }
 
@@ -20,6 +23,15 @@ type UpdateWebroot struct {
 	RuntimeVersion         string          `json:"runtime_version"`
 	RuntimeConfig          json.RawMessage `json:"runtime_config"`
 	PublicFolder           *string         `json:"public_folder"`
+	ErrorPages             map[int]string  `json:"error_pages"`
 	EnvFileName            *string         `json:"env_file_name"`
 	ServiceHostnameEnabled *bool           `json:"service_hostname_enabled"`
+	AccessLogEnabled       *bool           `json:"access_log_enabled"`
+}
+
+// CloneWebroot selects what is cloned besides the webroot's settings and
+// files. Secret env vars missing from SecretEnvVars get a new random value.
+type CloneWebroot struct {
+	DatabaseID    string            `json:"database_id"`
+	SecretEnvVars map[string]string `json:"secret_env_vars" validate:"omitempty,dive,required"`
 }
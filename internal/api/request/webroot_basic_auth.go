@@ -0,0 +1,56 @@
+package request
+
+import (
+	"fmt"
+	"regexp"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// basicAuthUsernameRe keeps usernames safe to write to an htpasswd file.
+var basicAuthUsernameRe = regexp.MustCompile(`^[A-Za-z0-9._@-]{1,64}$`)
+
+// SetWebrootBasicAuth replaces the users of a webroot's HTTP basic auth. An
+// empty list disables basic auth.
+type SetWebrootBasicAuth struct {
+	Users []BasicAuthUser `json:"users" validate:"dive"`
+}
+
+// BasicAuthUser is one basic-auth user. Exactly one of Password (hashed with
+// bcrypt by the API) and PasswordHash (an existing bcrypt hash) must be set.
+type BasicAuthUser struct {
+	Username     string `json:"username" validate:"required"`
+	Password     string `json:"password,omitempty"`
+	PasswordHash string `json:"password_hash,omitempty"`
+}
+
+// Validate checks usernames, rejects duplicates and checks that each user has
+// exactly one usable password or bcrypt hash.
+func (r *SetWebrootBasicAuth) Validate() error {
+	seen := make(map[string]bool, len(r.Users))
+	for _, u := range r.Users {
+		if !basicAuthUsernameRe.MatchString(u.Username) {
+			return fmt.Errorf("invalid username %q: must match %s", u.Username, basicAuthUsernameRe.String())
+		}
+		if seen[u.Username] {
+			return fmt.Errorf("duplicate username %q", u.Username)
+		}
+		seen[u.Username] = true
+
+		switch {
+		case u.Password != "" && u.PasswordHash != "":
+			return fmt.Errorf("user %q: set either password or password_hash, not both", u.Username)
+		case u.Password != "":
+			if len(u.Password) < 8 || len(u.Password) > 72 {
+				return fmt.Errorf("user %q: password must be 8 to 72 bytes", u.Username)
+			}
+		case u.PasswordHash != "":
+			if _, err := bcrypt.Cost([]byte(u.PasswordHash)); err != nil {
+				return fmt.Errorf("user %q: password_hash must be a bcrypt hash", u.Username)
+			}
+		default:
+			return fmt.Errorf("user %q: password or password_hash is required", u.Username)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetWebrootBasicAuth_Validate(t *testing.T) {
+	const hash = "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy"
+
+	tests := []struct {
+		name    string
+		users   []BasicAuthUser
+		wantErr string
+	}{
+		{"empty disables", nil, ""},
+		{"password", []BasicAuthUser{{Username: "staging", Password: "s3cret-pass"}}, ""},
+		{"bcrypt hash", []BasicAuthUser{{Username: "ci@example.com", PasswordHash: hash}}, ""},
+		{"colon in username", []BasicAuthUser{{Username: "a:b", Password: "s3cret-pass"}}, "invalid username"},
+		{"duplicate", []BasicAuthUser{{Username: "a", Password: "s3cret-pass"}, {Username: "a", PasswordHash: hash}}, "duplicate username"},
+		{"both set", []BasicAuthUser{{Username: "a", Password: "s3cret-pass", PasswordHash: hash}}, "not both"},
+		{"neither set", []BasicAuthUser{{Username: "a"}}, "is required"},
+		{"short password", []BasicAuthUser{{Username: "a", Password: "short"}}, "8 to 72 bytes"},
+		{"plain text hash", []BasicAuthUser{{Username: "a", PasswordHash: "hunter2hunter2"}}, "must be a bcrypt hash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := SetWebrootBasicAuth{Users: tt.users}
+			err := req.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
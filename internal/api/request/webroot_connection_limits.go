@@ -0,0 +1,19 @@
+package request
+
+import "errors"
+
+// SetWebrootConnectionLimits sets the per-client-IP limits of a webroot.
+// Zero disables a limit.
+type SetWebrootConnectionLimits struct {
+	MaxConnsPerIP     int `json:"max_conns_per_ip" validate:"min=0,max=10000"`
+	RequestsPerSecond int `json:"requests_per_second" validate:"min=0,max=10000"`
+	Burst             int `json:"burst" validate:"min=0,max=10000"`
+}
+
+// Validate rejects a burst without a request rate to apply it to.
+func (r *SetWebrootConnectionLimits) Validate() error {
+	if r.Burst > 0 && r.RequestsPerSecond == 0 {
+		return errors.New("burst requires requests_per_second")
+	}
+	return nil
+}
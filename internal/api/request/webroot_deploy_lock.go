@@ -0,0 +1,14 @@
+package request
+
+import "time"
+
+// SetWebrootDeployLock locks a webroot for ttl_seconds. The maximum matches
+// model.WebrootDeployLockMaxTTL.
+type SetWebrootDeployLock struct {
+	TTLSeconds int `json:"ttl_seconds" validate:"required,min=1,max=600"`
+}
+
+// TTL returns the lock duration.
+func (r *SetWebrootDeployLock) TTL() time.Duration {
+	return time.Duration(r.TTLSeconds) * time.Second
+}
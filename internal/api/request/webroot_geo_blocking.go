@@ -0,0 +1,29 @@
+package request
+
+import (
+	"strings"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// SetWebrootGeoBlocking restricts a webroot to (allow) or blocks (deny)
+// clients from a list of ISO 3166-1 alpha-2 country codes.
+type SetWebrootGeoBlocking struct {
+	Mode      string   `json:"mode" validate:"required,oneof=allow deny"`
+	Countries []string `json:"countries" validate:"required,min=1,max=249"`
+}
+
+// GeoBlocking returns the request as the webroot's geo-blocking config, with
+// country codes in upper case.
+func (r *SetWebrootGeoBlocking) GeoBlocking() model.WebrootGeoBlocking {
+	countries := make([]string, len(r.Countries))
+	for i, c := range r.Countries {
+		countries[i] = strings.ToUpper(strings.TrimSpace(c))
+	}
+	return model.WebrootGeoBlocking{Mode: r.Mode, Countries: countries}
+}
+
+// Validate checks the country codes.
+func (r *SetWebrootGeoBlocking) Validate() error {
+	return r.GeoBlocking().Validate()
+}
@@ -0,0 +1,20 @@
+package request
+
+import "github.com/edvin/hosting/internal/model"
+
+// SetWebrootStaticRules replaces the caching rules and MIME types of a
+// webroot.
+type SetWebrootStaticRules struct {
+	CacheRules []model.WebrootCacheRule `json:"cache_rules"`
+	MimeTypes  map[string]string        `json:"mime_types"`
+}
+
+// Rules returns the request as the webroot's static rules.
+func (r *SetWebrootStaticRules) Rules() model.WebrootStaticRules {
+	return model.WebrootStaticRules{CacheRules: r.CacheRules, MimeTypes: r.MimeTypes}
+}
+
+// Validate checks the patterns and header values.
+func (r *SetWebrootStaticRules) Validate() error {
+	return r.Rules().Validate()
+}
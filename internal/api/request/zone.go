@@ -6,8 +6,11 @@ type CreateZone struct {
 	TenantID       string `json:"tenant_id"`
 	SubscriptionID string `json:"subscription_id" validate:"required"`
 	RegionID       string `json:"region_id" validate:"required"`
+	// SecondaryRegionIDs are regions whose nameservers also serve the zone.
+	SecondaryRegionIDs []string `json:"secondary_region_ids" validate:"omitempty,max=5,dive,required"`
 }
 
 type UpdateZone struct {
-	TenantID *string `json:"tenant_id"`
+	TenantID           *string   `json:"tenant_id"`
+	SecondaryRegionIDs *[]string `json:"secondary_region_ids" validate:"omitempty,max=5,dive,required"`
 }
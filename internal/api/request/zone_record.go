@@ -13,3 +13,8 @@ type UpdateZoneRecord struct {
 	TTL      *int   `json:"ttl" validate:"omitempty,min=60,max=86400"`
 	Priority *int   `json:"priority"`
 }
+
+// ReplaceZoneRecords is the full desired set of a zone's custom records.
+type ReplaceZoneRecords struct {
+	Records []CreateZoneRecord `json:"records" validate:"required,dive"`
+}
@@ -1,12 +1,15 @@
 package response
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"net/http"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/edvin/hosting/internal/model"
 )
 
 func WriteJSON(w http.ResponseWriter, status int, v any) {
@@ -15,13 +18,53 @@ func WriteJSON(w http.ResponseWriter, status int, v any) {
 	json.NewEncoder(w).Encode(v)
 }
 
-// ErrorResponse is the standard error response body.
+// WriteJSONWithWarnings writes v like WriteJSON with a top-level "warnings"
+// array added to it. v must encode to a JSON object. Without warnings the
+// body is exactly what WriteJSON writes.
+func WriteJSONWithWarnings(w http.ResponseWriter, status int, v any, warnings []model.Warning) {
+	if len(warnings) == 0 {
+		WriteJSON(w, status, v)
+		return
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	list, err := json.Marshal(warnings)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	body = bytes.TrimSpace(body)
+	if len(body) < 2 || body[0] != '{' {
+		WriteError(w, http.StatusInternalServerError, "response with warnings is not a JSON object")
+		return
+	}
+	var buf bytes.Buffer
+	buf.Write(body[:len(body)-1])
+	if len(body) > 2 {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(`"warnings":`)
+	buf.Write(list)
+	buf.WriteString("}\n")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+// ErrorResponse is the standard error response body. RequestID matches the
+// X-Request-ID response header so users can reference it in support tickets.
 type ErrorResponse struct {
-	Error string `json:"error"`
+	Error     string `json:"error"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 func WriteError(w http.ResponseWriter, status int, message string) {
-	WriteJSON(w, status, ErrorResponse{Error: message})
+	// The request logger sets the header before any handler runs.
+	WriteJSON(w, status, ErrorResponse{Error: message, RequestID: w.Header().Get("X-Request-ID")})
 }
 
 // WriteServiceError maps well-known service errors to appropriate HTTP status
@@ -8,6 +8,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/model"
 )
 
 func TestWriteJSON(t *testing.T) {
@@ -50,3 +52,43 @@ func TestWriteJSON_NilValue(t *testing.T) {
 	// json.Encode(nil) produces "null\n"
 	assert.Equal(t, "null\n", w.Body.String())
 }
+
+func TestWriteJSONWithWarnings(t *testing.T) {
+	w := httptest.NewRecorder()
+	payload := struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}{"f1", "example.com"}
+
+	WriteJSONWithWarnings(w, http.StatusAccepted, payload, []model.Warning{
+		{Code: model.WarningDNSNotPointed, Message: "example.com does not resolve"},
+	})
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"id":"f1","name":"example.com","warnings":[{"code":"dns_not_pointed","message":"example.com does not resolve"}]}`, w.Body.String())
+}
+
+func TestWriteJSONWithWarnings_NoWarnings(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	WriteJSONWithWarnings(w, http.StatusOK, map[string]string{"id": "f1"}, nil)
+
+	assert.Equal(t, "{\"id\":\"f1\"}\n", w.Body.String())
+}
+
+func TestWriteJSONWithWarnings_EmptyObject(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	WriteJSONWithWarnings(w, http.StatusOK, struct{}{}, []model.Warning{{Code: "c", Message: "m"}})
+
+	assert.JSONEq(t, `{"warnings":[{"code":"c","message":"m"}]}`, w.Body.String())
+}
+
+func TestWriteJSONWithWarnings_NotAnObject(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	WriteJSONWithWarnings(w, http.StatusOK, []string{"a"}, []model.Warning{{Code: "c", Message: "m"}})
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -16,9 +16,13 @@ import (
 
 	"github.com/edvin/hosting/internal/api/handler"
 	mw "github.com/edvin/hosting/internal/api/middleware"
+	"github.com/edvin/hosting/internal/api/response"
 	"github.com/edvin/hosting/internal/config"
 	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/ipallow"
+	"github.com/edvin/hosting/internal/logging"
 	"github.com/edvin/hosting/internal/mcpserver"
+	"github.com/edvin/hosting/internal/objectstore"
 	"github.com/edvin/hosting/internal/sshca"
 )
 
@@ -35,15 +39,30 @@ type Server struct {
 	corePool       *pgxpool.Pool
 	temporalClient temporalclient.Client
 	cfg            *config.Config
+	cfgStore       *config.Store
 	auditLogger    *mw.AuditLogger
+	inFlight       mw.InFlight
 }
 
-func NewServer(logger zerolog.Logger, coreDB *pgxpool.Pool, temporalClient temporalclient.Client, cfg *config.Config) *Server {
+func NewServer(logger zerolog.Logger, coreDB *pgxpool.Pool, temporalClient temporalclient.Client, cfgStore *config.Store) *Server {
+	cfg := cfgStore.Get()
 	services := core.NewServices(coreDB, temporalClient, cfg.OIDCIssuerURL, cfg.SecretEncryptionKey)
 	// Override WireGuard endpoint from config.
 	if cfg.WireGuardEndpoint != "" {
 		services.WireGuardPeer = core.NewWireGuardPeerService(coreDB, temporalClient, cfg.WireGuardEndpoint)
 	}
+	// Egress rules may not open blocked ranges (validated at startup) to tenants.
+	egressBlocklist, _ := ipallow.ParsePrefixes(cfg.EgressCIDRBlocklist)
+	services.TenantEgressRule = core.NewTenantEgressRuleService(coreDB, temporalClient, egressBlocklist)
+	// Relay send counts come from Stalwart's log in the platform Loki.
+	services.SMTPRelayUser = core.NewSMTPRelayUserService(coreDB, temporalClient, cfg.LokiURL)
+	// Tenant exports need object storage for the archives, and backup
+	// downloads stage files through the same bucket.
+	if bucket := objectstore.New(cfg.ExportS3Endpoint, cfg.ExportS3Region, cfg.ExportS3Bucket, cfg.ExportS3AccessKey, cfg.ExportS3SecretKey); bucket != nil {
+		services.TenantExport = core.NewTenantExportService(coreDB, temporalClient, bucket, cfg.ExportRetentionDays)
+		services.BackupDownload = core.NewBackupDownloadService(coreDB, temporalClient, bucket, cfg.SecretEncryptionKey,
+			cfg.OIDCIssuerURL, time.Duration(cfg.BackupDownloadURLTTLSecs)*time.Second)
+	}
 	auditLogger := mw.NewAuditLogger(coreDB, logger)
 
 	s := &Server{
@@ -53,6 +72,7 @@ func NewServer(logger zerolog.Logger, coreDB *pgxpool.Pool, temporalClient tempo
 		corePool:       coreDB,
 		temporalClient: temporalClient,
 		cfg:            cfg,
+		cfgStore:       cfgStore,
 		auditLogger:    auditLogger,
 	}
 
@@ -63,11 +83,41 @@ func NewServer(logger zerolog.Logger, coreDB *pgxpool.Pool, temporalClient tempo
 }
 
 func (s *Server) setupMiddleware() {
+	apiAllowlist, err := ipallow.New(s.cfg.APIIPAllowlist, s.cfg.TrustedProxies)
+	if err != nil {
+		s.logger.Fatal().Err(err).Msg("invalid API_IP_ALLOWLIST")
+	}
+	ssoAllowlist, err := ipallow.New(s.cfg.SSOIPAllowlist, s.cfg.TrustedProxies)
+	if err != nil {
+		s.logger.Fatal().Err(err).Msg("invalid SSO_IP_ALLOWLIST")
+	}
+	corsOrigins, err := s.cfg.CORSOrigins()
+	if err != nil {
+		s.logger.Fatal().Err(err).Msg("invalid CORS_ALLOWED_ORIGINS")
+	}
+	corsPolicy := mw.CORSPolicy{
+		AllowedOrigins:   corsOrigins,
+		AllowedMethods:   config.SplitList(s.cfg.CORSAllowedMethods),
+		AllowedHeaders:   config.SplitList(s.cfg.CORSAllowedHeaders),
+		AllowCredentials: s.cfg.CORSAllowCredentials,
+		MaxAgeSecs:       s.cfg.CORSMaxAgeSecs,
+	}
+
+	s.router.Use(s.inFlight.Middleware)
 	s.router.Use(middleware.RequestID)
-	s.router.Use(middleware.RealIP)
 	s.router.Use(mw.RequestLogger(s.logger))
+	// The allowlist resolves the client address itself and must see the
+	// original peer address, so it runs before RealIP rewrites it.
+	s.router.Use(mw.IPAllowlist(apiAllowlist, ssoAllowlist))
+	// CORS runs ahead of the route groups so preflights are answered before
+	// Auth, which would reject them for lacking a bearer token.
+	s.router.Use(mw.CORS(corsPolicy))
+	s.router.Use(middleware.RealIP)
 	s.router.Use(middleware.Recoverer)
 	s.router.Use(mw.Metrics)
+	// Bodies are capped before any middleware (notably the audit logger)
+	// or handler reads them.
+	s.router.Use(mw.MaxBodySize(int64(s.cfg.MaxRequestBodyBytes), int64(s.cfg.MaxUploadBodyBytes)))
 }
 
 func (s *Server) setupRoutes() {
@@ -118,44 +168,57 @@ func (s *Server) setupRoutes() {
 		s.router.Get("/api/v1/tenants/{tenantID}/terminal", terminal.Connect)
 	}
 
+	// Backup downloads are authenticated by the signed token in the URL
+	// rather than an API key, so they are served outside the auth group.
+	backupDownload := handler.NewBackupDownload(s.services.BackupDownload, s.services.Backup, s.services.Tenant, s.auditLogger)
+	s.router.Get("/api/v1/backups/{id}/download", backupDownload.Download)
+
 	s.router.Route("/api/v1", func(r chi.Router) {
 		r.Use(mw.Auth(s.corePool))
 		r.Use(mw.CallbackURL)
+		r.Use(mw.Operations)
 		r.Use(s.auditLogger.Middleware)
+		r.Use(mw.Idempotency(s.services.Idempotency, s.logger))
 
 		// Initialize handlers
 		dashboard := handler.NewDashboard(s.services.Dashboard)
 		audit := handler.NewAudit(s.corePool)
-		logs := handler.NewLogs(s.cfg.LokiURL, s.cfg.TenantLokiURL)
+		logs := handler.NewLogs(s.cfg.LokiURL, s.cfg.TenantLokiURL, s.services.Ownership)
 		platformCfg := handler.NewPlatformConfig(s.services.PlatformConfig)
 		brand := handler.NewBrand(s.services.Brand)
+		reseller := handler.NewReseller(s.services)
 		region := handler.NewRegion(s.services.Region)
 		cluster := handler.NewCluster(s.services.Cluster)
 		clusterLBAddress := handler.NewClusterLBAddressHandler(s.services.ClusterLBAddress)
 		shard := handler.NewShard(s.services.Shard)
 		node := handler.NewNode(s.services.Node)
 		tenant := handler.NewTenant(s.services)
-		oidcLogin := handler.NewOIDCLogin(s.services.OIDC, s.temporalClient)
+		loginSession := handler.NewLoginSession(s.services.LoginSession, s.services.Tenant)
+		oidcLogin := handler.NewOIDCLogin(s.services.OIDC, s.services.LoginSession, s.services.Ownership, s.temporalClient)
 		oidcClient := handler.NewOIDCClient(s.services.OIDC)
 		webroot := handler.NewWebroot(s.services)
 		fqdn := handler.NewFQDN(s.services)
-		cert := handler.NewCertificate(s.services.Certificate)
+		cert := handler.NewCertificate(s.services.Certificate, s.services.Ownership)
 		zone := handler.NewZone(s.services)
-		zoneRecord := handler.NewZoneRecord(s.services.ZoneRecord)
+		zoneRecord := handler.NewZoneRecord(s.services.ZoneRecord, s.services.Tenant, s.services.Ownership)
 		database := handler.NewDatabase(s.services.Database, s.services.DatabaseUser, s.services.Tenant)
-		dbUser := handler.NewDatabaseUser(s.services.DatabaseUser, s.services.Database)
+		dbUser := handler.NewDatabaseUser(s.services.DatabaseUser, s.services.Database, s.services.Tenant, s.services.Ownership)
 		valkeyInstance := handler.NewValkeyInstance(s.services.ValkeyInstance, s.services.ValkeyUser, s.services.Tenant)
-		valkeyUser := handler.NewValkeyUser(s.services.ValkeyUser, s.services.ValkeyInstance)
+		valkeyUser := handler.NewValkeyUser(s.services.ValkeyUser, s.services.ValkeyInstance, s.services.Tenant, s.services.Ownership)
 		s3Bucket := handler.NewS3Bucket(s.services.S3Bucket, s.services.S3AccessKey, s.services.Tenant)
-		s3AccessKey := handler.NewS3AccessKey(s.services.S3AccessKey)
+		s3AccessKey := handler.NewS3AccessKey(s.services.S3AccessKey, s.services.S3Bucket, s.services.Tenant, s.services.Ownership)
 		sshKey := handler.NewSSHKey(s.services.SSHKey, s.services.Tenant)
 		egressRule := handler.NewTenantEgressRule(s.services.TenantEgressRule, s.services.Tenant)
 		subscription := handler.NewSubscription(s.services)
 		emailAccount := handler.NewEmailAccount(s.services)
-		emailAlias := handler.NewEmailAlias(s.services.EmailAlias)
-		emailForward := handler.NewEmailForward(s.services.EmailForward)
-		emailAutoReply := handler.NewEmailAutoReply(s.services.EmailAutoReply)
+		emailAlias := handler.NewEmailAlias(s.services.EmailAlias, s.services.Ownership)
+		emailForward := handler.NewEmailForward(s.services.EmailForward, s.services.Ownership)
+		emailAutoReply := handler.NewEmailAutoReply(s.services.EmailAutoReply, s.services.Ownership)
+		smtpRelayUser := handler.NewSMTPRelayUser(s.services.SMTPRelayUser, s.services.Tenant)
 		backup := handler.NewBackup(s.services.Backup, s.services.Webroot, s.services.Database, s.services.Tenant)
+		tenantExport := handler.NewTenantExport(s.services.TenantExport, s.services.Tenant)
+		operation := handler.NewOperation(s.services.Operation, s.services.Tenant)
+		statusReset := handler.NewStatusReset(s.services.StatusReset)
 		search := handler.NewSearch(s.services.Search)
 		apiKey := handler.NewAPIKey(s.services.APIKey)
 		internalNode := handler.NewInternalNode(s.services.DesiredState, s.services.NodeHealth, s.services.CronJob)
@@ -163,6 +226,9 @@ func (s *Server) setupRoutes() {
 		capabilityGap := handler.NewCapabilityGap(s.services.CapabilityGap)
 		wireguardPeer := handler.NewWireGuardPeer(s.services.WireGuardPeer, s.services.Tenant)
 
+		// Caller identity (no scope required)
+		r.Get("/me", apiKey.Me)
+
 		// Workflow await (admin-only, blocks until workflow completes)
 		workflow := handler.NewWorkflow(s.temporalClient)
 		r.Group(func(r chi.Router) {
@@ -176,6 +242,7 @@ func (s *Server) setupRoutes() {
 
 			// Dashboard
 			r.Get("/dashboard/stats", dashboard.Stats)
+			r.Get("/failures", dashboard.Failures)
 
 			// Audit logs
 			r.Get("/audit-logs", audit.List)
@@ -193,6 +260,24 @@ func (s *Server) setupRoutes() {
 			// Search
 			r.Get("/search", search.Search)
 
+			// Workflow history of operations
+			r.Group(func(r chi.Router) {
+				r.Use(mw.RequireScope("operations", "read"))
+				r.Get("/operations/{id}/history", operation.History)
+			})
+
+			// Stuck status recovery
+			r.Post("/tenants/{id}/reset-status", statusReset.Tenant)
+			r.Post("/webroots/{id}/reset-status", statusReset.Webroot)
+			r.Post("/databases/{id}/reset-status", statusReset.Database)
+			r.Post("/certificates/{id}/reset-status", statusReset.Certificate)
+
+			// Tenant brand reassignment
+			r.Post("/tenants/{id}/reassign-brand", tenant.ReassignBrand)
+
+			// Tenant storage permission repair
+			r.Post("/tenants/{id}/fix-permissions", tenant.FixPermissions)
+
 			// OIDC clients (admin)
 			r.Post("/oidc/clients", oidcClient.Create)
 
@@ -204,7 +289,7 @@ func (s *Server) setupRoutes() {
 			})
 			r.Group(func(r chi.Router) {
 				r.Use(mw.RequireScope("api_keys", "write"))
-				r.Post("/api-keys", apiKey.Create)
+				r.With(mw.SecretResponse).Post("/api-keys", apiKey.Create)
 				r.Put("/api-keys/{id}", apiKey.Update)
 			})
 			r.Group(func(r chi.Router) {
@@ -267,12 +352,16 @@ func (s *Server) setupRoutes() {
 				r.Use(mw.RequireScope("shards", "read"))
 				r.Get("/clusters/{clusterID}/shards", shard.ListByCluster)
 				r.Get("/shards/{id}", shard.Get)
+				r.Get("/shards/{id}/converge", shard.ConvergeStatus)
+				r.Get("/converge-batches/{id}", shard.GetConvergeBatch)
 			})
 			r.Group(func(r chi.Router) {
 				r.Use(mw.RequireScope("shards", "write"))
 				r.Post("/clusters/{clusterID}/shards", shard.Create)
 				r.Put("/shards/{id}", shard.Update)
 				r.Post("/shards/{id}/converge", shard.Converge)
+				r.Post("/shards/{id}/promote-replica", shard.PromoteReplica)
+				r.Post("/clusters/{clusterID}/converge", shard.ConvergeCluster)
 				r.Post("/shards/{id}/retry", shard.Retry)
 			})
 			r.Group(func(r chi.Router) {
@@ -285,6 +374,10 @@ func (s *Server) setupRoutes() {
 				r.Use(mw.RequireScope("nodes", "read"))
 				r.Get("/clusters/{clusterID}/nodes", node.ListByCluster)
 				r.Get("/nodes/{id}", node.Get)
+				r.Get("/nodes/{id}/diagnostics", node.Diagnostics)
+				r.Get("/nodes/{id}/stats", node.Stats)
+				r.Get("/nodes/{id}/capabilities", node.Capabilities)
+				r.Get("/nodes/{id}/resources", node.Resources)
 			})
 			r.Group(func(r chi.Router) {
 				r.Use(mw.RequireScope("nodes", "write"))
@@ -296,21 +389,28 @@ func (s *Server) setupRoutes() {
 				r.Delete("/nodes/{id}", node.Delete)
 			})
 
-			// Internal API (node agent, cron outcome reporting)
-			r.Group(func(r chi.Router) {
-				r.Use(mw.RequireScope("nodes", "read"))
-				r.Get("/internal/v1/nodes/{nodeID}/desired-state", internalNode.GetDesiredState)
-				r.Get("/internal/v1/nodes/{nodeID}/health", internalNode.GetHealth)
-				r.Get("/internal/v1/nodes/{nodeID}/drift-events", internalNode.ListDriftEvents)
-			})
+			// Internal API (node agent, cron outcome reporting, dbadmin-proxy).
+			// Signed with INTERNAL_SIGNING_SECRET when set.
 			r.Group(func(r chi.Router) {
-				r.Use(mw.RequireScope("nodes", "write"))
-				r.Post("/internal/v1/nodes/{nodeID}/health", internalNode.ReportHealth)
-				r.Post("/internal/v1/nodes/{nodeID}/drift-events", internalNode.ReportDriftEvents)
-				r.Post("/internal/v1/cron-jobs/{cronJobID}/outcome", internalNode.ReportCronOutcome)
-				r.Post("/internal/v1/login-sessions/validate", oidcLogin.ValidateLoginSession)
-				r.Post("/internal/v1/databases/{id}/temp-access", oidcLogin.CreateTempAccess)
+				r.Use(mw.RequireSignature(s.cfg.InternalSigningSecret))
+				r.Group(func(r chi.Router) {
+					r.Use(mw.RequireScope("nodes", "read"))
+					r.Get("/internal/v1/nodes/{nodeID}/desired-state", internalNode.GetDesiredState)
+					r.Get("/internal/v1/nodes/{nodeID}/health", internalNode.GetHealth)
+					r.Get("/internal/v1/nodes/{nodeID}/drift-events", internalNode.ListDriftEvents)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(mw.RequireScope("nodes", "write"))
+					r.Post("/internal/v1/nodes/{nodeID}/health", internalNode.ReportHealth)
+					r.Post("/internal/v1/nodes/{nodeID}/drift-events", internalNode.ReportDriftEvents)
+					r.Post("/internal/v1/cron-jobs/{cronJobID}/outcome", internalNode.ReportCronOutcome)
+					r.Post("/internal/v1/login-sessions/validate", oidcLogin.ValidateLoginSession)
+					r.With(mw.SecretResponse).Post("/internal/v1/databases/{id}/temp-access", oidcLogin.CreateTempAccess)
+				})
 			})
+
+			// Config hot-reload (same as sending SIGHUP to the process)
+			r.Post("/internal/v1/reload-config", s.handleReloadConfig)
 		})
 
 		// Brand-scoped endpoints — scope middleware per resource group
@@ -320,18 +420,51 @@ func (s *Server) setupRoutes() {
 			r.Get("/brands", brand.List)
 			r.Get("/brands/{id}", brand.Get)
 			r.Get("/brands/{id}/clusters", brand.ListClusters)
+			r.Get("/brands/{id}/zone-templates", brand.ListZoneTemplates)
+			r.Get("/brands/{id}/app-templates", brand.ListAppTemplates)
+			r.Get("/brands/{id}/acme", brand.GetACMEConfig)
+			r.Get("/brands/{id}/runtime-defaults", brand.GetRuntimeDefaults)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("brands", "write"))
+			r.Use(mw.RequireBrandLevel())
 			r.Post("/brands", brand.Create)
 			r.Put("/brands/{id}", brand.Update)
 			r.Put("/brands/{id}/clusters", brand.SetClusters)
+			r.Put("/brands/{id}/zone-templates", brand.SetZoneTemplates)
+			r.Put("/brands/{id}/app-templates", brand.SetAppTemplates)
+			r.Put("/brands/{id}/acme", brand.SetACMEConfig)
+			r.Delete("/brands/{id}/acme", brand.DeleteACMEConfig)
+			r.Put("/brands/{id}/runtime-defaults", brand.SetRuntimeDefaults)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("brands", "delete"))
+			r.Use(mw.RequireBrandLevel())
 			r.Delete("/brands/{id}", brand.Delete)
 		})
 
+		// Resellers
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope("resellers", "read"))
+			r.Get("/resellers", reseller.List)
+			r.Get("/resellers/{id}", reseller.Get)
+			r.Get("/resellers/{id}/tenants", reseller.ListTenants)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope("resellers", "write"))
+			r.Use(mw.RequireBrandLevel())
+			r.Post("/resellers", reseller.Create)
+			r.Put("/resellers/{id}", reseller.Update)
+			r.Post("/resellers/{id}/tenants", reseller.AssignTenant)
+			r.Delete("/resellers/{id}/tenants/{tenantID}", reseller.UnassignTenant)
+			r.With(mw.SecretResponse).Post("/resellers/{id}/api-keys", reseller.CreateAPIKey)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope("resellers", "delete"))
+			r.Use(mw.RequireBrandLevel())
+			r.Delete("/resellers/{id}", reseller.Delete)
+		})
+
 		// Tenants
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("tenants", "read"))
@@ -339,6 +472,13 @@ func (s *Server) setupRoutes() {
 			r.Get("/tenants/{id}", tenant.Get)
 			r.Get("/tenants/{id}/resource-summary", tenant.ResourceSummary)
 			r.Get("/tenants/{id}/resource-usage", tenant.ResourceUsage)
+			r.Get("/tenants/{id}/migration-status", tenant.MigrationStatus)
+			r.Get("/tenants/{id}/brand-reassignment", tenant.BrandReassignmentStatus)
+			r.Get("/tenants/{id}/lb-split", tenant.GetLBSplit)
+			r.Get("/tenants/{id}/maintenance-window", tenant.GetMaintenanceWindow)
+			r.Get("/tenants/{id}/backup-schedule", tenant.GetBackupSchedule)
+			r.Get("/tenants/{id}/sessions", loginSession.ListByTenant)
+			r.Get("/tenants/{id}/events", tenant.Events)
 			r.Get("/tenants/{tenantID}/logs", logs.TenantLogs)
 		})
 		r.Group(func(r chi.Router) {
@@ -348,9 +488,16 @@ func (s *Server) setupRoutes() {
 			r.Post("/tenants/{id}/suspend", tenant.Suspend)
 			r.Post("/tenants/{id}/unsuspend", tenant.Unsuspend)
 			r.Post("/tenants/{id}/migrate", tenant.Migrate)
+			r.Put("/tenants/{id}/lb-split", tenant.SetLBSplit)
+			r.Delete("/tenants/{id}/lb-split", tenant.DeleteLBSplit)
+			r.Put("/tenants/{id}/maintenance-window", tenant.SetMaintenanceWindow)
+			r.Delete("/tenants/{id}/maintenance-window", tenant.DeleteMaintenanceWindow)
+			r.Put("/tenants/{id}/backup-schedule", tenant.SetBackupSchedule)
 			r.Post("/tenants/{id}/retry", tenant.Retry)
 			r.Post("/tenants/{id}/retry-failed", tenant.RetryFailed)
 			r.Post("/tenants/{id}/login-sessions", oidcLogin.CreateLoginSession)
+			r.Delete("/tenants/{id}/sessions", loginSession.RevokeAll)
+			r.Delete("/tenants/{id}/sessions/{sessionID}", loginSession.Revoke)
 			r.Delete("/tenants/{tenantID}/logs", logs.DeleteTenantLogs)
 		})
 		r.Group(func(r chi.Router) {
@@ -377,17 +524,35 @@ func (s *Server) setupRoutes() {
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("webroots", "read"))
 			r.Get("/tenants/{tenantID}/webroots", webroot.ListByTenant)
+			r.Get("/tenants/{tenantID}/app-templates", webroot.ListTemplates)
 			r.Get("/webroots/{id}", webroot.Get)
+			r.Get("/webroots/{id}/nginx-preview", webroot.NginxPreview)
+			r.Get("/webroots/{id}/access-logs", webroot.AccessLogs)
+			r.Get("/webroots/{id}/basic-auth", webroot.GetBasicAuth)
+			r.Get("/webroots/{id}/connection-limits", webroot.GetConnectionLimits)
+			r.Get("/webroots/{id}/static-rules", webroot.GetStaticRules)
+			r.Get("/webroots/{id}/geo-blocking", webroot.GetGeoBlocking)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("webroots", "write"))
 			r.Post("/tenants/{tenantID}/webroots", webroot.Create)
 			r.Put("/webroots/{id}", webroot.Update)
+			r.Put("/webroots/{id}/basic-auth", webroot.SetBasicAuth)
+			r.Put("/webroots/{id}/connection-limits", webroot.SetConnectionLimits)
+			r.Put("/webroots/{id}/static-rules", webroot.SetStaticRules)
+			r.Put("/webroots/{id}/geo-blocking", webroot.SetGeoBlocking)
+			r.Post("/webroots/{id}/deploy-lock", webroot.SetDeployLock)
 			r.Post("/webroots/{id}/retry", webroot.Retry)
+			r.Post("/webroots/{id}/clone", webroot.Clone)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("webroots", "delete"))
 			r.Delete("/webroots/{id}", webroot.Delete)
+			r.Delete("/webroots/{id}/basic-auth", webroot.DeleteBasicAuth)
+			r.Delete("/webroots/{id}/connection-limits", webroot.DeleteConnectionLimits)
+			r.Delete("/webroots/{id}/static-rules", webroot.DeleteStaticRules)
+			r.Delete("/webroots/{id}/geo-blocking", webroot.DeleteGeoBlocking)
+			r.Delete("/webroots/{id}/deploy-lock", webroot.DeleteDeployLock)
 		})
 
 		// Webroot env vars
@@ -405,6 +570,19 @@ func (s *Server) setupRoutes() {
 			r.Delete("/webroots/{webrootID}/env-vars/{name}", envVar.Delete)
 		})
 
+		// Webroot releases
+		release := handler.NewWebrootRelease(s.services)
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope("webroots", "read"))
+			r.Get("/webroots/{webrootID}/releases", release.ListByWebroot)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope("webroots", "write"))
+			r.Post("/webroots/{webrootID}/releases", release.Create)
+			r.Post("/webroots/{webrootID}/releases/{releaseID}/promote", release.Promote)
+			r.Post("/webroots/{id}/rollback", release.Rollback)
+		})
+
 		// Vault encrypt/decrypt
 		vault := handler.NewVault(s.services)
 		r.Group(func(r chi.Router) {
@@ -446,6 +624,8 @@ func (s *Server) setupRoutes() {
 			r.Put("/cron-jobs/{id}", cronJob.Update)
 			r.Post("/cron-jobs/{id}/enable", cronJob.Enable)
 			r.Post("/cron-jobs/{id}/disable", cronJob.Disable)
+			r.Post("/cron-jobs/{id}/pause", cronJob.Pause)
+			r.Post("/cron-jobs/{id}/resume", cronJob.Resume)
 			r.Post("/cron-jobs/{id}/retry", cronJob.Retry)
 		})
 		r.Group(func(r chi.Router) {
@@ -453,12 +633,28 @@ func (s *Server) setupRoutes() {
 			r.Delete("/cron-jobs/{id}", cronJob.Delete)
 		})
 
+		// Cron job env vars
+		cronEnvVar := handler.NewCronJobEnvVar(s.services)
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope("cron_jobs", "read"))
+			r.Get("/cron-jobs/{id}/env-vars", cronEnvVar.List)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope("cron_jobs", "write"))
+			r.Put("/cron-jobs/{id}/env-vars", cronEnvVar.Set)
+		})
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope("cron_jobs", "delete"))
+			r.Delete("/cron-jobs/{id}/env-vars/{name}", cronEnvVar.Delete)
+		})
+
 		// FQDNs
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("fqdns", "read"))
 			r.Get("/tenants/{tenantID}/fqdns", fqdn.ListByTenant)
 			r.Get("/webroots/{webrootID}/fqdns", fqdn.ListByWebroot)
 			r.Get("/fqdns/{id}", fqdn.Get)
+			r.Get("/fqdns/{id}/dns-check", fqdn.DNSCheck)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("fqdns", "write"))
@@ -475,11 +671,16 @@ func (s *Server) setupRoutes() {
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("certificates", "read"))
 			r.Get("/fqdns/{fqdnID}/certificates", cert.ListByFQDN)
+			r.Get("/fqdns/{id}/certificate", fqdn.GetCertificate)
+			r.Get("/certificates/{id}/renew", cert.GetRenewal)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("certificates", "write"))
 			r.Post("/fqdns/{fqdnID}/certificates", cert.Upload)
+			r.Post("/fqdns/{id}/certificate", fqdn.ImportCertificate)
 			r.Post("/certificates/{id}/retry", cert.Retry)
+			r.Post("/certificates/{id}/renew", cert.Renew)
+			r.Post("/tenants/{tenantID}/certificates/bulk", cert.BulkProvision)
 		})
 
 		// Zones
@@ -487,12 +688,17 @@ func (s *Server) setupRoutes() {
 			r.Use(mw.RequireScope("zones", "read"))
 			r.Get("/zones", zone.List)
 			r.Get("/zones/{id}", zone.Get)
+			r.Get("/zones/{id}/dnssec", zone.GetDNSSEC)
+			r.Get("/zones/{id}/nameservers", zone.Nameservers)
+			r.Get("/zones/{id}/export", zone.Export)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("zones", "write"))
 			r.Post("/zones", zone.Create)
 			r.Put("/zones/{id}", zone.Update)
 			r.Post("/zones/{id}/retry", zone.Retry)
+			r.Post("/zones/{id}/dnssec", zone.Sign)
+			r.Delete("/zones/{id}/dnssec", zone.Unsign)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("zones", "delete"))
@@ -503,11 +709,14 @@ func (s *Server) setupRoutes() {
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("zone_records", "read"))
 			r.Get("/zones/{zoneID}/records", zoneRecord.ListByZone)
+			r.Post("/zones/{zoneID}/records/preview", zoneRecord.PreviewByZone)
 			r.Get("/zone-records/{id}", zoneRecord.Get)
+			r.Get("/tenants/{tenantID}/zone-records/search", zoneRecord.SearchByTenant)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("zone_records", "write"))
 			r.Post("/zones/{zoneID}/records", zoneRecord.Create)
+			r.Put("/zones/{zoneID}/records", zoneRecord.ReplaceByZone)
 			r.Put("/zone-records/{id}", zoneRecord.Update)
 			r.Post("/zone-records/{id}/retry", zoneRecord.Retry)
 		})
@@ -521,6 +730,8 @@ func (s *Server) setupRoutes() {
 			r.Use(mw.RequireScope("databases", "read"))
 			r.Get("/tenants/{tenantID}/databases", database.ListByTenant)
 			r.Get("/databases/{id}", database.Get)
+			r.Get("/databases/{id}/connections", database.ListConnections)
+			r.Get("/databases/{id}/connection-info", database.ConnectionInfo)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("databases", "write"))
@@ -531,6 +742,7 @@ func (s *Server) setupRoutes() {
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("databases", "delete"))
 			r.Delete("/databases/{id}", database.Delete)
+			r.Delete("/databases/{id}/connections/{pid}", database.KillConnection)
 		})
 
 		// Database users
@@ -555,10 +767,13 @@ func (s *Server) setupRoutes() {
 			r.Use(mw.RequireScope("valkey", "read"))
 			r.Get("/tenants/{tenantID}/valkey-instances", valkeyInstance.ListByTenant)
 			r.Get("/valkey-instances/{id}", valkeyInstance.Get)
+			r.Get("/valkey-instances/{id}/stats", valkeyInstance.Stats)
+			r.Get("/valkey-instances/{id}/connection-info", valkeyInstance.ConnectionInfo)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("valkey", "write"))
-			r.Post("/tenants/{tenantID}/valkey-instances", valkeyInstance.Create)
+			r.With(mw.SecretResponse).Post("/tenants/{tenantID}/valkey-instances", valkeyInstance.Create)
+			r.Put("/valkey-instances/{id}", valkeyInstance.Update)
 			r.Post("/valkey-instances/{id}/migrate", valkeyInstance.Migrate)
 			r.Post("/valkey-instances/{id}/retry", valkeyInstance.Retry)
 		})
@@ -594,6 +809,7 @@ func (s *Server) setupRoutes() {
 			r.Use(mw.RequireScope("s3", "write"))
 			r.Post("/tenants/{tenantID}/s3-buckets", s3Bucket.Create)
 			r.Put("/s3-buckets/{id}", s3Bucket.Update)
+			r.Put("/s3-buckets/{id}/lifecycle", s3Bucket.SetLifecycle)
 			r.Post("/s3-buckets/{id}/retry", s3Bucket.Retry)
 		})
 		r.Group(func(r chi.Router) {
@@ -608,7 +824,8 @@ func (s *Server) setupRoutes() {
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("s3", "write"))
-			r.Post("/s3-buckets/{bucketID}/access-keys", s3AccessKey.Create)
+			r.With(mw.SecretResponse).Post("/s3-buckets/{bucketID}/access-keys", s3AccessKey.Create)
+			r.With(mw.SecretResponse).Post("/s3-access-keys/{id}/rotate", s3AccessKey.Rotate)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("s3", "delete"))
@@ -658,10 +875,14 @@ func (s *Server) setupRoutes() {
 			r.Get("/email-accounts/{id}/forwards", emailForward.ListByAccount)
 			r.Get("/email-forwards/{forwardID}", emailForward.Get)
 			r.Get("/email-accounts/{id}/autoreply", emailAutoReply.Get)
+			r.Get("/fqdns/{id}/dkim", fqdn.GetDKIM)
+			r.Get("/tenants/{tenantID}/smtp-relay-users", smtpRelayUser.ListByTenant)
+			r.Get("/smtp-relay-users/{id}", smtpRelayUser.Get)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("email", "write"))
 			r.Post("/fqdns/{fqdnID}/email-accounts", emailAccount.Create)
+			r.Post("/fqdns/{fqdnID}/email-accounts/import", emailAccount.Import)
 			r.Post("/email-accounts/{id}/retry", emailAccount.Retry)
 			r.Post("/email-accounts/{id}/aliases", emailAlias.Create)
 			r.Post("/email-aliases/{aliasID}/retry", emailAlias.Retry)
@@ -669,6 +890,10 @@ func (s *Server) setupRoutes() {
 			r.Post("/email-forwards/{forwardID}/retry", emailForward.Retry)
 			r.Put("/email-accounts/{id}/autoreply", emailAutoReply.Put)
 			r.Post("/email-autoreplies/{id}/retry", emailAutoReply.Retry)
+			r.Post("/fqdns/{id}/dkim", fqdn.ProvisionDKIM)
+			r.With(mw.SecretResponse).Post("/tenants/{tenantID}/smtp-relay-users", smtpRelayUser.Create)
+			r.Put("/smtp-relay-users/{id}", smtpRelayUser.Update)
+			r.Post("/smtp-relay-users/{id}/retry", smtpRelayUser.Retry)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("email", "delete"))
@@ -676,6 +901,7 @@ func (s *Server) setupRoutes() {
 			r.Delete("/email-aliases/{aliasID}", emailAlias.Delete)
 			r.Delete("/email-forwards/{forwardID}", emailForward.Delete)
 			r.Delete("/email-accounts/{id}/autoreply", emailAutoReply.Delete)
+			r.Delete("/smtp-relay-users/{id}", smtpRelayUser.Delete)
 		})
 
 		// Backups
@@ -683,18 +909,29 @@ func (s *Server) setupRoutes() {
 			r.Use(mw.RequireScope("backups", "read"))
 			r.Get("/tenants/{tenantID}/backups", backup.ListByTenant)
 			r.Get("/backups/{id}", backup.Get)
+			r.Get("/backups/{id}/files", backup.Files)
+			r.Post("/backups/{id}/download-url", backupDownload.CreateURL)
+			r.Get("/tenants/{tenantID}/export", tenantExport.Get)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("backups", "write"))
 			r.Post("/tenants/{tenantID}/backups", backup.Create)
 			r.Post("/backups/{id}/restore", backup.Restore)
+			r.Post("/backups/{id}/restore-files", backup.RestoreFiles)
 			r.Post("/backups/{id}/retry", backup.Retry)
+			r.Post("/tenants/{tenantID}/export", tenantExport.Create)
 		})
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("backups", "delete"))
 			r.Delete("/backups/{id}", backup.Delete)
 		})
 
+		// Operations
+		r.Group(func(r chi.Router) {
+			r.Use(mw.RequireScope("operations", "read"))
+			r.Get("/operations/{id}", operation.Get)
+		})
+
 		// WireGuard peers
 		r.Group(func(r chi.Router) {
 			r.Use(mw.RequireScope("wireguard", "read"))
@@ -779,6 +1016,47 @@ func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(checks)
 }
 
+// ReloadConfig re-reads the environment and applies the hot-reloadable
+// config fields to the running server. Which fields that is depends on the
+// config.Store; core-api's only reloads config.LogLevelReloadable.
+func (s *Server) ReloadConfig() ([]string, error) {
+	changed, err := s.cfgStore.Reload()
+	if err != nil {
+		return nil, err
+	}
+	if err := logging.SetLevel(s.cfgStore.Get().LogLevel); err != nil {
+		return nil, err
+	}
+	if err := logging.SetComponentLevels(s.cfgStore.Get().LogLevels); err != nil {
+		return nil, err
+	}
+	s.logger.Info().Strs("changed", changed).Msg("config reloaded")
+	return changed, nil
+}
+
+func (s *Server) handleReloadConfig(w http.ResponseWriter, _ *http.Request) {
+	changed, err := s.ReloadConfig()
+	if err != nil {
+		response.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if changed == nil {
+		changed = []string{}
+	}
+	response.WriteJSON(w, http.StatusOK, map[string]any{"changed": changed})
+}
+
+// Close flushes the audit log. It must be called after the HTTP server has
+// shut down.
+func (s *Server) Close() {
+	s.auditLogger.Close()
+}
+
+// InFlightRequests returns the number of requests being served.
+func (s *Server) InFlightRequests() int {
+	return s.inFlight.Count()
+}
+
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.router.ServeHTTP(w, r)
 }
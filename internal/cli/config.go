@@ -7,6 +7,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // WireGuardConfig represents a parsed WireGuard configuration file.
@@ -49,6 +50,16 @@ func (s ServiceEntry) RemotePort() int {
 	return s.DefaultPort()
 }
 
+// EnsureKeepalive enables the WireGuard persistent keepalive at the given
+// period so an idle tunnel keeps its NAT mapping. A keepalive already set in
+// the profile is left untouched.
+func (c *WireGuardConfig) EnsureKeepalive(period time.Duration) {
+	if c.PersistentKeepalive > 0 || period <= 0 {
+		return
+	}
+	c.PersistentKeepalive = max(int(period/time.Second), 1)
+}
+
 // ParseConfig reads and parses a WireGuard config file.
 func ParseConfig(path string) (*WireGuardConfig, error) {
 	f, err := os.Open(path)
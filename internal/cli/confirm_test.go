@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfirm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"y\n", true},
+		{"YES\n", true},
+		{" yes \n", true},
+		{"y", true},
+		{"n\n", false},
+		{"\n", false},
+		{"sure\n", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		got := Confirm(strings.NewReader(tt.input), &out, "Delete profile \"acme\"?")
+		assert.Equal(t, tt.want, got, "input %q", tt.input)
+		assert.True(t, strings.HasPrefix(out.String(), "Delete profile \"acme\"? [y/N]: "))
+	}
+}
@@ -1,22 +1,53 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"net"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// DefaultKeepalive is the default TCP keepalive period for proxied connections.
+const DefaultKeepalive = 30 * time.Second
+
 // ProxyTarget describes a service to proxy from localhost to the tunnel.
 type ProxyTarget struct {
 	Service   ServiceEntry
 	LocalPort int
+
+	// Keepalive is the TCP keepalive period for accepted connections.
+	// Zero disables keepalive.
+	Keepalive time.Duration
+	// IdleTimeout closes a proxied connection once no data has flowed in
+	// either direction for this long. Zero disables the timeout.
+	IdleTimeout time.Duration
+}
+
+// DefaultGracePeriod is how long DrainProxies waits by default for proxied
+// connections to close on shutdown.
+const DefaultGracePeriod = 30 * time.Second
+
+// Proxy is a running local listener forwarding connections through the tunnel.
+type Proxy struct {
+	listener net.Listener
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
 }
 
 // StartProxy listens on localhost:localPort and forwards connections through the tunnel
 // to the remote service address.
-func StartProxy(tunnel *Tunnel, target ProxyTarget) (net.Listener, error) {
+//
+// Keepalive is applied to the local socket. Connections on the tunnel side
+// live in the userspace netstack, which exposes no socket options; what keeps
+// that path alive through NAT is the WireGuard persistent keepalive (see
+// WireGuardConfig.EnsureKeepalive).
+func StartProxy(tunnel *Tunnel, target ProxyTarget) (*Proxy, error) {
 	remoteAddr := fmt.Sprintf("[%s]:%d", target.Service.Address, target.Service.RemotePort())
 	localAddr := fmt.Sprintf("127.0.0.1:%d", target.LocalPort)
 
@@ -25,20 +56,134 @@ func StartProxy(tunnel *Tunnel, target ProxyTarget) (net.Listener, error) {
 		return nil, fmt.Errorf("listen on %s: %w", localAddr, err)
 	}
 
-	go func() {
-		for {
-			local, err := listener.Accept()
-			if err != nil {
-				return // listener closed
+	p := &Proxy{listener: listener, conns: make(map[net.Conn]struct{})}
+	go p.serve(func(local net.Conn) {
+		setKeepalive(local, target.Keepalive)
+		handleProxy(tunnel, local, remoteAddr, target.IdleTimeout)
+	})
+
+	return p, nil
+}
+
+// serve accepts connections until the listener is closed, running handle for
+// each one while tracking it as open.
+func (p *Proxy) serve(handle func(net.Conn)) {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return // listener closed
+		}
+		if !p.track(conn) {
+			conn.Close()
+			continue
+		}
+		go func() {
+			defer p.untrack(conn)
+			handle(conn)
+		}()
+	}
+}
+
+func (p *Proxy) track(conn net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+func (p *Proxy) untrack(conn net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.conns, conn)
+}
+
+// Active returns the number of open proxied connections.
+func (p *Proxy) Active() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.conns)
+}
+
+// StopAccepting closes the listener. Open connections are left running.
+func (p *Proxy) StopAccepting() {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	p.listener.Close()
+}
+
+// Close closes the listener and every open proxied connection.
+func (p *Proxy) Close() {
+	p.StopAccepting()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for conn := range p.conns {
+		conn.Close()
+	}
+}
+
+// DrainProxies stops the proxies accepting connections and waits for the open
+// ones to finish until ctx is done, calling report with the number still open
+// every interval. Connections still open at that point are closed; their
+// count is returned.
+func DrainProxies(ctx context.Context, proxies []*Proxy, interval time.Duration, report func(open int)) int {
+	active := func() int {
+		n := 0
+		for _, p := range proxies {
+			n += p.Active()
+		}
+		return n
+	}
+
+	for _, p := range proxies {
+		p.StopAccepting()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	poll := time.NewTicker(min(interval, 50*time.Millisecond))
+	defer poll.Stop()
+
+	open := active()
+	if open > 0 {
+		report(open)
+	}
+	for open > 0 {
+		select {
+		case <-ctx.Done():
+			for _, p := range proxies {
+				p.Close()
 			}
-			go handleProxy(tunnel, local, remoteAddr)
+			return open
+		case <-ticker.C:
+			report(open)
+		case <-poll.C:
 		}
-	}()
+		open = active()
+	}
+	return 0
+}
 
-	return listener, nil
+// setKeepalive enables TCP keepalive with the given period on conn, or
+// disables it when period is zero.
+func setKeepalive(conn net.Conn, period time.Duration) {
+	tcp, ok := conn.(*net.TCPConn)
+	if !ok {
+		return
+	}
+	if err := tcp.SetKeepAliveConfig(net.KeepAliveConfig{
+		Enable:   period > 0,
+		Idle:     period,
+		Interval: period,
+	}); err != nil {
+		log.Printf("set keepalive on %s: %v", conn.RemoteAddr(), err)
+	}
 }
 
-func handleProxy(tunnel *Tunnel, local net.Conn, remoteAddr string) {
+func handleProxy(tunnel *Tunnel, local net.Conn, remoteAddr string, idleTimeout time.Duration) {
 	defer local.Close()
 
 	remote, err := tunnel.DialTCP(remoteAddr)
@@ -48,18 +193,70 @@ func handleProxy(tunnel *Tunnel, local net.Conn, remoteAddr string) {
 	}
 	defer remote.Close()
 
+	if idle := pipe(local, remote, idleTimeout); idle {
+		log.Printf("closed idle connection to %s after %s", remoteAddr, idleTimeout)
+	}
+}
+
+// pipe copies data between a and b until either side closes. With a non-zero
+// idleTimeout both connections are closed once no data has flowed in either
+// direction for that long; pipe then reports true.
+func pipe(a, b net.Conn, idleTimeout time.Duration) (idle bool) {
+	var lastActive atomic.Int64
+	lastActive.Store(time.Now().UnixNano())
+
 	var wg sync.WaitGroup
 	wg.Add(2)
 
 	go func() {
 		defer wg.Done()
-		io.Copy(remote, local)
+		io.Copy(b, &activityReader{r: a, lastActive: &lastActive})
+		b.Close()
 	}()
 
 	go func() {
 		defer wg.Done()
-		io.Copy(local, remote)
+		io.Copy(a, &activityReader{r: b, lastActive: &lastActive})
+		a.Close()
 	}()
 
+	done := make(chan struct{})
+	var timedOut atomic.Bool
+	if idleTimeout > 0 {
+		go func() {
+			ticker := time.NewTicker(max(idleTimeout/4, 10*time.Millisecond))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					if time.Since(time.Unix(0, lastActive.Load())) >= idleTimeout {
+						timedOut.Store(true)
+						a.Close()
+						b.Close()
+						return
+					}
+				}
+			}
+		}()
+	}
+
 	wg.Wait()
+	close(done)
+	return timedOut.Load()
+}
+
+// activityReader records the time of every successful read.
+type activityReader struct {
+	r          io.Reader
+	lastActive *atomic.Int64
+}
+
+func (r *activityReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.lastActive.Store(time.Now().UnixNano())
+	}
+	return n, err
 }
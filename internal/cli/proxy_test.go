@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipe_ForwardsBothDirections(t *testing.T) {
+	client, local := net.Pipe()
+	remote, server := net.Pipe()
+
+	done := make(chan bool)
+	go func() { done <- pipe(local, remote, 0) }()
+
+	go client.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	_, err := io.ReadFull(server, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "ping", string(buf))
+
+	go server.Write([]byte("pong"))
+	_, err = io.ReadFull(client, buf)
+	require.NoError(t, err)
+	assert.Equal(t, "pong", string(buf))
+
+	// Closing one end tears down the other.
+	client.Close()
+	_, err = server.Read(buf)
+	assert.Error(t, err)
+	assert.False(t, <-done)
+}
+
+func TestPipe_IdleTimeoutClosesConnections(t *testing.T) {
+	client, local := net.Pipe()
+	remote, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	done := make(chan bool)
+	go func() { done <- pipe(local, remote, 50*time.Millisecond) }()
+
+	select {
+	case idle := <-done:
+		assert.True(t, idle)
+	case <-time.After(2 * time.Second):
+		t.Fatal("idle connection was not closed")
+	}
+	_, err := client.Read(make([]byte, 1))
+	assert.Error(t, err)
+}
+
+func TestPipe_ActivityResetsIdleTimeout(t *testing.T) {
+	client, local := net.Pipe()
+	remote, server := net.Pipe()
+	defer server.Close()
+
+	done := make(chan bool)
+	go func() { done <- pipe(local, remote, 100*time.Millisecond) }()
+	go io.Copy(io.Discard, server)
+
+	// Keep writing for longer than the idle timeout.
+	for i := 0; i < 6; i++ {
+		_, err := client.Write([]byte("x"))
+		require.NoError(t, err)
+		time.Sleep(40 * time.Millisecond)
+	}
+	client.Close()
+	assert.False(t, <-done)
+}
+
+func TestEnsureKeepalive(t *testing.T) {
+	cfg := &WireGuardConfig{}
+	cfg.EnsureKeepalive(30 * time.Second)
+	assert.Equal(t, 30, cfg.PersistentKeepalive)
+
+	// An explicit profile keepalive is kept.
+	cfg = &WireGuardConfig{PersistentKeepalive: 25}
+	cfg.EnsureKeepalive(10 * time.Second)
+	assert.Equal(t, 25, cfg.PersistentKeepalive)
+
+	cfg = &WireGuardConfig{}
+	cfg.EnsureKeepalive(0)
+	assert.Equal(t, 0, cfg.PersistentKeepalive)
+}
+
+// startTestProxy starts a Proxy on a random local port whose connections are
+// held open until the client closes them.
+func startTestProxy(t *testing.T) *Proxy {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	p := &Proxy{listener: listener, conns: make(map[net.Conn]struct{})}
+	go p.serve(func(conn net.Conn) {
+		defer conn.Close()
+		io.Copy(io.Discard, conn)
+	})
+	t.Cleanup(p.Close)
+	return p
+}
+
+func waitActive(t *testing.T, p *Proxy, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool { return p.Active() == n }, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestDrainProxies_WaitsForConnections(t *testing.T) {
+	p := startTestProxy(t)
+	conn, err := net.Dial("tcp", p.listener.Addr().String())
+	require.NoError(t, err)
+	waitActive(t, p, 1)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		conn.Close()
+	}()
+
+	var reports []int
+	remaining := DrainProxies(context.Background(), []*Proxy{p}, time.Hour, func(open int) { reports = append(reports, open) })
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, []int{1}, reports)
+
+	// The listener no longer accepts connections.
+	_, err = net.Dial("tcp", p.listener.Addr().String())
+	assert.Error(t, err)
+}
+
+func TestDrainProxies_ClosesAfterGracePeriod(t *testing.T) {
+	p := startTestProxy(t)
+	conn, err := net.Dial("tcp", p.listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	waitActive(t, p, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	remaining := DrainProxies(ctx, []*Proxy{p}, time.Hour, func(int) {})
+	assert.Equal(t, 1, remaining)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, err = conn.Read(make([]byte, 1))
+	assert.Error(t, err)
+	waitActive(t, p, 0)
+}
+
+func TestDrainProxies_NoConnections(t *testing.T) {
+	p := startTestProxy(t)
+	called := false
+	assert.Equal(t, 0, DrainProxies(context.Background(), []*Proxy{p}, time.Hour, func(int) { called = true }))
+	assert.False(t, called)
+}
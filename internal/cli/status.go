@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats accepted by the -o flag of status and active.
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+)
+
+// ParseOutputFormat validates an -o flag value. An empty value selects the
+// human-readable text output.
+func ParseOutputFormat(s string) (string, error) {
+	switch s {
+	case "", OutputText:
+		return OutputText, nil
+	case OutputJSON, OutputYAML:
+		return s, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q (want json or yaml)", s)
+	}
+}
+
+// ProfileStatus is the structured form of a profile and its tunnel config,
+// printed by status and active with -o json or -o yaml.
+type ProfileStatus struct {
+	Profile  string          `json:"profile" yaml:"profile"`
+	Alias    string          `json:"alias,omitempty" yaml:"alias,omitempty"`
+	TenantID string          `json:"tenant_id,omitempty" yaml:"tenant_id,omitempty"`
+	Address  string          `json:"address" yaml:"address"`
+	Endpoint string          `json:"endpoint" yaml:"endpoint"`
+	Services []ServiceStatus `json:"services" yaml:"services"`
+}
+
+// ServiceStatus is a service reachable through the tunnel. Port is the local
+// port proxy listens on by default, and 0 for unknown service types.
+type ServiceStatus struct {
+	Type    string `json:"type" yaml:"type"`
+	Address string `json:"address" yaml:"address"`
+	Port    int    `json:"port" yaml:"port"`
+}
+
+// NewProfileStatus builds the structured status of a profile. Services is
+// never nil, so it always serializes as a list.
+func NewProfileStatus(profile *Profile, cfg *WireGuardConfig) ProfileStatus {
+	status := ProfileStatus{
+		Profile:  profile.Name,
+		Alias:    profile.Alias,
+		TenantID: profile.TenantID,
+		Address:  cfg.Address.String(),
+		Endpoint: cfg.Endpoint,
+		Services: make([]ServiceStatus, 0, len(cfg.Services)),
+	}
+	for _, svc := range cfg.Services {
+		status.Services = append(status.Services, ServiceStatus{
+			Type:    svc.Type,
+			Address: svc.Address,
+			Port:    svc.DefaultPort(),
+		})
+	}
+	return status
+}
+
+// WriteProfileStatus encodes status to w in the given structured format.
+func WriteProfileStatus(w io.Writer, status ProfileStatus, format string) error {
+	switch format {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(status)
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(status); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("output format %q is not structured", format)
+	}
+}
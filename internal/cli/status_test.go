@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func testProfileStatus() ProfileStatus {
+	profile := &Profile{Name: "t_abc", TenantID: "t_abc", Alias: "acme"}
+	cfg := &WireGuardConfig{
+		Address:  netip.MustParsePrefix("fd00:abcd:ffff::1/128"),
+		Endpoint: "gw.massive-hosting.com:51820",
+		Services: []ServiceEntry{
+			{Type: "mysql", Address: "fd00:abcd:101::1388"},
+			{Type: "valkey", Address: "fd00:abcd:201::1388"},
+			{Type: "unknown", Address: "fd00:abcd:301::1388"},
+		},
+	}
+	return NewProfileStatus(profile, cfg)
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for in, want := range map[string]string{"": OutputText, "text": OutputText, "json": OutputJSON, "yaml": OutputYAML} {
+		got, err := ParseOutputFormat(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	_, err := ParseOutputFormat("xml")
+	assert.ErrorContains(t, err, "unknown output format")
+}
+
+func TestNewProfileStatus_ResolvesPorts(t *testing.T) {
+	status := testProfileStatus()
+
+	assert.Equal(t, "fd00:abcd:ffff::1/128", status.Address)
+	require.Len(t, status.Services, 3)
+	assert.Equal(t, ServiceStatus{Type: "mysql", Address: "fd00:abcd:101::1388", Port: 3306}, status.Services[0])
+	assert.Equal(t, 6379, status.Services[1].Port)
+	assert.Equal(t, 0, status.Services[2].Port)
+}
+
+func TestWriteProfileStatus_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteProfileStatus(&buf, testProfileStatus(), OutputJSON))
+
+	var decoded ProfileStatus
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, testProfileStatus(), decoded)
+	assert.Contains(t, buf.String(), `"tenant_id": "t_abc"`)
+}
+
+func TestWriteProfileStatus_YAML(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteProfileStatus(&buf, testProfileStatus(), OutputYAML))
+
+	var decoded ProfileStatus
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, testProfileStatus(), decoded)
+}
+
+func TestWriteProfileStatus_NoServices(t *testing.T) {
+	status := NewProfileStatus(&Profile{Name: "bare"}, &WireGuardConfig{})
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteProfileStatus(&buf, status, OutputJSON))
+	assert.Contains(t, buf.String(), `"services": []`)
+	assert.NotContains(t, buf.String(), "alias")
+}
+
+func TestWriteProfileStatus_TextRejected(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, WriteProfileStatus(&buf, testProfileStatus(), OutputText))
+}
@@ -2,9 +2,15 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/edvin/hosting/internal/ipallow"
+	"github.com/edvin/hosting/internal/secrets"
 )
 
 type Config struct {
@@ -15,6 +21,10 @@ type Config struct {
 	MySQLDSN           string
 	RegistryURL        string
 	LogLevel           string
+	LogFormat          string // LOG_FORMAT — json (default) or console, human-readable output for development
+	LogLevels          string // LOG_LEVELS — per-component level overrides as component=level,... (e.g. nginx-manager=debug)
+	LogSampleBurst     int    // LOG_SAMPLE_BURST — debug and info messages logged per LOG_SAMPLE_PERIOD_SECS before the rest are dropped; 0 disables sampling (default: 0)
+	LogSamplePeriodSecs int   // LOG_SAMPLE_PERIOD_SECS — length of a sampling period (default: 1)
 	StalwartAdminToken string
 	// NodeID is the unique identifier for this node when running as a Temporal worker.
 	// Used to register on the "node-{id}" task queue.
@@ -23,10 +33,37 @@ type Config struct {
 	ACMEEmail        string // ACME_EMAIL — contact email for Let's Encrypt
 	ACMEDirectoryURL string // ACME_DIRECTORY_URL — defaults to LE production
 
+	// Activity retry tuning (worker)
+	ACMEMaxAttempts        int // ACME_MAX_ATTEMPTS — attempts per ACME activity, retried with backoff up to 10 minutes (default: 10)
+	DNSPropagationWaitSecs int // DNS_PROPAGATION_WAIT_SECS — wait after writing DNS-01 challenge records (default: 30)
+
+	// Temporal worker tuning (worker + node-agent). 0 keeps the SDK default.
+	WorkerMaxConcurrentActivities    int     // WORKER_MAX_CONCURRENT_ACTIVITIES — activities executed in parallel (SDK default: 1000)
+	WorkerMaxConcurrentWorkflowTasks int     // WORKER_MAX_CONCURRENT_WORKFLOW_TASKS — workflow tasks executed in parallel (SDK default: 1000)
+	WorkerActivitiesPerSecond        float64 // WORKER_ACTIVITIES_PER_SECOND — activities started per second by this worker; 0 is unlimited
+	WorkerActivityConcurrency        string  // WORKER_ACTIVITY_CONCURRENCY — per-activity caps as Name=N,...; node-agent defaults to DefaultNodeActivityConcurrency
+
+	// Core database pool (core-api + worker)
+	DBMaxConns            int // DB_MAX_CONNS — max pool connections; 0 keeps the pgx default of max(4, CPUs)
+	DBMinConns            int // DB_MIN_CONNS — connections kept open when idle (default: 0)
+	DBMaxConnLifetimeSecs int // DB_MAX_CONN_LIFETIME_SECS — connections are closed after this age (default: 3600)
+	DBMaxConnIdleTimeSecs int // DB_MAX_CONN_IDLE_TIME_SECS — idle connections are closed after this time (default: 1800)
+	DBSlowQueryMS         int // DB_SLOW_QUERY_MS — log queries slower than this; 0 disables (default: 500)
+
 	// Retention
 	AuditLogRetentionDays int // AUDIT_LOG_RETENTION_DAYS — default 90
 	BackupRetentionDays   int // BACKUP_RETENTION_DAYS — default 30
 
+	// Backup verification (daily restore test of a random sample)
+	BackupVerifySampleSize int // BACKUP_VERIFY_SAMPLE_SIZE — backups restore-tested per run; 0 disables (default: 5)
+	BackupVerifyMaxAgeDays int // BACKUP_VERIFY_MAX_AGE_DAYS — only backups completed this recently are sampled (default: 7)
+
+	// Backup downloads (core-api). Downloads are staged through the export bucket.
+	BackupDownloadURLTTLSecs int // BACKUP_DOWNLOAD_URL_TTL_SECS — default lifetime of signed backup download URLs (default: 900)
+
+	// DNSSEC (worker)
+	DNSSECZSKRolloverDays int // DNSSEC_ZSK_ROLLOVER_DAYS — age at which signed zones' ZSKs are rolled over; 0 disables (default: 90)
+
 	// OIDC
 	OIDCIssuerURL string // OIDC_ISSUER_URL — issuer URL for the built-in OIDC provider
 
@@ -44,11 +81,15 @@ type Config struct {
 	ServiceName string // SERVICE_NAME
 	MetricsAddr string // METRICS_ADDR — listen addr for /metrics (worker + node-agent)
 
+	ScheduleMetricsIntervalSecs int // SCHEDULE_METRICS_INTERVAL_SECS — how often the worker refreshes cron schedule metrics (default: 60)
+
 	LokiURL       string // LOKI_URL — Loki query endpoint for platform logs (default: http://127.0.0.1:3100)
 	TenantLokiURL string // TENANT_LOKI_URL — Loki query endpoint for tenant logs (default: http://127.0.0.1:3101)
 
 	SecretEncryptionKey string // SECRET_ENCRYPTION_KEY — 32-byte AES-256 key, hex-encoded
 
+	InternalSigningSecret string // INTERNAL_SIGNING_SECRET — when set, /internal/v1 node and session endpoints also require an HMAC request signature
+
 	InternalNetworkCIDR string // INTERNAL_NETWORK_CIDR — default 10.0.0.0/8, used for database ingress default
 
 	// LLM Agent
@@ -66,6 +107,41 @@ type Config struct {
 	WireGuardEndpoint string // WIREGUARD_ENDPOINT — public endpoint for WireGuard VPN (e.g. "vpn.massive-hosting.com:51820")
 
 	MCPApiURL string // MCP_API_URL — base URL the MCP proxy uses to reach the core API (default: http://127.0.0.1:8090)
+
+	ConfigReloadFile string // CONFIG_RELOAD_FILE — optional KEY=VALUE file re-read on SIGHUP / reload-config
+
+	// IP allowlisting (core-api). Empty lists allow all clients.
+	APIIPAllowlist string // API_IP_ALLOWLIST — comma-separated CIDRs allowed to reach /api/v1, docs, MCP and terminal
+	SSOIPAllowlist string // SSO_IP_ALLOWLIST — comma-separated CIDRs allowed to reach the OIDC provider endpoints
+	TrustedProxies string // TRUSTED_PROXIES — comma-separated CIDRs whose X-Forwarded-For is honored
+
+	EgressCIDRBlocklist string // EGRESS_CIDR_BLOCKLIST — comma-separated CIDRs tenant egress rules may not overlap (default: link-local ranges)
+
+	// Generated passwords (core-api), e.g. for Valkey instances.
+	GeneratedPasswordLength  int    // GENERATED_PASSWORD_LENGTH — length of generated passwords (default: 32)
+	GeneratedPasswordClasses string // GENERATED_PASSWORD_CLASSES — comma-separated classes from lower, upper, digit, symbol (default: lower,upper,digit)
+
+	// Request body limits (core-api). Larger bodies are rejected with 413; 0 disables a limit.
+	MaxRequestBodyBytes int // MAX_REQUEST_BODY_BYTES — default limit for API request bodies (default: 1 MiB)
+	MaxUploadBodyBytes  int // MAX_UPLOAD_BODY_BYTES — limit for certificate upload/import bodies (default: 16 MiB)
+
+	// CORS (core-api). With no allowed origins no CORS headers are sent and
+	// browsers may only call the API from its own origin.
+	CORSAllowedOrigins   string // CORS_ALLOWED_ORIGINS — comma-separated origins (scheme://host[:port]), or "*" for any origin
+	CORSAllowedMethods   string // CORS_ALLOWED_METHODS — methods allowed in preflight (default: GET,POST,PUT,PATCH,DELETE)
+	CORSAllowedHeaders   string // CORS_ALLOWED_HEADERS — request headers allowed in preflight (default: Authorization,Content-Type,Idempotency-Key,X-Callback-URL)
+	CORSAllowCredentials bool   // CORS_ALLOW_CREDENTIALS — send Access-Control-Allow-Credentials (default: false); not allowed with "*"
+	CORSMaxAgeSecs       int    // CORS_MAX_AGE_SECS — how long browsers may cache a preflight (default: 600)
+
+	ShutdownTimeoutSecs int // SHUTDOWN_TIMEOUT_SECS — how long core-api waits for in-flight requests on shutdown (default: 20)
+
+	// Tenant exports (core-api + worker). Exports are disabled unless endpoint and bucket are set.
+	ExportS3Endpoint    string // EXPORT_S3_ENDPOINT — S3 endpoint holding tenant export archives
+	ExportS3Region      string // EXPORT_S3_REGION — default us-east-1
+	ExportS3Bucket      string // EXPORT_S3_BUCKET — bucket for tenant export archives
+	ExportS3AccessKey   string // EXPORT_S3_ACCESS_KEY
+	ExportS3SecretKey   string // EXPORT_S3_SECRET_KEY
+	ExportRetentionDays int    // EXPORT_RETENTION_DAYS — days an export archive is kept (default: 7)
 }
 
 func Load() (*Config, error) {
@@ -77,6 +153,10 @@ func Load() (*Config, error) {
 		MySQLDSN:              getEnv("MYSQL_DSN", ""),
 		RegistryURL:           getEnv("REGISTRY_URL", "registry.localhost:5000"),
 		LogLevel:              getEnv("LOG_LEVEL", "info"),
+		LogFormat:             getEnv("LOG_FORMAT", LogFormatJSON),
+		LogLevels:             getEnv("LOG_LEVELS", ""),
+		LogSampleBurst:        getEnvInt("LOG_SAMPLE_BURST", 0),
+		LogSamplePeriodSecs:   getEnvInt("LOG_SAMPLE_PERIOD_SECS", 1),
 		StalwartAdminToken:    getEnv("STALWART_ADMIN_TOKEN", ""),
 		NodeID:                getEnv("NODE_ID", ""),
 		ACMEEmail:             getEnv("ACME_EMAIL", ""),
@@ -84,11 +164,29 @@ func Load() (*Config, error) {
 		OIDCIssuerURL:         getEnv("OIDC_ISSUER_URL", "http://api.hosting.localhost"),
 		AuditLogRetentionDays: getEnvInt("AUDIT_LOG_RETENTION_DAYS", 90),
 		BackupRetentionDays:   getEnvInt("BACKUP_RETENTION_DAYS", 30),
+		BackupVerifySampleSize: getEnvInt("BACKUP_VERIFY_SAMPLE_SIZE", 5),
+		BackupVerifyMaxAgeDays: getEnvInt("BACKUP_VERIFY_MAX_AGE_DAYS", 7),
+		BackupDownloadURLTTLSecs: getEnvInt("BACKUP_DOWNLOAD_URL_TTL_SECS", 900),
+		DNSSECZSKRolloverDays: getEnvInt("DNSSEC_ZSK_ROLLOVER_DAYS", 90),
 		TemporalTLSCert:       getEnv("TEMPORAL_TLS_CERT", ""),
 		TemporalTLSKey:        getEnv("TEMPORAL_TLS_KEY", ""),
 		TemporalTLSCACert:     getEnv("TEMPORAL_TLS_CA_CERT", ""),
 		TemporalTLSServerName: getEnv("TEMPORAL_TLS_SERVER_NAME", ""),
 
+		ACMEMaxAttempts:        getEnvInt("ACME_MAX_ATTEMPTS", 10),
+		DNSPropagationWaitSecs: getEnvInt("DNS_PROPAGATION_WAIT_SECS", 30),
+
+		WorkerMaxConcurrentActivities:    getEnvInt("WORKER_MAX_CONCURRENT_ACTIVITIES", 0),
+		WorkerMaxConcurrentWorkflowTasks: getEnvInt("WORKER_MAX_CONCURRENT_WORKFLOW_TASKS", 0),
+		WorkerActivitiesPerSecond:        getEnvFloat("WORKER_ACTIVITIES_PER_SECOND", 0),
+		WorkerActivityConcurrency:        getEnv("WORKER_ACTIVITY_CONCURRENCY", ""),
+
+		DBMaxConns:            getEnvInt("DB_MAX_CONNS", 0),
+		DBMinConns:            getEnvInt("DB_MIN_CONNS", 0),
+		DBMaxConnLifetimeSecs: getEnvInt("DB_MAX_CONN_LIFETIME_SECS", 3600),
+		DBMaxConnIdleTimeSecs: getEnvInt("DB_MAX_CONN_IDLE_TIME_SECS", 1800),
+		DBSlowQueryMS:         getEnvInt("DB_SLOW_QUERY_MS", 500),
+
 		RegionID:    getEnv("REGION_ID", ""),
 		ClusterID:   getEnv("CLUSTER_ID", ""),
 		ShardName:   getEnv("SHARD_NAME", ""),
@@ -96,11 +194,15 @@ func Load() (*Config, error) {
 		ServiceName: getEnv("SERVICE_NAME", ""),
 		MetricsAddr: getEnv("METRICS_ADDR", ""),
 
+		ScheduleMetricsIntervalSecs: getEnvInt("SCHEDULE_METRICS_INTERVAL_SECS", 60),
+
 		LokiURL:       getEnv("LOKI_URL", "http://127.0.0.1:3100"),
 		TenantLokiURL: getEnv("TENANT_LOKI_URL", "http://127.0.0.1:3101"),
 
 		SecretEncryptionKey: getEnv("SECRET_ENCRYPTION_KEY", ""),
 
+		InternalSigningSecret: getEnv("INTERNAL_SIGNING_SECRET", ""),
+
 		InternalNetworkCIDR: getEnv("INTERNAL_NETWORK_CIDR", "10.0.0.0/8"),
 
 		AgentEnabled:            getEnvBool("AGENT_ENABLED", false),
@@ -117,6 +219,35 @@ func Load() (*Config, error) {
 		WireGuardEndpoint: getEnv("WIREGUARD_ENDPOINT", ""),
 
 		MCPApiURL: getEnv("MCP_API_URL", "http://127.0.0.1:8090"),
+
+		ConfigReloadFile: getEnv("CONFIG_RELOAD_FILE", ""),
+
+		APIIPAllowlist: getEnv("API_IP_ALLOWLIST", ""),
+		SSOIPAllowlist: getEnv("SSO_IP_ALLOWLIST", ""),
+		TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
+
+		EgressCIDRBlocklist: getEnv("EGRESS_CIDR_BLOCKLIST", "169.254.0.0/16,fe80::/10"),
+
+		GeneratedPasswordLength:  getEnvInt("GENERATED_PASSWORD_LENGTH", 32),
+		GeneratedPasswordClasses: getEnv("GENERATED_PASSWORD_CLASSES", "lower,upper,digit"),
+
+		MaxRequestBodyBytes: getEnvInt("MAX_REQUEST_BODY_BYTES", 1<<20),
+		MaxUploadBodyBytes:  getEnvInt("MAX_UPLOAD_BODY_BYTES", 16<<20),
+
+		CORSAllowedOrigins:   getEnv("CORS_ALLOWED_ORIGINS", ""),
+		CORSAllowedMethods:   getEnv("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE"),
+		CORSAllowedHeaders:   getEnv("CORS_ALLOWED_HEADERS", "Authorization,Content-Type,Idempotency-Key,X-Callback-URL"),
+		CORSAllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", false),
+		CORSMaxAgeSecs:       getEnvInt("CORS_MAX_AGE_SECS", 600),
+
+		ShutdownTimeoutSecs: getEnvInt("SHUTDOWN_TIMEOUT_SECS", 20),
+
+		ExportS3Endpoint:    getEnv("EXPORT_S3_ENDPOINT", ""),
+		ExportS3Region:      getEnv("EXPORT_S3_REGION", "us-east-1"),
+		ExportS3Bucket:      getEnv("EXPORT_S3_BUCKET", ""),
+		ExportS3AccessKey:   getEnv("EXPORT_S3_ACCESS_KEY", ""),
+		ExportS3SecretKey:   getEnv("EXPORT_S3_SECRET_KEY", ""),
+		ExportRetentionDays: getEnvInt("EXPORT_RETENTION_DAYS", 7),
 	}
 
 	return cfg, nil
@@ -166,11 +297,53 @@ func (c *Config) Validate(binary string) error {
 		return fmt.Errorf("missing required config: %s", strings.Join(missing, ", "))
 	}
 
+	if err := c.validateLogging(); err != nil {
+		return err
+	}
+
 	// Cross-field: cert and key must both be set or both unset.
 	if (c.TemporalTLSCert != "") != (c.TemporalTLSKey != "") {
 		return fmt.Errorf("TEMPORAL_TLS_CERT and TEMPORAL_TLS_KEY must both be set or both unset")
 	}
 
+	if binary == "worker" || binary == "node-agent" {
+		if err := c.validateWorkerTuning(); err != nil {
+			return err
+		}
+	}
+
+	if binary == "core-api" {
+		for name, value := range map[string]string{
+			"API_IP_ALLOWLIST":      c.APIIPAllowlist,
+			"SSO_IP_ALLOWLIST":      c.SSOIPAllowlist,
+			"TRUSTED_PROXIES":       c.TrustedProxies,
+			"EGRESS_CIDR_BLOCKLIST": c.EgressCIDRBlocklist,
+		} {
+			if _, err := ipallow.ParsePrefixes(value); err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		if _, err := c.PasswordGenerator(); err != nil {
+			return err
+		}
+		if c.MaxRequestBodyBytes < 0 || c.MaxUploadBodyBytes < 0 {
+			return fmt.Errorf("MAX_REQUEST_BODY_BYTES and MAX_UPLOAD_BODY_BYTES must not be negative")
+		}
+		if c.MaxUploadBodyBytes > 0 && c.MaxUploadBodyBytes < c.MaxRequestBodyBytes {
+			return fmt.Errorf("MAX_UPLOAD_BODY_BYTES (%d) must not be less than MAX_REQUEST_BODY_BYTES (%d)", c.MaxUploadBodyBytes, c.MaxRequestBodyBytes)
+		}
+		origins, err := c.CORSOrigins()
+		if err != nil {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS: %w", err)
+		}
+		if c.CORSAllowCredentials && len(origins) == 1 && origins[0] == "*" {
+			return fmt.Errorf("CORS_ALLOW_CREDENTIALS cannot be used with CORS_ALLOWED_ORIGINS=*")
+		}
+		if c.CORSMaxAgeSecs < 0 {
+			return fmt.Errorf("CORS_MAX_AGE_SECS must not be negative")
+		}
+	}
+
 	// Agent: require LLM_BASE_URL and AGENT_API_KEY when enabled.
 	if c.AgentEnabled {
 		if c.LLMBaseURL == "" {
@@ -187,6 +360,182 @@ func (c *Config) Validate(binary string) error {
 	return nil
 }
 
+// DefaultNodeActivityConcurrency caps the node-agent activities that shell
+// out to mysqldump or tar, so a burst of backups or exports cannot saturate
+// a node's disk and CPU.
+const DefaultNodeActivityConcurrency = "CreateWebBackup=2,CreateMySQLBackup=2,DumpMySQLDatabase=2,DumpValkeyData=2,RestoreWebBackup=1,RestoreMySQLBackup=1,ReplayMySQLBinlogs=1,FixTenantPermissions=1,CreateTenantExportArchive=1"
+
+// ActivityConcurrencyLimits parses WORKER_ACTIVITY_CONCURRENCY into the
+// maximum number of parallel executions per activity name. An empty value
+// means no per-activity limits.
+func (c *Config) ActivityConcurrencyLimits() (map[string]int, error) {
+	return parseActivityConcurrency(c.WorkerActivityConcurrency)
+}
+
+// PasswordGenerator builds the generator for platform-generated passwords
+// from GENERATED_PASSWORD_LENGTH and GENERATED_PASSWORD_CLASSES. A zero
+// length or empty class list keeps the secrets package default.
+func (c *Config) PasswordGenerator() (secrets.Generator, error) {
+	g := secrets.DefaultGenerator
+	if c.GeneratedPasswordLength != 0 {
+		g.Length = c.GeneratedPasswordLength
+	}
+	classes, err := secrets.ParseClasses(c.GeneratedPasswordClasses)
+	if err != nil {
+		return secrets.Generator{}, fmt.Errorf("GENERATED_PASSWORD_CLASSES: %w", err)
+	}
+	if len(classes) > 0 {
+		g.Classes = classes
+	}
+	if err := g.Validate(); err != nil {
+		return secrets.Generator{}, fmt.Errorf("GENERATED_PASSWORD_LENGTH/CLASSES: %w", err)
+	}
+	return g, nil
+}
+
+// CORSOrigins parses CORS_ALLOWED_ORIGINS into normalized origins. "*" must
+// be the only entry; every other entry must be an http(s) origin without a
+// path.
+func (c *Config) CORSOrigins() ([]string, error) {
+	var origins []string
+	for _, entry := range SplitList(c.CORSAllowedOrigins) {
+		if entry == "*" {
+			origins = append(origins, entry)
+			continue
+		}
+		u, err := url.Parse(entry)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+			return nil, fmt.Errorf("invalid origin %q, expected scheme://host[:port]", entry)
+		}
+		origins = append(origins, strings.ToLower(u.Scheme+"://"+u.Host))
+	}
+	if len(origins) > 1 {
+		for _, o := range origins {
+			if o == "*" {
+				return nil, fmt.Errorf("\"*\" cannot be combined with other origins")
+			}
+		}
+	}
+	return origins, nil
+}
+
+// SplitList splits a comma-separated value, trimming whitespace and dropping
+// empty entries.
+func SplitList(value string) []string {
+	var out []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func parseActivityConcurrency(value string) (map[string]int, error) {
+	limits := map[string]int{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, n, ok := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected Name=N", entry)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(n))
+		if err != nil || limit < 1 {
+			return nil, fmt.Errorf("invalid limit for %s: %q must be a positive integer", name, n)
+		}
+		if _, dup := limits[name]; dup {
+			return nil, fmt.Errorf("duplicate entry for %s", name)
+		}
+		limits[name] = limit
+	}
+	return limits, nil
+}
+
+// Log output formats.
+const (
+	LogFormatJSON    = "json"
+	LogFormatConsole = "console"
+)
+
+// validateLogging checks the log format, level overrides and sampling.
+func (c *Config) validateLogging() error {
+	switch c.LogFormat {
+	case "", LogFormatJSON, LogFormatConsole:
+	default:
+		return fmt.Errorf("LOG_FORMAT must be %s or %s, got %q", LogFormatJSON, LogFormatConsole, c.LogFormat)
+	}
+	if _, err := ParseLogLevels(c.LogLevels); err != nil {
+		return fmt.Errorf("LOG_LEVELS: %w", err)
+	}
+	if c.LogSampleBurst < 0 {
+		return fmt.Errorf("LOG_SAMPLE_BURST must not be negative")
+	}
+	if c.LogSampleBurst > 0 && c.LogSamplePeriodSecs <= 0 {
+		return fmt.Errorf("LOG_SAMPLE_PERIOD_SECS must be positive when LOG_SAMPLE_BURST is set")
+	}
+	return nil
+}
+
+// ParseLogLevels parses per-component log levels given as
+// component=level,... into a map.
+func ParseLogLevels(spec string) (map[string]zerolog.Level, error) {
+	levels := map[string]zerolog.Level{}
+	for _, entry := range SplitList(spec) {
+		component, level, ok := strings.Cut(entry, "=")
+		component = strings.TrimSpace(component)
+		if !ok || component == "" {
+			return nil, fmt.Errorf("invalid entry %q, expected component=level", entry)
+		}
+		if _, dup := levels[component]; dup {
+			return nil, fmt.Errorf("duplicate component %q", component)
+		}
+		// ParseLevel maps "" to NoLevel without an error.
+		level = strings.TrimSpace(level)
+		lvl, err := zerolog.ParseLevel(level)
+		if err != nil || level == "" {
+			return nil, fmt.Errorf("invalid level %q for %s", level, component)
+		}
+		levels[component] = lvl
+	}
+	return levels, nil
+}
+
+// validateWorkerTuning checks the Temporal worker options shared by the
+// worker and node-agent.
+func (c *Config) validateWorkerTuning() error {
+	if c.WorkerMaxConcurrentActivities < 0 {
+		return fmt.Errorf("WORKER_MAX_CONCURRENT_ACTIVITIES must not be negative")
+	}
+	if c.WorkerMaxConcurrentWorkflowTasks < 0 {
+		return fmt.Errorf("WORKER_MAX_CONCURRENT_WORKFLOW_TASKS must not be negative")
+	}
+	if c.WorkerMaxConcurrentWorkflowTasks == 1 {
+		// The SDK rejects a single workflow task slot: sticky and normal
+		// queues are polled separately and need one each.
+		return fmt.Errorf("WORKER_MAX_CONCURRENT_WORKFLOW_TASKS must be at least 2")
+	}
+	if c.WorkerActivitiesPerSecond < 0 {
+		return fmt.Errorf("WORKER_ACTIVITIES_PER_SECOND must not be negative")
+	}
+	limits, err := c.ActivityConcurrencyLimits()
+	if err != nil {
+		return fmt.Errorf("WORKER_ACTIVITY_CONCURRENCY: %w", err)
+	}
+	if c.WorkerMaxConcurrentActivities > 0 {
+		for name, limit := range limits {
+			if limit > c.WorkerMaxConcurrentActivities {
+				return fmt.Errorf("WORKER_ACTIVITY_CONCURRENCY: limit for %s (%d) exceeds WORKER_MAX_CONCURRENT_ACTIVITIES (%d)", name, limit, c.WorkerMaxConcurrentActivities)
+			}
+		}
+	}
+	return nil
+}
+
 func getEnv(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -210,3 +559,11 @@ func getEnvInt(key string, fallback int) int {
 	return fallback
 }
 
+func getEnvFloat(key string, fallback float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return fallback
+}
@@ -4,8 +4,11 @@ import (
 	"os"
 	"testing"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/secrets"
 )
 
 func TestLoad_EmptyCoreDBURL(t *testing.T) {
@@ -47,6 +50,20 @@ func TestLoad_Defaults(t *testing.T) {
 	assert.Equal(t, "", cfg.PowerDNSDatabaseURL)
 }
 
+func TestLoad_DBPoolDefaults(t *testing.T) {
+	t.Setenv("CORE_DATABASE_URL", "postgres://localhost/core")
+	t.Setenv("DB_MAX_CONNS", "40")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, 40, cfg.DBMaxConns)
+	assert.Equal(t, 0, cfg.DBMinConns)
+	assert.Equal(t, 3600, cfg.DBMaxConnLifetimeSecs)
+	assert.Equal(t, 1800, cfg.DBMaxConnIdleTimeSecs)
+	assert.Equal(t, 500, cfg.DBSlowQueryMS)
+}
+
 func TestLoad_AllEnvVars(t *testing.T) {
 	t.Setenv("CORE_DATABASE_URL", "postgres://core:5432/coredb")
 	t.Setenv("POWERDNS_DATABASE_URL", "postgres://svc:5432/svcdb")
@@ -106,6 +123,66 @@ func TestValidate_TLS_MismatchedCertKey(t *testing.T) {
 	assert.Contains(t, err.Error(), "TEMPORAL_TLS_CERT and TEMPORAL_TLS_KEY must both be set")
 }
 
+func TestValidate_CoreAPI_InvalidIPAllowlist(t *testing.T) {
+	cfg := &Config{
+		CoreDatabaseURL:     "postgres://localhost/db",
+		TemporalAddress:     "localhost:7233",
+		HTTPListenAddr:      ":8090",
+		SecretEncryptionKey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		APIIPAllowlist:      "203.0.113.0/24, office",
+	}
+	err := cfg.Validate("core-api")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "API_IP_ALLOWLIST")
+}
+
+func TestValidate_CoreAPI_UploadLimitBelowRequestLimit(t *testing.T) {
+	cfg := &Config{
+		CoreDatabaseURL:     "postgres://localhost/db",
+		TemporalAddress:     "localhost:7233",
+		HTTPListenAddr:      ":8090",
+		SecretEncryptionKey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		MaxRequestBodyBytes: 1 << 20,
+		MaxUploadBodyBytes:  1 << 10,
+	}
+	err := cfg.Validate("core-api")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MAX_UPLOAD_BODY_BYTES")
+}
+
+func TestValidate_CoreAPI_InvalidPasswordGenerator(t *testing.T) {
+	base := Config{
+		CoreDatabaseURL:     "postgres://localhost/db",
+		TemporalAddress:     "localhost:7233",
+		HTTPListenAddr:      ":8090",
+		SecretEncryptionKey: "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	}
+
+	cfg := base
+	cfg.GeneratedPasswordClasses = "lower,emoji"
+	err := cfg.Validate("core-api")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GENERATED_PASSWORD_CLASSES")
+
+	cfg = base
+	cfg.GeneratedPasswordLength = 6
+	err = cfg.Validate("core-api")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GENERATED_PASSWORD_LENGTH")
+}
+
+func TestConfig_PasswordGenerator(t *testing.T) {
+	cfg := &Config{GeneratedPasswordLength: 24, GeneratedPasswordClasses: "lower,digit,symbol"}
+	g, err := cfg.PasswordGenerator()
+	require.NoError(t, err)
+	assert.Equal(t, 24, g.Length)
+	assert.Equal(t, []secrets.Class{secrets.Lower, secrets.Digit, secrets.Symbol}, g.Classes)
+
+	g, err = (&Config{}).PasswordGenerator()
+	require.NoError(t, err)
+	assert.Equal(t, secrets.DefaultGenerator, g)
+}
+
 func TestValidate_AllPresent(t *testing.T) {
 	cfg := &Config{
 		CoreDatabaseURL:     "postgres://localhost/db",
@@ -122,3 +199,125 @@ func TestValidate_AllPresent(t *testing.T) {
 	assert.NoError(t, cfg.Validate("worker"))
 	assert.NoError(t, cfg.Validate("node-agent"))
 }
+
+func TestLoad_WorkerTuning(t *testing.T) {
+	t.Setenv("WORKER_MAX_CONCURRENT_ACTIVITIES", "20")
+	t.Setenv("WORKER_ACTIVITIES_PER_SECOND", "2.5")
+	t.Setenv("WORKER_ACTIVITY_CONCURRENCY", "CreateWebBackup=2, DumpMySQLDatabase=1")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, cfg.WorkerMaxConcurrentActivities)
+	assert.Equal(t, 0, cfg.WorkerMaxConcurrentWorkflowTasks)
+	assert.Equal(t, 2.5, cfg.WorkerActivitiesPerSecond)
+
+	limits, err := cfg.ActivityConcurrencyLimits()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"CreateWebBackup": 2, "DumpMySQLDatabase": 1}, limits)
+}
+
+func TestDefaultNodeActivityConcurrency_Parses(t *testing.T) {
+	limits, err := parseActivityConcurrency(DefaultNodeActivityConcurrency)
+	require.NoError(t, err)
+	assert.Equal(t, 2, limits["CreateMySQLBackup"])
+	assert.Equal(t, 1, limits["CreateTenantExportArchive"])
+}
+
+func TestValidate_WorkerTuning(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		errMsg string
+	}{
+		{"negative activities", func(c *Config) { c.WorkerMaxConcurrentActivities = -1 }, "WORKER_MAX_CONCURRENT_ACTIVITIES"},
+		{"single workflow task slot", func(c *Config) { c.WorkerMaxConcurrentWorkflowTasks = 1 }, "at least 2"},
+		{"negative rate", func(c *Config) { c.WorkerActivitiesPerSecond = -0.5 }, "WORKER_ACTIVITIES_PER_SECOND"},
+		{"malformed entry", func(c *Config) { c.WorkerActivityConcurrency = "CreateWebBackup" }, "expected Name=N"},
+		{"zero limit", func(c *Config) { c.WorkerActivityConcurrency = "CreateWebBackup=0" }, "positive integer"},
+		{"duplicate entry", func(c *Config) { c.WorkerActivityConcurrency = "CreateWebBackup=1,CreateWebBackup=2" }, "duplicate"},
+		{"limit above activity slots", func(c *Config) {
+			c.WorkerMaxConcurrentActivities = 2
+			c.WorkerActivityConcurrency = "CreateWebBackup=4"
+		}, "exceeds WORKER_MAX_CONCURRENT_ACTIVITIES"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{NodeID: "node-1", TemporalAddress: "localhost:7233"}
+			tt.modify(cfg)
+			err := cfg.Validate("node-agent")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestValidate_Logging(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		errMsg string
+	}{
+		{"unknown format", func(c *Config) { c.LogFormat = "text" }, "LOG_FORMAT"},
+		{"malformed override", func(c *Config) { c.LogLevels = "nginx-manager" }, "expected component=level"},
+		{"unknown level", func(c *Config) { c.LogLevels = "nginx-manager=chatty" }, "invalid level"},
+		{"empty level", func(c *Config) { c.LogLevels = "nginx-manager=" }, "invalid level"},
+		{"duplicate override", func(c *Config) { c.LogLevels = "cron-manager=debug,cron-manager=warn" }, "duplicate"},
+		{"negative burst", func(c *Config) { c.LogSampleBurst = -1 }, "LOG_SAMPLE_BURST"},
+		{"burst without period", func(c *Config) { c.LogSampleBurst = 100 }, "LOG_SAMPLE_PERIOD_SECS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{NodeID: "node-1", TemporalAddress: "localhost:7233"}
+			tt.modify(cfg)
+			err := cfg.Validate("node-agent")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestParseLogLevels(t *testing.T) {
+	levels, err := ParseLogLevels(" nginx-manager=debug , ConvergeShardWorkflow=trace")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]zerolog.Level{
+		"nginx-manager":         zerolog.DebugLevel,
+		"ConvergeShardWorkflow": zerolog.TraceLevel,
+	}, levels)
+
+	levels, err = ParseLogLevels("")
+	require.NoError(t, err)
+	assert.Empty(t, levels)
+}
+
+func TestConfig_CORSOrigins(t *testing.T) {
+	origins, err := (&Config{CORSAllowedOrigins: " https://Dash.example.com/ , http://localhost:5173"}).CORSOrigins()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://dash.example.com", "http://localhost:5173"}, origins)
+
+	origins, err = (&Config{}).CORSOrigins()
+	require.NoError(t, err)
+	assert.Empty(t, origins)
+
+	for _, bad := range []string{"dash.example.com", "https://dash.example.com/app", "ftp://x", "*,https://a.example.com"} {
+		_, err := (&Config{CORSAllowedOrigins: bad}).CORSOrigins()
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestValidate_CoreAPI_CORSCredentialedWildcard(t *testing.T) {
+	cfg := &Config{
+		CoreDatabaseURL:      "postgres://localhost/db",
+		TemporalAddress:      "localhost:7233",
+		HTTPListenAddr:       ":8090",
+		SecretEncryptionKey:  "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		CORSAllowedOrigins:   "*",
+		CORSAllowCredentials: true,
+	}
+	err := cfg.Validate("core-api")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CORS_ALLOW_CREDENTIALS")
+
+	cfg.CORSAllowCredentials = false
+	assert.NoError(t, cfg.Validate("core-api"))
+}
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// Store holds the active config of a running process. Reload re-reads the
+// environment and atomically swaps in a new snapshot where only the
+// hot-reloadable fields have changed; everything else keeps its startup value.
+//
+// Hot-reloadable: LOG_LEVEL, LOG_LEVELS, AUDIT_LOG_RETENTION_DAYS,
+// BACKUP_RETENTION_DAYS.
+// Everything else (listen address, database URLs, Temporal connection, keys)
+// is bound at startup and requires a restart.
+type Store struct {
+	current    atomic.Pointer[Config]
+	reloadable map[string]bool
+}
+
+// LogLevelReloadable are the hot-reloadable env vars core-api applies. The
+// retention settings only matter to the worker, which owns the retention
+// cron schedules.
+var LogLevelReloadable = []string{"LOG_LEVEL", "LOG_LEVELS"}
+
+// NewStore creates a Store holding cfg. Reload only re-reads the
+// hot-reloadable env vars in reloadable, or all of them if none are given.
+func NewStore(cfg *Config, reloadable ...string) *Store {
+	s := &Store{}
+	if len(reloadable) > 0 {
+		s.reloadable = make(map[string]bool, len(reloadable))
+		for _, name := range reloadable {
+			s.reloadable[name] = true
+		}
+	}
+	s.current.Store(cfg)
+	return s
+}
+
+// reloads reports whether Reload re-reads the env var name.
+func (s *Store) reloads(name string) bool {
+	return s.reloadable == nil || s.reloadable[name]
+}
+
+// Get returns the current config snapshot. Callers must not modify it.
+func (s *Store) Get() *Config {
+	return s.current.Load()
+}
+
+// Reload re-reads the environment and swaps in the store's hot-reloadable
+// fields.
+// A process cannot observe changes to its own environment, so when
+// CONFIG_RELOAD_FILE is set its KEY=VALUE lines are applied to the
+// environment first (e.g. a mounted ConfigMap). It returns the names of the
+// env vars whose values changed.
+func (s *Store) Reload() ([]string, error) {
+	if path := s.Get().ConfigReloadFile; path != "" {
+		if err := applyEnvFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	next, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if s.reloads("LOG_LEVEL") {
+		if _, err := zerolog.ParseLevel(next.LogLevel); err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVEL %q: %w", next.LogLevel, err)
+		}
+	}
+	if s.reloads("LOG_LEVELS") {
+		if _, err := ParseLogLevels(next.LogLevels); err != nil {
+			return nil, fmt.Errorf("invalid LOG_LEVELS %q: %w", next.LogLevels, err)
+		}
+	}
+	if s.reloads("AUDIT_LOG_RETENTION_DAYS") && next.AuditLogRetentionDays <= 0 {
+		return nil, fmt.Errorf("AUDIT_LOG_RETENTION_DAYS must be positive, got %d", next.AuditLogRetentionDays)
+	}
+	if s.reloads("BACKUP_RETENTION_DAYS") && next.BackupRetentionDays <= 0 {
+		return nil, fmt.Errorf("BACKUP_RETENTION_DAYS must be positive, got %d", next.BackupRetentionDays)
+	}
+
+	cur := s.Get()
+	merged, changed := mergeReloadable(cur, next, s.reloads)
+	s.current.Store(merged)
+	return changed, nil
+}
+
+// mergeReloadable returns a copy of cur with the hot-reloadable fields that
+// reloads accepts taken from next, plus the env var names of the fields
+// that differ.
+func mergeReloadable(cur, next *Config, reloads func(name string) bool) (*Config, []string) {
+	merged := *cur
+	var changed []string
+
+	if reloads("LOG_LEVEL") && cur.LogLevel != next.LogLevel {
+		merged.LogLevel = next.LogLevel
+		changed = append(changed, "LOG_LEVEL")
+	}
+	if reloads("LOG_LEVELS") && cur.LogLevels != next.LogLevels {
+		merged.LogLevels = next.LogLevels
+		changed = append(changed, "LOG_LEVELS")
+	}
+	if reloads("AUDIT_LOG_RETENTION_DAYS") && cur.AuditLogRetentionDays != next.AuditLogRetentionDays {
+		merged.AuditLogRetentionDays = next.AuditLogRetentionDays
+		changed = append(changed, "AUDIT_LOG_RETENTION_DAYS")
+	}
+	if reloads("BACKUP_RETENTION_DAYS") && cur.BackupRetentionDays != next.BackupRetentionDays {
+		merged.BackupRetentionDays = next.BackupRetentionDays
+		changed = append(changed, "BACKUP_RETENTION_DAYS")
+	}
+
+	return &merged, changed
+}
+
+// applyEnvFile sets environment variables from a file of KEY=VALUE lines.
+// Blank lines and lines starting with # are ignored; values may be quoted.
+func applyEnvFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open config reload file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("config reload file: invalid line %q", line)
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		if err := os.Setenv(strings.TrimSpace(key), value); err != nil {
+			return fmt.Errorf("config reload file: set %s: %w", key, err)
+		}
+	}
+	return scanner.Err()
+}
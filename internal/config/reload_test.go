@@ -0,0 +1,106 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_ReloadSwapsHotReloadableFields(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "info")
+	t.Setenv("AUDIT_LOG_RETENTION_DAYS", "90")
+	t.Setenv("HTTP_LISTEN_ADDR", ":8090")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	store := NewStore(cfg)
+
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("AUDIT_LOG_RETENTION_DAYS", "30")
+	t.Setenv("HTTP_LISTEN_ADDR", ":9999")
+
+	changed, err := store.Reload()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"LOG_LEVEL", "AUDIT_LOG_RETENTION_DAYS"}, changed)
+
+	got := store.Get()
+	assert.Equal(t, "debug", got.LogLevel)
+	assert.Equal(t, 30, got.AuditLogRetentionDays)
+	// Restart-only fields keep their startup value.
+	assert.Equal(t, ":8090", got.HTTPListenAddr)
+	// The original snapshot is never mutated.
+	assert.Equal(t, "info", cfg.LogLevel)
+}
+
+func TestStore_ReloadRejectsInvalidLogLevel(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "info")
+	cfg, err := Load()
+	require.NoError(t, err)
+	store := NewStore(cfg)
+
+	t.Setenv("LOG_LEVEL", "chatty")
+	_, err = store.Reload()
+	require.Error(t, err)
+	assert.Equal(t, "info", store.Get().LogLevel)
+}
+
+func TestStore_ReloadComponentLogLevels(t *testing.T) {
+	t.Setenv("LOG_LEVELS", "")
+	cfg, err := Load()
+	require.NoError(t, err)
+	store := NewStore(cfg)
+
+	t.Setenv("LOG_LEVELS", "nginx-manager=chatty")
+	_, err = store.Reload()
+	require.Error(t, err)
+
+	t.Setenv("LOG_LEVELS", "nginx-manager=debug")
+	changed, err := store.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"LOG_LEVELS"}, changed)
+	assert.Equal(t, "nginx-manager=debug", store.Get().LogLevels)
+}
+
+func TestStore_ReloadFromFile(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "info")
+	t.Setenv("BACKUP_RETENTION_DAYS", "30")
+	path := filepath.Join(t.TempDir(), "reload.env")
+	t.Setenv("CONFIG_RELOAD_FILE", path)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	store := NewStore(cfg)
+
+	require.NoError(t, os.WriteFile(path, []byte("# reloadable\nLOG_LEVEL=warn\nBACKUP_RETENTION_DAYS=\"14\"\n"), 0o600))
+
+	changed, err := store.Reload()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"LOG_LEVEL", "BACKUP_RETENTION_DAYS"}, changed)
+	assert.Equal(t, "warn", store.Get().LogLevel)
+	assert.Equal(t, 14, store.Get().BackupRetentionDays)
+}
+
+func TestStore_ReloadOnlyAppliesItsFields(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "info")
+	t.Setenv("AUDIT_LOG_RETENTION_DAYS", "90")
+	t.Setenv("BACKUP_RETENTION_DAYS", "30")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	store := NewStore(cfg, LogLevelReloadable...)
+
+	t.Setenv("LOG_LEVEL", "debug")
+	t.Setenv("AUDIT_LOG_RETENTION_DAYS", "30")
+	// Out of scope, so an invalid value does not abort the reload.
+	t.Setenv("BACKUP_RETENTION_DAYS", "0")
+
+	changed, err := store.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"LOG_LEVEL"}, changed)
+	assert.Equal(t, "debug", store.Get().LogLevel)
+	assert.Equal(t, 90, store.Get().AuditLogRetentionDays)
+	assert.Equal(t, 30, store.Get().BackupRetentionDays)
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/edvin/hosting/internal/controlpanel/api/response"
+	"github.com/edvin/hosting/internal/ipallow"
+)
+
+// IPAllowlist returns a middleware that rejects requests from clients outside
+// the allowed ranges with 403. Health checks and metrics are never
+// restricted. It must run before chi's RealIP, which trusts X-Forwarded-For
+// from any peer.
+func IPAllowlist(list *ipallow.List) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if !list.Enabled() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/healthz", "/readyz", "/metrics":
+			default:
+				if !list.Allows(r) {
+					response.WriteError(w, http.StatusForbidden, "client address not allowed")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
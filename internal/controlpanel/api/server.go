@@ -18,6 +18,7 @@ import (
 	"github.com/edvin/hosting/internal/controlpanel/config"
 	"github.com/edvin/hosting/internal/controlpanel/core"
 	"github.com/edvin/hosting/internal/controlpanel/hosting"
+	"github.com/edvin/hosting/internal/ipallow"
 )
 
 type Server struct {
@@ -47,9 +48,16 @@ func NewServer(logger zerolog.Logger, pool *pgxpool.Pool, cfg *config.Config) *S
 }
 
 func (s *Server) setupMiddleware() {
+	allowlist, err := ipallow.New(s.cfg.IPAllowlist, s.cfg.TrustedProxies)
+	if err != nil {
+		s.logger.Fatal().Err(err).Msg("invalid IP allowlist")
+	}
+
 	s.router.Use(chimw.RequestID)
-	s.router.Use(chimw.RealIP)
 	s.router.Use(mw.RequestLogger(s.logger))
+	// Runs before RealIP so the allowlist sees the original peer address.
+	s.router.Use(mw.IPAllowlist(allowlist))
+	s.router.Use(chimw.RealIP)
 	s.router.Use(chimw.Recoverer)
 	s.router.Use(mw.Metrics)
 	s.router.Use(mw.CORS(s.cfg.CORSOrigins))
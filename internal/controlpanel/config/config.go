@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/edvin/hosting/internal/ipallow"
 )
 
 type OIDCProvider struct {
@@ -28,6 +30,8 @@ type Config struct {
 	HostingAPIKey  string
 	DevMode        bool
 	OIDCProviders  []OIDCProvider
+	IPAllowlist    string // IP_ALLOWLIST — comma-separated CIDRs allowed to reach the API; empty allows all
+	TrustedProxies string // TRUSTED_PROXIES — comma-separated CIDRs whose X-Forwarded-For is honored
 }
 
 func Load() (*Config, error) {
@@ -49,6 +53,8 @@ func Load() (*Config, error) {
 		HostingAPIURL:  getEnv("HOSTING_API_URL", ""),
 		HostingAPIKey:  getEnv("HOSTING_API_KEY", ""),
 		DevMode:        getEnv("DEV_MODE", "") == "true",
+		IPAllowlist:    getEnv("IP_ALLOWLIST", ""),
+		TrustedProxies: getEnv("TRUSTED_PROXIES", ""),
 	}
 
 	cfg.OIDCProviders = loadOIDCProviders()
@@ -70,6 +76,9 @@ func (c *Config) Validate() error {
 	if len(missing) > 0 {
 		return fmt.Errorf("missing required config: %s", strings.Join(missing, ", "))
 	}
+	if _, err := ipallow.New(c.IPAllowlist, c.TrustedProxies); err != nil {
+		return fmt.Errorf("IP_ALLOWLIST/TRUSTED_PROXIES: %w", err)
+	}
 	return nil
 }
 
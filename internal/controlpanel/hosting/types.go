@@ -15,14 +15,20 @@ type Webroot struct {
 }
 
 type FQDN struct {
-	ID         string    `json:"id"`
-	TenantID   string    `json:"tenant_id"`
-	WebrootID  *string   `json:"webroot_id"`
-	FQDN       string    `json:"fqdn"`
-	SSLEnabled bool      `json:"ssl_enabled"`
-	Status     string    `json:"status"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID         string  `json:"id"`
+	TenantID   string  `json:"tenant_id"`
+	WebrootID  *string `json:"webroot_id"`
+	FQDN       string  `json:"fqdn"`
+	SSLEnabled bool    `json:"ssl_enabled"`
+	ForceHTTPS bool    `json:"force_https"`
+	// RedirectTarget is set when the FQDN is a domain alias.
+	RedirectTarget       *string   `json:"redirect_target,omitempty"`
+	RedirectCode         int       `json:"redirect_code"`
+	RedirectPreservePath bool      `json:"redirect_preserve_path"`
+	IPMode               string    `json:"ip_mode"`
+	Status               string    `json:"status"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
 }
 
 type Daemon struct {
@@ -52,6 +58,7 @@ type CronJob struct {
 	Enabled          bool      `json:"enabled"`
 	TimeoutSeconds   int       `json:"timeout_seconds"`
 	MaxMemoryMB      int       `json:"max_memory_mb"`
+	Timezone         string    `json:"timezone"`
 	Status           string    `json:"status"`
 	StatusMessage    *string   `json:"status_message"`
 	CreatedAt        time.Time `json:"created_at"`
@@ -255,6 +262,8 @@ type Backup struct {
 	StatusMessage *string    `json:"status_message"`
 	StartedAt     *time.Time `json:"started_at"`
 	CompletedAt   *time.Time `json:"completed_at"`
+	VerifyStatus  *string    `json:"verify_status"`
+	VerifiedAt    *time.Time `json:"verified_at"`
 	CreatedAt     time.Time  `json:"created_at"`
 }
 
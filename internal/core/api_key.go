@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/edvin/hosting/internal/model"
 	"github.com/edvin/hosting/internal/platform"
@@ -21,8 +22,17 @@ func NewAPIKeyService(db DB) *APIKeyService {
 	return &APIKeyService{db: db}
 }
 
+// SSOKeyPrefix prefixes the names of keys the admin UI provisions for SSO
+// logins. At most one unrevoked key exists per SSO name.
+const SSOKeyPrefix = "sso:"
+
 // Create generates a new API key, stores the hash, and returns the model along
 // with the raw key string. The raw key must be shown to the user exactly once.
+//
+// Creating a key whose name starts with SSOKeyPrefix is idempotent: if an
+// unrevoked key with that name exists, its secret is rotated and the same key
+// ID is returned with the new raw value. Any session still holding the old
+// value must sign in again.
 func (s *APIKeyService) Create(ctx context.Context, name string, scopes, brands []string) (*model.APIKey, string, error) {
 	// Generate a random 32-byte key.
 	rawBytes := make([]byte, 32)
@@ -31,7 +41,20 @@ func (s *APIKeyService) Create(ctx context.Context, name string, scopes, brands
 	}
 	rawKey := "hst_" + hex.EncodeToString(rawBytes) // 68 chars total
 
-	return s.createWithKey(ctx, name, rawKey, scopes, brands)
+	return s.createWithKey(ctx, name, rawKey, scopes, brands, nil)
+}
+
+// CreateForReseller generates a new API key bound to a reseller. The key's
+// brand access is the reseller's brand, and it only reaches the reseller's
+// tenants.
+func (s *APIKeyService) CreateForReseller(ctx context.Context, name string, scopes []string, reseller *model.Reseller) (*model.APIKey, string, error) {
+	rawBytes := make([]byte, 32)
+	if _, err := rand.Read(rawBytes); err != nil {
+		return nil, "", fmt.Errorf("generate api key: %w", err)
+	}
+	rawKey := "hst_" + hex.EncodeToString(rawBytes)
+
+	return s.createWithKey(ctx, name, rawKey, scopes, []string{reseller.BrandID}, &reseller.ID)
 }
 
 // CreateWithRawKey stores an API key with a caller-provided raw key value.
@@ -68,7 +91,7 @@ func (s *APIKeyService) CreateWithRawKey(ctx context.Context, name, rawKey strin
 	}, nil
 }
 
-func (s *APIKeyService) createWithKey(ctx context.Context, name, rawKey string, scopes, brands []string) (*model.APIKey, string, error) {
+func (s *APIKeyService) createWithKey(ctx context.Context, name, rawKey string, scopes, brands []string, resellerID *string) (*model.APIKey, string, error) {
 	id := platform.NewID()
 
 	hash := sha256.Sum256([]byte(rawKey))
@@ -82,20 +105,25 @@ func (s *APIKeyService) createWithKey(ctx context.Context, name, rawKey string,
 		brands = []string{"*"}
 	}
 
+	if resellerID == nil && strings.HasPrefix(name, SSOKeyPrefix) {
+		return s.upsertSSOKey(ctx, name, rawKey, keyHash, keyPrefix, scopes, brands)
+	}
+
 	_, err := s.db.Exec(ctx,
-		`INSERT INTO api_keys (id, name, key_hash, key_prefix, scopes, brands, created_at) VALUES ($1, $2, $3, $4, $5, $6, now())`,
-		id, name, keyHash, keyPrefix, scopes, brands,
+		`INSERT INTO api_keys (id, name, key_hash, key_prefix, scopes, brands, reseller_id, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, now())`,
+		id, name, keyHash, keyPrefix, scopes, brands, resellerID,
 	)
 	if err != nil {
 		return nil, "", fmt.Errorf("insert api key: %w", err)
 	}
 
 	key := &model.APIKey{
-		ID:        id,
-		Name:      name,
-		KeyPrefix: keyPrefix,
-		Scopes:    scopes,
-		Brands:    brands,
+		ID:         id,
+		Name:       name,
+		KeyPrefix:  keyPrefix,
+		Scopes:     scopes,
+		Brands:     brands,
+		ResellerID: resellerID,
 	}
 	// Fetch the server-generated created_at.
 	err = s.db.QueryRow(ctx, "SELECT created_at FROM api_keys WHERE id = $1", id).Scan(&key.CreatedAt)
@@ -106,12 +134,35 @@ func (s *APIKeyService) createWithKey(ctx context.Context, name, rawKey string,
 	return key, rawKey, nil
 }
 
+// upsertSSOKey inserts an SSO key or rotates the secret of the existing
+// unrevoked key with the same name, so repeated or retried logins never leave
+// more than one active key per user.
+func (s *APIKeyService) upsertSSOKey(ctx context.Context, name, rawKey, keyHash, keyPrefix string, scopes, brands []string) (*model.APIKey, string, error) {
+	key := &model.APIKey{
+		Name:      name,
+		KeyPrefix: keyPrefix,
+		Scopes:    scopes,
+		Brands:    brands,
+	}
+	err := s.db.QueryRow(ctx,
+		`INSERT INTO api_keys (id, name, key_hash, key_prefix, scopes, brands, created_at) VALUES ($1, $2, $3, $4, $5, $6, now())
+		ON CONFLICT (name) WHERE revoked_at IS NULL AND name LIKE 'sso:%'
+		DO UPDATE SET key_hash = EXCLUDED.key_hash, key_prefix = EXCLUDED.key_prefix, scopes = EXCLUDED.scopes, brands = EXCLUDED.brands
+		RETURNING id, created_at`,
+		platform.NewID(), name, keyHash, keyPrefix, scopes, brands,
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return nil, "", fmt.Errorf("upsert sso api key %s: %w", name, err)
+	}
+	return key, rawKey, nil
+}
+
 // GetByID retrieves an API key by its ID.
 func (s *APIKeyService) GetByID(ctx context.Context, id string) (*model.APIKey, error) {
 	var k model.APIKey
 	err := s.db.QueryRow(ctx,
-		`SELECT id, name, key_prefix, scopes, brands, created_at, revoked_at FROM api_keys WHERE id = $1`, id,
-	).Scan(&k.ID, &k.Name, &k.KeyPrefix, &k.Scopes, &k.Brands, &k.CreatedAt, &k.RevokedAt)
+		`SELECT id, name, key_prefix, scopes, brands, reseller_id, created_at, revoked_at FROM api_keys WHERE id = $1`, id,
+	).Scan(&k.ID, &k.Name, &k.KeyPrefix, &k.Scopes, &k.Brands, &k.ResellerID, &k.CreatedAt, &k.RevokedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get api key %s: %w", id, err)
 	}
@@ -120,7 +171,7 @@ func (s *APIKeyService) GetByID(ctx context.Context, id string) (*model.APIKey,
 
 // List retrieves API keys with cursor-based pagination.
 func (s *APIKeyService) List(ctx context.Context, limit int, cursor string) ([]model.APIKey, bool, error) {
-	query := `SELECT id, name, key_prefix, scopes, brands, created_at, revoked_at FROM api_keys WHERE 1=1`
+	query := `SELECT id, name, key_prefix, scopes, brands, reseller_id, created_at, revoked_at FROM api_keys WHERE 1=1`
 	args := []any{}
 	argIdx := 1
 
@@ -143,7 +194,7 @@ func (s *APIKeyService) List(ctx context.Context, limit int, cursor string) ([]m
 	var keys []model.APIKey
 	for rows.Next() {
 		var k model.APIKey
-		if err := rows.Scan(&k.ID, &k.Name, &k.KeyPrefix, &k.Scopes, &k.Brands, &k.CreatedAt, &k.RevokedAt); err != nil {
+		if err := rows.Scan(&k.ID, &k.Name, &k.KeyPrefix, &k.Scopes, &k.Brands, &k.ResellerID, &k.CreatedAt, &k.RevokedAt); err != nil {
 			return nil, false, fmt.Errorf("scan api key: %w", err)
 		}
 		keys = append(keys, k)
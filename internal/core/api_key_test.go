@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyService_Create_SSORotatesExistingKey(t *testing.T) {
+	db := &mockDB{}
+	svc := NewAPIKeyService(db)
+	ctx := context.Background()
+	created := time.Now().Add(-24 * time.Hour)
+
+	db.On("QueryRow", ctx, queryContaining("ON CONFLICT (name)"), mock.Anything).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "existing-key"
+			*(dest[1].(*time.Time)) = created
+			return nil
+		}}).Twice()
+
+	key1, raw1, err := svc.Create(ctx, "sso:alice@example.com", []string{"*:*"}, []string{"*"})
+	require.NoError(t, err)
+	key2, raw2, err := svc.Create(ctx, "sso:alice@example.com", []string{"*:*"}, []string{"*"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "existing-key", key1.ID)
+	assert.Equal(t, key1.ID, key2.ID)
+	assert.Equal(t, created, key2.CreatedAt)
+	assert.NotEqual(t, raw1, raw2)
+	assert.Equal(t, raw2[:12], key2.KeyPrefix)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+	db.AssertExpectations(t)
+}
+
+func TestAPIKeyService_Create_SSOError(t *testing.T) {
+	db := &mockDB{}
+	svc := NewAPIKeyService(db)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("ON CONFLICT (name)"), mock.Anything).
+		Return(&mockRow{scanFunc: func(dest ...any) error { return errors.New("db down") }})
+
+	_, _, err := svc.Create(ctx, "sso:alice@example.com", nil, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "upsert sso api key")
+}
+
+func TestAPIKeyService_Create_RegularKeyInserts(t *testing.T) {
+	db := &mockDB{}
+	svc := NewAPIKeyService(db)
+	ctx := context.Background()
+
+	db.On("Exec", ctx, queryContaining("INSERT INTO api_keys"), mock.Anything).Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+	db.On("QueryRow", ctx, queryContaining("SELECT created_at"), mock.Anything).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*time.Time)) = time.Now()
+			return nil
+		}})
+
+	key, raw, err := svc.Create(ctx, "ci", nil, nil)
+	require.NoError(t, err)
+	assert.NotEmpty(t, key.ID)
+	assert.Len(t, raw, 68)
+	db.AssertExpectations(t)
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrAppTemplateNotFound is returned when a template ID does not exist for
+// the tenant's brand.
+var ErrAppTemplateNotFound = errors.New("app template not found")
+
+// ErrNoDatabaseShard is returned by CreateFromTemplate when the template
+// creates a database but the tenant's cluster has no active database shard.
+var ErrNoDatabaseShard = errors.New("no active database shard in the tenant's cluster")
+
+const appTemplateColumns = `id, brand_id, name, description, runtime, runtime_version, runtime_config, public_folder, create_database, env_vars, created_at, updated_at`
+
+func scanAppTemplate(row pgx.Row) (model.AppTemplate, error) {
+	var t model.AppTemplate
+	var envVars []byte
+	if err := row.Scan(&t.ID, &t.BrandID, &t.Name, &t.Description, &t.Runtime, &t.RuntimeVersion,
+		&t.RuntimeConfig, &t.PublicFolder, &t.CreateDatabase, &envVars, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(envVars, &t.EnvVars); err != nil {
+		return t, fmt.Errorf("unmarshal env vars of app template %s: %w", t.ID, err)
+	}
+	return t, nil
+}
+
+// ListAppTemplates returns the brand's app templates ordered by name.
+func (s *BrandService) ListAppTemplates(ctx context.Context, brandID string) ([]model.AppTemplate, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT `+appTemplateColumns+` FROM app_templates WHERE brand_id = $1 ORDER BY name, id`, brandID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list app templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []model.AppTemplate
+	for rows.Next() {
+		t, err := scanAppTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan app template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// GetAppTemplate returns one of the brand's app templates, or
+// ErrAppTemplateNotFound.
+func (s *BrandService) GetAppTemplate(ctx context.Context, brandID, id string) (*model.AppTemplate, error) {
+	t, err := scanAppTemplate(s.db.QueryRow(ctx,
+		`SELECT `+appTemplateColumns+` FROM app_templates WHERE brand_id = $1 AND id = $2`, brandID, id,
+	))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAppTemplateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get app template %s: %w", id, err)
+	}
+	return &t, nil
+}
+
+// SetAppTemplates replaces the brand's app templates. Webroots already
+// created from a template are not changed.
+func (s *BrandService) SetAppTemplates(ctx context.Context, brandID string, templates []model.AppTemplate) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM app_templates WHERE brand_id = $1`, brandID)
+	if err != nil {
+		return fmt.Errorf("clear app templates: %w", err)
+	}
+
+	for _, t := range templates {
+		envVars, err := json.Marshal(t.EnvVars)
+		if err != nil {
+			return fmt.Errorf("marshal env vars of app template %s: %w", t.ID, err)
+		}
+		_, err = s.db.Exec(ctx,
+			`INSERT INTO app_templates (`+appTemplateColumns+`)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
+			t.ID, brandID, t.Name, t.Description, t.Runtime, t.RuntimeVersion, t.RuntimeConfig,
+			t.PublicFolder, t.CreateDatabase, envVars, t.CreatedAt, t.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("insert app template %s: %w", t.ID, err)
+		}
+	}
+	return nil
+}
+
+// CreateFromTemplate creates a webroot whose runtime and public folder were
+// taken from tmpl, together with the template's env vars and, if the template
+// asks for one, a database on the first active database shard of the
+// tenant's cluster. All of it is provisioned by
+// CreateWebrootFromTemplateWorkflow, which removes every created resource if
+// a step fails. It returns the created database, or nil.
+func (s *WebrootService) CreateFromTemplate(ctx context.Context, webroot *model.Webroot, tmpl *model.AppTemplate) (*model.Database, error) {
+	var database *model.Database
+	if tmpl.CreateDatabase {
+		var shardID string
+		err := s.db.QueryRow(ctx,
+			`SELECT s.id FROM shards s JOIN tenants t ON t.cluster_id = s.cluster_id
+			 WHERE t.id = $1 AND s.role = $2 AND s.status = $3
+			 ORDER BY s.name LIMIT 1`, webroot.TenantID, model.ShardRoleDatabase, model.StatusActive,
+		).Scan(&shardID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoDatabaseShard
+		}
+		if err != nil {
+			return nil, fmt.Errorf("find database shard: %w", err)
+		}
+		charset, collation, _ := model.ResolveDatabaseCharset("", "")
+		database = &model.Database{
+			ID:             platform.NewName("db"),
+			TenantID:       webroot.TenantID,
+			SubscriptionID: webroot.SubscriptionID,
+			ShardID:        &shardID,
+			Charset:        charset,
+			Collation:      collation,
+			Status:         model.StatusPending,
+			CreatedAt:      webroot.CreatedAt,
+			UpdatedAt:      webroot.UpdatedAt,
+		}
+	}
+
+	if err := s.insert(ctx, webroot); err != nil {
+		return nil, err
+	}
+
+	params := model.CreateWebrootFromTemplateParams{WebrootID: webroot.ID}
+	var databaseName string
+	if database != nil {
+		_, err := s.db.Exec(ctx,
+			`INSERT INTO databases (id, tenant_id, subscription_id, shard_id, node_id, charset, collation, status, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			database.ID, database.TenantID, database.SubscriptionID, database.ShardID, database.NodeID,
+			database.Charset, database.Collation, database.Status, database.CreatedAt, database.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("insert database: %w", err)
+		}
+		params.DatabaseID = database.ID
+		databaseName = database.ID
+	}
+
+	for name, value := range tmpl.EnvVars {
+		_, err := s.db.Exec(ctx,
+			`INSERT INTO webroot_env_vars (id, webroot_id, name, value, is_secret, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, false, $5, $6)`,
+			platform.NewID(), webroot.ID, name, model.ExpandAppTemplateEnv(value, webroot.ID, databaseName),
+			webroot.CreatedAt, webroot.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("insert env var %s: %w", name, err)
+		}
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, webroot.TenantID, model.ProvisionTask{
+		WorkflowName: "CreateWebrootFromTemplateWorkflow",
+		WorkflowID:   workflowID("create-webroot-template", webroot.ID),
+		Arg:          params,
+	}); err != nil {
+		return nil, fmt.Errorf("signal CreateWebrootFromTemplateWorkflow: %w", err)
+	}
+
+	return database, nil
+}
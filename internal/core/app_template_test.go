@@ -0,0 +1,107 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func TestBrandService_GetAppTemplate_NotFound(t *testing.T) {
+	db := &mockDB{}
+	svc := NewBrandService(db, "")
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"acme", "wordpress"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }})
+
+	_, err := svc.GetAppTemplate(ctx, "acme", "wordpress")
+	assert.ErrorIs(t, err, ErrAppTemplateNotFound)
+}
+
+func TestBrandService_GetAppTemplate_Success(t *testing.T) {
+	db := &mockDB{}
+	svc := NewBrandService(db, "")
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"acme", "wordpress"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "wordpress"
+			*(dest[1].(*string)) = "acme"
+			*(dest[4].(*string)) = "php"
+			*(dest[8].(*bool)) = true
+			*(dest[9].(*[]byte)) = []byte(`{"DB_NAME":"{database_name}"}`)
+			return nil
+		}})
+
+	tmpl, err := svc.GetAppTemplate(ctx, "acme", "wordpress")
+	require.NoError(t, err)
+	assert.Equal(t, "php", tmpl.Runtime)
+	assert.True(t, tmpl.CreateDatabase)
+	assert.Equal(t, map[string]string{"DB_NAME": "{database_name}"}, tmpl.EnvVars)
+}
+
+func TestWebrootService_CreateFromTemplate_WithDatabase(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(db, tc)
+	ctx := context.Background()
+	now := time.Now()
+
+	webroot := &model.Webroot{ID: "w1", TenantID: "test-tenant-1", SubscriptionID: "sub-1", Runtime: "php", RuntimeVersion: "8.3", CreatedAt: now, UpdatedAt: now}
+	tmpl := &model.AppTemplate{ID: "wordpress", CreateDatabase: true, EnvVars: map[string]string{"DB_NAME": "{database_name}"}}
+
+	db.On("QueryRow", ctx, mock.MatchedBy(func(q string) bool { return strings.Contains(q, "FROM shards") }),
+		[]any{"test-tenant-1", model.ShardRoleDatabase, model.StatusActive}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "db-shard-1"
+			return nil
+		}})
+	db.On("Exec", ctx, mock.MatchedBy(func(q string) bool { return strings.Contains(q, "INSERT INTO webroots") }), mock.Anything).
+		Return(pgconn.NewCommandTag("INSERT 0 1"), nil).Once()
+	var databaseID string
+	db.On("Exec", ctx, mock.MatchedBy(func(q string) bool { return strings.Contains(q, "INSERT INTO databases") }), mock.Anything).
+		Run(func(args mock.Arguments) { databaseID = args.Get(2).([]any)[0].(string) }).
+		Return(pgconn.NewCommandTag("INSERT 0 1"), nil).Once()
+	db.On("Exec", ctx, mock.MatchedBy(func(q string) bool { return strings.Contains(q, "INSERT INTO webroot_env_vars") }),
+		mock.MatchedBy(func(args []any) bool { return args[2] == "DB_NAME" && args[3] == databaseID })).
+		Return(pgconn.NewCommandTag("INSERT 0 1"), nil).Once()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("SignalWithStartWorkflow", mock.Anything, "tenant-test-tenant-1", model.ProvisionSignalName,
+		mock.MatchedBy(func(task model.ProvisionTask) bool {
+			params, ok := task.Arg.(model.CreateWebrootFromTemplateParams)
+			return task.WorkflowName == "CreateWebrootFromTemplateWorkflow" && ok &&
+				params.WebrootID == "w1" && params.DatabaseID == databaseID
+		}), mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	database, err := svc.CreateFromTemplate(ctx, webroot, tmpl)
+	require.NoError(t, err)
+	require.NotNil(t, database)
+	assert.Equal(t, "db-shard-1", *database.ShardID)
+	assert.Equal(t, "sub-1", database.SubscriptionID)
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestWebrootService_CreateFromTemplate_NoDatabaseShard(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).
+		Return(&mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }})
+
+	_, err := svc.CreateFromTemplate(ctx, &model.Webroot{ID: "w1", TenantID: "test-tenant-1"}, &model.AppTemplate{CreateDatabase: true})
+	assert.ErrorIs(t, err, ErrNoDatabaseShard)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,9 +2,12 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
@@ -43,11 +46,11 @@ func (s *BackupService) Create(ctx context.Context, backup *model.Backup) error
 func (s *BackupService) GetByID(ctx context.Context, id string) (*model.Backup, error) {
 	var b model.Backup
 	err := s.db.QueryRow(ctx,
-		`SELECT id, tenant_id, type, source_id, source_name, storage_path, size_bytes, status, status_message, started_at, completed_at, created_at, updated_at
+		`SELECT id, tenant_id, type, source_id, source_name, storage_path, size_bytes, status, status_message, started_at, completed_at, verify_status, verify_message, verified_at, created_at, updated_at
 		 FROM backups WHERE id = $1`, id,
 	).Scan(&b.ID, &b.TenantID, &b.Type, &b.SourceID, &b.SourceName,
 		&b.StoragePath, &b.SizeBytes, &b.Status, &b.StatusMessage, &b.StartedAt,
-		&b.CompletedAt, &b.CreatedAt, &b.UpdatedAt)
+		&b.CompletedAt, &b.VerifyStatus, &b.VerifyMessage, &b.VerifiedAt, &b.CreatedAt, &b.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get backup %s: %w", id, err)
 	}
@@ -55,7 +58,7 @@ func (s *BackupService) GetByID(ctx context.Context, id string) (*model.Backup,
 }
 
 func (s *BackupService) ListByTenant(ctx context.Context, tenantID string, limit int, cursor string) ([]model.Backup, bool, error) {
-	query := `SELECT id, tenant_id, type, source_id, source_name, storage_path, size_bytes, status, status_message, started_at, completed_at, created_at, updated_at FROM backups WHERE tenant_id = $1`
+	query := `SELECT id, tenant_id, type, source_id, source_name, storage_path, size_bytes, status, status_message, started_at, completed_at, verify_status, verify_message, verified_at, created_at, updated_at FROM backups WHERE tenant_id = $1`
 	args := []any{tenantID}
 	argIdx := 2
 
@@ -80,7 +83,7 @@ func (s *BackupService) ListByTenant(ctx context.Context, tenantID string, limit
 		var b model.Backup
 		if err := rows.Scan(&b.ID, &b.TenantID, &b.Type, &b.SourceID, &b.SourceName,
 			&b.StoragePath, &b.SizeBytes, &b.Status, &b.StatusMessage, &b.StartedAt,
-			&b.CompletedAt, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			&b.CompletedAt, &b.VerifyStatus, &b.VerifyMessage, &b.VerifiedAt, &b.CreatedAt, &b.UpdatedAt); err != nil {
 			return nil, false, fmt.Errorf("scan backup: %w", err)
 		}
 		backups = append(backups, b)
@@ -117,7 +120,23 @@ func (s *BackupService) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
-func (s *BackupService) Restore(ctx context.Context, id string) error {
+// ErrInvalidRestoreTo is returned when a point-in-time restore cannot reach
+// the requested time from the database's backups.
+var ErrInvalidRestoreTo = errors.New("invalid restore_to")
+
+// RestoreBackupParams holds parameters for the RestoreBackupWorkflow. If
+// RestoreTo is set, the database backup is rolled forward to that time by
+// replaying binlogs after it is restored.
+type RestoreBackupParams struct {
+	BackupID  string     `json:"backup_id"`
+	RestoreTo *time.Time `json:"restore_to,omitempty"`
+}
+
+// Restore restores a backup to its source. If restoreTo is set, the backup
+// must be a database backup and identifies the database only: the newest
+// active backup of it completed before restoreTo is restored and rolled
+// forward to restoreTo.
+func (s *BackupService) Restore(ctx context.Context, id string, restoreTo *time.Time) error {
 	// Validate backup exists and is active.
 	backup, err := s.GetByID(ctx, id)
 	if err != nil {
@@ -127,10 +146,19 @@ func (s *BackupService) Restore(ctx context.Context, id string) error {
 		return fmt.Errorf("backup %s is not active (status: %s)", id, backup.Status)
 	}
 
+	params := RestoreBackupParams{BackupID: id}
+	if restoreTo != nil {
+		base, err := s.pointInTimeBase(ctx, backup, *restoreTo)
+		if err != nil {
+			return err
+		}
+		params = RestoreBackupParams{BackupID: base, RestoreTo: restoreTo}
+	}
+
 	if err := signalProvision(ctx, s.tc, s.db, backup.TenantID, model.ProvisionTask{
 		WorkflowName: "RestoreBackupWorkflow",
-		WorkflowID:   workflowID("backup-restore", id),
-		Arg:          id,
+		WorkflowID:   workflowID("backup-restore", params.BackupID),
+		Arg:          params,
 	}); err != nil {
 		return fmt.Errorf("signal RestoreBackupWorkflow: %w", err)
 	}
@@ -138,6 +166,35 @@ func (s *BackupService) Restore(ctx context.Context, id string) error {
 	return nil
 }
 
+// pointInTimeBase returns the ID of the backup a point-in-time restore of
+// backup's database to restoreTo starts from: the newest active backup of
+// the database completed before restoreTo. Whether the binlogs from there
+// on are still retained is checked on the node by the workflow.
+func (s *BackupService) pointInTimeBase(ctx context.Context, backup *model.Backup, restoreTo time.Time) (string, error) {
+	if backup.Type != model.BackupTypeDatabase {
+		return "", fmt.Errorf("%w: point-in-time restore is only supported for database backups", ErrInvalidRestoreTo)
+	}
+	if restoreTo.After(time.Now()) {
+		return "", fmt.Errorf("%w: %s is in the future", ErrInvalidRestoreTo, restoreTo.Format(time.RFC3339))
+	}
+
+	var baseID string
+	err := s.db.QueryRow(ctx,
+		`SELECT id FROM backups
+		 WHERE source_id = $1 AND type = $2 AND status = $3 AND completed_at <= $4
+		 ORDER BY completed_at DESC LIMIT 1`,
+		backup.SourceID, model.BackupTypeDatabase, model.StatusActive, restoreTo,
+	).Scan(&baseID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("%w: no active backup of database %s completed before %s",
+			ErrInvalidRestoreTo, backup.SourceName, restoreTo.Format(time.RFC3339))
+	}
+	if err != nil {
+		return "", fmt.Errorf("find backup to restore %s to %s: %w", backup.SourceName, restoreTo.Format(time.RFC3339), err)
+	}
+	return baseID, nil
+}
+
 func (s *BackupService) Retry(ctx context.Context, id string) error {
 	var status, name, tenantID string
 	err := s.db.QueryRow(ctx, "SELECT status, type || '/' || source_name, tenant_id FROM backups WHERE id = $1", id).Scan(&status, &name, &tenantID)
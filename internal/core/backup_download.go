@@ -0,0 +1,281 @@
+package core
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	temporalclient "go.temporal.io/sdk/client"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/objectstore"
+	"github.com/edvin/hosting/internal/platform"
+)
+
+// backupStageURLTTL is how long the presigned URL used to upload a backup
+// file into the export bucket stays valid.
+const backupStageURLTTL = time.Hour
+
+// backupFetchURLTTL is how long the presigned URL a download is redirected
+// to stays valid. It only has to outlive the redirect.
+const backupFetchURLTTL = 5 * time.Minute
+
+// ErrInvalidDownloadToken is returned when a backup download token is
+// malformed, tampered with, expired or issued for another backup.
+var ErrInvalidDownloadToken = errors.New("invalid or expired download token")
+
+// ErrDownloadTokenUsed is returned when a single-use download URL has
+// already been used.
+var ErrDownloadTokenUsed = errors.New("download URL has already been used")
+
+// ErrBackupNotDownloadable is returned for backups that have no completed
+// backup file to download.
+var ErrBackupNotDownloadable = errors.New("backup is not active")
+
+// BackupDownloadService issues and redeems signed, expiring backup download
+// URLs. Backup files live on the tenant's shard nodes; a download stages the
+// file in the export bucket and redirects the client there.
+type BackupDownloadService struct {
+	db         DB
+	tc         temporalclient.Client
+	bucket     *objectstore.Bucket
+	key        []byte
+	baseURL    string
+	defaultTTL time.Duration
+}
+
+// NewBackupDownloadService creates a BackupDownloadService. URLs are signed
+// with a key derived from secret and point at baseURL, the public URL of the
+// core API. Downloads are disabled when bucket is nil or secret is empty.
+func NewBackupDownloadService(db DB, tc temporalclient.Client, bucket *objectstore.Bucket, secret, baseURL string, defaultTTL time.Duration) *BackupDownloadService {
+	var key []byte
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte("backup-download"))
+		key = mac.Sum(nil)
+	}
+	return &BackupDownloadService{
+		db:         db,
+		tc:         tc,
+		bucket:     bucket,
+		key:        key,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		defaultTTL: defaultTTL,
+	}
+}
+
+// Enabled reports whether backup downloads are configured.
+func (s *BackupDownloadService) Enabled() bool {
+	return s.bucket != nil && len(s.key) > 0
+}
+
+// backupDownloadClaims is the signed payload of a download token. Nonce is
+// only set for single-use URLs.
+type backupDownloadClaims struct {
+	BackupID string `json:"bid"`
+	TenantID string `json:"tid"`
+	APIKeyID string `json:"kid,omitempty"`
+	Nonce    string `json:"n,omitempty"`
+	Exp      int64  `json:"exp"`
+}
+
+// BackupDownloadGrant is a verified download token.
+type BackupDownloadGrant struct {
+	Backup   *model.Backup
+	APIKeyID string // the API key that requested the URL
+	nonce    string
+}
+
+// CreateURL returns a signed URL for downloading the backup, valid for ttl
+// (or the configured default when zero). apiKeyID is recorded in the token so
+// downloads can be attributed to the key that requested the URL. A
+// single-use URL stops working after its first download.
+func (s *BackupDownloadService) CreateURL(ctx context.Context, backup *model.Backup, apiKeyID string, ttl time.Duration, singleUse bool) (*model.BackupDownloadURL, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("backup downloads are not configured")
+	}
+	if backup.Status != model.StatusActive || backup.StoragePath == "" {
+		return nil, ErrBackupNotDownloadable
+	}
+	if ttl <= 0 {
+		ttl = s.defaultTTL
+	}
+
+	expiresAt := time.Now().Add(ttl)
+	claims := backupDownloadClaims{
+		BackupID: backup.ID,
+		TenantID: backup.TenantID,
+		APIKeyID: apiKeyID,
+		Exp:      expiresAt.Unix(),
+	}
+	if singleUse {
+		claims.Nonce = platform.NewID()
+		var keyID *string
+		if apiKeyID != "" {
+			keyID = &apiKeyID
+		}
+		_, err := s.db.Exec(ctx,
+			`INSERT INTO backup_download_tokens (nonce, backup_id, api_key_id, expires_at) VALUES ($1, $2, $3, $4)`,
+			claims.Nonce, backup.ID, keyID, expiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("insert backup download token: %w", err)
+		}
+	}
+
+	token, err := s.sign(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &model.BackupDownloadURL{
+		URL:       fmt.Sprintf("%s/api/v1/backups/%s/download?token=%s", s.baseURL, url.PathEscape(backup.ID), url.QueryEscape(token)),
+		ExpiresAt: expiresAt,
+		SingleUse: singleUse,
+	}, nil
+}
+
+// Verify checks a download token for the given backup and loads the backup.
+// The token must be unexpired, carry a valid signature, and name the backup
+// and the tenant that still owns it. Single-use tokens are not consumed until
+// Claim is called.
+func (s *BackupDownloadService) Verify(ctx context.Context, backupID, token string) (*BackupDownloadGrant, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("backup downloads are not configured")
+	}
+	claims, err := s.verify(token, time.Now())
+	if err != nil || claims.BackupID != backupID {
+		return nil, ErrInvalidDownloadToken
+	}
+
+	backup, err := NewBackupService(s.db, s.tc).GetByID(ctx, backupID)
+	if err != nil {
+		return nil, err
+	}
+	if backup.TenantID != claims.TenantID {
+		return nil, ErrInvalidDownloadToken
+	}
+	if backup.Status != model.StatusActive || backup.StoragePath == "" {
+		return nil, ErrBackupNotDownloadable
+	}
+	return &BackupDownloadGrant{Backup: backup, APIKeyID: claims.APIKeyID, nonce: claims.Nonce}, nil
+}
+
+// Claim consumes a single-use grant. It returns ErrDownloadTokenUsed if the
+// URL was already used, and does nothing for reusable URLs.
+func (s *BackupDownloadService) Claim(ctx context.Context, grant *BackupDownloadGrant) error {
+	if grant.nonce == "" {
+		return nil
+	}
+	tag, err := s.db.Exec(ctx,
+		`UPDATE backup_download_tokens SET used_at = now()
+		 WHERE nonce = $1 AND backup_id = $2 AND used_at IS NULL AND expires_at > now()`,
+		grant.nonce, grant.Backup.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("claim backup download token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDownloadTokenUsed
+	}
+	return nil
+}
+
+// stageBackupDownloadArgs mirrors workflow.StageBackupDownloadArgs.
+type stageBackupDownloadArgs struct {
+	BackupID string
+	URL      string
+}
+
+// Stage copies the backup file from its node into the export bucket, waiting
+// for StageBackupDownloadWorkflow, and returns a presigned URL the client
+// downloads it from, valid for backupFetchURLTTL. The staged copy is removed
+// by the bucket's lifecycle rule on the backup-downloads/ prefix.
+func (s *BackupDownloadService) Stage(ctx context.Context, backup *model.Backup) (string, error) {
+	key := fmt.Sprintf("backup-downloads/%s/%s/%s", backup.ID, platform.NewID(), path.Base(backup.StoragePath))
+	putURL, err := s.bucket.PresignPut(ctx, key, backupStageURLTTL)
+	if err != nil {
+		return "", fmt.Errorf("presign backup staging upload: %w", err)
+	}
+
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("stage-backup-download", backup.ID+"-"+platform.NewID()),
+		TaskQueue: taskQueue,
+	}, "StageBackupDownloadWorkflow", stageBackupDownloadArgs{BackupID: backup.ID, URL: putURL})
+	if err != nil {
+		return "", fmt.Errorf("start StageBackupDownloadWorkflow: %w", err)
+	}
+	if err := run.Get(ctx, nil); err != nil {
+		s.removeStaged(key)
+		return "", fmt.Errorf("stage backup %s: %w", backup.ID, err)
+	}
+
+	getURL, err := s.bucket.PresignGet(ctx, key, backupFetchURLTTL)
+	if err != nil {
+		s.removeStaged(key)
+		return "", fmt.Errorf("presign staged backup download: %w", err)
+	}
+	return getURL, nil
+}
+
+// removeStaged deletes a staged backup copy that will not be downloaded. It
+// runs after the request that staged it may have been cancelled, so it does
+// not use the request context. Failures are left to the bucket's lifecycle
+// policy.
+func (s *BackupDownloadService) removeStaged(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	_ = s.bucket.Delete(ctx, key)
+}
+
+// sign HMAC-signs the claims and returns a base64url-encoded
+// "payload.signature" token.
+func (s *BackupDownloadService) sign(claims backupDownloadClaims) (string, error) {
+	claimsJSON, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("marshal download claims: %w", err)
+	}
+	payload := base64.RawURLEncoding.EncodeToString(claimsJSON)
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	sig := base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+	return payload + "." + sig, nil
+}
+
+// verify checks the token's HMAC signature and expiry and decodes its claims.
+func (s *BackupDownloadService) verify(token string, now time.Time) (*backupDownloadClaims, error) {
+	payload, sigB64, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, fmt.Errorf("invalid token format")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(sigB64)
+	if err != nil {
+		return nil, fmt.Errorf("decode signature: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(payload))
+	if !hmac.Equal(mac.Sum(nil), sig) {
+		return nil, fmt.Errorf("invalid signature")
+	}
+
+	claimsJSON, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	var claims backupDownloadClaims
+	if err := json.Unmarshal(claimsJSON, &claims); err != nil {
+		return nil, fmt.Errorf("unmarshal claims: %w", err)
+	}
+	if now.Unix() > claims.Exp {
+		return nil, fmt.Errorf("token expired")
+	}
+	return &claims, nil
+}
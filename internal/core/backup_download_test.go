@@ -0,0 +1,186 @@
+package core
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func newTestBackupDownloadService(db *mockDB) *BackupDownloadService {
+	return NewBackupDownloadService(db, &temporalmocks.Client{}, testExportBucket(), "test-secret", "https://api.example.com/", 15*time.Minute)
+}
+
+func testDownloadBackup() *model.Backup {
+	return &model.Backup{
+		ID:          "test-backup-1",
+		TenantID:    "test-tenant-1",
+		Status:      model.StatusActive,
+		StoragePath: "/var/backups/hosting/test-tenant-1/test-backup-1.tar.gz",
+	}
+}
+
+// backupRow returns a row for BackupService.GetByID.
+func backupRow(b *model.Backup) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = b.ID
+		*(dest[1].(*string)) = b.TenantID
+		*(dest[5].(*string)) = b.StoragePath
+		*(dest[7].(*string)) = b.Status
+		return nil
+	}}
+}
+
+func downloadToken(t *testing.T, rawURL string) string {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	require.NoError(t, err)
+	return u.Query().Get("token")
+}
+
+func TestBackupDownloadService_NotConfigured(t *testing.T) {
+	svc := NewBackupDownloadService(&mockDB{}, &temporalmocks.Client{}, nil, "test-secret", "https://api.example.com", time.Minute)
+	assert.False(t, svc.Enabled())
+	_, err := svc.CreateURL(context.Background(), testDownloadBackup(), "key-1", 0, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not configured")
+
+	svc = NewBackupDownloadService(&mockDB{}, &temporalmocks.Client{}, testExportBucket(), "", "https://api.example.com", time.Minute)
+	assert.False(t, svc.Enabled())
+}
+
+func TestBackupDownloadService_CreateURL(t *testing.T) {
+	db := &mockDB{}
+	svc := newTestBackupDownloadService(db)
+
+	download, err := svc.CreateURL(context.Background(), testDownloadBackup(), "key-1", 0, false)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(download.URL, "https://api.example.com/api/v1/backups/test-backup-1/download?token="))
+	assert.False(t, download.SingleUse)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), download.ExpiresAt, 5*time.Second)
+
+	claims, err := svc.verify(downloadToken(t, download.URL), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, "test-backup-1", claims.BackupID)
+	assert.Equal(t, "test-tenant-1", claims.TenantID)
+	assert.Equal(t, "key-1", claims.APIKeyID)
+	assert.Empty(t, claims.Nonce)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBackupDownloadService_CreateURL_SingleUse(t *testing.T) {
+	db := &mockDB{}
+	svc := newTestBackupDownloadService(db)
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.MatchedBy(func(sql string) bool {
+		return strings.Contains(sql, "INSERT INTO backup_download_tokens")
+	}), mock.Anything).Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+
+	download, err := svc.CreateURL(ctx, testDownloadBackup(), "key-1", time.Hour, true)
+	require.NoError(t, err)
+	assert.True(t, download.SingleUse)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), download.ExpiresAt, 5*time.Second)
+
+	claims, err := svc.verify(downloadToken(t, download.URL), time.Now())
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.Nonce)
+	db.AssertExpectations(t)
+}
+
+func TestBackupDownloadService_CreateURL_NotActive(t *testing.T) {
+	svc := newTestBackupDownloadService(&mockDB{})
+	backup := testDownloadBackup()
+	backup.Status = model.StatusProvisioning
+
+	_, err := svc.CreateURL(context.Background(), backup, "key-1", 0, false)
+	assert.ErrorIs(t, err, ErrBackupNotDownloadable)
+}
+
+func TestBackupDownloadService_Verify(t *testing.T) {
+	db := &mockDB{}
+	svc := newTestBackupDownloadService(db)
+	ctx := context.Background()
+	backup := testDownloadBackup()
+
+	download, err := svc.CreateURL(ctx, backup, "key-1", 0, false)
+	require.NoError(t, err)
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(backupRow(backup))
+
+	grant, err := svc.Verify(ctx, backup.ID, downloadToken(t, download.URL))
+	require.NoError(t, err)
+	assert.Equal(t, backup.ID, grant.Backup.ID)
+	assert.Equal(t, "key-1", grant.APIKeyID)
+}
+
+func TestBackupDownloadService_Verify_Rejected(t *testing.T) {
+	svc := newTestBackupDownloadService(&mockDB{})
+	ctx := context.Background()
+
+	token, err := svc.sign(backupDownloadClaims{BackupID: "test-backup-1", TenantID: "test-tenant-1", Exp: time.Now().Add(time.Minute).Unix()})
+	require.NoError(t, err)
+	expired, err := svc.sign(backupDownloadClaims{BackupID: "test-backup-1", TenantID: "test-tenant-1", Exp: time.Now().Add(-time.Minute).Unix()})
+	require.NoError(t, err)
+	other := NewBackupDownloadService(&mockDB{}, &temporalmocks.Client{}, testExportBucket(), "other-secret", "", time.Minute)
+	foreign, err := other.sign(backupDownloadClaims{BackupID: "test-backup-1", TenantID: "test-tenant-1", Exp: time.Now().Add(time.Minute).Unix()})
+	require.NoError(t, err)
+
+	payload, _, _ := strings.Cut(token, ".")
+	tests := map[string]struct {
+		backupID string
+		token    string
+	}{
+		"malformed":       {"test-backup-1", "not-a-token"},
+		"tampered":        {"test-backup-1", payload + ".AAAA"},
+		"expired":         {"test-backup-1", expired},
+		"wrong key":       {"test-backup-1", foreign},
+		"other backup id": {"test-backup-2", token},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.Verify(ctx, tt.backupID, tt.token)
+			assert.ErrorIs(t, err, ErrInvalidDownloadToken)
+		})
+	}
+}
+
+func TestBackupDownloadService_Verify_TenantMismatch(t *testing.T) {
+	db := &mockDB{}
+	svc := newTestBackupDownloadService(db)
+	ctx := context.Background()
+
+	token, err := svc.sign(backupDownloadClaims{BackupID: "test-backup-1", TenantID: "previous-tenant", Exp: time.Now().Add(time.Minute).Unix()})
+	require.NoError(t, err)
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(backupRow(testDownloadBackup()))
+
+	_, err = svc.Verify(ctx, "test-backup-1", token)
+	assert.ErrorIs(t, err, ErrInvalidDownloadToken)
+}
+
+func TestBackupDownloadService_Claim(t *testing.T) {
+	db := &mockDB{}
+	svc := newTestBackupDownloadService(db)
+	ctx := context.Background()
+	grant := &BackupDownloadGrant{Backup: testDownloadBackup(), nonce: "nonce-1"}
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{"nonce-1", "test-backup-1"}).
+		Return(pgconn.NewCommandTag("UPDATE 1"), nil).Once()
+	require.NoError(t, svc.Claim(ctx, grant))
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{"nonce-1", "test-backup-1"}).
+		Return(pgconn.NewCommandTag("UPDATE 0"), nil).Once()
+	assert.ErrorIs(t, svc.Claim(ctx, grant), ErrDownloadTokenUsed)
+
+	// Reusable URLs have nothing to claim.
+	require.NoError(t, svc.Claim(ctx, &BackupDownloadGrant{Backup: testDownloadBackup()}))
+	db.AssertNumberOfCalls(t, "Exec", 2)
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	temporalclient "go.temporal.io/sdk/client"
+)
+
+// ErrBackupHasNoFiles is returned when browsing or restoring files of a
+// backup that is not a completed web backup.
+var ErrBackupHasNoFiles = errors.New("only active web backups have files to list or restore")
+
+// ListFiles lists the entries of a web backup archive whose path starts with
+// prefix, read on the node that holds the archive.
+func (s *BackupService) ListFiles(ctx context.Context, backup *model.Backup, prefix string) (*model.BackupFileList, error) {
+	if err := checkBackupHasFiles(backup); err != nil {
+		return nil, err
+	}
+
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("backup-files", backup.ID+"-"+platform.NewID()),
+		TaskQueue: taskQueue,
+	}, "ListBackupFilesWorkflow", backup.ID, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("start ListBackupFilesWorkflow: %w", err)
+	}
+	var list model.BackupFileList
+	if err := run.Get(ctx, &list); err != nil {
+		return nil, fmt.Errorf("list files of backup %s: %w", backup.ID, err)
+	}
+	return &list, nil
+}
+
+// RestoreFiles restores individual paths of a web backup into its webroot
+// and waits for the result. Files that are newer on disk than in the backup
+// are skipped unless force is set.
+func (s *BackupService) RestoreFiles(ctx context.Context, backup *model.Backup, paths []string, force bool) (*model.BackupFilesRestore, error) {
+	if err := checkBackupHasFiles(backup); err != nil {
+		return nil, err
+	}
+
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("backup-restore-files", backup.ID+"-"+platform.NewID()),
+		TaskQueue: taskQueue,
+	}, "RestoreBackupFilesWorkflow", backup.ID, paths, force)
+	if err != nil {
+		return nil, fmt.Errorf("start RestoreBackupFilesWorkflow: %w", err)
+	}
+	var res model.BackupFilesRestore
+	if err := run.Get(ctx, &res); err != nil {
+		return nil, fmt.Errorf("restore files from backup %s: %w", backup.ID, err)
+	}
+	return &res, nil
+}
+
+func checkBackupHasFiles(backup *model.Backup) error {
+	if backup.Type != model.BackupTypeWeb || backup.Status != model.StatusActive || backup.StoragePath == "" {
+		return ErrBackupHasNoFiles
+	}
+	return nil
+}
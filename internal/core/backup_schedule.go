@@ -0,0 +1,49 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNoBackupSchedule is returned when a tenant has no backup schedule.
+var ErrNoBackupSchedule = errors.New("tenant has no backup schedule")
+
+// GetBackupSchedule returns the tenant's backup schedule, or
+// ErrNoBackupSchedule if the tenant is only backed up on request.
+func (s *TenantService) GetBackupSchedule(ctx context.Context, tenantID string) (*model.BackupSchedule, error) {
+	var bs model.BackupSchedule
+	err := s.db.QueryRow(ctx,
+		`SELECT tenant_id, frequency, retention_days, enabled, last_run_at, created_at, updated_at
+		 FROM backup_schedules WHERE tenant_id = $1`, tenantID,
+	).Scan(&bs.TenantID, &bs.Frequency, &bs.RetentionDays, &bs.Enabled, &bs.LastRunAt, &bs.CreatedAt, &bs.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoBackupSchedule
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get backup schedule for tenant %s: %w", tenantID, err)
+	}
+	return &bs, nil
+}
+
+// SetBackupSchedule creates or replaces the tenant's backup schedule. It
+// takes effect from the next hourly run of RunScheduledBackupsWorkflow; the
+// time of the last run is kept so changing the schedule doesn't trigger an
+// extra backup.
+func (s *TenantService) SetBackupSchedule(ctx context.Context, bs *model.BackupSchedule) error {
+	err := s.db.QueryRow(ctx,
+		`INSERT INTO backup_schedules (tenant_id, frequency, retention_days, enabled)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (tenant_id) DO UPDATE SET frequency = EXCLUDED.frequency,
+		   retention_days = EXCLUDED.retention_days, enabled = EXCLUDED.enabled, updated_at = now()
+		 RETURNING last_run_at, created_at, updated_at`,
+		bs.TenantID, bs.Frequency, bs.RetentionDays, bs.Enabled,
+	).Scan(&bs.LastRunAt, &bs.CreatedAt, &bs.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("set backup schedule for tenant %s: %w", bs.TenantID, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func TestTenantService_GetBackupSchedule_None(t *testing.T) {
+	db := &mockDB{}
+	svc := NewTenantService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }})
+
+	_, err := svc.GetBackupSchedule(ctx, "test-tenant-1")
+	assert.ErrorIs(t, err, ErrNoBackupSchedule)
+}
+
+func TestTenantService_SetBackupSchedule_KeepsLastRun(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+	now := time.Now()
+	lastRun := now.Add(-6 * time.Hour)
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1", model.BackupFrequencyWeekly, 90, true}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(**time.Time)) = &lastRun
+			*(dest[1].(*time.Time)) = now
+			*(dest[2].(*time.Time)) = now
+			return nil
+		}})
+
+	bs := &model.BackupSchedule{TenantID: "test-tenant-1", Frequency: model.BackupFrequencyWeekly, RetentionDays: 90, Enabled: true}
+	require.NoError(t, svc.SetBackupSchedule(ctx, bs))
+	require.NotNil(t, bs.LastRunAt)
+	assert.Equal(t, lastRun, *bs.LastRunAt)
+	db.AssertExpectations(t)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -3,10 +3,12 @@ package core
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -117,8 +119,11 @@ func TestBackupService_GetByID_Success(t *testing.T) {
 		*(dest[8].(**string)) = nil // status_message
 		*(dest[9].(**time.Time)) = &now
 		*(dest[10].(**time.Time)) = &now
-		*(dest[11].(*time.Time)) = now
-		*(dest[12].(*time.Time)) = now
+		verifyStatus := model.BackupVerifyPassed
+		*(dest[11].(**string)) = &verifyStatus
+		*(dest[13].(**time.Time)) = &now
+		*(dest[14].(*time.Time)) = now
+		*(dest[15].(*time.Time)) = now
 		return nil
 	}}
 	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
@@ -133,6 +138,9 @@ func TestBackupService_GetByID_Success(t *testing.T) {
 	assert.Equal(t, "mysite", result.SourceName)
 	assert.Equal(t, int64(1024), result.SizeBytes)
 	assert.Equal(t, model.StatusActive, result.Status)
+	require.NotNil(t, result.VerifyStatus)
+	assert.Equal(t, model.BackupVerifyPassed, *result.VerifyStatus)
+	assert.Equal(t, &now, result.VerifiedAt)
 	db.AssertExpectations(t)
 }
 
@@ -177,8 +185,8 @@ func TestBackupService_ListByTenant_Success(t *testing.T) {
 			*(dest[8].(**string)) = nil // status_message
 			*(dest[9].(**time.Time)) = &now
 			*(dest[10].(**time.Time)) = &now
-			*(dest[11].(*time.Time)) = now
-			*(dest[12].(*time.Time)) = now
+			*(dest[14].(*time.Time)) = now
+			*(dest[15].(*time.Time)) = now
 			return nil
 		},
 	)
@@ -329,8 +337,8 @@ func TestBackupService_Restore_Success(t *testing.T) {
 		*(dest[8].(**string)) = nil // status_message
 		*(dest[9].(**time.Time)) = &now
 		*(dest[10].(**time.Time)) = &now
-		*(dest[11].(*time.Time)) = now
-		*(dest[12].(*time.Time)) = now
+		*(dest[14].(*time.Time)) = now
+		*(dest[15].(*time.Time)) = now
 		return nil
 	}}
 	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row).Once()
@@ -341,7 +349,7 @@ func TestBackupService_Restore_Success(t *testing.T) {
 	wfRun.On("GetRunID").Return("mock-run-id")
 	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil)
 
-	err := svc.Restore(ctx, "test-backup-1")
+	err := svc.Restore(ctx, "test-backup-1", nil)
 	require.NoError(t, err)
 	db.AssertExpectations(t)
 	tc.AssertExpectations(t)
@@ -367,13 +375,13 @@ func TestBackupService_Restore_NotActive(t *testing.T) {
 		*(dest[8].(**string)) = nil // status_message
 		*(dest[9].(**time.Time)) = nil
 		*(dest[10].(**time.Time)) = nil
-		*(dest[11].(*time.Time)) = now
-		*(dest[12].(*time.Time)) = now
+		*(dest[14].(*time.Time)) = now
+		*(dest[15].(*time.Time)) = now
 		return nil
 	}}
 	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
 
-	err := svc.Restore(ctx, "test-backup-1")
+	err := svc.Restore(ctx, "test-backup-1", nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "not active")
 	db.AssertExpectations(t)
@@ -390,7 +398,7 @@ func TestBackupService_Restore_NotFound(t *testing.T) {
 	}}
 	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
 
-	err := svc.Restore(ctx, "nonexistent")
+	err := svc.Restore(ctx, "nonexistent", nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "get backup for restore")
 	db.AssertExpectations(t)
@@ -416,8 +424,8 @@ func TestBackupService_Restore_WorkflowError(t *testing.T) {
 		*(dest[8].(**string)) = nil // status_message
 		*(dest[9].(**time.Time)) = &now
 		*(dest[10].(**time.Time)) = &now
-		*(dest[11].(*time.Time)) = now
-		*(dest[12].(*time.Time)) = now
+		*(dest[14].(*time.Time)) = now
+		*(dest[15].(*time.Time)) = now
 		return nil
 	}}
 	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row).Once()
@@ -425,9 +433,168 @@ func TestBackupService_Restore_WorkflowError(t *testing.T) {
 
 	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("temporal down"))
 
-	err := svc.Restore(ctx, "test-backup-1")
+	err := svc.Restore(ctx, "test-backup-1", nil)
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "signal RestoreBackupWorkflow")
 	db.AssertExpectations(t)
 	tc.AssertExpectations(t)
 }
+
+func activeDatabaseBackupRow(now time.Time) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-backup-1"
+		*(dest[1].(*string)) = "test-tenant-1"
+		*(dest[2].(*string)) = model.BackupTypeDatabase
+		*(dest[3].(*string)) = "test-database-1"
+		*(dest[4].(*string)) = "shop"
+		*(dest[5].(*string)) = "/var/backups/hosting/tenant1/test-backup-1.sql.gz"
+		*(dest[6].(*int64)) = 1024
+		*(dest[7].(*string)) = model.StatusActive
+		*(dest[9].(**time.Time)) = &now
+		*(dest[10].(**time.Time)) = &now
+		*(dest[14].(*time.Time)) = now
+		*(dest[15].(*time.Time)) = now
+		return nil
+	}}
+}
+
+func TestBackupService_Restore_PointInTime(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewBackupService(db, tc)
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Microsecond)
+	restoreTo := now.Add(-time.Hour)
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(activeDatabaseBackupRow(now)).Once()
+	db.On("QueryRow", ctx, mock.MatchedBy(func(sql string) bool {
+		return strings.Contains(sql, "completed_at <= $4")
+	}), []any{"test-database-1", model.BackupTypeDatabase, model.StatusActive, restoreTo}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-backup-0"
+		return nil
+	}}).Once()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("SignalWithStartWorkflow", mock.Anything, "tenant-test-tenant-1", model.ProvisionSignalName,
+		mock.MatchedBy(func(task model.ProvisionTask) bool {
+			params, ok := task.Arg.(RestoreBackupParams)
+			return task.WorkflowName == "RestoreBackupWorkflow" && ok &&
+				params.BackupID == "test-backup-0" && params.RestoreTo.Equal(restoreTo)
+		}), mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	err := svc.Restore(ctx, "test-backup-1", &restoreTo)
+	require.NoError(t, err)
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestBackupService_Restore_PointInTimeNoBaseBackup(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewBackupService(db, tc)
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Microsecond)
+	restoreTo := now.Add(-30 * 24 * time.Hour)
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(activeDatabaseBackupRow(now)).Once()
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		return pgx.ErrNoRows
+	}}).Once()
+
+	err := svc.Restore(ctx, "test-backup-1", &restoreTo)
+	assert.ErrorIs(t, err, ErrInvalidRestoreTo)
+	assert.Contains(t, err.Error(), "no active backup of database shop")
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBackupService_Restore_PointInTimeRejected(t *testing.T) {
+	now := time.Now().Truncate(time.Microsecond)
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		backupRow *mockRow
+		restoreTo time.Time
+		want      string
+	}{
+		{"future", activeDatabaseBackupRow(now), future, "is in the future"},
+		{"web backup", &mockRow{scanFunc: func(dest ...any) error {
+			*(dest[2].(*string)) = model.BackupTypeWeb
+			*(dest[7].(*string)) = model.StatusActive
+			return nil
+		}}, past, "only supported for database backups"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			tc := &temporalmocks.Client{}
+			svc := NewBackupService(db, tc)
+			ctx := context.Background()
+
+			db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(tt.backupRow).Once()
+
+			err := svc.Restore(ctx, "test-backup-1", &tt.restoreTo)
+			assert.ErrorIs(t, err, ErrInvalidRestoreTo)
+			assert.Contains(t, err.Error(), tt.want)
+			db.AssertExpectations(t)
+		})
+	}
+}
+
+// ---------- Files ----------
+
+func activeWebBackup() *model.Backup {
+	return &model.Backup{
+		ID:          "test-backup-1",
+		TenantID:    "test-tenant-1",
+		Type:        model.BackupTypeWeb,
+		Status:      model.StatusActive,
+		StoragePath: "/var/backups/hosting/test-tenant-1/test-backup-1.tar.gz",
+	}
+}
+
+func TestBackupService_ListFiles_RejectsDatabaseBackup(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewBackupService(&mockDB{}, tc)
+
+	backup := activeWebBackup()
+	backup.Type = model.BackupTypeDatabase
+	_, err := svc.ListFiles(context.Background(), backup, "")
+	assert.ErrorIs(t, err, ErrBackupHasNoFiles)
+	tc.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBackupService_RestoreFiles_RejectsPendingBackup(t *testing.T) {
+	svc := NewBackupService(&mockDB{}, &temporalmocks.Client{})
+
+	backup := activeWebBackup()
+	backup.Status = model.StatusProvisioning
+	_, err := svc.RestoreFiles(context.Background(), backup, []string{"wp-config.php"}, false)
+	assert.ErrorIs(t, err, ErrBackupHasNoFiles)
+}
+
+func TestBackupService_RestoreFiles_Success(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewBackupService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*model.BackupFilesRestore)) = model.BackupFilesRestore{
+			BackupID: "test-backup-1",
+			Restored: []string{"wp-config.php"},
+			Skipped:  []string{"public/index.php"},
+		}
+	}).Return(nil)
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "RestoreBackupFilesWorkflow", "test-backup-1",
+		[]string{"wp-config.php", "public/index.php"}, false).Return(wfRun, nil)
+
+	res, err := svc.RestoreFiles(ctx, activeWebBackup(), []string{"wp-config.php", "public/index.php"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"wp-config.php"}, res.Restored)
+	assert.Equal(t, []string{"public/index.php"}, res.Skipped)
+	tc.AssertExpectations(t)
+}
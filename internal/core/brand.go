@@ -2,27 +2,39 @@ package core
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	"github.com/edvin/hosting/internal/secrets"
+	"github.com/jackc/pgx/v5"
 )
 
 type BrandService struct {
-	db DB
+	db  DB
+	kek []byte // master key (KEK), encrypts ACME EAB keys
 }
 
-func NewBrandService(db DB) *BrandService {
-	return &BrandService{db: db}
+func NewBrandService(db DB, kekHex string) *BrandService {
+	var kek []byte
+	if kekHex != "" {
+		kek, _ = hex.DecodeString(kekHex)
+	}
+	return &BrandService{db: db, kek: kek}
 }
 
 func (s *BrandService) Create(ctx context.Context, brand *model.Brand) error {
 	_, err := s.db.Exec(ctx,
-		`INSERT INTO brands (id, name, base_hostname, primary_ns, secondary_ns, hostmaster_email, mail_hostname, spf_includes, dkim_selector, dkim_public_key, dmarc_policy, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		`INSERT INTO brands (id, name, base_hostname, primary_ns, secondary_ns, hostmaster_email, mail_hostname, spf_includes, dkim_selector, dkim_public_key, dmarc_policy, dns_ttls, dns_migration_ttl, status, created_at, updated_at, password_min_length, password_classes, webroot_max_conns_per_ip, webroot_max_requests_per_sec)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, COALESCE($12, '{}'::jsonb), $13, $14, $15, $16, $17, COALESCE($18, '{}'::text[]), $19, $20)`,
 		brand.ID, brand.Name, brand.BaseHostname, brand.PrimaryNS, brand.SecondaryNS,
 		brand.HostmasterEmail, brand.MailHostname, brand.SPFIncludes, brand.DKIMSelector,
-		brand.DKIMPublicKey, brand.DMARCPolicy, brand.Status, brand.CreatedAt, brand.UpdatedAt,
+		brand.DKIMPublicKey, brand.DMARCPolicy, brand.DNSTTLs, brand.DNSMigrationTTL, brand.Status, brand.CreatedAt, brand.UpdatedAt,
+		brand.PasswordMinLength, brand.PasswordClasses, brand.WebrootMaxConnsPerIP, brand.WebrootMaxRequestsPerSec,
 	)
 	if err != nil {
 		return fmt.Errorf("insert brand: %w", err)
@@ -33,11 +45,12 @@ func (s *BrandService) Create(ctx context.Context, brand *model.Brand) error {
 func (s *BrandService) GetByID(ctx context.Context, id string) (*model.Brand, error) {
 	var b model.Brand
 	err := s.db.QueryRow(ctx,
-		`SELECT id, name, base_hostname, primary_ns, secondary_ns, hostmaster_email, mail_hostname, spf_includes, dkim_selector, dkim_public_key, dmarc_policy, status, created_at, updated_at
+		`SELECT id, name, base_hostname, primary_ns, secondary_ns, hostmaster_email, mail_hostname, spf_includes, dkim_selector, dkim_public_key, dmarc_policy, dns_ttls, dns_migration_ttl, status, created_at, updated_at, password_min_length, password_classes, webroot_max_conns_per_ip, webroot_max_requests_per_sec
 		 FROM brands WHERE id = $1`, id,
 	).Scan(&b.ID, &b.Name, &b.BaseHostname, &b.PrimaryNS, &b.SecondaryNS,
 		&b.HostmasterEmail, &b.MailHostname, &b.SPFIncludes, &b.DKIMSelector,
-		&b.DKIMPublicKey, &b.DMARCPolicy, &b.Status, &b.CreatedAt, &b.UpdatedAt)
+		&b.DKIMPublicKey, &b.DMARCPolicy, &b.DNSTTLs, &b.DNSMigrationTTL, &b.Status, &b.CreatedAt, &b.UpdatedAt,
+		&b.PasswordMinLength, &b.PasswordClasses, &b.WebrootMaxConnsPerIP, &b.WebrootMaxRequestsPerSec)
 	if err != nil {
 		return nil, fmt.Errorf("get brand %s: %w", id, err)
 	}
@@ -45,7 +58,7 @@ func (s *BrandService) GetByID(ctx context.Context, id string) (*model.Brand, er
 }
 
 func (s *BrandService) List(ctx context.Context, params request.ListParams) ([]model.Brand, bool, error) {
-	query := `SELECT id, name, base_hostname, primary_ns, secondary_ns, hostmaster_email, mail_hostname, spf_includes, dkim_selector, dkim_public_key, dmarc_policy, status, created_at, updated_at FROM brands WHERE true`
+	query := `SELECT id, name, base_hostname, primary_ns, secondary_ns, hostmaster_email, mail_hostname, spf_includes, dkim_selector, dkim_public_key, dmarc_policy, dns_ttls, dns_migration_ttl, status, created_at, updated_at, password_min_length, password_classes, webroot_max_conns_per_ip, webroot_max_requests_per_sec FROM brands WHERE true`
 	args := []any{}
 	argIdx := 1
 
@@ -93,7 +106,8 @@ func (s *BrandService) List(ctx context.Context, params request.ListParams) ([]m
 		var b model.Brand
 		if err := rows.Scan(&b.ID, &b.Name, &b.BaseHostname, &b.PrimaryNS, &b.SecondaryNS,
 			&b.HostmasterEmail, &b.MailHostname, &b.SPFIncludes, &b.DKIMSelector,
-			&b.DKIMPublicKey, &b.DMARCPolicy, &b.Status, &b.CreatedAt, &b.UpdatedAt); err != nil {
+			&b.DKIMPublicKey, &b.DMARCPolicy, &b.DNSTTLs, &b.DNSMigrationTTL, &b.Status, &b.CreatedAt, &b.UpdatedAt,
+			&b.PasswordMinLength, &b.PasswordClasses, &b.WebrootMaxConnsPerIP, &b.WebrootMaxRequestsPerSec); err != nil {
 			return nil, false, fmt.Errorf("scan brand: %w", err)
 		}
 		brands = append(brands, b)
@@ -113,11 +127,14 @@ func (s *BrandService) Update(ctx context.Context, brand *model.Brand) error {
 	_, err := s.db.Exec(ctx,
 		`UPDATE brands SET name = $1, base_hostname = $2, primary_ns = $3, secondary_ns = $4,
 		 hostmaster_email = $5, mail_hostname = $6, spf_includes = $7, dkim_selector = $8,
-		 dkim_public_key = $9, dmarc_policy = $10, status = $11, updated_at = now()
-		 WHERE id = $12`,
+		 dkim_public_key = $9, dmarc_policy = $10, dns_ttls = COALESCE($11, '{}'::jsonb), dns_migration_ttl = $12,
+		 status = $13, password_min_length = $14, password_classes = COALESCE($15, '{}'::text[]),
+		 webroot_max_conns_per_ip = $16, webroot_max_requests_per_sec = $17, updated_at = now()
+		 WHERE id = $18`,
 		brand.Name, brand.BaseHostname, brand.PrimaryNS, brand.SecondaryNS,
 		brand.HostmasterEmail, brand.MailHostname, brand.SPFIncludes, brand.DKIMSelector,
-		brand.DKIMPublicKey, brand.DMARCPolicy, brand.Status, brand.ID,
+		brand.DKIMPublicKey, brand.DMARCPolicy, brand.DNSTTLs, brand.DNSMigrationTTL, brand.Status,
+		brand.PasswordMinLength, brand.PasswordClasses, brand.WebrootMaxConnsPerIP, brand.WebrootMaxRequestsPerSec, brand.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update brand %s: %w", brand.ID, err)
@@ -135,6 +152,38 @@ func (s *BrandService) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// PasswordPolicy returns the policy user-supplied passwords of the brand's
+// tenants must meet.
+func (s *BrandService) PasswordPolicy(ctx context.Context, brandID string) (secrets.Policy, error) {
+	row := s.db.QueryRow(ctx,
+		`SELECT password_min_length, password_classes FROM brands WHERE id = $1`, brandID,
+	)
+	policy, err := scanPasswordPolicy(row)
+	if err != nil {
+		return secrets.Policy{}, fmt.Errorf("get password policy for brand %s: %w", brandID, err)
+	}
+	return policy, nil
+}
+
+// scanPasswordPolicy scans a brand's password_min_length and
+// password_classes columns into a policy.
+func scanPasswordPolicy(row pgx.Row) (secrets.Policy, error) {
+	var minLength int
+	var names []string
+	if err := row.Scan(&minLength, &names); err != nil {
+		return secrets.Policy{}, err
+	}
+	policy := secrets.Policy{MinLength: minLength}
+	for _, name := range names {
+		c, err := secrets.ParseClass(name)
+		if err != nil {
+			return secrets.Policy{}, err
+		}
+		policy.Classes = append(policy.Classes, c)
+	}
+	return policy, nil
+}
+
 func (s *BrandService) ListClusters(ctx context.Context, brandID string) ([]string, error) {
 	rows, err := s.db.Query(ctx,
 		`SELECT cluster_id FROM brand_clusters WHERE brand_id = $1 ORDER BY cluster_id`, brandID,
@@ -172,3 +221,82 @@ func (s *BrandService) SetClusters(ctx context.Context, brandID string, clusterI
 	}
 	return nil
 }
+
+func (s *BrandService) ListZoneTemplates(ctx context.Context, brandID string) ([]model.BrandZoneTemplate, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT id, brand_id, type, name, content, ttl, priority, created_at, updated_at
+		 FROM brand_zone_templates WHERE brand_id = $1 ORDER BY created_at, id`, brandID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list brand zone templates: %w", err)
+	}
+	defer rows.Close()
+
+	var templates []model.BrandZoneTemplate
+	for rows.Next() {
+		var t model.BrandZoneTemplate
+		if err := rows.Scan(&t.ID, &t.BrandID, &t.Type, &t.Name, &t.Content, &t.TTL, &t.Priority,
+			&t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan brand zone template: %w", err)
+		}
+		templates = append(templates, t)
+	}
+	return templates, rows.Err()
+}
+
+// ErrMultipleSOATemplates is returned by SetZoneTemplates when more than one
+// SOA template is given.
+var ErrMultipleSOATemplates = errors.New("at most one SOA template is allowed")
+
+// defaultZoneTemplateTTL is the TTL of zone templates given without one.
+const defaultZoneTemplateTTL = 3600
+
+// SetZoneTemplates replaces the brand's zone templates in one transaction and
+// returns them as stored, with IDs assigned and missing TTLs defaulted. Zones
+// that already exist are not changed; templates only apply to zones created
+// afterwards.
+func (s *BrandService) SetZoneTemplates(ctx context.Context, brandID string, templates []model.BrandZoneTemplate) ([]model.BrandZoneTemplate, error) {
+	now := time.Now()
+	stored := make([]model.BrandZoneTemplate, 0, len(templates))
+	soaCount := 0
+	for _, t := range templates {
+		if t.Type == "SOA" {
+			soaCount++
+		}
+		if t.TTL == 0 {
+			t.TTL = defaultZoneTemplateTTL
+		}
+		t.ID = platform.NewID()
+		t.BrandID = brandID
+		t.CreatedAt = now
+		t.UpdatedAt = now
+		stored = append(stored, t)
+	}
+	if soaCount > 1 {
+		return nil, ErrMultipleSOATemplates
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin tx: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if _, err := tx.Exec(ctx, `DELETE FROM brand_zone_templates WHERE brand_id = $1`, brandID); err != nil {
+		return nil, fmt.Errorf("clear brand zone templates: %w", err)
+	}
+	for _, t := range stored {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO brand_zone_templates (id, brand_id, type, name, content, ttl, priority, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			t.ID, brandID, t.Type, t.Name, t.Content, t.TTL, t.Priority, t.CreatedAt, t.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("insert brand zone template %s: %w", t.ID, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit brand zone templates: %w", err)
+	}
+	return stored, nil
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/edvin/hosting/internal/crypto"
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/crypto/acme"
+)
+
+// ErrNoBrandACMEConfig is returned when a brand uses the platform ACME CA.
+var ErrNoBrandACMEConfig = errors.New("brand has no ACME configuration")
+
+// ErrInvalidACMEConfig is returned when a brand's ACME directory cannot be
+// used or its External Account Binding credentials are malformed or missing.
+var ErrInvalidACMEConfig = errors.New("invalid ACME configuration")
+
+// discoverACMEDirectory fetches an ACME directory. Tests replace it to avoid
+// network access.
+var discoverACMEDirectory = func(ctx context.Context, directoryURL string) (acme.Directory, error) {
+	return (&acme.Client{DirectoryURL: directoryURL}).Discover(ctx)
+}
+
+// GetACMEConfig returns the brand's ACME configuration, or
+// ErrNoBrandACMEConfig if the brand uses the platform default CA.
+func (s *BrandService) GetACMEConfig(ctx context.Context, brandID string) (*model.BrandACMEConfig, error) {
+	var c model.BrandACMEConfig
+	err := s.db.QueryRow(ctx,
+		`SELECT brand_id, directory_url, email, eab_key_id, created_at, updated_at
+		 FROM brand_acme_configs WHERE brand_id = $1`, brandID,
+	).Scan(&c.BrandID, &c.DirectoryURL, &c.Email, &c.EABKeyID, &c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoBrandACMEConfig
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get ACME config for brand %s: %w", brandID, err)
+	}
+	return &c, nil
+}
+
+// SetACMEConfig creates or replaces the brand's ACME configuration. The
+// directory is fetched to check that it is an ACME server, and EAB
+// credentials are required if the CA says so. eabHMACKey is the base64url
+// key issued by the CA and must be set together with c.EABKeyID. It returns
+// ErrInvalidACMEConfig if the configuration cannot be used. Certificates
+// issued afterwards use the new CA; existing certificates are not reissued.
+func (s *BrandService) SetACMEConfig(ctx context.Context, c *model.BrandACMEConfig, eabHMACKey string) error {
+	u, err := url.Parse(c.DirectoryURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: directory URL %q must be an absolute https URL", ErrInvalidACMEConfig, c.DirectoryURL)
+	}
+	if (c.EABKeyID == "") != (eabHMACKey == "") {
+		return fmt.Errorf("%w: eab_key_id and eab_hmac_key must be set together", ErrInvalidACMEConfig)
+	}
+
+	var encryptedHMAC string
+	if eabHMACKey != "" {
+		key, err := decodeEABHMACKey(eabHMACKey)
+		if err != nil {
+			return fmt.Errorf("%w: eab_hmac_key is not base64url encoded", ErrInvalidACMEConfig)
+		}
+		if s.kek == nil {
+			return fmt.Errorf("cannot store EAB key: SECRET_ENCRYPTION_KEY is not configured")
+		}
+		encryptedHMAC, err = crypto.Encrypt(key, s.kek)
+		if err != nil {
+			return fmt.Errorf("encrypt EAB key: %w", err)
+		}
+	}
+
+	discoverCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	dir, err := discoverACMEDirectory(discoverCtx, c.DirectoryURL)
+	if err != nil {
+		return fmt.Errorf("%w: fetch directory %s: %v", ErrInvalidACMEConfig, c.DirectoryURL, err)
+	}
+	if dir.ExternalAccountRequired && c.EABKeyID == "" {
+		return fmt.Errorf("%w: %s requires External Account Binding credentials", ErrInvalidACMEConfig, c.DirectoryURL)
+	}
+
+	err = s.db.QueryRow(ctx,
+		`INSERT INTO brand_acme_configs (brand_id, directory_url, email, eab_key_id, eab_hmac_key_encrypted)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (brand_id) DO UPDATE SET directory_url = EXCLUDED.directory_url,
+		   email = EXCLUDED.email, eab_key_id = EXCLUDED.eab_key_id,
+		   eab_hmac_key_encrypted = EXCLUDED.eab_hmac_key_encrypted, updated_at = now()
+		 RETURNING created_at, updated_at`,
+		c.BrandID, c.DirectoryURL, c.Email, c.EABKeyID, encryptedHMAC,
+	).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("set ACME config for brand %s: %w", c.BrandID, err)
+	}
+	return nil
+}
+
+// DeleteACMEConfig returns the brand to the platform default CA. It returns
+// ErrNoBrandACMEConfig if the brand has no configuration.
+func (s *BrandService) DeleteACMEConfig(ctx context.Context, brandID string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM brand_acme_configs WHERE brand_id = $1`, brandID)
+	if err != nil {
+		return fmt.Errorf("delete ACME config for brand %s: %w", brandID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoBrandACMEConfig
+	}
+	return nil
+}
+
+// decodeEABHMACKey decodes an EAB HMAC key. CAs hand these out base64url
+// encoded, usually without padding.
+func decodeEABHMACKey(s string) ([]byte, error) {
+	key, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) == 0 {
+		return nil, errors.New("empty key")
+	}
+	return key, nil
+}
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edvin/hosting/internal/crypto"
+	"github.com/edvin/hosting/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/acme"
+)
+
+const testKEKHex = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+func stubACMEDirectory(t *testing.T, dir acme.Directory, err error) {
+	t.Helper()
+	orig := discoverACMEDirectory
+	discoverACMEDirectory = func(context.Context, string) (acme.Directory, error) { return dir, err }
+	t.Cleanup(func() { discoverACMEDirectory = orig })
+}
+
+func TestBrandService_SetACMEConfig_RejectsNonHTTPS(t *testing.T) {
+	svc := NewBrandService(&mockDB{}, testKEKHex)
+
+	err := svc.SetACMEConfig(context.Background(), &model.BrandACMEConfig{
+		BrandID: "acme", DirectoryURL: "http://ca.example.com/directory",
+	}, "")
+	assert.ErrorIs(t, err, ErrInvalidACMEConfig)
+}
+
+func TestBrandService_SetACMEConfig_EABMustBePaired(t *testing.T) {
+	svc := NewBrandService(&mockDB{}, testKEKHex)
+
+	err := svc.SetACMEConfig(context.Background(), &model.BrandACMEConfig{
+		BrandID: "acme", DirectoryURL: "https://acme.zerossl.com/v2/DV90", EABKeyID: "kid-1",
+	}, "")
+	assert.ErrorIs(t, err, ErrInvalidACMEConfig)
+}
+
+func TestBrandService_SetACMEConfig_EABRequiredByCA(t *testing.T) {
+	stubACMEDirectory(t, acme.Directory{ExternalAccountRequired: true}, nil)
+	svc := NewBrandService(&mockDB{}, testKEKHex)
+
+	err := svc.SetACMEConfig(context.Background(), &model.BrandACMEConfig{
+		BrandID: "acme", DirectoryURL: "https://acme.zerossl.com/v2/DV90",
+	}, "")
+	require.ErrorIs(t, err, ErrInvalidACMEConfig)
+	assert.Contains(t, err.Error(), "External Account Binding")
+}
+
+func TestBrandService_SetACMEConfig_DirectoryUnreachable(t *testing.T) {
+	stubACMEDirectory(t, acme.Directory{}, errors.New("connection refused"))
+	svc := NewBrandService(&mockDB{}, testKEKHex)
+
+	err := svc.SetACMEConfig(context.Background(), &model.BrandACMEConfig{
+		BrandID: "acme", DirectoryURL: "https://ca.internal.example/directory",
+	}, "")
+	assert.ErrorIs(t, err, ErrInvalidACMEConfig)
+}
+
+func TestBrandService_SetACMEConfig_StoresEncryptedEABKey(t *testing.T) {
+	stubACMEDirectory(t, acme.Directory{ExternalAccountRequired: true}, nil)
+	db := &mockDB{}
+	svc := NewBrandService(db, testKEKHex)
+	ctx := context.Background()
+	now := time.Now()
+
+	var stored string
+	db.On("QueryRow", ctx, mock.MatchedBy(func(q string) bool { return strings.Contains(q, "brand_acme_configs") }), mock.Anything).
+		Run(func(args mock.Arguments) {
+			stored = args.Get(2).([]any)[4].(string)
+		}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*time.Time)) = now
+			*(dest[1].(*time.Time)) = now
+			return nil
+		}})
+
+	cfg := &model.BrandACMEConfig{BrandID: "acme", DirectoryURL: "https://acme.zerossl.com/v2/DV90", EABKeyID: "kid-1"}
+	err := svc.SetACMEConfig(ctx, cfg, "c2VjcmV0LWhtYWMta2V5")
+	require.NoError(t, err)
+	assert.Equal(t, now, cfg.CreatedAt)
+
+	require.NotEmpty(t, stored)
+	assert.NotContains(t, stored, "c2VjcmV0LWhtYWMta2V5")
+	plain, err := crypto.Decrypt(stored, svc.kek)
+	require.NoError(t, err)
+	assert.Equal(t, "secret-hmac-key", string(plain))
+}
+
+func TestDecodeEABHMACKey(t *testing.T) {
+	key, err := decodeEABHMACKey("c2VjcmV0LWhtYWMta2V5")
+	require.NoError(t, err)
+	assert.Equal(t, "secret-hmac-key", string(key))
+
+	_, err = decodeEABHMACKey("not base64!")
+	assert.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrInvalidRuntimeDefault is returned when a brand default names a runtime
+// without versions or a version not installed in the brand's clusters.
+var ErrInvalidRuntimeDefault = errors.New("invalid runtime default")
+
+// ErrNoRuntimeDefault is returned when a webroot is created without a
+// runtime version and the tenant's brand has no default for the runtime.
+var ErrNoRuntimeDefault = errors.New("no default runtime version")
+
+// ErrRuntimeDefaultUnavailable is returned when the brand default version is
+// not installed on the tenant's shard.
+var ErrRuntimeDefaultUnavailable = errors.New("default runtime version is not installed on the tenant's shard")
+
+// GetRuntimeDefaults returns the brand's default version per runtime.
+func (s *BrandService) GetRuntimeDefaults(ctx context.Context, brandID string) (map[string]string, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT runtime, version FROM brand_runtime_defaults WHERE brand_id = $1 ORDER BY runtime`, brandID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list runtime defaults: %w", err)
+	}
+	defer rows.Close()
+
+	defaults := map[string]string{}
+	for rows.Next() {
+		var runtime, version string
+		if err := rows.Scan(&runtime, &version); err != nil {
+			return nil, fmt.Errorf("scan runtime default: %w", err)
+		}
+		defaults[runtime] = version
+	}
+	return defaults, rows.Err()
+}
+
+// SetRuntimeDefaults replaces the brand's default runtime versions. Every
+// version must have been reported installed by a node in one of the brand's
+// clusters; otherwise ErrInvalidRuntimeDefault is returned. Existing
+// webroots keep their version.
+func (s *BrandService) SetRuntimeDefaults(ctx context.Context, brandID string, defaults map[string]string) error {
+	installed := map[string][]string{}
+	if len(defaults) > 0 {
+		rows, err := s.db.Query(ctx,
+			`SELECT DISTINCT nr.runtime, nr.version
+			 FROM node_runtimes nr
+			 JOIN nodes n ON n.id = nr.node_id
+			 JOIN brand_clusters bc ON bc.cluster_id = n.cluster_id
+			 WHERE bc.brand_id = $1
+			 ORDER BY nr.runtime, nr.version`, brandID,
+		)
+		if err != nil {
+			return fmt.Errorf("list installed runtimes for brand %s: %w", brandID, err)
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var runtime, version string
+			if err := rows.Scan(&runtime, &version); err != nil {
+				return fmt.Errorf("scan installed runtime: %w", err)
+			}
+			installed[runtime] = append(installed[runtime], version)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("list installed runtimes for brand %s: %w", brandID, err)
+		}
+	}
+
+	runtimes := make([]string, 0, len(defaults))
+	for runtime := range defaults {
+		runtimes = append(runtimes, runtime)
+	}
+	sort.Strings(runtimes)
+	for _, runtime := range runtimes {
+		version := defaults[runtime]
+		switch runtime {
+		case model.RuntimePHP, model.RuntimeNode, model.RuntimePython, model.RuntimeRuby:
+		default:
+			return fmt.Errorf("%w: runtime %q has no versions", ErrInvalidRuntimeDefault, runtime)
+		}
+		versions := installed[runtime]
+		if !slices.Contains(versions, version) {
+			available := "none"
+			if len(versions) > 0 {
+				available = strings.Join(versions, ", ")
+			}
+			return fmt.Errorf("%w: %s %s is not installed in any of the brand's clusters; installed %s versions: %s",
+				ErrInvalidRuntimeDefault, runtime, version, runtime, available)
+		}
+	}
+
+	if _, err := s.db.Exec(ctx, `DELETE FROM brand_runtime_defaults WHERE brand_id = $1`, brandID); err != nil {
+		return fmt.Errorf("clear runtime defaults: %w", err)
+	}
+	for _, runtime := range runtimes {
+		_, err := s.db.Exec(ctx,
+			`INSERT INTO brand_runtime_defaults (brand_id, runtime, version) VALUES ($1, $2, $3)`,
+			brandID, runtime, defaults[runtime],
+		)
+		if err != nil {
+			return fmt.Errorf("insert runtime default %s: %w", runtime, err)
+		}
+	}
+	return nil
+}
+
+// DefaultRuntimeVersion returns the version a new webroot of the tenant gets
+// for runtime when none is given: the default of the tenant's brand. It
+// returns ErrNoRuntimeDefault if the brand has none, and
+// ErrRuntimeDefaultUnavailable if a node of the tenant's shard has reported
+// its runtimes without the version. Nodes that never reported are left to
+// the create workflow, which asks them directly.
+func (s *WebrootService) DefaultRuntimeVersion(ctx context.Context, tenantID, runtime string) (string, error) {
+	var version string
+	var available bool
+	err := s.db.QueryRow(ctx,
+		`SELECT d.version, NOT EXISTS (
+		     SELECT 1 FROM node_shard_assignments nsa
+		     WHERE nsa.shard_id = t.shard_id
+		       AND EXISTS (SELECT 1 FROM node_runtimes nr WHERE nr.node_id = nsa.node_id)
+		       AND NOT EXISTS (SELECT 1 FROM node_runtimes nr
+		                       WHERE nr.node_id = nsa.node_id AND nr.runtime = d.runtime AND nr.version = d.version))
+		 FROM tenants t
+		 JOIN brand_runtime_defaults d ON d.brand_id = t.brand_id AND d.runtime = $2
+		 WHERE t.id = $1`, tenantID, runtime,
+	).Scan(&version, &available)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("%w for %s: runtime_version is required", ErrNoRuntimeDefault, runtime)
+	}
+	if err != nil {
+		return "", fmt.Errorf("get default %s version for tenant %s: %w", runtime, tenantID, err)
+	}
+	if !available {
+		return "", fmt.Errorf("%w: %s %s; set runtime_version explicitly", ErrRuntimeDefaultUnavailable, runtime, version)
+	}
+	return version, nil
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func installedRuntimeRow(runtime, version string) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = runtime
+		*(dest[1].(*string)) = version
+		return nil
+	}
+}
+
+func TestBrandService_SetRuntimeDefaults(t *testing.T) {
+	db := &mockDB{}
+	svc := NewBrandService(db, "")
+	ctx := context.Background()
+
+	db.On("Query", ctx, queryContaining("FROM node_runtimes"), []any{"acme"}).
+		Return(newMockRows(installedRuntimeRow("php", "8.2"), installedRuntimeRow("php", "8.3")), nil)
+	db.On("Exec", ctx, queryContaining("DELETE FROM brand_runtime_defaults"), []any{"acme"}).
+		Return(pgconn.CommandTag{}, nil)
+	db.On("Exec", ctx, queryContaining("INSERT INTO brand_runtime_defaults"), []any{"acme", "php", "8.3"}).
+		Return(pgconn.CommandTag{}, nil)
+
+	require.NoError(t, svc.SetRuntimeDefaults(ctx, "acme", map[string]string{"php": "8.3"}))
+	db.AssertExpectations(t)
+}
+
+func TestBrandService_SetRuntimeDefaults_NotInstalled(t *testing.T) {
+	db := &mockDB{}
+	svc := NewBrandService(db, "")
+	ctx := context.Background()
+
+	db.On("Query", ctx, queryContaining("FROM node_runtimes"), []any{"acme"}).
+		Return(newMockRows(installedRuntimeRow("php", "8.2"), installedRuntimeRow("php", "8.3")), nil)
+
+	err := svc.SetRuntimeDefaults(ctx, "acme", map[string]string{"php": "8.4"})
+	require.ErrorIs(t, err, ErrInvalidRuntimeDefault)
+	assert.Contains(t, err.Error(), "installed php versions: 8.2, 8.3")
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBrandService_SetRuntimeDefaults_NothingReported(t *testing.T) {
+	db := &mockDB{}
+	svc := NewBrandService(db, "")
+	ctx := context.Background()
+
+	db.On("Query", ctx, queryContaining("FROM node_runtimes"), []any{"acme"}).
+		Return(newEmptyMockRows(), nil)
+
+	err := svc.SetRuntimeDefaults(ctx, "acme", map[string]string{"node": "22"})
+	require.ErrorIs(t, err, ErrInvalidRuntimeDefault)
+	assert.Contains(t, err.Error(), "installed node versions: none")
+}
+
+func TestBrandService_SetRuntimeDefaults_Clear(t *testing.T) {
+	db := &mockDB{}
+	svc := NewBrandService(db, "")
+	ctx := context.Background()
+
+	db.On("Exec", ctx, queryContaining("DELETE FROM brand_runtime_defaults"), []any{"acme"}).
+		Return(pgconn.CommandTag{}, nil)
+
+	require.NoError(t, svc.SetRuntimeDefaults(ctx, "acme", map[string]string{}))
+	db.AssertNotCalled(t, "Query", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebrootService_DefaultRuntimeVersion(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, nil)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("brand_runtime_defaults"), []any{"t1", "php"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "8.3"
+			*(dest[1].(*bool)) = true
+			return nil
+		}})
+
+	version, err := svc.DefaultRuntimeVersion(ctx, "t1", "php")
+	require.NoError(t, err)
+	assert.Equal(t, "8.3", version)
+}
+
+func TestWebrootService_DefaultRuntimeVersion_NoDefault(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, nil)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("brand_runtime_defaults"), []any{"t1", "node"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }})
+
+	_, err := svc.DefaultRuntimeVersion(ctx, "t1", "node")
+	assert.ErrorIs(t, err, ErrNoRuntimeDefault)
+}
+
+func TestWebrootService_DefaultRuntimeVersion_NotOnShard(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, nil)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("brand_runtime_defaults"), []any{"t1", "php"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "8.4"
+			*(dest[1].(*bool)) = false
+			return nil
+		}})
+
+	_, err := svc.DefaultRuntimeVersion(ctx, "t1", "php")
+	require.ErrorIs(t, err, ErrRuntimeDefaultUnavailable)
+	assert.Contains(t, err.Error(), "php 8.4")
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func TestBrandService_SetZoneTemplates_Success(t *testing.T) {
+	db := &mockDB{}
+	tx := &mockTx{}
+	svc := NewBrandService(db, "")
+	ctx := context.Background()
+
+	db.On("Begin", ctx).Return(tx, nil)
+	tx.On("Exec", ctx, mock.MatchedBy(func(sql string) bool { return sql == `DELETE FROM brand_zone_templates WHERE brand_id = $1` }), mock.Anything).Return(pgconn.CommandTag{}, nil)
+	tx.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+	tx.On("Commit", ctx).Return(nil)
+	tx.On("Rollback", ctx).Return(nil)
+
+	stored, err := svc.SetZoneTemplates(ctx, "acme", []model.BrandZoneTemplate{
+		{Type: "CNAME", Name: "www.{zone}", Content: "{zone}"},
+		{Type: "TXT", Name: "{zone}", Content: "v=spf1 -all", TTL: 300},
+	})
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, defaultZoneTemplateTTL, stored[0].TTL)
+	assert.Equal(t, 300, stored[1].TTL)
+	for _, tmpl := range stored {
+		assert.NotEmpty(t, tmpl.ID)
+		assert.Equal(t, "acme", tmpl.BrandID)
+	}
+	tx.AssertNumberOfCalls(t, "Exec", 3)
+	tx.AssertCalled(t, "Commit", ctx)
+}
+
+func TestBrandService_SetZoneTemplates_MultipleSOA(t *testing.T) {
+	db := &mockDB{}
+	svc := NewBrandService(db, "")
+	soa := model.BrandZoneTemplate{Type: "SOA", Name: "{zone}", Content: "{primary_ns} {hostmaster_email} 1 10800 3600 604800 300"}
+
+	_, err := svc.SetZoneTemplates(context.Background(), "acme", []model.BrandZoneTemplate{soa, soa})
+	assert.ErrorIs(t, err, ErrMultipleSOATemplates)
+	db.AssertNotCalled(t, "Begin", mock.Anything)
+}
+
+func TestBrandService_SetZoneTemplates_InsertFailsRollsBack(t *testing.T) {
+	db := &mockDB{}
+	tx := &mockTx{}
+	svc := NewBrandService(db, "")
+	ctx := context.Background()
+
+	db.On("Begin", ctx).Return(tx, nil)
+	tx.On("Exec", ctx, mock.MatchedBy(func(sql string) bool { return sql == `DELETE FROM brand_zone_templates WHERE brand_id = $1` }), mock.Anything).Return(pgconn.CommandTag{}, nil)
+	tx.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, errors.New("insert failed"))
+	tx.On("Rollback", ctx).Return(nil)
+
+	_, err := svc.SetZoneTemplates(ctx, "acme", []model.BrandZoneTemplate{
+		{Type: "CNAME", Name: "www.{zone}", Content: "{zone}"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "insert brand zone template")
+	tx.AssertNotCalled(t, "Commit", mock.Anything)
+	tx.AssertCalled(t, "Rollback", ctx)
+}
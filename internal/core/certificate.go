@@ -3,8 +3,12 @@ package core
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/edvin/hosting/internal/crypto"
 	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	enumspb "go.temporal.io/api/enums/v1"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
@@ -45,6 +49,41 @@ func (s *CertificateService) Upload(ctx context.Context, cert *model.Certificate
 	return nil
 }
 
+// Import splits a user-supplied bundle (PEM in any order, or PKCS#12) into
+// leaf, key, and chain, checks it against the FQDN, and uploads the
+// normalized PEMs as a custom certificate. Bundle mistakes are returned
+// wrapping crypto.ErrInvalidCertificateBundle.
+func (s *CertificateService) Import(ctx context.Context, fqdn *model.FQDN, bundle []byte, passphrase string) (*model.Certificate, error) {
+	parsed, err := crypto.ParseCertificateBundle(bundle, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := parsed.Validate(fqdn.FQDN, now); err != nil {
+		return nil, err
+	}
+
+	issuedAt := parsed.Leaf.NotBefore
+	expiresAt := parsed.Leaf.NotAfter
+	cert := &model.Certificate{
+		ID:        platform.NewID(),
+		FQDNID:    fqdn.ID,
+		Type:      model.CertTypeCustom,
+		CertPEM:   parsed.CertPEM,
+		KeyPEM:    parsed.KeyPEM,
+		ChainPEM:  parsed.ChainPEM,
+		IssuedAt:  &issuedAt,
+		ExpiresAt: &expiresAt,
+		Status:    model.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.Upload(ctx, cert); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
 func (s *CertificateService) GetByID(ctx context.Context, id string) (*model.Certificate, error) {
 	var c model.Certificate
 	err := s.db.QueryRow(ctx,
@@ -58,6 +97,21 @@ func (s *CertificateService) GetByID(ctx context.Context, id string) (*model.Cer
 	return &c, nil
 }
 
+// GetActiveByFQDN returns the certificate currently served for an FQDN. The
+// error wraps pgx.ErrNoRows if none has been issued yet.
+func (s *CertificateService) GetActiveByFQDN(ctx context.Context, fqdnID string) (*model.Certificate, error) {
+	var c model.Certificate
+	err := s.db.QueryRow(ctx,
+		`SELECT id, fqdn_id, type, cert_pem, key_pem, chain_pem, issued_at, expires_at, status, status_message, is_active, created_at, updated_at
+		 FROM certificates WHERE fqdn_id = $1 AND is_active = true`, fqdnID,
+	).Scan(&c.ID, &c.FQDNID, &c.Type, &c.CertPEM, &c.KeyPEM, &c.ChainPEM,
+		&c.IssuedAt, &c.ExpiresAt, &c.Status, &c.StatusMessage, &c.IsActive, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get active certificate for fqdn %s: %w", fqdnID, err)
+	}
+	return &c, nil
+}
+
 func (s *CertificateService) ListByFQDN(ctx context.Context, fqdnID string, limit int, cursor string) ([]model.Certificate, bool, error) {
 	query := `SELECT id, fqdn_id, type, cert_pem, key_pem, chain_pem, issued_at, expires_at, status, status_message, is_active, created_at, updated_at FROM certificates WHERE fqdn_id = $1`
 	args := []any{fqdnID}
@@ -124,3 +178,72 @@ func (s *CertificateService) Retry(ctx context.Context, id string) error {
 		Arg:          id,
 	})
 }
+
+// Renew forces immediate reissue of a Let's Encrypt certificate by starting
+// ProvisionLECertWorkflow for its FQDN. The workflow ID is derived from the
+// certificate ID (the same one the nightly renewal cron uses), so a renewal
+// that is already in flight is returned instead of starting a second one.
+func (s *CertificateService) Renew(ctx context.Context, cert *model.Certificate) (*model.CertificateRenewal, error) {
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("renew-le-cert", cert.ID),
+		TaskQueue: taskQueue,
+	}, "ProvisionLECertWorkflow", cert.FQDNID)
+	if err != nil {
+		return nil, fmt.Errorf("start renewal for certificate %s: %w", cert.ID, err)
+	}
+	return &model.CertificateRenewal{
+		CertificateID: cert.ID,
+		WorkflowID:    run.GetID(),
+		RunID:         run.GetRunID(),
+		Status:        model.CertRenewalRunning,
+	}, nil
+}
+
+// GetRenewal reports the latest renewal of a certificate. Once the renewal has
+// completed, the FQDN's newly active certificate and its expiry are included.
+func (s *CertificateService) GetRenewal(ctx context.Context, cert *model.Certificate) (*model.CertificateRenewal, error) {
+	wfID := workflowID("renew-le-cert", cert.ID)
+	desc, err := s.tc.DescribeWorkflowExecution(ctx, wfID, "")
+	if err != nil {
+		return nil, fmt.Errorf("describe renewal for certificate %s: %w", cert.ID, err)
+	}
+	info := desc.GetWorkflowExecutionInfo()
+
+	renewal := &model.CertificateRenewal{
+		CertificateID: cert.ID,
+		WorkflowID:    wfID,
+		RunID:         info.GetExecution().GetRunId(),
+	}
+	if info.GetStartTime() != nil {
+		t := info.GetStartTime().AsTime()
+		renewal.StartedAt = &t
+	}
+	if info.GetCloseTime() != nil {
+		t := info.GetCloseTime().AsTime()
+		renewal.ClosedAt = &t
+	}
+
+	switch info.GetStatus() {
+	case enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING:
+		renewal.Status = model.CertRenewalRunning
+	case enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED:
+		renewal.Status = model.CertRenewalCompleted
+		var expiresAt *time.Time
+		err := s.db.QueryRow(ctx,
+			"SELECT id, expires_at FROM certificates WHERE fqdn_id = $1 AND is_active = true",
+			cert.FQDNID,
+		).Scan(&renewal.NewCertificateID, &expiresAt)
+		if err != nil {
+			return nil, fmt.Errorf("get renewed certificate for fqdn %s: %w", cert.FQDNID, err)
+		}
+		renewal.ExpiresAt = expiresAt
+	default:
+		renewal.Status = model.CertRenewalFailed
+		if err := s.tc.GetWorkflow(ctx, wfID, renewal.RunID).Get(ctx, nil); err != nil {
+			renewal.Error = err.Error()
+		} else {
+			renewal.Error = info.GetStatus().String()
+		}
+	}
+	return renewal, nil
+}
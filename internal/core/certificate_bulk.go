@@ -0,0 +1,100 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.temporal.io/api/serviceerror"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ErrInvalidBulkCerts is returned by BulkProvision for FQDNs that cannot get
+// a certificate through a bulk issuance.
+var ErrInvalidBulkCerts = errors.New("invalid bulk certificate issuance")
+
+// ErrBulkCertsRunning is returned by BulkProvision while an earlier bulk
+// issuance for the tenant is still running.
+var ErrBulkCertsRunning = errors.New("a bulk certificate issuance is already running for this tenant")
+
+// Defaults of the issuance rates of a bulk certificate issuance. Let's
+// Encrypt allows 50 new certificates per registered domain per week.
+const (
+	DefaultBulkCertsPerHour          = 20
+	DefaultBulkCertsPerDomainPerWeek = 50
+)
+
+// BulkProvisionCertsParams holds parameters for the
+// BulkProvisionCertsWorkflow. PerHour caps issuances across all FQDNs;
+// PerDomainPerWeek caps new certificates per registered domain in any 7 days.
+type BulkProvisionCertsParams struct {
+	TenantID         string   `json:"tenant_id"`
+	FQDNIDs          []string `json:"fqdn_ids"`
+	PerHour          int      `json:"per_hour"`
+	PerDomainPerWeek int      `json:"per_domain_per_week"`
+}
+
+// BulkProvision issues Let's Encrypt certificates for many of a tenant's
+// FQDNs by starting BulkProvisionCertsWorkflow, which spreads the issuances
+// over time to stay within the CA's rate limits. The workflow is started
+// directly rather than through the tenant's provision queue, as it can run
+// for days. The FQDNs must belong to the tenant, have SSL enabled and not use
+// a custom certificate. Only one bulk issuance runs per tenant at a time.
+func (s *CertificateService) BulkProvision(ctx context.Context, params BulkProvisionCertsParams) error {
+	if params.PerHour == 0 {
+		params.PerHour = DefaultBulkCertsPerHour
+	}
+	if params.PerDomainPerWeek == 0 {
+		params.PerDomainPerWeek = DefaultBulkCertsPerDomainPerWeek
+	}
+
+	rows, err := s.db.Query(ctx,
+		`SELECT f.id, f.fqdn, f.ssl_enabled,
+		        EXISTS (SELECT 1 FROM certificates c WHERE c.fqdn_id = f.id AND c.is_active AND c.type = $3)
+		 FROM fqdns f WHERE f.id = ANY($1) AND f.tenant_id = $2 AND f.status NOT IN ($4, $5)`,
+		params.FQDNIDs, params.TenantID, model.CertTypeCustom, model.StatusDeleting, model.StatusDeleted,
+	)
+	if err != nil {
+		return fmt.Errorf("list fqdns for bulk certificates: %w", err)
+	}
+	defer rows.Close()
+
+	found := make(map[string]bool, len(params.FQDNIDs))
+	for rows.Next() {
+		var id, fqdn string
+		var sslEnabled, customCert bool
+		if err := rows.Scan(&id, &fqdn, &sslEnabled, &customCert); err != nil {
+			return fmt.Errorf("scan fqdn: %w", err)
+		}
+		switch {
+		case !sslEnabled:
+			return fmt.Errorf("%w: %s does not have SSL enabled", ErrInvalidBulkCerts, fqdn)
+		case customCert:
+			return fmt.Errorf("%w: %s uses a custom certificate", ErrInvalidBulkCerts, fqdn)
+		}
+		found[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate fqdns: %w", err)
+	}
+	for _, id := range params.FQDNIDs {
+		if !found[id] {
+			return fmt.Errorf("%w: fqdn %s not found in tenant %s", ErrInvalidBulkCerts, id, params.TenantID)
+		}
+	}
+
+	err = startWorkflow(ctx, s.tc, s.db, params.TenantID, model.ProvisionTask{
+		WorkflowName: "BulkProvisionCertsWorkflow",
+		WorkflowID:   workflowID("bulk-certs", params.TenantID),
+		Arg:          params,
+	})
+	var started *serviceerror.WorkflowExecutionAlreadyStarted
+	if errors.As(err, &started) {
+		return ErrBulkCertsRunning
+	}
+	if err != nil {
+		return fmt.Errorf("start BulkProvisionCertsWorkflow: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	temporalmocks "go.temporal.io/sdk/mocks"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func bulkCertFQDNRow(id, fqdn string, sslEnabled, customCert bool) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = id
+		*(dest[1].(*string)) = fqdn
+		*(dest[2].(*bool)) = sslEnabled
+		*(dest[3].(*bool)) = customCert
+		return nil
+	}
+}
+
+func TestCertificateService_BulkProvision(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+	ctx := context.Background()
+
+	db.On("Query", ctx, sqlContains("FROM fqdns"), mock.Anything).Return(newMockRows(
+		bulkCertFQDNRow("fqdn-1", "a.example.com", true, false),
+		bulkCertFQDNRow("fqdn-2", "b.example.com", true, false),
+	), nil)
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("ExecuteWorkflow", ctx, mock.MatchedBy(func(opts any) bool { return true }), "BulkProvisionCertsWorkflow",
+		BulkProvisionCertsParams{
+			TenantID: "tenant-1", FQDNIDs: []string{"fqdn-1", "fqdn-2"},
+			PerHour: DefaultBulkCertsPerHour, PerDomainPerWeek: 10,
+		}).Return(wfRun, nil)
+
+	err := svc.BulkProvision(ctx, BulkProvisionCertsParams{TenantID: "tenant-1", FQDNIDs: []string{"fqdn-1", "fqdn-2"}, PerDomainPerWeek: 10})
+	require.NoError(t, err)
+	tc.AssertExpectations(t)
+}
+
+func TestCertificateService_BulkProvision_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []func(dest ...any) error
+		want string
+	}{
+		{"other tenant", []func(dest ...any) error{bulkCertFQDNRow("fqdn-1", "a.example.com", true, false)}, "fqdn-2 not found"},
+		{"ssl disabled", []func(dest ...any) error{bulkCertFQDNRow("fqdn-1", "a.example.com", false, false)}, "does not have SSL enabled"},
+		{"custom certificate", []func(dest ...any) error{bulkCertFQDNRow("fqdn-1", "a.example.com", true, true)}, "uses a custom certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			tc := &temporalmocks.Client{}
+			svc := NewCertificateService(db, tc)
+			ctx := context.Background()
+
+			db.On("Query", ctx, sqlContains("FROM fqdns"), mock.Anything).Return(newMockRows(tt.rows...), nil)
+
+			err := svc.BulkProvision(ctx, BulkProvisionCertsParams{TenantID: "tenant-1", FQDNIDs: []string{"fqdn-1", "fqdn-2"}})
+			assert.ErrorIs(t, err, ErrInvalidBulkCerts)
+			assert.Contains(t, err.Error(), tt.want)
+			tc.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestCertificateService_BulkProvision_AlreadyRunning(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+	ctx := WithOperationTracking(context.Background())
+
+	db.On("Query", ctx, sqlContains("FROM fqdns"), mock.Anything).Return(newMockRows(
+		bulkCertFQDNRow("fqdn-1", "a.example.com", true, false),
+	), nil)
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "BulkProvisionCertsWorkflow", mock.Anything).
+		Return(nil, serviceerror.NewWorkflowExecutionAlreadyStarted("already started", "", "run-0"))
+
+	err := svc.BulkProvision(ctx, BulkProvisionCertsParams{TenantID: "tenant-1", FQDNIDs: []string{"fqdn-1"}})
+	assert.ErrorIs(t, err, ErrBulkCertsRunning)
+	// The failed start is recorded on the operation.
+	last := db.Calls[len(db.Calls)-1]
+	assert.Equal(t, model.OperationFailed, last.Arguments.Get(2).([]any)[0])
+}
@@ -3,15 +3,24 @@ package core
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/edvin/hosting/internal/crypto"
 	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	common "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	temporalclient "go.temporal.io/sdk/client"
 	temporalmocks "go.temporal.io/sdk/mocks"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestNewCertificateService(t *testing.T) {
@@ -147,6 +156,20 @@ func TestCertificateService_Upload_WorkflowError(t *testing.T) {
 	tc.AssertExpectations(t)
 }
 
+// ---------- Import ----------
+
+func TestCertificateService_Import_InvalidBundle(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+
+	fqdn := &model.FQDN{ID: "test-fqdn-1", FQDN: "example.com"}
+	_, err := svc.Import(context.Background(), fqdn, []byte("not a certificate"), "")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, crypto.ErrInvalidCertificateBundle)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
 // ---------- GetByID ----------
 
 func TestCertificateService_GetByID_Success(t *testing.T) {
@@ -207,6 +230,57 @@ func TestCertificateService_GetByID_NotFound(t *testing.T) {
 	db.AssertExpectations(t)
 }
 
+// ---------- GetActiveByFQDN ----------
+
+func TestCertificateService_GetActiveByFQDN_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Microsecond)
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-cert-1"
+		*(dest[1].(*string)) = "test-fqdn-1"
+		*(dest[2].(*string)) = model.CertTypeLetsEncrypt
+		*(dest[3].(*string)) = "cert-pem"
+		*(dest[4].(*string)) = "key-pem"
+		*(dest[5].(*string)) = "chain-pem"
+		*(dest[8].(*string)) = model.StatusActive
+		*(dest[10].(*bool)) = true
+		*(dest[11].(*time.Time)) = now
+		*(dest[12].(*time.Time)) = now
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.MatchedBy(func(q string) bool {
+		return strings.Contains(q, "is_active = true")
+	}), []any{"test-fqdn-1"}).Return(row)
+
+	result, err := svc.GetActiveByFQDN(ctx, "test-fqdn-1")
+	require.NoError(t, err)
+	assert.Equal(t, "test-cert-1", result.ID)
+	assert.Equal(t, "chain-pem", result.ChainPEM)
+	db.AssertExpectations(t)
+}
+
+func TestCertificateService_GetActiveByFQDN_NoneIssued(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		return pgx.ErrNoRows
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+	result, err := svc.GetActiveByFQDN(ctx, "test-fqdn-1")
+	require.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+	db.AssertExpectations(t)
+}
+
 // ---------- ListByFQDN ----------
 
 func TestCertificateService_ListByFQDN_Success(t *testing.T) {
@@ -279,3 +353,130 @@ func TestCertificateService_ListByFQDN_QueryError(t *testing.T) {
 	assert.Contains(t, err.Error(), "list certificates")
 	db.AssertExpectations(t)
 }
+
+// ---------- Renew ----------
+
+func TestCertificateService_Renew_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+	ctx := context.Background()
+
+	cert := &model.Certificate{ID: "test-cert-1", FQDNID: "test-fqdn-1", Type: model.CertTypeLetsEncrypt}
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("renew-le-cert-test-cert-1")
+	wfRun.On("GetRunID").Return("mock-run-id")
+	tc.On("ExecuteWorkflow", ctx, mock.MatchedBy(func(opts temporalclient.StartWorkflowOptions) bool {
+		return opts.ID == "renew-le-cert-test-cert-1"
+	}), "ProvisionLECertWorkflow", "test-fqdn-1").Return(wfRun, nil)
+
+	renewal, err := svc.Renew(ctx, cert)
+	require.NoError(t, err)
+	assert.Equal(t, "test-cert-1", renewal.CertificateID)
+	assert.Equal(t, "renew-le-cert-test-cert-1", renewal.WorkflowID)
+	assert.Equal(t, "mock-run-id", renewal.RunID)
+	assert.Equal(t, model.CertRenewalRunning, renewal.Status)
+	tc.AssertExpectations(t)
+}
+
+func TestCertificateService_Renew_WorkflowError(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+	ctx := context.Background()
+
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "ProvisionLECertWorkflow", mock.Anything).Return(nil, errors.New("temporal down"))
+
+	renewal, err := svc.Renew(ctx, &model.Certificate{ID: "test-cert-1", FQDNID: "test-fqdn-1"})
+	require.Error(t, err)
+	assert.Nil(t, renewal)
+	assert.Contains(t, err.Error(), "start renewal")
+}
+
+// ---------- GetRenewal ----------
+
+func describeRenewal(status enumspb.WorkflowExecutionStatus) *workflowservice.DescribeWorkflowExecutionResponse {
+	return &workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflow.WorkflowExecutionInfo{
+			Execution: &common.WorkflowExecution{WorkflowId: "renew-le-cert-test-cert-1", RunId: "mock-run-id"},
+			Status:    status,
+			StartTime: timestamppb.Now(),
+		},
+	}
+}
+
+func TestCertificateService_GetRenewal_Running(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+	ctx := context.Background()
+
+	tc.On("DescribeWorkflowExecution", ctx, "renew-le-cert-test-cert-1", "").
+		Return(describeRenewal(enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING), nil)
+
+	renewal, err := svc.GetRenewal(ctx, &model.Certificate{ID: "test-cert-1", FQDNID: "test-fqdn-1"})
+	require.NoError(t, err)
+	assert.Equal(t, model.CertRenewalRunning, renewal.Status)
+	assert.Equal(t, "mock-run-id", renewal.RunID)
+	assert.NotNil(t, renewal.StartedAt)
+	assert.Nil(t, renewal.ExpiresAt)
+	db.AssertExpectations(t)
+}
+
+func TestCertificateService_GetRenewal_CompletedReturnsNewExpiry(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+	ctx := context.Background()
+
+	expires := time.Now().Add(90 * 24 * time.Hour).Truncate(time.Microsecond)
+	tc.On("DescribeWorkflowExecution", ctx, "renew-le-cert-test-cert-1", "").
+		Return(describeRenewal(enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED), nil)
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-cert-2"
+		*(dest[1].(**time.Time)) = &expires
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-fqdn-1"}).Return(row)
+
+	renewal, err := svc.GetRenewal(ctx, &model.Certificate{ID: "test-cert-1", FQDNID: "test-fqdn-1"})
+	require.NoError(t, err)
+	assert.Equal(t, model.CertRenewalCompleted, renewal.Status)
+	assert.Equal(t, "test-cert-2", renewal.NewCertificateID)
+	require.NotNil(t, renewal.ExpiresAt)
+	assert.Equal(t, expires, *renewal.ExpiresAt)
+	db.AssertExpectations(t)
+}
+
+func TestCertificateService_GetRenewal_FailedIncludesError(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+	ctx := context.Background()
+
+	tc.On("DescribeWorkflowExecution", ctx, "renew-le-cert-test-cert-1", "").
+		Return(describeRenewal(enumspb.WORKFLOW_EXECUTION_STATUS_FAILED), nil)
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, nil).Return(errors.New("acme: challenge invalid"))
+	tc.On("GetWorkflow", ctx, "renew-le-cert-test-cert-1", "mock-run-id").Return(wfRun)
+
+	renewal, err := svc.GetRenewal(ctx, &model.Certificate{ID: "test-cert-1", FQDNID: "test-fqdn-1"})
+	require.NoError(t, err)
+	assert.Equal(t, model.CertRenewalFailed, renewal.Status)
+	assert.Contains(t, renewal.Error, "challenge invalid")
+}
+
+func TestCertificateService_GetRenewal_DescribeError(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCertificateService(db, tc)
+	ctx := context.Background()
+
+	tc.On("DescribeWorkflowExecution", ctx, "renew-le-cert-test-cert-1", "").Return(nil, errors.New("not found"))
+
+	renewal, err := svc.GetRenewal(ctx, &model.Certificate{ID: "test-cert-1", FQDNID: "test-fqdn-1"})
+	require.Error(t, err)
+	assert.Nil(t, renewal)
+	assert.Contains(t, err.Error(), "describe renewal")
+}
@@ -0,0 +1,135 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// Local ports hosting-cli proxy listens on by default.
+const (
+	localMySQLPort  = 3306
+	localValkeyPort = 6379
+)
+
+// mysqlPort is the port MySQL listens on on database shard nodes.
+const mysqlPort = 3306
+
+// ConnectionInfo returns the address and DSN templates of a database, one
+// per database user.
+func (s *DatabaseService) ConnectionInfo(ctx context.Context, database *model.Database) (*model.ConnectionInfo, error) {
+	if database.ShardID == nil {
+		return nil, fmt.Errorf("database %s has no shard assigned", database.ID)
+	}
+	host, err := tenantServiceAddress(ctx, s.db, database.TenantID, *database.ShardID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve address of database %s: %w", database.ID, err)
+	}
+	users, err := s.connectionUsers(ctx, database.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &model.ConnectionInfo{
+		Type:         "mysql",
+		Host:         host,
+		Port:         mysqlPort,
+		Database:     database.ID,
+		LocalPort:    localMySQLPort,
+		ProxyCommand: proxyCommand(database.TenantID, host, mysqlPort, localMySQLPort),
+		Users:        []model.ConnectionUser{},
+	}
+	for _, u := range users {
+		info.Users = append(info.Users, model.ConnectionUser{
+			Username: u,
+			DSN:      connectionDSN("mysql", u, host, mysqlPort, "/"+database.ID),
+			LocalDSN: connectionDSN("mysql", u, "127.0.0.1", localMySQLPort, "/"+database.ID),
+		})
+	}
+	return info, nil
+}
+
+// ConnectionInfo returns the address and DSN templates of a Valkey
+// instance: one for the default user, which authenticates with the instance
+// password, and one per Valkey user.
+func (s *ValkeyInstanceService) ConnectionInfo(ctx context.Context, instance *model.ValkeyInstance) (*model.ConnectionInfo, error) {
+	if instance.ShardID == nil {
+		return nil, fmt.Errorf("valkey instance %s has no shard assigned", instance.ID)
+	}
+	host, err := tenantServiceAddress(ctx, s.db, instance.TenantID, *instance.ShardID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve address of valkey instance %s: %w", instance.ID, err)
+	}
+
+	rows, err := s.db.Query(ctx, "SELECT username FROM valkey_users WHERE valkey_instance_id = $1 ORDER BY username", instance.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list users for valkey instance %s: %w", instance.ID, err)
+	}
+	defer rows.Close()
+	users := []string{"default"}
+	for rows.Next() {
+		var u string
+		if err := rows.Scan(&u); err != nil {
+			return nil, fmt.Errorf("scan valkey user: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate valkey users: %w", err)
+	}
+
+	info := &model.ConnectionInfo{
+		Type:         "valkey",
+		Host:         host,
+		Port:         instance.Port,
+		LocalPort:    localValkeyPort,
+		ProxyCommand: proxyCommand(instance.TenantID, host, instance.Port, localValkeyPort),
+	}
+	for _, u := range users {
+		info.Users = append(info.Users, model.ConnectionUser{
+			Username: u,
+			DSN:      connectionDSN("redis", u, host, instance.Port, ""),
+			LocalDSN: connectionDSN("redis", u, "127.0.0.1", localValkeyPort, ""),
+		})
+	}
+	return info, nil
+}
+
+// tenantServiceAddress returns the tenant's ULA address on the primary of a
+// database or Valkey shard. The primary is the shard's
+// config.primary_node_id, falling back to the shard's first node. It is
+// computed like the service addresses in WireGuard client configs, which
+// hosting-cli proxy forwards to.
+func tenantServiceAddress(ctx context.Context, db DB, tenantID, shardID string) (string, error) {
+	var clusterID, shardRole string
+	var tenantUID, shardIndex int
+	err := db.QueryRow(ctx,
+		`SELECT t.cluster_id, t.uid, s.role, nsa.shard_index
+		 FROM tenants t
+		 JOIN shards s ON s.id = $2
+		 JOIN node_shard_assignments nsa ON nsa.shard_id = s.id
+			AND (nsa.node_id = s.config->>'primary_node_id'
+				OR (COALESCE(s.config->>'primary_node_id', '') = '' AND nsa.shard_index = 1))
+		 WHERE t.id = $1`, tenantID, shardID,
+	).Scan(&clusterID, &tenantUID, &shardRole, &shardIndex)
+	if err != nil {
+		return "", err
+	}
+	return ComputeTenantULA(clusterID, TransitIndex(shardRole, shardIndex), tenantUID), nil
+}
+
+// proxyCommand is the hosting-cli invocation that forwards localPort to the
+// service through the tenant's tunnel.
+func proxyCommand(tenantID, host string, port, localPort int) string {
+	return fmt.Sprintf("hosting-cli proxy -profile %s -target %s -port %d",
+		tenantID, net.JoinHostPort(host, strconv.Itoa(port)), localPort)
+}
+
+// connectionDSN builds a URL-style DSN with the password placeholder.
+func connectionDSN(scheme, username, host string, port int, path string) string {
+	return fmt.Sprintf("%s://%s:%s@%s%s", scheme, username, model.DSNPasswordPlaceholder,
+		net.JoinHostPort(host, strconv.Itoa(port)), path)
+}
@@ -19,11 +19,11 @@ func NewCronJobService(db DB, tc temporalclient.Client) *CronJobService {
 
 func (s *CronJobService) Create(ctx context.Context, cronJob *model.CronJob) error {
 	_, err := s.db.Exec(ctx,
-		`INSERT INTO cron_jobs (id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, max_failures, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		`INSERT INTO cron_jobs (id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, timezone, max_failures, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
 		cronJob.ID, cronJob.TenantID, cronJob.WebrootID, cronJob.Schedule,
 		cronJob.Command, cronJob.WorkingDirectory, cronJob.Enabled, cronJob.TimeoutSeconds,
-		cronJob.MaxMemoryMB, cronJob.MaxFailures, cronJob.Status, cronJob.CreatedAt, cronJob.UpdatedAt,
+		cronJob.MaxMemoryMB, cronJob.Timezone, cronJob.MaxFailures, cronJob.Status, cronJob.CreatedAt, cronJob.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("insert cron job: %w", err)
@@ -40,12 +40,12 @@ func (s *CronJobService) Create(ctx context.Context, cronJob *model.CronJob) err
 	return nil
 }
 
-const cronJobColumns = `id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, consecutive_failures, max_failures, status, status_message, created_at, updated_at`
+const cronJobColumns = `id, tenant_id, webroot_id, schedule, command, working_directory, enabled, timeout_seconds, max_memory_mb, timezone, consecutive_failures, max_failures, status, status_message, created_at, updated_at`
 
 func scanCronJob(row interface{ Scan(dest ...any) error }) (model.CronJob, error) {
 	var c model.CronJob
 	err := row.Scan(&c.ID, &c.TenantID, &c.WebrootID, &c.Schedule, &c.Command,
-		&c.WorkingDirectory, &c.Enabled, &c.TimeoutSeconds, &c.MaxMemoryMB,
+		&c.WorkingDirectory, &c.Enabled, &c.TimeoutSeconds, &c.MaxMemoryMB, &c.Timezone,
 		&c.ConsecutiveFailures, &c.MaxFailures,
 		&c.Status, &c.StatusMessage, &c.CreatedAt, &c.UpdatedAt)
 	return c, err
@@ -105,9 +105,9 @@ func (s *CronJobService) ListByWebroot(ctx context.Context, webrootID string, li
 func (s *CronJobService) Update(ctx context.Context, cronJob *model.CronJob) error {
 	_, err := s.db.Exec(ctx,
 		`UPDATE cron_jobs SET schedule = $1, command = $2, working_directory = $3, timeout_seconds = $4,
-		 max_memory_mb = $5, status = $6, updated_at = now() WHERE id = $7`,
+		 max_memory_mb = $5, timezone = $6, status = $7, updated_at = now() WHERE id = $8`,
 		cronJob.Schedule, cronJob.Command, cronJob.WorkingDirectory, cronJob.TimeoutSeconds,
-		cronJob.MaxMemoryMB, cronJob.Status, cronJob.ID,
+		cronJob.MaxMemoryMB, cronJob.Timezone, cronJob.Status, cronJob.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update cron job %s: %w", cronJob.ID, err)
@@ -195,6 +195,40 @@ func (s *CronJobService) Disable(ctx context.Context, id string) error {
 	})
 }
 
+// Pause disables a cron job like Disable and also stops a run in progress,
+// for stopping a misbehaving job quickly. The job keeps its units and is
+// resumed with Resume.
+func (s *CronJobService) Pause(ctx context.Context, id string) error {
+	var status, tenantID string
+	err := s.db.QueryRow(ctx, "SELECT status, tenant_id FROM cron_jobs WHERE id = $1", id).Scan(&status, &tenantID)
+	if err != nil {
+		return fmt.Errorf("get cron job status: %w", err)
+	}
+	if status != model.StatusActive {
+		return fmt.Errorf("cron job %s is not in active state (current: %s)", id, status)
+	}
+
+	_, err = s.db.Exec(ctx,
+		"UPDATE cron_jobs SET enabled = false, status = $1, updated_at = now() WHERE id = $2",
+		model.StatusProvisioning, id,
+	)
+	if err != nil {
+		return fmt.Errorf("pause cron job %s: %w", id, err)
+	}
+
+	return signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "PauseCronJobWorkflow",
+		WorkflowID:   workflowID("cron-job", id),
+		Arg:          id,
+	})
+}
+
+// Resume re-enables a paused cron job's timers. It is Enable under the name
+// that pairs with Pause.
+func (s *CronJobService) Resume(ctx context.Context, id string) error {
+	return s.Enable(ctx, id)
+}
+
 func (s *CronJobService) Retry(ctx context.Context, id string) error {
 	var status, tenantID string
 	err := s.db.QueryRow(ctx, "SELECT status, tenant_id FROM cron_jobs WHERE id = $1", id).Scan(&status, &tenantID)
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/edvin/hosting/internal/crypto"
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	temporalclient "go.temporal.io/sdk/client"
+)
+
+// ErrNoStoredSecret is returned by BulkSet when an entry asks to keep a secret
+// that is not stored.
+var ErrNoStoredSecret = errors.New("no stored secret to keep")
+
+// CronJobEnvVarInput is one entry passed to CronJobEnvVarService.BulkSet. A
+// secret with a nil Value keeps the value already stored under that name, so
+// clients can resend the full set without knowing the secrets in it.
+type CronJobEnvVarInput struct {
+	Name     string
+	Value    *string
+	IsSecret bool
+}
+
+type CronJobEnvVarService struct {
+	db  DB
+	tc  temporalclient.Client
+	kek []byte // master key (KEK), 32 bytes
+}
+
+func NewCronJobEnvVarService(db DB, tc temporalclient.Client, kekHex string) *CronJobEnvVarService {
+	var kek []byte
+	if kekHex != "" {
+		kek, _ = hex.DecodeString(kekHex)
+	}
+	return &CronJobEnvVarService{db: db, tc: tc, kek: kek}
+}
+
+// List returns all env vars for a cron job. Secret values are redacted.
+func (s *CronJobEnvVarService) List(ctx context.Context, cronJobID string) ([]model.CronJobEnvVar, error) {
+	vars, err := s.list(ctx, cronJobID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range vars {
+		if vars[i].IsSecret {
+			vars[i].Value = "***"
+		}
+	}
+	return vars, nil
+}
+
+func (s *CronJobEnvVarService) list(ctx context.Context, cronJobID string) ([]model.CronJobEnvVar, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT id, cron_job_id, name, value, is_secret, created_at, updated_at
+		 FROM cron_job_env_vars WHERE cron_job_id = $1 ORDER BY name`, cronJobID)
+	if err != nil {
+		return nil, fmt.Errorf("list cron job env vars: %w", err)
+	}
+	defer rows.Close()
+
+	var vars []model.CronJobEnvVar
+	for rows.Next() {
+		var v model.CronJobEnvVar
+		if err := rows.Scan(&v.ID, &v.CronJobID, &v.Name, &v.Value, &v.IsSecret, &v.CreatedAt, &v.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan cron job env var: %w", err)
+		}
+		vars = append(vars, v)
+	}
+	return vars, rows.Err()
+}
+
+// BulkSet replaces all env vars for a cron job and rewrites its units on the
+// nodes. Secrets are encrypted with the tenant DEK before storage.
+func (s *CronJobEnvVarService) BulkSet(ctx context.Context, cronJobID string, vars []CronJobEnvVarInput) error {
+	tenantID, err := resolveTenantIDFromCronJob(ctx, s.db, cronJobID)
+	if err != nil {
+		return fmt.Errorf("resolve tenant for cron job %s: %w", cronJobID, err)
+	}
+
+	existing, err := s.list(ctx, cronJobID)
+	if err != nil {
+		return err
+	}
+	storedSecrets := make(map[string]string, len(existing))
+	for _, v := range existing {
+		if v.IsSecret {
+			storedSecrets[v.Name] = v.Value
+		}
+	}
+
+	// Resolve every value before touching the table so a bad entry leaves
+	// the current set in place.
+	values := make([]string, len(vars))
+	for i, v := range vars {
+		switch {
+		case v.Value == nil:
+			stored, ok := storedSecrets[v.Name]
+			if !ok || !v.IsSecret {
+				return fmt.Errorf("env var %q: %w", v.Name, ErrNoStoredSecret)
+			}
+			values[i] = stored
+		case v.IsSecret:
+			dek, err := loadOrCreateTenantDEK(ctx, s.db, s.kek, tenantID)
+			if err != nil {
+				return fmt.Errorf("get tenant dek: %w", err)
+			}
+			encrypted, err := crypto.Encrypt([]byte(*v.Value), dek)
+			if err != nil {
+				return fmt.Errorf("encrypt env var %s: %w", v.Name, err)
+			}
+			values[i] = encrypted
+		default:
+			values[i] = *v.Value
+		}
+	}
+
+	if _, err := s.db.Exec(ctx, `DELETE FROM cron_job_env_vars WHERE cron_job_id = $1`, cronJobID); err != nil {
+		return fmt.Errorf("delete existing cron job env vars: %w", err)
+	}
+
+	now := time.Now()
+	for i, v := range vars {
+		_, err := s.db.Exec(ctx,
+			`INSERT INTO cron_job_env_vars (id, cron_job_id, name, value, is_secret, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			platform.NewID(), cronJobID, v.Name, values[i], v.IsSecret, now, now)
+		if err != nil {
+			return fmt.Errorf("insert cron job env var %s: %w", v.Name, err)
+		}
+	}
+
+	return s.signalUpdate(ctx, tenantID, cronJobID)
+}
+
+// DeleteByName deletes a single env var by name.
+func (s *CronJobEnvVarService) DeleteByName(ctx context.Context, cronJobID, name string) error {
+	tag, err := s.db.Exec(ctx,
+		`DELETE FROM cron_job_env_vars WHERE cron_job_id = $1 AND name = $2`, cronJobID, name)
+	if err != nil {
+		return fmt.Errorf("delete cron job env var: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("env var %q not found", name)
+	}
+
+	tenantID, err := resolveTenantIDFromCronJob(ctx, s.db, cronJobID)
+	if err != nil {
+		return fmt.Errorf("resolve tenant for cron job %s: %w", cronJobID, err)
+	}
+	return s.signalUpdate(ctx, tenantID, cronJobID)
+}
+
+func (s *CronJobEnvVarService) signalUpdate(ctx context.Context, tenantID, cronJobID string) error {
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "UpdateCronJobWorkflow",
+		WorkflowID:   workflowID("cron-job", cronJobID),
+		Arg:          cronJobID,
+	}); err != nil {
+		return fmt.Errorf("signal UpdateCronJobWorkflow: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edvin/hosting/internal/crypto"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func cronEnvVarRow(name, value string, secret bool) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = "env-" + name
+		*(dest[1].(*string)) = "test-cron-1"
+		*(dest[2].(*string)) = name
+		*(dest[3].(*string)) = value
+		*(dest[4].(*bool)) = secret
+		*(dest[5].(*time.Time)) = time.Now()
+		*(dest[6].(*time.Time)) = time.Now()
+		return nil
+	}
+}
+
+func isCronEnvQuery(sql string) bool {
+	return strings.Contains(sql, "FROM cron_job_env_vars")
+}
+
+func isTenantResolve(sql string) bool {
+	return strings.Contains(sql, "SELECT tenant_id FROM cron_jobs")
+}
+
+func mockSignalOK(tc *temporalmocks.Client) {
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id")
+	wfRun.On("GetRunID").Return("mock-run-id")
+	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil)
+}
+
+func TestCronJobEnvVarService_List_RedactsSecrets(t *testing.T) {
+	db := &mockDB{}
+	svc := NewCronJobEnvVarService(db, nil, "")
+	ctx := context.Background()
+
+	rows := newMockRows(
+		cronEnvVarRow("API_TOKEN", "ciphertext", true),
+		cronEnvVarRow("LOG_LEVEL", "debug", false),
+	)
+	db.On("Query", ctx, mock.MatchedBy(isCronEnvQuery), []any{"test-cron-1"}).Return(rows, nil)
+
+	vars, err := svc.List(ctx, "test-cron-1")
+	require.NoError(t, err)
+	require.Len(t, vars, 2)
+	assert.Equal(t, "***", vars[0].Value)
+	assert.True(t, vars[0].IsSecret)
+	assert.Equal(t, "debug", vars[1].Value)
+}
+
+func TestCronJobEnvVarService_BulkSet_EncryptsSecrets(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	kek, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	dek, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	encryptedDEK, err := crypto.Encrypt(dek, kek)
+	require.NoError(t, err)
+	svc := NewCronJobEnvVarService(db, tc, hex.EncodeToString(kek))
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.MatchedBy(isTenantResolve), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-tenant-1"
+		return nil
+	}})
+	db.On("QueryRow", ctx, mock.MatchedBy(func(sql string) bool {
+		return strings.Contains(sql, "FROM tenant_encryption_keys")
+	}), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = encryptedDEK
+		return nil
+	}})
+	db.On("Query", ctx, mock.MatchedBy(isCronEnvQuery), mock.Anything).Return(newEmptyMockRows(), nil)
+
+	var inserted []any
+	db.On("Exec", ctx, mock.MatchedBy(func(sql string) bool {
+		return strings.Contains(sql, "INSERT INTO cron_job_env_vars")
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		inserted = args.Get(2).([]any)
+	}).Return(pgconn.CommandTag{}, nil)
+	db.On("Exec", ctx, mock.Anything, mock.Anything).Return(pgconn.CommandTag{}, nil)
+	mockSignalOK(tc)
+
+	secret := "s3cr3t"
+	err = svc.BulkSet(ctx, "test-cron-1", []CronJobEnvVarInput{{Name: "API_TOKEN", Value: &secret, IsSecret: true}})
+	require.NoError(t, err)
+
+	require.Len(t, inserted, 7)
+	stored := inserted[3].(string)
+	assert.NotEqual(t, secret, stored)
+	plaintext, err := crypto.Decrypt(stored, dek)
+	require.NoError(t, err)
+	assert.Equal(t, secret, string(plaintext))
+	tc.AssertExpectations(t)
+}
+
+func TestCronJobEnvVarService_BulkSet_KeepsStoredSecret(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCronJobEnvVarService(db, tc, "")
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.MatchedBy(isTenantResolve), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-tenant-1"
+		return nil
+	}})
+	db.On("Query", ctx, mock.MatchedBy(isCronEnvQuery), mock.Anything).Return(newMockRows(
+		cronEnvVarRow("API_TOKEN", "stored-ciphertext", true),
+	), nil)
+
+	var inserted []any
+	db.On("Exec", ctx, mock.MatchedBy(func(sql string) bool {
+		return strings.Contains(sql, "INSERT INTO cron_job_env_vars")
+	}), mock.Anything).Run(func(args mock.Arguments) {
+		inserted = args.Get(2).([]any)
+	}).Return(pgconn.CommandTag{}, nil)
+	db.On("Exec", ctx, mock.Anything, mock.Anything).Return(pgconn.CommandTag{}, nil)
+	mockSignalOK(tc)
+
+	err := svc.BulkSet(ctx, "test-cron-1", []CronJobEnvVarInput{{Name: "API_TOKEN", IsSecret: true}})
+	require.NoError(t, err)
+	require.Len(t, inserted, 7)
+	assert.Equal(t, "stored-ciphertext", inserted[3])
+	assert.Equal(t, true, inserted[4])
+}
+
+func TestCronJobEnvVarService_BulkSet_KeepWithoutStoredSecret(t *testing.T) {
+	db := &mockDB{}
+	svc := NewCronJobEnvVarService(db, nil, "")
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.MatchedBy(isTenantResolve), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-tenant-1"
+		return nil
+	}})
+	db.On("Query", ctx, mock.MatchedBy(isCronEnvQuery), mock.Anything).Return(newMockRows(
+		cronEnvVarRow("API_TOKEN", "plain", false),
+	), nil)
+
+	err := svc.BulkSet(ctx, "test-cron-1", []CronJobEnvVarInput{{Name: "API_TOKEN", IsSecret: true}})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNoStoredSecret))
+	// Nothing was deleted or written.
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestCronJobEnvVarService_DeleteByName_NotFound(t *testing.T) {
+	db := &mockDB{}
+	svc := NewCronJobEnvVarService(db, nil, "")
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{"test-cron-1", "MISSING"}).Return(pgconn.NewCommandTag("DELETE 0"), nil)
+
+	err := svc.DeleteByName(ctx, "test-cron-1", "MISSING")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+}
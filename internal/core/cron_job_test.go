@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func cronJobStatusRow(status string) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = status
+		*(dest[1].(*string)) = "test-tenant-1"
+		return nil
+	}}
+}
+
+func TestCronJobService_Pause_SignalsPauseWorkflow(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCronJobService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(cronJobStatusRow(model.StatusActive))
+	db.On("Exec", ctx, mock.MatchedBy(func(sql string) bool {
+		return strings.Contains(sql, "SET enabled = false")
+	}), mock.Anything).Return(pgconn.CommandTag{}, nil)
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("SignalWithStartWorkflow", mock.Anything, "tenant-test-tenant-1", model.ProvisionSignalName,
+		mock.MatchedBy(func(task model.ProvisionTask) bool {
+			return task.WorkflowName == "PauseCronJobWorkflow" && task.Arg == "test-cron-1"
+		}), mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	require.NoError(t, svc.Pause(ctx, "test-cron-1"))
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestCronJobService_Pause_NotActive(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewCronJobService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(cronJobStatusRow(model.StatusAutoDisabled))
+
+	err := svc.Pause(ctx, "test-cron-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not in active state")
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -19,7 +19,9 @@ func NewDaemonService(db DB, tc temporalclient.Client) *DaemonService {
 }
 
 func (s *DaemonService) Create(ctx context.Context, daemon *model.Daemon) error {
-	// Assign node_id via least-loaded round-robin across active shard nodes.
+	// Assign node_id via least-loaded round-robin across active shard nodes,
+	// preferring the node with the most available memory on a tie. Stats
+	// older than 15 minutes are ignored.
 	var shardID *string
 	err := s.db.QueryRow(ctx, "SELECT shard_id FROM tenants WHERE id = $1", daemon.TenantID).Scan(&shardID)
 	if err != nil {
@@ -32,9 +34,10 @@ func (s *DaemonService) Create(ctx context.Context, daemon *model.Daemon) error
 			`SELECT n.id FROM nodes n
 			 JOIN node_shard_assignments nsa ON nsa.node_id = n.id
 			 LEFT JOIN daemons d ON d.node_id = n.id
+			 LEFT JOIN node_stats ns ON ns.node_id = n.id AND ns.collected_at > now() - interval '15 minutes'
 			 WHERE nsa.shard_id = $1 AND n.status = 'active'
 			 GROUP BY n.id
-			 ORDER BY COUNT(d.id) ASC, n.id ASC
+			 ORDER BY COUNT(d.id) ASC, MAX(ns.mem_available_bytes) DESC NULLS LAST, n.id ASC
 			 LIMIT 1`, *shardID,
 		).Scan(&nodeID)
 		if err != nil {
@@ -6,6 +6,7 @@ import (
 
 	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
@@ -20,10 +21,10 @@ func NewDatabaseService(db DB, tc temporalclient.Client) *DatabaseService {
 
 func (s *DatabaseService) Create(ctx context.Context, database *model.Database) error {
 	_, err := s.db.Exec(ctx,
-		`INSERT INTO databases (id, tenant_id, subscription_id, shard_id, node_id, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		`INSERT INTO databases (id, tenant_id, subscription_id, shard_id, node_id, charset, collation, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
 		database.ID, database.TenantID, database.SubscriptionID, database.ShardID, database.NodeID,
-		database.Status, database.CreatedAt, database.UpdatedAt,
+		database.Charset, database.Collation, database.Status, database.CreatedAt, database.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("insert database: %w", err)
@@ -44,13 +45,13 @@ func (s *DatabaseService) GetByID(ctx context.Context, id string) (*model.Databa
 	var d model.Database
 	err := s.db.QueryRow(ctx,
 		`SELECT d.id, d.tenant_id, d.subscription_id, d.shard_id, d.node_id, d.status, d.status_message, d.suspend_reason, d.created_at, d.updated_at,
-		        s.name
+		        d.charset, d.collation, s.name
 		 FROM databases d
 		 LEFT JOIN shards s ON s.id = d.shard_id
 		 WHERE d.id = $1`, id,
 	).Scan(&d.ID, &d.TenantID, &d.SubscriptionID, &d.ShardID, &d.NodeID,
 		&d.Status, &d.StatusMessage, &d.SuspendReason, &d.CreatedAt, &d.UpdatedAt,
-		&d.ShardName)
+		&d.Charset, &d.Collation, &d.ShardName)
 	if err != nil {
 		return nil, fmt.Errorf("get database %s: %w", id, err)
 	}
@@ -58,7 +59,7 @@ func (s *DatabaseService) GetByID(ctx context.Context, id string) (*model.Databa
 }
 
 func (s *DatabaseService) ListByTenant(ctx context.Context, tenantID string, params request.ListParams) ([]model.Database, bool, error) {
-	query := `SELECT d.id, d.tenant_id, d.subscription_id, d.shard_id, d.node_id, d.status, d.status_message, d.suspend_reason, d.created_at, d.updated_at, s.name FROM databases d LEFT JOIN shards s ON s.id = d.shard_id WHERE d.tenant_id = $1`
+	query := `SELECT d.id, d.tenant_id, d.subscription_id, d.shard_id, d.node_id, d.status, d.status_message, d.suspend_reason, d.created_at, d.updated_at, d.charset, d.collation, s.name FROM databases d LEFT JOIN shards s ON s.id = d.shard_id WHERE d.tenant_id = $1`
 	args := []any{tenantID}
 	argIdx := 2
 
@@ -106,7 +107,7 @@ func (s *DatabaseService) ListByTenant(ctx context.Context, tenantID string, par
 		var d model.Database
 		if err := rows.Scan(&d.ID, &d.TenantID, &d.SubscriptionID, &d.ShardID, &d.NodeID,
 			&d.Status, &d.StatusMessage, &d.SuspendReason, &d.CreatedAt, &d.UpdatedAt,
-			&d.ShardName); err != nil {
+			&d.Charset, &d.Collation, &d.ShardName); err != nil {
 			return nil, false, fmt.Errorf("scan database: %w", err)
 		}
 		databases = append(databases, d)
@@ -123,7 +124,7 @@ func (s *DatabaseService) ListByTenant(ctx context.Context, tenantID string, par
 }
 
 func (s *DatabaseService) ListByShard(ctx context.Context, shardID string, limit int, cursor string) ([]model.Database, bool, error) {
-	query := `SELECT d.id, d.tenant_id, d.subscription_id, d.shard_id, d.node_id, d.status, d.status_message, d.suspend_reason, d.created_at, d.updated_at, s.name FROM databases d LEFT JOIN shards s ON s.id = d.shard_id WHERE d.shard_id = $1`
+	query := `SELECT d.id, d.tenant_id, d.subscription_id, d.shard_id, d.node_id, d.status, d.status_message, d.suspend_reason, d.created_at, d.updated_at, d.charset, d.collation, s.name FROM databases d LEFT JOIN shards s ON s.id = d.shard_id WHERE d.shard_id = $1`
 	args := []any{shardID}
 	argIdx := 2
 
@@ -148,7 +149,7 @@ func (s *DatabaseService) ListByShard(ctx context.Context, shardID string, limit
 		var d model.Database
 		if err := rows.Scan(&d.ID, &d.TenantID, &d.SubscriptionID, &d.ShardID, &d.NodeID,
 			&d.Status, &d.StatusMessage, &d.SuspendReason, &d.CreatedAt, &d.UpdatedAt,
-			&d.ShardName); err != nil {
+			&d.Charset, &d.Collation, &d.ShardName); err != nil {
 			return nil, false, fmt.Errorf("scan database: %w", err)
 		}
 		databases = append(databases, d)
@@ -244,3 +245,86 @@ func (s *DatabaseService) Retry(ctx context.Context, id string) error {
 		Arg:          id,
 	})
 }
+
+// connectionUsers returns the MySQL usernames of a database. Only connections
+// of these users are visible to, and killable by, the database's tenant.
+func (s *DatabaseService) connectionUsers(ctx context.Context, databaseID string) ([]string, error) {
+	rows, err := s.db.Query(ctx, "SELECT username FROM database_users WHERE database_id = $1 ORDER BY username", databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("list users for database %s: %w", databaseID, err)
+	}
+	defer rows.Close()
+
+	var users []string
+	for rows.Next() {
+		var u string
+		if err := rows.Scan(&u); err != nil {
+			return nil, fmt.Errorf("scan database user: %w", err)
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate database users: %w", err)
+	}
+	return users, nil
+}
+
+// databaseConnectionsArgs mirrors workflow.DatabaseConnectionsArgs.
+type databaseConnectionsArgs struct {
+	ShardID      string
+	Users        []string
+	ConnectionID int64
+}
+
+// ListConnections returns the live MySQL connections of the database's users
+// on the shard primary. It waits for ListDatabaseConnectionsWorkflow.
+func (s *DatabaseService) ListConnections(ctx context.Context, database *model.Database) ([]model.DatabaseConnection, error) {
+	if database.ShardID == nil {
+		return nil, fmt.Errorf("database %s has no shard assigned", database.ID)
+	}
+	users, err := s.connectionUsers(ctx, database.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return []model.DatabaseConnection{}, nil
+	}
+
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("list-db-connections", database.ID+"-"+platform.NewID()),
+		TaskQueue: taskQueue,
+	}, "ListDatabaseConnectionsWorkflow", databaseConnectionsArgs{ShardID: *database.ShardID, Users: users})
+	if err != nil {
+		return nil, fmt.Errorf("start ListDatabaseConnectionsWorkflow: %w", err)
+	}
+	conns := []model.DatabaseConnection{}
+	if err := run.Get(ctx, &conns); err != nil {
+		return nil, fmt.Errorf("list connections for database %s: %w", database.ID, err)
+	}
+	return conns, nil
+}
+
+// KillConnection kills one of the database's connections on the shard
+// primary. The node agent verifies that the connection belongs to one of the
+// database's users before killing it.
+func (s *DatabaseService) KillConnection(ctx context.Context, database *model.Database, connectionID int64) error {
+	if database.ShardID == nil {
+		return fmt.Errorf("database %s has no shard assigned", database.ID)
+	}
+	users, err := s.connectionUsers(ctx, database.ID)
+	if err != nil {
+		return err
+	}
+
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("kill-db-connection", fmt.Sprintf("%s-%d", database.ID, connectionID)),
+		TaskQueue: taskQueue,
+	}, "KillDatabaseConnectionWorkflow", databaseConnectionsArgs{ShardID: *database.ShardID, Users: users, ConnectionID: connectionID})
+	if err != nil {
+		return fmt.Errorf("start KillDatabaseConnectionWorkflow: %w", err)
+	}
+	if err := run.Get(ctx, nil); err != nil {
+		return fmt.Errorf("kill connection %d for database %s: %w", connectionID, database.ID, err)
+	}
+	return nil
+}
@@ -118,7 +118,9 @@ func TestDatabaseService_GetByID_Success(t *testing.T) {
 		*(dest[7].(*string)) = ""  // suspend_reason
 		*(dest[8].(*time.Time)) = now
 		*(dest[9].(*time.Time)) = now
-		*(dest[10].(**string)) = &shardName
+		*(dest[10].(*string)) = model.DefaultDatabaseCharset
+		*(dest[11].(*string)) = model.DefaultDatabaseCollation
+		*(dest[12].(**string)) = &shardName
 		return nil
 	}}
 	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
@@ -179,7 +181,9 @@ func TestDatabaseService_ListByTenant_Success(t *testing.T) {
 			*(dest[7].(*string)) = ""  // suspend_reason
 			*(dest[8].(*time.Time)) = now
 			*(dest[9].(*time.Time)) = now
-			*(dest[10].(**string)) = &shardName
+			*(dest[10].(*string)) = model.DefaultDatabaseCharset
+			*(dest[11].(*string)) = model.DefaultDatabaseCollation
+			*(dest[12].(**string)) = &shardName
 			return nil
 		},
 	)
@@ -235,7 +239,9 @@ func TestDatabaseService_ListByShard_Success(t *testing.T) {
 			*(dest[7].(*string)) = ""  // suspend_reason
 			*(dest[8].(*time.Time)) = now
 			*(dest[9].(*time.Time)) = now
-			*(dest[10].(**string)) = &shardName
+			*(dest[10].(*string)) = model.DefaultDatabaseCharset
+			*(dest[11].(*string)) = model.DefaultDatabaseCollation
+			*(dest[12].(**string)) = &shardName
 			return nil
 		},
 		func(dest ...any) error {
@@ -249,7 +255,9 @@ func TestDatabaseService_ListByShard_Success(t *testing.T) {
 			*(dest[7].(*string)) = ""  // suspend_reason
 			*(dest[8].(*time.Time)) = now
 			*(dest[9].(*time.Time)) = now
-			*(dest[10].(**string)) = &shardName
+			*(dest[10].(*string)) = model.DefaultDatabaseCharset
+			*(dest[11].(*string)) = model.DefaultDatabaseCollation
+			*(dest[12].(**string)) = &shardName
 			return nil
 		},
 	)
@@ -386,3 +394,88 @@ func TestDatabaseService_Delete_WorkflowError(t *testing.T) {
 	tc.AssertExpectations(t)
 }
 
+
+// ---------- Connections ----------
+
+func TestDatabaseService_ListConnections_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewDatabaseService(db, tc)
+	ctx := context.Background()
+
+	shardID := "test-shard-1"
+	database := &model.Database{ID: "test-database-1", ShardID: &shardID}
+
+	rows := newMockRows(
+		func(dest ...any) error {
+			*(dest[0].(*string)) = "app_rw"
+			return nil
+		},
+	)
+	db.On("Query", ctx, mock.AnythingOfType("string"), []any{"test-database-1"}).Return(rows, nil)
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*[]model.DatabaseConnection)) = []model.DatabaseConnection{{ID: 42, User: "app_rw", Command: "Query"}}
+	}).Return(nil)
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "ListDatabaseConnectionsWorkflow", databaseConnectionsArgs{ShardID: shardID, Users: []string{"app_rw"}}).Return(wfRun, nil)
+
+	conns, err := svc.ListConnections(ctx, database)
+	require.NoError(t, err)
+	require.Len(t, conns, 1)
+	assert.Equal(t, int64(42), conns[0].ID)
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestDatabaseService_ListConnections_NoUsers(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewDatabaseService(db, tc)
+	ctx := context.Background()
+
+	shardID := "test-shard-1"
+	database := &model.Database{ID: "test-database-1", ShardID: &shardID}
+
+	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(newMockRows(), nil)
+
+	conns, err := svc.ListConnections(ctx, database)
+	require.NoError(t, err)
+	assert.Empty(t, conns)
+	tc.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDatabaseService_KillConnection_WorkflowError(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewDatabaseService(db, tc)
+	ctx := context.Background()
+
+	shardID := "test-shard-1"
+	database := &model.Database{ID: "test-database-1", ShardID: &shardID}
+
+	rows := newMockRows(
+		func(dest ...any) error {
+			*(dest[0].(*string)) = "app_rw"
+			return nil
+		},
+	)
+	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, nil).Return(errors.New("connection 7 not found"))
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "KillDatabaseConnectionWorkflow", databaseConnectionsArgs{ShardID: shardID, Users: []string{"app_rw"}, ConnectionID: 7}).Return(wfRun, nil)
+
+	err := svc.KillConnection(ctx, database, 7)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kill connection 7")
+	tc.AssertExpectations(t)
+}
+
+func TestDatabaseService_KillConnection_NoShard(t *testing.T) {
+	svc := NewDatabaseService(&mockDB{}, &temporalmocks.Client{})
+
+	err := svc.KillConnection(context.Background(), &model.Database{ID: "test-database-1"}, 7)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no shard assigned")
+}
@@ -232,7 +232,7 @@ func (s *DesiredStateService) loadWebState(ctx context.Context, shardID string,
 
 		// 4. Batch-fetch all active FQDNs for those webroots.
 		fqdnRows, err := s.db.Query(ctx, `
-			SELECT webroot_id, fqdn, ssl_enabled, status
+			SELECT webroot_id, fqdn, ssl_enabled, force_https, redirect_target, redirect_code, redirect_preserve_path, status
 			FROM fqdns WHERE webroot_id = ANY($1) AND status = 'active'
 			ORDER BY fqdn`, webrootIDs)
 		if err != nil {
@@ -244,7 +244,7 @@ func (s *DesiredStateService) loadWebState(ctx context.Context, shardID string,
 		for fqdnRows.Next() {
 			var webrootID string
 			var f model.DesiredFQDN
-			if err := fqdnRows.Scan(&webrootID, &f.FQDN, &f.SSLEnabled, &f.Status); err != nil {
+			if err := fqdnRows.Scan(&webrootID, &f.FQDN, &f.SSLEnabled, &f.ForceHTTPS, &f.RedirectTarget, &f.RedirectCode, &f.RedirectPreservePath, &f.Status); err != nil {
 				return fmt.Errorf("scan fqdn: %w", err)
 			}
 			fqdnsByWebroot[webrootID] = append(fqdnsByWebroot[webrootID], f)
@@ -364,7 +364,7 @@ func (s *DesiredStateService) loadDatabaseState(ctx context.Context, shardID str
 
 func (s *DesiredStateService) loadValkeyState(ctx context.Context, shardID string, ss *model.ShardState) error {
 	rows, err := s.db.Query(ctx, `
-		SELECT id, port, password_hash, max_memory_mb, status
+		SELECT id, port, password_hash, max_memory_mb, persistence_mode, status
 		FROM valkey_instances WHERE shard_id = $1 AND status = 'active'
 		ORDER BY id`, shardID)
 	if err != nil {
@@ -374,7 +374,7 @@ func (s *DesiredStateService) loadValkeyState(ctx context.Context, shardID strin
 
 	for rows.Next() {
 		var vi model.DesiredValkeyInstance
-		if err := rows.Scan(&vi.ID, &vi.Port, &vi.PasswordHash, &vi.MaxMemoryMB, &vi.Status); err != nil {
+		if err := rows.Scan(&vi.ID, &vi.Port, &vi.PasswordHash, &vi.MaxMemoryMB, &vi.PersistenceMode, &vi.Status); err != nil {
 			return fmt.Errorf("scan valkey instance: %w", err)
 		}
 
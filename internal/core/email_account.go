@@ -2,12 +2,34 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
 	temporalclient "go.temporal.io/sdk/client"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/errgroup"
 )
 
+// ErrEmailLimitExceeded is returned when new email accounts would exceed the
+// FQDN's account or quota limit.
+var ErrEmailLimitExceeded = errors.New("email limit exceeded")
+
+// ErrEmailImportInvalid is returned with the per-row results when rows of an
+// import fail validation. Nothing is created.
+var ErrEmailImportInvalid = errors.New("email account import has invalid rows")
+
+// ErrSubscriptionTenantMismatch is returned when a subscription belongs to
+// a different tenant than the resource it is used for.
+var ErrSubscriptionTenantMismatch = errors.New("subscription belongs to a different tenant")
+
+// emailImportConcurrency caps how many accounts of an import are created,
+// and their workflows signalled, at once.
+const emailImportConcurrency = 5
+
 type EmailAccountService struct {
 	db DB
 	tc temporalclient.Client
@@ -18,10 +40,17 @@ func NewEmailAccountService(db DB, tc temporalclient.Client) *EmailAccountServic
 }
 
 func (s *EmailAccountService) Create(ctx context.Context, a *model.EmailAccount) error {
+	if err := checkEmailLimits(ctx, s.db, a.FQDNID, []int64{a.QuotaBytes}); err != nil {
+		return err
+	}
+	return s.create(ctx, a)
+}
+
+func (s *EmailAccountService) create(ctx context.Context, a *model.EmailAccount) error {
 	_, err := s.db.Exec(ctx,
-		`INSERT INTO email_accounts (id, fqdn_id, subscription_id, address, display_name, quota_bytes, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
-		a.ID, a.FQDNID, a.SubscriptionID, a.Address, a.DisplayName, a.QuotaBytes, a.Status, a.CreatedAt, a.UpdatedAt,
+		`INSERT INTO email_accounts (id, fqdn_id, subscription_id, address, display_name, quota_bytes, password_hash, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		a.ID, a.FQDNID, a.SubscriptionID, a.Address, a.DisplayName, a.QuotaBytes, a.PasswordHash, a.Status, a.CreatedAt, a.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("insert email account: %w", err)
@@ -42,6 +71,191 @@ func (s *EmailAccountService) Create(ctx context.Context, a *model.EmailAccount)
 	return nil
 }
 
+// Import creates email accounts under an FQDN from parsed import rows. All
+// rows are validated first: if any is invalid, or the accounts would exceed
+// the FQDN's email limits, nothing is created. Otherwise each account is
+// created and its CreateEmailAccountWorkflow signalled, a few at a time,
+// and the outcome reported per row. A dry run stops after validation.
+func (s *EmailAccountService) Import(ctx context.Context, fqdnID, subscriptionID string, rows []model.EmailAccountImportRow, dryRun bool) (*model.EmailAccountImport, error) {
+	var fqdnName string
+	var sameTenant *bool
+	err := s.db.QueryRow(ctx,
+		`SELECT f.fqdn, f.tenant_id = sub.tenant_id
+		 FROM fqdns f LEFT JOIN subscriptions sub ON sub.id = $2
+		 WHERE f.id = $1`, fqdnID, subscriptionID,
+	).Scan(&fqdnName, &sameTenant)
+	if err != nil {
+		return nil, fmt.Errorf("get fqdn %s: %w", fqdnID, err)
+	}
+	if sameTenant == nil {
+		return nil, fmt.Errorf("subscription %s not found", subscriptionID)
+	}
+	if !*sameTenant {
+		return nil, ErrSubscriptionTenantMismatch
+	}
+
+	addresses := make([]string, len(rows))
+	for i, row := range rows {
+		addresses[i] = row.Address
+	}
+	taken, err := s.existingAddresses(ctx, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.EmailAccountImport{DryRun: dryRun, Rows: make([]model.EmailAccountImportResult, len(rows))}
+	seen := make(map[string]int, len(rows))
+	quotas := make([]int64, len(rows))
+	invalid := false
+	for i, row := range rows {
+		res := model.EmailAccountImportResult{Line: row.Line, Address: row.Address, Status: model.EmailImportValid}
+		_, domain, _ := strings.Cut(row.Address, "@")
+		switch {
+		case row.Error != "":
+			res.Error = row.Error
+		case !strings.EqualFold(domain, fqdnName):
+			res.Error = fmt.Sprintf("address is not in domain %s", fqdnName)
+		case seen[row.Address] != 0:
+			res.Error = fmt.Sprintf("duplicate of line %d", seen[row.Address])
+		case taken[row.Address]:
+			res.Error = "address already exists"
+		}
+		if res.Error != "" {
+			res.Status = model.EmailImportInvalid
+			invalid = true
+		} else {
+			seen[row.Address] = row.Line
+		}
+		quotas[i] = row.QuotaBytes
+		result.Rows[i] = res
+	}
+	if invalid {
+		return result, ErrEmailImportInvalid
+	}
+	if err := checkEmailLimits(ctx, s.db, fqdnID, quotas); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	var g errgroup.Group
+	g.SetLimit(emailImportConcurrency)
+	for i, row := range rows {
+		g.Go(func() error {
+			res := &result.Rows[i]
+			id, err := s.importAccount(ctx, fqdnID, subscriptionID, row)
+			if err != nil {
+				res.Status, res.Error = model.EmailImportFailed, err.Error()
+				return nil
+			}
+			res.Status, res.ID = model.EmailImportCreated, id
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	for _, res := range result.Rows {
+		if res.Status == model.EmailImportCreated {
+			result.Created++
+		} else {
+			result.Failed++
+		}
+	}
+	return result, nil
+}
+
+// importAccount creates one account of an import, hashing its initial
+// password for Stalwart.
+func (s *EmailAccountService) importAccount(ctx context.Context, fqdnID, subscriptionID string, row model.EmailAccountImportRow) (string, error) {
+	now := time.Now()
+	a := &model.EmailAccount{
+		ID:             platform.NewID(),
+		FQDNID:         fqdnID,
+		SubscriptionID: subscriptionID,
+		Address:        row.Address,
+		DisplayName:    row.DisplayName,
+		QuotaBytes:     row.QuotaBytes,
+		Status:         model.StatusPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if row.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(row.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", fmt.Errorf("hash password: %w", err)
+		}
+		a.PasswordHash = string(hash)
+	}
+	if err := s.create(ctx, a); err != nil {
+		return "", err
+	}
+	return a.ID, nil
+}
+
+// existingAddresses returns which of the given addresses are already used by
+// an email account. Addresses stay reserved by deleted accounts too.
+func (s *EmailAccountService) existingAddresses(ctx context.Context, addresses []string) (map[string]bool, error) {
+	rows, err := s.db.Query(ctx, `SELECT address FROM email_accounts WHERE address = ANY($1)`, addresses)
+	if err != nil {
+		return nil, fmt.Errorf("check existing email addresses: %w", err)
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var address string
+		if err := rows.Scan(&address); err != nil {
+			return nil, fmt.Errorf("scan email address: %w", err)
+		}
+		taken[address] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate email addresses: %w", err)
+	}
+	return taken, nil
+}
+
+// checkEmailLimits returns ErrEmailLimitExceeded if adding accounts with the
+// given quotas to an FQDN would exceed its account or quota limit. Under a
+// quota limit every account needs a quota; an unlimited mailbox would escape
+// it.
+func checkEmailLimits(ctx context.Context, db DB, fqdnID string, quotas []int64) error {
+	var maxAccounts *int
+	var maxQuota *int64
+	var count int
+	var used int64
+	err := db.QueryRow(ctx,
+		`SELECT f.max_email_accounts, f.email_quota_bytes, COUNT(ea.id), COALESCE(SUM(ea.quota_bytes), 0)
+		 FROM fqdns f LEFT JOIN email_accounts ea ON ea.fqdn_id = f.id AND ea.status <> $2
+		 WHERE f.id = $1 GROUP BY f.id`, fqdnID, model.StatusDeleted,
+	).Scan(&maxAccounts, &maxQuota, &count, &used)
+	if err != nil {
+		return fmt.Errorf("get email usage for fqdn %s: %w", fqdnID, err)
+	}
+
+	if maxAccounts != nil && count+len(quotas) > *maxAccounts {
+		return fmt.Errorf("%w: fqdn allows %d accounts and has %d, cannot add %d",
+			ErrEmailLimitExceeded, *maxAccounts, count, len(quotas))
+	}
+	if maxQuota == nil {
+		return nil
+	}
+	var added int64
+	for _, q := range quotas {
+		if q == 0 {
+			return fmt.Errorf("%w: fqdn has an email quota of %d bytes, so every account needs a quota",
+				ErrEmailLimitExceeded, *maxQuota)
+		}
+		added += q
+	}
+	if used+added > *maxQuota {
+		return fmt.Errorf("%w: fqdn email quota is %d bytes with %d allocated, cannot add %d",
+			ErrEmailLimitExceeded, *maxQuota, used, added)
+	}
+	return nil
+}
+
 func (s *EmailAccountService) GetByID(ctx context.Context, id string) (*model.EmailAccount, error) {
 	var a model.EmailAccount
 	err := s.db.QueryRow(ctx,
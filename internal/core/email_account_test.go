@@ -3,14 +3,17 @@ package core
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	temporalmocks "go.temporal.io/sdk/mocks"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestNewEmailAccountService(t *testing.T) {
@@ -137,3 +140,153 @@ func TestEmailAccountService_ListByFQDN_Empty(t *testing.T) {
 	assert.Empty(t, result)
 	db.AssertExpectations(t)
 }
+
+// ---------- Import ----------
+
+func sqlContaining(s string) any {
+	return mock.MatchedBy(func(q string) bool { return strings.Contains(q, s) })
+}
+
+// mockEmailImportFQDN mocks the FQDN lookup, existing addresses and email
+// usage queries of an import.
+func mockEmailImportFQDN(db *mockDB, sameTenant bool, existing []string, maxAccounts *int, maxQuota *int64, count int, used int64) {
+	db.On("QueryRow", mock.Anything, sqlContaining("LEFT JOIN subscriptions"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "example.com"
+		*(dest[1].(**bool)) = &sameTenant
+		return nil
+	}})
+	var scans []func(dest ...any) error
+	for _, addr := range existing {
+		scans = append(scans, func(dest ...any) error {
+			*(dest[0].(*string)) = addr
+			return nil
+		})
+	}
+	db.On("Query", mock.Anything, sqlContaining("address = ANY"), mock.Anything).Return(newMockRows(scans...), nil).Maybe()
+	db.On("QueryRow", mock.Anything, sqlContaining("max_email_accounts"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(**int)) = maxAccounts
+		*(dest[1].(**int64)) = maxQuota
+		*(dest[2].(*int)) = count
+		*(dest[3].(*int64)) = used
+		return nil
+	}}).Maybe()
+}
+
+func TestEmailAccountService_Import_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewEmailAccountService(db, tc)
+	ctx := context.Background()
+
+	mockEmailImportFQDN(db, true, nil, nil, nil, 0, 0)
+	db.On("QueryRow", mock.Anything, sqlContaining("SELECT tenant_id FROM fqdns"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "tenant-1"
+		return nil
+	}})
+	db.On("Exec", mock.Anything, sqlContaining("INSERT INTO email_accounts"), mock.MatchedBy(func(args []any) bool {
+		hash := args[6].(string)
+		if args[3] == "alice@example.com" {
+			return bcrypt.CompareHashAndPassword([]byte(hash), []byte("s3cret-pass")) == nil
+		}
+		return hash == ""
+	})).Return(pgconn.CommandTag{}, nil).Twice()
+	db.On("Exec", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id")
+	wfRun.On("GetRunID").Return("mock-run-id")
+	tc.On("SignalWithStartWorkflow", mock.Anything, "tenant-tenant-1", model.ProvisionSignalName,
+		mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil).Twice()
+
+	result, err := svc.Import(ctx, "fqdn-1", "sub-1", []model.EmailAccountImportRow{
+		{Line: 1, Address: "alice@example.com", DisplayName: "Alice", Password: "s3cret-pass"},
+		{Line: 2, Address: "bob@example.com", DisplayName: "Bob"},
+	}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 0, result.Failed)
+	for _, row := range result.Rows {
+		assert.Equal(t, model.EmailImportCreated, row.Status)
+		assert.NotEmpty(t, row.ID)
+	}
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestEmailAccountService_Import_InvalidRows(t *testing.T) {
+	db := &mockDB{}
+	svc := NewEmailAccountService(db, &temporalmocks.Client{})
+
+	mockEmailImportFQDN(db, true, []string{"taken@example.com"}, nil, nil, 0, 0)
+
+	result, err := svc.Import(context.Background(), "fqdn-1", "sub-1", []model.EmailAccountImportRow{
+		{Line: 1, Address: "alice@example.com"},
+		{Line: 2, Address: "alice@example.com"},
+		{Line: 3, Address: "bob@other.com"},
+		{Line: 4, Address: "taken@example.com"},
+		{Line: 5, Error: "invalid quota"},
+	}, false)
+	require.ErrorIs(t, err, ErrEmailImportInvalid)
+	require.Len(t, result.Rows, 5)
+	assert.Equal(t, model.EmailImportValid, result.Rows[0].Status)
+	assert.Equal(t, "duplicate of line 1", result.Rows[1].Error)
+	assert.Contains(t, result.Rows[2].Error, "not in domain example.com")
+	assert.Equal(t, "address already exists", result.Rows[3].Error)
+	assert.Equal(t, "invalid quota", result.Rows[4].Error)
+	for _, row := range result.Rows[1:] {
+		assert.Equal(t, model.EmailImportInvalid, row.Status)
+	}
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEmailAccountService_Import_Limits(t *testing.T) {
+	maxAccounts := 2
+	maxQuota := int64(1000)
+	rows := []model.EmailAccountImportRow{
+		{Line: 1, Address: "alice@example.com", QuotaBytes: 400},
+		{Line: 2, Address: "bob@example.com", QuotaBytes: 400},
+	}
+
+	tests := []struct {
+		name        string
+		maxAccounts *int
+		maxQuota    *int64
+		count       int
+		used        int64
+		rows        []model.EmailAccountImportRow
+		wantErr     string
+	}{
+		{"within limits", &maxAccounts, &maxQuota, 0, 100, rows, ""},
+		{"too many accounts", &maxAccounts, nil, 1, 0, rows, "allows 2 accounts"},
+		{"quota exceeded", nil, &maxQuota, 1, 300, rows, "cannot add 800"},
+		{"unlimited mailbox", nil, &maxQuota, 0, 0, []model.EmailAccountImportRow{{Line: 1, Address: "alice@example.com"}}, "needs a quota"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			svc := NewEmailAccountService(db, &temporalmocks.Client{})
+			mockEmailImportFQDN(db, true, nil, tt.maxAccounts, tt.maxQuota, tt.count, tt.used)
+
+			result, err := svc.Import(context.Background(), "fqdn-1", "sub-1", tt.rows, true)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.True(t, result.DryRun)
+				assert.Equal(t, model.EmailImportValid, result.Rows[0].Status)
+			} else {
+				require.ErrorIs(t, err, ErrEmailLimitExceeded)
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+			db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestEmailAccountService_Import_SubscriptionOfOtherTenant(t *testing.T) {
+	db := &mockDB{}
+	svc := NewEmailAccountService(db, &temporalmocks.Client{})
+	mockEmailImportFQDN(db, false, nil, nil, nil, 0, 0)
+
+	_, err := svc.Import(context.Background(), "fqdn-1", "sub-2", []model.EmailAccountImportRow{
+		{Line: 1, Address: "alice@example.com"},
+	}, false)
+	require.ErrorIs(t, err, ErrSubscriptionTenantMismatch)
+}
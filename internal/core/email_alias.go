@@ -2,7 +2,9 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/edvin/hosting/internal/model"
 	temporalclient "go.temporal.io/sdk/client"
@@ -17,7 +19,32 @@ func NewEmailAliasService(db DB, tc temporalclient.Client) *EmailAliasService {
 	return &EmailAliasService{db: db, tc: tc}
 }
 
+// ErrCatchAllExists is returned by Create when the domain already has a
+// catch-all alias.
+var ErrCatchAllExists = errors.New("domain already has a catch-all alias")
+
+// Create records an alias and provisions it in Stalwart. For a catch-all
+// alias the address is derived from the account's domain.
 func (s *EmailAliasService) Create(ctx context.Context, a *model.EmailAlias) error {
+	if a.CatchAll {
+		var accountAddress string
+		err := s.db.QueryRow(ctx, "SELECT address FROM email_accounts WHERE id = $1", a.EmailAccountID).Scan(&accountAddress)
+		if err != nil {
+			return fmt.Errorf("get email account %s: %w", a.EmailAccountID, err)
+		}
+		_, domain, _ := strings.Cut(accountAddress, "@")
+		a.Address = model.CatchAllAddress(domain)
+
+		var exists bool
+		err = s.db.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM email_aliases WHERE address = $1)", a.Address).Scan(&exists)
+		if err != nil {
+			return fmt.Errorf("check catch-all for %s: %w", domain, err)
+		}
+		if exists {
+			return ErrCatchAllExists
+		}
+	}
+
 	_, err := s.db.Exec(ctx,
 		`INSERT INTO email_aliases (id, email_account_id, address, status, created_at, updated_at)
 		 VALUES ($1, $2, $3, $4, $5, $6)`,
@@ -51,6 +78,7 @@ func (s *EmailAliasService) GetByID(ctx context.Context, id string) (*model.Emai
 	if err != nil {
 		return nil, fmt.Errorf("get email alias %s: %w", id, err)
 	}
+	a.CatchAll = model.IsCatchAllAddress(a.Address)
 	return &a, nil
 }
 
@@ -81,6 +109,7 @@ func (s *EmailAliasService) ListByAccountID(ctx context.Context, accountID strin
 		if err := rows.Scan(&a.ID, &a.EmailAccountID, &a.Address, &a.Status, &a.StatusMessage, &a.CreatedAt, &a.UpdatedAt); err != nil {
 			return nil, false, fmt.Errorf("scan email alias: %w", err)
 		}
+		a.CatchAll = model.IsCatchAllAddress(a.Address)
 		aliases = append(aliases, a)
 	}
 	if err := rows.Err(); err != nil {
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func TestEmailAliasService_Create_CatchAllDerivesAddress(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewEmailAliasService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, "SELECT address FROM email_accounts WHERE id = $1", []any{"acct-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "info@example.com"
+		return nil
+	}})
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"@example.com"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*bool)) = false
+		return nil
+	}})
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, errors.New("insert failed"))
+
+	alias := &model.EmailAlias{ID: "alias-1", EmailAccountID: "acct-1", CatchAll: true}
+	err := svc.Create(ctx, alias)
+	require.Error(t, err)
+	assert.Equal(t, "@example.com", alias.Address)
+	db.AssertExpectations(t)
+}
+
+func TestEmailAliasService_Create_CatchAllExists(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewEmailAliasService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, "SELECT address FROM email_accounts WHERE id = $1", []any{"acct-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "info@example.com"
+		return nil
+	}})
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"@example.com"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*bool)) = true
+		return nil
+	}})
+
+	err := svc.Create(ctx, &model.EmailAlias{ID: "alias-1", EmailAccountID: "acct-1", CatchAll: true})
+	assert.ErrorIs(t, err, ErrCatchAllExists)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEmailAliasService_GetByID_CatchAll(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewEmailAliasService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"alias-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "alias-1"
+		*(dest[2].(*string)) = "@example.com"
+		return nil
+	}})
+
+	alias, err := svc.GetByID(ctx, "alias-1")
+	require.NoError(t, err)
+	assert.True(t, alias.CatchAll)
+}
@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	temporalclient "go.temporal.io/sdk/client"
+)
+
+// EmailDKIMService manages per-FQDN DKIM keys. Mail domains without one
+// sign with the brand's DKIM key.
+type EmailDKIMService struct {
+	db DB
+	tc temporalclient.Client
+}
+
+func NewEmailDKIMService(db DB, tc temporalclient.Client) *EmailDKIMService {
+	return &EmailDKIMService{db: db, tc: tc}
+}
+
+// Provision stores a new DKIM key for the FQDN, replacing any existing one,
+// and starts the ProvisionEmailDKIMWorkflow to install it in Stalwart and
+// publish it in the FQDN's zone.
+func (s *EmailDKIMService) Provision(ctx context.Context, key *model.EmailDKIMKey) error {
+	err := s.db.QueryRow(ctx,
+		`INSERT INTO fqdn_dkim_keys (id, fqdn_id, selector, private_key_pem, public_key, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (fqdn_id) DO UPDATE SET
+		   selector = EXCLUDED.selector,
+		   private_key_pem = EXCLUDED.private_key_pem,
+		   public_key = EXCLUDED.public_key,
+		   status = EXCLUDED.status,
+		   status_message = NULL,
+		   updated_at = EXCLUDED.updated_at
+		 RETURNING id, created_at`,
+		key.ID, key.FQDNID, key.Selector, key.PrivateKeyPEM, key.PublicKey, key.Status, key.CreatedAt, key.UpdatedAt,
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("upsert dkim key: %w", err)
+	}
+
+	tenantID, err := resolveTenantIDFromFQDN(ctx, s.db, key.FQDNID)
+	if err != nil {
+		return fmt.Errorf("resolve tenant for dkim key: %w", err)
+	}
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "ProvisionEmailDKIMWorkflow",
+		WorkflowID:   workflowID("email-dkim", key.FQDNID),
+		Arg:          key.FQDNID,
+	}); err != nil {
+		return fmt.Errorf("signal ProvisionEmailDKIMWorkflow: %w", err)
+	}
+
+	return nil
+}
+
+// GetByFQDN returns the FQDN's DKIM key. The private key is not loaded.
+func (s *EmailDKIMService) GetByFQDN(ctx context.Context, fqdnID string) (*model.EmailDKIMKey, error) {
+	var k model.EmailDKIMKey
+	err := s.db.QueryRow(ctx,
+		`SELECT id, fqdn_id, selector, public_key, status, status_message, created_at, updated_at
+		 FROM fqdn_dkim_keys WHERE fqdn_id = $1`, fqdnID,
+	).Scan(&k.ID, &k.FQDNID, &k.Selector, &k.PublicKey, &k.Status, &k.StatusMessage, &k.CreatedAt, &k.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get dkim key for fqdn %s: %w", fqdnID, err)
+	}
+	return &k, nil
+}
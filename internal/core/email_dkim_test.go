@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func TestEmailDKIMService_Provision_UpsertFails(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewEmailDKIMService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		return errors.New("db down")
+	}})
+
+	err := svc.Provision(ctx, &model.EmailDKIMKey{ID: "key-1", FQDNID: "fqdn-1", Selector: "s202610"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "upsert dkim key")
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEmailDKIMService_GetByFQDN(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewEmailDKIMService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"fqdn-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "key-1"
+		*(dest[1].(*string)) = "fqdn-1"
+		*(dest[2].(*string)) = "s202610"
+		*(dest[3].(*string)) = "MIIBIj"
+		*(dest[4].(*string)) = model.StatusActive
+		return nil
+	}})
+
+	key, err := svc.GetByFQDN(ctx, "fqdn-1")
+	require.NoError(t, err)
+	assert.Equal(t, "s202610", key.Selector)
+	assert.Empty(t, key.PrivateKeyPEM)
+}
+
+func TestEmailDKIMService_GetByFQDN_NotFound(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewEmailDKIMService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"fqdn-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		return pgx.ErrNoRows
+	}})
+
+	_, err := svc.GetByFQDN(ctx, "fqdn-1")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
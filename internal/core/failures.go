@@ -0,0 +1,193 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ErrUnknownResourceType is returned by ListFailures for a resource_type
+// filter that is not one of FailureResourceTypes.
+var ErrUnknownResourceType = errors.New("unknown resource type")
+
+// ErrInvalidCursor is returned by ListFailures for a cursor it did not issue.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ResourceFailure is a resource left in failed status by its workflow.
+type ResourceFailure struct {
+	ResourceType  string    `json:"resource_type"`
+	ResourceID    string    `json:"resource_id"`
+	TenantID      *string   `json:"tenant_id,omitempty"`
+	StatusMessage *string   `json:"status_message,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// FailureListParams filters and pages ListFailures. Order is "asc" or "desc"
+// by updated_at; Cursor is the NextCursor of the previous page.
+type FailureListParams struct {
+	ResourceType string
+	TenantID     string
+	Search       string
+	Order        string
+	Limit        int
+	Cursor       string
+}
+
+// failureSource selects the failed rows of one resource table as
+// (resource_type, resource_id, tenant_id, status_message, updated_at).
+type failureSource struct {
+	resourceType string
+	from         string
+	tenantExpr   string
+}
+
+var failureSources = []failureSource{
+	{"tenant", "tenants r", "r.id"},
+	{"webroot", "webroots r", "r.tenant_id"},
+	{"webroot_release", "webroot_releases r", "r.tenant_id"},
+	{"fqdn", "fqdns r", "r.tenant_id"},
+	{"fqdn_dkim_key", "fqdn_dkim_keys r JOIN fqdns f ON f.id = r.fqdn_id", "f.tenant_id"},
+	{"certificate", "certificates r JOIN fqdns f ON f.id = r.fqdn_id", "f.tenant_id"},
+	{"zone", "zones r", "r.tenant_id"},
+	{"zone_record", "zone_records r JOIN zones z ON z.id = r.zone_id", "z.tenant_id"},
+	{"database", "databases r", "r.tenant_id"},
+	{"database_user", "database_users r JOIN databases d ON d.id = r.database_id", "d.tenant_id"},
+	{"valkey_instance", "valkey_instances r", "r.tenant_id"},
+	{"valkey_user", "valkey_users r JOIN valkey_instances vi ON vi.id = r.valkey_instance_id", "vi.tenant_id"},
+	{"email_account", "email_accounts r JOIN fqdns f ON f.id = r.fqdn_id", "f.tenant_id"},
+	{"email_alias", "email_aliases r JOIN email_accounts ea ON ea.id = r.email_account_id JOIN fqdns f ON f.id = ea.fqdn_id", "f.tenant_id"},
+	{"email_forward", "email_forwards r JOIN email_accounts ea ON ea.id = r.email_account_id JOIN fqdns f ON f.id = ea.fqdn_id", "f.tenant_id"},
+	{"email_autoreply", "email_autoreplies r JOIN email_accounts ea ON ea.id = r.email_account_id JOIN fqdns f ON f.id = ea.fqdn_id", "f.tenant_id"},
+	{"smtp_relay_user", "smtp_relay_users r", "r.tenant_id"},
+	{"s3_bucket", "s3_buckets r", "r.tenant_id"},
+	{"s3_access_key", "s3_access_keys r JOIN s3_buckets b ON b.id = r.s3_bucket_id", "b.tenant_id"},
+	{"ssh_key", "ssh_keys r", "r.tenant_id"},
+	{"backup", "backups r", "r.tenant_id"},
+	{"tenant_export", "tenant_exports r", "r.tenant_id"},
+	{"cron_job", "cron_jobs r", "r.tenant_id"},
+	{"daemon", "daemons r", "r.tenant_id"},
+	{"egress_rule", "tenant_egress_rules r", "r.tenant_id"},
+	{"wireguard_peer", "wireguard_peers r", "r.tenant_id"},
+	{"shard", "shards r", "NULL::text"},
+}
+
+// FailureResourceTypes lists the resource types ListFailures covers.
+var FailureResourceTypes = func() []string {
+	types := make([]string, len(failureSources))
+	for i, src := range failureSources {
+		types[i] = src.resourceType
+	}
+	return types
+}()
+
+// failuresQuery is the UNION ALL of the failed rows of every resource table.
+var failuresQuery = func() string {
+	parts := make([]string, len(failureSources))
+	for i, src := range failureSources {
+		parts[i] = fmt.Sprintf(`SELECT '%s' AS resource_type, r.id AS resource_id, %s AS tenant_id, r.status_message, r.updated_at FROM %s WHERE r.status = '%s'`,
+			src.resourceType, src.tenantExpr, src.from, model.StatusFailed)
+	}
+	return strings.Join(parts, "\n\t\tUNION ALL\n\t\t")
+}()
+
+// ListFailures returns resources in failed status across all resource tables
+// in a single query, most recently updated first unless Order is "asc".
+func (s *DashboardService) ListFailures(ctx context.Context, params FailureListParams) ([]ResourceFailure, bool, error) {
+	query := `SELECT resource_type, resource_id, tenant_id, status_message, updated_at FROM (
+		` + failuresQuery + `
+	) f WHERE true`
+	args := []any{}
+	argIdx := 1
+
+	if params.ResourceType != "" {
+		known := false
+		for _, t := range FailureResourceTypes {
+			known = known || t == params.ResourceType
+		}
+		if !known {
+			return nil, false, ErrUnknownResourceType
+		}
+		query += fmt.Sprintf(` AND resource_type = $%d`, argIdx)
+		args = append(args, params.ResourceType)
+		argIdx++
+	}
+	if params.TenantID != "" {
+		query += fmt.Sprintf(` AND tenant_id = $%d`, argIdx)
+		args = append(args, params.TenantID)
+		argIdx++
+	}
+	if params.Search != "" {
+		query += fmt.Sprintf(` AND (resource_id ILIKE $%d OR status_message ILIKE $%d)`, argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+
+	cmp, order := "<", "DESC"
+	if params.Order == "asc" {
+		cmp, order = ">", "ASC"
+	}
+	if params.Cursor != "" {
+		c, err := decodeFailureCursor(params.Cursor)
+		if err != nil {
+			return nil, false, err
+		}
+		query += fmt.Sprintf(` AND (updated_at, resource_type, resource_id) %s ($%d, $%d, $%d)`, cmp, argIdx, argIdx+1, argIdx+2)
+		args = append(args, c.UpdatedAt, c.ResourceType, c.ResourceID)
+		argIdx += 3
+	}
+
+	query += fmt.Sprintf(` ORDER BY updated_at %[1]s, resource_type %[1]s, resource_id %[1]s`, order)
+	query += fmt.Sprintf(` LIMIT $%d`, argIdx)
+	args = append(args, params.Limit+1)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("list failures: %w", err)
+	}
+	defer rows.Close()
+
+	var failures []ResourceFailure
+	for rows.Next() {
+		var f ResourceFailure
+		if err := rows.Scan(&f.ResourceType, &f.ResourceID, &f.TenantID, &f.StatusMessage, &f.UpdatedAt); err != nil {
+			return nil, false, fmt.Errorf("scan failure: %w", err)
+		}
+		failures = append(failures, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("iterate failures: %w", err)
+	}
+
+	hasMore := len(failures) > params.Limit
+	if hasMore {
+		failures = failures[:params.Limit]
+	}
+	return failures, hasMore, nil
+}
+
+// FailureCursor returns the cursor for the page after f.
+func FailureCursor(f ResourceFailure) string {
+	raw := f.UpdatedAt.UTC().Format(time.RFC3339Nano) + "|" + f.ResourceType + "|" + f.ResourceID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeFailureCursor(cursor string) (ResourceFailure, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ResourceFailure{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return ResourceFailure{}, ErrInvalidCursor
+	}
+	updatedAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return ResourceFailure{}, ErrInvalidCursor
+	}
+	return ResourceFailure{UpdatedAt: updatedAt, ResourceType: parts[1], ResourceID: parts[2]}, nil
+}
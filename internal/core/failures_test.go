@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func failureScan(resourceType, id string, updatedAt time.Time) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = resourceType
+		*(dest[1].(*string)) = id
+		tenantID := "test-tenant-1"
+		*(dest[2].(**string)) = &tenantID
+		msg := "boom"
+		*(dest[3].(**string)) = &msg
+		*(dest[4].(*time.Time)) = updatedAt
+		return nil
+	}
+}
+
+func TestFailuresQuery_CoversAllSources(t *testing.T) {
+	assert.Equal(t, len(failureSources)-1, strings.Count(failuresQuery, "UNION ALL"))
+	for _, src := range failureSources {
+		assert.Contains(t, failuresQuery, "'"+src.resourceType+"' AS resource_type")
+	}
+}
+
+func TestDashboardService_ListFailures_Success(t *testing.T) {
+	db := &mockDB{}
+	svc := NewDashboardService(db)
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	rows := newMockRows(
+		failureScan("webroot", "wr-1", now),
+		failureScan("fqdn", "fq-1", now.Add(-time.Minute)),
+		failureScan("database", "db-1", now.Add(-2*time.Minute)),
+	)
+	db.On("Query", ctx, mock.MatchedBy(func(q string) bool {
+		return strings.Contains(q, "UNION ALL") &&
+			strings.Contains(q, "resource_type = $1") &&
+			strings.Contains(q, "tenant_id = $2") &&
+			strings.Contains(q, "ORDER BY updated_at DESC")
+	}), []any{"webroot", "test-tenant-1", 3}).Return(rows, nil)
+
+	failures, hasMore, err := svc.ListFailures(ctx, FailureListParams{
+		ResourceType: "webroot",
+		TenantID:     "test-tenant-1",
+		Limit:        2,
+	})
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	require.Len(t, failures, 2)
+	assert.Equal(t, "wr-1", failures[0].ResourceID)
+	assert.Equal(t, "test-tenant-1", *failures[0].TenantID)
+	db.AssertExpectations(t)
+}
+
+func TestDashboardService_ListFailures_Cursor(t *testing.T) {
+	db := &mockDB{}
+	svc := NewDashboardService(db)
+	ctx := context.Background()
+	updatedAt := time.Date(2026, 3, 1, 12, 0, 0, 123, time.UTC)
+
+	cursor := FailureCursor(ResourceFailure{ResourceType: "fqdn", ResourceID: "fq-1", UpdatedAt: updatedAt})
+	db.On("Query", ctx, mock.MatchedBy(func(q string) bool {
+		return strings.Contains(q, "(updated_at, resource_type, resource_id) > ($1, $2, $3)") &&
+			strings.Contains(q, "ORDER BY updated_at ASC")
+	}), []any{updatedAt, "fqdn", "fq-1", 51}).Return(newEmptyMockRows(), nil)
+
+	failures, hasMore, err := svc.ListFailures(ctx, FailureListParams{Order: "asc", Limit: 50, Cursor: cursor})
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.Empty(t, failures)
+	db.AssertExpectations(t)
+}
+
+func TestDashboardService_ListFailures_InvalidParams(t *testing.T) {
+	svc := NewDashboardService(&mockDB{})
+	ctx := context.Background()
+
+	_, _, err := svc.ListFailures(ctx, FailureListParams{ResourceType: "widget", Limit: 50})
+	assert.ErrorIs(t, err, ErrUnknownResourceType)
+
+	_, _, err = svc.ListFailures(ctx, FailureListParams{Cursor: "not-a-cursor", Limit: 50})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
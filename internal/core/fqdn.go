@@ -3,25 +3,40 @@ package core
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/edvin/hosting/internal/dnscheck"
 	"github.com/edvin/hosting/internal/model"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
+// dnsChecker resolves an FQDN and compares it with expected addresses.
+type dnsChecker interface {
+	Check(ctx context.Context, name string, expected []string) (*model.FQDNDNSCheck, error)
+	MX(ctx context.Context, name string) ([]string, error)
+}
+
+// warningTimeout bounds the DNS lookups behind create warnings, which run
+// while the API request waits.
+const warningTimeout = 2 * time.Second
+
 type FQDNService struct {
-	db DB
-	tc temporalclient.Client
+	db  DB
+	tc  temporalclient.Client
+	dns dnsChecker
 }
 
 func NewFQDNService(db DB, tc temporalclient.Client) *FQDNService {
-	return &FQDNService{db: db, tc: tc}
+	return &FQDNService{db: db, tc: tc, dns: dnscheck.New(dnscheck.DefaultResolvers)}
 }
 
 func (s *FQDNService) Create(ctx context.Context, fqdn *model.FQDN) error {
 	_, err := s.db.Exec(ctx,
-		`INSERT INTO fqdns (id, tenant_id, fqdn, webroot_id, ssl_enabled, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
-		fqdn.ID, fqdn.TenantID, fqdn.FQDN, fqdn.WebrootID, fqdn.SSLEnabled, fqdn.Status,
+		`INSERT INTO fqdns (id, tenant_id, fqdn, webroot_id, ssl_enabled, force_https, redirect_target, redirect_code, redirect_preserve_path, ip_mode, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+		fqdn.ID, fqdn.TenantID, fqdn.FQDN, fqdn.WebrootID, fqdn.SSLEnabled, fqdn.ForceHTTPS,
+		fqdn.RedirectTarget, fqdn.RedirectCode, fqdn.RedirectPreservePath, fqdn.IPMode, fqdn.Status,
 		fqdn.CreatedAt, fqdn.UpdatedAt,
 	)
 	if err != nil {
@@ -39,21 +54,168 @@ func (s *FQDNService) Create(ctx context.Context, fqdn *model.FQDN) error {
 	return nil
 }
 
+// ValidateBinding checks that binding name to webrootID is allowed. Wildcard
+// FQDNs must fall within a zone owned by the tenant, since their certificates
+// are issued via DNS-01 against that zone. On a single webroot, a wildcard
+// and a more specific FQDN it covers cannot both be bound, as they would
+// produce overlapping server_name entries with competing certificates.
+// excludeID skips an existing binding (used when re-pointing an FQDN).
+func (s *FQDNService) ValidateBinding(ctx context.Context, tenantID, name string, webrootID *string, excludeID string) error {
+	if model.IsWildcardFQDN(name) {
+		base := strings.TrimSuffix(strings.TrimPrefix(name, "*."), ".")
+		var owned bool
+		err := s.db.QueryRow(ctx,
+			`SELECT EXISTS(SELECT 1 FROM zones WHERE tenant_id = $1 AND ($2 = name OR right($2, length(name) + 1) = '.' || name))`,
+			tenantID, base,
+		).Scan(&owned)
+		if err != nil {
+			return fmt.Errorf("check zone ownership for %s: %w", name, err)
+		}
+		if !owned {
+			return fmt.Errorf("wildcard fqdn %s requires a zone for %s owned by the tenant", name, base)
+		}
+	}
+
+	if webrootID == nil {
+		return nil
+	}
+
+	rows, err := s.db.Query(ctx,
+		`SELECT id, fqdn FROM fqdns WHERE webroot_id = $1 AND status != $2`,
+		*webrootID, model.StatusDeleting,
+	)
+	if err != nil {
+		return fmt.Errorf("list fqdns for webroot %s: %w", *webrootID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, existing string
+		if err := rows.Scan(&id, &existing); err != nil {
+			return fmt.Errorf("scan fqdn: %w", err)
+		}
+		if id == excludeID {
+			continue
+		}
+		if model.WildcardCovers(existing, name) || model.WildcardCovers(name, existing) {
+			return fmt.Errorf("fqdn %s conflicts with %s already bound to webroot %s", name, existing, *webrootID)
+		}
+	}
+	return rows.Err()
+}
+
 func (s *FQDNService) GetByID(ctx context.Context, id string) (*model.FQDN, error) {
 	var f model.FQDN
 	err := s.db.QueryRow(ctx,
-		`SELECT id, tenant_id, fqdn, webroot_id, ssl_enabled, status, status_message, created_at, updated_at
+		`SELECT id, tenant_id, fqdn, webroot_id, ssl_enabled, force_https, redirect_target, redirect_code, redirect_preserve_path, ip_mode, status, status_message, created_at, updated_at, max_email_accounts, email_quota_bytes
 		 FROM fqdns WHERE id = $1`, id,
-	).Scan(&f.ID, &f.TenantID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.Status, &f.StatusMessage,
-		&f.CreatedAt, &f.UpdatedAt)
+	).Scan(&f.ID, &f.TenantID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.ForceHTTPS, &f.RedirectTarget, &f.RedirectCode, &f.RedirectPreservePath, &f.IPMode, &f.Status, &f.StatusMessage,
+		&f.CreatedAt, &f.UpdatedAt, &f.MaxEmailAccounts, &f.EmailQuotaBytes)
 	if err != nil {
 		return nil, fmt.Errorf("get fqdn %s: %w", id, err)
 	}
 	return &f, nil
 }
 
+// DNSCheck resolves the FQDN on its authoritative nameservers and public
+// resolvers and compares the answers with the load balancer addresses of the
+// tenant's cluster.
+func (s *FQDNService) DNSCheck(ctx context.Context, id string) (*model.FQDNDNSCheck, error) {
+	fqdn, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.checkDNS(ctx, fqdn)
+}
+
+func (s *FQDNService) checkDNS(ctx context.Context, fqdn *model.FQDN) (*model.FQDNDNSCheck, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT host(a.address) FROM cluster_lb_addresses a
+		 JOIN tenants t ON t.cluster_id = a.cluster_id
+		 WHERE t.id = $1 AND ($2 <> 'ipv6_only' OR a.family = 6)
+		 ORDER BY a.family, a.address`, fqdn.TenantID, fqdn.IPMode,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list lb addresses for fqdn %s: %w", fqdn.ID, err)
+	}
+	defer rows.Close()
+
+	expected := []string{}
+	for rows.Next() {
+		var addr string
+		if err := rows.Scan(&addr); err != nil {
+			return nil, fmt.Errorf("scan lb address: %w", err)
+		}
+		expected = append(expected, addr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate lb addresses: %w", err)
+	}
+
+	check, err := s.dns.Check(ctx, fqdn.FQDN, expected)
+	if err != nil {
+		return nil, fmt.Errorf("dns check %s: %w", fqdn.FQDN, err)
+	}
+	return check, nil
+}
+
+// Warnings returns advisory findings for a newly created or updated FQDN:
+// an ipv6_only warning if it is served over IPv6 only, and a dns_not_pointed
+// warning if it does not resolve to its cluster's load balancers yet.
+// Wildcards are not checked for DNS, and lookups that fail or time out
+// produce no warning.
+func (s *FQDNService) Warnings(ctx context.Context, fqdn *model.FQDN) []model.Warning {
+	var warnings []model.Warning
+	if fqdn.IPMode == model.IPModeIPv6Only {
+		warnings = append(warnings, model.Warning{
+			Code:    model.WarningIPv6Only,
+			Message: fmt.Sprintf("%s is served over IPv6 only and gets no A record; clients without IPv6 connectivity cannot reach it", fqdn.FQDN),
+		})
+	}
+	if model.IsWildcardFQDN(fqdn.FQDN) {
+		return warnings
+	}
+	ctx, cancel := context.WithTimeout(ctx, warningTimeout)
+	defer cancel()
+
+	check, err := s.checkDNS(ctx, fqdn)
+	if err != nil || len(check.Expected) == 0 {
+		return warnings
+	}
+	switch check.Status {
+	case model.DNSCheckMismatch, model.DNSCheckNoRecords:
+		warnings = append(warnings, model.Warning{
+			Code: model.WarningDNSNotPointed,
+			Message: fmt.Sprintf("%s does not resolve to %s yet; see GET /fqdns/%s/dns-check once DNS is updated",
+				fqdn.FQDN, strings.Join(check.Expected, ", "), fqdn.ID),
+		})
+	}
+	return warnings
+}
+
+// MailWarnings returns a no_mx_record warning if the FQDN has no MX record,
+// for email account creation. Lookups that fail or time out produce no
+// warning.
+func (s *FQDNService) MailWarnings(ctx context.Context, fqdnID string) []model.Warning {
+	fqdn, err := s.GetByID(ctx, fqdnID)
+	if err != nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, warningTimeout)
+	defer cancel()
+
+	mx, err := s.dns.MX(ctx, fqdn.FQDN)
+	if err != nil || len(mx) > 0 {
+		return nil
+	}
+	return []model.Warning{{
+		Code:    model.WarningNoMXRecord,
+		Message: fmt.Sprintf("%s has no MX record; mail for it will not be delivered until one points to the platform's mail servers", fqdn.FQDN),
+	}}
+}
+
 func (s *FQDNService) ListByWebroot(ctx context.Context, webrootID string, limit int, cursor string) ([]model.FQDN, bool, error) {
-	query := `SELECT id, tenant_id, fqdn, webroot_id, ssl_enabled, status, status_message, created_at, updated_at FROM fqdns WHERE webroot_id = $1`
+	query := `SELECT id, tenant_id, fqdn, webroot_id, ssl_enabled, force_https, redirect_target, redirect_code, redirect_preserve_path, ip_mode, status, status_message, created_at, updated_at, max_email_accounts, email_quota_bytes FROM fqdns WHERE webroot_id = $1`
 	args := []any{webrootID}
 	argIdx := 2
 
@@ -76,8 +238,8 @@ func (s *FQDNService) ListByWebroot(ctx context.Context, webrootID string, limit
 	var fqdns []model.FQDN
 	for rows.Next() {
 		var f model.FQDN
-		if err := rows.Scan(&f.ID, &f.TenantID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.Status, &f.StatusMessage,
-			&f.CreatedAt, &f.UpdatedAt); err != nil {
+		if err := rows.Scan(&f.ID, &f.TenantID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.ForceHTTPS, &f.RedirectTarget, &f.RedirectCode, &f.RedirectPreservePath, &f.IPMode, &f.Status, &f.StatusMessage,
+			&f.CreatedAt, &f.UpdatedAt, &f.MaxEmailAccounts, &f.EmailQuotaBytes); err != nil {
 			return nil, false, fmt.Errorf("scan fqdn: %w", err)
 		}
 		fqdns = append(fqdns, f)
@@ -94,7 +256,7 @@ func (s *FQDNService) ListByWebroot(ctx context.Context, webrootID string, limit
 }
 
 func (s *FQDNService) ListByTenant(ctx context.Context, tenantID string, limit int, cursor string) ([]model.FQDN, bool, error) {
-	query := `SELECT id, tenant_id, fqdn, webroot_id, ssl_enabled, status, status_message, created_at, updated_at FROM fqdns WHERE tenant_id = $1`
+	query := `SELECT id, tenant_id, fqdn, webroot_id, ssl_enabled, force_https, redirect_target, redirect_code, redirect_preserve_path, ip_mode, status, status_message, created_at, updated_at, max_email_accounts, email_quota_bytes FROM fqdns WHERE tenant_id = $1`
 	args := []any{tenantID}
 	argIdx := 2
 
@@ -117,8 +279,8 @@ func (s *FQDNService) ListByTenant(ctx context.Context, tenantID string, limit i
 	var fqdns []model.FQDN
 	for rows.Next() {
 		var f model.FQDN
-		if err := rows.Scan(&f.ID, &f.TenantID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.Status, &f.StatusMessage,
-			&f.CreatedAt, &f.UpdatedAt); err != nil {
+		if err := rows.Scan(&f.ID, &f.TenantID, &f.FQDN, &f.WebrootID, &f.SSLEnabled, &f.ForceHTTPS, &f.RedirectTarget, &f.RedirectCode, &f.RedirectPreservePath, &f.IPMode, &f.Status, &f.StatusMessage,
+			&f.CreatedAt, &f.UpdatedAt, &f.MaxEmailAccounts, &f.EmailQuotaBytes); err != nil {
 			return nil, false, fmt.Errorf("scan fqdn: %w", err)
 		}
 		fqdns = append(fqdns, f)
@@ -136,12 +298,43 @@ func (s *FQDNService) ListByTenant(ctx context.Context, tenantID string, limit i
 
 func (s *FQDNService) Update(ctx context.Context, fqdn *model.FQDN) error {
 	_, err := s.db.Exec(ctx,
-		`UPDATE fqdns SET webroot_id = $1, ssl_enabled = $2, updated_at = now() WHERE id = $3`,
-		fqdn.WebrootID, fqdn.SSLEnabled, fqdn.ID,
+		`UPDATE fqdns SET webroot_id = $1, ssl_enabled = $2, force_https = $3, redirect_target = $4, redirect_code = $5, redirect_preserve_path = $6,
+		   ip_mode = $7, max_email_accounts = $8, email_quota_bytes = $9, updated_at = now()
+		 WHERE id = $10`,
+		fqdn.WebrootID, fqdn.SSLEnabled, fqdn.ForceHTTPS, fqdn.RedirectTarget, fqdn.RedirectCode, fqdn.RedirectPreservePath,
+		fqdn.IPMode, fqdn.MaxEmailAccounts, fqdn.EmailQuotaBytes, fqdn.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update fqdn %s: %w", fqdn.ID, err)
 	}
+
+	// Regenerate the bound webroot's nginx config so ssl_enabled,
+	// force_https and redirect changes take effect.
+	if fqdn.WebrootID != nil {
+		if err := signalProvision(ctx, s.tc, s.db, fqdn.TenantID, model.ProvisionTask{
+			WorkflowName: "UpdateWebrootWorkflow",
+			WorkflowID:   workflowID("webroot", *fqdn.WebrootID),
+			Arg:          *fqdn.WebrootID,
+		}); err != nil {
+			return fmt.Errorf("signal UpdateWebrootWorkflow: %w", err)
+		}
+	}
+	return nil
+}
+
+// SyncDNS republishes the auto DNS records of a bound FQDN, as after an
+// IP mode change. Unbound FQDNs have no auto records and are skipped.
+func (s *FQDNService) SyncDNS(ctx context.Context, fqdn *model.FQDN) error {
+	if fqdn.WebrootID == nil {
+		return nil
+	}
+	if err := signalProvision(ctx, s.tc, s.db, fqdn.TenantID, model.ProvisionTask{
+		WorkflowName: "SyncFQDNDNSWorkflow",
+		WorkflowID:   workflowID("fqdn-dns", fqdn.ID),
+		Arg:          fqdn.ID,
+	}); err != nil {
+		return fmt.Errorf("signal SyncFQDNDNSWorkflow: %w", err)
+	}
 	return nil
 }
 
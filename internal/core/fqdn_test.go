@@ -58,6 +58,81 @@ func TestFQDNService_Create_Success(t *testing.T) {
 	tc.AssertExpectations(t)
 }
 
+func TestFQDNService_Update_SignalsWebrootUpdate(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewFQDNService(db, tc)
+	ctx := context.Background()
+
+	webrootID := "test-webroot-1"
+	fqdn := &model.FQDN{
+		ID:         "test-fqdn-1",
+		TenantID:   "test-tenant-1",
+		FQDN:       "example.com",
+		WebrootID:  &webrootID,
+		SSLEnabled: true,
+		ForceHTTPS: false,
+	}
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id")
+	wfRun.On("GetRunID").Return("mock-run-id")
+	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	err := svc.Update(ctx, fqdn)
+	require.NoError(t, err)
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestFQDNService_Update_UnboundSkipsWorkflow(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewFQDNService(db, tc)
+	ctx := context.Background()
+
+	fqdn := &model.FQDN{ID: "test-fqdn-1", TenantID: "test-tenant-1", FQDN: "example.com"}
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+
+	err := svc.Update(ctx, fqdn)
+	require.NoError(t, err)
+	db.AssertExpectations(t)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFQDNService_SyncDNS_SignalsWorkflow(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewFQDNService(db, tc)
+	ctx := context.Background()
+
+	webrootID := "test-webroot-1"
+	fqdn := &model.FQDN{ID: "test-fqdn-1", TenantID: "test-tenant-1", FQDN: "example.com", WebrootID: &webrootID, IPMode: model.IPModeIPv6Only}
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("SignalWithStartWorkflow", mock.Anything, "tenant-test-tenant-1", model.ProvisionSignalName,
+		mock.MatchedBy(func(task model.ProvisionTask) bool {
+			return task.WorkflowName == "SyncFQDNDNSWorkflow" && task.Arg == "test-fqdn-1"
+		}), mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	require.NoError(t, svc.SyncDNS(ctx, fqdn))
+	tc.AssertExpectations(t)
+}
+
+func TestFQDNService_SyncDNS_UnboundSkipsWorkflow(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewFQDNService(&mockDB{}, tc)
+
+	fqdn := &model.FQDN{ID: "test-fqdn-1", TenantID: "test-tenant-1", FQDN: "example.com", IPMode: model.IPModeIPv6Only}
+
+	require.NoError(t, svc.SyncDNS(context.Background(), fqdn))
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestFQDNService_Create_InsertError(t *testing.T) {
 	db := &mockDB{}
 	tc := &temporalmocks.Client{}
@@ -95,6 +170,85 @@ func TestFQDNService_Create_WorkflowError(t *testing.T) {
 
 // ---------- GetByID ----------
 
+// ---------- ValidateBinding ----------
+
+func TestFQDNService_ValidateBinding_WildcardRequiresOwnedZone(t *testing.T) {
+	db := &mockDB{}
+	svc := NewFQDNService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*bool)) = false
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+	err := svc.ValidateBinding(ctx, "test-tenant-1", "*.example.com", nil, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a zone for example.com")
+	db.AssertExpectations(t)
+}
+
+func TestFQDNService_ValidateBinding_WildcardInOwnedZone(t *testing.T) {
+	db := &mockDB{}
+	svc := NewFQDNService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+	webrootID := "test-webroot-1"
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*bool)) = true
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+	rows := newMockRows(func(dest ...any) error {
+		*(dest[0].(*string)) = "fqdn-apex"
+		*(dest[1].(*string)) = "example.com"
+		return nil
+	})
+	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+
+	err := svc.ValidateBinding(ctx, "test-tenant-1", "*.example.com", &webrootID, "")
+	require.NoError(t, err)
+	db.AssertExpectations(t)
+}
+
+func TestFQDNService_ValidateBinding_ConflictsWithWildcardOnWebroot(t *testing.T) {
+	db := &mockDB{}
+	svc := NewFQDNService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+	webrootID := "test-webroot-1"
+
+	rows := newMockRows(func(dest ...any) error {
+		*(dest[0].(*string)) = "fqdn-wildcard"
+		*(dest[1].(*string)) = "*.example.com"
+		return nil
+	})
+	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+
+	err := svc.ValidateBinding(ctx, "test-tenant-1", "www.example.com", &webrootID, "")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "conflicts with *.example.com")
+	db.AssertExpectations(t)
+}
+
+func TestFQDNService_ValidateBinding_ExcludesSelf(t *testing.T) {
+	db := &mockDB{}
+	svc := NewFQDNService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+	webrootID := "test-webroot-1"
+
+	rows := newMockRows(func(dest ...any) error {
+		*(dest[0].(*string)) = "fqdn-www"
+		*(dest[1].(*string)) = "www.example.com"
+		return nil
+	})
+	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+
+	err := svc.ValidateBinding(ctx, "test-tenant-1", "www.example.com", &webrootID, "fqdn-www")
+	require.NoError(t, err)
+	db.AssertExpectations(t)
+}
+
 func TestFQDNService_GetByID_Success(t *testing.T) {
 	db := &mockDB{}
 	tc := &temporalmocks.Client{}
@@ -106,16 +260,22 @@ func TestFQDNService_GetByID_Success(t *testing.T) {
 	now := time.Now().Truncate(time.Microsecond)
 
 	tenantID := "test-tenant-1"
+	target := "example.org"
 	row := &mockRow{scanFunc: func(dest ...any) error {
 		*(dest[0].(*string)) = fqdnID
 		*(dest[1].(*string)) = tenantID
 		*(dest[2].(*string)) = "example.com"
 		*(dest[3].(**string)) = &webrootID
 		*(dest[4].(*bool)) = true
-		*(dest[5].(*string)) = model.StatusActive
-		*(dest[6].(**string)) = nil // status_message
-		*(dest[7].(*time.Time)) = now
-		*(dest[8].(*time.Time)) = now
+		*(dest[5].(*bool)) = true
+		*(dest[6].(**string)) = &target
+		*(dest[7].(*int)) = 301
+		*(dest[8].(*bool)) = true
+		*(dest[9].(*string)) = model.IPModeDualStack
+		*(dest[10].(*string)) = model.StatusActive
+		*(dest[11].(**string)) = nil // status_message
+		*(dest[12].(*time.Time)) = now
+		*(dest[13].(*time.Time)) = now
 		return nil
 	}}
 	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
@@ -127,6 +287,11 @@ func TestFQDNService_GetByID_Success(t *testing.T) {
 	assert.Equal(t, "example.com", result.FQDN)
 	assert.Equal(t, &webrootID, result.WebrootID)
 	assert.True(t, result.SSLEnabled)
+	assert.True(t, result.ForceHTTPS)
+	assert.Equal(t, &target, result.RedirectTarget)
+	assert.Equal(t, 301, result.RedirectCode)
+	assert.True(t, result.RedirectPreservePath)
+	assert.Equal(t, model.IPModeDualStack, result.IPMode)
 	assert.Equal(t, model.StatusActive, result.Status)
 	db.AssertExpectations(t)
 }
@@ -149,6 +314,170 @@ func TestFQDNService_GetByID_NotFound(t *testing.T) {
 	db.AssertExpectations(t)
 }
 
+// ---------- DNSCheck ----------
+
+type fakeDNSChecker struct {
+	name     string
+	expected []string
+	status   string // defaults to match
+	mx       []string
+	mxErr    error
+}
+
+func (f *fakeDNSChecker) Check(_ context.Context, name string, expected []string) (*model.FQDNDNSCheck, error) {
+	f.name, f.expected = name, expected
+	status := f.status
+	if status == "" {
+		status = model.DNSCheckMatch
+	}
+	return &model.FQDNDNSCheck{FQDN: name, Expected: expected, Status: status}, nil
+}
+
+func (f *fakeDNSChecker) MX(_ context.Context, name string) ([]string, error) {
+	f.name = name
+	return f.mx, f.mxErr
+}
+
+func TestFQDNService_DNSCheck_UsesClusterLBAddresses(t *testing.T) {
+	db := &mockDB{}
+	svc := NewFQDNService(db, &temporalmocks.Client{})
+	checker := &fakeDNSChecker{}
+	svc.dns = checker
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-fqdn-1"
+		*(dest[1].(*string)) = "test-tenant-1"
+		*(dest[2].(*string)) = "www.example.com"
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+	rows := newMockRows(
+		func(dest ...any) error { *(dest[0].(*string)) = "203.0.113.10"; return nil },
+		func(dest ...any) error { *(dest[0].(*string)) = "2001:db8::10"; return nil },
+	)
+	db.On("Query", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1", ""}).Return(rows, nil)
+
+	result, err := svc.DNSCheck(ctx, "test-fqdn-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.DNSCheckMatch, result.Status)
+	assert.Equal(t, "www.example.com", checker.name)
+	assert.Equal(t, []string{"203.0.113.10", "2001:db8::10"}, checker.expected)
+	db.AssertExpectations(t)
+}
+
+func TestFQDNService_DNSCheck_NotFound(t *testing.T) {
+	db := &mockDB{}
+	svc := NewFQDNService(db, &temporalmocks.Client{})
+	svc.dns = &fakeDNSChecker{}
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		return errors.New("no rows in result set")
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+	_, err := svc.DNSCheck(ctx, "nonexistent-fqdn")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "get fqdn")
+}
+
+// ---------- Warnings ----------
+
+func TestFQDNService_Warnings_DNSNotPointed(t *testing.T) {
+	db := &mockDB{}
+	svc := NewFQDNService(db, &temporalmocks.Client{})
+	svc.dns = &fakeDNSChecker{status: model.DNSCheckMismatch}
+
+	rows := newMockRows(
+		func(dest ...any) error { *(dest[0].(*string)) = "203.0.113.10"; return nil },
+	)
+	db.On("Query", mock.Anything, mock.AnythingOfType("string"), []any{"test-tenant-1", ""}).Return(rows, nil)
+
+	warnings := svc.Warnings(context.Background(), &model.FQDN{ID: "test-fqdn-1", TenantID: "test-tenant-1", FQDN: "www.example.com"})
+	require.Len(t, warnings, 1)
+	assert.Equal(t, model.WarningDNSNotPointed, warnings[0].Code)
+	assert.Contains(t, warnings[0].Message, "www.example.com does not resolve to 203.0.113.10")
+	assert.Contains(t, warnings[0].Message, "/fqdns/test-fqdn-1/dns-check")
+}
+
+func TestFQDNService_Warnings_Pointed(t *testing.T) {
+	db := &mockDB{}
+	svc := NewFQDNService(db, &temporalmocks.Client{})
+	svc.dns = &fakeDNSChecker{}
+
+	rows := newMockRows(
+		func(dest ...any) error { *(dest[0].(*string)) = "203.0.113.10"; return nil },
+	)
+	db.On("Query", mock.Anything, mock.AnythingOfType("string"), []any{"test-tenant-1", ""}).Return(rows, nil)
+
+	assert.Empty(t, svc.Warnings(context.Background(), &model.FQDN{ID: "test-fqdn-1", TenantID: "test-tenant-1", FQDN: "www.example.com"}))
+}
+
+func TestFQDNService_Warnings_IPv6Only(t *testing.T) {
+	db := &mockDB{}
+	svc := NewFQDNService(db, &temporalmocks.Client{})
+	checker := &fakeDNSChecker{}
+	svc.dns = checker
+
+	rows := newMockRows(
+		func(dest ...any) error { *(dest[0].(*string)) = "2001:db8::10"; return nil },
+	)
+	db.On("Query", mock.Anything, mock.AnythingOfType("string"), []any{"test-tenant-1", model.IPModeIPv6Only}).Return(rows, nil)
+
+	warnings := svc.Warnings(context.Background(), &model.FQDN{ID: "test-fqdn-1", TenantID: "test-tenant-1", FQDN: "www.example.com", IPMode: model.IPModeIPv6Only})
+	require.Len(t, warnings, 1)
+	assert.Equal(t, model.WarningIPv6Only, warnings[0].Code)
+	assert.Contains(t, warnings[0].Message, "clients without IPv6 connectivity cannot reach it")
+	assert.Equal(t, []string{"2001:db8::10"}, checker.expected)
+}
+
+func TestFQDNService_Warnings_SkipsWildcards(t *testing.T) {
+	svc := NewFQDNService(&mockDB{}, &temporalmocks.Client{})
+	checker := &fakeDNSChecker{status: model.DNSCheckNoRecords}
+	svc.dns = checker
+
+	assert.Empty(t, svc.Warnings(context.Background(), &model.FQDN{ID: "test-fqdn-1", FQDN: "*.example.com"}))
+	assert.Empty(t, checker.name)
+}
+
+func TestFQDNService_MailWarnings(t *testing.T) {
+	tests := []struct {
+		name  string
+		mx    []string
+		mxErr error
+		want  bool
+	}{
+		{"no mx", nil, nil, true},
+		{"has mx", []string{"mail.example.com"}, nil, false},
+		{"lookup fails", nil, errors.New("i/o timeout"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			svc := NewFQDNService(db, &temporalmocks.Client{})
+			svc.dns = &fakeDNSChecker{mx: tt.mx, mxErr: tt.mxErr}
+
+			row := &mockRow{scanFunc: func(dest ...any) error {
+				*(dest[0].(*string)) = "test-fqdn-1"
+				*(dest[1].(*string)) = "test-tenant-1"
+				*(dest[2].(*string)) = "example.com"
+				return nil
+			}}
+			db.On("QueryRow", mock.Anything, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+			warnings := svc.MailWarnings(context.Background(), "test-fqdn-1")
+			if !tt.want {
+				assert.Empty(t, warnings)
+				return
+			}
+			require.Len(t, warnings, 1)
+			assert.Equal(t, model.WarningNoMXRecord, warnings[0].Code)
+			assert.Contains(t, warnings[0].Message, "example.com has no MX record")
+		})
+	}
+}
+
 // ---------- ListByWebroot ----------
 
 func TestFQDNService_ListByWebroot_Success(t *testing.T) {
@@ -169,10 +498,12 @@ func TestFQDNService_ListByWebroot_Success(t *testing.T) {
 			*(dest[2].(*string)) = "alpha.example.com"
 			*(dest[3].(**string)) = &webrootID
 			*(dest[4].(*bool)) = true
-			*(dest[5].(*string)) = model.StatusActive
-			*(dest[6].(**string)) = nil // status_message
-			*(dest[7].(*time.Time)) = now
-			*(dest[8].(*time.Time)) = now
+			*(dest[5].(*bool)) = true
+			*(dest[9].(*string)) = model.IPModeDualStack
+			*(dest[10].(*string)) = model.StatusActive
+			*(dest[11].(**string)) = nil // status_message
+			*(dest[12].(*time.Time)) = now
+			*(dest[13].(*time.Time)) = now
 			return nil
 		},
 		func(dest ...any) error {
@@ -181,10 +512,12 @@ func TestFQDNService_ListByWebroot_Success(t *testing.T) {
 			*(dest[2].(*string)) = "beta.example.com"
 			*(dest[3].(**string)) = &webrootID
 			*(dest[4].(*bool)) = false
-			*(dest[5].(*string)) = model.StatusPending
-			*(dest[6].(**string)) = nil // status_message
-			*(dest[7].(*time.Time)) = now
-			*(dest[8].(*time.Time)) = now
+			*(dest[5].(*bool)) = true
+			*(dest[9].(*string)) = model.IPModeDualStack
+			*(dest[10].(*string)) = model.StatusPending
+			*(dest[11].(**string)) = nil // status_message
+			*(dest[12].(*time.Time)) = now
+			*(dest[13].(*time.Time)) = now
 			return nil
 		},
 	)
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// IdempotencyWindow is how long an Idempotency-Key and its response are kept.
+// A key reused after the window starts a new request.
+const IdempotencyWindow = 24 * time.Hour
+
+// idempotencyClaimTimeout is how long a claimed key may go without a stored
+// response before it is treated as abandoned (e.g. the API process died
+// mid-request) and can be claimed again.
+const idempotencyClaimTimeout = 5 * time.Minute
+
+// ErrIdempotencyKeyInProgress is returned when the request that first used a
+// key has not finished yet.
+var ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+
+// ErrIdempotencyKeyReused is returned when a key is sent again with a
+// different method, path or body.
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+type IdempotencyService struct {
+	db DB
+}
+
+func NewIdempotencyService(db DB) *IdempotencyService {
+	return &IdempotencyService{db: db}
+}
+
+// Claim reserves key for a request with the given hash. It returns nil when
+// the caller owns the key and must handle the request, then call Complete or
+// Release. If the key already has a stored response for the same request, that
+// response is returned for replay.
+func (s *IdempotencyService) Claim(ctx context.Context, apiKeyID, key, requestHash string) (*model.IdempotencyKey, error) {
+	// Expired keys and abandoned claims no longer block the key.
+	_, err := s.db.Exec(ctx,
+		`DELETE FROM idempotency_keys WHERE api_key_id = $1 AND key = $2
+		 AND (created_at < now() - make_interval(secs => $3)
+		      OR (status_code IS NULL AND created_at < now() - make_interval(secs => $4)))`,
+		apiKeyID, key, IdempotencyWindow.Seconds(), idempotencyClaimTimeout.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("expire idempotency key: %w", err)
+	}
+
+	tag, err := s.db.Exec(ctx,
+		`INSERT INTO idempotency_keys (api_key_id, key, request_hash, created_at)
+		 VALUES ($1, $2, $3, now())
+		 ON CONFLICT (api_key_id, key) DO NOTHING`,
+		apiKeyID, key, requestHash)
+	if err != nil {
+		return nil, fmt.Errorf("claim idempotency key: %w", err)
+	}
+	if tag.RowsAffected() == 1 {
+		return nil, nil
+	}
+
+	var k model.IdempotencyKey
+	err = s.db.QueryRow(ctx,
+		`SELECT api_key_id, key, request_hash, status_code, response_headers, response_body, response_withheld, created_at
+		 FROM idempotency_keys WHERE api_key_id = $1 AND key = $2`,
+		apiKeyID, key,
+	).Scan(&k.APIKeyID, &k.Key, &k.RequestHash, &k.StatusCode, &k.ResponseHeaders, &k.ResponseBody, &k.ResponseWithheld, &k.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+	if k.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if k.StatusCode == nil {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	return &k, nil
+}
+
+// Complete stores the response of the request that claimed key. A withheld
+// response is stored without its body.
+func (s *IdempotencyService) Complete(ctx context.Context, apiKeyID, key string, statusCode int, headers http.Header, body []byte, withheld bool) error {
+	if headers == nil {
+		headers = http.Header{}
+	}
+	if withheld {
+		body = nil
+	}
+	_, err := s.db.Exec(ctx,
+		`UPDATE idempotency_keys SET status_code = $1, response_headers = $2, response_body = $3, response_withheld = $4
+		 WHERE api_key_id = $5 AND key = $6`,
+		statusCode, headers, body, withheld, apiKeyID, key)
+	if err != nil {
+		return fmt.Errorf("store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release drops an unfinished claim so the key can be retried, used when the
+// request failed in a way that should not be replayed.
+func (s *IdempotencyService) Release(ctx context.Context, apiKeyID, key string) error {
+	_, err := s.db.Exec(ctx,
+		`DELETE FROM idempotency_keys WHERE api_key_id = $1 AND key = $2 AND status_code IS NULL`,
+		apiKeyID, key)
+	if err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
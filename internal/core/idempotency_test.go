@@ -0,0 +1,88 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// idempotencyDB mocks the expire and claim statements of Claim; inserted
+// controls whether the claim wins.
+func idempotencyDB(ctx context.Context, inserted bool) *mockDB {
+	db := &mockDB{}
+	db.On("Exec", ctx, mock.MatchedBy(func(q string) bool { return strings.HasPrefix(q, "DELETE") }), mock.Anything).
+		Return(pgconn.NewCommandTag("DELETE 0"), nil)
+	tag := pgconn.NewCommandTag("INSERT 0 0")
+	if inserted {
+		tag = pgconn.NewCommandTag("INSERT 0 1")
+	}
+	db.On("Exec", ctx, mock.MatchedBy(func(q string) bool { return strings.HasPrefix(q, "INSERT") }), mock.Anything).
+		Return(tag, nil)
+	return db
+}
+
+// storedKeyRow returns a row for an existing key with the given hash and
+// status (nil while in progress).
+func storedKeyRow(hash string, status *int) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "key-1"
+		*(dest[1].(*string)) = "abc"
+		*(dest[2].(*string)) = hash
+		*(dest[3].(**int)) = status
+		*(dest[4].(*http.Header)) = http.Header{"Content-Type": {"application/json"}}
+		*(dest[5].(*[]byte)) = []byte(`{"id":"t-1"}`)
+		return nil
+	}}
+}
+
+func TestIdempotencyService_Claim_NewKey(t *testing.T) {
+	ctx := context.Background()
+	db := idempotencyDB(ctx, true)
+	svc := NewIdempotencyService(db)
+
+	stored, err := svc.Claim(ctx, "key-1", "abc", "hash-1")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+	db.AssertExpectations(t)
+}
+
+func TestIdempotencyService_Claim_Replay(t *testing.T) {
+	ctx := context.Background()
+	db := idempotencyDB(ctx, false)
+	status := http.StatusAccepted
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(storedKeyRow("hash-1", &status))
+	svc := NewIdempotencyService(db)
+
+	stored, err := svc.Claim(ctx, "key-1", "abc", "hash-1")
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.Equal(t, http.StatusAccepted, *stored.StatusCode)
+	assert.Equal(t, `{"id":"t-1"}`, string(stored.ResponseBody))
+}
+
+func TestIdempotencyService_Claim_DifferentRequest(t *testing.T) {
+	ctx := context.Background()
+	db := idempotencyDB(ctx, false)
+	status := http.StatusAccepted
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(storedKeyRow("hash-1", &status))
+	svc := NewIdempotencyService(db)
+
+	_, err := svc.Claim(ctx, "key-1", "abc", "hash-2")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyReused)
+}
+
+func TestIdempotencyService_Claim_InProgress(t *testing.T) {
+	ctx := context.Background()
+	db := idempotencyDB(ctx, false)
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(storedKeyRow("hash-1", nil))
+	svc := NewIdempotencyService(db)
+
+	_, err := svc.Claim(ctx, "key-1", "abc", "hash-1")
+	assert.ErrorIs(t, err, ErrIdempotencyKeyInProgress)
+}
@@ -0,0 +1,171 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	temporalclient "go.temporal.io/sdk/client"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// TempAccessTTL is how long the temporary MySQL user of a DB Admin login
+// lives. The node drops it after this time on its own (see
+// agent.DatabaseManager.CreateTempUser).
+const TempAccessTTL = 2 * time.Hour
+
+// ErrLoginSessionNotUsable is returned when temporary access is requested for
+// a login session that was not just validated for that database, already
+// has access, or was revoked.
+var ErrLoginSessionNotUsable = errors.New("login session is not valid for temporary access")
+
+// loginSessionLiveCond selects login sessions that can still be used or have
+// live DB Admin access.
+const loginSessionLiveCond = `revoked_at IS NULL AND ((NOT used AND expires_at > now()) OR active_until > now())`
+
+// LoginSessionService is the server-side registry of login sessions and the
+// DB Admin access opened with them.
+type LoginSessionService struct {
+	db DB
+	tc temporalclient.Client
+}
+
+func NewLoginSessionService(db DB, tc temporalclient.Client) *LoginSessionService {
+	return &LoginSessionService{db: db, tc: tc}
+}
+
+// Activate records the temporary MySQL user created for a validated login
+// session. It fails with ErrLoginSessionNotUsable unless the session was
+// used for databaseID within the last few minutes, has no user yet and is
+// not revoked, so each session opens at most one access.
+func (s *LoginSessionService) Activate(ctx context.Context, sessionID, databaseID, username string) error {
+	var id string
+	err := s.db.QueryRow(ctx,
+		`UPDATE oidc_login_sessions SET temp_username = $3, active_until = $4
+		 WHERE id = $1 AND database_id = $2 AND used AND temp_username IS NULL AND revoked_at IS NULL
+		   AND created_at > now() - interval '5 minutes'
+		 RETURNING id`,
+		sessionID, databaseID, username, time.Now().Add(TempAccessTTL),
+	).Scan(&id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrLoginSessionNotUsable
+	}
+	if err != nil {
+		return fmt.Errorf("activate login session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether a login session has been revoked.
+func (s *LoginSessionService) IsRevoked(ctx context.Context, sessionID string) (bool, error) {
+	var revoked bool
+	err := s.db.QueryRow(ctx,
+		`SELECT revoked_at IS NOT NULL FROM oidc_login_sessions WHERE id = $1`, sessionID,
+	).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("get login session %s: %w", sessionID, err)
+	}
+	return revoked, nil
+}
+
+// ListActive returns the tenant's login sessions that are still pending or
+// have live DB Admin access, newest first.
+func (s *LoginSessionService) ListActive(ctx context.Context, tenantID string) ([]model.OIDCLoginSession, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT id, tenant_id, database_id, expires_at, used, temp_username, active_until, created_at
+		 FROM oidc_login_sessions
+		 WHERE tenant_id = $1 AND `+loginSessionLiveCond+`
+		 ORDER BY created_at DESC`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list login sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []model.OIDCLoginSession{}
+	for rows.Next() {
+		var sess model.OIDCLoginSession
+		if err := rows.Scan(&sess.ID, &sess.TenantID, &sess.DatabaseID, &sess.ExpiresAt, &sess.Used,
+			&sess.TempUsername, &sess.ActiveUntil, &sess.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan login session: %w", err)
+		}
+		sess.Status = model.LoginSessionPending
+		if sess.Used {
+			sess.Status = model.LoginSessionActive
+		}
+		sessions = append(sessions, sess)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate login sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+// Revoke revokes the tenant's live login sessions, or only sessionID if it
+// is not empty, and returns the revoked sessions. Revoked sessions can no
+// longer be validated or get temporary access. Sessions with a temporary
+// MySQL user get it dropped and its connections killed by
+// RevokeLoginSessionWorkflow.
+func (s *LoginSessionService) Revoke(ctx context.Context, tenantID, sessionID string) ([]model.OIDCLoginSession, error) {
+	query := `UPDATE oidc_login_sessions SET revoked_at = now()
+		 WHERE tenant_id = $1 AND ` + loginSessionLiveCond
+	args := []any{tenantID}
+	if sessionID != "" {
+		query += ` AND id = $2`
+		args = append(args, sessionID)
+	}
+	query += ` RETURNING id, tenant_id, database_id, expires_at, used, temp_username, active_until, revoked_at, created_at,
+		 (SELECT shard_id FROM databases d WHERE d.id = oidc_login_sessions.database_id)`
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("revoke login sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []model.OIDCLoginSession{}
+	var drops []model.RevokeLoginSessionParams
+	for rows.Next() {
+		var sess model.OIDCLoginSession
+		var shardID *string
+		if err := rows.Scan(&sess.ID, &sess.TenantID, &sess.DatabaseID, &sess.ExpiresAt, &sess.Used,
+			&sess.TempUsername, &sess.ActiveUntil, &sess.RevokedAt, &sess.CreatedAt, &shardID); err != nil {
+			return nil, fmt.Errorf("scan login session: %w", err)
+		}
+		sessions = append(sessions, sess)
+		if sess.TempUsername != nil && shardID != nil {
+			drops = append(drops, model.RevokeLoginSessionParams{
+				SessionID: sess.ID,
+				ShardID:   *shardID,
+				Username:  *sess.TempUsername,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate login sessions: %w", err)
+	}
+
+	for _, p := range drops {
+		if err := s.StartRevokeAccess(ctx, tenantID, p); err != nil {
+			return nil, err
+		}
+	}
+	return sessions, nil
+}
+
+// StartRevokeAccess starts RevokeLoginSessionWorkflow to drop a session's
+// temporary MySQL user. It runs outside the tenant's provisioning queue so
+// access is cut right away.
+func (s *LoginSessionService) StartRevokeAccess(ctx context.Context, tenantID string, params model.RevokeLoginSessionParams) error {
+	err := startWorkflow(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "RevokeLoginSessionWorkflow",
+		WorkflowID:   workflowID("revoke-login-session", params.SessionID),
+		Arg:          params,
+	})
+	if err != nil {
+		return fmt.Errorf("start RevokeLoginSessionWorkflow: %w", err)
+	}
+	return nil
+}
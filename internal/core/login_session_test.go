@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func loginSessionRow(id string, used bool, tempUsername *string) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = id
+		*(dest[1].(*string)) = "test-tenant-1"
+		dbID := "test-db-1"
+		*(dest[2].(**string)) = &dbID
+		*(dest[3].(*time.Time)) = time.Now().Add(30 * time.Second)
+		*(dest[4].(*bool)) = used
+		*(dest[5].(**string)) = tempUsername
+		return nil
+	}
+}
+
+func TestLoginSessionService_Activate_NotUsable(t *testing.T) {
+	db := &mockDB{}
+	svc := NewLoginSessionService(db, nil)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, sqlContains("UPDATE oidc_login_sessions SET temp_username"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		return pgx.ErrNoRows
+	}})
+
+	err := svc.Activate(ctx, "sess-1", "test-db-1", "tmp_abc")
+	require.ErrorIs(t, err, ErrLoginSessionNotUsable)
+}
+
+func TestLoginSessionService_ListActive_Status(t *testing.T) {
+	db := &mockDB{}
+	svc := NewLoginSessionService(db, nil)
+	ctx := context.Background()
+
+	user := "tmp_abc"
+	db.On("Query", ctx, sqlContains("FROM oidc_login_sessions"), mock.Anything).Return(newMockRows(
+		loginSessionRow("sess-2", false, nil),
+		loginSessionRow("sess-1", true, &user),
+	), nil)
+
+	sessions, err := svc.ListActive(ctx, "test-tenant-1")
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, model.LoginSessionPending, sessions[0].Status)
+	assert.Equal(t, model.LoginSessionActive, sessions[1].Status)
+	assert.Equal(t, "tmp_abc", *sessions[1].TempUsername)
+}
+
+func TestLoginSessionService_Revoke_DropsTempUsers(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewLoginSessionService(db, tc)
+	ctx := context.Background()
+
+	user := "tmp_abc"
+	withShard := func(scan func(dest ...any) error, shardID *string) func(dest ...any) error {
+		return func(dest ...any) error {
+			*(dest[9].(**string)) = shardID
+			return scan(dest...)
+		}
+	}
+	shardID := "test-db-shard"
+	db.On("Query", ctx, sqlContains("SET revoked_at = now()"), mock.Anything).Return(newMockRows(
+		withShard(loginSessionRow("sess-2", false, nil), &shardID),
+		withShard(loginSessionRow("sess-1", true, &user), &shardID),
+	), nil)
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "RevokeLoginSessionWorkflow", model.RevokeLoginSessionParams{
+		SessionID: "sess-1",
+		ShardID:   "test-db-shard",
+		Username:  "tmp_abc",
+	}).Return(wfRun, nil).Once()
+
+	sessions, err := svc.Revoke(ctx, "test-tenant-1", "")
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	tc.AssertExpectations(t)
+}
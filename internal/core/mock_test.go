@@ -33,6 +33,49 @@ func (m *mockDB) QueryRow(ctx context.Context, sql string, arguments ...any) pgx
 	return args.Get(0).(pgx.Row)
 }
 
+func (m *mockDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(pgx.Tx), args.Error(1)
+}
+
+// ---------- Mock Tx ----------
+
+// mockTx implements pgx.Tx for testing. Only Exec, Query, QueryRow, Commit
+// and Rollback are mocked; the embedded interface panics on anything else.
+type mockTx struct {
+	pgx.Tx
+	mock.Mock
+}
+
+func (m *mockTx) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	args := m.Called(ctx, sql, arguments)
+	return args.Get(0).(pgconn.CommandTag), args.Error(1)
+}
+
+func (m *mockTx) Query(ctx context.Context, sql string, arguments ...any) (pgx.Rows, error) {
+	args := m.Called(ctx, sql, arguments)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(pgx.Rows), args.Error(1)
+}
+
+func (m *mockTx) QueryRow(ctx context.Context, sql string, arguments ...any) pgx.Row {
+	args := m.Called(ctx, sql, arguments)
+	return args.Get(0).(pgx.Row)
+}
+
+func (m *mockTx) Commit(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+func (m *mockTx) Rollback(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
 // ---------- Mock Row ----------
 
 // mockRow implements pgx.Row for testing.
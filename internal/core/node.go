@@ -6,14 +6,17 @@ import (
 
 	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	temporalclient "go.temporal.io/sdk/client"
 )
 
 type NodeService struct {
 	db DB
+	tc temporalclient.Client
 }
 
-func NewNodeService(db DB, tc ...any) *NodeService {
-	return &NodeService{db: db}
+func NewNodeService(db DB, tc temporalclient.Client) *NodeService {
+	return &NodeService{db: db, tc: tc}
 }
 
 func (s *NodeService) Create(ctx context.Context, node *model.Node, shardIDs []string) error {
@@ -38,10 +41,13 @@ func (s *NodeService) Create(ctx context.Context, node *model.Node, shardIDs []s
 func (s *NodeService) GetByID(ctx context.Context, id string) (*model.Node, error) {
 	var n model.Node
 	err := s.db.QueryRow(ctx,
-		`SELECT id, cluster_id, hostname, ip_address::text, ip6_address::text, roles, status, created_at, updated_at
-		 FROM nodes WHERE id = $1`, id,
+		`SELECT n.id, n.cluster_id, n.hostname, n.ip_address::text, n.ip6_address::text, n.roles, n.status, n.created_at, n.updated_at,
+		        nc.agent_version
+		 FROM nodes n
+		 LEFT JOIN node_capabilities nc ON nc.node_id = n.id
+		 WHERE n.id = $1`, id,
 	).Scan(&n.ID, &n.ClusterID, &n.Hostname, &n.IPAddress, &n.IP6Address,
-		&n.Roles, &n.Status, &n.CreatedAt, &n.UpdatedAt)
+		&n.Roles, &n.Status, &n.CreatedAt, &n.UpdatedAt, &n.AgentVersion)
 	if err != nil {
 		return nil, fmt.Errorf("get node %s: %w", id, err)
 	}
@@ -53,34 +59,34 @@ func (s *NodeService) GetByID(ctx context.Context, id string) (*model.Node, erro
 }
 
 func (s *NodeService) ListByCluster(ctx context.Context, clusterID string, params request.ListParams) ([]model.Node, bool, error) {
-	query := `SELECT id, cluster_id, hostname, ip_address::text, ip6_address::text, roles, status, created_at, updated_at FROM nodes WHERE cluster_id = $1`
+	query := `SELECT n.id, n.cluster_id, n.hostname, n.ip_address::text, n.ip6_address::text, n.roles, n.status, n.created_at, n.updated_at, nc.agent_version FROM nodes n LEFT JOIN node_capabilities nc ON nc.node_id = n.id WHERE n.cluster_id = $1`
 	args := []any{clusterID}
 	argIdx := 2
 
 	if params.Search != "" {
-		query += fmt.Sprintf(` AND hostname ILIKE $%d`, argIdx)
+		query += fmt.Sprintf(` AND n.hostname ILIKE $%d`, argIdx)
 		args = append(args, "%"+params.Search+"%")
 		argIdx++
 	}
 	if params.Status != "" {
-		query += fmt.Sprintf(` AND status = $%d`, argIdx)
+		query += fmt.Sprintf(` AND n.status = $%d`, argIdx)
 		args = append(args, params.Status)
 		argIdx++
 	}
 	if params.Cursor != "" {
-		query += fmt.Sprintf(` AND id > $%d`, argIdx)
+		query += fmt.Sprintf(` AND n.id > $%d`, argIdx)
 		args = append(args, params.Cursor)
 		argIdx++
 	}
 
-	sortCol := "created_at"
+	sortCol := "n.created_at"
 	switch params.Sort {
 	case "hostname":
-		sortCol = "hostname"
+		sortCol = "n.hostname"
 	case "status":
-		sortCol = "status"
+		sortCol = "n.status"
 	case "created_at":
-		sortCol = "created_at"
+		sortCol = "n.created_at"
 	}
 	order := "DESC"
 	if params.Order == "asc" {
@@ -100,7 +106,7 @@ func (s *NodeService) ListByCluster(ctx context.Context, clusterID string, param
 	for rows.Next() {
 		var n model.Node
 		if err := rows.Scan(&n.ID, &n.ClusterID, &n.Hostname, &n.IPAddress, &n.IP6Address,
-			&n.Roles, &n.Status, &n.CreatedAt, &n.UpdatedAt); err != nil {
+			&n.Roles, &n.Status, &n.CreatedAt, &n.UpdatedAt, &n.AgentVersion); err != nil {
 			return nil, false, fmt.Errorf("scan node: %w", err)
 		}
 		nodes = append(nodes, n)
@@ -294,3 +300,27 @@ func (s *NodeService) batchLoadShardAssignments(ctx context.Context, nodes []mod
 	}
 	return nil
 }
+
+// nodeDiagnosticsArgs mirrors workflow.NodeDiagnosticsArgs.
+type nodeDiagnosticsArgs struct {
+	NodeID string
+	Roles  []string
+}
+
+// Diagnostics runs the node agent self-tests on the node and returns the
+// report. It waits for NodeDiagnosticsWorkflow, which fails fast if the node
+// agent is not polling its task queue.
+func (s *NodeService) Diagnostics(ctx context.Context, node *model.Node) (*model.NodeDiagnostics, error) {
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("node-diagnostics", node.ID+"-"+platform.NewID()),
+		TaskQueue: taskQueue,
+	}, "NodeDiagnosticsWorkflow", nodeDiagnosticsArgs{NodeID: node.ID, Roles: node.Roles})
+	if err != nil {
+		return nil, fmt.Errorf("start NodeDiagnosticsWorkflow: %w", err)
+	}
+	var report model.NodeDiagnostics
+	if err := run.Get(ctx, &report); err != nil {
+		return nil, fmt.Errorf("diagnostics for node %s: %w", node.ID, err)
+	}
+	return &report, nil
+}
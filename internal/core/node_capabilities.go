@@ -0,0 +1,32 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNoNodeCapabilities is returned when a node's agent has not reported its
+// capabilities yet.
+var ErrNoNodeCapabilities = errors.New("no capabilities reported for node")
+
+// GetCapabilities returns the agent version and activities last reported by
+// the node, as stored by CollectNodeCapabilitiesWorkflow, or
+// ErrNoNodeCapabilities if there is no report.
+func (s *NodeService) GetCapabilities(ctx context.Context, nodeID string) (*model.NodeCapabilities, error) {
+	var c model.NodeCapabilities
+	err := s.db.QueryRow(ctx,
+		`SELECT node_id, agent_version, activities, reported_at
+		 FROM node_capabilities WHERE node_id = $1`, nodeID,
+	).Scan(&c.NodeID, &c.AgentVersion, &c.Activities, &c.ReportedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoNodeCapabilities
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get capabilities for node %s: %w", nodeID, err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,45 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func TestNodeService_GetCapabilities_Success(t *testing.T) {
+	db := &mockDB{}
+	svc := NewNodeService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"node-1"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "node-1"
+			*(dest[1].(*string)) = "1.4.0"
+			*(dest[2].(*[]string)) = []string{"CreateWebroot", "ReportNodeCapabilities"}
+			*(dest[3].(*time.Time)) = time.Now()
+			return nil
+		}})
+
+	caps, err := svc.GetCapabilities(ctx, "node-1")
+	require.NoError(t, err)
+	assert.Equal(t, "1.4.0", caps.AgentVersion)
+	assert.Equal(t, []string{"CreateWebroot", "ReportNodeCapabilities"}, caps.Activities)
+}
+
+func TestNodeService_GetCapabilities_None(t *testing.T) {
+	db := &mockDB{}
+	svc := NewNodeService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).
+		Return(&mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }})
+
+	_, err := svc.GetCapabilities(ctx, "node-1")
+	assert.ErrorIs(t, err, ErrNoNodeCapabilities)
+}
@@ -0,0 +1,129 @@
+package core
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ListResources returns the tenants, webroots, databases, Valkey instances
+// and daemons hosted on a node, found through its shard assignments and the
+// direct node_id references of databases and daemons. Databases on a shard
+// the node is primary of, and anything referencing the node directly, are
+// marked pinned.
+func (s *NodeService) ListResources(ctx context.Context, nodeID string) (*model.NodeResources, error) {
+	res := &model.NodeResources{
+		NodeID:          nodeID,
+		Shards:          []model.NodeResourceShard{},
+		Tenants:         []model.NodeResource{},
+		Webroots:        []model.NodeResource{},
+		Databases:       []model.NodeResource{},
+		ValkeyInstances: []model.NodeResource{},
+		Daemons:         []model.NodeResource{},
+	}
+
+	// The primary falls back to the shard's first node by ID, matching how
+	// database workflows pick it when the shard config names none.
+	rows, err := s.db.Query(ctx,
+		`SELECT s.id, s.role,
+		        (SELECT COUNT(*) FROM node_shard_assignments x WHERE x.shard_id = s.id),
+		        COALESCE(NULLIF(s.config->>'primary_node_id', ''),
+		                 (SELECT MIN(x.node_id) FROM node_shard_assignments x WHERE x.shard_id = s.id))
+		 FROM node_shard_assignments nsa
+		 JOIN shards s ON s.id = nsa.shard_id
+		 WHERE nsa.node_id = $1
+		 ORDER BY s.role, s.id`, nodeID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list shards of node %s: %w", nodeID, err)
+	}
+	var shardIDs []string
+	primaryOf := make(map[string]bool)
+	for rows.Next() {
+		var sh model.NodeResourceShard
+		var primaryID string
+		if err := rows.Scan(&sh.ShardID, &sh.ShardRole, &sh.NodeCount, &primaryID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan node shard: %w", err)
+		}
+		sh.Primary = sh.ShardRole == model.ShardRoleDatabase && primaryID == nodeID
+		primaryOf[sh.ShardID] = sh.Primary
+		shardIDs = append(shardIDs, sh.ShardID)
+		res.Shards = append(res.Shards, sh)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate node shards: %w", err)
+	}
+
+	if len(shardIDs) > 0 {
+		res.Tenants, err = s.queryNodeResources(ctx,
+			`SELECT id, '', shard_id, status, NULL FROM tenants WHERE shard_id = ANY($1) ORDER BY id`, shardIDs)
+		if err != nil {
+			return nil, fmt.Errorf("list tenants on node %s: %w", nodeID, err)
+		}
+		res.Webroots, err = s.queryNodeResources(ctx,
+			`SELECT w.id, w.tenant_id, t.shard_id, w.status, NULL
+			 FROM webroots w JOIN tenants t ON t.id = w.tenant_id
+			 WHERE t.shard_id = ANY($1) ORDER BY w.id`, shardIDs)
+		if err != nil {
+			return nil, fmt.Errorf("list webroots on node %s: %w", nodeID, err)
+		}
+		res.ValkeyInstances, err = s.queryNodeResources(ctx,
+			`SELECT id, tenant_id, shard_id, status, NULL FROM valkey_instances WHERE shard_id = ANY($1) ORDER BY id`, shardIDs)
+		if err != nil {
+			return nil, fmt.Errorf("list valkey instances on node %s: %w", nodeID, err)
+		}
+	}
+
+	res.Databases, err = s.queryNodeResources(ctx,
+		`SELECT id, tenant_id, shard_id, status, CASE WHEN node_id = $2 THEN node_id END FROM databases
+		 WHERE shard_id = ANY($1) OR node_id = $2 ORDER BY id`, shardIDs, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("list databases on node %s: %w", nodeID, err)
+	}
+	for i := range res.Databases {
+		d := &res.Databases[i]
+		switch {
+		case d.Pinned:
+			d.PinReason = "database is placed on this node"
+		case d.ShardID != nil && primaryOf[*d.ShardID]:
+			d.Pinned = true
+			d.PinReason = "node is the database shard's primary"
+		}
+	}
+
+	res.Daemons, err = s.queryNodeResources(ctx,
+		`SELECT id, tenant_id, NULL, status, node_id FROM daemons WHERE node_id = $1 ORDER BY id`, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("list daemons on node %s: %w", nodeID, err)
+	}
+	for i := range res.Daemons {
+		res.Daemons[i].PinReason = "daemon runs on this node"
+	}
+
+	return res, nil
+}
+
+// queryNodeResources scans rows of (id, tenant_id, shard_id, status,
+// node_id). A non-NULL node_id marks the resource pinned to the node.
+func (s *NodeService) queryNodeResources(ctx context.Context, query string, args ...any) ([]model.NodeResource, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []model.NodeResource{}
+	for rows.Next() {
+		var r model.NodeResource
+		var pinnedTo *string
+		if err := rows.Scan(&r.ID, &r.TenantID, &r.ShardID, &r.Status, &pinnedTo); err != nil {
+			return nil, err
+		}
+		r.Pinned = pinnedTo != nil
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,108 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func queryContaining(s string) any {
+	return mock.MatchedBy(func(q string) bool { return strings.Contains(q, s) })
+}
+
+func resourceRow(id, tenantID, shardID string, nodeID *string) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = id
+		*(dest[1].(*string)) = tenantID
+		if shardID != "" {
+			*(dest[2].(**string)) = &shardID
+		}
+		*(dest[3].(*string)) = "active"
+		*(dest[4].(**string)) = nodeID
+		return nil
+	}
+}
+
+func TestNodeService_ListResources_PinsPrimaryDatabases(t *testing.T) {
+	db := &mockDB{}
+	svc := NewNodeService(db, nil)
+	ctx := context.Background()
+	node := "node-1"
+
+	db.On("Query", ctx, queryContaining("FROM node_shard_assignments nsa"), mock.Anything).
+		Return(newMockRows(
+			func(dest ...any) error {
+				*(dest[0].(*string)) = "db-shard"
+				*(dest[1].(*string)) = "database"
+				*(dest[2].(*int)) = 2
+				*(dest[3].(*string)) = node
+				return nil
+			},
+			func(dest ...any) error {
+				*(dest[0].(*string)) = "web-shard"
+				*(dest[1].(*string)) = "web"
+				*(dest[2].(*int)) = 3
+				*(dest[3].(*string)) = "node-0"
+				return nil
+			},
+		), nil)
+	db.On("Query", ctx, queryContaining("FROM tenants"), mock.Anything).
+		Return(newMockRows(resourceRow("t1", "", "web-shard", nil)), nil)
+	db.On("Query", ctx, queryContaining("FROM webroots"), mock.Anything).
+		Return(newMockRows(resourceRow("w1", "t1", "web-shard", nil)), nil)
+	db.On("Query", ctx, queryContaining("FROM valkey_instances"), mock.Anything).
+		Return(newEmptyMockRows(), nil)
+	db.On("Query", ctx, queryContaining("FROM databases"), mock.Anything).
+		Return(newMockRows(
+			resourceRow("d1", "t1", "db-shard", nil),
+			resourceRow("d2", "t1", "", &node),
+		), nil)
+	db.On("Query", ctx, queryContaining("FROM daemons"), mock.Anything).
+		Return(newMockRows(resourceRow("dm1", "t1", "", &node)), nil)
+
+	res, err := svc.ListResources(ctx, node)
+	require.NoError(t, err)
+
+	require.Len(t, res.Shards, 2)
+	assert.True(t, res.Shards[0].Primary)
+	assert.False(t, res.Shards[1].Primary, "only database shards have a primary")
+
+	require.Len(t, res.Tenants, 1)
+	assert.False(t, res.Tenants[0].Pinned)
+	require.Len(t, res.Webroots, 1)
+	assert.False(t, res.Webroots[0].Pinned)
+	assert.Empty(t, res.ValkeyInstances)
+
+	require.Len(t, res.Databases, 2)
+	assert.True(t, res.Databases[0].Pinned)
+	assert.Equal(t, "node is the database shard's primary", res.Databases[0].PinReason)
+	assert.True(t, res.Databases[1].Pinned)
+	assert.Equal(t, "database is placed on this node", res.Databases[1].PinReason)
+
+	require.Len(t, res.Daemons, 1)
+	assert.True(t, res.Daemons[0].Pinned)
+}
+
+func TestNodeService_ListResources_NoShards(t *testing.T) {
+	db := &mockDB{}
+	svc := NewNodeService(db, nil)
+	ctx := context.Background()
+
+	db.On("Query", ctx, queryContaining("FROM node_shard_assignments nsa"), mock.Anything).
+		Return(newEmptyMockRows(), nil)
+	db.On("Query", ctx, queryContaining("FROM databases"), mock.Anything).
+		Return(newEmptyMockRows(), nil)
+	db.On("Query", ctx, queryContaining("FROM daemons"), mock.Anything).
+		Return(newEmptyMockRows(), nil)
+
+	res, err := svc.ListResources(ctx, "node-1")
+	require.NoError(t, err)
+	assert.Empty(t, res.Shards)
+	assert.NotNil(t, res.Tenants)
+	assert.Empty(t, res.Databases)
+	db.AssertNotCalled(t, "Query", ctx, queryContaining("FROM tenants"), mock.Anything)
+}
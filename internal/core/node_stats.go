@@ -0,0 +1,40 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNoNodeStats is returned when no stats have been collected for a node yet.
+var ErrNoNodeStats = errors.New("no stats collected for node")
+
+// GetStats returns the latest CPU, memory and disk snapshot stored for the
+// node by CollectNodeStatsWorkflow, or ErrNoNodeStats if there is none.
+func (s *NodeService) GetStats(ctx context.Context, nodeID string) (*model.NodeStats, error) {
+	var st model.NodeStats
+	var disks, errs []byte
+	err := s.db.QueryRow(ctx,
+		`SELECT node_id, cpu_count, load_1, load_5, load_15,
+		   mem_total_bytes, mem_available_bytes, swap_total_bytes, swap_free_bytes, disks, errors, collected_at
+		 FROM node_stats WHERE node_id = $1`, nodeID,
+	).Scan(&st.NodeID, &st.CPUCount, &st.Load1, &st.Load5, &st.Load15,
+		&st.MemTotalBytes, &st.MemAvailableBytes, &st.SwapTotalBytes, &st.SwapFreeBytes, &disks, &errs, &st.CollectedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoNodeStats
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get stats for node %s: %w", nodeID, err)
+	}
+	if err := json.Unmarshal(disks, &st.Disks); err != nil {
+		return nil, fmt.Errorf("unmarshal disks for node %s: %w", nodeID, err)
+	}
+	if err := json.Unmarshal(errs, &st.Errors); err != nil {
+		return nil, fmt.Errorf("unmarshal stats errors for node %s: %w", nodeID, err)
+	}
+	return &st, nil
+}
@@ -0,0 +1,52 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func TestNodeService_GetStats_Success(t *testing.T) {
+	db := &mockDB{}
+	svc := NewNodeService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"node-1"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "node-1"
+			*(dest[1].(*int)) = 4
+			load := 1.5
+			*(dest[2].(**float64)) = &load
+			*(dest[9].(*[]byte)) = []byte(`[{"path":"/","fs_type":"ext4","total_bytes":100,"used_bytes":40,"free_bytes":60,"used_pct":40}]`)
+			*(dest[10].(*[]byte)) = []byte(`["meminfo: permission denied"]`)
+			*(dest[11].(*time.Time)) = time.Now()
+			return nil
+		}})
+
+	stats, err := svc.GetStats(ctx, "node-1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, stats.CPUCount)
+	assert.Equal(t, 1.5, *stats.Load1)
+	assert.Nil(t, stats.MemTotalBytes)
+	require.Len(t, stats.Disks, 1)
+	assert.Equal(t, "ext4", stats.Disks[0].FSType)
+	assert.Equal(t, []string{"meminfo: permission denied"}, stats.Errors)
+}
+
+func TestNodeService_GetStats_None(t *testing.T) {
+	db := &mockDB{}
+	svc := NewNodeService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).
+		Return(&mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }})
+
+	_, err := svc.GetStats(ctx, "node-1")
+	assert.ErrorIs(t, err, ErrNoNodeStats)
+}
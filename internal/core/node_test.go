@@ -12,11 +12,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
 )
 
 func TestNewNodeService(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 
 	require.NotNil(t, svc)
 	assert.Equal(t, db, svc.db)
@@ -26,7 +27,7 @@ func TestNewNodeService(t *testing.T) {
 
 func TestNodeService_Create_Success(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	ip := "10.0.0.10"
@@ -56,7 +57,7 @@ func TestNodeService_Create_Success(t *testing.T) {
 
 func TestNodeService_Create_DBError(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	node := &model.Node{ID: "test-node-1", Hostname: "node-1"}
@@ -73,7 +74,7 @@ func TestNodeService_Create_DBError(t *testing.T) {
 
 func TestNodeService_GetByID_Success(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	nodeID := "test-node-1"
@@ -115,7 +116,7 @@ func TestNodeService_GetByID_Success(t *testing.T) {
 
 func TestNodeService_GetByID_NotFound(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	row := &mockRow{scanFunc: func(dest ...any) error {
@@ -134,7 +135,7 @@ func TestNodeService_GetByID_NotFound(t *testing.T) {
 
 func TestNodeService_ListByCluster_Success(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	clusterID := "test-cluster-1"
@@ -154,6 +155,8 @@ func TestNodeService_ListByCluster_Success(t *testing.T) {
 			*(dest[6].(*string)) = model.StatusActive
 			*(dest[7].(*time.Time)) = now
 			*(dest[8].(*time.Time)) = now
+			version := "1.4.0"
+			*(dest[9].(**string)) = &version
 			return nil
 		},
 		func(dest ...any) error {
@@ -182,13 +185,15 @@ func TestNodeService_ListByCluster_Success(t *testing.T) {
 	assert.False(t, hasMore)
 	require.Len(t, result, 2)
 	assert.Equal(t, "node-1", result[0].Hostname)
+	assert.Equal(t, "1.4.0", *result[0].AgentVersion)
 	assert.Equal(t, "node-2", result[1].Hostname)
+	assert.Nil(t, result[1].AgentVersion)
 	db.AssertExpectations(t)
 }
 
 func TestNodeService_ListByCluster_Empty(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	rows := newEmptyMockRows()
@@ -203,7 +208,7 @@ func TestNodeService_ListByCluster_Empty(t *testing.T) {
 
 func TestNodeService_ListByCluster_QueryError(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(nil, errors.New("connection lost"))
@@ -219,7 +224,7 @@ func TestNodeService_ListByCluster_QueryError(t *testing.T) {
 
 func TestNodeService_ListByShard_Success(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	shardID := "test-shard-1"
@@ -276,7 +281,7 @@ func TestNodeService_ListByShard_Success(t *testing.T) {
 
 func TestNodeService_ListByShard_Empty(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	rows := newEmptyMockRows()
@@ -291,7 +296,7 @@ func TestNodeService_ListByShard_Empty(t *testing.T) {
 
 func TestNodeService_ListByShard_QueryError(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(nil, errors.New("connection lost"))
@@ -305,7 +310,7 @@ func TestNodeService_ListByShard_QueryError(t *testing.T) {
 
 func TestNodeService_ListByShard_RowsErr(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	rows := newEmptyMockRows()
@@ -323,7 +328,7 @@ func TestNodeService_ListByShard_RowsErr(t *testing.T) {
 
 func TestNodeService_Update_Success(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	updIP := "10.0.0.20"
@@ -349,7 +354,7 @@ func TestNodeService_Update_Success(t *testing.T) {
 
 func TestNodeService_Update_DBError(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	node := &model.Node{ID: "test-node-1"}
@@ -365,7 +370,7 @@ func TestNodeService_Update_DBError(t *testing.T) {
 
 func TestNodeService_Delete_Success(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
@@ -377,7 +382,7 @@ func TestNodeService_Delete_Success(t *testing.T) {
 
 func TestNodeService_Delete_DBError(t *testing.T) {
 	db := &mockDB{}
-	svc := NewNodeService(db)
+	svc := NewNodeService(db, nil)
 	ctx := context.Background()
 
 	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, errors.New("foreign key violation"))
@@ -387,3 +392,46 @@ func TestNodeService_Delete_DBError(t *testing.T) {
 	assert.Contains(t, err.Error(), "delete node")
 	db.AssertExpectations(t)
 }
+
+// ---------- Diagnostics ----------
+
+func TestNodeService_Diagnostics_Success(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewNodeService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	node := &model.Node{ID: "test-node-1", Roles: []string{"web"}}
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*model.NodeDiagnostics)) = model.NodeDiagnostics{
+			NodeID:  "test-node-1",
+			Healthy: false,
+			Checks:  []model.NodeDiagnosticCheck{{Name: "nginx", Error: "nginx -t failed"}},
+		}
+	}).Return(nil)
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "NodeDiagnosticsWorkflow", nodeDiagnosticsArgs{NodeID: "test-node-1", Roles: []string{"web"}}).Return(wfRun, nil)
+
+	report, err := svc.Diagnostics(ctx, node)
+	require.NoError(t, err)
+	assert.False(t, report.Healthy)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "nginx", report.Checks[0].Name)
+	tc.AssertExpectations(t)
+}
+
+func TestNodeService_Diagnostics_WorkflowError(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewNodeService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	node := &model.Node{ID: "test-node-1", Roles: []string{"web"}}
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Return(errors.New("activity schedule-to-start timeout"))
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "NodeDiagnosticsWorkflow", mock.Anything).Return(wfRun, nil)
+
+	_, err := svc.Diagnostics(ctx, node)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "diagnostics for node test-node-1")
+}
@@ -178,7 +178,7 @@ func (s *OIDCService) ValidateLoginSession(ctx context.Context, sessionID string
 	var sess model.OIDCLoginSession
 	err := s.db.QueryRow(ctx,
 		`UPDATE oidc_login_sessions SET used = true
-		 WHERE id = $1 AND used = false AND expires_at > now()
+		 WHERE id = $1 AND used = false AND expires_at > now() AND revoked_at IS NULL
 		 RETURNING id, tenant_id, database_id, expires_at`,
 		sessionID,
 	).Scan(&sess.ID, &sess.TenantID, &sess.DatabaseID, &sess.ExpiresAt)
@@ -198,13 +198,18 @@ type DatabaseConnectionInfo struct {
 }
 
 // GetDatabaseConnectionInfo looks up the database name and its primary node IP.
+// The primary is the shard's config.primary_node_id, which changes when a
+// replica is promoted, falling back to the shard's first node.
 // If tenantID is non-empty, the database must belong to that tenant.
 func (s *OIDCService) GetDatabaseConnectionInfo(ctx context.Context, databaseID, tenantID string) (*DatabaseConnectionInfo, error) {
 	var info DatabaseConnectionInfo
 	query := `
 		SELECT d.id, d.id, COALESCE(host(n.ip_address), ''), 3306
 		FROM databases d
-		LEFT JOIN node_shard_assignments ns ON ns.shard_id = d.shard_id AND ns.shard_index = 1
+		LEFT JOIN shards sh ON sh.id = d.shard_id
+		LEFT JOIN node_shard_assignments ns ON ns.shard_id = d.shard_id
+			AND (ns.node_id = sh.config->>'primary_node_id'
+				OR (COALESCE(sh.config->>'primary_node_id', '') = '' AND ns.shard_index = 1))
 		LEFT JOIN nodes n ON n.id = ns.node_id
 		WHERE d.id = $1`
 	args := []any{databaseID}
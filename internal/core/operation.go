@@ -0,0 +1,189 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	enumspb "go.temporal.io/api/enums/v1"
+	temporalclient "go.temporal.io/sdk/client"
+)
+
+// operationsKey is a context key that enables operation tracking.
+type operationsKey struct{}
+
+// operationLog collects the IDs of operations recorded during one request.
+type operationLog struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+// WithOperationTracking returns a context in which every workflow started
+// through signalProvision or startWorkflow is recorded as an Operation. The
+// IDs are available from OperationIDs without changing any service method
+// signatures.
+func WithOperationTracking(ctx context.Context) context.Context {
+	return context.WithValue(ctx, operationsKey{}, &operationLog{})
+}
+
+// OperationIDs returns the IDs of the operations recorded in ctx, in the
+// order their workflows were started.
+func OperationIDs(ctx context.Context) []string {
+	log, ok := ctx.Value(operationsKey{}).(*operationLog)
+	if !ok {
+		return nil
+	}
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return append([]string(nil), log.ids...)
+}
+
+// recordOperation inserts a pending operation for task when tracking is
+// enabled in ctx. It returns the operation ID, or "" when untracked.
+func recordOperation(ctx context.Context, db DB, tenantID string, task model.ProvisionTask) (string, error) {
+	log, ok := ctx.Value(operationsKey{}).(*operationLog)
+	if !ok {
+		return "", nil
+	}
+
+	id := platform.NewID()
+	var tenant *string
+	if tenantID != "" {
+		tenant = &tenantID
+	}
+	_, err := db.Exec(ctx,
+		`INSERT INTO operations (id, tenant_id, workflow_name, workflow_id, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, now(), now())`,
+		id, tenant, task.WorkflowName, task.WorkflowID, model.OperationPending,
+	)
+	if err != nil {
+		return "", fmt.Errorf("insert operation for %s: %w", task.WorkflowID, err)
+	}
+
+	log.mu.Lock()
+	log.ids = append(log.ids, id)
+	log.mu.Unlock()
+	return id, nil
+}
+
+// finishOperationStart records the outcome of starting an operation's
+// workflow: running with the run ID on success, failed otherwise. Failures to
+// update the row are ignored; the start error is what the caller reports.
+func finishOperationStart(ctx context.Context, db DB, operationID string, run temporalclient.WorkflowRun, startErr error) {
+	if operationID == "" {
+		return
+	}
+	if startErr != nil {
+		_, _ = db.Exec(ctx,
+			`UPDATE operations SET status = $1, error = $2, completed_at = now(), updated_at = now() WHERE id = $3`,
+			model.OperationFailed, startErr.Error(), operationID,
+		)
+		return
+	}
+	if run == nil {
+		return
+	}
+	_, _ = db.Exec(ctx,
+		`UPDATE operations SET status = $1, run_id = $2, started_at = now(), updated_at = now() WHERE id = $3`,
+		model.OperationRunning, run.GetRunID(), operationID,
+	)
+}
+
+// operationProgressQueries maps the workflows that report their progress to
+// the workflow query returning it.
+var operationProgressQueries = map[string]string{
+	"BulkProvisionCertsWorkflow": "bulk-cert-progress",
+}
+
+type OperationService struct {
+	db DB
+	tc temporalclient.Client
+}
+
+func NewOperationService(db DB, tc temporalclient.Client) *OperationService {
+	return &OperationService{db: db, tc: tc}
+}
+
+// GetByID returns an operation. Running operations are reconciled with their
+// workflow execution first, so the status is current even for workflows that
+// were started directly rather than through the tenant's provision queue.
+func (s *OperationService) GetByID(ctx context.Context, id string) (*model.Operation, error) {
+	var op model.Operation
+	err := s.db.QueryRow(ctx,
+		`SELECT id, tenant_id, workflow_name, workflow_id, run_id, status, error, started_at, completed_at, created_at, updated_at
+		 FROM operations WHERE id = $1`, id,
+	).Scan(&op.ID, &op.TenantID, &op.WorkflowName, &op.WorkflowID, &op.RunID, &op.Status, &op.Error,
+		&op.StartedAt, &op.CompletedAt, &op.CreatedAt, &op.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get operation %s: %w", id, err)
+	}
+
+	if op.Status == model.OperationRunning && op.RunID != nil {
+		if err := s.reconcile(ctx, &op); err != nil {
+			return nil, err
+		}
+	}
+	s.progress(ctx, &op)
+	return &op, nil
+}
+
+// progress sets the operation's progress if its workflow reports one. Closed
+// workflows answer the query until their history is purged; after that, or
+// if the query fails, the operation has no progress.
+func (s *OperationService) progress(ctx context.Context, op *model.Operation) {
+	query, ok := operationProgressQueries[op.WorkflowName]
+	if !ok || op.RunID == nil {
+		return
+	}
+	val, err := s.tc.QueryWorkflow(ctx, op.WorkflowID, *op.RunID, query)
+	if err != nil {
+		return
+	}
+	var progress any
+	if err := val.Get(&progress); err == nil {
+		op.Progress = progress
+	}
+}
+
+// reconcile updates a running operation from its workflow execution. If the
+// execution can no longer be described (e.g. its history has been purged),
+// the last recorded status is kept.
+func (s *OperationService) reconcile(ctx context.Context, op *model.Operation) error {
+	desc, err := s.tc.DescribeWorkflowExecution(ctx, op.WorkflowID, *op.RunID)
+	if err != nil {
+		return nil
+	}
+	info := desc.GetWorkflowExecutionInfo()
+
+	switch info.GetStatus() {
+	case enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING, enumspb.WORKFLOW_EXECUTION_STATUS_CONTINUED_AS_NEW:
+		return nil
+	case enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED:
+		op.Status = model.OperationSucceeded
+	default:
+		op.Status = model.OperationFailed
+		msg := info.GetStatus().String()
+		if err := s.tc.GetWorkflow(ctx, op.WorkflowID, *op.RunID).Get(ctx, nil); err != nil {
+			msg = err.Error()
+		}
+		op.Error = &msg
+	}
+
+	completedAt := time.Now()
+	if info.GetCloseTime() != nil {
+		completedAt = info.GetCloseTime().AsTime()
+	}
+	op.CompletedAt = &completedAt
+
+	_, err = s.db.Exec(ctx,
+		`UPDATE operations SET status = $1, error = $2, completed_at = $3, updated_at = now() WHERE id = $4`,
+		op.Status, op.Error, op.CompletedAt, op.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update operation %s: %w", op.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	commonpb "go.temporal.io/api/common/v1"
+	enumspb "go.temporal.io/api/enums/v1"
+	failurepb "go.temporal.io/api/failure/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/sdk/converter"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ErrOperationHistoryUnavailable is returned when an operation's workflow
+// has no history in Temporal anymore, e.g. after the namespace retention
+// period.
+var ErrOperationHistoryUnavailable = errors.New("workflow history is not available")
+
+// maxHistoryEvents bounds how many events of a workflow's history are read.
+const maxHistoryEvents = 5000
+
+// maxInputStringLen is the length above which strings in step inputs are
+// cut short, so file contents and certificates don't bloat the response.
+const maxInputStringLen = 512
+
+const redacted = "[redacted]"
+
+// sensitiveKeyParts mark input fields that are never returned. Keys are
+// compared lowercased and without '_' and '-'.
+var sensitiveKeyParts = []string{
+	"password", "passwd", "secret", "token", "privatekey", "keypem",
+	"apikey", "accesskey", "credential", "basicauth", "envvars", "dek",
+}
+
+// History returns the summarized event history of an operation's workflow:
+// the activities, child workflows and timers it ran, with their outcomes and
+// errors. Inputs are decoded with secrets redacted. For running workflows
+// the attempt and last error of activities that are being retried come from
+// the pending activities, as Temporal only records them in the history once
+// the activity finishes.
+func (s *OperationService) History(ctx context.Context, id string) (*model.OperationHistory, error) {
+	op, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &model.OperationHistory{
+		OperationID:  op.ID,
+		WorkflowName: op.WorkflowName,
+		WorkflowID:   op.WorkflowID,
+		RunID:        op.RunID,
+		Status:       op.Status,
+		Error:        op.Error,
+		Steps:        []model.OperationHistoryStep{},
+	}
+	if op.RunID == nil {
+		return history, nil
+	}
+
+	b := newHistoryBuilder()
+	iter := s.tc.GetWorkflowHistory(ctx, op.WorkflowID, *op.RunID, false, enumspb.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+	for iter.HasNext() {
+		if b.events == maxHistoryEvents {
+			history.Truncated = true
+			break
+		}
+		event, err := iter.Next()
+		if err != nil {
+			var notFound *serviceerror.NotFound
+			if errors.As(err, &notFound) {
+				return nil, fmt.Errorf("%w: %v", ErrOperationHistoryUnavailable, err)
+			}
+			return nil, fmt.Errorf("get history of workflow %s: %w", op.WorkflowID, err)
+		}
+		b.add(event)
+	}
+
+	if op.Status == model.OperationRunning {
+		// Best effort: without it, retried activities just show as scheduled.
+		if desc, err := s.tc.DescribeWorkflowExecution(ctx, op.WorkflowID, *op.RunID); err == nil {
+			for _, pa := range desc.GetPendingActivities() {
+				b.pendingActivity(pa.GetActivityId(), pa.GetAttempt(), pa.GetLastFailure())
+			}
+		}
+	}
+
+	history.Steps = b.finish()
+	return history, nil
+}
+
+// historyBuilder folds workflow history events into steps.
+type historyBuilder struct {
+	steps        []model.OperationHistoryStep
+	byEventID    map[int64]int  // scheduling event ID -> step index
+	byActivityID map[string]int // activity ID -> step index
+	workflow     int            // index of the workflow step, -1 until seen
+	events       int
+}
+
+func newHistoryBuilder() *historyBuilder {
+	return &historyBuilder{
+		byEventID:    map[int64]int{},
+		byActivityID: map[string]int{},
+		workflow:     -1,
+	}
+}
+
+func (b *historyBuilder) add(event *historypb.HistoryEvent) {
+	b.events++
+	at := event.GetEventTime().AsTime()
+
+	switch event.GetEventType() {
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_STARTED:
+		attrs := event.GetWorkflowExecutionStartedEventAttributes()
+		b.workflow = b.start(event, model.HistoryStepWorkflow, attrs.GetWorkflowType().GetName(), attrs.GetInput())
+		b.steps[b.workflow].Status = model.HistoryStepStarted
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_COMPLETED:
+		b.finishStep(b.workflow, at, model.HistoryStepCompleted, "")
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_FAILED:
+		b.finishStep(b.workflow, at, model.HistoryStepFailed, failureMessage(event.GetWorkflowExecutionFailedEventAttributes().GetFailure()))
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TIMED_OUT:
+		b.finishStep(b.workflow, at, model.HistoryStepTimedOut, "workflow timed out")
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CANCELED:
+		b.finishStep(b.workflow, at, model.HistoryStepCanceled, "")
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_TERMINATED:
+		b.finishStep(b.workflow, at, model.HistoryStepTerminated, event.GetWorkflowExecutionTerminatedEventAttributes().GetReason())
+	case enumspb.EVENT_TYPE_WORKFLOW_EXECUTION_CONTINUED_AS_NEW:
+		b.finishStep(b.workflow, at, model.HistoryStepContinuedAsNew, "")
+
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_SCHEDULED:
+		attrs := event.GetActivityTaskScheduledEventAttributes()
+		i := b.start(event, model.HistoryStepActivity, attrs.GetActivityType().GetName(), attrs.GetInput())
+		b.byEventID[event.GetEventId()] = i
+		b.byActivityID[attrs.GetActivityId()] = i
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_STARTED:
+		attrs := event.GetActivityTaskStartedEventAttributes()
+		if i, ok := b.byEventID[attrs.GetScheduledEventId()]; ok {
+			b.steps[i].Status = model.HistoryStepStarted
+			b.steps[i].Attempt = attrs.GetAttempt()
+			if attrs.GetLastFailure() != nil {
+				b.steps[i].Error = failureMessage(attrs.GetLastFailure())
+			}
+		}
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_COMPLETED:
+		attrs := event.GetActivityTaskCompletedEventAttributes()
+		b.finishEvent(attrs.GetScheduledEventId(), at, model.HistoryStepCompleted, "")
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_FAILED:
+		attrs := event.GetActivityTaskFailedEventAttributes()
+		b.finishEvent(attrs.GetScheduledEventId(), at, model.HistoryStepFailed, failureMessage(attrs.GetFailure()))
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_TIMED_OUT:
+		attrs := event.GetActivityTaskTimedOutEventAttributes()
+		b.finishEvent(attrs.GetScheduledEventId(), at, model.HistoryStepTimedOut, failureMessage(attrs.GetFailure()))
+	case enumspb.EVENT_TYPE_ACTIVITY_TASK_CANCELED:
+		attrs := event.GetActivityTaskCanceledEventAttributes()
+		b.finishEvent(attrs.GetScheduledEventId(), at, model.HistoryStepCanceled, "")
+
+	case enumspb.EVENT_TYPE_START_CHILD_WORKFLOW_EXECUTION_INITIATED:
+		attrs := event.GetStartChildWorkflowExecutionInitiatedEventAttributes()
+		i := b.start(event, model.HistoryStepChildWorkflow, attrs.GetWorkflowType().GetName(), attrs.GetInput())
+		b.steps[i].WorkflowID = attrs.GetWorkflowId()
+		b.byEventID[event.GetEventId()] = i
+	case enumspb.EVENT_TYPE_START_CHILD_WORKFLOW_EXECUTION_FAILED:
+		attrs := event.GetStartChildWorkflowExecutionFailedEventAttributes()
+		b.finishEvent(attrs.GetInitiatedEventId(), at, model.HistoryStepFailed, "could not start: "+attrs.GetCause().String())
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_STARTED:
+		if i, ok := b.byEventID[event.GetChildWorkflowExecutionStartedEventAttributes().GetInitiatedEventId()]; ok {
+			b.steps[i].Status = model.HistoryStepStarted
+		}
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_COMPLETED:
+		attrs := event.GetChildWorkflowExecutionCompletedEventAttributes()
+		b.finishEvent(attrs.GetInitiatedEventId(), at, model.HistoryStepCompleted, "")
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_FAILED:
+		attrs := event.GetChildWorkflowExecutionFailedEventAttributes()
+		b.finishEvent(attrs.GetInitiatedEventId(), at, model.HistoryStepFailed, failureMessage(attrs.GetFailure()))
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_TIMED_OUT:
+		attrs := event.GetChildWorkflowExecutionTimedOutEventAttributes()
+		b.finishEvent(attrs.GetInitiatedEventId(), at, model.HistoryStepTimedOut, "child workflow timed out")
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_CANCELED:
+		attrs := event.GetChildWorkflowExecutionCanceledEventAttributes()
+		b.finishEvent(attrs.GetInitiatedEventId(), at, model.HistoryStepCanceled, "")
+	case enumspb.EVENT_TYPE_CHILD_WORKFLOW_EXECUTION_TERMINATED:
+		attrs := event.GetChildWorkflowExecutionTerminatedEventAttributes()
+		b.finishEvent(attrs.GetInitiatedEventId(), at, model.HistoryStepTerminated, "")
+
+	case enumspb.EVENT_TYPE_TIMER_STARTED:
+		attrs := event.GetTimerStartedEventAttributes()
+		i := b.start(event, model.HistoryStepTimer, attrs.GetStartToFireTimeout().AsDuration().String(), nil)
+		b.steps[i].Status = model.HistoryStepStarted
+		b.byEventID[event.GetEventId()] = i
+	case enumspb.EVENT_TYPE_TIMER_FIRED:
+		b.finishEvent(event.GetTimerFiredEventAttributes().GetStartedEventId(), at, model.HistoryStepCompleted, "")
+	case enumspb.EVENT_TYPE_TIMER_CANCELED:
+		b.finishEvent(event.GetTimerCanceledEventAttributes().GetStartedEventId(), at, model.HistoryStepCanceled, "")
+	}
+}
+
+// start appends a scheduled step for event and returns its index.
+func (b *historyBuilder) start(event *historypb.HistoryEvent, kind, name string, input *commonpb.Payloads) int {
+	b.steps = append(b.steps, model.OperationHistoryStep{
+		EventID:   event.GetEventId(),
+		Kind:      kind,
+		Name:      name,
+		Status:    model.HistoryStepScheduled,
+		Input:     decodeInput(input),
+		StartedAt: event.GetEventTime().AsTime(),
+	})
+	return len(b.steps) - 1
+}
+
+func (b *historyBuilder) finishEvent(eventID int64, at time.Time, status, errMsg string) {
+	if i, ok := b.byEventID[eventID]; ok {
+		b.finishStep(i, at, status, errMsg)
+	}
+}
+
+func (b *historyBuilder) finishStep(i int, at time.Time, status, errMsg string) {
+	if i < 0 {
+		return
+	}
+	step := &b.steps[i]
+	step.Status = status
+	step.FinishedAt = &at
+	step.DurationMS = at.Sub(step.StartedAt).Milliseconds()
+	if errMsg != "" || status == model.HistoryStepCompleted {
+		// A successful attempt clears the error of a failed earlier one.
+		step.Error = errMsg
+	}
+}
+
+// pendingActivity adds the retry state of an activity that has not finished.
+func (b *historyBuilder) pendingActivity(activityID string, attempt int32, lastFailure *failurepb.Failure) {
+	i, ok := b.byActivityID[activityID]
+	if !ok {
+		return
+	}
+	b.steps[i].Attempt = attempt
+	if lastFailure != nil {
+		b.steps[i].Error = failureMessage(lastFailure)
+	}
+}
+
+// finish fills in the summaries and returns the steps.
+func (b *historyBuilder) finish() []model.OperationHistoryStep {
+	if b.steps == nil {
+		return []model.OperationHistoryStep{}
+	}
+	for i := range b.steps {
+		b.steps[i].Summary = stepSummary(&b.steps[i])
+	}
+	return b.steps
+}
+
+// stepSummary describes a step in one line, e.g.
+// "CreateWebroot failed after 3 attempts: node unreachable".
+func stepSummary(step *model.OperationHistoryStep) string {
+	var sb strings.Builder
+	sb.WriteString(step.Name)
+	sb.WriteString(" ")
+	sb.WriteString(strings.ReplaceAll(step.Status, "_", " "))
+	if step.FinishedAt != nil {
+		fmt.Fprintf(&sb, " in %s", (time.Duration(step.DurationMS) * time.Millisecond).String())
+	}
+	if step.Attempt > 1 {
+		if step.FinishedAt != nil {
+			fmt.Fprintf(&sb, " after %d attempts", step.Attempt)
+		} else {
+			fmt.Fprintf(&sb, ", attempt %d", step.Attempt)
+		}
+	}
+	if step.Error != "" {
+		if step.FinishedAt == nil || step.Status == model.HistoryStepCompleted {
+			sb.WriteString("; last error: ")
+		} else {
+			sb.WriteString(": ")
+		}
+		sb.WriteString(step.Error)
+	}
+	return sb.String()
+}
+
+// failureMessage joins the messages of a failure and its causes, e.g.
+// "activity error: node unreachable".
+func failureMessage(f *failurepb.Failure) string {
+	var parts []string
+	for ; f != nil; f = f.GetCause() {
+		msg := f.GetMessage()
+		if msg != "" && (len(parts) == 0 || parts[len(parts)-1] != msg) {
+			parts = append(parts, msg)
+		}
+	}
+	return strings.Join(parts, ": ")
+}
+
+// decodeInput decodes the arguments of a workflow, activity or child
+// workflow with secrets redacted. A single argument is returned as is,
+// several as a list.
+func decodeInput(payloads *commonpb.Payloads) any {
+	if payloads == nil || len(payloads.GetPayloads()) == 0 {
+		return nil
+	}
+	dc := converter.GetDefaultDataConverter()
+	args := make([]any, 0, len(payloads.GetPayloads()))
+	for _, p := range payloads.GetPayloads() {
+		var v any
+		if err := dc.FromPayload(p, &v); err != nil {
+			args = append(args, "[undecodable]")
+			continue
+		}
+		args = append(args, redactInput(v))
+	}
+	if len(args) == 1 {
+		return args[0]
+	}
+	return args
+}
+
+// redactInput replaces the values of sensitive fields and private keys in a
+// decoded input, and shortens long strings.
+func redactInput(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if isSensitiveKey(k) && !isEmptyInput(val) {
+				t[k] = redacted
+			} else {
+				t[k] = redactInput(val)
+			}
+		}
+	case []any:
+		for i := range t {
+			t[i] = redactInput(t[i])
+		}
+	case string:
+		if strings.Contains(t, "PRIVATE KEY-----") {
+			return redacted
+		}
+		if len(t) > maxInputStringLen {
+			return fmt.Sprintf("%s... (%d bytes)", t[:maxInputStringLen], len(t))
+		}
+	}
+	return v
+}
+
+func isSensitiveKey(key string) bool {
+	k := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(k, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func isEmptyInput(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case string:
+		return t == ""
+	case map[string]any:
+		return len(t) == 0
+	case []any:
+		return len(t) == 0
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	temporalmocks "go.temporal.io/sdk/mocks"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ---------- Tracking ----------
+
+func TestSignalProvision_Untracked_RecordsNothing(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	ctx := context.Background()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("SignalWithStartWorkflow", ctx, "tenant-test-tenant-1", model.ProvisionSignalName,
+		mock.MatchedBy(func(task model.ProvisionTask) bool { return task.OperationID == "" }),
+		mock.Anything, "TenantProvisionWorkflow").Return(wfRun, nil)
+
+	err := signalProvision(ctx, tc, db, "test-tenant-1", model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   "webroot-test-webroot-1",
+		Arg:          "test-webroot-1",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, OperationIDs(ctx))
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+	tc.AssertExpectations(t)
+}
+
+func TestSignalProvision_Tracked_RecordsOperation(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	ctx := WithOperationTracking(context.Background())
+
+	db.On("Exec", ctx, mock.MatchedBy(func(sql string) bool {
+		return strings.HasPrefix(sql, "INSERT INTO operations")
+	}), mock.MatchedBy(func(args []any) bool {
+		tenant, _ := args[1].(*string)
+		return tenant != nil && *tenant == "test-tenant-1" &&
+			args[2] == "UpdateWebrootWorkflow" && args[4] == model.OperationPending
+	})).Return(pgconn.CommandTag{}, nil)
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	var signalled model.ProvisionTask
+	tc.On("SignalWithStartWorkflow", ctx, "tenant-test-tenant-1", model.ProvisionSignalName,
+		mock.Anything, mock.Anything, "TenantProvisionWorkflow").
+		Run(func(args mock.Arguments) { signalled = args.Get(3).(model.ProvisionTask) }).
+		Return(wfRun, nil)
+
+	err := signalProvision(ctx, tc, db, "test-tenant-1", model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   "webroot-test-webroot-1",
+		Arg:          "test-webroot-1",
+	})
+	require.NoError(t, err)
+
+	ids := OperationIDs(ctx)
+	require.Len(t, ids, 1)
+	assert.Equal(t, ids[0], signalled.OperationID)
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestSignalProvision_Tracked_SignalErrorFailsOperation(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	ctx := WithOperationTracking(context.Background())
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil).Twice()
+	tc.On("SignalWithStartWorkflow", ctx, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil, errors.New("temporal down"))
+
+	err := signalProvision(ctx, tc, db, "test-tenant-1", model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   "webroot-test-webroot-1",
+	})
+	require.Error(t, err)
+
+	// The second Exec marks the operation failed with the start error.
+	last := db.Calls[len(db.Calls)-1]
+	args := last.Arguments.Get(2).([]any)
+	assert.Equal(t, model.OperationFailed, args[0])
+	assert.Equal(t, "temporal down", args[1])
+	db.AssertExpectations(t)
+}
+
+func TestStartWorkflow_Tracked_RecordsRunID(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	ctx := WithOperationTracking(context.Background())
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil).Twice()
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetRunID").Return("run-1")
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "ConvergeShardWorkflow", mock.Anything).Return(wfRun, nil)
+
+	err := startWorkflow(ctx, tc, db, "", model.ProvisionTask{
+		WorkflowName: "ConvergeShardWorkflow",
+		WorkflowID:   "converge-shard-test-shard-1",
+	})
+	require.NoError(t, err)
+	require.Len(t, OperationIDs(ctx), 1)
+
+	last := db.Calls[len(db.Calls)-1]
+	args := last.Arguments.Get(2).([]any)
+	assert.Equal(t, model.OperationRunning, args[0])
+	assert.Equal(t, "run-1", args[1])
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+// ---------- GetByID ----------
+
+func operationRow(status string, runID *string) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		tenantID := "test-tenant-1"
+		*(dest[0].(*string)) = "test-op-1"
+		*(dest[1].(**string)) = &tenantID
+		*(dest[2].(*string)) = "ConvergeShardWorkflow"
+		*(dest[3].(*string)) = "converge-shard-test-shard-1"
+		*(dest[4].(**string)) = runID
+		*(dest[5].(*string)) = status
+		*(dest[6].(**string)) = nil
+		*(dest[9].(*time.Time)) = time.Now()
+		*(dest[10].(*time.Time)) = time.Now()
+		return nil
+	}}
+}
+
+func describeOperation(status enumspb.WorkflowExecutionStatus) *workflowservice.DescribeWorkflowExecutionResponse {
+	return &workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflow.WorkflowExecutionInfo{
+			Status:    status,
+			CloseTime: timestamppb.Now(),
+		},
+	}
+}
+
+func TestOperationService_GetByID_Pending(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewOperationService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-op-1"}).Return(operationRow(model.OperationPending, nil))
+
+	op, err := svc.GetByID(ctx, "test-op-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.OperationPending, op.Status)
+	tc.AssertNotCalled(t, "DescribeWorkflowExecution", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOperationService_GetByID_ReconcilesSucceeded(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewOperationService(db, tc)
+	ctx := context.Background()
+
+	runID := "run-1"
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-op-1"}).Return(operationRow(model.OperationRunning, &runID))
+	tc.On("DescribeWorkflowExecution", ctx, "converge-shard-test-shard-1", "run-1").
+		Return(describeOperation(enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED), nil)
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+
+	op, err := svc.GetByID(ctx, "test-op-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.OperationSucceeded, op.Status)
+	assert.Nil(t, op.Error)
+	assert.NotNil(t, op.CompletedAt)
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestOperationService_GetByID_ReconcilesFailed(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewOperationService(db, tc)
+	ctx := context.Background()
+
+	runID := "run-1"
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-op-1"}).Return(operationRow(model.OperationRunning, &runID))
+	tc.On("DescribeWorkflowExecution", ctx, "converge-shard-test-shard-1", "run-1").
+		Return(describeOperation(enumspb.WORKFLOW_EXECUTION_STATUS_FAILED), nil)
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, nil).Return(errors.New("node unreachable"))
+	tc.On("GetWorkflow", ctx, "converge-shard-test-shard-1", "run-1").Return(wfRun)
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+
+	op, err := svc.GetByID(ctx, "test-op-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.OperationFailed, op.Status)
+	require.NotNil(t, op.Error)
+	assert.Contains(t, *op.Error, "node unreachable")
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestOperationService_GetByID_StillRunning(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewOperationService(db, tc)
+	ctx := context.Background()
+
+	runID := "run-1"
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-op-1"}).Return(operationRow(model.OperationRunning, &runID))
+	tc.On("DescribeWorkflowExecution", ctx, "converge-shard-test-shard-1", "run-1").
+		Return(describeOperation(enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING), nil)
+
+	op, err := svc.GetByID(ctx, "test-op-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.OperationRunning, op.Status)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOperationService_GetByID_Progress(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewOperationService(db, tc)
+	ctx := context.Background()
+
+	runID := "run-1"
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-op-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[2].(*string)) = "BulkProvisionCertsWorkflow"
+		*(dest[3].(*string)) = "bulk-certs-test-tenant-1"
+		*(dest[4].(**string)) = &runID
+		*(dest[5].(*string)) = model.OperationRunning
+		return nil
+	}})
+	tc.On("DescribeWorkflowExecution", ctx, "bulk-certs-test-tenant-1", "run-1").
+		Return(describeOperation(enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING), nil)
+	val := &temporalmocks.Value{}
+	val.On("Get", mock.Anything).Run(func(args mock.Arguments) {
+		*(args.Get(0).(*any)) = map[string]any{"total": 3, "issued": 1}
+	}).Return(nil)
+	tc.On("QueryWorkflow", ctx, "bulk-certs-test-tenant-1", "run-1", "bulk-cert-progress").Return(val, nil)
+
+	op, err := svc.GetByID(ctx, "test-op-1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"total": 3, "issued": 1}, op.Progress)
+	tc.AssertExpectations(t)
+}
+
+func TestOperationService_GetByID_NotFound(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewOperationService(db, tc)
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error { return errors.New("no rows in result set") }}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"missing"}).Return(row)
+
+	_, err := svc.GetByID(ctx, "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "get operation missing")
+}
+
+// ---------- Shutdown ----------
+
+func TestWaitWorkflowStarts(t *testing.T) {
+	assert.Equal(t, 0, WaitWorkflowStarts(context.Background()))
+
+	workflowStarts.Add(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, 1, WaitWorkflowStarts(ctx))
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		workflowStarts.Add(-1)
+	}()
+	assert.Equal(t, 0, WaitWorkflowStarts(context.Background()))
+	assert.Equal(t, 0, InFlightWorkflowStarts())
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// ownedResources maps the resource types OwnershipService can check to the
+// resolver of their owning tenant, which walks up parent resources (alias →
+// email account → FQDN → tenant and so on).
+var ownedResources = map[string]func(ctx context.Context, db DB, id string) (string, error){
+	"tenants": func(_ context.Context, _ DB, id string) (string, error) {
+		return id, nil
+	},
+	"webroots":          resolveTenantIDFromWebroot,
+	"cron_jobs":         resolveTenantIDFromCronJob,
+	"fqdns":             resolveTenantIDFromFQDN,
+	"certificates":      resolveTenantIDFromCertificate,
+	"databases":         resolveTenantIDFromDatabase,
+	"database_users":    resolveTenantIDFromDatabaseUser,
+	"valkey_instances":  resolveTenantIDFromValkeyInstance,
+	"valkey_users":      resolveTenantIDFromValkeyUser,
+	"s3_buckets":        resolveTenantIDFromS3Bucket,
+	"s3_access_keys":    resolveTenantIDFromS3AccessKey,
+	"email_accounts":    resolveTenantIDFromEmailAccount,
+	"email_aliases":     resolveTenantIDFromEmailAlias,
+	"email_forwards":    resolveTenantIDFromEmailForward,
+	"email_autoreplies": resolveTenantIDFromEmailAutoReply,
+	"zones":             resolveTenantIDFromZone,
+	"zone_records":      resolveTenantIDFromZoneRecord,
+}
+
+// ResourceOwner is the tenant a resource belongs to, with the brand and
+// reseller that API key access is checked against.
+type ResourceOwner struct {
+	TenantID   string
+	BrandID    string
+	ResellerID *string
+}
+
+// OwnershipService resolves which tenant owns a resource, so handlers can
+// authorize requests for resources that only reference their tenant through
+// parent resources.
+type OwnershipService struct {
+	db DB
+}
+
+func NewOwnershipService(db DB) *OwnershipService {
+	return &OwnershipService{db: db}
+}
+
+// Owner returns the tenant owning the resource of the given type and ID.
+// Resources that do not exist return an error wrapping pgx.ErrNoRows.
+func (s *OwnershipService) Owner(ctx context.Context, resourceType, id string) (*ResourceOwner, error) {
+	resolve, ok := ownedResources[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("ownership checks are not supported for %s", resourceType)
+	}
+
+	tenantID, err := resolve(ctx, s.db, id)
+	if err != nil {
+		return nil, fmt.Errorf("resolve tenant for %s %s: %w", resourceType, id, err)
+	}
+
+	owner := &ResourceOwner{TenantID: tenantID}
+	err = s.db.QueryRow(ctx,
+		`SELECT brand_id, reseller_id FROM tenants WHERE id = $1`, tenantID,
+	).Scan(&owner.BrandID, &owner.ResellerID)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant %s: %w", tenantID, err)
+	}
+	return owner, nil
+}
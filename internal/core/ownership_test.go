@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOwnershipService_Owner_WalksToTenant(t *testing.T) {
+	db := &mockDB{}
+	svc := NewOwnershipService(db)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("JOIN email_aliases"), []any{"alias-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "tenant-1"
+		return nil
+	}})
+	reseller := "reseller-1"
+	db.On("QueryRow", ctx, queryContaining("FROM tenants"), []any{"tenant-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "brand-1"
+		*(dest[1].(**string)) = &reseller
+		return nil
+	}})
+
+	owner, err := svc.Owner(ctx, "email_aliases", "alias-1")
+	require.NoError(t, err)
+	assert.Equal(t, &ResourceOwner{TenantID: "tenant-1", BrandID: "brand-1", ResellerID: &reseller}, owner)
+}
+
+func TestOwnershipService_Owner_Tenant(t *testing.T) {
+	db := &mockDB{}
+	svc := NewOwnershipService(db)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("FROM tenants"), []any{"tenant-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "brand-1"
+		return nil
+	}})
+
+	owner, err := svc.Owner(ctx, "tenants", "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-1", owner.TenantID)
+	assert.Nil(t, owner.ResellerID)
+	db.AssertNumberOfCalls(t, "QueryRow", 1)
+}
+
+func TestOwnershipService_Owner_NotFound(t *testing.T) {
+	db := &mockDB{}
+	svc := NewOwnershipService(db)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		return pgx.ErrNoRows
+	}})
+
+	for resourceType := range ownedResources {
+		if resourceType == "tenants" {
+			continue
+		}
+		_, err := svc.Owner(ctx, resourceType, "missing")
+		assert.ErrorIs(t, err, pgx.ErrNoRows, resourceType)
+	}
+}
+
+func TestOwnershipService_Owner_UnsupportedType(t *testing.T) {
+	svc := NewOwnershipService(&mockDB{})
+
+	_, err := svc.Owner(context.Background(), "brands", "brand-1")
+	assert.ErrorContains(t, err, "not supported for brands")
+}
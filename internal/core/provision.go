@@ -3,6 +3,8 @@ package core
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
+	"time"
 
 	"github.com/edvin/hosting/internal/model"
 	temporalclient "go.temporal.io/sdk/client"
@@ -10,6 +12,34 @@ import (
 
 const taskQueue = "hosting-tasks"
 
+// workflowStarts counts the workflow starts in progress, so shutdown can
+// wait for them before closing the Temporal client.
+var workflowStarts atomic.Int64
+
+// WaitWorkflowStarts waits until no workflow starts are in progress, or ctx
+// is done. It returns the number still in progress. Used on shutdown, after
+// the HTTP server has stopped, before the Temporal client is closed.
+func WaitWorkflowStarts(ctx context.Context) int {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		n := workflowStarts.Load()
+		if n == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return int(n)
+		case <-ticker.C:
+		}
+	}
+}
+
+// InFlightWorkflowStarts returns the number of workflow starts in progress.
+func InFlightWorkflowStarts() int {
+	return int(workflowStarts.Load())
+}
+
 // ctxKey is a context key type for callback URL propagation.
 type ctxKey string
 
@@ -51,42 +81,63 @@ func workflowID(prefix, id string) string {
 // It uses SignalWithStartWorkflow to ensure sequential execution of all
 // tenant-related workflows. If the context has WithSkipWorkflow set, this is a
 // no-op. If tenantID is empty (for unassigned resources like tenant-less zones),
-// the workflow is started directly without per-tenant serialization.
+// the workflow is started directly without per-tenant serialization. With
+// WithOperationTracking the task is recorded as an Operation, which the entity
+// workflow keeps up to date.
 func signalProvision(ctx context.Context, tc temporalclient.Client, db DB, tenantID string, task model.ProvisionTask) error {
 	if v, _ := ctx.Value(skipWorkflowKey{}).(bool); v {
 		return nil
 	}
+	workflowStarts.Add(1)
+	defer workflowStarts.Add(-1)
 
 	if tenantID == "" {
 		// No tenant — start workflow directly.
-		_, err := tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
-			ID:        task.WorkflowID,
-			TaskQueue: taskQueue,
-		}, task.WorkflowName, task.Arg)
+		return startWorkflow(ctx, tc, db, "", task)
+	}
+
+	opID, err := recordOperation(ctx, db, tenantID, task)
+	if err != nil {
 		return err
 	}
+	task.OperationID = opID
 
 	wfID := fmt.Sprintf("tenant-%s", tenantID)
-	_, err := tc.SignalWithStartWorkflow(ctx, wfID, model.ProvisionSignalName, task,
+	_, err = tc.SignalWithStartWorkflow(ctx, wfID, model.ProvisionSignalName, task,
 		temporalclient.StartWorkflowOptions{
 			ID:        wfID,
 			TaskQueue: taskQueue,
 		},
 		"TenantProvisionWorkflow",
 	)
+	if err != nil {
+		finishOperationStart(ctx, db, opID, nil, err)
+	}
 	return err
 }
 
 // startWorkflow directly executes a Temporal workflow without per-tenant
-// serialization. Used for non-tenant-scoped workflows (shard convergence, etc.).
-func startWorkflow(ctx context.Context, tc temporalclient.Client, workflowName, wfID string, arg any) error {
+// serialization. Used for workflows that must not wait in a tenant queue
+// (shard convergence, exports, etc.).
+// With WithOperationTracking the workflow is recorded as an Operation in the
+// same step; tenantID only attributes the operation.
+func startWorkflow(ctx context.Context, tc temporalclient.Client, db DB, tenantID string, task model.ProvisionTask) error {
 	if v, _ := ctx.Value(skipWorkflowKey{}).(bool); v {
 		return nil
 	}
-	_, err := tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
-		ID:        wfID,
+	workflowStarts.Add(1)
+	defer workflowStarts.Add(-1)
+
+	opID, err := recordOperation(ctx, db, tenantID, task)
+	if err != nil {
+		return err
+	}
+
+	run, err := tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        task.WorkflowID,
 		TaskQueue: taskQueue,
-	}, workflowName, arg)
+	}, task.WorkflowName, task.Arg)
+	finishOperationStart(ctx, db, opID, run, err)
 	return err
 }
 
@@ -100,6 +151,12 @@ func resolveTenantIDFromWebroot(ctx context.Context, db DB, webrootID string) (s
 	return id, err
 }
 
+func resolveTenantIDFromCronJob(ctx context.Context, db DB, cronJobID string) (string, error) {
+	var id string
+	err := db.QueryRow(ctx, "SELECT tenant_id FROM cron_jobs WHERE id = $1", cronJobID).Scan(&id)
+	return id, err
+}
+
 func resolveTenantIDFromFQDN(ctx context.Context, db DB, fqdnID string) (string, error) {
 	var id string
 	err := db.QueryRow(ctx, "SELECT tenant_id FROM fqdns WHERE id = $1", fqdnID).Scan(&id)
@@ -0,0 +1,188 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/edvin/hosting/internal/api/request"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ErrResellerHasTenants is returned by Delete while tenants are still
+// assigned to the reseller.
+var ErrResellerHasTenants = errors.New("reseller still has tenants")
+
+// ErrResellerBrandMismatch is returned by AssignTenant when the tenant
+// belongs to a different brand than the reseller.
+var ErrResellerBrandMismatch = errors.New("tenant belongs to a different brand than the reseller")
+
+type ResellerService struct {
+	db DB
+}
+
+func NewResellerService(db DB) *ResellerService {
+	return &ResellerService{db: db}
+}
+
+func (s *ResellerService) Create(ctx context.Context, reseller *model.Reseller) error {
+	_, err := s.db.Exec(ctx,
+		`INSERT INTO resellers (id, brand_id, name, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)`,
+		reseller.ID, reseller.BrandID, reseller.Name, reseller.CreatedAt, reseller.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert reseller: %w", err)
+	}
+	return nil
+}
+
+func (s *ResellerService) GetByID(ctx context.Context, id string) (*model.Reseller, error) {
+	var r model.Reseller
+	err := s.db.QueryRow(ctx,
+		`SELECT id, brand_id, name, created_at, updated_at FROM resellers WHERE id = $1`, id,
+	).Scan(&r.ID, &r.BrandID, &r.Name, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get reseller %s: %w", id, err)
+	}
+	return &r, nil
+}
+
+// List returns resellers, filtered to params.BrandIDs when set. A set
+// params.ResellerID limits the result to that reseller.
+func (s *ResellerService) List(ctx context.Context, params request.ListParams) ([]model.Reseller, bool, error) {
+	query := `SELECT id, brand_id, name, created_at, updated_at FROM resellers WHERE true`
+	args := []any{}
+	argIdx := 1
+
+	if params.Search != "" {
+		query += fmt.Sprintf(` AND (id ILIKE $%d OR name ILIKE $%d)`, argIdx, argIdx)
+		args = append(args, "%"+params.Search+"%")
+		argIdx++
+	}
+	if params.Cursor != "" {
+		query += fmt.Sprintf(` AND id > $%d`, argIdx)
+		args = append(args, params.Cursor)
+		argIdx++
+	}
+	if len(params.BrandIDs) > 0 {
+		query += fmt.Sprintf(` AND brand_id = ANY($%d)`, argIdx)
+		args = append(args, params.BrandIDs)
+		argIdx++
+	}
+	if params.ResellerID != "" {
+		query += fmt.Sprintf(` AND id = $%d`, argIdx)
+		args = append(args, params.ResellerID)
+		argIdx++
+	}
+
+	sortCol := "created_at"
+	switch params.Sort {
+	case "name":
+		sortCol = "name"
+	case "created_at":
+		sortCol = "created_at"
+	}
+	order := "DESC"
+	if params.Order == "asc" {
+		order = "ASC"
+	}
+	query += fmt.Sprintf(` ORDER BY %s %s`, sortCol, order)
+	query += fmt.Sprintf(` LIMIT $%d`, argIdx)
+	args = append(args, params.Limit+1)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("list resellers: %w", err)
+	}
+	defer rows.Close()
+
+	var resellers []model.Reseller
+	for rows.Next() {
+		var r model.Reseller
+		if err := rows.Scan(&r.ID, &r.BrandID, &r.Name, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, false, fmt.Errorf("scan reseller: %w", err)
+		}
+		resellers = append(resellers, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("iterate resellers: %w", err)
+	}
+
+	hasMore := len(resellers) > params.Limit
+	if hasMore {
+		resellers = resellers[:params.Limit]
+	}
+	return resellers, hasMore, nil
+}
+
+func (s *ResellerService) Update(ctx context.Context, reseller *model.Reseller) error {
+	_, err := s.db.Exec(ctx,
+		`UPDATE resellers SET name = $1, updated_at = now() WHERE id = $2`,
+		reseller.Name, reseller.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update reseller %s: %w", reseller.ID, err)
+	}
+	return nil
+}
+
+// Delete removes a reseller and its API keys. Tenants must be unassigned
+// first so none silently fall back to brand-level access.
+func (s *ResellerService) Delete(ctx context.Context, id string) error {
+	var count int
+	err := s.db.QueryRow(ctx, `SELECT COUNT(*) FROM tenants WHERE reseller_id = $1`, id).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("count reseller %s tenants: %w", id, err)
+	}
+	if count > 0 {
+		return fmt.Errorf("%w: %d tenant(s) assigned", ErrResellerHasTenants, count)
+	}
+
+	_, err = s.db.Exec(ctx, "DELETE FROM resellers WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("delete reseller %s: %w", id, err)
+	}
+	return nil
+}
+
+// AssignTenant moves a tenant of the reseller's brand under the reseller.
+// A tenant already owned by another reseller is reassigned.
+func (s *ResellerService) AssignTenant(ctx context.Context, resellerID, tenantID string) error {
+	reseller, err := s.GetByID(ctx, resellerID)
+	if err != nil {
+		return err
+	}
+
+	var brandID string
+	err = s.db.QueryRow(ctx, `SELECT brand_id FROM tenants WHERE id = $1`, tenantID).Scan(&brandID)
+	if err != nil {
+		return fmt.Errorf("get tenant %s: %w", tenantID, err)
+	}
+	if brandID != reseller.BrandID {
+		return fmt.Errorf("%w: tenant %s is in brand %s", ErrResellerBrandMismatch, tenantID, brandID)
+	}
+
+	_, err = s.db.Exec(ctx,
+		`UPDATE tenants SET reseller_id = $1, updated_at = now() WHERE id = $2`, resellerID, tenantID,
+	)
+	if err != nil {
+		return fmt.Errorf("assign tenant %s to reseller %s: %w", tenantID, resellerID, err)
+	}
+	return nil
+}
+
+// UnassignTenant returns a tenant to its brand.
+func (s *ResellerService) UnassignTenant(ctx context.Context, resellerID, tenantID string) error {
+	tag, err := s.db.Exec(ctx,
+		`UPDATE tenants SET reseller_id = NULL, updated_at = now() WHERE id = $1 AND reseller_id = $2`, tenantID, resellerID,
+	)
+	if err != nil {
+		return fmt.Errorf("unassign tenant %s from reseller %s: %w", tenantID, resellerID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("tenant %s is not assigned to reseller %s: %w", tenantID, resellerID, pgx.ErrNoRows)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func resellerRow(id, brandID string) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = id
+		*(dest[1].(*string)) = brandID
+		*(dest[2].(*string)) = "Acme Reseller"
+		*(dest[3].(*time.Time)) = time.Now()
+		*(dest[4].(*time.Time)) = time.Now()
+		return nil
+	}}
+}
+
+func TestResellerService_Delete_HasTenants(t *testing.T) {
+	db := &mockDB{}
+	svc := NewResellerService(db)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, "SELECT COUNT(*) FROM tenants WHERE reseller_id = $1", []any{"test-reseller-1"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*int)) = 2
+			return nil
+		}})
+
+	err := svc.Delete(ctx, "test-reseller-1")
+	require.ErrorIs(t, err, ErrResellerHasTenants)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResellerService_Delete_Success(t *testing.T) {
+	db := &mockDB{}
+	svc := NewResellerService(db)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-reseller-1"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*int)) = 0
+			return nil
+		}})
+	db.On("Exec", ctx, "DELETE FROM resellers WHERE id = $1", []any{"test-reseller-1"}).
+		Return(pgconn.NewCommandTag("DELETE 1"), nil)
+
+	require.NoError(t, svc.Delete(ctx, "test-reseller-1"))
+	db.AssertExpectations(t)
+}
+
+func TestResellerService_AssignTenant_Success(t *testing.T) {
+	db := &mockDB{}
+	svc := NewResellerService(db)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-reseller-1"}).
+		Return(resellerRow("test-reseller-1", "acme"))
+	db.On("QueryRow", ctx, "SELECT brand_id FROM tenants WHERE id = $1", []any{"test-tenant-1"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "acme"
+			return nil
+		}})
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{"test-reseller-1", "test-tenant-1"}).
+		Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+
+	require.NoError(t, svc.AssignTenant(ctx, "test-reseller-1", "test-tenant-1"))
+	db.AssertExpectations(t)
+}
+
+func TestResellerService_AssignTenant_BrandMismatch(t *testing.T) {
+	db := &mockDB{}
+	svc := NewResellerService(db)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-reseller-1"}).
+		Return(resellerRow("test-reseller-1", "acme"))
+	db.On("QueryRow", ctx, "SELECT brand_id FROM tenants WHERE id = $1", []any{"test-tenant-1"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "other"
+			return nil
+		}})
+
+	err := svc.AssignTenant(ctx, "test-reseller-1", "test-tenant-1")
+	require.ErrorIs(t, err, ErrResellerBrandMismatch)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestResellerService_UnassignTenant_NotAssigned(t *testing.T) {
+	db := &mockDB{}
+	svc := NewResellerService(db)
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1", "test-reseller-1"}).
+		Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+
+	err := svc.UnassignTenant(ctx, "test-reseller-1", "test-tenant-1")
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
@@ -3,14 +3,20 @@ package core
 import (
 	"context"
 	"crypto/rand"
+	"errors"
 	"fmt"
 	"math/big"
 
 	"github.com/edvin/hosting/internal/crypto"
 	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
+// ErrS3AccessKeyNotActive is returned when rotating a key that is not active,
+// including one whose previous rotation is still in progress.
+var ErrS3AccessKeyNotActive = errors.New("s3 access key is not active")
+
 // CreateS3AccessKeyArgs is passed as the workflow Arg so the plaintext secret
 // is available for the Ceph RGW activity (S3 protocol requires the raw key).
 type CreateS3AccessKeyArgs struct {
@@ -18,6 +24,10 @@ type CreateS3AccessKeyArgs struct {
 	SecretAccessKey string `json:"secret_access_key"`
 }
 
+// RotateS3AccessKeyArgs is the workflow Arg of RotateS3AccessKeyWorkflow,
+// carrying the new plaintext secret for Ceph RGW.
+type RotateS3AccessKeyArgs = CreateS3AccessKeyArgs
+
 type S3AccessKeyService struct {
 	db DB
 	tc temporalclient.Client
@@ -85,6 +95,49 @@ func (s *S3AccessKeyService) Create(ctx context.Context, key *model.S3AccessKey)
 	return secretAccessKey, nil
 }
 
+// Rotate replaces the secret of an active access key, keeping its access key
+// ID. The key moves to provisioning until RGW has the new secret, after which
+// the old one no longer works. Returns the updated key and the plaintext
+// secret for the one-time API response.
+func (s *S3AccessKeyService) Rotate(ctx context.Context, id string) (*model.S3AccessKey, string, error) {
+	secretAccessKey, err := generateRandomString(40)
+	if err != nil {
+		return nil, "", fmt.Errorf("generate secret access key: %w", err)
+	}
+
+	var k model.S3AccessKey
+	err = s.db.QueryRow(ctx,
+		`UPDATE s3_access_keys SET secret_key_hash = $1, status = $2, status_message = NULL, updated_at = now()
+		 WHERE id = $3 AND status = $4
+		 RETURNING id, s3_bucket_id, access_key_id, secret_key_hash, permissions, status, status_message, created_at, updated_at`,
+		crypto.GenericHash(secretAccessKey), model.StatusProvisioning, id, model.StatusActive,
+	).Scan(&k.ID, &k.S3BucketID, &k.AccessKeyID, &k.SecretKeyHash,
+		&k.Permissions, &k.Status, &k.StatusMessage, &k.CreatedAt, &k.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", ErrS3AccessKeyNotActive
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("rotate s3 access key %s: %w", id, err)
+	}
+
+	tenantID, err := resolveTenantIDFromS3Bucket(ctx, s.db, k.S3BucketID)
+	if err != nil {
+		return nil, "", fmt.Errorf("resolve tenant for s3 access key: %w", err)
+	}
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "RotateS3AccessKeyWorkflow",
+		WorkflowID:   workflowID("s3-access-key", id),
+		Arg: RotateS3AccessKeyArgs{
+			KeyID:           id,
+			SecretAccessKey: secretAccessKey,
+		},
+	}); err != nil {
+		return nil, "", fmt.Errorf("signal RotateS3AccessKeyWorkflow: %w", err)
+	}
+
+	return &k, secretAccessKey, nil
+}
+
 func (s *S3AccessKeyService) GetByID(ctx context.Context, id string) (*model.S3AccessKey, error) {
 	var k model.S3AccessKey
 	err := s.db.QueryRow(ctx,
@@ -20,10 +20,10 @@ func NewS3BucketService(db DB, tc temporalclient.Client) *S3BucketService {
 
 func (s *S3BucketService) Create(ctx context.Context, bucket *model.S3Bucket) error {
 	_, err := s.db.Exec(ctx,
-		`INSERT INTO s3_buckets (id, tenant_id, subscription_id, shard_id, public, quota_bytes, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		`INSERT INTO s3_buckets (id, tenant_id, subscription_id, shard_id, public, quota_bytes, lifecycle_rules, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
 		bucket.ID, bucket.TenantID, bucket.SubscriptionID, bucket.ShardID,
-		bucket.Public, bucket.QuotaBytes, bucket.Status, bucket.CreatedAt, bucket.UpdatedAt,
+		bucket.Public, bucket.QuotaBytes, lifecycleRulesOrEmpty(bucket.LifecycleRules), bucket.Status, bucket.CreatedAt, bucket.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("insert s3 bucket: %w", err)
@@ -44,13 +44,13 @@ func (s *S3BucketService) GetByID(ctx context.Context, id string) (*model.S3Buck
 	var b model.S3Bucket
 	err := s.db.QueryRow(ctx,
 		`SELECT b.id, b.tenant_id, b.subscription_id, b.shard_id, b.public, b.quota_bytes, b.status, b.status_message, b.suspend_reason, b.created_at, b.updated_at,
-		        sh.name
+		        b.lifecycle_rules, sh.name
 		 FROM s3_buckets b
 		 LEFT JOIN shards sh ON sh.id = b.shard_id
 		 WHERE b.id = $1`, id,
 	).Scan(&b.ID, &b.TenantID, &b.SubscriptionID, &b.ShardID,
 		&b.Public, &b.QuotaBytes, &b.Status, &b.StatusMessage, &b.SuspendReason, &b.CreatedAt, &b.UpdatedAt,
-		&b.ShardName)
+		&b.LifecycleRules, &b.ShardName)
 	if err != nil {
 		return nil, fmt.Errorf("get s3 bucket %s: %w", id, err)
 	}
@@ -58,7 +58,7 @@ func (s *S3BucketService) GetByID(ctx context.Context, id string) (*model.S3Buck
 }
 
 func (s *S3BucketService) ListByTenant(ctx context.Context, tenantID string, params request.ListParams) ([]model.S3Bucket, bool, error) {
-	query := `SELECT b.id, b.tenant_id, b.subscription_id, b.shard_id, b.public, b.quota_bytes, b.status, b.status_message, b.suspend_reason, b.created_at, b.updated_at, sh.name FROM s3_buckets b LEFT JOIN shards sh ON sh.id = b.shard_id WHERE b.tenant_id = $1`
+	query := `SELECT b.id, b.tenant_id, b.subscription_id, b.shard_id, b.public, b.quota_bytes, b.status, b.status_message, b.suspend_reason, b.created_at, b.updated_at, b.lifecycle_rules, sh.name FROM s3_buckets b LEFT JOIN shards sh ON sh.id = b.shard_id WHERE b.tenant_id = $1`
 	args := []any{tenantID}
 	argIdx := 2
 
@@ -106,7 +106,7 @@ func (s *S3BucketService) ListByTenant(ctx context.Context, tenantID string, par
 		var b model.S3Bucket
 		if err := rows.Scan(&b.ID, &b.TenantID, &b.SubscriptionID, &b.ShardID,
 			&b.Public, &b.QuotaBytes, &b.Status, &b.StatusMessage, &b.SuspendReason, &b.CreatedAt, &b.UpdatedAt,
-			&b.ShardName); err != nil {
+			&b.LifecycleRules, &b.ShardName); err != nil {
 			return nil, false, fmt.Errorf("scan s3 bucket: %w", err)
 		}
 		buckets = append(buckets, b)
@@ -157,6 +157,40 @@ func (s *S3BucketService) Update(ctx context.Context, id string, public *bool, q
 	return nil
 }
 
+// SetLifecycle replaces the bucket's lifecycle rules and converges them onto
+// RGW. An empty rule set removes the lifecycle configuration.
+func (s *S3BucketService) SetLifecycle(ctx context.Context, id string, rules []model.S3LifecycleRule) error {
+	_, err := s.db.Exec(ctx,
+		"UPDATE s3_buckets SET lifecycle_rules = $1, updated_at = now() WHERE id = $2",
+		lifecycleRulesOrEmpty(rules), id,
+	)
+	if err != nil {
+		return fmt.Errorf("update s3 bucket %s lifecycle rules: %w", id, err)
+	}
+
+	tenantID, err := resolveTenantIDFromS3Bucket(ctx, s.db, id)
+	if err != nil {
+		return fmt.Errorf("resolve tenant for s3 bucket %s: %w", id, err)
+	}
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "UpdateS3BucketWorkflow",
+		WorkflowID:   workflowID("s3-bucket", id),
+		Arg:          id,
+	}); err != nil {
+		return fmt.Errorf("signal UpdateS3BucketWorkflow: %w", err)
+	}
+
+	return nil
+}
+
+// lifecycleRulesOrEmpty keeps a nil rule set from being stored as JSON null.
+func lifecycleRulesOrEmpty(rules []model.S3LifecycleRule) []model.S3LifecycleRule {
+	if rules == nil {
+		return []model.S3LifecycleRule{}
+	}
+	return rules
+}
+
 func (s *S3BucketService) Delete(ctx context.Context, id string) error {
 	_, err := s.db.Exec(ctx,
 		"UPDATE s3_buckets SET status = $1, updated_at = now() WHERE id = $2",
@@ -117,7 +117,8 @@ func TestS3BucketService_GetByID_Success(t *testing.T) {
 		*(dest[8].(*string)) = ""  // suspend_reason
 		*(dest[9].(*time.Time)) = now
 		*(dest[10].(*time.Time)) = now
-		*(dest[11].(**string)) = &shardName
+		*(dest[11].(*[]model.S3LifecycleRule)) = []model.S3LifecycleRule{{ID: "rule-1", ExpirationDays: 30}}
+		*(dest[12].(**string)) = &shardName
 		return nil
 	}}
 	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
@@ -133,6 +134,7 @@ func TestS3BucketService_GetByID_Success(t *testing.T) {
 	assert.Equal(t, model.StatusActive, result.Status)
 	assert.Equal(t, now, result.CreatedAt)
 	assert.Equal(t, now, result.UpdatedAt)
+	assert.Equal(t, []model.S3LifecycleRule{{ID: "rule-1", ExpirationDays: 30}}, result.LifecycleRules)
 	assert.Equal(t, &shardName, result.ShardName)
 	db.AssertExpectations(t)
 }
@@ -182,7 +184,8 @@ func TestS3BucketService_ListByTenant_Success(t *testing.T) {
 			*(dest[8].(*string)) = ""  // suspend_reason
 			*(dest[9].(*time.Time)) = now
 			*(dest[10].(*time.Time)) = now
-			*(dest[11].(**string)) = &shardName
+			*(dest[11].(*[]model.S3LifecycleRule)) = nil
+		*(dest[12].(**string)) = &shardName
 			return nil
 		},
 	)
@@ -211,6 +214,50 @@ func TestS3BucketService_ListByTenant_QueryError(t *testing.T) {
 	db.AssertExpectations(t)
 }
 
+// ---------- SetLifecycle ----------
+
+func TestS3BucketService_SetLifecycle_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewS3BucketService(db, tc)
+	ctx := context.Background()
+
+	bucketID := "test-bucket-1"
+	rules := []model.S3LifecycleRule{{ID: "rule-1", Prefix: "logs/", ExpirationDays: 14}}
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{rules, bucketID}).Return(pgconn.CommandTag{}, nil)
+
+	resolveRow := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-tenant-1"
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(resolveRow).Once()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id")
+	wfRun.On("GetRunID").Return("mock-run-id")
+	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	err := svc.SetLifecycle(ctx, bucketID, rules)
+	require.NoError(t, err)
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestS3BucketService_SetLifecycle_NilStoresEmptyList(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewS3BucketService(db, tc)
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{[]model.S3LifecycleRule{}, "test-bucket-1"}).Return(pgconn.CommandTag{}, errors.New("db error"))
+
+	err := svc.SetLifecycle(ctx, "test-bucket-1", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "lifecycle rules")
+	db.AssertExpectations(t)
+}
+
 // ---------- Delete ----------
 
 func TestS3BucketService_Delete_Success(t *testing.T) {
@@ -19,6 +19,7 @@ type Services struct {
 	Dashboard          *DashboardService
 	PlatformConfig     *PlatformConfigService
 	Brand              *BrandService
+	Reseller           *ResellerService
 	Region             *RegionService
 	Cluster            *ClusterService
 	ClusterLBAddress   *ClusterLBAddressService
@@ -28,6 +29,8 @@ type Services struct {
 	Subscription       *SubscriptionService
 	Webroot            *WebrootService
 	WebrootEnvVar      *WebrootEnvVarService
+	WebrootClone       *WebrootCloneService
+	WebrootRelease     *WebrootReleaseService
 	FQDN               *FQDNService
 	Certificate        *CertificateService
 	Zone               *ZoneService
@@ -38,6 +41,8 @@ type Services struct {
 	EmailAlias         *EmailAliasService
 	EmailForward       *EmailForwardService
 	EmailAutoReply     *EmailAutoReplyService
+	EmailDKIM          *EmailDKIMService
+	SMTPRelayUser      *SMTPRelayUserService
 	ValkeyInstance     *ValkeyInstanceService
 	ValkeyUser         *ValkeyUserService
 	S3Bucket           *S3BucketService
@@ -45,10 +50,19 @@ type Services struct {
 	SSHKey             *SSHKeyService
 	TenantEgressRule   *TenantEgressRuleService
 	Backup             *BackupService
+	BackupDownload     *BackupDownloadService
+	TenantExport       *TenantExportService
+	TenantEvent        *TenantEventService
+	Operation          *OperationService
+	Idempotency        *IdempotencyService
+	StatusReset        *StatusResetService
+	Ownership          *OwnershipService
 	CronJob            *CronJobService
+	CronJobEnvVar      *CronJobEnvVarService
 	Daemon             *DaemonService
 	APIKey             *APIKeyService
 	OIDC               *OIDCService
+	LoginSession       *LoginSessionService
 	Search             *SearchService
 	DesiredState       *DesiredStateService
 	NodeHealth         *NodeHealthService
@@ -71,16 +85,19 @@ func newServicesFromDB(db DB, tc temporalclient.Client, oidcIssuerURL string, se
 
 		Dashboard:          NewDashboardService(db),
 		PlatformConfig:     NewPlatformConfigService(db),
-		Brand:              NewBrandService(db),
+		Brand:              NewBrandService(db, secretEncryptionKey),
+		Reseller:           NewResellerService(db),
 		Region:             NewRegionService(db),
 		Cluster:            NewClusterService(db),
 		ClusterLBAddress:   NewClusterLBAddressService(db),
 		Shard:              NewShardService(db, tc),
-		Node:               NewNodeService(db),
+		Node:               NewNodeService(db, tc),
 		Tenant:             NewTenantService(db, tc),
 		Subscription:       NewSubscriptionService(db, tc),
 		Webroot:            NewWebrootService(db, tc),
 		WebrootEnvVar:      NewWebrootEnvVarService(db, tc, secretEncryptionKey),
+		WebrootClone:       NewWebrootCloneService(db, tc, secretEncryptionKey),
+		WebrootRelease:     NewWebrootReleaseService(db, tc),
 		FQDN:               NewFQDNService(db, tc),
 		Certificate:        NewCertificateService(db, tc),
 		Zone:               NewZoneService(db, tc),
@@ -91,17 +108,28 @@ func newServicesFromDB(db DB, tc temporalclient.Client, oidcIssuerURL string, se
 		EmailAlias:         NewEmailAliasService(db, tc),
 		EmailForward:       NewEmailForwardService(db, tc),
 		EmailAutoReply:     NewEmailAutoReplyService(db, tc),
+		EmailDKIM:          NewEmailDKIMService(db, tc),
+		SMTPRelayUser:      NewSMTPRelayUserService(db, tc, ""),
 		ValkeyInstance:     NewValkeyInstanceService(db, tc),
 		ValkeyUser:         NewValkeyUserService(db, tc),
 		S3Bucket:           NewS3BucketService(db, tc),
 		S3AccessKey:        NewS3AccessKeyService(db, tc),
 		SSHKey:             NewSSHKeyService(db, tc),
-		TenantEgressRule:   NewTenantEgressRuleService(db, tc),
+		TenantEgressRule:   NewTenantEgressRuleService(db, tc, nil),
 		Backup:             NewBackupService(db, tc),
+		BackupDownload:     NewBackupDownloadService(db, tc, nil, "", "", 0),
+		TenantExport:       NewTenantExportService(db, tc, nil, 0),
+		TenantEvent:        NewTenantEventService(db),
+		Operation:          NewOperationService(db, tc),
+		Idempotency:        NewIdempotencyService(db),
+		StatusReset:        NewStatusResetService(db, tc),
+		Ownership:          NewOwnershipService(db),
 		CronJob:            NewCronJobService(db, tc),
+		CronJobEnvVar:      NewCronJobEnvVarService(db, tc, secretEncryptionKey),
 		Daemon:             NewDaemonService(db, tc),
 		APIKey:             NewAPIKeyService(db),
 		OIDC:               NewOIDCService(db, oidcIssuerURL),
+		LoginSession:       NewLoginSessionService(db, tc),
 		Search:             NewSearchService(db),
 		DesiredState:       NewDesiredStateService(db, secretEncryptionKey),
 		NodeHealth:         NewNodeHealthService(db),
@@ -125,6 +153,8 @@ func (s *Services) WithTx(ctx context.Context, fn func(tx *Services) error) erro
 	defer pgxTx.Rollback(ctx)
 
 	txSvc := newServicesFromDB(pgxTx, s.tc, s.oidcIssuerURL, s.secretEncryptionKey)
+	// The egress blocklist is set by NewServer after construction.
+	txSvc.TenantEgressRule.blocked = s.TenantEgressRule.blocked
 	if err := fn(txSvc); err != nil {
 		return err
 	}
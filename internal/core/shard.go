@@ -3,13 +3,47 @@ package core
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
 	temporalclient "go.temporal.io/sdk/client"
 
 	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
 )
 
+// ErrConvergeRunning is returned by Converge while a convergence for the
+// shard is already in flight.
+var ErrConvergeRunning = errors.New("convergence already running for shard")
+
+// ConvergeShardWorkflowID is the workflow ID every ConvergeShardWorkflow run
+// for a shard uses, whether started from the API or as a child workflow, so
+// that at most one convergence per shard runs at a time.
+func ConvergeShardWorkflowID(shardID string) string {
+	return workflowID("converge-shard", shardID)
+}
+
+// ErrNotDatabaseShard is returned by PromoteReplica for shards that don't
+// run MySQL.
+var ErrNotDatabaseShard = errors.New("shard is not a database shard")
+
+// PromoteReplicaWorkflowID is the workflow ID of a shard's replica
+// promotion. Starting a promotion while one is running for the shard joins
+// the running one.
+func PromoteReplicaWorkflowID(shardID string) string {
+	return workflowID("promote-replica", shardID)
+}
+
+// PromoteReplicaParams mirrors workflow.PromoteReplicaParams.
+type PromoteReplicaParams struct {
+	ShardID       string `json:"shard_id"`
+	NodeID        string `json:"node_id,omitempty"`
+	MaxLagSeconds int    `json:"max_lag_seconds,omitempty"`
+	Force         bool   `json:"force,omitempty"`
+}
+
 type ShardService struct {
 	db DB
 	tc temporalclient.Client
@@ -122,24 +156,134 @@ func (s *ShardService) Retry(ctx context.Context, id string) error {
 	if err != nil {
 		return fmt.Errorf("reset shard %s status: %w", id, err)
 	}
-	return s.Converge(ctx, id)
+	if err := s.Converge(ctx, id); err != nil && !errors.Is(err, ErrConvergeRunning) {
+		return err
+	}
+	return nil
 }
 
+// Converge starts ConvergeShardWorkflow for a shard. It returns
+// ErrConvergeRunning if a convergence for the shard is already in flight;
+// a concurrent start that slips past the check is deduplicated by Temporal
+// through the fixed workflow ID.
 func (s *ShardService) Converge(ctx context.Context, shardID string) error {
 	var shardName string
 	if err := s.db.QueryRow(ctx, "SELECT name FROM shards WHERE id = $1", shardID).Scan(&shardName); err != nil {
 		return fmt.Errorf("get shard name for converge: %w", err)
 	}
 
-	wfID := workflowID("converge-shard", shardID)
-	_, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
-		ID:        wfID,
-		TaskQueue: "hosting-tasks",
-	}, "ConvergeShardWorkflow", struct {
-		ShardID string `json:"shard_id"`
-	}{ShardID: shardID})
+	status, err := s.ConvergeStatus(ctx, shardID)
+	if err != nil {
+		return err
+	}
+	if status.Running {
+		return fmt.Errorf("%w %s (workflow %s)", ErrConvergeRunning, shardName, status.WorkflowID)
+	}
+
+	err = startWorkflow(ctx, s.tc, s.db, "", model.ProvisionTask{
+		WorkflowName: "ConvergeShardWorkflow",
+		WorkflowID:   ConvergeShardWorkflowID(shardID),
+		Arg: struct {
+			ShardID string `json:"shard_id"`
+		}{ShardID: shardID},
+	})
 	if err != nil {
 		return fmt.Errorf("start ConvergeShardWorkflow: %w", err)
 	}
 	return nil
 }
+
+// PromoteReplica starts PromoteReplicaWorkflow to fail the database shard
+// over to one of its replicas. It is refused while the shard is converging,
+// since convergence acts on whichever node it read as primary.
+func (s *ShardService) PromoteReplica(ctx context.Context, shard *model.Shard, params PromoteReplicaParams) error {
+	if shard.Role != model.ShardRoleDatabase {
+		return ErrNotDatabaseShard
+	}
+
+	status, err := s.ConvergeStatus(ctx, shard.ID)
+	if err != nil {
+		return err
+	}
+	if status.Running {
+		return fmt.Errorf("%w %s (workflow %s)", ErrConvergeRunning, shard.Name, status.WorkflowID)
+	}
+
+	params.ShardID = shard.ID
+	err = startWorkflow(ctx, s.tc, s.db, "", model.ProvisionTask{
+		WorkflowName: "PromoteReplicaWorkflow",
+		WorkflowID:   PromoteReplicaWorkflowID(shard.ID),
+		Arg:          params,
+	})
+	if err != nil {
+		return fmt.Errorf("start PromoteReplicaWorkflow: %w", err)
+	}
+	return nil
+}
+
+// ConvergeStatus reports whether a ConvergeShardWorkflow is running for the
+// shard, including runs started as children of a cluster batch or tenant
+// deletion.
+func (s *ShardService) ConvergeStatus(ctx context.Context, shardID string) (*model.ShardConvergence, error) {
+	wfID := ConvergeShardWorkflowID(shardID)
+	status := &model.ShardConvergence{ShardID: shardID, WorkflowID: wfID}
+
+	desc, err := s.tc.DescribeWorkflowExecution(ctx, wfID, "")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			return status, nil
+		}
+		return nil, fmt.Errorf("describe convergence for shard %s: %w", shardID, err)
+	}
+	info := desc.GetWorkflowExecutionInfo()
+	if info.GetStatus() != enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING {
+		return status, nil
+	}
+	status.Running = true
+	status.RunID = info.GetExecution().GetRunId()
+	if info.GetStartTime() != nil {
+		t := info.GetStartTime().AsTime()
+		status.StartedAt = &t
+	}
+	return status, nil
+}
+
+// ConvergeCluster starts a ConvergeClusterWorkflow that converges every shard
+// in the cluster with at most maxConcurrent shards in flight. It returns the
+// batch ID used to poll progress with GetConvergeBatch.
+func (s *ShardService) ConvergeCluster(ctx context.Context, clusterID string, maxConcurrent int) (string, error) {
+	var id string
+	if err := s.db.QueryRow(ctx, "SELECT id FROM clusters WHERE id = $1", clusterID).Scan(&id); err != nil {
+		return "", fmt.Errorf("get cluster %s: %w", clusterID, err)
+	}
+
+	batchID := platform.NewName("cb")
+	err := startWorkflow(ctx, s.tc, s.db, "", model.ProvisionTask{
+		WorkflowName: "ConvergeClusterWorkflow",
+		WorkflowID:   workflowID("converge-cluster", batchID),
+		Arg: struct {
+			BatchID       string `json:"batch_id"`
+			ClusterID     string `json:"cluster_id"`
+			MaxConcurrent int    `json:"max_concurrent"`
+		}{BatchID: batchID, ClusterID: clusterID, MaxConcurrent: maxConcurrent},
+	})
+	if err != nil {
+		return "", fmt.Errorf("start ConvergeClusterWorkflow: %w", err)
+	}
+	return batchID, nil
+}
+
+// GetConvergeBatch returns the aggregated progress of a cluster convergence
+// batch by querying its workflow.
+func (s *ShardService) GetConvergeBatch(ctx context.Context, batchID string) (*model.ConvergeBatch, error) {
+	val, err := s.tc.QueryWorkflow(ctx, workflowID("converge-cluster", batchID), "", model.ConvergeBatchQuery)
+	if err != nil {
+		return nil, fmt.Errorf("query converge batch %s: %w", batchID, err)
+	}
+	var batch model.ConvergeBatch
+	if err := val.Get(&batch); err != nil {
+		return nil, fmt.Errorf("decode converge batch %s: %w", batchID, err)
+	}
+	return &batch, nil
+}
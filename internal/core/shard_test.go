@@ -12,6 +12,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	temporalclient "go.temporal.io/sdk/client"
+	temporalmocks "go.temporal.io/sdk/mocks"
 )
 
 func TestNewShardService(t *testing.T) {
@@ -285,3 +289,79 @@ func TestShardService_Delete_DBError(t *testing.T) {
 	assert.Contains(t, err.Error(), "delete shard")
 	db.AssertExpectations(t)
 }
+
+func TestShardService_ConvergeStatus_NotRunning(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewShardService(db, tc)
+	ctx := context.Background()
+
+	tc.On("DescribeWorkflowExecution", ctx, "converge-shard-test-shard-1", "").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+
+	status, err := svc.ConvergeStatus(ctx, "test-shard-1")
+	require.NoError(t, err)
+	assert.False(t, status.Running)
+	assert.Equal(t, "converge-shard-test-shard-1", status.WorkflowID)
+}
+
+func TestShardService_Converge_AlreadyRunning(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewShardService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, "SELECT name FROM shards WHERE id = $1", []any{"test-shard-1"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "web-1"
+			return nil
+		}})
+	tc.On("DescribeWorkflowExecution", ctx, "converge-shard-test-shard-1", "").
+		Return(describeStatus(enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING), nil)
+
+	err := svc.Converge(ctx, "test-shard-1")
+	require.ErrorIs(t, err, ErrConvergeRunning)
+	tc.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ---------- PromoteReplica ----------
+
+func TestShardService_PromoteReplica_NotDatabaseShard(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewShardService(&mockDB{}, tc)
+
+	err := svc.PromoteReplica(context.Background(), &model.Shard{ID: "test-shard-1", Role: model.ShardRoleWeb}, PromoteReplicaParams{})
+	require.ErrorIs(t, err, ErrNotDatabaseShard)
+	tc.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestShardService_PromoteReplica_ConvergeRunning(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewShardService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	tc.On("DescribeWorkflowExecution", ctx, "converge-shard-test-shard-1", "").
+		Return(describeStatus(enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING), nil)
+
+	err := svc.PromoteReplica(ctx, &model.Shard{ID: "test-shard-1", Name: "db-1", Role: model.ShardRoleDatabase}, PromoteReplicaParams{})
+	require.ErrorIs(t, err, ErrConvergeRunning)
+	tc.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestShardService_PromoteReplica_Success(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewShardService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	tc.On("DescribeWorkflowExecution", ctx, "converge-shard-test-shard-1", "").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+	tc.On("ExecuteWorkflow", ctx, mock.MatchedBy(func(opts temporalclient.StartWorkflowOptions) bool {
+		return opts.ID == "promote-replica-test-shard-1"
+	}), "PromoteReplicaWorkflow", PromoteReplicaParams{
+		ShardID: "test-shard-1", NodeID: "node-2", Force: true,
+	}).Return(&temporalmocks.WorkflowRun{}, nil)
+
+	err := svc.PromoteReplica(ctx, &model.Shard{ID: "test-shard-1", Role: model.ShardRoleDatabase}, PromoteReplicaParams{NodeID: "node-2", Force: true})
+	require.NoError(t, err)
+	tc.AssertExpectations(t)
+}
@@ -0,0 +1,312 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/secrets"
+	temporalclient "go.temporal.io/sdk/client"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrEmailNotEnabled is returned when a tenant's cluster has no mail server,
+// so the tenant cannot use email features.
+var ErrEmailNotEnabled = errors.New("email is not enabled for this tenant")
+
+// SMTPRelayUserService manages per-tenant SMTP submission credentials for
+// apps that send mail through the platform's Stalwart instance. Send counts
+// are read from the platform Loki when a Loki URL is configured.
+type SMTPRelayUserService struct {
+	db      DB
+	tc      temporalclient.Client
+	lokiURL string
+	client  *http.Client
+}
+
+func NewSMTPRelayUserService(db DB, tc temporalclient.Client, lokiURL string) *SMTPRelayUserService {
+	return &SMTPRelayUserService{
+		db:      db,
+		tc:      tc,
+		lokiURL: strings.TrimRight(lokiURL, "/"),
+		client:  &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Create generates a password for the relay user, stores its bcrypt hash and
+// starts the CreateSMTPRelayUserWorkflow. The tenant's cluster must run
+// Stalwart. Returns the plaintext password for the one-time API response.
+func (s *SMTPRelayUserService) Create(ctx context.Context, u *model.SMTPRelayUser) (string, error) {
+	hostname, err := s.relayHostname(ctx, u.TenantID)
+	if err != nil {
+		return "", err
+	}
+
+	password, err := secrets.Generate()
+	if err != nil {
+		return "", fmt.Errorf("generate relay password: %w", err)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("hash relay password: %w", err)
+	}
+	u.PasswordHash = string(hash)
+
+	_, err = s.db.Exec(ctx,
+		`INSERT INTO smtp_relay_users (id, tenant_id, username, password_hash, max_messages_per_hour, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		u.ID, u.TenantID, u.Username, u.PasswordHash, u.MaxMessagesPerHour, u.Status, u.CreatedAt, u.UpdatedAt,
+	)
+	if err != nil {
+		return "", fmt.Errorf("insert smtp relay user: %w", err)
+	}
+	u.Hostname = hostname
+	u.Port = model.SMTPRelayPort
+
+	if err := signalProvision(ctx, s.tc, s.db, u.TenantID, model.ProvisionTask{
+		WorkflowName: "CreateSMTPRelayUserWorkflow",
+		WorkflowID:   workflowID("smtp-relay-user", u.ID),
+		Arg:          u.ID,
+	}); err != nil {
+		return "", fmt.Errorf("signal CreateSMTPRelayUserWorkflow: %w", err)
+	}
+
+	return password, nil
+}
+
+// relayHostname returns the submission hostname for a tenant: the brand's
+// mail_hostname, falling back to the cluster's. It returns
+// ErrEmailNotEnabled if the tenant's cluster has no Stalwart instance.
+func (s *SMTPRelayUserService) relayHostname(ctx context.Context, tenantID string) (string, error) {
+	var brandHostname string
+	var clusterConfig []byte
+	err := s.db.QueryRow(ctx,
+		`SELECT b.mail_hostname, c.config
+		 FROM tenants t
+		 JOIN brands b ON b.id = t.brand_id
+		 JOIN clusters c ON c.id = t.cluster_id
+		 WHERE t.id = $1`, tenantID,
+	).Scan(&brandHostname, &clusterConfig)
+	if err != nil {
+		return "", fmt.Errorf("get mail config for tenant %s: %w", tenantID, err)
+	}
+
+	var cfg struct {
+		StalwartURL  string `json:"stalwart_url"`
+		MailHostname string `json:"mail_hostname"`
+	}
+	if len(clusterConfig) > 0 {
+		if err := json.Unmarshal(clusterConfig, &cfg); err != nil {
+			return "", fmt.Errorf("parse cluster config: %w", err)
+		}
+	}
+	if cfg.StalwartURL == "" {
+		return "", ErrEmailNotEnabled
+	}
+	if brandHostname != "" {
+		return brandHostname, nil
+	}
+	return cfg.MailHostname, nil
+}
+
+// GetByID returns a relay user with its hostname. Send counts are loaded
+// separately by LoadSendCounts.
+func (s *SMTPRelayUserService) GetByID(ctx context.Context, id string) (*model.SMTPRelayUser, error) {
+	var u model.SMTPRelayUser
+	err := s.db.QueryRow(ctx,
+		`SELECT id, tenant_id, username, max_messages_per_hour, status, status_message, created_at, updated_at
+		 FROM smtp_relay_users WHERE id = $1`, id,
+	).Scan(&u.ID, &u.TenantID, &u.Username, &u.MaxMessagesPerHour, &u.Status, &u.StatusMessage, &u.CreatedAt, &u.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get smtp relay user %s: %w", id, err)
+	}
+
+	if hostname, err := s.relayHostname(ctx, u.TenantID); err == nil {
+		u.Hostname = hostname
+	}
+	u.Port = model.SMTPRelayPort
+	return &u, nil
+}
+
+// ListByTenant returns a page of a tenant's relay users.
+func (s *SMTPRelayUserService) ListByTenant(ctx context.Context, tenantID string, limit int, cursor string) ([]model.SMTPRelayUser, bool, error) {
+	query := `SELECT id, tenant_id, username, max_messages_per_hour, status, status_message, created_at, updated_at FROM smtp_relay_users WHERE tenant_id = $1`
+	args := []any{tenantID}
+	argIdx := 2
+
+	if cursor != "" {
+		query += fmt.Sprintf(` AND id > $%d`, argIdx)
+		args = append(args, cursor)
+		argIdx++
+	}
+
+	query += ` ORDER BY id`
+	query += fmt.Sprintf(` LIMIT $%d`, argIdx)
+	args = append(args, limit+1)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("list smtp relay users for tenant %s: %w", tenantID, err)
+	}
+	defer rows.Close()
+
+	var users []model.SMTPRelayUser
+	for rows.Next() {
+		var u model.SMTPRelayUser
+		if err := rows.Scan(&u.ID, &u.TenantID, &u.Username, &u.MaxMessagesPerHour, &u.Status, &u.StatusMessage,
+			&u.CreatedAt, &u.UpdatedAt); err != nil {
+			return nil, false, fmt.Errorf("scan smtp relay user: %w", err)
+		}
+		u.Port = model.SMTPRelayPort
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("iterate smtp relay users: %w", err)
+	}
+
+	hasMore := len(users) > limit
+	if hasMore {
+		users = users[:limit]
+	}
+	if len(users) > 0 {
+		if hostname, err := s.relayHostname(ctx, tenantID); err == nil {
+			for i := range users {
+				users[i].Hostname = hostname
+			}
+		}
+	}
+	return users, hasMore, nil
+}
+
+// UpdateRateLimit changes a relay user's hourly message limit and starts the
+// UpdateSMTPRelayUserWorkflow to apply it in Stalwart.
+func (s *SMTPRelayUserService) UpdateRateLimit(ctx context.Context, id string, maxMessagesPerHour int) error {
+	var tenantID string
+	err := s.db.QueryRow(ctx,
+		`UPDATE smtp_relay_users SET max_messages_per_hour = $1, status = $2, status_message = NULL, updated_at = now()
+		 WHERE id = $3 RETURNING tenant_id`,
+		maxMessagesPerHour, model.StatusProvisioning, id,
+	).Scan(&tenantID)
+	if err != nil {
+		return fmt.Errorf("update smtp relay user %s: %w", id, err)
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "UpdateSMTPRelayUserWorkflow",
+		WorkflowID:   workflowID("smtp-relay-user", id),
+		Arg:          id,
+	}); err != nil {
+		return fmt.Errorf("signal UpdateSMTPRelayUserWorkflow: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPRelayUserService) Delete(ctx context.Context, id string) error {
+	var tenantID string
+	err := s.db.QueryRow(ctx,
+		"UPDATE smtp_relay_users SET status = $1, updated_at = now() WHERE id = $2 RETURNING tenant_id",
+		model.StatusDeleting, id,
+	).Scan(&tenantID)
+	if err != nil {
+		return fmt.Errorf("set smtp relay user %s status to deleting: %w", id, err)
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "DeleteSMTPRelayUserWorkflow",
+		WorkflowID:   workflowID("smtp-relay-user", id),
+		Arg:          id,
+	}); err != nil {
+		return fmt.Errorf("signal DeleteSMTPRelayUserWorkflow: %w", err)
+	}
+	return nil
+}
+
+func (s *SMTPRelayUserService) Retry(ctx context.Context, id string) error {
+	var status, tenantID string
+	err := s.db.QueryRow(ctx, "SELECT status, tenant_id FROM smtp_relay_users WHERE id = $1", id).Scan(&status, &tenantID)
+	if err != nil {
+		return fmt.Errorf("get smtp relay user status: %w", err)
+	}
+	if status != model.StatusFailed {
+		return fmt.Errorf("smtp relay user %s is not in failed state (current: %s)", id, status)
+	}
+	_, err = s.db.Exec(ctx, "UPDATE smtp_relay_users SET status = $1, status_message = NULL, updated_at = now() WHERE id = $2", model.StatusProvisioning, id)
+	if err != nil {
+		return fmt.Errorf("set smtp relay user %s status to provisioning: %w", id, err)
+	}
+	return signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "CreateSMTPRelayUserWorkflow",
+		WorkflowID:   workflowID("smtp-relay-user", id),
+		Arg:          id,
+	})
+}
+
+// LoadSendCounts sets the messages a relay user submitted in the last hour
+// and day, counted from Stalwart's log in Loki. SendCounts is left nil if
+// Loki is not configured or cannot be queried, since the counts are
+// informational.
+func (s *SMTPRelayUserService) LoadSendCounts(ctx context.Context, u *model.SMTPRelayUser) {
+	if s.lokiURL == "" {
+		return
+	}
+	hour, err := s.countSubmissions(ctx, u.Username, "1h")
+	if err != nil {
+		return
+	}
+	day, err := s.countSubmissions(ctx, u.Username, "1d")
+	if err != nil {
+		return
+	}
+	u.SendCounts = &model.SMTPRelaySendCounts{LastHour: hour, LastDay: day}
+}
+
+// countSubmissions runs a Loki instant query counting the messages Stalwart
+// queued for an authenticated relay user over the given range.
+func (s *SMTPRelayUserService) countSubmissions(ctx context.Context, username, window string) (int64, error) {
+	query := fmt.Sprintf(`sum(count_over_time({job="stalwart"} |= "queue.queue-message-authenticated" |= %q [%s]))`,
+		`"`+username+`"`, window)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		s.lokiURL+"/loki/api/v1/query?query="+url.QueryEscape(query), nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("loki returned %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Result []struct {
+				Value [2]any `json:"value"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("decode loki response: %w", err)
+	}
+	// An empty vector means no matching log lines in the window.
+	if len(body.Data.Result) == 0 {
+		return 0, nil
+	}
+	v, ok := body.Data.Result[0].Value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected loki sample value")
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse loki sample value: %w", err)
+	}
+	return int64(f), nil
+}
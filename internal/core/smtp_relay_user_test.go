@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/secrets"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func relayMailConfigRow(brandHostname, clusterConfig string) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = brandHostname
+		*(dest[1].(*[]byte)) = []byte(clusterConfig)
+		return nil
+	}}
+}
+
+func TestSMTPRelayUserService_Create(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewSMTPRelayUserService(db, tc, "")
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("b.mail_hostname"), []any{"tenant-1"}).
+		Return(relayMailConfigRow("", `{"stalwart_url":"http://stalwart:8080","mail_hostname":"mail.cluster.example"}`))
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id")
+	wfRun.On("GetRunID").Return("mock-run-id")
+	tc.On("SignalWithStartWorkflow", mock.Anything, "tenant-tenant-1", model.ProvisionSignalName,
+		mock.MatchedBy(func(task model.ProvisionTask) bool {
+			return task.WorkflowName == "CreateSMTPRelayUserWorkflow" && task.Arg == "relay-abc"
+		}), mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	u := &model.SMTPRelayUser{ID: "relay-abc", TenantID: "tenant-1", Username: "relay-abc", MaxMessagesPerHour: 100}
+	password, err := svc.Create(ctx, u)
+	require.NoError(t, err)
+	assert.Len(t, password, secrets.DefaultGenerator.Length)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)))
+	assert.Equal(t, "mail.cluster.example", u.Hostname)
+	assert.Equal(t, model.SMTPRelayPort, u.Port)
+	tc.AssertExpectations(t)
+}
+
+func TestSMTPRelayUserService_Create_EmailNotEnabled(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewSMTPRelayUserService(db, tc, "")
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("b.mail_hostname"), []any{"tenant-1"}).
+		Return(relayMailConfigRow("mail.brand.example", `{}`))
+
+	_, err := svc.Create(ctx, &model.SMTPRelayUser{ID: "relay-abc", TenantID: "tenant-1", Username: "relay-abc"})
+	assert.ErrorIs(t, err, ErrEmailNotEnabled)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSMTPRelayUserService_RelayHostname_PrefersBrand(t *testing.T) {
+	db := &mockDB{}
+	svc := NewSMTPRelayUserService(db, nil, "")
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("b.mail_hostname"), []any{"tenant-1"}).
+		Return(relayMailConfigRow("mail.brand.example", `{"stalwart_url":"http://stalwart:8080","mail_hostname":"mail.cluster.example"}`))
+
+	hostname, err := svc.relayHostname(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, "mail.brand.example", hostname)
+}
+
+func TestSMTPRelayUserService_LoadSendCounts(t *testing.T) {
+	var queries []string
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/loki/api/v1/query", r.URL.Path)
+		q := r.URL.Query().Get("query")
+		queries = append(queries, q)
+		if strings.Contains(q, "[1h]") {
+			_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1760000000,"7"]}]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1760000000,"42"]}]}}`))
+	}))
+	defer loki.Close()
+
+	svc := NewSMTPRelayUserService(&mockDB{}, nil, loki.URL+"/")
+	u := &model.SMTPRelayUser{Username: "relay-abc"}
+	svc.LoadSendCounts(context.Background(), u)
+
+	require.NotNil(t, u.SendCounts)
+	assert.Equal(t, int64(7), u.SendCounts.LastHour)
+	assert.Equal(t, int64(42), u.SendCounts.LastDay)
+	require.Len(t, queries, 2)
+	assert.Contains(t, queries[0], `{job="stalwart"}`)
+	assert.Contains(t, queries[0], `relay-abc`)
+}
+
+func TestSMTPRelayUserService_LoadSendCounts_NoMatches(t *testing.T) {
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[]}}`))
+	}))
+	defer loki.Close()
+
+	svc := NewSMTPRelayUserService(&mockDB{}, nil, loki.URL)
+	u := &model.SMTPRelayUser{Username: "relay-abc"}
+	svc.LoadSendCounts(context.Background(), u)
+
+	require.NotNil(t, u.SendCounts)
+	assert.Zero(t, u.SendCounts.LastHour)
+	assert.Zero(t, u.SendCounts.LastDay)
+}
+
+func TestSMTPRelayUserService_LoadSendCounts_LokiError(t *testing.T) {
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer loki.Close()
+
+	svc := NewSMTPRelayUserService(&mockDB{}, nil, loki.URL)
+	u := &model.SMTPRelayUser{Username: "relay-abc"}
+	svc.LoadSendCounts(context.Background(), u)
+	assert.Nil(t, u.SendCounts)
+
+	NewSMTPRelayUserService(&mockDB{}, nil, "").LoadSendCounts(context.Background(), u)
+	assert.Nil(t, u.SendCounts)
+}
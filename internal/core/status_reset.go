@@ -0,0 +1,146 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	temporalclient "go.temporal.io/sdk/client"
+)
+
+// ErrWorkflowRunning is returned by Reset while a workflow that may still
+// update the resource is running.
+var ErrWorkflowRunning = errors.New("a workflow for this resource is still running")
+
+// ErrStatusNotStuck is returned by Reset for resources that are not in a
+// transitional status.
+var ErrStatusNotStuck = errors.New("resource is not in a transitional status")
+
+// stuckStatuses are the transitional statuses a crashed workflow can leave
+// behind. Everything else is either settled or handled by retry.
+var stuckStatuses = map[string]bool{
+	model.StatusPending:      true,
+	model.StatusProvisioning: true,
+	model.StatusConverging:   true,
+	model.StatusMigrating:    true,
+	model.StatusDeleting:     true,
+}
+
+// resettableResource describes how to find a resource's status and the
+// workflows that may still be working on it.
+type resettableResource struct {
+	table         string
+	resolveTenant func(ctx context.Context, db DB, id string) (string, error)
+	// directWorkflows lists workflows started outside the tenant's
+	// provision queue that may update the resource.
+	directWorkflows func(id string) []string
+}
+
+var resettableResources = map[string]resettableResource{
+	"tenants": {
+		table: "tenants",
+		resolveTenant: func(_ context.Context, _ DB, id string) (string, error) {
+			return id, nil
+		},
+	},
+	"webroots": {
+		table:         "webroots",
+		resolveTenant: resolveTenantIDFromWebroot,
+	},
+	"databases": {
+		table:         "databases",
+		resolveTenant: resolveTenantIDFromDatabase,
+	},
+	"certificates": {
+		table:         "certificates",
+		resolveTenant: resolveTenantIDFromCertificate,
+		directWorkflows: func(id string) []string {
+			return []string{workflowID("renew-le-cert", id)}
+		},
+	},
+}
+
+// StatusResetService recovers resources left in a transitional status by a
+// workflow that crashed or was terminated.
+type StatusResetService struct {
+	db DB
+	tc temporalclient.Client
+}
+
+func NewStatusResetService(db DB, tc temporalclient.Client) *StatusResetService {
+	return &StatusResetService{db: db, tc: tc}
+}
+
+// Reset moves a stuck resource to failed so it can be retried. It refuses
+// while the tenant's provision workflow, which runs and queues every
+// provisioning task for the tenant, or any directly started workflow for the
+// resource is still running.
+func (s *StatusResetService) Reset(ctx context.Context, resourceType, id string) (*model.StatusReset, error) {
+	res, ok := resettableResources[resourceType]
+	if !ok {
+		return nil, fmt.Errorf("status reset is not supported for %s", resourceType)
+	}
+
+	var status string
+	err := s.db.QueryRow(ctx, "SELECT status FROM "+res.table+" WHERE id = $1", id).Scan(&status)
+	if err != nil {
+		return nil, fmt.Errorf("get %s %s status: %w", resourceType, id, err)
+	}
+	if !stuckStatuses[status] {
+		return nil, fmt.Errorf("%w: %s %s is %s", ErrStatusNotStuck, resourceType, id, status)
+	}
+
+	tenantID, err := res.resolveTenant(ctx, s.db, id)
+	if err != nil {
+		return nil, fmt.Errorf("resolve tenant for %s %s: %w", resourceType, id, err)
+	}
+	wfIDs := []string{fmt.Sprintf("tenant-%s", tenantID)}
+	if res.directWorkflows != nil {
+		wfIDs = append(wfIDs, res.directWorkflows(id)...)
+	}
+	for _, wfID := range wfIDs {
+		running, err := s.workflowRunning(ctx, wfID)
+		if err != nil {
+			return nil, err
+		}
+		if running {
+			return nil, fmt.Errorf("%w: %s", ErrWorkflowRunning, wfID)
+		}
+	}
+
+	msg := fmt.Sprintf("status reset from %s: no workflow running", status)
+	tag, err := s.db.Exec(ctx,
+		"UPDATE "+res.table+" SET status = $1, status_message = $2, updated_at = now() WHERE id = $3 AND status = $4",
+		model.StatusFailed, msg, id, status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("reset %s %s status: %w", resourceType, id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, fmt.Errorf("%w: %s %s changed status during reset", ErrWorkflowRunning, resourceType, id)
+	}
+
+	return &model.StatusReset{
+		ResourceType:   resourceType,
+		ResourceID:     id,
+		PreviousStatus: status,
+		Status:         model.StatusFailed,
+	}, nil
+}
+
+// workflowRunning reports whether the latest run of wfID is still running.
+// A workflow that was never started, or whose history is gone, is not.
+func (s *StatusResetService) workflowRunning(ctx context.Context, wfID string) (bool, error) {
+	desc, err := s.tc.DescribeWorkflowExecution(ctx, wfID, "")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("describe workflow %s: %w", wfID, err)
+	}
+	return desc.GetWorkflowExecutionInfo().GetStatus() == enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING, nil
+}
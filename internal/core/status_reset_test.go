@@ -0,0 +1,160 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/api/serviceerror"
+	"go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func statusRow(status string) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = status
+		return nil
+	}}
+}
+
+func tenantIDRow(id string) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = id
+		return nil
+	}}
+}
+
+func describeStatus(status enumspb.WorkflowExecutionStatus) *workflowservice.DescribeWorkflowExecutionResponse {
+	return &workflowservice.DescribeWorkflowExecutionResponse{
+		WorkflowExecutionInfo: &workflow.WorkflowExecutionInfo{Status: status},
+	}
+}
+
+func TestStatusResetService_Reset_Webroot(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewStatusResetService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, "SELECT status FROM webroots WHERE id = $1", []any{"test-webroot-1"}).
+		Return(statusRow(model.StatusProvisioning)).Once()
+	db.On("QueryRow", ctx, "SELECT tenant_id FROM webroots WHERE id = $1", []any{"test-webroot-1"}).
+		Return(tenantIDRow("test-tenant-1")).Once()
+	tc.On("DescribeWorkflowExecution", ctx, "tenant-test-tenant-1", "").
+		Return(describeStatus(enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED), nil)
+	db.On("Exec", ctx, mock.AnythingOfType("string"),
+		[]any{model.StatusFailed, "status reset from provisioning: no workflow running", "test-webroot-1", model.StatusProvisioning}).
+		Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+
+	reset, err := svc.Reset(ctx, "webroots", "test-webroot-1")
+	require.NoError(t, err)
+	assert.Equal(t, &model.StatusReset{
+		ResourceType:   "webroots",
+		ResourceID:     "test-webroot-1",
+		PreviousStatus: model.StatusProvisioning,
+		Status:         model.StatusFailed,
+	}, reset)
+	db.AssertExpectations(t)
+}
+
+func TestStatusResetService_Reset_TenantWorkflowNeverStarted(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewStatusResetService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, "SELECT status FROM tenants WHERE id = $1", []any{"test-tenant-1"}).
+		Return(statusRow(model.StatusDeleting))
+	tc.On("DescribeWorkflowExecution", ctx, "tenant-test-tenant-1", "").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).
+		Return(pgconn.NewCommandTag("UPDATE 1"), nil)
+
+	reset, err := svc.Reset(ctx, "tenants", "test-tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusDeleting, reset.PreviousStatus)
+	assert.Equal(t, model.StatusFailed, reset.Status)
+}
+
+func TestStatusResetService_Reset_TenantWorkflowRunning(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewStatusResetService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, "SELECT status FROM databases WHERE id = $1", []any{"test-db-1"}).
+		Return(statusRow(model.StatusProvisioning)).Once()
+	db.On("QueryRow", ctx, "SELECT tenant_id FROM databases WHERE id = $1", []any{"test-db-1"}).
+		Return(tenantIDRow("test-tenant-1")).Once()
+	tc.On("DescribeWorkflowExecution", ctx, "tenant-test-tenant-1", "").
+		Return(describeStatus(enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING), nil)
+
+	_, err := svc.Reset(ctx, "databases", "test-db-1")
+	require.ErrorIs(t, err, ErrWorkflowRunning)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStatusResetService_Reset_CertificateRenewalRunning(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewStatusResetService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, "SELECT status FROM certificates WHERE id = $1", []any{"test-cert-1"}).
+		Return(statusRow(model.StatusPending)).Once()
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-cert-1"}).
+		Return(tenantIDRow("test-tenant-1")).Once()
+	tc.On("DescribeWorkflowExecution", ctx, "tenant-test-tenant-1", "").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+	tc.On("DescribeWorkflowExecution", ctx, "renew-le-cert-test-cert-1", "").
+		Return(describeStatus(enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING), nil)
+
+	_, err := svc.Reset(ctx, "certificates", "test-cert-1")
+	require.ErrorIs(t, err, ErrWorkflowRunning)
+	assert.Contains(t, err.Error(), "renew-le-cert-test-cert-1")
+}
+
+func TestStatusResetService_Reset_NotStuck(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewStatusResetService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, "SELECT status FROM webroots WHERE id = $1", []any{"test-webroot-1"}).
+		Return(statusRow(model.StatusActive))
+
+	_, err := svc.Reset(ctx, "webroots", "test-webroot-1")
+	require.ErrorIs(t, err, ErrStatusNotStuck)
+	tc.AssertNotCalled(t, "DescribeWorkflowExecution", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestStatusResetService_Reset_StatusChangedConcurrently(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewStatusResetService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, "SELECT status FROM tenants WHERE id = $1", []any{"test-tenant-1"}).
+		Return(statusRow(model.StatusProvisioning))
+	tc.On("DescribeWorkflowExecution", ctx, "tenant-test-tenant-1", "").
+		Return(describeStatus(enumspb.WORKFLOW_EXECUTION_STATUS_TERMINATED), nil)
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).
+		Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+
+	_, err := svc.Reset(ctx, "tenants", "test-tenant-1")
+	require.ErrorIs(t, err, ErrWorkflowRunning)
+}
+
+func TestStatusResetService_Reset_UnsupportedResource(t *testing.T) {
+	svc := NewStatusResetService(&mockDB{}, &temporalmocks.Client{})
+
+	_, err := svc.Reset(context.Background(), "zones", "test-zone-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported")
+}
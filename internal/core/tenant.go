@@ -2,13 +2,25 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	"github.com/edvin/hosting/internal/secrets"
+	"go.temporal.io/api/serviceerror"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
+// ErrTenantMigrating is returned by Migrate while the tenant is already being
+// moved to another shard.
+var ErrTenantMigrating = errors.New("tenant is being migrated to another shard")
+
+// ErrNoMigration is returned by MigrationStatus for a tenant that has not
+// been migrated, or whose migration history has expired.
+var ErrNoMigration = errors.New("no migration found for tenant")
+
 type TenantService struct {
 	db DB
 	tc temporalclient.Client
@@ -18,17 +30,14 @@ func NewTenantService(db DB, tc temporalclient.Client) *TenantService {
 	return &TenantService{db: db, tc: tc}
 }
 
+// Create inserts the tenant and starts CreateTenantWorkflow. The tenant's UID
+// is left at 0; the workflow allocates it from the cluster's UID range.
 func (s *TenantService) Create(ctx context.Context, tenant *model.Tenant) error {
-	uid, err := s.NextUID(ctx)
-	if err != nil {
-		return fmt.Errorf("create tenant: %w", err)
-	}
-	tenant.UID = uid
-
-	_, err = s.db.Exec(ctx,
-		`INSERT INTO tenants (id, brand_id, customer_id, region_id, cluster_id, shard_id, uid, sftp_enabled, ssh_enabled, disk_quota_bytes, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
-		tenant.ID, tenant.BrandID, tenant.CustomerID, tenant.RegionID, tenant.ClusterID, tenant.ShardID, tenant.UID,
+	tenant.UID = 0
+	_, err := s.db.Exec(ctx,
+		`INSERT INTO tenants (id, brand_id, customer_id, reseller_id, region_id, cluster_id, shard_id, uid, sftp_enabled, ssh_enabled, disk_quota_bytes, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		tenant.ID, tenant.BrandID, tenant.CustomerID, tenant.ResellerID, tenant.RegionID, tenant.ClusterID, tenant.ShardID, tenant.UID,
 		tenant.SFTPEnabled, tenant.SSHEnabled, tenant.DiskQuotaBytes, tenant.Status, tenant.CreatedAt, tenant.UpdatedAt,
 	)
 	if err != nil {
@@ -50,7 +59,7 @@ func (s *TenantService) GetByID(ctx context.Context, id string) (*model.Tenant,
 	var t model.Tenant
 	err := s.db.QueryRow(ctx,
 		`SELECT t.id, t.brand_id, t.customer_id, t.region_id, t.cluster_id, t.shard_id, t.uid, t.sftp_enabled, t.ssh_enabled, t.disk_quota_bytes, t.status, t.status_message, t.suspend_reason, t.created_at, t.updated_at,
-		        r.name, c.name, s.name
+		        r.name, c.name, s.name, t.reseller_id
 		 FROM tenants t
 		 JOIN regions r ON r.id = t.region_id
 		 JOIN clusters c ON c.id = t.cluster_id
@@ -58,15 +67,29 @@ func (s *TenantService) GetByID(ctx context.Context, id string) (*model.Tenant,
 		 WHERE t.id = $1`, id,
 	).Scan(&t.ID, &t.BrandID, &t.CustomerID, &t.RegionID, &t.ClusterID, &t.ShardID, &t.UID,
 		&t.SFTPEnabled, &t.SSHEnabled, &t.DiskQuotaBytes, &t.Status, &t.StatusMessage, &t.SuspendReason, &t.CreatedAt, &t.UpdatedAt,
-		&t.RegionName, &t.ClusterName, &t.ShardName)
+		&t.RegionName, &t.ClusterName, &t.ShardName, &t.ResellerID)
 	if err != nil {
 		return nil, fmt.Errorf("get tenant %s: %w", id, err)
 	}
 	return &t, nil
 }
 
+// PasswordPolicy returns the password policy of the tenant's brand.
+func (s *TenantService) PasswordPolicy(ctx context.Context, tenantID string) (secrets.Policy, error) {
+	row := s.db.QueryRow(ctx,
+		`SELECT b.password_min_length, b.password_classes
+		 FROM tenants t JOIN brands b ON b.id = t.brand_id
+		 WHERE t.id = $1`, tenantID,
+	)
+	policy, err := scanPasswordPolicy(row)
+	if err != nil {
+		return secrets.Policy{}, fmt.Errorf("get password policy for tenant %s: %w", tenantID, err)
+	}
+	return policy, nil
+}
+
 func (s *TenantService) List(ctx context.Context, params request.ListParams) ([]model.Tenant, bool, error) {
-	query := `SELECT t.id, t.brand_id, t.customer_id, t.region_id, t.cluster_id, t.shard_id, t.uid, t.sftp_enabled, t.ssh_enabled, t.disk_quota_bytes, t.status, t.status_message, t.suspend_reason, t.created_at, t.updated_at, r.name, c.name, s.name FROM tenants t JOIN regions r ON r.id = t.region_id JOIN clusters c ON c.id = t.cluster_id LEFT JOIN shards s ON s.id = t.shard_id WHERE true`
+	query := `SELECT t.id, t.brand_id, t.customer_id, t.region_id, t.cluster_id, t.shard_id, t.uid, t.sftp_enabled, t.ssh_enabled, t.disk_quota_bytes, t.status, t.status_message, t.suspend_reason, t.created_at, t.updated_at, r.name, c.name, s.name, t.reseller_id FROM tenants t JOIN regions r ON r.id = t.region_id JOIN clusters c ON c.id = t.cluster_id LEFT JOIN shards s ON s.id = t.shard_id WHERE true`
 	args := []any{}
 	argIdx := 1
 
@@ -95,6 +118,11 @@ func (s *TenantService) List(ctx context.Context, params request.ListParams) ([]
 		args = append(args, params.CustomerID)
 		argIdx++
 	}
+	if params.ResellerID != "" {
+		query += fmt.Sprintf(` AND t.reseller_id = $%d`, argIdx)
+		args = append(args, params.ResellerID)
+		argIdx++
+	}
 
 	sortCol := "t.created_at"
 	switch params.Sort {
@@ -122,7 +150,7 @@ func (s *TenantService) List(ctx context.Context, params request.ListParams) ([]
 		var t model.Tenant
 		if err := rows.Scan(&t.ID, &t.BrandID, &t.CustomerID, &t.RegionID, &t.ClusterID, &t.ShardID, &t.UID,
 			&t.SFTPEnabled, &t.SSHEnabled, &t.DiskQuotaBytes, &t.Status, &t.StatusMessage, &t.SuspendReason, &t.CreatedAt, &t.UpdatedAt,
-			&t.RegionName, &t.ClusterName, &t.ShardName); err != nil {
+			&t.RegionName, &t.ClusterName, &t.ShardName, &t.ResellerID); err != nil {
 			return nil, false, fmt.Errorf("scan tenant: %w", err)
 		}
 		tenants = append(tenants, t)
@@ -139,7 +167,7 @@ func (s *TenantService) List(ctx context.Context, params request.ListParams) ([]
 }
 
 func (s *TenantService) ListByShard(ctx context.Context, shardID string, limit int, cursor string) ([]model.Tenant, bool, error) {
-	query := `SELECT t.id, t.brand_id, t.customer_id, t.region_id, t.cluster_id, t.shard_id, t.uid, t.sftp_enabled, t.ssh_enabled, t.disk_quota_bytes, t.status, t.status_message, t.suspend_reason, t.created_at, t.updated_at, r.name, c.name, s.name FROM tenants t JOIN regions r ON r.id = t.region_id JOIN clusters c ON c.id = t.cluster_id LEFT JOIN shards s ON s.id = t.shard_id WHERE t.shard_id = $1`
+	query := `SELECT t.id, t.brand_id, t.customer_id, t.region_id, t.cluster_id, t.shard_id, t.uid, t.sftp_enabled, t.ssh_enabled, t.disk_quota_bytes, t.status, t.status_message, t.suspend_reason, t.created_at, t.updated_at, r.name, c.name, s.name, t.reseller_id FROM tenants t JOIN regions r ON r.id = t.region_id JOIN clusters c ON c.id = t.cluster_id LEFT JOIN shards s ON s.id = t.shard_id WHERE t.shard_id = $1`
 	args := []any{shardID}
 	argIdx := 2
 
@@ -164,7 +192,7 @@ func (s *TenantService) ListByShard(ctx context.Context, shardID string, limit i
 		var t model.Tenant
 		if err := rows.Scan(&t.ID, &t.BrandID, &t.CustomerID, &t.RegionID, &t.ClusterID, &t.ShardID, &t.UID,
 			&t.SFTPEnabled, &t.SSHEnabled, &t.DiskQuotaBytes, &t.Status, &t.StatusMessage, &t.SuspendReason, &t.CreatedAt, &t.UpdatedAt,
-			&t.RegionName, &t.ClusterName, &t.ShardName); err != nil {
+			&t.RegionName, &t.ClusterName, &t.ShardName, &t.ResellerID); err != nil {
 			return nil, false, fmt.Errorf("scan tenant: %w", err)
 		}
 		tenants = append(tenants, t)
@@ -261,18 +289,24 @@ func (s *TenantService) Unsuspend(ctx context.Context, id string) error {
 	return nil
 }
 
+// Migrate moves the tenant to targetShardID. The tenant is set to migrating
+// right away so conflicting changes are rejected until MigrateTenantWorkflow
+// finishes; ErrTenantMigrating is returned if a migration is already running.
 func (s *TenantService) Migrate(ctx context.Context, id string, targetShardID string, migrateZones, migrateFQDNs bool) error {
-	_, err := s.db.Exec(ctx,
-		"UPDATE tenants SET status = $1, updated_at = now() WHERE id = $2",
-		model.StatusProvisioning, id,
+	tag, err := s.db.Exec(ctx,
+		"UPDATE tenants SET status = $1, updated_at = now() WHERE id = $2 AND status <> $1",
+		model.StatusMigrating, id,
 	)
 	if err != nil {
-		return fmt.Errorf("set tenant %s status to provisioning: %w", id, err)
+		return fmt.Errorf("set tenant %s status to migrating: %w", id, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrTenantMigrating
 	}
 
 	if err := signalProvision(ctx, s.tc, s.db, id, model.ProvisionTask{
 		WorkflowName: "MigrateTenantWorkflow",
-		WorkflowID:   fmt.Sprintf("migrate-tenant-%s", id),
+		WorkflowID:   migrateTenantWorkflowID(id),
 		Arg: MigrateTenantParams{
 			TenantID:      id,
 			TargetShardID: targetShardID,
@@ -286,6 +320,47 @@ func (s *TenantService) Migrate(ctx context.Context, id string, targetShardID st
 	return nil
 }
 
+// MigrationStatus returns the progress of the tenant's latest migration by
+// querying its MigrateTenantWorkflow, which also answers after it finished.
+func (s *TenantService) MigrationStatus(ctx context.Context, id string) (*model.TenantMigration, error) {
+	val, err := s.tc.QueryWorkflow(ctx, migrateTenantWorkflowID(id), "", "tenant-migration")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			return nil, ErrNoMigration
+		}
+		return nil, fmt.Errorf("query migration of tenant %s: %w", id, err)
+	}
+	var migration model.TenantMigration
+	if err := val.Get(&migration); err != nil {
+		return nil, fmt.Errorf("decode migration of tenant %s: %w", id, err)
+	}
+	return &migration, nil
+}
+
+// FixPermissions reconciles the ownership and modes of the tenant's storage
+// tree on its shard and returns what was changed. It waits for
+// FixTenantPermissionsWorkflow, which fails fast if the node agent is not
+// polling its task queue.
+func (s *TenantService) FixPermissions(ctx context.Context, id string) (*model.TenantPermissionsFix, error) {
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("fix-tenant-permissions", id+"-"+platform.NewID()),
+		TaskQueue: taskQueue,
+	}, "FixTenantPermissionsWorkflow", id)
+	if err != nil {
+		return nil, fmt.Errorf("start FixTenantPermissionsWorkflow: %w", err)
+	}
+	var fix model.TenantPermissionsFix
+	if err := run.Get(ctx, &fix); err != nil {
+		return nil, fmt.Errorf("fix permissions of tenant %s: %w", id, err)
+	}
+	return &fix, nil
+}
+
+func migrateTenantWorkflowID(tenantID string) string {
+	return fmt.Sprintf("migrate-tenant-%s", tenantID)
+}
+
 // MigrateTenantParams holds parameters for the MigrateTenantWorkflow.
 type MigrateTenantParams struct {
 	TenantID      string `json:"tenant_id"`
@@ -420,7 +495,7 @@ func (s *TenantService) ResourceSummary(ctx context.Context, tenantID string) (*
 
 func (s *TenantService) ListResourceUsage(ctx context.Context, tenantID string) ([]model.ResourceUsage, error) {
 	rows, err := s.db.Query(ctx,
-		`SELECT ru.id, ru.resource_type, ru.resource_id, ru.tenant_id, ru.bytes_used, ru.collected_at
+		`SELECT ru.id, ru.resource_type, ru.resource_id, ru.tenant_id, ru.bytes_used, ru.key_count, ru.available, ru.collected_at
 		 FROM resource_usage ru WHERE ru.tenant_id = $1 ORDER BY ru.resource_type, ru.collected_at DESC`, tenantID,
 	)
 	if err != nil {
@@ -431,7 +506,7 @@ func (s *TenantService) ListResourceUsage(ctx context.Context, tenantID string)
 	var usages []model.ResourceUsage
 	for rows.Next() {
 		var u model.ResourceUsage
-		if err := rows.Scan(&u.ID, &u.ResourceType, &u.ResourceID, &u.TenantID, &u.BytesUsed, &u.CollectedAt); err != nil {
+		if err := rows.Scan(&u.ID, &u.ResourceType, &u.ResourceID, &u.TenantID, &u.BytesUsed, &u.KeyCount, &u.Available, &u.CollectedAt); err != nil {
 			return nil, fmt.Errorf("scan resource usage: %w", err)
 		}
 		usages = append(usages, u)
@@ -442,13 +517,25 @@ func (s *TenantService) ListResourceUsage(ctx context.Context, tenantID string)
 	return usages, nil
 }
 
-func (s *TenantService) NextUID(ctx context.Context) (int, error) {
-	var uid int
-	err := s.db.QueryRow(ctx, "SELECT nextval('tenant_uid_seq')").Scan(&uid)
-	if err != nil {
-		return 0, fmt.Errorf("next tenant uid: %w", err)
-	}
-	return uid, nil
+// QuotaWarnings returns a quota_near_limit warning if the tenant's last
+// measured webroot usage is at or above model.QuotaWarningPercent of its disk
+// quota. Tenants without a quota, and lookups that fail, produce no warning.
+func (s *TenantService) QuotaWarnings(ctx context.Context, tenantID string) []model.Warning {
+	var quota, used int64
+	err := s.db.QueryRow(ctx,
+		`SELECT t.disk_quota_bytes, COALESCE(SUM(ru.bytes_used), 0)::BIGINT
+		 FROM tenants t
+		 LEFT JOIN resource_usage ru ON ru.tenant_id = t.id AND ru.resource_type = 'webroot'
+		 WHERE t.id = $1 GROUP BY t.disk_quota_bytes`, tenantID,
+	).Scan(&quota, &used)
+	if err != nil || quota <= 0 || used*100 < quota*model.QuotaWarningPercent {
+		return nil
+	}
+	return []model.Warning{{
+		Code: model.WarningQuotaNearLimit,
+		Message: fmt.Sprintf("tenant %s uses %d of %d bytes (%d%%) of its disk quota; writes fail once it is full",
+			tenantID, used, quota, used*100/quota),
+	}}
 }
 
 func (s *TenantService) Retry(ctx context.Context, id string) error {
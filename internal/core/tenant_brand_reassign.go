@@ -0,0 +1,138 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+	"go.temporal.io/api/serviceerror"
+)
+
+// ErrInvalidBrandReassignment is returned by ReassignBrand when the tenant
+// cannot move to the requested brand or reseller.
+var ErrInvalidBrandReassignment = errors.New("invalid brand reassignment")
+
+// ErrNoBrandReassignment is returned by BrandReassignmentStatus for a tenant
+// that has not been moved to another brand, or whose history has expired.
+var ErrNoBrandReassignment = errors.New("no brand reassignment found for tenant")
+
+// ReassignTenantBrandParams holds parameters for the
+// ReassignTenantBrandWorkflow. ResellerID is the tenant's reseller after the
+// move.
+type ReassignTenantBrandParams struct {
+	TenantID   string  `json:"tenant_id"`
+	BrandID    string  `json:"brand_id"`
+	ResellerID *string `json:"reseller_id,omitempty"`
+}
+
+// ReassignBrand moves the tenant to brandID by starting
+// ReassignTenantBrandWorkflow. The target brand must be active and serve the
+// tenant's cluster: tenants cannot change cluster, so a brand limited to
+// other clusters is rejected. resellerID, if set, must be a reseller of the
+// target brand. Otherwise the tenant keeps its reseller only if that
+// reseller belongs to the target brand. Reassigning a tenant to its current
+// brand re-applies the brand's configuration.
+func (s *TenantService) ReassignBrand(ctx context.Context, id, brandID string, resellerID *string) error {
+	var clusterID string
+	var currentReseller *string
+	err := s.db.QueryRow(ctx,
+		`SELECT cluster_id, reseller_id FROM tenants WHERE id = $1`, id,
+	).Scan(&clusterID, &currentReseller)
+	if err != nil {
+		return fmt.Errorf("get tenant %s: %w", id, err)
+	}
+
+	var brandStatus string
+	err = s.db.QueryRow(ctx, `SELECT status FROM brands WHERE id = $1`, brandID).Scan(&brandStatus)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%w: brand %s not found", ErrInvalidBrandReassignment, brandID)
+	}
+	if err != nil {
+		return fmt.Errorf("get brand %s: %w", brandID, err)
+	}
+	if brandStatus != model.StatusActive {
+		return fmt.Errorf("%w: brand %s is %s", ErrInvalidBrandReassignment, brandID, brandStatus)
+	}
+
+	rows, err := s.db.Query(ctx, `SELECT cluster_id FROM brand_clusters WHERE brand_id = $1`, brandID)
+	if err != nil {
+		return fmt.Errorf("list clusters of brand %s: %w", brandID, err)
+	}
+	var clusters []string
+	for rows.Next() {
+		var c string
+		if err := rows.Scan(&c); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan brand cluster: %w", err)
+		}
+		clusters = append(clusters, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("list clusters of brand %s: %w", brandID, err)
+	}
+	if len(clusters) > 0 && !slices.Contains(clusters, clusterID) {
+		return fmt.Errorf("%w: brand %s does not serve the tenant's cluster %s; tenants cannot move between clusters",
+			ErrInvalidBrandReassignment, brandID, clusterID)
+	}
+
+	newReseller := resellerID
+	if newReseller == nil {
+		newReseller = currentReseller
+	}
+	if newReseller != nil {
+		var resellerBrand string
+		err := s.db.QueryRow(ctx, `SELECT brand_id FROM resellers WHERE id = $1`, *newReseller).Scan(&resellerBrand)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%w: reseller %s not found", ErrInvalidBrandReassignment, *newReseller)
+		}
+		if err != nil {
+			return fmt.Errorf("get reseller %s: %w", *newReseller, err)
+		}
+		if resellerBrand != brandID {
+			if resellerID != nil {
+				return fmt.Errorf("%w: reseller %s belongs to brand %s", ErrInvalidBrandReassignment, *resellerID, resellerBrand)
+			}
+			// The current reseller stays with the source brand.
+			newReseller = nil
+		}
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, id, model.ProvisionTask{
+		WorkflowName: "ReassignTenantBrandWorkflow",
+		WorkflowID:   reassignTenantBrandWorkflowID(id),
+		Arg: ReassignTenantBrandParams{
+			TenantID:   id,
+			BrandID:    brandID,
+			ResellerID: newReseller,
+		},
+	}); err != nil {
+		return fmt.Errorf("signal ReassignTenantBrandWorkflow: %w", err)
+	}
+	return nil
+}
+
+// BrandReassignmentStatus returns the per-resource results of the tenant's
+// latest brand reassignment by querying its ReassignTenantBrandWorkflow.
+func (s *TenantService) BrandReassignmentStatus(ctx context.Context, id string) (*model.TenantBrandReassignment, error) {
+	val, err := s.tc.QueryWorkflow(ctx, reassignTenantBrandWorkflowID(id), "", "tenant-brand-reassignment")
+	if err != nil {
+		var notFound *serviceerror.NotFound
+		if errors.As(err, &notFound) {
+			return nil, ErrNoBrandReassignment
+		}
+		return nil, fmt.Errorf("query brand reassignment of tenant %s: %w", id, err)
+	}
+	var reassignment model.TenantBrandReassignment
+	if err := val.Get(&reassignment); err != nil {
+		return nil, fmt.Errorf("decode brand reassignment of tenant %s: %w", id, err)
+	}
+	return &reassignment, nil
+}
+
+func reassignTenantBrandWorkflowID(tenantID string) string {
+	return fmt.Sprintf("reassign-tenant-brand-%s", tenantID)
+}
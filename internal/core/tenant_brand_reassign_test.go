@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+// expectBrandReassignTarget mocks the tenant (cluster-1, reseller) and an
+// active target brand serving clusters.
+func expectBrandReassignTarget(db *mockDB, ctx context.Context, reseller *string, clusters ...string) {
+	db.On("QueryRow", ctx, queryContaining("FROM tenants"), []any{"tenant-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "cluster-1"
+		*(dest[1].(**string)) = reseller
+		return nil
+	}})
+	db.On("QueryRow", ctx, queryContaining("FROM brands"), []any{"brand-2"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = model.StatusActive
+		return nil
+	}})
+	var rows []func(dest ...any) error
+	for _, c := range clusters {
+		rows = append(rows, func(dest ...any) error {
+			*(dest[0].(*string)) = c
+			return nil
+		})
+	}
+	db.On("Query", ctx, queryContaining("FROM brand_clusters"), []any{"brand-2"}).Return(newMockRows(rows...), nil)
+}
+
+func resellerBrandRow(brandID string) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = brandID
+		return nil
+	}}
+}
+
+func TestTenantService_ReassignBrand_ClearsResellerOfSourceBrand(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+
+	reseller := "reseller-1"
+	expectBrandReassignTarget(db, ctx, &reseller, "cluster-1", "cluster-2")
+	db.On("QueryRow", ctx, queryContaining("FROM resellers"), []any{"reseller-1"}).Return(resellerBrandRow("brand-1"))
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id")
+	wfRun.On("GetRunID").Return("mock-run-id")
+	tc.On("SignalWithStartWorkflow", mock.Anything, "tenant-tenant-1", model.ProvisionSignalName,
+		mock.MatchedBy(func(task model.ProvisionTask) bool {
+			params, ok := task.Arg.(ReassignTenantBrandParams)
+			return task.WorkflowName == "ReassignTenantBrandWorkflow" &&
+				task.WorkflowID == "reassign-tenant-brand-tenant-1" &&
+				ok && params.BrandID == "brand-2" && params.ResellerID == nil
+		}), mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	err := svc.ReassignBrand(ctx, "tenant-1", "brand-2", nil)
+	require.NoError(t, err)
+	tc.AssertExpectations(t)
+}
+
+func TestTenantService_ReassignBrand_OtherCluster(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+
+	expectBrandReassignTarget(db, ctx, nil, "cluster-2")
+
+	err := svc.ReassignBrand(ctx, "tenant-1", "brand-2", nil)
+	assert.ErrorIs(t, err, ErrInvalidBrandReassignment)
+	assert.Contains(t, err.Error(), "cannot move between clusters")
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTenantService_ReassignBrand_ResellerOfOtherBrand(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+
+	expectBrandReassignTarget(db, ctx, nil)
+	db.On("QueryRow", ctx, queryContaining("FROM resellers"), []any{"reseller-3"}).Return(resellerBrandRow("brand-3"))
+
+	reseller := "reseller-3"
+	err := svc.ReassignBrand(ctx, "tenant-1", "brand-2", &reseller)
+	assert.ErrorIs(t, err, ErrInvalidBrandReassignment)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTenantService_BrandReassignmentStatus_NotFound(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+
+	tc.On("QueryWorkflow", ctx, "reassign-tenant-brand-tenant-1", "", "tenant-brand-reassignment").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+
+	_, err := svc.BrandReassignmentStatus(ctx, "tenant-1")
+	assert.ErrorIs(t, err, ErrNoBrandReassignment)
+}
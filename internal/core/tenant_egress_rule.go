@@ -2,23 +2,49 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/netip"
 
 	"github.com/edvin/hosting/internal/model"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
+// ErrInvalidEgressCIDR is returned when an egress rule's CIDR is malformed or
+// overlaps a blocked range.
+var ErrInvalidEgressCIDR = errors.New("invalid egress rule CIDR")
+
+// ErrEgressRuleOverlap is returned when an egress rule overlaps another rule
+// of the same tenant.
+var ErrEgressRuleOverlap = errors.New("egress rule overlaps an existing rule")
+
 type TenantEgressRuleService struct {
 	db DB
 	tc temporalclient.Client
+	// blocked are ranges tenants may not allow egress to, e.g. the
+	// platform's management network.
+	blocked []netip.Prefix
 }
 
-func NewTenantEgressRuleService(db DB, tc temporalclient.Client) *TenantEgressRuleService {
-	return &TenantEgressRuleService{db: db, tc: tc}
+func NewTenantEgressRuleService(db DB, tc temporalclient.Client, blocked []netip.Prefix) *TenantEgressRuleService {
+	return &TenantEgressRuleService{db: db, tc: tc, blocked: blocked}
 }
 
+// Create validates and normalizes rule.CIDR (host bits cleared), then stores
+// the rule and syncs the tenant's egress rules. It returns
+// ErrInvalidEgressCIDR if the CIDR is malformed or overlaps a blocked range,
+// and ErrEgressRuleOverlap if it overlaps one of the tenant's rules.
 func (s *TenantEgressRuleService) Create(ctx context.Context, rule *model.TenantEgressRule) error {
-	_, err := s.db.Exec(ctx,
+	prefix, err := s.parseCIDR(rule.CIDR)
+	if err != nil {
+		return err
+	}
+	rule.CIDR = prefix.String()
+	if err := s.checkOverlap(ctx, rule.TenantID, prefix); err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(ctx,
 		`INSERT INTO tenant_egress_rules (id, tenant_id, cidr, description, status, created_at, updated_at)
 		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
 		rule.ID, rule.TenantID, rule.CIDR, rule.Description,
@@ -133,3 +159,51 @@ func (s *TenantEgressRuleService) Retry(ctx context.Context, id string) error {
 		Arg:          tenantID,
 	})
 }
+
+// parseCIDR parses cidr with its host bits cleared and checks it against the
+// blocklist.
+func (s *TenantEgressRuleService) parseCIDR(cidr string) (netip.Prefix, error) {
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w: %q is not a CIDR", ErrInvalidEgressCIDR, cidr)
+	}
+	if prefix.Addr().Is4In6() {
+		return netip.Prefix{}, fmt.Errorf("%w: %q is an IPv4-mapped IPv6 range, use the IPv4 CIDR", ErrInvalidEgressCIDR, cidr)
+	}
+	prefix = prefix.Masked()
+	for _, b := range s.blocked {
+		if prefix.Overlaps(b) {
+			return netip.Prefix{}, fmt.Errorf("%w: %s overlaps blocked range %s", ErrInvalidEgressCIDR, prefix, b)
+		}
+	}
+	return prefix, nil
+}
+
+// checkOverlap returns ErrEgressRuleOverlap if prefix overlaps any of the
+// tenant's rules that are not being deleted.
+func (s *TenantEgressRuleService) checkOverlap(ctx context.Context, tenantID string, prefix netip.Prefix) error {
+	rows, err := s.db.Query(ctx,
+		`SELECT cidr FROM tenant_egress_rules WHERE tenant_id = $1 AND status != $2`,
+		tenantID, model.StatusDeleting,
+	)
+	if err != nil {
+		return fmt.Errorf("list egress rules for tenant %s: %w", tenantID, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cidr string
+		if err := rows.Scan(&cidr); err != nil {
+			return fmt.Errorf("scan egress rule cidr: %w", err)
+		}
+		// Rules created before validation may not parse; they cannot overlap.
+		existing, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			continue
+		}
+		if prefix.Overlaps(existing.Masked()) {
+			return fmt.Errorf("%w: %s overlaps %s", ErrEgressRuleOverlap, prefix, cidr)
+		}
+	}
+	return rows.Err()
+}
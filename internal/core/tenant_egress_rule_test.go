@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func cidrRow(cidr string) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = cidr
+		return nil
+	}
+}
+
+func TestTenantEgressRuleService_Create_Normalizes(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantEgressRuleService(db, tc, nil)
+	ctx := context.Background()
+
+	db.On("Query", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1", model.StatusDeleting}).
+		Return(newMockRows(cidrRow("93.184.216.0/24")), nil)
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.MatchedBy(func(args []any) bool {
+		return args[2] == "2001:db8::/32"
+	})).Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+	tc.On("SignalWithStartWorkflow", ctx, "tenant-test-tenant-1", model.ProvisionSignalName, mock.Anything, mock.Anything, mock.Anything).
+		Return(&temporalmocks.WorkflowRun{}, nil)
+
+	rule := &model.TenantEgressRule{ID: "rule-1", TenantID: "test-tenant-1", CIDR: "2001:db8::1/32"}
+	require.NoError(t, svc.Create(ctx, rule))
+	assert.Equal(t, "2001:db8::/32", rule.CIDR)
+	db.AssertExpectations(t)
+}
+
+func TestTenantEgressRuleService_Create_Invalid(t *testing.T) {
+	blocked := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("169.254.0.0/16")}
+	tests := []struct {
+		name string
+		cidr string
+	}{
+		{"malformed", "10.0.0.300/24"},
+		{"bare address", "93.184.216.34"},
+		{"inside blocked", "10.1.2.0/24"},
+		{"covers blocked", "0.0.0.0/0"},
+		{"metadata", "169.254.169.254/32"},
+		{"ipv4-mapped", "::ffff:10.0.0.0/104"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			svc := NewTenantEgressRuleService(db, &temporalmocks.Client{}, blocked)
+
+			err := svc.Create(context.Background(), &model.TenantEgressRule{TenantID: "test-tenant-1", CIDR: tt.cidr})
+			assert.ErrorIs(t, err, ErrInvalidEgressCIDR)
+			db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTenantEgressRuleService_Create_Overlap(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		cidr     string
+	}{
+		{"duplicate", "93.184.216.0/24", "93.184.216.0/24"},
+		{"duplicate after normalizing", "93.184.216.0/24", "93.184.216.34/24"},
+		{"inside existing", "93.184.0.0/16", "93.184.216.0/24"},
+		{"covers existing", "93.184.216.0/24", "93.184.0.0/16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			svc := NewTenantEgressRuleService(db, &temporalmocks.Client{}, nil)
+			ctx := context.Background()
+
+			db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).
+				Return(newMockRows(cidrRow(tt.existing)), nil)
+
+			err := svc.Create(ctx, &model.TenantEgressRule{TenantID: "test-tenant-1", CIDR: tt.cidr})
+			assert.ErrorIs(t, err, ErrEgressRuleOverlap)
+			db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// tenantEventRoute maps successful requests to an API route to an event of
+// the tenant's feed. Segments of the pattern are matched literally, except
+// "*", which matches any ID, and "{id}", which matches the ID of the
+// resource the event is about.
+type tenantEventRoute struct {
+	method    string
+	pattern   string
+	eventType string
+	summary   string
+}
+
+// tenantEventRoutes lists the requests shown in tenant event feeds. Anything
+// not listed is left out, notably retries, status resets, brand
+// reassignments, LB splits and secret or download requests. Moves of the
+// tenant's resources to other shards or nodes are shown as maintenance,
+// without detail.
+var tenantEventRoutes = []tenantEventRoute{
+	{http.MethodPost, "tenants", "tenant.created", "Account created"},
+	{http.MethodPut, "tenants/{id}", "tenant.updated", "Account settings updated"},
+	{http.MethodDelete, "tenants/{id}", "tenant.deleted", "Account deleted"},
+	{http.MethodPost, "tenants/{id}/suspend", "tenant.suspended", "Account suspended"},
+	{http.MethodPost, "tenants/{id}/unsuspend", "tenant.unsuspended", "Account reactivated"},
+	{http.MethodPost, "tenants/{id}/migrate", "tenant.maintenance", "Platform maintenance"},
+	{http.MethodPut, "tenants/{id}/maintenance-window", "tenant.updated", "Maintenance window updated"},
+	{http.MethodDelete, "tenants/{id}/maintenance-window", "tenant.updated", "Maintenance window removed"},
+	{http.MethodDelete, "tenants/{id}/sessions", "tenant.sessions_revoked", "All login sessions revoked"},
+	{http.MethodDelete, "tenants/*/sessions/*", "tenant.sessions_revoked", "Login session revoked"},
+	{http.MethodPost, "tenants/{id}/export", "tenant.export_requested", "Account export requested"},
+
+	{http.MethodPost, "tenants/*/subscriptions", "subscription.created", "Subscription added"},
+	{http.MethodDelete, "subscriptions/{id}", "subscription.deleted", "Subscription removed"},
+
+	{http.MethodPost, "tenants/*/webroots", "webroot.created", "Website created"},
+	{http.MethodPut, "webroots/{id}", "webroot.updated", "Website updated"},
+	{http.MethodDelete, "webroots/{id}", "webroot.deleted", "Website deleted"},
+	{http.MethodPost, "webroots/{id}/clone", "webroot.cloned", "Website cloned"},
+	{http.MethodPut, "webroots/{id}/basic-auth", "webroot.updated", "Website password protection updated"},
+	{http.MethodDelete, "webroots/{id}/basic-auth", "webroot.updated", "Website password protection removed"},
+	{http.MethodPut, "webroots/{id}/connection-limits", "webroot.updated", "Website connection limits updated"},
+	{http.MethodDelete, "webroots/{id}/connection-limits", "webroot.updated", "Website connection limits removed"},
+	{http.MethodPut, "webroots/{id}/static-rules", "webroot.updated", "Website static file rules updated"},
+	{http.MethodDelete, "webroots/{id}/static-rules", "webroot.updated", "Website static file rules removed"},
+	{http.MethodPut, "webroots/{id}/geo-blocking", "webroot.updated", "Website geo-blocking updated"},
+	{http.MethodDelete, "webroots/{id}/geo-blocking", "webroot.updated", "Website geo-blocking removed"},
+	{http.MethodPut, "webroots/{id}/env-vars", "webroot.env_vars_updated", "Website environment variables updated"},
+	{http.MethodDelete, "webroots/{id}/env-vars/*", "webroot.env_vars_updated", "Website environment variable removed"},
+	{http.MethodPost, "webroots/{id}/releases", "webroot.release_created", "Website release created"},
+	{http.MethodPost, "webroots/*/releases/{id}/promote", "webroot.release_promoted", "Website release promoted"},
+	{http.MethodPost, "webroots/{id}/rollback", "webroot.rolled_back", "Website rolled back"},
+
+	{http.MethodPost, "webroots/*/daemons", "daemon.created", "Daemon created"},
+	{http.MethodPut, "daemons/{id}", "daemon.updated", "Daemon updated"},
+	{http.MethodDelete, "daemons/{id}", "daemon.deleted", "Daemon deleted"},
+	{http.MethodPost, "daemons/{id}/enable", "daemon.enabled", "Daemon enabled"},
+	{http.MethodPost, "daemons/{id}/disable", "daemon.disabled", "Daemon disabled"},
+
+	{http.MethodPost, "webroots/*/cron-jobs", "cron_job.created", "Cron job created"},
+	{http.MethodPut, "cron-jobs/{id}", "cron_job.updated", "Cron job updated"},
+	{http.MethodDelete, "cron-jobs/{id}", "cron_job.deleted", "Cron job deleted"},
+	{http.MethodPost, "cron-jobs/{id}/enable", "cron_job.enabled", "Cron job enabled"},
+	{http.MethodPost, "cron-jobs/{id}/disable", "cron_job.disabled", "Cron job disabled"},
+	{http.MethodPost, "cron-jobs/{id}/pause", "cron_job.paused", "Cron job paused"},
+	{http.MethodPost, "cron-jobs/{id}/resume", "cron_job.resumed", "Cron job resumed"},
+	{http.MethodPut, "cron-jobs/{id}/env-vars", "cron_job.env_vars_updated", "Cron job environment variables updated"},
+	{http.MethodDelete, "cron-jobs/{id}/env-vars/*", "cron_job.env_vars_updated", "Cron job environment variable removed"},
+
+	{http.MethodPost, "tenants/*/fqdns", "fqdn.created", "Domain added"},
+	{http.MethodPut, "fqdns/{id}", "fqdn.updated", "Domain updated"},
+	{http.MethodDelete, "fqdns/{id}", "fqdn.deleted", "Domain removed"},
+
+	{http.MethodPost, "fqdns/*/certificates", "certificate.uploaded", "Certificate uploaded"},
+	{http.MethodPost, "fqdns/*/certificate", "certificate.uploaded", "Certificate uploaded"},
+	{http.MethodPost, "certificates/{id}/renew", "certificate.renewal_requested", "Certificate renewal requested"},
+	{http.MethodPost, "tenants/*/certificates/bulk", "certificate.bulk_issuance_started", "Certificate issuance started for several domains"},
+
+	{http.MethodPost, "zones", "zone.created", "DNS zone created"},
+	{http.MethodPut, "zones/{id}", "zone.updated", "DNS zone updated"},
+	{http.MethodDelete, "zones/{id}", "zone.deleted", "DNS zone deleted"},
+	{http.MethodPost, "zones/{id}/dnssec", "zone.dnssec_enabled", "DNSSEC enabled"},
+	{http.MethodDelete, "zones/{id}/dnssec", "zone.dnssec_disabled", "DNSSEC disabled"},
+	{http.MethodPost, "zones/*/records", "zone_record.created", "DNS record created"},
+	{http.MethodPut, "zones/{id}/records", "zone.records_replaced", "DNS records replaced"},
+	{http.MethodPut, "zone-records/{id}", "zone_record.updated", "DNS record updated"},
+	{http.MethodDelete, "zone-records/{id}", "zone_record.deleted", "DNS record deleted"},
+
+	{http.MethodPost, "tenants/*/databases", "database.created", "Database created"},
+	{http.MethodDelete, "databases/{id}", "database.deleted", "Database deleted"},
+	{http.MethodPost, "databases/{id}/migrate", "database.maintenance", "Database maintenance"},
+	{http.MethodPost, "databases/*/users", "database_user.created", "Database user created"},
+	{http.MethodPut, "database-users/{id}", "database_user.updated", "Database user updated"},
+	{http.MethodDelete, "database-users/{id}", "database_user.deleted", "Database user deleted"},
+
+	{http.MethodPost, "tenants/*/valkey-instances", "valkey_instance.created", "Valkey instance created"},
+	{http.MethodPut, "valkey-instances/{id}", "valkey_instance.updated", "Valkey instance updated"},
+	{http.MethodDelete, "valkey-instances/{id}", "valkey_instance.deleted", "Valkey instance deleted"},
+	{http.MethodPost, "valkey-instances/{id}/migrate", "valkey_instance.maintenance", "Valkey instance maintenance"},
+	{http.MethodPost, "valkey-instances/*/users", "valkey_user.created", "Valkey user created"},
+	{http.MethodPut, "valkey-users/{id}", "valkey_user.updated", "Valkey user updated"},
+	{http.MethodDelete, "valkey-users/{id}", "valkey_user.deleted", "Valkey user deleted"},
+
+	{http.MethodPost, "tenants/*/s3-buckets", "s3_bucket.created", "S3 bucket created"},
+	{http.MethodPut, "s3-buckets/{id}", "s3_bucket.updated", "S3 bucket updated"},
+	{http.MethodDelete, "s3-buckets/{id}", "s3_bucket.deleted", "S3 bucket deleted"},
+	{http.MethodPut, "s3-buckets/{id}/lifecycle", "s3_bucket.updated", "S3 bucket lifecycle rules updated"},
+	{http.MethodPost, "s3-buckets/*/access-keys", "s3_access_key.created", "S3 access key created"},
+	{http.MethodPost, "s3-access-keys/{id}/rotate", "s3_access_key.rotated", "S3 access key rotated"},
+	{http.MethodDelete, "s3-access-keys/{id}", "s3_access_key.deleted", "S3 access key deleted"},
+
+	{http.MethodPost, "fqdns/*/email-accounts", "email_account.created", "Email account created"},
+	{http.MethodPost, "fqdns/*/email-accounts/import", "email_account.imported", "Email accounts imported"},
+	{http.MethodDelete, "email-accounts/{id}", "email_account.deleted", "Email account deleted"},
+	{http.MethodPost, "email-accounts/*/aliases", "email_alias.created", "Email alias created"},
+	{http.MethodDelete, "email-aliases/{id}", "email_alias.deleted", "Email alias deleted"},
+	{http.MethodPost, "email-accounts/*/forwards", "email_forward.created", "Email forward created"},
+	{http.MethodDelete, "email-forwards/{id}", "email_forward.deleted", "Email forward deleted"},
+	{http.MethodPut, "email-accounts/{id}/autoreply", "email_account.autoreply_updated", "Email auto-reply updated"},
+	{http.MethodDelete, "email-accounts/{id}/autoreply", "email_account.autoreply_updated", "Email auto-reply removed"},
+
+	{http.MethodPost, "tenants/*/smtp-relay-users", "smtp_relay_user.created", "SMTP relay user created"},
+	{http.MethodPut, "smtp-relay-users/{id}", "smtp_relay_user.updated", "SMTP relay user updated"},
+	{http.MethodDelete, "smtp-relay-users/{id}", "smtp_relay_user.deleted", "SMTP relay user deleted"},
+
+	{http.MethodPost, "tenants/*/ssh-keys", "ssh_key.created", "SSH key added"},
+	{http.MethodDelete, "ssh-keys/{id}", "ssh_key.deleted", "SSH key removed"},
+
+	{http.MethodPost, "tenants/*/egress-rules", "egress_rule.created", "Outbound network rule added"},
+	{http.MethodDelete, "egress-rules/{id}", "egress_rule.deleted", "Outbound network rule removed"},
+
+	{http.MethodPost, "tenants/*/wireguard-peers", "wireguard_peer.created", "WireGuard peer added"},
+	{http.MethodDelete, "wireguard-peers/{id}", "wireguard_peer.deleted", "WireGuard peer removed"},
+
+	{http.MethodPost, "tenants/*/backups", "backup.created", "Backup started"},
+	{http.MethodDelete, "backups/{id}", "backup.deleted", "Backup deleted"},
+	{http.MethodPost, "backups/{id}/restore", "backup.restore_started", "Backup restore started"},
+	{http.MethodPost, "backups/{id}/restore-files", "backup.restore_started", "File restore from backup started"},
+	{http.MethodPut, "tenants/{id}/backup-schedule", "backup.schedule_updated", "Backup schedule updated"},
+}
+
+// tenantEventFor returns the feed event for an audited request, or false if
+// the request is not shown in tenant feeds.
+func tenantEventFor(method, path string) (model.TenantEvent, bool) {
+	rest, ok := strings.CutPrefix(path, "/api/v1/")
+	if !ok {
+		return model.TenantEvent{}, false
+	}
+	segments := strings.Split(strings.TrimSuffix(rest, "/"), "/")
+
+	for _, route := range tenantEventRoutes {
+		if route.method != method {
+			continue
+		}
+		pattern := strings.Split(route.pattern, "/")
+		if len(pattern) != len(segments) {
+			continue
+		}
+		var resourceID *string
+		match := true
+		for i, p := range pattern {
+			switch p {
+			case "*":
+			case "{id}":
+				resourceID = &segments[i]
+			default:
+				match = p == segments[i]
+			}
+			if !match {
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		resourceType, _, _ := strings.Cut(route.eventType, ".")
+		return model.TenantEvent{
+			Type:         route.eventType,
+			ResourceType: resourceType,
+			ResourceID:   resourceID,
+			Summary:      route.summary,
+		}, true
+	}
+	return model.TenantEvent{}, false
+}
+
+// tenantEventBatch is how many audit log entries are read at a time to fill
+// a page of events.
+const tenantEventBatch = 200
+
+// TenantEventService builds the event feeds of tenants from the audit log.
+type TenantEventService struct {
+	db DB
+}
+
+func NewTenantEventService(db DB) *TenantEventService {
+	return &TenantEventService{db: db}
+}
+
+// List returns a page of the tenant's events, newest first. eventType, if
+// set, matches either an event type (webroot.created) or a resource type
+// (webroot). cursor is the ID of the last event of the previous page.
+// Only successful requests that acted on the tenant and are listed in
+// tenantEventRoutes are included.
+func (s *TenantEventService) List(ctx context.Context, tenantID, eventType string, limit int, cursor string) ([]model.TenantEvent, bool, error) {
+	var events []model.TenantEvent
+	for len(events) <= limit {
+		query := `SELECT id, method, path, created_at FROM audit_logs
+		          WHERE tenant_id = $1 AND status_code < 400 AND method IN ('POST', 'PUT', 'DELETE')`
+		args := []any{tenantID}
+		if cursor != "" {
+			query += ` AND (created_at, id) < (SELECT created_at, id FROM audit_logs WHERE id = $2)`
+			args = append(args, cursor)
+		}
+		query += fmt.Sprintf(` ORDER BY created_at DESC, id DESC LIMIT %d`, tenantEventBatch)
+
+		n, err := s.scanBatch(ctx, query, args, eventType, &events, &cursor)
+		if err != nil {
+			return nil, false, fmt.Errorf("list events for tenant %s: %w", tenantID, err)
+		}
+		if n < tenantEventBatch {
+			break
+		}
+	}
+
+	hasMore := len(events) > limit
+	if hasMore {
+		events = events[:limit]
+	}
+	return events, hasMore, nil
+}
+
+// scanBatch appends the events of one batch of audit log entries and moves
+// the cursor to its last entry. It returns the number of entries read.
+func (s *TenantEventService) scanBatch(ctx context.Context, query string, args []any, eventType string, events *[]model.TenantEvent, cursor *string) (int, error) {
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var id, method, path string
+		var createdAt time.Time
+		if err := rows.Scan(&id, &method, &path, &createdAt); err != nil {
+			return 0, fmt.Errorf("scan audit log: %w", err)
+		}
+		n++
+		*cursor = id
+
+		ev, ok := tenantEventFor(method, path)
+		if !ok || (eventType != "" && eventType != ev.Type && eventType != ev.ResourceType) {
+			continue
+		}
+		ev.ID = id
+		ev.CreatedAt = createdAt
+		*events = append(*events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("iterate audit logs: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantEventFor(t *testing.T) {
+	tests := []struct {
+		method, path string
+		wantType     string
+		wantID       string
+	}{
+		{http.MethodPost, "/api/v1/tenants", "tenant.created", ""},
+		{http.MethodPost, "/api/v1/tenants/t-1/suspend", "tenant.suspended", "t-1"},
+		{http.MethodPost, "/api/v1/tenants/t-1/webroots", "webroot.created", ""},
+		{http.MethodDelete, "/api/v1/webroots/w-1", "webroot.deleted", "w-1"},
+		{http.MethodPost, "/api/v1/webroots/w-1/releases/r-1/promote", "webroot.release_promoted", "r-1"},
+		{http.MethodPost, "/api/v1/certificates/c-1/renew", "certificate.renewal_requested", "c-1"},
+		{http.MethodPost, "/api/v1/tenants/t-1/migrate", "tenant.maintenance", "t-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			ev, ok := tenantEventFor(tt.method, tt.path)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantType, ev.Type)
+			assert.NotEmpty(t, ev.Summary)
+			if tt.wantID == "" {
+				assert.Nil(t, ev.ResourceID)
+			} else {
+				require.NotNil(t, ev.ResourceID)
+				assert.Equal(t, tt.wantID, *ev.ResourceID)
+			}
+		})
+	}
+}
+
+func TestTenantEventFor_Excluded(t *testing.T) {
+	for _, req := range [][2]string{
+		{http.MethodPost, "/api/v1/tenants/t-1/reset-status"},
+		{http.MethodPost, "/api/v1/tenants/t-1/reassign-brand"},
+		{http.MethodPost, "/api/v1/tenants/t-1/fix-permissions"},
+		{http.MethodPost, "/api/v1/webroots/w-1/retry"},
+		{http.MethodPost, "/api/v1/backups/b-1/download-url"},
+		{http.MethodGet, "/api/v1/fqdns/f-1/certificate"},
+		{http.MethodPost, "/internal/v1/cron-jobs/c-1/outcome"},
+		{http.MethodPost, "/api/v1/tenants/t-1/webroots/extra"},
+	} {
+		_, ok := tenantEventFor(req[0], req[1])
+		assert.False(t, ok, "%s %s", req[0], req[1])
+	}
+}
+
+func auditLogRow(id, method, path string, createdAt time.Time) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = id
+		*(dest[1].(*string)) = method
+		*(dest[2].(*string)) = path
+		*(dest[3].(*time.Time)) = createdAt
+		return nil
+	}
+}
+
+func TestTenantEventService_List(t *testing.T) {
+	db := &mockDB{}
+	svc := NewTenantEventService(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	db.On("Query", ctx, sqlContains("FROM audit_logs"), []any{"t-1"}).Return(newMockRows(
+		auditLogRow("a-4", http.MethodPost, "/api/v1/webroots/w-1/retry", now),
+		auditLogRow("a-3", http.MethodDelete, "/api/v1/webroots/w-1", now.Add(-time.Minute)),
+		auditLogRow("a-2", http.MethodPost, "/api/v1/tenants/t-1/fqdns", now.Add(-2*time.Minute)),
+		auditLogRow("a-1", http.MethodPost, "/api/v1/tenants/t-1/webroots", now.Add(-3*time.Minute)),
+	), nil)
+
+	events, hasMore, err := svc.List(ctx, "t-1", "", 2, "")
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	require.Len(t, events, 2)
+	assert.Equal(t, "a-3", events[0].ID)
+	assert.Equal(t, "webroot.deleted", events[0].Type)
+	assert.Equal(t, "webroot", events[0].ResourceType)
+	assert.Equal(t, "a-2", events[1].ID)
+	assert.Equal(t, "fqdn.created", events[1].Type)
+}
+
+func TestTenantEventService_List_TypeFilterAndCursor(t *testing.T) {
+	db := &mockDB{}
+	svc := NewTenantEventService(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	db.On("Query", ctx, sqlContains("(created_at, id) <"), []any{"t-1", "a-9"}).Return(newMockRows(
+		auditLogRow("a-3", http.MethodDelete, "/api/v1/webroots/w-1", now),
+		auditLogRow("a-2", http.MethodPost, "/api/v1/tenants/t-1/fqdns", now.Add(-time.Minute)),
+		auditLogRow("a-1", http.MethodPost, "/api/v1/tenants/t-1/webroots", now.Add(-2*time.Minute)),
+	), nil)
+
+	events, hasMore, err := svc.List(ctx, "t-1", "webroot", 50, "a-9")
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	require.Len(t, events, 2)
+	assert.Equal(t, "webroot.deleted", events[0].Type)
+	assert.Equal(t, "webroot.created", events[1].Type)
+	db.AssertExpectations(t)
+}
@@ -0,0 +1,118 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	temporalclient "go.temporal.io/sdk/client"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/objectstore"
+	"github.com/edvin/hosting/internal/platform"
+)
+
+// tenantExportURLTTL is how long a signed export download URL stays valid.
+const tenantExportURLTTL = time.Hour
+
+// ErrTenantExportInProgress is returned by Create when the tenant already
+// has a pending or provisioning export.
+var ErrTenantExportInProgress = errors.New("an export is already in progress")
+
+type TenantExportService struct {
+	db            DB
+	tc            temporalclient.Client
+	bucket        *objectstore.Bucket
+	retentionDays int
+}
+
+// NewTenantExportService creates a TenantExportService. Exports are disabled
+// when bucket is nil.
+func NewTenantExportService(db DB, tc temporalclient.Client, bucket *objectstore.Bucket, retentionDays int) *TenantExportService {
+	return &TenantExportService{db: db, tc: tc, bucket: bucket, retentionDays: retentionDays}
+}
+
+// Enabled reports whether export storage is configured.
+func (s *TenantExportService) Enabled() bool {
+	return s.bucket != nil
+}
+
+// Create records a new export and starts ExportTenantWorkflow. The workflow
+// is started directly rather than through the tenant's provision queue so a
+// long-running export doesn't hold up other changes to the tenant. Only one
+// export runs per tenant at a time, enforced by a partial unique index.
+func (s *TenantExportService) Create(ctx context.Context, tenantID string) (*model.TenantExport, error) {
+	if !s.Enabled() {
+		return nil, fmt.Errorf("tenant exports are not configured")
+	}
+
+	now := time.Now()
+	export := &model.TenantExport{
+		ID:        platform.NewID(),
+		TenantID:  tenantID,
+		Status:    model.StatusPending,
+		ExpiresAt: now.AddDate(0, 0, s.retentionDays),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	tag, err := s.db.Exec(ctx,
+		`INSERT INTO tenant_exports (id, tenant_id, status, expires_at, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 ON CONFLICT (tenant_id) WHERE status IN ('pending', 'provisioning') DO NOTHING`,
+		export.ID, export.TenantID, export.Status, export.ExpiresAt, export.CreatedAt, export.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("insert tenant export: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrTenantExportInProgress
+	}
+
+	err = startWorkflow(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "ExportTenantWorkflow",
+		WorkflowID:   workflowID("tenant-export", export.ID),
+		Arg:          export.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("start ExportTenantWorkflow: %w", err)
+	}
+	return export, nil
+}
+
+// GetLatest returns the tenant's most recent export.
+func (s *TenantExportService) GetLatest(ctx context.Context, tenantID string) (*model.TenantExport, error) {
+	var e model.TenantExport
+	err := s.db.QueryRow(ctx,
+		`SELECT id, tenant_id, object_key, size_bytes, status, status_message, expires_at, completed_at, created_at, updated_at
+		 FROM tenant_exports WHERE tenant_id = $1
+		 ORDER BY created_at DESC LIMIT 1`, tenantID,
+	).Scan(&e.ID, &e.TenantID, &e.ObjectKey, &e.SizeBytes, &e.Status, &e.StatusMessage,
+		&e.ExpiresAt, &e.CompletedAt, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("get latest export for tenant %s: %w", tenantID, err)
+	}
+	return &e, nil
+}
+
+// SignDownload sets the export's DownloadURL to a signed URL valid for
+// tenantExportURLTTL, or the remaining retention if shorter. Exports that are
+// not yet uploaded are left without a URL.
+func (s *TenantExportService) SignDownload(ctx context.Context, export *model.TenantExport) error {
+	if !s.Enabled() || export.Status != model.StatusActive || export.ObjectKey == "" {
+		return nil
+	}
+	ttl := time.Until(export.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+	if ttl > tenantExportURLTTL {
+		ttl = tenantExportURLTTL
+	}
+	url, err := s.bucket.PresignGet(ctx, export.ObjectKey, ttl)
+	if err != nil {
+		return fmt.Errorf("sign export %s: %w", export.ID, err)
+	}
+	export.DownloadURL = url
+	return nil
+}
@@ -0,0 +1,125 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalclient "go.temporal.io/sdk/client"
+	temporalmocks "go.temporal.io/sdk/mocks"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/objectstore"
+)
+
+func testExportBucket() *objectstore.Bucket {
+	return objectstore.New("http://rgw.internal:7480", "", "exports", "AKID", "SECRET")
+}
+
+func TestTenantExportService_Create_NotConfigured(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantExportService(db, tc, nil, 7)
+
+	assert.False(t, svc.Enabled())
+	_, err := svc.Create(context.Background(), "test-tenant-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not configured")
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTenantExportService_Create_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantExportService(db, tc, testExportBucket(), 7)
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("ExecuteWorkflow", ctx, mock.MatchedBy(func(opts temporalclient.StartWorkflowOptions) bool {
+		return opts.TaskQueue == taskQueue
+	}), "ExportTenantWorkflow", mock.AnythingOfType("string")).Return(wfRun, nil)
+
+	export, err := svc.Create(ctx, "test-tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, "test-tenant-1", export.TenantID)
+	assert.Equal(t, model.StatusPending, export.Status)
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 7), export.ExpiresAt, time.Minute)
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestTenantExportService_Create_WorkflowError(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantExportService(db, tc, testExportBucket(), 7)
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+	tc.On("ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("temporal down"))
+
+	_, err := svc.Create(ctx, "test-tenant-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "start ExportTenantWorkflow")
+}
+
+func TestTenantExportService_Create_InProgress(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantExportService(db, tc, testExportBucket(), 7)
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.NewCommandTag("INSERT 0 0"), nil)
+
+	_, err := svc.Create(ctx, "test-tenant-1")
+	assert.ErrorIs(t, err, ErrTenantExportInProgress)
+	tc.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTenantExportService_GetLatest_NotFound(t *testing.T) {
+	db := &mockDB{}
+	svc := NewTenantExportService(db, nil, nil, 7)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		return pgx.ErrNoRows
+	}})
+
+	_, err := svc.GetLatest(ctx, "test-tenant-1")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, pgx.ErrNoRows)
+}
+
+func TestTenantExportService_SignDownload(t *testing.T) {
+	svc := NewTenantExportService(&mockDB{}, nil, testExportBucket(), 7)
+	ctx := context.Background()
+
+	active := &model.TenantExport{
+		ID:        "export-1",
+		Status:    model.StatusActive,
+		ObjectKey: "tenant-exports/t1/export-1.tar.gz",
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+	require.NoError(t, svc.SignDownload(ctx, active))
+	assert.Contains(t, active.DownloadURL, "/exports/tenant-exports/t1/export-1.tar.gz")
+	assert.Contains(t, active.DownloadURL, "X-Amz-Expires=3600")
+
+	pending := &model.TenantExport{ID: "export-2", Status: model.StatusProvisioning, ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, svc.SignDownload(ctx, pending))
+	assert.Empty(t, pending.DownloadURL)
+
+	expired := &model.TenantExport{
+		ID:        "export-3",
+		Status:    model.StatusActive,
+		ObjectKey: "tenant-exports/t1/export-3.tar.gz",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	}
+	require.NoError(t, svc.SignDownload(ctx, expired))
+	assert.Empty(t, expired.DownloadURL)
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNoLBSplit is returned when a tenant has no traffic split.
+var ErrNoLBSplit = errors.New("tenant has no traffic split")
+
+// ErrInvalidLBSplitShard is returned by SetLBSplit when the shard cannot take
+// a share of the tenant's traffic.
+var ErrInvalidLBSplitShard = errors.New("split shard must be another web shard in the tenant's cluster")
+
+// GetLBSplit returns the tenant's traffic split, or ErrNoLBSplit if all of its
+// traffic goes to its own shard.
+func (s *TenantService) GetLBSplit(ctx context.Context, tenantID string) (*model.TenantLBSplit, error) {
+	var split model.TenantLBSplit
+	err := s.db.QueryRow(ctx,
+		`SELECT tenant_id, shard_id, weight, created_at, updated_at FROM tenant_lb_splits WHERE tenant_id = $1`, tenantID,
+	).Scan(&split.TenantID, &split.ShardID, &split.Weight, &split.CreatedAt, &split.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoLBSplit
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get lb split for tenant %s: %w", tenantID, err)
+	}
+	return &split, nil
+}
+
+// SetLBSplit sends weight percent of the tenant's web traffic to shardID's LB
+// backend and starts UpdateTenantLBSplitWorkflow to update the LB nodes. The
+// shard must be a web shard in the tenant's cluster other than its own.
+func (s *TenantService) SetLBSplit(ctx context.Context, tenantID, shardID string, weight int) error {
+	var tenantClusterID, shardClusterID, role string
+	var tenantShardID *string
+	err := s.db.QueryRow(ctx,
+		`SELECT t.cluster_id, t.shard_id, s.cluster_id, s.role
+		 FROM tenants t, shards s
+		 WHERE t.id = $1 AND s.id = $2`, tenantID, shardID,
+	).Scan(&tenantClusterID, &tenantShardID, &shardClusterID, &role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrInvalidLBSplitShard
+	}
+	if err != nil {
+		return fmt.Errorf("get shard %s for lb split: %w", shardID, err)
+	}
+	if role != model.ShardRoleWeb || shardClusterID != tenantClusterID || tenantShardID == nil || *tenantShardID == shardID {
+		return ErrInvalidLBSplitShard
+	}
+
+	_, err = s.db.Exec(ctx,
+		`INSERT INTO tenant_lb_splits (tenant_id, shard_id, weight)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (tenant_id) DO UPDATE SET shard_id = EXCLUDED.shard_id, weight = EXCLUDED.weight, updated_at = now()`,
+		tenantID, shardID, weight,
+	)
+	if err != nil {
+		return fmt.Errorf("set lb split for tenant %s: %w", tenantID, err)
+	}
+
+	return s.signalLBSplit(ctx, tenantID)
+}
+
+// DeleteLBSplit sends all of the tenant's web traffic back to its own shard.
+// It returns ErrNoLBSplit if the tenant has no split.
+func (s *TenantService) DeleteLBSplit(ctx context.Context, tenantID string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM tenant_lb_splits WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete lb split for tenant %s: %w", tenantID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoLBSplit
+	}
+
+	return s.signalLBSplit(ctx, tenantID)
+}
+
+func (s *TenantService) signalLBSplit(ctx context.Context, tenantID string) error {
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "UpdateTenantLBSplitWorkflow",
+		WorkflowID:   workflowID("tenant-lb-split", tenantID),
+		Arg:          tenantID,
+	}); err != nil {
+		return fmt.Errorf("signal UpdateTenantLBSplitWorkflow: %w", err)
+	}
+	return nil
+}
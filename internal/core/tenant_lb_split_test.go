@@ -0,0 +1,99 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func splitShardRow(tenantCluster, tenantShard, shardCluster, role string) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = tenantCluster
+		*(dest[1].(**string)) = &tenantShard
+		*(dest[2].(*string)) = shardCluster
+		*(dest[3].(*string)) = role
+		return nil
+	}}
+}
+
+func TestTenantService_GetLBSplit_None(t *testing.T) {
+	db := &mockDB{}
+	svc := NewTenantService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).
+		Return(&mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }})
+
+	_, err := svc.GetLBSplit(ctx, "test-tenant-1")
+	assert.ErrorIs(t, err, ErrNoLBSplit)
+}
+
+func TestTenantService_SetLBSplit_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1", "web-2"}).
+		Return(splitShardRow("cluster-1", "web-1", "cluster-1", model.ShardRoleWeb))
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1", "web-2", 25}).
+		Return(pgconn.NewCommandTag("INSERT 0 1"), nil)
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("SignalWithStartWorkflow", mock.Anything, "tenant-test-tenant-1", model.ProvisionSignalName,
+		mock.MatchedBy(func(task model.ProvisionTask) bool {
+			return task.WorkflowName == "UpdateTenantLBSplitWorkflow" && task.WorkflowID == "tenant-lb-split-test-tenant-1"
+		}), mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	require.NoError(t, svc.SetLBSplit(ctx, "test-tenant-1", "web-2", 25))
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestTenantService_SetLBSplit_InvalidShard(t *testing.T) {
+	tests := []struct {
+		name string
+		row  *mockRow
+	}{
+		{"own shard", splitShardRow("cluster-1", "web-2", "cluster-1", model.ShardRoleWeb)},
+		{"other cluster", splitShardRow("cluster-1", "web-1", "cluster-2", model.ShardRoleWeb)},
+		{"not a web shard", splitShardRow("cluster-1", "web-1", "cluster-1", model.ShardRoleDatabase)},
+		{"unknown shard", &mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			tc := &temporalmocks.Client{}
+			svc := NewTenantService(db, tc)
+			ctx := context.Background()
+
+			db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(tt.row)
+
+			err := svc.SetLBSplit(ctx, "test-tenant-1", "web-2", 25)
+			assert.ErrorIs(t, err, ErrInvalidLBSplitShard)
+			db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+			tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestTenantService_DeleteLBSplit_None(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1"}).
+		Return(pgconn.NewCommandTag("DELETE 0"), nil)
+
+	err := svc.DeleteLBSplit(ctx, "test-tenant-1")
+	assert.ErrorIs(t, err, ErrNoLBSplit)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
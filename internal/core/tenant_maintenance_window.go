@@ -0,0 +1,61 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNoMaintenanceWindow is returned when a tenant has no maintenance window.
+var ErrNoMaintenanceWindow = errors.New("tenant has no maintenance window")
+
+// GetMaintenanceWindow returns the tenant's maintenance window, or
+// ErrNoMaintenanceWindow if automatic operations follow the platform default
+// schedule.
+func (s *TenantService) GetMaintenanceWindow(ctx context.Context, tenantID string) (*model.TenantMaintenanceWindow, error) {
+	var mw model.TenantMaintenanceWindow
+	err := s.db.QueryRow(ctx,
+		`SELECT tenant_id, start_time, duration_minutes, timezone, created_at, updated_at
+		 FROM tenant_maintenance_windows WHERE tenant_id = $1`, tenantID,
+	).Scan(&mw.TenantID, &mw.StartTime, &mw.DurationMinutes, &mw.Timezone, &mw.CreatedAt, &mw.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoMaintenanceWindow
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get maintenance window for tenant %s: %w", tenantID, err)
+	}
+	return &mw, nil
+}
+
+// SetMaintenanceWindow creates or replaces the tenant's maintenance window.
+// It takes effect from the next run of the affected cron workflows.
+func (s *TenantService) SetMaintenanceWindow(ctx context.Context, mw *model.TenantMaintenanceWindow) error {
+	err := s.db.QueryRow(ctx,
+		`INSERT INTO tenant_maintenance_windows (tenant_id, start_time, duration_minutes, timezone)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (tenant_id) DO UPDATE SET start_time = EXCLUDED.start_time,
+		   duration_minutes = EXCLUDED.duration_minutes, timezone = EXCLUDED.timezone, updated_at = now()
+		 RETURNING created_at, updated_at`,
+		mw.TenantID, mw.StartTime, mw.DurationMinutes, mw.Timezone,
+	).Scan(&mw.CreatedAt, &mw.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("set maintenance window for tenant %s: %w", mw.TenantID, err)
+	}
+	return nil
+}
+
+// DeleteMaintenanceWindow returns the tenant to the platform default schedule.
+// It returns ErrNoMaintenanceWindow if the tenant has no window.
+func (s *TenantService) DeleteMaintenanceWindow(ctx context.Context, tenantID string) error {
+	tag, err := s.db.Exec(ctx, `DELETE FROM tenant_maintenance_windows WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete maintenance window for tenant %s: %w", tenantID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoMaintenanceWindow
+	}
+	return nil
+}
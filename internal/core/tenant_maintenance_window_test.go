@@ -0,0 +1,60 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func TestTenantService_GetMaintenanceWindow_None(t *testing.T) {
+	db := &mockDB{}
+	svc := NewTenantService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }})
+
+	_, err := svc.GetMaintenanceWindow(ctx, "test-tenant-1")
+	assert.ErrorIs(t, err, ErrNoMaintenanceWindow)
+}
+
+func TestTenantService_SetMaintenanceWindow_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+	now := time.Now()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1", "03:00", 120, "Europe/Oslo"}).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*time.Time)) = now
+			*(dest[1].(*time.Time)) = now
+			return nil
+		}})
+
+	mw := &model.TenantMaintenanceWindow{TenantID: "test-tenant-1", StartTime: "03:00", DurationMinutes: 120, Timezone: "Europe/Oslo"}
+	require.NoError(t, svc.SetMaintenanceWindow(ctx, mw))
+	assert.Equal(t, now, mw.UpdatedAt)
+	db.AssertExpectations(t)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestTenantService_DeleteMaintenanceWindow_None(t *testing.T) {
+	db := &mockDB{}
+	svc := NewTenantService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1"}).
+		Return(pgconn.NewCommandTag("DELETE 0"), nil)
+
+	err := svc.DeleteMaintenanceWindow(ctx, "test-tenant-1")
+	assert.ErrorIs(t, err, ErrNoMaintenanceWindow)
+}
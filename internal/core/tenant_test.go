@@ -8,10 +8,12 @@ import (
 
 	"github.com/edvin/hosting/internal/api/request"
 	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/secrets"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.temporal.io/api/serviceerror"
 	temporalmocks "go.temporal.io/sdk/mocks"
 )
 
@@ -25,46 +27,6 @@ func TestNewTenantService(t *testing.T) {
 	assert.Equal(t, tc, svc.tc)
 }
 
-// ---------- NextUID ----------
-
-func TestTenantService_NextUID_Success(t *testing.T) {
-	db := &mockDB{}
-	tc := &temporalmocks.Client{}
-	svc := NewTenantService(db, tc)
-	ctx := context.Background()
-
-	row := &mockRow{scanFunc: func(dest ...any) error {
-		*(dest[0].(*int)) = 5001
-		return nil
-	}}
-
-	db.On("QueryRow", ctx, "SELECT nextval('tenant_uid_seq')", []any(nil)).Return(row)
-
-	uid, err := svc.NextUID(ctx)
-	require.NoError(t, err)
-	assert.Equal(t, 5001, uid)
-	db.AssertExpectations(t)
-}
-
-func TestTenantService_NextUID_DBError(t *testing.T) {
-	db := &mockDB{}
-	tc := &temporalmocks.Client{}
-	svc := NewTenantService(db, tc)
-	ctx := context.Background()
-
-	row := &mockRow{scanFunc: func(dest ...any) error {
-		return errors.New("connection refused")
-	}}
-
-	db.On("QueryRow", ctx, "SELECT nextval('tenant_uid_seq')", []any(nil)).Return(row)
-
-	uid, err := svc.NextUID(ctx)
-	require.Error(t, err)
-	assert.Equal(t, 0, uid)
-	assert.Contains(t, err.Error(), "next tenant uid")
-	db.AssertExpectations(t)
-}
-
 // ---------- Create ----------
 
 func TestTenantService_Create_Success(t *testing.T) {
@@ -84,13 +46,6 @@ func TestTenantService_Create_Success(t *testing.T) {
 		UpdatedAt:   time.Now(),
 	}
 
-	// NextUID query
-	row := &mockRow{scanFunc: func(dest ...any) error {
-		*(dest[0].(*int)) = 5001
-		return nil
-	}}
-	db.On("QueryRow", ctx, "SELECT nextval('tenant_uid_seq')", []any(nil)).Return(row)
-
 	// INSERT exec
 	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
 
@@ -104,30 +59,11 @@ func TestTenantService_Create_Success(t *testing.T) {
 
 	err := svc.Create(ctx, tenant)
 	require.NoError(t, err)
-	assert.Equal(t, 5001, tenant.UID)
+	assert.Equal(t, 0, tenant.UID, "UID is allocated by CreateTenantWorkflow")
 	db.AssertExpectations(t)
 	tc.AssertExpectations(t)
 }
 
-func TestTenantService_Create_NextUIDError(t *testing.T) {
-	db := &mockDB{}
-	tc := &temporalmocks.Client{}
-	svc := NewTenantService(db, tc)
-	ctx := context.Background()
-
-	tenant := &model.Tenant{ID: "test-tenant-1", BrandID: "test-brand"}
-
-	row := &mockRow{scanFunc: func(dest ...any) error {
-		return errors.New("sequence error")
-	}}
-	db.On("QueryRow", ctx, "SELECT nextval('tenant_uid_seq')", []any(nil)).Return(row)
-
-	err := svc.Create(ctx, tenant)
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "create tenant")
-	db.AssertExpectations(t)
-}
-
 func TestTenantService_Create_InsertError(t *testing.T) {
 	db := &mockDB{}
 	tc := &temporalmocks.Client{}
@@ -144,11 +80,6 @@ func TestTenantService_Create_InsertError(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	row := &mockRow{scanFunc: func(dest ...any) error {
-		*(dest[0].(*int)) = 5001
-		return nil
-	}}
-	db.On("QueryRow", ctx, "SELECT nextval('tenant_uid_seq')", []any(nil)).Return(row)
 	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, errors.New("unique violation"))
 
 	err := svc.Create(ctx, tenant)
@@ -173,11 +104,6 @@ func TestTenantService_Create_WorkflowError(t *testing.T) {
 		UpdatedAt: time.Now(),
 	}
 
-	row := &mockRow{scanFunc: func(dest ...any) error {
-		*(dest[0].(*int)) = 5001
-		return nil
-	}}
-	db.On("QueryRow", ctx, "SELECT nextval('tenant_uid_seq')", []any(nil)).Return(row)
 	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
 
 
@@ -271,6 +197,93 @@ func TestTenantService_GetByID_NotFound(t *testing.T) {
 	db.AssertExpectations(t)
 }
 
+// ---------- PasswordPolicy ----------
+
+func TestTenantService_PasswordPolicy(t *testing.T) {
+	db := &mockDB{}
+	svc := NewTenantService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*int)) = 12
+		*(dest[1].(*[]string)) = []string{"upper", "digit"}
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1"}).Return(row)
+
+	policy, err := svc.PasswordPolicy(ctx, "test-tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, secrets.Policy{MinLength: 12, Classes: []secrets.Class{secrets.Upper, secrets.Digit}}, policy)
+	db.AssertExpectations(t)
+}
+
+func TestTenantService_PasswordPolicy_UnknownClass(t *testing.T) {
+	db := &mockDB{}
+	svc := NewTenantService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*int)) = 8
+		*(dest[1].(*[]string)) = []string{"emoji"}
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+	_, err := svc.PasswordPolicy(ctx, "test-tenant-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "password policy for tenant test-tenant-1")
+}
+
+// ---------- QuotaWarnings ----------
+
+func TestTenantService_QuotaWarnings(t *testing.T) {
+	tests := []struct {
+		name        string
+		quota, used int64
+		want        bool
+	}{
+		{"below threshold", 1000, 899, false},
+		{"at threshold", 1000, 900, true},
+		{"over quota", 1000, 1200, true},
+		{"no quota", 0, 5000, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			svc := NewTenantService(db, &temporalmocks.Client{})
+			ctx := context.Background()
+
+			row := &mockRow{scanFunc: func(dest ...any) error {
+				*(dest[0].(*int64)) = tt.quota
+				*(dest[1].(*int64)) = tt.used
+				return nil
+			}}
+			db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-tenant-1"}).Return(row)
+
+			warnings := svc.QuotaWarnings(ctx, "test-tenant-1")
+			if !tt.want {
+				assert.Empty(t, warnings)
+				return
+			}
+			require.Len(t, warnings, 1)
+			assert.Equal(t, model.WarningQuotaNearLimit, warnings[0].Code)
+		})
+	}
+}
+
+func TestTenantService_QuotaWarnings_LookupFails(t *testing.T) {
+	db := &mockDB{}
+	svc := NewTenantService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		return errors.New("no rows in result set")
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+	assert.Empty(t, svc.QuotaWarnings(ctx, "test-tenant-1"))
+}
+
 // ---------- List ----------
 
 func TestTenantService_List_Success(t *testing.T) {
@@ -764,3 +777,94 @@ func TestTenantService_Unsuspend_WorkflowError(t *testing.T) {
 	db.AssertExpectations(t)
 	tc.AssertExpectations(t)
 }
+
+// ---------- Migrate ----------
+
+func TestTenantService_Migrate_AlreadyMigrating(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), []any{model.StatusMigrating, "test-tenant-1"}).
+		Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+
+	err := svc.Migrate(ctx, "test-tenant-1", "target-shard-1", false, true)
+	assert.ErrorIs(t, err, ErrTenantMigrating)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ---------- MigrationStatus ----------
+
+func TestTenantService_MigrationStatus_NotFound(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+
+	tc.On("QueryWorkflow", ctx, "migrate-tenant-test-tenant-1", "", "tenant-migration").
+		Return(nil, serviceerror.NewNotFound("workflow not found"))
+
+	_, err := svc.MigrationStatus(ctx, "test-tenant-1")
+	assert.ErrorIs(t, err, ErrNoMigration)
+}
+
+func TestTenantService_MigrationStatus_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(db, tc)
+	ctx := context.Background()
+
+	val := &temporalmocks.Value{}
+	val.On("Get", mock.Anything).Run(func(args mock.Arguments) {
+		m := args.Get(0).(*model.TenantMigration)
+		m.TenantID = "test-tenant-1"
+		m.Phase = model.MigrationPhaseTransfer
+		m.Location = model.MigrationLocationSource
+	}).Return(nil)
+	tc.On("QueryWorkflow", ctx, "migrate-tenant-test-tenant-1", "", "tenant-migration").Return(val, nil)
+
+	m, err := svc.MigrationStatus(ctx, "test-tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, model.MigrationPhaseTransfer, m.Phase)
+	assert.Equal(t, model.MigrationLocationSource, m.Location)
+}
+
+// ---------- FixPermissions ----------
+
+func TestTenantService_FixPermissions_Success(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*model.TenantPermissionsFix)) = model.TenantPermissionsFix{
+			TenantID: "test-tenant-1",
+			NodeID:   "test-node-1",
+			Scanned:  42,
+			Changed:  5,
+		}
+	}).Return(nil)
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "FixTenantPermissionsWorkflow", "test-tenant-1").Return(wfRun, nil)
+
+	fix, err := svc.FixPermissions(ctx, "test-tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, 42, fix.Scanned)
+	assert.Equal(t, 5, fix.Changed)
+	tc.AssertExpectations(t)
+}
+
+func TestTenantService_FixPermissions_WorkflowError(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewTenantService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Return(errors.New("activity schedule-to-start timeout"))
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "FixTenantPermissionsWorkflow", mock.Anything).Return(wfRun, nil)
+
+	_, err := svc.FixPermissions(ctx, "test-tenant-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fix permissions of tenant test-tenant-1")
+}
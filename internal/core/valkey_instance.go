@@ -2,10 +2,13 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/edvin/hosting/internal/crypto"
 	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
@@ -39,10 +42,10 @@ func (s *ValkeyInstanceService) Create(ctx context.Context, instance *model.Valk
 	instance.Port = nextPort
 
 	_, err = s.db.Exec(ctx,
-		`INSERT INTO valkey_instances (id, tenant_id, subscription_id, shard_id, port, max_memory_mb, password_hash, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		`INSERT INTO valkey_instances (id, tenant_id, subscription_id, shard_id, port, max_memory_mb, persistence_mode, password_hash, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
 		instance.ID, instance.TenantID, instance.SubscriptionID, instance.ShardID, instance.Port,
-		instance.MaxMemoryMB, instance.PasswordHash, instance.Status, instance.CreatedAt, instance.UpdatedAt,
+		instance.MaxMemoryMB, instance.PersistenceMode, instance.PasswordHash, instance.Status, instance.CreatedAt, instance.UpdatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("insert valkey instance: %w", err)
@@ -62,12 +65,12 @@ func (s *ValkeyInstanceService) Create(ctx context.Context, instance *model.Valk
 func (s *ValkeyInstanceService) GetByID(ctx context.Context, id string) (*model.ValkeyInstance, error) {
 	var v model.ValkeyInstance
 	err := s.db.QueryRow(ctx,
-		`SELECT vi.id, vi.tenant_id, vi.subscription_id, vi.shard_id, vi.port, vi.max_memory_mb, vi.password_hash, vi.status, vi.status_message, vi.suspend_reason, vi.created_at, vi.updated_at,
+		`SELECT vi.id, vi.tenant_id, vi.subscription_id, vi.shard_id, vi.port, vi.max_memory_mb, vi.persistence_mode, vi.password_hash, vi.status, vi.status_message, vi.suspend_reason, vi.created_at, vi.updated_at,
 		        s.name
 		 FROM valkey_instances vi
 		 LEFT JOIN shards s ON s.id = vi.shard_id
 		 WHERE vi.id = $1`, id,
-	).Scan(&v.ID, &v.TenantID, &v.SubscriptionID, &v.ShardID, &v.Port, &v.MaxMemoryMB,
+	).Scan(&v.ID, &v.TenantID, &v.SubscriptionID, &v.ShardID, &v.Port, &v.MaxMemoryMB, &v.PersistenceMode,
 		&v.PasswordHash, &v.Status, &v.StatusMessage, &v.SuspendReason, &v.CreatedAt, &v.UpdatedAt,
 		&v.ShardName)
 	if err != nil {
@@ -76,8 +79,36 @@ func (s *ValkeyInstanceService) GetByID(ctx context.Context, id string) (*model.
 	return &v, nil
 }
 
+// GetStats returns the latest memory and key-count snapshot collected by the
+// valkey stats cron. Instances that have not been sampled yet are reported as
+// unavailable with no collection timestamp.
+func (s *ValkeyInstanceService) GetStats(ctx context.Context, instance *model.ValkeyInstance) (*model.ValkeyInstanceStats, error) {
+	stats := &model.ValkeyInstanceStats{
+		InstanceID:     instance.ID,
+		MaxMemoryBytes: int64(instance.MaxMemoryMB) * 1024 * 1024,
+	}
+
+	var keyCount *int64
+	var collectedAt time.Time
+	err := s.db.QueryRow(ctx,
+		`SELECT bytes_used, key_count, available, collected_at
+		 FROM resource_usage WHERE resource_type = 'valkey' AND resource_id = $1`, instance.ID,
+	).Scan(&stats.UsedMemoryBytes, &keyCount, &stats.Available, &collectedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return stats, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get valkey instance stats %s: %w", instance.ID, err)
+	}
+	if keyCount != nil {
+		stats.KeyCount = *keyCount
+	}
+	stats.CollectedAt = &collectedAt
+	return stats, nil
+}
+
 func (s *ValkeyInstanceService) ListByTenant(ctx context.Context, tenantID string, limit int, cursor string) ([]model.ValkeyInstance, bool, error) {
-	query := `SELECT vi.id, vi.tenant_id, vi.subscription_id, vi.shard_id, vi.port, vi.max_memory_mb, vi.password_hash, vi.status, vi.status_message, vi.suspend_reason, vi.created_at, vi.updated_at, s.name FROM valkey_instances vi LEFT JOIN shards s ON s.id = vi.shard_id WHERE vi.tenant_id = $1`
+	query := `SELECT vi.id, vi.tenant_id, vi.subscription_id, vi.shard_id, vi.port, vi.max_memory_mb, vi.persistence_mode, vi.password_hash, vi.status, vi.status_message, vi.suspend_reason, vi.created_at, vi.updated_at, s.name FROM valkey_instances vi LEFT JOIN shards s ON s.id = vi.shard_id WHERE vi.tenant_id = $1`
 	args := []any{tenantID}
 	argIdx := 2
 
@@ -100,7 +131,7 @@ func (s *ValkeyInstanceService) ListByTenant(ctx context.Context, tenantID strin
 	var instances []model.ValkeyInstance
 	for rows.Next() {
 		var v model.ValkeyInstance
-		if err := rows.Scan(&v.ID, &v.TenantID, &v.SubscriptionID, &v.ShardID, &v.Port, &v.MaxMemoryMB,
+		if err := rows.Scan(&v.ID, &v.TenantID, &v.SubscriptionID, &v.ShardID, &v.Port, &v.MaxMemoryMB, &v.PersistenceMode,
 			&v.PasswordHash, &v.Status, &v.StatusMessage, &v.SuspendReason, &v.CreatedAt, &v.UpdatedAt,
 			&v.ShardName); err != nil {
 			return nil, false, fmt.Errorf("scan valkey instance: %w", err)
@@ -118,6 +149,28 @@ func (s *ValkeyInstanceService) ListByTenant(ctx context.Context, tenantID strin
 	return instances, hasMore, nil
 }
 
+// Update stores the instance's persistence mode and converges it onto the
+// shard's nodes, where running instances are reconfigured in place.
+func (s *ValkeyInstanceService) Update(ctx context.Context, instance *model.ValkeyInstance) error {
+	_, err := s.db.Exec(ctx,
+		`UPDATE valkey_instances SET persistence_mode = $1, updated_at = now() WHERE id = $2`,
+		instance.PersistenceMode, instance.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("update valkey instance %s: %w", instance.ID, err)
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, instance.TenantID, model.ProvisionTask{
+		WorkflowName: "CreateValkeyInstanceWorkflow",
+		WorkflowID:   workflowID("valkey-instance", instance.ID),
+		Arg:          instance.ID,
+	}); err != nil {
+		return fmt.Errorf("signal CreateValkeyInstanceWorkflow: %w", err)
+	}
+
+	return nil
+}
+
 func (s *ValkeyInstanceService) Delete(ctx context.Context, id string) error {
 	_, err := s.db.Exec(ctx,
 		"UPDATE valkey_instances SET status = $1, updated_at = now() WHERE id = $2",
@@ -2,12 +2,37 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
 	temporalclient "go.temporal.io/sdk/client"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrConnectionLimitAboveBrandMax is returned when a webroot's connection
+// limits exceed the maximum set on its tenant's brand.
+var ErrConnectionLimitAboveBrandMax = errors.New("connection limit exceeds the brand maximum")
+
+// ErrLimitZoneBudgetExceeded is returned when a shard's web nodes have no
+// shared memory left for another webroot's limit zones.
+var ErrLimitZoneBudgetExceeded = errors.New("shard connection limit zone budget exceeded")
+
+// ErrInvalidStaticRules is returned when a webroot's cache rules or MIME
+// types cannot be rendered into its nginx config.
+var ErrInvalidStaticRules = errors.New("invalid static rules")
+
+// ErrInvalidGeoBlocking is returned when a webroot's geo-blocking mode or
+// country codes are invalid.
+var ErrInvalidGeoBlocking = errors.New("invalid geo-blocking")
+
+// ErrInvalidDeployLockTTL is returned when a deploy lock TTL is not positive
+// or exceeds model.WebrootDeployLockMaxTTL.
+var ErrInvalidDeployLockTTL = errors.New("invalid deploy lock TTL")
+
 type WebrootService struct {
 	db DB
 	tc temporalclient.Client
@@ -18,15 +43,8 @@ func NewWebrootService(db DB, tc temporalclient.Client) *WebrootService {
 }
 
 func (s *WebrootService) Create(ctx context.Context, webroot *model.Webroot) error {
-	_, err := s.db.Exec(ctx,
-		`INSERT INTO webroots (id, tenant_id, subscription_id, runtime, runtime_version, runtime_config, public_folder, env_file_name, service_hostname_enabled, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`,
-		webroot.ID, webroot.TenantID, webroot.SubscriptionID, webroot.Runtime, webroot.RuntimeVersion,
-		webroot.RuntimeConfig, webroot.PublicFolder, webroot.EnvFileName,
-		webroot.ServiceHostnameEnabled, webroot.Status, webroot.CreatedAt, webroot.UpdatedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("insert webroot: %w", err)
+	if err := s.insert(ctx, webroot); err != nil {
+		return err
 	}
 
 	if err := signalProvision(ctx, s.tc, s.db, webroot.TenantID, model.ProvisionTask{
@@ -40,14 +58,28 @@ func (s *WebrootService) Create(ctx context.Context, webroot *model.Webroot) err
 	return nil
 }
 
+func (s *WebrootService) insert(ctx context.Context, webroot *model.Webroot) error {
+	_, err := s.db.Exec(ctx,
+		`INSERT INTO webroots (id, tenant_id, subscription_id, runtime, runtime_version, runtime_config, public_folder, error_pages, env_file_name, service_hostname_enabled, access_log_enabled, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`,
+		webroot.ID, webroot.TenantID, webroot.SubscriptionID, webroot.Runtime, webroot.RuntimeVersion,
+		webroot.RuntimeConfig, webroot.PublicFolder, errorPagesOrEmpty(webroot.ErrorPages), webroot.EnvFileName,
+		webroot.ServiceHostnameEnabled, webroot.AccessLogEnabled, webroot.Status, webroot.CreatedAt, webroot.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert webroot: %w", err)
+	}
+	return nil
+}
+
 func (s *WebrootService) GetByID(ctx context.Context, id string) (*model.Webroot, error) {
 	var w model.Webroot
 	err := s.db.QueryRow(ctx,
-		`SELECT id, tenant_id, subscription_id, runtime, runtime_version, runtime_config, public_folder, env_file_name, service_hostname_enabled, status, status_message, suspend_reason, created_at, updated_at
+		`SELECT id, tenant_id, subscription_id, runtime, runtime_version, runtime_config, public_folder, error_pages, env_file_name, service_hostname_enabled, access_log_enabled, connection_limits, static_rules, geo_blocking, deploy_locked_until, status, status_message, suspend_reason, created_at, updated_at
 		 FROM webroots WHERE id = $1`, id,
 	).Scan(&w.ID, &w.TenantID, &w.SubscriptionID, &w.Runtime, &w.RuntimeVersion,
-		&w.RuntimeConfig, &w.PublicFolder, &w.EnvFileName,
-		&w.ServiceHostnameEnabled, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt)
+		&w.RuntimeConfig, &w.PublicFolder, &w.ErrorPages, &w.EnvFileName,
+		&w.ServiceHostnameEnabled, &w.AccessLogEnabled, &w.ConnectionLimits, &w.StaticRules, &w.GeoBlocking, &w.DeployLockedUntil, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("get webroot %s: %w", id, err)
 	}
@@ -55,7 +87,7 @@ func (s *WebrootService) GetByID(ctx context.Context, id string) (*model.Webroot
 }
 
 func (s *WebrootService) ListByTenant(ctx context.Context, tenantID string, limit int, cursor string) ([]model.Webroot, bool, error) {
-	query := `SELECT id, tenant_id, subscription_id, runtime, runtime_version, runtime_config, public_folder, env_file_name, service_hostname_enabled, status, status_message, suspend_reason, created_at, updated_at FROM webroots WHERE tenant_id = $1`
+	query := `SELECT id, tenant_id, subscription_id, runtime, runtime_version, runtime_config, public_folder, error_pages, env_file_name, service_hostname_enabled, access_log_enabled, connection_limits, static_rules, geo_blocking, deploy_locked_until, status, status_message, suspend_reason, created_at, updated_at FROM webroots WHERE tenant_id = $1`
 	args := []any{tenantID}
 	argIdx := 2
 
@@ -79,8 +111,8 @@ func (s *WebrootService) ListByTenant(ctx context.Context, tenantID string, limi
 	for rows.Next() {
 		var w model.Webroot
 		if err := rows.Scan(&w.ID, &w.TenantID, &w.SubscriptionID, &w.Runtime, &w.RuntimeVersion,
-			&w.RuntimeConfig, &w.PublicFolder, &w.EnvFileName,
-			&w.ServiceHostnameEnabled, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt); err != nil {
+			&w.RuntimeConfig, &w.PublicFolder, &w.ErrorPages, &w.EnvFileName,
+			&w.ServiceHostnameEnabled, &w.AccessLogEnabled, &w.ConnectionLimits, &w.StaticRules, &w.GeoBlocking, &w.DeployLockedUntil, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt); err != nil {
 			return nil, false, fmt.Errorf("scan webroot: %w", err)
 		}
 		webroots = append(webroots, w)
@@ -99,9 +131,9 @@ func (s *WebrootService) ListByTenant(ctx context.Context, tenantID string, limi
 func (s *WebrootService) Update(ctx context.Context, webroot *model.Webroot) error {
 	_, err := s.db.Exec(ctx,
 		`UPDATE webroots SET runtime = $1, runtime_version = $2, runtime_config = $3,
-		 public_folder = $4, env_file_name = $5, service_hostname_enabled = $6, status = $7, updated_at = now() WHERE id = $8`,
+		 public_folder = $4, error_pages = $5, env_file_name = $6, service_hostname_enabled = $7, access_log_enabled = $8, status = $9, updated_at = now() WHERE id = $10`,
 		webroot.Runtime, webroot.RuntimeVersion, webroot.RuntimeConfig,
-		webroot.PublicFolder, webroot.EnvFileName, webroot.ServiceHostnameEnabled, webroot.Status, webroot.ID,
+		webroot.PublicFolder, errorPagesOrEmpty(webroot.ErrorPages), webroot.EnvFileName, webroot.ServiceHostnameEnabled, webroot.AccessLogEnabled, webroot.Status, webroot.ID,
 	)
 	if err != nil {
 		return fmt.Errorf("update webroot %s: %w", webroot.ID, err)
@@ -158,3 +190,280 @@ func (s *WebrootService) Retry(ctx context.Context, id string) error {
 		Arg:          id,
 	})
 }
+
+// errorPagesOrEmpty returns an empty map for nil so the NOT NULL JSONB column
+// is written as {} rather than SQL NULL.
+func errorPagesOrEmpty(pages map[int]string) map[int]string {
+	if pages == nil {
+		return map[int]string{}
+	}
+	return pages
+}
+
+// NginxPreview renders the nginx config a web node would generate for the
+// webroot from its current desired state. Nothing is written to the nodes.
+func (s *WebrootService) NginxPreview(ctx context.Context, webrootID string) (*model.WebrootNginxPreview, error) {
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("webroot-nginx-preview", webrootID+"-"+platform.NewID()),
+		TaskQueue: taskQueue,
+	}, "WebrootNginxPreviewWorkflow", webrootID)
+	if err != nil {
+		return nil, fmt.Errorf("start WebrootNginxPreviewWorkflow: %w", err)
+	}
+	var preview model.WebrootNginxPreview
+	if err := run.Get(ctx, &preview); err != nil {
+		return nil, fmt.Errorf("nginx preview for webroot %s: %w", webrootID, err)
+	}
+	return &preview, nil
+}
+
+// AccessLog returns the last tail requests from a webroot's shared access
+// logs, read on one of its web nodes.
+func (s *WebrootService) AccessLog(ctx context.Context, webrootID string, tail int) (*model.WebrootAccessLog, error) {
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("webroot-access-log", webrootID+"-"+platform.NewID()),
+		TaskQueue: taskQueue,
+	}, "WebrootAccessLogWorkflow", webrootID, tail)
+	if err != nil {
+		return nil, fmt.Errorf("start WebrootAccessLogWorkflow: %w", err)
+	}
+	var log model.WebrootAccessLog
+	if err := run.Get(ctx, &log); err != nil {
+		return nil, fmt.Errorf("access log for webroot %s: %w", webrootID, err)
+	}
+	return &log, nil
+}
+
+// GetBasicAuth returns the users allowed through a webroot's HTTP basic auth.
+func (s *WebrootService) GetBasicAuth(ctx context.Context, webrootID string) (*model.WebrootBasicAuth, error) {
+	var users map[string]string
+	err := s.db.QueryRow(ctx, `SELECT basic_auth FROM webroots WHERE id = $1`, webrootID).Scan(&users)
+	if err != nil {
+		return nil, fmt.Errorf("get basic auth for webroot %s: %w", webrootID, err)
+	}
+
+	usernames := make([]string, 0, len(users))
+	for u := range users {
+		usernames = append(usernames, u)
+	}
+	sort.Strings(usernames)
+
+	return &model.WebrootBasicAuth{
+		WebrootID: webrootID,
+		Enabled:   len(usernames) > 0,
+		Usernames: usernames,
+	}, nil
+}
+
+// SetBasicAuth replaces the users of a webroot's HTTP basic auth and
+// regenerates its nginx config. Plain passwords are hashed with bcrypt; only
+// hashes are stored. No users disables basic auth.
+func (s *WebrootService) SetBasicAuth(ctx context.Context, webrootID string, users []model.WebrootBasicAuthUser) error {
+	hashes := make(map[string]string, len(users))
+	for _, u := range users {
+		hash := u.PasswordHash
+		if u.Password != "" {
+			h, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
+			if err != nil {
+				return fmt.Errorf("hash password for %s: %w", u.Username, err)
+			}
+			hash = string(h)
+		}
+		hashes[u.Username] = hash
+	}
+
+	var tenantID string
+	err := s.db.QueryRow(ctx,
+		`UPDATE webroots SET basic_auth = $1, updated_at = now() WHERE id = $2 RETURNING tenant_id`,
+		hashes, webrootID,
+	).Scan(&tenantID)
+	if err != nil {
+		return fmt.Errorf("set basic auth for webroot %s: %w", webrootID, err)
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   workflowID("webroot", webrootID),
+		Arg:          webrootID,
+	}); err != nil {
+		return fmt.Errorf("signal UpdateWebrootWorkflow: %w", err)
+	}
+
+	return nil
+}
+
+// SetConnectionLimits sets the per-client-IP connection and request rate
+// limits of a webroot and regenerates its nginx config. The limits must not
+// exceed the brand's maximums, and their shared memory zones must fit in the
+// shard's budget together with those of the shard's other webroots.
+func (s *WebrootService) SetConnectionLimits(ctx context.Context, webrootID string, limits model.WebrootConnectionLimits) error {
+	var tenantID string
+	var shardID *string
+	var maxConns, maxRate int
+	err := s.db.QueryRow(ctx,
+		`SELECT w.tenant_id, t.shard_id, b.webroot_max_conns_per_ip, b.webroot_max_requests_per_sec
+		 FROM webroots w
+		 JOIN tenants t ON t.id = w.tenant_id
+		 JOIN brands b ON b.id = t.brand_id
+		 WHERE w.id = $1`, webrootID,
+	).Scan(&tenantID, &shardID, &maxConns, &maxRate)
+	if err != nil {
+		return fmt.Errorf("get connection limit maximums for webroot %s: %w", webrootID, err)
+	}
+
+	if maxConns > 0 && limits.MaxConnsPerIP > maxConns {
+		return fmt.Errorf("%w: max_conns_per_ip %d is above %d", ErrConnectionLimitAboveBrandMax, limits.MaxConnsPerIP, maxConns)
+	}
+	if maxRate > 0 && limits.RequestsPerSecond > maxRate {
+		return fmt.Errorf("%w: requests_per_second %d is above %d", ErrConnectionLimitAboveBrandMax, limits.RequestsPerSecond, maxRate)
+	}
+
+	if zones := limits.Zones(); zones > 0 && shardID != nil {
+		var used int
+		err := s.db.QueryRow(ctx,
+			`SELECT COALESCE(SUM(
+			          CASE WHEN (w.connection_limits->>'max_conns_per_ip')::int > 0 THEN 1 ELSE 0 END +
+			          CASE WHEN (w.connection_limits->>'requests_per_second')::int > 0 THEN 1 ELSE 0 END), 0)
+			 FROM webroots w JOIN tenants t ON t.id = w.tenant_id
+			 WHERE t.shard_id = $1 AND w.id <> $2`, *shardID, webrootID,
+		).Scan(&used)
+		if err != nil {
+			return fmt.Errorf("count limit zones on shard %s: %w", *shardID, err)
+		}
+		if (used+zones)*model.WebrootLimitZoneKB > model.WebrootLimitZoneBudgetKB {
+			return fmt.Errorf("%w: shard %s already has %d zones of %dk (budget %dk)",
+				ErrLimitZoneBudgetExceeded, *shardID, used, model.WebrootLimitZoneKB, model.WebrootLimitZoneBudgetKB)
+		}
+	}
+
+	if _, err := s.db.Exec(ctx,
+		`UPDATE webroots SET connection_limits = $1, updated_at = now() WHERE id = $2`,
+		limits, webrootID,
+	); err != nil {
+		return fmt.Errorf("set connection limits for webroot %s: %w", webrootID, err)
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   workflowID("webroot", webrootID),
+		Arg:          webrootID,
+	}); err != nil {
+		return fmt.Errorf("signal UpdateWebrootWorkflow: %w", err)
+	}
+
+	return nil
+}
+
+// SetStaticRules sets the caching headers and MIME types of a webroot and
+// regenerates its nginx config.
+func (s *WebrootService) SetStaticRules(ctx context.Context, webrootID string, rules model.WebrootStaticRules) error {
+	if err := rules.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidStaticRules, err)
+	}
+
+	var tenantID string
+	err := s.db.QueryRow(ctx,
+		`UPDATE webroots SET static_rules = $1, updated_at = now() WHERE id = $2 RETURNING tenant_id`,
+		rules, webrootID,
+	).Scan(&tenantID)
+	if err != nil {
+		return fmt.Errorf("set static rules for webroot %s: %w", webrootID, err)
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   workflowID("webroot", webrootID),
+		Arg:          webrootID,
+	}); err != nil {
+		return fmt.Errorf("signal UpdateWebrootWorkflow: %w", err)
+	}
+
+	return nil
+}
+
+// SetGeoBlocking sets the country allow or deny list of a webroot and
+// regenerates its nginx config. The zero value removes the restriction.
+func (s *WebrootService) SetGeoBlocking(ctx context.Context, webrootID string, geo model.WebrootGeoBlocking) error {
+	if err := geo.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidGeoBlocking, err)
+	}
+
+	var tenantID string
+	err := s.db.QueryRow(ctx,
+		`UPDATE webroots SET geo_blocking = $1, updated_at = now() WHERE id = $2 RETURNING tenant_id`,
+		geo, webrootID,
+	).Scan(&tenantID)
+	if err != nil {
+		return fmt.Errorf("set geo-blocking for webroot %s: %w", webrootID, err)
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   workflowID("webroot", webrootID),
+		Arg:          webrootID,
+	}); err != nil {
+		return fmt.Errorf("signal UpdateWebrootWorkflow: %w", err)
+	}
+
+	return nil
+}
+
+// WebrootDeployLockParams is the argument of WebrootDeployLockWorkflow.
+type WebrootDeployLockParams struct {
+	WebrootID string    `json:"webroot_id"`
+	Until     time.Time `json:"until"`
+}
+
+// SetDeployLock makes nginx answer the webroot's requests with 503 for ttl,
+// see model.WebrootDeployLock. Setting it again replaces the expiry. The
+// lock is applied and lifted by WebrootDeployLockWorkflow, started directly
+// rather than through the tenant's queue so that neither waits for queued
+// work.
+func (s *WebrootService) SetDeployLock(ctx context.Context, webrootID string, ttl time.Duration) (*model.WebrootDeployLock, error) {
+	if ttl <= 0 || ttl > model.WebrootDeployLockMaxTTL {
+		return nil, fmt.Errorf("%w: must be between 1s and %s", ErrInvalidDeployLockTTL, model.WebrootDeployLockMaxTTL)
+	}
+	// At the database's precision, so the workflow finds the lock it set.
+	until := time.Now().Add(ttl).Truncate(time.Microsecond)
+
+	var tenantID string
+	err := s.db.QueryRow(ctx,
+		`UPDATE webroots SET deploy_locked_until = $1, updated_at = now() WHERE id = $2 RETURNING tenant_id`,
+		until, webrootID,
+	).Scan(&tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("set deploy lock for webroot %s: %w", webrootID, err)
+	}
+
+	if err := startWorkflow(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "WebrootDeployLockWorkflow",
+		WorkflowID:   workflowID("webroot-deploy-lock", webrootID+"-"+platform.NewID()),
+		Arg:          WebrootDeployLockParams{WebrootID: webrootID, Until: until},
+	}); err != nil {
+		return nil, fmt.Errorf("start WebrootDeployLockWorkflow: %w", err)
+	}
+
+	return &model.WebrootDeployLock{WebrootID: webrootID, LockedUntil: &until}, nil
+}
+
+// ClearDeployLock lifts the webroot's deploy lock before it expires.
+func (s *WebrootService) ClearDeployLock(ctx context.Context, webrootID string) error {
+	var tenantID string
+	err := s.db.QueryRow(ctx,
+		`UPDATE webroots SET deploy_locked_until = NULL, updated_at = now() WHERE id = $1 RETURNING tenant_id`,
+		webrootID,
+	).Scan(&tenantID)
+	if err != nil {
+		return fmt.Errorf("clear deploy lock for webroot %s: %w", webrootID, err)
+	}
+
+	if err := startWorkflow(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   workflowID("webroot-deploy-unlock", webrootID+"-"+platform.NewID()),
+		Arg:          webrootID,
+	}); err != nil {
+		return fmt.Errorf("start UpdateWebrootWorkflow: %w", err)
+	}
+
+	return nil
+}
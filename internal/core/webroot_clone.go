@@ -0,0 +1,221 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/edvin/hosting/internal/crypto"
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	"github.com/edvin/hosting/internal/secrets"
+	"github.com/jackc/pgx/v5"
+	temporalclient "go.temporal.io/sdk/client"
+)
+
+// ErrCloneSourceNotActive is returned when the webroot to clone is not active.
+var ErrCloneSourceNotActive = errors.New("only active webroots can be cloned")
+
+// ErrCloneDatabaseNotFound is returned when the database to clone is not an
+// active database of the webroot's tenant.
+var ErrCloneDatabaseNotFound = errors.New("database to clone not found among the tenant's active databases")
+
+// ErrCloneUnknownSecret is returned when a secret value is given for a name
+// that is not a secret env var of the source webroot.
+var ErrCloneUnknownSecret = errors.New("not a secret env var of the source webroot")
+
+// CloneWebrootInput selects what is cloned besides the webroot's settings and
+// files.
+type CloneWebrootInput struct {
+	// DatabaseID is a database of the tenant to clone along with the
+	// webroot, or empty.
+	DatabaseID string
+	// SecretEnvVars are values for the source's secret env vars by name.
+	// Secrets without a value get a new random one.
+	SecretEnvVars map[string]string
+}
+
+type WebrootCloneService struct {
+	db  DB
+	tc  temporalclient.Client
+	kek []byte // master key (KEK), 32 bytes
+}
+
+func NewWebrootCloneService(db DB, tc temporalclient.Client, kekHex string) *WebrootCloneService {
+	var kek []byte
+	if kekHex != "" {
+		kek, _ = hex.DecodeString(kekHex)
+	}
+	return &WebrootCloneService{db: db, tc: tc, kek: kek}
+}
+
+// Clone creates a new webroot in the source's tenant with the source's
+// runtime, settings and basic auth, and its env vars. Secret env vars are not
+// copied: they take the value from in.SecretEnvVars or a new random one. The
+// clone gets no FQDNs. If in.DatabaseID is set, that database is cloned to a
+// new database on the same shard, and references to the source webroot and
+// database names in plain env vars are pointed at the clones. Files and data
+// are copied by CloneWebrootWorkflow, which deletes the clones again if a
+// step fails.
+func (s *WebrootCloneService) Clone(ctx context.Context, sourceID string, in CloneWebrootInput) (*model.WebrootClone, error) {
+	var tenantID, status string
+	err := s.db.QueryRow(ctx, `SELECT tenant_id, status FROM webroots WHERE id = $1`, sourceID).Scan(&tenantID, &status)
+	if err != nil {
+		return nil, fmt.Errorf("get webroot %s: %w", sourceID, err)
+	}
+	if status != model.StatusActive {
+		return nil, fmt.Errorf("%w: webroot %s is %s", ErrCloneSourceNotActive, sourceID, status)
+	}
+
+	now := time.Now()
+	cloneID := platform.NewName("w")
+
+	var database *model.Database
+	if in.DatabaseID != "" {
+		database = &model.Database{
+			ID:        platform.NewName("db"),
+			TenantID:  tenantID,
+			Status:    model.StatusPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		err := s.db.QueryRow(ctx,
+			`SELECT subscription_id, shard_id, charset, collation FROM databases
+			 WHERE id = $1 AND tenant_id = $2 AND status = $3`, in.DatabaseID, tenantID, model.StatusActive,
+		).Scan(&database.SubscriptionID, &database.ShardID, &database.Charset, &database.Collation)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && database.ShardID == nil) {
+			return nil, fmt.Errorf("%w: %s", ErrCloneDatabaseNotFound, in.DatabaseID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get database %s: %w", in.DatabaseID, err)
+		}
+	}
+
+	vars, regenerated, err := s.cloneEnvVars(ctx, tenantID, sourceID, cloneID, in, database)
+	if err != nil {
+		return nil, err
+	}
+
+	var w model.Webroot
+	err = s.db.QueryRow(ctx,
+		`INSERT INTO webroots (id, tenant_id, subscription_id, runtime, runtime_version, runtime_config, public_folder, error_pages, basic_auth, env_file_name, service_hostname_enabled, access_log_enabled, static_rules, geo_blocking, status, created_at, updated_at)
+		 SELECT $1, tenant_id, subscription_id, runtime, runtime_version, runtime_config, public_folder, error_pages, basic_auth, env_file_name, service_hostname_enabled, access_log_enabled, static_rules, geo_blocking, $2, $3, $3
+		 FROM webroots WHERE id = $4
+		 RETURNING id, tenant_id, subscription_id, runtime, runtime_version, runtime_config, public_folder, error_pages, env_file_name, service_hostname_enabled, access_log_enabled, connection_limits, static_rules, geo_blocking, deploy_locked_until, status, status_message, suspend_reason, created_at, updated_at`,
+		cloneID, model.StatusPending, now, sourceID,
+	).Scan(&w.ID, &w.TenantID, &w.SubscriptionID, &w.Runtime, &w.RuntimeVersion,
+		&w.RuntimeConfig, &w.PublicFolder, &w.ErrorPages, &w.EnvFileName,
+		&w.ServiceHostnameEnabled, &w.AccessLogEnabled, &w.ConnectionLimits, &w.StaticRules, &w.GeoBlocking, &w.DeployLockedUntil, &w.Status, &w.StatusMessage, &w.SuspendReason, &w.CreatedAt, &w.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("insert clone of webroot %s: %w", sourceID, err)
+	}
+
+	for _, v := range vars {
+		_, err := s.db.Exec(ctx,
+			`INSERT INTO webroot_env_vars (id, webroot_id, name, value, is_secret, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			platform.NewID(), cloneID, v.Name, v.Value, v.IsSecret, now, now)
+		if err != nil {
+			return nil, fmt.Errorf("insert env var %s: %w", v.Name, err)
+		}
+	}
+
+	params := model.CloneWebrootParams{SourceWebrootID: sourceID, WebrootID: cloneID}
+	if database != nil {
+		_, err := s.db.Exec(ctx,
+			`INSERT INTO databases (id, tenant_id, subscription_id, shard_id, node_id, charset, collation, status, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			database.ID, database.TenantID, database.SubscriptionID, database.ShardID, database.NodeID,
+			database.Charset, database.Collation, database.Status, database.CreatedAt, database.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("insert database: %w", err)
+		}
+		params.SourceDatabaseID = in.DatabaseID
+		params.DatabaseID = database.ID
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "CloneWebrootWorkflow",
+		WorkflowID:   workflowID("clone-webroot", cloneID),
+		Arg:          params,
+	}); err != nil {
+		return nil, fmt.Errorf("signal CloneWebrootWorkflow: %w", err)
+	}
+
+	return &model.WebrootClone{
+		SourceWebrootID:    sourceID,
+		Webroot:            &w,
+		Database:           database,
+		RegeneratedSecrets: regenerated,
+	}, nil
+}
+
+// cloneEnvVars returns the env vars of the clone, ready to insert, and the
+// names of the secrets that were regenerated.
+func (s *WebrootCloneService) cloneEnvVars(ctx context.Context, tenantID, sourceID, cloneID string, in CloneWebrootInput, database *model.Database) ([]model.WebrootEnvVar, []string, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT name, value, is_secret FROM webroot_env_vars WHERE webroot_id = $1 ORDER BY name`, sourceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("list env vars: %w", err)
+	}
+	defer rows.Close()
+
+	var vars []model.WebrootEnvVar
+	secretNames := map[string]bool{}
+	for rows.Next() {
+		var v model.WebrootEnvVar
+		if err := rows.Scan(&v.Name, &v.Value, &v.IsSecret); err != nil {
+			return nil, nil, fmt.Errorf("scan env var: %w", err)
+		}
+		if v.IsSecret {
+			secretNames[v.Name] = true
+		}
+		vars = append(vars, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate env vars: %w", err)
+	}
+
+	for name := range in.SecretEnvVars {
+		if !secretNames[name] {
+			return nil, nil, fmt.Errorf("%w: %s", ErrCloneUnknownSecret, name)
+		}
+	}
+
+	pairs := []string{sourceID, cloneID}
+	if database != nil {
+		pairs = append(pairs, in.DatabaseID, database.ID)
+	}
+	rename := strings.NewReplacer(pairs...)
+
+	var dek []byte
+	regenerated := []string{}
+	for i, v := range vars {
+		if !v.IsSecret {
+			vars[i].Value = rename.Replace(v.Value)
+			continue
+		}
+		value, ok := in.SecretEnvVars[v.Name]
+		if !ok {
+			if value, err = secrets.Generate(); err != nil {
+				return nil, nil, fmt.Errorf("generate env var %s: %w", v.Name, err)
+			}
+			regenerated = append(regenerated, v.Name)
+		}
+		if dek == nil {
+			if dek, err = loadOrCreateTenantDEK(ctx, s.db, s.kek, tenantID); err != nil {
+				return nil, nil, fmt.Errorf("get tenant dek: %w", err)
+			}
+		}
+		encrypted, err := crypto.Encrypt([]byte(value), dek)
+		if err != nil {
+			return nil, nil, fmt.Errorf("encrypt env var %s: %w", v.Name, err)
+		}
+		vars[i].Value = encrypted
+	}
+	return vars, regenerated, nil
+}
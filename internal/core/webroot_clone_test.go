@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/edvin/hosting/internal/crypto"
+	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/secrets"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func sqlContains(substr string) any {
+	return mock.MatchedBy(func(sql string) bool { return strings.Contains(sql, substr) })
+}
+
+func cloneSourceRow(status string) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-tenant-1"
+		*(dest[1].(*string)) = status
+		return nil
+	}}
+}
+
+func cloneEnvVarRow(name, value string, secret bool) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = name
+		*(dest[1].(*string)) = value
+		*(dest[2].(*bool)) = secret
+		return nil
+	}
+}
+
+func TestWebrootCloneService_Clone_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	kek, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	dek, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	encryptedDEK, err := crypto.Encrypt(dek, kek)
+	require.NoError(t, err)
+	svc := NewWebrootCloneService(db, tc, hex.EncodeToString(kek))
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, sqlContains("SELECT tenant_id, status FROM webroots"), mock.Anything).Return(cloneSourceRow(model.StatusActive))
+	db.On("QueryRow", ctx, sqlContains("FROM databases"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		shardID := "test-db-shard"
+		*(dest[0].(*string)) = "test-sub-1"
+		*(dest[1].(**string)) = &shardID
+		*(dest[2].(*string)) = "utf8mb4"
+		*(dest[3].(*string)) = "utf8mb4_unicode_ci"
+		return nil
+	}})
+	db.On("Query", ctx, sqlContains("FROM webroot_env_vars"), mock.Anything).Return(newMockRows(
+		cloneEnvVarRow("API_KEY", "ciphertext", true),
+		cloneEnvVarRow("DB_NAME", "db_source", false),
+		cloneEnvVarRow("SMTP_PASSWORD", "ciphertext", true),
+	), nil)
+	db.On("QueryRow", ctx, sqlContains("FROM tenant_encryption_keys"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = encryptedDEK
+		return nil
+	}})
+	db.On("QueryRow", ctx, sqlContains("INSERT INTO webroots"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "w_clone"
+		*(dest[1].(*string)) = "test-tenant-1"
+		*(dest[15].(*string)) = model.StatusPending
+		return nil
+	}})
+
+	envVars := map[string]string{}
+	db.On("Exec", ctx, sqlContains("INSERT INTO webroot_env_vars"), mock.Anything).Run(func(args mock.Arguments) {
+		vals := args.Get(2).([]any)
+		envVars[vals[2].(string)] = vals[3].(string)
+	}).Return(pgconn.CommandTag{}, nil)
+	db.On("Exec", ctx, sqlContains("INSERT INTO databases"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+	mockSignalOK(tc)
+
+	clone, err := svc.Clone(ctx, "w_source", CloneWebrootInput{
+		DatabaseID:    "db_source",
+		SecretEnvVars: map[string]string{"SMTP_PASSWORD": "staging-password"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "w_source", clone.SourceWebrootID)
+	assert.Equal(t, model.StatusPending, clone.Webroot.Status)
+	require.NotNil(t, clone.Database)
+	assert.Equal(t, "test-sub-1", clone.Database.SubscriptionID)
+	assert.Equal(t, "test-db-shard", *clone.Database.ShardID)
+	assert.Equal(t, []string{"API_KEY"}, clone.RegeneratedSecrets)
+
+	// Plain env vars point at the cloned database.
+	assert.Equal(t, clone.Database.ID, envVars["DB_NAME"])
+	// Secrets are stored encrypted, with the given value or a new one.
+	plain, err := crypto.Decrypt(envVars["SMTP_PASSWORD"], dek)
+	require.NoError(t, err)
+	assert.Equal(t, "staging-password", string(plain))
+	plain, err = crypto.Decrypt(envVars["API_KEY"], dek)
+	require.NoError(t, err)
+	assert.Len(t, plain, secrets.DefaultGenerator.Length)
+
+	tc.AssertCalled(t, "SignalWithStartWorkflow", mock.Anything, "tenant-test-tenant-1", mock.Anything, mock.MatchedBy(func(task model.ProvisionTask) bool {
+		p, ok := task.Arg.(model.CloneWebrootParams)
+		return ok && task.WorkflowName == "CloneWebrootWorkflow" &&
+			p.SourceWebrootID == "w_source" && p.SourceDatabaseID == "db_source" && p.DatabaseID == clone.Database.ID
+	}), mock.Anything, mock.Anything)
+}
+
+func TestWebrootCloneService_Clone_SourceNotActive(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootCloneService(db, nil, "")
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, sqlContains("SELECT tenant_id, status FROM webroots"), mock.Anything).Return(cloneSourceRow(model.StatusSuspended))
+
+	_, err := svc.Clone(ctx, "w_source", CloneWebrootInput{})
+	require.ErrorIs(t, err, ErrCloneSourceNotActive)
+}
+
+func TestWebrootCloneService_Clone_DatabaseNotInTenant(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootCloneService(db, nil, "")
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, sqlContains("SELECT tenant_id, status FROM webroots"), mock.Anything).Return(cloneSourceRow(model.StatusActive))
+	db.On("QueryRow", ctx, sqlContains("FROM databases"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		return pgx.ErrNoRows
+	}})
+
+	_, err := svc.Clone(ctx, "w_source", CloneWebrootInput{DatabaseID: "db_other"})
+	require.ErrorIs(t, err, ErrCloneDatabaseNotFound)
+}
+
+func TestWebrootCloneService_Clone_UnknownSecret(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootCloneService(db, nil, "")
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, sqlContains("SELECT tenant_id, status FROM webroots"), mock.Anything).Return(cloneSourceRow(model.StatusActive))
+	db.On("Query", ctx, sqlContains("FROM webroot_env_vars"), mock.Anything).Return(newMockRows(
+		cloneEnvVarRow("DB_NAME", "db_source", false),
+	), nil)
+
+	_, err := svc.Clone(ctx, "w_source", CloneWebrootInput{SecretEnvVars: map[string]string{"DB_NAME": "x"}})
+	require.ErrorIs(t, err, ErrCloneUnknownSecret)
+	db.AssertNotCalled(t, "QueryRow", ctx, sqlContains("INSERT INTO webroots"), mock.Anything)
+}
@@ -208,29 +208,40 @@ func (s *WebrootEnvVarService) VaultDecrypt(ctx context.Context, webrootID, toke
 
 // getTenantDEK retrieves and decrypts the tenant's DEK using the KEK.
 func (s *WebrootEnvVarService) getTenantDEK(ctx context.Context, tenantID string) ([]byte, error) {
+	return loadTenantDEK(ctx, s.db, s.kek, tenantID)
+}
+
+// getOrCreateTenantDEK retrieves or creates the tenant's DEK.
+func (s *WebrootEnvVarService) getOrCreateTenantDEK(ctx context.Context, tenantID string) ([]byte, error) {
+	return loadOrCreateTenantDEK(ctx, s.db, s.kek, tenantID)
+}
+
+// loadTenantDEK retrieves and decrypts the tenant's DEK using the KEK.
+func loadTenantDEK(ctx context.Context, db DB, kek []byte, tenantID string) ([]byte, error) {
 	var encryptedDEK string
-	err := s.db.QueryRow(ctx,
+	err := db.QueryRow(ctx,
 		`SELECT encrypted_dek FROM tenant_encryption_keys WHERE tenant_id = $1`, tenantID,
 	).Scan(&encryptedDEK)
 	if err != nil {
 		return nil, fmt.Errorf("get tenant encryption key for %s: %w", tenantID, err)
 	}
-	dek, err := crypto.Decrypt(encryptedDEK, s.kek)
+	dek, err := crypto.Decrypt(encryptedDEK, kek)
 	if err != nil {
 		return nil, fmt.Errorf("decrypt tenant dek: %w", err)
 	}
 	return dek, nil
 }
 
-// getOrCreateTenantDEK retrieves or creates the tenant's DEK.
-func (s *WebrootEnvVarService) getOrCreateTenantDEK(ctx context.Context, tenantID string) ([]byte, error) {
+// loadOrCreateTenantDEK retrieves the tenant's DEK, creating and storing a
+// new one on first use.
+func loadOrCreateTenantDEK(ctx context.Context, db DB, kek []byte, tenantID string) ([]byte, error) {
 	var encryptedDEK string
-	err := s.db.QueryRow(ctx,
+	err := db.QueryRow(ctx,
 		`SELECT encrypted_dek FROM tenant_encryption_keys WHERE tenant_id = $1`, tenantID,
 	).Scan(&encryptedDEK)
 	if err == nil {
 		// DEK exists, decrypt and return.
-		dek, err := crypto.Decrypt(encryptedDEK, s.kek)
+		dek, err := crypto.Decrypt(encryptedDEK, kek)
 		if err != nil {
 			return nil, fmt.Errorf("decrypt tenant dek: %w", err)
 		}
@@ -244,13 +255,13 @@ func (s *WebrootEnvVarService) getOrCreateTenantDEK(ctx context.Context, tenantI
 	}
 
 	// Encrypt DEK with KEK.
-	encrypted, err := crypto.Encrypt(dek, s.kek)
+	encrypted, err := crypto.Encrypt(dek, kek)
 	if err != nil {
 		return nil, fmt.Errorf("encrypt tenant dek: %w", err)
 	}
 
 	// Store encrypted DEK.
-	_, err = s.db.Exec(ctx,
+	_, err = db.Exec(ctx,
 		`INSERT INTO tenant_encryption_keys (tenant_id, encrypted_dek) VALUES ($1, $2)
 		 ON CONFLICT (tenant_id) DO NOTHING`,
 		tenantID, encrypted)
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+	temporalclient "go.temporal.io/sdk/client"
+)
+
+// ErrNoPreviousRelease is returned by Rollback when the webroot has no
+// earlier promoted release to go back to.
+var ErrNoPreviousRelease = errors.New("no previous release to roll back to")
+
+type WebrootReleaseService struct {
+	db DB
+	tc temporalclient.Client
+}
+
+func NewWebrootReleaseService(db DB, tc temporalclient.Client) *WebrootReleaseService {
+	return &WebrootReleaseService{db: db, tc: tc}
+}
+
+// Create records a release and creates its (empty) directory on the shard.
+func (s *WebrootReleaseService) Create(ctx context.Context, release *model.WebrootRelease) error {
+	_, err := s.db.Exec(ctx,
+		`INSERT INTO webroot_releases (id, tenant_id, webroot_id, name, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		release.ID, release.TenantID, release.WebrootID, release.Name, release.Status,
+		release.CreatedAt, release.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert webroot release: %w", err)
+	}
+
+	if err := signalProvision(ctx, s.tc, s.db, release.TenantID, model.ProvisionTask{
+		WorkflowName: "CreateWebrootReleaseWorkflow",
+		WorkflowID:   fmt.Sprintf("create-webroot-release-%s", release.ID),
+		Arg:          release.ID,
+	}); err != nil {
+		return fmt.Errorf("signal CreateWebrootReleaseWorkflow: %w", err)
+	}
+
+	return nil
+}
+
+const webrootReleaseColumns = `id, tenant_id, webroot_id, name, current, status, status_message, promoted_at, created_at, updated_at`
+
+func scanWebrootRelease(row interface{ Scan(dest ...any) error }) (model.WebrootRelease, error) {
+	var r model.WebrootRelease
+	err := row.Scan(&r.ID, &r.TenantID, &r.WebrootID, &r.Name, &r.Current,
+		&r.Status, &r.StatusMessage, &r.PromotedAt, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+func (s *WebrootReleaseService) GetByID(ctx context.Context, id string) (*model.WebrootRelease, error) {
+	r, err := scanWebrootRelease(s.db.QueryRow(ctx,
+		`SELECT `+webrootReleaseColumns+` FROM webroot_releases WHERE id = $1`, id))
+	if err != nil {
+		return nil, fmt.Errorf("get webroot release %s: %w", id, err)
+	}
+	return &r, nil
+}
+
+// ListByWebroot returns a webroot's releases, newest first. Release names
+// sort by creation time, so the cursor is the last release's name.
+func (s *WebrootReleaseService) ListByWebroot(ctx context.Context, webrootID string, limit int, cursor string) ([]model.WebrootRelease, bool, error) {
+	query := `SELECT ` + webrootReleaseColumns + ` FROM webroot_releases WHERE webroot_id = $1`
+	args := []any{webrootID}
+	argIdx := 2
+
+	if cursor != "" {
+		query += fmt.Sprintf(` AND name < $%d`, argIdx)
+		args = append(args, cursor)
+		argIdx++
+	}
+
+	query += ` ORDER BY name DESC`
+	query += fmt.Sprintf(` LIMIT $%d`, argIdx)
+	args = append(args, limit+1)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("list releases for webroot %s: %w", webrootID, err)
+	}
+	defer rows.Close()
+
+	var releases []model.WebrootRelease
+	for rows.Next() {
+		r, err := scanWebrootRelease(rows)
+		if err != nil {
+			return nil, false, fmt.Errorf("scan webroot release: %w", err)
+		}
+		releases = append(releases, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("iterate webroot releases: %w", err)
+	}
+
+	hasMore := len(releases) > limit
+	if hasMore {
+		releases = releases[:limit]
+	}
+	return releases, hasMore, nil
+}
+
+// Promote makes a release live: the webroot's current symlink is swapped to
+// it and the runtime reloaded.
+func (s *WebrootReleaseService) Promote(ctx context.Context, release *model.WebrootRelease) error {
+	if err := signalProvision(ctx, s.tc, s.db, release.TenantID, model.ProvisionTask{
+		WorkflowName: "PromoteWebrootReleaseWorkflow",
+		WorkflowID:   fmt.Sprintf("promote-webroot-release-%s", release.ID),
+		Arg:          release.ID,
+	}); err != nil {
+		return fmt.Errorf("signal PromoteWebrootReleaseWorkflow: %w", err)
+	}
+	return nil
+}
+
+// Rollback promotes the newest previously-live release that is older than
+// the current one and returns it. Repeated rollbacks walk further back.
+func (s *WebrootReleaseService) Rollback(ctx context.Context, webrootID string) (*model.WebrootRelease, error) {
+	r, err := scanWebrootRelease(s.db.QueryRow(ctx,
+		`SELECT `+webrootReleaseColumns+` FROM webroot_releases
+		 WHERE webroot_id = $1 AND NOT current AND status = $2 AND promoted_at IS NOT NULL
+		   AND name < (SELECT name FROM webroot_releases WHERE webroot_id = $1 AND current)
+		 ORDER BY name DESC LIMIT 1`,
+		webrootID, model.StatusActive))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNoPreviousRelease
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find previous release for webroot %s: %w", webrootID, err)
+	}
+
+	if err := s.Promote(ctx, &r); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
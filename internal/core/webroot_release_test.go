@@ -0,0 +1,93 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+)
+
+func TestWebrootReleaseService_Create_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootReleaseService(db, tc)
+	ctx := context.Background()
+
+	release := &model.WebrootRelease{
+		ID:        "test-release-1",
+		TenantID:  "test-tenant-1",
+		WebrootID: "test-webroot-1",
+		Name:      "20261015T091203Z",
+		Status:    model.StatusPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("SignalWithStartWorkflow", ctx, "tenant-test-tenant-1", model.ProvisionSignalName,
+		mock.MatchedBy(func(task model.ProvisionTask) bool {
+			return task.WorkflowName == "CreateWebrootReleaseWorkflow" && task.Arg == "test-release-1"
+		}),
+		mock.Anything, "TenantProvisionWorkflow").Return(wfRun, nil)
+
+	require.NoError(t, svc.Create(ctx, release))
+	db.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func webrootReleaseRow(id, name string) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		promoted := time.Now()
+		*(dest[0].(*string)) = id
+		*(dest[1].(*string)) = "test-tenant-1"
+		*(dest[2].(*string)) = "test-webroot-1"
+		*(dest[3].(*string)) = name
+		*(dest[4].(*bool)) = false
+		*(dest[5].(*string)) = model.StatusActive
+		*(dest[7].(**time.Time)) = &promoted
+		return nil
+	}}
+}
+
+func TestWebrootReleaseService_Rollback_PromotesPreviousRelease(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootReleaseService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).
+		Return(webrootReleaseRow("test-release-1", "20261014T080000Z"))
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("SignalWithStartWorkflow", ctx, "tenant-test-tenant-1", model.ProvisionSignalName,
+		mock.MatchedBy(func(task model.ProvisionTask) bool {
+			return task.WorkflowName == "PromoteWebrootReleaseWorkflow" && task.Arg == "test-release-1"
+		}),
+		mock.Anything, "TenantProvisionWorkflow").Return(wfRun, nil)
+
+	release, err := svc.Rollback(ctx, "test-webroot-1")
+	require.NoError(t, err)
+	assert.Equal(t, "20261014T080000Z", release.Name)
+	tc.AssertExpectations(t)
+}
+
+func TestWebrootReleaseService_Rollback_NoPreviousRelease(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootReleaseService(db, tc)
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error { return pgx.ErrNoRows }}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+	_, err := svc.Rollback(ctx, "test-webroot-1")
+	assert.ErrorIs(t, err, ErrNoPreviousRelease)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
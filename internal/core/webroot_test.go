@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	temporalmocks "go.temporal.io/sdk/mocks"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestNewWebrootService(t *testing.T) {
@@ -104,6 +106,7 @@ func TestWebrootService_GetByID_Success(t *testing.T) {
 	webrootID := "test-webroot-1"
 	tenantID := "test-tenant-1"
 	now := time.Now().Truncate(time.Microsecond)
+	lockedUntil := now.Add(time.Minute)
 	cfg := json.RawMessage(`{"pool_size":5}`)
 
 	row := &mockRow{scanFunc: func(dest ...any) error {
@@ -114,13 +117,19 @@ func TestWebrootService_GetByID_Success(t *testing.T) {
 		*(dest[4].(*string)) = "8.2"
 		*(dest[5].(*json.RawMessage)) = cfg
 		*(dest[6].(*string)) = "/public"
-		*(dest[7].(*string)) = ".env.hosting"
-		*(dest[8].(*bool)) = true  // service_hostname_enabled
-		*(dest[9].(*string)) = model.StatusActive
-		*(dest[10].(**string)) = nil // status_message
-		*(dest[11].(*string)) = ""  // suspend_reason
-		*(dest[12].(*time.Time)) = now
-		*(dest[13].(*time.Time)) = now
+		*(dest[7].(*map[int]string)) = map[int]string{404: "public/404.html"}
+		*(dest[8].(*string)) = ".env.hosting"
+		*(dest[9].(*bool)) = true  // service_hostname_enabled
+		*(dest[10].(*bool)) = true // access_log_enabled
+		*(dest[11].(*model.WebrootConnectionLimits)) = model.WebrootConnectionLimits{RequestsPerSecond: 10}
+		*(dest[12].(*model.WebrootStaticRules)) = model.WebrootStaticRules{MimeTypes: map[string]string{"md": "text/markdown"}}
+		*(dest[13].(*model.WebrootGeoBlocking)) = model.WebrootGeoBlocking{Mode: model.GeoBlockingDeny, Countries: []string{"KP"}}
+		*(dest[14].(**time.Time)) = &lockedUntil
+		*(dest[15].(*string)) = model.StatusActive
+		*(dest[16].(**string)) = nil // status_message
+		*(dest[17].(*string)) = ""  // suspend_reason
+		*(dest[18].(*time.Time)) = now
+		*(dest[19].(*time.Time)) = now
 		return nil
 	}}
 	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
@@ -132,6 +141,12 @@ func TestWebrootService_GetByID_Success(t *testing.T) {
 	assert.Equal(t, "php", result.Runtime)
 	assert.Equal(t, "8.2", result.RuntimeVersion)
 	assert.Equal(t, "/public", result.PublicFolder)
+	assert.Equal(t, "public/404.html", result.ErrorPages[404])
+	assert.True(t, result.AccessLogEnabled)
+	assert.Equal(t, 10, result.ConnectionLimits.RequestsPerSecond)
+	assert.Equal(t, []string{"KP"}, result.GeoBlocking.Countries)
+	assert.Equal(t, &lockedUntil, result.DeployLockedUntil)
+	assert.Equal(t, "text/markdown", result.StaticRules.MimeTypes["md"])
 	db.AssertExpectations(t)
 }
 
@@ -175,13 +190,19 @@ func TestWebrootService_ListByTenant_Success(t *testing.T) {
 			*(dest[4].(*string)) = "8.2"
 			*(dest[5].(*json.RawMessage)) = cfg
 			*(dest[6].(*string)) = "/public"
-			*(dest[7].(*string)) = ".env.hosting"
-			*(dest[8].(*bool)) = true  // service_hostname_enabled
-			*(dest[9].(*string)) = model.StatusActive
-			*(dest[10].(**string)) = nil // status_message
-			*(dest[11].(*string)) = ""  // suspend_reason
-			*(dest[12].(*time.Time)) = now
-			*(dest[13].(*time.Time)) = now
+			*(dest[7].(*map[int]string)) = map[int]string{404: "public/404.html"}
+			*(dest[8].(*string)) = ".env.hosting"
+			*(dest[9].(*bool)) = true  // service_hostname_enabled
+			*(dest[10].(*bool)) = true // access_log_enabled
+			*(dest[11].(*model.WebrootConnectionLimits)) = model.WebrootConnectionLimits{RequestsPerSecond: 10}
+			*(dest[12].(*model.WebrootStaticRules)) = model.WebrootStaticRules{}
+			*(dest[13].(*model.WebrootGeoBlocking)) = model.WebrootGeoBlocking{}
+			*(dest[14].(**time.Time)) = nil // deploy_locked_until
+			*(dest[15].(*string)) = model.StatusActive
+			*(dest[16].(**string)) = nil // status_message
+			*(dest[17].(*string)) = ""  // suspend_reason
+			*(dest[18].(*time.Time)) = now
+			*(dest[19].(*time.Time)) = now
 			return nil
 		},
 	)
@@ -334,3 +355,373 @@ func TestWebrootService_Delete_WorkflowError(t *testing.T) {
 	db.AssertExpectations(t)
 	tc.AssertExpectations(t)
 }
+
+// ---------- NginxPreview ----------
+
+func TestWebrootService_NginxPreview_Success(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*model.WebrootNginxPreview)) = model.WebrootNginxPreview{
+			WebrootID: "test-webroot-1",
+			NodeID:    "test-node-1",
+			Config:    "server {}",
+			Note:      model.WebrootNginxPreviewNote,
+		}
+	}).Return(nil)
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "WebrootNginxPreviewWorkflow", "test-webroot-1").Return(wfRun, nil)
+
+	preview, err := svc.NginxPreview(ctx, "test-webroot-1")
+	require.NoError(t, err)
+	assert.Equal(t, "server {}", preview.Config)
+	assert.Equal(t, "test-node-1", preview.NodeID)
+	tc.AssertExpectations(t)
+}
+
+func TestWebrootService_NginxPreview_WorkflowError(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Return(errors.New("activity schedule-to-start timeout"))
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "WebrootNginxPreviewWorkflow", "test-webroot-1").Return(wfRun, nil)
+
+	_, err := svc.NginxPreview(ctx, "test-webroot-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nginx preview for webroot test-webroot-1")
+}
+
+// ---------- AccessLog ----------
+
+func TestWebrootService_AccessLog_Success(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		*(args.Get(1).(*model.WebrootAccessLog)) = model.WebrootAccessLog{
+			WebrootID:    "test-webroot-1",
+			Enabled:      true,
+			Lines:        []model.WebrootAccessLogLine{{URI: "/", Status: 200}},
+			StatusCounts: map[string]int{"2xx": 1},
+		}
+	}).Return(nil)
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "WebrootAccessLogWorkflow", "test-webroot-1", 20).Return(wfRun, nil)
+
+	log, err := svc.AccessLog(ctx, "test-webroot-1", 20)
+	require.NoError(t, err)
+	assert.True(t, log.Enabled)
+	require.Len(t, log.Lines, 1)
+	assert.Equal(t, 1, log.StatusCounts["2xx"])
+	tc.AssertExpectations(t)
+}
+
+func TestWebrootService_AccessLog_WorkflowError(t *testing.T) {
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(&mockDB{}, tc)
+	ctx := context.Background()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Return(errors.New("activity schedule-to-start timeout"))
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "WebrootAccessLogWorkflow", "test-webroot-1", 100).Return(wfRun, nil)
+
+	_, err := svc.AccessLog(ctx, "test-webroot-1", 100)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "access log for webroot test-webroot-1")
+}
+
+// ---------- Basic auth ----------
+
+func TestWebrootService_GetBasicAuth_SortsUsernames(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*map[string]string)) = map[string]string{"bob": "$2a$10$x", "alice": "$2a$10$y"}
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+	result, err := svc.GetBasicAuth(ctx, "test-webroot-1")
+	require.NoError(t, err)
+	assert.True(t, result.Enabled)
+	assert.Equal(t, []string{"alice", "bob"}, result.Usernames)
+}
+
+func TestWebrootService_GetBasicAuth_Disabled(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*map[string]string)) = map[string]string{}
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+	result, err := svc.GetBasicAuth(ctx, "test-webroot-1")
+	require.NoError(t, err)
+	assert.False(t, result.Enabled)
+	assert.Empty(t, result.Usernames)
+}
+
+func TestWebrootService_SetBasicAuth_StoresOnlyHashes(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(db, tc)
+	ctx := context.Background()
+
+	var stored map[string]string
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-tenant-1"
+		return nil
+	}}
+	db.On("QueryRow", ctx, mock.MatchedBy(func(q string) bool { return strings.Contains(q, "basic_auth") }), mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(2).([]any)[0].(map[string]string) }).
+		Return(row)
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row).Maybe()
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil).Maybe()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id").Maybe()
+	wfRun.On("GetRunID").Return("mock-run-id").Maybe()
+	tc.On("ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil).Maybe()
+	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil).Maybe()
+
+	err := svc.SetBasicAuth(ctx, "test-webroot-1", []model.WebrootBasicAuthUser{
+		{Username: "alice", Password: "correct horse"},
+		{Username: "bob", PasswordHash: "$2y$10$existinghashexistinghashexistinghashexistinghashexi"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, stored, 2)
+	assert.NotEqual(t, "correct horse", stored["alice"])
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored["alice"]), []byte("correct horse")))
+	assert.Equal(t, "$2y$10$existinghashexistinghashexistinghashexistinghashexi", stored["bob"])
+}
+
+func TestWebrootService_SetBasicAuth_NotFound(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	row := &mockRow{scanFunc: func(dest ...any) error {
+		return errors.New("no rows in result set")
+	}}
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(row)
+
+	err := svc.SetBasicAuth(ctx, "missing", nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "set basic auth for webroot missing")
+}
+
+// --- Connection limits ---
+
+func limitMaximumsRow(shardID string, maxConns, maxRate int) *mockRow {
+	return &mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "test-tenant-1"
+		*(dest[1].(**string)) = &shardID
+		*(dest[2].(*int)) = maxConns
+		*(dest[3].(*int)) = maxRate
+		return nil
+	}}
+}
+
+func TestWebrootService_SetConnectionLimits_AboveBrandMax(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("JOIN brands b"), mock.Anything).Return(limitMaximumsRow("web-1", 20, 0))
+
+	err := svc.SetConnectionLimits(ctx, "test-webroot-1", model.WebrootConnectionLimits{MaxConnsPerIP: 50})
+	require.ErrorIs(t, err, ErrConnectionLimitAboveBrandMax)
+	assert.Contains(t, err.Error(), "max_conns_per_ip 50 is above 20")
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebrootService_SetConnectionLimits_ZoneBudgetExceeded(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("JOIN brands b"), mock.Anything).Return(limitMaximumsRow("web-1", 0, 0))
+	db.On("QueryRow", ctx, queryContaining("connection_limits->>"), mock.Anything).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*int)) = model.WebrootLimitZoneBudgetKB/model.WebrootLimitZoneKB - 1
+			return nil
+		}})
+
+	err := svc.SetConnectionLimits(ctx, "test-webroot-1", model.WebrootConnectionLimits{MaxConnsPerIP: 10, RequestsPerSecond: 5})
+	assert.ErrorIs(t, err, ErrLimitZoneBudgetExceeded)
+}
+
+func TestWebrootService_SetConnectionLimits_Stores(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("JOIN brands b"), mock.Anything).Return(limitMaximumsRow("web-1", 20, 50))
+	db.On("QueryRow", ctx, queryContaining("connection_limits->>"), mock.Anything).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*int)) = 3
+			return nil
+		}})
+	var stored model.WebrootConnectionLimits
+	db.On("Exec", ctx, queryContaining("SET connection_limits"), mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(2).([]any)[0].(model.WebrootConnectionLimits) }).
+		Return(pgconn.CommandTag{}, nil)
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(&mockRow{}).Maybe()
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil).Maybe()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id").Maybe()
+	wfRun.On("GetRunID").Return("mock-run-id").Maybe()
+	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil).Maybe()
+
+	limits := model.WebrootConnectionLimits{MaxConnsPerIP: 20, RequestsPerSecond: 10, Burst: 20}
+	require.NoError(t, svc.SetConnectionLimits(ctx, "test-webroot-1", limits))
+	assert.Equal(t, limits, stored)
+}
+
+func TestWebrootService_SetStaticRules_Invalid(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, &temporalmocks.Client{})
+
+	err := svc.SetStaticRules(context.Background(), "test-webroot-1", model.WebrootStaticRules{
+		CacheRules: []model.WebrootCacheRule{{Pattern: "*.css", CacheControl: `public"; evil`}},
+	})
+	require.ErrorIs(t, err, ErrInvalidStaticRules)
+	db.AssertNotCalled(t, "QueryRow", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebrootService_SetStaticRules_Stores(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(db, tc)
+	ctx := context.Background()
+
+	var stored model.WebrootStaticRules
+	db.On("QueryRow", ctx, queryContaining("SET static_rules"), mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(2).([]any)[0].(model.WebrootStaticRules) }).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "test-tenant-1"
+			return nil
+		}})
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(&mockRow{}).Maybe()
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil).Maybe()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id").Maybe()
+	wfRun.On("GetRunID").Return("mock-run-id").Maybe()
+	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil).Maybe()
+
+	rules := model.WebrootStaticRules{
+		CacheRules: []model.WebrootCacheRule{{Pattern: "/assets/", CacheControl: "public, max-age=31536000, immutable"}},
+		MimeTypes:  map[string]string{"wasm": "application/wasm"},
+	}
+	require.NoError(t, svc.SetStaticRules(ctx, "test-webroot-1", rules))
+	assert.Equal(t, rules, stored)
+}
+
+func TestWebrootService_SetGeoBlocking_Invalid(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, &temporalmocks.Client{})
+
+	err := svc.SetGeoBlocking(context.Background(), "test-webroot-1", model.WebrootGeoBlocking{
+		Mode: model.GeoBlockingDeny, Countries: []string{"ZZ"},
+	})
+	require.ErrorIs(t, err, ErrInvalidGeoBlocking)
+	db.AssertNotCalled(t, "QueryRow", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebrootService_SetGeoBlocking_Stores(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(db, tc)
+	ctx := context.Background()
+
+	var stored model.WebrootGeoBlocking
+	db.On("QueryRow", ctx, queryContaining("SET geo_blocking"), mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(2).([]any)[0].(model.WebrootGeoBlocking) }).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "test-tenant-1"
+			return nil
+		}})
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), mock.Anything).Return(&mockRow{}).Maybe()
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil).Maybe()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id").Maybe()
+	wfRun.On("GetRunID").Return("mock-run-id").Maybe()
+	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil).Maybe()
+
+	geo := model.WebrootGeoBlocking{Mode: model.GeoBlockingAllow, Countries: []string{"NO", "SE"}}
+	require.NoError(t, svc.SetGeoBlocking(ctx, "test-webroot-1", geo))
+	assert.Equal(t, geo, stored)
+}
+
+func TestWebrootService_SetDeployLock_InvalidTTL(t *testing.T) {
+	db := &mockDB{}
+	svc := NewWebrootService(db, &temporalmocks.Client{})
+
+	for _, ttl := range []time.Duration{0, -time.Second, model.WebrootDeployLockMaxTTL + time.Second} {
+		_, err := svc.SetDeployLock(context.Background(), "test-webroot-1", ttl)
+		require.ErrorIs(t, err, ErrInvalidDeployLockTTL)
+	}
+	db.AssertNotCalled(t, "QueryRow", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebrootService_SetDeployLock_StartsWorkflow(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(db, tc)
+	ctx := context.Background()
+
+	var stored time.Time
+	db.On("QueryRow", ctx, queryContaining("SET deploy_locked_until"), mock.Anything).
+		Run(func(args mock.Arguments) { stored = args.Get(2).([]any)[0].(time.Time) }).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "test-tenant-1"
+			return nil
+		}})
+
+	var params WebrootDeployLockParams
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "WebrootDeployLockWorkflow", mock.Anything).
+		Run(func(args mock.Arguments) { params = args.Get(3).(WebrootDeployLockParams) }).
+		Return(wfRun, nil)
+
+	lock, err := svc.SetDeployLock(ctx, "test-webroot-1", 30*time.Second)
+	require.NoError(t, err)
+	require.NotNil(t, lock.LockedUntil)
+	assert.Equal(t, stored, *lock.LockedUntil)
+	assert.Equal(t, WebrootDeployLockParams{WebrootID: "test-webroot-1", Until: stored}, params)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), stored, 5*time.Second)
+	tc.AssertExpectations(t)
+}
+
+func TestWebrootService_ClearDeployLock(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewWebrootService(db, tc)
+	ctx := context.Background()
+
+	db.On("QueryRow", ctx, queryContaining("SET deploy_locked_until = NULL"), mock.Anything).
+		Return(&mockRow{scanFunc: func(dest ...any) error {
+			*(dest[0].(*string)) = "test-tenant-1"
+			return nil
+		}})
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "UpdateWebrootWorkflow", "test-webroot-1").Return(wfRun, nil)
+
+	require.NoError(t, svc.ClearDeployLock(ctx, "test-webroot-1"))
+	tc.AssertExpectations(t)
+}
@@ -99,42 +99,7 @@ func (s *WireGuardPeerService) Create(ctx context.Context, peer *model.WireGuard
 	}
 
 	// Build service metadata comments for CLI tool.
-	var serviceLines string
-	type svcRow struct {
-		svcType   string
-		shardRole string
-		shardIdx  int
-	}
-	var services []svcRow
-	rows, err := s.db.Query(ctx, `
-		SELECT 'mysql' AS svc_type, s.role, nsa.shard_index
-		FROM databases d
-		JOIN shards s ON s.id = d.shard_id
-		JOIN node_shard_assignments nsa ON nsa.shard_id = d.shard_id AND nsa.shard_index = 1
-		WHERE d.tenant_id = $1 AND d.status NOT IN ('deleting', 'deleted', 'failed')
-		UNION ALL
-		SELECT 'valkey' AS svc_type, s.role, nsa.shard_index
-		FROM valkey_instances v
-		JOIN shards s ON s.id = v.shard_id
-		JOIN node_shard_assignments nsa ON nsa.shard_id = v.shard_id AND nsa.shard_index = 1
-		WHERE v.tenant_id = $1 AND v.status NOT IN ('deleting', 'deleted', 'failed')
-	`, peer.TenantID)
-	if err == nil {
-		defer rows.Close()
-		for rows.Next() {
-			var sr svcRow
-			if err := rows.Scan(&sr.svcType, &sr.shardRole, &sr.shardIdx); err == nil {
-				services = append(services, sr)
-			}
-		}
-	}
-	if len(services) > 0 {
-		serviceLines = "\n# hosting-cli:services\n"
-		for _, sr := range services {
-			ula := ComputeTenantULA(clusterID, TransitIndex(sr.shardRole, sr.shardIdx), tenantUID)
-			serviceLines += fmt.Sprintf("# %s=%s\n", sr.svcType, ula)
-		}
-	}
+	serviceLines := wireGuardServiceLines(ctx, s.db, clusterID, peer.TenantID, tenantUID)
 
 	// Build client config.
 	clientConfig := fmt.Sprintf(`[Interface]
@@ -165,6 +130,55 @@ PersistentKeepalive = 25
 	}, nil
 }
 
+// wireGuardServiceLines returns the hosting-cli service comments for a client
+// config: the tenant's ULA address on the primary of each shard it has a
+// database or Valkey instance on. The primary is the shard's
+// config.primary_node_id, falling back to the shard's first node. Lookup
+// errors leave the comments out.
+func wireGuardServiceLines(ctx context.Context, db DB, clusterID, tenantID string, tenantUID int) string {
+	type svcRow struct {
+		svcType   string
+		shardRole string
+		shardIdx  int
+	}
+	var services []svcRow
+	rows, err := db.Query(ctx, `
+		SELECT 'mysql' AS svc_type, s.role, nsa.shard_index
+		FROM databases d
+		JOIN shards s ON s.id = d.shard_id
+		JOIN node_shard_assignments nsa ON nsa.shard_id = d.shard_id
+			AND (nsa.node_id = s.config->>'primary_node_id'
+				OR (COALESCE(s.config->>'primary_node_id', '') = '' AND nsa.shard_index = 1))
+		WHERE d.tenant_id = $1 AND d.status NOT IN ('deleting', 'deleted', 'failed')
+		UNION ALL
+		SELECT 'valkey' AS svc_type, s.role, nsa.shard_index
+		FROM valkey_instances v
+		JOIN shards s ON s.id = v.shard_id
+		JOIN node_shard_assignments nsa ON nsa.shard_id = v.shard_id
+			AND (nsa.node_id = s.config->>'primary_node_id'
+				OR (COALESCE(s.config->>'primary_node_id', '') = '' AND nsa.shard_index = 1))
+		WHERE v.tenant_id = $1 AND v.status NOT IN ('deleting', 'deleted', 'failed')
+	`, tenantID)
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var sr svcRow
+			if err := rows.Scan(&sr.svcType, &sr.shardRole, &sr.shardIdx); err == nil {
+				services = append(services, sr)
+			}
+		}
+	}
+	if len(services) == 0 {
+		return ""
+	}
+	lines := "\n# hosting-cli:services\n"
+	for _, sr := range services {
+		ula := ComputeTenantULA(clusterID, TransitIndex(sr.shardRole, sr.shardIdx), tenantUID)
+		lines += fmt.Sprintf("# %s=%s\n", sr.svcType, ula)
+	}
+	return lines
+}
+
 func (s *WireGuardPeerService) GetByID(ctx context.Context, id string) (*model.WireGuardPeer, error) {
 	var p model.WireGuardPeer
 	err := s.db.QueryRow(ctx,
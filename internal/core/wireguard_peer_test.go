@@ -0,0 +1,54 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func serviceShardRow(svcType, role string, shardIndex int) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = svcType
+		*(dest[1].(*string)) = role
+		*(dest[2].(*int)) = shardIndex
+		return nil
+	}
+}
+
+func TestWireGuardServiceLines_UsesShardPrimary(t *testing.T) {
+	db := &mockDB{}
+	ctx := context.Background()
+
+	// A promoted replica: the primary is the shard's second node.
+	rows := newMockRows(
+		serviceShardRow("mysql", "database", 2),
+		serviceShardRow("valkey", "valkey", 1),
+	)
+	db.On("Query", ctx, queryContaining("primary_node_id"), []any{"tenant-1"}).Return(rows, nil)
+
+	lines := wireGuardServiceLines(ctx, db, "cluster-1", "tenant-1", 5000)
+
+	assert.Equal(t, "\n# hosting-cli:services\n"+
+		"# mysql="+ComputeTenantULA("cluster-1", TransitIndex("database", 2), 5000)+"\n"+
+		"# valkey="+ComputeTenantULA("cluster-1", TransitIndex("valkey", 1), 5000)+"\n", lines)
+	db.AssertExpectations(t)
+}
+
+func TestWireGuardServiceLines_NoServices(t *testing.T) {
+	db := &mockDB{}
+	ctx := context.Background()
+
+	db.On("Query", ctx, mock.AnythingOfType("string"), []any{"tenant-1"}).Return(newEmptyMockRows(), nil)
+	assert.Empty(t, wireGuardServiceLines(ctx, db, "cluster-1", "tenant-1", 5000))
+}
+
+func TestWireGuardServiceLines_QueryError(t *testing.T) {
+	db := &mockDB{}
+	ctx := context.Background()
+
+	db.On("Query", ctx, mock.AnythingOfType("string"), []any{"tenant-1"}).Return(nil, errors.New("db down"))
+	assert.Empty(t, wireGuardServiceLines(ctx, db, "cluster-1", "tenant-1", 5000))
+}
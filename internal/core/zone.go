@@ -29,10 +29,16 @@ func (s *ZoneService) Create(ctx context.Context, zone *model.Zone) error {
 		zone.BrandID = brandID
 	}
 
-	_, err := s.db.Exec(ctx,
-		`INSERT INTO zones (id, brand_id, tenant_id, subscription_id, name, region_id, status, created_at, updated_at)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
-		zone.ID, zone.BrandID, zone.TenantID, zone.SubscriptionID, zone.Name, zone.RegionID, zone.Status,
+	secondaryRegionIDs, err := s.validateSecondaryRegions(ctx, zone.RegionID, zone.SecondaryRegionIDs)
+	if err != nil {
+		return err
+	}
+	zone.SecondaryRegionIDs = secondaryRegionIDs
+
+	_, err = s.db.Exec(ctx,
+		`INSERT INTO zones (id, brand_id, tenant_id, subscription_id, name, region_id, secondary_region_ids, status, created_at, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		zone.ID, zone.BrandID, zone.TenantID, zone.SubscriptionID, zone.Name, zone.RegionID, zone.SecondaryRegionIDs, zone.Status,
 		zone.CreatedAt, zone.UpdatedAt,
 	)
 	if err != nil {
@@ -54,14 +60,14 @@ func (s *ZoneService) GetByID(ctx context.Context, id string) (*model.Zone, erro
 	var z model.Zone
 	err := s.db.QueryRow(ctx,
 		`SELECT z.id, z.brand_id, z.tenant_id, z.subscription_id, z.name, z.region_id, z.status, z.status_message, z.suspend_reason, z.created_at, z.updated_at,
-		        r.name, t.name
+		        r.name, t.name, z.dnssec_status, z.secondary_region_ids
 		 FROM zones z
 		 JOIN regions r ON r.id = z.region_id
 		 LEFT JOIN tenants t ON t.id = z.tenant_id
 		 WHERE z.id = $1`, id,
 	).Scan(&z.ID, &z.BrandID, &z.TenantID, &z.SubscriptionID, &z.Name, &z.RegionID, &z.Status, &z.StatusMessage, &z.SuspendReason,
 		&z.CreatedAt, &z.UpdatedAt,
-		&z.RegionName, &z.TenantName)
+		&z.RegionName, &z.TenantName, &z.DNSSECStatus, &z.SecondaryRegionIDs)
 	if err != nil {
 		return nil, fmt.Errorf("get zone %s: %w", id, err)
 	}
@@ -69,7 +75,7 @@ func (s *ZoneService) GetByID(ctx context.Context, id string) (*model.Zone, erro
 }
 
 func (s *ZoneService) List(ctx context.Context, params request.ListParams) ([]model.Zone, bool, error) {
-	query := `SELECT z.id, z.brand_id, z.tenant_id, z.subscription_id, z.name, z.region_id, z.status, z.status_message, z.suspend_reason, z.created_at, z.updated_at, r.name, t.name FROM zones z JOIN regions r ON r.id = z.region_id LEFT JOIN tenants t ON t.id = z.tenant_id WHERE true`
+	query := `SELECT z.id, z.brand_id, z.tenant_id, z.subscription_id, z.name, z.region_id, z.status, z.status_message, z.suspend_reason, z.created_at, z.updated_at, r.name, t.name, z.dnssec_status, z.secondary_region_ids FROM zones z JOIN regions r ON r.id = z.region_id LEFT JOIN tenants t ON t.id = z.tenant_id WHERE true`
 	args := []any{}
 	argIdx := 1
 
@@ -93,6 +99,11 @@ func (s *ZoneService) List(ctx context.Context, params request.ListParams) ([]mo
 		args = append(args, params.BrandIDs)
 		argIdx++
 	}
+	if params.ResellerID != "" {
+		query += fmt.Sprintf(` AND t.reseller_id = $%d`, argIdx)
+		args = append(args, params.ResellerID)
+		argIdx++
+	}
 
 	sortCol := "z.created_at"
 	switch params.Sort {
@@ -122,7 +133,7 @@ func (s *ZoneService) List(ctx context.Context, params request.ListParams) ([]mo
 		var z model.Zone
 		if err := rows.Scan(&z.ID, &z.BrandID, &z.TenantID, &z.SubscriptionID, &z.Name, &z.RegionID, &z.Status, &z.StatusMessage, &z.SuspendReason,
 			&z.CreatedAt, &z.UpdatedAt,
-			&z.RegionName, &z.TenantName); err != nil {
+			&z.RegionName, &z.TenantName, &z.DNSSECStatus, &z.SecondaryRegionIDs); err != nil {
 			return nil, false, fmt.Errorf("scan zone: %w", err)
 		}
 		zones = append(zones, z)
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ErrDNSSECState is returned when a zone's state doesn't allow the requested
+// DNSSEC change, e.g. signing a zone that is already signed.
+var ErrDNSSECState = errors.New("zone dnssec state does not allow this change")
+
+// Sign enables DNSSEC for an active, unsigned zone. SignZoneWorkflow
+// generates the keys; the DS record is available from GetDNSSEC once the
+// zone is signed. A zone whose last signing change failed can be signed
+// again.
+func (s *ZoneService) Sign(ctx context.Context, zone *model.Zone) error {
+	if zone.Status != model.StatusActive {
+		return fmt.Errorf("%w: zone is %s", ErrDNSSECState, zone.Status)
+	}
+	return s.changeDNSSEC(ctx, zone, model.DNSSECSigning, "SignZoneWorkflow",
+		model.DNSSECUnsigned, model.DNSSECFailed)
+}
+
+// Unsign disables DNSSEC for a signed zone. The DS record must be removed at
+// the registrar first.
+func (s *ZoneService) Unsign(ctx context.Context, zone *model.Zone) error {
+	return s.changeDNSSEC(ctx, zone, model.DNSSECUnsigning, "UnsignZoneWorkflow",
+		model.DNSSECSigned, model.DNSSECFailed)
+}
+
+// changeDNSSEC moves a zone to a transitional DNSSEC status, if it is in
+// one of the allowed statuses, and starts the workflow completing the change.
+func (s *ZoneService) changeDNSSEC(ctx context.Context, zone *model.Zone, status, workflowName string, from ...string) error {
+	tag, err := s.db.Exec(ctx,
+		`UPDATE zones SET dnssec_status = $1, updated_at = now() WHERE id = $2 AND dnssec_status = ANY($3)`,
+		status, zone.ID, from,
+	)
+	if err != nil {
+		return fmt.Errorf("set zone %s dnssec status: %w", zone.ID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: dnssec is %s", ErrDNSSECState, zone.DNSSECStatus)
+	}
+	zone.DNSSECStatus = status
+
+	if err := signalProvision(ctx, s.tc, s.db, zone.TenantID, model.ProvisionTask{
+		WorkflowName: workflowName,
+		WorkflowID:   workflowID("zone-dnssec", zone.ID),
+		Arg:          zone.ID,
+	}); err != nil {
+		return fmt.Errorf("signal %s: %w", workflowName, err)
+	}
+	return nil
+}
+
+// GetDNSSEC returns a zone's DNSSEC status and keys. DS holds the records
+// of the published KSKs, to be added at the registrar.
+func (s *ZoneService) GetDNSSEC(ctx context.Context, zone *model.Zone) (*model.ZoneDNSSEC, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT pdns_key_id, key_type, key_tag, algorithm, dnskey, ds, active, published, created_at
+		 FROM zone_dnssec_keys WHERE zone_id = $1 ORDER BY key_type, pdns_key_id`, zone.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list dnssec keys of zone %s: %w", zone.ID, err)
+	}
+	defer rows.Close()
+
+	result := &model.ZoneDNSSEC{
+		ZoneID: zone.ID,
+		Status: zone.DNSSECStatus,
+		DS:     []string{},
+		Keys:   []model.ZoneDNSSECKey{},
+	}
+	for rows.Next() {
+		var k model.ZoneDNSSECKey
+		if err := rows.Scan(&k.ID, &k.KeyType, &k.KeyTag, &k.Algorithm, &k.DNSKEY, &k.DS,
+			&k.Active, &k.Published, &k.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan dnssec key: %w", err)
+		}
+		if k.KeyType == model.DNSSECKeyKSK && k.Published && k.DS != nil {
+			result.DS = append(result.DS, *k.DS)
+		}
+		result.Keys = append(result.Keys, k)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate dnssec keys: %w", err)
+	}
+	return result, nil
+}
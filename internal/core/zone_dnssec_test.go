@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func testDNSSECZone(dnssecStatus string) *model.Zone {
+	return &model.Zone{
+		ID:           "test-zone-1",
+		TenantID:     "test-tenant-1",
+		Name:         "example.com",
+		Status:       model.StatusActive,
+		DNSSECStatus: dnssecStatus,
+	}
+}
+
+func TestZoneService_Sign_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneService(db, tc)
+	ctx := context.Background()
+	zone := testDNSSECZone(model.DNSSECUnsigned)
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"),
+		[]any{model.DNSSECSigning, "test-zone-1", []string{model.DNSSECUnsigned, model.DNSSECFailed}}).
+		Return(pgconn.NewCommandTag("UPDATE 1"), nil).Once()
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id")
+	wfRun.On("GetRunID").Return("mock-run-id")
+	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	require.NoError(t, svc.Sign(ctx, zone))
+	assert.Equal(t, model.DNSSECSigning, zone.DNSSECStatus)
+	tc.AssertExpectations(t)
+}
+
+func TestZoneService_Sign_AlreadySigned(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneService(db, tc)
+	ctx := context.Background()
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.NewCommandTag("UPDATE 0"), nil)
+
+	err := svc.Sign(ctx, testDNSSECZone(model.DNSSECSigned))
+	assert.ErrorIs(t, err, ErrDNSSECState)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestZoneService_Sign_ZoneNotActive(t *testing.T) {
+	db := &mockDB{}
+	svc := NewZoneService(db, &temporalmocks.Client{})
+	zone := testDNSSECZone(model.DNSSECUnsigned)
+	zone.Status = model.StatusProvisioning
+
+	err := svc.Sign(context.Background(), zone)
+	assert.ErrorIs(t, err, ErrDNSSECState)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestZoneService_Unsign_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneService(db, tc)
+	ctx := context.Background()
+	zone := testDNSSECZone(model.DNSSECSigned)
+
+	db.On("Exec", ctx, mock.AnythingOfType("string"),
+		[]any{model.DNSSECUnsigning, "test-zone-1", []string{model.DNSSECSigned, model.DNSSECFailed}}).
+		Return(pgconn.NewCommandTag("UPDATE 1"), nil).Once()
+	db.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil)
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("GetID").Return("mock-wf-id")
+	wfRun.On("GetRunID").Return("mock-run-id")
+	tc.On("SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(wfRun, nil)
+
+	require.NoError(t, svc.Unsign(ctx, zone))
+	assert.Equal(t, model.DNSSECUnsigning, zone.DNSSECStatus)
+}
+
+func TestZoneService_GetDNSSEC(t *testing.T) {
+	db := &mockDB{}
+	svc := NewZoneService(db, &temporalmocks.Client{})
+	ctx := context.Background()
+	now := time.Now()
+	ds := "55648 13 2 B4C8C1FE2E7477127B27115656AD6256F424625BF5C1E2770CE6D6E37DF61D17"
+
+	key := func(id int, keyType string, ds *string, active bool) func(dest ...any) error {
+		return func(dest ...any) error {
+			*(dest[0].(*int)) = id
+			*(dest[1].(*string)) = keyType
+			*(dest[2].(*int)) = 1000 + id
+			*(dest[3].(*int)) = 13
+			*(dest[4].(*string)) = "256 3 13 AAAA"
+			*(dest[5].(**string)) = ds
+			*(dest[6].(*bool)) = active
+			*(dest[7].(*bool)) = true
+			*(dest[8].(*time.Time)) = now
+			return nil
+		}
+	}
+	rows := newMockRows(
+		key(1, model.DNSSECKeyKSK, &ds, true),
+		key(2, model.DNSSECKeyZSK, nil, true),
+		key(3, model.DNSSECKeyZSK, nil, false),
+	)
+	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+
+	result, err := svc.GetDNSSEC(ctx, testDNSSECZone(model.DNSSECSigned))
+	require.NoError(t, err)
+	assert.Equal(t, model.DNSSECSigned, result.Status)
+	assert.Equal(t, []string{ds}, result.DS)
+	require.Len(t, result.Keys, 3)
+	assert.False(t, result.Keys[2].Active)
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// Export returns the zone's SOA and NS records, built from the brand's
+// nameservers and hostmaster or its SOA and NS templates the same way
+// CreateZoneWorkflow writes them, followed by the zone's custom, template and
+// auto-managed records.
+func (s *ZoneService) Export(ctx context.Context, zone *model.Zone) (*model.ZoneExport, error) {
+	var brand model.Brand
+	err := s.db.QueryRow(ctx,
+		`SELECT base_hostname, primary_ns, secondary_ns, hostmaster_email, mail_hostname, dkim_selector, dkim_public_key, dmarc_policy
+		 FROM brands WHERE id = $1`, zone.BrandID,
+	).Scan(&brand.BaseHostname, &brand.PrimaryNS, &brand.SecondaryNS, &brand.HostmasterEmail,
+		&brand.MailHostname, &brand.DKIMSelector, &brand.DKIMPublicKey, &brand.DMARCPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("get brand %s: %w", zone.BrandID, err)
+	}
+
+	apex, err := s.exportApexRecords(ctx, zone, brand)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(ctx,
+		`SELECT type, name, content, ttl, priority, managed_by FROM zone_records
+		 WHERE zone_id = $1 AND status NOT IN ($2, $3) ORDER BY name, type, content`,
+		zone.ID, model.StatusDeleting, model.StatusDeleted,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list zone records for zone %s: %w", zone.ID, err)
+	}
+	defer rows.Close()
+
+	export := &model.ZoneExport{Zone: zone.Name, Records: apex}
+	for rows.Next() {
+		var r model.ZoneExportRecord
+		if err := rows.Scan(&r.Type, &r.Name, &r.Content, &r.TTL, &r.Priority, &r.ManagedBy); err != nil {
+			return nil, fmt.Errorf("scan zone record: %w", err)
+		}
+		r.PlatformManaged = r.ManagedBy == model.ManagedByAuto
+		export.Records = append(export.Records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate zone records: %w", err)
+	}
+	return export, nil
+}
+
+// exportApexRecords returns the zone's SOA and NS records. Templates that
+// cannot be expanded are skipped, as they are when the zone is created.
+func (s *ZoneService) exportApexRecords(ctx context.Context, zone *model.Zone, brand model.Brand) ([]model.ZoneExportRecord, error) {
+	rows, err := s.db.Query(ctx,
+		`SELECT type, name, content, ttl FROM brand_zone_templates
+		 WHERE brand_id = $1 AND type IN ('SOA', 'NS') ORDER BY created_at, id`, zone.BrandID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list brand zone templates: %w", err)
+	}
+	defer rows.Close()
+
+	apexRecord := func(typ, content string, ttl int) model.ZoneExportRecord {
+		return model.ZoneExportRecord{
+			Type: typ, Name: zone.Name, Content: content, TTL: ttl,
+			ManagedBy: model.ManagedByBrand, PlatformManaged: true,
+		}
+	}
+	soa := apexRecord("SOA", fmt.Sprintf("%s %s 1 10800 3600 604800 300", brand.PrimaryNS, brand.HostmasterEmail), 86400)
+	var ns []model.ZoneExportRecord
+
+	vars := model.ZoneTemplateVars(zone.Name, brand)
+	for rows.Next() {
+		var t model.BrandZoneTemplate
+		if err := rows.Scan(&t.Type, &t.Name, &t.Content, &t.TTL); err != nil {
+			return nil, fmt.Errorf("scan brand zone template: %w", err)
+		}
+		content, err := model.ExpandZoneTemplate(t.Content, vars)
+		if err != nil || strings.TrimSpace(content) == "" {
+			continue
+		}
+		if t.Type == "SOA" {
+			soa = apexRecord("SOA", content, t.TTL)
+		} else {
+			ns = append(ns, apexRecord("NS", content, t.TTL))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate brand zone templates: %w", err)
+	}
+
+	if len(ns) == 0 {
+		ns = []model.ZoneExportRecord{
+			apexRecord("NS", brand.PrimaryNS, 86400),
+			apexRecord("NS", brand.SecondaryNS, 86400),
+		}
+	}
+	return append([]model.ZoneExportRecord{soa}, ns...), nil
+}
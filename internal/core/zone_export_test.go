@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func expectExportBrand(db *mockDB, ctx context.Context) {
+	db.On("QueryRow", ctx, sqlContains("FROM brands"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[1].(*string)) = "ns1.example.net"
+		*(dest[2].(*string)) = "ns2.example.net"
+		*(dest[3].(*string)) = "hostmaster.example.net"
+		return nil
+	}})
+}
+
+func zoneExportRecordRow(typ, name, content string, ttl int, managedBy string) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = typ
+		*(dest[1].(*string)) = name
+		*(dest[2].(*string)) = content
+		*(dest[3].(*int)) = ttl
+		*(dest[5].(*string)) = managedBy
+		return nil
+	}
+}
+
+func TestZoneService_Export(t *testing.T) {
+	db := &mockDB{}
+	svc := NewZoneService(db, nil)
+	ctx := context.Background()
+
+	expectExportBrand(db, ctx)
+	db.On("Query", ctx, sqlContains("FROM brand_zone_templates"), mock.Anything).Return(newMockRows(), nil)
+	db.On("Query", ctx, sqlContains("FROM zone_records"), []any{"test-zone-1", model.StatusDeleting, model.StatusDeleted}).Return(newMockRows(
+		zoneExportRecordRow("A", "example.com", "203.0.113.10", 300, model.ManagedByAuto),
+		zoneExportRecordRow("A", "www.example.com", "203.0.113.11", 3600, model.ManagedByCustom),
+	), nil)
+
+	export, err := svc.Export(ctx, testReplicatedZone())
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", export.Zone)
+	require.Len(t, export.Records, 5)
+	assert.Equal(t, model.ZoneExportRecord{
+		Type: "SOA", Name: "example.com", Content: "ns1.example.net hostmaster.example.net 1 10800 3600 604800 300", TTL: 86400,
+		ManagedBy: model.ManagedByBrand, PlatformManaged: true,
+	}, export.Records[0])
+	assert.Equal(t, "ns2.example.net", export.Records[2].Content)
+	assert.True(t, export.Records[3].PlatformManaged)
+	assert.False(t, export.Records[4].PlatformManaged)
+}
+
+func TestZoneService_Export_Templates(t *testing.T) {
+	db := &mockDB{}
+	svc := NewZoneService(db, nil)
+	ctx := context.Background()
+
+	template := func(typ, content string) func(dest ...any) error {
+		return func(dest ...any) error {
+			*(dest[0].(*string)) = typ
+			*(dest[1].(*string)) = "{zone}"
+			*(dest[2].(*string)) = content
+			*(dest[3].(*int)) = 3600
+			return nil
+		}
+	}
+	expectExportBrand(db, ctx)
+	db.On("Query", ctx, sqlContains("FROM brand_zone_templates"), mock.Anything).Return(newMockRows(
+		template("SOA", "{primary_ns} dns.{zone} 1 7200 3600 604800 60"),
+		template("NS", "ns.{zone}"),
+		template("NS", "{unknown}"),
+	), nil)
+	db.On("Query", ctx, sqlContains("FROM zone_records"), mock.Anything).Return(newMockRows(), nil)
+
+	export, err := svc.Export(ctx, testReplicatedZone())
+	require.NoError(t, err)
+	require.Len(t, export.Records, 2)
+	assert.Equal(t, "ns1.example.net dns.example.com 1 7200 3600 604800 60", export.Records[0].Content)
+	assert.Equal(t, "ns.example.com", export.Records[1].Content)
+}
@@ -2,12 +2,20 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/edvin/hosting/internal/model"
+	"github.com/edvin/hosting/internal/platform"
+	"github.com/jackc/pgx/v5"
 	temporalclient "go.temporal.io/sdk/client"
 )
 
+// ErrZoneRecordsBusy is returned by ReplaceRecords and PreviewRecords while a
+// record of the zone still has a change in flight.
+var ErrZoneRecordsBusy = errors.New("zone has record changes in progress")
+
 type ZoneRecordService struct {
 	db DB
 	tc temporalclient.Client
@@ -114,6 +122,77 @@ func (s *ZoneRecordService) ListByZone(ctx context.Context, zoneID string, limit
 	return records, hasMore, nil
 }
 
+// ZoneRecordSearchFilters holds the filters of a tenant-wide record search.
+// Type and Content match exactly; Name matches any part of the record name.
+type ZoneRecordSearchFilters struct {
+	Type    string
+	Name    string
+	Content string
+}
+
+// SearchByTenant finds records across all of a tenant's zones in a single
+// query, returning each match with its zone name. Deleted records and
+// records of deleted zones are skipped.
+func (s *ZoneRecordService) SearchByTenant(ctx context.Context, tenantID string, filters ZoneRecordSearchFilters, limit int, cursor string) ([]model.ZoneRecordSearchResult, bool, error) {
+	query := `SELECT r.id, r.zone_id, r.type, r.name, r.content, r.ttl, r.priority, r.managed_by, r.source_type, r.source_fqdn_id, r.status, r.status_message, r.created_at, r.updated_at, z.name
+		FROM zone_records r JOIN zones z ON z.id = r.zone_id
+		WHERE z.tenant_id = $1 AND z.status != $2 AND r.status != $2`
+	args := []any{tenantID, model.StatusDeleted}
+	argIdx := 3
+
+	if filters.Type != "" {
+		query += fmt.Sprintf(` AND r.type = $%d`, argIdx)
+		args = append(args, strings.ToUpper(filters.Type))
+		argIdx++
+	}
+	if filters.Name != "" {
+		query += fmt.Sprintf(` AND r.name ILIKE $%d`, argIdx)
+		args = append(args, "%"+filters.Name+"%")
+		argIdx++
+	}
+	if filters.Content != "" {
+		query += fmt.Sprintf(` AND r.content = $%d`, argIdx)
+		args = append(args, filters.Content)
+		argIdx++
+	}
+	if cursor != "" {
+		query += fmt.Sprintf(` AND r.id > $%d`, argIdx)
+		args = append(args, cursor)
+		argIdx++
+	}
+
+	query += ` ORDER BY r.id`
+	query += fmt.Sprintf(` LIMIT $%d`, argIdx)
+	args = append(args, limit+1)
+
+	rows, err := s.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("search zone records for tenant %s: %w", tenantID, err)
+	}
+	defer rows.Close()
+
+	var results []model.ZoneRecordSearchResult
+	for rows.Next() {
+		var m model.ZoneRecordSearchResult
+		r := &m.ZoneRecord
+		if err := rows.Scan(&r.ID, &r.ZoneID, &r.Type, &r.Name, &r.Content,
+			&r.TTL, &r.Priority, &r.ManagedBy, &r.SourceType, &r.SourceFQDNID,
+			&r.Status, &r.StatusMessage, &r.CreatedAt, &r.UpdatedAt, &m.ZoneName); err != nil {
+			return nil, false, fmt.Errorf("scan zone record: %w", err)
+		}
+		results = append(results, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("iterate zone records: %w", err)
+	}
+
+	hasMore := len(results) > limit
+	if hasMore {
+		results = results[:limit]
+	}
+	return results, hasMore, nil
+}
+
 func (s *ZoneRecordService) Update(ctx context.Context, record *model.ZoneRecord) error {
 	_, err := s.db.Exec(ctx,
 		`UPDATE zone_records SET type = $1, name = $2, content = $3, ttl = $4, priority = $5, updated_at = now()
@@ -239,6 +318,243 @@ func (s *ZoneRecordService) Retry(ctx context.Context, id string) error {
 	})
 }
 
+// ReplaceRecords makes the zone's custom and template records match desired
+// and applies the difference to PowerDNS as one batch. Records are matched by
+// type, name and content: unmatched desired records are created as custom
+// records, matched records whose TTL or priority changed (or that failed
+// before) are updated, and existing records missing from desired are deleted.
+// Auto-managed records are left alone. Nothing is started if there is no
+// difference.
+//
+// The zone row is locked for the diff and the writes, so concurrent calls see
+// each other's changes as in flight, and the workflow is only signalled once
+// they are committed.
+func (s *ZoneRecordService) ReplaceRecords(ctx context.Context, zoneID string, desired []model.ZoneRecord) (*model.ZoneRecordSetChange, error) {
+	var change zoneRecordDiff
+	batch := model.ZoneRecordBatchParams{ZoneID: zoneID}
+	err := pgx.BeginFunc(ctx, s.db, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, "SELECT name FROM zones WHERE id = $1 FOR UPDATE", zoneID).Scan(&batch.ZoneName)
+		if err != nil {
+			return fmt.Errorf("lock zone %s: %w", zoneID, err)
+		}
+
+		current, err := listReplaceableRecords(ctx, tx, zoneID)
+		if err != nil {
+			return err
+		}
+
+		change = diffZoneRecords(current, desired)
+		if change.busy {
+			return ErrZoneRecordsBusy
+		}
+
+		for _, r := range change.Created {
+			_, err := tx.Exec(ctx,
+				`INSERT INTO zone_records (id, zone_id, type, name, content, ttl, priority, managed_by, source_type, source_fqdn_id, status, created_at, updated_at)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`,
+				r.ID, zoneID, r.Type, r.Name, r.Content,
+				r.TTL, r.Priority, r.ManagedBy, r.SourceType, r.SourceFQDNID,
+				r.Status, r.CreatedAt, r.UpdatedAt,
+			)
+			if err != nil {
+				return fmt.Errorf("insert zone record: %w", err)
+			}
+			batch.Writes = append(batch.Writes, zoneRecordParams(r, batch.ZoneName))
+		}
+		for _, r := range change.Updated {
+			_, err := tx.Exec(ctx,
+				`UPDATE zone_records SET ttl = $1, priority = $2, status = $3, status_message = NULL, updated_at = now()
+				 WHERE id = $4`,
+				r.TTL, r.Priority, r.Status, r.ID,
+			)
+			if err != nil {
+				return fmt.Errorf("update zone record %s: %w", r.ID, err)
+			}
+			batch.Writes = append(batch.Writes, zoneRecordParams(r, batch.ZoneName))
+		}
+		for _, r := range change.Deleted {
+			_, err := tx.Exec(ctx,
+				`UPDATE zone_records SET status = $1, updated_at = now() WHERE id = $2`,
+				model.StatusDeleting, r.ID,
+			)
+			if err != nil {
+				return fmt.Errorf("set zone record %s status to deleting: %w", r.ID, err)
+			}
+			batch.Deletes = append(batch.Deletes, zoneRecordParams(r, batch.ZoneName))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if change.Empty() {
+		return &change.ZoneRecordSetChange, nil
+	}
+
+	tenantID, err := resolveTenantIDFromZone(ctx, s.db, zoneID)
+	if err != nil {
+		return nil, fmt.Errorf("resolve tenant for zone records: %w", err)
+	}
+	if err := signalProvision(ctx, s.tc, s.db, tenantID, model.ProvisionTask{
+		WorkflowName: "ApplyZoneRecordsWorkflow",
+		WorkflowID:   workflowID("zone-records", zoneID),
+		Arg:          batch,
+	}); err != nil {
+		return nil, fmt.Errorf("signal ApplyZoneRecordsWorkflow: %w", err)
+	}
+
+	return &change.ZoneRecordSetChange, nil
+}
+
+// PreviewRecords returns the diff ReplaceRecords would apply for desired,
+// without changing anything, with the zone's SOA serial now and after the
+// change. The serial is bumped once if the diff is not empty. Like
+// ReplaceRecords it returns ErrZoneRecordsBusy while a change is in flight.
+// Created records get new IDs when applied.
+func (s *ZoneRecordService) PreviewRecords(ctx context.Context, zoneID string, desired []model.ZoneRecord) (*model.ZoneRecordSetPreview, error) {
+	current, err := listReplaceableRecords(ctx, s.db, zoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	change := diffZoneRecords(current, desired)
+	if change.busy {
+		return nil, ErrZoneRecordsBusy
+	}
+
+	zoneName, err := s.getZoneName(ctx, zoneID)
+	if err != nil {
+		return nil, fmt.Errorf("get zone name for records: %w", err)
+	}
+	run, err := s.tc.ExecuteWorkflow(ctx, temporalclient.StartWorkflowOptions{
+		ID:        workflowID("zone-soa-serial", zoneID+"-"+platform.NewID()),
+		TaskQueue: taskQueue,
+	}, "GetZoneSOASerialWorkflow", zoneName)
+	if err != nil {
+		return nil, fmt.Errorf("start GetZoneSOASerialWorkflow: %w", err)
+	}
+	var serial *uint32
+	if err := run.Get(ctx, &serial); err != nil {
+		return nil, fmt.Errorf("get soa serial of zone %s: %w", zoneName, err)
+	}
+
+	preview := &model.ZoneRecordSetPreview{
+		ZoneRecordSetChange: change.ZoneRecordSetChange,
+		CurrentSOASerial:    serial,
+		SOASerial:           serial,
+	}
+	if serial != nil && !change.Empty() {
+		next := model.NextSOASerial(*serial)
+		preview.SOASerial = &next
+	}
+	return preview, nil
+}
+
+// listReplaceableRecords returns the zone's custom and template records, the
+// ones ReplaceRecords manages.
+func listReplaceableRecords(ctx context.Context, db DB, zoneID string) ([]model.ZoneRecord, error) {
+	rows, err := db.Query(ctx,
+		`SELECT id, zone_id, type, name, content, ttl, priority, managed_by, source_type, source_fqdn_id, status, status_message, created_at, updated_at
+		 FROM zone_records WHERE zone_id = $1 AND managed_by <> $2 ORDER BY id`,
+		zoneID, model.ManagedByAuto,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list zone records for zone %s: %w", zoneID, err)
+	}
+	defer rows.Close()
+
+	var current []model.ZoneRecord
+	for rows.Next() {
+		var r model.ZoneRecord
+		if err := rows.Scan(&r.ID, &r.ZoneID, &r.Type, &r.Name, &r.Content,
+			&r.TTL, &r.Priority, &r.ManagedBy, &r.SourceType, &r.SourceFQDNID,
+			&r.Status, &r.StatusMessage, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan zone record: %w", err)
+		}
+		current = append(current, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate zone records: %w", err)
+	}
+	return current, nil
+}
+
+// zoneRecordDiff is a ZoneRecordSetChange plus whether any current record
+// still has a change in flight.
+type zoneRecordDiff struct {
+	model.ZoneRecordSetChange
+	busy bool
+}
+
+// diffZoneRecords compares the zone's current records with the desired set.
+// Created and updated records are returned with status pending.
+func diffZoneRecords(current, desired []model.ZoneRecord) zoneRecordDiff {
+	key := func(r model.ZoneRecord) string {
+		return r.Type + "\x00" + r.Name + "\x00" + r.Content
+	}
+
+	diff := zoneRecordDiff{ZoneRecordSetChange: model.ZoneRecordSetChange{
+		Created: []model.ZoneRecord{},
+		Updated: []model.ZoneRecord{},
+		Deleted: []model.ZoneRecord{},
+	}}
+	byKey := make(map[string]model.ZoneRecord, len(current))
+	for _, r := range current {
+		switch r.Status {
+		case model.StatusPending, model.StatusProvisioning, model.StatusDeleting:
+			diff.busy = true
+		}
+		byKey[key(r)] = r
+	}
+
+	matched := make(map[string]bool, len(desired))
+	for _, d := range desired {
+		k := key(d)
+		cur, ok := byKey[k]
+		if !ok {
+			d.Status = model.StatusPending
+			diff.Created = append(diff.Created, d)
+			continue
+		}
+		matched[k] = true
+		if cur.TTL == d.TTL && equalPriority(cur.Priority, d.Priority) && cur.Status == model.StatusActive {
+			diff.Unchanged++
+			continue
+		}
+		cur.TTL = d.TTL
+		cur.Priority = d.Priority
+		cur.Status = model.StatusPending
+		cur.StatusMessage = nil
+		diff.Updated = append(diff.Updated, cur)
+	}
+	for _, r := range current {
+		if !matched[key(r)] {
+			diff.Deleted = append(diff.Deleted, r)
+		}
+	}
+	return diff
+}
+
+func equalPriority(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func zoneRecordParams(r model.ZoneRecord, zoneName string) model.ZoneRecordParams {
+	return model.ZoneRecordParams{
+		RecordID:  r.ID,
+		Name:      r.Name,
+		Type:      r.Type,
+		Content:   r.Content,
+		TTL:       r.TTL,
+		Priority:  r.Priority,
+		ManagedBy: r.ManagedBy,
+		ZoneName:  zoneName,
+	}
+}
+
 // getZoneName fetches the zone name by zone ID.
 func (s *ZoneRecordService) getZoneName(ctx context.Context, zoneID string) (string, error) {
 	var name string
@@ -3,10 +3,12 @@ package core
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/edvin/hosting/internal/model"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -245,6 +247,65 @@ func TestZoneRecordService_ListByZone_QueryError(t *testing.T) {
 	db.AssertExpectations(t)
 }
 
+// ---------- SearchByTenant ----------
+
+func TestZoneRecordService_SearchByTenant(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneRecordService(db, tc)
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Microsecond)
+	row := func(id, zoneID, zoneName string) func(dest ...any) error {
+		return func(dest ...any) error {
+			*(dest[0].(*string)) = id
+			*(dest[1].(*string)) = zoneID
+			*(dest[2].(*string)) = "A"
+			*(dest[3].(*string)) = "www"
+			*(dest[4].(*string)) = "1.2.3.4"
+			*(dest[5].(*int)) = 3600
+			*(dest[7].(*string)) = model.ManagedByCustom
+			*(dest[10].(*string)) = model.StatusActive
+			*(dest[12].(*time.Time)) = now
+			*(dest[13].(*time.Time)) = now
+			*(dest[14].(*string)) = zoneName
+			return nil
+		}
+	}
+	rows := newMockRows(row("rec-1", "zone-1", "example.com"), row("rec-2", "zone-2", "example.org"), row("rec-3", "zone-2", "example.org"))
+	db.On("Query", ctx, mock.MatchedBy(func(q string) bool {
+		return strings.Contains(q, "JOIN zones z") && strings.Contains(q, "r.type = $3") &&
+			strings.Contains(q, "r.name ILIKE $4") && strings.Contains(q, "r.content = $5") && strings.Contains(q, "r.id > $6")
+	}), []any{"tenant-1", model.StatusDeleted, "A", "%ww%", "1.2.3.4", "rec-0", 3}).Return(rows, nil)
+
+	results, hasMore, err := svc.SearchByTenant(ctx, "tenant-1",
+		ZoneRecordSearchFilters{Type: "a", Name: "ww", Content: "1.2.3.4"}, 2, "rec-0")
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	require.Len(t, results, 2)
+	assert.Equal(t, "example.com", results[0].ZoneName)
+	assert.Equal(t, "zone-2", results[1].ZoneID)
+	assert.Equal(t, "example.org", results[1].ZoneName)
+	db.AssertExpectations(t)
+}
+
+func TestZoneRecordService_SearchByTenant_NoFilters(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneRecordService(db, tc)
+	ctx := context.Background()
+
+	db.On("Query", ctx, mock.MatchedBy(func(q string) bool {
+		return !strings.Contains(q, "r.type =") && !strings.Contains(q, "ILIKE")
+	}), []any{"tenant-1", model.StatusDeleted, 51}).Return(newEmptyMockRows(), nil)
+
+	results, hasMore, err := svc.SearchByTenant(ctx, "tenant-1", ZoneRecordSearchFilters{}, 50, "")
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.Empty(t, results)
+	db.AssertExpectations(t)
+}
+
 // ---------- Update ----------
 
 func TestZoneRecordService_Update_Success(t *testing.T) {
@@ -445,3 +506,250 @@ func TestZoneRecordService_Delete_WorkflowError(t *testing.T) {
 	db.AssertExpectations(t)
 	tc.AssertExpectations(t)
 }
+
+// ---------- ReplaceRecords ----------
+
+func zoneRecordScan(id, rtype, name, content string, ttl int, status string) func(dest ...any) error {
+	return func(dest ...any) error {
+		*(dest[0].(*string)) = id
+		*(dest[1].(*string)) = "test-zone-1"
+		*(dest[2].(*string)) = rtype
+		*(dest[3].(*string)) = name
+		*(dest[4].(*string)) = content
+		*(dest[5].(*int)) = ttl
+		*(dest[6].(**int)) = nil
+		*(dest[7].(*string)) = model.ManagedByCustom
+		*(dest[8].(*string)) = ""
+		*(dest[9].(**string)) = nil
+		*(dest[10].(*string)) = status
+		*(dest[11].(**string)) = nil
+		*(dest[12].(*time.Time)) = time.Now()
+		*(dest[13].(*time.Time)) = time.Now()
+		return nil
+	}
+}
+
+func TestDiffZoneRecords(t *testing.T) {
+	ten := 10
+	current := []model.ZoneRecord{
+		{ID: "keep", Type: "A", Name: "example.com", Content: "1.2.3.4", TTL: 3600, Status: model.StatusActive},
+		{ID: "ttl", Type: "A", Name: "www.example.com", Content: "1.2.3.4", TTL: 3600, Status: model.StatusActive},
+		{ID: "failed", Type: "TXT", Name: "example.com", Content: "v=spf1 -all", TTL: 3600, Status: model.StatusFailed},
+		{ID: "gone", Type: "MX", Name: "example.com", Content: "mail.example.com", TTL: 3600, Priority: &ten, Status: model.StatusActive},
+	}
+	desired := []model.ZoneRecord{
+		{ID: "new-1", Type: "A", Name: "example.com", Content: "1.2.3.4", TTL: 3600},
+		{ID: "new-2", Type: "A", Name: "www.example.com", Content: "1.2.3.4", TTL: 300},
+		{ID: "new-3", Type: "TXT", Name: "example.com", Content: "v=spf1 -all", TTL: 3600},
+		{ID: "new-4", Type: "AAAA", Name: "example.com", Content: "2001:db8::1", TTL: 3600},
+	}
+
+	diff := diffZoneRecords(current, desired)
+
+	assert.False(t, diff.busy)
+	assert.Equal(t, 1, diff.Unchanged)
+	require.Len(t, diff.Created, 1)
+	assert.Equal(t, "new-4", diff.Created[0].ID)
+	assert.Equal(t, model.StatusPending, diff.Created[0].Status)
+	require.Len(t, diff.Updated, 2)
+	assert.Equal(t, "ttl", diff.Updated[0].ID)
+	assert.Equal(t, 300, diff.Updated[0].TTL)
+	assert.Equal(t, "failed", diff.Updated[1].ID)
+	assert.Equal(t, model.StatusPending, diff.Updated[1].Status)
+	require.Len(t, diff.Deleted, 1)
+	assert.Equal(t, "gone", diff.Deleted[0].ID)
+}
+
+func TestDiffZoneRecords_InFlightIsBusy(t *testing.T) {
+	current := []model.ZoneRecord{
+		{ID: "rec-1", Type: "A", Name: "example.com", Content: "1.2.3.4", TTL: 3600, Status: model.StatusProvisioning},
+	}
+	diff := diffZoneRecords(current, nil)
+	assert.True(t, diff.busy)
+}
+
+// expectZoneLock expects ReplaceRecords to lock the zone row inside tx.
+func expectZoneLock(tx *mockTx, ctx context.Context) {
+	tx.On("QueryRow", ctx, mock.MatchedBy(func(sql string) bool { return strings.HasSuffix(sql, "FOR UPDATE") }), []any{"test-zone-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "example.com"
+		return nil
+	}}).Once()
+}
+
+func TestZoneRecordService_ReplaceRecords_Success(t *testing.T) {
+	db := &mockDB{}
+	tx := &mockTx{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneRecordService(db, tc)
+	ctx := context.Background()
+
+	db.On("Begin", ctx).Return(tx, nil)
+	expectZoneLock(tx, ctx)
+	rows := newMockRows(
+		zoneRecordScan("rec-old", "A", "www.example.com", "10.0.0.1", 3600, model.StatusActive),
+	)
+	tx.On("Query", ctx, mock.AnythingOfType("string"), []any{"test-zone-1", model.ManagedByAuto}).Return(rows, nil)
+	tx.On("Exec", ctx, mock.AnythingOfType("string"), mock.Anything).Return(pgconn.CommandTag{}, nil).Twice()
+	tx.On("Commit", ctx).Return(nil)
+	tx.On("Rollback", ctx).Return(pgx.ErrTxClosed)
+
+	// resolveTenantIDFromZone (empty tenant => signalProvision uses ExecuteWorkflow)
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-zone-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = ""
+		return nil
+	}}).Once()
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	tc.On("ExecuteWorkflow", mock.Anything, mock.Anything, "ApplyZoneRecordsWorkflow", mock.MatchedBy(func(p model.ZoneRecordBatchParams) bool {
+		return p.ZoneName == "example.com" &&
+			len(p.Writes) == 1 && p.Writes[0].RecordID == "rec-new" && p.Writes[0].Content == "10.0.0.2" &&
+			len(p.Deletes) == 1 && p.Deletes[0].RecordID == "rec-old"
+	})).Return(wfRun, nil).Run(func(mock.Arguments) {
+		tx.AssertCalled(t, "Commit", ctx)
+	})
+
+	change, err := svc.ReplaceRecords(ctx, "test-zone-1", []model.ZoneRecord{
+		{ID: "rec-new", ZoneID: "test-zone-1", Type: "A", Name: "www.example.com", Content: "10.0.0.2", TTL: 3600, ManagedBy: model.ManagedByCustom},
+	})
+	require.NoError(t, err)
+	require.Len(t, change.Created, 1)
+	require.Len(t, change.Deleted, 1)
+	assert.Empty(t, change.Updated)
+	db.AssertExpectations(t)
+	tx.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestZoneRecordService_ReplaceRecords_NoChange(t *testing.T) {
+	db := &mockDB{}
+	tx := &mockTx{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneRecordService(db, tc)
+	ctx := context.Background()
+
+	db.On("Begin", ctx).Return(tx, nil)
+	expectZoneLock(tx, ctx)
+	rows := newMockRows(
+		zoneRecordScan("rec-1", "A", "www.example.com", "10.0.0.1", 3600, model.StatusActive),
+	)
+	tx.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+	tx.On("Commit", ctx).Return(nil)
+	tx.On("Rollback", ctx).Return(pgx.ErrTxClosed)
+
+	change, err := svc.ReplaceRecords(ctx, "test-zone-1", []model.ZoneRecord{
+		{ID: "ignored", Type: "A", Name: "www.example.com", Content: "10.0.0.1", TTL: 3600},
+	})
+	require.NoError(t, err)
+	assert.True(t, change.Empty())
+	assert.Equal(t, 1, change.Unchanged)
+	tx.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+	tc.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestZoneRecordService_ReplaceRecords_Busy(t *testing.T) {
+	db := &mockDB{}
+	tx := &mockTx{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneRecordService(db, tc)
+	ctx := context.Background()
+
+	db.On("Begin", ctx).Return(tx, nil)
+	expectZoneLock(tx, ctx)
+	rows := newMockRows(
+		zoneRecordScan("rec-1", "A", "www.example.com", "10.0.0.1", 3600, model.StatusPending),
+	)
+	tx.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+	tx.On("Rollback", ctx).Return(nil)
+
+	_, err := svc.ReplaceRecords(ctx, "test-zone-1", nil)
+	assert.ErrorIs(t, err, ErrZoneRecordsBusy)
+	tx.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+	tx.AssertNotCalled(t, "Commit", mock.Anything)
+	tc.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// ---------- PreviewRecords ----------
+
+func TestZoneRecordService_PreviewRecords_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneRecordService(db, tc)
+	ctx := context.Background()
+
+	rows := newMockRows(
+		zoneRecordScan("rec-old", "A", "www.example.com", "10.0.0.1", 3600, model.StatusActive),
+	)
+	db.On("Query", ctx, mock.AnythingOfType("string"), []any{"test-zone-1", model.ManagedByAuto}).Return(rows, nil)
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-zone-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "example.com"
+		return nil
+	}})
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		serial := uint32(2026101501)
+		*(args.Get(1).(**uint32)) = &serial
+	}).Return(nil)
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "GetZoneSOASerialWorkflow", "example.com").Return(wfRun, nil)
+
+	preview, err := svc.PreviewRecords(ctx, "test-zone-1", []model.ZoneRecord{
+		{ID: "rec-new", ZoneID: "test-zone-1", Type: "A", Name: "www.example.com", Content: "10.0.0.2", TTL: 3600, ManagedBy: model.ManagedByCustom},
+	})
+	require.NoError(t, err)
+	require.Len(t, preview.Created, 1)
+	require.Len(t, preview.Deleted, 1)
+	assert.Equal(t, "rec-old", preview.Deleted[0].ID)
+	assert.Equal(t, uint32(2026101501), *preview.CurrentSOASerial)
+	assert.Equal(t, uint32(2026101502), *preview.SOASerial)
+	db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+	tc.AssertNotCalled(t, "SignalWithStartWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	tc.AssertExpectations(t)
+}
+
+func TestZoneRecordService_PreviewRecords_NoChangeKeepsSerial(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneRecordService(db, tc)
+	ctx := context.Background()
+
+	rows := newMockRows(
+		zoneRecordScan("rec-1", "A", "www.example.com", "10.0.0.1", 3600, model.StatusActive),
+	)
+	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+	db.On("QueryRow", ctx, mock.AnythingOfType("string"), []any{"test-zone-1"}).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "example.com"
+		return nil
+	}})
+
+	wfRun := &temporalmocks.WorkflowRun{}
+	wfRun.On("Get", ctx, mock.Anything).Run(func(args mock.Arguments) {
+		serial := uint32(7)
+		*(args.Get(1).(**uint32)) = &serial
+	}).Return(nil)
+	tc.On("ExecuteWorkflow", ctx, mock.Anything, "GetZoneSOASerialWorkflow", "example.com").Return(wfRun, nil)
+
+	preview, err := svc.PreviewRecords(ctx, "test-zone-1", []model.ZoneRecord{
+		{ID: "ignored", Type: "A", Name: "www.example.com", Content: "10.0.0.1", TTL: 3600},
+	})
+	require.NoError(t, err)
+	assert.True(t, preview.Empty())
+	assert.Equal(t, 1, preview.Unchanged)
+	assert.Equal(t, uint32(7), *preview.SOASerial)
+}
+
+func TestZoneRecordService_PreviewRecords_Busy(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneRecordService(db, tc)
+	ctx := context.Background()
+
+	rows := newMockRows(
+		zoneRecordScan("rec-1", "A", "www.example.com", "10.0.0.1", 3600, model.StatusDeleting),
+	)
+	db.On("Query", ctx, mock.AnythingOfType("string"), mock.Anything).Return(rows, nil)
+
+	_, err := svc.PreviewRecords(ctx, "test-zone-1", nil)
+	assert.ErrorIs(t, err, ErrZoneRecordsBusy)
+	tc.AssertNotCalled(t, "ExecuteWorkflow", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,210 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ErrInvalidSecondaryRegion is returned when a zone's secondary region does
+// not exist, is the zone's own region or has no nameservers configured.
+var ErrInvalidSecondaryRegion = errors.New("invalid secondary region")
+
+// ErrZoneReplicationState is returned when the secondary regions of a zone
+// that is not active are changed.
+var ErrZoneReplicationState = errors.New("zone state does not allow changing replication")
+
+// nameserverProbeTimeout bounds each reachability check of a nameserver.
+const nameserverProbeTimeout = 3 * time.Second
+
+// probeNameserver asks a nameserver for the zone's NS records over UDP. It
+// fails if the nameserver cannot be resolved, does not answer or does not
+// serve the zone. Tests replace it to avoid network access.
+var probeNameserver = func(ctx context.Context, host, zone string) error {
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, net.JoinHostPort(host, "53"))
+		},
+	}
+	ns, err := resolver.LookupNS(ctx, zone)
+	if err != nil {
+		return err
+	}
+	if len(ns) == 0 {
+		return fmt.Errorf("no NS records for %s", zone)
+	}
+	return nil
+}
+
+// regionNameservers returns the configured nameservers of the given
+// regions, keyed by region ID. Regions that do not exist are missing from
+// the map.
+func (s *ZoneService) regionNameservers(ctx context.Context, regionIDs []string) (map[string][]model.RegionNameserver, error) {
+	rows, err := s.db.Query(ctx, `SELECT id, config FROM regions WHERE id = ANY($1)`, regionIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get region configs: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string][]model.RegionNameserver, len(regionIDs))
+	for rows.Next() {
+		var id string
+		var raw json.RawMessage
+		if err := rows.Scan(&id, &raw); err != nil {
+			return nil, fmt.Errorf("scan region config: %w", err)
+		}
+		var cfg model.RegionConfig
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &cfg); err != nil {
+				return nil, fmt.Errorf("parse config of region %s: %w", id, err)
+			}
+		}
+		result[id] = cfg.DNS.Nameservers
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate region configs: %w", err)
+	}
+	return result, nil
+}
+
+// validateSecondaryRegions checks a zone's secondary regions and returns
+// them without duplicates.
+func (s *ZoneService) validateSecondaryRegions(ctx context.Context, regionID string, ids []string) ([]string, error) {
+	unique := []string{}
+	seen := map[string]bool{}
+	for _, id := range ids {
+		if id == regionID {
+			return nil, fmt.Errorf("%w: %s is the zone's own region", ErrInvalidSecondaryRegion, id)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return unique, nil
+	}
+
+	nameservers, err := s.regionNameservers(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range unique {
+		ns, ok := nameservers[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: region %s not found", ErrInvalidSecondaryRegion, id)
+		}
+		if len(ns) == 0 {
+			return nil, fmt.Errorf("%w: region %s has no nameservers configured", ErrInvalidSecondaryRegion, id)
+		}
+	}
+	return unique, nil
+}
+
+// SetSecondaryRegions changes the regions whose nameservers serve an active
+// zone as secondaries. ConfigureZoneReplicationWorkflow updates the zone's
+// NS records and transfer settings in PowerDNS. An empty list stops the
+// replication.
+func (s *ZoneService) SetSecondaryRegions(ctx context.Context, zone *model.Zone, regionIDs []string) error {
+	if zone.Status != model.StatusActive {
+		return fmt.Errorf("%w: zone is %s", ErrZoneReplicationState, zone.Status)
+	}
+	ids, err := s.validateSecondaryRegions(ctx, zone.RegionID, regionIDs)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(ctx,
+		`UPDATE zones SET secondary_region_ids = $1, updated_at = now() WHERE id = $2`, ids, zone.ID)
+	if err != nil {
+		return fmt.Errorf("set secondary regions of zone %s: %w", zone.ID, err)
+	}
+	zone.SecondaryRegionIDs = ids
+
+	if err := signalProvision(ctx, s.tc, s.db, zone.TenantID, model.ProvisionTask{
+		WorkflowName: "ConfigureZoneReplicationWorkflow",
+		WorkflowID:   workflowID("zone-replication", zone.ID),
+		Arg:          zone.ID,
+	}); err != nil {
+		return fmt.Errorf("signal ConfigureZoneReplicationWorkflow: %w", err)
+	}
+	return nil
+}
+
+// Nameservers returns the nameservers a zone is served from: the brand's
+// primary_ns and secondary_ns, the nameservers of the zone's region and
+// those of its secondary regions. Each is probed for the zone, so an
+// unreachable nameserver or one that does not serve the zone yet is
+// reported with reachable false.
+func (s *ZoneService) Nameservers(ctx context.Context, zone *model.Zone) (*model.ZoneNameservers, error) {
+	var primaryNS, secondaryNS string
+	err := s.db.QueryRow(ctx,
+		`SELECT primary_ns, secondary_ns FROM brands WHERE id = $1`, zone.BrandID,
+	).Scan(&primaryNS, &secondaryNS)
+	if err != nil {
+		return nil, fmt.Errorf("get brand %s nameservers: %w", zone.BrandID, err)
+	}
+
+	regionIDs := append([]string{zone.RegionID}, zone.SecondaryRegionIDs...)
+	byRegion, err := s.regionNameservers(ctx, regionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.ZoneNameservers{
+		ZoneID:             zone.ID,
+		RegionID:           zone.RegionID,
+		SecondaryRegionIDs: zone.SecondaryRegionIDs,
+		Nameservers:        []model.ZoneNameserver{},
+	}
+	if result.SecondaryRegionIDs == nil {
+		result.SecondaryRegionIDs = []string{}
+	}
+	for _, host := range []string{primaryNS, secondaryNS} {
+		if host != "" {
+			result.Nameservers = append(result.Nameservers, model.ZoneNameserver{
+				Hostname: host, Role: model.NameserverPrimary, Source: "brand",
+			})
+		}
+	}
+	for _, regionID := range regionIDs {
+		role := model.NameserverSecondary
+		if regionID == zone.RegionID {
+			role = model.NameserverPrimary
+		}
+		for _, ns := range byRegion[regionID] {
+			result.Nameservers = append(result.Nameservers, model.ZoneNameserver{
+				Hostname: ns.Hostname, Address: ns.Address, RegionID: regionID, Role: role, Source: "region",
+			})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range result.Nameservers {
+		wg.Add(1)
+		go func(ns *model.ZoneNameserver) {
+			defer wg.Done()
+			host := ns.Address
+			if host == "" {
+				host = ns.Hostname
+			}
+			probeCtx, cancel := context.WithTimeout(ctx, nameserverProbeTimeout)
+			defer cancel()
+			if err := probeNameserver(probeCtx, host, zone.Name); err != nil {
+				ns.Error = err.Error()
+				return
+			}
+			ns.Reachable = true
+		}(&result.Nameservers[i])
+	}
+	wg.Wait()
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	temporalmocks "go.temporal.io/sdk/mocks"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+func regionConfigRow(id string, nameservers ...model.RegionNameserver) func(dest ...any) error {
+	return func(dest ...any) error {
+		cfg, _ := json.Marshal(model.RegionConfig{DNS: model.RegionDNSConfig{Nameservers: nameservers}})
+		*(dest[0].(*string)) = id
+		*(dest[1].(*json.RawMessage)) = cfg
+		return nil
+	}
+}
+
+func testReplicatedZone() *model.Zone {
+	return &model.Zone{
+		ID:                 "test-zone-1",
+		BrandID:            "test-brand",
+		TenantID:           "test-tenant-1",
+		Name:               "example.com",
+		RegionID:           "osl-1",
+		SecondaryRegionIDs: []string{"fra-1"},
+		Status:             model.StatusActive,
+	}
+}
+
+func TestZoneService_SetSecondaryRegions_Success(t *testing.T) {
+	db := &mockDB{}
+	tc := &temporalmocks.Client{}
+	svc := NewZoneService(db, tc)
+	ctx := context.Background()
+	zone := testReplicatedZone()
+
+	db.On("Query", ctx, sqlContains("FROM regions"), mock.Anything).Return(newMockRows(
+		regionConfigRow("fra-1", model.RegionNameserver{Hostname: "ns1.fra.example.net", Address: "192.0.2.53"}),
+		regionConfigRow("ams-1", model.RegionNameserver{Hostname: "ns1.ams.example.net", Address: "192.0.2.54"}),
+	), nil)
+	db.On("Exec", ctx, sqlContains("SET secondary_region_ids"), []any{[]string{"fra-1", "ams-1"}, "test-zone-1"}).
+		Return(pgconn.NewCommandTag("UPDATE 1"), nil).Once()
+	mockSignalOK(tc)
+
+	err := svc.SetSecondaryRegions(ctx, zone, []string{"fra-1", "ams-1", "fra-1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fra-1", "ams-1"}, zone.SecondaryRegionIDs)
+	tc.AssertCalled(t, "SignalWithStartWorkflow", mock.Anything, "tenant-test-tenant-1", mock.Anything, mock.MatchedBy(func(task model.ProvisionTask) bool {
+		return task.WorkflowName == "ConfigureZoneReplicationWorkflow" && task.Arg == "test-zone-1"
+	}), mock.Anything, mock.Anything)
+}
+
+func TestZoneService_SetSecondaryRegions_Invalid(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		regions []string
+		rows    []func(dest ...any) error
+	}{
+		{name: "own region", regions: []string{"osl-1"}},
+		{name: "unknown region", regions: []string{"fra-1"}},
+		{name: "no nameservers", regions: []string{"fra-1"}, rows: []func(dest ...any) error{regionConfigRow("fra-1")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &mockDB{}
+			svc := NewZoneService(db, nil)
+			db.On("Query", ctx, sqlContains("FROM regions"), mock.Anything).Return(newMockRows(tt.rows...), nil)
+
+			err := svc.SetSecondaryRegions(ctx, testReplicatedZone(), tt.regions)
+			require.ErrorIs(t, err, ErrInvalidSecondaryRegion)
+			db.AssertNotCalled(t, "Exec", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestZoneService_SetSecondaryRegions_NotActive(t *testing.T) {
+	svc := NewZoneService(&mockDB{}, nil)
+	zone := testReplicatedZone()
+	zone.Status = model.StatusProvisioning
+
+	err := svc.SetSecondaryRegions(context.Background(), zone, nil)
+	require.ErrorIs(t, err, ErrZoneReplicationState)
+}
+
+func TestZoneService_Nameservers(t *testing.T) {
+	db := &mockDB{}
+	svc := NewZoneService(db, nil)
+	ctx := context.Background()
+
+	probed := map[string]bool{}
+	orig := probeNameserver
+	probeNameserver = func(ctx context.Context, host, zone string) error {
+		if host == "192.0.2.53" {
+			return errors.New("i/o timeout")
+		}
+		return nil
+	}
+	defer func() { probeNameserver = orig }()
+
+	db.On("QueryRow", ctx, sqlContains("FROM brands"), mock.Anything).Return(&mockRow{scanFunc: func(dest ...any) error {
+		*(dest[0].(*string)) = "ns1.example.net"
+		*(dest[1].(*string)) = "ns2.example.net"
+		return nil
+	}})
+	db.On("Query", ctx, sqlContains("FROM regions"), mock.Anything).Return(newMockRows(
+		regionConfigRow("osl-1", model.RegionNameserver{Hostname: "ns1.osl.example.net", Address: "198.51.100.53"}),
+		regionConfigRow("fra-1", model.RegionNameserver{Hostname: "ns1.fra.example.net", Address: "192.0.2.53"}),
+	), nil)
+
+	result, err := svc.Nameservers(ctx, testReplicatedZone())
+	require.NoError(t, err)
+	require.Len(t, result.Nameservers, 4)
+	for _, ns := range result.Nameservers {
+		probed[ns.Hostname] = ns.Reachable
+	}
+	assert.Equal(t, map[string]bool{
+		"ns1.example.net":     true,
+		"ns2.example.net":     true,
+		"ns1.osl.example.net": true,
+		"ns1.fra.example.net": false,
+	}, probed)
+
+	fra := result.Nameservers[3]
+	assert.Equal(t, model.NameserverSecondary, fra.Role)
+	assert.Equal(t, "fra-1", fra.RegionID)
+	assert.Equal(t, "i/o timeout", fra.Error)
+	assert.Equal(t, model.NameserverPrimary, result.Nameservers[2].Role)
+}
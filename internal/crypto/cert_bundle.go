@@ -0,0 +1,242 @@
+package crypto
+
+import (
+	"bytes"
+	gocrypto "crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/pkcs12"
+)
+
+// ErrInvalidCertificateBundle is wrapped by every error ParseCertificateBundle
+// and CertificateBundle.Validate return for a bad bundle, so callers can tell
+// user mistakes apart from internal failures.
+var ErrInvalidCertificateBundle = errors.New("invalid certificate bundle")
+
+// Specific bundle mistakes. Each wraps ErrInvalidCertificateBundle.
+var (
+	ErrBundleUnrecognized     = fmt.Errorf("%w: expected PEM text or base64-encoded PKCS#12", ErrInvalidCertificateBundle)
+	ErrBundleNoCertificate    = fmt.Errorf("%w: private key found but no certificate", ErrInvalidCertificateBundle)
+	ErrBundleNoKey            = fmt.Errorf("%w: certificate found but no private key", ErrInvalidCertificateBundle)
+	ErrBundleMultipleKeys     = fmt.Errorf("%w: more than one private key", ErrInvalidCertificateBundle)
+	ErrBundleKeyMismatch      = fmt.Errorf("%w: private key does not match any certificate", ErrInvalidCertificateBundle)
+	ErrBundlePassphrase       = fmt.Errorf("%w: missing or incorrect passphrase", ErrInvalidCertificateBundle)
+	ErrBundleUnrelatedCert    = fmt.Errorf("%w: certificate is not part of the leaf's chain", ErrInvalidCertificateBundle)
+	ErrBundleExpired          = fmt.Errorf("%w: certificate has expired", ErrInvalidCertificateBundle)
+	ErrBundleNotYetValid      = fmt.Errorf("%w: certificate is not yet valid", ErrInvalidCertificateBundle)
+	ErrBundleHostnameMismatch = fmt.Errorf("%w: certificate is not valid for the FQDN", ErrInvalidCertificateBundle)
+)
+
+// CertificateBundle is a certificate bundle split into its parts and
+// re-encoded as normalized PEM: the leaf, the PKCS#8 private key, and the
+// intermediates ordered from the leaf's issuer upwards.
+type CertificateBundle struct {
+	Leaf     *x509.Certificate
+	Chain    []*x509.Certificate
+	CertPEM  string
+	KeyPEM   string
+	ChainPEM string
+}
+
+// ParseCertificateBundle auto-detects the format of data and splits it into
+// leaf, key, and chain. PEM blocks may appear in any order; anything without
+// a PEM header is treated as PKCS#12, raw or base64-encoded. The passphrase
+// decrypts PKCS#12 files and legacy encrypted PEM keys. Self-signed roots are
+// dropped from the chain since clients must already trust them.
+func ParseCertificateBundle(data []byte, passphrase string) (*CertificateBundle, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, ErrBundleUnrecognized
+	}
+
+	var blocks []*pem.Block
+	if bytes.Contains(data, []byte("-----BEGIN ")) {
+		rest := data
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			blocks = append(blocks, block)
+		}
+	} else {
+		var err error
+		if blocks, err = pkcs12Blocks(data, passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	var certs []*x509.Certificate
+	var key gocrypto.Signer
+	for _, block := range blocks {
+		switch {
+		case block.Type == "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("%w: parse certificate: %v", ErrInvalidCertificateBundle, err)
+			}
+			certs = append(certs, cert)
+		case block.Type == "ENCRYPTED PRIVATE KEY":
+			return nil, fmt.Errorf("%w: encrypted PKCS#8 keys are not supported, decrypt the key first (openssl pkey -in key.pem)", ErrInvalidCertificateBundle)
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			if key != nil {
+				return nil, ErrBundleMultipleKeys
+			}
+			der := block.Bytes
+			// Legacy "Proc-Type: 4,ENCRYPTED" keys, as exported by many panels.
+			if x509.IsEncryptedPEMBlock(block) {
+				if passphrase == "" {
+					return nil, ErrBundlePassphrase
+				}
+				var err error
+				if der, err = x509.DecryptPEMBlock(block, []byte(passphrase)); err != nil {
+					return nil, ErrBundlePassphrase
+				}
+			}
+			k, err := parseSigner(der)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidCertificateBundle, err)
+			}
+			key = k
+		}
+	}
+
+	switch {
+	case len(certs) == 0 && key != nil:
+		return nil, ErrBundleNoCertificate
+	case len(certs) == 0:
+		return nil, ErrBundleUnrecognized
+	case key == nil:
+		return nil, ErrBundleNoKey
+	}
+
+	leafIdx := -1
+	for i, cert := range certs {
+		if publicKeysEqual(cert.PublicKey, key.Public()) {
+			leafIdx = i
+			break
+		}
+	}
+	if leafIdx < 0 {
+		return nil, ErrBundleKeyMismatch
+	}
+	leaf := certs[leafIdx]
+	rest := append(certs[:leafIdx:leafIdx], certs[leafIdx+1:]...)
+
+	// Walk issuer links from the leaf so intermediates can come in any order.
+	var chain []*x509.Certificate
+	for current := leaf; len(rest) > 0; {
+		next := -1
+		for i, cand := range rest {
+			if bytes.Equal(current.RawIssuer, cand.RawSubject) && current.CheckSignatureFrom(cand) == nil {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			break
+		}
+		current = rest[next]
+		rest = append(rest[:next], rest[next+1:]...)
+		if !isSelfSigned(current) {
+			chain = append(chain, current)
+		}
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrBundleUnrelatedCert, rest[0].Subject)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshal private key: %w", err)
+	}
+	var chainPEM strings.Builder
+	for _, cert := range chain {
+		chainPEM.Write(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+	}
+
+	return &CertificateBundle{
+		Leaf:     leaf,
+		Chain:    chain,
+		CertPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: leaf.Raw})),
+		KeyPEM:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+		ChainPEM: chainPEM.String(),
+	}, nil
+}
+
+// Validate checks that the leaf is currently valid and covers hostname.
+// Wildcard FQDNs match a certificate for the same wildcard name.
+func (b *CertificateBundle) Validate(hostname string, now time.Time) error {
+	if now.After(b.Leaf.NotAfter) {
+		return fmt.Errorf("%w (expired %s)", ErrBundleExpired, b.Leaf.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(b.Leaf.NotBefore) {
+		return fmt.Errorf("%w (valid from %s)", ErrBundleNotYetValid, b.Leaf.NotBefore.UTC().Format(time.RFC3339))
+	}
+	if err := b.Leaf.VerifyHostname(hostname); err != nil {
+		return fmt.Errorf("%w: %s (certificate names: %s)", ErrBundleHostnameMismatch, hostname, strings.Join(b.Leaf.DNSNames, ", "))
+	}
+	return nil
+}
+
+// pkcs12Blocks decodes a raw or base64-encoded PKCS#12 file into PEM blocks.
+func pkcs12Blocks(data []byte, passphrase string) ([]*pem.Block, error) {
+	der := data
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.Join(bytes.Fields(data), nil))); err == nil {
+		der = decoded
+	}
+	blocks, err := pkcs12.ToPEM(der, passphrase)
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return nil, ErrBundlePassphrase
+	}
+	var notImplemented pkcs12.NotImplementedError
+	if errors.As(err, &notImplemented) {
+		return nil, fmt.Errorf("%w: unsupported PKCS#12 encryption, re-export with openssl pkcs12 -export -legacy", ErrInvalidCertificateBundle)
+	}
+	if err != nil {
+		return nil, ErrBundleUnrecognized
+	}
+	return blocks, nil
+}
+
+// parseSigner parses a private key in PKCS8, PKCS1, or EC (SEC 1) form.
+func parseSigner(der []byte) (gocrypto.Signer, error) {
+	if key, err := x509.ParsePKCS8PrivateKey(der); err == nil {
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return k, nil
+		case *ecdsa.PrivateKey:
+			return k, nil
+		case ed25519.PrivateKey:
+			return k, nil
+		default:
+			return nil, fmt.Errorf("unsupported private key type in PKCS8")
+		}
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("failed to parse private key")
+}
+
+func publicKeysEqual(a, b gocrypto.PublicKey) bool {
+	eq, ok := a.(interface{ Equal(gocrypto.PublicKey) bool })
+	return ok && eq.Equal(b)
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
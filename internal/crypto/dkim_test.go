@@ -0,0 +1,32 @@
+package crypto
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDKIMKey(t *testing.T) {
+	privPEM, pub, err := GenerateDKIMKey()
+	require.NoError(t, err)
+
+	block, _ := pem.Decode([]byte(privPEM))
+	require.NotNil(t, block)
+	assert.Equal(t, "PRIVATE KEY", block.Type)
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	require.NoError(t, err)
+	priv, ok := parsed.(*rsa.PrivateKey)
+	require.True(t, ok)
+	assert.Equal(t, DKIMKeyBits, priv.N.BitLen())
+
+	der, err := base64.StdEncoding.DecodeString(pub)
+	require.NoError(t, err)
+	pubKey, err := x509.ParsePKIXPublicKey(der)
+	require.NoError(t, err)
+	assert.True(t, priv.PublicKey.Equal(pubKey), "public key must match the private key")
+}
@@ -0,0 +1,146 @@
+package crypto
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DNSSECAlgorithm is the DNSSEC algorithm used for generated keys:
+// ECDSAP256SHA256 (13, RFC 6605), which RFC 8624 recommends for signing.
+const DNSSECAlgorithm = 13
+
+// DNSKEY flags for zone-signing and key-signing keys (RFC 4034 §2.1.1).
+const (
+	DNSKEYFlagsZSK = 256
+	DNSKEYFlagsKSK = 257
+)
+
+// GenerateDNSSECKey creates an ECDSA P-256 key and returns it in the BIND
+// private-key format PowerDNS stores in its cryptokeys table.
+func GenerateDNSSECKey() (string, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", fmt.Errorf("generate ecdsa key: %w", err)
+	}
+	raw, err := key.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("encode ecdsa key: %w", err)
+	}
+	return fmt.Sprintf("Private-key-format: v1.2\nAlgorithm: %d (ECDSAP256SHA256)\nPrivateKey: %s\n",
+		DNSSECAlgorithm, base64.StdEncoding.EncodeToString(raw)), nil
+}
+
+// DNSSECPublicKey returns the base64 public key, as published in a DNSKEY
+// record, of a private key in BIND format.
+func DNSSECPublicKey(privateKey string) (string, error) {
+	var algorithm, encoded string
+	sc := bufio.NewScanner(strings.NewReader(privateKey))
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(k) {
+		case "Algorithm":
+			algorithm, _, _ = strings.Cut(strings.TrimSpace(v), " ")
+		case "PrivateKey":
+			encoded = strings.TrimSpace(v)
+		}
+	}
+	if algorithm != fmt.Sprint(DNSSECAlgorithm) {
+		return "", fmt.Errorf("unsupported dnssec algorithm %q", algorithm)
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("decode private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return "", fmt.Errorf("parse private key: %w", err)
+	}
+	pub, err := key.PublicKey.Bytes()
+	if err != nil {
+		return "", fmt.Errorf("encode public key: %w", err)
+	}
+	// DNSKEY carries the bare X and Y coordinates, without the 0x04
+	// uncompressed-point prefix.
+	return base64.StdEncoding.EncodeToString(pub[1:]), nil
+}
+
+// DNSKEYContent returns the DNSKEY record content for a public key.
+func DNSKEYContent(flags int, publicKey string) string {
+	return fmt.Sprintf("%d 3 %d %s", flags, DNSSECAlgorithm, publicKey)
+}
+
+// DNSSECKeyTag computes the key tag of a DNSKEY (RFC 4034 Appendix B).
+func DNSSECKeyTag(flags int, publicKey string) (int, error) {
+	rdata, err := dnskeyRData(flags, publicKey)
+	if err != nil {
+		return 0, err
+	}
+	var ac uint32
+	for i, b := range rdata {
+		if i&1 == 0 {
+			ac += uint32(b) << 8
+		} else {
+			ac += uint32(b)
+		}
+	}
+	ac += ac >> 16 & 0xffff
+	return int(ac & 0xffff), nil
+}
+
+// DSContent returns the SHA-256 DS record content (RFC 4509) for a DNSKEY
+// of the given zone, as it is entered at the registrar.
+func DSContent(zone string, flags int, publicKey string) (string, error) {
+	rdata, err := dnskeyRData(flags, publicKey)
+	if err != nil {
+		return "", err
+	}
+	tag, err := DNSSECKeyTag(flags, publicKey)
+	if err != nil {
+		return "", err
+	}
+	owner, err := canonicalWireName(zone)
+	if err != nil {
+		return "", err
+	}
+	digest := sha256.Sum256(append(owner, rdata...))
+	return fmt.Sprintf("%d %d 2 %s", tag, DNSSECAlgorithm, strings.ToUpper(hex.EncodeToString(digest[:]))), nil
+}
+
+// dnskeyRData encodes DNSKEY RDATA: flags, protocol 3, algorithm, key.
+func dnskeyRData(flags int, publicKey string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
+	}
+	rdata := binary.BigEndian.AppendUint16(nil, uint16(flags))
+	rdata = append(rdata, 3, DNSSECAlgorithm)
+	return append(rdata, key...), nil
+}
+
+// canonicalWireName encodes a domain name in lowercase wire format
+// (RFC 4034 §6.2).
+func canonicalWireName(name string) ([]byte, error) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	var wire []byte
+	if name != "" {
+		for _, label := range strings.Split(name, ".") {
+			if label == "" || len(label) > 63 {
+				return nil, fmt.Errorf("invalid domain name %q", name)
+			}
+			wire = append(wire, byte(len(label)))
+			wire = append(wire, label...)
+		}
+	}
+	return append(wire, 0), nil
+}
@@ -0,0 +1,50 @@
+package crypto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6605Key is the ECDSAP256SHA256 example key from RFC 6605 §6.1.
+const rfc6605Key = `Private-key-format: v1.2
+Algorithm: 13 (ECDSAP256SHA256)
+PrivateKey: GU6SnQ/Ou+xC5RumuIUIuJZteXT2z0O/ok1s38Et6mQ=
+`
+
+const rfc6605PublicKey = "GojIhhXUN/u4v54ZQqGSnyhWJwaubCvTmeexv7bR6edbkrSqQpF64cYbcB7wNcP+e+MAnLr+Wi9xMWyQLc8NAA=="
+
+func TestDNSSECPublicKey_RFC6605(t *testing.T) {
+	pub, err := DNSSECPublicKey(rfc6605Key)
+	require.NoError(t, err)
+	assert.Equal(t, rfc6605PublicKey, pub)
+	assert.Equal(t, "257 3 13 "+rfc6605PublicKey, DNSKEYContent(DNSKEYFlagsKSK, pub))
+}
+
+func TestDSContent_RFC6605(t *testing.T) {
+	tag, err := DNSSECKeyTag(DNSKEYFlagsKSK, rfc6605PublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, 55648, tag)
+
+	ds, err := DSContent("Example.NET.", DNSKEYFlagsKSK, rfc6605PublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, "55648 13 2 B4C8C1FE2E7477127B27115656AD6256F424625BF5C1E2770CE6D6E37DF61D17", ds)
+}
+
+func TestGenerateDNSSECKey(t *testing.T) {
+	priv, err := GenerateDNSSECKey()
+	require.NoError(t, err)
+	assert.Contains(t, priv, "Algorithm: 13 (ECDSAP256SHA256)")
+
+	pub, err := DNSSECPublicKey(priv)
+	require.NoError(t, err)
+	assert.Len(t, pub, 88) // 64 bytes of X and Y
+}
+
+func TestDNSSECPublicKey_Invalid(t *testing.T) {
+	_, err := DNSSECPublicKey("Private-key-format: v1.2\nAlgorithm: 8 (RSASHA256)\nModulus: AAAA\n")
+	assert.Error(t, err)
+	_, err = DNSSECPublicKey("Private-key-format: v1.2\nAlgorithm: 13 (ECDSAP256SHA256)\nPrivateKey: not-base64\n")
+	assert.Error(t, err)
+}
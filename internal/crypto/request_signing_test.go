@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signedRequest(t *testing.T, body string) (*http.Request, []byte) {
+	t.Helper()
+	req, err := http.NewRequest("POST", "http://api.test/api/v1/internal/v1/login-sessions/validate?session_id=abc", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SignRequest(req, "s3cret"); err != nil {
+		t.Fatalf("SignRequest: %v", err)
+	}
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != body {
+		t.Fatalf("body after signing = %q, want %q", b, body)
+	}
+	return req, b
+}
+
+func TestVerifyRequestSignature(t *testing.T) {
+	req, body := signedRequest(t, `{"a":1}`)
+	if err := VerifyRequestSignature(req, body, "s3cret", time.Now()); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+}
+
+func TestVerifyRequestSignature_Rejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(r *http.Request, body []byte) []byte
+		secret string
+		now    time.Time
+		want   error
+	}{
+		{"wrong secret", nil, "other", time.Now(), ErrSignatureInvalid},
+		{"stale", nil, "s3cret", time.Now().Add(6 * time.Minute), ErrSignatureStale},
+		{"future", nil, "s3cret", time.Now().Add(-6 * time.Minute), ErrSignatureStale},
+		{"body changed", func(r *http.Request, _ []byte) []byte { return []byte(`{"a":2}`) }, "s3cret", time.Now(), ErrSignatureInvalid},
+		{"query changed", func(r *http.Request, body []byte) []byte {
+			r.URL.RawQuery = "session_id=xyz"
+			return body
+		}, "s3cret", time.Now(), ErrSignatureInvalid},
+		{"method changed", func(r *http.Request, body []byte) []byte {
+			r.Method = "GET"
+			return body
+		}, "s3cret", time.Now(), ErrSignatureInvalid},
+		{"missing", func(r *http.Request, body []byte) []byte {
+			r.Header.Del(SignatureHeader)
+			return body
+		}, "s3cret", time.Now(), ErrSignatureMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, body := signedRequest(t, `{"a":1}`)
+			if tt.mutate != nil {
+				body = tt.mutate(req, body)
+			}
+			if err := VerifyRequestSignature(req, body, tt.secret, tt.now); err != tt.want {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
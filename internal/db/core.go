@@ -3,15 +3,63 @@ package db
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/edvin/hosting/internal/config"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
 )
 
-func NewCorePool(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
+// PoolOptions tunes a connection pool. Zero values keep the pgx defaults.
+type PoolOptions struct {
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+	// Tracer, if set, is called around every query (see QueryTracer).
+	Tracer pgx.QueryTracer
+}
+
+// CorePoolOptions returns the pool options configured for the core database,
+// with a QueryTracer that logs slow queries to logger.
+func CorePoolOptions(cfg *config.Config, logger zerolog.Logger) PoolOptions {
+	return PoolOptions{
+		MaxConns:        int32(cfg.DBMaxConns),
+		MinConns:        int32(cfg.DBMinConns),
+		MaxConnLifetime: time.Duration(cfg.DBMaxConnLifetimeSecs) * time.Second,
+		MaxConnIdleTime: time.Duration(cfg.DBMaxConnIdleTimeSecs) * time.Second,
+		Tracer: &QueryTracer{
+			Logger:        logger,
+			SlowThreshold: time.Duration(cfg.DBSlowQueryMS) * time.Millisecond,
+		},
+	}
+}
+
+func (o PoolOptions) apply(cfg *pgxpool.Config) {
+	if o.MaxConns > 0 {
+		cfg.MaxConns = o.MaxConns
+	}
+	if o.MinConns > 0 {
+		cfg.MinConns = o.MinConns
+	}
+	if o.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = o.MaxConnLifetime
+	}
+	if o.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = o.MaxConnIdleTime
+	}
+	if o.Tracer != nil {
+		cfg.ConnConfig.Tracer = o.Tracer
+	}
+}
+
+func NewCorePool(ctx context.Context, databaseURL string, opts PoolOptions) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, fmt.Errorf("parse core db config: %w", err)
 	}
+	opts.apply(cfg)
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+)
+
+var queryDuration = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "pgx_query_duration_seconds",
+		Help:    "Core database query duration in seconds, by query name",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	},
+	[]string{"query"},
+)
+
+type queryNameKey struct{}
+
+// WithQueryName tags the queries run with ctx with a name for the query
+// duration metric and slow query log. Untagged queries are named after their
+// statement and table, e.g. "select zone_records".
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+// QueryTracer is a pgx.QueryTracer that records every query's duration in a
+// Prometheus histogram and logs queries slower than SlowThreshold. Only the
+// parameterized SQL is logged, never the argument values.
+type QueryTracer struct {
+	Logger zerolog.Logger
+	// SlowThreshold is the duration from which a query is logged. Zero
+	// disables the slow query log; durations are still recorded.
+	SlowThreshold time.Duration
+}
+
+type queryTrace struct {
+	name  string
+	sql   string
+	start time.Time
+}
+
+type queryTraceKey struct{}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	name, _ := ctx.Value(queryNameKey{}).(string)
+	if name == "" {
+		name = queryLabel(data.SQL)
+	}
+	return context.WithValue(ctx, queryTraceKey{}, queryTrace{name: name, sql: data.SQL, start: time.Now()})
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	trace, ok := ctx.Value(queryTraceKey{}).(queryTrace)
+	if !ok {
+		return
+	}
+	elapsed := time.Since(trace.start)
+	queryDuration.WithLabelValues(trace.name).Observe(elapsed.Seconds())
+
+	if t.SlowThreshold <= 0 || elapsed < t.SlowThreshold {
+		return
+	}
+	event := t.Logger.Warn().
+		Str("query", trace.name).
+		Str("sql", strings.Join(strings.Fields(trace.sql), " ")).
+		Dur("duration", elapsed).
+		Int64("rows", data.CommandTag.RowsAffected())
+	if data.Err != nil {
+		event = event.Str("error", data.Err.Error())
+	}
+	event.Msg("slow query")
+}
+
+// queryLabel derives a low-cardinality name from a SQL statement: its verb
+// and the first table it names, such as "select zone_records" or
+// "insert audit_logs". Statements it cannot parse get just the verb.
+func queryLabel(sql string) string {
+	fields := strings.Fields(strings.ToLower(stripLineComments(sql)))
+	if len(fields) == 0 {
+		return "unknown"
+	}
+
+	verb := fields[0]
+	var after string
+	switch verb {
+	case "select", "delete":
+		after = "from"
+	case "insert":
+		after = "into"
+	case "update":
+		if len(fields) > 1 {
+			return verb + " " + tableName(fields[1])
+		}
+		return verb
+	default:
+		return verb
+	}
+	for i := 1; i < len(fields)-1; i++ {
+		if fields[i] == after {
+			return verb + " " + tableName(fields[i+1])
+		}
+	}
+	return verb
+}
+
+// tableName strips the punctuation around a table token, e.g. "zones,"
+// or "records(domain_id".
+func tableName(token string) string {
+	if i := strings.IndexAny(token, "(,;"); i >= 0 {
+		token = token[:i]
+	}
+	return strings.Trim(token, `"`)
+}
+
+// stripLineComments removes "--" comments, which sqlc and hand-written
+// queries put above the statement.
+func stripLineComments(sql string) string {
+	lines := strings.Split(sql, "\n")
+	for i, line := range lines {
+		if j := strings.Index(line, "--"); j >= 0 {
+			lines[i] = line[:j]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
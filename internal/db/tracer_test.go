@@ -0,0 +1,98 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryLabel(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want string
+	}{
+		{"SELECT id, name FROM tenants WHERE id = $1", "select tenants"},
+		{"select w.id from webroots w join tenants t on t.id = w.tenant_id", "select webroots"},
+		{"INSERT INTO audit_logs (id) VALUES ($1)", "insert audit_logs"},
+		{"UPDATE zones SET status = $1 WHERE id = $2", "update zones"},
+		{"DELETE FROM records WHERE domain_id = $1", "delete records"},
+		{"-- name: GetZone\nSELECT * FROM zones", "select zones"},
+		{"SELECT 1", "select"},
+		{"BEGIN", "begin"},
+		{"  ", "unknown"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, queryLabel(tt.sql), tt.sql)
+	}
+}
+
+func runTrace(tracer *QueryTracer, ctx context.Context, sql string, sleep time.Duration, err error) {
+	ctx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql, Args: []any{"secret-value"}})
+	time.Sleep(sleep)
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 3"), Err: err})
+}
+
+func TestQueryTracer_LogsSlowQuery(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := &QueryTracer{Logger: zerolog.New(&buf), SlowThreshold: time.Millisecond}
+
+	ctx := WithQueryName(context.Background(), "GetShardDesiredState")
+	runTrace(tracer, ctx, "SELECT *\n\t  FROM webroots\n WHERE tenant_id = ANY($1)", 5*time.Millisecond, errors.New("boom"))
+
+	out := buf.String()
+	assert.Contains(t, out, `"message":"slow query"`)
+	assert.Contains(t, out, `"query":"GetShardDesiredState"`)
+	assert.Contains(t, out, `"sql":"SELECT * FROM webroots WHERE tenant_id = ANY($1)"`)
+	assert.Contains(t, out, `"rows":3`)
+	assert.Contains(t, out, `"error":"boom"`)
+	assert.NotContains(t, out, "secret-value")
+}
+
+func TestQueryTracer_FastQueryNotLogged(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := &QueryTracer{Logger: zerolog.New(&buf), SlowThreshold: time.Hour}
+
+	runTrace(tracer, context.Background(), "SELECT 1", 0, nil)
+	assert.Empty(t, buf.String())
+}
+
+func TestQueryTracer_ZeroThresholdDisablesLog(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := &QueryTracer{Logger: zerolog.New(&buf)}
+
+	runTrace(tracer, context.Background(), "SELECT 1", time.Millisecond, nil)
+	assert.Empty(t, buf.String())
+}
+
+func TestPoolOptions_Apply(t *testing.T) {
+	cfg, err := pgxpool.ParseConfig("postgres://localhost/core")
+	require.NoError(t, err)
+	defaults := *cfg
+
+	PoolOptions{}.apply(cfg)
+	assert.Equal(t, defaults.MaxConns, cfg.MaxConns)
+	assert.Nil(t, cfg.ConnConfig.Tracer)
+
+	tracer := &QueryTracer{}
+	PoolOptions{
+		MaxConns:        40,
+		MinConns:        2,
+		MaxConnLifetime: time.Hour,
+		MaxConnIdleTime: time.Minute,
+		Tracer:          tracer,
+	}.apply(cfg)
+	assert.Equal(t, int32(40), cfg.MaxConns)
+	assert.Equal(t, int32(2), cfg.MinConns)
+	assert.Equal(t, time.Hour, cfg.MaxConnLifetime)
+	assert.Equal(t, time.Minute, cfg.MaxConnIdleTime)
+	assert.Same(t, tracer, cfg.ConnConfig.Tracer)
+}
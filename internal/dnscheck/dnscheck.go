@@ -0,0 +1,293 @@
+// Package dnscheck resolves a name's A and AAAA records against its
+// authoritative nameservers and a set of public resolvers, concurrently and
+// with bounded timeouts, and compares the answers with expected addresses.
+package dnscheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// DefaultResolvers are the public resolvers queried by New.
+var DefaultResolvers = []string{"1.1.1.1", "8.8.8.8", "9.9.9.9"}
+
+const (
+	// queryTimeout bounds a single DNS query.
+	queryTimeout = 3 * time.Second
+	// maxNameservers caps the authoritative nameservers queried.
+	maxNameservers = 4
+)
+
+// Checker runs DNS checks.
+type Checker struct {
+	resolvers []string
+	// exchange sends a DNS query to server and returns the response.
+	// Replaced in tests.
+	exchange func(ctx context.Context, server string, query []byte) ([]byte, error)
+}
+
+// New returns a Checker that queries the given public resolvers, as IP
+// addresses, on port 53.
+func New(resolvers []string) *Checker {
+	return &Checker{resolvers: resolvers, exchange: exchangeUDP}
+}
+
+// Check resolves name on its authoritative nameservers and the public
+// resolvers and compares the answers with expected. Lookup failures are
+// reported per server; Check itself only fails if ctx is done.
+func (c *Checker) Check(ctx context.Context, name string, expected []string) (*model.FQDNDNSCheck, error) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	result := &model.FQDNDNSCheck{FQDN: name, Expected: expected}
+
+	zone, nameservers := c.authoritative(ctx, name)
+	result.Zone = zone
+
+	type target struct{ server, kind string }
+	var targets []target
+	for _, ns := range nameservers {
+		targets = append(targets, target{ns, model.DNSServerAuthoritative})
+	}
+	for _, r := range c.resolvers {
+		targets = append(targets, target{r, model.DNSServerPublic})
+	}
+
+	result.Results = make([]model.DNSCheckResult, len(targets))
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result.Results[i] = c.resolve(ctx, t.server, t.kind, name, expected)
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result.Status = overallStatus(result.Results)
+	return result, nil
+}
+
+// authoritative finds the zone containing name by asking a public resolver
+// for NS records of name and then each parent, and returns the zone and the
+// addresses of up to maxNameservers of its nameservers.
+func (c *Checker) authoritative(ctx context.Context, name string) (string, []string) {
+	if len(c.resolvers) == 0 {
+		return "", nil
+	}
+	resolver := c.resolvers[0]
+
+	for zone := name; strings.Contains(zone, "."); zone = zone[strings.Index(zone, ".")+1:] {
+		answer, err := c.query(ctx, resolver, zone, dnsmessage.TypeNS, true)
+		if err != nil || len(answer.nameservers) == 0 {
+			continue
+		}
+		var addrs []string
+		for _, ns := range answer.nameservers {
+			if len(addrs) == maxNameservers {
+				break
+			}
+			a, err := c.query(ctx, resolver, ns, dnsmessage.TypeA, true)
+			if err != nil || len(a.addresses) == 0 {
+				continue
+			}
+			addrs = append(addrs, a.addresses[0])
+		}
+		return zone, addrs
+	}
+	return "", nil
+}
+
+// MX returns the mail exchangers of name, as reported by the first public
+// resolver that answers.
+func (c *Checker) MX(ctx context.Context, name string) ([]string, error) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	var errs []error
+	for _, r := range c.resolvers {
+		answer, err := c.query(ctx, r, name, dnsmessage.TypeMX, true)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return answer.mailExchangers, nil
+	}
+	if len(errs) == 0 {
+		return nil, errors.New("no resolvers configured")
+	}
+	return nil, errors.Join(errs...)
+}
+
+// resolve queries server for name's A and AAAA records.
+func (c *Checker) resolve(ctx context.Context, server, kind, name string, expected []string) model.DNSCheckResult {
+	res := model.DNSCheckResult{Server: server, Kind: kind, Addresses: []string{}}
+	recursive := kind == model.DNSServerPublic
+
+	var errs []error
+	for _, qtype := range []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA} {
+		answer, err := c.query(ctx, server, name, qtype, recursive)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		res.Addresses = append(res.Addresses, answer.addresses...)
+		if answer.cname != "" {
+			res.CNAME = answer.cname
+		}
+		if answer.ttl != nil && (res.TTL == nil || *answer.ttl < *res.TTL) {
+			res.TTL = answer.ttl
+		}
+	}
+
+	switch {
+	case len(errs) == 2:
+		res.Status = model.DNSCheckError
+		res.Error = errors.Join(errs...).Error()
+	case len(res.Addresses) == 0:
+		res.Status = model.DNSCheckNoRecords
+	case allExpected(res.Addresses, expected):
+		res.Status = model.DNSCheckMatch
+	default:
+		res.Status = model.DNSCheckMismatch
+	}
+	return res
+}
+
+// allExpected reports whether every address is one of expected.
+func allExpected(addresses, expected []string) bool {
+	want := make(map[netip.Addr]bool, len(expected))
+	for _, e := range expected {
+		if a, err := netip.ParseAddr(e); err == nil {
+			want[a.Unmap()] = true
+		}
+	}
+	for _, s := range addresses {
+		a, err := netip.ParseAddr(s)
+		if err != nil || !want[a.Unmap()] {
+			return false
+		}
+	}
+	return true
+}
+
+// overallStatus is match only if every server matched, mismatch if any
+// server returned other addresses, and otherwise the first failure.
+func overallStatus(results []model.DNSCheckResult) string {
+	if len(results) == 0 {
+		return model.DNSCheckError
+	}
+	status := model.DNSCheckMatch
+	for _, r := range results {
+		switch {
+		case r.Status == model.DNSCheckMismatch:
+			return model.DNSCheckMismatch
+		case r.Status != model.DNSCheckMatch && status == model.DNSCheckMatch:
+			status = r.Status
+		}
+	}
+	return status
+}
+
+// answer is the part of a DNS response a check needs.
+type answer struct {
+	addresses      []string
+	nameservers    []string
+	mailExchangers []string
+	cname          string
+	ttl            *uint32
+}
+
+// query sends one question to server and parses the answer section.
+func (c *Checker) query(ctx context.Context, server, name string, qtype dnsmessage.Type, recursive bool) (*answer, error) {
+	qname, err := dnsmessage.NewName(name + ".")
+	if err != nil {
+		return nil, fmt.Errorf("invalid name %q: %w", name, err)
+	}
+	id := uint16(rand.UintN(1 << 16))
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id, RecursionDesired: recursive},
+		Questions: []dnsmessage.Question{{Name: qname, Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := msg.Pack()
+	if err != nil {
+		return nil, fmt.Errorf("pack query: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
+	defer cancel()
+	raw, err := c.exchange(ctx, server, packed)
+	if err != nil {
+		return nil, fmt.Errorf("query %s %s: %w", qtype, name, err)
+	}
+
+	var resp dnsmessage.Message
+	if err := resp.Unpack(raw); err != nil {
+		return nil, fmt.Errorf("parse %s response for %s: %w", qtype, name, err)
+	}
+	if resp.ID != id {
+		return nil, fmt.Errorf("%s response for %s has mismatched ID", qtype, name)
+	}
+	if resp.RCode != dnsmessage.RCodeSuccess && resp.RCode != dnsmessage.RCodeNameError {
+		return nil, fmt.Errorf("%s %s: %s", qtype, name, resp.RCode)
+	}
+
+	var a answer
+	for _, rr := range resp.Answers {
+		switch body := rr.Body.(type) {
+		case *dnsmessage.AResource:
+			a.addresses = append(a.addresses, netip.AddrFrom4(body.A).String())
+			a.observeTTL(rr.Header.TTL)
+		case *dnsmessage.AAAAResource:
+			a.addresses = append(a.addresses, netip.AddrFrom16(body.AAAA).String())
+			a.observeTTL(rr.Header.TTL)
+		case *dnsmessage.NSResource:
+			a.nameservers = append(a.nameservers, strings.TrimSuffix(body.NS.String(), "."))
+		case *dnsmessage.MXResource:
+			a.mailExchangers = append(a.mailExchangers, strings.TrimSuffix(body.MX.String(), "."))
+		case *dnsmessage.CNAMEResource:
+			a.cname = strings.TrimSuffix(body.CNAME.String(), ".")
+		}
+	}
+	slices.Sort(a.addresses)
+	return &a, nil
+}
+
+func (a *answer) observeTTL(ttl uint32) {
+	if a.ttl == nil || ttl < *a.ttl {
+		a.ttl = &ttl
+	}
+}
+
+// exchangeUDP sends query to server on port 53 over UDP.
+func exchangeUDP(ctx context.Context, server string, query []byte) ([]byte, error) {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", net.JoinHostPort(server, "53"))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return nil, err
+	}
+	return buf[:n], nil
+}
@@ -0,0 +1,173 @@
+package dnscheck
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// fakeDNS answers from records, keyed by "server|type|name". Missing keys
+// get an empty NOERROR answer; servers in down fail as if timed out.
+type fakeDNS struct {
+	records map[string][]dnsmessage.Resource
+	down    map[string]bool
+}
+
+func (f *fakeDNS) exchange(_ context.Context, server string, query []byte) ([]byte, error) {
+	if f.down[server] {
+		return nil, errors.New("i/o timeout")
+	}
+	var q dnsmessage.Message
+	if err := q.Unpack(query); err != nil {
+		return nil, err
+	}
+	question := q.Questions[0]
+	key := server + "|" + question.Type.String() + "|" + strings.TrimSuffix(question.Name.String(), ".")
+	resp := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: q.ID, Response: true},
+		Questions: q.Questions,
+		Answers:   f.records[key],
+	}
+	return resp.Pack()
+}
+
+func name(s string) dnsmessage.Name {
+	return dnsmessage.MustNewName(s + ".")
+}
+
+func aRecord(owner, addr string, ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name(owner), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.AResource{A: netip.MustParseAddr(addr).As4()},
+	}
+}
+
+func aaaaRecord(owner, addr string, ttl uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name(owner), Type: dnsmessage.TypeAAAA, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.AAAAResource{AAAA: netip.MustParseAddr(addr).As16()},
+	}
+}
+
+func nsRecord(owner, ns string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name(owner), Type: dnsmessage.TypeNS, Class: dnsmessage.ClassINET, TTL: 3600},
+		Body:   &dnsmessage.NSResource{NS: name(ns)},
+	}
+}
+
+func mxRecord(owner, mx string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name(owner), Type: dnsmessage.TypeMX, Class: dnsmessage.ClassINET, TTL: 300},
+		Body:   &dnsmessage.MXResource{Pref: 10, MX: name(mx)},
+	}
+}
+
+func newFakeChecker(f *fakeDNS) *Checker {
+	return &Checker{resolvers: []string{"1.1.1.1", "8.8.8.8"}, exchange: f.exchange}
+}
+
+func TestCheck_AllMatch(t *testing.T) {
+	f := &fakeDNS{records: map[string][]dnsmessage.Resource{
+		"1.1.1.1|TypeNS|example.com":          {nsRecord("example.com", "ns1.example.net")},
+		"1.1.1.1|TypeA|ns1.example.net":       {aRecord("ns1.example.net", "192.0.2.53", 3600)},
+		"192.0.2.53|TypeA|www.example.com":    {aRecord("www.example.com", "203.0.113.10", 300)},
+		"192.0.2.53|TypeAAAA|www.example.com": {aaaaRecord("www.example.com", "2001:db8::10", 600)},
+		"1.1.1.1|TypeA|www.example.com":       {aRecord("www.example.com", "203.0.113.10", 120)},
+		"8.8.8.8|TypeA|www.example.com":       {aRecord("www.example.com", "203.0.113.10", 250)},
+	}}
+
+	result, err := newFakeChecker(f).Check(context.Background(), "WWW.example.com.", []string{"203.0.113.10", "2001:db8::10"})
+	require.NoError(t, err)
+	assert.Equal(t, "www.example.com", result.FQDN)
+	assert.Equal(t, "example.com", result.Zone)
+	assert.Equal(t, model.DNSCheckMatch, result.Status)
+	require.Len(t, result.Results, 3)
+
+	auth := result.Results[0]
+	assert.Equal(t, "192.0.2.53", auth.Server)
+	assert.Equal(t, model.DNSServerAuthoritative, auth.Kind)
+	assert.Equal(t, []string{"203.0.113.10", "2001:db8::10"}, auth.Addresses)
+	require.NotNil(t, auth.TTL)
+	assert.Equal(t, uint32(300), *auth.TTL)
+
+	public := result.Results[1]
+	assert.Equal(t, model.DNSServerPublic, public.Kind)
+	assert.Equal(t, model.DNSCheckMatch, public.Status)
+	assert.Equal(t, uint32(120), *public.TTL)
+}
+
+func TestCheck_StaleResolverMismatch(t *testing.T) {
+	f := &fakeDNS{records: map[string][]dnsmessage.Resource{
+		"1.1.1.1|TypeA|example.com": {aRecord("example.com", "198.51.100.1", 3600)},
+		"8.8.8.8|TypeA|example.com": {aRecord("example.com", "203.0.113.10", 300)},
+	}}
+
+	result, err := newFakeChecker(f).Check(context.Background(), "example.com", []string{"203.0.113.10"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Zone)
+	assert.Equal(t, model.DNSCheckMismatch, result.Status)
+	require.Len(t, result.Results, 2)
+	assert.Equal(t, model.DNSCheckMismatch, result.Results[0].Status)
+	assert.Equal(t, model.DNSCheckMatch, result.Results[1].Status)
+}
+
+func TestCheck_NoRecordsAndErrors(t *testing.T) {
+	f := &fakeDNS{down: map[string]bool{"8.8.8.8": true}}
+
+	result, err := newFakeChecker(f).Check(context.Background(), "new.example.com", []string{"203.0.113.10"})
+	require.NoError(t, err)
+	require.Len(t, result.Results, 2)
+	assert.Equal(t, model.DNSCheckNoRecords, result.Results[0].Status)
+	assert.Nil(t, result.Results[0].TTL)
+	assert.Equal(t, model.DNSCheckError, result.Results[1].Status)
+	assert.Contains(t, result.Results[1].Error, "i/o timeout")
+	assert.Equal(t, model.DNSCheckNoRecords, result.Status)
+}
+
+func TestCheck_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := newFakeChecker(&fakeDNS{}).Check(ctx, "example.com", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestAllExpected(t *testing.T) {
+	assert.True(t, allExpected([]string{"203.0.113.10"}, []string{"203.0.113.10", "2001:db8::10"}))
+	assert.True(t, allExpected([]string{"2001:db8::10"}, []string{"2001:DB8::10"}))
+	assert.False(t, allExpected([]string{"203.0.113.11"}, []string{"203.0.113.10"}))
+	assert.False(t, allExpected([]string{"203.0.113.10"}, nil))
+}
+
+func TestMX(t *testing.T) {
+	f := &fakeDNS{
+		records: map[string][]dnsmessage.Resource{
+			"8.8.8.8|TypeMX|example.com": {mxRecord("example.com", "mail.example.com")},
+		},
+		down: map[string]bool{"1.1.1.1": true},
+	}
+
+	mx, err := newFakeChecker(f).MX(context.Background(), "Example.com.")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mail.example.com"}, mx)
+
+	mx, err = newFakeChecker(f).MX(context.Background(), "nomail.example.com")
+	require.NoError(t, err)
+	assert.Empty(t, mx)
+}
+
+func TestMX_AllResolversDown(t *testing.T) {
+	f := &fakeDNS{down: map[string]bool{"1.1.1.1": true, "8.8.8.8": true}}
+
+	_, err := newFakeChecker(f).MX(context.Background(), "example.com")
+	assert.ErrorContains(t, err, "i/o timeout")
+}
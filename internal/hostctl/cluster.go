@@ -251,7 +251,11 @@ func applyNodes(client *Client, clusterID string, def ClusterDef) error {
 
 			shardID := shardNameToID[name]
 			fmt.Printf("Converging shard %q...\n", name)
-			_, err := client.Post(fmt.Sprintf("/shards/%s/converge", shardID), nil)
+			resp, err := client.Post(fmt.Sprintf("/shards/%s/converge", shardID), nil)
+			if resp != nil && resp.StatusCode == http.StatusConflict {
+				fmt.Printf("  Convergence already running for shard %q\n", name)
+				continue
+			}
 			if err != nil {
 				fmt.Printf("  Warning: convergence failed for shard %q: %v\n", name, err)
 			}
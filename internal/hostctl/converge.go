@@ -1,14 +1,86 @@
 package hostctl
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
 
-// ConvergeShard triggers shard convergence via the API.
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ConvergeShard triggers shard convergence via the API. A convergence that
+// is already running for the shard is reported rather than treated as an
+// error.
 func ConvergeShard(apiURL, apiKey, shardID string) error {
 	client := NewClient(apiURL, apiKey)
 	resp, err := client.Post(fmt.Sprintf("/api/v1/shards/%s/converge", shardID), nil)
+	if resp != nil && resp.StatusCode == http.StatusConflict {
+		fmt.Printf("Convergence already running for shard %s\n", shardID)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 	fmt.Printf("Convergence started (status %d): %s\n", resp.StatusCode, string(resp.Body))
 	return nil
 }
+
+// ConvergeCluster starts a batch convergence of every shard in a cluster and
+// streams per-shard progress until the batch finishes.
+func ConvergeCluster(apiURL, apiKey, clusterID string, maxConcurrent int, pollInterval time.Duration) error {
+	client := NewClient(apiURL, apiKey)
+
+	var body any
+	if maxConcurrent > 0 {
+		body = map[string]int{"max_concurrent": maxConcurrent}
+	}
+	resp, err := client.Post(fmt.Sprintf("/api/v1/clusters/%s/converge", clusterID), body)
+	if err != nil {
+		return err
+	}
+	var started struct {
+		BatchID string `json:"batch_id"`
+	}
+	if err := json.Unmarshal(resp.Body, &started); err != nil {
+		return fmt.Errorf("parse converge response: %w", err)
+	}
+	fmt.Printf("Cluster convergence started (batch %s)\n", started.BatchID)
+
+	seen := make(map[string]string)
+	for {
+		time.Sleep(pollInterval)
+
+		resp, err := client.Get(fmt.Sprintf("/api/v1/converge-batches/%s", started.BatchID))
+		if err != nil {
+			return err
+		}
+		var batch model.ConvergeBatch
+		if err := json.Unmarshal(resp.Body, &batch); err != nil {
+			return fmt.Errorf("parse batch: %w", err)
+		}
+
+		for _, sh := range batch.Shards {
+			if seen[sh.ShardID] == sh.Status {
+				continue
+			}
+			seen[sh.ShardID] = sh.Status
+			if sh.Status == model.ConvergeShardPending {
+				continue
+			}
+			line := fmt.Sprintf("  [%d/%d] %s (%s): %s", batch.Completed, batch.Total, sh.ShardName, sh.Role, sh.Status)
+			if sh.Error != "" {
+				line += " — " + sh.Error
+			}
+			fmt.Println(line)
+		}
+
+		if batch.Status != model.ConvergeBatchRunning {
+			fmt.Printf("Batch %s %s: %d succeeded, %d failed\n", batch.ID, batch.Status, batch.Succeeded, batch.Failed)
+			if batch.Failed > 0 {
+				return fmt.Errorf("%d of %d shards failed to converge", batch.Failed, batch.Total)
+			}
+			return nil
+		}
+	}
+}
@@ -132,6 +132,31 @@ func Seed(configPath string, timeout time.Duration) error {
 			}
 			fmt.Printf("  Brand %q: allowed clusters set (%v)\n", b.Name, b.AllowedClusters)
 		}
+
+		if len(b.ZoneTemplates) > 0 {
+			records := make([]map[string]any, 0, len(b.ZoneTemplates))
+			for _, zt := range b.ZoneTemplates {
+				rec := map[string]any{
+					"type":    zt.Type,
+					"name":    zt.Name,
+					"content": zt.Content,
+				}
+				if zt.TTL > 0 {
+					rec["ttl"] = zt.TTL
+				}
+				if zt.Priority != nil {
+					rec["priority"] = *zt.Priority
+				}
+				records = append(records, rec)
+			}
+			_, err := client.Put(fmt.Sprintf("/brands/%s/zone-templates", brandID), map[string]any{
+				"records": records,
+			})
+			if err != nil {
+				return fmt.Errorf("set brand %q zone templates: %w", b.Name, err)
+			}
+			fmt.Printf("  Brand %q: %d zone templates set\n", b.Name, len(records))
+		}
 	}
 
 	// Discover web node IPs once (needed for fixture deployment).
@@ -288,6 +313,12 @@ func Seed(configPath string, timeout time.Duration) error {
 							"fqdn":        f.FQDN,
 							"ssl_enabled": f.SSLEnabled,
 						}
+						if f.ForceHTTPS != nil {
+							fqdnEntry["force_https"] = *f.ForceHTTPS
+						}
+						if f.RedirectTarget != nil {
+							fqdnEntry["redirect_target"] = *f.RedirectTarget
+						}
 						if emails, ok := emailsByFQDN[f.FQDN]; ok {
 							fqdnEntry["email_accounts"] = buildEmailAccountEntries(emails, resolveSubID)
 						}
@@ -323,6 +354,9 @@ func Seed(configPath string, timeout time.Duration) error {
 						if j.WorkingDirectory != "" {
 							entry["working_directory"] = j.WorkingDirectory
 						}
+						if j.Timezone != "" {
+							entry["timezone"] = j.Timezone
+						}
 						jobs = append(jobs, entry)
 					}
 					wr["cron_jobs"] = jobs
@@ -365,6 +399,9 @@ func Seed(configPath string, timeout time.Duration) error {
 				if v.MaxMemoryMB > 0 {
 					entry["max_memory_mb"] = v.MaxMemoryMB
 				}
+				if v.PersistenceMode != "" {
+					entry["persistence_mode"] = v.PersistenceMode
+				}
 				if len(v.Users) > 0 {
 					var users []map[string]any
 					for _, u := range v.Users {
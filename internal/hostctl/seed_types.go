@@ -31,7 +31,16 @@ type BrandDef struct {
 	DKIMSelector    string   `yaml:"dkim_selector"`
 	DKIMPublicKey   string   `yaml:"dkim_public_key"`
 	DMARCPolicy     string   `yaml:"dmarc_policy"`
-	AllowedClusters []string `yaml:"allowed_clusters"`
+	AllowedClusters []string          `yaml:"allowed_clusters"`
+	ZoneTemplates   []ZoneTemplateDef `yaml:"zone_templates"`
+}
+
+type ZoneTemplateDef struct {
+	Type     string `yaml:"type"`
+	Name     string `yaml:"name"`
+	Content  string `yaml:"content"`
+	TTL      int    `yaml:"ttl"`
+	Priority *int   `yaml:"priority"`
 }
 
 type ZoneDef struct {
@@ -92,8 +101,10 @@ type EnvVarDef struct {
 }
 
 type FQDNDef struct {
-	FQDN       string `yaml:"fqdn"`
-	SSLEnabled bool   `yaml:"ssl_enabled"`
+	FQDN           string  `yaml:"fqdn"`
+	SSLEnabled     bool    `yaml:"ssl_enabled"`
+	ForceHTTPS     *bool   `yaml:"force_https"`
+	RedirectTarget *string `yaml:"redirect_target"` // Makes the FQDN a domain alias of this host
 }
 
 type FixtureDef struct {
@@ -126,10 +137,11 @@ type DatabaseUserDef struct {
 }
 
 type ValkeyInstanceDef struct {
-	Subscription string          `yaml:"subscription"`
-	Shard        string          `yaml:"shard"`
-	MaxMemoryMB int             `yaml:"max_memory_mb"`
-	Users       []ValkeyUserDef `yaml:"users"`
+	Subscription    string          `yaml:"subscription"`
+	Shard           string          `yaml:"shard"`
+	MaxMemoryMB     int             `yaml:"max_memory_mb"`
+	PersistenceMode string          `yaml:"persistence_mode,omitempty"`
+	Users           []ValkeyUserDef `yaml:"users"`
 }
 
 type ValkeyUserDef struct {
@@ -183,6 +195,7 @@ type CronJobDef struct {
 	WorkingDirectory string `yaml:"working_directory"`
 	TimeoutSeconds   int    `yaml:"timeout_seconds"`
 	MaxMemoryMB      int    `yaml:"max_memory_mb"`
+	Timezone         string `yaml:"timezone"`
 }
 
 type BackupDef struct {
@@ -0,0 +1,129 @@
+// Package ipallow restricts HTTP access to clients from configured CIDR
+// ranges. The client address is the TCP peer, unless the peer is an explicitly
+// trusted proxy, in which case X-Forwarded-For is walked from the right and
+// the first untrusted hop is used. Untrusted peers cannot spoof their address
+// by sending X-Forwarded-For themselves.
+package ipallow
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// List is an IP allowlist. A nil List, or one without allowed ranges, allows
+// every request.
+type List struct {
+	allowed []netip.Prefix
+	trusted []netip.Prefix
+}
+
+// New parses comma-separated CIDR lists of allowed client ranges and trusted
+// proxies. Bare IP addresses are accepted as single-host ranges.
+func New(allowed, trustedProxies string) (*List, error) {
+	a, err := ParsePrefixes(allowed)
+	if err != nil {
+		return nil, fmt.Errorf("allowlist: %w", err)
+	}
+	t, err := ParsePrefixes(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("trusted proxies: %w", err)
+	}
+	return &List{allowed: a, trusted: t}, nil
+}
+
+// ParsePrefixes parses a comma-separated list of CIDRs or IP addresses.
+func ParsePrefixes(s string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if strings.Contains(part, "/") {
+			p, err := netip.ParsePrefix(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", part, err)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP %q: %w", part, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Enabled reports whether the list restricts anything.
+func (l *List) Enabled() bool {
+	return l != nil && len(l.allowed) > 0
+}
+
+// Allows reports whether the request's client address is in an allowed range.
+// Requests whose client address cannot be determined are rejected.
+func (l *List) Allows(r *http.Request) bool {
+	if !l.Enabled() {
+		return true
+	}
+	addr, ok := l.ClientIP(r)
+	if !ok {
+		return false
+	}
+	return contains(l.allowed, addr)
+}
+
+// ClientIP resolves the client address of the request. X-Forwarded-For is
+// only consulted when the TCP peer is a trusted proxy.
+func (l *List) ClientIP(r *http.Request) (netip.Addr, bool) {
+	peer, ok := parseRemoteAddr(r.RemoteAddr)
+	if !ok {
+		return netip.Addr{}, false
+	}
+	if l == nil || !contains(l.trusted, peer) {
+		return peer, true
+	}
+
+	var hops []string
+	for _, v := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+	client := peer
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+		client = addr.Unmap()
+		if !contains(l.trusted, client) {
+			break
+		}
+	}
+	return client, true
+}
+
+func parseRemoteAddr(remoteAddr string) (netip.Addr, bool) {
+	host := remoteAddr
+	if h, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		host = h
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+func contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package ipallow
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequest(remoteAddr string, xff ...string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = remoteAddr
+	for _, v := range xff {
+		r.Header.Add("X-Forwarded-For", v)
+	}
+	return r
+}
+
+func TestParsePrefixes(t *testing.T) {
+	prefixes, err := ParsePrefixes(" 10.0.0.0/8, 192.0.2.7 ,2001:db8::/32,,")
+	require.NoError(t, err)
+	require.Len(t, prefixes, 3)
+	assert.Equal(t, "10.0.0.0/8", prefixes[0].String())
+	assert.Equal(t, "192.0.2.7/32", prefixes[1].String())
+	assert.Equal(t, "2001:db8::/32", prefixes[2].String())
+
+	_, err = ParsePrefixes("10.0.0.0/33")
+	assert.Error(t, err)
+	_, err = ParsePrefixes("not-an-ip")
+	assert.Error(t, err)
+}
+
+func TestList_DisabledAllowsAll(t *testing.T) {
+	var nilList *List
+	assert.True(t, nilList.Allows(newRequest("198.51.100.1:1234")))
+
+	l, err := New("", "10.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, l.Enabled())
+	assert.True(t, l.Allows(newRequest("198.51.100.1:1234")))
+}
+
+func TestList_DirectPeer(t *testing.T) {
+	l, err := New("203.0.113.0/24", "")
+	require.NoError(t, err)
+
+	assert.True(t, l.Allows(newRequest("203.0.113.9:5000")))
+	assert.False(t, l.Allows(newRequest("198.51.100.1:5000")))
+	assert.True(t, l.Allows(newRequest("[::ffff:203.0.113.9]:5000")))
+}
+
+func TestList_IgnoresForwardedForFromUntrustedPeer(t *testing.T) {
+	l, err := New("203.0.113.0/24", "10.0.0.1")
+	require.NoError(t, err)
+
+	assert.False(t, l.Allows(newRequest("198.51.100.1:5000", "203.0.113.9")))
+}
+
+func TestList_TrustedProxyChain(t *testing.T) {
+	l, err := New("203.0.113.0/24", "10.0.0.0/24")
+	require.NoError(t, err)
+
+	// Client -> LB (10.0.0.2) -> admin-ui (10.0.0.3) -> API.
+	assert.True(t, l.Allows(newRequest("10.0.0.3:5000", "203.0.113.9, 10.0.0.2")))
+	// A spoofed leftmost entry is ignored; the first untrusted hop from the right wins.
+	assert.False(t, l.Allows(newRequest("10.0.0.3:5000", "203.0.113.9, 198.51.100.1")))
+	// Multiple headers are treated as one list.
+	assert.True(t, l.Allows(newRequest("10.0.0.3:5000", "198.51.100.1", "203.0.113.9")))
+	// A trusted proxy without X-Forwarded-For is itself the client.
+	assert.False(t, l.Allows(newRequest("10.0.0.3:5000")))
+	// Garbage in the chain is rejected.
+	assert.False(t, l.Allows(newRequest("10.0.0.3:5000", "bogus")))
+}
+
+func TestList_UnparseableRemoteAddr(t *testing.T) {
+	l, err := New("0.0.0.0/0", "")
+	require.NoError(t, err)
+
+	assert.False(t, l.Allows(newRequest("@")))
+}
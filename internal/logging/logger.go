@@ -1,17 +1,47 @@
 package logging
 
 import (
+	"io"
 	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog"
 
 	"github.com/edvin/hosting/internal/config"
 )
 
+// levelState is the process-wide log level plus per-component overrides.
+type levelState struct {
+	base       zerolog.Level
+	components map[string]zerolog.Level
+}
+
+var (
+	levels atomic.Pointer[levelState]
+	// burst samples debug and info messages across all loggers created by
+	// NewLogger; nil when sampling is off.
+	burst zerolog.Sampler
+)
+
+func init() {
+	levels.Store(&levelState{base: zerolog.InfoLevel})
+}
+
 // NewLogger creates a structured zerolog.Logger with observability context fields
 // from the config. Non-empty fields are added automatically.
 func NewLogger(cfg *config.Config) zerolog.Logger {
-	ctx := zerolog.New(os.Stdout).With().Timestamp()
+	var out io.Writer = os.Stdout
+	if cfg.LogFormat == config.LogFormatConsole {
+		out = zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	}
+	if cfg.LogSampleBurst > 0 {
+		burst = &zerolog.BurstSampler{
+			Burst:  uint32(cfg.LogSampleBurst),
+			Period: time.Duration(cfg.LogSamplePeriodSecs) * time.Second,
+		}
+	}
+	ctx := zerolog.New(out).Sample(&levelSampler{}).With().Timestamp()
 
 	if cfg.ServiceName != "" {
 		ctx = ctx.Str("service", cfg.ServiceName)
@@ -32,12 +62,89 @@ func NewLogger(cfg *config.Config) zerolog.Logger {
 		ctx = ctx.Str("node_role", cfg.NodeRole)
 	}
 
-	logger := ctx.Logger()
+	// Levels are checked by the logger's sampler rather than set on the
+	// logger so that SetLevel and SetComponentLevels can change them at
+	// runtime for every derived logger.
+	if err := SetLevel(cfg.LogLevel); err != nil {
+		_ = SetLevel(zerolog.InfoLevel.String())
+	}
+	if err := SetComponentLevels(cfg.LogLevels); err != nil {
+		_ = SetComponentLevels("")
+	}
+
+	return ctx.Logger()
+}
 
-	level, err := zerolog.ParseLevel(cfg.LogLevel)
+// Component returns a logger for a component of the process, tagged with a
+// "component" field. Its level is the component's override from LOG_LEVELS,
+// if any, else the process-wide level.
+func Component(logger zerolog.Logger, name string) zerolog.Logger {
+	return logger.With().Str("component", name).Logger().Sample(&levelSampler{component: name})
+}
+
+// SetLevel changes the process-wide log level. It takes effect immediately
+// for all loggers created by NewLogger.
+func SetLevel(level string) error {
+	lvl, err := zerolog.ParseLevel(level)
 	if err != nil {
-		level = zerolog.InfoLevel
+		return err
 	}
+	cur := levels.Load()
+	storeLevels(&levelState{base: lvl, components: cur.components})
+	return nil
+}
 
-	return logger.Level(level)
+// SetComponentLevels replaces the per-component level overrides with those
+// of spec (component=level,...). It takes effect immediately for all
+// component loggers.
+func SetComponentLevels(spec string) error {
+	components, err := config.ParseLogLevels(spec)
+	if err != nil {
+		return err
+	}
+	cur := levels.Load()
+	storeLevels(&levelState{base: cur.base, components: components})
+	return nil
+}
+
+// storeLevels swaps in s and lowers the zerolog global level to the lowest
+// level in use, so that messages below every configured level are dropped
+// before reaching a sampler.
+func storeLevels(s *levelState) {
+	levels.Store(s)
+	lowest := s.base
+	for _, lvl := range s.components {
+		if lvl < lowest {
+			lowest = lvl
+		}
+	}
+	zerolog.SetGlobalLevel(lowest)
+}
+
+// enabled reports whether a message of the component at lvl is logged.
+// Messages of unknown components, or none, use the process-wide level.
+func enabled(component string, lvl zerolog.Level) bool {
+	s := levels.Load()
+	min, ok := s.components[component]
+	if !ok {
+		min = s.base
+	}
+	return lvl >= min
+}
+
+// levelSampler filters a logger's messages by its component's level, then
+// samples debug and info messages if sampling is on. Warnings and errors
+// are never sampled.
+type levelSampler struct {
+	component string
+}
+
+func (s *levelSampler) Sample(lvl zerolog.Level) bool {
+	if !enabled(s.component, lvl) {
+		return false
+	}
+	if burst != nil && lvl <= zerolog.InfoLevel {
+		return burst.Sample(lvl)
+	}
+	return true
 }
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLogger returns a logger like NewLogger's, writing to buf, with the
+// process-wide levels restored after the test.
+func testLogger(t *testing.T, buf *bytes.Buffer) zerolog.Logger {
+	t.Helper()
+	prev := levels.Load()
+	t.Cleanup(func() { storeLevels(prev) })
+	return zerolog.New(buf).Sample(&levelSampler{})
+}
+
+func messages(buf *bytes.Buffer) []string {
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal([]byte(line), &entry) == nil {
+			msgs = append(msgs, entry.Message)
+		}
+	}
+	return msgs
+}
+
+func TestComponent_LevelOverride(t *testing.T) {
+	var buf bytes.Buffer
+	logger := testLogger(t, &buf)
+	require.NoError(t, SetLevel("info"))
+	require.NoError(t, SetComponentLevels("nginx-manager=debug,cron-manager=error"))
+
+	nginx := Component(logger, "nginx-manager")
+	cron := Component(logger, "cron-manager")
+	ssh := Component(logger, "ssh-manager")
+
+	logger.Debug().Msg("base debug")
+	logger.Info().Msg("base info")
+	nginx.Debug().Msg("nginx debug")
+	cron.Warn().Msg("cron warn")
+	cron.Error().Msg("cron error")
+	ssh.Debug().Msg("ssh debug")
+
+	assert.Equal(t, []string{"base info", "nginx debug", "cron error"}, messages(&buf))
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+}
+
+func TestSetComponentLevels_RuntimeChange(t *testing.T) {
+	var buf bytes.Buffer
+	nginx := Component(testLogger(t, &buf), "nginx-manager")
+	require.NoError(t, SetLevel("info"))
+	require.NoError(t, SetComponentLevels(""))
+
+	nginx.Debug().Msg("before")
+	require.NoError(t, SetComponentLevels("nginx-manager=debug"))
+	nginx.Debug().Msg("after")
+
+	assert.Equal(t, []string{"after"}, messages(&buf))
+	assert.Error(t, SetComponentLevels("nginx-manager"))
+}
+
+func TestTemporalLogger_WorkflowTypeOverride(t *testing.T) {
+	var buf bytes.Buffer
+	logger := TemporalLogger(testLogger(t, &buf))
+	require.NoError(t, SetLevel("info"))
+	require.NoError(t, SetComponentLevels("ConvergeShardWorkflow=debug"))
+
+	logger.Debug("converge debug", "WorkflowType", "ConvergeShardWorkflow", "node", "node-1")
+	logger.Debug("other debug", "WorkflowType", "CreateWebrootWorkflow")
+	logger.Info("sdk info", "Namespace", "default")
+
+	assert.Equal(t, []string{"converge debug", "sdk info"}, messages(&buf))
+	assert.Contains(t, buf.String(), `"node":"node-1"`)
+	assert.Contains(t, buf.String(), `"component":"temporal"`)
+}
+
+func TestSampling_DropsOnlyDebugAndInfo(t *testing.T) {
+	var buf bytes.Buffer
+	logger := testLogger(t, &buf)
+	require.NoError(t, SetLevel("info"))
+	prev := burst
+	burst = &zerolog.BurstSampler{Burst: 2, Period: time.Hour}
+	t.Cleanup(func() { burst = prev })
+
+	for i := 0; i < 5; i++ {
+		logger.Info().Msg("info")
+	}
+	logger.Warn().Msg("warn")
+	logger.Error().Msg("error")
+
+	assert.Equal(t, []string{"info", "info", "warn", "error"}, messages(&buf))
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog"
+	"go.temporal.io/sdk/log"
+)
+
+// temporalComponent is the component of the Temporal SDK's own log messages,
+// and of workflow and activity messages whose type has no level override.
+const temporalComponent = "temporal"
+
+// TemporalLogger adapts a logger for the Temporal SDK, so SDK, workflow and
+// activity messages share the process's format, levels and sampling. A
+// LOG_LEVELS override for a workflow or activity type (e.g.
+// ConvergeShardWorkflow=debug) applies to that type's messages; other
+// messages use the "temporal" component.
+func TemporalLogger(logger zerolog.Logger) log.Logger {
+	return &temporalLogger{logger: logger.With().Str("component", temporalComponent).Logger().Sample(nil)}
+}
+
+type temporalLogger struct {
+	logger zerolog.Logger
+}
+
+func (l *temporalLogger) Debug(msg string, keyvals ...interface{}) {
+	l.log(zerolog.DebugLevel, msg, keyvals)
+}
+
+func (l *temporalLogger) Info(msg string, keyvals ...interface{}) {
+	l.log(zerolog.InfoLevel, msg, keyvals)
+}
+
+func (l *temporalLogger) Warn(msg string, keyvals ...interface{}) {
+	l.log(zerolog.WarnLevel, msg, keyvals)
+}
+
+func (l *temporalLogger) Error(msg string, keyvals ...interface{}) {
+	l.log(zerolog.ErrorLevel, msg, keyvals)
+}
+
+func (l *temporalLogger) log(lvl zerolog.Level, msg string, keyvals []interface{}) {
+	component := temporalComponent
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		if k, _ := keyvals[i].(string); k == "WorkflowType" || k == "ActivityType" {
+			name, _ := keyvals[i+1].(string)
+			if _, ok := levels.Load().components[name]; ok {
+				component = name
+			}
+		}
+	}
+	s := levelSampler{component: component}
+	if !s.Sample(lvl) {
+		return
+	}
+
+	e := l.logger.WithLevel(lvl)
+	for i := 0; i < len(keyvals); i += 2 {
+		key := fmt.Sprint(keyvals[i])
+		if i+1 == len(keyvals) {
+			e = e.Interface("EXTRA_VALUE_AT_END", keyvals[i])
+			break
+		}
+		if err, ok := keyvals[i+1].(error); ok {
+			e = e.AnErr(key, err)
+		} else {
+			e = e.Interface(key, keyvals[i+1])
+		}
+	}
+	e.Msg(msg)
+}
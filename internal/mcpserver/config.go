@@ -14,6 +14,15 @@ type Config struct {
 	Defaults map[string]MethodDefaults `yaml:"defaults"`
 	Groups   map[string]GroupConfig    `yaml:"groups"`
 	Overrides map[string]ToolOverride  `yaml:"overrides"`
+
+	// SpecCachePath is where the standalone server keeps the last good spec,
+	// so it can start while the API is unavailable. Empty disables the cache.
+	SpecCachePath string `yaml:"spec_cache_path"`
+	// SpecFetchAttempts is how often a spec fetch is tried before giving up.
+	SpecFetchAttempts int `yaml:"spec_fetch_attempts"`
+	// AdminToken guards the standalone server's /refresh-spec endpoint.
+	// Empty disables the endpoint.
+	AdminToken string `yaml:"admin_token"`
 }
 
 // MethodDefaults defines default MCP annotations for an HTTP method.
@@ -60,6 +69,9 @@ func ParseConfig(data []byte) (*Config, error) {
 	if cfg.SpecPath == "" {
 		cfg.SpecPath = "/docs/openapi.json"
 	}
+	if cfg.SpecFetchAttempts <= 0 {
+		cfg.SpecFetchAttempts = 10
+	}
 
 	return &cfg, nil
 }
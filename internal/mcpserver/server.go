@@ -1,11 +1,16 @@
 package mcpserver
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -13,17 +18,35 @@ import (
 	"github.com/rs/zerolog"
 )
 
+// refreshMinInterval rate-limits on-demand spec refreshes so the admin
+// endpoint cannot be used to hammer the API.
+const refreshMinInterval = 30 * time.Second
+
+// ErrRefreshTooSoon is returned by RefreshSpec when another refresh is running
+// or the previous one started less than refreshMinInterval ago.
+var ErrRefreshTooSoon = errors.New("spec was refreshed too recently")
+
 // Server is the MCP server that proxies tool calls to the REST API.
 type Server struct {
 	router chi.Router
 	logger zerolog.Logger
 	cfg    *Config
+
+	// mcp holds the current tool handler. It is replaced as a whole when the
+	// spec is reloaded, so requests never see a partially built tool set.
+	mcp atomic.Pointer[http.Handler]
+
+	refreshMu   sync.Mutex
+	lastRefresh time.Time
 }
 
 // New creates and configures a new standalone MCP server from the given config and swagger spec.
 func New(cfg *Config, specData []byte, logger zerolog.Logger) (*Server, error) {
-	mcpRouter, err := NewHandler(cfg, specData, logger)
-	if err != nil {
+	s := &Server{
+		logger: logger,
+		cfg:    cfg,
+	}
+	if err := s.Reload(specData); err != nil {
 		return nil, err
 	}
 
@@ -39,13 +62,131 @@ func New(cfg *Config, specData []byte, logger zerolog.Logger) (*Server, error) {
 		w.Write([]byte(`{"status":"ok"}`))
 	})
 
-	router.Mount("/mcp", mcpRouter)
+	router.Post("/refresh-spec", s.handleRefreshSpec)
 
-	return &Server{
-		router: router,
-		logger: logger,
-		cfg:    cfg,
-	}, nil
+	router.Mount("/mcp", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		(*s.mcp.Load()).ServeHTTP(w, r)
+	}))
+
+	s.router = router
+	return s, nil
+}
+
+// Start creates a standalone MCP server, fetching the spec from the API.
+//
+// If a cached spec exists the server is built from it right away and the
+// live spec is fetched in the background (with the same retries, stopping
+// when ctx is done), so a slow API does not delay startup. Without a cache the fetch is retried with backoff
+// and Start fails only once every attempt has failed.
+func Start(ctx context.Context, cfg *Config, logger zerolog.Logger) (*Server, error) {
+	cached, err := loadCachedSpec(cfg.SpecCachePath)
+	if err != nil {
+		logger.Warn().Err(err).Msg("ignoring unreadable spec cache")
+	}
+	if cached != nil {
+		s, err := New(cfg, cached, logger)
+		if err == nil {
+			logger.Info().Str("path", cfg.SpecCachePath).Msg("started from cached spec, refreshing in background")
+			go func() {
+				if err := s.refresh(ctx, cfg.SpecFetchAttempts); err != nil {
+					logger.Error().Err(err).Msg("background spec refresh failed, still serving cached spec")
+				}
+			}()
+			return s, nil
+		}
+		logger.Warn().Err(err).Msg("ignoring invalid cached spec")
+	}
+
+	data, err := FetchSpecWithRetry(ctx, cfg.APIURL, cfg.SpecPath, cfg.SpecFetchAttempts, logger)
+	if err != nil {
+		return nil, err
+	}
+	s, err := New(cfg, data, logger)
+	if err != nil {
+		return nil, err
+	}
+	if err := saveCachedSpec(cfg.SpecCachePath, data); err != nil {
+		logger.Warn().Err(err).Msg("failed to cache spec")
+	}
+	s.lastRefresh = time.Now()
+	return s, nil
+}
+
+// Reload rebuilds the tool set from specData and swaps it in atomically.
+// On error the current tool set stays in place. Open MCP sessions are bound
+// to the old tool set and must re-initialize to see new tools.
+func (s *Server) Reload(specData []byte) error {
+	h, err := NewHandler(s.cfg, specData, s.logger)
+	if err != nil {
+		return err
+	}
+	var handler http.Handler = h
+	s.mcp.Store(&handler)
+	return nil
+}
+
+// RefreshSpec re-fetches the spec from the API, rebuilds the tool set and
+// updates the spec cache. It returns ErrRefreshTooSoon if another refresh is
+// running or the last one started less than refreshMinInterval ago.
+func (s *Server) RefreshSpec(ctx context.Context) error {
+	return s.refresh(ctx, s.cfg.SpecFetchAttempts)
+}
+
+func (s *Server) refresh(ctx context.Context, attempts int) error {
+	if !s.refreshMu.TryLock() {
+		return fmt.Errorf("%w: a refresh is already in progress", ErrRefreshTooSoon)
+	}
+	defer s.refreshMu.Unlock()
+
+	if since := time.Since(s.lastRefresh); since < refreshMinInterval {
+		return fmt.Errorf("%w: retry in %s", ErrRefreshTooSoon, (refreshMinInterval - since).Round(time.Second))
+	}
+	s.lastRefresh = time.Now()
+
+	data, err := FetchSpecWithRetry(ctx, s.cfg.APIURL, s.cfg.SpecPath, attempts, s.logger)
+	if err != nil {
+		return err
+	}
+	if err := s.Reload(data); err != nil {
+		return err
+	}
+	if err := saveCachedSpec(s.cfg.SpecCachePath, data); err != nil {
+		s.logger.Warn().Err(err).Msg("failed to cache spec")
+	}
+	s.logger.Info().Msg("spec refreshed")
+	return nil
+}
+
+// handleRefreshSpec re-fetches the spec on demand, e.g. after an API deploy
+// adds endpoints. It requires the configured admin token as a bearer token.
+func (s *Server) handleRefreshSpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if s.cfg.AdminToken == "" {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"spec refresh is disabled"}`))
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.AdminToken)) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid admin token"}`))
+		return
+	}
+
+	// A single attempt: the caller can retry, and the request should not
+	// hang for the full backoff schedule.
+	err := s.refresh(r.Context(), 1)
+	switch {
+	case errors.Is(err, ErrRefreshTooSoon):
+		w.WriteHeader(http.StatusTooManyRequests)
+	case err != nil:
+		w.WriteHeader(http.StatusBadGateway)
+	default:
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"refreshed"}`))
+		return
+	}
+	w.Write([]byte(fmt.Sprintf(`{"error":%q}`, err.Error())))
 }
 
 // NewHandler returns a chi.Router with all MCP endpoints, suitable for mounting at /mcp.
@@ -127,19 +268,3 @@ func NewHandler(cfg *Config, specData []byte, logger zerolog.Logger) (chi.Router
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	s.router.ServeHTTP(w, r)
 }
-
-// FetchSpec downloads the swagger spec from the API.
-func FetchSpec(apiURL, specPath string) ([]byte, error) {
-	url := strings.TrimRight(apiURL, "/") + specPath
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, fmt.Errorf("fetch spec from %s: %w", url, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("fetch spec from %s: HTTP %d", url, resp.StatusCode)
-	}
-
-	return io.ReadAll(resp.Body)
-}
@@ -0,0 +1,162 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const oneToolSpec = `{"paths":{"/tenants":{"get":{"tags":["Tenants"],"summary":"List tenants"}}}}`
+
+const twoToolSpec = `{"paths":{"/tenants":{"get":{"tags":["Tenants"],"summary":"List tenants"},"post":{"tags":["Tenants"],"summary":"Create a tenant"}}}}`
+
+func init() {
+	specFetchInitialBackoff = time.Millisecond
+	specFetchMaxBackoff = time.Millisecond
+}
+
+// specAPI serves the given spec after failing the first failures requests.
+func specAPI(t *testing.T, spec *atomic.Value, failures int32) *httptest.Server {
+	t.Helper()
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(spec.Load().(string)))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testConfig(apiURL string) *Config {
+	return &Config{
+		APIURL:            apiURL,
+		SpecPath:          "/docs/openapi.json",
+		SpecFetchAttempts: 5,
+		Groups:            map[string]GroupConfig{"tenants": {Tags: []string{"Tenants"}}},
+	}
+}
+
+// toolCount returns the number of tools on the unified endpoint.
+func toolCount(t *testing.T, s *Server) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/mcp/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var groups []struct {
+		Name  string `json:"name"`
+		Tools int    `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &groups))
+	for _, g := range groups {
+		if g.Name == "all" {
+			return g.Tools
+		}
+	}
+	t.Fatal("no unified endpoint in index")
+	return 0
+}
+
+func TestFetchSpecWithRetry_RecoversAfterFailures(t *testing.T) {
+	var spec atomic.Value
+	spec.Store(oneToolSpec)
+	api := specAPI(t, &spec, 2)
+
+	data, err := FetchSpecWithRetry(context.Background(), api.URL, "/docs/openapi.json", 3, zerolog.Nop())
+	require.NoError(t, err)
+	assert.JSONEq(t, oneToolSpec, string(data))
+}
+
+func TestFetchSpecWithRetry_GivesUp(t *testing.T) {
+	var spec atomic.Value
+	spec.Store(oneToolSpec)
+	api := specAPI(t, &spec, 10)
+
+	_, err := FetchSpecWithRetry(context.Background(), api.URL, "/docs/openapi.json", 3, zerolog.Nop())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "giving up after 3 attempts")
+}
+
+func TestStart_FetchesAndCachesSpec(t *testing.T) {
+	var spec atomic.Value
+	spec.Store(oneToolSpec)
+	api := specAPI(t, &spec, 1)
+
+	cfg := testConfig(api.URL)
+	cfg.SpecCachePath = filepath.Join(t.TempDir(), "spec.json")
+
+	s, err := Start(context.Background(), cfg, zerolog.Nop())
+	require.NoError(t, err)
+	assert.Equal(t, 1, toolCount(t, s))
+
+	cached, err := os.ReadFile(cfg.SpecCachePath)
+	require.NoError(t, err)
+	assert.JSONEq(t, oneToolSpec, string(cached))
+}
+
+func TestStart_UsesCacheWhenAPIDown(t *testing.T) {
+	cfg := testConfig("http://127.0.0.1:1")
+	cfg.SpecCachePath = filepath.Join(t.TempDir(), "spec.json")
+	cfg.SpecFetchAttempts = 1
+	require.NoError(t, os.WriteFile(cfg.SpecCachePath, []byte(twoToolSpec), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	s, err := Start(ctx, cfg, zerolog.Nop())
+	require.NoError(t, err)
+	assert.Equal(t, 2, toolCount(t, s))
+}
+
+func TestRefreshSpecEndpoint(t *testing.T) {
+	var spec atomic.Value
+	spec.Store(oneToolSpec)
+	api := specAPI(t, &spec, 0)
+
+	cfg := testConfig(api.URL)
+	cfg.AdminToken = "secret"
+	s, err := New(cfg, []byte(oneToolSpec), zerolog.Nop())
+	require.NoError(t, err)
+	require.Equal(t, 1, toolCount(t, s))
+
+	refresh := func(token string) int {
+		req := httptest.NewRequest(http.MethodPost, "/refresh-spec", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, refresh(""))
+	assert.Equal(t, http.StatusUnauthorized, refresh("wrong"))
+
+	spec.Store(twoToolSpec)
+	assert.Equal(t, http.StatusOK, refresh("secret"))
+	assert.Equal(t, 2, toolCount(t, s))
+
+	// Rate-limited until refreshMinInterval has passed.
+	assert.Equal(t, http.StatusTooManyRequests, refresh("secret"))
+}
+
+func TestRefreshSpecEndpoint_DisabledWithoutToken(t *testing.T) {
+	s, err := New(testConfig("http://127.0.0.1:1"), []byte(oneToolSpec), zerolog.Nop())
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/refresh-spec", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
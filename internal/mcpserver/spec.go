@@ -0,0 +1,121 @@
+package mcpserver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// specFetchTimeout bounds a single spec download.
+const specFetchTimeout = 30 * time.Second
+
+// specFetchInitialBackoff and specFetchMaxBackoff bound the delay between
+// retries while the API is coming up. Variables so tests can shorten them.
+var (
+	specFetchInitialBackoff = time.Second
+	specFetchMaxBackoff     = 30 * time.Second
+)
+
+var specHTTPClient = &http.Client{Timeout: specFetchTimeout}
+
+// FetchSpec downloads the swagger spec from the API.
+func FetchSpec(apiURL, specPath string) ([]byte, error) {
+	return fetchSpec(context.Background(), apiURL, specPath)
+}
+
+func fetchSpec(ctx context.Context, apiURL, specPath string) ([]byte, error) {
+	url := strings.TrimRight(apiURL, "/") + specPath
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fetch spec from %s: %w", url, err)
+	}
+	resp, err := specHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch spec from %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch spec from %s: HTTP %d", url, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// FetchSpecWithRetry downloads the swagger spec, retrying with exponential
+// backoff up to attempts times. It gives up early when ctx is done.
+func FetchSpecWithRetry(ctx context.Context, apiURL, specPath string, attempts int, logger zerolog.Logger) ([]byte, error) {
+	backoff := specFetchInitialBackoff
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		data, err := fetchSpec(ctx, apiURL, specPath)
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+		if attempt == attempts {
+			break
+		}
+
+		logger.Warn().Err(err).
+			Int("attempt", attempt).
+			Dur("retry_in", backoff).
+			Msg("spec fetch failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("fetch spec: %w (last error: %v)", ctx.Err(), lastErr)
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, specFetchMaxBackoff)
+	}
+	return nil, fmt.Errorf("fetch spec: giving up after %d attempts: %w", attempts, lastErr)
+}
+
+// loadCachedSpec reads the last good spec from path. It returns nil without
+// an error when no cache is configured or the file does not exist yet.
+func loadCachedSpec(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read cached spec %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// saveCachedSpec writes data to path via a temporary file and rename, so a
+// crash mid-write never leaves a truncated cache behind.
+func saveCachedSpec(path string, data []byte) error {
+	if path == "" {
+		return nil
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("write cached spec %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cached spec %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write cached spec %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write cached spec %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	enumspb "go.temporal.io/api/enums/v1"
+	temporalclient "go.temporal.io/sdk/client"
+)
+
+// ScheduleCollector exports the health of Temporal cron schedules. Schedules
+// are described on an interval by Run rather than on every scrape, so
+// Prometheus scrapes never reach Temporal.
+type ScheduleCollector struct {
+	tc     temporalclient.Client
+	ids    []string
+	logger zerolog.Logger
+
+	lastRun        *prometheus.GaugeVec
+	lastSuccess    *prometheus.GaugeVec
+	recentFailures *prometheus.GaugeVec
+	paused         *prometheus.GaugeVec
+
+	mu sync.Mutex
+	// outcomes caches the result of closed runs by run ID so each run is
+	// described once, not on every refresh while it stays in RecentActions.
+	outcomes map[string]runOutcome
+}
+
+type runOutcome struct {
+	success  bool
+	closedAt time.Time
+}
+
+// NewScheduleCollector creates a collector for the given schedule IDs. The
+// collector must be registered with a Prometheus registerer and started with Run.
+func NewScheduleCollector(tc temporalclient.Client, ids []string, logger zerolog.Logger) *ScheduleCollector {
+	return &ScheduleCollector{
+		tc:     tc,
+		ids:    ids,
+		logger: logger,
+		lastRun: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cron_schedule_last_run_timestamp",
+			Help: "Unix time the schedule last started a workflow",
+		}, []string{"id"}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cron_schedule_last_success_timestamp",
+			Help: "Unix time a workflow started by the schedule last completed successfully",
+		}, []string{"id"}),
+		recentFailures: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cron_schedule_recent_failures",
+			Help: "Number of failed, timed out, terminated or canceled runs among the schedule's recent actions",
+		}, []string{"id"}),
+		paused: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "cron_schedule_paused",
+			Help: "1 if the schedule is paused, 0 otherwise",
+		}, []string{"id"}),
+		outcomes: make(map[string]runOutcome),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *ScheduleCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.lastRun.Describe(ch)
+	c.lastSuccess.Describe(ch)
+	c.recentFailures.Describe(ch)
+	c.paused.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *ScheduleCollector) Collect(ch chan<- prometheus.Metric) {
+	c.lastRun.Collect(ch)
+	c.lastSuccess.Collect(ch)
+	c.recentFailures.Collect(ch)
+	c.paused.Collect(ch)
+}
+
+// Run refreshes the gauges immediately and then every interval until ctx is
+// cancelled. A non-positive interval refreshes once a minute.
+func (c *ScheduleCollector) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh describes every schedule and updates the gauges. A schedule that
+// cannot be described keeps its previous values.
+func (c *ScheduleCollector) Refresh(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := make(map[string]bool)
+	for _, id := range c.ids {
+		desc, err := c.tc.ScheduleClient().GetHandle(ctx, id).Describe(ctx)
+		if err != nil {
+			c.logger.Warn().Err(err).Str("id", id).Msg("failed to describe cron schedule")
+			continue
+		}
+
+		if desc.Schedule.State != nil && desc.Schedule.State.Paused {
+			c.paused.WithLabelValues(id).Set(1)
+		} else {
+			c.paused.WithLabelValues(id).Set(0)
+		}
+
+		var lastRun, lastSuccess time.Time
+		failures := 0
+		for _, action := range desc.Info.RecentActions {
+			if action.ActualTime.After(lastRun) {
+				lastRun = action.ActualTime
+			}
+			if action.StartWorkflowResult == nil {
+				continue
+			}
+			runID := action.StartWorkflowResult.FirstExecutionRunID
+			seen[runID] = true
+
+			outcome, closed := c.outcome(ctx, id, action.StartWorkflowResult)
+			if !closed {
+				continue
+			}
+			if !outcome.success {
+				failures++
+				continue
+			}
+			at := outcome.closedAt
+			if at.IsZero() {
+				at = action.ActualTime
+			}
+			if at.After(lastSuccess) {
+				lastSuccess = at
+			}
+		}
+
+		if !lastRun.IsZero() {
+			c.lastRun.WithLabelValues(id).Set(float64(lastRun.Unix()))
+		}
+		// A success that has aged out of RecentActions keeps the last
+		// exported value rather than resetting the gauge.
+		if !lastSuccess.IsZero() {
+			c.lastSuccess.WithLabelValues(id).Set(float64(lastSuccess.Unix()))
+		}
+		c.recentFailures.WithLabelValues(id).Set(float64(failures))
+	}
+
+	for runID := range c.outcomes {
+		if !seen[runID] {
+			delete(c.outcomes, runID)
+		}
+	}
+}
+
+// outcome returns the result of a scheduled run and whether it has closed.
+// Open runs and runs that cannot be described are not cached.
+func (c *ScheduleCollector) outcome(ctx context.Context, id string, run *temporalclient.ScheduleWorkflowExecution) (runOutcome, bool) {
+	if o, ok := c.outcomes[run.FirstExecutionRunID]; ok {
+		return o, true
+	}
+
+	resp, err := c.tc.DescribeWorkflowExecution(ctx, run.WorkflowID, run.FirstExecutionRunID)
+	if err != nil {
+		c.logger.Warn().Err(err).Str("id", id).Str("workflow_id", run.WorkflowID).Msg("failed to describe scheduled workflow run")
+		return runOutcome{}, false
+	}
+
+	info := resp.GetWorkflowExecutionInfo()
+	var o runOutcome
+	switch info.GetStatus() {
+	case enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED:
+		o.success = true
+	case enumspb.WORKFLOW_EXECUTION_STATUS_FAILED,
+		enumspb.WORKFLOW_EXECUTION_STATUS_TIMED_OUT,
+		enumspb.WORKFLOW_EXECUTION_STATUS_TERMINATED,
+		enumspb.WORKFLOW_EXECUTION_STATUS_CANCELED:
+	default:
+		// Still running, or continued as new and finishing in a later run.
+		return runOutcome{}, false
+	}
+	if info.GetCloseTime() != nil {
+		o.closedAt = info.GetCloseTime().AsTime()
+	}
+
+	c.outcomes[run.FirstExecutionRunID] = o
+	return o, true
+}
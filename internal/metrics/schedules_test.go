@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	enumspb "go.temporal.io/api/enums/v1"
+	workflowpb "go.temporal.io/api/workflow/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	temporalclient "go.temporal.io/sdk/client"
+	temporalmocks "go.temporal.io/sdk/mocks"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func scheduledRun(runID string, at time.Time) temporalclient.ScheduleActionResult {
+	return temporalclient.ScheduleActionResult{
+		ScheduleTime: at,
+		ActualTime:   at,
+		StartWorkflowResult: &temporalclient.ScheduleWorkflowExecution{
+			WorkflowID:          "cert-renewal-cron-" + runID,
+			FirstExecutionRunID: runID,
+		},
+	}
+}
+
+func workflowStatus(status enumspb.WorkflowExecutionStatus, closedAt time.Time) *workflowservice.DescribeWorkflowExecutionResponse {
+	info := &workflowpb.WorkflowExecutionInfo{Status: status}
+	if !closedAt.IsZero() {
+		info.CloseTime = timestamppb.New(closedAt)
+	}
+	return &workflowservice.DescribeWorkflowExecutionResponse{WorkflowExecutionInfo: info}
+}
+
+func gaugeValue(t *testing.T, vec *prometheus.GaugeVec, id string) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := vec.WithLabelValues(id).Write(&m); err != nil {
+		t.Fatal(err)
+	}
+	return m.GetGauge().GetValue()
+}
+
+func newScheduleMocks(desc *temporalclient.ScheduleDescription, err error) *temporalmocks.Client {
+	tc := &temporalmocks.Client{}
+	sc := &temporalmocks.ScheduleClient{}
+	handle := &temporalmocks.ScheduleHandle{}
+	tc.On("ScheduleClient").Return(sc)
+	sc.On("GetHandle", mock.Anything, "cert-renewal-cron").Return(handle)
+	handle.On("Describe", mock.Anything).Return(desc, err)
+	return tc
+}
+
+func TestScheduleCollector_Refresh(t *testing.T) {
+	t1 := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	t3 := t2.Add(time.Hour)
+
+	desc := &temporalclient.ScheduleDescription{
+		Info: temporalclient.ScheduleInfo{
+			RecentActions: []temporalclient.ScheduleActionResult{
+				scheduledRun("run-1", t1),
+				scheduledRun("run-2", t2),
+				scheduledRun("run-3", t3),
+			},
+		},
+	}
+	tc := newScheduleMocks(desc, nil)
+	tc.On("DescribeWorkflowExecution", mock.Anything, "cert-renewal-cron-run-1", "run-1").
+		Return(workflowStatus(enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED, t1.Add(5*time.Minute)), nil).Once()
+	tc.On("DescribeWorkflowExecution", mock.Anything, "cert-renewal-cron-run-2", "run-2").
+		Return(workflowStatus(enumspb.WORKFLOW_EXECUTION_STATUS_FAILED, t2.Add(time.Minute)), nil).Once()
+	tc.On("DescribeWorkflowExecution", mock.Anything, "cert-renewal-cron-run-3", "run-3").
+		Return(workflowStatus(enumspb.WORKFLOW_EXECUTION_STATUS_RUNNING, time.Time{}), nil)
+
+	c := NewScheduleCollector(tc, []string{"cert-renewal-cron"}, zerolog.Nop())
+	c.Refresh(context.Background())
+
+	assert.Equal(t, float64(t3.Unix()), gaugeValue(t, c.lastRun, "cert-renewal-cron"))
+	assert.Equal(t, float64(t1.Add(5*time.Minute).Unix()), gaugeValue(t, c.lastSuccess, "cert-renewal-cron"))
+	assert.Equal(t, float64(1), gaugeValue(t, c.recentFailures, "cert-renewal-cron"))
+	assert.Equal(t, float64(0), gaugeValue(t, c.paused, "cert-renewal-cron"))
+
+	// Closed runs are cached; only the running one is described again.
+	c.Refresh(context.Background())
+	tc.AssertNumberOfCalls(t, "DescribeWorkflowExecution", 4)
+}
+
+func TestScheduleCollector_Refresh_KeepsLastSuccess(t *testing.T) {
+	t1 := time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	tc := &temporalmocks.Client{}
+	sc := &temporalmocks.ScheduleClient{}
+	handle := &temporalmocks.ScheduleHandle{}
+	tc.On("ScheduleClient").Return(sc)
+	sc.On("GetHandle", mock.Anything, "cert-renewal-cron").Return(handle)
+	handle.On("Describe", mock.Anything).Return(&temporalclient.ScheduleDescription{
+		Info: temporalclient.ScheduleInfo{
+			RecentActions: []temporalclient.ScheduleActionResult{scheduledRun("run-1", t1)},
+		},
+	}, nil).Once()
+	handle.On("Describe", mock.Anything).Return(&temporalclient.ScheduleDescription{
+		Schedule: temporalclient.Schedule{State: &temporalclient.ScheduleState{Paused: true}},
+		Info: temporalclient.ScheduleInfo{
+			RecentActions: []temporalclient.ScheduleActionResult{scheduledRun("run-2", t2)},
+		},
+	}, nil).Once()
+	tc.On("DescribeWorkflowExecution", mock.Anything, "cert-renewal-cron-run-1", "run-1").
+		Return(workflowStatus(enumspb.WORKFLOW_EXECUTION_STATUS_COMPLETED, time.Time{}), nil)
+	tc.On("DescribeWorkflowExecution", mock.Anything, "cert-renewal-cron-run-2", "run-2").
+		Return(workflowStatus(enumspb.WORKFLOW_EXECUTION_STATUS_TIMED_OUT, t2), nil)
+
+	c := NewScheduleCollector(tc, []string{"cert-renewal-cron"}, zerolog.Nop())
+	c.Refresh(context.Background())
+	// Without a close time the start time is used.
+	assert.Equal(t, float64(t1.Unix()), gaugeValue(t, c.lastSuccess, "cert-renewal-cron"))
+
+	c.Refresh(context.Background())
+	assert.Equal(t, float64(t1.Unix()), gaugeValue(t, c.lastSuccess, "cert-renewal-cron"))
+	assert.Equal(t, float64(1), gaugeValue(t, c.recentFailures, "cert-renewal-cron"))
+	assert.Equal(t, float64(1), gaugeValue(t, c.paused, "cert-renewal-cron"))
+	assert.NotContains(t, c.outcomes, "run-1")
+}
+
+func TestScheduleCollector_Refresh_DescribeError(t *testing.T) {
+	tc := newScheduleMocks(nil, errors.New("unavailable"))
+
+	c := NewScheduleCollector(tc, []string{"cert-renewal-cron"}, zerolog.Nop())
+	c.Refresh(context.Background())
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(c)
+	families, err := reg.Gather()
+	assert.NoError(t, err)
+	assert.Empty(t, families)
+	tc.AssertNotCalled(t, "DescribeWorkflowExecution", mock.Anything, mock.Anything, mock.Anything)
+}
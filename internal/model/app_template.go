@@ -0,0 +1,39 @@
+package model
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// AppTemplate is a brand-defined webroot preset, e.g. WordPress or Laravel.
+// Creating a webroot from a template sets its runtime and public folder,
+// optionally creates a linked database and adds default env vars.
+type AppTemplate struct {
+	ID             string            `json:"id" db:"id"`
+	BrandID        string            `json:"brand_id" db:"brand_id"`
+	Name           string            `json:"name" db:"name"`
+	Description    string            `json:"description" db:"description"`
+	Runtime        string            `json:"runtime" db:"runtime"`
+	RuntimeVersion string            `json:"runtime_version" db:"runtime_version"`
+	RuntimeConfig  json.RawMessage   `json:"runtime_config" db:"runtime_config"`
+	PublicFolder   string            `json:"public_folder" db:"public_folder"`
+	CreateDatabase bool              `json:"create_database" db:"create_database"`
+	EnvVars        map[string]string `json:"env_vars" db:"env_vars"`
+	CreatedAt      time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// CreateWebrootFromTemplateParams is the argument of
+// CreateWebrootFromTemplateWorkflow. DatabaseID is empty if the template
+// creates no database.
+type CreateWebrootFromTemplateParams struct {
+	WebrootID  string `json:"webroot_id"`
+	DatabaseID string `json:"database_id,omitempty"`
+}
+
+// ExpandAppTemplateEnv replaces the {webroot_id} and {database_name}
+// placeholders in a template env var value.
+func ExpandAppTemplateEnv(value, webrootID, databaseName string) string {
+	return strings.NewReplacer("{webroot_id}", webrootID, "{database_name}", databaseName).Replace(value)
+}
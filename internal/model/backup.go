@@ -14,11 +14,61 @@ type Backup struct {
 	StatusMessage *string    `json:"status_message,omitempty"`
 	StartedAt   *time.Time `json:"started_at,omitempty"`
 	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	VerifyStatus  *string    `json:"verify_status,omitempty"`
+	VerifyMessage *string    `json:"verify_message,omitempty"`
+	VerifiedAt    *time.Time `json:"verified_at,omitempty"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
+// BackupDownloadURL is a signed, expiring URL for downloading a backup file.
+type BackupDownloadURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+	SingleUse bool      `json:"single_use"`
+}
+
 const (
 	BackupTypeWeb      = "web"
 	BackupTypeDatabase = "database"
 )
+
+// Backup verification outcomes, recorded in verify_status after a restore test.
+const (
+	BackupVerifyPassed = "passed"
+	BackupVerifyFailed = "failed"
+)
+
+// Types of BackupFile entries.
+const (
+	BackupFileTypeFile    = "file"
+	BackupFileTypeDir     = "dir"
+	BackupFileTypeSymlink = "symlink"
+)
+
+// BackupFile is an entry of a web backup archive. Path is relative to the
+// webroot's storage dir.
+type BackupFile struct {
+	Path    string    `json:"path"`
+	Type    string    `json:"type"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// BackupFileList is the contents of a web backup archive, in archive order.
+type BackupFileList struct {
+	BackupID string       `json:"backup_id"`
+	Files    []BackupFile `json:"files"`
+	// Truncated is set when the archive has more entries than were returned.
+	Truncated bool `json:"truncated"`
+}
+
+// BackupFilesRestore reports the outcome of restoring individual files from
+// a web backup.
+type BackupFilesRestore struct {
+	BackupID string   `json:"backup_id"`
+	Restored []string `json:"restored"`
+	// Skipped files exist on disk with a newer modification time than in the
+	// backup and were left alone; restore them with force to overwrite.
+	Skipped []string `json:"skipped"`
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// Backup schedule frequencies.
+const (
+	BackupFrequencyDaily  = "daily"
+	BackupFrequencyWeekly = "weekly"
+)
+
+// BackupSchedule backs up all of a tenant's webroots and databases at a fixed
+// frequency. RetentionDays replaces the platform backup retention for the
+// tenant's backups while the schedule is enabled.
+type BackupSchedule struct {
+	TenantID      string     `json:"tenant_id" db:"tenant_id"`
+	Frequency     string     `json:"frequency" db:"frequency"`
+	RetentionDays int        `json:"retention_days" db:"retention_days"`
+	Enabled       bool       `json:"enabled" db:"enabled"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty" db:"last_run_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Interval returns the time between scheduled runs.
+func (s BackupSchedule) Interval() time.Duration {
+	if s.Frequency == BackupFrequencyWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+// Due reports whether the hourly backup cron should back the tenant up at
+// now. Tenants are spread over the hours of the day by a hash of their ID so
+// backups don't all start at once. With a maintenance window the run falls on
+// one of the first hours of the window; without one it falls on a fixed UTC
+// hour. A run is due once most of the interval has passed since the last one,
+// so a run that started late doesn't push every later run back.
+func (s BackupSchedule) Due(now time.Time, window *TenantMaintenanceWindow) (bool, error) {
+	if !s.Enabled {
+		return false, nil
+	}
+	if s.LastRunAt != nil && now.Sub(*s.LastRunAt) < s.Interval()-time.Hour {
+		return false, nil
+	}
+
+	slot := s.slot()
+	if window == nil {
+		return now.UTC().Hour() == slot%24, nil
+	}
+
+	elapsed, inside, err := window.Elapsed(now)
+	if err != nil || !inside {
+		return false, err
+	}
+	// Keep runs in the first half of the window so they finish inside it.
+	hours := max(1, window.DurationMinutes/120)
+	return elapsed >= time.Duration(slot%hours)*time.Hour, nil
+}
+
+func (s BackupSchedule) slot() int {
+	h := fnv.New32a()
+	h.Write([]byte(s.TenantID))
+	return int(h.Sum32() % 1024)
+}
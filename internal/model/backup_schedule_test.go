@@ -0,0 +1,100 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupScheduleDue_NoWindow(t *testing.T) {
+	s := BackupSchedule{TenantID: "tenant-1", Frequency: BackupFrequencyDaily, Enabled: true}
+	hour := s.slot() % 24
+	slot := time.Date(2026, 6, 1, hour, 0, 0, 0, time.UTC)
+
+	due, err := s.Due(slot, nil)
+	require.NoError(t, err)
+	assert.True(t, due)
+
+	due, err = s.Due(slot.Add(time.Hour), nil)
+	require.NoError(t, err)
+	assert.False(t, due)
+}
+
+func TestBackupScheduleDue_Interval(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	s := BackupSchedule{TenantID: "tenant-1", Enabled: true}
+	now = now.Add(time.Duration(s.slot()%24) * time.Hour)
+
+	tests := []struct {
+		name      string
+		frequency string
+		lastRun   time.Duration
+		want      bool
+	}{
+		{"daily, ran yesterday", BackupFrequencyDaily, 24 * time.Hour, true},
+		{"daily, ran late yesterday", BackupFrequencyDaily, 23 * time.Hour, true},
+		{"daily, ran this morning", BackupFrequencyDaily, 6 * time.Hour, false},
+		{"weekly, ran yesterday", BackupFrequencyWeekly, 24 * time.Hour, false},
+		{"weekly, ran last week", BackupFrequencyWeekly, 7 * 24 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lastRun := now.Add(-tt.lastRun)
+			s.Frequency = tt.frequency
+			s.LastRunAt = &lastRun
+			due, err := s.Due(now, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, due)
+		})
+	}
+}
+
+func TestBackupScheduleDue_Disabled(t *testing.T) {
+	s := BackupSchedule{TenantID: "tenant-1", Frequency: BackupFrequencyDaily}
+	for h := range 24 {
+		due, err := s.Due(time.Date(2026, 6, 1, h, 0, 0, 0, time.UTC), nil)
+		require.NoError(t, err)
+		assert.False(t, due)
+	}
+}
+
+func TestBackupScheduleDue_Window(t *testing.T) {
+	window := &TenantMaintenanceWindow{StartTime: "01:00", DurationMinutes: 360, Timezone: "UTC"}
+	s := BackupSchedule{TenantID: "tenant-1", Frequency: BackupFrequencyDaily, Enabled: true}
+	first := 1 + s.slot()%3
+
+	var dueHours []int
+	for h := range 24 {
+		due, err := s.Due(time.Date(2026, 6, 1, h, 0, 0, 0, time.UTC), window)
+		require.NoError(t, err)
+		if due {
+			dueHours = append(dueHours, h)
+		}
+	}
+	// Due from the tenant's slot until the window closes; the first run
+	// records last_run_at and suppresses the rest.
+	require.NotEmpty(t, dueHours)
+	assert.Equal(t, first, dueHours[0])
+	assert.Equal(t, 6, dueHours[len(dueHours)-1])
+}
+
+func TestBackupScheduleDue_InvalidWindow(t *testing.T) {
+	s := BackupSchedule{TenantID: "tenant-1", Frequency: BackupFrequencyDaily, Enabled: true}
+	_, err := s.Due(time.Now(), &TenantMaintenanceWindow{StartTime: "01:00", DurationMinutes: 60, Timezone: "Mars/Olympus"})
+	assert.Error(t, err)
+}
+
+func TestTenantMaintenanceWindowElapsed(t *testing.T) {
+	w := TenantMaintenanceWindow{StartTime: "23:00", DurationMinutes: 180, Timezone: "UTC"}
+
+	elapsed, inside, err := w.Elapsed(time.Date(2026, 6, 2, 1, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, inside)
+	assert.Equal(t, 150*time.Minute, elapsed)
+
+	_, inside, err = w.Elapsed(time.Date(2026, 6, 2, 3, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.False(t, inside)
+}
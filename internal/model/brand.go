@@ -3,18 +3,56 @@ package model
 import "time"
 
 type Brand struct {
-	ID               string    `json:"id" db:"id"`
-	Name             string    `json:"name" db:"name"`
-	BaseHostname     string    `json:"base_hostname" db:"base_hostname"`
-	PrimaryNS        string    `json:"primary_ns" db:"primary_ns"`
-	SecondaryNS      string    `json:"secondary_ns" db:"secondary_ns"`
-	HostmasterEmail  string    `json:"hostmaster_email" db:"hostmaster_email"`
-	MailHostname     string    `json:"mail_hostname" db:"mail_hostname"`
-	SPFIncludes      string    `json:"spf_includes" db:"spf_includes"`
-	DKIMSelector     string    `json:"dkim_selector" db:"dkim_selector"`
-	DKIMPublicKey    string    `json:"dkim_public_key" db:"dkim_public_key"`
-	DMARCPolicy      string    `json:"dmarc_policy" db:"dmarc_policy"`
-	Status           string    `json:"status" db:"status"`
-	CreatedAt        time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+	ID                string         `json:"id" db:"id"`
+	Name              string         `json:"name" db:"name"`
+	BaseHostname      string         `json:"base_hostname" db:"base_hostname"`
+	PrimaryNS         string         `json:"primary_ns" db:"primary_ns"`
+	SecondaryNS       string         `json:"secondary_ns" db:"secondary_ns"`
+	HostmasterEmail   string         `json:"hostmaster_email" db:"hostmaster_email"`
+	MailHostname      string         `json:"mail_hostname" db:"mail_hostname"`
+	SPFIncludes       string         `json:"spf_includes" db:"spf_includes"`
+	DKIMSelector      string         `json:"dkim_selector" db:"dkim_selector"`
+	DKIMPublicKey     string         `json:"dkim_public_key" db:"dkim_public_key"`
+	DMARCPolicy       string         `json:"dmarc_policy" db:"dmarc_policy"`
+	DNSTTLs           map[string]int `json:"dns_ttls" db:"dns_ttls"`
+	DNSMigrationTTL   int            `json:"dns_migration_ttl" db:"dns_migration_ttl"`
+	PasswordMinLength int            `json:"password_min_length" db:"password_min_length"`
+	PasswordClasses   []string       `json:"password_classes" db:"password_classes"`
+	// Highest connection limits the brand's webroots may set; 0 means no
+	// maximum.
+	WebrootMaxConnsPerIP     int       `json:"webroot_max_conns_per_ip" db:"webroot_max_conns_per_ip"`
+	WebrootMaxRequestsPerSec int       `json:"webroot_max_requests_per_sec" db:"webroot_max_requests_per_sec"`
+	Status                   string    `json:"status" db:"status"`
+	CreatedAt                time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt                time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DefaultDNSTTL is the TTL of auto-managed records of types without a default
+// in DefaultDNSTTLs.
+const DefaultDNSTTL = 300
+
+// DefaultDNSMigrationTTL is the TTL auto A/AAAA records are lowered to while
+// a tenant moves to another shard.
+const DefaultDNSMigrationTTL = 60
+
+// DefaultDNSTTLs are the TTLs of auto-managed records per record type. Address
+// records stay short so they can follow LB changes; mail records rarely change.
+var DefaultDNSTTLs = map[string]int{
+	"A":     300,
+	"AAAA":  300,
+	"CNAME": 300,
+	"MX":    3600,
+	"TXT":   3600,
+}
+
+// AutoRecordTTL returns the TTL for an auto-managed record of the given type:
+// the brand's override if set, otherwise the platform default.
+func (b *Brand) AutoRecordTTL(recordType string) int {
+	if ttl, ok := b.DNSTTLs[recordType]; ok && ttl > 0 {
+		return ttl
+	}
+	if ttl, ok := DefaultDNSTTLs[recordType]; ok {
+		return ttl
+	}
+	return DefaultDNSTTL
 }
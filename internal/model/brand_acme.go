@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// BrandACMEConfig overrides the platform ACME CA for certificates of a
+// brand's FQDNs. The EAB HMAC key is write-only: it is stored encrypted and
+// never returned by the API.
+type BrandACMEConfig struct {
+	BrandID      string    `json:"brand_id" db:"brand_id"`
+	DirectoryURL string    `json:"directory_url" db:"directory_url"`
+	Email        string    `json:"email,omitempty" db:"email"`
+	EABKeyID     string    `json:"eab_key_id,omitempty" db:"eab_key_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBrandAutoRecordTTL(t *testing.T) {
+	b := &Brand{}
+	assert.Equal(t, 300, b.AutoRecordTTL("A"))
+	assert.Equal(t, 3600, b.AutoRecordTTL("MX"))
+	assert.Equal(t, DefaultDNSTTL, b.AutoRecordTTL("SRV"))
+
+	b.DNSTTLs = map[string]int{"A": 60, "TXT": 7200}
+	assert.Equal(t, 60, b.AutoRecordTTL("A"))
+	assert.Equal(t, 300, b.AutoRecordTTL("AAAA"))
+	assert.Equal(t, 7200, b.AutoRecordTTL("TXT"))
+}
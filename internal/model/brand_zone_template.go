@@ -0,0 +1,60 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// BrandZoneTemplate is a DNS record applied to every zone created for a
+// brand. Name and content may contain {variable} placeholders that are
+// expanded against the zone and brand when the zone is created.
+type BrandZoneTemplate struct {
+	ID        string    `json:"id" db:"id"`
+	BrandID   string    `json:"brand_id" db:"brand_id"`
+	Type      string    `json:"type" db:"type"`
+	Name      string    `json:"name" db:"name"`
+	Content   string    `json:"content" db:"content"`
+	TTL       int       `json:"ttl" db:"ttl"`
+	Priority  *int      `json:"priority,omitempty" db:"priority"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+var zoneTemplateVarPattern = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// ZoneTemplateVars returns the placeholder values available to zone
+// templates for a zone of the given brand.
+func ZoneTemplateVars(zone string, brand Brand) map[string]string {
+	return map[string]string{
+		"zone":             zone,
+		"base_hostname":    brand.BaseHostname,
+		"primary_ns":       brand.PrimaryNS,
+		"secondary_ns":     brand.SecondaryNS,
+		"hostmaster_email": brand.HostmasterEmail,
+		"mail_hostname":    brand.MailHostname,
+		"dkim_selector":    brand.DKIMSelector,
+		"dkim_public_key":  brand.DKIMPublicKey,
+		"dmarc_policy":     brand.DMARCPolicy,
+	}
+}
+
+// ExpandZoneTemplate replaces every {variable} in s with its value from vars.
+// It fails on placeholders that are not in vars.
+func ExpandZoneTemplate(s string, vars map[string]string) (string, error) {
+	var unknown string
+	out := zoneTemplateVarPattern.ReplaceAllStringFunc(s, func(m string) string {
+		v, ok := vars[m[1:len(m)-1]]
+		if !ok {
+			if unknown == "" {
+				unknown = m
+			}
+			return m
+		}
+		return v
+	})
+	if unknown != "" {
+		return "", fmt.Errorf("unknown template variable %s", unknown)
+	}
+	return out, nil
+}
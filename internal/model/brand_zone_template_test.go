@@ -0,0 +1,30 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandZoneTemplate(t *testing.T) {
+	vars := ZoneTemplateVars("example.com", Brand{MailHostname: "mail.hosting.test", DKIMSelector: "hosting"})
+
+	out, err := ExpandZoneTemplate("{dkim_selector}._domainkey.{zone}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "hosting._domainkey.example.com", out)
+
+	out, err = ExpandZoneTemplate("{mail_hostname}", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "mail.hosting.test", out)
+
+	out, err = ExpandZoneTemplate("v=spf1 mx ~all", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "v=spf1 mx ~all", out)
+}
+
+func TestExpandZoneTemplate_UnknownVariable(t *testing.T) {
+	_, err := ExpandZoneTemplate("{zone} {tenant}", ZoneTemplateVars("example.com", Brand{}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "{tenant}")
+}
@@ -22,3 +22,25 @@ const (
 	CertTypeLetsEncrypt = "lets_encrypt"
 	CertTypeCustom      = "custom"
 )
+
+// Statuses of an on-demand certificate renewal.
+const (
+	CertRenewalRunning   = "running"
+	CertRenewalCompleted = "completed"
+	CertRenewalFailed    = "failed"
+)
+
+// CertificateRenewal describes a forced renewal of a Let's Encrypt
+// certificate. It is derived from the renewal workflow execution rather than
+// stored, and on success points at the newly issued certificate.
+type CertificateRenewal struct {
+	CertificateID    string     `json:"certificate_id"`
+	WorkflowID       string     `json:"workflow_id"`
+	RunID            string     `json:"run_id"`
+	Status           string     `json:"status"`
+	Error            string     `json:"error,omitempty"`
+	NewCertificateID string     `json:"new_certificate_id,omitempty"`
+	ExpiresAt        *time.Time `json:"expires_at,omitempty"`
+	StartedAt        *time.Time `json:"started_at,omitempty"`
+	ClosedAt         *time.Time `json:"closed_at,omitempty"`
+}
@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Statuses of an FQDN in a bulk certificate issuance.
+const (
+	BulkCertQueued      = "queued"
+	BulkCertIssuing     = "issuing"
+	BulkCertIssued      = "issued"
+	BulkCertRateLimited = "rate_limited"
+	BulkCertFailed      = "failed"
+)
+
+// BulkCertProgress reports a BulkProvisionCertsWorkflow. It is kept in the
+// workflow state and returned as the progress of its operation. Items are in
+// issuance order: FQDNs without a certificate first, then early renewals.
+type BulkCertProgress struct {
+	Total     int            `json:"total"`
+	Issued    int            `json:"issued"`
+	Failed    int            `json:"failed"`
+	Remaining int            `json:"remaining"`
+	Items     []BulkCertItem `json:"items"`
+}
+
+// BulkCertItem is the issuance state of one FQDN. Rate-limited items are
+// retried at NextAttemptAt; NextAttemptAt is also set for queued items held
+// back by the per-domain limit.
+type BulkCertItem struct {
+	FQDNID           string     `json:"fqdn_id"`
+	FQDN             string     `json:"fqdn"`
+	RegisteredDomain string     `json:"registered_domain"`
+	Renewal          bool       `json:"renewal"`
+	Status           string     `json:"status"`
+	Attempts         int        `json:"attempts"`
+	Error            string     `json:"error,omitempty"`
+	NextAttemptAt    *time.Time `json:"next_attempt_at,omitempty"`
+}
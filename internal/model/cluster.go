@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -15,3 +16,51 @@ type Cluster struct {
 	CreatedAt time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
 }
+
+// Tenant UIDs are allocated from DefaultTenantUIDMin–DefaultTenantUIDMax
+// unless the cluster config sets its own range. UIDs below MinTenantUID are
+// reserved for system accounts.
+const (
+	DefaultTenantUIDMin = 5000
+	DefaultTenantUIDMax = 59999
+	MinTenantUID        = 1000
+)
+
+// ClusterConfig holds the settings read from a cluster's config document.
+// Unknown keys are ignored.
+type ClusterConfig struct {
+	TenantUIDMin int `json:"tenant_uid_min,omitempty"`
+	TenantUIDMax int `json:"tenant_uid_max,omitempty"`
+}
+
+// ParseClusterConfig decodes a cluster config document. An empty document is
+// valid and yields the defaults.
+func ParseClusterConfig(raw json.RawMessage) (ClusterConfig, error) {
+	var cfg ClusterConfig
+	if len(raw) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return cfg, fmt.Errorf("invalid cluster config: %w", err)
+	}
+	return cfg, nil
+}
+
+// TenantUIDRange returns the inclusive range tenant UIDs are allocated from,
+// with unset bounds replaced by the defaults.
+func (c ClusterConfig) TenantUIDRange() (lo, hi int, err error) {
+	lo, hi = c.TenantUIDMin, c.TenantUIDMax
+	if lo == 0 {
+		lo = DefaultTenantUIDMin
+	}
+	if hi == 0 {
+		hi = DefaultTenantUIDMax
+	}
+	if lo < MinTenantUID {
+		return 0, 0, fmt.Errorf("tenant_uid_min %d is below %d", lo, MinTenantUID)
+	}
+	if hi < lo {
+		return 0, 0, fmt.Errorf("tenant_uid_max %d is below tenant_uid_min %d", hi, lo)
+	}
+	return lo, hi, nil
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterConfig_TenantUIDRange_Defaults(t *testing.T) {
+	cfg, err := ParseClusterConfig(json.RawMessage(`{"max_nodes": 10}`))
+	require.NoError(t, err)
+
+	lo, hi, err := cfg.TenantUIDRange()
+	require.NoError(t, err)
+	assert.Equal(t, DefaultTenantUIDMin, lo)
+	assert.Equal(t, DefaultTenantUIDMax, hi)
+}
+
+func TestClusterConfig_TenantUIDRange_Configured(t *testing.T) {
+	cfg, err := ParseClusterConfig(json.RawMessage(`{"tenant_uid_min": 20000, "tenant_uid_max": 29999}`))
+	require.NoError(t, err)
+
+	lo, hi, err := cfg.TenantUIDRange()
+	require.NoError(t, err)
+	assert.Equal(t, 20000, lo)
+	assert.Equal(t, 29999, hi)
+}
+
+func TestClusterConfig_TenantUIDRange_Invalid(t *testing.T) {
+	_, _, err := ClusterConfig{TenantUIDMin: 500}.TenantUIDRange()
+	assert.ErrorContains(t, err, "below 1000")
+
+	_, _, err = ClusterConfig{TenantUIDMin: 70000}.TenantUIDRange()
+	assert.ErrorContains(t, err, "tenant_uid_max")
+
+	_, err = ParseClusterConfig(json.RawMessage(`{"tenant_uid_min": "x"}`))
+	assert.Error(t, err)
+}
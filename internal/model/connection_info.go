@@ -0,0 +1,27 @@
+package model
+
+// DSNPasswordPlaceholder stands in for the password in connection info
+// DSNs. Passwords are only stored as hashes, so the API cannot fill it in.
+const DSNPasswordPlaceholder = "{password}"
+
+// ConnectionInfo describes how a tenant reaches a database or Valkey
+// instance: Host is the tenant's ULA address on the shard primary, reachable
+// through the tenant's WireGuard tunnel, and ProxyCommand forwards it to
+// LocalPort on the tenant's machine.
+type ConnectionInfo struct {
+	Type         string           `json:"type"`
+	Host         string           `json:"host"`
+	Port         int              `json:"port"`
+	Database     string           `json:"database,omitempty"`
+	LocalPort    int              `json:"local_port"`
+	ProxyCommand string           `json:"proxy_command"`
+	Users        []ConnectionUser `json:"users"`
+}
+
+// ConnectionUser is a DSN template for one user. DSN connects through the
+// tunnel directly, LocalDSN through the proxy.
+type ConnectionUser struct {
+	Username string `json:"username"`
+	DSN      string `json:"dsn"`
+	LocalDSN string `json:"local_dsn"`
+}
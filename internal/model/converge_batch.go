@@ -0,0 +1,52 @@
+package model
+
+import "time"
+
+// Batch and per-shard statuses for a cluster convergence batch.
+const (
+	ConvergeBatchRunning   = "running"
+	ConvergeBatchCompleted = "completed"
+	ConvergeBatchFailed    = "failed"
+
+	ConvergeShardPending   = "pending"
+	ConvergeShardRunning   = "running"
+	ConvergeShardSucceeded = "succeeded"
+	ConvergeShardFailed    = "failed"
+)
+
+// ConvergeBatchQuery is the workflow query name that returns the current
+// ConvergeBatch state of a ConvergeClusterWorkflow.
+const ConvergeBatchQuery = "converge-batch"
+
+// ConvergeBatch tracks a cluster-wide convergence run. It is kept in the
+// ConvergeClusterWorkflow state and read through a workflow query.
+type ConvergeBatch struct {
+	ID            string               `json:"id"`
+	ClusterID     string               `json:"cluster_id"`
+	Status        string               `json:"status"`
+	MaxConcurrent int                  `json:"max_concurrent"`
+	Total         int                  `json:"total"`
+	Completed     int                  `json:"completed"`
+	Succeeded     int                  `json:"succeeded"`
+	Failed        int                  `json:"failed"`
+	Shards        []ConvergeBatchShard `json:"shards"`
+}
+
+// ConvergeBatchShard is the per-shard result within a ConvergeBatch.
+type ConvergeBatchShard struct {
+	ShardID   string `json:"shard_id"`
+	ShardName string `json:"shard_name"`
+	Role      string `json:"role"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ShardConvergence reports whether a ConvergeShardWorkflow is currently
+// running for a shard. It is derived from the workflow execution.
+type ShardConvergence struct {
+	ShardID    string     `json:"shard_id"`
+	Running    bool       `json:"running"`
+	WorkflowID string     `json:"workflow_id"`
+	RunID      string     `json:"run_id,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+}
@@ -2,6 +2,10 @@ package model
 
 import "time"
 
+// DefaultCronTimezone is the timezone cron schedules are evaluated in unless
+// a job sets its own.
+const DefaultCronTimezone = "UTC"
+
 type CronJob struct {
 	ID                  string    `json:"id"`
 	TenantID            string    `json:"tenant_id"`
@@ -12,6 +16,7 @@ type CronJob struct {
 	Enabled             bool      `json:"enabled"`
 	TimeoutSeconds      int       `json:"timeout_seconds"`
 	MaxMemoryMB         int       `json:"max_memory_mb"`
+	Timezone            string    `json:"timezone"`
 	ConsecutiveFailures int       `json:"consecutive_failures"`
 	MaxFailures         int       `json:"max_failures"`
 	Status              string    `json:"status"`
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+type CronJobEnvVar struct {
+	ID        string    `json:"id"`
+	CronJobID string    `json:"cron_job_id"`
+	Name      string    `json:"name"`
+	Value     string    `json:"value"`
+	IsSecret  bool      `json:"is_secret"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
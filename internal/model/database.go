@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Database struct {
 	ID             string  `json:"id" db:"id"`
@@ -8,6 +11,8 @@ type Database struct {
 	SubscriptionID string  `json:"subscription_id" db:"subscription_id"`
 	ShardID        *string `json:"shard_id,omitempty" db:"shard_id"`
 	NodeID         *string `json:"node_id,omitempty" db:"node_id"`
+	Charset        string  `json:"charset" db:"charset"`
+	Collation      string  `json:"collation" db:"collation"`
 	Status         string  `json:"status" db:"status"`
 	StatusMessage  *string `json:"status_message,omitempty" db:"status_message"`
 	SuspendReason  string  `json:"suspend_reason" db:"suspend_reason"`
@@ -15,3 +20,55 @@ type Database struct {
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 	ShardName      *string   `json:"shard_name,omitempty" db:"-"`
 }
+
+// Default MySQL character set and collation for new databases.
+const (
+	DefaultDatabaseCharset   = "utf8mb4"
+	DefaultDatabaseCollation = "utf8mb4_unicode_ci"
+)
+
+// DatabaseCollations is the allowlist of character sets and their collations
+// accepted for new databases. The values end up in a CREATE DATABASE
+// statement, so nothing outside this list may reach the node agent.
+var DatabaseCollations = map[string][]string{
+	"utf8mb4": {"utf8mb4_unicode_ci", "utf8mb4_general_ci", "utf8mb4_unicode_520_ci", "utf8mb4_0900_ai_ci", "utf8mb4_bin"},
+	"utf8mb3": {"utf8mb3_general_ci", "utf8mb3_unicode_ci", "utf8mb3_bin"},
+	"latin1":  {"latin1_swedish_ci", "latin1_general_ci", "latin1_bin"},
+	"ascii":   {"ascii_general_ci", "ascii_bin"},
+	"binary":  {"binary"},
+}
+
+// ResolveDatabaseCharset applies defaults and checks the pair against
+// DatabaseCollations. An empty charset means utf8mb4; an empty collation means
+// the charset's default (the first entry in its list).
+func ResolveDatabaseCharset(charset, collation string) (string, string, error) {
+	if charset == "" {
+		charset = DefaultDatabaseCharset
+	}
+	collations, ok := DatabaseCollations[charset]
+	if !ok {
+		return "", "", fmt.Errorf("unsupported charset %q", charset)
+	}
+	if collation == "" {
+		return charset, collations[0], nil
+	}
+	for _, c := range collations {
+		if c == collation {
+			return charset, collation, nil
+		}
+	}
+	return "", "", fmt.Errorf("collation %q is not valid for charset %q", collation, charset)
+}
+
+// DatabaseConnection is a live MySQL connection (one SHOW PROCESSLIST row)
+// belonging to one of a database's users.
+type DatabaseConnection struct {
+	ID          int64  `json:"id"`
+	User        string `json:"user"`
+	Host        string `json:"host"`
+	Database    string `json:"database,omitempty"`
+	Command     string `json:"command"`
+	TimeSeconds int64  `json:"time_seconds"`
+	State       string `json:"state,omitempty"`
+	Query       string `json:"query,omitempty"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveDatabaseCharset_Defaults(t *testing.T) {
+	charset, collation, err := ResolveDatabaseCharset("", "")
+	require.NoError(t, err)
+	assert.Equal(t, "utf8mb4", charset)
+	assert.Equal(t, "utf8mb4_unicode_ci", collation)
+
+	charset, collation, err = ResolveDatabaseCharset("latin1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "latin1", charset)
+	assert.Equal(t, "latin1_swedish_ci", collation)
+}
+
+func TestResolveDatabaseCharset_Explicit(t *testing.T) {
+	charset, collation, err := ResolveDatabaseCharset("utf8mb4", "utf8mb4_bin")
+	require.NoError(t, err)
+	assert.Equal(t, "utf8mb4", charset)
+	assert.Equal(t, "utf8mb4_bin", collation)
+}
+
+func TestResolveDatabaseCharset_Rejects(t *testing.T) {
+	_, _, err := ResolveDatabaseCharset("utf8mb4; DROP DATABASE x", "")
+	assert.ErrorContains(t, err, "unsupported charset")
+
+	_, _, err = ResolveDatabaseCharset("utf8mb4", "latin1_swedish_ci")
+	assert.ErrorContains(t, err, "not valid for charset")
+
+	_, _, err = ResolveDatabaseCharset("", "utf8mb4_bin` COLLATE x")
+	assert.Error(t, err)
+}
@@ -72,7 +72,13 @@ type DesiredDaemon struct {
 type DesiredFQDN struct {
 	FQDN       string `json:"fqdn"`
 	SSLEnabled bool   `json:"ssl_enabled"`
-	Status     string `json:"status"`
+	ForceHTTPS bool   `json:"force_https"`
+	// RedirectTarget is set when the FQDN redirects instead of serving the
+	// webroot.
+	RedirectTarget       *string `json:"redirect_target,omitempty"`
+	RedirectCode         int     `json:"redirect_code,omitempty"`
+	RedirectPreservePath bool    `json:"redirect_preserve_path,omitempty"`
+	Status               string  `json:"status"`
 }
 
 // DesiredDatabase is a database in the desired state for database shards.
@@ -93,12 +99,13 @@ type DesiredDBUser struct {
 
 // DesiredValkeyInstance is a Valkey instance in the desired state.
 type DesiredValkeyInstance struct {
-	ID           string              `json:"id"`
-	Port         int                 `json:"port"`
-	PasswordHash string              `json:"password_hash"`
-	MaxMemoryMB  int                 `json:"max_memory_mb"`
-	Status       string              `json:"status"`
-	Users        []DesiredValkeyUser `json:"users,omitempty"`
+	ID              string              `json:"id"`
+	Port            int                 `json:"port"`
+	PasswordHash    string              `json:"password_hash"`
+	MaxMemoryMB     int                 `json:"max_memory_mb"`
+	PersistenceMode string              `json:"persistence_mode"`
+	Status          string              `json:"status"`
+	Users           []DesiredValkeyUser `json:"users,omitempty"`
 }
 
 // DesiredValkeyUser is a Valkey user in the desired state.
@@ -13,4 +13,43 @@ type EmailAccount struct {
 	StatusMessage *string   `json:"status_message,omitempty" db:"status_message"`
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	// PasswordHash is the bcrypt hash of the initial password, if any. It is
+	// only read by the provisioning workflow, never by the API.
+	PasswordHash string `json:"password_hash,omitempty" db:"password_hash"`
+}
+
+// Row statuses of a bulk email account import.
+const (
+	EmailImportValid   = "valid"
+	EmailImportInvalid = "invalid"
+	EmailImportCreated = "created"
+	EmailImportFailed  = "failed"
+)
+
+// EmailAccountImportRow is one account parsed from an import CSV. Error is
+// set when the row could not be parsed.
+type EmailAccountImportRow struct {
+	Line        int
+	Address     string
+	DisplayName string
+	QuotaBytes  int64
+	Password    string
+	Error       string
+}
+
+// EmailAccountImportResult is the outcome of one row of an import.
+type EmailAccountImportResult struct {
+	Line    int    `json:"line"`
+	Address string `json:"address"`
+	Status  string `json:"status"`
+	ID      string `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// EmailAccountImport is the outcome of a bulk email account import.
+type EmailAccountImport struct {
+	DryRun  bool                       `json:"dry_run"`
+	Created int                        `json:"created"`
+	Failed  int                        `json:"failed"`
+	Rows    []EmailAccountImportResult `json:"rows"`
 }
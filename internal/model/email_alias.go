@@ -1,13 +1,31 @@
 package model
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 type EmailAlias struct {
-	ID             string    `json:"id" db:"id"`
-	EmailAccountID string    `json:"email_account_id" db:"email_account_id"`
-	Address        string    `json:"address" db:"address"`
-	Status         string    `json:"status" db:"status"`
-	StatusMessage  *string   `json:"status_message,omitempty" db:"status_message"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	ID             string `json:"id" db:"id"`
+	EmailAccountID string `json:"email_account_id" db:"email_account_id"`
+	Address        string `json:"address" db:"address"`
+	// CatchAll marks the domain's catch-all alias. Its address is "@domain"
+	// and it receives mail for every address on the domain that no account
+	// or other alias claims.
+	CatchAll      bool      `json:"catch_all" db:"-"`
+	Status        string    `json:"status" db:"status"`
+	StatusMessage *string   `json:"status_message,omitempty" db:"status_message"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// CatchAllAddress returns the alias address that catches all mail for domain,
+// in the form Stalwart expects.
+func CatchAllAddress(domain string) string {
+	return "@" + domain
+}
+
+// IsCatchAllAddress reports whether address is a catch-all alias address.
+func IsCatchAllAddress(address string) bool {
+	return strings.HasPrefix(address, "@")
 }
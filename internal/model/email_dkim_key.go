@@ -0,0 +1,30 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// EmailDKIMKey is a DKIM signing key owned by a single mail domain (FQDN).
+// Once active it takes precedence over the brand-level DKIM key.
+type EmailDKIMKey struct {
+	ID            string    `json:"id" db:"id"`
+	FQDNID        string    `json:"fqdn_id" db:"fqdn_id"`
+	Selector      string    `json:"selector" db:"selector"`
+	PrivateKeyPEM string    `json:"-" db:"private_key_pem"`
+	PublicKey     string    `json:"public_key" db:"public_key"`
+	Status        string    `json:"status" db:"status"`
+	StatusMessage *string   `json:"status_message,omitempty" db:"status_message"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DKIMRecordName returns the TXT record name a DKIM key is published under.
+func DKIMRecordName(selector, domain string) string {
+	return fmt.Sprintf("%s._domainkey.%s", selector, domain)
+}
+
+// DKIMRecordContent returns the TXT record value for an RSA DKIM public key.
+func DKIMRecordContent(publicKey string) string {
+	return fmt.Sprintf("v=DKIM1; k=rsa; p=%s", publicKey)
+}
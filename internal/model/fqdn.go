@@ -1,15 +1,80 @@
 package model
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 type FQDN struct {
-	ID            string    `json:"id" db:"id"`
-	TenantID      string    `json:"tenant_id" db:"tenant_id"`
-	FQDN          string    `json:"fqdn" db:"fqdn"`
-	WebrootID     *string   `json:"webroot_id" db:"webroot_id"`
-	SSLEnabled    bool      `json:"ssl_enabled" db:"ssl_enabled"`
-	Status        string    `json:"status" db:"status"`
-	StatusMessage *string   `json:"status_message,omitempty" db:"status_message"`
-	CreatedAt     time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+	ID         string  `json:"id" db:"id"`
+	TenantID   string  `json:"tenant_id" db:"tenant_id"`
+	FQDN       string  `json:"fqdn" db:"fqdn"`
+	WebrootID  *string `json:"webroot_id" db:"webroot_id"`
+	SSLEnabled bool    `json:"ssl_enabled" db:"ssl_enabled"`
+	ForceHTTPS bool    `json:"force_https" db:"force_https"`
+	// RedirectTarget makes the FQDN a domain alias: requests are answered
+	// with a RedirectCode redirect to this host instead of being served by
+	// the webroot. RedirectPreservePath keeps the request path and query.
+	RedirectTarget       *string `json:"redirect_target,omitempty" db:"redirect_target"`
+	RedirectCode         int     `json:"redirect_code" db:"redirect_code"`
+	RedirectPreservePath bool    `json:"redirect_preserve_path" db:"redirect_preserve_path"`
+	// IPMode selects the address families the FQDN's auto DNS records
+	// publish; see IPModeDualStack and IPModeIPv6Only.
+	IPMode        string  `json:"ip_mode" db:"ip_mode"`
+	Status        string  `json:"status" db:"status"`
+	StatusMessage *string `json:"status_message,omitempty" db:"status_message"`
+	// MaxEmailAccounts and EmailQuotaBytes cap the number of email
+	// accounts under the FQDN and the sum of their quotas. Nil is unlimited.
+	MaxEmailAccounts *int      `json:"max_email_accounts,omitempty" db:"max_email_accounts"`
+	EmailQuotaBytes  *int64    `json:"email_quota_bytes,omitempty" db:"email_quota_bytes"`
+	CreatedAt        time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IP modes of an FQDN.
+const (
+	// IPModeDualStack publishes A and AAAA records for the cluster's IPv4
+	// and IPv6 load balancer addresses. It is the default.
+	IPModeDualStack = "dual_stack"
+	// IPModeIPv6Only publishes AAAA records only, so the FQDN uses no IPv4
+	// address. Clients without IPv6 cannot reach it.
+	IPModeIPv6Only = "ipv6_only"
+)
+
+// ValidateRedirect checks the redirect settings of a domain alias. The
+// redirect is served from the nginx config of the bound webroot, so a
+// redirecting FQDN must be bound to one.
+func (f FQDN) ValidateRedirect() error {
+	if f.RedirectTarget == nil {
+		return nil
+	}
+	if f.WebrootID == nil {
+		return errors.New("a redirecting FQDN must be bound to a webroot")
+	}
+	if strings.EqualFold(strings.TrimSuffix(*f.RedirectTarget, "."), strings.TrimSuffix(f.FQDN, ".")) {
+		return fmt.Errorf("fqdn %s cannot redirect to itself", f.FQDN)
+	}
+	return nil
+}
+
+// IsWildcardFQDN reports whether name is a wildcard binding such as
+// "*.example.com".
+func IsWildcardFQDN(name string) bool {
+	return strings.HasPrefix(name, "*.")
+}
+
+// WildcardCovers reports whether the wildcard FQDN matches name. As in nginx
+// server_name and TLS certificates, a wildcard covers exactly one label:
+// "*.example.com" matches "www.example.com" but neither "example.com" nor
+// "a.b.example.com".
+func WildcardCovers(wildcard, name string) bool {
+	if !IsWildcardFQDN(wildcard) || IsWildcardFQDN(name) {
+		return false
+	}
+	base := strings.TrimSuffix(strings.ToLower(wildcard[2:]), ".")
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	label, rest, ok := strings.Cut(name, ".")
+	return ok && label != "" && rest == base
 }
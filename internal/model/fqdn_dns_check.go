@@ -0,0 +1,40 @@
+package model
+
+// DNS check statuses, per server and overall.
+const (
+	DNSCheckMatch     = "match"
+	DNSCheckMismatch  = "mismatch"
+	DNSCheckNoRecords = "no_records"
+	DNSCheckError     = "error"
+)
+
+// DNS check server kinds.
+const (
+	DNSServerAuthoritative = "authoritative"
+	DNSServerPublic        = "public"
+)
+
+// FQDNDNSCheck is the result of resolving an FQDN's A/AAAA records against
+// its authoritative nameservers and public resolvers, compared with the
+// addresses of its cluster's load balancers.
+type FQDNDNSCheck struct {
+	FQDN     string   `json:"fqdn"`
+	Expected []string `json:"expected"`
+	// Zone is the zone whose nameservers were queried, empty if none was found.
+	Zone    string           `json:"zone,omitempty"`
+	Status  string           `json:"status"`
+	Results []DNSCheckResult `json:"results"`
+}
+
+// DNSCheckResult is what one nameserver or resolver answered. Status is
+// match when every returned address is an expected one.
+type DNSCheckResult struct {
+	Server    string   `json:"server"`
+	Kind      string   `json:"kind"`
+	Addresses []string `json:"addresses"`
+	CNAME     string   `json:"cname,omitempty"`
+	// TTL is the lowest TTL of the returned address records, in seconds.
+	TTL    *uint32 `json:"ttl,omitempty"`
+	Status string  `json:"status"`
+	Error  string  `json:"error,omitempty"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsWildcardFQDN(t *testing.T) {
+	assert.True(t, IsWildcardFQDN("*.example.com"))
+	assert.False(t, IsWildcardFQDN("www.example.com"))
+	assert.False(t, IsWildcardFQDN("example.*.com"))
+}
+
+func TestWildcardCovers(t *testing.T) {
+	assert.True(t, WildcardCovers("*.example.com", "www.example.com"))
+	assert.True(t, WildcardCovers("*.example.com", "WWW.Example.com."))
+	assert.False(t, WildcardCovers("*.example.com", "example.com"))
+	assert.False(t, WildcardCovers("*.example.com", "a.b.example.com"))
+	assert.False(t, WildcardCovers("*.example.com", "www.example.org"))
+	assert.False(t, WildcardCovers("*.example.com", "*.example.com"))
+	assert.False(t, WildcardCovers("www.example.com", "www.example.com"))
+}
+
+func TestFQDNValidateRedirect(t *testing.T) {
+	webrootID := "webroot-1"
+	target := func(s string) *string { return &s }
+
+	assert.NoError(t, FQDN{FQDN: "www.example.com"}.ValidateRedirect())
+	assert.NoError(t, FQDN{FQDN: "www.example.com", WebrootID: &webrootID, RedirectTarget: target("example.com")}.ValidateRedirect())
+	assert.ErrorContains(t, FQDN{FQDN: "www.example.com", RedirectTarget: target("example.com")}.ValidateRedirect(), "bound to a webroot")
+	assert.ErrorContains(t, FQDN{FQDN: "www.example.com", WebrootID: &webrootID, RedirectTarget: target("WWW.example.com.")}.ValidateRedirect(), "itself")
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyKey is a stored Idempotency-Key and the response of the request
+// that first used it. StatusCode is nil while that request is still running.
+// ResponseWithheld is set when the response carried credentials, in which
+// case its body is not stored.
+type IdempotencyKey struct {
+	APIKeyID         string      `json:"api_key_id"`
+	Key              string      `json:"key"`
+	RequestHash      string      `json:"request_hash"`
+	StatusCode       *int        `json:"status_code,omitempty"`
+	ResponseHeaders  http.Header `json:"response_headers"`
+	ResponseBody     []byte      `json:"response_body"`
+	ResponseWithheld bool        `json:"response_withheld"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
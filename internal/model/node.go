@@ -27,6 +27,10 @@ type Node struct {
 	// Populated by GetByID and ListByCluster — all shard assignments for this node.
 	Shards []NodeShardAssignment `json:"shards,omitempty"`
 
+	// Populated by GetByID and ListByCluster from the agent's last
+	// capability report. Nil if the agent has not reported yet.
+	AgentVersion *string `json:"agent_version,omitempty"`
+
 	// Transient fields — populated by ListByShard from the join row.
 	// Not stored in the nodes table; used by convergence workflows.
 	ShardID    *string `json:"shard_id,omitempty"`
@@ -52,3 +56,92 @@ type DriftEvent struct {
 	Detail    string    `json:"detail,omitempty" db:"detail"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 }
+
+// NodeDiagnostics is the structured report of a node self-test. Healthy is
+// true only if every check passed.
+type NodeDiagnostics struct {
+	NodeID    string                `json:"node_id"`
+	Healthy   bool                  `json:"healthy"`
+	CheckedAt time.Time             `json:"checked_at"`
+	Checks    []NodeDiagnosticCheck `json:"checks"`
+}
+
+// NodeDiagnosticCheck is the outcome of one node self-test.
+type NodeDiagnosticCheck struct {
+	Name       string `json:"name"`
+	OK         bool   `json:"ok"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// NodeStats is the latest CPU, memory and disk snapshot collected from a node
+// agent. Metrics the agent could not read are nil and described in Errors.
+type NodeStats struct {
+	NodeID            string          `json:"node_id" db:"node_id"`
+	CPUCount          int             `json:"cpu_count" db:"cpu_count"`
+	Load1             *float64        `json:"load_1,omitempty" db:"load_1"`
+	Load5             *float64        `json:"load_5,omitempty" db:"load_5"`
+	Load15            *float64        `json:"load_15,omitempty" db:"load_15"`
+	MemTotalBytes     *int64          `json:"mem_total_bytes,omitempty" db:"mem_total_bytes"`
+	MemAvailableBytes *int64          `json:"mem_available_bytes,omitempty" db:"mem_available_bytes"`
+	SwapTotalBytes    *int64          `json:"swap_total_bytes,omitempty" db:"swap_total_bytes"`
+	SwapFreeBytes     *int64          `json:"swap_free_bytes,omitempty" db:"swap_free_bytes"`
+	Disks             []NodeDiskStats `json:"disks" db:"disks"`
+	Errors            []string        `json:"errors,omitempty" db:"errors"`
+	CollectedAt       time.Time       `json:"collected_at" db:"collected_at"`
+}
+
+// NodeDiskStats is the usage of one mounted filesystem on a node.
+type NodeDiskStats struct {
+	Path       string  `json:"path"`
+	FSType     string  `json:"fs_type"`
+	TotalBytes int64   `json:"total_bytes"`
+	UsedBytes  int64   `json:"used_bytes"`
+	FreeBytes  int64   `json:"free_bytes"`
+	UsedPct    float64 `json:"used_pct"`
+}
+
+// NodeCapabilities is what a node agent last reported about itself: its
+// version and the names of the Temporal activities it registers.
+type NodeCapabilities struct {
+	NodeID       string    `json:"node_id" db:"node_id"`
+	AgentVersion string    `json:"agent_version" db:"agent_version"`
+	Activities   []string  `json:"activities" db:"activities"`
+	ReportedAt   time.Time `json:"reported_at" db:"reported_at"`
+}
+
+// NodeResources is everything hosted on a node, used to plan a drain or
+// migration before decommissioning it. Resources placed on one of the node's
+// shards float across the shard's nodes unless Pinned is set.
+type NodeResources struct {
+	NodeID          string              `json:"node_id"`
+	Shards          []NodeResourceShard `json:"shards"`
+	Tenants         []NodeResource      `json:"tenants"`
+	Webroots        []NodeResource      `json:"webroots"`
+	Databases       []NodeResource      `json:"databases"`
+	ValkeyInstances []NodeResource      `json:"valkey_instances"`
+	Daemons         []NodeResource      `json:"daemons"`
+}
+
+// NodeResourceShard is one of a node's shards. A shard whose NodeCount is 1
+// has nowhere to move its resources until another node joins it.
+type NodeResourceShard struct {
+	ShardID   string `json:"shard_id"`
+	ShardRole string `json:"shard_role"`
+	NodeCount int    `json:"node_count"`
+	Primary   bool   `json:"primary"` // node is the database shard's primary
+}
+
+// NodeResource is a resource hosted on a node. Pinned resources live only on
+// this node (a shard primary's databases, daemons bound to the node) and need
+// a migration or failover before the node can go; the others are served by
+// the shard's remaining nodes.
+type NodeResource struct {
+	ID        string  `json:"id"`
+	TenantID  string  `json:"tenant_id,omitempty"`
+	ShardID   *string `json:"shard_id,omitempty"`
+	Status    string  `json:"status"`
+	Pinned    bool    `json:"pinned"`
+	PinReason string  `json:"pin_reason,omitempty"`
+}
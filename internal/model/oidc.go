@@ -31,11 +31,35 @@ type OIDCAuthCode struct {
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
+// OIDCLoginSession is a single-use login token for a tenant. Once consumed
+// by the dbadmin proxy it stays registered while the DB Admin access it
+// opened is live, so it can be listed and revoked.
 type OIDCLoginSession struct {
 	ID         string    `json:"id" db:"id"`
 	TenantID   string    `json:"tenant_id" db:"tenant_id"`
 	DatabaseID *string   `json:"database_id,omitempty" db:"database_id"`
 	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
 	Used       bool      `json:"used" db:"used"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	// TempUsername is the temporary MySQL user created for the session and
+	// ActiveUntil when it expires.
+	TempUsername *string    `json:"temp_username,omitempty" db:"temp_username"`
+	ActiveUntil  *time.Time `json:"active_until,omitempty" db:"active_until"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	// Status is LoginSessionPending or LoginSessionActive when listed.
+	Status    string    `json:"status,omitempty" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// Login session states. A pending session has not been used yet; an active
+// one has open DB Admin access.
+const (
+	LoginSessionPending = "pending"
+	LoginSessionActive  = "active"
+)
+
+// RevokeLoginSessionParams is the argument of RevokeLoginSessionWorkflow.
+type RevokeLoginSessionParams struct {
+	SessionID string `json:"session_id"`
+	ShardID   string `json:"shard_id"`
+	Username  string `json:"username"`
 }
@@ -0,0 +1,84 @@
+package model
+
+import "time"
+
+// Statuses of an async operation.
+const (
+	OperationPending   = "pending"
+	OperationRunning   = "running"
+	OperationSucceeded = "succeeded"
+	OperationFailed    = "failed"
+)
+
+// Operation tracks a single workflow started on behalf of an API request, so
+// clients can poll one endpoint regardless of the resource being changed.
+type Operation struct {
+	ID           string     `json:"id" db:"id"`
+	TenantID     *string    `json:"tenant_id,omitempty" db:"tenant_id"`
+	WorkflowName string     `json:"workflow_name" db:"workflow_name"`
+	WorkflowID   string     `json:"workflow_id" db:"workflow_id"`
+	RunID        *string    `json:"run_id,omitempty" db:"run_id"`
+	Status       string     `json:"status" db:"status"`
+	Error        *string    `json:"error,omitempty" db:"error"`
+	StartedAt    *time.Time `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	// Progress is reported by long-running workflows that track it, such as
+	// BulkProvisionCertsWorkflow (a BulkCertProgress).
+	Progress any `json:"progress,omitempty" db:"-"`
+}
+
+// Kinds of steps in an operation's history.
+const (
+	HistoryStepWorkflow      = "workflow"
+	HistoryStepActivity      = "activity"
+	HistoryStepChildWorkflow = "child_workflow"
+	HistoryStepTimer         = "timer"
+)
+
+// Statuses of a step in an operation's history.
+const (
+	HistoryStepScheduled      = "scheduled"
+	HistoryStepStarted        = "started"
+	HistoryStepCompleted      = "completed"
+	HistoryStepFailed         = "failed"
+	HistoryStepTimedOut       = "timed_out"
+	HistoryStepCanceled       = "canceled"
+	HistoryStepTerminated     = "terminated"
+	HistoryStepContinuedAsNew = "continued_as_new"
+)
+
+// OperationHistory is a summary of the Temporal event history of an
+// operation's workflow. It is empty while the operation is pending.
+type OperationHistory struct {
+	OperationID  string  `json:"operation_id"`
+	WorkflowName string  `json:"workflow_name"`
+	WorkflowID   string  `json:"workflow_id"`
+	RunID        *string `json:"run_id,omitempty"`
+	Status       string  `json:"status"`
+	Error        *string `json:"error,omitempty"`
+	// Steps are the workflow itself followed by its activities, child
+	// workflows and timers, in the order they were scheduled.
+	Steps []OperationHistoryStep `json:"steps"`
+	// Truncated is set when the history was too long to read completely.
+	Truncated bool `json:"truncated"`
+}
+
+// OperationHistoryStep is one activity, child workflow or timer of an
+// operation's workflow, or the workflow itself. Input is the decoded
+// argument with passwords, keys and other secrets replaced by "[redacted]".
+type OperationHistoryStep struct {
+	EventID    int64      `json:"event_id"`
+	Kind       string     `json:"kind"`
+	Name       string     `json:"name"`
+	Status     string     `json:"status"`
+	Summary    string     `json:"summary"`
+	Attempt    int32      `json:"attempt,omitempty"`
+	Input      any        `json:"input,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	WorkflowID string     `json:"workflow_id,omitempty"` // child workflows only
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	DurationMS int64      `json:"duration_ms,omitempty"`
+}
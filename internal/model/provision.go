@@ -10,4 +10,5 @@ type ProvisionTask struct {
 	WorkflowName string `json:"workflow_name"`
 	WorkflowID   string `json:"workflow_id"`
 	Arg          any    `json:"arg"`
+	OperationID  string `json:"operation_id,omitempty"` // Set when the task is tracked as an Operation.
 }
@@ -2,6 +2,9 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
+	"net"
+	"regexp"
 	"time"
 )
 
@@ -12,3 +15,37 @@ type Region struct {
 	CreatedAt time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time       `json:"updated_at" db:"updated_at"`
 }
+
+// RegionConfig is the config of a region.
+type RegionConfig struct {
+	DNS RegionDNSConfig `json:"dns"`
+}
+
+// RegionDNSConfig lists the PowerDNS servers run in a region. Zones of other
+// regions that name this region as a secondary get these hostnames in their
+// NS set and are transferred to the addresses by AXFR.
+type RegionDNSConfig struct {
+	Nameservers []RegionNameserver `json:"nameservers"`
+}
+
+// RegionNameserver is a nameserver's public hostname and the address it
+// sends zone transfer requests from and receives NOTIFYs on.
+type RegionNameserver struct {
+	Hostname string `json:"hostname"`
+	Address  string `json:"address"`
+}
+
+var nameserverHostnameRe = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?\.)+[a-zA-Z]{2,}\.?$`)
+
+// Validate checks that every nameserver has a hostname and an IP address.
+func (c RegionDNSConfig) Validate() error {
+	for i, ns := range c.Nameservers {
+		if !nameserverHostnameRe.MatchString(ns.Hostname) {
+			return fmt.Errorf("nameservers[%d]: invalid hostname %q", i, ns.Hostname)
+		}
+		if net.ParseIP(ns.Address) == nil {
+			return fmt.Errorf("nameservers[%d]: invalid address %q", i, ns.Address)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// Reseller owns a subset of a brand's tenants. API keys bound to a reseller
+// are limited to its tenants.
+type Reseller struct {
+	ID        string    `json:"id" db:"id"`
+	BrandID   string    `json:"brand_id" db:"brand_id"`
+	Name      string    `json:"name" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
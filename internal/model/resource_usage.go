@@ -8,5 +8,18 @@ type ResourceUsage struct {
 	ResourceID   string    `json:"resource_id"`
 	TenantID     string    `json:"tenant_id"`
 	BytesUsed    int64     `json:"bytes_used"`
+	KeyCount     *int64    `json:"key_count,omitempty"`
+	Available    bool      `json:"available"`
 	CollectedAt  time.Time `json:"collected_at"`
 }
+
+// ValkeyInstanceStats is the most recently collected memory and keyspace
+// snapshot for a Valkey instance.
+type ValkeyInstanceStats struct {
+	InstanceID      string     `json:"instance_id"`
+	UsedMemoryBytes int64      `json:"used_memory_bytes"`
+	MaxMemoryBytes  int64      `json:"max_memory_bytes"`
+	KeyCount        int64      `json:"key_count"`
+	Available       bool       `json:"available"`
+	CollectedAt     *time.Time `json:"collected_at,omitempty"`
+}
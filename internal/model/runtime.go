@@ -28,3 +28,20 @@ const (
 	RuntimeRuby   = "ruby"
 	RuntimeStatic = "static"
 )
+
+// InstalledRuntime is a runtime version available on a node, as reported by
+// the node agent. Static webroots need no runtime and are never reported.
+// For PHP, Extensions lists the toggleable extensions available for the
+// version.
+type InstalledRuntime struct {
+	Runtime    string   `json:"runtime"`
+	Version    string   `json:"version"`
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// NodeRuntimes is the cached runtime report of a node.
+type NodeRuntimes struct {
+	NodeID     string             `json:"node_id"`
+	Runtimes   []InstalledRuntime `json:"runtimes"`
+	ReportedAt time.Time          `json:"reported_at"`
+}
@@ -1,6 +1,11 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
 
 type S3Bucket struct {
 	ID             string  `json:"id" db:"id"`
@@ -14,5 +19,85 @@ type S3Bucket struct {
 	SuspendReason  string  `json:"suspend_reason" db:"suspend_reason"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+	LifecycleRules []S3LifecycleRule `json:"lifecycle_rules" db:"lifecycle_rules"`
 	ShardName      *string   `json:"shard_name,omitempty" db:"-"`
 }
+
+// Limits on S3 lifecycle rules, matching what RGW accepts.
+const (
+	MaxS3LifecycleRules     = 100
+	MaxS3LifecycleDays      = 36500
+	MaxS3LifecyclePrefixLen = 1024
+	MaxS3LifecycleRuleIDLen = 255
+)
+
+// S3LifecycleRule expires objects under a key prefix and/or aborts multipart
+// uploads under it that were never completed. An empty prefix matches the
+// whole bucket. At least one of the day counts must be set.
+type S3LifecycleRule struct {
+	ID                                 string `json:"id"`
+	Prefix                             string `json:"prefix"`
+	ExpirationDays                     int    `json:"expiration_days,omitempty"`
+	AbortIncompleteMultipartUploadDays int    `json:"abort_incomplete_multipart_upload_days,omitempty"`
+}
+
+// NormalizeS3LifecycleRules validates rules and fills in missing IDs as
+// rule-1, rule-2, ... by position, so the same policy always produces the same
+// rule set on RGW.
+func NormalizeS3LifecycleRules(rules []S3LifecycleRule) ([]S3LifecycleRule, error) {
+	if len(rules) > MaxS3LifecycleRules {
+		return nil, fmt.Errorf("at most %d lifecycle rules are allowed", MaxS3LifecycleRules)
+	}
+	out := make([]S3LifecycleRule, len(rules))
+	ids := make(map[string]bool, len(rules))
+	prefixes := make(map[string]bool, len(rules))
+	for i, r := range rules {
+		if r.ID == "" {
+			r.ID = fmt.Sprintf("rule-%d", i+1)
+		}
+		if len(r.ID) > MaxS3LifecycleRuleIDLen {
+			return nil, fmt.Errorf("rule %d: id is longer than %d characters", i+1, MaxS3LifecycleRuleIDLen)
+		}
+		if ids[r.ID] {
+			return nil, fmt.Errorf("rule %d: duplicate id %q", i+1, r.ID)
+		}
+		ids[r.ID] = true
+
+		if err := validateS3LifecyclePrefix(r.Prefix); err != nil {
+			return nil, fmt.Errorf("rule %q: %w", r.ID, err)
+		}
+		if prefixes[r.Prefix] {
+			return nil, fmt.Errorf("rule %q: another rule already uses prefix %q", r.ID, r.Prefix)
+		}
+		prefixes[r.Prefix] = true
+
+		if r.ExpirationDays == 0 && r.AbortIncompleteMultipartUploadDays == 0 {
+			return nil, fmt.Errorf("rule %q: set expiration_days or abort_incomplete_multipart_upload_days", r.ID)
+		}
+		if r.ExpirationDays < 0 || r.ExpirationDays > MaxS3LifecycleDays {
+			return nil, fmt.Errorf("rule %q: expiration_days must be between 1 and %d", r.ID, MaxS3LifecycleDays)
+		}
+		if r.AbortIncompleteMultipartUploadDays < 0 || r.AbortIncompleteMultipartUploadDays > MaxS3LifecycleDays {
+			return nil, fmt.Errorf("rule %q: abort_incomplete_multipart_upload_days must be between 1 and %d", r.ID, MaxS3LifecycleDays)
+		}
+		out[i] = r
+	}
+	return out, nil
+}
+
+// validateS3LifecyclePrefix rejects prefixes that cannot match an object key
+// written through the S3 API.
+func validateS3LifecyclePrefix(prefix string) error {
+	if len(prefix) > MaxS3LifecyclePrefixLen {
+		return fmt.Errorf("prefix is longer than %d bytes", MaxS3LifecyclePrefixLen)
+	}
+	if strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("prefix must not start with /")
+	}
+	for _, c := range prefix {
+		if c == unicode.ReplacementChar || unicode.IsControl(c) {
+			return fmt.Errorf("prefix contains invalid characters")
+		}
+	}
+	return nil
+}
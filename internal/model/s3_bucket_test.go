@@ -0,0 +1,60 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeS3LifecycleRules_FillsIDs(t *testing.T) {
+	rules, err := NormalizeS3LifecycleRules([]S3LifecycleRule{
+		{Prefix: "tmp/", ExpirationDays: 7},
+		{ID: "uploads", AbortIncompleteMultipartUploadDays: 2},
+		{Prefix: "logs/", ExpirationDays: 30, AbortIncompleteMultipartUploadDays: 1},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "rule-1", rules[0].ID)
+	assert.Equal(t, "uploads", rules[1].ID)
+	assert.Equal(t, "rule-3", rules[2].ID)
+}
+
+func TestNormalizeS3LifecycleRules_Empty(t *testing.T) {
+	rules, err := NormalizeS3LifecycleRules(nil)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestNormalizeS3LifecycleRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []S3LifecycleRule
+		want  string
+	}{
+		{"no action", []S3LifecycleRule{{Prefix: "a/"}}, "set expiration_days"},
+		{"negative days", []S3LifecycleRule{{ExpirationDays: -1}}, "expiration_days must be"},
+		{"too many days", []S3LifecycleRule{{AbortIncompleteMultipartUploadDays: MaxS3LifecycleDays + 1}}, "abort_incomplete_multipart_upload_days must be"},
+		{"leading slash", []S3LifecycleRule{{Prefix: "/logs", ExpirationDays: 1}}, "must not start with /"},
+		{"control char", []S3LifecycleRule{{Prefix: "a\nb", ExpirationDays: 1}}, "invalid characters"},
+		{"invalid utf8", []S3LifecycleRule{{Prefix: "a\xffb", ExpirationDays: 1}}, "invalid characters"},
+		{"long prefix", []S3LifecycleRule{{Prefix: strings.Repeat("a", MaxS3LifecyclePrefixLen+1), ExpirationDays: 1}}, "longer than"},
+		{"duplicate id", []S3LifecycleRule{{ID: "x", ExpirationDays: 1}, {ID: "x", Prefix: "b/", ExpirationDays: 1}}, "duplicate id"},
+		{"duplicate prefix", []S3LifecycleRule{{Prefix: "a/", ExpirationDays: 1}, {Prefix: "a/", ExpirationDays: 2}}, "already uses prefix"},
+		{"generated id clash", []S3LifecycleRule{{ID: "rule-2", ExpirationDays: 1}, {Prefix: "b/", ExpirationDays: 1}}, "duplicate id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NormalizeS3LifecycleRules(tt.rules)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestNormalizeS3LifecycleRules_TooMany(t *testing.T) {
+	rules := make([]S3LifecycleRule, MaxS3LifecycleRules+1)
+	_, err := NormalizeS3LifecycleRules(rules)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "at most")
+}
@@ -2,6 +2,8 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
 	"time"
 )
 
@@ -46,3 +48,80 @@ type GatewayShardConfig struct {
 	PublicKey    string `json:"public_key"`
 	ExternalHost string `json:"external_host"`
 }
+
+// WebShardConfig holds configuration for a web shard.
+type WebShardConfig struct {
+	Nginx NginxTuning `json:"nginx"`
+	// ProxyProtocol has the cluster's LBs send the PROXY protocol to the
+	// shard's nodes, which take the client address from it instead of
+	// X-Forwarded-For. The LBs must be configured to match (see the haproxy
+	// role's web_proxy_protocol).
+	ProxyProtocol bool `json:"proxy_protocol,omitempty"`
+}
+
+// NginxTuning holds the main and http-level nginx settings rendered into the
+// base nginx.conf of a web shard's nodes. Zero values keep the nginx or
+// distribution default.
+type NginxTuning struct {
+	WorkerConnections int `json:"worker_connections,omitempty"`
+	// KeepaliveTimeout is in seconds.
+	KeepaliveTimeout int `json:"keepalive_timeout,omitempty"`
+	// Sizes take an nginx size ("16k", "8m"); buffer counts and sizes take
+	// "number size" ("4 16k").
+	ClientBodyBufferSize     string `json:"client_body_buffer_size,omitempty"`
+	ClientMaxBodySize        string `json:"client_max_body_size,omitempty"`
+	LargeClientHeaderBuffers string `json:"large_client_header_buffers,omitempty"`
+	ProxyBufferSize          string `json:"proxy_buffer_size,omitempty"`
+	ProxyBuffers             string `json:"proxy_buffers,omitempty"`
+	// Gzip turns compression off when false; nil leaves it on.
+	Gzip          *bool    `json:"gzip,omitempty"`
+	GzipCompLevel int      `json:"gzip_comp_level,omitempty"`
+	GzipMinLength int      `json:"gzip_min_length,omitempty"`
+	GzipTypes     []string `json:"gzip_types,omitempty"`
+}
+
+var (
+	nginxSizeRe    = regexp.MustCompile(`^[1-9][0-9]*[kKmM]?$`)
+	nginxBuffersRe = regexp.MustCompile(`^[1-9][0-9]* [1-9][0-9]*[kKmM]?$`)
+	mimeTypeRe     = regexp.MustCompile(`^[a-z]+/[a-zA-Z0-9.+-]+$`)
+)
+
+// Validate checks the settings are in range and safe to render into
+// nginx.conf.
+func (t NginxTuning) Validate() error {
+	if t.WorkerConnections != 0 && (t.WorkerConnections < 512 || t.WorkerConnections > 65535) {
+		return fmt.Errorf("worker_connections must be between 512 and 65535")
+	}
+	if t.KeepaliveTimeout < 0 || t.KeepaliveTimeout > 300 {
+		return fmt.Errorf("keepalive_timeout must be between 0 and 300 seconds")
+	}
+	for name, v := range map[string]string{
+		"client_body_buffer_size": t.ClientBodyBufferSize,
+		"client_max_body_size":    t.ClientMaxBodySize,
+		"proxy_buffer_size":       t.ProxyBufferSize,
+	} {
+		if v != "" && !nginxSizeRe.MatchString(v) {
+			return fmt.Errorf("%s must be an nginx size such as 16k or 8m", name)
+		}
+	}
+	for name, v := range map[string]string{
+		"large_client_header_buffers": t.LargeClientHeaderBuffers,
+		"proxy_buffers":               t.ProxyBuffers,
+	} {
+		if v != "" && !nginxBuffersRe.MatchString(v) {
+			return fmt.Errorf("%s must be a buffer count and size such as \"4 16k\"", name)
+		}
+	}
+	if t.GzipCompLevel < 0 || t.GzipCompLevel > 9 {
+		return fmt.Errorf("gzip_comp_level must be between 1 and 9")
+	}
+	if t.GzipMinLength < 0 {
+		return fmt.Errorf("gzip_min_length must not be negative")
+	}
+	for _, mt := range t.GzipTypes {
+		if !mimeTypeRe.MatchString(mt) {
+			return fmt.Errorf("invalid gzip_types entry %q", mt)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+// SMTPRelayPort is the submission port (STARTTLS) relay users connect to.
+const SMTPRelayPort = 587
+
+// DefaultSMTPRelayMaxMessagesPerHour is the send limit of relay users created
+// without one.
+const DefaultSMTPRelayMaxMessagesPerHour = 100
+
+// SMTPRelayUser is an SMTP submission login a tenant's applications send
+// mail with. Hostname is the brand's mail hostname and is not stored.
+type SMTPRelayUser struct {
+	ID                 string    `json:"id" db:"id"`
+	TenantID           string    `json:"tenant_id" db:"tenant_id"`
+	Username           string    `json:"username" db:"username"`
+	PasswordHash       string    `json:"-" db:"password_hash"`
+	MaxMessagesPerHour int       `json:"max_messages_per_hour" db:"max_messages_per_hour"`
+	Hostname           string    `json:"hostname" db:"-"`
+	Port               int       `json:"port" db:"-"`
+	Status             string    `json:"status" db:"status"`
+	StatusMessage      *string   `json:"status_message,omitempty" db:"status_message"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+	// SendCounts is only set when a single relay user is fetched.
+	SendCounts *SMTPRelaySendCounts `json:"send_counts,omitempty" db:"-"`
+}
+
+// SMTPRelaySendCounts are the recent authenticated submissions of a relay
+// user, counted from Stalwart's log.
+type SMTPRelaySendCounts struct {
+	LastHour int64 `json:"last_hour"`
+	LastDay  int64 `json:"last_day"`
+}
@@ -5,6 +5,7 @@ const (
 	StatusPending      = "pending"
 	StatusProvisioning = "provisioning"
 	StatusConverging   = "converging"
+	StatusMigrating    = "migrating"
 	StatusActive       = "active"
 	StatusFailed       = "failed"
 	StatusSuspended    = "suspended"
@@ -0,0 +1,10 @@
+package model
+
+// StatusReset describes a resource that was forced out of a transitional
+// status after its workflow died.
+type StatusReset struct {
+	ResourceType   string `json:"resource_type"`
+	ResourceID     string `json:"resource_id"`
+	PreviousStatus string `json:"previous_status"`
+	Status         string `json:"status"`
+}
@@ -6,6 +6,7 @@ type Tenant struct {
 	ID          string  `json:"id" db:"id"`
 	BrandID     string  `json:"brand_id" db:"brand_id"`
 	CustomerID  string  `json:"customer_id" db:"customer_id"`
+	ResellerID  *string `json:"reseller_id,omitempty" db:"reseller_id"`
 	RegionID    string  `json:"region_id" db:"region_id"`
 	ClusterID   string  `json:"cluster_id" db:"cluster_id"`
 	ShardID     *string `json:"shard_id,omitempty" db:"shard_id"`
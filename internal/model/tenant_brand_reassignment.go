@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Resource types reconfigured when a tenant moves to another brand.
+const (
+	BrandReassignTenant          = "tenant"
+	BrandReassignZone            = "zone"
+	BrandReassignEmailDomain     = "email_domain"
+	BrandReassignServiceHostname = "service_hostname"
+	BrandReassignCertificate     = "certificate"
+)
+
+// TenantBrandReassignment reports a tenant's move to another brand. It is
+// kept in the ReassignTenantBrandWorkflow state and read through a workflow
+// query. Status is MigrationCompleted only if every step succeeded; the
+// tenant keeps the target brand even if some steps failed.
+type TenantBrandReassignment struct {
+	TenantID      string                        `json:"tenant_id"`
+	SourceBrandID string                        `json:"source_brand_id"`
+	TargetBrandID string                        `json:"target_brand_id"`
+	Status        string                        `json:"status"`
+	Error         string                        `json:"error,omitempty"`
+	Steps         []TenantBrandReassignmentStep `json:"steps"`
+	StartedAt     time.Time                     `json:"started_at"`
+	FinishedAt    *time.Time                    `json:"finished_at,omitempty"`
+}
+
+// TenantBrandReassignmentStep is the result of reconfiguring one resource,
+// e.g. re-rendering the SOA and NS records of a zone.
+type TenantBrandReassignmentStep struct {
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	Name         string `json:"name,omitempty"`
+	Action       string `json:"action"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
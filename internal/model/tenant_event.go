@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// TenantEvent is an entry of a tenant's event feed: an action taken on the
+// tenant's account, derived from the audit log and described for the tenant.
+// ResourceID is unset for resources created by the action, whose ID is not
+// in the request.
+type TenantEvent struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   *string   `json:"resource_id,omitempty"`
+	Summary      string    `json:"summary"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// TenantExport is an account-wide export archive of a tenant's data.
+type TenantExport struct {
+	ID            string     `json:"id"`
+	TenantID      string     `json:"tenant_id"`
+	ObjectKey     string     `json:"object_key,omitempty"`
+	SizeBytes     int64      `json:"size_bytes"`
+	Status        string     `json:"status"`
+	StatusMessage *string    `json:"status_message,omitempty"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	DownloadURL   string     `json:"download_url,omitempty"`
+}
+
+// TenantExportManifest is written as manifest.json at the root of an export
+// archive. It describes the tenant's configuration and lists the data files
+// included alongside it.
+type TenantExportManifest struct {
+	TenantID        string             `json:"tenant_id"`
+	ExportedAt      time.Time          `json:"exported_at"`
+	Webroots        []Webroot          `json:"webroots"`
+	FQDNs           []FQDN             `json:"fqdns"`
+	Zones           []TenantExportZone `json:"zones"`
+	CronJobs        []CronJob          `json:"cron_jobs"`
+	Daemons         []Daemon           `json:"daemons"`
+	Databases       []Database         `json:"databases"`
+	ValkeyInstances []ValkeyInstance   `json:"valkey_instances"`
+	EmailAccounts   []EmailAccount     `json:"email_accounts"`
+	Files           []string           `json:"files"`
+	Excluded        []string           `json:"excluded,omitempty"`
+}
+
+// TenantExportZone is a DNS zone and its records in an export manifest.
+type TenantExportZone struct {
+	Zone    Zone         `json:"zone"`
+	Records []ZoneRecord `json:"records"`
+}
@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// TenantLBSplit sends Weight percent of a tenant's web traffic to another
+// shard's LB backend, e.g. to shift traffic gradually before a migration.
+// The rest goes to the tenant's own shard.
+type TenantLBSplit struct {
+	TenantID  string    `json:"tenant_id" db:"tenant_id"`
+	ShardID   string    `json:"shard_id" db:"shard_id"`
+	Weight    int       `json:"weight" db:"weight"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
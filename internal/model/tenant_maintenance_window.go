@@ -0,0 +1,53 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaintenanceWindowTimeLayout is the layout of TenantMaintenanceWindow.StartTime.
+const MaintenanceWindowTimeLayout = "15:04"
+
+// TenantMaintenanceWindow is a daily window, in the tenant's timezone, during
+// which disruptive automatic operations such as certificate renewals (which
+// reload nginx) are scheduled.
+type TenantMaintenanceWindow struct {
+	TenantID        string    `json:"tenant_id" db:"tenant_id"`
+	StartTime       string    `json:"start_time" db:"start_time"`
+	DurationMinutes int       `json:"duration_minutes" db:"duration_minutes"`
+	Timezone        string    `json:"timezone" db:"timezone"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Contains reports whether t falls inside the window. A window may run past
+// midnight into the next local day. Start times are resolved on the local
+// calendar, so a window keeps its wall-clock start across DST changes.
+func (w TenantMaintenanceWindow) Contains(t time.Time) (bool, error) {
+	_, inside, err := w.Elapsed(t)
+	return inside, err
+}
+
+// Elapsed returns how long the window containing t has been open, and whether
+// t falls inside a window at all.
+func (w TenantMaintenanceWindow) Elapsed(t time.Time) (time.Duration, bool, error) {
+	loc, err := time.LoadLocation(w.Timezone)
+	if err != nil {
+		return 0, false, fmt.Errorf("maintenance window timezone %q: %w", w.Timezone, err)
+	}
+	start, err := time.Parse(MaintenanceWindowTimeLayout, w.StartTime)
+	if err != nil {
+		return 0, false, fmt.Errorf("maintenance window start time %q: %w", w.StartTime, err)
+	}
+
+	local := t.In(loc)
+	duration := time.Duration(w.DurationMinutes) * time.Minute
+	// The window containing t started either today or yesterday.
+	for _, day := range []int{0, -1} {
+		from := time.Date(local.Year(), local.Month(), local.Day()+day, start.Hour(), start.Minute(), 0, 0, loc)
+		if !local.Before(from) && local.Before(from.Add(duration)) {
+			return local.Sub(from), true, nil
+		}
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenantMaintenanceWindowContains(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		window TenantMaintenanceWindow
+		at     time.Time
+		want   bool
+	}{
+		{"inside", TenantMaintenanceWindow{StartTime: "03:00", DurationMinutes: 120, Timezone: "Europe/Oslo"}, time.Date(2026, 6, 1, 4, 30, 0, 0, oslo), true},
+		{"at start", TenantMaintenanceWindow{StartTime: "03:00", DurationMinutes: 120, Timezone: "Europe/Oslo"}, time.Date(2026, 6, 1, 3, 0, 0, 0, oslo), true},
+		{"at end", TenantMaintenanceWindow{StartTime: "03:00", DurationMinutes: 120, Timezone: "Europe/Oslo"}, time.Date(2026, 6, 1, 5, 0, 0, 0, oslo), false},
+		{"before", TenantMaintenanceWindow{StartTime: "03:00", DurationMinutes: 120, Timezone: "Europe/Oslo"}, time.Date(2026, 6, 1, 2, 59, 0, 0, oslo), false},
+		{"utc input", TenantMaintenanceWindow{StartTime: "03:00", DurationMinutes: 120, Timezone: "Europe/Oslo"}, time.Date(2026, 6, 1, 1, 30, 0, 0, time.UTC), true},
+		{"past midnight", TenantMaintenanceWindow{StartTime: "23:00", DurationMinutes: 180, Timezone: "Europe/Oslo"}, time.Date(2026, 6, 2, 1, 0, 0, 0, oslo), true},
+		{"past midnight same day", TenantMaintenanceWindow{StartTime: "23:00", DurationMinutes: 180, Timezone: "Europe/Oslo"}, time.Date(2026, 6, 1, 23, 30, 0, 0, oslo), true},
+		{"past midnight outside", TenantMaintenanceWindow{StartTime: "23:00", DurationMinutes: 180, Timezone: "Europe/Oslo"}, time.Date(2026, 6, 2, 2, 0, 0, 0, oslo), false},
+		{"winter offset", TenantMaintenanceWindow{StartTime: "03:00", DurationMinutes: 60, Timezone: "Europe/Oslo"}, time.Date(2026, 1, 15, 2, 0, 0, 0, time.UTC), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.window.Contains(tt.at)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTenantMaintenanceWindowContains_Invalid(t *testing.T) {
+	_, err := TenantMaintenanceWindow{StartTime: "03:00", DurationMinutes: 60, Timezone: "Mars/Olympus"}.Contains(time.Now())
+	assert.Error(t, err)
+
+	_, err = TenantMaintenanceWindow{StartTime: "3am", DurationMinutes: 60, Timezone: "UTC"}.Contains(time.Now())
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package model
+
+import "time"
+
+// Tenant migration phases, in the order MigrateTenantWorkflow runs them.
+const (
+	MigrationPhaseSnapshot = "snapshot"
+	MigrationPhaseTransfer = "transfer"
+	MigrationPhaseCutover  = "cutover"
+	MigrationPhaseCleanup  = "cleanup"
+)
+
+// Overall and per-step statuses of a tenant migration.
+const (
+	MigrationRunning   = "running"
+	MigrationCompleted = "completed"
+	MigrationFailed    = "failed"
+
+	MigrationStepPending = "pending"
+	MigrationStepRunning = "running"
+	MigrationStepDone    = "done"
+	MigrationStepFailed  = "failed"
+)
+
+// Where a migrating tenant's traffic is served from. A migration that fails
+// in the cutover phase can leave some FQDNs routed to the target shard.
+const (
+	MigrationLocationSource = "source"
+	MigrationLocationSplit  = "split"
+	MigrationLocationTarget = "target"
+)
+
+// TenantMigration tracks a tenant's move between shards. It is kept in the
+// MigrateTenantWorkflow state and read through a workflow query.
+type TenantMigration struct {
+	TenantID      string                `json:"tenant_id"`
+	SourceShardID string                `json:"source_shard_id"`
+	TargetShardID string                `json:"target_shard_id"`
+	Status        string                `json:"status"`
+	Phase         string                `json:"phase"`
+	FailedPhase   string                `json:"failed_phase,omitempty"`
+	Error         string                `json:"error,omitempty"`
+	Location      string                `json:"location"`
+	Steps         []TenantMigrationStep `json:"steps"`
+	StartedAt     time.Time             `json:"started_at"`
+	FinishedAt    *time.Time            `json:"finished_at,omitempty"`
+}
+
+// TenantMigrationStep is the progress of one resource within a migration
+// phase, e.g. a webroot being created on one target node.
+type TenantMigrationStep struct {
+	Phase        string `json:"phase"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	NodeID       string `json:"node_id,omitempty"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+}
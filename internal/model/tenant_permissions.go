@@ -0,0 +1,20 @@
+package model
+
+// TenantPermissionsFix reports a reconciliation of the ownership and modes
+// of a tenant's storage tree with their expected state.
+type TenantPermissionsFix struct {
+	TenantID string `json:"tenant_id"`
+	NodeID   string `json:"node_id"`
+	// Scanned counts the files, directories and symlinks looked at.
+	Scanned int `json:"scanned"`
+	// Changed counts the entries whose owner or mode was changed.
+	Changed int `json:"changed"`
+	// Skipped counts hard-linked files owned by someone other than the
+	// tenant, which are left alone: changing them would also change the
+	// other links, which may be outside the tenant's storage.
+	Skipped int `json:"skipped"`
+	// Truncated is set when the change or time limit was reached before the
+	// whole tree was scanned. Running the fix again gets further, since
+	// entries that are already right are only scanned.
+	Truncated bool `json:"truncated"`
+}
@@ -3,17 +3,26 @@ package model
 import "time"
 
 type ValkeyInstance struct {
-	ID             string  `json:"id" db:"id"`
-	TenantID       string  `json:"tenant_id" db:"tenant_id"`
-	SubscriptionID string  `json:"subscription_id" db:"subscription_id"`
-	ShardID        *string `json:"shard_id,omitempty" db:"shard_id"`
-	Port           int     `json:"port" db:"port"`
-	MaxMemoryMB    int     `json:"max_memory_mb" db:"max_memory_mb"`
-	PasswordHash   string  `json:"password_hash,omitempty" db:"password_hash"`
-	Status         string  `json:"status" db:"status"`
-	StatusMessage  *string `json:"status_message,omitempty" db:"status_message"`
-	SuspendReason  string  `json:"suspend_reason" db:"suspend_reason"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
-	ShardName      *string   `json:"shard_name,omitempty" db:"-"`
+	ID              string    `json:"id" db:"id"`
+	TenantID        string    `json:"tenant_id" db:"tenant_id"`
+	SubscriptionID  string    `json:"subscription_id" db:"subscription_id"`
+	ShardID         *string   `json:"shard_id,omitempty" db:"shard_id"`
+	Port            int       `json:"port" db:"port"`
+	MaxMemoryMB     int       `json:"max_memory_mb" db:"max_memory_mb"`
+	PersistenceMode string    `json:"persistence_mode" db:"persistence_mode"`
+	PasswordHash    string    `json:"password_hash,omitempty" db:"password_hash"`
+	Status          string    `json:"status" db:"status"`
+	StatusMessage   *string   `json:"status_message,omitempty" db:"status_message"`
+	SuspendReason   string    `json:"suspend_reason" db:"suspend_reason"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+	ShardName       *string   `json:"shard_name,omitempty" db:"-"`
 }
+
+// Valkey persistence modes. RDB takes periodic snapshots, AOF logs every
+// write, and none keeps data in memory only, for pure caches.
+const (
+	ValkeyPersistenceRDB  = "rdb"
+	ValkeyPersistenceAOF  = "aof"
+	ValkeyPersistenceNone = "none"
+)
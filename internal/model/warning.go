@@ -0,0 +1,28 @@
+package model
+
+// Warning codes returned in the warnings array of successful API responses.
+const (
+	// WarningDNSNotPointed: the FQDN does not resolve to its cluster's load
+	// balancers yet.
+	WarningDNSNotPointed = "dns_not_pointed"
+	// WarningNoMXRecord: the email domain has no MX record, so mail for the
+	// new account is not delivered to the platform.
+	WarningNoMXRecord = "no_mx_record"
+	// WarningQuotaNearLimit: the tenant's last measured disk usage is at or
+	// above QuotaWarningPercent of its disk quota.
+	WarningQuotaNearLimit = "quota_near_limit"
+	// WarningIPv6Only: the FQDN is served over IPv6 only, so clients
+	// without IPv6 connectivity cannot reach it.
+	WarningIPv6Only = "ipv6_only"
+)
+
+// QuotaWarningPercent is the disk usage, as a percentage of the quota, from
+// which WarningQuotaNearLimit is returned.
+const QuotaWarningPercent = 90
+
+// Warning is a non-fatal finding about a request that succeeded, such as DNS
+// that is not set up yet. Code is stable; Message is for humans.
+type Warning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
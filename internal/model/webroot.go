@@ -2,6 +2,9 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 )
 
@@ -13,11 +16,221 @@ type Webroot struct {
 	RuntimeVersion string          `json:"runtime_version" db:"runtime_version"`
 	RuntimeConfig  json.RawMessage `json:"runtime_config" db:"runtime_config"`
 	PublicFolder   string          `json:"public_folder" db:"public_folder"`
-	EnvFileName            string          `json:"env_file_name" db:"env_file_name"`
-	ServiceHostnameEnabled bool            `json:"service_hostname_enabled" db:"service_hostname_enabled"`
-	Status                 string          `json:"status" db:"status"`
-	StatusMessage  *string         `json:"status_message,omitempty" db:"status_message"`
-	SuspendReason  string          `json:"suspend_reason" db:"suspend_reason"`
-	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
+	ErrorPages     map[int]string  `json:"error_pages" db:"error_pages"`
+	// BasicAuth maps usernames to bcrypt hashes. It is only loaded for
+	// workflows; API reads leave it empty (see WebrootBasicAuth).
+	BasicAuth              map[string]string       `json:"basic_auth,omitempty" db:"basic_auth" swaggerignore:"true"`
+	EnvFileName            string                  `json:"env_file_name" db:"env_file_name"`
+	ServiceHostnameEnabled bool                    `json:"service_hostname_enabled" db:"service_hostname_enabled"`
+	AccessLogEnabled       bool                    `json:"access_log_enabled" db:"access_log_enabled"`
+	ConnectionLimits       WebrootConnectionLimits `json:"connection_limits" db:"connection_limits"`
+	StaticRules            WebrootStaticRules      `json:"static_rules" db:"static_rules"`
+	GeoBlocking            WebrootGeoBlocking      `json:"geo_blocking" db:"geo_blocking"`
+	DeployLockedUntil      *time.Time              `json:"deploy_locked_until,omitempty" db:"deploy_locked_until"`
+	Status                 string                  `json:"status" db:"status"`
+	StatusMessage          *string                 `json:"status_message,omitempty" db:"status_message"`
+	SuspendReason          string                  `json:"suspend_reason" db:"suspend_reason"`
+	CreatedAt              time.Time               `json:"created_at" db:"created_at"`
+	UpdatedAt              time.Time               `json:"updated_at" db:"updated_at"`
+}
+
+// WebrootBasicAuth is the HTTP basic-auth protection of a webroot. Only the
+// usernames are exposed; password hashes stay in the database.
+type WebrootBasicAuth struct {
+	WebrootID string   `json:"webroot_id"`
+	Enabled   bool     `json:"enabled"`
+	Usernames []string `json:"usernames"`
+}
+
+// WebrootBasicAuthUser is a user to allow through a webroot's basic auth.
+// Exactly one of Password and PasswordHash is set; a plain Password is hashed
+// with bcrypt before it is stored.
+type WebrootBasicAuthUser struct {
+	Username     string `json:"username"`
+	Password     string `json:"-"`
+	PasswordHash string `json:"-"`
+}
+
+// CloneWebrootParams is the argument of CloneWebrootWorkflow. DatabaseID and
+// SourceDatabaseID are empty when no database is cloned.
+type CloneWebrootParams struct {
+	SourceWebrootID  string `json:"source_webroot_id"`
+	WebrootID        string `json:"webroot_id"`
+	SourceDatabaseID string `json:"source_database_id,omitempty"`
+	DatabaseID       string `json:"database_id,omitempty"`
+}
+
+// WebrootClone is a webroot created as a copy of another, together with the
+// database cloned along with it, if any. Both are still being provisioned.
+type WebrootClone struct {
+	SourceWebrootID string    `json:"source_webroot_id"`
+	Webroot         *Webroot  `json:"webroot"`
+	Database        *Database `json:"database,omitempty"`
+	// RegeneratedSecrets names the secret env vars that were given a new
+	// random value because the request did not supply one.
+	RegeneratedSecrets []string `json:"regenerated_secrets"`
+}
+
+// WebrootConnectionLimits caps what a single client IP can use of a webroot.
+// Zero disables a limit; the zero value disables both.
+type WebrootConnectionLimits struct {
+	// MaxConnsPerIP is the number of concurrent connections per client IP
+	// (nginx limit_conn).
+	MaxConnsPerIP int `json:"max_conns_per_ip"`
+	// RequestsPerSecond is the sustained request rate per client IP and Burst
+	// the number of requests above it served without delay (nginx limit_req).
+	RequestsPerSecond int `json:"requests_per_second"`
+	Burst             int `json:"burst"`
+}
+
+// Enabled reports whether any limit is set.
+func (l WebrootConnectionLimits) Enabled() bool {
+	return l.MaxConnsPerIP > 0 || l.RequestsPerSecond > 0
+}
+
+// Zones returns the number of nginx shared memory zones the limits need.
+func (l WebrootConnectionLimits) Zones() int {
+	n := 0
+	if l.MaxConnsPerIP > 0 {
+		n++
+	}
+	if l.RequestsPerSecond > 0 {
+		n++
+	}
+	return n
+}
+
+// Each limit of a webroot gets its own nginx shared memory zone of
+// WebrootLimitZoneKB, enough for about 8000 client IPs. Every web node of a
+// shard allocates the zones of all the shard's webroots, so their total is
+// capped at WebrootLimitZoneBudgetKB per shard.
+const (
+	WebrootLimitZoneKB       = 512
+	WebrootLimitZoneBudgetKB = 256 * 1024
+)
+
+// Most cache rules and MIME type mappings a webroot can have.
+const (
+	MaxWebrootCacheRules = 50
+	MaxWebrootMimeTypes  = 50
+)
+
+var (
+	cachePathPrefixRe   = regexp.MustCompile(`^/[A-Za-z0-9._~/-]*$`)
+	cacheExtensionRe    = regexp.MustCompile(`^\*\.[A-Za-z0-9]+(\.[A-Za-z0-9]+)*$`)
+	cacheControlRe      = regexp.MustCompile(`^[A-Za-z0-9=, -]+$`)
+	expiresRe           = regexp.MustCompile(`^(max|epoch|off|[1-9][0-9]*(ms|s|m|h|d|w|M|y)?)$`)
+	mimeTypeExtensionRe = regexp.MustCompile(`^[a-z0-9]{1,16}$`)
+)
+
+// WebrootStaticRules are the caching headers and MIME types a webroot's
+// nginx adds to its responses. The zero value adds nothing.
+type WebrootStaticRules struct {
+	// CacheRules are matched against the request path in order; the first
+	// match sets the caching headers of the response.
+	CacheRules []WebrootCacheRule `json:"cache_rules,omitempty"`
+	// MimeTypes maps file extensions, without the dot, to the Content-Type
+	// they are served with. They override nginx's defaults.
+	MimeTypes map[string]string `json:"mime_types,omitempty"`
+}
+
+// WebrootCacheRule sets the caching headers of matching requests. Pattern
+// is a path prefix ("/assets/") or an extension glob ("*.woff2", matched
+// case-insensitively). Exactly one of CacheControl and Expires is set:
+// CacheControl is sent as the Cache-Control header, Expires is an nginx
+// expires time ("30d", "max", "epoch", "off") that sets both Expires and
+// Cache-Control max-age.
+type WebrootCacheRule struct {
+	Pattern      string `json:"pattern"`
+	CacheControl string `json:"cache_control,omitempty"`
+	Expires      string `json:"expires,omitempty"`
+}
+
+// IsExtension reports whether the rule matches by file extension rather
+// than by path prefix.
+func (r WebrootCacheRule) IsExtension() bool {
+	return strings.HasPrefix(r.Pattern, "*.")
+}
+
+// Validate checks the rules are well-formed and safe to render into the
+// webroot's nginx config.
+func (s WebrootStaticRules) Validate() error {
+	if len(s.CacheRules) > MaxWebrootCacheRules {
+		return fmt.Errorf("at most %d cache rules are allowed", MaxWebrootCacheRules)
+	}
+	seen := map[string]bool{}
+	for i, r := range s.CacheRules {
+		switch {
+		case len(r.Pattern) > 128:
+			return fmt.Errorf("cache_rules[%d]: pattern is longer than 128 characters", i)
+		case !cachePathPrefixRe.MatchString(r.Pattern) && !cacheExtensionRe.MatchString(r.Pattern):
+			return fmt.Errorf("cache_rules[%d]: pattern must be a path prefix such as /assets/ or an extension glob such as *.css", i)
+		case seen[r.Pattern]:
+			return fmt.Errorf("cache_rules[%d]: duplicate pattern %q", i, r.Pattern)
+		}
+		seen[r.Pattern] = true
+
+		if (r.CacheControl == "") == (r.Expires == "") {
+			return fmt.Errorf("cache_rules[%d]: exactly one of cache_control and expires must be set", i)
+		}
+		if r.CacheControl != "" && (len(r.CacheControl) > 256 || !cacheControlRe.MatchString(r.CacheControl)) {
+			return fmt.Errorf("cache_rules[%d]: cache_control may only contain letters, digits, '=', ',', '-' and spaces", i)
+		}
+		if r.Expires != "" && !expiresRe.MatchString(r.Expires) {
+			return fmt.Errorf("cache_rules[%d]: expires must be a time such as 30d or 12h, max, epoch or off", i)
+		}
+	}
+
+	if len(s.MimeTypes) > MaxWebrootMimeTypes {
+		return fmt.Errorf("at most %d MIME types are allowed", MaxWebrootMimeTypes)
+	}
+	for ext, mt := range s.MimeTypes {
+		if !mimeTypeExtensionRe.MatchString(ext) {
+			return fmt.Errorf("mime_types: extension %q must be 1-16 lowercase letters or digits, without the dot", ext)
+		}
+		if !mimeTypeRe.MatchString(mt) {
+			return fmt.Errorf("mime_types: invalid MIME type %q for %s", mt, ext)
+		}
+	}
+	return nil
+}
+
+// WebrootNginxPreviewNote is returned with every nginx preview.
+const WebrootNginxPreviewNote = "Rendered from the webroot's current desired state; not read from disk. " +
+	"The config on the nodes may differ until the next webroot update or shard convergence."
+
+// WebrootNginxPreview is the nginx server block a web node would generate for
+// a webroot. It is rendered on demand and never written.
+type WebrootNginxPreview struct {
+	WebrootID   string    `json:"webroot_id"`
+	NodeID      string    `json:"node_id"`
+	Config      string    `json:"config"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Note        string    `json:"note"`
+}
+
+// WebrootAccessLog is the tail of a webroot's access log, merged across the
+// web nodes of its shard.
+type WebrootAccessLog struct {
+	WebrootID string `json:"webroot_id"`
+	Enabled   bool   `json:"enabled"`
+	// Lines are the most recent requests, oldest first.
+	Lines []WebrootAccessLogLine `json:"lines"`
+	// StatusCounts counts Lines by HTTP status class ("2xx", "4xx", ...).
+	StatusCounts map[string]int `json:"status_counts"`
+}
+
+// WebrootAccessLogLine is one request from a webroot's access log.
+type WebrootAccessLogLine struct {
+	Time        time.Time `json:"time"`
+	Node        string    `json:"node"`
+	RemoteAddr  string    `json:"remote_addr"`
+	Method      string    `json:"method"`
+	URI         string    `json:"uri"`
+	Status      int       `json:"status"`
+	BytesSent   int64     `json:"bytes_sent"`
+	RequestTime float64   `json:"request_time"`
+	Host        string    `json:"host"`
+	Referer     string    `json:"referer,omitempty"`
+	UserAgent   string    `json:"user_agent,omitempty"`
 }
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// WebrootDeployLockMaxTTL caps how long a deploy lock holds off requests. A
+// lock is meant to cover the moment a deploy swaps code, not the deploy.
+const WebrootDeployLockMaxTTL = 10 * time.Minute
+
+// WebrootDeployLock is the deploy lock of a webroot. Until LockedUntil, nginx
+// answers its requests with 503 and a Retry-After of the seconds left,
+// serving the webroot's custom 503 page if it has one. ACME HTTP-01
+// challenges are never held off. The lock lifts by itself when it expires.
+type WebrootDeployLock struct {
+	WebrootID string `json:"webroot_id"`
+	// LockedUntil is nil when the webroot is not locked.
+	LockedUntil *time.Time `json:"locked_until"`
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Geo-blocking modes of a webroot.
+const (
+	// GeoBlockingAllow serves only clients from the listed countries.
+	GeoBlockingAllow = "allow"
+	// GeoBlockingDeny serves everyone except clients from the listed
+	// countries.
+	GeoBlockingDeny = "deny"
+)
+
+// WebrootGeoBlockingNote is returned with a webroot's geo-blocking config.
+const WebrootGeoBlockingNote = "Geo-blocking requires the nginx geoip2 module and a GeoIP2 country database on the shard's web nodes. " +
+	"Webroot updates and shard convergence fail on nodes without them."
+
+// WebrootGeoBlocking restricts a webroot to, or blocks, clients from a list
+// of countries, looked up by client IP in the node's GeoIP2 database.
+// Blocked clients get 403. Clients whose country is unknown, such as private
+// addresses, are never blocked, and neither are ACME HTTP-01 challenges. The
+// zero value restricts nothing.
+type WebrootGeoBlocking struct {
+	// Mode is GeoBlockingAllow or GeoBlockingDeny.
+	Mode string `json:"mode,omitempty"`
+	// Countries are ISO 3166-1 alpha-2 codes in upper case, e.g. "NO".
+	Countries []string `json:"countries,omitempty"`
+}
+
+// Enabled reports whether the webroot restricts access by country.
+func (g WebrootGeoBlocking) Enabled() bool {
+	return g.Mode != "" && len(g.Countries) > 0
+}
+
+// Validate checks the mode and that every country is an assigned ISO 3166-1
+// alpha-2 code, without duplicates.
+func (g WebrootGeoBlocking) Validate() error {
+	if g.Mode == "" && len(g.Countries) == 0 {
+		return nil
+	}
+	if g.Mode != GeoBlockingAllow && g.Mode != GeoBlockingDeny {
+		return fmt.Errorf("mode must be %q or %q", GeoBlockingAllow, GeoBlockingDeny)
+	}
+	if len(g.Countries) == 0 {
+		return fmt.Errorf("countries must not be empty")
+	}
+	seen := map[string]bool{}
+	for i, c := range g.Countries {
+		if !isoCountryCodes[c] {
+			return fmt.Errorf("countries[%d]: %q is not an ISO 3166-1 alpha-2 country code", i, c)
+		}
+		if seen[c] {
+			return fmt.Errorf("countries[%d]: duplicate country %q", i, c)
+		}
+		seen[c] = true
+	}
+	return nil
+}
+
+// isoCountryCodes are the officially assigned ISO 3166-1 alpha-2 codes, the
+// codes GeoIP2 country databases report.
+var isoCountryCodes = func() map[string]bool {
+	codes := strings.Fields(`
+		AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ
+		BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW BY BZ
+		CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ
+		DE DJ DK DM DO DZ
+		EC EE EG EH ER ES ET
+		FI FJ FK FM FO FR
+		GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY
+		HK HM HN HR HT HU
+		ID IE IL IM IN IO IQ IR IS IT
+		JE JM JO JP
+		KE KG KH KI KM KN KP KR KW KY KZ
+		LA LB LC LI LK LR LS LT LU LV LY
+		MA MC MD ME MF MG MH MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ
+		NA NC NE NF NG NI NL NO NP NR NU NZ
+		OM
+		PA PE PF PG PH PK PL PM PN PR PS PT PW PY
+		QA
+		RE RO RS RU RW
+		SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ
+		TC TD TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ
+		UA UG UM US UY UZ
+		VA VC VE VG VI VN VU
+		WF WS
+		YE YT
+		ZA ZM ZW`)
+	m := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		m[c] = true
+	}
+	return m
+}()
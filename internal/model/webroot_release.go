@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// WebrootReleasesToKeep is how many releases a webroot keeps for rollback.
+// Older ones are pruned after each promotion; the live release is always kept.
+const WebrootReleasesToKeep = 5
+
+// WebrootReleaseNameFormat is the time layout of release names, which double
+// as the release directory under the webroot's releases/ folder.
+const WebrootReleaseNameFormat = "20060102T150405Z"
+
+// WebrootRelease is one deploy of a webroot's code. Its files live in
+// releases/<name> inside the webroot; the live release is the one the
+// webroot's current symlink points at.
+type WebrootRelease struct {
+	ID            string     `json:"id" db:"id"`
+	TenantID      string     `json:"tenant_id" db:"tenant_id"`
+	WebrootID     string     `json:"webroot_id" db:"webroot_id"`
+	Name          string     `json:"name" db:"name"`
+	Current       bool       `json:"current" db:"current"`
+	Status        string     `json:"status" db:"status"`
+	StatusMessage *string    `json:"status_message,omitempty" db:"status_message"`
+	PromotedAt    *time.Time `json:"promoted_at,omitempty" db:"promoted_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+}
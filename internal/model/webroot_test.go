@@ -0,0 +1,61 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebrootStaticRulesValidate(t *testing.T) {
+	valid := WebrootStaticRules{
+		CacheRules: []WebrootCacheRule{
+			{Pattern: "/assets/", CacheControl: "public, max-age=31536000, immutable"},
+			{Pattern: "*.tar.gz", Expires: "7d"},
+			{Pattern: "/", Expires: "off"},
+		},
+		MimeTypes: map[string]string{"wasm": "application/wasm"},
+	}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, WebrootStaticRules{}.Validate())
+
+	rule := func(r WebrootCacheRule) WebrootStaticRules {
+		return WebrootStaticRules{CacheRules: []WebrootCacheRule{r}}
+	}
+	assert.ErrorContains(t, rule(WebrootCacheRule{Pattern: "assets/", Expires: "1d"}).Validate(), "pattern must be")
+	assert.ErrorContains(t, rule(WebrootCacheRule{Pattern: "*.{css,js}", Expires: "1d"}).Validate(), "pattern must be")
+	assert.ErrorContains(t, rule(WebrootCacheRule{Pattern: "/a b", Expires: "1d"}).Validate(), "pattern must be")
+	assert.ErrorContains(t, rule(WebrootCacheRule{Pattern: "*.css"}).Validate(), "exactly one")
+	assert.ErrorContains(t, rule(WebrootCacheRule{Pattern: "*.css", CacheControl: "no-cache", Expires: "1d"}).Validate(), "exactly one")
+	assert.ErrorContains(t, rule(WebrootCacheRule{Pattern: "*.css", CacheControl: "no-cache;\nroot /"}).Validate(), "cache_control")
+	assert.ErrorContains(t, rule(WebrootCacheRule{Pattern: "*.css", Expires: "30 days"}).Validate(), "expires")
+
+	dup := WebrootStaticRules{CacheRules: []WebrootCacheRule{
+		{Pattern: "*.css", Expires: "1d"}, {Pattern: "*.css", Expires: "2d"},
+	}}
+	assert.ErrorContains(t, dup.Validate(), "duplicate pattern")
+
+	tooMany := WebrootStaticRules{}
+	for i := 0; i <= MaxWebrootCacheRules; i++ {
+		tooMany.CacheRules = append(tooMany.CacheRules, WebrootCacheRule{Pattern: fmt.Sprintf("/p%d/", i), Expires: "1d"})
+	}
+	assert.ErrorContains(t, tooMany.Validate(), "at most")
+
+	assert.ErrorContains(t, WebrootStaticRules{MimeTypes: map[string]string{".wasm": "application/wasm"}}.Validate(), "extension")
+	assert.ErrorContains(t, WebrootStaticRules{MimeTypes: map[string]string{"wasm": "wasm"}}.Validate(), "invalid MIME type")
+}
+
+func TestWebrootGeoBlockingValidate(t *testing.T) {
+	assert.NoError(t, WebrootGeoBlocking{}.Validate())
+	assert.False(t, WebrootGeoBlocking{}.Enabled())
+
+	valid := WebrootGeoBlocking{Mode: GeoBlockingAllow, Countries: []string{"NO", "SE", "DK"}}
+	assert.NoError(t, valid.Validate())
+	assert.True(t, valid.Enabled())
+
+	assert.ErrorContains(t, WebrootGeoBlocking{Mode: "block", Countries: []string{"NO"}}.Validate(), "mode must be")
+	assert.ErrorContains(t, WebrootGeoBlocking{Mode: GeoBlockingDeny}.Validate(), "must not be empty")
+	assert.ErrorContains(t, WebrootGeoBlocking{Mode: GeoBlockingDeny, Countries: []string{"XX"}}.Validate(), "not an ISO 3166-1")
+	assert.ErrorContains(t, WebrootGeoBlocking{Mode: GeoBlockingDeny, Countries: []string{"no"}}.Validate(), "not an ISO 3166-1")
+	assert.ErrorContains(t, WebrootGeoBlocking{Mode: GeoBlockingDeny, Countries: []string{"NO", "NO"}}.Validate(), "duplicate")
+}
@@ -12,8 +12,76 @@ type Zone struct {
 	Status         string  `json:"status" db:"status"`
 	StatusMessage  *string `json:"status_message,omitempty" db:"status_message"`
 	SuspendReason  string  `json:"suspend_reason" db:"suspend_reason"`
+	DNSSECStatus   string  `json:"dnssec_status" db:"dnssec_status"`
+	SecondaryRegionIDs []string `json:"secondary_region_ids" db:"secondary_region_ids"`
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 	RegionName     string    `json:"region_name,omitempty" db:"-"`
 	TenantName     *string   `json:"tenant_name,omitempty" db:"-"`
 }
+
+// DNSSEC signing states of a zone.
+const (
+	DNSSECUnsigned  = "unsigned"
+	DNSSECSigning   = "signing"
+	DNSSECSigned    = "signed"
+	DNSSECUnsigning = "unsigning"
+	DNSSECFailed    = "failed"
+)
+
+// DNSSEC key types.
+const (
+	DNSSECKeyKSK = "ksk"
+	DNSSECKeyZSK = "zsk"
+)
+
+// ZoneDNSSECKey is the public part of one of a zone's DNSSEC keys. A key
+// that is published but not active is being rolled in or out.
+type ZoneDNSSECKey struct {
+	ID        int       `json:"id" db:"pdns_key_id"`
+	KeyType   string    `json:"key_type" db:"key_type"`
+	KeyTag    int       `json:"key_tag" db:"key_tag"`
+	Algorithm int       `json:"algorithm" db:"algorithm"`
+	DNSKEY    string    `json:"dnskey" db:"dnskey"`
+	DS        *string   `json:"ds,omitempty" db:"ds"`
+	Active    bool      `json:"active" db:"active"`
+	Published bool      `json:"published" db:"published"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ZoneDNSSEC is a zone's DNSSEC state. DS lists the records to add at the
+// registrar; it is empty while the zone is unsigned.
+type ZoneDNSSEC struct {
+	ZoneID string          `json:"zone_id"`
+	Status string          `json:"status"`
+	DS     []string        `json:"ds"`
+	Keys   []ZoneDNSSECKey `json:"keys"`
+}
+
+// Roles of a nameserver serving a zone.
+const (
+	NameserverPrimary   = "primary"
+	NameserverSecondary = "secondary"
+)
+
+// ZoneNameserver is a nameserver a zone is served from. Brand nameservers
+// come from the brand's primary_ns/secondary_ns; region nameservers are the
+// ones of the zone's region and of its secondary regions. Reachable reports
+// whether the nameserver answered an NS query for the zone.
+type ZoneNameserver struct {
+	Hostname  string `json:"hostname"`
+	Address   string `json:"address,omitempty"`
+	RegionID  string `json:"region_id,omitempty"`
+	Role      string `json:"role"`
+	Source    string `json:"source"`
+	Reachable bool   `json:"reachable"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ZoneNameservers lists where a zone is served.
+type ZoneNameservers struct {
+	ZoneID             string           `json:"zone_id"`
+	RegionID           string           `json:"region_id"`
+	SecondaryRegionIDs []string         `json:"secondary_region_ids"`
+	Nameservers        []ZoneNameserver `json:"nameservers"`
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Zone export formats.
+const (
+	ZoneExportBIND = "bind"
+	ZoneExportJSON = "json"
+)
+
+// ManagedByBrand marks the exported SOA and NS records, which come from the
+// brand configuration rather than from zone records.
+const ManagedByBrand = "brand"
+
+// ZoneExport is a snapshot of everything a zone serves: the SOA and NS
+// records from the brand configuration followed by the zone's records.
+type ZoneExport struct {
+	Zone    string             `json:"zone"`
+	Records []ZoneExportRecord `json:"records"`
+}
+
+// ZoneExportRecord is an exported record. PlatformManaged records (the SOA
+// and NS records and auto-managed records) cannot be edited through the zone
+// record API; the others can be passed unchanged to PUT /zones/{id}/records.
+type ZoneExportRecord struct {
+	Type            string `json:"type"`
+	Name            string `json:"name"`
+	Content         string `json:"content"`
+	TTL             int    `json:"ttl"`
+	Priority        *int   `json:"priority,omitempty"`
+	ManagedBy       string `json:"managed_by"`
+	PlatformManaged bool   `json:"platform_managed"`
+}
+
+// BIND renders the export as an RFC 1035 zone file. Names and hostnames in
+// record data are written fully qualified, TXT data is quoted and split into
+// 255 byte strings, and MX and SRV priorities are written in front of the
+// data.
+func (e *ZoneExport) BIND() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "$ORIGIN %s\n", absoluteName(e.Zone))
+	b.WriteString("$TTL 3600\n")
+	for _, r := range e.Records {
+		fmt.Fprintf(&b, "%s\t%d\tIN\t%s\t%s\n", absoluteName(r.Name), r.TTL, r.Type, bindRData(r))
+	}
+	return b.String()
+}
+
+func bindRData(r ZoneExportRecord) string {
+	data := r.Content
+	switch r.Type {
+	case "CNAME", "NS", "PTR", "ALIAS", "DNAME", "MX":
+		data = absoluteName(data)
+	case "SRV":
+		// weight port target
+		if f := strings.Fields(data); len(f) == 3 {
+			f[2] = absoluteName(f[2])
+			data = strings.Join(f, " ")
+		}
+	case "SOA":
+		// mname rname serial refresh retry expire minimum
+		if f := strings.Fields(data); len(f) == 7 {
+			f[0], f[1] = absoluteName(f[0]), absoluteName(f[1])
+			data = strings.Join(f, " ")
+		}
+	case "TXT":
+		data = quoteTXT(data)
+	}
+	if r.Priority != nil && (r.Type == "MX" || r.Type == "SRV") {
+		data = fmt.Sprintf("%d %s", *r.Priority, data)
+	}
+	return data
+}
+
+func absoluteName(name string) string {
+	if name == "" || strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// quoteTXT quotes TXT content as character strings of at most 255 bytes.
+// Content that is already quoted is returned as is.
+func quoteTXT(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return s
+	}
+	var parts []string
+	for len(s) > 255 {
+		parts = append(parts, s[:255])
+		s = s[255:]
+	}
+	parts = append(parts, s)
+	for i, p := range parts {
+		p = strings.ReplaceAll(p, `\`, `\\`)
+		parts[i] = `"` + strings.ReplaceAll(p, `"`, `\"`) + `"`
+	}
+	return strings.Join(parts, " ")
+}
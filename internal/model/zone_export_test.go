@@ -0,0 +1,84 @@
+package model
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testZoneExport() *ZoneExport {
+	prio := func(p int) *int { return &p }
+	return &ZoneExport{Zone: "example.com", Records: []ZoneExportRecord{
+		{Type: "SOA", Name: "example.com", Content: "ns1.hosting.test hostmaster.hosting.test 1 10800 3600 604800 300", TTL: 86400, ManagedBy: ManagedByBrand, PlatformManaged: true},
+		{Type: "NS", Name: "example.com", Content: "ns1.hosting.test", TTL: 86400, ManagedBy: ManagedByBrand, PlatformManaged: true},
+		{Type: "A", Name: "example.com", Content: "203.0.113.10", TTL: 300, ManagedBy: ManagedByAuto, PlatformManaged: true},
+		{Type: "MX", Name: "example.com", Content: "mail.hosting.test", TTL: 3600, Priority: prio(10), ManagedBy: ManagedByTemplate},
+		{Type: "TXT", Name: "example.com", Content: `v=spf1 include:"_spf.hosting.test" ~all`, TTL: 3600, ManagedBy: ManagedByCustom},
+		{Type: "SRV", Name: "_sip._tcp.example.com", Content: "5 5060 sip.example.com", TTL: 3600, Priority: prio(20), ManagedBy: ManagedByCustom},
+		{Type: "CNAME", Name: "www.example.com", Content: "example.com", TTL: 3600, ManagedBy: ManagedByCustom},
+	}}
+}
+
+func TestZoneExport_BIND(t *testing.T) {
+	assert.Equal(t, `$ORIGIN example.com.
+$TTL 3600
+example.com.	86400	IN	SOA	ns1.hosting.test. hostmaster.hosting.test. 1 10800 3600 604800 300
+example.com.	86400	IN	NS	ns1.hosting.test.
+example.com.	300	IN	A	203.0.113.10
+example.com.	3600	IN	MX	10 mail.hosting.test.
+example.com.	3600	IN	TXT	"v=spf1 include:\"_spf.hosting.test\" ~all"
+_sip._tcp.example.com.	3600	IN	SRV	20 5 5060 sip.example.com.
+www.example.com.	3600	IN	CNAME	example.com.
+`, testZoneExport().BIND())
+}
+
+func TestZoneExport_BIND_LongTXT(t *testing.T) {
+	key := strings.Repeat("A", 300)
+	e := &ZoneExport{Zone: "example.com", Records: []ZoneExportRecord{
+		{Type: "TXT", Name: "hosting._domainkey.example.com", Content: "p=" + key, TTL: 3600},
+	}}
+	assert.Contains(t, e.BIND(), `"p=`+key[:253]+`" "`+key[253:]+`"`)
+}
+
+// TestZoneExport_BIND_RoundTrip parses the zone file back into records the
+// way a zone file import reads it and checks nothing is lost.
+func TestZoneExport_BIND_RoundTrip(t *testing.T) {
+	e := testZoneExport()
+	lines := strings.Split(strings.TrimSpace(e.BIND()), "\n")
+	require.Equal(t, "$ORIGIN example.com.", lines[0])
+	require.Len(t, lines[2:], len(e.Records))
+
+	for i, line := range lines[2:] {
+		want := e.Records[i]
+		cols := strings.SplitN(line, "\t", 5)
+		require.Len(t, cols, 5, line)
+		assert.Equal(t, want.Name, strings.TrimSuffix(cols[0], "."))
+		assert.Equal(t, strconv.Itoa(want.TTL), cols[1])
+		assert.Equal(t, "IN", cols[2])
+		assert.Equal(t, want.Type, cols[3])
+
+		data := cols[4]
+		if want.Priority != nil {
+			p, rest, _ := strings.Cut(data, " ")
+			assert.Equal(t, strconv.Itoa(*want.Priority), p)
+			data = rest
+		}
+		if want.Type == "TXT" {
+			unquoted, err := strconv.Unquote(data)
+			require.NoError(t, err)
+			data = unquoted
+		}
+		fields := strings.Fields(data)
+		for j, f := range fields {
+			fields[j] = strings.TrimSuffix(f, ".")
+		}
+		if want.Type == "TXT" {
+			assert.Equal(t, want.Content, data)
+		} else {
+			assert.Equal(t, want.Content, strings.Join(fields, " "))
+		}
+	}
+}
@@ -19,6 +19,13 @@ type ZoneRecord struct {
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// ZoneRecordSearchResult is a record found by a tenant-wide record search,
+// with the zone it belongs to.
+type ZoneRecordSearchResult struct {
+	ZoneRecord
+	ZoneName string `json:"zone_name"`
+}
+
 // ZoneRecordParams contains all data needed by zone record workflows.
 // Passed directly from the service layer to avoid an extra activity call.
 type ZoneRecordParams struct {
@@ -32,9 +39,53 @@ type ZoneRecordParams struct {
 	ZoneName  string `json:"zone_name"`
 }
 
+// ZoneRecordBatchParams is a set of record changes to one zone, applied to
+// PowerDNS in a single transaction by ApplyZoneRecordsWorkflow. Writes are
+// created or updated records; Deletes are removed records.
+type ZoneRecordBatchParams struct {
+	ZoneID   string             `json:"zone_id"`
+	ZoneName string             `json:"zone_name"`
+	Writes   []ZoneRecordParams `json:"writes"`
+	Deletes  []ZoneRecordParams `json:"deletes"`
+}
+
+// ZoneRecordSetChange is the server-side diff of a zone's records against a
+// desired record set.
+type ZoneRecordSetChange struct {
+	Created   []ZoneRecord `json:"created"`
+	Updated   []ZoneRecord `json:"updated"`
+	Deleted   []ZoneRecord `json:"deleted"`
+	Unchanged int          `json:"unchanged"`
+}
+
+// Empty reports whether the change has nothing to apply.
+func (c *ZoneRecordSetChange) Empty() bool {
+	return len(c.Created) == 0 && len(c.Updated) == 0 && len(c.Deleted) == 0
+}
+
+// ZoneRecordSetPreview is the diff a replacement of a zone's records would
+// apply, with the zone's SOA serial now and after the change. The serials
+// are nil if the zone has no SOA record in DNS.
+type ZoneRecordSetPreview struct {
+	ZoneRecordSetChange
+	CurrentSOASerial *uint32 `json:"current_soa_serial"`
+	SOASerial        *uint32 `json:"soa_serial"`
+}
+
+// NextSOASerial returns the serial following serial. It wraps per RFC 1982
+// but skips 0, which PowerDNS treats as "compute the serial itself".
+func NextSOASerial(serial uint32) uint32 {
+	serial++
+	if serial == 0 {
+		serial = 1
+	}
+	return serial
+}
+
 const (
 	ManagedByCustom   = "custom"
 	ManagedByAuto     = "auto"
+	ManagedByTemplate = "template"
 
 	// Source types for auto-managed records.
 	SourceTypeFQDN      = "fqdn"
@@ -0,0 +1,75 @@
+// Package objectstore wraps a single S3 bucket used for platform-owned
+// objects such as tenant export archives. Uploads and downloads go through
+// presigned URLs so node agents and end users never hold bucket credentials.
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Bucket is an S3 bucket. A nil Bucket means object storage is not configured.
+type Bucket struct {
+	client  *s3.Client
+	presign *s3.PresignClient
+	name    string
+}
+
+// New returns a Bucket for the given endpoint and credentials, or nil if no
+// endpoint or bucket is configured.
+func New(endpoint, region, bucket, accessKey, secretKey string) *Bucket {
+	if endpoint == "" || bucket == "" {
+		return nil
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	client := s3.New(s3.Options{
+		BaseEndpoint: aws.String(endpoint),
+		Region:       region,
+		Credentials:  credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""),
+		UsePathStyle: true,
+	})
+	return &Bucket{client: client, presign: s3.NewPresignClient(client), name: bucket}
+}
+
+// PresignPut returns a URL that accepts a single HTTP PUT of the object.
+func (b *Bucket) PresignPut(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	req, err := b.presign.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("presign put %s: %w", key, err)
+	}
+	return req.URL, nil
+}
+
+// PresignGet returns a URL that downloads the object until ttl elapses.
+func (b *Bucket) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	req, err := b.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("presign get %s: %w", key, err)
+	}
+	return req.URL, nil
+}
+
+// Delete removes the object. Deleting a missing object is not an error.
+func (b *Bucket) Delete(ctx context.Context, key string) error {
+	_, err := b.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(b.name),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("delete object %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package objectstore
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_Unconfigured(t *testing.T) {
+	assert.Nil(t, New("", "", "exports", "key", "secret"))
+	assert.Nil(t, New("http://rgw:7480", "", "", "key", "secret"))
+}
+
+func TestPresign(t *testing.T) {
+	b := New("http://rgw.internal:7480", "", "exports", "AKID", "SECRET")
+	require.NotNil(t, b)
+
+	raw, err := b.PresignGet(context.Background(), "tenant-exports/t1/e1.tar.gz", time.Hour)
+	require.NoError(t, err)
+	u, err := url.Parse(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "rgw.internal:7480", u.Host)
+	assert.Equal(t, "/exports/tenant-exports/t1/e1.tar.gz", u.Path)
+	assert.Equal(t, "3600", u.Query().Get("X-Amz-Expires"))
+	assert.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+
+	raw, err = b.PresignPut(context.Background(), "tenant-exports/t1/e1.tar.gz", 15*time.Minute)
+	require.NoError(t, err)
+	u, err = url.Parse(raw)
+	require.NoError(t, err)
+	assert.Equal(t, "900", u.Query().Get("X-Amz-Expires"))
+}
@@ -0,0 +1,217 @@
+// Package secrets generates passwords for platform-managed credentials and
+// checks user-supplied passwords against a complexity policy.
+//
+// Generated passwords are drawn from letters, digits and "-_." only, and
+// always start with a letter, so they can be embedded unquoted in shell
+// commands, URLs and DSNs, MySQL statements, and Valkey ACL and config files.
+package secrets
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// Class is a character class a password can be required to contain.
+type Class string
+
+const (
+	Lower  Class = "lower"
+	Upper  Class = "upper"
+	Digit  Class = "digit"
+	Symbol Class = "symbol"
+)
+
+// alphabets are the characters generated passwords draw from per class.
+var alphabets = map[Class]string{
+	Lower:  "abcdefghijklmnopqrstuvwxyz",
+	Upper:  "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	Digit:  "0123456789",
+	Symbol: "-_.",
+}
+
+// MinLength is the shortest password any policy accepts.
+const MinLength = 8
+
+// ErrWeakPassword is returned when a password does not meet a policy.
+var ErrWeakPassword = errors.New("password does not meet the password policy")
+
+// ParseClass returns the class named s.
+func ParseClass(s string) (Class, error) {
+	c := Class(strings.ToLower(strings.TrimSpace(s)))
+	if _, ok := alphabets[c]; !ok {
+		return "", fmt.Errorf("unknown character class %q: must be one of lower, upper, digit, symbol", s)
+	}
+	return c, nil
+}
+
+// ParseClasses parses a comma-separated list of class names.
+func ParseClasses(s string) ([]Class, error) {
+	var classes []Class
+	for _, part := range strings.Split(s, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		c, err := ParseClass(part)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, c)
+	}
+	return classes, nil
+}
+
+// Policy is the minimum length and the character classes a user-supplied
+// password must contain.
+type Policy struct {
+	MinLength int
+	Classes   []Class
+}
+
+// DefaultPolicy only enforces MinLength.
+var DefaultPolicy = Policy{MinLength: MinLength}
+
+// Check returns an error wrapping ErrWeakPassword that names the first rule
+// password breaks. Any character that is not a letter or digit counts as a
+// symbol.
+func (p Policy) Check(password string) error {
+	minLength := max(p.MinLength, MinLength)
+	if n := len([]rune(password)); n < minLength {
+		return fmt.Errorf("%w: must be at least %d characters, got %d", ErrWeakPassword, minLength, n)
+	}
+	for _, c := range p.Classes {
+		if !containsClass(password, c) {
+			return fmt.Errorf("%w: must contain %s", ErrWeakPassword, describe(c))
+		}
+	}
+	return nil
+}
+
+func containsClass(password string, c Class) bool {
+	for _, r := range password {
+		switch c {
+		case Lower:
+			if unicode.IsLower(r) {
+				return true
+			}
+		case Upper:
+			if unicode.IsUpper(r) {
+				return true
+			}
+		case Digit:
+			if unicode.IsDigit(r) {
+				return true
+			}
+		case Symbol:
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func describe(c Class) string {
+	switch c {
+	case Lower:
+		return "a lowercase letter"
+	case Upper:
+		return "an uppercase letter"
+	case Digit:
+		return "a digit"
+	default:
+		return "a symbol"
+	}
+}
+
+// Generator generates passwords of Length characters from Classes, with at
+// least one character of each class.
+type Generator struct {
+	Length  int
+	Classes []Class
+}
+
+// DefaultGenerator is used by Generate. The core API replaces it with the
+// configured length and classes at startup.
+var DefaultGenerator = Generator{Length: 32, Classes: []Class{Lower, Upper, Digit}}
+
+// Generate returns a password from DefaultGenerator.
+func Generate() (string, error) {
+	return DefaultGenerator.Generate()
+}
+
+// Validate checks that the generator can produce passwords.
+func (g Generator) Validate() error {
+	if g.Length < MinLength {
+		return fmt.Errorf("password length %d is below the minimum of %d", g.Length, MinLength)
+	}
+	if g.Length < len(g.Classes)+1 {
+		return fmt.Errorf("password length %d is too short for %d character classes", g.Length, len(g.Classes))
+	}
+	hasLetter := false
+	for _, c := range g.Classes {
+		if _, ok := alphabets[c]; !ok {
+			return fmt.Errorf("unknown character class %q", c)
+		}
+		hasLetter = hasLetter || c == Lower || c == Upper
+	}
+	if !hasLetter {
+		return errors.New("password classes must include lower or upper")
+	}
+	return nil
+}
+
+// Generate returns a random password. The first character is a letter so the
+// password is never mistaken for a command-line flag.
+func (g Generator) Generate() (string, error) {
+	if err := g.Validate(); err != nil {
+		return "", err
+	}
+
+	var all, letters strings.Builder
+	for _, c := range g.Classes {
+		all.WriteString(alphabets[c])
+		if c == Lower || c == Upper {
+			letters.WriteString(alphabets[c])
+		}
+	}
+
+	for {
+		b := make([]byte, g.Length)
+		first, err := randomChar(letters.String())
+		if err != nil {
+			return "", err
+		}
+		b[0] = first
+		for i := 1; i < len(b); i++ {
+			if b[i], err = randomChar(all.String()); err != nil {
+				return "", err
+			}
+		}
+		// Resample rather than patch in missing classes, which would make
+		// some positions predictable.
+		if (Policy{Classes: g.Classes}).checkClasses(string(b)) {
+			return string(b), nil
+		}
+	}
+}
+
+func (p Policy) checkClasses(password string) bool {
+	for _, c := range p.Classes {
+		if !strings.ContainsAny(password, alphabets[c]) {
+			return false
+		}
+	}
+	return true
+}
+
+func randomChar(alphabet string) (byte, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(len(alphabet))))
+	if err != nil {
+		return 0, fmt.Errorf("generate password: %w", err)
+	}
+	return alphabet[n.Int64()], nil
+}
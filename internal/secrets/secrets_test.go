@@ -0,0 +1,96 @@
+package secrets
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	policy := Policy{MinLength: 12, Classes: []Class{Lower, Upper, Digit, Symbol}}
+
+	tests := []struct {
+		password string
+		wantErr  string
+	}{
+		{"Correct-Horse-9", ""},
+		{"Sh0rt-pass", "at least 12 characters, got 10"},
+		{"NO-LOWER-CASE-1", "a lowercase letter"},
+		{"no-upper-case-1", "an uppercase letter"},
+		{"No-Digits-Here", "a digit"},
+		{"NoSymbolsHere1", "a symbol"},
+		{"Ünïcode-Pässwörd-1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			err := policy.Check(tt.password)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, ErrWeakPassword))
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestPolicyCheck_EnforcesPlatformMinimum(t *testing.T) {
+	err := Policy{MinLength: 4}.Check("abc12")
+	require.ErrorIs(t, err, ErrWeakPassword)
+	assert.Contains(t, err.Error(), "at least 8 characters")
+
+	assert.NoError(t, DefaultPolicy.Check("abcdefgh"))
+}
+
+func TestParseClasses(t *testing.T) {
+	classes, err := ParseClasses("lower, UPPER,digit,,symbol")
+	require.NoError(t, err)
+	assert.Equal(t, []Class{Lower, Upper, Digit, Symbol}, classes)
+
+	_, err = ParseClasses("lower,emoji")
+	assert.ErrorContains(t, err, `unknown character class "emoji"`)
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	g := Generator{Length: 20, Classes: []Class{Lower, Upper, Digit, Symbol}}
+	policy := Policy{MinLength: 20, Classes: g.Classes}
+
+	seen := map[string]bool{}
+	for range 200 {
+		pw, err := g.Generate()
+		require.NoError(t, err)
+		assert.Len(t, pw, 20)
+		assert.True(t, unicode.IsLetter(rune(pw[0])), "first character of %q is not a letter", pw)
+		assert.NoError(t, policy.Check(pw))
+		assert.Empty(t, strings.Trim(pw, alphabets[Lower]+alphabets[Upper]+alphabets[Digit]+"-_."))
+		assert.False(t, seen[pw])
+		seen[pw] = true
+	}
+}
+
+func TestGenerator_OnlyRequestedClasses(t *testing.T) {
+	pw, err := Generator{Length: 16, Classes: []Class{Lower, Digit}}.Generate()
+	require.NoError(t, err)
+	assert.Empty(t, strings.Trim(pw, alphabets[Lower]+alphabets[Digit]))
+}
+
+func TestGenerator_Validate(t *testing.T) {
+	assert.NoError(t, DefaultGenerator.Validate())
+	assert.ErrorContains(t, Generator{Length: 6, Classes: []Class{Lower}}.Validate(), "below the minimum")
+	assert.ErrorContains(t, Generator{Length: 16, Classes: []Class{Digit, Symbol}}.Validate(), "must include lower or upper")
+	assert.ErrorContains(t, Generator{Length: 16, Classes: []Class{"emoji"}}.Validate(), "unknown character class")
+
+	_, err := Generator{Length: 4}.Generate()
+	assert.Error(t, err)
+}
+
+func TestGenerate_UsesDefault(t *testing.T) {
+	pw, err := Generate()
+	require.NoError(t, err)
+	assert.Len(t, pw, DefaultGenerator.Length)
+}
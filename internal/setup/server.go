@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"math"
 	"net/http"
@@ -49,6 +50,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("GET /api/config", s.handleGetConfig)
 	mux.HandleFunc("PUT /api/config", s.handlePutConfig)
 	mux.HandleFunc("POST /api/validate", s.handleValidate)
+	mux.HandleFunc("POST /api/validate-manifest", s.handleValidateManifest)
 	mux.HandleFunc("POST /api/generate", s.handleGenerate)
 	mux.HandleFunc("GET /api/roles", s.handleGetRoles)
 	mux.HandleFunc("GET /api/info", s.handleGetInfo)
@@ -88,11 +90,22 @@ func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
 	cfg := s.config
 	s.mu.Unlock()
 
-	errs := Validate(cfg)
-	writeJSON(w, http.StatusOK, map[string]any{
-		"valid":  len(errs) == 0,
-		"errors": errs,
-	})
+	writeJSON(w, http.StatusOK, newValidationResult(Validate(cfg)))
+}
+
+// maxManifestSize bounds manifests uploaded for validation.
+const maxManifestSize = 1 << 20
+
+// handleValidateManifest validates a setup.yaml sent as the request body
+// without touching the wizard's config or generating files.
+func (s *Server) handleValidateManifest(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxManifestSize))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Read manifest: " + err.Error()})
+		return
+	}
+	_, result := ValidateManifestData(data)
+	writeJSON(w, http.StatusOK, result)
 }
 
 func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
@@ -100,12 +113,8 @@ func (s *Server) handleGenerate(w http.ResponseWriter, r *http.Request) {
 	cfg := s.config
 	s.mu.Unlock()
 
-	errs := Validate(cfg)
-	if len(errs) > 0 {
-		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
-			"valid":  false,
-			"errors": errs,
-		})
+	if errs := Validate(cfg); len(errs) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, newValidationResult(errs))
 		return
 	}
 
@@ -241,18 +250,15 @@ func (s *Server) spaHandler() http.Handler {
 // GenerateFromManifest loads a manifest file and generates deployment files.
 // This is the CLI entry point for `setup generate`.
 func GenerateFromManifest(manifestPath, outputDir string) error {
-	cfg, err := LoadManifest(manifestPath)
+	data, err := os.ReadFile(manifestPath)
 	if err != nil {
-		return err
+		return fmt.Errorf("read manifest: %w", err)
 	}
 
-	errs := Validate(cfg)
-	if len(errs) > 0 {
-		fmt.Fprintf(os.Stderr, "Validation errors in %s:\n", manifestPath)
-		for _, e := range errs {
-			fmt.Fprintf(os.Stderr, "  %s: %s\n", e.Field, e.Message)
-		}
-		return fmt.Errorf("%d validation errors", len(errs))
+	cfg, validation := ValidateManifestData(data)
+	if !validation.Valid {
+		PrintValidationErrors(os.Stderr, manifestPath, validation)
+		return fmt.Errorf("%d validation errors", len(validation.Errors))
 	}
 
 	result, err := Generate(cfg, outputDir, func(msg string) {
@@ -272,6 +278,15 @@ func GenerateFromManifest(manifestPath, outputDir string) error {
 	return nil
 }
 
+// PrintValidationErrors writes the errors of a manifest validation, one per
+// line.
+func PrintValidationErrors(w io.Writer, manifestPath string, result *ValidationResult) {
+	fmt.Fprintf(w, "Validation errors in %s:\n", manifestPath)
+	for _, e := range result.Errors {
+		fmt.Fprintf(w, "  %s: %s\n", e.Field, e.Message)
+	}
+}
+
 // PodInfo is a simplified view of a Kubernetes pod for the UI.
 type PodInfo struct {
 	Name      string `json:"name"`
@@ -1,9 +1,15 @@
 package setup
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"regexp"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // ValidationError represents a field-level validation error.
@@ -12,6 +18,25 @@ type ValidationError struct {
 	Message string `json:"message"`
 }
 
+// ValidationResult is the outcome of validating a config or manifest. It is
+// the body of the wizard's validate endpoints and of
+// `setup generate -validate -json`.
+type ValidationResult struct {
+	Valid  bool              `json:"valid"`
+	Errors []ValidationError `json:"errors"`
+}
+
+func newValidationResult(errs []ValidationError) *ValidationResult {
+	if errs == nil {
+		errs = []ValidationError{}
+	}
+	return &ValidationResult{Valid: len(errs) == 0, Errors: errs}
+}
+
+// slugPattern matches region and cluster names, which become IDs in the
+// generated seed and Helm values.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
 // Validate checks the config and returns any validation errors.
 func Validate(cfg *Config) []ValidationError {
 	var errs []ValidationError
@@ -34,9 +59,13 @@ func Validate(cfg *Config) []ValidationError {
 	// Region & cluster
 	if cfg.RegionName == "" {
 		add("region_name", "Region name is required")
+	} else if !slugPattern.MatchString(cfg.RegionName) {
+		add("region_name", "Must be lowercase letters, digits and dashes; it is used as the region ID")
 	}
 	if cfg.ClusterName == "" {
 		add("cluster_name", "Cluster name is required")
+	} else if !slugPattern.MatchString(cfg.ClusterName) {
+		add("cluster_name", "Must be lowercase letters, digits and dashes; it is used as the cluster ID")
 	}
 
 	// Brand
@@ -61,6 +90,9 @@ func Validate(cfg *Config) []ValidationError {
 	if cfg.Brand.SecondaryNSIP != "" && !isValidIP(cfg.Brand.SecondaryNSIP) {
 		add("brand.secondary_ns_ip", "Must be a valid IP address")
 	}
+	if cfg.Brand.PrimaryNS != "" && strings.EqualFold(cfg.Brand.PrimaryNS, cfg.Brand.SecondaryNS) {
+		add("brand.secondary_ns", "Must differ from the primary nameserver")
+	}
 	if cfg.Brand.MailHostname == "" {
 		add("brand.mail_hostname", "Mail hostname is required")
 	}
@@ -69,6 +101,11 @@ func Validate(cfg *Config) []ValidationError {
 	}
 
 	// Control plane DB
+	switch cfg.ControlPlane.Database.Mode {
+	case "builtin", "external":
+	default:
+		add("control_plane.database.mode", "Must be builtin or external")
+	}
 	if cfg.ControlPlane.Database.Mode == "external" {
 		if cfg.ControlPlane.Database.Host == "" {
 			add("control_plane.database.host", "Database host is required for external mode")
@@ -90,20 +127,37 @@ func Validate(cfg *Config) []ValidationError {
 			add("nodes", "At least one node is required in multi-node mode")
 		}
 		hasControlPlane := false
+		knownRoles := map[NodeRole]bool{}
+		for _, r := range AllRoles {
+			knownRoles[r] = true
+		}
+		hostnames := map[string]int{}
+		ips := map[string]int{}
 		for i, n := range cfg.Nodes {
 			prefix := fmt.Sprintf("nodes[%d]", i)
 			if n.Hostname == "" {
 				add(prefix+".hostname", "Hostname is required")
+			} else if j, dup := hostnames[n.Hostname]; dup {
+				add(prefix+".hostname", fmt.Sprintf("Duplicate of nodes[%d]", j))
+			} else {
+				hostnames[n.Hostname] = i
 			}
 			if n.IP == "" {
 				add(prefix+".ip", "IP address is required")
 			} else if !isValidIP(n.IP) {
 				add(prefix+".ip", "Must be a valid IP address")
+			} else if j, dup := ips[n.IP]; dup {
+				add(prefix+".ip", fmt.Sprintf("Duplicate of nodes[%d]", j))
+			} else {
+				ips[n.IP] = i
 			}
 			if len(n.Roles) == 0 {
 				add(prefix+".roles", "At least one role must be assigned")
 			}
 			for _, r := range n.Roles {
+				if !knownRoles[r] {
+					add(prefix+".roles", fmt.Sprintf("Unknown role %q", r))
+				}
 				if r == RoleControlPlane {
 					hasControlPlane = true
 				}
@@ -147,11 +201,21 @@ func Validate(cfg *Config) []ValidationError {
 	}
 
 	// TLS
+	switch cfg.TLS.Mode {
+	case "letsencrypt", "manual":
+	default:
+		add("tls.mode", "Must be letsencrypt or manual")
+	}
 	if cfg.TLS.Mode == "letsencrypt" && cfg.TLS.Email == "" {
 		add("tls.email", "Email is required for Let's Encrypt")
 	}
 
 	// SSO
+	switch cfg.SSO.Mode {
+	case "", "internal", "external":
+	default:
+		add("sso.mode", "Must be internal, external or empty")
+	}
 	if cfg.SSO.Mode == "internal" {
 		if cfg.SSO.AdminEmail == "" {
 			add("sso.admin_email", "Admin email is required")
@@ -188,6 +252,40 @@ func Validate(cfg *Config) []ValidationError {
 	return errs
 }
 
+// ValidateManifest checks a manifest file without generating anything. It
+// returns all problems at once: keys that are not part of the manifest,
+// values of the wrong type and everything Validate reports. The error is
+// only set if the file cannot be read.
+func ValidateManifest(path string) (*ValidationResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	_, result := ValidateManifestData(data)
+	return result, nil
+}
+
+// ValidateManifestData parses and validates a manifest. The config is nil
+// if the YAML is malformed.
+func ValidateManifestData(data []byte) (*Config, *ValidationResult) {
+	var errs []ValidationError
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		// A TypeError still leaves the rest of the manifest decoded.
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, newValidationResult([]ValidationError{{Field: "manifest", Message: err.Error()}})
+		}
+		for _, msg := range typeErr.Errors {
+			errs = append(errs, ValidationError{Field: "manifest", Message: msg})
+		}
+	}
+	errs = append(errs, Validate(&cfg)...)
+	return &cfg, newValidationResult(errs)
+}
+
 func isValidIP(s string) bool {
 	return net.ParseIP(strings.TrimSpace(s)) != nil
 }
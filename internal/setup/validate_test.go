@@ -0,0 +1,126 @@
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validManifest = `deploy_mode: multi
+region_name: eu-1
+cluster_name: cluster-1
+brand:
+  name: Acme
+  platform_domain: acme.test
+  customer_domain: acme-sites.test
+  hostmaster_email: hostmaster@acme.test
+  primary_ns: ns1.acme.test
+  primary_ns_ip: 10.0.0.1
+  secondary_ns: ns2.acme.test
+  secondary_ns_ip: 10.0.0.2
+  mail_hostname: mail.acme.test
+control_plane:
+  database:
+    mode: builtin
+nodes:
+  - hostname: cp1
+    ip: 10.0.0.10
+    roles: [controlplane, web, database, valkey, dns, email, storage, dbadmin, lb, gateway]
+tls:
+  mode: manual
+email:
+  stalwart_admin_token: token
+php_versions: ["8.3"]
+api_key: hst_test
+`
+
+func errorFields(result *ValidationResult) []string {
+	fields := []string{}
+	for _, e := range result.Errors {
+		fields = append(fields, e.Field)
+	}
+	return fields
+}
+
+func TestValidateManifestData_Valid(t *testing.T) {
+	cfg, result := ValidateManifestData([]byte(validManifest))
+	require.NotNil(t, cfg)
+	assert.True(t, result.Valid, "errors: %v", result.Errors)
+	assert.Empty(t, result.Errors)
+	assert.NotNil(t, result.Errors)
+}
+
+func TestValidateManifestData_ReportsAllErrors(t *testing.T) {
+	manifest := `deploy_mode: multi
+region_name: EU West
+cluster_name: cluster-1
+brand:
+  primary_ns: ns1.acme.test
+  secondary_ns: NS1.acme.test
+control_plane:
+  database:
+    mode: managed
+nodes:
+  - hostname: web1
+    ip: 10.0.0.10
+    roles: [web, mailserver]
+  - hostname: web1
+    ip: 10.0.0.10
+    roles: [controlplane]
+tls:
+  mode: selfsigned
+sso:
+  mode: ldap
+unknown_key: true
+`
+	cfg, result := ValidateManifestData([]byte(manifest))
+	require.NotNil(t, cfg)
+	assert.False(t, result.Valid)
+
+	fields := errorFields(result)
+	for _, want := range []string{
+		"manifest",
+		"region_name",
+		"brand.secondary_ns",
+		"control_plane.database.mode",
+		"nodes[0].roles",
+		"nodes[1].hostname",
+		"nodes[1].ip",
+		"tls.mode",
+		"sso.mode",
+	} {
+		assert.Contains(t, fields, want)
+	}
+	assert.NotContains(t, fields, "cluster_name")
+}
+
+func TestValidateManifestData_TypeErrorStillValidates(t *testing.T) {
+	manifest := validManifest + "storage_enabled: maybe\n"
+	cfg, result := ValidateManifestData([]byte(manifest))
+	require.NotNil(t, cfg)
+	assert.False(t, result.Valid)
+	assert.Equal(t, []string{"manifest"}, errorFields(result))
+	assert.Equal(t, "eu-1", cfg.RegionName)
+}
+
+func TestValidateManifestData_MalformedYAML(t *testing.T) {
+	cfg, result := ValidateManifestData([]byte("deploy_mode: [single\n"))
+	assert.Nil(t, cfg)
+	assert.False(t, result.Valid)
+	assert.Equal(t, []string{"manifest"}, errorFields(result))
+}
+
+func TestValidateManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ManifestFilename)
+	require.NoError(t, os.WriteFile(path, []byte(validManifest), 0600))
+
+	result, err := ValidateManifest(path)
+	require.NoError(t, err)
+	assert.True(t, result.Valid, "errors: %v", result.Errors)
+
+	_, err = ValidateManifest(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
@@ -219,3 +219,180 @@ func (c *Client) GetPrincipalID(ctx context.Context, baseURL, adminToken, accoun
 	}
 	return result.Data.ID, nil
 }
+
+// DKIMSignatureID returns the id of a domain's DKIM signature. Stalwart's
+// default auth.dkim.sign rule signs mail from a local domain with the
+// signature named "rsa-{domain}".
+func DKIMSignatureID(domain string) string {
+	return "rsa-" + domain
+}
+
+// SetDKIMSignature installs or replaces the RSA DKIM signature for a domain.
+// The signature's settings are cleared first so a rotated key never keeps
+// stale values from the previous one.
+func (c *Client) SetDKIMSignature(ctx context.Context, baseURL, adminToken string, sig DKIMSignature) error {
+	prefix := "signature." + DKIMSignatureID(sig.Domain)
+	payload := []map[string]any{
+		{"type": "clear", "prefix": prefix + "."},
+		{
+			"type":   "insert",
+			"prefix": prefix,
+			"values": [][2]string{
+				{"algorithm", "rsa-sha256"},
+				{"domain", sig.Domain},
+				{"selector", sig.Selector},
+				{"private-key", sig.PrivateKeyPEM},
+				{"canonicalization", "relaxed/relaxed"},
+				{"headers", "From,To,Date,Subject,Message-ID"},
+			},
+			"assert_empty": false,
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal dkim signature: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/settings", baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("set dkim signature request: %w", err)
+	}
+	req.SetBasicAuth("admin", adminToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("set dkim signature: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("set dkim signature for %s: status %d: %s", sig.Domain, resp.StatusCode, string(respBody))
+	}
+	return c.reloadSettings(ctx, baseURL, adminToken)
+}
+
+// reloadSettings makes Stalwart apply settings written through the API.
+func (c *Client) reloadSettings(ctx context.Context, baseURL, adminToken string) error {
+	url := fmt.Sprintf("%s/api/reload", baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("reload settings request: %w", err)
+	}
+	req.SetBasicAuth("admin", adminToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("reload settings: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("reload settings: status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// CreateRelayUser creates a principal that can only authenticate for SMTP
+// submission. It has no mailbox and no addresses. secret may be a password
+// hash.
+func (c *Client) CreateRelayUser(ctx context.Context, baseURL, adminToken, name, secret string) error {
+	payload := map[string]any{
+		"type":        "individual",
+		"name":        name,
+		"description": "SMTP relay",
+		"secrets":     []string{secret},
+		"emails":      []string{},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal create relay user: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/principal", baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create relay user request: %w", err)
+	}
+	req.SetBasicAuth("admin", adminToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("create relay user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("create relay user %s: status %d: %s", name, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// SetRelayThrottle limits the messages a relay user may submit to perHour
+// with a session throttle named after the user. The throttle's settings are
+// cleared first so no stale values remain from a previous limit.
+func (c *Client) SetRelayThrottle(ctx context.Context, baseURL, adminToken, name string, perHour int) error {
+	prefix := "session.throttle." + name
+	payload := []map[string]any{
+		{"type": "clear", "prefix": prefix + "."},
+		{
+			"type":   "insert",
+			"prefix": prefix,
+			"values": [][2]string{
+				{"enable", "true"},
+				{"match", fmt.Sprintf("authenticated_as == '%s'", name)},
+				{"key", "authenticated_as"},
+				{"rate", fmt.Sprintf("%d/1h", perHour)},
+			},
+			"assert_empty": false,
+		},
+	}
+	if err := c.updateSettings(ctx, baseURL, adminToken, payload); err != nil {
+		return fmt.Errorf("set relay throttle for %s: %w", name, err)
+	}
+	return c.reloadSettings(ctx, baseURL, adminToken)
+}
+
+// DeleteRelayThrottle removes a relay user's throttle.
+func (c *Client) DeleteRelayThrottle(ctx context.Context, baseURL, adminToken, name string) error {
+	payload := []map[string]any{
+		{"type": "clear", "prefix": "session.throttle." + name + "."},
+	}
+	if err := c.updateSettings(ctx, baseURL, adminToken, payload); err != nil {
+		return fmt.Errorf("delete relay throttle for %s: %w", name, err)
+	}
+	return c.reloadSettings(ctx, baseURL, adminToken)
+}
+
+// updateSettings applies settings operations through the settings API.
+func (c *Client) updateSettings(ctx context.Context, baseURL, adminToken string, ops []map[string]any) error {
+	body, err := json.Marshal(ops)
+	if err != nil {
+		return fmt.Errorf("marshal settings: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/api/settings", baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("settings request: %w", err)
+	}
+	req.SetBasicAuth("admin", adminToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("update settings: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
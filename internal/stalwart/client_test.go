@@ -296,3 +296,130 @@ func TestClient_GetPrincipalID_Error(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "status 404")
 }
+
+// ---------- SetDKIMSignature ----------
+
+func TestClient_SetDKIMSignature_Success(t *testing.T) {
+	var reloaded bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/settings":
+			assert.Equal(t, http.MethodPost, r.Method)
+			var payload []map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			require.Len(t, payload, 2)
+			assert.Equal(t, "clear", payload[0]["type"])
+			assert.Equal(t, "signature.rsa-example.com.", payload[0]["prefix"])
+			assert.Equal(t, "insert", payload[1]["type"])
+			assert.Equal(t, "signature.rsa-example.com", payload[1]["prefix"])
+			values := map[string]string{}
+			for _, v := range payload[1]["values"].([]any) {
+				kv := v.([]any)
+				values[kv[0].(string)] = kv[1].(string)
+			}
+			assert.Equal(t, "example.com", values["domain"])
+			assert.Equal(t, "s202610", values["selector"])
+			assert.Equal(t, "PEM", values["private-key"])
+			assert.Equal(t, "rsa-sha256", values["algorithm"])
+		case "/api/reload":
+			assert.Equal(t, http.MethodGet, r.Method)
+			reloaded = true
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":null}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient()
+	err := client.SetDKIMSignature(context.Background(), srv.URL, "test-token", DKIMSignature{
+		Domain: "example.com", Selector: "s202610", PrivateKeyPEM: "PEM",
+	})
+	require.NoError(t, err)
+	assert.True(t, reloaded, "expected settings reload")
+}
+
+func TestClient_SetDKIMSignature_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("bad setting"))
+	}))
+	defer srv.Close()
+
+	client := NewClient()
+	err := client.SetDKIMSignature(context.Background(), srv.URL, "test-token", DKIMSignature{Domain: "example.com"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 400")
+}
+
+// ---------- Relay users ----------
+
+func TestClient_CreateRelayUser_Success(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/principal", r.URL.Path)
+
+		var payload map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "individual", payload["type"])
+		assert.Equal(t, "relay-abc", payload["name"])
+		assert.Equal(t, []any{"$2a$10$hash"}, payload["secrets"])
+		assert.Empty(t, payload["emails"])
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":7}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient()
+	err := client.CreateRelayUser(context.Background(), srv.URL, "test-token", "relay-abc", "$2a$10$hash")
+	require.NoError(t, err)
+}
+
+func TestClient_SetRelayThrottle_Success(t *testing.T) {
+	var reloaded bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/settings":
+			var payload []map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+			require.Len(t, payload, 2)
+			assert.Equal(t, "clear", payload[0]["type"])
+			assert.Equal(t, "session.throttle.relay-abc.", payload[0]["prefix"])
+			assert.Equal(t, "session.throttle.relay-abc", payload[1]["prefix"])
+			values := map[string]string{}
+			for _, v := range payload[1]["values"].([]any) {
+				kv := v.([]any)
+				values[kv[0].(string)] = kv[1].(string)
+			}
+			assert.Equal(t, "authenticated_as == 'relay-abc'", values["match"])
+			assert.Equal(t, "authenticated_as", values["key"])
+			assert.Equal(t, "250/1h", values["rate"])
+		case "/api/reload":
+			reloaded = true
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":null}`))
+	}))
+	defer srv.Close()
+
+	client := NewClient()
+	require.NoError(t, client.SetRelayThrottle(context.Background(), srv.URL, "test-token", "relay-abc", 250))
+	assert.True(t, reloaded, "expected settings reload")
+}
+
+func TestClient_DeleteRelayThrottle_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("store unavailable"))
+	}))
+	defer srv.Close()
+
+	client := NewClient()
+	err := client.DeleteRelayThrottle(context.Background(), srv.URL, "test-token", "relay-abc")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 500")
+}
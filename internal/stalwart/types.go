@@ -13,6 +13,13 @@ type Account struct {
 	QuotaBytes  int64  `json:"quota_bytes"`
 }
 
+// DKIMSignature is an RSA DKIM signing key for one domain.
+type DKIMSignature struct {
+	Domain        string
+	Selector      string
+	PrivateKeyPEM string
+}
+
 type PatchOp struct {
 	Action string `json:"action"` // "set", "addItem", "removeItem"
 	Field  string `json:"field"`
@@ -0,0 +1,62 @@
+package workflow
+
+import (
+	"context"
+
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/interceptor"
+)
+
+// ActivityConcurrencyInterceptor is a Temporal worker interceptor that caps
+// how many executions of selected activities run at once on this worker.
+// Temporal only limits concurrency per worker, so without it a node-agent
+// would happily run as many parallel backups as it has activity slots.
+//
+// An execution waiting for a slot holds one of the worker's activity slots
+// and its wait counts toward the activity's StartToCloseTimeout.
+type ActivityConcurrencyInterceptor struct {
+	interceptor.WorkerInterceptorBase
+	slots map[string]chan struct{}
+}
+
+// NewActivityConcurrencyInterceptor returns an interceptor enforcing limits,
+// a map of activity name to maximum parallel executions.
+func NewActivityConcurrencyInterceptor(limits map[string]int) *ActivityConcurrencyInterceptor {
+	slots := make(map[string]chan struct{}, len(limits))
+	for name, limit := range limits {
+		slots[name] = make(chan struct{}, limit)
+	}
+	return &ActivityConcurrencyInterceptor{slots: slots}
+}
+
+func (i *ActivityConcurrencyInterceptor) InterceptActivity(
+	ctx context.Context,
+	next interceptor.ActivityInboundInterceptor,
+) interceptor.ActivityInboundInterceptor {
+	return &activityConcurrencyInbound{
+		ActivityInboundInterceptorBase: interceptor.ActivityInboundInterceptorBase{Next: next},
+		slots:                          i.slots,
+	}
+}
+
+type activityConcurrencyInbound struct {
+	interceptor.ActivityInboundInterceptorBase
+	slots map[string]chan struct{}
+}
+
+func (a *activityConcurrencyInbound) ExecuteActivity(
+	ctx context.Context,
+	in *interceptor.ExecuteActivityInput,
+) (interface{}, error) {
+	sem, ok := a.slots[activity.GetInfo(ctx).ActivityType.Name]
+	if !ok {
+		return a.Next.ExecuteActivity(ctx, in)
+	}
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-sem }()
+	return a.Next.ExecuteActivity(ctx, in)
+}
@@ -0,0 +1,70 @@
+package workflow
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+)
+
+// concurrencyProbe records the peak number of activities running at once.
+type concurrencyProbe struct {
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (p *concurrencyProbe) run(ctx context.Context) error {
+	n := p.running.Add(1)
+	defer p.running.Add(-1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return nil
+}
+
+func runConcurrently(t *testing.T, limits map[string]int, name string, count int) int32 {
+	t.Helper()
+	limiter := NewActivityConcurrencyInterceptor(limits)
+	probe := &concurrencyProbe{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var ts testsuite.WorkflowTestSuite
+			env := ts.NewTestActivityEnvironment()
+			env.SetWorkerOptions(worker.Options{
+				Interceptors: []interceptor.WorkerInterceptor{limiter},
+			})
+			env.RegisterActivityWithOptions(probe.run, activity.RegisterOptions{Name: name})
+			_, err := env.ExecuteActivity(name)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	return probe.peak.Load()
+}
+
+func TestActivityConcurrencyInterceptor_CapsLimitedActivity(t *testing.T) {
+	peak := runConcurrently(t, map[string]int{"CreateWebBackup": 2}, "CreateWebBackup", 6)
+	require.Positive(t, peak)
+	assert.LessOrEqual(t, peak, int32(2))
+}
+
+func TestActivityConcurrencyInterceptor_IgnoresOtherActivities(t *testing.T) {
+	peak := runConcurrently(t, map[string]int{"CreateWebBackup": 1}, "WriteNginxConfig", 4)
+	assert.Greater(t, peak, int32(1))
+}
@@ -2,12 +2,14 @@ package workflow
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 
 	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/core"
 	"github.com/edvin/hosting/internal/model"
 )
 
@@ -124,8 +126,13 @@ func CreateBackupWorkflow(ctx workflow.Context, backupID string) error {
 	}).Get(ctx, nil)
 }
 
-// RestoreBackupWorkflow restores a backup to the target resource.
-func RestoreBackupWorkflow(ctx workflow.Context, backupID string) error {
+// RestoreBackupWorkflow restores a backup to the target resource. With
+// params.RestoreTo, a database backup is then rolled forward to that time by
+// replaying binlogs; if the node no longer retains them, the restore is
+// refused before the database is touched.
+func RestoreBackupWorkflow(ctx workflow.Context, params core.RestoreBackupParams) error {
+	backupID := params.BackupID
+
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 5 * time.Minute,
 		RetryPolicy: &temporal.RetryPolicy{
@@ -190,9 +197,38 @@ func RestoreBackupWorkflow(ctx workflow.Context, backupID string) error {
 			}
 		}
 
+		// The archive carries the owners and modes of whoever made it, so
+		// reconcile the tenant's tree before the restore counts as done.
+		var fix model.TenantPermissionsFix
+		err = workflow.ExecuteActivity(nodeActivityCtx(ctx, bctx.Nodes[0].ID), "FixTenantPermissions", activity.FixTenantPermissionsParams{
+			TenantName: bctx.Tenant.ID,
+		}).Get(ctx, &fix)
+		if err != nil {
+			_ = setResourceFailed(ctx, "backups", backupID, err)
+			return err
+		}
+		if fix.Truncated {
+			workflow.GetLogger(ctx).Warn("tenant permissions fix after restore stopped early", "backup", backupID, "tenant", bctx.Tenant.ID, "changed", fix.Changed)
+		}
+
 	case model.BackupTypeDatabase:
 		// Restore on first node only.
 		nodeCtx := nodeActivityCtx(ctx, bctx.Nodes[0].ID)
+		if params.RestoreTo != nil {
+			err = workflow.ExecuteActivity(nodeCtx, "CheckMySQLBinlogs", bctx.Backup.StoragePath).Get(ctx, nil)
+			if err != nil {
+				// The backup itself is fine; leave it restorable.
+				msg := fmt.Sprintf("point-in-time restore to %s refused: %v", params.RestoreTo.Format(time.RFC3339), err)
+				_ = workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+					Table:         "backups",
+					ID:            backupID,
+					Status:        model.StatusActive,
+					StatusMessage: &msg,
+				}).Get(ctx, nil)
+				return err
+			}
+		}
+
 		err = workflow.ExecuteActivity(nodeCtx, "RestoreMySQLBackup", activity.RestoreMySQLBackupParams{
 			DatabaseName: bctx.Backup.SourceName,
 			BackupPath:   bctx.Backup.StoragePath,
@@ -202,6 +238,25 @@ func RestoreBackupWorkflow(ctx workflow.Context, backupID string) error {
 			return err
 		}
 
+		if params.RestoreTo != nil {
+			// Replaying is not idempotent, so it is never retried. It can
+			// take much longer than a dump import.
+			ao := workflow.GetActivityOptions(nodeCtx)
+			ao.StartToCloseTimeout = 2 * time.Hour
+			ao.ScheduleToCloseTimeout = 2 * time.Hour
+			ao.RetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 1}
+			replayCtx := workflow.WithActivityOptions(nodeCtx, ao)
+			err = workflow.ExecuteActivity(replayCtx, "ReplayMySQLBinlogs", activity.ReplayMySQLBinlogsParams{
+				DatabaseName: bctx.Backup.SourceName,
+				BackupPath:   bctx.Backup.StoragePath,
+				StopAt:       *params.RestoreTo,
+			}).Get(ctx, nil)
+			if err != nil {
+				_ = setResourceFailed(ctx, "backups", backupID, err)
+				return err
+			}
+		}
+
 	default:
 		unsupportedErr := fmt.Errorf("unsupported backup type: %s", bctx.Backup.Type)
 		_ = setResourceFailed(ctx, "backups", backupID, unsupportedErr)
@@ -266,3 +321,101 @@ func DeleteBackupWorkflow(ctx workflow.Context, backupID string) error {
 		Status: model.StatusDeleted,
 	}).Get(ctx, nil)
 }
+
+// backupVerifyActivityCtx returns a node activity context with room for
+// restoring a full backup.
+func backupVerifyActivityCtx(ctx workflow.Context, nodeID string) workflow.Context {
+	ctx = nodeActivityCtx(ctx, nodeID)
+	ao := workflow.GetActivityOptions(ctx)
+	ao.StartToCloseTimeout = time.Hour
+	ao.ScheduleToCloseTimeout = 3 * time.Hour
+	return workflow.WithActivityOptions(ctx, ao)
+}
+
+// VerifyBackupWorkflow restore-tests a backup on the node that holds it:
+// web archives are extracted into a scratch directory and listed, database
+// dumps are imported into a scratch database and checked with mysqlcheck.
+// The scratch copy is always removed afterwards. The outcome is recorded in
+// the backup's verify_status, and a failed check opens an incident. The
+// workflow only returns an error when the check itself could not be run.
+func VerifyBackupWorkflow(ctx workflow.Context, backupID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var bctx activity.BackupContext
+	err := workflow.ExecuteActivity(ctx, "GetBackupContext", backupID).Get(ctx, &bctx)
+	if err != nil {
+		return err
+	}
+
+	if bctx.Backup.Status != model.StatusActive || bctx.Backup.StoragePath == "" {
+		return fmt.Errorf("backup %s is not active (status: %s)", backupID, bctx.Backup.Status)
+	}
+	if len(bctx.Nodes) == 0 {
+		return fmt.Errorf("no nodes found for tenant %s", bctx.Backup.TenantID)
+	}
+
+	// Backups are written on the first node, so verify there.
+	nodeID := bctx.Nodes[0].ID
+	verifyCtx := backupVerifyActivityCtx(ctx, nodeID)
+
+	var cleanup activity.CleanupBackupVerificationParams
+	var verifyErr error
+	switch bctx.Backup.Type {
+	case model.BackupTypeWeb:
+		cleanup.ScratchDir = fmt.Sprintf("/var/backups/hosting/.verify/%s", backupID)
+		verifyErr = workflow.ExecuteActivity(verifyCtx, "VerifyWebBackup", activity.VerifyWebBackupParams{
+			BackupPath: bctx.Backup.StoragePath,
+			ScratchDir: cleanup.ScratchDir,
+		}).Get(ctx, nil)
+	case model.BackupTypeDatabase:
+		cleanup.ScratchDatabase = "verify_" + strings.ReplaceAll(backupID, "-", "")
+		verifyErr = workflow.ExecuteActivity(verifyCtx, "VerifyMySQLBackup", activity.VerifyMySQLBackupParams{
+			BackupPath:      bctx.Backup.StoragePath,
+			ScratchDatabase: cleanup.ScratchDatabase,
+		}).Get(ctx, nil)
+	default:
+		return fmt.Errorf("unsupported backup type: %s", bctx.Backup.Type)
+	}
+
+	cleanupErr := workflow.ExecuteActivity(nodeActivityCtx(ctx, nodeID), "CleanupBackupVerification", cleanup).Get(ctx, nil)
+	if cleanupErr != nil {
+		workflow.GetLogger(ctx).Warn("backup verification cleanup failed", "backup", backupID, "error", cleanupErr)
+	}
+
+	result := activity.UpdateBackupVerificationParams{
+		ID:         backupID,
+		Status:     model.BackupVerifyPassed,
+		VerifiedAt: workflow.Now(ctx),
+	}
+	if verifyErr != nil {
+		result.Status = model.BackupVerifyFailed
+		result.Message = verifyErr.Error()
+	}
+	if err := workflow.ExecuteActivity(ctx, "UpdateBackupVerification", result).Get(ctx, nil); err != nil {
+		return err
+	}
+
+	if verifyErr != nil {
+		createIncident(ctx, activity.CreateIncidentParams{
+			DedupeKey:    fmt.Sprintf("backup_verify_failed:%s", backupID),
+			Type:         "backup_verify_failed",
+			Severity:     "warning",
+			Title:        fmt.Sprintf("Backup %s failed verification", backupID),
+			Detail:       fmt.Sprintf("%s backup of %s (tenant %s) could not be restored: %s", bctx.Backup.Type, bctx.Backup.SourceName, bctx.Backup.TenantID, verifyErr),
+			ResourceType: strPtr("backup"),
+			ResourceID:   &backupID,
+			Source:       "backup-verification",
+		})
+	}
+
+	return cleanupErr
+}
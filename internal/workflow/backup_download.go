@@ -0,0 +1,61 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// StageBackupDownloadArgs names a backup and the presigned URL its file is
+// uploaded to for download.
+type StageBackupDownloadArgs struct {
+	BackupID string
+	URL      string
+}
+
+// StageBackupDownloadWorkflow uploads a backup file from the node that holds
+// it to a presigned URL in the export bucket, from where the core API
+// streams it to the client. The caller is an API request waiting for the
+// result, so nothing is retried for long.
+func StageBackupDownloadWorkflow(ctx workflow.Context, args StageBackupDownloadArgs) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    2,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    5 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	})
+
+	var bctx activity.BackupContext
+	err := workflow.ExecuteActivity(ctx, "GetBackupContext", args.BackupID).Get(ctx, &bctx)
+	if err != nil {
+		return err
+	}
+	if bctx.Backup.Status != model.StatusActive || bctx.Backup.StoragePath == "" {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("backup %s is not active", args.BackupID), "BACKUP_NOT_ACTIVE", nil)
+	}
+	if len(bctx.Nodes) == 0 {
+		return fmt.Errorf("no nodes found for backup %s", args.BackupID)
+	}
+
+	// The backup file lives on the first node, as in CreateBackupWorkflow.
+	nodeCtx := nodeActivityCtx(ctx, bctx.Nodes[0].ID)
+	ao := workflow.GetActivityOptions(nodeCtx)
+	ao.StartToCloseTimeout = time.Hour
+	ao.ScheduleToCloseTimeout = time.Hour
+	ao.RetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 1}
+	nodeCtx = workflow.WithActivityOptions(nodeCtx, ao)
+
+	return workflow.ExecuteActivity(nodeCtx, "UploadBackupFile", activity.UploadBackupFileParams{
+		Path: bctx.Backup.StoragePath,
+		URL:  args.URL,
+	}).Get(ctx, nil)
+}
@@ -0,0 +1,70 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type StageBackupDownloadWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *StageBackupDownloadWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *StageBackupDownloadWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *StageBackupDownloadWorkflowTestSuite) TestSuccess() {
+	backup := model.Backup{
+		ID:          "test-backup-1",
+		TenantID:    "test-tenant-1",
+		Status:      model.StatusActive,
+		StoragePath: "/var/backups/hosting/test-tenant-1/test-backup-1.tar.gz",
+	}
+	s.env.OnActivity("GetBackupContext", mock.Anything, backup.ID).Return(&activity.BackupContext{
+		Backup: backup,
+		Nodes:  []model.Node{{ID: "node-1"}, {ID: "node-2"}},
+	}, nil)
+	s.env.OnActivity("UploadBackupFile", mock.Anything, activity.UploadBackupFileParams{
+		Path: backup.StoragePath,
+		URL:  "https://exports.example/put",
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(StageBackupDownloadWorkflow, StageBackupDownloadArgs{
+		BackupID: backup.ID,
+		URL:      "https://exports.example/put",
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *StageBackupDownloadWorkflowTestSuite) TestBackupNotActive() {
+	s.env.OnActivity("GetBackupContext", mock.Anything, "test-backup-1").Return(&activity.BackupContext{
+		Backup: model.Backup{ID: "test-backup-1", Status: model.StatusFailed},
+		Nodes:  []model.Node{{ID: "node-1"}},
+	}, nil)
+
+	s.env.ExecuteWorkflow(StageBackupDownloadWorkflow, StageBackupDownloadArgs{
+		BackupID: "test-backup-1",
+		URL:      "https://exports.example/put",
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.env.AssertNotCalled(s.T(), "UploadBackupFile", mock.Anything, mock.Anything)
+}
+
+func TestStageBackupDownloadWorkflow(t *testing.T) {
+	suite.Run(t, new(StageBackupDownloadWorkflowTestSuite))
+}
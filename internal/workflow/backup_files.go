@@ -0,0 +1,90 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// maxBackupFiles caps the entries ListBackupFilesWorkflow returns.
+const maxBackupFiles = 5000
+
+// ListBackupFilesWorkflow lists the entries of a web backup archive whose
+// path starts with prefix. The archive lives on the node that created it,
+// the shard's first node. The caller is an API request waiting for the
+// result, so the node activity is not retried.
+func ListBackupFilesWorkflow(ctx workflow.Context, backupID, prefix string) (*model.BackupFileList, error) {
+	bctx, nodeCtx, err := webBackupNodeCtx(ctx, backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var list model.BackupFileList
+	err = workflow.ExecuteActivity(nodeCtx, "ListBackupContents", activity.ListBackupContentsParams{
+		BackupPath: bctx.Backup.StoragePath,
+		Prefix:     prefix,
+		Limit:      maxBackupFiles,
+	}).Get(ctx, &list)
+	if err != nil {
+		return nil, fmt.Errorf("list backup %s: %w", backupID, err)
+	}
+	list.BackupID = backupID
+	return &list, nil
+}
+
+// RestoreBackupFilesWorkflow restores individual paths of a web backup into
+// its webroot, leaving files that are newer on disk alone unless force is
+// set. Web storage is shared, so restoring on one node is enough.
+func RestoreBackupFilesWorkflow(ctx workflow.Context, backupID string, paths []string, force bool) (*model.BackupFilesRestore, error) {
+	bctx, nodeCtx, err := webBackupNodeCtx(ctx, backupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var res model.BackupFilesRestore
+	err = workflow.ExecuteActivity(nodeCtx, "RestoreBackupFiles", activity.RestoreBackupFilesParams{
+		TenantName:  bctx.Tenant.ID,
+		WebrootName: bctx.Backup.SourceID,
+		BackupPath:  bctx.Backup.StoragePath,
+		Paths:       paths,
+		Force:       force,
+	}).Get(ctx, &res)
+	if err != nil {
+		return nil, fmt.Errorf("restore files from backup %s: %w", backupID, err)
+	}
+	res.BackupID = backupID
+	return &res, nil
+}
+
+// webBackupNodeCtx loads a web backup and returns an activity context for
+// the node holding its archive.
+func webBackupNodeCtx(ctx workflow.Context, backupID string) (*activity.BackupContext, workflow.Context, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 3},
+	})
+
+	var bctx activity.BackupContext
+	if err := workflow.ExecuteActivity(ctx, "GetBackupContext", backupID).Get(ctx, &bctx); err != nil {
+		return nil, nil, fmt.Errorf("get backup context: %w", err)
+	}
+	if bctx.Backup.Type != model.BackupTypeWeb {
+		return nil, nil, fmt.Errorf("backup %s is not a web backup", backupID)
+	}
+	if len(bctx.Nodes) == 0 {
+		return nil, nil, fmt.Errorf("backup %s has no nodes to read the archive on", backupID)
+	}
+
+	nodeCtx := nodeActivityCtx(ctx, bctx.Nodes[0].ID)
+	ao := workflow.GetActivityOptions(nodeCtx)
+	ao.ScheduleToStartTimeout = 10 * time.Second
+	ao.StartToCloseTimeout = 2 * time.Minute
+	ao.ScheduleToCloseTimeout = 0
+	ao.RetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 1}
+	return &bctx, workflow.WithActivityOptions(nodeCtx, ao), nil
+}
@@ -0,0 +1,91 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type BackupFilesWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *BackupFilesWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *BackupFilesWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *BackupFilesWorkflowTestSuite) backupContext(backupType string) *activity.BackupContext {
+	return &activity.BackupContext{
+		Backup: model.Backup{
+			ID:          "test-backup-1",
+			TenantID:    "test-tenant-1",
+			Type:        backupType,
+			SourceID:    "test-webroot-1",
+			StoragePath: "/var/backups/hosting/test-tenant-1/test-backup-1.tar.gz",
+		},
+		Tenant: model.Tenant{ID: "test-tenant-1"},
+		Nodes:  []model.Node{{ID: "node-1"}, {ID: "node-2"}},
+	}
+}
+
+func (s *BackupFilesWorkflowTestSuite) TestListsOnFirstNode() {
+	s.env.OnActivity("GetBackupContext", mock.Anything, "test-backup-1").Return(s.backupContext(model.BackupTypeWeb), nil)
+	s.env.OnActivity("ListBackupContents", mock.Anything, activity.ListBackupContentsParams{
+		BackupPath: "/var/backups/hosting/test-tenant-1/test-backup-1.tar.gz",
+		Prefix:     "public/",
+		Limit:      maxBackupFiles,
+	}).Return(&model.BackupFileList{Files: []model.BackupFile{{Path: "public/index.php", Type: model.BackupFileTypeFile}}}, nil).Once()
+
+	s.env.ExecuteWorkflow(ListBackupFilesWorkflow, "test-backup-1", "public/")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var got model.BackupFileList
+	s.NoError(s.env.GetWorkflowResult(&got))
+	s.Equal("test-backup-1", got.BackupID)
+	s.Len(got.Files, 1)
+}
+
+func (s *BackupFilesWorkflowTestSuite) TestRestoresIntoWebroot() {
+	s.env.OnActivity("GetBackupContext", mock.Anything, "test-backup-1").Return(s.backupContext(model.BackupTypeWeb), nil)
+	s.env.OnActivity("RestoreBackupFiles", mock.Anything, activity.RestoreBackupFilesParams{
+		TenantName:  "test-tenant-1",
+		WebrootName: "test-webroot-1",
+		BackupPath:  "/var/backups/hosting/test-tenant-1/test-backup-1.tar.gz",
+		Paths:       []string{"wp-config.php"},
+		Force:       true,
+	}).Return(&model.BackupFilesRestore{Restored: []string{"wp-config.php"}, Skipped: []string{}}, nil).Once()
+
+	s.env.ExecuteWorkflow(RestoreBackupFilesWorkflow, "test-backup-1", []string{"wp-config.php"}, true)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var got model.BackupFilesRestore
+	s.NoError(s.env.GetWorkflowResult(&got))
+	s.Equal("test-backup-1", got.BackupID)
+	s.Equal([]string{"wp-config.php"}, got.Restored)
+}
+
+func (s *BackupFilesWorkflowTestSuite) TestRejectsDatabaseBackup() {
+	s.env.OnActivity("GetBackupContext", mock.Anything, "test-backup-1").Return(s.backupContext(model.BackupTypeDatabase), nil)
+
+	s.env.ExecuteWorkflow(ListBackupFilesWorkflow, "test-backup-1", "")
+	s.True(s.env.IsWorkflowCompleted())
+	s.ErrorContains(s.env.GetWorkflowError(), "not a web backup")
+}
+
+func TestBackupFilesWorkflow(t *testing.T) {
+	suite.Run(t, new(BackupFilesWorkflowTestSuite))
+}
@@ -1,15 +1,19 @@
 package workflow
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/testsuite"
 
 	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/core"
 	"github.com/edvin/hosting/internal/model"
 )
 
@@ -273,11 +277,14 @@ func (s *RestoreBackupWorkflowTestSuite) TestSuccess_WebRestore() {
 	}).Return(nil)
 	s.env.OnActivity("GetWebrootByID", mock.Anything, "test-webroot-1").Return(&webroot, nil)
 	s.env.OnActivity("RestoreWebBackup", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("FixTenantPermissions", mock.Anything, activity.FixTenantPermissionsParams{
+		TenantName: tenantID,
+	}).Return(&model.TenantPermissionsFix{Scanned: 10, Changed: 3}, nil)
 	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
 		Table: "backups", ID: backupID, Status: model.StatusActive,
 	}).Return(nil)
 
-	s.env.ExecuteWorkflow(RestoreBackupWorkflow, backupID)
+	s.env.ExecuteWorkflow(RestoreBackupWorkflow, core.RestoreBackupParams{BackupID: backupID})
 	s.True(s.env.IsWorkflowCompleted())
 	s.NoError(s.env.GetWorkflowError())
 }
@@ -322,7 +329,7 @@ func (s *RestoreBackupWorkflowTestSuite) TestSuccess_DatabaseRestore() {
 		Table: "backups", ID: backupID, Status: model.StatusActive,
 	}).Return(nil)
 
-	s.env.ExecuteWorkflow(RestoreBackupWorkflow, backupID)
+	s.env.ExecuteWorkflow(RestoreBackupWorkflow, core.RestoreBackupParams{BackupID: backupID})
 	s.True(s.env.IsWorkflowCompleted())
 	s.NoError(s.env.GetWorkflowError())
 }
@@ -332,7 +339,7 @@ func (s *RestoreBackupWorkflowTestSuite) TestGetBackupFails() {
 
 	s.env.OnActivity("GetBackupContext", mock.Anything, backupID).Return(nil, fmt.Errorf("not found"))
 
-	s.env.ExecuteWorkflow(RestoreBackupWorkflow, backupID)
+	s.env.ExecuteWorkflow(RestoreBackupWorkflow, core.RestoreBackupParams{BackupID: backupID})
 	s.True(s.env.IsWorkflowCompleted())
 	s.Error(s.env.GetWorkflowError())
 }
@@ -374,11 +381,86 @@ func (s *RestoreBackupWorkflowTestSuite) TestRestoreFails_SetsStatusFailed() {
 	s.env.OnActivity("RestoreMySQLBackup", mock.Anything, mock.Anything).Return(fmt.Errorf("restore failed"))
 	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("backups", backupID)).Return(nil)
 
-	s.env.ExecuteWorkflow(RestoreBackupWorkflow, backupID)
+	s.env.ExecuteWorkflow(RestoreBackupWorkflow, core.RestoreBackupParams{BackupID: backupID})
 	s.True(s.env.IsWorkflowCompleted())
 	s.Error(s.env.GetWorkflowError())
 }
 
+func (s *RestoreBackupWorkflowTestSuite) pointInTimeContext(backupID string) {
+	shardID := "test-shard-1"
+	now := time.Now()
+	s.env.OnActivity("GetBackupContext", mock.Anything, backupID).Return(&activity.BackupContext{
+		Backup: model.Backup{
+			ID:          backupID,
+			TenantID:    "test-tenant-1",
+			Type:        model.BackupTypeDatabase,
+			SourceID:    "test-database-1",
+			SourceName:  "mydb",
+			StoragePath: "/var/backups/hosting/t_test123456/" + backupID + ".sql.gz",
+			Status:      model.StatusActive,
+			StartedAt:   &now,
+			CompletedAt: &now,
+		},
+		Tenant: model.Tenant{ID: "test-tenant-1", BrandID: "test-brand", ShardID: &shardID},
+		Nodes:  []model.Node{{ID: "node-1"}},
+	}, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "backups", ID: backupID, Status: model.StatusProvisioning,
+	}).Return(nil)
+}
+
+func (s *RestoreBackupWorkflowTestSuite) TestPointInTime_ReplaysBinlogs() {
+	backupID := "test-backup-5"
+	restoreTo := time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)
+	s.pointInTimeContext(backupID)
+	path := "/var/backups/hosting/t_test123456/test-backup-5.sql.gz"
+
+	var order []string
+	s.env.OnActivity("CheckMySQLBinlogs", mock.Anything, path).Return(func(_ context.Context, _ string) error {
+		order = append(order, "check")
+		return nil
+	})
+	s.env.OnActivity("RestoreMySQLBackup", mock.Anything, mock.Anything).Return(func(_ context.Context, _ activity.RestoreMySQLBackupParams) error {
+		order = append(order, "restore")
+		return nil
+	})
+	s.env.OnActivity("ReplayMySQLBinlogs", mock.Anything, activity.ReplayMySQLBinlogsParams{
+		DatabaseName: "mydb",
+		BackupPath:   path,
+		StopAt:       restoreTo,
+	}).Return(func(_ context.Context, _ activity.ReplayMySQLBinlogsParams) error {
+		order = append(order, "replay")
+		return nil
+	})
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "backups", ID: backupID, Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(RestoreBackupWorkflow, core.RestoreBackupParams{BackupID: backupID, RestoreTo: &restoreTo})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.Equal([]string{"check", "restore", "replay"}, order)
+}
+
+func (s *RestoreBackupWorkflowTestSuite) TestPointInTime_BinlogsNotRetained() {
+	backupID := "test-backup-6"
+	restoreTo := time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)
+	s.pointInTimeContext(backupID)
+
+	s.env.OnActivity("CheckMySQLBinlogs", mock.Anything, mock.Anything).Return(
+		temporal.NewNonRetryableApplicationError("binlogs are not retained far enough back", "FailedPrecondition", nil))
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, mock.MatchedBy(func(p activity.UpdateResourceStatusParams) bool {
+		return p.ID == backupID && p.Status == model.StatusActive && p.StatusMessage != nil &&
+			strings.Contains(*p.StatusMessage, "point-in-time restore to 2026-10-01T12:30:00Z refused") &&
+			strings.Contains(*p.StatusMessage, "not retained far enough back")
+	})).Return(nil)
+
+	s.env.ExecuteWorkflow(RestoreBackupWorkflow, core.RestoreBackupParams{BackupID: backupID, RestoreTo: &restoreTo})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.env.AssertNotCalled(s.T(), "RestoreMySQLBackup", mock.Anything, mock.Anything)
+}
+
 // ---------- DeleteBackupWorkflow ----------
 
 type DeleteBackupWorkflowTestSuite struct {
@@ -485,6 +567,112 @@ func (s *DeleteBackupWorkflowTestSuite) TestDeleteFileFails_SetsStatusFailed() {
 	s.Error(s.env.GetWorkflowError())
 }
 
+// ---------- VerifyBackupWorkflow ----------
+
+type VerifyBackupWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *VerifyBackupWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *VerifyBackupWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *VerifyBackupWorkflowTestSuite) backupContext(backupID, backupType, path string) *activity.BackupContext {
+	return &activity.BackupContext{
+		Backup: model.Backup{
+			ID:          backupID,
+			TenantID:    "test-tenant-1",
+			Type:        backupType,
+			SourceName:  "mysite",
+			StoragePath: path,
+			Status:      model.StatusActive,
+		},
+		Tenant: model.Tenant{ID: "test-tenant-1"},
+		Nodes:  []model.Node{{ID: "node-1"}},
+	}
+}
+
+func (s *VerifyBackupWorkflowTestSuite) TestWebBackupPasses() {
+	backupID := "6f1c2b9e-0d4a-4f51-9b0e-7a3c1d2e4f50"
+	path := "/var/backups/hosting/test-tenant-1/" + backupID + ".tar.gz"
+	scratch := "/var/backups/hosting/.verify/" + backupID
+
+	s.env.OnActivity("GetBackupContext", mock.Anything, backupID).Return(s.backupContext(backupID, model.BackupTypeWeb, path), nil)
+	s.env.OnActivity("VerifyWebBackup", mock.Anything, activity.VerifyWebBackupParams{
+		BackupPath: path, ScratchDir: scratch,
+	}).Return(nil)
+	s.env.OnActivity("CleanupBackupVerification", mock.Anything, activity.CleanupBackupVerificationParams{
+		ScratchDir: scratch,
+	}).Return(nil)
+	s.env.OnActivity("UpdateBackupVerification", mock.Anything, mock.MatchedBy(func(p activity.UpdateBackupVerificationParams) bool {
+		return p.ID == backupID && p.Status == model.BackupVerifyPassed && p.Message == "" && !p.VerifiedAt.IsZero()
+	})).Return(nil)
+
+	s.env.ExecuteWorkflow(VerifyBackupWorkflow, backupID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *VerifyBackupWorkflowTestSuite) TestDatabaseBackupFails_RecordsAndOpensIncident() {
+	backupID := "6f1c2b9e-0d4a-4f51-9b0e-7a3c1d2e4f51"
+	path := "/var/backups/hosting/test-tenant-1/" + backupID + ".sql.gz"
+	scratchDB := "verify_6f1c2b9e0d4a4f519b0e7a3c1d2e4f51"
+
+	s.env.OnActivity("GetBackupContext", mock.Anything, backupID).Return(s.backupContext(backupID, model.BackupTypeDatabase, path), nil)
+	s.env.OnActivity("VerifyMySQLBackup", mock.Anything, activity.VerifyMySQLBackupParams{
+		BackupPath: path, ScratchDatabase: scratchDB,
+	}).Return(temporal.NewNonRetryableApplicationError("mysqlcheck: error : Table is marked as crashed", "BACKUP_VERIFY_FAILED", nil))
+	s.env.OnActivity("CleanupBackupVerification", mock.Anything, activity.CleanupBackupVerificationParams{
+		ScratchDatabase: scratchDB,
+	}).Return(nil)
+	s.env.OnActivity("UpdateBackupVerification", mock.Anything, mock.MatchedBy(func(p activity.UpdateBackupVerificationParams) bool {
+		return p.ID == backupID && p.Status == model.BackupVerifyFailed && strings.Contains(p.Message, "marked as crashed")
+	})).Return(nil)
+	s.env.OnActivity("CreateIncident", mock.Anything, mock.MatchedBy(func(p activity.CreateIncidentParams) bool {
+		return p.Type == "backup_verify_failed" && p.DedupeKey == "backup_verify_failed:"+backupID
+	})).Return(&activity.CreateIncidentResult{ID: "inc-1"}, nil)
+
+	s.env.ExecuteWorkflow(VerifyBackupWorkflow, backupID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *VerifyBackupWorkflowTestSuite) TestCleanupFails_StillRecordsResult() {
+	backupID := "test-backup-verify-3"
+	path := "/var/backups/hosting/test-tenant-1/" + backupID + ".tar.gz"
+
+	s.env.OnActivity("GetBackupContext", mock.Anything, backupID).Return(s.backupContext(backupID, model.BackupTypeWeb, path), nil)
+	s.env.OnActivity("VerifyWebBackup", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("CleanupBackupVerification", mock.Anything, mock.Anything).Return(
+		temporal.NewNonRetryableApplicationError("permission denied", "TEST", nil))
+	s.env.OnActivity("UpdateBackupVerification", mock.Anything, mock.MatchedBy(func(p activity.UpdateBackupVerificationParams) bool {
+		return p.Status == model.BackupVerifyPassed
+	})).Return(nil)
+
+	s.env.ExecuteWorkflow(VerifyBackupWorkflow, backupID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func (s *VerifyBackupWorkflowTestSuite) TestBackupNotActive() {
+	backupID := "test-backup-verify-4"
+	bctx := s.backupContext(backupID, model.BackupTypeWeb, "")
+	bctx.Backup.Status = model.StatusFailed
+
+	s.env.OnActivity("GetBackupContext", mock.Anything, backupID).Return(bctx, nil)
+
+	s.env.ExecuteWorkflow(VerifyBackupWorkflow, backupID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
 // ---------- Run all suites ----------
 
 func TestCreateBackupWorkflow(t *testing.T) {
@@ -498,3 +686,7 @@ func TestRestoreBackupWorkflow(t *testing.T) {
 func TestDeleteBackupWorkflow(t *testing.T) {
 	suite.Run(t, new(DeleteBackupWorkflowTestSuite))
 }
+
+func TestVerifyBackupWorkflow(t *testing.T) {
+	suite.Run(t, new(VerifyBackupWorkflowTestSuite))
+}
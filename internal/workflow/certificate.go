@@ -12,8 +12,21 @@ import (
 	"github.com/edvin/hosting/internal/platform"
 )
 
+// dns01PropagationDelay gives PowerDNS time to serve a freshly written
+// _acme-challenge TXT record (packet cache) before the ACME server checks it.
+var dns01PropagationDelay = 30 * time.Second
+
+// SetDNS01PropagationDelay overrides the DNS-01 propagation wait. It must be
+// called before the worker starts.
+func SetDNS01PropagationDelay(d time.Duration) {
+	if d > 0 {
+		dns01PropagationDelay = d
+	}
+}
+
 // ProvisionLECertWorkflow provisions a Let's Encrypt certificate for an FQDN
-// using the ACME HTTP-01 challenge flow.
+// using the ACME HTTP-01 challenge flow. Wildcard FQDNs cannot be validated
+// over HTTP and use DNS-01 against the tenant's zone instead.
 func ProvisionLECertWorkflow(ctx workflow.Context, fqdnID string) error {
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 2 * time.Minute,
@@ -65,10 +78,11 @@ func ProvisionLECertWorkflow(ctx workflow.Context, fqdnID string) error {
 		return err
 	}
 
-	// Step 1: Create ACME order.
+	// Step 1: Create ACME order with the CA of the tenant's brand.
 	var orderResult activity.ACMEOrderResult
 	err = workflow.ExecuteActivity(ctx, "CreateOrder", activity.ACMEOrderParams{
-		FQDN: fctx.FQDN.FQDN,
+		FQDN:    fctx.FQDN.FQDN,
+		BrandID: fctx.Tenant.BrandID,
 	}).Get(ctx, &orderResult)
 	if err != nil {
 		_ = setResourceFailed(ctx, "certificates", certID, err)
@@ -78,12 +92,27 @@ func ProvisionLECertWorkflow(ctx workflow.Context, fqdnID string) error {
 	// Step 2: For each authorization, get the HTTP-01 challenge.
 	// Typically there is one authz per domain; we handle them all.
 	webrootPath := fmt.Sprintf("/var/www/storage/%s/%s/%s", fctx.Tenant.ID, fctx.Webroot.ID, fctx.Webroot.PublicFolder)
+	wildcard := model.IsWildcardFQDN(fctx.FQDN.FQDN)
+
+	// Wildcards: publish DNS-01 TXT records instead (steps 2-4).
+	var dns01Records []activity.DNS01ChallengeParams
+	if wildcard {
+		dns01Records, err = completeDNS01Challenges(ctx, orderResult)
+		if err != nil {
+			_ = setResourceFailed(ctx, "certificates", certID, err)
+			return err
+		}
+	}
 
 	for _, authzURL := range orderResult.AuthzURLs {
+		if wildcard {
+			break
+		}
 		var challengeResult activity.ACMEChallengeResult
 		err = workflow.ExecuteActivity(ctx, "GetHTTP01Challenge", activity.ACMEChallengeParams{
-			AuthzURL:   authzURL,
-			AccountKey: orderResult.AccountKey,
+			AuthzURL:     authzURL,
+			AccountKey:   orderResult.AccountKey,
+			DirectoryURL: orderResult.DirectoryURL,
 		}).Get(ctx, &challengeResult)
 		if err != nil {
 			_ = setResourceFailed(ctx, "certificates", certID, err)
@@ -109,6 +138,7 @@ func ProvisionLECertWorkflow(ctx workflow.Context, fqdnID string) error {
 		err = workflow.ExecuteActivity(ctx, "AcceptChallenge", activity.ACMEAcceptParams{
 			ChallengeURL: challengeResult.ChallengeURL,
 			AccountKey:   orderResult.AccountKey,
+			DirectoryURL: orderResult.DirectoryURL,
 		}).Get(ctx, nil)
 		if err != nil {
 			// Best-effort cleanup of challenge files (parallel).
@@ -128,9 +158,10 @@ func ProvisionLECertWorkflow(ctx workflow.Context, fqdnID string) error {
 	// Step 5: Finalize the order and get the certificate.
 	var finalizeResult activity.ACMEFinalizeResult
 	err = workflow.ExecuteActivity(ctx, "FinalizeOrder", activity.ACMEFinalizeParams{
-		OrderURL:   orderResult.OrderURL,
-		FQDN:       fctx.FQDN.FQDN,
-		AccountKey: orderResult.AccountKey,
+		OrderURL:     orderResult.OrderURL,
+		FQDN:         fctx.FQDN.FQDN,
+		AccountKey:   orderResult.AccountKey,
+		DirectoryURL: orderResult.DirectoryURL,
 	}).Get(ctx, &finalizeResult)
 	if err != nil {
 		_ = setResourceFailed(ctx, "certificates", certID, err)
@@ -138,13 +169,20 @@ func ProvisionLECertWorkflow(ctx workflow.Context, fqdnID string) error {
 	}
 
 	// Step 6: Cleanup challenge files on all nodes (best effort).
+	for _, rec := range dns01Records {
+		_ = workflow.ExecuteActivity(ctx, "CleanupDNS01Challenge", rec).Get(ctx, nil)
+	}
 	for _, authzURL := range orderResult.AuthzURLs {
+		if wildcard {
+			break
+		}
 		// Re-derive the token from the challenge. Since we only need the token
 		// for cleanup and the authzURL loop is identical, we re-fetch.
 		var cleanupChallenge activity.ACMEChallengeResult
 		_ = workflow.ExecuteActivity(ctx, "GetHTTP01Challenge", activity.ACMEChallengeParams{
-			AuthzURL:   authzURL,
-			AccountKey: orderResult.AccountKey,
+			AuthzURL:     authzURL,
+			AccountKey:   orderResult.AccountKey,
+			DirectoryURL: orderResult.DirectoryURL,
 		}).Get(ctx, &cleanupChallenge)
 
 		_ = fanOutNodes(ctx, fctx.Nodes, func(gCtx workflow.Context, node model.Node) error {
@@ -204,6 +242,59 @@ func ProvisionLECertWorkflow(ctx workflow.Context, fqdnID string) error {
 	return nil
 }
 
+// completeDNS01Challenges publishes the _acme-challenge TXT record for each
+// authorization of the order and tells the ACME server to validate it. It
+// returns the records written so the caller can remove them after
+// finalization; on failure they are removed before returning.
+func completeDNS01Challenges(ctx workflow.Context, order activity.ACMEOrderResult) ([]activity.DNS01ChallengeParams, error) {
+	var records []activity.DNS01ChallengeParams
+	var challengeURLs []string
+	cleanup := func() {
+		for _, rec := range records {
+			_ = workflow.ExecuteActivity(ctx, "CleanupDNS01Challenge", rec).Get(ctx, nil)
+		}
+	}
+
+	for _, authzURL := range order.AuthzURLs {
+		var challenge activity.ACMEDNS01ChallengeResult
+		err := workflow.ExecuteActivity(ctx, "GetDNS01Challenge", activity.ACMEChallengeParams{
+			AuthzURL:     authzURL,
+			AccountKey:   order.AccountKey,
+			DirectoryURL: order.DirectoryURL,
+		}).Get(ctx, &challenge)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		rec := activity.DNS01ChallengeParams{Domain: challenge.Domain, Value: challenge.RecordValue}
+		if err := workflow.ExecuteActivity(ctx, "PlaceDNS01Challenge", rec).Get(ctx, nil); err != nil {
+			cleanup()
+			return nil, err
+		}
+		records = append(records, rec)
+		challengeURLs = append(challengeURLs, challenge.ChallengeURL)
+	}
+
+	if err := workflow.Sleep(ctx, dns01PropagationDelay); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	for _, challengeURL := range challengeURLs {
+		err := workflow.ExecuteActivity(ctx, "AcceptChallenge", activity.ACMEAcceptParams{
+			ChallengeURL: challengeURL,
+			AccountKey:   order.AccountKey,
+			DirectoryURL: order.DirectoryURL,
+		}).Get(ctx, nil)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
 // UploadCustomCertWorkflow validates, stores, and installs a custom certificate.
 func UploadCustomCertWorkflow(ctx workflow.Context, certID string) error {
 	ao := workflow.ActivityOptions{
@@ -289,9 +380,20 @@ func UploadCustomCertWorkflow(ctx workflow.Context, certID string) error {
 	return nil
 }
 
-// RenewLECertWorkflow is a cron workflow that renews Let's Encrypt certificates
-// expiring within 30 days. For each expiring cert it starts a child
-// ProvisionLECertWorkflow to issue a fresh certificate via ACME.
+// Certificate renewal schedule. Certificates enter renewal leRenewalDays
+// before expiry. Those of tenants with a maintenance window are renewed inside
+// the window; the rest, and any within leUrgentRenewalDays of expiry, during
+// leDefaultRenewalHourUTC.
+const (
+	leRenewalDays           = 30
+	leUrgentRenewalDays     = 7
+	leDefaultRenewalHourUTC = 2
+)
+
+// RenewLECertWorkflow is an hourly cron workflow that renews Let's Encrypt
+// certificates expiring within 30 days, deferring each to its tenant's
+// maintenance window unless it is about to expire. For each certificate due
+// it starts a child ProvisionLECertWorkflow to issue a fresh one via ACME.
 func RenewLECertWorkflow(ctx workflow.Context) error {
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 30 * time.Second,
@@ -305,13 +407,18 @@ func RenewLECertWorkflow(ctx workflow.Context) error {
 	ctx = workflow.WithActivityOptions(ctx, ao)
 
 	var certsToRenew []model.Certificate
-	err := workflow.ExecuteActivity(ctx, "GetExpiringLECerts", 30).Get(ctx, &certsToRenew)
+	err := workflow.ExecuteActivity(ctx, "GetLECertsDueForRenewal", activity.GetLECertsDueForRenewalParams{
+		DaysBeforeExpiry: leRenewalDays,
+		UrgentDays:       leUrgentRenewalDays,
+		DefaultHourUTC:   leDefaultRenewalHourUTC,
+		Now:              workflow.Now(ctx),
+	}).Get(ctx, &certsToRenew)
 	if err != nil {
 		return err
 	}
 
 	logger := workflow.GetLogger(ctx)
-	logger.Info("found expiring LE certificates", "count", len(certsToRenew))
+	logger.Info("found LE certificates due for renewal", "count", len(certsToRenew))
 
 	var children []ChildWorkflowSpec
 	for _, cert := range certsToRenew {
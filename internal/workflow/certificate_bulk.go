@@ -0,0 +1,228 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// BulkCertProgressQuery is the workflow query name that returns the current
+// model.BulkCertProgress of a BulkProvisionCertsWorkflow.
+const BulkCertProgressQuery = "bulk-cert-progress"
+
+const (
+	// bulkCertDomainWindow is the window of the per-registered-domain limit.
+	bulkCertDomainWindow = 7 * 24 * time.Hour
+	// bulkCertMaxAttempts is how often an FQDN is tried before it is failed,
+	// counting rate-limited attempts only.
+	bulkCertMaxAttempts = 5
+	// bulkCertBackoff is the wait after the first rate-limited attempt,
+	// doubled on every further one up to bulkCertMaxBackoff. A longer
+	// Retry-After from the CA takes precedence.
+	bulkCertBackoff    = time.Hour
+	bulkCertMaxBackoff = 24 * time.Hour
+)
+
+// bulkCertScheduler decides which FQDN of a BulkProvisionCertsWorkflow is
+// issued next and when.
+type bulkCertScheduler struct {
+	params   core.BulkProvisionCertsParams
+	progress model.BulkCertProgress
+	// issued holds the issuance times of new certificates per registered
+	// domain. Renewals are exempt from the CA's per-domain limit and are not
+	// counted.
+	issued map[string][]time.Time
+}
+
+func newBulkCertScheduler(params core.BulkProvisionCertsParams, fqdns []activity.BulkCertFQDN) *bulkCertScheduler {
+	// FQDNs without a certificate first, then early renewals, otherwise in
+	// the order given.
+	sort.SliceStable(fqdns, func(i, j int) bool {
+		return !fqdns[i].HasCert && fqdns[j].HasCert
+	})
+
+	s := &bulkCertScheduler{
+		params:   params,
+		progress: model.BulkCertProgress{Total: len(fqdns), Remaining: len(fqdns), Items: []model.BulkCertItem{}},
+		issued:   map[string][]time.Time{},
+	}
+	for _, f := range fqdns {
+		s.progress.Items = append(s.progress.Items, model.BulkCertItem{
+			FQDNID:           f.FQDNID,
+			FQDN:             f.FQDN,
+			RegisteredDomain: f.RegisteredDomain,
+			Renewal:          f.HasCert,
+			Status:           model.BulkCertQueued,
+		})
+	}
+	return s
+}
+
+// domainFreeAt returns when the registered domain can take another new
+// certificate.
+func (s *bulkCertScheduler) domainFreeAt(domain string, now time.Time) time.Time {
+	var recent []time.Time
+	for _, t := range s.issued[domain] {
+		if now.Sub(t) < bulkCertDomainWindow {
+			recent = append(recent, t)
+		}
+	}
+	s.issued[domain] = recent
+	if len(recent) < s.params.PerDomainPerWeek {
+		return now
+	}
+	return recent[len(recent)-s.params.PerDomainPerWeek].Add(bulkCertDomainWindow)
+}
+
+// next returns the index of the first item that can be issued at now. If
+// none can, it returns -1 and when the earliest one can; if no items are
+// left, -1 and the zero time.
+func (s *bulkCertScheduler) next(now time.Time) (int, time.Time) {
+	var earliest time.Time
+	for i := range s.progress.Items {
+		item := &s.progress.Items[i]
+		if item.Status != model.BulkCertQueued && item.Status != model.BulkCertRateLimited {
+			continue
+		}
+		readyAt := now
+		if item.NextAttemptAt != nil && item.NextAttemptAt.After(readyAt) {
+			readyAt = *item.NextAttemptAt
+		}
+		if !item.Renewal {
+			if freeAt := s.domainFreeAt(item.RegisteredDomain, now); freeAt.After(readyAt) {
+				readyAt = freeAt
+				if item.Status == model.BulkCertQueued {
+					item.NextAttemptAt = &freeAt
+				}
+			}
+		}
+		if !readyAt.After(now) {
+			return i, time.Time{}
+		}
+		if earliest.IsZero() || readyAt.Before(earliest) {
+			earliest = readyAt
+		}
+	}
+	return -1, earliest
+}
+
+// record updates an item with the result of an issuance attempt started at
+// startedAt and finished at now.
+func (s *bulkCertScheduler) record(i int, startedAt, now time.Time, err error) {
+	item := &s.progress.Items[i]
+	item.NextAttemptAt = nil
+	if err == nil {
+		item.Status = model.BulkCertIssued
+		item.Error = ""
+		s.progress.Issued++
+		s.progress.Remaining--
+		if !item.Renewal {
+			s.issued[item.RegisteredDomain] = append(s.issued[item.RegisteredDomain], startedAt)
+		}
+		return
+	}
+
+	item.Error = err.Error()
+	var appErr *temporal.ApplicationError
+	if errors.As(err, &appErr) && appErr.Type() == activity.ErrTypeACMERateLimited && item.Attempts < bulkCertMaxAttempts {
+		var retryAfter time.Duration
+		_ = appErr.Details(&retryAfter)
+		backoff := bulkCertBackoff << (item.Attempts - 1)
+		if backoff > bulkCertMaxBackoff {
+			backoff = bulkCertMaxBackoff
+		}
+		if retryAfter > backoff {
+			backoff = retryAfter
+		}
+		retryAt := now.Add(backoff)
+		item.Status = model.BulkCertRateLimited
+		item.NextAttemptAt = &retryAt
+		return
+	}
+
+	item.Status = model.BulkCertFailed
+	s.progress.Failed++
+	s.progress.Remaining--
+}
+
+// BulkProvisionCertsWorkflow issues Let's Encrypt certificates for a set of
+// FQDNs, one at a time through child ProvisionLECertWorkflows. FQDNs without
+// a certificate go first, then early renewals. Issuances are spaced to at
+// most PerHour, and new certificates per registered domain are held to
+// PerDomainPerWeek in any 7 days. An attempt the CA rejects for rate limits
+// is retried after a backoff of 1h, doubling up to 24h (or the CA's
+// Retry-After if longer), up to 5 attempts; other failures fail the FQDN
+// without stopping the rest. Progress is reported via BulkCertProgressQuery.
+func BulkProvisionCertsWorkflow(ctx workflow.Context, params core.BulkProvisionCertsParams) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var fqdns []activity.BulkCertFQDN
+	err := workflow.ExecuteActivity(ctx, "GetBulkCertFQDNs", params.FQDNIDs).Get(ctx, &fqdns)
+	if err != nil {
+		return err
+	}
+
+	s := newBulkCertScheduler(params, fqdns)
+	if err := workflow.SetQueryHandler(ctx, BulkCertProgressQuery, func() (model.BulkCertProgress, error) {
+		return s.progress, nil
+	}); err != nil {
+		return fmt.Errorf("set query handler: %w", err)
+	}
+
+	logger := workflow.GetLogger(ctx)
+	interval := time.Hour / time.Duration(params.PerHour)
+	wfID := workflow.GetInfo(ctx).WorkflowExecution.ID
+	var lastStart time.Time
+	for {
+		now := workflow.Now(ctx)
+		i, readyAt := s.next(now)
+		if i < 0 && readyAt.IsZero() {
+			break
+		}
+		if i >= 0 && !lastStart.IsZero() && now.Before(lastStart.Add(interval)) {
+			readyAt = lastStart.Add(interval)
+		}
+		if !readyAt.IsZero() {
+			if err := workflow.Sleep(ctx, readyAt.Sub(now)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		item := &s.progress.Items[i]
+		item.Status = model.BulkCertIssuing
+		item.Attempts++
+		lastStart = now
+		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+			WorkflowID: fmt.Sprintf("%s-%s-%d", wfID, item.FQDNID, item.Attempts),
+			TaskQueue:  "hosting-tasks",
+		})
+		err := workflow.ExecuteChildWorkflow(childCtx, "ProvisionLECertWorkflow", item.FQDNID).Get(ctx, nil)
+		if err != nil {
+			logger.Warn("bulk certificate issuance failed", "fqdn", item.FQDN, "attempt", item.Attempts, "error", err)
+		}
+		s.record(i, now, workflow.Now(ctx), err)
+	}
+
+	if s.progress.Failed > 0 {
+		return fmt.Errorf("%d of %d certificates failed", s.progress.Failed, s.progress.Total)
+	}
+	return nil
+}
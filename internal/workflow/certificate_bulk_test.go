@@ -0,0 +1,155 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ---------- BulkProvisionCertsWorkflow ----------
+
+type BulkProvisionCertsWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *BulkProvisionCertsWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+	s.env.RegisterWorkflow(ProvisionLECertWorkflow)
+}
+
+func (s *BulkProvisionCertsWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *BulkProvisionCertsWorkflowTestSuite) expectFQDNs(fqdns ...activity.BulkCertFQDN) core.BulkProvisionCertsParams {
+	params := core.BulkProvisionCertsParams{TenantID: "tenant-1", PerHour: 2, PerDomainPerWeek: 50}
+	for _, f := range fqdns {
+		params.FQDNIDs = append(params.FQDNIDs, f.FQDNID)
+	}
+	s.env.OnActivity("GetBulkCertFQDNs", mock.Anything, params.FQDNIDs).Return(fqdns, nil)
+	return params
+}
+
+func (s *BulkProvisionCertsWorkflowTestSuite) progress() model.BulkCertProgress {
+	val, err := s.env.QueryWorkflow(BulkCertProgressQuery)
+	s.Require().NoError(err)
+	var p model.BulkCertProgress
+	s.Require().NoError(val.Get(&p))
+	return p
+}
+
+func (s *BulkProvisionCertsWorkflowTestSuite) TestNewCertsFirstAndSpaced() {
+	params := s.expectFQDNs(
+		activity.BulkCertFQDN{FQDNID: "fqdn-1", FQDN: "a.example.com", RegisteredDomain: "example.com", HasCert: true},
+		activity.BulkCertFQDN{FQDNID: "fqdn-2", FQDN: "b.example.com", RegisteredDomain: "example.com"},
+		activity.BulkCertFQDN{FQDNID: "fqdn-3", FQDN: "c.example.org", RegisteredDomain: "example.org"},
+	)
+	var order []string
+	var starts []time.Time
+	s.env.OnWorkflow(ProvisionLECertWorkflow, mock.Anything, mock.Anything).Return(func(ctx workflow.Context, fqdnID string) error {
+		order = append(order, fqdnID)
+		starts = append(starts, s.env.Now())
+		return nil
+	})
+
+	s.env.ExecuteWorkflow(BulkProvisionCertsWorkflow, params)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	s.Equal([]string{"fqdn-2", "fqdn-3", "fqdn-1"}, order)
+	s.GreaterOrEqual(starts[1].Sub(starts[0]), 30*time.Minute)
+	s.GreaterOrEqual(starts[2].Sub(starts[1]), 30*time.Minute)
+
+	p := s.progress()
+	s.Equal(3, p.Total)
+	s.Equal(3, p.Issued)
+	s.Equal(0, p.Remaining)
+	s.True(p.Items[2].Renewal)
+	for _, item := range p.Items {
+		s.Equal(model.BulkCertIssued, item.Status)
+	}
+}
+
+func (s *BulkProvisionCertsWorkflowTestSuite) TestRateLimitedIsRetried() {
+	params := s.expectFQDNs(activity.BulkCertFQDN{FQDNID: "fqdn-1", FQDN: "a.example.com", RegisteredDomain: "example.com"})
+	var starts []time.Time
+	rateLimited := temporal.NewNonRetryableApplicationError("authorize order: 429 rateLimited", activity.ErrTypeACMERateLimited, nil, 3*time.Hour)
+	s.env.OnWorkflow(ProvisionLECertWorkflow, mock.Anything, "fqdn-1").Return(func(ctx workflow.Context, fqdnID string) error {
+		starts = append(starts, s.env.Now())
+		if len(starts) == 1 {
+			return rateLimited
+		}
+		return nil
+	})
+
+	s.env.ExecuteWorkflow(BulkProvisionCertsWorkflow, params)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	// The CA's Retry-After is longer than the first backoff.
+	s.Require().Len(starts, 2)
+	s.GreaterOrEqual(starts[1].Sub(starts[0]), 3*time.Hour)
+	p := s.progress()
+	s.Equal(model.BulkCertIssued, p.Items[0].Status)
+	s.Equal(2, p.Items[0].Attempts)
+}
+
+func (s *BulkProvisionCertsWorkflowTestSuite) TestPerDomainLimit() {
+	params := s.expectFQDNs(
+		activity.BulkCertFQDN{FQDNID: "fqdn-1", FQDN: "a.example.com", RegisteredDomain: "example.com"},
+		activity.BulkCertFQDN{FQDNID: "fqdn-2", FQDN: "b.example.com", RegisteredDomain: "example.com"},
+		activity.BulkCertFQDN{FQDNID: "fqdn-3", FQDN: "c.example.org", RegisteredDomain: "example.org"},
+	)
+	params.PerDomainPerWeek = 1
+	starts := map[string]time.Time{}
+	s.env.OnWorkflow(ProvisionLECertWorkflow, mock.Anything, mock.Anything).Return(func(ctx workflow.Context, fqdnID string) error {
+		starts[fqdnID] = s.env.Now()
+		return nil
+	})
+
+	s.env.ExecuteWorkflow(BulkProvisionCertsWorkflow, params)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	// The other domain is not held up by example.com's limit.
+	s.Less(starts["fqdn-3"].Sub(starts["fqdn-1"]), time.Hour)
+	s.GreaterOrEqual(starts["fqdn-2"].Sub(starts["fqdn-1"]), 7*24*time.Hour)
+}
+
+func (s *BulkProvisionCertsWorkflowTestSuite) TestFailureDoesNotStopOthers() {
+	params := s.expectFQDNs(
+		activity.BulkCertFQDN{FQDNID: "fqdn-1", FQDN: "a.example.com", RegisteredDomain: "example.com"},
+		activity.BulkCertFQDN{FQDNID: "fqdn-2", FQDN: "b.example.com", RegisteredDomain: "example.com"},
+	)
+	s.env.OnWorkflow(ProvisionLECertWorkflow, mock.Anything, "fqdn-1").Return(errors.New("challenge failed")).Once()
+	s.env.OnWorkflow(ProvisionLECertWorkflow, mock.Anything, "fqdn-2").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(BulkProvisionCertsWorkflow, params)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "1 of 2 certificates failed")
+
+	p := s.progress()
+	s.Equal(1, p.Issued)
+	s.Equal(1, p.Failed)
+	s.Equal(model.BulkCertFailed, p.Items[0].Status)
+	s.Contains(p.Items[0].Error, "challenge failed")
+	s.Equal(model.BulkCertIssued, p.Items[1].Status)
+}
+
+func TestBulkProvisionCertsWorkflow(t *testing.T) {
+	suite.Run(t, new(BulkProvisionCertsWorkflowTestSuite))
+}
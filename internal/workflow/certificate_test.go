@@ -65,7 +65,7 @@ func (s *ProvisionLECertWorkflowTestSuite) setupACMESuccessMocks(
 	}, nil)
 	s.env.OnActivity("CreateCertificate", mock.Anything, mock.Anything).Return(nil)
 	s.env.OnActivity("UpdateResourceStatus", mock.Anything, mock.Anything).Return(nil)
-	s.env.OnActivity("CreateOrder", mock.Anything, activity.ACMEOrderParams{FQDN: fqdn.FQDN}).Return(orderResult, nil)
+	s.env.OnActivity("CreateOrder", mock.Anything, activity.ACMEOrderParams{FQDN: fqdn.FQDN, BrandID: tenant.BrandID}).Return(orderResult, nil)
 	s.env.OnActivity("GetHTTP01Challenge", mock.Anything, mock.Anything).Return(challengeResult, nil)
 	s.env.OnActivity("PlaceHTTP01Challenge", mock.Anything, mock.Anything).Return(nil)
 	s.env.OnActivity("AcceptChallenge", mock.Anything, mock.Anything).Return(nil)
@@ -99,6 +99,111 @@ func (s *ProvisionLECertWorkflowTestSuite) TestSuccess() {
 	s.NoError(s.env.GetWorkflowError())
 }
 
+func (s *ProvisionLECertWorkflowTestSuite) TestWildcard_UsesDNS01() {
+	fqdnID := "test-fqdn-wild"
+	webrootID := "test-webroot-wild"
+	tenantID := "test-tenant-wild"
+	shardID := "test-shard-wild"
+	fqdn := model.FQDN{
+		ID:         fqdnID,
+		FQDN:       "*.example.com",
+		WebrootID:  &webrootID,
+		SSLEnabled: true,
+	}
+	webroot := model.Webroot{ID: webrootID, TenantID: tenantID, PublicFolder: "public"}
+	tenant := model.Tenant{ID: tenantID, BrandID: "test-brand", ShardID: &shardID}
+	nodes := []model.Node{{ID: "node-1"}}
+	shard := model.Shard{ID: shardID}
+	now := time.Now()
+
+	record := activity.DNS01ChallengeParams{Domain: "example.com", Value: "dns01-digest"}
+	s.env.OnActivity("GetFQDNContext", mock.Anything, fqdnID).Return(&activity.FQDNContext{
+		FQDN:    fqdn,
+		Webroot: webroot,
+		Tenant:  tenant,
+		Shard:   shard,
+		Nodes:   nodes,
+	}, nil)
+	s.env.OnActivity("CreateCertificate", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("CreateOrder", mock.Anything, activity.ACMEOrderParams{FQDN: fqdn.FQDN, BrandID: tenant.BrandID}).Return(&activity.ACMEOrderResult{
+		OrderURL:     "https://acme.example.com/order/123",
+		AuthzURLs:    []string{"https://acme.example.com/authz/456"},
+		AccountKey:   []byte("FAKE_ACCOUNT_KEY_PEM"),
+		DirectoryURL: "https://acme.example.com/directory",
+	}, nil)
+	s.env.OnActivity("GetDNS01Challenge", mock.Anything, mock.Anything).Return(&activity.ACMEDNS01ChallengeResult{
+		ChallengeURL: "https://acme.example.com/challenge/789",
+		Domain:       record.Domain,
+		RecordValue:  record.Value,
+	}, nil)
+	s.env.OnActivity("PlaceDNS01Challenge", mock.Anything, record).Return(nil)
+	s.env.OnActivity("AcceptChallenge", mock.Anything, activity.ACMEAcceptParams{
+		ChallengeURL: "https://acme.example.com/challenge/789",
+		AccountKey:   []byte("FAKE_ACCOUNT_KEY_PEM"),
+		DirectoryURL: "https://acme.example.com/directory",
+	}).Return(nil)
+	s.env.OnActivity("FinalizeOrder", mock.Anything, mock.Anything).Return(&activity.ACMEFinalizeResult{
+		CertPEM:   "REAL_CERT_PEM",
+		KeyPEM:    "REAL_KEY_PEM",
+		ChainPEM:  "REAL_CHAIN_PEM",
+		IssuedAt:  now,
+		ExpiresAt: now.Add(90 * 24 * time.Hour),
+	}, nil)
+	s.env.OnActivity("CleanupDNS01Challenge", mock.Anything, record).Return(nil).Once()
+	s.env.OnActivity("StoreCertificate", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("InstallCertificate", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("DeactivateOtherCerts", mock.Anything, fqdnID, mock.Anything).Return(nil)
+	s.env.OnActivity("ActivateCertificate", mock.Anything, mock.Anything).Return(nil)
+
+	s.env.ExecuteWorkflow(ProvisionLECertWorkflow, fqdnID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ProvisionLECertWorkflowTestSuite) TestWildcard_AcceptFails_CleansUpTXT() {
+	fqdnID := "test-fqdn-wild-2"
+	webrootID := "test-webroot-wild-2"
+	tenantID := "test-tenant-wild-2"
+	shardID := "test-shard-wild-2"
+	fqdn := model.FQDN{
+		ID:         fqdnID,
+		FQDN:       "*.example.com",
+		WebrootID:  &webrootID,
+		SSLEnabled: true,
+	}
+	webroot := model.Webroot{ID: webrootID, TenantID: tenantID, PublicFolder: "public"}
+	tenant := model.Tenant{ID: tenantID, BrandID: "test-brand", ShardID: &shardID}
+
+	s.env.OnActivity("GetFQDNContext", mock.Anything, fqdnID).Return(&activity.FQDNContext{
+		FQDN:    fqdn,
+		Webroot: webroot,
+		Tenant:  tenant,
+		Shard:   model.Shard{ID: shardID},
+		Nodes:   []model.Node{{ID: "node-1"}},
+	}, nil)
+	s.env.OnActivity("CreateCertificate", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("CreateOrder", mock.Anything, mock.Anything).Return(&activity.ACMEOrderResult{
+		OrderURL:     "https://acme.example.com/order/123",
+		AuthzURLs:    []string{"https://acme.example.com/authz/456"},
+		AccountKey:   []byte("FAKE_ACCOUNT_KEY_PEM"),
+		DirectoryURL: "https://acme.example.com/directory",
+	}, nil)
+	s.env.OnActivity("GetDNS01Challenge", mock.Anything, mock.Anything).Return(&activity.ACMEDNS01ChallengeResult{
+		ChallengeURL: "https://acme.example.com/challenge/789",
+		Domain:       "example.com",
+		RecordValue:  "dns01-digest",
+	}, nil)
+	s.env.OnActivity("PlaceDNS01Challenge", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("AcceptChallenge", mock.Anything, mock.Anything).Return(fmt.Errorf("invalid challenge"))
+	s.env.OnActivity("CleanupDNS01Challenge", mock.Anything, mock.Anything).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(ProvisionLECertWorkflow, fqdnID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
 func (s *ProvisionLECertWorkflowTestSuite) TestGetFQDNContextFails() {
 	fqdnID := "test-fqdn-2"
 
@@ -646,7 +751,7 @@ func (s *RenewLECertWorkflowTestSuite) AfterTest(suiteName, testName string) {
 }
 
 func (s *RenewLECertWorkflowTestSuite) TestSuccess_NoCerts() {
-	s.env.OnActivity("GetExpiringLECerts", mock.Anything, 30).Return([]model.Certificate{}, nil)
+	s.env.OnActivity("GetLECertsDueForRenewal", mock.Anything, mock.Anything).Return([]model.Certificate{}, nil)
 
 	s.env.ExecuteWorkflow(RenewLECertWorkflow)
 	s.True(s.env.IsWorkflowCompleted())
@@ -660,7 +765,7 @@ func (s *RenewLECertWorkflowTestSuite) TestSuccess_RenewsExpiring() {
 		{ID: "cert-2", FQDNID: "fqdn-2", ExpiresAt: timePtr(now.Add(10 * 24 * time.Hour))},
 	}
 
-	s.env.OnActivity("GetExpiringLECerts", mock.Anything, 30).Return(expiring, nil)
+	s.env.OnActivity("GetLECertsDueForRenewal", mock.Anything, mock.Anything).Return(expiring, nil)
 
 	// Expect child workflows for each cert
 	s.env.OnWorkflow(ProvisionLECertWorkflow, mock.Anything, "fqdn-1").Return(nil)
@@ -678,7 +783,7 @@ func (s *RenewLECertWorkflowTestSuite) TestChildWorkflowFailure_ContinuesOthers(
 		{ID: "cert-2", FQDNID: "fqdn-2", ExpiresAt: timePtr(now.Add(10 * 24 * time.Hour))},
 	}
 
-	s.env.OnActivity("GetExpiringLECerts", mock.Anything, 30).Return(expiring, nil)
+	s.env.OnActivity("GetLECertsDueForRenewal", mock.Anything, mock.Anything).Return(expiring, nil)
 
 	// First child fails, second succeeds
 	s.env.OnWorkflow(ProvisionLECertWorkflow, mock.Anything, "fqdn-1").Return(fmt.Errorf("ACME error"))
@@ -690,7 +795,7 @@ func (s *RenewLECertWorkflowTestSuite) TestChildWorkflowFailure_ContinuesOthers(
 }
 
 func (s *RenewLECertWorkflowTestSuite) TestGetExpiringFails() {
-	s.env.OnActivity("GetExpiringLECerts", mock.Anything, 30).Return(nil, fmt.Errorf("db error"))
+	s.env.OnActivity("GetLECertsDueForRenewal", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("db error"))
 
 	s.env.ExecuteWorkflow(RenewLECertWorkflow)
 	s.True(s.env.IsWorkflowCompleted())
@@ -0,0 +1,109 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// defaultConvergeConcurrency is used when the caller does not set a limit.
+const defaultConvergeConcurrency = 2
+
+// ConvergeClusterParams holds parameters for the ConvergeClusterWorkflow.
+type ConvergeClusterParams struct {
+	BatchID       string `json:"batch_id"`
+	ClusterID     string `json:"cluster_id"`
+	MaxConcurrent int    `json:"max_concurrent"`
+}
+
+// ConvergeClusterWorkflow runs ConvergeShardWorkflow for every shard in a
+// cluster as child workflows, with at most MaxConcurrent running at once so
+// nodes are not overloaded. Per-shard results are aggregated into a
+// model.ConvergeBatch that callers poll via the model.ConvergeBatchQuery query.
+//
+// A failing shard does not stop the batch; the workflow returns an error
+// after all shards have run if any of them failed.
+func ConvergeClusterWorkflow(ctx workflow.Context, params ConvergeClusterParams) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	maxConcurrent := params.MaxConcurrent
+	if maxConcurrent <= 0 {
+		maxConcurrent = defaultConvergeConcurrency
+	}
+
+	batch := model.ConvergeBatch{
+		ID:            params.BatchID,
+		ClusterID:     params.ClusterID,
+		Status:        model.ConvergeBatchRunning,
+		MaxConcurrent: maxConcurrent,
+	}
+	if err := workflow.SetQueryHandler(ctx, model.ConvergeBatchQuery, func() (model.ConvergeBatch, error) {
+		return batch, nil
+	}); err != nil {
+		return fmt.Errorf("set query handler: %w", err)
+	}
+
+	var shards []model.Shard
+	if err := workflow.ExecuteActivity(ctx, "ListShardsByCluster", params.ClusterID).Get(ctx, &shards); err != nil {
+		batch.Status = model.ConvergeBatchFailed
+		return fmt.Errorf("list shards: %w", err)
+	}
+
+	batch.Total = len(shards)
+	batch.Shards = make([]model.ConvergeBatchShard, len(shards))
+	for i, shard := range shards {
+		batch.Shards[i] = model.ConvergeBatchShard{
+			ShardID:   shard.ID,
+			ShardName: shard.Name,
+			Role:      shard.Role,
+			Status:    model.ConvergeShardPending,
+		}
+	}
+
+	// Buffered channel used as a semaphore to cap concurrent children.
+	sem := workflow.NewBufferedChannel(ctx, maxConcurrent)
+	wg := workflow.NewWaitGroup(ctx)
+	for i := range shards {
+		i := i
+		sem.Send(ctx, struct{}{})
+		wg.Add(1)
+		batch.Shards[i].Status = model.ConvergeShardRunning
+		workflow.Go(ctx, func(gCtx workflow.Context) {
+			defer wg.Done()
+			defer sem.Receive(gCtx, nil)
+
+			err := executeConvergeShard(gCtx, batch.Shards[i].ShardID)
+
+			batch.Completed++
+			if err != nil {
+				batch.Shards[i].Status = model.ConvergeShardFailed
+				batch.Shards[i].Error = err.Error()
+				batch.Failed++
+				return
+			}
+			batch.Shards[i].Status = model.ConvergeShardSucceeded
+			batch.Succeeded++
+		})
+	}
+	wg.Wait(ctx)
+
+	if batch.Failed > 0 {
+		batch.Status = model.ConvergeBatchFailed
+		return fmt.Errorf("%d of %d shards failed to converge", batch.Failed, batch.Total)
+	}
+	batch.Status = model.ConvergeBatchCompleted
+	return nil
+}
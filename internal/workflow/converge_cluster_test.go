@@ -0,0 +1,113 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+type ConvergeClusterWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func TestConvergeClusterWorkflow(t *testing.T) {
+	suite.Run(t, new(ConvergeClusterWorkflowTestSuite))
+}
+
+func (s *ConvergeClusterWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+	s.env.RegisterWorkflow(ConvergeShardWorkflow)
+}
+
+func (s *ConvergeClusterWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *ConvergeClusterWorkflowTestSuite) shards() []model.Shard {
+	return []model.Shard{
+		{ID: "shard-web", Name: "web-1", Role: model.ShardRoleWeb},
+		{ID: "shard-db", Name: "db-1", Role: model.ShardRoleDatabase},
+		{ID: "shard-vk", Name: "valkey-1", Role: model.ShardRoleValkey},
+	}
+}
+
+func (s *ConvergeClusterWorkflowTestSuite) queryBatch() model.ConvergeBatch {
+	val, err := s.env.QueryWorkflow(model.ConvergeBatchQuery)
+	s.Require().NoError(err)
+	var batch model.ConvergeBatch
+	s.Require().NoError(val.Get(&batch))
+	return batch
+}
+
+func (s *ConvergeClusterWorkflowTestSuite) TestSuccess() {
+	s.env.OnActivity("ListShardsByCluster", mock.Anything, "cluster-1").Return(s.shards(), nil)
+	s.env.OnWorkflow(ConvergeShardWorkflow, mock.Anything, ConvergeShardParams{ShardID: "shard-web"}).Return(nil)
+	s.env.OnWorkflow(ConvergeShardWorkflow, mock.Anything, ConvergeShardParams{ShardID: "shard-db"}).Return(nil)
+	s.env.OnWorkflow(ConvergeShardWorkflow, mock.Anything, ConvergeShardParams{ShardID: "shard-vk"}).Return(nil)
+
+	s.env.ExecuteWorkflow(ConvergeClusterWorkflow, ConvergeClusterParams{BatchID: "cb-1", ClusterID: "cluster-1"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	batch := s.queryBatch()
+	s.Equal(model.ConvergeBatchCompleted, batch.Status)
+	s.Equal(defaultConvergeConcurrency, batch.MaxConcurrent)
+	s.Equal(3, batch.Total)
+	s.Equal(3, batch.Completed)
+	s.Equal(3, batch.Succeeded)
+	for _, sh := range batch.Shards {
+		s.Equal(model.ConvergeShardSucceeded, sh.Status)
+	}
+}
+
+func (s *ConvergeClusterWorkflowTestSuite) TestShardFailure_ContinuesOthers() {
+	s.env.OnActivity("ListShardsByCluster", mock.Anything, "cluster-1").Return(s.shards(), nil)
+	s.env.OnWorkflow(ConvergeShardWorkflow, mock.Anything, ConvergeShardParams{ShardID: "shard-web"}).Return(nil)
+	s.env.OnWorkflow(ConvergeShardWorkflow, mock.Anything, ConvergeShardParams{ShardID: "shard-db"}).Return(fmt.Errorf("node unreachable"))
+	s.env.OnWorkflow(ConvergeShardWorkflow, mock.Anything, ConvergeShardParams{ShardID: "shard-vk"}).Return(nil)
+
+	s.env.ExecuteWorkflow(ConvergeClusterWorkflow, ConvergeClusterParams{BatchID: "cb-1", ClusterID: "cluster-1", MaxConcurrent: 1})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+
+	batch := s.queryBatch()
+	s.Equal(model.ConvergeBatchFailed, batch.Status)
+	s.Equal(3, batch.Completed)
+	s.Equal(2, batch.Succeeded)
+	s.Equal(1, batch.Failed)
+	s.Equal(model.ConvergeShardFailed, batch.Shards[1].Status)
+	s.Contains(batch.Shards[1].Error, "node unreachable")
+}
+
+func (s *ConvergeClusterWorkflowTestSuite) TestListShardsFails() {
+	s.env.OnActivity("ListShardsByCluster", mock.Anything, "cluster-1").Return(nil, fmt.Errorf("db error"))
+
+	s.env.ExecuteWorkflow(ConvergeClusterWorkflow, ConvergeClusterParams{BatchID: "cb-1", ClusterID: "cluster-1"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func (s *ConvergeClusterWorkflowTestSuite) TestShardAlreadyConverging_QueuesBehind() {
+	s.env.OnActivity("ListShardsByCluster", mock.Anything, "cluster-1").Return(s.shards()[:1], nil)
+	s.env.OnWorkflow(ConvergeShardWorkflow, mock.Anything, ConvergeShardParams{ShardID: "shard-web"}).
+		Return(&temporal.ChildWorkflowExecutionAlreadyStartedError{}).Once()
+	s.env.OnWorkflow(ConvergeShardWorkflow, mock.Anything, ConvergeShardParams{ShardID: "shard-web"}).
+		Return(nil).Once()
+
+	s.env.ExecuteWorkflow(ConvergeClusterWorkflow, ConvergeClusterParams{BatchID: "cb-1", ClusterID: "cluster-1"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	batch := s.queryBatch()
+	s.Equal(model.ConvergeBatchCompleted, batch.Status)
+	s.Equal(1, batch.Succeeded)
+}
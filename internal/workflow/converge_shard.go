@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	enumspb "go.temporal.io/api/enums/v1"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 
@@ -15,6 +16,14 @@ import (
 	"github.com/edvin/hosting/internal/model"
 )
 
+// convergeQueuePollInterval is how often a parent re-tries starting a shard
+// convergence while another one for the same shard is still running, and
+// convergeQueueTimeout is how long it waits in total before giving up.
+const (
+	convergeQueuePollInterval = 15 * time.Second
+	convergeQueueTimeout      = time.Hour
+)
+
 // ConvergeShardParams holds parameters for the ConvergeShardWorkflow.
 type ConvergeShardParams struct {
 	ShardID string `json:"shard_id"`
@@ -65,7 +74,7 @@ func ConvergeShardWorkflow(ctx workflow.Context, params ConvergeShardParams) err
 	var errs []string
 	switch shard.Role {
 	case model.ShardRoleWeb:
-		errs = convergeWebShard(ctx, params.ShardID, nodes)
+		errs = convergeWebShard(ctx, shard, nodes)
 	case model.ShardRoleDatabase:
 		errs = convergeDatabaseShard(ctx, params.ShardID, nodes)
 	case model.ShardRoleValkey:
@@ -103,6 +112,34 @@ func setShardStatus(ctx workflow.Context, shardID, status string, msg *string) {
 	}).Get(ctx, nil)
 }
 
+// executeConvergeShard runs ConvergeShardWorkflow for a shard as a child
+// workflow under the shard's fixed workflow ID. If a convergence for the
+// shard is already running (e.g. started from the API), it queues behind it
+// and starts its own run once that one has finished, so the caller still
+// gets a convergence that began after its own changes.
+func executeConvergeShard(ctx workflow.Context, shardID string) error {
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID:            core.ConvergeShardWorkflowID(shardID),
+		TaskQueue:             "hosting-tasks",
+		WorkflowIDReusePolicy: enumspb.WORKFLOW_ID_REUSE_POLICY_ALLOW_DUPLICATE,
+	})
+	deadline := workflow.Now(ctx).Add(convergeQueueTimeout)
+	for {
+		err := workflow.ExecuteChildWorkflow(childCtx, ConvergeShardWorkflow,
+			ConvergeShardParams{ShardID: shardID}).Get(ctx, nil)
+		if !temporal.IsWorkflowExecutionAlreadyStartedError(err) {
+			return err
+		}
+		if workflow.Now(ctx).After(deadline) {
+			return fmt.Errorf("shard %s: another convergence still running after %s", shardID, convergeQueueTimeout)
+		}
+		workflow.GetLogger(ctx).Info("convergence already running for shard, waiting", "shard", shardID)
+		if err := workflow.Sleep(ctx, convergeQueuePollInterval); err != nil {
+			return err
+		}
+	}
+}
+
 func convergeLBShard(ctx workflow.Context, shard model.Shard, nodes []model.Node) []string {
 	// Fetch all active FQDN-to-backend mappings for this cluster.
 	var mappings []activity.FQDNMapping
@@ -116,8 +153,10 @@ func convergeLBShard(ctx workflow.Context, shard model.Shard, nodes []model.Node
 		for _, node := range nodes {
 			nodeCtx := nodeActivityCtx(ctx, node.ID)
 			err = workflow.ExecuteActivity(nodeCtx, "SetLBMapEntry", activity.SetLBMapEntryParams{
-				FQDN:      m.FQDN,
-				LBBackend: m.LBBackend,
+				FQDN:         m.FQDN,
+				LBBackend:    m.LBBackend,
+				SplitBackend: m.SplitBackend,
+				SplitWeight:  m.SplitWeight,
 			}).Get(ctx, nil)
 			if err != nil {
 				errs = append(errs, fmt.Sprintf("set lb map %s on node %s: %v", m.FQDN, node.ID, err))
@@ -128,14 +167,70 @@ func convergeLBShard(ctx workflow.Context, shard model.Shard, nodes []model.Node
 	return errs
 }
 
-func convergeWebShard(ctx workflow.Context, shardID string, nodes []model.Node) []string {
+// proxyProtocolSources returns the addresses of a cluster's LB nodes, which
+// a web shard with PROXY protocol on trusts to send it.
+func proxyProtocolSources(ctx workflow.Context, clusterID string) ([]string, []string) {
+	var lbNodes []model.Node
+	if err := workflow.ExecuteActivity(ctx, "GetNodesByClusterAndRole", clusterID, model.ShardRoleLB).Get(ctx, &lbNodes); err != nil {
+		return nil, []string{fmt.Sprintf("list lb nodes: %v", err)}
+	}
+	var addrs []string
+	for _, n := range lbNodes {
+		if n.IPAddress != nil {
+			addrs = append(addrs, *n.IPAddress)
+		}
+		if n.IP6Address != nil {
+			addrs = append(addrs, *n.IP6Address)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, []string{fmt.Sprintf("proxy_protocol is on but cluster %s has no LB node addresses", clusterID)}
+	}
+	return addrs, nil
+}
+
+func convergeWebShard(ctx workflow.Context, shard model.Shard, nodes []model.Node) []string {
 	logger := workflow.GetLogger(ctx)
+	shardID := shard.ID
+
+	// Apply the shard's nginx tuning to the base nginx.conf first. Nodes only
+	// reload when it changed, and a failure here does not hold back the
+	// site configs.
+	var errs []string
+	var cfg model.WebShardConfig
+	if len(shard.Config) > 0 {
+		if err := json.Unmarshal(shard.Config, &cfg); err != nil {
+			errs = append(errs, fmt.Sprintf("parse web shard config: %v", err))
+		}
+	}
+	// With PROXY protocol on, the nodes trust the header from the cluster's
+	// LBs only. The site configs regenerated below pick up the setting.
+	var realIPFrom []string
+	if len(errs) == 0 && cfg.ProxyProtocol {
+		realIPFrom, errs = proxyProtocolSources(ctx, shard.ClusterID)
+	}
+	if len(errs) == 0 {
+		baseErrs := fanOutNodes(ctx, nodes, func(gCtx workflow.Context, node model.Node) error {
+			var result activity.ApplyNginxBaseConfigResult
+			if err := workflow.ExecuteActivity(nodeActivityCtx(gCtx, node.ID), "ApplyNginxBaseConfig", activity.ApplyNginxBaseConfigParams{
+				Tuning:     cfg.Nginx,
+				RealIPFrom: realIPFrom,
+			}).Get(gCtx, &result); err != nil {
+				return fmt.Errorf("apply nginx base config on node %s: %v", node.ID, err)
+			}
+			if result.Changed {
+				logger.Info("applied new nginx base config", "node", node.ID)
+			}
+			return nil
+		})
+		errs = append(errs, baseErrs...)
+	}
 
 	// Fetch all desired state for the shard in a single batch query.
 	var state activity.ShardDesiredState
 	err := workflow.ExecuteActivity(ctx, "GetShardDesiredState", shardID).Get(ctx, &state)
 	if err != nil {
-		return []string{fmt.Sprintf("get shard desired state: %v", err)}
+		return append(errs, fmt.Sprintf("get shard desired state: %v", err))
 	}
 
 	// Build expected nginx config, FPM pool, and daemon config sets from batch data.
@@ -149,7 +244,6 @@ func convergeWebShard(ctx workflow.Context, shardID string, nodes []model.Node)
 	}
 	var webrootEntries []webrootEntry
 
-	var errs []string
 	for _, tenant := range state.Tenants {
 		if tenant.Status != model.StatusActive {
 			continue
@@ -403,6 +497,13 @@ func convergeWebShard(ctx workflow.Context, shardID string, nodes []model.Node)
 				RuntimeVersion: e.webroot.RuntimeVersion,
 				RuntimeConfig:  string(e.webroot.RuntimeConfig),
 				PublicFolder:   e.webroot.PublicFolder,
+				ErrorPages:     e.webroot.ErrorPages,
+				BasicAuth:      e.webroot.BasicAuth,
+				AccessLog:      e.webroot.AccessLogEnabled,
+				Limits:         e.webroot.ConnectionLimits,
+				StaticRules:    e.webroot.StaticRules,
+				GeoBlocking:    e.webroot.GeoBlocking,
+				DeployLock:     e.webroot.DeployLockedUntil,
 				EnvVars:        state.EnvVars[e.webroot.ID],
 				EnvFileName:    e.webroot.EnvFileName,
 				FQDNs:          e.fqdns,
@@ -433,7 +534,9 @@ func convergeWebShard(ctx workflow.Context, shardID string, nodes []model.Node)
 				WorkingDirectory: job.WorkingDirectory,
 				TimeoutSeconds:   job.TimeoutSeconds,
 				MaxMemoryMB:      job.MaxMemoryMB,
+				Timezone:         job.Timezone,
 				EnvFileName:      entry.webroot.EnvFileName,
+				EnvVars:          state.CronJobEnvVars[job.ID],
 			}
 
 			// Write unit files on all nodes (parallel).
@@ -447,21 +550,25 @@ func convergeWebShard(ctx workflow.Context, shardID string, nodes []model.Node)
 			})
 			errs = append(errs, cronErrs...)
 
-			// Enable timer on all nodes (parallel) — flock ensures single execution.
-			if j.Enabled {
-				timerParams := activity.CronJobTimerParams{
-					ID:         j.ID,
-					TenantName: entry.tenant.ID,
-				}
-				timerErrs := fanOutNodes(ctx, nodes, func(gCtx workflow.Context, node model.Node) error {
-					nodeCtx := nodeActivityCtx(gCtx, node.ID)
-					if err := workflow.ExecuteActivity(nodeCtx, "EnableCronJobTimer", timerParams).Get(gCtx, nil); err != nil {
-						return fmt.Errorf("enable cron timer %s on node %s: %v", j.ID, node.ID, err)
-					}
-					return nil
-				})
-				errs = append(errs, timerErrs...)
+			// Enable timer on all nodes (parallel) — flock ensures single
+			// execution. Timers of disabled or paused jobs are disabled, in
+			// case a node still runs them.
+			timerParams := activity.CronJobTimerParams{
+				ID:         j.ID,
+				TenantName: entry.tenant.ID,
+			}
+			timerActivity, timerAction := "EnableCronJobTimer", "enable"
+			if !j.Enabled {
+				timerActivity, timerAction = "DisableCronJobTimer", "disable"
 			}
+			timerErrs := fanOutNodes(ctx, nodes, func(gCtx workflow.Context, node model.Node) error {
+				nodeCtx := nodeActivityCtx(gCtx, node.ID)
+				if err := workflow.ExecuteActivity(nodeCtx, timerActivity, timerParams).Get(gCtx, nil); err != nil {
+					return fmt.Errorf("%s cron timer %s on node %s: %v", timerAction, j.ID, node.ID, err)
+				}
+				return nil
+			})
+			errs = append(errs, timerErrs...)
 		}
 	}
 
@@ -583,7 +690,11 @@ func convergeDatabaseShard(ctx workflow.Context, shardID string, nodes []model.N
 			continue
 		}
 
-		err = workflow.ExecuteActivity(primaryCtx, "CreateDatabase", database.ID).Get(ctx, nil)
+		err = workflow.ExecuteActivity(primaryCtx, "CreateDatabase", activity.CreateDatabaseParams{
+			Name:      database.ID,
+			Charset:   database.Charset,
+			Collation: database.Collation,
+		}).Get(ctx, nil)
 		if err != nil {
 			errs = append(errs, fmt.Sprintf("create database %s on primary: %v", database.ID, err))
 		}
@@ -842,10 +953,11 @@ func convergeValkeyShard(ctx workflow.Context, shardID string, nodes []model.Nod
 		for _, node := range nodes {
 			nodeCtx := nodeActivityCtx(ctx, node.ID)
 			err = workflow.ExecuteActivity(nodeCtx, "CreateValkeyInstance", activity.CreateValkeyInstanceParams{
-				Name:         instance.ID,
-				Port:         instance.Port,
-				PasswordHash: instance.PasswordHash,
-				MaxMemoryMB:  instance.MaxMemoryMB,
+				Name:            instance.ID,
+				Port:            instance.Port,
+				PasswordHash:    instance.PasswordHash,
+				MaxMemoryMB:     instance.MaxMemoryMB,
+				PersistenceMode: instance.PersistenceMode,
 			}).Get(ctx, nil)
 			if err != nil {
 				errs = append(errs, fmt.Sprintf("create valkey instance %s on node %s: %v", instance.ID, node.ID, err))
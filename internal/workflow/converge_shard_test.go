@@ -75,6 +75,10 @@ func (s *ConvergeShardWorkflowTestSuite) TestWebShard() {
 		SSHKeys:  map[string][]string{},
 	}, nil)
 
+	// Base nginx.conf with default tuning on each node.
+	s.env.OnActivity("ApplyNginxBaseConfig", mock.Anything, activity.ApplyNginxBaseConfigParams{}).
+		Return(activity.ApplyNginxBaseConfigResult{}, nil)
+
 	// CleanOrphanedConfigs on each node before creating webroots.
 	s.env.OnActivity("CleanOrphanedConfigs", mock.Anything, activity.CleanOrphanedConfigsInput{
 		ExpectedConfigs: map[string]bool{"tenant-1_wr-1.conf": true},
@@ -111,6 +115,51 @@ func (s *ConvergeShardWorkflowTestSuite) TestWebShard() {
 	s.NoError(s.env.GetWorkflowError())
 }
 
+func (s *ConvergeShardWorkflowTestSuite) TestWebShardPausedCronJobTimerDisabled() {
+	shardID := "shard-web-5"
+	tenantShardID := shardID
+	shard := model.Shard{ID: shardID, Role: model.ShardRoleWeb}
+	nodes := []model.Node{{ID: "node-1"}}
+	tenants := []model.Tenant{
+		{ID: "tenant-1", BrandID: "test-brand", ShardID: &tenantShardID, UID: 1000, Status: model.StatusActive},
+	}
+	webroots := []model.Webroot{
+		{ID: "wr-1", TenantID: "tenant-1", Runtime: "static", RuntimeConfig: json.RawMessage(`{}`), Status: model.StatusActive},
+	}
+	s.env.OnActivity("GetShardByID", mock.Anything, shardID).Return(&shard, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchShardStatus(shardID, model.StatusConverging)).Return(nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return(nodes, nil)
+	s.env.OnActivity("GetShardDesiredState", mock.Anything, shardID).Return(&activity.ShardDesiredState{
+		Tenants:  tenants,
+		Webroots: map[string][]model.Webroot{"tenant-1": webroots},
+		FQDNs:    map[string][]activity.FQDNParam{},
+		EnvVars:  map[string]map[string]string{},
+		Daemons:  map[string][]model.Daemon{},
+		CronJobs: map[string][]model.CronJob{
+			"wr-1": {{ID: "cron-1", TenantID: "tenant-1", WebrootID: "wr-1", Schedule: "*/5 * * * *", Command: "true", Enabled: false, Status: model.StatusActive}},
+		},
+		SSHKeys: map[string][]string{},
+	}, nil)
+	s.env.OnActivity("ApplyNginxBaseConfig", mock.Anything, mock.Anything).Return(activity.ApplyNginxBaseConfigResult{}, nil)
+	s.env.OnActivity("CleanOrphanedConfigs", mock.Anything, mock.Anything).Return(activity.CleanOrphanedConfigsResult{}, nil)
+	s.env.OnActivity("CreateTenant", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("SyncSSHConfig", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("CreateWebroot", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("ReloadNginx", mock.Anything).Return(nil)
+	s.env.OnActivity("CreateCronJobUnits", mock.Anything, mock.Anything).Return(nil)
+	// A paused job's timer is disabled, not left as the node has it.
+	s.env.OnActivity("DisableCronJobTimer", mock.Anything, activity.CronJobTimerParams{
+		ID: "cron-1", TenantName: "tenant-1",
+	}).Return(nil).Once()
+	s.env.OnActivity("ListShardsByClusterAndRole", mock.Anything, "", model.ShardRoleDatabase).Return([]model.Shard{}, nil)
+	s.env.OnActivity("ListShardsByClusterAndRole", mock.Anything, "", model.ShardRoleValkey).Return([]model.Shard{}, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchShardStatus(shardID, model.StatusActive)).Return(nil)
+
+	s.env.ExecuteWorkflow(ConvergeShardWorkflow, ConvergeShardParams{ShardID: shardID})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
 func (s *ConvergeShardWorkflowTestSuite) TestDatabaseShard() {
 	shardID := "shard-db-1"
 	shard := model.Shard{
@@ -133,7 +182,7 @@ func (s *ConvergeShardWorkflowTestSuite) TestDatabaseShard() {
 	// SetReadOnly(false) on the primary node.
 	s.env.OnActivity("SetReadOnly", mock.Anything, false).Return(nil)
 	s.env.OnActivity("ListDatabasesByShard", mock.Anything, shardID).Return(databases, nil)
-	s.env.OnActivity("CreateDatabase", mock.Anything, "db-1").Return(nil)
+	s.env.OnActivity("CreateDatabase", mock.Anything, activity.CreateDatabaseParams{Name: "db-1"}).Return(nil)
 	s.env.OnActivity("ListDatabaseUsersByDatabaseID", mock.Anything, "db-1").Return(users, nil)
 	s.env.OnActivity("CreateDatabaseUser", mock.Anything, activity.CreateDatabaseUserParams{
 		DatabaseName: "db-1",
@@ -251,6 +300,9 @@ func (s *ConvergeShardWorkflowTestSuite) TestSkipsInactiveResources() {
 		SSHKeys:  map[string][]string{},
 	}, nil)
 
+	s.env.OnActivity("ApplyNginxBaseConfig", mock.Anything, activity.ApplyNginxBaseConfigParams{}).
+		Return(activity.ApplyNginxBaseConfigResult{}, nil)
+
 	// CleanOrphanedConfigs with empty expected set (no active webroots).
 	s.env.OnActivity("CleanOrphanedConfigs", mock.Anything, activity.CleanOrphanedConfigsInput{
 		ExpectedConfigs: map[string]bool{},
@@ -277,6 +329,72 @@ func (s *ConvergeShardWorkflowTestSuite) TestSkipsInactiveResources() {
 	s.NoError(s.env.GetWorkflowError())
 }
 
+func (s *ConvergeShardWorkflowTestSuite) TestWebShardNginxTuning() {
+	shardID := "shard-web-3"
+	shard := model.Shard{
+		ID:     shardID,
+		Role:   model.ShardRoleWeb,
+		Config: json.RawMessage(`{"nginx":{"worker_connections":4096,"client_max_body_size":"64m"}}`),
+	}
+	nodes := []model.Node{{ID: "node-1"}}
+
+	s.env.OnActivity("GetShardByID", mock.Anything, shardID).Return(&shard, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchShardStatus(shardID, model.StatusConverging)).Return(nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return(nodes, nil)
+
+	s.env.OnActivity("ApplyNginxBaseConfig", mock.Anything, activity.ApplyNginxBaseConfigParams{
+		Tuning: model.NginxTuning{WorkerConnections: 4096, ClientMaxBodySize: "64m"},
+	}).Return(activity.ApplyNginxBaseConfigResult{Changed: true}, nil).Once()
+
+	s.env.OnActivity("GetShardDesiredState", mock.Anything, shardID).Return(&activity.ShardDesiredState{}, nil)
+	s.env.OnActivity("CleanOrphanedConfigs", mock.Anything, activity.CleanOrphanedConfigsInput{
+		ExpectedConfigs: map[string]bool{},
+	}).Return(activity.CleanOrphanedConfigsResult{}, nil)
+	s.env.OnActivity("ReloadNginx", mock.Anything).Return(nil)
+	s.env.OnActivity("ListShardsByClusterAndRole", mock.Anything, "", model.ShardRoleDatabase).Return([]model.Shard{}, nil)
+	s.env.OnActivity("ListShardsByClusterAndRole", mock.Anything, "", model.ShardRoleValkey).Return([]model.Shard{}, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchShardStatus(shardID, model.StatusActive)).Return(nil)
+
+	s.env.ExecuteWorkflow(ConvergeShardWorkflow, ConvergeShardParams{ShardID: shardID})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ConvergeShardWorkflowTestSuite) TestWebShardProxyProtocol() {
+	shardID := "shard-web-4"
+	lbIP := "10.0.0.5/32"
+	shard := model.Shard{
+		ID:        shardID,
+		ClusterID: "cluster-1",
+		Role:      model.ShardRoleWeb,
+		Config:    json.RawMessage(`{"proxy_protocol":true}`),
+	}
+	nodes := []model.Node{{ID: "node-1"}}
+
+	s.env.OnActivity("GetShardByID", mock.Anything, shardID).Return(&shard, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchShardStatus(shardID, model.StatusConverging)).Return(nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return(nodes, nil)
+
+	s.env.OnActivity("GetNodesByClusterAndRole", mock.Anything, "cluster-1", model.ShardRoleLB).
+		Return([]model.Node{{ID: "lb-1", IPAddress: &lbIP}}, nil)
+	s.env.OnActivity("ApplyNginxBaseConfig", mock.Anything, activity.ApplyNginxBaseConfigParams{
+		RealIPFrom: []string{lbIP},
+	}).Return(activity.ApplyNginxBaseConfigResult{Changed: true}, nil).Once()
+
+	s.env.OnActivity("GetShardDesiredState", mock.Anything, shardID).Return(&activity.ShardDesiredState{}, nil)
+	s.env.OnActivity("CleanOrphanedConfigs", mock.Anything, activity.CleanOrphanedConfigsInput{
+		ExpectedConfigs: map[string]bool{},
+	}).Return(activity.CleanOrphanedConfigsResult{}, nil)
+	s.env.OnActivity("ReloadNginx", mock.Anything).Return(nil)
+	s.env.OnActivity("ListShardsByClusterAndRole", mock.Anything, "", model.ShardRoleDatabase).Return([]model.Shard{}, nil)
+	s.env.OnActivity("ListShardsByClusterAndRole", mock.Anything, "", model.ShardRoleValkey).Return([]model.Shard{}, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchShardStatus(shardID, model.StatusActive)).Return(nil)
+
+	s.env.ExecuteWorkflow(ConvergeShardWorkflow, ConvergeShardParams{ShardID: shardID})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
 func (s *ConvergeShardWorkflowTestSuite) TestGetShardFails() {
 	shardID := "shard-fail"
 	s.env.OnActivity("GetShardByID", mock.Anything, shardID).Return(nil, fmt.Errorf("not found"))
@@ -311,7 +429,7 @@ func (s *ConvergeShardWorkflowTestSuite) TestPartialFailureContinues() {
 	s.env.OnActivity("ListDatabasesByShard", mock.Anything, shardID).Return(databases, nil)
 
 	// CreateDatabase on primary only - succeeds.
-	s.env.OnActivity("CreateDatabase", mock.Anything, "db-1").Return(nil)
+	s.env.OnActivity("CreateDatabase", mock.Anything, activity.CreateDatabaseParams{Name: "db-1"}).Return(nil)
 
 	// User listing still runs (workflow doesn't stop).
 	s.env.OnActivity("ListDatabaseUsersByDatabaseID", mock.Anything, "db-1").Return([]model.DatabaseUser{}, nil)
@@ -59,7 +59,9 @@ func CreateCronJobWorkflow(ctx workflow.Context, cronJobID string) error {
 		WorkingDirectory: cronCtx.CronJob.WorkingDirectory,
 		TimeoutSeconds:   cronCtx.CronJob.TimeoutSeconds,
 		MaxMemoryMB:      cronCtx.CronJob.MaxMemoryMB,
+		Timezone:         cronCtx.CronJob.Timezone,
 		EnvFileName:      cronCtx.Webroot.EnvFileName,
+		EnvVars:          cronCtx.EnvVars,
 	}
 
 	// Write unit files on all nodes.
@@ -154,7 +156,9 @@ func UpdateCronJobWorkflow(ctx workflow.Context, cronJobID string) error {
 		WorkingDirectory: cronCtx.CronJob.WorkingDirectory,
 		TimeoutSeconds:   cronCtx.CronJob.TimeoutSeconds,
 		MaxMemoryMB:      cronCtx.CronJob.MaxMemoryMB,
+		Timezone:         cronCtx.CronJob.Timezone,
 		EnvFileName:      cronCtx.Webroot.EnvFileName,
+		EnvVars:          cronCtx.EnvVars,
 	}
 
 	var errs []string
@@ -346,6 +350,19 @@ func EnableCronJobWorkflow(ctx workflow.Context, cronJobID string) error {
 
 // DisableCronJobWorkflow disables the cron timer on all nodes.
 func DisableCronJobWorkflow(ctx workflow.Context, cronJobID string) error {
+	return disableCronJob(ctx, cronJobID, false)
+}
+
+// PauseCronJobWorkflow disables the cron timer on all nodes like
+// DisableCronJobWorkflow, and also stops a run in progress, so that a
+// misbehaving job stops at once.
+func PauseCronJobWorkflow(ctx workflow.Context, cronJobID string) error {
+	return disableCronJob(ctx, cronJobID, true)
+}
+
+// disableCronJob disables the cron timer on all nodes and, with stopRun,
+// stops the job's service on them as well.
+func disableCronJob(ctx workflow.Context, cronJobID string, stopRun bool) error {
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 30 * time.Second,
 		RetryPolicy: &temporal.RetryPolicy{
@@ -390,6 +407,12 @@ func DisableCronJobWorkflow(ctx workflow.Context, cronJobID string) error {
 		nodeCtx := nodeActivityCtx(ctx, node.ID)
 		if err := workflow.ExecuteActivity(nodeCtx, "DisableCronJobTimer", timerParams).Get(ctx, nil); err != nil {
 			errs = append(errs, fmt.Sprintf("node %s: %v", node.ID, err))
+			continue
+		}
+		if stopRun {
+			if err := workflow.ExecuteActivity(nodeCtx, "StopCronJobRun", timerParams).Get(ctx, nil); err != nil {
+				errs = append(errs, fmt.Sprintf("node %s: %v", node.ID, err))
+			}
 		}
 	}
 
@@ -0,0 +1,71 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ---------- PauseCronJobWorkflow ----------
+
+type PauseCronJobWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *PauseCronJobWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *PauseCronJobWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *PauseCronJobWorkflowTestSuite) expectContext(cronJobID string) activity.CronJobTimerParams {
+	shardID := "shard-1"
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "cron_jobs", ID: cronJobID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetCronJobContext", mock.Anything, cronJobID).Return(&activity.CronJobContext{
+		CronJob: model.CronJob{ID: cronJobID, TenantID: "tenant-1"},
+		Tenant:  model.Tenant{ID: "tenant-1", ShardID: &shardID},
+		Nodes:   []model.Node{{ID: "node-1"}, {ID: "node-2"}},
+	}, nil)
+	return activity.CronJobTimerParams{ID: cronJobID, TenantName: "tenant-1"}
+}
+
+func (s *PauseCronJobWorkflowTestSuite) TestStopsTimerAndRun() {
+	params := s.expectContext("cron-1")
+	s.env.OnActivity("DisableCronJobTimer", mock.Anything, params).Return(nil).Twice()
+	s.env.OnActivity("StopCronJobRun", mock.Anything, params).Return(nil).Twice()
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "cron_jobs", ID: "cron-1", Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(PauseCronJobWorkflow, "cron-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *PauseCronJobWorkflowTestSuite) TestTimerStillActive_SetsStatusFailed() {
+	params := s.expectContext("cron-2")
+	s.env.OnActivity("DisableCronJobTimer", mock.Anything, params).Return(fmt.Errorf("timer still active after disable"))
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("cron_jobs", "cron-2")).Return(nil)
+
+	s.env.ExecuteWorkflow(PauseCronJobWorkflow, "cron-2")
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "still active")
+}
+
+func TestPauseCronJobWorkflow(t *testing.T) {
+	suite.Run(t, new(PauseCronJobWorkflowTestSuite))
+}
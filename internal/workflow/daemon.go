@@ -434,40 +434,11 @@ func DisableDaemonWorkflow(ctx workflow.Context, daemonID string) error {
 	}).Get(ctx, nil)
 }
 
-// regenerateWebrootNginxOnNodes fetches daemons and FQDNs for a webroot,
-// regenerates the nginx config with daemon proxy locations on all nodes, and reloads nginx.
-func regenerateWebrootNginxOnNodes(ctx workflow.Context, webroot model.Webroot, tenant model.Tenant, nodes []model.Node) []string {
-	var errs []string
-
-	// Fetch active daemons for this webroot to build proxy locations.
-	var daemons []model.Daemon
-	err := workflow.ExecuteActivity(ctx, "ListDaemonsByWebroot", webroot.ID).Get(ctx, &daemons)
-	if err != nil {
-		return []string{fmt.Sprintf("list daemons for webroot %s: %v", webroot.ID, err)}
-	}
-
-	// Fetch FQDNs for this webroot.
-	var fqdns []model.FQDN
-	err = workflow.ExecuteActivity(ctx, "GetFQDNsByWebrootID", webroot.ID).Get(ctx, &fqdns)
-	if err != nil {
-		return []string{fmt.Sprintf("get fqdns for webroot %s: %v", webroot.ID, err)}
-	}
-
-	var fqdnParams []activity.FQDNParam
-	for _, f := range fqdns {
-		if f.Status != model.StatusDeleted {
-			var webrootID string
-			if f.WebrootID != nil {
-				webrootID = *f.WebrootID
-			}
-			fqdnParams = append(fqdnParams, activity.FQDNParam{
-				FQDN:       f.FQDN,
-				WebrootID:  webrootID,
-				SSLEnabled: f.SSLEnabled,
-			})
-		}
-	}
-
+// webrootDaemonProxies builds the nginx proxy locations for a webroot's
+// active and provisioning daemons that have a proxy_path. Provisioning
+// daemons are included because CreateDaemonWorkflow regenerates nginx before
+// the daemon's status is set to active.
+func webrootDaemonProxies(daemons []model.Daemon, tenant model.Tenant, nodes []model.Node) []activity.DaemonProxyInfo {
 	// Build a node index map for ULA computation.
 	nodeShardIndex := make(map[string]int) // node ID -> shard_index
 	for _, n := range nodes {
@@ -482,9 +453,6 @@ func regenerateWebrootNginxOnNodes(ctx workflow.Context, webroot model.Webroot,
 		clusterID = nodes[0].ClusterID
 	}
 
-	// Build daemon proxy info for active/provisioning daemons with proxy_path.
-	// Include provisioning daemons because this function is called during CreateDaemonWorkflow
-	// before the daemon's status is set to active.
 	var daemonProxies []activity.DaemonProxyInfo
 	for _, d := range daemons {
 		if (d.Status == model.StatusActive || d.Status == model.StatusProvisioning) && d.ProxyPath != nil && d.ProxyPort != nil {
@@ -502,6 +470,48 @@ func regenerateWebrootNginxOnNodes(ctx workflow.Context, webroot model.Webroot,
 			})
 		}
 	}
+	return daemonProxies
+}
+
+// regenerateWebrootNginxOnNodes fetches daemons and FQDNs for a webroot,
+// regenerates the nginx config with daemon proxy locations on all nodes, and reloads nginx.
+func regenerateWebrootNginxOnNodes(ctx workflow.Context, webroot model.Webroot, tenant model.Tenant, nodes []model.Node) []string {
+	var errs []string
+
+	// Fetch active daemons for this webroot to build proxy locations.
+	var daemons []model.Daemon
+	err := workflow.ExecuteActivity(ctx, "ListDaemonsByWebroot", webroot.ID).Get(ctx, &daemons)
+	if err != nil {
+		return []string{fmt.Sprintf("list daemons for webroot %s: %v", webroot.ID, err)}
+	}
+
+	// Fetch FQDNs for this webroot.
+	var fqdns []model.FQDN
+	err = workflow.ExecuteActivity(ctx, "GetFQDNsByWebrootID", webroot.ID).Get(ctx, &fqdns)
+	if err != nil {
+		return []string{fmt.Sprintf("get fqdns for webroot %s: %v", webroot.ID, err)}
+	}
+
+	var fqdnParams []activity.FQDNParam
+	for _, f := range fqdns {
+		if f.Status != model.StatusDeleted {
+			var webrootID string
+			if f.WebrootID != nil {
+				webrootID = *f.WebrootID
+			}
+			fqdnParams = append(fqdnParams, activity.FQDNParam{
+				FQDN:                 f.FQDN,
+				WebrootID:            webrootID,
+				SSLEnabled:           f.SSLEnabled,
+				ForceHTTPS:           f.ForceHTTPS,
+				RedirectTarget:       f.RedirectTarget,
+				RedirectCode:         f.RedirectCode,
+				RedirectPreservePath: f.RedirectPreservePath,
+			})
+		}
+	}
+
+	daemonProxies := webrootDaemonProxies(daemons, tenant, nodes)
 
 	// Regenerate nginx on each node by calling UpdateWebroot which handles nginx config.
 	for _, node := range nodes {
@@ -514,6 +524,13 @@ func regenerateWebrootNginxOnNodes(ctx workflow.Context, webroot model.Webroot,
 			RuntimeVersion: webroot.RuntimeVersion,
 			RuntimeConfig:  string(webroot.RuntimeConfig),
 			PublicFolder:   webroot.PublicFolder,
+			ErrorPages:     webroot.ErrorPages,
+			BasicAuth:      webroot.BasicAuth,
+			AccessLog:      webroot.AccessLogEnabled,
+			Limits:         webroot.ConnectionLimits,
+			StaticRules:    webroot.StaticRules,
+			GeoBlocking:    webroot.GeoBlocking,
+			DeployLock:     webroot.DeployLockedUntil,
 			FQDNs:          fqdnParams,
 			Daemons:        daemonProxies,
 		}).Get(ctx, nil)
@@ -58,7 +58,11 @@ func CreateDatabaseWorkflow(ctx workflow.Context, databaseID string) error {
 
 	// Create database on the PRIMARY only (replicas get data via replication).
 	primaryCtx := nodeActivityCtx(ctx, primaryID)
-	err = workflow.ExecuteActivity(primaryCtx, "CreateDatabase", database.ID).Get(ctx, nil)
+	err = workflow.ExecuteActivity(primaryCtx, "CreateDatabase", activity.CreateDatabaseParams{
+		Name:      database.ID,
+		Charset:   database.Charset,
+		Collation: database.Collation,
+	}).Get(ctx, nil)
 	if err != nil {
 		_ = setResourceFailed(ctx, "databases", databaseID, err)
 		return err
@@ -0,0 +1,68 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// DatabaseConnectionsArgs identifies a database's shard and the MySQL users
+// whose connections may be listed or killed.
+type DatabaseConnectionsArgs struct {
+	ShardID      string
+	Users        []string
+	ConnectionID int64
+}
+
+// databaseConnectionsActivityOptions are short and barely retried: the
+// caller is an API request waiting for the result.
+var databaseConnectionsActivityOptions = workflow.ActivityOptions{
+	StartToCloseTimeout: 15 * time.Second,
+	RetryPolicy: &temporal.RetryPolicy{
+		MaximumAttempts:    2,
+		InitialInterval:    1 * time.Second,
+		MaximumInterval:    5 * time.Second,
+		BackoffCoefficient: 2.0,
+	},
+}
+
+// ListDatabaseConnectionsWorkflow lists the live connections of the given
+// users on the primary node of a database shard.
+func ListDatabaseConnectionsWorkflow(ctx workflow.Context, args DatabaseConnectionsArgs) ([]model.DatabaseConnection, error) {
+	ctx = workflow.WithActivityOptions(ctx, databaseConnectionsActivityOptions)
+
+	primaryID, _, err := dbShardPrimary(ctx, args.ShardID)
+	if err != nil {
+		return nil, fmt.Errorf("determine primary node: %w", err)
+	}
+
+	var conns []model.DatabaseConnection
+	err = workflow.ExecuteActivity(nodeActivityCtx(ctx, primaryID), "ListDatabaseConnections", activity.ListDatabaseConnectionsParams{
+		Users: args.Users,
+	}).Get(ctx, &conns)
+	if err != nil {
+		return nil, err
+	}
+	return conns, nil
+}
+
+// KillDatabaseConnectionWorkflow kills a connection on the primary node of a
+// database shard. The node agent refuses connections not owned by args.Users.
+func KillDatabaseConnectionWorkflow(ctx workflow.Context, args DatabaseConnectionsArgs) error {
+	ctx = workflow.WithActivityOptions(ctx, databaseConnectionsActivityOptions)
+
+	primaryID, _, err := dbShardPrimary(ctx, args.ShardID)
+	if err != nil {
+		return fmt.Errorf("determine primary node: %w", err)
+	}
+
+	return workflow.ExecuteActivity(nodeActivityCtx(ctx, primaryID), "KillDatabaseConnection", activity.KillDatabaseConnectionParams{
+		ConnectionID: args.ConnectionID,
+		Users:        args.Users,
+	}).Get(ctx, nil)
+}
@@ -0,0 +1,90 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type DatabaseConnectionsWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *DatabaseConnectionsWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *DatabaseConnectionsWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *DatabaseConnectionsWorkflowTestSuite) mockShard() {
+	cfg, _ := json.Marshal(model.DatabaseShardConfig{PrimaryNodeID: "node-db-2"})
+	s.env.OnActivity("GetShardByID", mock.Anything, "shard-db-1").
+		Return(&model.Shard{ID: "shard-db-1", Role: model.ShardRoleDatabase, Config: cfg}, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "shard-db-1").
+		Return([]model.Node{{ID: "node-db-1"}, {ID: "node-db-2"}}, nil)
+}
+
+func (s *DatabaseConnectionsWorkflowTestSuite) TestListOnPrimary() {
+	s.mockShard()
+	s.env.OnActivity("ListDatabaseConnections", mock.Anything, activity.ListDatabaseConnectionsParams{
+		Users: []string{"db_abc_app"},
+	}).Return([]model.DatabaseConnection{{ID: 12, User: "db_abc_app", Command: "Sleep"}}, nil)
+
+	s.env.ExecuteWorkflow(ListDatabaseConnectionsWorkflow, DatabaseConnectionsArgs{
+		ShardID: "shard-db-1",
+		Users:   []string{"db_abc_app"},
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var conns []model.DatabaseConnection
+	s.NoError(s.env.GetWorkflowResult(&conns))
+	s.Len(conns, 1)
+	s.Equal(int64(12), conns[0].ID)
+}
+
+func (s *DatabaseConnectionsWorkflowTestSuite) TestKillPassesUsers() {
+	s.mockShard()
+	s.env.OnActivity("KillDatabaseConnection", mock.Anything, activity.KillDatabaseConnectionParams{
+		ConnectionID: 12,
+		Users:        []string{"db_abc_app"},
+	}).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(KillDatabaseConnectionWorkflow, DatabaseConnectionsArgs{
+		ShardID:      "shard-db-1",
+		Users:        []string{"db_abc_app"},
+		ConnectionID: 12,
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *DatabaseConnectionsWorkflowTestSuite) TestKillFails() {
+	s.mockShard()
+	s.env.OnActivity("KillDatabaseConnection", mock.Anything, mock.Anything).
+		Return(fmt.Errorf("connection 99 not found"))
+
+	s.env.ExecuteWorkflow(KillDatabaseConnectionWorkflow, DatabaseConnectionsArgs{
+		ShardID:      "shard-db-1",
+		Users:        []string{"db_abc_app"},
+		ConnectionID: 99,
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func TestDatabaseConnectionsWorkflow(t *testing.T) {
+	suite.Run(t, new(DatabaseConnectionsWorkflowTestSuite))
+}
@@ -33,8 +33,10 @@ func (s *CreateDatabaseWorkflowTestSuite) TestSuccess() {
 	databaseID := "test-database-1"
 	shardID := "test-shard-1"
 	database := model.Database{
-		ID:      databaseID,
-		ShardID: &shardID,
+		ID:        databaseID,
+		ShardID:   &shardID,
+		Charset:   "latin1",
+		Collation: "latin1_bin",
 	}
 	shard := model.Shard{ID: shardID, Role: model.ShardRoleDatabase}
 	nodes := []model.Node{
@@ -47,7 +49,9 @@ func (s *CreateDatabaseWorkflowTestSuite) TestSuccess() {
 	s.env.OnActivity("GetDatabaseByID", mock.Anything, databaseID).Return(&database, nil)
 	s.env.OnActivity("GetShardByID", mock.Anything, shardID).Return(&shard, nil)
 	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return(nodes, nil)
-	s.env.OnActivity("CreateDatabase", mock.Anything, databaseID).Return(nil)
+	s.env.OnActivity("CreateDatabase", mock.Anything, activity.CreateDatabaseParams{
+		Name: databaseID, Charset: "latin1", Collation: "latin1_bin",
+	}).Return(nil)
 	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
 		Table: "databases", ID: databaseID, Status: model.StatusActive,
 	}).Return(nil)
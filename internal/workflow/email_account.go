@@ -69,6 +69,7 @@ func CreateEmailAccountWorkflow(ctx workflow.Context, accountID string) error {
 		Address:     account.Address,
 		DisplayName: account.DisplayName,
 		QuotaBytes:  account.QuotaBytes,
+		Password:    account.PasswordHash,
 	}).Get(ctx, nil)
 	if err != nil {
 		_ = setResourceFailed(ctx, "email_accounts", accountID, err)
@@ -0,0 +1,93 @@
+package workflow
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ProvisionEmailDKIMWorkflow installs an FQDN's DKIM key as a Stalwart signing
+// key and publishes the matching TXT record in the FQDN's zone. Re-running it
+// after a new key has been stored rotates the key.
+func ProvisionEmailDKIMWorkflow(ctx workflow.Context, fqdnID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	// Look up the key (without the private key, which stays out of history).
+	var key model.EmailDKIMKey
+	err := workflow.ExecuteActivity(ctx, "GetEmailDKIMKeyByFQDN", fqdnID).Get(ctx, &key)
+	if err != nil {
+		return err
+	}
+
+	// Set status to provisioning.
+	err = workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "fqdn_dkim_keys",
+		ID:     key.ID,
+		Status: model.StatusProvisioning,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	// Resolve Stalwart context (FQDN → webroot → tenant → cluster in one query).
+	var sctx activity.StalwartContext
+	err = workflow.ExecuteActivity(ctx, "GetStalwartContext", fqdnID).Get(ctx, &sctx)
+	if err != nil {
+		_ = setResourceFailed(ctx, "fqdn_dkim_keys", key.ID, err)
+		return err
+	}
+
+	// Create domain in Stalwart (idempotent).
+	err = workflow.ExecuteActivity(ctx, "StalwartCreateDomain", activity.StalwartDomainParams{
+		BaseURL:    sctx.StalwartURL,
+		AdminToken: sctx.StalwartToken,
+		Domain:     sctx.FQDN,
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setResourceFailed(ctx, "fqdn_dkim_keys", key.ID, err)
+		return err
+	}
+
+	// Install the signing key.
+	err = workflow.ExecuteActivity(ctx, "StalwartSetDKIM", activity.StalwartSetDKIMParams{
+		BaseURL:    sctx.StalwartURL,
+		AdminToken: sctx.StalwartToken,
+		FQDNID:     fqdnID,
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setResourceFailed(ctx, "fqdn_dkim_keys", key.ID, err)
+		return err
+	}
+
+	// Publish the DKIM record, replacing any previous or brand-level one.
+	err = workflow.ExecuteActivity(ctx, "PublishDKIMRecord", activity.PublishDKIMRecordParams{
+		FQDN:         sctx.FQDN,
+		Selector:     key.Selector,
+		PublicKey:    key.PublicKey,
+		SourceFQDNID: fqdnID,
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setResourceFailed(ctx, "fqdn_dkim_keys", key.ID, err)
+		return err
+	}
+
+	// Set status to active.
+	return workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "fqdn_dkim_keys",
+		ID:     key.ID,
+		Status: model.StatusActive,
+	}).Get(ctx, nil)
+}
@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type ProvisionEmailDKIMWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *ProvisionEmailDKIMWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *ProvisionEmailDKIMWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *ProvisionEmailDKIMWorkflowTestSuite) TestSuccess() {
+	fqdnID := "test-fqdn-1"
+	keyID := "test-dkim-1"
+
+	s.env.OnActivity("GetEmailDKIMKeyByFQDN", mock.Anything, fqdnID).Return(&model.EmailDKIMKey{
+		ID: keyID, FQDNID: fqdnID, Selector: "s202610", PublicKey: "MIIBIj", Status: model.StatusPending,
+	}, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "fqdn_dkim_keys", ID: keyID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetStalwartContext", mock.Anything, fqdnID).Return(&activity.StalwartContext{
+		StalwartURL:   "https://mail.example.com",
+		StalwartToken: "admin-token",
+		FQDNID:        fqdnID,
+		FQDN:          "example.com",
+	}, nil)
+	s.env.OnActivity("StalwartCreateDomain", mock.Anything, activity.StalwartDomainParams{
+		BaseURL: "https://mail.example.com", AdminToken: "admin-token", Domain: "example.com",
+	}).Return(nil)
+	s.env.OnActivity("StalwartSetDKIM", mock.Anything, activity.StalwartSetDKIMParams{
+		BaseURL: "https://mail.example.com", AdminToken: "admin-token", FQDNID: fqdnID,
+	}).Return(nil)
+	s.env.OnActivity("PublishDKIMRecord", mock.Anything, activity.PublishDKIMRecordParams{
+		FQDN: "example.com", Selector: "s202610", PublicKey: "MIIBIj", SourceFQDNID: fqdnID,
+	}).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "fqdn_dkim_keys", ID: keyID, Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(ProvisionEmailDKIMWorkflow, fqdnID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ProvisionEmailDKIMWorkflowTestSuite) TestSetDKIMFails_SetsStatusFailed() {
+	fqdnID := "test-fqdn-2"
+	keyID := "test-dkim-2"
+
+	s.env.OnActivity("GetEmailDKIMKeyByFQDN", mock.Anything, fqdnID).Return(&model.EmailDKIMKey{
+		ID: keyID, FQDNID: fqdnID, Selector: "s202610", PublicKey: "MIIBIj",
+	}, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "fqdn_dkim_keys", ID: keyID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetStalwartContext", mock.Anything, fqdnID).Return(&activity.StalwartContext{
+		StalwartURL: "https://mail.example.com", StalwartToken: "admin-token", FQDNID: fqdnID, FQDN: "example.com",
+	}, nil)
+	s.env.OnActivity("StalwartCreateDomain", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("StalwartSetDKIM", mock.Anything, mock.Anything).Return(fmt.Errorf("stalwart down"))
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("fqdn_dkim_keys", keyID)).Return(nil)
+
+	s.env.ExecuteWorkflow(ProvisionEmailDKIMWorkflow, fqdnID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func TestProvisionEmailDKIMWorkflow(t *testing.T) {
+	suite.Run(t, new(ProvisionEmailDKIMWorkflowTestSuite))
+}
@@ -48,6 +48,7 @@ func BindFQDNWorkflow(ctx workflow.Context, fqdnID string) error {
 		FQDN:         fctx.FQDN.FQDN,
 		LBAddresses:  fctx.LBAddresses,
 		SourceFQDNID: fqdnID,
+		IPv6Only:     fctx.FQDN.IPMode == model.IPModeIPv6Only,
 	}).Get(ctx, nil)
 	if err != nil {
 		_ = setResourceFailed(ctx, "fqdns", fqdnID, err)
@@ -75,9 +76,13 @@ func BindFQDNWorkflow(ctx workflow.Context, fqdnID string) error {
 			webrootID = *f.WebrootID
 		}
 		fqdnParams = append(fqdnParams, activity.FQDNParam{
-			FQDN:       f.FQDN,
-			WebrootID:  webrootID,
-			SSLEnabled: f.SSLEnabled,
+			FQDN:                 f.FQDN,
+			WebrootID:            webrootID,
+			SSLEnabled:           f.SSLEnabled,
+			ForceHTTPS:           f.ForceHTTPS,
+			RedirectTarget:       f.RedirectTarget,
+			RedirectCode:         f.RedirectCode,
+			RedirectPreservePath: f.RedirectPreservePath,
 		})
 	}
 
@@ -99,6 +104,13 @@ func BindFQDNWorkflow(ctx workflow.Context, fqdnID string) error {
 			RuntimeVersion: fctx.Webroot.RuntimeVersion,
 			RuntimeConfig:  string(fctx.Webroot.RuntimeConfig),
 			PublicFolder:   fctx.Webroot.PublicFolder,
+			ErrorPages:     fctx.Webroot.ErrorPages,
+			BasicAuth:      fctx.Webroot.BasicAuth,
+			AccessLog:      fctx.Webroot.AccessLogEnabled,
+			Limits:         fctx.Webroot.ConnectionLimits,
+			StaticRules:    fctx.Webroot.StaticRules,
+			GeoBlocking:    fctx.Webroot.GeoBlocking,
+			DeployLock:     fctx.Webroot.DeployLockedUntil,
 			FQDNs:          fqdnParams,
 		}).Get(gCtx, nil)
 	})
@@ -125,8 +137,10 @@ func BindFQDNWorkflow(ctx workflow.Context, fqdnID string) error {
 	lbErrs := fanOutNodes(ctx, fctx.LBNodes, func(gCtx workflow.Context, lbNode model.Node) error {
 		lbCtx := nodeActivityCtx(gCtx, lbNode.ID)
 		return workflow.ExecuteActivity(lbCtx, "SetLBMapEntry", activity.SetLBMapEntryParams{
-			FQDN:      fctx.FQDN.FQDN,
-			LBBackend: fctx.Shard.LBBackend,
+			FQDN:         fctx.FQDN.FQDN,
+			LBBackend:    fctx.Shard.LBBackend,
+			SplitBackend: fctx.SplitBackend,
+			SplitWeight:  fctx.SplitWeight,
 		}).Get(gCtx, nil)
 	})
 	if len(lbErrs) > 0 {
@@ -219,9 +233,13 @@ func UnbindFQDNWorkflow(ctx workflow.Context, fqdnID string) error {
 				webrootID = *f.WebrootID
 			}
 			fqdnParams = append(fqdnParams, activity.FQDNParam{
-				FQDN:       f.FQDN,
-				WebrootID:  webrootID,
-				SSLEnabled: f.SSLEnabled,
+				FQDN:                 f.FQDN,
+				WebrootID:            webrootID,
+				SSLEnabled:           f.SSLEnabled,
+				ForceHTTPS:           f.ForceHTTPS,
+				RedirectTarget:       f.RedirectTarget,
+				RedirectCode:         f.RedirectCode,
+				RedirectPreservePath: f.RedirectPreservePath,
 			})
 		}
 
@@ -243,6 +261,13 @@ func UnbindFQDNWorkflow(ctx workflow.Context, fqdnID string) error {
 				RuntimeVersion: fctx.Webroot.RuntimeVersion,
 				RuntimeConfig:  string(fctx.Webroot.RuntimeConfig),
 				PublicFolder:   fctx.Webroot.PublicFolder,
+				ErrorPages:     fctx.Webroot.ErrorPages,
+				BasicAuth:      fctx.Webroot.BasicAuth,
+				AccessLog:      fctx.Webroot.AccessLogEnabled,
+				Limits:         fctx.Webroot.ConnectionLimits,
+				StaticRules:    fctx.Webroot.StaticRules,
+				GeoBlocking:    fctx.Webroot.GeoBlocking,
+				DeployLock:     fctx.Webroot.DeployLockedUntil,
 				FQDNs:          fqdnParams,
 			}).Get(gCtx, nil)
 		})
@@ -273,3 +298,38 @@ func UnbindFQDNWorkflow(ctx workflow.Context, fqdnID string) error {
 		Status: model.StatusDeleted,
 	}).Get(ctx, nil)
 }
+
+// SyncFQDNDNSWorkflow brings a bound FQDN's auto DNS records in line with
+// its IP mode, adding or removing its A records. A failure marks the FQDN
+// failed; retrying it rebinds the FQDN, which syncs the records again.
+func SyncFQDNDNSWorkflow(ctx workflow.Context, fqdnID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var fctx activity.FQDNContext
+	err := workflow.ExecuteActivity(ctx, "GetFQDNContext", fqdnID).Get(ctx, &fctx)
+	if err != nil {
+		_ = setResourceFailed(ctx, "fqdns", fqdnID, err)
+		return err
+	}
+
+	err = workflow.ExecuteActivity(ctx, "AutoCreateDNSRecords", activity.AutoCreateDNSRecordsParams{
+		FQDN:         fctx.FQDN.FQDN,
+		LBAddresses:  fctx.LBAddresses,
+		SourceFQDNID: fqdnID,
+		IPv6Only:     fctx.FQDN.IPMode == model.IPModeIPv6Only,
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setResourceFailed(ctx, "fqdns", fqdnID, err)
+		return err
+	}
+	return nil
+}
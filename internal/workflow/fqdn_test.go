@@ -534,6 +534,58 @@ func (s *UnbindFQDNWorkflowTestSuite) TestUpdateWebrootFails_SetsStatusFailed()
 	s.Error(s.env.GetWorkflowError())
 }
 
+// ---------- SyncFQDNDNSWorkflow ----------
+
+type SyncFQDNDNSWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *SyncFQDNDNSWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *SyncFQDNDNSWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *SyncFQDNDNSWorkflowTestSuite) TestIPv6Only() {
+	fqdnID := "test-fqdn-1"
+	lbAddresses := []model.ClusterLBAddress{
+		{ID: "test-lb-1", ClusterID: "test-cluster-1", Address: "10.0.0.1", Family: 4, Label: "primary"},
+		{ID: "test-lb-2", ClusterID: "test-cluster-1", Address: "::1", Family: 6, Label: "primary"},
+	}
+
+	s.env.OnActivity("GetFQDNContext", mock.Anything, fqdnID).Return(&activity.FQDNContext{
+		FQDN:        model.FQDN{ID: fqdnID, FQDN: "example.com", IPMode: model.IPModeIPv6Only},
+		LBAddresses: lbAddresses,
+	}, nil)
+	s.env.OnActivity("AutoCreateDNSRecords", mock.Anything, activity.AutoCreateDNSRecordsParams{
+		FQDN:         "example.com",
+		LBAddresses:  lbAddresses,
+		SourceFQDNID: fqdnID,
+		IPv6Only:     true,
+	}).Return(nil)
+	s.env.ExecuteWorkflow(SyncFQDNDNSWorkflow, fqdnID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *SyncFQDNDNSWorkflowTestSuite) TestAutoCreateDNSFails_SetsStatusFailed() {
+	fqdnID := "test-fqdn-2"
+
+	s.env.OnActivity("GetFQDNContext", mock.Anything, fqdnID).Return(&activity.FQDNContext{
+		FQDN: model.FQDN{ID: fqdnID, FQDN: "example.com", IPMode: model.IPModeDualStack},
+	}, nil)
+	s.env.OnActivity("AutoCreateDNSRecords", mock.Anything, mock.Anything).Return(fmt.Errorf("dns error"))
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("fqdns", fqdnID)).Return(nil)
+	s.env.ExecuteWorkflow(SyncFQDNDNSWorkflow, fqdnID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
 // ---------- Run all suites ----------
 
 func TestBindFQDNWorkflow(t *testing.T) {
@@ -543,3 +595,7 @@ func TestBindFQDNWorkflow(t *testing.T) {
 func TestUnbindFQDNWorkflow(t *testing.T) {
 	suite.Run(t, new(UnbindFQDNWorkflowTestSuite))
 }
+
+func TestSyncFQDNDNSWorkflow(t *testing.T) {
+	suite.Run(t, new(SyncFQDNDNSWorkflowTestSuite))
+}
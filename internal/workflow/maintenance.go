@@ -1,15 +1,20 @@
 package workflow
 
 import (
+	"fmt"
 	"time"
 
+	enumspb "go.temporal.io/api/enums/v1"
 	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 
+	"github.com/edvin/hosting/internal/core"
 	"github.com/edvin/hosting/internal/model"
 )
 
-// CleanupAuditLogsWorkflow deletes audit log entries older than the specified days.
+// CleanupAuditLogsWorkflow deletes audit log entries and finished operations
+// older than the specified days, idempotency keys past their window, and
+// expired single-use backup download tokens.
 func CleanupAuditLogsWorkflow(ctx workflow.Context, retentionDays int) error {
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 30 * time.Second,
@@ -31,6 +36,24 @@ func CleanupAuditLogsWorkflow(ctx workflow.Context, retentionDays int) error {
 	logger := workflow.GetLogger(ctx)
 	logger.Info("cleaned up old audit logs", "deleted", deleted, "retentionDays", retentionDays)
 
+	err = workflow.ExecuteActivity(ctx, "DeleteOldOperations", retentionDays).Get(ctx, &deleted)
+	if err != nil {
+		return err
+	}
+	logger.Info("cleaned up old operations", "deleted", deleted, "retentionDays", retentionDays)
+
+	err = workflow.ExecuteActivity(ctx, "DeleteExpiredIdempotencyKeys", core.IdempotencyWindow).Get(ctx, &deleted)
+	if err != nil {
+		return err
+	}
+	logger.Info("cleaned up expired idempotency keys", "deleted", deleted)
+
+	err = workflow.ExecuteActivity(ctx, "DeleteExpiredBackupDownloadTokens").Get(ctx, &deleted)
+	if err != nil {
+		return err
+	}
+	logger.Info("cleaned up expired backup download tokens", "deleted", deleted)
+
 	return nil
 }
 
@@ -71,3 +94,137 @@ func CleanupOldBackupsWorkflow(ctx workflow.Context, retentionDays int) error {
 
 	return nil
 }
+
+// RunScheduledBackupsWorkflow runs hourly and backs up every tenant whose
+// backup schedule is due, starting a CreateBackupWorkflow for each of the
+// tenant's webroots and databases. Backups run independently of this
+// workflow so a slow tenant doesn't hold up the next hour's run.
+func RunScheduledBackupsWorkflow(ctx workflow.Context) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+	logger := workflow.GetLogger(ctx)
+
+	var tenantIDs []string
+	if err := workflow.ExecuteActivity(ctx, "GetTenantsDueForBackup", workflow.Now(ctx)).Get(ctx, &tenantIDs); err != nil {
+		return err
+	}
+
+	started := 0
+	for _, tenantID := range tenantIDs {
+		var backupIDs []string
+		if err := workflow.ExecuteActivity(ctx, "CreateScheduledBackups", tenantID).Get(ctx, &backupIDs); err != nil {
+			logger.Error("scheduled backup failed", "tenant", tenantID, "error", err)
+			continue
+		}
+		for _, id := range backupIDs {
+			childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+				WorkflowID:        "create-backup-" + id,
+				TaskQueue:         "hosting-tasks",
+				ParentClosePolicy: enumspb.PARENT_CLOSE_POLICY_ABANDON,
+			})
+			child := workflow.ExecuteChildWorkflow(childCtx, CreateBackupWorkflow, id)
+			if err := child.GetChildWorkflowExecution().Get(ctx, nil); err != nil {
+				logger.Error("scheduled backup not started", "tenant", tenantID, "backup", id, "error", err)
+				continue
+			}
+			started++
+		}
+	}
+	logger.Info("started scheduled backups", "tenants", len(tenantIDs), "backups", started)
+	return nil
+}
+
+// VerifyBackupsParams controls which backups VerifyRecentBackupsWorkflow samples.
+type VerifyBackupsParams struct {
+	SampleSize int // backups restore-tested per run
+	MaxAgeDays int // only backups completed within this many days are sampled
+}
+
+// VerifyRecentBackupsWorkflow restore-tests a random sample of recent,
+// not-yet-verified backups by starting a child VerifyBackupWorkflow for each.
+func VerifyRecentBackupsWorkflow(ctx workflow.Context, params VerifyBackupsParams) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var backups []model.Backup
+	err := workflow.ExecuteActivity(ctx, "GetBackupsToVerify", params.SampleSize, params.MaxAgeDays).Get(ctx, &backups)
+	if err != nil {
+		return err
+	}
+
+	logger := workflow.GetLogger(ctx)
+	logger.Info("sampled backups to verify", "count", len(backups))
+
+	var children []ChildWorkflowSpec
+	for _, backup := range backups {
+		children = append(children, ChildWorkflowSpec{
+			WorkflowName: "VerifyBackupWorkflow",
+			WorkflowID:   "verify-backup-" + backup.ID,
+			Arg:          backup.ID,
+		})
+	}
+	if errs := fanOutChildWorkflows(ctx, children); len(errs) > 0 {
+		logger.Error("backup verification failures", "errors", joinErrors(errs))
+	}
+
+	return nil
+}
+
+// CleanupTenantExportsWorkflow deletes tenant export archives whose retention
+// has passed, then removes their records. Exports that failed before upload
+// have no archive and only lose their record.
+func CleanupTenantExportsWorkflow(ctx workflow.Context) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var expired []model.TenantExport
+	err := workflow.ExecuteActivity(ctx, "GetExpiredTenantExports").Get(ctx, &expired)
+	if err != nil {
+		return err
+	}
+
+	logger := workflow.GetLogger(ctx)
+	logger.Info("found expired tenant exports to clean up", "count", len(expired))
+
+	var errs []string
+	for _, export := range expired {
+		if export.ObjectKey != "" {
+			if err := workflow.ExecuteActivity(ctx, "DeleteTenantExportObject", export.ObjectKey).Get(ctx, nil); err != nil {
+				errs = append(errs, fmt.Sprintf("%s: %v", export.ID, err))
+				continue
+			}
+		}
+		if err := workflow.ExecuteActivity(ctx, "DeleteTenantExport", export.ID).Get(ctx, nil); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", export.ID, err))
+		}
+	}
+	if len(errs) > 0 {
+		logger.Error("tenant export cleanup failures", "errors", joinErrors(errs))
+	}
+
+	return nil
+}
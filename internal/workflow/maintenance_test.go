@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/suite"
 	"go.temporal.io/sdk/testsuite"
 
+	"github.com/edvin/hosting/internal/core"
 	"github.com/edvin/hosting/internal/model"
 )
 
@@ -31,12 +32,24 @@ func (s *CleanupAuditLogsWorkflowTestSuite) AfterTest(suiteName, testName string
 
 func (s *CleanupAuditLogsWorkflowTestSuite) TestSuccess() {
 	s.env.OnActivity("DeleteOldAuditLogs", mock.Anything, 90).Return(int64(42), nil)
+	s.env.OnActivity("DeleteOldOperations", mock.Anything, 90).Return(int64(7), nil)
+	s.env.OnActivity("DeleteExpiredIdempotencyKeys", mock.Anything, core.IdempotencyWindow).Return(int64(3), nil)
+	s.env.OnActivity("DeleteExpiredBackupDownloadTokens", mock.Anything).Return(int64(2), nil)
 
 	s.env.ExecuteWorkflow(CleanupAuditLogsWorkflow, 90)
 	s.True(s.env.IsWorkflowCompleted())
 	s.NoError(s.env.GetWorkflowError())
 }
 
+func (s *CleanupAuditLogsWorkflowTestSuite) TestDeleteOperationsFails() {
+	s.env.OnActivity("DeleteOldAuditLogs", mock.Anything, 90).Return(int64(42), nil)
+	s.env.OnActivity("DeleteOldOperations", mock.Anything, 90).Return(int64(0), fmt.Errorf("db error"))
+
+	s.env.ExecuteWorkflow(CleanupAuditLogsWorkflow, 90)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
 func (s *CleanupAuditLogsWorkflowTestSuite) TestDeleteFails() {
 	s.env.OnActivity("DeleteOldAuditLogs", mock.Anything, 90).Return(int64(0), fmt.Errorf("db error"))
 
@@ -110,8 +123,147 @@ func (s *CleanupOldBackupsWorkflowTestSuite) TestGetOldBackupsFails() {
 	s.Error(s.env.GetWorkflowError())
 }
 
+// ---------- RunScheduledBackupsWorkflow ----------
+
+type RunScheduledBackupsWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *RunScheduledBackupsWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+	s.env.RegisterWorkflow(CreateBackupWorkflow)
+}
+
+func (s *RunScheduledBackupsWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *RunScheduledBackupsWorkflowTestSuite) TestSuccess() {
+	s.env.OnActivity("GetTenantsDueForBackup", mock.Anything, mock.Anything).Return([]string{"tenant-1", "tenant-2"}, nil)
+	s.env.OnActivity("CreateScheduledBackups", mock.Anything, "tenant-1").Return([]string{"backup-1", "backup-2"}, nil)
+	s.env.OnActivity("CreateScheduledBackups", mock.Anything, "tenant-2").Return([]string{"backup-3"}, nil)
+	s.env.OnWorkflow(CreateBackupWorkflow, mock.Anything, mock.Anything).Return(nil).Times(3)
+
+	s.env.ExecuteWorkflow(RunScheduledBackupsWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *RunScheduledBackupsWorkflowTestSuite) TestTenantFails_ContinuesOthers() {
+	s.env.OnActivity("GetTenantsDueForBackup", mock.Anything, mock.Anything).Return([]string{"tenant-1", "tenant-2"}, nil)
+	s.env.OnActivity("CreateScheduledBackups", mock.Anything, "tenant-1").Return(nil, fmt.Errorf("db error"))
+	s.env.OnActivity("CreateScheduledBackups", mock.Anything, "tenant-2").Return([]string{"backup-3"}, nil)
+	s.env.OnWorkflow(CreateBackupWorkflow, mock.Anything, "backup-3").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(RunScheduledBackupsWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *RunScheduledBackupsWorkflowTestSuite) TestNoneDue() {
+	s.env.OnActivity("GetTenantsDueForBackup", mock.Anything, mock.Anything).Return([]string{}, nil)
+
+	s.env.ExecuteWorkflow(RunScheduledBackupsWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.env.AssertNotCalled(s.T(), "CreateScheduledBackups", mock.Anything, mock.Anything)
+}
+
+// ---------- VerifyRecentBackupsWorkflow ----------
+
+type VerifyRecentBackupsWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *VerifyRecentBackupsWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *VerifyRecentBackupsWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *VerifyRecentBackupsWorkflowTestSuite) TestVerifiesSample() {
+	backups := []model.Backup{
+		{ID: "backup-1", TenantID: "tenant-1"},
+		{ID: "backup-2", TenantID: "tenant-2"},
+	}
+
+	s.env.OnActivity("GetBackupsToVerify", mock.Anything, 5, 7).Return(backups, nil)
+	s.env.OnWorkflow(VerifyBackupWorkflow, mock.Anything, "backup-1").Return(fmt.Errorf("node down"))
+	s.env.OnWorkflow(VerifyBackupWorkflow, mock.Anything, "backup-2").Return(nil)
+
+	s.env.ExecuteWorkflow(VerifyRecentBackupsWorkflow, VerifyBackupsParams{SampleSize: 5, MaxAgeDays: 7})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *VerifyRecentBackupsWorkflowTestSuite) TestGetBackupsFails() {
+	s.env.OnActivity("GetBackupsToVerify", mock.Anything, 5, 7).Return(nil, fmt.Errorf("db error"))
+
+	s.env.ExecuteWorkflow(VerifyRecentBackupsWorkflow, VerifyBackupsParams{SampleSize: 5, MaxAgeDays: 7})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+// ---------- CleanupTenantExportsWorkflow ----------
+
+type CleanupTenantExportsWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *CleanupTenantExportsWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *CleanupTenantExportsWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *CleanupTenantExportsWorkflowTestSuite) TestSuccess() {
+	expired := []model.TenantExport{
+		{ID: "export-1", TenantID: "tenant-1", ObjectKey: "tenant-exports/tenant-1/export-1.tar.gz"},
+		{ID: "export-2", TenantID: "tenant-2"}, // failed before upload
+	}
+
+	s.env.OnActivity("GetExpiredTenantExports", mock.Anything).Return(expired, nil)
+	s.env.OnActivity("DeleteTenantExportObject", mock.Anything, "tenant-exports/tenant-1/export-1.tar.gz").Return(nil)
+	s.env.OnActivity("DeleteTenantExport", mock.Anything, "export-1").Return(nil)
+	s.env.OnActivity("DeleteTenantExport", mock.Anything, "export-2").Return(nil)
+
+	s.env.ExecuteWorkflow(CleanupTenantExportsWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CleanupTenantExportsWorkflowTestSuite) TestDeleteObjectFails_KeepsRecord() {
+	expired := []model.TenantExport{
+		{ID: "export-1", TenantID: "tenant-1", ObjectKey: "tenant-exports/tenant-1/export-1.tar.gz"},
+	}
+
+	s.env.OnActivity("GetExpiredTenantExports", mock.Anything).Return(expired, nil)
+	s.env.OnActivity("DeleteTenantExportObject", mock.Anything, mock.Anything).Return(fmt.Errorf("s3 unavailable"))
+
+	s.env.ExecuteWorkflow(CleanupTenantExportsWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
 // ---------- Run all suites ----------
 
+func TestVerifyRecentBackupsWorkflow(t *testing.T) {
+	suite.Run(t, new(VerifyRecentBackupsWorkflowTestSuite))
+}
+
 func TestCleanupAuditLogsWorkflow(t *testing.T) {
 	suite.Run(t, new(CleanupAuditLogsWorkflowTestSuite))
 }
@@ -119,3 +271,11 @@ func TestCleanupAuditLogsWorkflow(t *testing.T) {
 func TestCleanupOldBackupsWorkflow(t *testing.T) {
 	suite.Run(t, new(CleanupOldBackupsWorkflowTestSuite))
 }
+
+func TestRunScheduledBackupsWorkflow(t *testing.T) {
+	suite.Run(t, new(RunScheduledBackupsWorkflowTestSuite))
+}
+
+func TestCleanupTenantExportsWorkflow(t *testing.T) {
+	suite.Run(t, new(CleanupTenantExportsWorkflowTestSuite))
+}
@@ -18,8 +18,10 @@ type MigrateDatabaseParams struct {
 }
 
 // MigrateDatabaseWorkflow moves a database from one database shard to another
-// within the same cluster. It dumps data on the source node, imports on the
-// target node, migrates all database users, and updates the shard assignment.
+// within the same cluster. It streams mysqldump from the source node straight
+// into the target node, falling back to a gzipped dump file if the nodes are
+// in different clusters or the stream fails. It then migrates all database
+// users and updates the shard assignment.
 func MigrateDatabaseWorkflow(ctx workflow.Context, params MigrateDatabaseParams) error {
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 10 * time.Minute,
@@ -92,31 +94,47 @@ func MigrateDatabaseWorkflow(ctx workflow.Context, params MigrateDatabaseParams)
 
 	// Create the database on the target node.
 	targetCtx := nodeActivityCtx(ctx, targetNode.ID)
-	err = workflow.ExecuteActivity(targetCtx, "CreateDatabase", database.ID).Get(ctx, nil)
+	err = workflow.ExecuteActivity(targetCtx, "CreateDatabase", activity.CreateDatabaseParams{
+		Name:      database.ID,
+		Charset:   database.Charset,
+		Collation: database.Collation,
+	}).Get(ctx, nil)
 	if err != nil {
 		_ = setResourceFailed(ctx, "databases", databaseID, err)
 		return fmt.Errorf("create database on target node %s: %w", targetNode.ID, err)
 	}
 
-	// Dump the database on the source node.
 	sourceCtx := nodeActivityCtx(ctx, sourceNode.ID)
-	err = workflow.ExecuteActivity(sourceCtx, "DumpMySQLDatabase", activity.DumpMySQLDatabaseParams{
-		DatabaseName: database.ID,
-		DumpPath:     dumpPath,
-	}).Get(ctx, nil)
-	if err != nil {
-		_ = setResourceFailed(ctx, "databases", databaseID, err)
-		return fmt.Errorf("dump database on source node %s: %w", sourceNode.ID, err)
+
+	streamed := false
+	if sourceNode.ClusterID == targetNode.ClusterID && sourceNode.IPAddress != nil {
+		streamed, err = streamDatabase(ctx, database, sourceNode, targetNode)
+		if err != nil {
+			_ = setResourceFailed(ctx, "databases", databaseID, err)
+			return err
+		}
 	}
 
-	// Import the dump on the target node.
-	err = workflow.ExecuteActivity(targetCtx, "ImportMySQLDatabase", activity.ImportMySQLDatabaseParams{
-		DatabaseName: database.ID,
-		DumpPath:     dumpPath,
-	}).Get(ctx, nil)
-	if err != nil {
-		_ = setResourceFailed(ctx, "databases", databaseID, err)
-		return fmt.Errorf("import database on target node %s: %w", targetNode.ID, err)
+	if !streamed {
+		// Dump the database on the source node.
+		err = workflow.ExecuteActivity(sourceCtx, "DumpMySQLDatabase", activity.DumpMySQLDatabaseParams{
+			DatabaseName: database.ID,
+			DumpPath:     dumpPath,
+		}).Get(ctx, nil)
+		if err != nil {
+			_ = setResourceFailed(ctx, "databases", databaseID, err)
+			return fmt.Errorf("dump database on source node %s: %w", sourceNode.ID, err)
+		}
+
+		// Import the dump on the target node.
+		err = workflow.ExecuteActivity(targetCtx, "ImportMySQLDatabase", activity.ImportMySQLDatabaseParams{
+			DatabaseName: database.ID,
+			DumpPath:     dumpPath,
+		}).Get(ctx, nil)
+		if err != nil {
+			_ = setResourceFailed(ctx, "databases", databaseID, err)
+			return fmt.Errorf("import database on target node %s: %w", targetNode.ID, err)
+		}
 	}
 
 	// Migrate database users to the target node.
@@ -150,11 +168,13 @@ func MigrateDatabaseWorkflow(ctx workflow.Context, params MigrateDatabaseParams)
 	// Cleanup: drop database on source node (best effort).
 	_ = workflow.ExecuteActivity(sourceCtx, "DeleteDatabase", database.ID).Get(ctx, nil)
 
-	// Cleanup: remove dump file on source node (best effort).
-	_ = workflow.ExecuteActivity(sourceCtx, "CleanupMigrateFile", dumpPath).Get(ctx, nil)
+	if !streamed {
+		// Cleanup: remove dump file on source node (best effort).
+		_ = workflow.ExecuteActivity(sourceCtx, "CleanupMigrateFile", dumpPath).Get(ctx, nil)
 
-	// Cleanup: remove dump file on target node (best effort).
-	_ = workflow.ExecuteActivity(targetCtx, "CleanupMigrateFile", dumpPath).Get(ctx, nil)
+		// Cleanup: remove dump file on target node (best effort).
+		_ = workflow.ExecuteActivity(targetCtx, "CleanupMigrateFile", dumpPath).Get(ctx, nil)
+	}
 
 	// Set database status to active.
 	return workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
@@ -163,3 +183,56 @@ func MigrateDatabaseWorkflow(ctx workflow.Context, params MigrateDatabaseParams)
 		Status: model.StatusActive,
 	}).Get(ctx, nil)
 }
+
+// streamDatabase copies the database from sourceNode into the (empty)
+// database on targetNode over the node mesh. It reports false without an
+// error if the stream failed and the target database was reset, so the
+// caller can fall back to a dump file.
+func streamDatabase(ctx workflow.Context, database model.Database, sourceNode, targetNode model.Node) (bool, error) {
+	logger := workflow.GetLogger(ctx)
+	sourceCtx := nodeActivityCtx(ctx, sourceNode.ID)
+	targetCtx := nodeActivityCtx(ctx, targetNode.ID)
+
+	err := workflow.ExecuteActivity(sourceCtx, "GrantMySQLMigrationAccess", database.ID).Get(ctx, nil)
+	if err != nil {
+		logger.Warn("grant migration access failed, using dump file", "database", database.ID, "error", err)
+		return false, nil
+	}
+
+	// A failed stream is not retried: the target holds a partial import,
+	// and the dump file path is the fallback.
+	streamCtx := workflow.WithActivityOptions(targetCtx, workflow.ActivityOptions{
+		StartToCloseTimeout: 6 * time.Hour,
+		HeartbeatTimeout:    5 * time.Minute,
+		RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 1},
+	})
+	var result activity.StreamMySQLDatabaseResult
+	streamErr := workflow.ExecuteActivity(streamCtx, "StreamMySQLDatabase", activity.StreamMySQLDatabaseParams{
+		DatabaseName: database.ID,
+		SourceHost:   *sourceNode.IPAddress,
+	}).Get(ctx, &result)
+
+	// Best effort; the user expires on its own otherwise.
+	_ = workflow.ExecuteActivity(sourceCtx, "RevokeMySQLMigrationAccess", database.ID).Get(ctx, nil)
+
+	if streamErr == nil {
+		logger.Info("streamed database", "database", database.ID, "bytes", result.BytesTransferred)
+		return true, nil
+	}
+	logger.Warn("database stream failed, using dump file", "database", database.ID, "error", streamErr)
+
+	// Drop the partial import and start over with an empty database.
+	err = workflow.ExecuteActivity(targetCtx, "DeleteDatabase", database.ID).Get(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("drop partial database on target node %s: %w", targetNode.ID, err)
+	}
+	err = workflow.ExecuteActivity(targetCtx, "CreateDatabase", activity.CreateDatabaseParams{
+		Name:      database.ID,
+		Charset:   database.Charset,
+		Collation: database.Collation,
+	}).Get(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("recreate database on target node %s: %w", targetNode.ID, err)
+	}
+	return false, nil
+}
@@ -65,7 +65,7 @@ func (s *MigrateDatabaseWorkflowTestSuite) TestSuccess() {
 	s.env.OnActivity("ListNodesByShard", mock.Anything, targetShardID).Return(targetNodes, nil)
 
 	// Create database on target.
-	s.env.OnActivity("CreateDatabase", mock.Anything, databaseID).Return(nil)
+	s.env.OnActivity("CreateDatabase", mock.Anything, activity.CreateDatabaseParams{Name: databaseID}).Return(nil)
 
 	// Dump on source.
 	s.env.OnActivity("DumpMySQLDatabase", mock.Anything, activity.DumpMySQLDatabaseParams{
@@ -128,7 +128,7 @@ func (s *MigrateDatabaseWorkflowTestSuite) TestSuccessNoUsers() {
 	s.env.OnActivity("GetDatabaseByID", mock.Anything, databaseID).Return(&database, nil)
 	s.env.OnActivity("ListNodesByShard", mock.Anything, sourceShardID).Return(sourceNodes, nil)
 	s.env.OnActivity("ListNodesByShard", mock.Anything, targetShardID).Return(targetNodes, nil)
-	s.env.OnActivity("CreateDatabase", mock.Anything, databaseID).Return(nil)
+	s.env.OnActivity("CreateDatabase", mock.Anything, activity.CreateDatabaseParams{Name: databaseID}).Return(nil)
 	s.env.OnActivity("DumpMySQLDatabase", mock.Anything, activity.DumpMySQLDatabaseParams{
 		DatabaseName: databaseID,
 		DumpPath:     dumpPath,
@@ -212,7 +212,7 @@ func (s *MigrateDatabaseWorkflowTestSuite) TestDumpFails_SetsStatusFailed() {
 	s.env.OnActivity("GetDatabaseByID", mock.Anything, databaseID).Return(&database, nil)
 	s.env.OnActivity("ListNodesByShard", mock.Anything, sourceShardID).Return(sourceNodes, nil)
 	s.env.OnActivity("ListNodesByShard", mock.Anything, targetShardID).Return(targetNodes, nil)
-	s.env.OnActivity("CreateDatabase", mock.Anything, databaseID).Return(nil)
+	s.env.OnActivity("CreateDatabase", mock.Anything, activity.CreateDatabaseParams{Name: databaseID}).Return(nil)
 	s.env.OnActivity("DumpMySQLDatabase", mock.Anything, mock.Anything).Return(fmt.Errorf("mysqldump failed"))
 	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("databases", databaseID)).Return(nil)
 
@@ -244,7 +244,7 @@ func (s *MigrateDatabaseWorkflowTestSuite) TestImportFails_SetsStatusFailed() {
 	s.env.OnActivity("GetDatabaseByID", mock.Anything, databaseID).Return(&database, nil)
 	s.env.OnActivity("ListNodesByShard", mock.Anything, sourceShardID).Return(sourceNodes, nil)
 	s.env.OnActivity("ListNodesByShard", mock.Anything, targetShardID).Return(targetNodes, nil)
-	s.env.OnActivity("CreateDatabase", mock.Anything, databaseID).Return(nil)
+	s.env.OnActivity("CreateDatabase", mock.Anything, activity.CreateDatabaseParams{Name: databaseID}).Return(nil)
 	s.env.OnActivity("DumpMySQLDatabase", mock.Anything, activity.DumpMySQLDatabaseParams{
 		DatabaseName: databaseID,
 		DumpPath:     dumpPath,
@@ -275,6 +275,102 @@ func (s *MigrateDatabaseWorkflowTestSuite) TestSetProvisioningFails() {
 	s.Error(s.env.GetWorkflowError())
 }
 
+func (s *MigrateDatabaseWorkflowTestSuite) TestStreamsWithinCluster() {
+	databaseID := "test-db-8"
+	sourceShardID := "source-shard-8"
+	targetShardID := "target-shard-8"
+	sourceIP := "10.0.1.5"
+
+	database := model.Database{ID: databaseID, ShardID: &sourceShardID}
+	sourceNodes := []model.Node{{ID: "source-node-8", ClusterID: "cluster-1", IPAddress: &sourceIP}}
+	targetNodes := []model.Node{{ID: "target-node-8", ClusterID: "cluster-1"}}
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "databases", ID: databaseID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetDatabaseByID", mock.Anything, databaseID).Return(&database, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, sourceShardID).Return(sourceNodes, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, targetShardID).Return(targetNodes, nil)
+	s.env.OnActivity("CreateDatabase", mock.Anything, activity.CreateDatabaseParams{Name: databaseID}).Return(nil).Once()
+
+	s.env.OnActivity("GrantMySQLMigrationAccess", mock.Anything, databaseID).Return(nil)
+	s.env.OnActivity("StreamMySQLDatabase", mock.Anything, activity.StreamMySQLDatabaseParams{
+		DatabaseName: databaseID,
+		SourceHost:   sourceIP,
+	}).Return(&activity.StreamMySQLDatabaseResult{BytesTransferred: 1 << 30}, nil)
+	s.env.OnActivity("RevokeMySQLMigrationAccess", mock.Anything, databaseID).Return(nil)
+
+	var emptyUsers []model.DatabaseUser
+	s.env.OnActivity("ListDatabaseUsersByDatabaseID", mock.Anything, databaseID).Return(emptyUsers, nil)
+	s.env.OnActivity("UpdateDatabaseShardID", mock.Anything, databaseID, targetShardID).Return(nil)
+	s.env.OnActivity("DeleteDatabase", mock.Anything, databaseID).Return(nil).Once()
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "databases", ID: databaseID, Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(MigrateDatabaseWorkflow, MigrateDatabaseParams{
+		DatabaseID:    databaseID,
+		TargetShardID: targetShardID,
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.env.AssertNotCalled(s.T(), "DumpMySQLDatabase", mock.Anything, mock.Anything)
+	s.env.AssertNotCalled(s.T(), "CleanupMigrateFile", mock.Anything, mock.Anything)
+}
+
+func (s *MigrateDatabaseWorkflowTestSuite) TestStreamFails_FallsBackToDumpFile() {
+	databaseID := "test-db-9"
+	sourceShardID := "source-shard-9"
+	targetShardID := "target-shard-9"
+	sourceIP := "10.0.1.5"
+
+	database := model.Database{ID: databaseID, ShardID: &sourceShardID}
+	sourceNodes := []model.Node{{ID: "source-node-9", ClusterID: "cluster-1", IPAddress: &sourceIP}}
+	targetNodes := []model.Node{{ID: "target-node-9", ClusterID: "cluster-1"}}
+	dumpPath := fmt.Sprintf("/var/backups/hosting/migrate/%s.sql.gz", database.ID)
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "databases", ID: databaseID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetDatabaseByID", mock.Anything, databaseID).Return(&database, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, sourceShardID).Return(sourceNodes, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, targetShardID).Return(targetNodes, nil)
+
+	s.env.OnActivity("GrantMySQLMigrationAccess", mock.Anything, databaseID).Return(nil)
+	s.env.OnActivity("StreamMySQLDatabase", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("mysqldump from 10.0.1.5 failed after 4096 bytes")).Once()
+	s.env.OnActivity("RevokeMySQLMigrationAccess", mock.Anything, databaseID).Return(nil)
+
+	// The partial import is dropped and the database recreated: created
+	// twice, and deleted on the target plus on the source at cleanup.
+	s.env.OnActivity("CreateDatabase", mock.Anything, activity.CreateDatabaseParams{Name: databaseID}).Return(nil).Twice()
+	s.env.OnActivity("DeleteDatabase", mock.Anything, databaseID).Return(nil).Twice()
+
+	s.env.OnActivity("DumpMySQLDatabase", mock.Anything, activity.DumpMySQLDatabaseParams{
+		DatabaseName: databaseID,
+		DumpPath:     dumpPath,
+	}).Return(nil)
+	s.env.OnActivity("ImportMySQLDatabase", mock.Anything, activity.ImportMySQLDatabaseParams{
+		DatabaseName: databaseID,
+		DumpPath:     dumpPath,
+	}).Return(nil)
+
+	var emptyUsers []model.DatabaseUser
+	s.env.OnActivity("ListDatabaseUsersByDatabaseID", mock.Anything, databaseID).Return(emptyUsers, nil)
+	s.env.OnActivity("UpdateDatabaseShardID", mock.Anything, databaseID, targetShardID).Return(nil)
+	s.env.OnActivity("CleanupMigrateFile", mock.Anything, dumpPath).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "databases", ID: databaseID, Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(MigrateDatabaseWorkflow, MigrateDatabaseParams{
+		DatabaseID:    databaseID,
+		TargetShardID: targetShardID,
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
 // ---------- Run all suites ----------
 
 func TestMigrateDatabaseWorkflow(t *testing.T) {
@@ -12,8 +12,84 @@ import (
 	"github.com/edvin/hosting/internal/model"
 )
 
+// TenantMigrationQuery is the workflow query name that returns the current
+// model.TenantMigration state of a MigrateTenantWorkflow.
+const TenantMigrationQuery = "tenant-migration"
+
+// migrationTracker records MigrateTenantWorkflow progress for the
+// TenantMigrationQuery query.
+type migrationTracker struct {
+	ctx   workflow.Context
+	state model.TenantMigration
+}
+
+func (t *migrationTracker) enter(phase string) {
+	t.state.Phase = phase
+}
+
+// track registers a pending step so the query shows the full plan up front.
+func (t *migrationTracker) track(phase, resourceType, resourceID, nodeID string) {
+	t.state.Steps = append(t.state.Steps, model.TenantMigrationStep{
+		Phase:        phase,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		NodeID:       nodeID,
+		Status:       model.MigrationStepPending,
+	})
+}
+
+// mark updates the status of a tracked step; err is recorded on failure.
+func (t *migrationTracker) mark(resourceType, resourceID, nodeID, status string, err error) {
+	for i := range t.state.Steps {
+		s := &t.state.Steps[i]
+		if s.Phase == t.state.Phase && s.ResourceType == resourceType && s.ResourceID == resourceID && s.NodeID == nodeID {
+			s.Status = status
+			if err != nil {
+				s.Error = err.Error()
+			}
+			return
+		}
+	}
+}
+
+// finish marks a step done, or failed if err is set.
+func (t *migrationTracker) finish(resourceType, resourceID, nodeID string, err error) {
+	if err != nil {
+		t.mark(resourceType, resourceID, nodeID, model.MigrationStepFailed, err)
+		return
+	}
+	t.mark(resourceType, resourceID, nodeID, model.MigrationStepDone, nil)
+}
+
+// fail records the failed phase and where the tenant is left, marks the
+// tenant failed, and returns an error saying both.
+func (t *migrationTracker) fail(err error) error {
+	var where string
+	switch t.state.Location {
+	case model.MigrationLocationTarget:
+		where = "tenant is served from the target shard"
+	case model.MigrationLocationSplit:
+		where = "some FQDNs already route to the target shard"
+	default:
+		where = "tenant is still served from the source shard"
+	}
+	err = fmt.Errorf("migration failed in %s phase (%s): %w", t.state.Phase, where, err)
+
+	now := workflow.Now(t.ctx)
+	t.state.Status = model.MigrationFailed
+	t.state.FailedPhase = t.state.Phase
+	t.state.Error = err.Error()
+	t.state.FinishedAt = &now
+	_ = setResourceFailed(t.ctx, "tenants", t.state.TenantID, err)
+	return err
+}
+
 // MigrateTenantWorkflow moves a tenant from its current shard to a target shard
-// within the same cluster.
+// within the same cluster. It runs in four phases — snapshot (read the tenant
+// and its resources), transfer (provision on the target nodes), cutover
+// (switch LB routing and the shard assignment) and cleanup (remove from the
+// source nodes) — and reports per-step progress via TenantMigrationQuery.
+// The tenant stays in the migrating status until the workflow ends.
 func MigrateTenantWorkflow(ctx workflow.Context, params core.MigrateTenantParams) error {
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 5 * time.Minute,
@@ -28,69 +104,143 @@ func MigrateTenantWorkflow(ctx workflow.Context, params core.MigrateTenantParams
 
 	tenantID := params.TenantID
 
-	// Set tenant status to provisioning.
+	tr := &migrationTracker{ctx: ctx, state: model.TenantMigration{
+		TenantID:      tenantID,
+		TargetShardID: params.TargetShardID,
+		Status:        model.MigrationRunning,
+		Phase:         model.MigrationPhaseSnapshot,
+		Location:      model.MigrationLocationSource,
+		Steps:         []model.TenantMigrationStep{},
+		StartedAt:     workflow.Now(ctx),
+	}}
+	if err := workflow.SetQueryHandler(ctx, TenantMigrationQuery, func() (model.TenantMigration, error) {
+		return tr.state, nil
+	}); err != nil {
+		return fmt.Errorf("set query handler: %w", err)
+	}
+
+	// ---------- snapshot ----------
+
+	// Set tenant status to migrating.
 	err := workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
 		Table:  "tenants",
 		ID:     tenantID,
-		Status: model.StatusProvisioning,
+		Status: model.StatusMigrating,
 	}).Get(ctx, nil)
 	if err != nil {
-		return err
+		return tr.fail(err)
 	}
 
 	// Get the tenant.
 	var tenant model.Tenant
 	err = workflow.ExecuteActivity(ctx, "GetTenantByID", tenantID).Get(ctx, &tenant)
 	if err != nil {
-		_ = setResourceFailed(ctx, "tenants", tenantID, err)
-		return err
+		return tr.fail(err)
 	}
 
 	if tenant.ShardID == nil {
-		noShardErr := fmt.Errorf("tenant %s has no current shard assignment", tenantID)
-		_ = setResourceFailed(ctx, "tenants", tenantID, noShardErr)
-		return noShardErr
+		return tr.fail(fmt.Errorf("tenant %s has no current shard assignment", tenantID))
 	}
+	tr.state.SourceShardID = *tenant.ShardID
 
 	// Get source and target shards.
 	var sourceShard model.Shard
 	err = workflow.ExecuteActivity(ctx, "GetShardByID", *tenant.ShardID).Get(ctx, &sourceShard)
 	if err != nil {
-		_ = setResourceFailed(ctx, "tenants", tenantID, err)
-		return err
+		return tr.fail(err)
 	}
 
 	var targetShard model.Shard
 	err = workflow.ExecuteActivity(ctx, "GetShardByID", params.TargetShardID).Get(ctx, &targetShard)
 	if err != nil {
-		_ = setResourceFailed(ctx, "tenants", tenantID, err)
-		return err
+		return tr.fail(err)
 	}
 
 	// Validate: same cluster.
 	if sourceShard.ClusterID != targetShard.ClusterID {
-		clusterErr := fmt.Errorf("source shard cluster %s != target shard cluster %s", sourceShard.ClusterID, targetShard.ClusterID)
-		_ = setResourceFailed(ctx, "tenants", tenantID, clusterErr)
-		return clusterErr
+		return tr.fail(fmt.Errorf("source shard cluster %s != target shard cluster %s", sourceShard.ClusterID, targetShard.ClusterID))
 	}
 
 	// Validate: target shard is a web shard.
 	if targetShard.Role != model.ShardRoleWeb {
-		roleErr := fmt.Errorf("target shard %s is not a web shard (role: %s)", targetShard.ID, targetShard.Role)
-		_ = setResourceFailed(ctx, "tenants", tenantID, roleErr)
-		return roleErr
+		return tr.fail(fmt.Errorf("target shard %s is not a web shard (role: %s)", targetShard.ID, targetShard.Role))
 	}
 
-	// Get target shard nodes.
+	// Lower the TTL of the tenant's address records before the LB switch so
+	// resolvers pick up the new backend quickly; the wait for the old TTL to
+	// expire overlaps with provisioning the target nodes.
+	var ttlLoweredAt time.Time
+	var previousTTL int
+	if params.MigrateFQDNs {
+		err = workflow.ExecuteActivity(ctx, "LowerTenantAddressRecordTTLs", tenantID).Get(ctx, &previousTTL)
+		if err != nil {
+			return tr.fail(fmt.Errorf("lower address record TTLs: %w", err))
+		}
+		ttlLoweredAt = workflow.Now(ctx)
+		defer func() {
+			_ = workflow.ExecuteActivity(ctx, "RestoreTenantAddressRecordTTLs", tenantID).Get(ctx, nil)
+		}()
+	}
+
+	// Get target and source shard nodes.
 	var targetNodes []model.Node
 	err = workflow.ExecuteActivity(ctx, "ListNodesByShard", params.TargetShardID).Get(ctx, &targetNodes)
 	if err != nil {
-		_ = setResourceFailed(ctx, "tenants", tenantID, err)
-		return err
+		return tr.fail(err)
 	}
 
+	var sourceNodes []model.Node
+	err = workflow.ExecuteActivity(ctx, "ListNodesByShard", *tenant.ShardID).Get(ctx, &sourceNodes)
+	if err != nil {
+		return tr.fail(err)
+	}
+
+	// Get webroots and their FQDNs. Mutations are rejected while the tenant
+	// is migrating, so this snapshot stays valid for the whole migration.
+	var webroots []model.Webroot
+	err = workflow.ExecuteActivity(ctx, "ListWebrootsByTenantID", tenantID).Get(ctx, &webroots)
+	if err != nil {
+		return tr.fail(err)
+	}
+
+	webrootFQDNs := make(map[string][]model.FQDN, len(webroots))
+	for _, webroot := range webroots {
+		var fqdns []model.FQDN
+		err = workflow.ExecuteActivity(ctx, "GetFQDNsByWebrootID", webroot.ID).Get(ctx, &fqdns)
+		if err != nil {
+			return tr.fail(err)
+		}
+		webrootFQDNs[webroot.ID] = fqdns
+	}
+
+	// Register every step of the remaining phases.
+	for _, node := range targetNodes {
+		tr.track(model.MigrationPhaseTransfer, "tenant", tenant.ID, node.ID)
+		for _, webroot := range webroots {
+			tr.track(model.MigrationPhaseTransfer, "webroot", webroot.ID, node.ID)
+		}
+	}
+	if params.MigrateFQDNs {
+		for _, webroot := range webroots {
+			for _, fqdn := range webrootFQDNs[webroot.ID] {
+				tr.track(model.MigrationPhaseCutover, "fqdn", fqdn.FQDN, "")
+			}
+		}
+	}
+	tr.track(model.MigrationPhaseCutover, "tenant", tenant.ID, "")
+	for _, node := range sourceNodes {
+		for _, webroot := range webroots {
+			tr.track(model.MigrationPhaseCleanup, "webroot", webroot.ID, node.ID)
+		}
+		tr.track(model.MigrationPhaseCleanup, "tenant", tenant.ID, node.ID)
+	}
+
+	// ---------- transfer ----------
+	tr.enter(model.MigrationPhaseTransfer)
+
 	// Provision tenant on each target node.
 	for _, node := range targetNodes {
+		tr.mark("tenant", tenant.ID, node.ID, model.MigrationStepRunning, nil)
 		nodeCtx := nodeActivityCtx(ctx, node.ID)
 		err = workflow.ExecuteActivity(nodeCtx, "CreateTenant", activity.CreateTenantParams{
 			ID:             tenant.ID,
@@ -100,29 +250,15 @@ func MigrateTenantWorkflow(ctx workflow.Context, params core.MigrateTenantParams
 			SSHEnabled:     tenant.SSHEnabled,
 			DiskQuotaBytes: tenant.DiskQuotaBytes,
 		}).Get(ctx, nil)
+		tr.finish("tenant", tenant.ID, node.ID, err)
 		if err != nil {
-			_ = setResourceFailed(ctx, "tenants", tenantID, err)
-			return fmt.Errorf("create tenant on node %s: %w", node.ID, err)
+			return tr.fail(fmt.Errorf("create tenant on node %s: %w", node.ID, err))
 		}
 	}
 
 	// Provision webroots on target nodes.
-	var webroots []model.Webroot
-	err = workflow.ExecuteActivity(ctx, "ListWebrootsByTenantID", tenantID).Get(ctx, &webroots)
-	if err != nil {
-		_ = setResourceFailed(ctx, "tenants", tenantID, err)
-		return err
-	}
-
 	for _, webroot := range webroots {
-		// Get FQDNs for the webroot.
-		var fqdns []model.FQDN
-		err = workflow.ExecuteActivity(ctx, "GetFQDNsByWebrootID", webroot.ID).Get(ctx, &fqdns)
-		if err != nil {
-			_ = setResourceFailed(ctx, "tenants", tenantID, err)
-			return err
-		}
-
+		fqdns := webrootFQDNs[webroot.ID]
 		fqdnParams := make([]activity.FQDNParam, len(fqdns))
 		for i, f := range fqdns {
 			var webrootID string
@@ -130,13 +266,18 @@ func MigrateTenantWorkflow(ctx workflow.Context, params core.MigrateTenantParams
 				webrootID = *f.WebrootID
 			}
 			fqdnParams[i] = activity.FQDNParam{
-				FQDN:       f.FQDN,
-				WebrootID:  webrootID,
-				SSLEnabled: f.SSLEnabled,
+				FQDN:                 f.FQDN,
+				WebrootID:            webrootID,
+				SSLEnabled:           f.SSLEnabled,
+				ForceHTTPS:           f.ForceHTTPS,
+				RedirectTarget:       f.RedirectTarget,
+				RedirectCode:         f.RedirectCode,
+				RedirectPreservePath: f.RedirectPreservePath,
 			}
 		}
 
 		for _, node := range targetNodes {
+			tr.mark("webroot", webroot.ID, node.ID, model.MigrationStepRunning, nil)
 			nodeCtx := nodeActivityCtx(ctx, node.ID)
 			err = workflow.ExecuteActivity(nodeCtx, "CreateWebroot", activity.CreateWebrootParams{
 				ID:             webroot.ID,
@@ -146,64 +287,89 @@ func MigrateTenantWorkflow(ctx workflow.Context, params core.MigrateTenantParams
 				RuntimeVersion: webroot.RuntimeVersion,
 				RuntimeConfig:  string(webroot.RuntimeConfig),
 				PublicFolder:   webroot.PublicFolder,
+				ErrorPages:     webroot.ErrorPages,
+				BasicAuth:      webroot.BasicAuth,
+				AccessLog:      webroot.AccessLogEnabled,
+				Limits:         webroot.ConnectionLimits,
+				StaticRules:    webroot.StaticRules,
+				GeoBlocking:    webroot.GeoBlocking,
+				DeployLock:     webroot.DeployLockedUntil,
 				FQDNs:          fqdnParams,
 			}).Get(ctx, nil)
+			tr.finish("webroot", webroot.ID, node.ID, err)
 			if err != nil {
-				_ = setResourceFailed(ctx, "tenants", tenantID, err)
-				return fmt.Errorf("create webroot %s on node %s: %w", webroot.ID, node.ID, err)
+				return tr.fail(fmt.Errorf("create webroot %s on node %s: %w", webroot.ID, node.ID, err))
 			}
 		}
 	}
 
+	// ---------- cutover ----------
+	tr.enter(model.MigrationPhaseCutover)
+
 	// Update LB map entries if requested.
 	if params.MigrateFQDNs {
-		for _, webroot := range webroots {
-			var fqdns []model.FQDN
-			_ = workflow.ExecuteActivity(ctx, "GetFQDNsByWebrootID", webroot.ID).Get(ctx, &fqdns)
+		if wait := ttlLoweredAt.Add(time.Duration(previousTTL) * time.Second).Sub(workflow.Now(ctx)); wait > 0 {
+			if err := workflow.Sleep(ctx, wait); err != nil {
+				return err
+			}
+		}
 
-			for _, fqdn := range fqdns {
+		for _, webroot := range webroots {
+			for _, fqdn := range webrootFQDNs[webroot.ID] {
+				tr.mark("fqdn", fqdn.FQDN, "", model.MigrationStepRunning, nil)
 				err = workflow.ExecuteActivity(ctx, "SetLBMapEntry", activity.SetLBMapEntryParams{
 					FQDN:      fqdn.FQDN,
 					LBBackend: targetShard.LBBackend,
 				}).Get(ctx, nil)
+				tr.finish("fqdn", fqdn.FQDN, "", err)
 				if err != nil {
-					_ = setResourceFailed(ctx, "tenants", tenantID, err)
-					return fmt.Errorf("update LB map for %s: %w", fqdn.FQDN, err)
+					return tr.fail(fmt.Errorf("update LB map for %s: %w", fqdn.FQDN, err))
 				}
+				tr.state.Location = model.MigrationLocationSplit
 			}
 		}
 	}
 
 	// Update tenant shard assignment in core DB.
+	tr.mark("tenant", tenant.ID, "", model.MigrationStepRunning, nil)
 	err = workflow.ExecuteActivity(ctx, "UpdateTenantShardID", tenantID, params.TargetShardID).Get(ctx, nil)
+	tr.finish("tenant", tenant.ID, "", err)
 	if err != nil {
-		_ = setResourceFailed(ctx, "tenants", tenantID, err)
-		return err
+		return tr.fail(err)
 	}
+	tr.state.Location = model.MigrationLocationTarget
 
-	// Cleanup: remove tenant from source shard nodes.
-	var sourceNodes []model.Node
-	err = workflow.ExecuteActivity(ctx, "ListNodesByShard", *tenant.ShardID).Get(ctx, &sourceNodes)
-	if err != nil {
-		// Non-fatal: tenant is already migrated, just log cleanup failure.
-		_ = setResourceFailed(ctx, "tenants", tenantID, err)
-		return err
-	}
+	// ---------- cleanup ----------
+	tr.enter(model.MigrationPhaseCleanup)
 
+	// Remove tenant from source shard nodes. Failures leave files behind on
+	// the source but do not affect the migrated tenant.
 	for _, node := range sourceNodes {
 		nodeCtx := nodeActivityCtx(ctx, node.ID)
 		// Delete webroots from source nodes.
 		for _, webroot := range webroots {
-			_ = workflow.ExecuteActivity(nodeCtx, "DeleteWebroot", tenant.ID, webroot.ID).Get(ctx, nil)
+			tr.mark("webroot", webroot.ID, node.ID, model.MigrationStepRunning, nil)
+			err := workflow.ExecuteActivity(nodeCtx, "DeleteWebroot", tenant.ID, webroot.ID).Get(ctx, nil)
+			tr.finish("webroot", webroot.ID, node.ID, err)
 		}
 		// Delete tenant from source nodes.
-		_ = workflow.ExecuteActivity(nodeCtx, "DeleteTenant", tenant.ID).Get(ctx, nil)
+		tr.mark("tenant", tenant.ID, node.ID, model.MigrationStepRunning, nil)
+		err := workflow.ExecuteActivity(nodeCtx, "DeleteTenant", tenant.ID).Get(ctx, nil)
+		tr.finish("tenant", tenant.ID, node.ID, err)
 	}
 
 	// Set tenant status to active.
-	return workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+	err = workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
 		Table:  "tenants",
 		ID:     tenantID,
 		Status: model.StatusActive,
 	}).Get(ctx, nil)
+	if err != nil {
+		return tr.fail(err)
+	}
+
+	now := workflow.Now(ctx)
+	tr.state.Status = model.MigrationCompleted
+	tr.state.FinishedAt = &now
+	return nil
 }
@@ -0,0 +1,180 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ---------- MigrateTenantWorkflow ----------
+
+type MigrateTenantWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *MigrateTenantWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *MigrateTenantWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *MigrateTenantWorkflowTestSuite) expectShards() {
+	sourceShardID := "source-shard-1"
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "tenants", ID: "test-tenant-1", Status: model.StatusMigrating,
+	}).Return(nil)
+	s.env.OnActivity("GetTenantByID", mock.Anything, "test-tenant-1").Return(&model.Tenant{
+		ID: "test-tenant-1", ShardID: &sourceShardID,
+	}, nil)
+	s.env.OnActivity("GetShardByID", mock.Anything, "source-shard-1").Return(&model.Shard{
+		ID: "source-shard-1", ClusterID: "cluster-1", Role: model.ShardRoleWeb,
+	}, nil)
+	s.env.OnActivity("GetShardByID", mock.Anything, "target-shard-1").Return(&model.Shard{
+		ID: "target-shard-1", ClusterID: "cluster-1", Role: model.ShardRoleWeb, LBBackend: "web-2",
+	}, nil)
+}
+
+func (s *MigrateTenantWorkflowTestSuite) TestMigrateFQDNs_LowersAndRestoresTTLs() {
+	s.expectShards()
+	var lowered, switched time.Time
+
+	s.env.OnActivity("LowerTenantAddressRecordTTLs", mock.Anything, "test-tenant-1").Return(300, nil).Run(func(args mock.Arguments) {
+		lowered = s.env.Now()
+	}).Once()
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "target-shard-1").Return([]model.Node{{ID: "target-node-1"}}, nil)
+	s.env.OnActivity("CreateTenant", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("ListWebrootsByTenantID", mock.Anything, "test-tenant-1").Return([]model.Webroot{{ID: "webroot-1"}}, nil)
+	s.env.OnActivity("GetFQDNsByWebrootID", mock.Anything, "webroot-1").Return([]model.FQDN{{FQDN: "example.com"}}, nil)
+	s.env.OnActivity("CreateWebroot", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("SetLBMapEntry", mock.Anything, activity.SetLBMapEntryParams{
+		FQDN: "example.com", LBBackend: "web-2",
+	}).Return(nil).Run(func(args mock.Arguments) {
+		switched = s.env.Now()
+	})
+	s.env.OnActivity("UpdateTenantShardID", mock.Anything, "test-tenant-1", "target-shard-1").Return(nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "source-shard-1").Return([]model.Node{{ID: "source-node-1"}}, nil)
+	s.env.OnActivity("DeleteWebroot", mock.Anything, "test-tenant-1", "webroot-1").Return(nil)
+	s.env.OnActivity("DeleteTenant", mock.Anything, "test-tenant-1").Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "tenants", ID: "test-tenant-1", Status: model.StatusActive,
+	}).Return(nil)
+	s.env.OnActivity("RestoreTenantAddressRecordTTLs", mock.Anything, "test-tenant-1").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(MigrateTenantWorkflow, core.MigrateTenantParams{
+		TenantID:      "test-tenant-1",
+		TargetShardID: "target-shard-1",
+		MigrateFQDNs:  true,
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.GreaterOrEqual(switched.Sub(lowered), 300*time.Second)
+}
+
+func (s *MigrateTenantWorkflowTestSuite) TestMigrateFQDNs_RestoresTTLsOnFailure() {
+	s.expectShards()
+
+	s.env.OnActivity("LowerTenantAddressRecordTTLs", mock.Anything, "test-tenant-1").Return(300, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "target-shard-1").Return(nil, errors.New("db down"))
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, mock.MatchedBy(func(p activity.UpdateResourceStatusParams) bool {
+		return p.Status == model.StatusFailed
+	})).Return(nil)
+	s.env.OnActivity("RestoreTenantAddressRecordTTLs", mock.Anything, "test-tenant-1").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(MigrateTenantWorkflow, core.MigrateTenantParams{
+		TenantID:      "test-tenant-1",
+		TargetShardID: "target-shard-1",
+		MigrateFQDNs:  true,
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "snapshot phase")
+	s.Contains(s.env.GetWorkflowError().Error(), "still served from the source shard")
+}
+
+func (s *MigrateTenantWorkflowTestSuite) TestLBSwitchFails_ReportsSplitCutover() {
+	s.expectShards()
+
+	s.env.OnActivity("LowerTenantAddressRecordTTLs", mock.Anything, "test-tenant-1").Return(0, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "target-shard-1").Return([]model.Node{{ID: "target-node-1"}}, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "source-shard-1").Return([]model.Node{{ID: "source-node-1"}}, nil)
+	s.env.OnActivity("ListWebrootsByTenantID", mock.Anything, "test-tenant-1").Return([]model.Webroot{{ID: "webroot-1"}}, nil)
+	s.env.OnActivity("GetFQDNsByWebrootID", mock.Anything, "webroot-1").Return([]model.FQDN{{FQDN: "a.example.com"}, {FQDN: "b.example.com"}}, nil)
+	s.env.OnActivity("CreateTenant", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("CreateWebroot", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("SetLBMapEntry", mock.Anything, activity.SetLBMapEntryParams{FQDN: "a.example.com", LBBackend: "web-2"}).Return(nil)
+	s.env.OnActivity("SetLBMapEntry", mock.Anything, activity.SetLBMapEntryParams{FQDN: "b.example.com", LBBackend: "web-2"}).Return(errors.New("haproxy unreachable"))
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("tenants", "test-tenant-1")).Return(nil)
+	s.env.OnActivity("RestoreTenantAddressRecordTTLs", mock.Anything, "test-tenant-1").Return(nil)
+
+	s.env.ExecuteWorkflow(MigrateTenantWorkflow, core.MigrateTenantParams{
+		TenantID:      "test-tenant-1",
+		TargetShardID: "target-shard-1",
+		MigrateFQDNs:  true,
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+
+	val, err := s.env.QueryWorkflow(TenantMigrationQuery)
+	s.Require().NoError(err)
+	var m model.TenantMigration
+	s.Require().NoError(val.Get(&m))
+	s.Equal(model.MigrationFailed, m.Status)
+	s.Equal(model.MigrationPhaseCutover, m.FailedPhase)
+	s.Equal(model.MigrationLocationSplit, m.Location)
+	s.Equal("source-shard-1", m.SourceShardID)
+
+	statuses := map[string]string{}
+	for _, step := range m.Steps {
+		statuses[step.Phase+"/"+step.ResourceType+"/"+step.ResourceID+"/"+step.NodeID] = step.Status
+	}
+	s.Equal(model.MigrationStepDone, statuses["transfer/webroot/webroot-1/target-node-1"])
+	s.Equal(model.MigrationStepDone, statuses["cutover/fqdn/a.example.com/"])
+	s.Equal(model.MigrationStepFailed, statuses["cutover/fqdn/b.example.com/"])
+	s.Equal(model.MigrationStepPending, statuses["cleanup/tenant/test-tenant-1/source-node-1"])
+}
+
+func (s *MigrateTenantWorkflowTestSuite) TestWithoutFQDNs_KeepsTTLs() {
+	s.expectShards()
+
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "target-shard-1").Return([]model.Node{}, nil)
+	s.env.OnActivity("ListWebrootsByTenantID", mock.Anything, "test-tenant-1").Return([]model.Webroot{}, nil)
+	s.env.OnActivity("UpdateTenantShardID", mock.Anything, "test-tenant-1", "target-shard-1").Return(nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "source-shard-1").Return([]model.Node{}, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "tenants", ID: "test-tenant-1", Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(MigrateTenantWorkflow, core.MigrateTenantParams{
+		TenantID:      "test-tenant-1",
+		TargetShardID: "target-shard-1",
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	val, err := s.env.QueryWorkflow(TenantMigrationQuery)
+	s.Require().NoError(err)
+	var m model.TenantMigration
+	s.Require().NoError(val.Get(&m))
+	s.Equal(model.MigrationCompleted, m.Status)
+	s.Equal(model.MigrationLocationTarget, m.Location)
+	s.NotNil(m.FinishedAt)
+}
+
+// ---------- Run all suites ----------
+
+func TestMigrateTenantWorkflow(t *testing.T) {
+	suite.Run(t, new(MigrateTenantWorkflowTestSuite))
+}
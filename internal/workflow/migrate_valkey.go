@@ -94,10 +94,11 @@ func MigrateValkeyInstanceWorkflow(ctx workflow.Context, params MigrateValkeyIns
 	// Create the instance on the target node.
 	targetCtx := nodeActivityCtx(ctx, targetNode.ID)
 	err = workflow.ExecuteActivity(targetCtx, "CreateValkeyInstance", activity.CreateValkeyInstanceParams{
-		Name:         instance.ID,
-		Port:         instance.Port,
-		PasswordHash: instance.PasswordHash,
-		MaxMemoryMB:  instance.MaxMemoryMB,
+		Name:            instance.ID,
+		Port:            instance.Port,
+		PasswordHash:    instance.PasswordHash,
+		MaxMemoryMB:     instance.MaxMemoryMB,
+		PersistenceMode: instance.PersistenceMode,
 	}).Get(ctx, nil)
 	if err != nil {
 		_ = setResourceFailed(ctx, "valkey_instances", instanceID, err)
@@ -0,0 +1,88 @@
+package workflow
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// CollectNodeCapabilitiesWorkflow runs on a cron schedule and stores the agent
+// version and registered activities of every active node in
+// node_capabilities. An unreachable node keeps its previous report.
+func CollectNodeCapabilitiesWorkflow(ctx workflow.Context) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 2,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+	logger := workflow.GetLogger(ctx)
+
+	var nodes []model.Node
+	err := workflow.ExecuteActivity(ctx, "ListActiveNodes").Get(ctx, &nodes)
+	if err != nil {
+		return fmt.Errorf("list active nodes: %w", err)
+	}
+
+	fanOutNodes(ctx, nodes, func(gCtx workflow.Context, node model.Node) error {
+		nodeCtx := workflow.WithActivityOptions(nodeActivityCtx(gCtx, node.ID), workflow.ActivityOptions{
+			StartToCloseTimeout: 15 * time.Second,
+			RetryPolicy: &temporal.RetryPolicy{
+				MaximumAttempts: 1,
+			},
+		})
+
+		var caps model.NodeCapabilities
+		if err := workflow.ExecuteActivity(nodeCtx, "ReportNodeCapabilities").Get(gCtx, &caps); err != nil {
+			logger.Warn("failed to collect node capabilities", "node", node.ID, "hostname", node.Hostname, "error", err)
+			return nil
+		}
+		caps.NodeID = node.ID
+
+		if err := workflow.ExecuteActivity(gCtx, "UpsertNodeCapabilities", caps).Get(gCtx, nil); err != nil {
+			logger.Warn("failed to store node capabilities", "node", node.ID, "error", err)
+		}
+		return nil
+	})
+
+	return nil
+}
+
+// nodesSupporting drops the nodes whose agent last reported that it does not
+// register activityName, so a rolling agent upgrade does not schedule work
+// that fails with "activity not registered". Nodes that have not reported are
+// kept, and if the lookup fails all nodes are returned.
+func nodesSupporting(ctx workflow.Context, nodes []model.Node, activityName string) []model.Node {
+	ids := make([]string, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+
+	var missing []string
+	err := workflow.ExecuteActivity(ctx, "ListNodesMissingActivity", activity.ListNodesMissingActivityParams{
+		NodeIDs:  ids,
+		Activity: activityName,
+	}).Get(ctx, &missing)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("failed to check node capabilities", "activity", activityName, "error", err)
+		return nodes
+	}
+	if len(missing) == 0 {
+		return nodes
+	}
+
+	var supported []model.Node
+	for _, n := range nodes {
+		if !slices.Contains(missing, n.ID) {
+			supported = append(supported, n)
+		}
+	}
+	return supported
+}
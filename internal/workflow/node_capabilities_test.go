@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+type CollectNodeCapabilitiesWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *CollectNodeCapabilitiesWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *CollectNodeCapabilitiesWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *CollectNodeCapabilitiesWorkflowTestSuite) TestStoresCapabilities() {
+	s.env.OnActivity("ListActiveNodes", mock.Anything).
+		Return([]model.Node{{ID: "node-1"}}, nil)
+	s.env.OnActivity("ReportNodeCapabilities", mock.Anything).
+		Return(&model.NodeCapabilities{AgentVersion: "1.4.0", Activities: []string{"CreateWebroot"}}, nil)
+	s.env.OnActivity("UpsertNodeCapabilities", mock.Anything, mock.MatchedBy(func(c model.NodeCapabilities) bool {
+		return c.NodeID == "node-1" && c.AgentVersion == "1.4.0" && len(c.Activities) == 1
+	})).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(CollectNodeCapabilitiesWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CollectNodeCapabilitiesWorkflowTestSuite) TestNodeDownSkipsUpsert() {
+	s.env.OnActivity("ListActiveNodes", mock.Anything).
+		Return([]model.Node{{ID: "node-1"}}, nil)
+	s.env.OnActivity("ReportNodeCapabilities", mock.Anything).
+		Return(nil, fmt.Errorf("node unreachable"))
+
+	s.env.ExecuteWorkflow(CollectNodeCapabilitiesWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func TestCollectNodeCapabilitiesWorkflow(t *testing.T) {
+	suite.Run(t, new(CollectNodeCapabilitiesWorkflowTestSuite))
+}
@@ -0,0 +1,42 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// NodeDiagnosticsArgs identifies the node to self-test and its roles.
+type NodeDiagnosticsArgs struct {
+	NodeID string
+	Roles  []string
+}
+
+// NodeDiagnosticsWorkflow runs the diagnostics activity on the node's task
+// queue and returns its report. The caller is an API request waiting for the
+// result, so the activity is not retried: an unreachable node should fail
+// fast rather than hold the request open.
+func NodeDiagnosticsWorkflow(ctx workflow.Context, args NodeDiagnosticsArgs) (*model.NodeDiagnostics, error) {
+	nodeCtx := nodeActivityCtx(ctx, args.NodeID)
+	ao := workflow.GetActivityOptions(nodeCtx)
+	ao.ScheduleToStartTimeout = 10 * time.Second
+	ao.StartToCloseTimeout = 60 * time.Second
+	ao.ScheduleToCloseTimeout = 0
+	ao.RetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 1}
+	nodeCtx = workflow.WithActivityOptions(nodeCtx, ao)
+
+	var report model.NodeDiagnostics
+	err := workflow.ExecuteActivity(nodeCtx, "RunNodeDiagnostics", activity.RunNodeDiagnosticsParams{
+		Roles: args.Roles,
+	}).Get(ctx, &report)
+	if err != nil {
+		return nil, fmt.Errorf("run diagnostics on node %s: %w", args.NodeID, err)
+	}
+	report.NodeID = args.NodeID
+	return &report, nil
+}
@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type NodeDiagnosticsWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *NodeDiagnosticsWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *NodeDiagnosticsWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *NodeDiagnosticsWorkflowTestSuite) TestReturnsReport() {
+	report := &model.NodeDiagnostics{
+		Healthy: false,
+		Checks: []model.NodeDiagnosticCheck{
+			{Name: "cephfs_mount", OK: true},
+			{Name: "nginx", OK: false, Error: "nginx -t failed"},
+		},
+	}
+	s.env.OnActivity("RunNodeDiagnostics", mock.Anything, activity.RunNodeDiagnosticsParams{
+		Roles: []string{"web"},
+	}).Return(report, nil).Once()
+
+	s.env.ExecuteWorkflow(NodeDiagnosticsWorkflow, NodeDiagnosticsArgs{NodeID: "node-1", Roles: []string{"web"}})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var got model.NodeDiagnostics
+	s.NoError(s.env.GetWorkflowResult(&got))
+	s.False(got.Healthy)
+	s.Len(got.Checks, 2)
+	s.Equal("nginx -t failed", got.Checks[1].Error)
+}
+
+func (s *NodeDiagnosticsWorkflowTestSuite) TestNodeUnreachable_NotRetried() {
+	s.env.OnActivity("RunNodeDiagnostics", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("activity timeout")).Once()
+
+	s.env.ExecuteWorkflow(NodeDiagnosticsWorkflow, NodeDiagnosticsArgs{NodeID: "node-1", Roles: []string{"web"}})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func TestNodeDiagnosticsWorkflow(t *testing.T) {
+	suite.Run(t, new(NodeDiagnosticsWorkflowTestSuite))
+}
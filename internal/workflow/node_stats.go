@@ -0,0 +1,55 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+// CollectNodeStatsWorkflow runs on a cron schedule, collects CPU load, memory
+// and disk usage from every active node and stores the latest snapshot in
+// node_stats. An unreachable node keeps its previous snapshot; it never aborts
+// collection for the others.
+func CollectNodeStatsWorkflow(ctx workflow.Context) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 2,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+	logger := workflow.GetLogger(ctx)
+
+	var nodes []model.Node
+	err := workflow.ExecuteActivity(ctx, "ListActiveNodes").Get(ctx, &nodes)
+	if err != nil {
+		return fmt.Errorf("list active nodes: %w", err)
+	}
+
+	fanOutNodes(ctx, nodes, func(gCtx workflow.Context, node model.Node) error {
+		nodeCtx := workflow.WithActivityOptions(nodeActivityCtx(gCtx, node.ID), workflow.ActivityOptions{
+			StartToCloseTimeout: 15 * time.Second,
+			RetryPolicy: &temporal.RetryPolicy{
+				MaximumAttempts: 1,
+			},
+		})
+
+		var stats model.NodeStats
+		if err := workflow.ExecuteActivity(nodeCtx, "CollectNodeStats").Get(gCtx, &stats); err != nil {
+			logger.Warn("failed to collect node stats", "node", node.ID, "hostname", node.Hostname, "error", err)
+			return nil
+		}
+		stats.NodeID = node.ID
+
+		if err := workflow.ExecuteActivity(gCtx, "UpsertNodeStats", stats).Get(gCtx, nil); err != nil {
+			logger.Warn("failed to store node stats", "node", node.ID, "error", err)
+		}
+		return nil
+	})
+
+	return nil
+}
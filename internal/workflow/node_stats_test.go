@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+type CollectNodeStatsWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *CollectNodeStatsWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *CollectNodeStatsWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *CollectNodeStatsWorkflowTestSuite) TestStoresStats() {
+	load := 0.5
+	s.env.OnActivity("ListActiveNodes", mock.Anything).
+		Return([]model.Node{{ID: "node-1"}}, nil)
+	s.env.OnActivity("CollectNodeStats", mock.Anything).
+		Return(&model.NodeStats{CPUCount: 4, Load1: &load}, nil)
+	s.env.OnActivity("UpsertNodeStats", mock.Anything, mock.MatchedBy(func(st model.NodeStats) bool {
+		return st.NodeID == "node-1" && st.CPUCount == 4 && st.Load1 != nil && *st.Load1 == 0.5
+	})).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(CollectNodeStatsWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CollectNodeStatsWorkflowTestSuite) TestNodeDownSkipsUpsert() {
+	s.env.OnActivity("ListActiveNodes", mock.Anything).
+		Return([]model.Node{{ID: "node-1"}}, nil)
+	s.env.OnActivity("CollectNodeStats", mock.Anything).
+		Return(nil, fmt.Errorf("node unreachable"))
+
+	s.env.ExecuteWorkflow(CollectNodeStatsWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func TestCollectNodeStatsWorkflow(t *testing.T) {
+	suite.Run(t, new(CollectNodeStatsWorkflowTestSuite))
+}
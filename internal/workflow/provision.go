@@ -3,8 +3,10 @@ package workflow
 import (
 	"time"
 
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/workflow"
 
+	"github.com/edvin/hosting/internal/activity"
 	"github.com/edvin/hosting/internal/model"
 )
 
@@ -39,12 +41,48 @@ func TenantProvisionWorkflow(ctx workflow.Context) error {
 			WorkflowID: task.WorkflowID,
 			TaskQueue:  "hosting-tasks",
 		})
-		if err := workflow.ExecuteChildWorkflow(childCtx, task.WorkflowName, task.Arg).Get(ctx, nil); err != nil {
+		child := workflow.ExecuteChildWorkflow(childCtx, task.WorkflowName, task.Arg)
+		if task.OperationID != "" {
+			var exec workflow.Execution
+			if err := child.GetChildWorkflowExecution().Get(ctx, &exec); err == nil {
+				trackOperation(ctx, "MarkOperationRunning", activity.MarkOperationRunningParams{
+					ID:    task.OperationID,
+					RunID: exec.RunID,
+				})
+			}
+		}
+		err := child.Get(ctx, nil)
+		if err != nil {
 			logger.Error("child workflow failed", "workflow", task.WorkflowName, "id", task.WorkflowID, "error", err)
 			// Don't return error — continue processing next signal.
 			// The child workflow is responsible for setting its own failed status.
 		}
+		if task.OperationID != "" {
+			params := activity.CompleteOperationParams{ID: task.OperationID}
+			if err != nil {
+				params.Error = err.Error()
+			}
+			trackOperation(ctx, "CompleteOperation", params)
+		}
 
 		opsCount++
 	}
 }
+
+// trackOperation runs an operation bookkeeping activity. Failures are logged
+// and otherwise ignored so they never block the tenant's queue; the operations
+// API reconciles running operations with Temporal on read.
+func trackOperation(ctx workflow.Context, activityName string, params any) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 10 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    5 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	})
+	if err := workflow.ExecuteActivity(ctx, activityName, params).Get(ctx, nil); err != nil {
+		workflow.GetLogger(ctx).Warn("operation tracking failed", "activity", activityName, "error", err)
+	}
+}
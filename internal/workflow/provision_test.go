@@ -0,0 +1,90 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type TenantProvisionWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *TenantProvisionWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *TenantProvisionWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *TenantProvisionWorkflowTestSuite) signal(task model.ProvisionTask) {
+	s.env.RegisterDelayedCallback(func() {
+		s.env.SignalWorkflow(model.ProvisionSignalName, task)
+	}, time.Second)
+}
+
+func (s *TenantProvisionWorkflowTestSuite) TestTrackedTaskSucceeds() {
+	s.env.OnWorkflow(UpdateWebrootWorkflow, mock.Anything, "webroot-1").Return(nil)
+	s.env.OnActivity("MarkOperationRunning", mock.Anything, mock.MatchedBy(func(p activity.MarkOperationRunningParams) bool {
+		return p.ID == "op-1" && p.RunID != ""
+	})).Return(nil)
+	s.env.OnActivity("CompleteOperation", mock.Anything, activity.CompleteOperationParams{ID: "op-1"}).Return(nil)
+
+	s.signal(model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   "webroot-webroot-1",
+		Arg:          "webroot-1",
+		OperationID:  "op-1",
+	})
+	s.env.ExecuteWorkflow(TenantProvisionWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *TenantProvisionWorkflowTestSuite) TestTrackedTaskFails() {
+	s.env.OnWorkflow(UpdateWebrootWorkflow, mock.Anything, "webroot-1").Return(fmt.Errorf("node unreachable"))
+	s.env.OnActivity("MarkOperationRunning", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("CompleteOperation", mock.Anything, mock.MatchedBy(func(p activity.CompleteOperationParams) bool {
+		return p.ID == "op-1" && p.Error != ""
+	})).Return(nil)
+
+	s.signal(model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   "webroot-webroot-1",
+		Arg:          "webroot-1",
+		OperationID:  "op-1",
+	})
+	s.env.ExecuteWorkflow(TenantProvisionWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *TenantProvisionWorkflowTestSuite) TestUntrackedTaskSkipsBookkeeping() {
+	s.env.OnWorkflow(UpdateWebrootWorkflow, mock.Anything, "webroot-1").Return(nil)
+
+	s.signal(model.ProvisionTask{
+		WorkflowName: "UpdateWebrootWorkflow",
+		WorkflowID:   "webroot-webroot-1",
+		Arg:          "webroot-1",
+	})
+	s.env.ExecuteWorkflow(TenantProvisionWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.env.AssertNotCalled(s.T(), "MarkOperationRunning", mock.Anything, mock.Anything)
+	s.env.AssertNotCalled(s.T(), "CompleteOperation", mock.Anything, mock.Anything)
+}
+
+func TestTenantProvisionWorkflow(t *testing.T) {
+	suite.Run(t, new(TenantProvisionWorkflowTestSuite))
+}
@@ -0,0 +1,330 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/agent"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// DefaultPromoteMaxLagSeconds is the replication lag a replica may have and
+// still be promoted without Force.
+const DefaultPromoteMaxLagSeconds = 30
+
+// promoteCatchUpTimeout is how long PromoteReplicaWorkflow waits for the
+// chosen replica to apply its relay log before giving up.
+const promoteCatchUpTimeout = 2 * time.Minute
+
+// PromoteReplicaParams describes a replica promotion on a database shard.
+type PromoteReplicaParams struct {
+	ShardID       string `json:"shard_id"`
+	NodeID        string `json:"node_id,omitempty"`         // replica to promote; empty picks the first caught-up replica
+	MaxLagSeconds int    `json:"max_lag_seconds,omitempty"` // 0 uses DefaultPromoteMaxLagSeconds
+	Force         bool   `json:"force,omitempty"`           // promote even if the replica is not caught up
+}
+
+// PromoteReplicaWorkflow fails a database shard over from its primary to one
+// of its replicas. It is started by an operator, never automatically:
+//
+//  1. The old primary is made read-only, best effort since it has usually
+//     failed, so it stops taking writes that would be lost.
+//  2. The replica must have applied everything it received from the old
+//     primary and lag no more than MaxLagSeconds. Without Force the workflow
+//     gives up after promoteCatchUpTimeout and makes the old primary
+//     writable again.
+//  3. Replication is stopped on the replica and it is made read-write.
+//  4. The shard's primary_node_id is updated, so workflows and connection
+//     lookups use the new primary from then on.
+//  5. Remaining replicas are repointed at the new primary.
+//
+// The old primary is left read-only and out of replication. The next shard
+// convergence configures it as a replica of the new primary; check it for
+// errant transactions before converging.
+func PromoteReplicaWorkflow(ctx workflow.Context, params PromoteReplicaParams) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+	logger := workflow.GetLogger(ctx)
+
+	maxLag := params.MaxLagSeconds
+	if maxLag <= 0 {
+		maxLag = DefaultPromoteMaxLagSeconds
+	}
+
+	var shard model.Shard
+	if err := workflow.ExecuteActivity(ctx, "GetShardByID", params.ShardID).Get(ctx, &shard); err != nil {
+		return fmt.Errorf("get shard: %w", err)
+	}
+	if shard.Role != model.ShardRoleDatabase {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("shard %s is not a database shard", params.ShardID), "INVALID_SHARD", nil)
+	}
+
+	oldPrimaryID, nodes, err := dbShardPrimary(ctx, params.ShardID)
+	if err != nil {
+		return err
+	}
+
+	var candidates []model.Node
+	for _, n := range nodes {
+		if n.ID == oldPrimaryID {
+			continue
+		}
+		if params.NodeID == "" || n.ID == params.NodeID {
+			candidates = append(candidates, n)
+		}
+	}
+	if len(candidates) == 0 {
+		if params.NodeID != "" {
+			return temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("node %s is not a replica in shard %s", params.NodeID, params.ShardID), "INVALID_REPLICA", nil)
+		}
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("shard %s has no replica to promote", params.ShardID), "NO_REPLICA", nil)
+	}
+
+	// Fence the old primary. It is usually down, so don't wait long.
+	fenceCtx := promoteNodeCtx(ctx, oldPrimaryID)
+	fenced := workflow.ExecuteActivity(fenceCtx, "SetReadOnly", true).Get(ctx, nil) == nil
+	if !fenced {
+		logger.Warn("could not make old primary read-only", "shard", params.ShardID, "node", oldPrimaryID)
+	}
+
+	newPrimary, reason := waitForCaughtUpReplica(ctx, candidates, maxLag)
+	if newPrimary == nil {
+		if !params.Force {
+			if fenced {
+				_ = workflow.ExecuteActivity(fenceCtx, "SetReadOnly", false).Get(ctx, nil)
+			}
+			return temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("no replica is safe to promote: %s (use force to promote anyway)", reason), "REPLICA_NOT_CAUGHT_UP", nil)
+		}
+		newPrimary = &candidates[0]
+		logger.Warn("force-promoting replica that is not caught up",
+			"shard", params.ShardID, "node", newPrimary.ID, "reason", reason)
+	}
+
+	setShardStatus(ctx, params.ShardID, model.StatusConverging,
+		strPtr(fmt.Sprintf("promoting %s to primary", newPrimary.ID)))
+
+	newPrimaryCtx := nodeActivityCtx(ctx, newPrimary.ID)
+	if err := workflow.ExecuteActivity(newPrimaryCtx, "StopReplication").Get(ctx, nil); err != nil {
+		setShardStatus(ctx, params.ShardID, model.StatusFailed, strPtr(fmt.Sprintf("stop replication on %s: %v", newPrimary.ID, err)))
+		return fmt.Errorf("stop replication on %s: %w", newPrimary.ID, err)
+	}
+	if err := workflow.ExecuteActivity(newPrimaryCtx, "SetReadOnly", false).Get(ctx, nil); err != nil {
+		setShardStatus(ctx, params.ShardID, model.StatusFailed, strPtr(fmt.Sprintf("make %s read-write: %v", newPrimary.ID, err)))
+		return fmt.Errorf("make %s read-write: %w", newPrimary.ID, err)
+	}
+
+	// Keep any other keys in the shard config.
+	cfg := map[string]any{}
+	if len(shard.Config) > 0 {
+		_ = json.Unmarshal(shard.Config, &cfg)
+	}
+	cfg["primary_node_id"] = newPrimary.ID
+	cfgJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("marshal shard config: %w", err)
+	}
+	err = workflow.ExecuteActivity(ctx, "UpdateShardConfig", activity.UpdateShardConfigParams{
+		ShardID: params.ShardID,
+		Config:  cfgJSON,
+	}).Get(ctx, nil)
+	if err != nil {
+		setShardStatus(ctx, params.ShardID, model.StatusFailed, strPtr(fmt.Sprintf("record new primary %s: %v", newPrimary.ID, err)))
+		return fmt.Errorf("update shard config: %w", err)
+	}
+	logger.Info("promoted replica", "shard", params.ShardID, "old_primary", oldPrimaryID, "new_primary", newPrimary.ID)
+
+	// Repoint the remaining replicas. Failures leave the shard degraded
+	// rather than failing the promotion, which has already taken effect.
+	var errs []string
+	for _, n := range nodes {
+		if n.ID == oldPrimaryID || n.ID == newPrimary.ID {
+			continue
+		}
+		if newPrimary.IPAddress == nil {
+			errs = append(errs, fmt.Sprintf("new primary %s has no IP address", newPrimary.ID))
+			break
+		}
+		replicaCtx := nodeActivityCtx(ctx, n.ID)
+		if err := workflow.ExecuteActivity(replicaCtx, "SetReadOnly", true).Get(ctx, nil); err != nil {
+			errs = append(errs, fmt.Sprintf("set replica %s read-only: %v", n.ID, err))
+		}
+		err := workflow.ExecuteActivity(replicaCtx, "ConfigureReplication", activity.ConfigureReplicationParams{
+			PrimaryHost: *newPrimary.IPAddress,
+			ReplUser:    "repl",
+		}).Get(ctx, nil)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("repoint replica %s: %v", n.ID, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		setShardStatus(ctx, params.ShardID, "degraded", strPtr(strings.Join(errs, "; ")))
+		return nil
+	}
+	msg := fmt.Sprintf("primary failed over from %s to %s", oldPrimaryID, newPrimary.ID)
+	if params.Force && reason != "" {
+		msg += " (forced: " + reason + ")"
+	}
+	setShardStatus(ctx, params.ShardID, model.StatusActive, &msg)
+	return nil
+}
+
+// promoteNodeCtx is a node activity context for a node that may be down:
+// a single short attempt instead of nodeActivityCtx's retries.
+func promoteNodeCtx(ctx workflow.Context, nodeID string) workflow.Context {
+	ctx = nodeActivityCtx(ctx, nodeID)
+	ao := workflow.GetActivityOptions(ctx)
+	ao.StartToCloseTimeout = 15 * time.Second
+	ao.ScheduleToCloseTimeout = 30 * time.Second
+	ao.RetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 1}
+	return workflow.WithActivityOptions(ctx, ao)
+}
+
+// waitForCaughtUpReplica polls the candidates until one of them is caught
+// up or promoteCatchUpTimeout passes. It returns the first caught-up
+// replica, or nil and the reason the last candidate was not caught up.
+func waitForCaughtUpReplica(ctx workflow.Context, candidates []model.Node, maxLag int) (*model.Node, string) {
+	deadline := workflow.Now(ctx).Add(promoteCatchUpTimeout)
+	var reason string
+	for {
+		for i := range candidates {
+			var status agent.ReplicationStatus
+			err := workflow.ExecuteActivity(promoteNodeCtx(ctx, candidates[i].ID), "GetReplicationStatus").Get(ctx, &status)
+			if err != nil {
+				reason = fmt.Sprintf("replica %s: get replication status: %v", candidates[i].ID, err)
+				continue
+			}
+			ok, why := replicaCaughtUp(status, maxLag)
+			if ok {
+				return &candidates[i], ""
+			}
+			reason = fmt.Sprintf("replica %s: %s", candidates[i].ID, why)
+		}
+		if !workflow.Now(ctx).Before(deadline) {
+			return nil, reason
+		}
+		_ = workflow.Sleep(ctx, 5*time.Second)
+	}
+}
+
+// replicaCaughtUp reports whether a replica is safe to promote: it must be
+// replicating, its SQL thread must not have stopped on an error, it must
+// have applied every transaction it retrieved, and its lag must be within
+// maxLag. The lag is unknown (nil) when the primary is unreachable, in which
+// case the applied relay log is all that can be checked.
+func replicaCaughtUp(status agent.ReplicationStatus, maxLag int) (bool, string) {
+	if !status.IORunning && !status.SQLRunning && status.RetrievedGTIDSet == "" && status.ExecutedGTIDSet == "" {
+		return false, "not configured as a replica"
+	}
+	if !status.SQLRunning && status.LastError != "" {
+		return false, "SQL thread stopped: " + status.LastError
+	}
+	if !gtidSetContains(status.ExecutedGTIDSet, status.RetrievedGTIDSet) {
+		return false, "relay log not yet applied"
+	}
+	if status.SecondsBehind != nil && *status.SecondsBehind > maxLag {
+		return false, fmt.Sprintf("%ds behind primary (limit %ds)", *status.SecondsBehind, maxLag)
+	}
+	return true, ""
+}
+
+// gtidSetContains reports whether GTID set super contains every transaction
+// in sub. Sets use MySQL's text form, e.g. "uuid:1-5:7,uuid2:1-3"; tagged
+// GTIDs ("uuid:tag:1-5") are keyed by uuid and tag. Unparseable sets are
+// treated as not contained.
+func gtidSetContains(super, sub string) bool {
+	superSet, ok := parseGTIDSet(super)
+	if !ok {
+		return false
+	}
+	subSet, ok := parseGTIDSet(sub)
+	if !ok {
+		return false
+	}
+	for source, intervals := range subSet {
+		have := mergeGTIDIntervals(superSet[source])
+		for _, iv := range intervals {
+			covered := false
+			for _, h := range have {
+				if h[0] <= iv[0] && iv[1] <= h[1] {
+					covered = true
+					break
+				}
+			}
+			if !covered {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// parseGTIDSet parses a GTID set into transaction intervals by source.
+func parseGTIDSet(s string) (map[string][][2]int64, bool) {
+	set := make(map[string][][2]int64)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) < 2 {
+			return nil, false
+		}
+		uuid := strings.ToLower(parts[0])
+		source := uuid
+		for _, p := range parts[1:] {
+			lo, hi, isInterval := strings.Cut(p, "-")
+			start, err := strconv.ParseInt(lo, 10, 64)
+			if err != nil {
+				source = uuid + ":" + strings.ToLower(p) // tag
+				continue
+			}
+			end := start
+			if isInterval {
+				if end, err = strconv.ParseInt(hi, 10, 64); err != nil || end < start {
+					return nil, false
+				}
+			}
+			set[source] = append(set[source], [2]int64{start, end})
+		}
+	}
+	return set, true
+}
+
+// mergeGTIDIntervals sorts intervals and merges overlapping and adjacent ones.
+func mergeGTIDIntervals(intervals [][2]int64) [][2]int64 {
+	sorted := append([][2]int64(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i][0] < sorted[j][0] })
+	var merged [][2]int64
+	for _, iv := range sorted {
+		if n := len(merged); n > 0 && iv[0] <= merged[n-1][1]+1 {
+			if iv[1] > merged[n-1][1] {
+				merged[n-1][1] = iv[1]
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
@@ -0,0 +1,180 @@
+package workflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/agent"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type PromoteReplicaWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *PromoteReplicaWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *PromoteReplicaWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *PromoteReplicaWorkflowTestSuite) mockShard() {
+	ip2, ip3 := "10.0.0.2", "10.0.0.3"
+	s.env.OnActivity("GetShardByID", mock.Anything, "shard-1").Return(&model.Shard{
+		ID:     "shard-1",
+		Role:   model.ShardRoleDatabase,
+		Config: json.RawMessage(`{"primary_node_id":"node-1","max_connections":200}`),
+	}, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "shard-1").Return([]model.Node{
+		{ID: "node-1"},
+		{ID: "node-2", IPAddress: &ip2},
+		{ID: "node-3", IPAddress: &ip3},
+	}, nil)
+}
+
+func caughtUpStatus() *agent.ReplicationStatus {
+	return &agent.ReplicationStatus{
+		SQLRunning:       true,
+		ExecutedGTIDSet:  "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100",
+		RetrievedGTIDSet: "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100",
+	}
+}
+
+func (s *PromoteReplicaWorkflowTestSuite) TestSuccess() {
+	s.mockShard()
+	s.env.OnActivity("SetReadOnly", mock.Anything, true).Return(nil).Twice() // fence node-1, node-3
+	s.env.OnActivity("GetReplicationStatus", mock.Anything).Return(caughtUpStatus(), nil).Once()
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("StopReplication", mock.Anything).Return(nil).Once()
+	s.env.OnActivity("SetReadOnly", mock.Anything, false).Return(nil).Once()
+	s.env.OnActivity("UpdateShardConfig", mock.Anything, mock.MatchedBy(func(p activity.UpdateShardConfigParams) bool {
+		var cfg map[string]any
+		_ = json.Unmarshal(p.Config, &cfg)
+		return p.ShardID == "shard-1" && cfg["primary_node_id"] == "node-2" && cfg["max_connections"] == float64(200)
+	})).Return(nil).Once()
+	s.env.OnActivity("ConfigureReplication", mock.Anything, activity.ConfigureReplicationParams{
+		PrimaryHost: "10.0.0.2",
+		ReplUser:    "repl",
+	}).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(PromoteReplicaWorkflow, PromoteReplicaParams{ShardID: "shard-1"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *PromoteReplicaWorkflowTestSuite) TestNotCaughtUp() {
+	s.mockShard()
+	behind := caughtUpStatus()
+	behind.RetrievedGTIDSet = "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-120"
+	s.env.OnActivity("SetReadOnly", mock.Anything, true).Return(nil).Once()
+	s.env.OnActivity("GetReplicationStatus", mock.Anything).Return(behind, nil)
+	s.env.OnActivity("SetReadOnly", mock.Anything, false).Return(nil).Once() // unfence node-1
+
+	s.env.ExecuteWorkflow(PromoteReplicaWorkflow, PromoteReplicaParams{ShardID: "shard-1", NodeID: "node-2"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "relay log not yet applied")
+	s.env.AssertNotCalled(s.T(), "StopReplication", mock.Anything)
+	s.env.AssertNotCalled(s.T(), "UpdateShardConfig", mock.Anything, mock.Anything)
+}
+
+func (s *PromoteReplicaWorkflowTestSuite) TestForce() {
+	s.mockShard()
+	lag := 600
+	behind := caughtUpStatus()
+	behind.SecondsBehind = &lag
+	// The old primary is unreachable and can't be fenced.
+	s.env.OnActivity("SetReadOnly", mock.Anything, true).Return(assert.AnError).Once()
+	s.env.OnActivity("GetReplicationStatus", mock.Anything).Return(behind, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("StopReplication", mock.Anything).Return(nil).Once()
+	s.env.OnActivity("SetReadOnly", mock.Anything, false).Return(nil).Once()
+	s.env.OnActivity("UpdateShardConfig", mock.Anything, mock.Anything).Return(nil).Once()
+	s.env.OnActivity("SetReadOnly", mock.Anything, true).Return(nil).Once() // node-2
+	s.env.OnActivity("ConfigureReplication", mock.Anything, activity.ConfigureReplicationParams{
+		PrimaryHost: "10.0.0.3",
+		ReplUser:    "repl",
+	}).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(PromoteReplicaWorkflow, PromoteReplicaParams{ShardID: "shard-1", NodeID: "node-3", Force: true})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *PromoteReplicaWorkflowTestSuite) TestNodeNotReplica() {
+	s.mockShard()
+
+	s.env.ExecuteWorkflow(PromoteReplicaWorkflow, PromoteReplicaParams{ShardID: "shard-1", NodeID: "node-1"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "not a replica")
+	s.env.AssertNotCalled(s.T(), "SetReadOnly", mock.Anything, mock.Anything)
+}
+
+func TestPromoteReplicaWorkflow(t *testing.T) {
+	suite.Run(t, new(PromoteReplicaWorkflowTestSuite))
+}
+
+func TestReplicaCaughtUp(t *testing.T) {
+	lag := 45
+	tests := map[string]struct {
+		status agent.ReplicationStatus
+		want   bool
+	}{
+		"caught up":     {*caughtUpStatus(), true},
+		"not a replica": {agent.ReplicationStatus{}, false},
+		"sql error": {agent.ReplicationStatus{
+			IORunning:        true,
+			LastError:        "Duplicate entry",
+			ExecutedGTIDSet:  caughtUpStatus().ExecutedGTIDSet,
+			RetrievedGTIDSet: caughtUpStatus().RetrievedGTIDSet,
+		}, false},
+		"lagging": {agent.ReplicationStatus{
+			IORunning:        true,
+			SQLRunning:       true,
+			SecondsBehind:    &lag,
+			ExecutedGTIDSet:  caughtUpStatus().ExecutedGTIDSet,
+			RetrievedGTIDSet: caughtUpStatus().RetrievedGTIDSet,
+		}, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, reason := replicaCaughtUp(tt.status, DefaultPromoteMaxLagSeconds)
+			assert.Equal(t, tt.want, got, reason)
+		})
+	}
+}
+
+func TestGTIDSetContains(t *testing.T) {
+	const a = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	const b = "4f22fa47-71ca-11e1-9e33-c80aa9429563"
+	tests := []struct {
+		super, sub string
+		want       bool
+	}{
+		{a + ":1-100", a + ":1-100", true},
+		{a + ":1-100", "", true},
+		{a + ":1-50:51-100", a + ":40-60", true},
+		{a + ":1-100," + b + ":1-5", b + ":3", true},
+		{a + ":1-100", a + ":1-101", false},
+		{a + ":1-50:52-100", a + ":1-100", false},
+		{a + ":1-100", b + ":1", false},
+		{a + ":1-10:tag1:1-5", a + ":tag1:2-4", true},
+		{a + ":1-10", a + ":tag1:1", false},
+		{"garbage", a + ":1", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, gtidSetContains(tt.super, tt.sub), "%q ⊇ %q", tt.super, tt.sub)
+	}
+}
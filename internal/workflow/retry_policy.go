@@ -0,0 +1,139 @@
+package workflow
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+// RetryPolicies maps activity names to the retry policy they are scheduled
+// with. A policy here replaces the one from the workflow's activity options,
+// so activities with unusual failure modes are tuned in one place instead of
+// in every workflow that calls them.
+type RetryPolicies map[string]*temporal.RetryPolicy
+
+// RetryConfig holds the operator-tunable inputs of the retry registry.
+type RetryConfig struct {
+	// ACMEMaxAttempts bounds the attempts of each ACME activity.
+	ACMEMaxAttempts int
+}
+
+// acmeActivities talk to the ACME server, which rate limits and has outages
+// measured in minutes. They retry slowly for a long time.
+var acmeActivities = []string{
+	"CreateOrder",
+	"GetHTTP01Challenge",
+	"GetDNS01Challenge",
+	"AcceptChallenge",
+	"FinalizeOrder",
+}
+
+// dnsActivities write to PowerDNS and are safe to repeat.
+var dnsActivities = []string{
+	"PlaceDNS01Challenge",
+	"CleanupDNS01Challenge",
+	"WriteDNSZone",
+	"WriteDNSRecord",
+	"UpdateDNSRecord",
+	"DeleteDNSRecord",
+	"ApplyDNSRecordBatch",
+	"DeleteDNSRecordsByDomain",
+	"DeleteDNSZone",
+	"AutoCreateDNSRecords",
+	"AutoDeleteDNSRecords",
+	"AutoCreateEmailDNSRecords",
+	"AutoDeleteEmailDNSRecords",
+}
+
+// validationActivities fail deterministically on bad input; retrying only
+// delays the error.
+var validationActivities = []string{
+	"ValidateCustomCert",
+}
+
+// NewRetryPolicies returns the retry registry for the given configuration.
+func NewRetryPolicies(cfg RetryConfig) RetryPolicies {
+	acmeAttempts := int32(cfg.ACMEMaxAttempts)
+	if acmeAttempts <= 0 {
+		acmeAttempts = 10
+	}
+
+	policies := RetryPolicies{}
+	for _, name := range acmeActivities {
+		policies[name] = &temporal.RetryPolicy{
+			InitialInterval:    30 * time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    10 * time.Minute,
+			MaximumAttempts:    acmeAttempts,
+		}
+	}
+	for _, name := range dnsActivities {
+		policies[name] = &temporal.RetryPolicy{
+			InitialInterval:    2 * time.Second,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    1 * time.Minute,
+			MaximumAttempts:    8,
+		}
+	}
+	for _, name := range validationActivities {
+		policies[name] = &temporal.RetryPolicy{MaximumAttempts: 1}
+	}
+	return policies
+}
+
+// apply returns ctx with the registered retry policy for activityType, if
+// any. Non-retryable error types set by the workflow are kept.
+func (p RetryPolicies) apply(ctx workflow.Context, activityType string) workflow.Context {
+	policy, ok := p[activityType]
+	if !ok {
+		return ctx
+	}
+	ao := workflow.GetActivityOptions(ctx)
+	override := *policy
+	if len(override.NonRetryableErrorTypes) == 0 && ao.RetryPolicy != nil {
+		override.NonRetryableErrorTypes = ao.RetryPolicy.NonRetryableErrorTypes
+	}
+	ao.RetryPolicy = &override
+	return workflow.WithActivityOptions(ctx, ao)
+}
+
+// RetryPolicyInterceptor is a Temporal worker interceptor that applies a
+// RetryPolicies registry to every activity a workflow schedules. The registry
+// must not change while the worker runs, or replays would diverge.
+type RetryPolicyInterceptor struct {
+	interceptor.WorkerInterceptorBase
+	Policies RetryPolicies
+}
+
+func (i *RetryPolicyInterceptor) InterceptWorkflow(
+	ctx workflow.Context,
+	next interceptor.WorkflowInboundInterceptor,
+) interceptor.WorkflowInboundInterceptor {
+	return &retryPolicyWorkflowInbound{
+		WorkflowInboundInterceptorBase: interceptor.WorkflowInboundInterceptorBase{Next: next},
+		policies:                       i.Policies,
+	}
+}
+
+type retryPolicyWorkflowInbound struct {
+	interceptor.WorkflowInboundInterceptorBase
+	policies RetryPolicies
+}
+
+func (i *retryPolicyWorkflowInbound) Init(outbound interceptor.WorkflowOutboundInterceptor) error {
+	return i.Next.Init(&retryPolicyWorkflowOutbound{
+		WorkflowOutboundInterceptorBase: interceptor.WorkflowOutboundInterceptorBase{Next: outbound},
+		policies:                        i.policies,
+	})
+}
+
+type retryPolicyWorkflowOutbound struct {
+	interceptor.WorkflowOutboundInterceptorBase
+	policies RetryPolicies
+}
+
+func (o *retryPolicyWorkflowOutbound) ExecuteActivity(ctx workflow.Context, activityType string, args ...interface{}) workflow.Future {
+	return o.Next.ExecuteActivity(o.policies.apply(ctx, activityType), activityType, args...)
+}
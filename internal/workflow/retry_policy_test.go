@@ -0,0 +1,85 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/interceptor"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+	"go.temporal.io/sdk/worker"
+	"go.temporal.io/sdk/workflow"
+)
+
+func TestNewRetryPolicies(t *testing.T) {
+	policies := NewRetryPolicies(RetryConfig{ACMEMaxAttempts: 25})
+
+	require.Contains(t, policies, "FinalizeOrder")
+	assert.Equal(t, int32(25), policies["FinalizeOrder"].MaximumAttempts)
+	assert.Equal(t, 10*time.Minute, policies["FinalizeOrder"].MaximumInterval)
+	assert.Equal(t, int32(1), policies["ValidateCustomCert"].MaximumAttempts)
+	assert.NotContains(t, policies, "UpdateResourceStatus")
+}
+
+func TestNewRetryPolicies_DefaultACMEAttempts(t *testing.T) {
+	policies := NewRetryPolicies(RetryConfig{})
+	assert.Equal(t, int32(10), policies["CreateOrder"].MaximumAttempts)
+}
+
+// retryTestWorkflow schedules one activity with a generous default policy.
+func retryTestWorkflow(ctx workflow.Context, activityName string) error {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 3,
+			InitialInterval: 1 * time.Second,
+		},
+	})
+	return workflow.ExecuteActivity(ctx, activityName, "cert", "key").Get(ctx, nil)
+}
+
+func newRetryTestEnv(t *testing.T) *testsuite.TestWorkflowEnvironment {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	registerActivities(env)
+	env.RegisterWorkflow(retryTestWorkflow)
+	env.SetWorkerOptions(worker.Options{
+		Interceptors: []interceptor.WorkerInterceptor{
+			&RetryPolicyInterceptor{Policies: NewRetryPolicies(RetryConfig{})},
+		},
+	})
+	return env
+}
+
+func TestRetryPolicyInterceptor_OverridesRegisteredActivity(t *testing.T) {
+	env := newRetryTestEnv(t)
+	attempts := 0
+	env.OnActivity("ValidateCustomCert", mock.Anything, "cert", "key").Return(func(context.Context, string, string) error {
+		attempts++
+		return fmt.Errorf("bad pem")
+	})
+
+	env.ExecuteWorkflow(retryTestWorkflow, "ValidateCustomCert")
+	require.True(t, env.IsWorkflowCompleted())
+	require.Error(t, env.GetWorkflowError())
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicyInterceptor_LeavesOtherActivities(t *testing.T) {
+	env := newRetryTestEnv(t)
+	attempts := 0
+	env.OnActivity("DeactivateOtherCerts", mock.Anything, "cert", "key").Return(func(context.Context, string, string) error {
+		attempts++
+		return fmt.Errorf("transient")
+	})
+
+	env.ExecuteWorkflow(retryTestWorkflow, "DeactivateOtherCerts")
+	require.True(t, env.IsWorkflowCompleted())
+	require.Error(t, env.GetWorkflowError())
+	assert.Equal(t, 3, attempts)
+}
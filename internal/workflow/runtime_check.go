@@ -0,0 +1,173 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// runtimeCacheTTL is how long a node's cached runtime report is trusted
+// before the node agent is asked again.
+const runtimeCacheTTL = time.Hour
+
+// errRuntimeUnavailable is the application error type returned when a
+// requested runtime version is not installed on the target nodes.
+const errRuntimeUnavailable = "RuntimeUnavailable"
+
+// ensureRuntimeAvailable checks that the runtime version, and for PHP the
+// given extensions, are installed on every node. Cached reports are used when
+// fresh and containing what is needed; otherwise the node agent is asked via
+// GetInstalledRuntimes and the cache is refreshed, so newly installed
+// versions are picked up immediately. Static webroots need no runtime. The
+// returned error is non-retryable and lists the versions (or extensions)
+// available on all nodes.
+func ensureRuntimeAvailable(ctx workflow.Context, nodes []model.Node, runtime, version string, extensions []string) error {
+	if runtime == "" || runtime == model.RuntimeStatic || len(nodes) == 0 {
+		return nil
+	}
+	want := model.InstalledRuntime{Runtime: runtime, Version: version, Extensions: extensions}
+
+	nodeIDs := make([]string, len(nodes))
+	for i, n := range nodes {
+		nodeIDs[i] = n.ID
+	}
+	var cached []model.NodeRuntimes
+	if err := workflow.ExecuteActivity(ctx, "GetCachedNodeRuntimes", nodeIDs).Get(ctx, &cached); err != nil {
+		return fmt.Errorf("get cached node runtimes: %w", err)
+	}
+	reports := make(map[string]model.NodeRuntimes, len(cached))
+	for _, r := range cached {
+		reports[r.NodeID] = r
+	}
+
+	now := workflow.Now(ctx)
+	var missing, missingExt []string
+	versionCount := map[string]int{}
+	extCount := map[string]int{}
+	for _, node := range nodes {
+		report, ok := reports[node.ID]
+		if !ok || now.Sub(report.ReportedAt) > runtimeCacheTTL || !hasRuntime(report.Runtimes, want) {
+			var installed []model.InstalledRuntime
+			err := workflow.ExecuteActivity(nodeActivityCtx(ctx, node.ID), "GetInstalledRuntimes").Get(ctx, &installed)
+			if err != nil {
+				return fmt.Errorf("get installed runtimes on node %s: %w", node.ID, err)
+			}
+			err = workflow.ExecuteActivity(ctx, "SaveNodeRuntimes", activity.SaveNodeRuntimesParams{
+				NodeID:   node.ID,
+				Runtimes: installed,
+			}).Get(ctx, nil)
+			if err != nil {
+				return fmt.Errorf("save node runtimes for %s: %w", node.ID, err)
+			}
+			report = model.NodeRuntimes{NodeID: node.ID, Runtimes: installed, ReportedAt: now}
+		}
+
+		rt := findRuntime(report.Runtimes, runtime, version)
+		switch {
+		case rt == nil:
+			missing = append(missing, node.ID)
+		case !hasRuntime([]model.InstalledRuntime{*rt}, want):
+			missingExt = append(missingExt, node.ID)
+		}
+		if rt != nil {
+			for _, ext := range rt.Extensions {
+				extCount[ext]++
+			}
+		}
+		for _, rt := range report.Runtimes {
+			if rt.Runtime == runtime {
+				versionCount[rt.Version]++
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%s %s is not installed on node(s) %s; supported %s versions: %s",
+				runtime, version, strings.Join(missing, ", "), runtime, onAllNodes(versionCount, len(nodes))),
+			errRuntimeUnavailable, nil)
+	}
+	if len(missingExt) > 0 {
+		var unavailable []string
+		for _, ext := range extensions {
+			if extCount[ext] < len(nodes) {
+				unavailable = append(unavailable, ext)
+			}
+		}
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("%s %s extension(s) %s not installed on node(s) %s; available extensions: %s",
+				runtime, version, strings.Join(unavailable, ", "), strings.Join(missingExt, ", "), onAllNodes(extCount, len(nodes))),
+			errRuntimeUnavailable, nil)
+	}
+	return nil
+}
+
+// onAllNodes returns the sorted keys counted on every node, or "none".
+func onAllNodes(counts map[string]int, nodes int) string {
+	var names []string
+	for name, n := range counts {
+		if n == nodes {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// enabledPHPExtensions returns the sorted extensions a PHP webroot enables in
+// its runtime_config. The config has been validated by the API.
+func enabledPHPExtensions(webroot model.Webroot) []string {
+	if webroot.Runtime != model.RuntimePHP || len(webroot.RuntimeConfig) == 0 {
+		return nil
+	}
+	var cfg struct {
+		Extensions map[string]bool `json:"extensions"`
+	}
+	if err := json.Unmarshal(webroot.RuntimeConfig, &cfg); err != nil {
+		return nil
+	}
+	var exts []string
+	for name, enabled := range cfg.Extensions {
+		if enabled {
+			exts = append(exts, name)
+		}
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+func findRuntime(installed []model.InstalledRuntime, runtime, version string) *model.InstalledRuntime {
+	for i := range installed {
+		if installed[i].Runtime == runtime && installed[i].Version == version {
+			return &installed[i]
+		}
+	}
+	return nil
+}
+
+// hasRuntime reports whether the wanted runtime version is installed with
+// all of the wanted extensions.
+func hasRuntime(installed []model.InstalledRuntime, want model.InstalledRuntime) bool {
+	rt := findRuntime(installed, want.Runtime, want.Version)
+	if rt == nil {
+		return false
+	}
+	for _, ext := range want.Extensions {
+		if !slices.Contains(rt.Extensions, ext) {
+			return false
+		}
+	}
+	return true
+}
@@ -78,6 +78,55 @@ func CreateS3AccessKeyWorkflow(ctx workflow.Context, args core.CreateS3AccessKey
 	}).Get(ctx, nil)
 }
 
+// RotateS3AccessKeyWorkflow replaces the secret of an S3 access key via the
+// node agent. The access key ID stays the same; RGW stops accepting the old
+// secret once the node activity completes.
+func RotateS3AccessKeyWorkflow(ctx workflow.Context, args core.RotateS3AccessKeyArgs) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	keyID := args.KeyID
+
+	var sctx activity.S3AccessKeyContext
+	err := workflow.ExecuteActivity(ctx, "GetS3AccessKeyContext", keyID).Get(ctx, &sctx)
+	if err != nil {
+		_ = setResourceFailed(ctx, "s3_access_keys", keyID, err)
+		return err
+	}
+
+	if len(sctx.Nodes) == 0 {
+		noNodesErr := fmt.Errorf("no nodes found for S3 bucket %s", sctx.Key.S3BucketID)
+		_ = setResourceFailed(ctx, "s3_access_keys", keyID, noNodesErr)
+		return noNodesErr
+	}
+
+	nodeCtx := nodeActivityCtx(ctx, sctx.Nodes[0].ID)
+
+	err = workflow.ExecuteActivity(nodeCtx, "RotateS3AccessKey", activity.RotateS3AccessKeyParams{
+		TenantID:        sctx.Bucket.TenantID,
+		AccessKeyID:     sctx.Key.AccessKeyID,
+		SecretAccessKey: args.SecretAccessKey,
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setResourceFailed(ctx, "s3_access_keys", keyID, err)
+		return err
+	}
+
+	return workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "s3_access_keys",
+		ID:     keyID,
+		Status: model.StatusActive,
+	}).Get(ctx, nil)
+}
+
 // DeleteS3AccessKeyWorkflow deletes an S3 access key via the node agent.
 func DeleteS3AccessKeyWorkflow(ctx workflow.Context, keyID string) error {
 	ao := workflow.ActivityOptions{
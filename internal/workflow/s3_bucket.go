@@ -103,6 +103,18 @@ func CreateS3BucketWorkflow(ctx workflow.Context, bucketID string) error {
 		}
 	}
 
+	// Apply lifecycle rules, if any.
+	if len(bucket.LifecycleRules) > 0 {
+		err = workflow.ExecuteActivity(nodeCtx, "SetS3BucketLifecycle", activity.SetS3BucketLifecycleParams{
+			Name:  internalName,
+			Rules: bucket.LifecycleRules,
+		}).Get(ctx, nil)
+		if err != nil {
+			_ = setResourceFailed(ctx, "s3_buckets", bucketID, err)
+			return err
+		}
+	}
+
 	// Set status to active.
 	err = workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
 		Table:  "s3_buckets",
@@ -118,7 +130,9 @@ func CreateS3BucketWorkflow(ctx workflow.Context, bucketID string) error {
 	return nil
 }
 
-// UpdateS3BucketWorkflow updates the policy/quota of an S3 bucket.
+// UpdateS3BucketWorkflow updates the policy and lifecycle rules of an S3
+// bucket. Both node activities converge to the stored state, so re-running
+// the workflow is harmless.
 func UpdateS3BucketWorkflow(ctx workflow.Context, bucketID string) error {
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 30 * time.Second,
@@ -172,7 +186,10 @@ func UpdateS3BucketWorkflow(ctx workflow.Context, bucketID string) error {
 		return err
 	}
 
-	return nil
+	return workflow.ExecuteActivity(nodeCtx, "SetS3BucketLifecycle", activity.SetS3BucketLifecycleParams{
+		Name:  internalName,
+		Rules: bucket.LifecycleRules,
+	}).Get(ctx, nil)
 }
 
 // DeleteS3BucketWorkflow deletes an S3 bucket via the node agent.
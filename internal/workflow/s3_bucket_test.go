@@ -106,6 +106,48 @@ func (s *CreateS3BucketWorkflowTestSuite) TestSuccessPublicBucket() {
 	s.NoError(s.env.GetWorkflowError())
 }
 
+func (s *CreateS3BucketWorkflowTestSuite) TestSuccessWithLifecycleRules() {
+	bucketID := "test-bucket-lc"
+	tenantID := "test-tenant-1"
+	shardID := "test-shard-1"
+	tenant := model.Tenant{ID: tenantID}
+	rules := []model.S3LifecycleRule{{ID: "rule-1", Prefix: "tmp/", ExpirationDays: 7}}
+	bucket := model.S3Bucket{
+		ID:             bucketID,
+		TenantID:       tenantID,
+		ShardID:        &shardID,
+		QuotaBytes:     1073741824,
+		LifecycleRules: rules,
+	}
+	nodes := []model.Node{
+		{ID: "node-1"},
+	}
+
+	internalName := tenantID + "-" + bucketID
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "s3_buckets", ID: bucketID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetS3BucketByID", mock.Anything, bucketID).Return(&bucket, nil)
+	s.env.OnActivity("GetTenantByID", mock.Anything, tenantID).Return(&tenant, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return(nodes, nil)
+	s.env.OnActivity("CreateS3Bucket", mock.Anything, activity.CreateS3BucketParams{
+		TenantID:   tenantID,
+		Name:       internalName,
+		QuotaBytes: 1073741824,
+	}).Return(nil)
+	s.env.OnActivity("SetS3BucketLifecycle", mock.Anything, activity.SetS3BucketLifecycleParams{
+		Name:  internalName,
+		Rules: rules,
+	}).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "s3_buckets", ID: bucketID, Status: model.StatusActive,
+	}).Return(nil)
+	s.env.ExecuteWorkflow(CreateS3BucketWorkflow, bucketID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
 func (s *CreateS3BucketWorkflowTestSuite) TestGetBucketFails_SetsStatusFailed() {
 	bucketID := "test-bucket-2"
 
@@ -159,6 +201,100 @@ func (s *CreateS3BucketWorkflowTestSuite) TestSetProvisioningFails() {
 	s.Error(s.env.GetWorkflowError())
 }
 
+// ---------- UpdateS3BucketWorkflow ----------
+
+type UpdateS3BucketWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *UpdateS3BucketWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *UpdateS3BucketWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *UpdateS3BucketWorkflowTestSuite) TestAppliesPolicyAndLifecycle() {
+	bucketID := "test-bucket-1"
+	tenantID := "test-tenant-1"
+	shardID := "test-shard-1"
+	tenant := model.Tenant{ID: tenantID}
+	rules := []model.S3LifecycleRule{{ID: "uploads", AbortIncompleteMultipartUploadDays: 3}}
+	bucket := model.S3Bucket{
+		ID:             bucketID,
+		TenantID:       tenantID,
+		ShardID:        &shardID,
+		Public:         true,
+		LifecycleRules: rules,
+	}
+	internalName := tenantID + "-" + bucketID
+
+	s.env.OnActivity("GetS3BucketByID", mock.Anything, bucketID).Return(&bucket, nil)
+	s.env.OnActivity("GetTenantByID", mock.Anything, tenantID).Return(&tenant, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return([]model.Node{{ID: "node-1"}}, nil)
+	s.env.OnActivity("UpdateS3BucketPolicy", mock.Anything, activity.UpdateS3BucketPolicyParams{
+		TenantID: tenantID,
+		Name:     internalName,
+		Public:   true,
+	}).Return(nil)
+	s.env.OnActivity("SetS3BucketLifecycle", mock.Anything, activity.SetS3BucketLifecycleParams{
+		Name:  internalName,
+		Rules: rules,
+	}).Return(nil)
+	s.env.ExecuteWorkflow(UpdateS3BucketWorkflow, bucketID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *UpdateS3BucketWorkflowTestSuite) TestClearsLifecycleWhenNoRules() {
+	bucketID := "test-bucket-2"
+	tenantID := "test-tenant-1"
+	shardID := "test-shard-1"
+	tenant := model.Tenant{ID: tenantID}
+	bucket := model.S3Bucket{
+		ID:       bucketID,
+		TenantID: tenantID,
+		ShardID:  &shardID,
+	}
+	internalName := tenantID + "-" + bucketID
+
+	s.env.OnActivity("GetS3BucketByID", mock.Anything, bucketID).Return(&bucket, nil)
+	s.env.OnActivity("GetTenantByID", mock.Anything, tenantID).Return(&tenant, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return([]model.Node{{ID: "node-1"}}, nil)
+	s.env.OnActivity("UpdateS3BucketPolicy", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("SetS3BucketLifecycle", mock.Anything, activity.SetS3BucketLifecycleParams{
+		Name: internalName,
+	}).Return(nil)
+	s.env.ExecuteWorkflow(UpdateS3BucketWorkflow, bucketID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *UpdateS3BucketWorkflowTestSuite) TestLifecycleFails() {
+	bucketID := "test-bucket-3"
+	tenantID := "test-tenant-1"
+	shardID := "test-shard-1"
+	tenant := model.Tenant{ID: tenantID}
+	bucket := model.S3Bucket{
+		ID:       bucketID,
+		TenantID: tenantID,
+		ShardID:  &shardID,
+	}
+
+	s.env.OnActivity("GetS3BucketByID", mock.Anything, bucketID).Return(&bucket, nil)
+	s.env.OnActivity("GetTenantByID", mock.Anything, tenantID).Return(&tenant, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return([]model.Node{{ID: "node-1"}}, nil)
+	s.env.OnActivity("UpdateS3BucketPolicy", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("SetS3BucketLifecycle", mock.Anything, mock.Anything).Return(fmt.Errorf("rgw error"))
+	s.env.ExecuteWorkflow(UpdateS3BucketWorkflow, bucketID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
 // ---------- DeleteS3BucketWorkflow ----------
 
 type DeleteS3BucketWorkflowTestSuite struct {
@@ -260,6 +396,10 @@ func TestCreateS3BucketWorkflow(t *testing.T) {
 	suite.Run(t, new(CreateS3BucketWorkflowTestSuite))
 }
 
+func TestUpdateS3BucketWorkflow(t *testing.T) {
+	suite.Run(t, new(UpdateS3BucketWorkflowTestSuite))
+}
+
 func TestDeleteS3BucketWorkflow(t *testing.T) {
 	suite.Run(t, new(DeleteS3BucketWorkflowTestSuite))
 }
@@ -0,0 +1,159 @@
+package workflow
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// CreateSMTPRelayUserWorkflow creates an SMTP relay user's principal in the
+// Stalwart instance of its tenant's cluster and throttles it to its
+// max_messages_per_hour.
+func CreateSMTPRelayUserWorkflow(ctx workflow.Context, relayUserID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	// Set status to provisioning.
+	err := workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "smtp_relay_users",
+		ID:     relayUserID,
+		Status: model.StatusProvisioning,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var rctx activity.SMTPRelayContext
+	err = workflow.ExecuteActivity(ctx, "GetSMTPRelayContext", relayUserID).Get(ctx, &rctx)
+	if err != nil {
+		_ = setResourceFailed(ctx, "smtp_relay_users", relayUserID, err)
+		return err
+	}
+
+	// Create the principal (the activity loads the password hash itself).
+	err = workflow.ExecuteActivity(ctx, "StalwartCreateRelayUser", activity.StalwartRelayUserParams{
+		BaseURL:     rctx.StalwartURL,
+		AdminToken:  rctx.StalwartToken,
+		RelayUserID: relayUserID,
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setResourceFailed(ctx, "smtp_relay_users", relayUserID, err)
+		return err
+	}
+
+	if err := setRelayRateLimit(ctx, rctx); err != nil {
+		_ = setResourceFailed(ctx, "smtp_relay_users", relayUserID, err)
+		return err
+	}
+
+	// Set status to active.
+	return workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "smtp_relay_users",
+		ID:     relayUserID,
+		Status: model.StatusActive,
+	}).Get(ctx, nil)
+}
+
+// UpdateSMTPRelayUserWorkflow applies a relay user's changed
+// max_messages_per_hour to its Stalwart throttle.
+func UpdateSMTPRelayUserWorkflow(ctx workflow.Context, relayUserID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var rctx activity.SMTPRelayContext
+	err := workflow.ExecuteActivity(ctx, "GetSMTPRelayContext", relayUserID).Get(ctx, &rctx)
+	if err != nil {
+		_ = setResourceFailed(ctx, "smtp_relay_users", relayUserID, err)
+		return err
+	}
+
+	if err := setRelayRateLimit(ctx, rctx); err != nil {
+		_ = setResourceFailed(ctx, "smtp_relay_users", relayUserID, err)
+		return err
+	}
+
+	return workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "smtp_relay_users",
+		ID:     relayUserID,
+		Status: model.StatusActive,
+	}).Get(ctx, nil)
+}
+
+// DeleteSMTPRelayUserWorkflow removes a relay user's throttle and principal
+// from Stalwart.
+func DeleteSMTPRelayUserWorkflow(ctx workflow.Context, relayUserID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	// Set status to deleting.
+	err := workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "smtp_relay_users",
+		ID:     relayUserID,
+		Status: model.StatusDeleting,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var rctx activity.SMTPRelayContext
+	err = workflow.ExecuteActivity(ctx, "GetSMTPRelayContext", relayUserID).Get(ctx, &rctx)
+	if err != nil {
+		_ = setResourceFailed(ctx, "smtp_relay_users", relayUserID, err)
+		return err
+	}
+
+	err = workflow.ExecuteActivity(ctx, "StalwartDeleteRelayUser", activity.StalwartDeleteRelayUserParams{
+		BaseURL:    rctx.StalwartURL,
+		AdminToken: rctx.StalwartToken,
+		Username:   rctx.RelayUser.Username,
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setResourceFailed(ctx, "smtp_relay_users", relayUserID, err)
+		return err
+	}
+
+	// Set status to deleted.
+	return workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "smtp_relay_users",
+		ID:     relayUserID,
+		Status: model.StatusDeleted,
+	}).Get(ctx, nil)
+}
+
+// setRelayRateLimit installs the relay user's throttle.
+func setRelayRateLimit(ctx workflow.Context, rctx activity.SMTPRelayContext) error {
+	return workflow.ExecuteActivity(ctx, "StalwartSetRelayRateLimit", activity.StalwartRelayRateLimitParams{
+		BaseURL:            rctx.StalwartURL,
+		AdminToken:         rctx.StalwartToken,
+		Username:           rctx.RelayUser.Username,
+		MaxMessagesPerHour: rctx.RelayUser.MaxMessagesPerHour,
+	}).Get(ctx, nil)
+}
@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type SMTPRelayUserWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *SMTPRelayUserWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *SMTPRelayUserWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func relayContext(id string, perHour int) *activity.SMTPRelayContext {
+	return &activity.SMTPRelayContext{
+		RelayUser: model.SMTPRelayUser{
+			ID: id, TenantID: "test-tenant-1", Username: id, MaxMessagesPerHour: perHour,
+		},
+		StalwartURL:   "https://mail.example.com",
+		StalwartToken: "admin-token",
+	}
+}
+
+func (s *SMTPRelayUserWorkflowTestSuite) TestCreate_Success() {
+	id := "relay-abc123"
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "smtp_relay_users", ID: id, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetSMTPRelayContext", mock.Anything, id).Return(relayContext(id, 250), nil)
+	s.env.OnActivity("StalwartCreateRelayUser", mock.Anything, activity.StalwartRelayUserParams{
+		BaseURL: "https://mail.example.com", AdminToken: "admin-token", RelayUserID: id,
+	}).Return(nil)
+	s.env.OnActivity("StalwartSetRelayRateLimit", mock.Anything, activity.StalwartRelayRateLimitParams{
+		BaseURL: "https://mail.example.com", AdminToken: "admin-token", Username: id, MaxMessagesPerHour: 250,
+	}).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "smtp_relay_users", ID: id, Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(CreateSMTPRelayUserWorkflow, id)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *SMTPRelayUserWorkflowTestSuite) TestCreate_StalwartFails_SetsStatusFailed() {
+	id := "relay-abc124"
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "smtp_relay_users", ID: id, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetSMTPRelayContext", mock.Anything, id).Return(relayContext(id, 100), nil)
+	s.env.OnActivity("StalwartCreateRelayUser", mock.Anything, mock.Anything).Return(fmt.Errorf("stalwart down"))
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("smtp_relay_users", id)).Return(nil)
+
+	s.env.ExecuteWorkflow(CreateSMTPRelayUserWorkflow, id)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func (s *SMTPRelayUserWorkflowTestSuite) TestUpdate_AppliesRateLimit() {
+	id := "relay-abc125"
+
+	s.env.OnActivity("GetSMTPRelayContext", mock.Anything, id).Return(relayContext(id, 20), nil)
+	s.env.OnActivity("StalwartSetRelayRateLimit", mock.Anything, activity.StalwartRelayRateLimitParams{
+		BaseURL: "https://mail.example.com", AdminToken: "admin-token", Username: id, MaxMessagesPerHour: 20,
+	}).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "smtp_relay_users", ID: id, Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(UpdateSMTPRelayUserWorkflow, id)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *SMTPRelayUserWorkflowTestSuite) TestDelete_Success() {
+	id := "relay-abc126"
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "smtp_relay_users", ID: id, Status: model.StatusDeleting,
+	}).Return(nil)
+	s.env.OnActivity("GetSMTPRelayContext", mock.Anything, id).Return(relayContext(id, 100), nil)
+	s.env.OnActivity("StalwartDeleteRelayUser", mock.Anything, activity.StalwartDeleteRelayUserParams{
+		BaseURL: "https://mail.example.com", AdminToken: "admin-token", Username: id,
+	}).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "smtp_relay_users", ID: id, Status: model.StatusDeleted,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(DeleteSMTPRelayUserWorkflow, id)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func TestSMTPRelayUserWorkflow(t *testing.T) {
+	suite.Run(t, new(SMTPRelayUserWorkflowTestSuite))
+}
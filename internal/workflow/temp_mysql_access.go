@@ -8,6 +8,7 @@ import (
 	"go.temporal.io/sdk/workflow"
 
 	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
 )
 
 // CreateTempMySQLAccessArgs holds parameters for creating temporary MySQL access.
@@ -50,3 +51,30 @@ func CreateTempMySQLAccessWorkflow(ctx workflow.Context, args CreateTempMySQLAcc
 
 	return nil
 }
+
+// RevokeLoginSessionWorkflow drops the temporary MySQL user of a revoked login
+// session on the database shard's primary and kills its connections, so the
+// DB Admin session opened with it stops working right away.
+func RevokeLoginSessionWorkflow(ctx workflow.Context, params model.RevokeLoginSessionParams) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	primaryID, _, err := dbShardPrimary(ctx, params.ShardID)
+	if err != nil {
+		return fmt.Errorf("determine primary node: %w", err)
+	}
+
+	err = workflow.ExecuteActivity(nodeActivityCtx(ctx, primaryID), "DropTempMySQLUser", params.Username).Get(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("drop temp user %s: %w", params.Username, err)
+	}
+	return nil
+}
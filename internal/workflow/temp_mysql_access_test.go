@@ -0,0 +1,67 @@
+package workflow
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/model"
+)
+
+type RevokeLoginSessionWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *RevokeLoginSessionWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *RevokeLoginSessionWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *RevokeLoginSessionWorkflowTestSuite) mockShard() {
+	cfg, _ := json.Marshal(model.DatabaseShardConfig{PrimaryNodeID: "node-db-2"})
+	s.env.OnActivity("GetShardByID", mock.Anything, "shard-db-1").
+		Return(&model.Shard{ID: "shard-db-1", Role: model.ShardRoleDatabase, Config: cfg}, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "shard-db-1").
+		Return([]model.Node{{ID: "node-db-1"}, {ID: "node-db-2"}}, nil)
+}
+
+func (s *RevokeLoginSessionWorkflowTestSuite) TestDropsTempUser() {
+	s.mockShard()
+	s.env.OnActivity("DropTempMySQLUser", mock.Anything, "tmp_abc123").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(RevokeLoginSessionWorkflow, model.RevokeLoginSessionParams{
+		SessionID: "sess-1",
+		ShardID:   "shard-db-1",
+		Username:  "tmp_abc123",
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *RevokeLoginSessionWorkflowTestSuite) TestDropFails() {
+	s.mockShard()
+	s.env.OnActivity("DropTempMySQLUser", mock.Anything, "tmp_abc123").
+		Return(temporal.NewNonRetryableApplicationError("mysql down", "TEST", nil)).Once()
+
+	s.env.ExecuteWorkflow(RevokeLoginSessionWorkflow, model.RevokeLoginSessionParams{
+		SessionID: "sess-1",
+		ShardID:   "shard-db-1",
+		Username:  "tmp_abc123",
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func TestRevokeLoginSessionWorkflow(t *testing.T) {
+	suite.Run(t, new(RevokeLoginSessionWorkflowTestSuite))
+}
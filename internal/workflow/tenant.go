@@ -50,6 +50,16 @@ func CreateTenantWorkflow(ctx workflow.Context, tenantID string) error {
 		return noShardErr
 	}
 
+	// Allocate the tenant's UID from its cluster's range. Retries and
+	// re-runs keep the UID allocated the first time.
+	if tenant.UID == 0 {
+		err = workflow.ExecuteActivity(ctx, "AllocateTenantUID", tenantID).Get(ctx, &tenant.UID)
+		if err != nil {
+			_ = setResourceFailed(ctx, "tenants", tenantID, err)
+			return err
+		}
+	}
+
 	var nodes []model.Node
 	err = workflow.ExecuteActivity(ctx, "ListNodesByShard", *tenant.ShardID).Get(ctx, &nodes)
 	if err != nil {
@@ -501,7 +511,8 @@ func UnsuspendTenantWorkflow(ctx workflow.Context, tenantID string) error {
 // DeleteTenantWorkflow deletes a tenant and all its resources across all shards.
 //
 // Phase 1: Cross-shard resource cleanup — uses existing delete workflows for
-// databases, valkey instances, S3 buckets, zones, and email accounts.
+// databases, valkey instances, S3 buckets, zones, email accounts and SMTP
+// relay users.
 // Phase 2: Web-node cleanup — removes ULA addresses, SSH config, user account,
 // bind mounts, CephFS directory, and log directory on each shard node.
 // Phase 3: Shard convergence — triggers convergence to clean orphaned configs
@@ -553,12 +564,14 @@ func DeleteTenantWorkflow(ctx workflow.Context, tenantID string) error {
 	var s3Buckets []model.S3Bucket
 	var zones []model.Zone
 	var emailAccounts []model.EmailAccount
+	var relayUsers []model.SMTPRelayUser
 
 	_ = workflow.ExecuteActivity(ctx, "ListDatabasesByTenantID", tenantID).Get(ctx, &databases)
 	_ = workflow.ExecuteActivity(ctx, "ListValkeyInstancesByTenantID", tenantID).Get(ctx, &valkeyInstances)
 	_ = workflow.ExecuteActivity(ctx, "ListS3BucketsByTenantID", tenantID).Get(ctx, &s3Buckets)
 	_ = workflow.ExecuteActivity(ctx, "ListZonesByTenantID", tenantID).Get(ctx, &zones)
 	_ = workflow.ExecuteActivity(ctx, "ListEmailAccountsByTenantID", tenantID).Get(ctx, &emailAccounts)
+	_ = workflow.ExecuteActivity(ctx, "ListSMTPRelayUsersByTenantID", tenantID).Get(ctx, &relayUsers)
 
 	var children []ChildWorkflowSpec
 	for _, d := range databases {
@@ -596,6 +609,16 @@ func DeleteTenantWorkflow(ctx workflow.Context, tenantID string) error {
 			Arg:          ea.ID,
 		})
 	}
+	for _, u := range relayUsers {
+		if u.Status == model.StatusDeleted {
+			continue
+		}
+		children = append(children, ChildWorkflowSpec{
+			WorkflowName: "DeleteSMTPRelayUserWorkflow",
+			WorkflowID:   fmt.Sprintf("delete-smtp-relay-user-%s", u.ID),
+			Arg:          u.ID,
+		})
+	}
 
 	if errs := fanOutChildWorkflows(ctx, children); len(errs) > 0 {
 		workflow.GetLogger(ctx).Warn("phase 1: cross-shard cleanup failures (non-fatal)", "errors", joinErrors(errs))
@@ -650,12 +673,7 @@ func DeleteTenantWorkflow(ctx workflow.Context, tenantID string) error {
 	}
 
 	// ── Phase 3: Shard convergence ───────────────────────────────────────
-	convergeCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
-		WorkflowID: fmt.Sprintf("converge-shard-%s-delete-tenant-%s", *tenant.ShardID, tenantID),
-		TaskQueue:  "hosting-tasks",
-	})
-	if err := workflow.ExecuteChildWorkflow(convergeCtx, ConvergeShardWorkflow,
-		ConvergeShardParams{ShardID: *tenant.ShardID}).Get(ctx, nil); err != nil {
+	if err := executeConvergeShard(ctx, *tenant.ShardID); err != nil {
 		workflow.GetLogger(ctx).Warn("phase 3: shard convergence failed (non-fatal)", "error", err)
 	}
 
@@ -0,0 +1,302 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// TenantBrandReassignmentQuery is the workflow query name that returns the
+// current model.TenantBrandReassignment state of a
+// ReassignTenantBrandWorkflow.
+const TenantBrandReassignmentQuery = "tenant-brand-reassignment"
+
+// brandReassignmentTracker records ReassignTenantBrandWorkflow results for
+// the TenantBrandReassignmentQuery query.
+type brandReassignmentTracker struct {
+	ctx   workflow.Context
+	state model.TenantBrandReassignment
+}
+
+// record appends the result of reconfiguring one resource.
+func (t *brandReassignmentTracker) record(resourceType, resourceID, name, action string, err error) {
+	step := model.TenantBrandReassignmentStep{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Name:         name,
+		Action:       action,
+		Status:       model.MigrationStepDone,
+	}
+	if err != nil {
+		step.Status = model.MigrationStepFailed
+		step.Error = err.Error()
+		workflow.GetLogger(t.ctx).Warn("brand reassignment step failed",
+			"resourceType", resourceType, "resourceID", resourceID, "error", err)
+	}
+	t.state.Steps = append(t.state.Steps, step)
+}
+
+// finish sets the final status: failed if err is set or any step failed.
+func (t *brandReassignmentTracker) finish(err error) error {
+	if err == nil {
+		failed := 0
+		for _, s := range t.state.Steps {
+			if s.Status == model.MigrationStepFailed {
+				failed++
+			}
+		}
+		if failed > 0 {
+			err = fmt.Errorf("%d of %d reconfiguration steps failed", failed, len(t.state.Steps))
+		}
+	}
+
+	now := workflow.Now(t.ctx)
+	t.state.FinishedAt = &now
+	if err != nil {
+		t.state.Status = model.MigrationFailed
+		t.state.Error = err.Error()
+		return err
+	}
+	t.state.Status = model.MigrationCompleted
+	return nil
+}
+
+// ReassignTenantBrandWorkflow moves a tenant to another brand within its
+// cluster. It switches the tenant and its zones to the target brand, then
+// reconfigures what derives from the brand: the SOA and NS records of the
+// tenant's zones, the Stalwart domain and mail DNS records (MX, SPF, DKIM,
+// DMARC) of its email domains, service hostnames when the base hostname
+// changes, and Let's Encrypt certificates when the base hostname or the ACME
+// CA changes. Reconfiguration failures are recorded per resource and do not
+// stop the remaining steps; the result is reported via
+// TenantBrandReassignmentQuery.
+func ReassignTenantBrandWorkflow(ctx workflow.Context, params core.ReassignTenantBrandParams) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 2 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	tr := &brandReassignmentTracker{ctx: ctx, state: model.TenantBrandReassignment{
+		TenantID:      params.TenantID,
+		TargetBrandID: params.BrandID,
+		Status:        model.MigrationRunning,
+		Steps:         []model.TenantBrandReassignmentStep{},
+		StartedAt:     workflow.Now(ctx),
+	}}
+	if err := workflow.SetQueryHandler(ctx, TenantBrandReassignmentQuery, func() (model.TenantBrandReassignment, error) {
+		return tr.state, nil
+	}); err != nil {
+		return fmt.Errorf("set query handler: %w", err)
+	}
+
+	// Read the tenant's brand-dependent resources before the brand changes.
+	var bc activity.BrandReassignmentContext
+	err := workflow.ExecuteActivity(ctx, "GetBrandReassignmentContext", params.TenantID, params.BrandID).Get(ctx, &bc)
+	if err != nil {
+		return tr.finish(err)
+	}
+	tr.state.SourceBrandID = bc.SourceBrand.ID
+	source, target := bc.SourceBrand, bc.TargetBrand
+
+	err = workflow.ExecuteActivity(ctx, "UpdateTenantBrand", activity.UpdateTenantBrandParams{
+		TenantID:   params.TenantID,
+		BrandID:    params.BrandID,
+		ResellerID: params.ResellerID,
+	}).Get(ctx, nil)
+	tr.record(model.BrandReassignTenant, params.TenantID, "", "set brand", err)
+	if err != nil {
+		return tr.finish(err)
+	}
+
+	// Zones: replace the SOA and NS records rendered from the source brand.
+	if len(bc.Zones) > 0 {
+		var sourceTemplates, targetTemplates []model.BrandZoneTemplate
+		err := workflow.ExecuteActivity(ctx, "ListBrandZoneTemplates", source.ID).Get(ctx, &sourceTemplates)
+		if err == nil {
+			err = workflow.ExecuteActivity(ctx, "ListBrandZoneTemplates", target.ID).Get(ctx, &targetTemplates)
+		}
+		for _, zone := range bc.Zones {
+			zoneErr := err
+			if zoneErr == nil {
+				zoneErr = reassignZoneApexRecords(ctx, zone.Name, source, sourceTemplates, target, targetTemplates)
+			}
+			tr.record(model.BrandReassignZone, zone.ID, zone.Name, "render SOA and NS records", zoneErr)
+		}
+	}
+
+	// Email domains: make sure Stalwart serves the domain and re-render the
+	// mail DNS records from the target brand's mail settings.
+	for _, fqdn := range bc.EmailFQDNs {
+		err := reassignEmailDomain(ctx, fqdn)
+		tr.record(model.BrandReassignEmailDomain, fqdn.ID, fqdn.Name, "update Stalwart domain and mail DNS records", err)
+	}
+
+	baseHostnameChanged := source.BaseHostname != target.BaseHostname
+
+	// Service hostnames live under the brand's base hostname.
+	if baseHostnameChanged {
+		for _, webrootID := range bc.ServiceHostnameWebroots {
+			hostname, err := moveWebrootServiceHostname(ctx, webrootID, source.BaseHostname)
+			tr.record(model.BrandReassignServiceHostname, webrootID, hostname, "move webroot service hostname", err)
+		}
+
+		err := moveTenantServiceHostnames(ctx, bc.Tenant.ID, source.BaseHostname)
+		tr.record(model.BrandReassignServiceHostname, bc.Tenant.ID, "", "move tenant service hostnames", err)
+	}
+
+	// Certificates are re-issued through the target brand's ACME account.
+	if baseHostnameChanged || bc.ACMECAChanged {
+		for _, fqdn := range bc.CertFQDNs {
+			childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+				WorkflowID: "provision-le-cert-" + fqdn.ID,
+			})
+			err := workflow.ExecuteChildWorkflow(childCtx, ProvisionLECertWorkflow, fqdn.ID).Get(ctx, nil)
+			tr.record(model.BrandReassignCertificate, fqdn.ID, fqdn.Name, "re-issue certificate", err)
+		}
+	}
+
+	return tr.finish(nil)
+}
+
+// reassignZoneApexRecords deletes a zone's SOA and NS records as rendered
+// from the source brand and writes those of the target brand.
+func reassignZoneApexRecords(ctx workflow.Context, zoneName string, source model.Brand, sourceTemplates []model.BrandZoneTemplate, target model.Brand, targetTemplates []model.BrandZoneTemplate) error {
+	var domainID int
+	err := workflow.ExecuteActivity(ctx, "GetDNSZoneIDByName", zoneName).Get(ctx, &domainID)
+	if err != nil {
+		return err
+	}
+
+	oldSOATemplate, oldNSTemplates, _ := expandZoneTemplates(ctx, zoneName, source, sourceTemplates)
+	oldSOA, oldNS := zoneApexRecords(domainID, zoneName, source, oldSOATemplate, oldNSTemplates)
+	newSOATemplate, newNSTemplates, _ := expandZoneTemplates(ctx, zoneName, target, targetTemplates)
+	newSOA, newNS := zoneApexRecords(domainID, zoneName, target, newSOATemplate, newNSTemplates)
+
+	// DeleteDNSRecord removes every record of a name and type, so each NS
+	// owner name is deleted once.
+	deletes := []activity.DeleteDNSRecordParams{{DomainID: domainID, Name: oldSOA.Name, Type: "SOA"}}
+	seen := map[string]bool{}
+	for _, ns := range append(oldNS, newNS...) {
+		if !seen[ns.Name] {
+			seen[ns.Name] = true
+			deletes = append(deletes, activity.DeleteDNSRecordParams{DomainID: domainID, Name: ns.Name, Type: "NS"})
+		}
+	}
+	for _, d := range deletes {
+		if err := workflow.ExecuteActivity(ctx, "DeleteDNSRecord", d).Get(ctx, nil); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range append([]activity.WriteDNSRecordParams{newSOA}, newNS...) {
+		if err := workflow.ExecuteActivity(ctx, "WriteDNSRecord", r).Get(ctx, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reassignEmailDomain re-creates the Stalwart domain (idempotent) and the
+// mail DNS records of an FQDN after its tenant's brand changed.
+func reassignEmailDomain(ctx workflow.Context, fqdn activity.NamedResource) error {
+	var sctx activity.StalwartContext
+	err := workflow.ExecuteActivity(ctx, "GetStalwartContext", fqdn.ID).Get(ctx, &sctx)
+	if err != nil {
+		return err
+	}
+
+	err = workflow.ExecuteActivity(ctx, "StalwartCreateDomain", activity.StalwartDomainParams{
+		BaseURL:    sctx.StalwartURL,
+		AdminToken: sctx.StalwartToken,
+		Domain:     sctx.FQDN,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = workflow.ExecuteActivity(ctx, "AutoDeleteEmailDNSRecords", sctx.FQDN).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	mailHostname := sctx.MailHostname
+	if mailHostname == "" {
+		mailHostname = "mail." + sctx.FQDN
+	}
+	return workflow.ExecuteActivity(ctx, "AutoCreateEmailDNSRecords", activity.AutoCreateEmailDNSRecordsParams{
+		FQDN:          sctx.FQDN,
+		MailHostname:  mailHostname,
+		SPFIncludes:   sctx.SPFIncludes,
+		DKIMSelector:  sctx.DKIMSelector,
+		DKIMPublicKey: sctx.DKIMPublicKey,
+		DMARCPolicy:   sctx.DMARCPolicy,
+		SourceFQDNID:  sctx.FQDNID,
+	}).Get(ctx, nil)
+}
+
+// moveWebrootServiceHostname removes a webroot's service hostname under the
+// old base hostname and re-renders the webroot, which sets up the new one.
+// It returns the new service hostname.
+func moveWebrootServiceHostname(ctx workflow.Context, webrootID, oldBaseHostname string) (string, error) {
+	var wctx activity.WebrootContext
+	err := workflow.ExecuteActivity(ctx, "GetWebrootContext", webrootID).Get(ctx, &wctx)
+	if err != nil {
+		return "", err
+	}
+
+	if oldBaseHostname != "" {
+		teardownServiceHostname(ctx, wctx, fmt.Sprintf("%s.%s.%s", webrootID, wctx.Tenant.ID, oldBaseHostname))
+	}
+
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID: "update-webroot-" + webrootID,
+	})
+	err = workflow.ExecuteChildWorkflow(childCtx, UpdateWebrootWorkflow, webrootID).Get(ctx, nil)
+	return webrootServiceHostname(wctx), err
+}
+
+// moveTenantServiceHostnames removes the tenant's service hostnames (e.g.
+// ssh.<tenant>.<base>) under the old base hostname and creates them under
+// the new one.
+func moveTenantServiceHostnames(ctx workflow.Context, tenantID, oldBaseHostname string) error {
+	var services []model.TenantService
+	err := workflow.ExecuteActivity(ctx, "GetTenantServicesByTenantID", tenantID).Get(ctx, &services)
+	if err != nil {
+		return err
+	}
+	if len(services) == 0 {
+		return nil
+	}
+
+	if oldBaseHostname != "" {
+		entries := make([]activity.ServiceHostnameEntry, 0, len(services))
+		for _, svc := range services {
+			entries = append(entries, activity.ServiceHostnameEntry{Service: svc.Service})
+		}
+		err = workflow.ExecuteActivity(ctx, "DeleteServiceHostnameRecords", activity.ServiceHostnameParams{
+			BaseHostname: oldBaseHostname,
+			TenantName:   tenantID,
+			Services:     entries,
+		}).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID: "update-service-hostnames-" + tenantID,
+	})
+	return workflow.ExecuteChildWorkflow(childCtx, UpdateServiceHostnamesWorkflow, tenantID).Get(ctx, nil)
+}
@@ -0,0 +1,163 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/core"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ---------- ReassignTenantBrandWorkflow ----------
+
+type ReassignTenantBrandWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *ReassignTenantBrandWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+	s.env.RegisterWorkflow(ProvisionLECertWorkflow)
+}
+
+func (s *ReassignTenantBrandWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *ReassignTenantBrandWorkflowTestSuite) expectContext(bc activity.BrandReassignmentContext) {
+	s.env.OnActivity("GetBrandReassignmentContext", mock.Anything, "tenant-1", "brand-2").Return(&bc, nil)
+	s.env.OnActivity("UpdateTenantBrand", mock.Anything, activity.UpdateTenantBrandParams{
+		TenantID: "tenant-1", BrandID: "brand-2",
+	}).Return(nil)
+}
+
+func (s *ReassignTenantBrandWorkflowTestSuite) result() model.TenantBrandReassignment {
+	val, err := s.env.QueryWorkflow(TenantBrandReassignmentQuery)
+	s.Require().NoError(err)
+	var r model.TenantBrandReassignment
+	s.Require().NoError(val.Get(&r))
+	return r
+}
+
+func (s *ReassignTenantBrandWorkflowTestSuite) TestReconfiguresZonesEmailAndCerts() {
+	s.expectContext(activity.BrandReassignmentContext{
+		Tenant:        model.Tenant{ID: "tenant-1", BrandID: "brand-1"},
+		SourceBrand:   model.Brand{ID: "brand-1", BaseHostname: "hosting.test", PrimaryNS: "ns1.old.test", SecondaryNS: "ns2.old.test", HostmasterEmail: "hostmaster.old.test"},
+		TargetBrand:   model.Brand{ID: "brand-2", BaseHostname: "hosting.test", PrimaryNS: "ns1.new.test", SecondaryNS: "ns2.new.test", HostmasterEmail: "hostmaster.new.test"},
+		ACMECAChanged: true,
+		Zones:         []activity.NamedResource{{ID: "zone-1", Name: "example.com"}},
+		EmailFQDNs:    []activity.NamedResource{{ID: "fqdn-1", Name: "example.com"}},
+		CertFQDNs:     []activity.NamedResource{{ID: "fqdn-1", Name: "example.com"}},
+	})
+
+	// Zone apex records.
+	s.env.OnActivity("ListBrandZoneTemplates", mock.Anything, "brand-1").Return([]model.BrandZoneTemplate{}, nil)
+	s.env.OnActivity("ListBrandZoneTemplates", mock.Anything, "brand-2").Return([]model.BrandZoneTemplate{}, nil)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(7, nil)
+	s.env.OnActivity("DeleteDNSRecord", mock.Anything, activity.DeleteDNSRecordParams{DomainID: 7, Name: "example.com", Type: "SOA"}).Return(nil)
+	s.env.OnActivity("DeleteDNSRecord", mock.Anything, activity.DeleteDNSRecordParams{DomainID: 7, Name: "example.com", Type: "NS"}).Return(nil)
+	s.env.OnActivity("WriteDNSRecord", mock.Anything, activity.WriteDNSRecordParams{
+		DomainID: 7, Name: "example.com", Type: "SOA", Content: "ns1.new.test hostmaster.new.test 1 10800 3600 604800 300", TTL: 86400,
+	}).Return(nil)
+	s.env.OnActivity("WriteDNSRecord", mock.Anything, activity.WriteDNSRecordParams{
+		DomainID: 7, Name: "example.com", Type: "NS", Content: "ns1.new.test", TTL: 86400,
+	}).Return(nil)
+	s.env.OnActivity("WriteDNSRecord", mock.Anything, activity.WriteDNSRecordParams{
+		DomainID: 7, Name: "example.com", Type: "NS", Content: "ns2.new.test", TTL: 86400,
+	}).Return(nil)
+
+	// Email domain.
+	s.env.OnActivity("GetStalwartContext", mock.Anything, "fqdn-1").Return(&activity.StalwartContext{
+		StalwartURL: "http://stalwart:8080", StalwartToken: "token", FQDNID: "fqdn-1", FQDN: "example.com",
+		MailHostname: "mail.new.test", SPFIncludes: "_spf.new.test", DMARCPolicy: "quarantine",
+	}, nil)
+	s.env.OnActivity("StalwartCreateDomain", mock.Anything, activity.StalwartDomainParams{
+		BaseURL: "http://stalwart:8080", AdminToken: "token", Domain: "example.com",
+	}).Return(nil)
+	s.env.OnActivity("AutoDeleteEmailDNSRecords", mock.Anything, "example.com").Return(nil)
+	s.env.OnActivity("AutoCreateEmailDNSRecords", mock.Anything, activity.AutoCreateEmailDNSRecordsParams{
+		FQDN: "example.com", MailHostname: "mail.new.test", SPFIncludes: "_spf.new.test",
+		DMARCPolicy: "quarantine", SourceFQDNID: "fqdn-1",
+	}).Return(nil)
+
+	// The ACME CA changed, so the certificate is re-issued.
+	s.env.OnWorkflow(ProvisionLECertWorkflow, mock.Anything, "fqdn-1").Return(nil)
+
+	s.env.ExecuteWorkflow(ReassignTenantBrandWorkflow, core.ReassignTenantBrandParams{TenantID: "tenant-1", BrandID: "brand-2"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	r := s.result()
+	s.Equal(model.MigrationCompleted, r.Status)
+	s.Equal("brand-1", r.SourceBrandID)
+	s.Require().Len(r.Steps, 4)
+	for _, step := range r.Steps {
+		s.Equal(model.MigrationStepDone, step.Status, step.ResourceType)
+	}
+	s.Equal(model.BrandReassignCertificate, r.Steps[3].ResourceType)
+}
+
+func (s *ReassignTenantBrandWorkflowTestSuite) TestStepFailureDoesNotStopOthers() {
+	s.expectContext(activity.BrandReassignmentContext{
+		Tenant:      model.Tenant{ID: "tenant-1", BrandID: "brand-1"},
+		SourceBrand: model.Brand{ID: "brand-1", BaseHostname: "hosting.test"},
+		TargetBrand: model.Brand{ID: "brand-2", BaseHostname: "hosting.test"},
+		EmailFQDNs: []activity.NamedResource{
+			{ID: "fqdn-1", Name: "a.example.com"},
+			{ID: "fqdn-2", Name: "b.example.com"},
+		},
+		CertFQDNs: []activity.NamedResource{{ID: "fqdn-1", Name: "a.example.com"}},
+	})
+
+	s.env.OnActivity("GetStalwartContext", mock.Anything, "fqdn-1").Return(nil, errors.New("db down"))
+	s.env.OnActivity("GetStalwartContext", mock.Anything, "fqdn-2").Return(&activity.StalwartContext{
+		FQDNID: "fqdn-2", FQDN: "b.example.com",
+	}, nil)
+	s.env.OnActivity("StalwartCreateDomain", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("AutoDeleteEmailDNSRecords", mock.Anything, "b.example.com").Return(nil)
+	s.env.OnActivity("AutoCreateEmailDNSRecords", mock.Anything, activity.AutoCreateEmailDNSRecordsParams{
+		FQDN: "b.example.com", MailHostname: "mail.b.example.com", SourceFQDNID: "fqdn-2",
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(ReassignTenantBrandWorkflow, core.ReassignTenantBrandParams{TenantID: "tenant-1", BrandID: "brand-2"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+
+	r := s.result()
+	s.Equal(model.MigrationFailed, r.Status)
+	s.Contains(r.Error, "1 of 3")
+	// Same base hostname and ACME CA: no certificate step.
+	s.Require().Len(r.Steps, 3)
+	s.Equal(model.MigrationStepFailed, r.Steps[1].Status)
+	s.Equal(model.MigrationStepDone, r.Steps[2].Status)
+}
+
+func (s *ReassignTenantBrandWorkflowTestSuite) TestTenantUpdateFails() {
+	s.env.OnActivity("GetBrandReassignmentContext", mock.Anything, "tenant-1", "brand-2").Return(&activity.BrandReassignmentContext{
+		Tenant:      model.Tenant{ID: "tenant-1", BrandID: "brand-1"},
+		SourceBrand: model.Brand{ID: "brand-1"},
+		TargetBrand: model.Brand{ID: "brand-2"},
+		Zones:       []activity.NamedResource{{ID: "zone-1", Name: "example.com"}},
+	}, nil)
+	s.env.OnActivity("UpdateTenantBrand", mock.Anything, mock.Anything).Return(errors.New("db down"))
+
+	s.env.ExecuteWorkflow(ReassignTenantBrandWorkflow, core.ReassignTenantBrandParams{TenantID: "tenant-1", BrandID: "brand-2"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+
+	r := s.result()
+	s.Equal(model.MigrationFailed, r.Status)
+	s.Require().Len(r.Steps, 1)
+	s.Equal(model.BrandReassignTenant, r.Steps[0].ResourceType)
+}
+
+func TestReassignTenantBrandWorkflow(t *testing.T) {
+	suite.Run(t, new(ReassignTenantBrandWorkflowTestSuite))
+}
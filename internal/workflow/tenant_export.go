@@ -0,0 +1,298 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// exportActivityCtx is nodeActivityCtx with timeouts long enough to archive
+// and upload a whole tenant.
+func exportActivityCtx(ctx workflow.Context, nodeID string) workflow.Context {
+	ctx = nodeActivityCtx(ctx, nodeID)
+	ao := workflow.GetActivityOptions(ctx)
+	ao.StartToCloseTimeout = time.Hour
+	ao.ScheduleToCloseTimeout = 3 * time.Hour
+	return workflow.WithActivityOptions(ctx, ao)
+}
+
+// ExportTenantWorkflow packs all of a tenant's data into a single archive and
+// uploads it to the export bucket. Per-resource data is produced by the
+// regular backup activities (CreateWebBackup, CreateMySQLBackup,
+// DumpValkeyData) into a staging directory on the web shard's shared backup
+// storage; the web node then adds a manifest.json of the tenant's
+// configuration, tars the directory and uploads it through a presigned URL.
+// The archive is removed from object storage by CleanupTenantExportsWorkflow
+// once it expires.
+func ExportTenantWorkflow(ctx workflow.Context, exportID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	err := workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "tenant_exports",
+		ID:     exportID,
+		Status: model.StatusProvisioning,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var export model.TenantExport
+	err = workflow.ExecuteActivity(ctx, "GetTenantExportByID", exportID).Get(ctx, &export)
+	if err != nil {
+		_ = setResourceFailed(ctx, "tenant_exports", exportID, err)
+		return err
+	}
+
+	var tenant model.Tenant
+	err = workflow.ExecuteActivity(ctx, "GetTenantByID", export.TenantID).Get(ctx, &tenant)
+	if err != nil {
+		_ = setResourceFailed(ctx, "tenant_exports", exportID, err)
+		return err
+	}
+	if tenant.ShardID == nil {
+		noShardErr := fmt.Errorf("tenant %s has no shard assigned", tenant.ID)
+		_ = setResourceFailed(ctx, "tenant_exports", exportID, noShardErr)
+		return noShardErr
+	}
+
+	var nodes []model.Node
+	err = workflow.ExecuteActivity(ctx, "ListNodesByShard", *tenant.ShardID).Get(ctx, &nodes)
+	if err != nil {
+		_ = setResourceFailed(ctx, "tenant_exports", exportID, err)
+		return err
+	}
+	if len(nodes) == 0 {
+		noNodesErr := fmt.Errorf("no nodes found for shard %s", *tenant.ShardID)
+		_ = setResourceFailed(ctx, "tenant_exports", exportID, noNodesErr)
+		return noNodesErr
+	}
+	webNodeID := nodes[0].ID
+
+	stagingDir := fmt.Sprintf("/var/backups/hosting/%s/exports/%s", tenant.ID, exportID)
+	archivePath := stagingDir + ".tar.gz"
+	objectKey := fmt.Sprintf("tenant-exports/%s/%s.tar.gz", tenant.ID, exportID)
+
+	result, err := exportTenantData(ctx, tenant, webNodeID, stagingDir, archivePath, objectKey)
+
+	// Local files are only needed until the upload finishes.
+	_ = workflow.ExecuteActivity(nodeActivityCtx(ctx, webNodeID), "CleanupTenantExport", activity.CleanupTenantExportParams{
+		StagingDir:  stagingDir,
+		ArchivePath: archivePath,
+	}).Get(ctx, nil)
+
+	if err != nil {
+		_ = setResourceFailed(ctx, "tenant_exports", exportID, err)
+		return err
+	}
+
+	err = workflow.ExecuteActivity(ctx, "UpdateTenantExportResult", activity.UpdateTenantExportResultParams{
+		ID:          exportID,
+		ObjectKey:   objectKey,
+		SizeBytes:   result.SizeBytes,
+		CompletedAt: workflow.Now(ctx),
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setResourceFailed(ctx, "tenant_exports", exportID, err)
+		return err
+	}
+
+	return workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "tenant_exports",
+		ID:     exportID,
+		Status: model.StatusActive,
+	}).Get(ctx, nil)
+}
+
+// exportTenantData dumps every resource of the tenant into stagingDir, builds
+// the manifest, archives it on the web node and uploads the archive.
+func exportTenantData(ctx workflow.Context, tenant model.Tenant, webNodeID, stagingDir, archivePath, objectKey string) (*activity.BackupResult, error) {
+	manifest := model.TenantExportManifest{
+		TenantID:   tenant.ID,
+		ExportedAt: workflow.Now(ctx),
+		Excluded:   []string{"email mailbox contents (account configuration only)", "S3 bucket contents"},
+	}
+
+	err := workflow.ExecuteActivity(ctx, "ListWebrootsByTenantID", tenant.ID).Get(ctx, &manifest.Webroots)
+	if err != nil {
+		return nil, fmt.Errorf("list webroots: %w", err)
+	}
+	for _, webroot := range manifest.Webroots {
+		var fqdns []model.FQDN
+		err = workflow.ExecuteActivity(ctx, "ListFQDNsByWebrootID", webroot.ID).Get(ctx, &fqdns)
+		if err != nil {
+			return nil, fmt.Errorf("list fqdns for webroot %s: %w", webroot.ID, err)
+		}
+		manifest.FQDNs = append(manifest.FQDNs, fqdns...)
+
+		file := fmt.Sprintf("webroots/%s.tar.gz", webroot.ID)
+		err = workflow.ExecuteActivity(exportActivityCtx(ctx, webNodeID), "CreateWebBackup", activity.CreateWebBackupParams{
+			TenantName:  tenant.ID,
+			WebrootName: webroot.ID,
+			BackupPath:  stagingDir + "/" + file,
+		}).Get(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("back up webroot %s: %w", webroot.ID, err)
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	var zones []model.Zone
+	err = workflow.ExecuteActivity(ctx, "ListZonesByTenantID", tenant.ID).Get(ctx, &zones)
+	if err != nil {
+		return nil, fmt.Errorf("list zones: %w", err)
+	}
+	for _, zone := range zones {
+		var records []model.ZoneRecord
+		err = workflow.ExecuteActivity(ctx, "ListZoneRecordsByZoneID", zone.ID).Get(ctx, &records)
+		if err != nil {
+			return nil, fmt.Errorf("list records for zone %s: %w", zone.ID, err)
+		}
+		manifest.Zones = append(manifest.Zones, model.TenantExportZone{Zone: zone, Records: records})
+	}
+
+	err = workflow.ExecuteActivity(ctx, "ListCronJobsByTenant", tenant.ID).Get(ctx, &manifest.CronJobs)
+	if err != nil {
+		return nil, fmt.Errorf("list cron jobs: %w", err)
+	}
+	err = workflow.ExecuteActivity(ctx, "ListDaemonsByTenant", tenant.ID).Get(ctx, &manifest.Daemons)
+	if err != nil {
+		return nil, fmt.Errorf("list daemons: %w", err)
+	}
+	err = workflow.ExecuteActivity(ctx, "ListEmailAccountsByTenantID", tenant.ID).Get(ctx, &manifest.EmailAccounts)
+	if err != nil {
+		return nil, fmt.Errorf("list email accounts: %w", err)
+	}
+
+	err = workflow.ExecuteActivity(ctx, "ListDatabasesByTenantID", tenant.ID).Get(ctx, &manifest.Databases)
+	if err != nil {
+		return nil, fmt.Errorf("list databases: %w", err)
+	}
+	for _, database := range manifest.Databases {
+		// Like CreateBackupWorkflow, dump from the web node so the file
+		// lands on the shard's shared backup storage.
+		file := fmt.Sprintf("databases/%s.sql.gz", database.ID)
+		err = workflow.ExecuteActivity(exportActivityCtx(ctx, webNodeID), "CreateMySQLBackup", activity.CreateMySQLBackupParams{
+			DatabaseName: database.ID,
+			BackupPath:   stagingDir + "/" + file,
+		}).Get(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("dump database %s: %w", database.ID, err)
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	err = workflow.ExecuteActivity(ctx, "ListValkeyInstancesByTenantID", tenant.ID).Get(ctx, &manifest.ValkeyInstances)
+	if err != nil {
+		return nil, fmt.Errorf("list valkey instances: %w", err)
+	}
+	for i, instance := range manifest.ValkeyInstances {
+		// Password hashes are credentials, not tenant data.
+		manifest.ValkeyInstances[i].PasswordHash = ""
+		if instance.ShardID == nil {
+			continue
+		}
+		file := fmt.Sprintf("valkey/%s.rdb", instance.ID)
+		if err := exportValkeyData(ctx, instance, webNodeID, stagingDir, file, objectKey); err != nil {
+			return nil, fmt.Errorf("dump valkey instance %s: %w", instance.ID, err)
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, temporal.NewNonRetryableApplicationError("marshal export manifest", "MARSHAL_ERROR", err)
+	}
+
+	var result activity.BackupResult
+	webCtx := exportActivityCtx(ctx, webNodeID)
+	err = workflow.ExecuteActivity(webCtx, "CreateTenantExportArchive", activity.CreateTenantExportArchiveParams{
+		StagingDir:  stagingDir,
+		ArchivePath: archivePath,
+		Manifest:    manifestJSON,
+	}).Get(ctx, &result)
+	if err != nil {
+		return nil, fmt.Errorf("create export archive: %w", err)
+	}
+
+	var uploadURL string
+	err = workflow.ExecuteActivity(ctx, "PresignTenantExportUpload", objectKey).Get(ctx, &uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("presign export upload: %w", err)
+	}
+	err = workflow.ExecuteActivity(webCtx, "UploadBackupFile", activity.UploadBackupFileParams{
+		Path: archivePath,
+		URL:  uploadURL,
+	}).Get(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("upload export archive: %w", err)
+	}
+
+	return &result, nil
+}
+
+// exportValkeyData dumps a Valkey instance into the export staging directory.
+// Valkey nodes have no shared backup storage, so the RDB file is dumped
+// locally and relayed to the web node through a temporary object next to the
+// export archive.
+func exportValkeyData(ctx workflow.Context, instance model.ValkeyInstance, webNodeID, stagingDir, file, objectKey string) error {
+	var nodes []model.Node
+	err := workflow.ExecuteActivity(ctx, "ListNodesByShard", *instance.ShardID).Get(ctx, &nodes)
+	if err != nil {
+		return fmt.Errorf("list nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("valkey shard %s has no nodes", *instance.ShardID)
+	}
+	valkeyCtx := exportActivityCtx(ctx, nodes[0].ID)
+	dumpPath := stagingDir + "/" + file
+	tempKey := strings.TrimSuffix(objectKey, ".tar.gz") + "/" + file
+
+	err = workflow.ExecuteActivity(valkeyCtx, "DumpValkeyData", activity.DumpValkeyDataParams{
+		Name:     instance.ID,
+		Port:     instance.Port,
+		DumpPath: dumpPath,
+	}).Get(ctx, nil)
+	if err == nil {
+		var uploadURL string
+		err = workflow.ExecuteActivity(ctx, "PresignTenantExportUpload", tempKey).Get(ctx, &uploadURL)
+		if err == nil {
+			err = workflow.ExecuteActivity(valkeyCtx, "UploadBackupFile", activity.UploadBackupFileParams{
+				Path: dumpPath,
+				URL:  uploadURL,
+			}).Get(ctx, nil)
+		}
+	}
+	_ = workflow.ExecuteActivity(nodeActivityCtx(ctx, nodes[0].ID), "CleanupTenantExport", activity.CleanupTenantExportParams{
+		StagingDir: stagingDir,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	var downloadURL string
+	err = workflow.ExecuteActivity(ctx, "PresignTenantExportDownload", tempKey).Get(ctx, &downloadURL)
+	if err == nil {
+		err = workflow.ExecuteActivity(exportActivityCtx(ctx, webNodeID), "DownloadBackupFile", activity.DownloadBackupFileParams{
+			URL:  downloadURL,
+			Path: dumpPath,
+		}).Get(ctx, nil)
+	}
+	_ = workflow.ExecuteActivity(ctx, "DeleteTenantExportObject", tempKey).Get(ctx, nil)
+	return err
+}
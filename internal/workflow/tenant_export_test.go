@@ -0,0 +1,157 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type ExportTenantWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *ExportTenantWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *ExportTenantWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *ExportTenantWorkflowTestSuite) mockTenant(exportID, tenantID, shardID string) {
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "tenant_exports", ID: exportID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetTenantExportByID", mock.Anything, exportID).Return(&model.TenantExport{
+		ID: exportID, TenantID: tenantID,
+	}, nil)
+	s.env.OnActivity("GetTenantByID", mock.Anything, tenantID).Return(&model.Tenant{
+		ID: tenantID, ShardID: &shardID,
+	}, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return([]model.Node{{ID: "web-node-1"}}, nil)
+}
+
+func (s *ExportTenantWorkflowTestSuite) TestSuccess() {
+	exportID := "export-1"
+	tenantID := "t1"
+	dbShardID := "db-shard-1"
+	valkeyShardID := "valkey-shard-1"
+	staging := "/var/backups/hosting/t1/exports/export-1"
+
+	s.mockTenant(exportID, tenantID, "web-shard-1")
+	s.env.OnActivity("ListWebrootsByTenantID", mock.Anything, tenantID).Return([]model.Webroot{{ID: "wr1"}}, nil)
+	s.env.OnActivity("ListFQDNsByWebrootID", mock.Anything, "wr1").Return([]model.FQDN{{ID: "f1", FQDN: "example.com"}}, nil)
+	s.env.OnActivity("CreateWebBackup", mock.Anything, activity.CreateWebBackupParams{
+		TenantName: tenantID, WebrootName: "wr1", BackupPath: staging + "/webroots/wr1.tar.gz",
+	}).Return(&activity.BackupResult{}, nil)
+	s.env.OnActivity("ListZonesByTenantID", mock.Anything, tenantID).Return([]model.Zone{{ID: "z1", Name: "example.com"}}, nil)
+	s.env.OnActivity("ListZoneRecordsByZoneID", mock.Anything, "z1").Return([]model.ZoneRecord{{ID: "r1", Type: "A"}}, nil)
+	s.env.OnActivity("ListCronJobsByTenant", mock.Anything, tenantID).Return([]model.CronJob{{ID: "cron1"}}, nil)
+	s.env.OnActivity("ListDaemonsByTenant", mock.Anything, tenantID).Return([]model.Daemon{{ID: "d1"}}, nil)
+	s.env.OnActivity("ListEmailAccountsByTenantID", mock.Anything, tenantID).Return([]model.EmailAccount{}, nil)
+	s.env.OnActivity("ListDatabasesByTenantID", mock.Anything, tenantID).Return([]model.Database{{ID: "db1", ShardID: &dbShardID}}, nil)
+	s.env.OnActivity("CreateMySQLBackup", mock.Anything, activity.CreateMySQLBackupParams{
+		DatabaseName: "db1", BackupPath: staging + "/databases/db1.sql.gz",
+	}).Return(&activity.BackupResult{}, nil)
+	s.env.OnActivity("ListValkeyInstancesByTenantID", mock.Anything, tenantID).Return([]model.ValkeyInstance{
+		{ID: "kv1", ShardID: &valkeyShardID, Port: 6380, PasswordHash: "secret-hash"},
+	}, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, valkeyShardID).Return([]model.Node{{ID: "valkey-node-1"}}, nil)
+	s.env.OnActivity("DumpValkeyData", mock.Anything, activity.DumpValkeyDataParams{
+		Name: "kv1", Port: 6380, DumpPath: staging + "/valkey/kv1.rdb",
+	}).Return(nil)
+	// The RDB file is relayed to the web node through a temporary object.
+	tempKey := "tenant-exports/t1/export-1/valkey/kv1.rdb"
+	s.env.OnActivity("PresignTenantExportUpload", mock.Anything, tempKey).Return("https://s3.example/put-rdb", nil)
+	s.env.OnActivity("UploadBackupFile", mock.Anything, activity.UploadBackupFileParams{
+		Path: staging + "/valkey/kv1.rdb", URL: "https://s3.example/put-rdb",
+	}).Return(nil)
+	s.env.OnActivity("CleanupTenantExport", mock.Anything, activity.CleanupTenantExportParams{
+		StagingDir: staging,
+	}).Return(nil)
+	s.env.OnActivity("PresignTenantExportDownload", mock.Anything, tempKey).Return("https://s3.example/get-rdb", nil)
+	s.env.OnActivity("DownloadBackupFile", mock.Anything, activity.DownloadBackupFileParams{
+		URL: "https://s3.example/get-rdb", Path: staging + "/valkey/kv1.rdb",
+	}).Return(nil)
+	s.env.OnActivity("DeleteTenantExportObject", mock.Anything, tempKey).Return(nil)
+
+	var manifest model.TenantExportManifest
+	s.env.OnActivity("CreateTenantExportArchive", mock.Anything, mock.MatchedBy(func(p activity.CreateTenantExportArchiveParams) bool {
+		return p.StagingDir == staging && p.ArchivePath == staging+".tar.gz" && json.Unmarshal(p.Manifest, &manifest) == nil
+	})).Return(&activity.BackupResult{StoragePath: staging + ".tar.gz", SizeBytes: 4096}, nil)
+	s.env.OnActivity("PresignTenantExportUpload", mock.Anything, "tenant-exports/t1/export-1.tar.gz").Return("https://s3.example/put", nil)
+	s.env.OnActivity("UploadBackupFile", mock.Anything, activity.UploadBackupFileParams{
+		Path: staging + ".tar.gz", URL: "https://s3.example/put",
+	}).Return(nil)
+	s.env.OnActivity("CleanupTenantExport", mock.Anything, activity.CleanupTenantExportParams{
+		StagingDir: staging, ArchivePath: staging + ".tar.gz",
+	}).Return(nil)
+	s.env.OnActivity("UpdateTenantExportResult", mock.Anything, mock.MatchedBy(func(p activity.UpdateTenantExportResultParams) bool {
+		return p.ID == exportID && p.ObjectKey == "tenant-exports/t1/export-1.tar.gz" && p.SizeBytes == 4096
+	})).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "tenant_exports", ID: exportID, Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(ExportTenantWorkflow, exportID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	s.Equal(tenantID, manifest.TenantID)
+	s.Equal([]string{"webroots/wr1.tar.gz", "databases/db1.sql.gz", "valkey/kv1.rdb"}, manifest.Files)
+	s.Len(manifest.FQDNs, 1)
+	s.Len(manifest.Zones, 1)
+	s.Len(manifest.Zones[0].Records, 1)
+	s.Len(manifest.CronJobs, 1)
+	s.Len(manifest.Daemons, 1)
+	s.Require().Len(manifest.ValkeyInstances, 1)
+	s.Empty(manifest.ValkeyInstances[0].PasswordHash)
+}
+
+func (s *ExportTenantWorkflowTestSuite) TestWebBackupFails_CleansUpAndFails() {
+	exportID := "export-1"
+	tenantID := "t1"
+	staging := "/var/backups/hosting/t1/exports/export-1"
+
+	s.mockTenant(exportID, tenantID, "web-shard-1")
+	s.env.OnActivity("ListWebrootsByTenantID", mock.Anything, tenantID).Return([]model.Webroot{{ID: "wr1"}}, nil)
+	s.env.OnActivity("ListFQDNsByWebrootID", mock.Anything, "wr1").Return([]model.FQDN{}, nil)
+	s.env.OnActivity("CreateWebBackup", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("tar failed"))
+	s.env.OnActivity("CleanupTenantExport", mock.Anything, activity.CleanupTenantExportParams{
+		StagingDir: staging, ArchivePath: staging + ".tar.gz",
+	}).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("tenant_exports", exportID)).Return(nil)
+
+	s.env.ExecuteWorkflow(ExportTenantWorkflow, exportID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func (s *ExportTenantWorkflowTestSuite) TestNoShard_Fails() {
+	exportID := "export-1"
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "tenant_exports", ID: exportID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetTenantExportByID", mock.Anything, exportID).Return(&model.TenantExport{ID: exportID, TenantID: "t1"}, nil)
+	s.env.OnActivity("GetTenantByID", mock.Anything, "t1").Return(&model.Tenant{ID: "t1"}, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("tenant_exports", exportID)).Return(nil)
+
+	s.env.ExecuteWorkflow(ExportTenantWorkflow, exportID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func TestExportTenantWorkflow(t *testing.T) {
+	suite.Run(t, new(ExportTenantWorkflowTestSuite))
+}
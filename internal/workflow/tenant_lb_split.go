@@ -0,0 +1,66 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// UpdateTenantLBSplitWorkflow applies a tenant's traffic split to the LB
+// nodes of its cluster by rewriting the map entries of each of its active
+// FQDNs. With no split, all requests go back to the tenant's own shard.
+func UpdateTenantLBSplitWorkflow(ctx workflow.Context, tenantID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var tenant model.Tenant
+	if err := workflow.ExecuteActivity(ctx, "GetTenantByID", tenantID).Get(ctx, &tenant); err != nil {
+		return err
+	}
+
+	var mappings []activity.FQDNMapping
+	if err := workflow.ExecuteActivity(ctx, "ListTenantFQDNMappings", tenantID).Get(ctx, &mappings); err != nil {
+		return err
+	}
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	var lbNodes []model.Node
+	if err := workflow.ExecuteActivity(ctx, "GetNodesByClusterAndRole", tenant.ClusterID, model.ShardRoleLB).Get(ctx, &lbNodes); err != nil {
+		return err
+	}
+
+	errs := fanOutNodes(ctx, lbNodes, func(gCtx workflow.Context, lbNode model.Node) error {
+		lbCtx := nodeActivityCtx(gCtx, lbNode.ID)
+		for _, m := range mappings {
+			err := workflow.ExecuteActivity(lbCtx, "SetLBMapEntry", activity.SetLBMapEntryParams{
+				FQDN:         m.FQDN,
+				LBBackend:    m.LBBackend,
+				SplitBackend: m.SplitBackend,
+				SplitWeight:  m.SplitWeight,
+			}).Get(gCtx, nil)
+			if err != nil {
+				return fmt.Errorf("set lb map %s: %w", m.FQDN, err)
+			}
+		}
+		return nil
+	})
+	if len(errs) > 0 {
+		return fmt.Errorf("update lb split errors: %s", joinErrors(errs))
+	}
+	return nil
+}
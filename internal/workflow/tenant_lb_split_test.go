@@ -0,0 +1,86 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type UpdateTenantLBSplitWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *UpdateTenantLBSplitWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *UpdateTenantLBSplitWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *UpdateTenantLBSplitWorkflowTestSuite) TestSuccess() {
+	tenantID := "test-tenant-1"
+	tenant := model.Tenant{ID: tenantID, ClusterID: "test-cluster-1"}
+	mappings := []activity.FQDNMapping{
+		{FQDN: "example.com", LBBackend: "shard-web-1", SplitBackend: "shard-web-2", SplitWeight: 20},
+		{FQDN: "www.example.com", LBBackend: "shard-web-1", SplitBackend: "shard-web-2", SplitWeight: 20},
+	}
+	lbNodes := []model.Node{{ID: "lb-1"}, {ID: "lb-2"}}
+
+	s.env.OnActivity("GetTenantByID", mock.Anything, tenantID).Return(&tenant, nil)
+	s.env.OnActivity("ListTenantFQDNMappings", mock.Anything, tenantID).Return(mappings, nil)
+	s.env.OnActivity("GetNodesByClusterAndRole", mock.Anything, "test-cluster-1", model.ShardRoleLB).Return(lbNodes, nil)
+	for _, m := range mappings {
+		s.env.OnActivity("SetLBMapEntry", mock.Anything, activity.SetLBMapEntryParams{
+			FQDN:         m.FQDN,
+			LBBackend:    "shard-web-1",
+			SplitBackend: "shard-web-2",
+			SplitWeight:  20,
+		}).Return(nil).Times(len(lbNodes))
+	}
+
+	s.env.ExecuteWorkflow(UpdateTenantLBSplitWorkflow, tenantID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *UpdateTenantLBSplitWorkflowTestSuite) TestNoActiveFQDNs() {
+	tenantID := "test-tenant-1"
+	tenant := model.Tenant{ID: tenantID, ClusterID: "test-cluster-1"}
+
+	s.env.OnActivity("GetTenantByID", mock.Anything, tenantID).Return(&tenant, nil)
+	s.env.OnActivity("ListTenantFQDNMappings", mock.Anything, tenantID).Return([]activity.FQDNMapping{}, nil)
+
+	s.env.ExecuteWorkflow(UpdateTenantLBSplitWorkflow, tenantID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *UpdateTenantLBSplitWorkflowTestSuite) TestSetLBMapEntryFails() {
+	tenantID := "test-tenant-1"
+	tenant := model.Tenant{ID: tenantID, ClusterID: "test-cluster-1"}
+	mappings := []activity.FQDNMapping{{FQDN: "example.com", LBBackend: "shard-web-1"}}
+
+	s.env.OnActivity("GetTenantByID", mock.Anything, tenantID).Return(&tenant, nil)
+	s.env.OnActivity("ListTenantFQDNMappings", mock.Anything, tenantID).Return(mappings, nil)
+	s.env.OnActivity("GetNodesByClusterAndRole", mock.Anything, "test-cluster-1", model.ShardRoleLB).Return([]model.Node{{ID: "lb-1"}}, nil)
+	s.env.OnActivity("SetLBMapEntry", mock.Anything, mock.Anything).Return(fmt.Errorf("haproxy down"))
+
+	s.env.ExecuteWorkflow(UpdateTenantLBSplitWorkflow, tenantID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "update lb split errors")
+}
+
+func TestUpdateTenantLBSplitWorkflow(t *testing.T) {
+	suite.Run(t, new(UpdateTenantLBSplitWorkflowTestSuite))
+}
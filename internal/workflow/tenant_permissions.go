@@ -0,0 +1,62 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// FixTenantPermissionsWorkflow reconciles the ownership and modes of a
+// tenant's storage tree on the first node of its shard (storage is shared,
+// so one node covers every webroot) and returns what was changed. Like
+// NodeDiagnosticsWorkflow it backs an API request waiting for the result,
+// so the node activity is not retried; a truncated run is repeated by
+// calling the endpoint again.
+func FixTenantPermissionsWorkflow(ctx workflow.Context, tenantID string) (*model.TenantPermissionsFix, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 3,
+		},
+	})
+
+	var tenant model.Tenant
+	if err := workflow.ExecuteActivity(ctx, "GetTenantByID", tenantID).Get(ctx, &tenant); err != nil {
+		return nil, fmt.Errorf("get tenant: %w", err)
+	}
+	if tenant.ShardID == nil {
+		return nil, fmt.Errorf("tenant %s has no shard assigned", tenantID)
+	}
+	var nodes []model.Node
+	if err := workflow.ExecuteActivity(ctx, "ListNodesByShard", *tenant.ShardID).Get(ctx, &nodes); err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no nodes found for shard %s", *tenant.ShardID)
+	}
+	nodeID := nodes[0].ID
+
+	nodeCtx := nodeActivityCtx(ctx, nodeID)
+	ao := workflow.GetActivityOptions(nodeCtx)
+	ao.ScheduleToStartTimeout = 10 * time.Second
+	ao.ScheduleToCloseTimeout = 0
+	ao.HeartbeatTimeout = 30 * time.Second
+	ao.RetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 1}
+	nodeCtx = workflow.WithActivityOptions(nodeCtx, ao)
+
+	var fix model.TenantPermissionsFix
+	err := workflow.ExecuteActivity(nodeCtx, "FixTenantPermissions", activity.FixTenantPermissionsParams{
+		TenantName: tenantID,
+	}).Get(ctx, &fix)
+	if err != nil {
+		return nil, fmt.Errorf("fix permissions on node %s: %w", nodeID, err)
+	}
+	fix.TenantID = tenantID
+	fix.NodeID = nodeID
+	return &fix, nil
+}
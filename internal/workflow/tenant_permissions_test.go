@@ -0,0 +1,73 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type FixTenantPermissionsWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *FixTenantPermissionsWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *FixTenantPermissionsWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *FixTenantPermissionsWorkflowTestSuite) TestReturnsResult() {
+	shardID := "shard-1"
+	s.env.OnActivity("GetTenantByID", mock.Anything, "tenant-1").Return(&model.Tenant{ID: "tenant-1", ShardID: &shardID}, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return([]model.Node{{ID: "node-1"}, {ID: "node-2"}}, nil)
+	s.env.OnActivity("FixTenantPermissions", mock.Anything, activity.FixTenantPermissionsParams{
+		TenantName: "tenant-1",
+	}).Return(&model.TenantPermissionsFix{Scanned: 120, Changed: 7}, nil).Once()
+
+	s.env.ExecuteWorkflow(FixTenantPermissionsWorkflow, "tenant-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var got model.TenantPermissionsFix
+	s.NoError(s.env.GetWorkflowResult(&got))
+	s.Equal("tenant-1", got.TenantID)
+	s.Equal("node-1", got.NodeID)
+	s.Equal(120, got.Scanned)
+	s.Equal(7, got.Changed)
+}
+
+func (s *FixTenantPermissionsWorkflowTestSuite) TestNoShard() {
+	s.env.OnActivity("GetTenantByID", mock.Anything, "tenant-1").Return(&model.Tenant{ID: "tenant-1"}, nil)
+
+	s.env.ExecuteWorkflow(FixTenantPermissionsWorkflow, "tenant-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "no shard assigned")
+}
+
+func (s *FixTenantPermissionsWorkflowTestSuite) TestNodeFailure_NotRetried() {
+	shardID := "shard-1"
+	s.env.OnActivity("GetTenantByID", mock.Anything, "tenant-1").Return(&model.Tenant{ID: "tenant-1", ShardID: &shardID}, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return([]model.Node{{ID: "node-1"}}, nil)
+	s.env.OnActivity("FixTenantPermissions", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("activity timeout")).Once()
+
+	s.env.ExecuteWorkflow(FixTenantPermissionsWorkflow, "tenant-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func TestFixTenantPermissionsWorkflow(t *testing.T) {
+	suite.Run(t, new(FixTenantPermissionsWorkflowTestSuite))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
 	"go.temporal.io/sdk/testsuite"
 
 	"github.com/edvin/hosting/internal/activity"
@@ -126,6 +127,57 @@ func (s *CreateTenantWorkflowTestSuite) TestNoShard_SetsStatusFailed() {
 	s.Error(s.env.GetWorkflowError())
 }
 
+func (s *CreateTenantWorkflowTestSuite) TestAllocatesUID() {
+	tenantID := "test-tenant-new-uid"
+	shardID := "test-shard-1"
+	tenant := model.Tenant{
+		ID:      tenantID,
+		BrandID: "test-brand",
+		ShardID: &shardID,
+	}
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "tenants", ID: tenantID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetTenantByID", mock.Anything, tenantID).Return(&tenant, nil)
+	s.env.OnActivity("AllocateTenantUID", mock.Anything, tenantID).Return(5003, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return([]model.Node{{ID: "node-1"}}, nil)
+	s.env.OnActivity("CreateTenant", mock.Anything, activity.CreateTenantParams{
+		ID:   tenantID,
+		Name: tenantID,
+		UID:  5003,
+	}).Return(nil)
+	s.env.OnActivity("SyncSSHConfig", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "tenants", ID: tenantID, Status: model.StatusActive,
+	}).Return(nil)
+	s.env.ExecuteWorkflow(CreateTenantWorkflow, tenantID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CreateTenantWorkflowTestSuite) TestUIDRangeExhausted_SetsStatusFailed() {
+	tenantID := "test-tenant-no-uid"
+	shardID := "test-shard-1"
+	tenant := model.Tenant{
+		ID:      tenantID,
+		BrandID: "test-brand",
+		ShardID: &shardID,
+	}
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "tenants", ID: tenantID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetTenantByID", mock.Anything, tenantID).Return(&tenant, nil)
+	s.env.OnActivity("AllocateTenantUID", mock.Anything, tenantID).Return(0,
+		temporal.NewNonRetryableApplicationError("tenant UID range 5000-5001 of cluster dev-1 is exhausted",
+			activity.ErrTypeTenantUIDRangeExhausted, nil)).Once()
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("tenants", tenantID)).Return(nil)
+	s.env.ExecuteWorkflow(CreateTenantWorkflow, tenantID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.ErrorContains(s.env.GetWorkflowError(), "exhausted")
+}
+
 func (s *CreateTenantWorkflowTestSuite) TestSetProvisioningFails() {
 	tenantID := "test-tenant-4"
 
@@ -400,6 +452,7 @@ func mockDeleteTenantPhase1Empty(env *testsuite.TestWorkflowEnvironment, tenantI
 	env.OnActivity("ListS3BucketsByTenantID", mock.Anything, tenantID).Return([]model.S3Bucket{}, nil)
 	env.OnActivity("ListZonesByTenantID", mock.Anything, tenantID).Return([]model.Zone{}, nil)
 	env.OnActivity("ListEmailAccountsByTenantID", mock.Anything, tenantID).Return([]model.EmailAccount{}, nil)
+	env.OnActivity("ListSMTPRelayUsersByTenantID", mock.Anything, tenantID).Return([]model.SMTPRelayUser{}, nil)
 }
 
 func (s *DeleteTenantWorkflowTestSuite) TestSuccess() {
@@ -475,11 +528,16 @@ func (s *DeleteTenantWorkflowTestSuite) TestWithCrossShardResources() {
 		{ID: "zone-1", TenantID: tenantID},
 	}, nil)
 	s.env.OnActivity("ListEmailAccountsByTenantID", mock.Anything, tenantID).Return([]model.EmailAccount{}, nil)
+	s.env.OnActivity("ListSMTPRelayUsersByTenantID", mock.Anything, tenantID).Return([]model.SMTPRelayUser{
+		{ID: "relay-1", TenantID: tenantID, Username: "relay-1", Status: model.StatusActive},
+		{ID: "relay-2", TenantID: tenantID, Username: "relay-2", Status: model.StatusDeleted},
+	}, nil)
 
 	// Phase 1 child workflows.
 	s.env.OnWorkflow(DeleteDatabaseWorkflow, mock.Anything, "db-1").Return(nil)
 	s.env.OnWorkflow(DeleteValkeyInstanceWorkflow, mock.Anything, "vi-1").Return(nil)
 	s.env.OnWorkflow(DeleteZoneWorkflow, mock.Anything, "zone-1").Return(nil)
+	s.env.OnWorkflow(DeleteSMTPRelayUserWorkflow, mock.Anything, "relay-1").Return(nil)
 
 	// Phase 2: web-node cleanup.
 	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return(nodes, nil)
@@ -60,10 +60,11 @@ func CreateValkeyInstanceWorkflow(ctx workflow.Context, instanceID string) error
 	for _, node := range nodes {
 		nodeCtx := nodeActivityCtx(ctx, node.ID)
 		err = workflow.ExecuteActivity(nodeCtx, "CreateValkeyInstance", activity.CreateValkeyInstanceParams{
-			Name:         instance.ID,
-			Port:         instance.Port,
-			PasswordHash: instance.PasswordHash,
-			MaxMemoryMB:  instance.MaxMemoryMB,
+			Name:            instance.ID,
+			Port:            instance.Port,
+			PasswordHash:    instance.PasswordHash,
+			MaxMemoryMB:     instance.MaxMemoryMB,
+			PersistenceMode: instance.PersistenceMode,
 		}).Get(ctx, nil)
 		if err != nil {
 			_ = setResourceFailed(ctx, "valkey_instances", instanceID, err)
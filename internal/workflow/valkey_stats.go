@@ -0,0 +1,97 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// CollectValkeyStatsWorkflow runs on a cron schedule, queries each active
+// Valkey shard for per-instance memory usage and key counts, and stores the
+// results in resource_usage. Instances that cannot be queried are recorded as
+// unavailable; a failing shard never aborts collection for the others.
+func CollectValkeyStatsWorkflow(ctx workflow.Context) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 60 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 2,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+	logger := workflow.GetLogger(ctx)
+
+	var shards []model.Shard
+	err := workflow.ExecuteActivity(ctx, "ListShardsByRole", model.ShardRoleValkey).Get(ctx, &shards)
+	if err != nil {
+		return fmt.Errorf("list valkey shards: %w", err)
+	}
+
+	for _, shard := range shards {
+		if shard.Status != model.StatusActive {
+			continue
+		}
+
+		var instances []model.ValkeyInstance
+		err := workflow.ExecuteActivity(ctx, "ListValkeyInstancesByShard", shard.ID).Get(ctx, &instances)
+		if err != nil {
+			logger.Warn("failed to list valkey instances", "shard", shard.ID, "error", err)
+			continue
+		}
+
+		// The control plane only holds password hashes; stats are read over the
+		// instance's local Unix socket, so no password is sent.
+		var targets []activity.ValkeyStatsTarget
+		for _, inst := range instances {
+			if inst.Status != model.StatusActive {
+				continue
+			}
+			targets = append(targets, activity.ValkeyStatsTarget{Name: inst.ID})
+		}
+		if len(targets) == 0 {
+			continue
+		}
+
+		var nodes []model.Node
+		err = workflow.ExecuteActivity(ctx, "ListNodesByShard", shard.ID).Get(ctx, &nodes)
+		if err != nil {
+			logger.Warn("failed to list nodes for valkey shard", "shard", shard.ID, "error", err)
+			continue
+		}
+		if len(nodes) == 0 {
+			continue
+		}
+
+		nodeCtx := nodeActivityCtx(ctx, nodes[0].ID)
+
+		var entries []activity.ValkeyStatsEntry
+		err = workflow.ExecuteActivity(nodeCtx, "GetValkeyStats", activity.GetValkeyStatsParams{
+			Instances: targets,
+		}).Get(ctx, &entries)
+		if err != nil {
+			// Node unreachable: mark every instance on the shard unavailable.
+			logger.Warn("failed to collect valkey stats", "shard", shard.ID, "node", nodes[0].ID, "error", err)
+			entries = make([]activity.ValkeyStatsEntry, 0, len(targets))
+			for _, t := range targets {
+				entries = append(entries, activity.ValkeyStatsEntry{Name: t.Name})
+			}
+		}
+
+		for _, entry := range entries {
+			keyCount := entry.KeyCount
+			_ = workflow.ExecuteActivity(ctx, "UpsertResourceUsage", activity.UpsertResourceUsageParams{
+				ResourceType: "valkey",
+				Name:         entry.Name,
+				BytesUsed:    entry.UsedMemoryBytes,
+				KeyCount:     &keyCount,
+				Unavailable:  !entry.Available,
+			}).Get(ctx, nil)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type CollectValkeyStatsWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *CollectValkeyStatsWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *CollectValkeyStatsWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *CollectValkeyStatsWorkflowTestSuite) mockShard() {
+	s.env.OnActivity("ListShardsByRole", mock.Anything, model.ShardRoleValkey).
+		Return([]model.Shard{{ID: "shard-kv-1", Status: model.StatusActive}}, nil)
+	s.env.OnActivity("ListValkeyInstancesByShard", mock.Anything, "shard-kv-1").
+		Return([]model.ValkeyInstance{
+			{ID: "kv-1", Status: model.StatusActive},
+			{ID: "kv-2", Status: model.StatusActive},
+		}, nil)
+	s.env.OnActivity("ListNodesByShard", mock.Anything, "shard-kv-1").
+		Return([]model.Node{{ID: "node-kv-1"}}, nil)
+}
+
+func (s *CollectValkeyStatsWorkflowTestSuite) TestStoresStats() {
+	s.mockShard()
+	s.env.OnActivity("GetValkeyStats", mock.Anything, mock.Anything).
+		Return([]activity.ValkeyStatsEntry{
+			{Name: "kv-1", UsedMemoryBytes: 1024, KeyCount: 10, Available: true},
+			{Name: "kv-2", Error: "valkey instance kv-2 is not running"},
+		}, nil)
+
+	s.env.OnActivity("UpsertResourceUsage", mock.Anything, mock.MatchedBy(func(p activity.UpsertResourceUsageParams) bool {
+		return p.ResourceType == "valkey" && p.Name == "kv-1" && p.BytesUsed == 1024 &&
+			p.KeyCount != nil && *p.KeyCount == 10 && !p.Unavailable
+	})).Return(nil).Once()
+	s.env.OnActivity("UpsertResourceUsage", mock.Anything, mock.MatchedBy(func(p activity.UpsertResourceUsageParams) bool {
+		return p.ResourceType == "valkey" && p.Name == "kv-2" && p.Unavailable
+	})).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(CollectValkeyStatsWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CollectValkeyStatsWorkflowTestSuite) TestNodeDownMarksUnavailable() {
+	s.mockShard()
+	s.env.OnActivity("GetValkeyStats", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("node unreachable"))
+
+	s.env.OnActivity("UpsertResourceUsage", mock.Anything, mock.MatchedBy(func(p activity.UpsertResourceUsageParams) bool {
+		return p.ResourceType == "valkey" && p.Unavailable
+	})).Return(nil).Twice()
+
+	s.env.ExecuteWorkflow(CollectValkeyStatsWorkflow)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func TestCollectValkeyStatsWorkflow(t *testing.T) {
+	suite.Run(t, new(CollectValkeyStatsWorkflowTestSuite))
+}
@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"go.temporal.io/sdk/temporal"
@@ -49,9 +50,13 @@ func CreateWebrootWorkflow(ctx workflow.Context, webrootID string) error {
 			webrootID = *f.WebrootID
 		}
 		fqdnParams[i] = activity.FQDNParam{
-			FQDN:       f.FQDN,
-			WebrootID:  webrootID,
-			SSLEnabled: f.SSLEnabled,
+			FQDN:                 f.FQDN,
+			WebrootID:            webrootID,
+			SSLEnabled:           f.SSLEnabled,
+			ForceHTTPS:           f.ForceHTTPS,
+			RedirectTarget:       f.RedirectTarget,
+			RedirectCode:         f.RedirectCode,
+			RedirectPreservePath: f.RedirectPreservePath,
 		}
 	}
 
@@ -70,6 +75,13 @@ func CreateWebrootWorkflow(ctx workflow.Context, webrootID string) error {
 		return noShardErr
 	}
 
+	// Fail fast if the requested runtime version or PHP extensions are not
+	// installed on the shard.
+	if err := ensureRuntimeAvailable(ctx, wctx.Nodes, wctx.Webroot.Runtime, wctx.Webroot.RuntimeVersion, enabledPHPExtensions(wctx.Webroot)); err != nil {
+		_ = setResourceFailed(ctx, "webroots", webrootID, err)
+		return err
+	}
+
 	// Create webroot on each node in the shard (parallel).
 	errs := fanOutNodes(ctx, wctx.Nodes, func(gCtx workflow.Context, node model.Node) error {
 		nodeCtx := nodeActivityCtx(gCtx, node.ID)
@@ -81,6 +93,13 @@ func CreateWebrootWorkflow(ctx workflow.Context, webrootID string) error {
 			RuntimeVersion: wctx.Webroot.RuntimeVersion,
 			RuntimeConfig:  string(wctx.Webroot.RuntimeConfig),
 			PublicFolder:   wctx.Webroot.PublicFolder,
+			ErrorPages:     wctx.Webroot.ErrorPages,
+			BasicAuth:      wctx.Webroot.BasicAuth,
+			AccessLog:      wctx.Webroot.AccessLogEnabled,
+			Limits:         wctx.Webroot.ConnectionLimits,
+			StaticRules:    wctx.Webroot.StaticRules,
+			GeoBlocking:    wctx.Webroot.GeoBlocking,
+			DeployLock:     wctx.Webroot.DeployLockedUntil,
 			EnvVars:        wctx.EnvVars,
 			EnvFileName:    wctx.Webroot.EnvFileName,
 			FQDNs:          fqdnParams,
@@ -99,9 +118,10 @@ func CreateWebrootWorkflow(ctx workflow.Context, webrootID string) error {
 
 	// Set status to active.
 	err = workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
-		Table:  "webroots",
-		ID:     webrootID,
-		Status: model.StatusActive,
+		Table:         "webroots",
+		ID:            webrootID,
+		Status:        model.StatusActive,
+		StatusMessage: errorPagesWarning(ctx, wctx),
 	}).Get(ctx, nil)
 	if err != nil {
 		return err
@@ -178,9 +198,13 @@ func UpdateWebrootWorkflow(ctx workflow.Context, webrootID string) error {
 			webrootID = *f.WebrootID
 		}
 		fqdnParams[i] = activity.FQDNParam{
-			FQDN:       f.FQDN,
-			WebrootID:  webrootID,
-			SSLEnabled: f.SSLEnabled,
+			FQDN:                 f.FQDN,
+			WebrootID:            webrootID,
+			SSLEnabled:           f.SSLEnabled,
+			ForceHTTPS:           f.ForceHTTPS,
+			RedirectTarget:       f.RedirectTarget,
+			RedirectCode:         f.RedirectCode,
+			RedirectPreservePath: f.RedirectPreservePath,
 		}
 	}
 
@@ -199,6 +223,11 @@ func UpdateWebrootWorkflow(ctx workflow.Context, webrootID string) error {
 		return noShardErr
 	}
 
+	if err := ensureRuntimeAvailable(ctx, wctx.Nodes, wctx.Webroot.Runtime, wctx.Webroot.RuntimeVersion, enabledPHPExtensions(wctx.Webroot)); err != nil {
+		_ = setResourceFailed(ctx, "webroots", webrootID, err)
+		return err
+	}
+
 	// Update webroot on each node in the shard (parallel).
 	errs := fanOutNodes(ctx, wctx.Nodes, func(gCtx workflow.Context, node model.Node) error {
 		nodeCtx := nodeActivityCtx(gCtx, node.ID)
@@ -210,6 +239,13 @@ func UpdateWebrootWorkflow(ctx workflow.Context, webrootID string) error {
 			RuntimeVersion: wctx.Webroot.RuntimeVersion,
 			RuntimeConfig:  string(wctx.Webroot.RuntimeConfig),
 			PublicFolder:   wctx.Webroot.PublicFolder,
+			ErrorPages:     wctx.Webroot.ErrorPages,
+			BasicAuth:      wctx.Webroot.BasicAuth,
+			AccessLog:      wctx.Webroot.AccessLogEnabled,
+			Limits:         wctx.Webroot.ConnectionLimits,
+			StaticRules:    wctx.Webroot.StaticRules,
+			GeoBlocking:    wctx.Webroot.GeoBlocking,
+			DeployLock:     wctx.Webroot.DeployLockedUntil,
 			EnvVars:        wctx.EnvVars,
 			EnvFileName:    wctx.Webroot.EnvFileName,
 			FQDNs:          fqdnParams,
@@ -228,12 +264,34 @@ func UpdateWebrootWorkflow(ctx workflow.Context, webrootID string) error {
 
 	// Set status to active.
 	return workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
-		Table:  "webroots",
-		ID:     webrootID,
-		Status: model.StatusActive,
+		Table:         "webroots",
+		ID:            webrootID,
+		Status:        model.StatusActive,
+		StatusMessage: errorPagesWarning(ctx, wctx),
 	}).Get(ctx, nil)
 }
 
+// errorPagesWarning checks the webroot's custom error pages on one node of the
+// shard (webroot storage is shared) and returns a status message listing the
+// pages that fell back to nginx defaults, or nil if all of them are in place.
+func errorPagesWarning(ctx workflow.Context, wctx activity.WebrootContext) *string {
+	if len(wctx.Webroot.ErrorPages) == 0 || len(wctx.Nodes) == 0 {
+		return nil
+	}
+	var missing []string
+	err := workflow.ExecuteActivity(nodeActivityCtx(ctx, wctx.Nodes[0].ID), "CheckErrorPages", activity.CheckErrorPagesParams{
+		TenantName:   wctx.Tenant.ID,
+		Name:         wctx.Webroot.ID,
+		PublicFolder: wctx.Webroot.PublicFolder,
+		ErrorPages:   wctx.Webroot.ErrorPages,
+	}).Get(ctx, &missing)
+	if err != nil || len(missing) == 0 {
+		return nil
+	}
+	msg := fmt.Sprintf("custom error pages not found, using nginx defaults: %s", strings.Join(missing, ", "))
+	return &msg
+}
+
 // DeleteWebrootWorkflow deletes a webroot from all nodes in the tenant's shard.
 func DeleteWebrootWorkflow(ctx workflow.Context, webrootID string) error {
 	ao := workflow.ActivityOptions{
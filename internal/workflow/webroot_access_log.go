@@ -0,0 +1,69 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// WebrootAccessLogWorkflow reads the last tail requests from a webroot's
+// shared access logs. Every node writes its own file to shared storage, so
+// one node of the shard reads and merges them all. The caller is an API
+// request waiting for the result, so the node activity is not retried.
+func WebrootAccessLogWorkflow(ctx workflow.Context, webrootID string, tail int) (*model.WebrootAccessLog, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 3},
+	})
+
+	var wctx activity.WebrootContext
+	if err := workflow.ExecuteActivity(ctx, "GetWebrootContext", webrootID).Get(ctx, &wctx); err != nil {
+		return nil, fmt.Errorf("get webroot context: %w", err)
+	}
+	if len(wctx.Nodes) == 0 {
+		return nil, fmt.Errorf("webroot %s has no web nodes to read logs on", webrootID)
+	}
+	nodes := nodesSupporting(ctx, wctx.Nodes, "ReadWebrootAccessLog")
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no web node of webroot %s runs an agent that can read access logs", webrootID)
+	}
+
+	node := nodes[0]
+	nodeCtx := nodeActivityCtx(ctx, node.ID)
+	ao := workflow.GetActivityOptions(nodeCtx)
+	ao.ScheduleToStartTimeout = 10 * time.Second
+	ao.StartToCloseTimeout = 30 * time.Second
+	ao.ScheduleToCloseTimeout = 0
+	ao.RetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 1}
+	nodeCtx = workflow.WithActivityOptions(nodeCtx, ao)
+
+	var lines []model.WebrootAccessLogLine
+	err := workflow.ExecuteActivity(nodeCtx, "ReadWebrootAccessLog", activity.ReadWebrootAccessLogParams{
+		TenantName: wctx.Tenant.ID,
+		Name:       wctx.Webroot.ID,
+		Tail:       tail,
+	}).Get(ctx, &lines)
+	if err != nil {
+		return nil, fmt.Errorf("read access log on node %s: %w", node.ID, err)
+	}
+	if lines == nil {
+		lines = []model.WebrootAccessLogLine{}
+	}
+
+	counts := map[string]int{}
+	for _, l := range lines {
+		counts[fmt.Sprintf("%dxx", l.Status/100)]++
+	}
+
+	return &model.WebrootAccessLog{
+		WebrootID:    webrootID,
+		Enabled:      wctx.Webroot.AccessLogEnabled,
+		Lines:        lines,
+		StatusCounts: counts,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type WebrootAccessLogWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *WebrootAccessLogWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *WebrootAccessLogWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *WebrootAccessLogWorkflowTestSuite) webrootContext() activity.WebrootContext {
+	return activity.WebrootContext{
+		Webroot: model.Webroot{ID: "test-webroot-1", TenantID: "test-tenant-1", AccessLogEnabled: true},
+		Tenant:  model.Tenant{ID: "test-tenant-1"},
+		Nodes:   []model.Node{{ID: "node-1"}, {ID: "node-2"}},
+	}
+}
+
+func (s *WebrootAccessLogWorkflowTestSuite) TestReadsOnFirstNode() {
+	wctx := s.webrootContext()
+	now := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	lines := []model.WebrootAccessLogLine{
+		{Time: now, Node: "node-1", URI: "/", Status: 200},
+		{Time: now.Add(time.Second), Node: "node-2", URI: "/missing", Status: 404},
+		{Time: now.Add(2 * time.Second), Node: "node-1", URI: "/about", Status: 200},
+	}
+
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(&wctx, nil)
+	s.env.OnActivity("ListNodesMissingActivity", mock.Anything, mock.Anything).Return(nil, nil)
+	s.env.OnActivity("ReadWebrootAccessLog", mock.Anything, activity.ReadWebrootAccessLogParams{
+		TenantName: "test-tenant-1",
+		Name:       "test-webroot-1",
+		Tail:       50,
+	}).Return(lines, nil).Once()
+
+	s.env.ExecuteWorkflow(WebrootAccessLogWorkflow, "test-webroot-1", 50)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var got model.WebrootAccessLog
+	s.NoError(s.env.GetWorkflowResult(&got))
+	s.Equal("test-webroot-1", got.WebrootID)
+	s.True(got.Enabled)
+	s.Len(got.Lines, 3)
+	s.Equal(map[string]int{"2xx": 2, "4xx": 1}, got.StatusCounts)
+}
+
+func (s *WebrootAccessLogWorkflowTestSuite) TestNoLines() {
+	wctx := s.webrootContext()
+	wctx.Webroot.AccessLogEnabled = false
+
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(&wctx, nil)
+	s.env.OnActivity("ListNodesMissingActivity", mock.Anything, mock.Anything).Return(nil, nil)
+	s.env.OnActivity("ReadWebrootAccessLog", mock.Anything, mock.Anything).Return(nil, nil).Once()
+
+	s.env.ExecuteWorkflow(WebrootAccessLogWorkflow, "test-webroot-1", 100)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var got model.WebrootAccessLog
+	s.NoError(s.env.GetWorkflowResult(&got))
+	s.False(got.Enabled)
+	s.NotNil(got.Lines)
+	s.Empty(got.Lines)
+	s.Empty(got.StatusCounts)
+}
+
+func (s *WebrootAccessLogWorkflowTestSuite) TestNodeFails_NotRetried() {
+	wctx := s.webrootContext()
+
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(&wctx, nil)
+	s.env.OnActivity("ListNodesMissingActivity", mock.Anything, mock.Anything).Return(nil, nil)
+	s.env.OnActivity("ReadWebrootAccessLog", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("activity timeout")).Once()
+
+	s.env.ExecuteWorkflow(WebrootAccessLogWorkflow, "test-webroot-1", 100)
+	s.True(s.env.IsWorkflowCompleted())
+	s.ErrorContains(s.env.GetWorkflowError(), "node-1")
+}
+
+func (s *WebrootAccessLogWorkflowTestSuite) TestSkipsNodeWithOutdatedAgent() {
+	wctx := s.webrootContext()
+
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(&wctx, nil)
+	s.env.OnActivity("ListNodesMissingActivity", mock.Anything, activity.ListNodesMissingActivityParams{
+		NodeIDs:  []string{"node-1", "node-2"},
+		Activity: "ReadWebrootAccessLog",
+	}).Return([]string{"node-1"}, nil)
+	s.env.OnActivity("ReadWebrootAccessLog", mock.Anything, mock.Anything).
+		Return(nil, fmt.Errorf("activity timeout")).Once()
+
+	s.env.ExecuteWorkflow(WebrootAccessLogWorkflow, "test-webroot-1", 100)
+	s.True(s.env.IsWorkflowCompleted())
+	s.ErrorContains(s.env.GetWorkflowError(), "node-2")
+}
+
+func (s *WebrootAccessLogWorkflowTestSuite) TestNoNodeSupportsRead() {
+	wctx := s.webrootContext()
+
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(&wctx, nil)
+	s.env.OnActivity("ListNodesMissingActivity", mock.Anything, mock.Anything).
+		Return([]string{"node-1", "node-2"}, nil)
+
+	s.env.ExecuteWorkflow(WebrootAccessLogWorkflow, "test-webroot-1", 100)
+	s.True(s.env.IsWorkflowCompleted())
+	s.ErrorContains(s.env.GetWorkflowError(), "can read access logs")
+}
+
+func TestWebrootAccessLogWorkflow(t *testing.T) {
+	suite.Run(t, new(WebrootAccessLogWorkflowTestSuite))
+}
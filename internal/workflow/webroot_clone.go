@@ -0,0 +1,154 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// CloneWebrootWorkflow provisions a webroot cloned by WebrootCloneService.
+// The database clone, if any, is created and filled from a dump of the
+// source database first. The source's files are then copied into the clone
+// before CreateWebrootWorkflow provisions it, so the clone's env file is
+// written fresh from its own env vars. The clone webroot only becomes active
+// once everything is copied. If any step fails, the cloned database and
+// webroot are deleted again and the original error is returned.
+func CloneWebrootWorkflow(ctx workflow.Context, params model.CloneWebrootParams) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	err := workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "webroots",
+		ID:     params.WebrootID,
+		Status: model.StatusProvisioning,
+	}).Get(ctx, nil)
+
+	databaseStarted := false
+	if err == nil && params.DatabaseID != "" {
+		databaseStarted = true
+		err = runChildWorkflow(ctx, CreateDatabaseWorkflow, "create-database-"+params.DatabaseID, params.DatabaseID)
+		if err == nil {
+			err = copyDatabaseData(ctx, params.SourceDatabaseID, params.DatabaseID)
+		}
+	}
+	if err == nil {
+		err = copyWebrootFiles(ctx, params.SourceWebrootID, params.WebrootID)
+	}
+	if err == nil {
+		err = runChildWorkflow(ctx, CreateWebrootWorkflow, "create-webroot-"+params.WebrootID, params.WebrootID)
+	}
+	if err == nil {
+		return nil
+	}
+
+	// Roll back the partial clone.
+	logger := workflow.GetLogger(ctx)
+	var rollbackErrs []string
+	if params.DatabaseID != "" {
+		var rbErr error
+		if databaseStarted {
+			rbErr = runChildWorkflow(ctx, DeleteDatabaseWorkflow, "database-"+params.DatabaseID, params.DatabaseID)
+		} else {
+			// Never provisioned on a node: only the row exists.
+			rbErr = workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+				Table:  "databases",
+				ID:     params.DatabaseID,
+				Status: model.StatusDeleted,
+			}).Get(ctx, nil)
+		}
+		if rbErr != nil {
+			rollbackErrs = append(rollbackErrs, fmt.Sprintf("database %s: %v", params.DatabaseID, rbErr))
+		}
+	}
+	if rbErr := runChildWorkflow(ctx, DeleteWebrootWorkflow, "webroot-"+params.WebrootID, params.WebrootID); rbErr != nil {
+		rollbackErrs = append(rollbackErrs, fmt.Sprintf("webroot %s: %v", params.WebrootID, rbErr))
+	}
+
+	if len(rollbackErrs) > 0 {
+		logger.Error("clone rollback incomplete", "webroot", params.WebrootID, "errors", joinErrors(rollbackErrs))
+		return fmt.Errorf("clone webroot %s: %w (rollback failed: %s)", params.SourceWebrootID, err, joinErrors(rollbackErrs))
+	}
+	return fmt.Errorf("clone webroot %s: %w", params.SourceWebrootID, err)
+}
+
+// copyWebrootFiles copies the source webroot's files into the clone on one
+// node of the tenant's shard. The env file and direnv setup are left out;
+// CreateWebrootWorkflow writes the clone's own.
+func copyWebrootFiles(ctx workflow.Context, sourceID, cloneID string) error {
+	var wctx activity.WebrootContext
+	if err := workflow.ExecuteActivity(ctx, "GetWebrootContext", sourceID).Get(ctx, &wctx); err != nil {
+		return err
+	}
+	if len(wctx.Nodes) == 0 {
+		return fmt.Errorf("no nodes found for webroot %s", sourceID)
+	}
+
+	envFileName := wctx.Webroot.EnvFileName
+	if envFileName == "" {
+		envFileName = ".env.hosting"
+	}
+
+	copyCtx := cloneCopyCtx(ctx, wctx.Nodes[0].ID)
+	return workflow.ExecuteActivity(copyCtx, "CopyWebrootFiles", activity.CopyWebrootFilesParams{
+		TenantName: wctx.Tenant.ID,
+		SourceName: sourceID,
+		TargetName: cloneID,
+		Exclude:    []string{envFileName, ".envrc", ".bin"},
+	}).Get(ctx, nil)
+}
+
+// copyDatabaseData dumps the source database on its shard primary and
+// imports the dump into the clone. The clone is on the same shard, so both
+// happen on the same node.
+func copyDatabaseData(ctx workflow.Context, sourceID, cloneID string) error {
+	var source model.Database
+	if err := workflow.ExecuteActivity(ctx, "GetDatabaseByID", sourceID).Get(ctx, &source); err != nil {
+		return err
+	}
+	if source.ShardID == nil {
+		return fmt.Errorf("database %s has no shard assigned", sourceID)
+	}
+	primaryID, _, err := dbShardPrimary(ctx, *source.ShardID)
+	if err != nil {
+		return err
+	}
+
+	primaryCtx := cloneCopyCtx(ctx, primaryID)
+	dumpPath := fmt.Sprintf("/var/backups/hosting/migrate/clone-%s.sql.gz", cloneID)
+	if err := workflow.ExecuteActivity(primaryCtx, "DumpMySQLDatabase", activity.DumpMySQLDatabaseParams{
+		DatabaseName: sourceID,
+		DumpPath:     dumpPath,
+	}).Get(ctx, nil); err != nil {
+		return fmt.Errorf("dump database %s: %w", sourceID, err)
+	}
+	defer func() {
+		_ = workflow.ExecuteActivity(nodeActivityCtx(ctx, primaryID), "CleanupMigrateFile", dumpPath).Get(ctx, nil)
+	}()
+	if err := workflow.ExecuteActivity(primaryCtx, "ImportMySQLDatabase", activity.ImportMySQLDatabaseParams{
+		DatabaseName: cloneID,
+		DumpPath:     dumpPath,
+	}).Get(ctx, nil); err != nil {
+		return fmt.Errorf("import database %s: %w", cloneID, err)
+	}
+	return nil
+}
+
+// cloneCopyCtx routes an activity to a node with room for copying a large
+// site or database.
+func cloneCopyCtx(ctx workflow.Context, nodeID string) workflow.Context {
+	ctx = workflow.WithStartToCloseTimeout(nodeActivityCtx(ctx, nodeID), time.Hour)
+	return workflow.WithScheduleToCloseTimeout(ctx, 3*time.Hour)
+}
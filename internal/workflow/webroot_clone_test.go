@@ -0,0 +1,126 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type CloneWebrootWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *CloneWebrootWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+	s.env.RegisterWorkflow(CreateWebrootWorkflow)
+	s.env.RegisterWorkflow(CreateDatabaseWorkflow)
+	s.env.RegisterWorkflow(DeleteWebrootWorkflow)
+	s.env.RegisterWorkflow(DeleteDatabaseWorkflow)
+}
+
+func (s *CloneWebrootWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *CloneWebrootWorkflowTestSuite) expectProvisioning() {
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "webroots", ID: "w_clone", Status: model.StatusProvisioning,
+	}).Return(nil).Once()
+}
+
+func (s *CloneWebrootWorkflowTestSuite) expectFileCopy(err error) {
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "w_source").Return(&activity.WebrootContext{
+		Webroot: model.Webroot{ID: "w_source", TenantID: "t1", EnvFileName: ".env"},
+		Tenant:  model.Tenant{ID: "t1"},
+		Nodes:   []model.Node{{ID: "web-1"}, {ID: "web-2"}},
+	}, nil).Once()
+	s.env.OnActivity("CopyWebrootFiles", mock.Anything, activity.CopyWebrootFilesParams{
+		TenantName: "t1",
+		SourceName: "w_source",
+		TargetName: "w_clone",
+		Exclude:    []string{".env", ".envrc", ".bin"},
+	}).Return(err).Once()
+}
+
+func (s *CloneWebrootWorkflowTestSuite) expectDatabaseCopy() {
+	shardID := "db-shard-1"
+	s.env.OnWorkflow(CreateDatabaseWorkflow, mock.Anything, "db_clone").Return(nil).Once()
+	s.env.OnActivity("GetDatabaseByID", mock.Anything, "db_source").Return(&model.Database{ID: "db_source", ShardID: &shardID}, nil).Once()
+	s.env.OnActivity("GetShardByID", mock.Anything, shardID).Return(&model.Shard{ID: shardID}, nil).Once()
+	s.env.OnActivity("ListNodesByShard", mock.Anything, shardID).Return([]model.Node{{ID: "db-1"}}, nil).Once()
+	dumpPath := "/var/backups/hosting/migrate/clone-db_clone.sql.gz"
+	s.env.OnActivity("DumpMySQLDatabase", mock.Anything, activity.DumpMySQLDatabaseParams{
+		DatabaseName: "db_source", DumpPath: dumpPath,
+	}).Return(nil).Once()
+	s.env.OnActivity("ImportMySQLDatabase", mock.Anything, activity.ImportMySQLDatabaseParams{
+		DatabaseName: "db_clone", DumpPath: dumpPath,
+	}).Return(nil).Once()
+	s.env.OnActivity("CleanupMigrateFile", mock.Anything, dumpPath).Return(nil).Once()
+}
+
+func (s *CloneWebrootWorkflowTestSuite) TestSuccess() {
+	s.expectProvisioning()
+	s.expectFileCopy(nil)
+	s.env.OnWorkflow(CreateWebrootWorkflow, mock.Anything, "w_clone").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(CloneWebrootWorkflow, model.CloneWebrootParams{SourceWebrootID: "w_source", WebrootID: "w_clone"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CloneWebrootWorkflowTestSuite) TestSuccessWithDatabase() {
+	s.expectProvisioning()
+	s.expectDatabaseCopy()
+	s.expectFileCopy(nil)
+	s.env.OnWorkflow(CreateWebrootWorkflow, mock.Anything, "w_clone").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(CloneWebrootWorkflow, model.CloneWebrootParams{
+		SourceWebrootID: "w_source", WebrootID: "w_clone",
+		SourceDatabaseID: "db_source", DatabaseID: "db_clone",
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CloneWebrootWorkflowTestSuite) TestCopyFailureRollsBack() {
+	s.expectProvisioning()
+	s.expectDatabaseCopy()
+	s.expectFileCopy(temporal.NewNonRetryableApplicationError("disk full", "TEST", nil))
+	s.env.OnWorkflow(DeleteDatabaseWorkflow, mock.Anything, "db_clone").Return(nil).Once()
+	s.env.OnWorkflow(DeleteWebrootWorkflow, mock.Anything, "w_clone").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(CloneWebrootWorkflow, model.CloneWebrootParams{
+		SourceWebrootID: "w_source", WebrootID: "w_clone",
+		SourceDatabaseID: "db_source", DatabaseID: "db_clone",
+	})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "disk full")
+	s.NotContains(s.env.GetWorkflowError().Error(), "rollback failed")
+}
+
+func (s *CloneWebrootWorkflowTestSuite) TestRollbackFailureReported() {
+	s.expectProvisioning()
+	s.expectFileCopy(nil)
+	s.env.OnWorkflow(CreateWebrootWorkflow, mock.Anything, "w_clone").Return(fmt.Errorf("runtime not installed")).Once()
+	s.env.OnWorkflow(DeleteWebrootWorkflow, mock.Anything, "w_clone").Return(fmt.Errorf("node down")).Once()
+
+	s.env.ExecuteWorkflow(CloneWebrootWorkflow, model.CloneWebrootParams{SourceWebrootID: "w_source", WebrootID: "w_clone"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "rollback failed")
+}
+
+func TestCloneWebrootWorkflow(t *testing.T) {
+	suite.Run(t, new(CloneWebrootWorkflowTestSuite))
+}
@@ -0,0 +1,66 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/core"
+)
+
+// WebrootDeployLockWorkflow applies a webroot's deploy lock to its nginx
+// config, waits until the lock expires and lifts it again. The wait is a
+// durable timer, so the lock is lifted even if the client never clears it
+// and workers restart in between. Clearing the lock early or setting it
+// again makes ClearWebrootDeployLock a no-op here: the API or the newer
+// workflow re-renders the config instead.
+func WebrootDeployLockWorkflow(ctx workflow.Context, params core.WebrootDeployLockParams) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	lockCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID: fmt.Sprintf("update-webroot-%s-deploy-lock-%d", params.WebrootID, params.Until.UnixMicro()),
+	})
+	if err := workflow.ExecuteChildWorkflow(lockCtx, UpdateWebrootWorkflow, params.WebrootID).Get(ctx, nil); err != nil {
+		// The lock is stored, so a later render may still apply it; it must
+		// be lifted at expiry either way.
+		workflow.GetLogger(ctx).Warn("failed to apply deploy lock", "webrootID", params.WebrootID, "error", err)
+	}
+
+	if d := params.Until.Sub(workflow.Now(ctx)); d > 0 {
+		if err := workflow.Sleep(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	var cleared bool
+	err := workflow.ExecuteActivity(ctx, "ClearWebrootDeployLock", activity.ClearWebrootDeployLockParams{
+		WebrootID: params.WebrootID,
+		Until:     params.Until,
+	}).Get(ctx, &cleared)
+	if err != nil {
+		return err
+	}
+	if !cleared {
+		return nil
+	}
+
+	unlockCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID: fmt.Sprintf("update-webroot-%s-deploy-unlock-%d", params.WebrootID, params.Until.UnixMicro()),
+	})
+	if err := workflow.ExecuteChildWorkflow(unlockCtx, UpdateWebrootWorkflow, params.WebrootID).Get(ctx, nil); err != nil {
+		return fmt.Errorf("lift deploy lock of webroot %s: %w", params.WebrootID, err)
+	}
+	return nil
+}
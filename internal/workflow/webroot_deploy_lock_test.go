@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/core"
+)
+
+type WebrootDeployLockWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *WebrootDeployLockWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *WebrootDeployLockWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *WebrootDeployLockWorkflowTestSuite) TestLiftsLockAtExpiry() {
+	start := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	params := core.WebrootDeployLockParams{WebrootID: "test-webroot-1", Until: start.Add(2 * time.Minute)}
+
+	s.env.OnWorkflow(UpdateWebrootWorkflow, mock.Anything, "test-webroot-1").Return(nil).Twice()
+	s.env.OnActivity("ClearWebrootDeployLock", mock.Anything, activity.ClearWebrootDeployLockParams{
+		WebrootID: "test-webroot-1", Until: params.Until,
+	}).Return(true, nil).Once()
+
+	s.env.ExecuteWorkflow(WebrootDeployLockWorkflow, params)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.False(s.env.Now().Before(params.Until))
+}
+
+func (s *WebrootDeployLockWorkflowTestSuite) TestExtendedLock_NotLifted() {
+	start := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	params := core.WebrootDeployLockParams{WebrootID: "test-webroot-1", Until: start.Add(time.Minute)}
+
+	// Only the render that applies the lock; the newer lock's workflow
+	// lifts it.
+	s.env.OnWorkflow(UpdateWebrootWorkflow, mock.Anything, "test-webroot-1").Return(nil).Once()
+	s.env.OnActivity("ClearWebrootDeployLock", mock.Anything, mock.Anything).Return(false, nil).Once()
+
+	s.env.ExecuteWorkflow(WebrootDeployLockWorkflow, params)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *WebrootDeployLockWorkflowTestSuite) TestApplyFailure_StillLifted() {
+	start := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	s.env.SetStartTime(start)
+	params := core.WebrootDeployLockParams{WebrootID: "test-webroot-1", Until: start.Add(time.Minute)}
+
+	s.env.OnWorkflow(UpdateWebrootWorkflow, mock.Anything, "test-webroot-1").Return(fmt.Errorf("node unreachable")).Once()
+	s.env.OnActivity("ClearWebrootDeployLock", mock.Anything, mock.Anything).Return(true, nil).Once()
+	s.env.OnWorkflow(UpdateWebrootWorkflow, mock.Anything, "test-webroot-1").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(WebrootDeployLockWorkflow, params)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func TestWebrootDeployLockWorkflow(t *testing.T) {
+	suite.Run(t, new(WebrootDeployLockWorkflowTestSuite))
+}
@@ -0,0 +1,102 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// WebrootNginxPreviewWorkflow renders the nginx config for a webroot on one
+// node of its shard from the same inputs UpdateWebrootWorkflow uses, plus the
+// webroot's daemon proxies, and returns it without applying anything. The
+// caller is an API request waiting for the result, so the node activity is
+// not retried.
+func WebrootNginxPreviewWorkflow(ctx workflow.Context, webrootID string) (*model.WebrootNginxPreview, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy:         &temporal.RetryPolicy{MaximumAttempts: 3},
+	})
+
+	var wctx activity.WebrootContext
+	if err := workflow.ExecuteActivity(ctx, "GetWebrootContext", webrootID).Get(ctx, &wctx); err != nil {
+		return nil, fmt.Errorf("get webroot context: %w", err)
+	}
+	if len(wctx.Nodes) == 0 {
+		return nil, fmt.Errorf("webroot %s has no web nodes to render on", webrootID)
+	}
+
+	fqdnParams := make([]activity.FQDNParam, len(wctx.FQDNs))
+	for i, f := range wctx.FQDNs {
+		var fqdnWebrootID string
+		if f.WebrootID != nil {
+			fqdnWebrootID = *f.WebrootID
+		}
+		fqdnParams[i] = activity.FQDNParam{
+			FQDN:                 f.FQDN,
+			WebrootID:            fqdnWebrootID,
+			SSLEnabled:           f.SSLEnabled,
+			ForceHTTPS:           f.ForceHTTPS,
+			RedirectTarget:       f.RedirectTarget,
+			RedirectCode:         f.RedirectCode,
+			RedirectPreservePath: f.RedirectPreservePath,
+		}
+	}
+	if serviceHostname := webrootServiceHostname(wctx); serviceHostname != "" {
+		fqdnParams = append(fqdnParams, activity.FQDNParam{
+			FQDN:      serviceHostname,
+			WebrootID: wctx.Webroot.ID,
+		})
+	}
+
+	var daemons []model.Daemon
+	if err := workflow.ExecuteActivity(ctx, "ListDaemonsByWebroot", webrootID).Get(ctx, &daemons); err != nil {
+		return nil, fmt.Errorf("list daemons: %w", err)
+	}
+
+	node := wctx.Nodes[0]
+	nodeCtx := nodeActivityCtx(ctx, node.ID)
+	ao := workflow.GetActivityOptions(nodeCtx)
+	ao.ScheduleToStartTimeout = 10 * time.Second
+	ao.StartToCloseTimeout = 30 * time.Second
+	ao.ScheduleToCloseTimeout = 0
+	ao.RetryPolicy = &temporal.RetryPolicy{MaximumAttempts: 1}
+	nodeCtx = workflow.WithActivityOptions(nodeCtx, ao)
+
+	var config string
+	err := workflow.ExecuteActivity(nodeCtx, "PreviewNginxConfig", activity.UpdateWebrootParams{
+		ID:             wctx.Webroot.ID,
+		TenantName:     wctx.Tenant.ID,
+		Name:           wctx.Webroot.ID,
+		Runtime:        wctx.Webroot.Runtime,
+		RuntimeVersion: wctx.Webroot.RuntimeVersion,
+		RuntimeConfig:  string(wctx.Webroot.RuntimeConfig),
+		PublicFolder:   wctx.Webroot.PublicFolder,
+		ErrorPages:     wctx.Webroot.ErrorPages,
+		BasicAuth:      wctx.Webroot.BasicAuth,
+		AccessLog:      wctx.Webroot.AccessLogEnabled,
+		Limits:         wctx.Webroot.ConnectionLimits,
+		StaticRules:    wctx.Webroot.StaticRules,
+		GeoBlocking:    wctx.Webroot.GeoBlocking,
+		DeployLock:     wctx.Webroot.DeployLockedUntil,
+		EnvVars:        wctx.EnvVars,
+		EnvFileName:    wctx.Webroot.EnvFileName,
+		FQDNs:          fqdnParams,
+		Daemons:        webrootDaemonProxies(daemons, wctx.Tenant, wctx.Nodes),
+	}).Get(ctx, &config)
+	if err != nil {
+		return nil, fmt.Errorf("render nginx config on node %s: %w", node.ID, err)
+	}
+
+	return &model.WebrootNginxPreview{
+		WebrootID:   webrootID,
+		NodeID:      node.ID,
+		Config:      config,
+		GeneratedAt: workflow.Now(ctx).UTC(),
+		Note:        model.WebrootNginxPreviewNote,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type WebrootNginxPreviewWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *WebrootNginxPreviewWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *WebrootNginxPreviewWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *WebrootNginxPreviewWorkflowTestSuite) previewContext() activity.WebrootContext {
+	webrootID := "test-webroot-1"
+	shardIdx := 2
+	return activity.WebrootContext{
+		Webroot: model.Webroot{
+			ID:                     webrootID,
+			TenantID:               "test-tenant-1",
+			Runtime:                "php",
+			RuntimeVersion:         "8.5",
+			PublicFolder:           "public",
+			ServiceHostnameEnabled: true,
+			ErrorPages:             map[int]string{404: "/404.html"},
+		},
+		Tenant: model.Tenant{ID: "test-tenant-1", UID: 5001},
+		Nodes: []model.Node{
+			{ID: "node-1", ClusterID: "dev-1", ShardIndex: &shardIdx},
+			{ID: "node-2", ClusterID: "dev-1"},
+		},
+		FQDNs: []model.FQDN{
+			{FQDN: "example.com", WebrootID: &webrootID, SSLEnabled: true},
+		},
+		BrandBaseHostname: "hosting.test",
+	}
+}
+
+func (s *WebrootNginxPreviewWorkflowTestSuite) TestRendersOnFirstNode() {
+	wctx := s.previewContext()
+	proxyPath := "/ws"
+	proxyPort := 14000
+	nodeID := "node-1"
+	daemons := []model.Daemon{
+		{ID: "d1", NodeID: &nodeID, ProxyPath: &proxyPath, ProxyPort: &proxyPort, Status: model.StatusActive},
+		{ID: "d2", ProxyPath: &proxyPath, ProxyPort: &proxyPort, Status: model.StatusFailed},
+	}
+
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(&wctx, nil)
+	s.env.OnActivity("ListDaemonsByWebroot", mock.Anything, "test-webroot-1").Return(daemons, nil)
+	s.env.OnActivity("PreviewNginxConfig", mock.Anything, mock.MatchedBy(func(p activity.UpdateWebrootParams) bool {
+		return p.TenantName == "test-tenant-1" && p.Name == "test-webroot-1" &&
+			p.PublicFolder == "public" && p.ErrorPages[404] == "/404.html" &&
+			len(p.FQDNs) == 2 && p.FQDNs[0].FQDN == "example.com" && p.FQDNs[0].SSLEnabled &&
+			p.FQDNs[1].FQDN == "test-webroot-1.test-tenant-1.hosting.test" &&
+			len(p.Daemons) == 1 && p.Daemons[0].ProxyPath == "/ws" && p.Daemons[0].TargetIP != "127.0.0.1"
+	})).Return("server { listen 80; }", nil).Once()
+
+	s.env.ExecuteWorkflow(WebrootNginxPreviewWorkflow, "test-webroot-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+
+	var got model.WebrootNginxPreview
+	s.NoError(s.env.GetWorkflowResult(&got))
+	s.Equal("test-webroot-1", got.WebrootID)
+	s.Equal("node-1", got.NodeID)
+	s.Equal("server { listen 80; }", got.Config)
+	s.Equal(model.WebrootNginxPreviewNote, got.Note)
+	s.False(got.GeneratedAt.IsZero())
+}
+
+func (s *WebrootNginxPreviewWorkflowTestSuite) TestNoNodes() {
+	wctx := s.previewContext()
+	wctx.Nodes = nil
+
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(&wctx, nil)
+
+	s.env.ExecuteWorkflow(WebrootNginxPreviewWorkflow, "test-webroot-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.ErrorContains(s.env.GetWorkflowError(), "no web nodes")
+}
+
+func (s *WebrootNginxPreviewWorkflowTestSuite) TestNodeFails_NotRetried() {
+	wctx := s.previewContext()
+
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(&wctx, nil)
+	s.env.OnActivity("ListDaemonsByWebroot", mock.Anything, "test-webroot-1").Return(nil, nil)
+	s.env.OnActivity("PreviewNginxConfig", mock.Anything, mock.Anything).
+		Return("", fmt.Errorf("activity timeout")).Once()
+
+	s.env.ExecuteWorkflow(WebrootNginxPreviewWorkflow, "test-webroot-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.ErrorContains(s.env.GetWorkflowError(), "node-1")
+}
+
+func TestWebrootNginxPreviewWorkflow(t *testing.T) {
+	suite.Run(t, new(WebrootNginxPreviewWorkflowTestSuite))
+}
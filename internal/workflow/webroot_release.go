@@ -0,0 +1,161 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// CreateWebrootReleaseWorkflow creates the directory a release is uploaded
+// into. Webroot storage is shared, so one node of the shard is enough.
+func CreateWebrootReleaseWorkflow(ctx workflow.Context, releaseID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	err := workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "webroot_releases",
+		ID:     releaseID,
+		Status: model.StatusProvisioning,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	release, wctx, err := getWebrootReleaseContext(ctx, releaseID)
+	if err != nil {
+		_ = setResourceFailed(ctx, "webroot_releases", releaseID, err)
+		return err
+	}
+
+	err = workflow.ExecuteActivity(nodeActivityCtx(ctx, wctx.Nodes[0].ID), "CreateWebrootRelease", activity.WebrootReleaseParams{
+		TenantName:  wctx.Tenant.ID,
+		WebrootName: wctx.Webroot.ID,
+		Release:     release.Name,
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setResourceFailed(ctx, "webroot_releases", releaseID, err)
+		return err
+	}
+
+	return workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+		Table:  "webroot_releases",
+		ID:     releaseID,
+		Status: model.StatusActive,
+	}).Get(ctx, nil)
+}
+
+// PromoteWebrootReleaseWorkflow makes a release live. The webroot's current
+// symlink is swapped to the release with a single rename, then the webroot is
+// updated on every node so nginx and the runtime serve from the symlink (the
+// first promotion moves them off the plain webroot dir) and the runtime is
+// reloaded onto the new code. Finally, releases beyond the newest
+// model.WebrootReleasesToKeep are pruned. Rollback uses this workflow too.
+func PromoteWebrootReleaseWorkflow(ctx workflow.Context, releaseID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	release, wctx, err := getWebrootReleaseContext(ctx, releaseID)
+	if err != nil {
+		return err
+	}
+	if release.Status != model.StatusActive {
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("release %s is %s, not active", release.Name, release.Status), "InvalidReleaseStatus", nil)
+	}
+
+	nodeCtx := nodeActivityCtx(ctx, wctx.Nodes[0].ID)
+	err = workflow.ExecuteActivity(nodeCtx, "PromoteWebrootRelease", activity.WebrootReleaseParams{
+		TenantName:  wctx.Tenant.ID,
+		WebrootName: wctx.Webroot.ID,
+		Release:     release.Name,
+	}).Get(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("promote release %s: %w", release.Name, err)
+	}
+
+	// The symlink is live from here on; record it before anything else can fail.
+	err = workflow.ExecuteActivity(ctx, "SetCurrentWebrootRelease", activity.SetCurrentWebrootReleaseParams{
+		WebrootID: release.WebrootID,
+		ReleaseID: release.ID,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID: fmt.Sprintf("update-webroot-%s-release-%s", release.WebrootID, release.Name),
+	})
+	if err := workflow.ExecuteChildWorkflow(childCtx, UpdateWebrootWorkflow, release.WebrootID).Get(ctx, nil); err != nil {
+		return fmt.Errorf("reload webroot after promoting %s: %w", release.Name, err)
+	}
+
+	pruneWebrootReleases(ctx, wctx, release.WebrootID)
+	return nil
+}
+
+// getWebrootReleaseContext fetches a release together with its webroot's
+// context, failing if the webroot has no nodes to act on.
+func getWebrootReleaseContext(ctx workflow.Context, releaseID string) (*model.WebrootRelease, activity.WebrootContext, error) {
+	var release model.WebrootRelease
+	var wctx activity.WebrootContext
+	if err := workflow.ExecuteActivity(ctx, "GetWebrootReleaseByID", releaseID).Get(ctx, &release); err != nil {
+		return nil, wctx, err
+	}
+	if err := workflow.ExecuteActivity(ctx, "GetWebrootContext", release.WebrootID).Get(ctx, &wctx); err != nil {
+		return nil, wctx, err
+	}
+	if len(wctx.Nodes) == 0 {
+		return nil, wctx, fmt.Errorf("webroot %s has no nodes", release.WebrootID)
+	}
+	return &release, wctx, nil
+}
+
+// pruneWebrootReleases deletes the releases that fell out of the rollback
+// window, directory first, then record. Failures are logged and retried on the next promotion.
+func pruneWebrootReleases(ctx workflow.Context, wctx activity.WebrootContext, webrootID string) {
+	var prunable []model.WebrootRelease
+	err := workflow.ExecuteActivity(ctx, "ListPrunableWebrootReleases", activity.ListPrunableWebrootReleasesParams{
+		WebrootID: webrootID,
+		Keep:      model.WebrootReleasesToKeep,
+	}).Get(ctx, &prunable)
+	if err != nil {
+		workflow.GetLogger(ctx).Warn("failed to list prunable releases", "webrootID", webrootID, "error", err)
+		return
+	}
+
+	nodeCtx := nodeActivityCtx(ctx, wctx.Nodes[0].ID)
+	for _, r := range prunable {
+		err := workflow.ExecuteActivity(nodeCtx, "DeleteWebrootRelease", activity.WebrootReleaseParams{
+			TenantName:  wctx.Tenant.ID,
+			WebrootName: wctx.Webroot.ID,
+			Release:     r.Name,
+		}).Get(ctx, nil)
+		if err == nil {
+			err = workflow.ExecuteActivity(ctx, "DeleteWebrootReleaseRecord", r.ID).Get(ctx, nil)
+		}
+		if err != nil {
+			workflow.GetLogger(ctx).Warn("failed to prune release", "release", r.Name, "error", err)
+		}
+	}
+}
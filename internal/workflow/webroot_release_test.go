@@ -0,0 +1,164 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+func webrootReleaseTestContext() *activity.WebrootContext {
+	shardID := "test-shard-1"
+	return &activity.WebrootContext{
+		Webroot: model.Webroot{ID: "test-webroot-1", TenantID: "test-tenant-1", Runtime: "php"},
+		Tenant:  model.Tenant{ID: "test-tenant-1", ShardID: &shardID},
+		Nodes:   []model.Node{{ID: "node-1"}, {ID: "node-2"}},
+	}
+}
+
+// ---------- CreateWebrootReleaseWorkflow ----------
+
+type CreateWebrootReleaseWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *CreateWebrootReleaseWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *CreateWebrootReleaseWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *CreateWebrootReleaseWorkflowTestSuite) TestSuccess() {
+	release := model.WebrootRelease{ID: "rel-1", WebrootID: "test-webroot-1", Name: "20261015T120000Z"}
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "webroot_releases", ID: "rel-1", Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetWebrootReleaseByID", mock.Anything, "rel-1").Return(&release, nil)
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(webrootReleaseTestContext(), nil)
+	s.env.OnActivity("CreateWebrootRelease", mock.Anything, activity.WebrootReleaseParams{
+		TenantName: "test-tenant-1", WebrootName: "test-webroot-1", Release: "20261015T120000Z",
+	}).Return(nil).Once()
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "webroot_releases", ID: "rel-1", Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(CreateWebrootReleaseWorkflow, "rel-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CreateWebrootReleaseWorkflowTestSuite) TestNodeFails_SetsFailed() {
+	release := model.WebrootRelease{ID: "rel-1", WebrootID: "test-webroot-1", Name: "20261015T120000Z"}
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "webroot_releases", ID: "rel-1", Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetWebrootReleaseByID", mock.Anything, "rel-1").Return(&release, nil)
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(webrootReleaseTestContext(), nil)
+	s.env.OnActivity("CreateWebrootRelease", mock.Anything, mock.Anything).Return(fmt.Errorf("mount missing"))
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("webroot_releases", "rel-1")).Return(nil)
+
+	s.env.ExecuteWorkflow(CreateWebrootReleaseWorkflow, "rel-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func TestCreateWebrootReleaseWorkflow(t *testing.T) {
+	suite.Run(t, new(CreateWebrootReleaseWorkflowTestSuite))
+}
+
+// ---------- PromoteWebrootReleaseWorkflow ----------
+
+type PromoteWebrootReleaseWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *PromoteWebrootReleaseWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *PromoteWebrootReleaseWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *PromoteWebrootReleaseWorkflowTestSuite) TestSuccess_PrunesOldReleases() {
+	release := model.WebrootRelease{ID: "rel-7", WebrootID: "test-webroot-1", Name: "20261015T120000Z", Status: model.StatusActive}
+	old := model.WebrootRelease{ID: "rel-1", WebrootID: "test-webroot-1", Name: "20261001T120000Z", Status: model.StatusActive}
+
+	s.env.OnActivity("GetWebrootReleaseByID", mock.Anything, "rel-7").Return(&release, nil)
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(webrootReleaseTestContext(), nil)
+	s.env.OnActivity("PromoteWebrootRelease", mock.Anything, activity.WebrootReleaseParams{
+		TenantName: "test-tenant-1", WebrootName: "test-webroot-1", Release: "20261015T120000Z",
+	}).Return(nil).Once()
+	s.env.OnActivity("SetCurrentWebrootRelease", mock.Anything, activity.SetCurrentWebrootReleaseParams{
+		WebrootID: "test-webroot-1", ReleaseID: "rel-7",
+	}).Return(nil)
+	s.env.OnWorkflow(UpdateWebrootWorkflow, mock.Anything, "test-webroot-1").Return(nil)
+	s.env.OnActivity("ListPrunableWebrootReleases", mock.Anything, activity.ListPrunableWebrootReleasesParams{
+		WebrootID: "test-webroot-1", Keep: model.WebrootReleasesToKeep,
+	}).Return([]model.WebrootRelease{old}, nil)
+	s.env.OnActivity("DeleteWebrootRelease", mock.Anything, activity.WebrootReleaseParams{
+		TenantName: "test-tenant-1", WebrootName: "test-webroot-1", Release: "20261001T120000Z",
+	}).Return(nil)
+	s.env.OnActivity("DeleteWebrootReleaseRecord", mock.Anything, "rel-1").Return(nil)
+
+	s.env.ExecuteWorkflow(PromoteWebrootReleaseWorkflow, "rel-7")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *PromoteWebrootReleaseWorkflowTestSuite) TestPruneFailure_StillSucceeds() {
+	release := model.WebrootRelease{ID: "rel-7", WebrootID: "test-webroot-1", Name: "20261015T120000Z", Status: model.StatusActive}
+
+	s.env.OnActivity("GetWebrootReleaseByID", mock.Anything, "rel-7").Return(&release, nil)
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(webrootReleaseTestContext(), nil)
+	s.env.OnActivity("PromoteWebrootRelease", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("SetCurrentWebrootRelease", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnWorkflow(UpdateWebrootWorkflow, mock.Anything, "test-webroot-1").Return(nil)
+	s.env.OnActivity("ListPrunableWebrootReleases", mock.Anything, mock.Anything).Return(nil, fmt.Errorf("db down"))
+
+	s.env.ExecuteWorkflow(PromoteWebrootReleaseWorkflow, "rel-7")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *PromoteWebrootReleaseWorkflowTestSuite) TestReleaseNotActive_Fails() {
+	release := model.WebrootRelease{ID: "rel-7", WebrootID: "test-webroot-1", Name: "20261015T120000Z", Status: model.StatusProvisioning}
+
+	s.env.OnActivity("GetWebrootReleaseByID", mock.Anything, "rel-7").Return(&release, nil)
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(webrootReleaseTestContext(), nil)
+
+	s.env.ExecuteWorkflow(PromoteWebrootReleaseWorkflow, "rel-7")
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func (s *PromoteWebrootReleaseWorkflowTestSuite) TestSymlinkSwapFails_DoesNotRecordCurrent() {
+	release := model.WebrootRelease{ID: "rel-7", WebrootID: "test-webroot-1", Name: "20261015T120000Z", Status: model.StatusActive}
+
+	s.env.OnActivity("GetWebrootReleaseByID", mock.Anything, "rel-7").Return(&release, nil)
+	s.env.OnActivity("GetWebrootContext", mock.Anything, "test-webroot-1").Return(webrootReleaseTestContext(), nil)
+	s.env.OnActivity("PromoteWebrootRelease", mock.Anything, mock.Anything).Return(fmt.Errorf("release does not exist"))
+
+	s.env.ExecuteWorkflow(PromoteWebrootReleaseWorkflow, "rel-7")
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func TestPromoteWebrootReleaseWorkflow(t *testing.T) {
+	suite.Run(t, new(PromoteWebrootReleaseWorkflowTestSuite))
+}
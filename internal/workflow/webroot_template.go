@@ -0,0 +1,78 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// CreateWebrootFromTemplateWorkflow provisions a webroot created from an app
+// template and, if the template created one, its database. The webroot's env
+// vars are already stored and are written by CreateWebrootWorkflow. If any
+// step fails, the database and webroot are deleted again so no half-built
+// site is left behind, and the original error is returned.
+func CreateWebrootFromTemplateWorkflow(ctx workflow.Context, params model.CreateWebrootFromTemplateParams) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	databaseStarted := false
+	err := runChildWorkflow(ctx, CreateWebrootWorkflow, "create-webroot-"+params.WebrootID, params.WebrootID)
+	if err == nil && params.DatabaseID != "" {
+		databaseStarted = true
+		err = runChildWorkflow(ctx, CreateDatabaseWorkflow, "create-database-"+params.DatabaseID, params.DatabaseID)
+	}
+	if err == nil {
+		return nil
+	}
+
+	// Roll back everything the template created.
+	logger := workflow.GetLogger(ctx)
+	var rollbackErrs []string
+	if params.DatabaseID != "" {
+		var rbErr error
+		if databaseStarted {
+			rbErr = runChildWorkflow(ctx, DeleteDatabaseWorkflow, "database-"+params.DatabaseID, params.DatabaseID)
+		} else {
+			// Never provisioned on a node: only the row exists.
+			rbErr = workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+				Table:  "databases",
+				ID:     params.DatabaseID,
+				Status: model.StatusDeleted,
+			}).Get(ctx, nil)
+		}
+		if rbErr != nil {
+			rollbackErrs = append(rollbackErrs, fmt.Sprintf("database %s: %v", params.DatabaseID, rbErr))
+		}
+	}
+	if rbErr := runChildWorkflow(ctx, DeleteWebrootWorkflow, "webroot-"+params.WebrootID, params.WebrootID); rbErr != nil {
+		rollbackErrs = append(rollbackErrs, fmt.Sprintf("webroot %s: %v", params.WebrootID, rbErr))
+	}
+
+	if len(rollbackErrs) > 0 {
+		logger.Error("template rollback incomplete", "webroot", params.WebrootID, "errors", joinErrors(rollbackErrs))
+		return fmt.Errorf("create webroot from template: %w (rollback failed: %s)", err, joinErrors(rollbackErrs))
+	}
+	return fmt.Errorf("create webroot from template: %w", err)
+}
+
+// runChildWorkflow runs fn as a child workflow with the given ID and waits
+// for it to finish.
+func runChildWorkflow(ctx workflow.Context, fn any, id string, arg any) error {
+	childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+		WorkflowID: id,
+	})
+	return workflow.ExecuteChildWorkflow(childCtx, fn, arg).Get(ctx, nil)
+}
@@ -0,0 +1,90 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type CreateWebrootFromTemplateWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *CreateWebrootFromTemplateWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+	s.env.RegisterWorkflow(CreateWebrootWorkflow)
+	s.env.RegisterWorkflow(CreateDatabaseWorkflow)
+	s.env.RegisterWorkflow(DeleteWebrootWorkflow)
+	s.env.RegisterWorkflow(DeleteDatabaseWorkflow)
+}
+
+func (s *CreateWebrootFromTemplateWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *CreateWebrootFromTemplateWorkflowTestSuite) TestSuccess() {
+	s.env.OnWorkflow(CreateWebrootWorkflow, mock.Anything, "w1").Return(nil).Once()
+	s.env.OnWorkflow(CreateDatabaseWorkflow, mock.Anything, "db1").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(CreateWebrootFromTemplateWorkflow, model.CreateWebrootFromTemplateParams{WebrootID: "w1", DatabaseID: "db1"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CreateWebrootFromTemplateWorkflowTestSuite) TestWithoutDatabase() {
+	s.env.OnWorkflow(CreateWebrootWorkflow, mock.Anything, "w1").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(CreateWebrootFromTemplateWorkflow, model.CreateWebrootFromTemplateParams{WebrootID: "w1"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *CreateWebrootFromTemplateWorkflowTestSuite) TestDatabaseFailureRollsBack() {
+	s.env.OnWorkflow(CreateWebrootWorkflow, mock.Anything, "w1").Return(nil).Once()
+	s.env.OnWorkflow(CreateDatabaseWorkflow, mock.Anything, "db1").Return(fmt.Errorf("mysql down")).Once()
+	s.env.OnWorkflow(DeleteDatabaseWorkflow, mock.Anything, "db1").Return(nil).Once()
+	s.env.OnWorkflow(DeleteWebrootWorkflow, mock.Anything, "w1").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(CreateWebrootFromTemplateWorkflow, model.CreateWebrootFromTemplateParams{WebrootID: "w1", DatabaseID: "db1"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "mysql down")
+}
+
+func (s *CreateWebrootFromTemplateWorkflowTestSuite) TestWebrootFailureDeletesPendingDatabase() {
+	s.env.OnWorkflow(CreateWebrootWorkflow, mock.Anything, "w1").Return(fmt.Errorf("runtime not installed")).Once()
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table:  "databases",
+		ID:     "db1",
+		Status: model.StatusDeleted,
+	}).Return(nil).Once()
+	s.env.OnWorkflow(DeleteWebrootWorkflow, mock.Anything, "w1").Return(nil).Once()
+
+	s.env.ExecuteWorkflow(CreateWebrootFromTemplateWorkflow, model.CreateWebrootFromTemplateParams{WebrootID: "w1", DatabaseID: "db1"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.NotContains(s.env.GetWorkflowError().Error(), "rollback failed")
+}
+
+func (s *CreateWebrootFromTemplateWorkflowTestSuite) TestRollbackFailureReported() {
+	s.env.OnWorkflow(CreateWebrootWorkflow, mock.Anything, "w1").Return(fmt.Errorf("node down")).Once()
+	s.env.OnWorkflow(DeleteWebrootWorkflow, mock.Anything, "w1").Return(fmt.Errorf("still down")).Once()
+
+	s.env.ExecuteWorkflow(CreateWebrootFromTemplateWorkflow, model.CreateWebrootFromTemplateParams{WebrootID: "w1"})
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "rollback failed")
+}
+
+func TestCreateWebrootFromTemplateWorkflow(t *testing.T) {
+	suite.Run(t, new(CreateWebrootFromTemplateWorkflowTestSuite))
+}
@@ -3,7 +3,9 @@ package workflow
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -51,7 +53,7 @@ func (s *CreateWebrootWorkflowTestSuite) TestSuccess() {
 		ShardID: &shardID,
 	}
 	fqdns := []model.FQDN{
-		{ID: fqdnID, FQDN: "example.com", WebrootID: &webrootID, SSLEnabled: true},
+		{ID: fqdnID, FQDN: "example.com", WebrootID: &webrootID, SSLEnabled: true, ForceHTTPS: true},
 	}
 	nodes := []model.Node{
 		{ID: "node-1"},
@@ -66,6 +68,9 @@ func (s *CreateWebrootWorkflowTestSuite) TestSuccess() {
 		Nodes:   nodes,
 		FQDNs:   fqdns,
 	}, nil)
+	s.env.OnActivity("GetCachedNodeRuntimes", mock.Anything, []string{"node-1"}).Return([]model.NodeRuntimes{
+		{NodeID: "node-1", Runtimes: []model.InstalledRuntime{{Runtime: "php", Version: "8.2"}}, ReportedAt: time.Now()},
+	}, nil)
 	s.env.OnActivity("CreateWebroot", mock.Anything, activity.CreateWebrootParams{
 		ID:             webrootID,
 		TenantName:     "test-tenant-1",
@@ -75,7 +80,7 @@ func (s *CreateWebrootWorkflowTestSuite) TestSuccess() {
 		RuntimeConfig:  "{}",
 		PublicFolder:   "public",
 		FQDNs: []activity.FQDNParam{
-			{FQDN: "example.com", WebrootID: webrootID, SSLEnabled: true},
+			{FQDN: "example.com", WebrootID: webrootID, SSLEnabled: true, ForceHTTPS: true},
 		},
 	}).Return(nil)
 	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
@@ -168,6 +173,9 @@ func (s *CreateWebrootWorkflowTestSuite) TestAgentFails_SetsStatusFailed() {
 		Nodes:   nodes,
 		FQDNs:   []model.FQDN{},
 	}, nil)
+	s.env.OnActivity("GetCachedNodeRuntimes", mock.Anything, []string{"node-1"}).Return([]model.NodeRuntimes{
+		{NodeID: "node-1", Runtimes: []model.InstalledRuntime{{Runtime: "php", Version: "8.2"}}, ReportedAt: time.Now()},
+	}, nil)
 	s.env.OnActivity("CreateWebroot", mock.Anything, mock.Anything).Return(fmt.Errorf("node agent down"))
 	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("webroots", webrootID)).Return(nil)
 	s.env.ExecuteWorkflow(CreateWebrootWorkflow, webrootID)
@@ -175,6 +183,47 @@ func (s *CreateWebrootWorkflowTestSuite) TestAgentFails_SetsStatusFailed() {
 	s.Error(s.env.GetWorkflowError())
 }
 
+func (s *CreateWebrootWorkflowTestSuite) TestRuntimeNotInstalled_FailsFast() {
+	webrootID := "test-webroot-5"
+	shardID := "test-shard-5"
+
+	webroot := model.Webroot{
+		ID:             webrootID,
+		TenantID:       "test-tenant-5",
+		Runtime:        "php",
+		RuntimeVersion: "7.4",
+		RuntimeConfig:  json.RawMessage(`{}`),
+	}
+	nodes := []model.Node{{ID: "node-1"}, {ID: "node-2"}}
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "webroots", ID: webrootID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetWebrootContext", mock.Anything, webrootID).Return(&activity.WebrootContext{
+		Webroot: webroot,
+		Tenant:  model.Tenant{ID: "test-tenant-5", ShardID: &shardID},
+		Nodes:   nodes,
+		FQDNs:   []model.FQDN{},
+	}, nil)
+	// node-1 has a stale report, node-2 has none: both are asked directly.
+	s.env.OnActivity("GetCachedNodeRuntimes", mock.Anything, []string{"node-1", "node-2"}).Return([]model.NodeRuntimes{
+		{NodeID: "node-1", Runtimes: []model.InstalledRuntime{{Runtime: "php", Version: "7.4"}}, ReportedAt: time.Now().Add(-2 * time.Hour)},
+	}, nil)
+	s.env.OnActivity("GetInstalledRuntimes", mock.Anything).Return([]model.InstalledRuntime{
+		{Runtime: "php", Version: "8.3"},
+		{Runtime: "php", Version: "8.5"},
+		{Runtime: "node", Version: "22"},
+	}, nil).Times(2)
+	s.env.OnActivity("SaveNodeRuntimes", mock.Anything, mock.Anything).Return(nil).Times(2)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, mock.MatchedBy(func(params activity.UpdateResourceStatusParams) bool {
+		return params.Status == model.StatusFailed && params.StatusMessage != nil &&
+			strings.Contains(*params.StatusMessage, "php 7.4 is not installed on node(s) node-1, node-2; supported php versions: 8.3, 8.5")
+	})).Return(nil)
+	s.env.ExecuteWorkflow(CreateWebrootWorkflow, webrootID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
 func (s *CreateWebrootWorkflowTestSuite) TestGetWebrootFails_SetsStatusFailed() {
 	webrootID := "test-webroot-4"
 
@@ -236,6 +285,9 @@ func (s *UpdateWebrootWorkflowTestSuite) TestSuccess() {
 		Nodes:   nodes,
 		FQDNs:   []model.FQDN{},
 	}, nil)
+	s.env.OnActivity("GetCachedNodeRuntimes", mock.Anything, []string{"node-1"}).Return([]model.NodeRuntimes{
+		{NodeID: "node-1", Runtimes: []model.InstalledRuntime{{Runtime: "php", Version: "8.3"}}, ReportedAt: time.Now()},
+	}, nil)
 	s.env.OnActivity("UpdateWebroot", mock.Anything, activity.UpdateWebrootParams{
 		ID:             webrootID,
 		TenantName:     "test-tenant-1",
@@ -254,6 +306,151 @@ func (s *UpdateWebrootWorkflowTestSuite) TestSuccess() {
 	s.NoError(s.env.GetWorkflowError())
 }
 
+func (s *UpdateWebrootWorkflowTestSuite) TestPHPExtensionNotInstalled_FailsFast() {
+	webrootID := "test-webroot-4"
+	shardID := "test-shard-4"
+
+	webroot := model.Webroot{
+		ID:             webrootID,
+		TenantID:       "test-tenant-4",
+		Runtime:        "php",
+		RuntimeVersion: "8.3",
+		RuntimeConfig:  json.RawMessage(`{"extensions":{"imagick":true,"opcache":false}}`),
+	}
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "webroots", ID: webrootID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetWebrootContext", mock.Anything, webrootID).Return(&activity.WebrootContext{
+		Webroot: webroot,
+		Tenant:  model.Tenant{ID: "test-tenant-4", ShardID: &shardID},
+		Nodes:   []model.Node{{ID: "node-1"}},
+		FQDNs:   []model.FQDN{},
+	}, nil)
+	// The cached report lacks imagick, so the node is asked again.
+	s.env.OnActivity("GetCachedNodeRuntimes", mock.Anything, []string{"node-1"}).Return([]model.NodeRuntimes{
+		{NodeID: "node-1", Runtimes: []model.InstalledRuntime{{Runtime: "php", Version: "8.3", Extensions: []string{"opcache"}}}, ReportedAt: time.Now()},
+	}, nil)
+	s.env.OnActivity("GetInstalledRuntimes", mock.Anything).Return([]model.InstalledRuntime{
+		{Runtime: "php", Version: "8.3", Extensions: []string{"apcu", "opcache"}},
+	}, nil).Once()
+	s.env.OnActivity("SaveNodeRuntimes", mock.Anything, mock.Anything).Return(nil).Once()
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, mock.MatchedBy(func(params activity.UpdateResourceStatusParams) bool {
+		return params.Status == model.StatusFailed && params.StatusMessage != nil &&
+			strings.Contains(*params.StatusMessage, "php 8.3 extension(s) imagick not installed on node(s) node-1; available extensions: apcu, opcache")
+	})).Return(nil)
+	s.env.ExecuteWorkflow(UpdateWebrootWorkflow, webrootID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func (s *UpdateWebrootWorkflowTestSuite) TestMissingErrorPages_SetsWarning() {
+	webrootID := "test-webroot-3"
+	tenantID := "test-tenant-3"
+	shardID := "test-shard-3"
+	errorPages := map[int]string{404: "public/404.html", 500: "public/500.html"}
+
+	webroot := model.Webroot{
+		ID:             webrootID,
+		TenantID:       tenantID,
+		Runtime:        "static",
+		RuntimeVersion: "1",
+		RuntimeConfig:  json.RawMessage(`{}`),
+		PublicFolder:   "public",
+		ErrorPages:     errorPages,
+	}
+	tenant := model.Tenant{
+		ID:      tenantID,
+		BrandID: "test-brand",
+		ShardID: &shardID,
+	}
+	nodes := []model.Node{
+		{ID: "node-1"},
+	}
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "webroots", ID: webrootID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetWebrootContext", mock.Anything, webrootID).Return(&activity.WebrootContext{
+		Webroot: webroot,
+		Tenant:  tenant,
+		Nodes:   nodes,
+		FQDNs:   []model.FQDN{},
+	}, nil)
+	s.env.OnActivity("UpdateWebroot", mock.Anything, activity.UpdateWebrootParams{
+		ID:             webrootID,
+		TenantName:     tenantID,
+		Name:           webrootID,
+		Runtime:        "static",
+		RuntimeVersion: "1",
+		RuntimeConfig:  `{}`,
+		PublicFolder:   "public",
+		ErrorPages:     errorPages,
+		FQDNs:          []activity.FQDNParam{},
+	}).Return(nil)
+	s.env.OnActivity("CheckErrorPages", mock.Anything, activity.CheckErrorPagesParams{
+		TenantName:   tenantID,
+		Name:         webrootID,
+		PublicFolder: "public",
+		ErrorPages:   errorPages,
+	}).Return([]string{"public/500.html"}, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, mock.MatchedBy(func(params activity.UpdateResourceStatusParams) bool {
+		return params.Status == model.StatusActive &&
+			params.StatusMessage != nil &&
+			strings.Contains(*params.StatusMessage, "public/500.html")
+	})).Return(nil)
+	s.env.ExecuteWorkflow(UpdateWebrootWorkflow, webrootID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *UpdateWebrootWorkflowTestSuite) TestBasicAuth_PassedToNodes() {
+	webrootID := "test-webroot-5"
+	tenantID := "test-tenant-5"
+	shardID := "test-shard-5"
+	basicAuth := map[string]string{"staging": "$2a$10$hash"}
+
+	webroot := model.Webroot{
+		ID:             webrootID,
+		TenantID:       tenantID,
+		Runtime:        "static",
+		RuntimeVersion: "1",
+		RuntimeConfig:  json.RawMessage(`{}`),
+		BasicAuth:      basicAuth,
+	}
+	tenant := model.Tenant{
+		ID:      tenantID,
+		BrandID: "test-brand",
+		ShardID: &shardID,
+	}
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "webroots", ID: webrootID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetWebrootContext", mock.Anything, webrootID).Return(&activity.WebrootContext{
+		Webroot: webroot,
+		Tenant:  tenant,
+		Nodes:   []model.Node{{ID: "node-1"}, {ID: "node-2"}},
+		FQDNs:   []model.FQDN{},
+	}, nil)
+	s.env.OnActivity("UpdateWebroot", mock.Anything, activity.UpdateWebrootParams{
+		ID:             webrootID,
+		TenantName:     tenantID,
+		Name:           webrootID,
+		Runtime:        "static",
+		RuntimeVersion: "1",
+		RuntimeConfig:  `{}`,
+		BasicAuth:      basicAuth,
+		FQDNs:          []activity.FQDNParam{},
+	}).Return(nil).Times(2)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "webroots", ID: webrootID, Status: model.StatusActive,
+	}).Return(nil)
+	s.env.ExecuteWorkflow(UpdateWebrootWorkflow, webrootID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
 func (s *UpdateWebrootWorkflowTestSuite) TestAgentFails_SetsStatusFailed() {
 	webrootID := "test-webroot-2"
 	tenantID := "test-tenant-2"
@@ -285,6 +482,9 @@ func (s *UpdateWebrootWorkflowTestSuite) TestAgentFails_SetsStatusFailed() {
 		Nodes:   nodes,
 		FQDNs:   []model.FQDN{},
 	}, nil)
+	s.env.OnActivity("GetCachedNodeRuntimes", mock.Anything, []string{"node-1"}).Return([]model.NodeRuntimes{
+		{NodeID: "node-1", Runtimes: []model.InstalledRuntime{{Runtime: "php", Version: "8.3"}}, ReportedAt: time.Now()},
+	}, nil)
 	s.env.OnActivity("UpdateWebroot", mock.Anything, mock.Anything).Return(fmt.Errorf("node agent down"))
 	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("webroots", webrootID)).Return(nil)
 	s.env.ExecuteWorkflow(UpdateWebrootWorkflow, webrootID)
@@ -26,6 +26,7 @@ func registerActivities(env *testsuite.TestWorkflowEnvironment) {
 	env.RegisterActivity(&activity.Stalwart{})
 	env.RegisterActivity(&activity.Callback{})
 	env.RegisterActivity(&activity.Webhook{})
+	env.RegisterActivity(&activity.ExportStorage{})
 	env.RegisterActivity(&activity.AgentActivities{})
 }
 
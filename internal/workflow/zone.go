@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"go.temporal.io/sdk/temporal"
@@ -12,7 +13,8 @@ import (
 )
 
 // CreateZoneWorkflow creates a DNS zone in the PowerDNS database
-// along with default SOA and NS records.
+// along with default SOA and NS records and the records of the
+// brand's zone templates.
 func CreateZoneWorkflow(ctx workflow.Context, zoneID string) error {
 	ao := workflow.ActivityOptions{
 		StartToCloseTimeout: 30 * time.Second,
@@ -51,6 +53,16 @@ func CreateZoneWorkflow(ctx workflow.Context, zoneID string) error {
 		return err
 	}
 
+	// Load the brand's zone templates. A SOA template or NS templates replace
+	// the defaults below; all other templates become zone records.
+	var templates []model.BrandZoneTemplate
+	err = workflow.ExecuteActivity(ctx, "ListBrandZoneTemplates", zone.BrandID).Get(ctx, &templates)
+	if err != nil {
+		_ = setResourceFailed(ctx, "zones", zoneID, err)
+		return err
+	}
+	soaTemplate, nsTemplates, templateRecords := expandZoneTemplates(ctx, zone.Name, brand, templates)
+
 	// Write zone to PowerDNS DB (PowerDNS domains table).
 	var domainID int
 	err = workflow.ExecuteActivity(ctx, "WriteDNSZone", activity.WriteDNSZoneParams{
@@ -62,44 +74,42 @@ func CreateZoneWorkflow(ctx workflow.Context, zoneID string) error {
 		return err
 	}
 
-	// Create SOA record.
-	soaContent := fmt.Sprintf("%s %s 1 10800 3600 604800 300", brand.PrimaryNS, brand.HostmasterEmail)
-	err = workflow.ExecuteActivity(ctx, "WriteDNSRecord", activity.WriteDNSRecordParams{
-		DomainID: domainID,
-		Name:     zone.Name,
-		Type:     "SOA",
-		Content:  soaContent,
-		TTL:      86400,
-	}).Get(ctx, nil)
+	// Create SOA and NS records: the brand's defaults unless templates define
+	// the zone's SOA or NS set.
+	soa, nsRecords := zoneApexRecords(domainID, zone.Name, brand, soaTemplate, nsTemplates)
+	err = workflow.ExecuteActivity(ctx, "WriteDNSRecord", soa).Get(ctx, nil)
 	if err != nil {
 		_ = setResourceFailed(ctx, "zones", zoneID, err)
 		return err
 	}
 
-	// Create primary NS record.
-	err = workflow.ExecuteActivity(ctx, "WriteDNSRecord", activity.WriteDNSRecordParams{
-		DomainID: domainID,
-		Name:     zone.Name,
-		Type:     "NS",
-		Content:  brand.PrimaryNS,
-		TTL:      86400,
-	}).Get(ctx, nil)
-	if err != nil {
-		_ = setResourceFailed(ctx, "zones", zoneID, err)
-		return err
+	for _, ns := range nsRecords {
+		err = workflow.ExecuteActivity(ctx, "WriteDNSRecord", ns).Get(ctx, nil)
+		if err != nil {
+			_ = setResourceFailed(ctx, "zones", zoneID, err)
+			return err
+		}
 	}
 
-	// Create secondary NS record.
-	err = workflow.ExecuteActivity(ctx, "WriteDNSRecord", activity.WriteDNSRecordParams{
-		DomainID: domainID,
-		Name:     zone.Name,
-		Type:     "NS",
-		Content:  brand.SecondaryNS,
-		TTL:      86400,
-	}).Get(ctx, nil)
-	if err != nil {
-		_ = setResourceFailed(ctx, "zones", zoneID, err)
-		return err
+	// Let the nameservers of the zone's secondary regions transfer it.
+	if len(zone.SecondaryRegionIDs) > 0 {
+		if err := configureZoneReplication(ctx, zone, domainID); err != nil {
+			_ = setResourceFailed(ctx, "zones", zoneID, err)
+			return err
+		}
+	}
+
+	// Record the remaining templates as pending zone records. They are pushed
+	// to PowerDNS by the CreateZoneRecordWorkflow children spawned below.
+	if len(templateRecords) > 0 {
+		err = workflow.ExecuteActivity(ctx, "CreateTemplateZoneRecords", activity.CreateTemplateZoneRecordsParams{
+			ZoneID:  zoneID,
+			Records: templateRecords,
+		}).Get(ctx, nil)
+		if err != nil {
+			_ = setResourceFailed(ctx, "zones", zoneID, err)
+			return err
+		}
 	}
 
 	// Create retroactive auto DNS records for any existing FQDNs/email accounts
@@ -154,6 +164,69 @@ func CreateZoneWorkflow(ctx workflow.Context, zoneID string) error {
 	return nil
 }
 
+// expandZoneTemplates expands a brand's zone templates for a zone and splits
+// them into the SOA template, NS templates and records to create. Templates
+// that cannot be expanded, or that expand to an empty name or content (e.g.
+// {mail_hostname} on a brand without a mail host), are skipped.
+func expandZoneTemplates(ctx workflow.Context, zoneName string, brand model.Brand, templates []model.BrandZoneTemplate) (soa *model.BrandZoneTemplate, ns, records []model.BrandZoneTemplate) {
+	vars := model.ZoneTemplateVars(zoneName, brand)
+	for _, t := range templates {
+		name, err := model.ExpandZoneTemplate(t.Name, vars)
+		if err == nil {
+			t.Content, err = model.ExpandZoneTemplate(t.Content, vars)
+		}
+		if err == nil && (name == "" || strings.TrimSpace(t.Content) == "") {
+			err = fmt.Errorf("expands to an empty name or content")
+		}
+		if err != nil {
+			workflow.GetLogger(ctx).Warn("skipping zone template", "templateID", t.ID, "zone", zoneName, "error", err)
+			continue
+		}
+		t.Name = name
+
+		switch t.Type {
+		case "SOA":
+			soa = &t
+		case "NS":
+			ns = append(ns, t)
+		default:
+			records = append(records, t)
+		}
+	}
+	return soa, ns, records
+}
+
+// zoneApexRecords returns the SOA and NS records of a zone: the brand's
+// primary and secondary nameservers and hostmaster, replaced by the expanded
+// SOA template and NS templates where the brand defines them.
+func zoneApexRecords(domainID int, zoneName string, brand model.Brand, soaTemplate *model.BrandZoneTemplate, nsTemplates []model.BrandZoneTemplate) (activity.WriteDNSRecordParams, []activity.WriteDNSRecordParams) {
+	soa := activity.WriteDNSRecordParams{
+		DomainID: domainID,
+		Name:     zoneName,
+		Type:     "SOA",
+		Content:  fmt.Sprintf("%s %s 1 10800 3600 604800 300", brand.PrimaryNS, brand.HostmasterEmail),
+		TTL:      86400,
+	}
+	if soaTemplate != nil {
+		soa.Content = soaTemplate.Content
+		soa.TTL = soaTemplate.TTL
+	}
+
+	nsRecords := []activity.WriteDNSRecordParams{
+		{DomainID: domainID, Name: zoneName, Type: "NS", Content: brand.PrimaryNS, TTL: 86400},
+		{DomainID: domainID, Name: zoneName, Type: "NS", Content: brand.SecondaryNS, TTL: 86400},
+	}
+	if len(nsTemplates) > 0 {
+		nsRecords = nsRecords[:0]
+		for _, t := range nsTemplates {
+			nsRecords = append(nsRecords, activity.WriteDNSRecordParams{
+				DomainID: domainID, Name: t.Name, Type: "NS", Content: t.Content, TTL: t.TTL,
+			})
+		}
+	}
+	return soa, nsRecords
+}
+
 // DeleteZoneWorkflow removes a DNS zone from the PowerDNS database.
 func DeleteZoneWorkflow(ctx workflow.Context, zoneID string) error {
 	ao := workflow.ActivityOptions{
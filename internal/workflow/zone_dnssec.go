@@ -0,0 +1,249 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	enumspb "go.temporal.io/api/enums/v1"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// zskRolloverWait is how long a ZSK rollover waits between its steps: for
+// the new DNSKEY set to reach resolver caches before signing with the new
+// key, and for signatures by the old key to expire before removing it.
+// Twice the longest TTL the API allows (86400s).
+const zskRolloverWait = 48 * time.Hour
+
+// SignZoneWorkflow enables DNSSEC for a zone. PowerDNS signs the zone live
+// once it has keys; the workflow generates a KSK and ZSK, enables NSEC3 and
+// mirrors the public keys to the core DB, where the DS record for the
+// registrar is read from.
+func SignZoneWorkflow(ctx workflow.Context, zoneID string) error {
+	ctx = workflow.WithActivityOptions(ctx, zoneDNSSECActivityOptions())
+
+	var zone model.Zone
+	if err := workflow.ExecuteActivity(ctx, "GetZoneByID", zoneID).Get(ctx, &zone); err != nil {
+		_ = setZoneDNSSECStatus(ctx, zoneID, model.DNSSECFailed)
+		return err
+	}
+	if zone.Status != model.StatusActive {
+		_ = setZoneDNSSECStatus(ctx, zoneID, model.DNSSECFailed)
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("zone %s is %s, not active", zone.Name, zone.Status), "ZONE_NOT_ACTIVE", nil)
+	}
+
+	domainID, err := dnsZoneDomainID(ctx, zone.Name)
+	if err != nil {
+		_ = setZoneDNSSECStatus(ctx, zoneID, model.DNSSECFailed)
+		return err
+	}
+
+	var keys []activity.DNSSECKey
+	err = workflow.ExecuteActivity(ctx, "EnableDNSSECZone", activity.EnableDNSSECZoneParams{
+		DomainID: domainID,
+		ZoneName: zone.Name,
+	}).Get(ctx, &keys)
+	if err != nil {
+		_ = setZoneDNSSECStatus(ctx, zoneID, model.DNSSECFailed)
+		return err
+	}
+
+	err = workflow.ExecuteActivity(ctx, "SyncZoneDNSSECKeys", activity.SyncZoneDNSSECKeysParams{
+		ZoneID: zoneID,
+		Keys:   keys,
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setZoneDNSSECStatus(ctx, zoneID, model.DNSSECFailed)
+		return err
+	}
+
+	return setZoneDNSSECStatus(ctx, zoneID, model.DNSSECSigned)
+}
+
+// UnsignZoneWorkflow disables DNSSEC for a zone by removing its keys from
+// PowerDNS. The DS record must be removed at the registrar first, or
+// validating resolvers will reject the zone.
+func UnsignZoneWorkflow(ctx workflow.Context, zoneID string) error {
+	ctx = workflow.WithActivityOptions(ctx, zoneDNSSECActivityOptions())
+
+	var zone model.Zone
+	if err := workflow.ExecuteActivity(ctx, "GetZoneByID", zoneID).Get(ctx, &zone); err != nil {
+		_ = setZoneDNSSECStatus(ctx, zoneID, model.DNSSECFailed)
+		return err
+	}
+
+	var domainID int
+	if err := workflow.ExecuteActivity(ctx, "GetDNSZoneIDByName", zone.Name).Get(ctx, &domainID); err != nil {
+		_ = setZoneDNSSECStatus(ctx, zoneID, model.DNSSECFailed)
+		return err
+	}
+	// A zone missing from PowerDNS has nothing to unsign.
+	if domainID != 0 {
+		if err := workflow.ExecuteActivity(ctx, "DisableDNSSECZone", domainID).Get(ctx, nil); err != nil {
+			_ = setZoneDNSSECStatus(ctx, zoneID, model.DNSSECFailed)
+			return err
+		}
+	}
+
+	err := workflow.ExecuteActivity(ctx, "SyncZoneDNSSECKeys", activity.SyncZoneDNSSECKeysParams{
+		ZoneID: zoneID,
+	}).Get(ctx, nil)
+	if err != nil {
+		_ = setZoneDNSSECStatus(ctx, zoneID, model.DNSSECFailed)
+		return err
+	}
+
+	return setZoneDNSSECStatus(ctx, zoneID, model.DNSSECUnsigned)
+}
+
+// RolloverZoneZSKWorkflow replaces a signed zone's ZSK using the pre-publish
+// method (RFC 6781 §4.1.1.1): publish the new key, wait, sign with it, wait,
+// remove the old key. The KSK, and so the DS record at the registrar, is
+// unchanged. The rollover stops early if the zone is unsigned meanwhile.
+func RolloverZoneZSKWorkflow(ctx workflow.Context, zoneID string) error {
+	ctx = workflow.WithActivityOptions(ctx, zoneDNSSECActivityOptions())
+	logger := workflow.GetLogger(ctx)
+
+	zone, domainID, signed, err := signedZoneDomain(ctx, zoneID)
+	if err != nil || !signed {
+		return err
+	}
+
+	var newKeyID int
+	if err := workflow.ExecuteActivity(ctx, "PrepublishDNSSECZSK", domainID).Get(ctx, &newKeyID); err != nil {
+		return err
+	}
+	if err := syncZoneDNSSECKeys(ctx, zoneID, domainID); err != nil {
+		return err
+	}
+	logger.Info("published new zsk", "zone", zone.Name, "key", newKeyID)
+
+	if err := workflow.Sleep(ctx, zskRolloverWait); err != nil {
+		return err
+	}
+	if _, domainID, signed, err = signedZoneDomain(ctx, zoneID); err != nil || !signed {
+		return err
+	}
+	err = workflow.ExecuteActivity(ctx, "ActivateDNSSECZSK", activity.ActivateDNSSECZSKParams{
+		DomainID: domainID,
+		KeyID:    newKeyID,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := syncZoneDNSSECKeys(ctx, zoneID, domainID); err != nil {
+		return err
+	}
+	logger.Info("signing with new zsk", "zone", zone.Name, "key", newKeyID)
+
+	if err := workflow.Sleep(ctx, zskRolloverWait); err != nil {
+		return err
+	}
+	if _, domainID, signed, err = signedZoneDomain(ctx, zoneID); err != nil || !signed {
+		return err
+	}
+	err = workflow.ExecuteActivity(ctx, "RemoveInactiveDNSSECZSKs", activity.ActivateDNSSECZSKParams{
+		DomainID: domainID,
+		KeyID:    newKeyID,
+	}).Get(ctx, nil)
+	if err != nil {
+		return err
+	}
+	return syncZoneDNSSECKeys(ctx, zoneID, domainID)
+}
+
+// ScheduleZSKRolloversWorkflow starts a RolloverZoneZSKWorkflow for every
+// signed zone whose ZSK is older than maxAgeDays. Rollovers take days, so
+// they are started as abandoned children and not waited for. A maxAgeDays
+// of 0 disables rollovers.
+func ScheduleZSKRolloversWorkflow(ctx workflow.Context, maxAgeDays int) error {
+	if maxAgeDays <= 0 {
+		return nil
+	}
+	ctx = workflow.WithActivityOptions(ctx, zoneDNSSECActivityOptions())
+	logger := workflow.GetLogger(ctx)
+
+	var zoneIDs []string
+	if err := workflow.ExecuteActivity(ctx, "ListZonesDueForZSKRollover", maxAgeDays).Get(ctx, &zoneIDs); err != nil {
+		return err
+	}
+
+	for _, id := range zoneIDs {
+		childCtx := workflow.WithChildOptions(ctx, workflow.ChildWorkflowOptions{
+			WorkflowID:        "dnssec-zsk-rollover-" + id,
+			TaskQueue:         "hosting-tasks",
+			ParentClosePolicy: enumspb.PARENT_CLOSE_POLICY_ABANDON,
+		})
+		child := workflow.ExecuteChildWorkflow(childCtx, RolloverZoneZSKWorkflow, id)
+		// A rollover already running for the zone fails to start; that's fine.
+		if err := child.GetChildWorkflowExecution().Get(ctx, nil); err != nil {
+			logger.Warn("zsk rollover not started", "zone", id, "error", err)
+		}
+	}
+	logger.Info("scheduled zsk rollovers", "count", len(zoneIDs))
+	return nil
+}
+
+func zoneDNSSECActivityOptions() workflow.ActivityOptions {
+	return workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+}
+
+func setZoneDNSSECStatus(ctx workflow.Context, zoneID, status string) error {
+	return workflow.ExecuteActivity(ctx, "UpdateZoneDNSSECStatus", activity.UpdateZoneDNSSECStatusParams{
+		ZoneID: zoneID,
+		Status: status,
+	}).Get(ctx, nil)
+}
+
+// dnsZoneDomainID returns the PowerDNS domain ID of a zone, failing if the
+// zone is missing from PowerDNS.
+func dnsZoneDomainID(ctx workflow.Context, zoneName string) (int, error) {
+	var domainID int
+	if err := workflow.ExecuteActivity(ctx, "GetDNSZoneIDByName", zoneName).Get(ctx, &domainID); err != nil {
+		return 0, err
+	}
+	if domainID == 0 {
+		return 0, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("zone %s not found in PowerDNS", zoneName), "ZONE_NOT_FOUND", nil)
+	}
+	return domainID, nil
+}
+
+// signedZoneDomain loads a zone and its PowerDNS domain ID and reports
+// whether the zone is still signed.
+func signedZoneDomain(ctx workflow.Context, zoneID string) (model.Zone, int, bool, error) {
+	var zone model.Zone
+	if err := workflow.ExecuteActivity(ctx, "GetZoneByID", zoneID).Get(ctx, &zone); err != nil {
+		return zone, 0, false, err
+	}
+	if zone.DNSSECStatus != model.DNSSECSigned {
+		workflow.GetLogger(ctx).Info("zone no longer signed, stopping zsk rollover", "zone", zone.Name)
+		return zone, 0, false, nil
+	}
+	domainID, err := dnsZoneDomainID(ctx, zone.Name)
+	return zone, domainID, err == nil, err
+}
+
+// syncZoneDNSSECKeys mirrors a zone's current PowerDNS keys to the core DB.
+func syncZoneDNSSECKeys(ctx workflow.Context, zoneID string, domainID int) error {
+	var keys []activity.DNSSECKey
+	if err := workflow.ExecuteActivity(ctx, "ListDNSSECKeys", domainID).Get(ctx, &keys); err != nil {
+		return err
+	}
+	return workflow.ExecuteActivity(ctx, "SyncZoneDNSSECKeys", activity.SyncZoneDNSSECKeysParams{
+		ZoneID: zoneID,
+		Keys:   keys,
+	}).Get(ctx, nil)
+}
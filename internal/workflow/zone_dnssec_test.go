@@ -0,0 +1,145 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type ZoneDNSSECWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) mockZone(status, dnssecStatus string) {
+	s.env.OnActivity("GetZoneByID", mock.Anything, "zone-1").Return(&model.Zone{
+		ID:           "zone-1",
+		Name:         "example.com",
+		Status:       status,
+		DNSSECStatus: dnssecStatus,
+	}, nil)
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) expectDNSSECStatus(status string) {
+	s.env.OnActivity("UpdateZoneDNSSECStatus", mock.Anything, activity.UpdateZoneDNSSECStatusParams{
+		ZoneID: "zone-1",
+		Status: status,
+	}).Return(nil).Once()
+}
+
+func testDNSSECKeys() []activity.DNSSECKey {
+	return []activity.DNSSECKey{
+		{ID: 1, Flags: 257, Active: true, Published: true, KeyTag: 55648, Algorithm: 13, DNSKEY: "257 3 13 AAAA", DS: "55648 13 2 ABCD"},
+		{ID: 2, Flags: 256, Active: true, Published: true, KeyTag: 12345, Algorithm: 13, DNSKEY: "256 3 13 BBBB", DS: "12345 13 2 EF01"},
+	}
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) TestSign() {
+	s.mockZone(model.StatusActive, model.DNSSECSigning)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(42, nil)
+	s.env.OnActivity("EnableDNSSECZone", mock.Anything, activity.EnableDNSSECZoneParams{
+		DomainID: 42,
+		ZoneName: "example.com",
+	}).Return(testDNSSECKeys(), nil)
+	s.env.OnActivity("SyncZoneDNSSECKeys", mock.Anything, activity.SyncZoneDNSSECKeysParams{
+		ZoneID: "zone-1",
+		Keys:   testDNSSECKeys(),
+	}).Return(nil)
+	s.expectDNSSECStatus(model.DNSSECSigned)
+
+	s.env.ExecuteWorkflow(SignZoneWorkflow, "zone-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) TestSign_ZoneNotActive() {
+	s.mockZone(model.StatusFailed, model.DNSSECSigning)
+	s.expectDNSSECStatus(model.DNSSECFailed)
+
+	s.env.ExecuteWorkflow(SignZoneWorkflow, "zone-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.env.AssertNotCalled(s.T(), "EnableDNSSECZone", mock.Anything, mock.Anything)
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) TestSign_MissingFromPowerDNS() {
+	s.mockZone(model.StatusActive, model.DNSSECSigning)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(0, nil)
+	s.expectDNSSECStatus(model.DNSSECFailed)
+
+	s.env.ExecuteWorkflow(SignZoneWorkflow, "zone-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+	s.Contains(s.env.GetWorkflowError().Error(), "not found in PowerDNS")
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) TestUnsign() {
+	s.mockZone(model.StatusActive, model.DNSSECUnsigning)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(42, nil)
+	s.env.OnActivity("DisableDNSSECZone", mock.Anything, 42).Return(nil).Once()
+	s.env.OnActivity("SyncZoneDNSSECKeys", mock.Anything, activity.SyncZoneDNSSECKeysParams{ZoneID: "zone-1"}).Return(nil)
+	s.expectDNSSECStatus(model.DNSSECUnsigned)
+
+	s.env.ExecuteWorkflow(UnsignZoneWorkflow, "zone-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) TestRolloverZSK() {
+	s.mockZone(model.StatusActive, model.DNSSECSigned)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(42, nil)
+	s.env.OnActivity("PrepublishDNSSECZSK", mock.Anything, 42).Return(3, nil).Once()
+	s.env.OnActivity("ActivateDNSSECZSK", mock.Anything, activity.ActivateDNSSECZSKParams{DomainID: 42, KeyID: 3}).Return(nil).Once()
+	s.env.OnActivity("RemoveInactiveDNSSECZSKs", mock.Anything, activity.ActivateDNSSECZSKParams{DomainID: 42, KeyID: 3}).Return(nil).Once()
+	s.env.OnActivity("ListDNSSECKeys", mock.Anything, 42).Return(testDNSSECKeys(), nil).Times(3)
+	s.env.OnActivity("SyncZoneDNSSECKeys", mock.Anything, mock.Anything).Return(nil).Times(3)
+
+	s.env.ExecuteWorkflow(RolloverZoneZSKWorkflow, "zone-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) TestRolloverZSK_Unsigned() {
+	s.mockZone(model.StatusActive, model.DNSSECUnsigned)
+
+	s.env.ExecuteWorkflow(RolloverZoneZSKWorkflow, "zone-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.env.AssertNotCalled(s.T(), "PrepublishDNSSECZSK", mock.Anything, mock.Anything)
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) TestScheduleZSKRollovers() {
+	s.env.RegisterWorkflow(RolloverZoneZSKWorkflow)
+	s.env.OnActivity("ListZonesDueForZSKRollover", mock.Anything, 90).Return([]string{"zone-1", "zone-2"}, nil)
+	s.env.OnWorkflow(RolloverZoneZSKWorkflow, mock.Anything, mock.Anything).Return(nil).Times(2)
+
+	s.env.ExecuteWorkflow(ScheduleZSKRolloversWorkflow, 90)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ZoneDNSSECWorkflowTestSuite) TestScheduleZSKRollovers_Disabled() {
+	s.env.ExecuteWorkflow(ScheduleZSKRolloversWorkflow, 0)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	s.env.AssertNotCalled(s.T(), "ListZonesDueForZSKRollover", mock.Anything, mock.Anything)
+}
+
+func TestZoneDNSSECWorkflow(t *testing.T) {
+	suite.Run(t, new(ZoneDNSSECWorkflowTestSuite))
+}
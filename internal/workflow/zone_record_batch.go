@@ -0,0 +1,130 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ApplyZoneRecordsWorkflow applies a batch of record changes to a zone in one
+// PowerDNS transaction, bumping the SOA serial once. The records' statuses in
+// core follow the batch: all become active (or deleted) together, or all are
+// marked failed.
+func ApplyZoneRecordsWorkflow(ctx workflow.Context, params model.ZoneRecordBatchParams) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	failAll := func(err error) error {
+		for _, r := range params.Writes {
+			_ = setResourceFailed(ctx, "zone_records", r.RecordID, err)
+		}
+		for _, r := range params.Deletes {
+			_ = setResourceFailed(ctx, "zone_records", r.RecordID, err)
+		}
+		return err
+	}
+
+	for _, r := range params.Writes {
+		err := workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+			Table:  "zone_records",
+			ID:     r.RecordID,
+			Status: model.StatusProvisioning,
+		}).Get(ctx, nil)
+		if err != nil {
+			return failAll(err)
+		}
+	}
+
+	// Get the PowerDNS domain ID.
+	var domainID int
+	err := workflow.ExecuteActivity(ctx, "GetDNSZoneIDByName", params.ZoneName).Get(ctx, &domainID)
+	if err != nil {
+		return failAll(err)
+	}
+	if domainID == 0 {
+		return failAll(fmt.Errorf("zone %q not found in DNS", params.ZoneName))
+	}
+
+	batch := activity.ApplyDNSRecordBatchParams{DomainID: domainID}
+	for _, r := range params.Deletes {
+		batch.Deletes = append(batch.Deletes, activity.DNSRecordKey{Name: r.Name, Type: r.Type, Content: r.Content})
+	}
+	for _, r := range params.Writes {
+		batch.Writes = append(batch.Writes, activity.DNSRecordBatchWrite{
+			Name:     r.Name,
+			Type:     r.Type,
+			Content:  r.Content,
+			TTL:      r.TTL,
+			Priority: r.Priority,
+		})
+	}
+	if err := workflow.ExecuteActivity(ctx, "ApplyDNSRecordBatch", batch).Get(ctx, nil); err != nil {
+		return failAll(err)
+	}
+
+	// Custom records override auto records with the same name and type;
+	// removing the custom record brings the auto records back.
+	for _, r := range params.Deletes {
+		err := workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+			Table:  "zone_records",
+			ID:     r.RecordID,
+			Status: model.StatusDeleted,
+		}).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if r.ManagedBy == model.ManagedByCustom {
+			_ = workflow.ExecuteActivity(ctx, "ReactivateAutoRecords", activity.DeactivateAutoRecordsParams{
+				Name: r.Name,
+				Type: r.Type,
+			}).Get(ctx, nil)
+		}
+	}
+	for _, r := range params.Writes {
+		if r.ManagedBy == model.ManagedByCustom {
+			_ = workflow.ExecuteActivity(ctx, "DeactivateAutoRecords", activity.DeactivateAutoRecordsParams{
+				Name: r.Name,
+				Type: r.Type,
+			}).Get(ctx, nil)
+		}
+		err := workflow.ExecuteActivity(ctx, "UpdateResourceStatus", activity.UpdateResourceStatusParams{
+			Table:  "zone_records",
+			ID:     r.RecordID,
+			Status: model.StatusActive,
+		}).Get(ctx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetZoneSOASerialWorkflow returns the SOA serial of a zone in PowerDNS, or
+// nil if it has none. The caller is an API request waiting for the result.
+func GetZoneSOASerialWorkflow(ctx workflow.Context, zoneName string) (*uint32, error) {
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 10 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts: 1,
+		},
+	})
+	var serial *uint32
+	if err := workflow.ExecuteActivity(ctx, "GetDNSZoneSOASerial", zoneName).Get(ctx, &serial); err != nil {
+		return nil, err
+	}
+	return serial, nil
+}
@@ -0,0 +1,113 @@
+package workflow
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type ApplyZoneRecordsWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *ApplyZoneRecordsWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *ApplyZoneRecordsWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func zoneRecordBatchTestParams() model.ZoneRecordBatchParams {
+	return model.ZoneRecordBatchParams{
+		ZoneID:   "test-zone-1",
+		ZoneName: "example.com",
+		Writes: []model.ZoneRecordParams{
+			{RecordID: "rec-new", Name: "www.example.com", Type: "A", Content: "10.0.0.2", TTL: 300, ManagedBy: model.ManagedByCustom, ZoneName: "example.com"},
+		},
+		Deletes: []model.ZoneRecordParams{
+			{RecordID: "rec-old", Name: "www.example.com", Type: "A", Content: "10.0.0.1", TTL: 300, ManagedBy: model.ManagedByCustom, ZoneName: "example.com"},
+		},
+	}
+}
+
+func (s *ApplyZoneRecordsWorkflowTestSuite) TestSuccess() {
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "zone_records", ID: "rec-new", Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(42, nil)
+	s.env.OnActivity("ApplyDNSRecordBatch", mock.Anything, activity.ApplyDNSRecordBatchParams{
+		DomainID: 42,
+		Deletes:  []activity.DNSRecordKey{{Name: "www.example.com", Type: "A", Content: "10.0.0.1"}},
+		Writes:   []activity.DNSRecordBatchWrite{{Name: "www.example.com", Type: "A", Content: "10.0.0.2", TTL: 300}},
+	}).Return(nil).Once()
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "zone_records", ID: "rec-old", Status: model.StatusDeleted,
+	}).Return(nil)
+	s.env.OnActivity("ReactivateAutoRecords", mock.Anything, activity.DeactivateAutoRecordsParams{
+		Name: "www.example.com", Type: "A",
+	}).Return(nil)
+	s.env.OnActivity("DeactivateAutoRecords", mock.Anything, activity.DeactivateAutoRecordsParams{
+		Name: "www.example.com", Type: "A",
+	}).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "zone_records", ID: "rec-new", Status: model.StatusActive,
+	}).Return(nil)
+
+	s.env.ExecuteWorkflow(ApplyZoneRecordsWorkflow, zoneRecordBatchTestParams())
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ApplyZoneRecordsWorkflowTestSuite) TestBatchFails_MarksAllFailed() {
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "zone_records", ID: "rec-new", Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(42, nil)
+	s.env.OnActivity("ApplyDNSRecordBatch", mock.Anything, mock.Anything).Return(fmt.Errorf("serialization failure"))
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("zone_records", "rec-new")).Return(nil).Once()
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("zone_records", "rec-old")).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(ApplyZoneRecordsWorkflow, zoneRecordBatchTestParams())
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func (s *ApplyZoneRecordsWorkflowTestSuite) TestZoneMissing_MarksAllFailed() {
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "zone_records", ID: "rec-new", Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(0, nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("zone_records", "rec-new")).Return(nil).Once()
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, matchFailedStatus("zone_records", "rec-old")).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(ApplyZoneRecordsWorkflow, zoneRecordBatchTestParams())
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func (s *ApplyZoneRecordsWorkflowTestSuite) TestGetZoneSOASerial() {
+	serial := uint32(2026101501)
+	s.env.OnActivity("GetDNSZoneSOASerial", mock.Anything, "example.com").Return(&serial, nil)
+
+	s.env.ExecuteWorkflow(GetZoneSOASerialWorkflow, "example.com")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+	var got *uint32
+	s.NoError(s.env.GetWorkflowResult(&got))
+	s.Require().NotNil(got)
+	s.Equal(serial, *got)
+}
+
+func TestApplyZoneRecordsWorkflow(t *testing.T) {
+	suite.Run(t, new(ApplyZoneRecordsWorkflowTestSuite))
+}
@@ -0,0 +1,55 @@
+package workflow
+
+import (
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+// ConfigureZoneReplicationWorkflow applies a zone's secondary regions to
+// PowerDNS after they were changed: the nameservers of those regions get
+// NS records and may transfer the zone, and those of regions no longer
+// listed lose them. The zone stays active throughout.
+func ConfigureZoneReplicationWorkflow(ctx workflow.Context, zoneID string) error {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 30 * time.Second,
+		RetryPolicy: &temporal.RetryPolicy{
+			MaximumAttempts:    3,
+			InitialInterval:    1 * time.Second,
+			MaximumInterval:    10 * time.Second,
+			BackoffCoefficient: 2.0,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var zone model.Zone
+	if err := workflow.ExecuteActivity(ctx, "GetZoneByID", zoneID).Get(ctx, &zone); err != nil {
+		return err
+	}
+	domainID, err := dnsZoneDomainID(ctx, zone.Name)
+	if err != nil {
+		return err
+	}
+	return configureZoneReplication(ctx, zone, domainID)
+}
+
+// configureZoneReplication looks up the nameservers of the zone's secondary
+// regions and makes them secondaries of the zone in PowerDNS.
+func configureZoneReplication(ctx workflow.Context, zone model.Zone, domainID int) error {
+	var nameservers []model.RegionNameserver
+	if len(zone.SecondaryRegionIDs) > 0 {
+		err := workflow.ExecuteActivity(ctx, "GetRegionNameservers", zone.SecondaryRegionIDs).Get(ctx, &nameservers)
+		if err != nil {
+			return err
+		}
+	}
+	return workflow.ExecuteActivity(ctx, "ConfigureDNSZoneReplication", activity.ConfigureDNSZoneReplicationParams{
+		DomainID:    domainID,
+		ZoneName:    zone.Name,
+		Nameservers: nameservers,
+	}).Get(ctx, nil)
+}
@@ -0,0 +1,81 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/edvin/hosting/internal/activity"
+	"github.com/edvin/hosting/internal/model"
+)
+
+type ZoneReplicationWorkflowTestSuite struct {
+	suite.Suite
+	testsuite.WorkflowTestSuite
+	env *testsuite.TestWorkflowEnvironment
+}
+
+func (s *ZoneReplicationWorkflowTestSuite) SetupTest() {
+	s.env = s.NewTestWorkflowEnvironment()
+	registerActivities(s.env)
+}
+
+func (s *ZoneReplicationWorkflowTestSuite) AfterTest(suiteName, testName string) {
+	s.env.AssertExpectations(s.T())
+}
+
+func (s *ZoneReplicationWorkflowTestSuite) TestConfiguresSecondaries() {
+	s.env.OnActivity("GetZoneByID", mock.Anything, "zone-1").Return(&model.Zone{
+		ID:                 "zone-1",
+		Name:               "example.com",
+		Status:             model.StatusActive,
+		SecondaryRegionIDs: []string{"fra-1"},
+	}, nil)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(42, nil)
+	nameservers := []model.RegionNameserver{{Hostname: "ns1.fra.example.net", Address: "192.0.2.53"}}
+	s.env.OnActivity("GetRegionNameservers", mock.Anything, []string{"fra-1"}).Return(nameservers, nil)
+	s.env.OnActivity("ConfigureDNSZoneReplication", mock.Anything, activity.ConfigureDNSZoneReplicationParams{
+		DomainID:    42,
+		ZoneName:    "example.com",
+		Nameservers: nameservers,
+	}).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(ConfigureZoneReplicationWorkflow, "zone-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ZoneReplicationWorkflowTestSuite) TestNoSecondariesResetsZone() {
+	s.env.OnActivity("GetZoneByID", mock.Anything, "zone-1").Return(&model.Zone{
+		ID:     "zone-1",
+		Name:   "example.com",
+		Status: model.StatusActive,
+	}, nil)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(42, nil)
+	s.env.OnActivity("ConfigureDNSZoneReplication", mock.Anything, activity.ConfigureDNSZoneReplicationParams{
+		DomainID: 42,
+		ZoneName: "example.com",
+	}).Return(nil).Once()
+
+	s.env.ExecuteWorkflow(ConfigureZoneReplicationWorkflow, "zone-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
+func (s *ZoneReplicationWorkflowTestSuite) TestZoneMissingInPowerDNS() {
+	s.env.OnActivity("GetZoneByID", mock.Anything, "zone-1").Return(&model.Zone{
+		ID:   "zone-1",
+		Name: "example.com",
+	}, nil)
+	s.env.OnActivity("GetDNSZoneIDByName", mock.Anything, "example.com").Return(0, nil)
+
+	s.env.ExecuteWorkflow(ConfigureZoneReplicationWorkflow, "zone-1")
+	s.True(s.env.IsWorkflowCompleted())
+	s.Error(s.env.GetWorkflowError())
+}
+
+func TestZoneReplicationWorkflow(t *testing.T) {
+	suite.Run(t, new(ZoneReplicationWorkflowTestSuite))
+}
@@ -45,6 +45,7 @@ func (s *CreateZoneWorkflowTestSuite) TestSuccess() {
 		ID: "test-brand", PrimaryNS: "ns1.example.com", SecondaryNS: "ns2.example.com",
 		HostmasterEmail: "hostmaster.example.com", BaseHostname: "example.com",
 	}, nil)
+	s.env.OnActivity("ListBrandZoneTemplates", mock.Anything, "test-brand").Return([]model.BrandZoneTemplate{}, nil)
 	s.env.OnActivity("WriteDNSZone", mock.Anything, activity.WriteDNSZoneParams{
 		Name: "example.com",
 		Type: "NATIVE",
@@ -106,6 +107,7 @@ func (s *CreateZoneWorkflowTestSuite) TestWriteDNSZoneFails_SetsStatusFailed() {
 		ID: "test-brand", PrimaryNS: "ns1.example.com", SecondaryNS: "ns2.example.com",
 		HostmasterEmail: "hostmaster.example.com", BaseHostname: "example.com",
 	}, nil)
+	s.env.OnActivity("ListBrandZoneTemplates", mock.Anything, "test-brand").Return([]model.BrandZoneTemplate{}, nil)
 	s.env.OnActivity("WriteDNSZone", mock.Anything, activity.WriteDNSZoneParams{
 		Name: "example.com",
 		Type: "NATIVE",
@@ -128,6 +130,7 @@ func (s *CreateZoneWorkflowTestSuite) TestWriteSOAFails_SetsStatusFailed() {
 		ID: "test-brand", PrimaryNS: "ns1.example.com", SecondaryNS: "ns2.example.com",
 		HostmasterEmail: "hostmaster.example.com", BaseHostname: "example.com",
 	}, nil)
+	s.env.OnActivity("ListBrandZoneTemplates", mock.Anything, "test-brand").Return([]model.BrandZoneTemplate{}, nil)
 	s.env.OnActivity("WriteDNSZone", mock.Anything, mock.Anything).Return(42, nil)
 	// SOA write fails
 	s.env.OnActivity("WriteDNSRecord", mock.Anything, activity.WriteDNSRecordParams{
@@ -158,6 +161,66 @@ func (s *CreateZoneWorkflowTestSuite) TestGetBrandFails_SetsStatusFailed() {
 	s.Error(s.env.GetWorkflowError())
 }
 
+func (s *CreateZoneWorkflowTestSuite) TestBrandTemplates() {
+	zoneID := "test-zone-6"
+	zone := model.Zone{ID: zoneID, BrandID: "test-brand", Name: "example.com"}
+	prio := 10
+
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "zones", ID: zoneID, Status: model.StatusProvisioning,
+	}).Return(nil)
+	s.env.OnActivity("GetZoneByID", mock.Anything, zoneID).Return(&zone, nil)
+	s.env.OnActivity("GetBrandByID", mock.Anything, "test-brand").Return(&model.Brand{
+		ID: "test-brand", PrimaryNS: "ns1.example.com", SecondaryNS: "ns2.example.com",
+		HostmasterEmail: "hostmaster.example.com", BaseHostname: "example.com",
+		MailHostname: "mail.hosting.test",
+	}, nil)
+	s.env.OnActivity("ListBrandZoneTemplates", mock.Anything, "test-brand").Return([]model.BrandZoneTemplate{
+		{ID: "t1", Type: "SOA", Name: "{zone}", Content: "{primary_ns} {hostmaster_email} 1 7200 900 1209600 60", TTL: 3600},
+		{ID: "t2", Type: "NS", Name: "{zone}", Content: "ns.{zone}", TTL: 3600},
+		{ID: "t3", Type: "MX", Name: "{zone}", Content: "{mail_hostname}", TTL: 3600, Priority: &prio},
+		{ID: "t4", Type: "TXT", Name: "_dmarc.{zone}", Content: "{dmarc_policy}", TTL: 3600},
+	}, nil)
+	s.env.OnActivity("WriteDNSZone", mock.Anything, mock.Anything).Return(42, nil)
+	// Templated SOA and NS replace the brand defaults.
+	s.env.OnActivity("WriteDNSRecord", mock.Anything, activity.WriteDNSRecordParams{
+		DomainID: 42,
+		Name:     "example.com",
+		Type:     "SOA",
+		Content:  "ns1.example.com hostmaster.example.com 1 7200 900 1209600 60",
+		TTL:      3600,
+	}).Return(nil).Once()
+	s.env.OnActivity("WriteDNSRecord", mock.Anything, activity.WriteDNSRecordParams{
+		DomainID: 42,
+		Name:     "example.com",
+		Type:     "NS",
+		Content:  "ns.example.com",
+		TTL:      3600,
+	}).Return(nil).Once()
+	// The DMARC template expands to empty content and is skipped.
+	s.env.OnActivity("CreateTemplateZoneRecords", mock.Anything, activity.CreateTemplateZoneRecordsParams{
+		ZoneID: zoneID,
+		Records: []model.BrandZoneTemplate{
+			{ID: "t3", Type: "MX", Name: "example.com", Content: "mail.hosting.test", TTL: 3600, Priority: &prio},
+		},
+	}).Return(nil).Once()
+	s.env.OnActivity("RetroactiveAutoRecords", mock.Anything, mock.Anything).Return(nil)
+	s.env.OnActivity("UpdateResourceStatus", mock.Anything, activity.UpdateResourceStatusParams{
+		Table: "zones", ID: zoneID, Status: model.StatusActive,
+	}).Return(nil)
+	s.env.OnActivity("ListZoneRecordsByZoneID", mock.Anything, zoneID).Return([]model.ZoneRecord{
+		{ID: "rec-1", Type: "MX", Name: "example.com", Content: "mail.hosting.test", TTL: 3600, Priority: &prio,
+			ManagedBy: model.ManagedByTemplate, Status: model.StatusPending},
+	}, nil)
+	s.env.OnWorkflow(CreateZoneRecordWorkflow, mock.Anything, model.ZoneRecordParams{
+		RecordID: "rec-1", Name: "example.com", Type: "MX", Content: "mail.hosting.test", TTL: 3600,
+		Priority: &prio, ManagedBy: model.ManagedByTemplate, ZoneName: "example.com",
+	}).Return(nil).Once()
+	s.env.ExecuteWorkflow(CreateZoneWorkflow, zoneID)
+	s.True(s.env.IsWorkflowCompleted())
+	s.NoError(s.env.GetWorkflowError())
+}
+
 // ---------- DeleteZoneWorkflow ----------
 
 type DeleteZoneWorkflowTestSuite struct {
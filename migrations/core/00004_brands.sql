@@ -11,12 +11,34 @@ CREATE TABLE brands (
     dkim_selector    TEXT NOT NULL DEFAULT '',
     dkim_public_key  TEXT NOT NULL DEFAULT '',
     dmarc_policy     TEXT NOT NULL DEFAULT '',
+    -- Per-record-type TTL overrides for auto-managed DNS records, and the TTL
+    -- auto A/AAAA records are lowered to while a tenant moves shards.
+    dns_ttls          JSONB NOT NULL DEFAULT '{}',
+    dns_migration_ttl INT NOT NULL DEFAULT 60,
+    -- Policy user-supplied passwords (database, Valkey and email accounts) of a
+    -- brand's tenants must meet. Classes are any of lower, upper, digit, symbol.
+    password_min_length INT NOT NULL DEFAULT 8 CHECK (password_min_length BETWEEN 8 AND 72),
+    password_classes    TEXT[] NOT NULL DEFAULT '{}',
+    -- Highest connection limits a brand's webroots may set; 0 means no maximum.
+    webroot_max_conns_per_ip     INT NOT NULL DEFAULT 0 CHECK (webroot_max_conns_per_ip >= 0),
+    webroot_max_requests_per_sec INT NOT NULL DEFAULT 0 CHECK (webroot_max_requests_per_sec >= 0),
     status           TEXT NOT NULL DEFAULT 'active',
     created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
     UNIQUE(name)
 );
 
+-- Resellers own a subset of a brand's tenants. API keys with a reseller_id
+-- only see and act on that reseller's tenants.
+CREATE TABLE resellers (
+    id          TEXT PRIMARY KEY,
+    brand_id    TEXT NOT NULL REFERENCES brands(id),
+    name        TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE(brand_id, name)
+);
+
 CREATE TABLE brand_clusters (
     brand_id   TEXT NOT NULL REFERENCES brands(id) ON DELETE CASCADE,
     cluster_id TEXT NOT NULL REFERENCES clusters(id) ON DELETE CASCADE,
@@ -25,4 +47,5 @@ CREATE TABLE brand_clusters (
 
 -- +goose Down
 DROP TABLE brand_clusters;
+DROP TABLE resellers;
 DROP TABLE brands;
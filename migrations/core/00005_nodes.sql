@@ -13,5 +13,18 @@ CREATE TABLE nodes (
     updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
+-- Runtime versions installed on each node, as last reported by its agent.
+-- Used to validate webroot runtime versions and PHP extensions before
+-- provisioning.
+CREATE TABLE node_runtimes (
+    node_id     TEXT NOT NULL REFERENCES nodes(id) ON DELETE CASCADE,
+    runtime     TEXT NOT NULL,
+    version     TEXT NOT NULL,
+    extensions  TEXT[] NOT NULL DEFAULT '{}',
+    reported_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (node_id, runtime, version)
+);
+
 -- +goose Down
+DROP TABLE node_runtimes;
 DROP TABLE nodes;
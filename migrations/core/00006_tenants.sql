@@ -2,10 +2,11 @@
 CREATE TABLE tenants (
     id           TEXT PRIMARY KEY,
     brand_id     TEXT NOT NULL REFERENCES brands(id),
+    reseller_id  TEXT REFERENCES resellers(id),
     customer_id  TEXT NOT NULL,
     region_id    TEXT NOT NULL REFERENCES regions(id),
     cluster_id   TEXT NOT NULL REFERENCES clusters(id),
-    uid          INT NOT NULL UNIQUE,
+    uid          INT NOT NULL DEFAULT 0, -- allocated by CreateTenantWorkflow from the cluster's tenant_uid_min/tenant_uid_max; 0 until then
     sftp_enabled BOOLEAN NOT NULL DEFAULT true,
     ssh_enabled  BOOLEAN NOT NULL DEFAULT false,
     disk_quota_bytes BIGINT NOT NULL DEFAULT 0,
@@ -17,8 +18,8 @@ CREATE TABLE tenants (
 );
 
 CREATE INDEX idx_tenants_customer_id ON tenants(customer_id);
-
-CREATE SEQUENCE tenant_uid_seq START 5000;
+CREATE INDEX idx_tenants_reseller_id ON tenants(reseller_id) WHERE reseller_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_tenants_cluster_uid ON tenants(cluster_id, uid) WHERE uid <> 0;
 
 CREATE TABLE subscriptions (
     id         TEXT PRIMARY KEY,
@@ -32,5 +33,4 @@ CREATE TABLE subscriptions (
 
 -- +goose Down
 DROP TABLE subscriptions;
-DROP SEQUENCE tenant_uid_seq;
 DROP TABLE tenants;
@@ -7,8 +7,24 @@ CREATE TABLE webroots (
     runtime_version          TEXT NOT NULL,
     runtime_config           JSONB NOT NULL DEFAULT '{}',
     public_folder            TEXT NOT NULL DEFAULT '',
+    error_pages              JSONB NOT NULL DEFAULT '{}',
+    basic_auth               JSONB NOT NULL DEFAULT '{}',
     env_file_name            TEXT NOT NULL DEFAULT '.env.hosting',
     service_hostname_enabled BOOLEAN NOT NULL DEFAULT true,
+    -- When enabled, web nodes also write the webroot's access log to the tenant's
+    -- shared logs directory so it can be read back through the API.
+    access_log_enabled       BOOLEAN NOT NULL DEFAULT false,
+    -- Per-client-IP connection and request rate limits, rendered as nginx
+    -- limit_conn/limit_req. '{}' means no limits.
+    connection_limits        JSONB NOT NULL DEFAULT '{}',
+    -- Caching headers and MIME types added to responses by nginx. '{}' adds nothing.
+    static_rules             JSONB NOT NULL DEFAULT '{}',
+    -- Country allow or deny list, enforced by nginx with the geoip2 module.
+    -- '{}' restricts nothing.
+    geo_blocking             JSONB NOT NULL DEFAULT '{}',
+    -- Until deploy_locked_until, nginx answers requests with 503 and
+    -- Retry-After, except ACME challenges. NULL when not locked.
+    deploy_locked_until      TIMESTAMPTZ,
     status                   TEXT NOT NULL DEFAULT 'pending',
     status_message           TEXT,
     suspend_reason           TEXT NOT NULL DEFAULT '',
@@ -22,6 +38,8 @@ CREATE TABLE resource_usage (
     resource_id   TEXT NOT NULL,
     tenant_id     TEXT NOT NULL REFERENCES tenants(id),
     bytes_used    BIGINT NOT NULL DEFAULT 0,
+    key_count     BIGINT,
+    available     BOOLEAN NOT NULL DEFAULT true,
     collected_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
     UNIQUE(resource_type, resource_id)
 );
@@ -5,6 +5,18 @@ CREATE TABLE fqdns (
     fqdn        TEXT NOT NULL,
     webroot_id  TEXT REFERENCES webroots(id),
     ssl_enabled BOOLEAN NOT NULL DEFAULT true,
+    force_https BOOLEAN NOT NULL DEFAULT true,
+    -- Per-FQDN email limits. NULL means unlimited.
+    max_email_accounts INTEGER,
+    email_quota_bytes  BIGINT,
+    -- An FQDN with a redirect_target is a domain alias: nginx answers it with a
+    -- redirect to the target host instead of serving the bound webroot.
+    redirect_target        TEXT,
+    redirect_code          INT NOT NULL DEFAULT 301 CHECK (redirect_code IN (301, 302)),
+    redirect_preserve_path BOOLEAN NOT NULL DEFAULT true,
+    -- Which address families the auto DNS records publish: both (dual_stack)
+    -- or only AAAA records (ipv6_only).
+    ip_mode TEXT NOT NULL DEFAULT 'dual_stack' CHECK (ip_mode IN ('dual_stack', 'ipv6_only')),
     status      TEXT NOT NULL DEFAULT 'pending',
     status_message TEXT,
     created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
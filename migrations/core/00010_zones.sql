@@ -6,6 +6,9 @@ CREATE TABLE zones (
     subscription_id TEXT NOT NULL REFERENCES subscriptions(id),
     name       TEXT NOT NULL UNIQUE,
     region_id  TEXT NOT NULL REFERENCES regions(id),
+    dnssec_status TEXT NOT NULL DEFAULT 'unsigned',
+    -- Regions whose nameservers also serve the zone as AXFR secondaries.
+    secondary_region_ids TEXT[] NOT NULL DEFAULT '{}',
     status     TEXT NOT NULL DEFAULT 'pending',
     status_message TEXT,
     suspend_reason TEXT NOT NULL DEFAULT '',
@@ -13,5 +16,7 @@ CREATE TABLE zones (
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
+CREATE INDEX idx_zones_tenant_id ON zones (tenant_id);
+
 -- +goose Down
 DROP TABLE zones;
@@ -16,5 +16,7 @@ CREATE TABLE zone_records (
     updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
+CREATE INDEX idx_zone_records_zone_id ON zone_records (zone_id);
+
 -- +goose Down
 DROP TABLE zone_records;
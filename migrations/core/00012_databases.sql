@@ -4,6 +4,8 @@ CREATE TABLE databases (
     tenant_id       TEXT NOT NULL REFERENCES tenants(id),
     subscription_id TEXT NOT NULL REFERENCES subscriptions(id),
     node_id    TEXT REFERENCES nodes(id),
+    charset    TEXT NOT NULL DEFAULT 'utf8mb4',
+    collation  TEXT NOT NULL DEFAULT 'utf8mb4_unicode_ci',
     status     TEXT NOT NULL DEFAULT 'pending',
     status_message TEXT,
     suspend_reason TEXT NOT NULL DEFAULT '',
@@ -6,6 +6,9 @@ CREATE TABLE email_accounts (
     address      TEXT NOT NULL UNIQUE,
     display_name TEXT NOT NULL DEFAULT '',
     quota_bytes  BIGINT NOT NULL DEFAULT 0,
+    -- bcrypt hash of the account's initial password, handed to Stalwart when
+    -- the account is provisioned. Empty when created without one.
+    password_hash TEXT NOT NULL DEFAULT '',
     status       TEXT NOT NULL DEFAULT 'pending',
     status_message TEXT,
     created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
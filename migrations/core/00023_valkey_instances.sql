@@ -7,6 +7,8 @@ CREATE TABLE valkey_instances (
     port           INTEGER NOT NULL,
     max_memory_mb  INTEGER NOT NULL DEFAULT 64,
     password_hash  TEXT NOT NULL,
+    -- How the instance persists data: RDB snapshots, an append-only file, or not at all.
+    persistence_mode TEXT NOT NULL DEFAULT 'rdb' CHECK (persistence_mode IN ('rdb', 'aof', 'none')),
     status         TEXT NOT NULL DEFAULT 'pending',
     status_message TEXT,
     suspend_reason TEXT NOT NULL DEFAULT '',
@@ -6,11 +6,15 @@ CREATE TABLE api_keys (
     key_prefix TEXT NOT NULL DEFAULT '',
     scopes TEXT[] NOT NULL DEFAULT '{"*:*"}',
     brands TEXT[] NOT NULL DEFAULT '{"*"}',
+    reseller_id TEXT REFERENCES resellers(id) ON DELETE CASCADE,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     revoked_at TIMESTAMPTZ
 );
 
 CREATE INDEX idx_api_keys_key_hash ON api_keys (key_hash) WHERE revoked_at IS NULL;
 
+-- Admin UI SSO logins provision one key per user, named sso:<email>.
+CREATE UNIQUE INDEX idx_api_keys_sso_name ON api_keys (name) WHERE revoked_at IS NULL AND name LIKE 'sso:%';
+
 -- +goose Down
 DROP TABLE IF EXISTS api_keys;
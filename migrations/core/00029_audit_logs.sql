@@ -6,6 +6,9 @@ CREATE TABLE audit_logs (
     path TEXT NOT NULL,
     resource_type TEXT,
     resource_id TEXT,
+    -- Tenant a request acted on, as reported by the handler. Requests without
+    -- one (platform and brand administration) are left out of tenant event feeds.
+    tenant_id TEXT,
     status_code INT NOT NULL,
     request_body JSONB,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now()
@@ -13,6 +16,7 @@ CREATE TABLE audit_logs (
 
 CREATE INDEX idx_audit_logs_created_at ON audit_logs (created_at);
 CREATE INDEX idx_audit_logs_resource_type ON audit_logs (resource_type) WHERE resource_type IS NOT NULL;
+CREATE INDEX idx_audit_logs_tenant ON audit_logs (tenant_id, created_at DESC, id DESC) WHERE tenant_id IS NOT NULL;
 
 -- +goose Down
 DROP TABLE IF EXISTS audit_logs;
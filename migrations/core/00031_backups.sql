@@ -11,10 +11,30 @@ CREATE TABLE backups (
     status_message TEXT,
     started_at TIMESTAMPTZ,
     completed_at TIMESTAMPTZ,
+    verify_status TEXT, -- NULL until restore-tested, then 'passed' or 'failed'
+    verify_message TEXT,
+    verified_at TIMESTAMPTZ,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
     updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 CREATE INDEX idx_backups_tenant_id ON backups(tenant_id);
 
+CREATE TABLE tenant_exports (
+    id TEXT PRIMARY KEY,
+    tenant_id TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    object_key TEXT NOT NULL DEFAULT '', -- key in the export bucket once uploaded
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    status TEXT NOT NULL DEFAULT 'pending',
+    status_message TEXT,
+    expires_at TIMESTAMPTZ NOT NULL, -- archive is deleted after this
+    completed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_tenant_exports_tenant_id ON tenant_exports(tenant_id);
+-- At most one export runs per tenant at a time.
+CREATE UNIQUE INDEX idx_tenant_exports_in_progress ON tenant_exports(tenant_id) WHERE status IN ('pending', 'provisioning');
+
 -- +goose Down
+DROP TABLE tenant_exports;
 DROP TABLE backups;
@@ -6,6 +6,7 @@ CREATE TABLE s3_buckets (
     shard_id    TEXT REFERENCES shards(id),
     public      BOOLEAN NOT NULL DEFAULT false,
     quota_bytes BIGINT NOT NULL DEFAULT 0,
+    lifecycle_rules JSONB NOT NULL DEFAULT '[]', -- converged onto the RGW bucket by UpdateS3BucketWorkflow
     status      TEXT NOT NULL DEFAULT 'pending',
     status_message TEXT,
     suspend_reason TEXT NOT NULL DEFAULT '',
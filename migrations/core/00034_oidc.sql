@@ -39,9 +39,18 @@ CREATE TABLE oidc_login_sessions (
     database_id TEXT REFERENCES databases(id),
     expires_at TIMESTAMPTZ NOT NULL,
     used BOOLEAN NOT NULL DEFAULT false,
+    -- A consumed session stays registered while its DB Admin access is live:
+    -- temp_username is the temporary MySQL user created for it and
+    -- active_until when that user expires. Revoked sessions cannot be used or
+    -- extended and their MySQL user is dropped.
+    temp_username TEXT,
+    active_until TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
     created_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
+CREATE INDEX idx_oidc_login_sessions_tenant ON oidc_login_sessions (tenant_id, created_at);
+
 -- +goose Down
 DROP TABLE IF EXISTS oidc_login_sessions;
 DROP TABLE IF EXISTS oidc_auth_codes;
@@ -4,6 +4,8 @@ CREATE TABLE cron_jobs (
     tenant_id             TEXT NOT NULL REFERENCES tenants(id),
     webroot_id            TEXT NOT NULL REFERENCES webroots(id),
     schedule              TEXT NOT NULL,
+    -- IANA timezone the schedule is evaluated in.
+    timezone              TEXT NOT NULL DEFAULT 'UTC',
     command               TEXT NOT NULL,
     working_directory     TEXT NOT NULL DEFAULT '',
     enabled               BOOLEAN NOT NULL DEFAULT false,
@@ -0,0 +1,21 @@
+-- +goose Up
+-- tenant_id has no foreign key so a tenant's delete operation stays
+-- queryable after the tenant row is gone.
+CREATE TABLE operations (
+    id            TEXT PRIMARY KEY,
+    tenant_id     TEXT,
+    workflow_name TEXT NOT NULL,
+    workflow_id   TEXT NOT NULL,
+    run_id        TEXT,
+    status        TEXT NOT NULL DEFAULT 'pending',
+    error         TEXT,
+    started_at    TIMESTAMPTZ,
+    completed_at  TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_operations_tenant_id ON operations (tenant_id);
+CREATE INDEX idx_operations_created_at ON operations (created_at);
+
+-- +goose Down
+DROP TABLE operations;
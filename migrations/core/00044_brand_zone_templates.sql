@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE brand_zone_templates (
+    id         TEXT PRIMARY KEY,
+    brand_id   TEXT NOT NULL REFERENCES brands(id) ON DELETE CASCADE,
+    type       TEXT NOT NULL,
+    name       TEXT NOT NULL,
+    content    TEXT NOT NULL,
+    ttl        INT NOT NULL DEFAULT 3600,
+    priority   INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_brand_zone_templates_brand_id ON brand_zone_templates (brand_id);
+
+-- +goose Down
+DROP TABLE brand_zone_templates;
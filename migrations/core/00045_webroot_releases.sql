@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE webroot_releases (
+    id             TEXT PRIMARY KEY,
+    tenant_id      TEXT NOT NULL REFERENCES tenants(id),
+    webroot_id     TEXT NOT NULL REFERENCES webroots(id) ON DELETE CASCADE,
+    name           TEXT NOT NULL,
+    current        BOOLEAN NOT NULL DEFAULT false,
+    status         TEXT NOT NULL DEFAULT 'pending',
+    status_message TEXT,
+    promoted_at    TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_webroot_releases_tenant_id ON webroot_releases(tenant_id);
+CREATE UNIQUE INDEX idx_webroot_releases_webroot_name ON webroot_releases(webroot_id, name);
+CREATE UNIQUE INDEX idx_webroot_releases_current ON webroot_releases(webroot_id) WHERE current;
+
+-- +goose Down
+DROP TABLE IF EXISTS webroot_releases;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- Per-cron-job environment. Secret values are encrypted with the tenant DEK
+-- (see tenant_encryption_keys), like webroot_env_vars.
+CREATE TABLE cron_job_env_vars (
+    id          TEXT PRIMARY KEY,
+    cron_job_id TEXT NOT NULL REFERENCES cron_jobs(id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    value       TEXT NOT NULL,
+    is_secret   BOOLEAN NOT NULL DEFAULT false,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE(cron_job_id, name)
+);
+
+-- +goose Down
+DROP TABLE cron_job_env_vars;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- Per-domain DKIM keys. When an FQDN has an active key, its mail is signed
+-- with it and its DKIM record is published from it instead of the brand's.
+CREATE TABLE fqdn_dkim_keys (
+    id              TEXT PRIMARY KEY,
+    fqdn_id         TEXT NOT NULL UNIQUE REFERENCES fqdns(id) ON DELETE CASCADE,
+    selector        TEXT NOT NULL,
+    private_key_pem TEXT NOT NULL,
+    public_key      TEXT NOT NULL, -- base64 SubjectPublicKeyInfo, as published in p=
+    status          TEXT NOT NULL DEFAULT 'pending',
+    status_message  TEXT,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE fqdn_dkim_keys;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Idempotency-Key values sent with POST requests, scoped per API key. A row
+-- without status_code is a request still being handled; once it finishes the
+-- response is stored and replayed for retries with the same key. Responses
+-- carrying credentials are stored without their body (response_withheld).
+CREATE TABLE idempotency_keys (
+    api_key_id       TEXT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    key              TEXT NOT NULL,
+    request_hash     TEXT NOT NULL, -- sha256 of method, path and body
+    status_code      INT,
+    response_headers JSONB NOT NULL DEFAULT '{}',
+    response_body    BYTEA,
+    response_withheld BOOLEAN NOT NULL DEFAULT false,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (api_key_id, key)
+);
+CREATE INDEX idx_idempotency_keys_created_at ON idempotency_keys (created_at);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Weighted traffic split for a tenant: weight percent of requests to the
+-- tenant's FQDNs go to shard_id's LB backend, the rest to the tenant's own
+-- shard. No row means all traffic goes to the tenant's shard.
+CREATE TABLE tenant_lb_splits (
+    tenant_id  TEXT PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    shard_id   TEXT NOT NULL REFERENCES shards(id),
+    weight     INT NOT NULL CHECK (weight BETWEEN 1 AND 100),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE tenant_lb_splits;
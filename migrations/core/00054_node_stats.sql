@@ -0,0 +1,20 @@
+-- +goose Up
+-- Latest CPU, memory and disk snapshot per node, written by
+-- CollectNodeStatsWorkflow. Metrics the node agent could not read are NULL.
+CREATE TABLE node_stats (
+    node_id             TEXT PRIMARY KEY REFERENCES nodes(id) ON DELETE CASCADE,
+    cpu_count           INT NOT NULL DEFAULT 0,
+    load_1              DOUBLE PRECISION,
+    load_5              DOUBLE PRECISION,
+    load_15             DOUBLE PRECISION,
+    mem_total_bytes     BIGINT,
+    mem_available_bytes BIGINT,
+    swap_total_bytes    BIGINT,
+    swap_free_bytes     BIGINT,
+    disks               JSONB NOT NULL DEFAULT '[]',
+    errors              JSONB NOT NULL DEFAULT '[]',
+    collected_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE node_stats;
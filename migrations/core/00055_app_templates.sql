@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE app_templates (
+    id              TEXT NOT NULL,
+    brand_id        TEXT NOT NULL REFERENCES brands(id) ON DELETE CASCADE,
+    name            TEXT NOT NULL,
+    description     TEXT NOT NULL DEFAULT '',
+    runtime         TEXT NOT NULL,
+    runtime_version TEXT NOT NULL,
+    runtime_config  JSONB NOT NULL DEFAULT '{}',
+    public_folder   TEXT NOT NULL DEFAULT '',
+    create_database BOOLEAN NOT NULL DEFAULT false,
+    env_vars        JSONB NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (brand_id, id)
+);
+
+-- +goose Down
+DROP TABLE app_templates;
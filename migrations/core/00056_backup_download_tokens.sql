@@ -0,0 +1,16 @@
+-- +goose Up
+-- Single-use backup download URLs. A row is consumed (used_at set) by the
+-- first download; reusable URLs are not recorded here.
+CREATE TABLE backup_download_tokens (
+    nonce       TEXT PRIMARY KEY,
+    backup_id   TEXT NOT NULL REFERENCES backups(id) ON DELETE CASCADE,
+    api_key_id  TEXT,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_backup_download_tokens_expires_at ON backup_download_tokens(expires_at);
+
+-- +goose Down
+DROP TABLE backup_download_tokens;
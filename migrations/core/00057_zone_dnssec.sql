@@ -0,0 +1,20 @@
+-- +goose Up
+-- Public part of each zone's DNSSEC keys, mirrored from the PowerDNS
+-- cryptokeys table so the API can show DNSKEY and DS records. Private keys
+-- only live in PowerDNS.
+CREATE TABLE zone_dnssec_keys (
+    zone_id     TEXT NOT NULL REFERENCES zones(id) ON DELETE CASCADE,
+    pdns_key_id INT NOT NULL,
+    key_type    TEXT NOT NULL,
+    key_tag     INT NOT NULL,
+    algorithm   INT NOT NULL,
+    dnskey      TEXT NOT NULL,
+    ds          TEXT,
+    active      BOOLEAN NOT NULL,
+    published   BOOLEAN NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (zone_id, pdns_key_id)
+);
+
+-- +goose Down
+DROP TABLE zone_dnssec_keys;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Daily window, in the tenant's timezone, during which disruptive automatic
+-- operations such as certificate renewals are scheduled. start_time is local
+-- "HH:MM". No row means the platform default schedule applies.
+CREATE TABLE tenant_maintenance_windows (
+    tenant_id        TEXT PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    start_time       TEXT NOT NULL,
+    duration_minutes INT NOT NULL CHECK (duration_minutes BETWEEN 60 AND 720),
+    timezone         TEXT NOT NULL,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE tenant_maintenance_windows;
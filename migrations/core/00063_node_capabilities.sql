@@ -0,0 +1,12 @@
+-- +goose Up
+-- Agent version and registered activities last reported by each node agent,
+-- written by CollectNodeCapabilitiesWorkflow.
+CREATE TABLE node_capabilities (
+    node_id       TEXT PRIMARY KEY REFERENCES nodes(id) ON DELETE CASCADE,
+    agent_version TEXT NOT NULL,
+    activities    TEXT[] NOT NULL DEFAULT '{}',
+    reported_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE node_capabilities;
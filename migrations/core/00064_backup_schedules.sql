@@ -0,0 +1,17 @@
+-- +goose Up
+-- Automatic backups of all of a tenant's webroots and databases, taken daily
+-- or weekly by RunScheduledBackupsWorkflow. retention_days overrides the
+-- platform backup retention for the tenant's backups while the schedule is
+-- enabled. No row means the tenant is only backed up on request.
+CREATE TABLE backup_schedules (
+    tenant_id      TEXT PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    frequency      TEXT NOT NULL CHECK (frequency IN ('daily', 'weekly')),
+    retention_days INT NOT NULL CHECK (retention_days BETWEEN 1 AND 365),
+    enabled        BOOLEAN NOT NULL DEFAULT true,
+    last_run_at    TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE backup_schedules;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- ACME CA a brand's certificates are issued from, e.g. ZeroSSL or an internal
+-- CA. Brands without a row use the platform default (ACME_DIRECTORY_URL).
+-- The External Account Binding HMAC key is encrypted with the platform KEK.
+CREATE TABLE brand_acme_configs (
+    brand_id               TEXT PRIMARY KEY REFERENCES brands(id) ON DELETE CASCADE,
+    directory_url          TEXT NOT NULL,
+    email                  TEXT NOT NULL DEFAULT '',
+    eab_key_id             TEXT NOT NULL DEFAULT '',
+    eab_hmac_key_encrypted TEXT NOT NULL DEFAULT '',
+    created_at             TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at             TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- +goose Down
+DROP TABLE brand_acme_configs;
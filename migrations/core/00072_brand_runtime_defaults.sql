@@ -0,0 +1,12 @@
+-- +goose Up
+-- Runtime version a webroot of the brand gets when it is created without
+-- one, e.g. php -> 8.3. Runtimes without a row have no default.
+CREATE TABLE brand_runtime_defaults (
+    brand_id TEXT NOT NULL REFERENCES brands(id) ON DELETE CASCADE,
+    runtime  TEXT NOT NULL,
+    version  TEXT NOT NULL,
+    PRIMARY KEY (brand_id, runtime)
+);
+
+-- +goose Down
+DROP TABLE brand_runtime_defaults;
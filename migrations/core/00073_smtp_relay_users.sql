@@ -0,0 +1,20 @@
+-- +goose Up
+-- SMTP relay credentials tenants' applications use to send mail through the
+-- cluster's Stalwart. Each user is a Stalwart principal named after its
+-- username, throttled to max_messages_per_hour. Only the bcrypt hash of the
+-- generated password is stored.
+CREATE TABLE smtp_relay_users (
+    id                    TEXT PRIMARY KEY,
+    tenant_id             TEXT NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    username              TEXT NOT NULL UNIQUE,
+    password_hash         TEXT NOT NULL,
+    max_messages_per_hour INT NOT NULL DEFAULT 100 CHECK (max_messages_per_hour > 0),
+    status                TEXT NOT NULL DEFAULT 'pending',
+    status_message        TEXT,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+CREATE INDEX idx_smtp_relay_users_tenant_id ON smtp_relay_users (tenant_id);
+
+-- +goose Down
+DROP TABLE smtp_relay_users;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- DNSSEC key and metadata tables of the PowerDNS gpgsql schema, used once
+-- gpgsql-dnssec is enabled.
+CREATE TABLE domainmetadata (
+    id        SERIAL PRIMARY KEY,
+    domain_id INT NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    kind      VARCHAR(32),
+    content   TEXT
+);
+CREATE INDEX domainmetadata_domain_id_idx ON domainmetadata(domain_id);
+
+CREATE TABLE cryptokeys (
+    id        SERIAL PRIMARY KEY,
+    domain_id INT NOT NULL REFERENCES domains(id) ON DELETE CASCADE,
+    flags     INT NOT NULL,
+    active    BOOLEAN,
+    published BOOLEAN DEFAULT true,
+    content   TEXT
+);
+CREATE INDEX cryptokeys_domain_id_idx ON cryptokeys(domain_id);
+
+-- +goose Down
+DROP TABLE cryptokeys;
+DROP TABLE domainmetadata;
@@ -10,7 +10,7 @@ apt-get install -y haproxy socat
 
 # Create map directory used by HAProxy for FQDN-to-shard routing.
 mkdir -p /var/lib/haproxy/maps
-touch /var/lib/haproxy/maps/fqdn-to-shard.map
+touch /var/lib/haproxy/maps/fqdn-to-shard.map /var/lib/haproxy/maps/fqdn-split.map
 chown -R haproxy:haproxy /var/lib/haproxy
 
 # Create HAProxy run directory.
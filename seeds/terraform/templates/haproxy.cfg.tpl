@@ -25,8 +25,16 @@ frontend stats
 frontend http
     bind *:80
     bind *:443 ssl crt /etc/haproxy/certs/hosting.pem alpn http/1.1
+    # Weighted traffic splits: fqdn-split.map holds "<backend>|<weight>" for FQDNs
+    # of tenants with a split; weight percent of their requests go to that backend
+    http-request set-var(txn.split) req.hdr(host),lower,map(/var/lib/haproxy/maps/fqdn-split.map)
+    http-request set-var(txn.split) req.hdr(host),lower,regsub(^[^.]+,*),map(/var/lib/haproxy/maps/fqdn-split.map) unless { var(txn.split) -m found } || { req.hdr(host),lower,map(/var/lib/haproxy/maps/fqdn-to-shard.map) -m found }
+    http-request set-var(txn.split_weight) var(txn.split),field(2,|)
+    use_backend %[var(txn.split),field(1,|)] if { var(txn.split) -m found } { rand(100),sub(txn.split_weight) lt 0 }
     # Tenant routing via dynamic map
-    use_backend %[req.hdr(host),lower,map(/var/lib/haproxy/maps/fqdn-to-shard.map,shard-default)]
+    use_backend %[req.hdr(host),lower,map(/var/lib/haproxy/maps/fqdn-to-shard.map)] if { req.hdr(host),lower,map(/var/lib/haproxy/maps/fqdn-to-shard.map) -m found }
+    # Wildcard bindings are stored as "*.example.com": retry with the first label replaced
+    use_backend %[req.hdr(host),lower,regsub(^[^.]+,*),map(/var/lib/haproxy/maps/fqdn-to-shard.map,shard-default)]
 
 # Default backend (returns 503 for unmapped FQDNs)
 backend shard-default
@@ -8,6 +8,7 @@ interface StatusBadgeProps {
 const statusConfig: Record<string, { bg: string; text: string; label?: string }> = {
   active: { bg: 'bg-emerald-500/10', text: 'text-emerald-500' },
   provisioning: { bg: 'bg-blue-500/10', text: 'text-blue-500' },
+  migrating: { bg: 'bg-blue-500/10', text: 'text-blue-500' },
   pending: { bg: 'bg-yellow-500/10', text: 'text-yellow-500' },
   suspended: { bg: 'bg-orange-500/10', text: 'text-orange-500' },
   failed: { bg: 'bg-red-500/10', text: 'text-red-500' },
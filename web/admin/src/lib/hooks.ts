@@ -328,7 +328,7 @@ export function useCreateWebroot() {
 export function useUpdateWebroot() {
   const qc = useQueryClient()
   return useMutation({
-    mutationFn: (data: { id: string; runtime?: string; runtime_version?: string; runtime_config?: Record<string, unknown>; public_folder?: string; env_file_name?: string; service_hostname_enabled?: boolean }) =>
+    mutationFn: (data: { id: string; runtime?: string; runtime_version?: string; runtime_config?: Record<string, unknown>; public_folder?: string; env_file_name?: string; service_hostname_enabled?: boolean; access_log_enabled?: boolean }) =>
       api.put<Webroot>(`/webroots/${data.id}`, data),
     onSuccess: () => {
       qc.invalidateQueries({ queryKey: ['webroots'] })
@@ -409,7 +409,7 @@ export function useCreateFQDN() {
 export function useUpdateFQDN() {
   const qc = useQueryClient()
   return useMutation({
-    mutationFn: (data: { id: string; webroot_id?: string | null; ssl_enabled?: boolean }) =>
+    mutationFn: (data: { id: string; webroot_id?: string | null; ssl_enabled?: boolean; force_https?: boolean }) =>
       api.put<FQDN>(`/fqdns/${data.id}`, data),
     onSuccess: () => {
       qc.invalidateQueries({ queryKey: ['fqdns'] })
@@ -751,7 +751,7 @@ export function useValkeyInstance(id: string) {
 export function useCreateValkeyInstance() {
   const qc = useQueryClient()
   return useMutation({
-    mutationFn: (data: { tenant_id: string; subscription_id: string; shard_id: string; max_memory_mb?: number; users?: ValkeyUserFormData[] }) =>
+    mutationFn: (data: { tenant_id: string; subscription_id: string; shard_id: string; max_memory_mb?: number; persistence_mode?: 'rdb' | 'aof' | 'none'; users?: ValkeyUserFormData[] }) =>
       api.post<ValkeyInstance>(`/tenants/${data.tenant_id}/valkey-instances`, data),
     onSuccess: () => qc.invalidateQueries({ queryKey: ['valkey-instances'] }),
   })
@@ -944,7 +944,7 @@ export function useCronJobs(webrootId: string) {
 export function useCreateCronJob() {
   const qc = useQueryClient()
   return useMutation({
-    mutationFn: (data: { webroot_id: string; schedule: string; command: string; working_directory?: string; timeout_seconds?: number; max_memory_mb?: number }) =>
+    mutationFn: (data: { webroot_id: string; schedule: string; command: string; working_directory?: string; timeout_seconds?: number; max_memory_mb?: number; timezone?: string }) =>
       api.post<CronJob>(`/webroots/${data.webroot_id}/cron-jobs`, data),
     onSuccess: () => qc.invalidateQueries({ queryKey: ['cron-jobs'] }),
   })
@@ -953,7 +953,7 @@ export function useCreateCronJob() {
 export function useUpdateCronJob() {
   const qc = useQueryClient()
   return useMutation({
-    mutationFn: (data: { id: string; schedule?: string; command?: string; working_directory?: string; timeout_seconds?: number; max_memory_mb?: number }) =>
+    mutationFn: (data: { id: string; schedule?: string; command?: string; working_directory?: string; timeout_seconds?: number; max_memory_mb?: number; timezone?: string }) =>
       api.put<CronJob>(`/cron-jobs/${data.id}`, data),
     onSuccess: () => qc.invalidateQueries({ queryKey: ['cron-jobs'] }),
   })
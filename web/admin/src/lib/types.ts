@@ -46,6 +46,7 @@ export interface Node {
   ip6_address?: string | null
   roles: string[]
   shards?: { shard_id: string; shard_role: string; shard_index: number }[]
+  agent_version?: string
   status: string
   created_at: string
   updated_at: string
@@ -63,6 +64,8 @@ export interface Brand {
   dkim_selector?: string
   dkim_public_key?: string
   dmarc_policy?: string
+  dns_ttls?: Record<string, number>
+  dns_migration_ttl?: number
   status: string
   created_at: string
   updated_at: string
@@ -113,6 +116,7 @@ export interface Webroot {
   public_folder: string
   env_file_name: string
   service_hostname_enabled: boolean
+  access_log_enabled: boolean
   status: string
   status_message?: string
   created_at: string
@@ -131,6 +135,13 @@ export interface FQDN {
   fqdn: string
   webroot_id?: string | null
   ssl_enabled: boolean
+  force_https: boolean
+  redirect_target?: string | null
+  redirect_code: number
+  redirect_preserve_path: boolean
+  ip_mode: 'dual_stack' | 'ipv6_only'
+  max_email_accounts?: number
+  email_quota_bytes?: number
   status: string
   status_message?: string
   created_at: string
@@ -163,6 +174,7 @@ export interface Zone {
   region_id: string
   status: string
   status_message?: string
+  dnssec_status: 'unsigned' | 'signing' | 'signed' | 'unsigning' | 'failed'
   created_at: string
   updated_at: string
   region_name?: string
@@ -216,6 +228,7 @@ export interface ValkeyInstance {
   shard_id?: string | null
   port: number
   max_memory_mb: number
+  persistence_mode: 'rdb' | 'aof' | 'none'
   password?: string
   status: string
   status_message?: string
@@ -294,6 +307,7 @@ export interface CronJob {
   working_directory: string
   timeout_seconds: number
   max_memory_mb: number
+  timezone: string
   consecutive_failures: number
   max_failures: number
   enabled: boolean
@@ -371,6 +385,9 @@ export interface Backup {
   status_message?: string
   started_at?: string | null
   completed_at?: string | null
+  verify_status?: 'passed' | 'failed' | null
+  verify_message?: string | null
+  verified_at?: string | null
   created_at: string
   updated_at: string
 }
@@ -505,7 +522,7 @@ export interface DatabaseUserFormData {
   username: string; password: string; privileges: string[]
 }
 export interface ValkeyInstanceFormData {
-  subscription_id: string; shard_id: string; max_memory_mb?: number
+  subscription_id: string; shard_id: string; max_memory_mb?: number; persistence_mode?: 'rdb' | 'aof' | 'none'
   users?: ValkeyUserFormData[]
 }
 export interface ValkeyUserFormData {
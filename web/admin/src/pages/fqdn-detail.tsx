@@ -119,6 +119,18 @@ export function FQDNDetailPage() {
           />
           <span>SSL {fqdn.ssl_enabled ? 'Enabled' : 'Disabled'}</span>
         </span>
+        <span className="ml-4 flex items-center gap-2">
+          <Switch
+            checked={fqdn.force_https}
+            disabled={updateFqdnMut.isPending || !fqdn.ssl_enabled}
+            onCheckedChange={(checked) =>
+              updateFqdnMut.mutateAsync({ id: fqdnId, force_https: checked })
+                .then(() => toast.success(checked ? 'HTTPS redirect enabled' : 'HTTPS redirect disabled'))
+                .catch((e: unknown) => toast.error(e instanceof Error ? e.message : 'Failed to update HTTPS redirect'))
+            }
+          />
+          <span>Force HTTPS {fqdn.force_https ? 'On' : 'Off'}</span>
+        </span>
       </div>
 
       <Tabs value={activeTab} onValueChange={(v) => { setActiveTab(v); window.history.replaceState(null, '', `#${v}`) }}>
@@ -680,6 +680,18 @@ export function TenantDetailPage() {
         </div>
       ),
     },
+    {
+      accessorKey: 'verify_status', header: 'Verified',
+      cell: ({ row }) => {
+        const b = row.original
+        if (!b.verify_status) return <span className="text-sm text-muted-foreground">-</span>
+        return (
+          <span className={`text-sm ${b.verify_status === 'failed' ? 'text-destructive' : 'text-muted-foreground'}`} title={b.verify_message ?? undefined}>
+            {b.verify_status === 'passed' ? 'Passed' : 'Failed'}{b.verified_at ? ` ${formatDate(b.verified_at)}` : ''}
+          </span>
+        )
+      },
+    },
     {
       accessorKey: 'created_at', header: 'Created',
       cell: ({ row }) => <span className="text-sm text-muted-foreground">{formatDate(row.original.created_at)}</span>,
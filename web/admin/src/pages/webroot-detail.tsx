@@ -102,6 +102,7 @@ export function WebrootDetailPage() {
   const [cronWorkDir, setCronWorkDir] = useState('')
   const [cronTimeout, setCronTimeout] = useState('300')
   const [cronMaxMem, setCronMaxMem] = useState('512')
+  const [cronTimezone, setCronTimezone] = useState('UTC')
   const [expandedCronId, setExpandedCronId] = useState<string | null>(null)
   const [expandedDaemonId, setExpandedDaemonId] = useState<string | null>(null)
 
@@ -239,7 +240,7 @@ export function WebrootDetailPage() {
 
   // Cron job helpers
   const resetCronForm = () => {
-    setCronSchedule(''); setCronCommand(''); setCronWorkDir(''); setCronTimeout('300'); setCronMaxMem('512')
+    setCronSchedule(''); setCronCommand(''); setCronWorkDir(''); setCronTimeout('300'); setCronMaxMem('512'); setCronTimezone('UTC')
     setTouched({})
   }
 
@@ -251,6 +252,7 @@ export function WebrootDetailPage() {
     setCronWorkDir(c.working_directory)
     setCronTimeout(String(c.timeout_seconds))
     setCronMaxMem(String(c.max_memory_mb))
+    setCronTimezone(c.timezone || 'UTC')
     setEditCron(c)
   }
 
@@ -265,6 +267,7 @@ export function WebrootDetailPage() {
         working_directory: cronWorkDir || undefined,
         timeout_seconds: parseInt(cronTimeout) || 300,
         max_memory_mb: parseInt(cronMaxMem) || 512,
+        timezone: cronTimezone.trim() || 'UTC',
       })
       toast.success('Cron job created'); setCreateCronOpen(false); resetCronForm()
     } catch (e: unknown) { toast.error(e instanceof Error ? e.message : 'Failed') }
@@ -280,6 +283,7 @@ export function WebrootDetailPage() {
         working_directory: cronWorkDir || undefined,
         timeout_seconds: parseInt(cronTimeout) || 300,
         max_memory_mb: parseInt(cronMaxMem) || 512,
+        timezone: cronTimezone.trim() || 'UTC',
       })
       toast.success('Cron job updated'); setEditCron(null)
     } catch (e: unknown) { toast.error(e instanceof Error ? e.message : 'Failed') }
@@ -393,7 +397,7 @@ export function WebrootDetailPage() {
     },
     {
       accessorKey: 'schedule', header: 'Schedule',
-      cell: ({ row }) => <span className="font-mono text-xs">{row.original.schedule}</span>,
+      cell: ({ row }) => <span className="font-mono text-xs">{row.original.schedule}{row.original.timezone && row.original.timezone !== 'UTC' && <span className="text-muted-foreground"> ({row.original.timezone})</span>}</span>,
     },
     {
       accessorKey: 'command', header: 'Command',
@@ -503,6 +507,11 @@ export function WebrootDetailPage() {
         {touched['cronSchedule'] && !cronSchedule.trim() && <p className="text-xs text-destructive">Required</p>}
         <p className="text-xs text-muted-foreground">Cron expression (minute hour day month weekday)</p>
       </div>
+      <div className="space-y-2">
+        <Label>Timezone</Label>
+        <Input placeholder="UTC" value={cronTimezone} onChange={(e) => setCronTimezone(e.target.value)} className="font-mono" />
+        <p className="text-xs text-muted-foreground">IANA timezone the schedule runs in, e.g. Europe/Oslo</p>
+      </div>
       <div className="space-y-2">
         <Label>Command *</Label>
         <Input placeholder="php artisan schedule:run" value={cronCommand} onChange={(e) => setCronCommand(e.target.value)} onBlur={() => touch('cronCmd')} />
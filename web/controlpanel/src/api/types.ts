@@ -69,6 +69,11 @@ export interface FQDN {
   webroot_id: string | null;
   fqdn: string;
   ssl_enabled: boolean;
+  force_https: boolean;
+  redirect_target?: string | null;
+  redirect_code: number;
+  redirect_preserve_path: boolean;
+  ip_mode: 'dual_stack' | 'ipv6_only';
   status: string;
   created_at: string;
   updated_at: string;
@@ -101,6 +106,7 @@ export interface CronJob {
   enabled: boolean;
   timeout_seconds: number;
   max_memory_mb: number;
+  timezone: string;
   status: string;
   status_message: string | null;
   created_at: string;